@@ -20,6 +20,8 @@ type Asset interface {
 	GetAssetProgresses(ctx context.Context, assetCIDs []string) (*types.PullResult, error) //perm:admin
 	// CreateAsset notify candidate that user upload asset, return auth token of candidate
 	CreateAsset(ctx context.Context, tokenPayload *types.AuthUserUploadDownloadAsset) (string, error) //perm:admin
+	// CreateIngestToken notify candidate that user ingest asset, return auth token of candidate
+	CreateIngestToken(ctx context.Context, tokenPayload *types.AuthUserIngestAsset) (string, error) //perm:admin
 	// PullAssetWithURL download the file locally from the url and save it as car file
 	PullAssetFromAWS(ctx context.Context, bucket, key string) error //perm:admin
 	// GetAssetView get asset view
@@ -30,4 +32,12 @@ type Asset interface {
 	GetAssetsInBucket(ctx context.Context, bucketID int) ([]string, error) //perm:admin
 	// SyncAssetViewAndData sync assetView and local car
 	SyncAssetViewAndData(ctx context.Context) error //perm:admin
+	// SetCacheConfig sets the policy by which the node evicts its cacheable assets
+	SetCacheConfig(ctx context.Context, cfg *types.CacheConfig) error //perm:admin
+	// MarkAssetsCache marks the assets as cacheable or pinned, the assets are pinned unless marked cacheable
+	MarkAssetsCache(ctx context.Context, marks []*types.AssetCacheMark) error //perm:admin
+	// GetCacheComposition returns the pinned and cacheable assets of the node
+	GetCacheComposition(ctx context.Context) (*types.CacheComposition, error) //perm:admin
+	// ProveStorage hashes the samples of the blocks of the asset picked by the storage challenge and signs the result
+	ProveStorage(ctx context.Context, challenge *types.StorageChallenge) (*types.StorageChallengeResult, error) //perm:admin
 }
@@ -19,6 +19,21 @@ type Candidate interface {
 	GetExternalAddress(ctx context.Context) (string, error)                        //perm:default
 	CheckNetworkConnectivity(ctx context.Context, network, targetURL string) error //perm:default
 	GetMinioConfig(ctx context.Context) (*types.MinioConfig, error)                //perm:admin
+	// AddRelaySession sets up the session to relay the retrievals of the edge
+	AddRelaySession(ctx context.Context, session *types.RelaySession) error //perm:admin
+	// CollectDiagnostics collects the diagnostics bundle of the candidate, the gzipped tar is returned
+	// or uploaded to the upload url of the request
+	CollectDiagnostics(ctx context.Context, req *types.NodeDiagnosticsReq) ([]byte, error) //perm:admin
+	// Upgrade verifies the upgrade command signed by the scheduler and replaces the binary of the candidate with the release,
+	// the candidate exits after and is restarted by its supervisor
+	Upgrade(ctx context.Context, cmd *types.UpgradeCommand) error //perm:admin
+	// StartLogStream streams the logs of the candidate filtered by the level and the subsystems of the request to the scheduler,
+	// rate limited and until the duration of the request elapses
+	StartLogStream(ctx context.Context, req *types.LogStreamReq) error //perm:admin
+	// StopLogStream closes the log stream
+	StopLogStream(ctx context.Context, id string) error //perm:admin
+	// ApplyNodeConfig applies the config pushed by the scheduler and acknowledges its revision
+	ApplyNodeConfig(ctx context.Context, push *types.NodeConfigPush) (*types.NodeConfigAck, error) //perm:admin
 }
 
 // ValidationResult node Validation result
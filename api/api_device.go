@@ -10,4 +10,6 @@ import (
 type Device interface {
 	GetNodeInfo(ctx context.Context) (types.NodeInfo, error) //perm:admin
 	GetNodeID(ctx context.Context) (string, error)           //perm:admin
+	// RunHardwareChallenge runs the disk probe and memory benchmark of the challenge
+	RunHardwareChallenge(ctx context.Context, challenge *types.HardwareChallenge) (*types.HardwareChallengeResult, error) //perm:admin
 }
@@ -21,4 +21,27 @@ type Edge interface {
 	UserNATPunch(ctx context.Context, userServiceAddress string, req *types.NatPunchReq) error //perm:admin
 	// GetEdgeOnlineStateFromScheduler this online state is get from scheduler
 	GetEdgeOnlineStateFromScheduler(ctx context.Context) (bool, error) //perm:default
+	// SetUploadLimit limits the upload rate of the data server, unit: byte per second, 0 removes the limit
+	SetUploadLimit(ctx context.Context, bytesPerSec int64) error //perm:admin
+	// BandwidthTest measures the latency and the bandwidth of the edge against the candidates one by one,
+	// the transfers are timed and signed by the candidates
+	BandwidthTest(ctx context.Context, req *types.BandwidthTestReq) ([]*types.BandwidthTestReport, error) //perm:admin
+	// StartBandwidthTest asks the scheduler to test the bandwidth of the edge and waits for the result
+	StartBandwidthTest(ctx context.Context) (*types.BandwidthTest, error) //perm:admin
+	// WebRTCOffer answers the webrtc offer of a browser relayed by the scheduler, the data channels of the session
+	// serve the blocks of the asset of the offer
+	WebRTCOffer(ctx context.Context, offer *types.WebRTCOffer) (*types.WebRTCAnswer, error) //perm:admin
+	// CollectDiagnostics collects the diagnostics bundle of the edge, the gzipped tar is returned
+	// or uploaded to the upload url of the request
+	CollectDiagnostics(ctx context.Context, req *types.NodeDiagnosticsReq) ([]byte, error) //perm:admin
+	// Upgrade verifies the upgrade command signed by the scheduler and replaces the binary of the edge with the release,
+	// the edge exits after and is restarted by its supervisor
+	Upgrade(ctx context.Context, cmd *types.UpgradeCommand) error //perm:admin
+	// StartLogStream streams the logs of the edge filtered by the level and the subsystems of the request to the scheduler,
+	// rate limited and until the duration of the request elapses
+	StartLogStream(ctx context.Context, req *types.LogStreamReq) error //perm:admin
+	// StopLogStream closes the log stream
+	StopLogStream(ctx context.Context, id string) error //perm:admin
+	// ApplyNodeConfig applies the config pushed by the scheduler and acknowledges its revision
+	ApplyNodeConfig(ctx context.Context, push *types.NodeConfigPush) (*types.NodeConfigAck, error) //perm:admin
 }
@@ -3,7 +3,9 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/filecoin-project/go-jsonrpc"
 )
@@ -13,6 +15,9 @@ const (
 	EWeb
 )
 
+// rpcMethodNotFound the code of the error go-jsonrpc answers for an unknown method
+const rpcMethodNotFound = -32601
+
 type ErrUnknown struct{}
 
 func (eu *ErrUnknown) Error() string {
@@ -68,6 +73,21 @@ func ErrorIsIn(err error, errorTypes []error) bool {
 	return false
 }
 
+// IsMethodNotSupported returns whether the error tells the remote does not support the method called,
+// either a remote of an older version without the method or one not implementing it
+func IsMethodNotSupported(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrNotSupported) {
+		return true
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, fmt.Sprintf("RPC error (%d)", rpcMethodNotFound)) || strings.Contains(msg, ErrNotSupported.Error())
+}
+
 func init() {
 	RPCErrors.Register(EUnknown, new(*ErrUnknown))
 	RPCErrors.Register(EWeb, new(*ErrWeb))
@@ -18,12 +18,18 @@ type Locator interface {
 	GetUserAccessPoint(ctx context.Context, userIP string) (*AccessPoint, error) //perm:default
 	// CandidateDownloadInfos retrieves information about candidate's download interface
 	CandidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) //perm:default
+	// GatewayNodes retrieves the nodes to serve the ipfs gateway request, the schedulers of the client area are preferred
+	GatewayNodes(ctx context.Context, cid string) ([]*types.GatewayNode, error) //perm:default
+	// GatewayToken retrieves the token of the node to serve the ipfs gateway request from the scheduler of the node
+	GatewayToken(ctx context.Context, cid string, node *types.GatewayNode) (*types.Token, error) //perm:default
 	// GetCandidateIP retrieves ip of candidate, used in the locator dns
 	GetCandidateIP(ctx context.Context, nodeID string) (string, error) //perm:admin
 	// GetSchedulerWithNode get the scheduler that the node is already connected to
 	GetSchedulerWithNode(ctx context.Context, nodeID string) (string, error) //perm:default
 	// GetSchedulerWithAPIKey get the scheduler that the user create the api key
 	GetSchedulerWithAPIKey(ctx context.Context, apiKey string) (string, error) //perm:default
+	// GetRegionStats rolls up the statistics of the schedulers per region, all regions if areaID is empty
+	GetRegionStats(ctx context.Context, areaID string) ([]*types.RegionStats, error) //perm:admin
 }
 
 // AccessPoint represents an access point within an area, containing scheduler information.
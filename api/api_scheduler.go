@@ -7,6 +7,7 @@ import (
 
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/google/uuid"
 )
 
@@ -21,6 +22,43 @@ type AssetAPI interface {
 	StopAssetRecord(ctx context.Context, cids []string) error //perm:admin
 	// RemoveAssetReplica deletes an asset replica with the specified CID and node from the scheduler
 	RemoveAssetReplica(ctx context.Context, cid, nodeID string) error //perm:admin
+	// GetSeedingProgress returns the progress of the edges pulling the asset from the candidates in waves
+	GetSeedingProgress(ctx context.Context, cid string) (*types.SeedingProgress, error) //perm:web,admin
+	// GetReplicaProgress returns the replicas of the asset, the partial replicas are counted by the share of the asset they hold
+	GetReplicaProgress(ctx context.Context, cid string) (*types.ReplicaProgress, error) //perm:web,admin
+	// OffloadAsset proposes the filecoin storage deal of the asset now rather than once it is idle,
+	// or removes the hot replicas of the asset if its deal is already active
+	OffloadAsset(ctx context.Context, cid string) error //perm:admin
+	// RestoreAsset pulls the archived asset back from its storage deal without waiting for a retrieval
+	RestoreAsset(ctx context.Context, cid string) error //perm:admin
+	// ListColdAssets lists the assets offloaded into the storage deals with the states of their deals, all the states if state is empty
+	ListColdAssets(ctx context.Context, state types.ColdAssetState, limit, offset int) (*types.ListColdAssetsRsp, error) //perm:web,admin
+	// AddToDenylist bans the assets, the pulls of the assets are refused, the replicas are purged and the retrievals are not routed
+	AddToDenylist(ctx context.Context, cids []string, reason string) error //perm:admin
+	// RemoveFromDenylist lifts the ban of the asset
+	RemoveFromDenylist(ctx context.Context, cid string) error //perm:admin
+	// ImportDenylist bans the assets listed by an external denylist in the compact denylist or one cid per line format
+	ImportDenylist(ctx context.Context, source, reason, content string) (*types.DenylistImportResult, error) //perm:admin
+	// ListDenylist lists the banned assets
+	ListDenylist(ctx context.Context, limit, offset int) (*types.ListDenylistRsp, error) //perm:web,admin
+	// ListDenylistEvents lists the audit trail of the denylist, all the assets if cid is empty
+	ListDenylistEvents(ctx context.Context, cid string, limit, offset int) (*types.ListDenylistEventsRsp, error) //perm:web,admin
+	// AddGatewayAsset gives the asset its own gateway hostname <cid>.<zone> pointed at the candidates holding it
+	AddGatewayAsset(ctx context.Context, cid string) error //perm:admin
+	// RemoveGatewayAsset removes the gateway hostname of the asset
+	RemoveGatewayAsset(ctx context.Context, cid string) error //perm:admin
+	// ListGatewayHosts lists the gateway hostnames published by the scheduler with the candidate ips they point at
+	ListGatewayHosts(ctx context.Context) ([]*types.GatewayHost, error) //perm:web,admin
+	// GetGatewayCertificate returns the certificate of the gateway domain obtained by acme with its private key,
+	// the candidates serve the gateway hostnames over https with it
+	GetGatewayCertificate(ctx context.Context) (*types.GatewayCertificate, error) //perm:candidate
+	// MarkAssetCacheable marks the replicas of the asset on the edges as cacheable or pinned,
+	// the cacheable replicas are evicted by the cache policy of the edges when their disks are full
+	MarkAssetCacheable(ctx context.Context, cid string, cacheable bool) error //perm:admin
+	// SetNodeCacheConfig sets the cache eviction policy of the edge node
+	SetNodeCacheConfig(ctx context.Context, nodeID string, cfg *types.CacheConfig) error //perm:admin
+	// GetNodeCacheComposition returns the pinned and cacheable assets of the edge node
+	GetNodeCacheComposition(ctx context.Context, nodeID string) (*types.CacheComposition, error) //perm:web,admin
 	// GetAssetRecord retrieves the asset record with the specified CID
 	GetAssetRecord(ctx context.Context, cid string) (*types.AssetRecord, error) //perm:web,admin
 	// GetAssetRecords retrieves a list of asset records with pagination using the specified limit, offset, and states
@@ -31,6 +69,26 @@ type AssetAPI interface {
 	RePullFailedAssets(ctx context.Context, hashes []types.AssetHash) error //perm:admin
 	// UpdateAssetExpiration updates the expiration time for an asset with the specified CID
 	UpdateAssetExpiration(ctx context.Context, cid string, time time.Time) error //perm:admin
+	// UpdateAssetQoSTier updates the qos tier of an asset with the specified CID, the replicas placed from then on follow the tier
+	UpdateAssetQoSTier(ctx context.Context, cid string, tier types.AssetQoSTier) error //perm:admin
+	// SetAssetGeoPolicy sets the geo policy of an asset with the specified CID, the replicas are placed and reconciled by its rules
+	SetAssetGeoPolicy(ctx context.Context, cid string, rules types.AssetGeoRules) error //perm:admin
+	// RemoveAssetGeoPolicy removes the geo policy of an asset with the specified CID
+	RemoveAssetGeoPolicy(ctx context.Context, cid string) error //perm:admin
+	// GetAssetGeoPolicy retrieves the geo policy of an asset with the specified CID
+	GetAssetGeoPolicy(ctx context.Context, cid string) (*types.AssetGeoPolicy, error) //perm:web,admin
+	// ListAssetGeoPolicies retrieves the geo policies of the assets, the latest updated first
+	ListAssetGeoPolicies(ctx context.Context, limit, offset int) (*types.ListAssetGeoPolicyRsp, error) //perm:web,admin
+	// ListAssetGeoViolations retrieves the violations of the geo policies, of the asset if the cid is not empty
+	ListAssetGeoViolations(ctx context.Context, cid string, limit, offset int) (*types.ListAssetGeoViolationRsp, error) //perm:web,admin
+	// UpdateAssetVideoFormat marks an asset with the specified CID as hls or dash, the nodes prefetch the segments of the marked assets
+	UpdateAssetVideoFormat(ctx context.Context, cid string, format types.AssetVideoFormat) error //perm:admin
+	// GetAssetVideoFormat retrieves the video format of an asset with the specified CID
+	GetAssetVideoFormat(ctx context.Context, cid string) (types.AssetVideoFormat, error) //perm:edge,candidate,web,admin
+	// ReportSegmentHits the node reports the retrievals of the segments of the video assets since its last report
+	ReportSegmentHits(ctx context.Context, hits []*types.SegmentHits) error //perm:edge,candidate
+	// ListSegmentStats retrieves the retrieval stats of the segments of a video asset, the most retrieved first
+	ListSegmentStats(ctx context.Context, cid string, limit, offset int) (*types.ListSegmentStatsRsp, error) //perm:web,admin
 	// NodeRemoveAssetResult the result of an asset removal operation
 	NodeRemoveAssetResult(ctx context.Context, resultInfo types.RemoveAssetResult) error //perm:edge,candidate
 	// GetAssetListForBucket retrieves a list of asset hashes for a bucket associated with the specified bucket ID (bucketID is hash code)
@@ -47,12 +105,33 @@ type AssetAPI interface {
 	GetReplicaEvents(ctx context.Context, start, end time.Time, limit, offset int) (*types.ListReplicaEventRsp, error) //perm:web,admin
 	// CreateAsset creates an asset with car CID, car name, and car size.
 	CreateAsset(ctx context.Context, req *types.CreateAssetReq) (*types.CreateAssetRsp, error) //perm:web,admin,user
+	// CreateIngestTask chooses a candidate to ingest the asset of the user, returns the ingest url and token of the candidate
+	CreateIngestTask(ctx context.Context, req *types.IngestAssetReq) (*types.CreateAssetRsp, error) //perm:web,admin,user
+	// IngestAssetCompleted records the root cid of the asset ingested by the candidate and starts the replication
+	IngestAssetCompleted(ctx context.Context, result *types.IngestAssetResult) error //perm:candidate
 	// ListAssets lists the assets of the user.
 	ListAssets(ctx context.Context, userID string, limit, offset, groupID int) (*types.ListAssetRecordRsp, error) //perm:web,admin,user
+	// SearchAssets searches the assets of the user by their labels, name prefix, size and creation time, the latest first
+	SearchAssets(ctx context.Context, req *types.SearchAssetsReq) (*types.SearchAssetsRsp, error) //perm:web,admin,user
 	// DeleteAsset deletes the asset of the user.
 	DeleteAsset(ctx context.Context, userID, assetCID string) error //perm:web,admin,user
 	// ShareAssets shares the assets of the user.
 	ShareAssets(ctx context.Context, userID string, assetCID []string) (map[string]string, error) //perm:web,admin,user
+	// CreateSignedURL signs a url to share the asset of the user until the expiration, at most 7 days,
+	// the nodes verify the signature with the public key of the scheduler and serve the asset without a token
+	CreateSignedURL(ctx context.Context, userID, assetCID string, expiration time.Time) (*types.SignedURL, error) //perm:web,admin,user
+	// GetDownloadSources retrieves the urls of the nodes holding the asset of the user to resume the download from multiple sources
+	GetDownloadSources(ctx context.Context, userID, assetCID string) (*types.DownloadSources, error) //perm:web,admin,user
+	// GetAssetEncryption retrieves the metadata to decrypt the asset encrypted by the user before the upload, nil if it is not encrypted
+	GetAssetEncryption(ctx context.Context, userID, assetCID string) (*types.AssetEncryption, error) //perm:web,admin,user
+	// SetAssetACL makes the asset of the user private, only the owners and the users and api keys in the acl can download it
+	SetAssetACL(ctx context.Context, userID string, acl *types.AssetACL) error //perm:web,admin,user
+	// RemoveAssetACL makes the asset of the user public
+	RemoveAssetACL(ctx context.Context, userID, assetCID string) error //perm:web,admin,user
+	// GetAssetACL retrieves the access control list of the asset of the user, nil if the asset is public
+	GetAssetACL(ctx context.Context, userID, assetCID string) (*types.AssetACL, error) //perm:web,admin,user
+	// CheckAssetAccess checks whether the user or the api key of the user can download the asset, userID is empty for anonymous requests
+	CheckAssetAccess(ctx context.Context, cid, userID, apiKeyName string) (bool, error) //perm:edge,candidate
 	// UpdateShareStatus update share status of the user asset
 	UpdateShareStatus(ctx context.Context, userID, assetCID string) error //perm:web,admin
 	// GetAssetStatus retrieves a asset status
@@ -74,10 +153,26 @@ type NodeAPI interface {
 	// Node-related methods
 	// GetOnlineNodeCount returns the count of online nodes for a given node type
 	GetOnlineNodeCount(ctx context.Context, nodeType types.NodeType) (int, error) //perm:web,admin
-	// RegisterNode adds new node to the scheduler
+	// GetRegionStats returns the aggregated statistics of the region of the scheduler, they are kept by counters
+	GetRegionStats(ctx context.Context) (*types.RegionStats, error) //perm:web,admin,locator
+	// GetZoneStats returns the statistics of each zone (area) served by the scheduler
+	GetZoneStats(ctx context.Context) ([]*types.RegionStats, error) //perm:web,admin,locator
+	// GetDashboardStats returns the online nodes by area, the assets by state, the validation pass rates of the last day
+	// and the points distribution of the nodes of the scheduler
+	GetDashboardStats(ctx context.Context) (*types.DashboardStats, error) //perm:web,admin
+	// RegisterNode adds new node to the scheduler, the node id is derived from the public key and may be empty,
+	// registering the same key again returns the existing registration
 	RegisterNode(ctx context.Context, nodeID, publicKey string, nodeType types.NodeType) (*types.ActivationDetail, error) //perm:default
+	// RegisterSandboxNode adds an ephemeral test node to the scheduler in the sandbox mode, the node is not limited by
+	// the registrations of its ip, is excluded from the settlements and is purged once its ttl elapses
+	RegisterSandboxNode(ctx context.Context, nodeID, publicKey string, nodeType types.NodeType) (*types.ActivationDetail, error) //perm:default
 	// RegisterEdgeNode adds new edge node to the scheduler
 	RegisterEdgeNode(ctx context.Context, nodeID, publicKey string) (*types.ActivationDetail, error) //perm:default
+	// GetNodeKeyTypes returns the key types accepted for node identity at registration, the preferred type first
+	GetNodeKeyTypes(ctx context.Context) ([]string, error) //perm:default
+	// MigrateNodeKey binds the node id to a new identity key, e.g. from rsa to ed25519, and returns the registration
+	// of the node, sign is the signature of the new public key pem by the current key of the node
+	MigrateNodeKey(ctx context.Context, nodeID, publicKey, sign string) (*types.ActivationDetail, error) //perm:default
 	// DeactivateNode is used to deactivate a node in the titan server.
 	// It stops the node from serving any requests and marks it as inactive.
 	// - nodeID: The ID of the node to deactivate.
@@ -90,6 +185,11 @@ type NodeAPI interface {
 	UndoNodeDeactivation(ctx context.Context, nodeID string) error //perm:web,admin
 	// UpdateNodePort updates the port for the node with the specified node
 	UpdateNodePort(ctx context.Context, nodeID, port string) error //perm:web,admin
+	// SetNodeUploadLimit sets the upload limit of the edge node and pushes it to the node,
+	// unit: byte per second, 0 removes the limit
+	SetNodeUploadLimit(ctx context.Context, nodeID string, bytesPerSec int64) error //perm:admin
+	// GetNodeUploadLimit returns the upload limit of the node and its compliance reported with keepalive
+	GetNodeUploadLimit(ctx context.Context, nodeID string) (*types.UploadLimit, error) //perm:web,admin
 	// EdgeConnect edge node login to the scheduler
 	EdgeConnect(ctx context.Context, opts *types.ConnectOptions) error //perm:edge
 	// CandidateConnect candidate node login to the scheduler
@@ -98,32 +198,240 @@ type NodeAPI interface {
 	GetExternalAddress(ctx context.Context) (string, error) //perm:default
 	// NodeLogin generates an authentication token for a node with the specified node ID and signature
 	NodeLogin(ctx context.Context, nodeID, sign string) (string, error) //perm:default
+	// NodeLoginChallenge issues a one-time nonce for the login of the node
+	NodeLoginChallenge(ctx context.Context, nodeID string) (string, error) //perm:default
+	// NodeLoginV2 generates an authentication token for a node that signed the nonce of NodeLoginChallenge,
+	// the token is bound to the host of the caller
+	NodeLoginV2(ctx context.Context, req *types.NodeLoginReq) (string, error) //perm:default
+	// IssueNodeCertificate issues a client certificate of mutual tls for the candidate,
+	// csr is pem encoded with the node id as common name, sign is the signature of csr by the node private key
+	IssueNodeCertificate(ctx context.Context, nodeID, sign string, csr []byte) (*types.NodeCertificate, error) //perm:default
 	// GetNodeInfo get information for node
 	GetNodeInfo(ctx context.Context, nodeID string) (types.NodeInfo, error) //perm:web,admin
 	// GetNodeList retrieves a list of nodes with pagination using the specified cursor and count
 	GetNodeList(ctx context.Context, cursor int, count int) (*types.ListNodesRsp, error) //perm:web,admin
+	// ListNodes retrieves a page of nodes matching the filters, sorted and paginated by cursor
+	ListNodes(ctx context.Context, req *types.ListNodesReq) (*types.ListNodesCursorRsp, error) //perm:web,admin
 	// GetCandidateURLsForDetectNat Get the rpc url of the specified number of candidate nodes
 	GetCandidateURLsForDetectNat(ctx context.Context) ([]string, error) //perm:default
 	// GetEdgeExternalServiceAddress nat travel, get edge external addr with different candidate
 	GetEdgeExternalServiceAddress(ctx context.Context, nodeID, candidateURL string) (string, error) //perm:admin
 	// NatPunch nat punch between user and node
 	NatPunch(ctx context.Context, target *types.NatPunchReq) error //perm:default
+	// GetRelaySession returns the relay session of the edge behind symmetric nat, a candidate is designated as the relay if the edge has none,
+	// returns nil if the edge needs no relay
+	GetRelaySession(ctx context.Context) (*types.RelaySession, error) //perm:edge
+	// SubmitRelayTraffic submits the bytes relayed by the candidate, the bytes are accounted to the edge and the candidate
+	SubmitRelayTraffic(ctx context.Context, traffics []*types.RelayTraffic) error //perm:candidate
+	// GetRelaySessions retrieves the relay sessions the node takes part in with the relayed bytes
+	GetRelaySessions(ctx context.Context, nodeID string, limit, offset int) (*types.ListRelaySessionRsp, error) //perm:web,admin
+	// GetNodeTrafficDaily retrieves the bytes the node uploaded and downloaded per day in the date range [start, end]
+	GetNodeTrafficDaily(ctx context.Context, nodeID string, start, end time.Time) ([]*types.NodeTrafficDaily, error) //perm:web,admin
+	// GetNodeTrafficStatement retrieves the traffic statement of the node in the month formatted as 2006-01, with the daily traffic
+	GetNodeTrafficStatement(ctx context.Context, nodeID, month string) (*types.NodeTrafficStatement, error) //perm:web,admin
+	// GetTrafficStatements retrieves the traffic statements of the nodes in the month formatted as 2006-01
+	GetTrafficStatements(ctx context.Context, month string, limit, offset int) (*types.ListNodeTrafficStatementRsp, error) //perm:web,admin
+	// GetSettlementEpochs retrieves the settled epochs of points with the merkle roots and signatures, the latest first
+	GetSettlementEpochs(ctx context.Context, limit, offset int) (*types.ListSettlementEpochRsp, error) //perm:web,admin
+	// GetSettlementShares retrieves the shares of the accounts in the settled epoch
+	GetSettlementShares(ctx context.Context, epoch int64, limit, offset int) (*types.ListSettlementShareRsp, error) //perm:web,admin
+	// GetSettlementProof retrieves the merkle proof of the share of the account in the settled epoch for the on-chain distributor
+	GetSettlementProof(ctx context.Context, epoch int64, accountID string) (*types.SettlementProof, error) //perm:web,admin
+	// ExportSettlement hands the signed report of the settled epoch to the payout hook again
+	ExportSettlement(ctx context.Context, epoch int64) error //perm:admin
+	// SavePenaltyRule saves the penalty rule and returns its id, the rule is updated if its id is set
+	SavePenaltyRule(ctx context.Context, rule *types.PenaltyRule) (int64, error) //perm:admin
+	// DeletePenaltyRule deletes the penalty rule, the penalties applied by the rule are kept
+	DeletePenaltyRule(ctx context.Context, id int64) error //perm:admin
+	// GetPenaltyRules retrieves the penalty rules with the number of times each has been applied
+	GetPenaltyRules(ctx context.Context) ([]*types.PenaltyRule, error) //perm:web,admin
+	// GetNodePenalties retrieves the penalties applied to the node, the latest first
+	GetNodePenalties(ctx context.Context, nodeID string, limit, offset int) (*types.ListPenaltyRsp, error) //perm:web,admin
+	// AppealPenalty appeals the penalty with the reason, a penalty can only be appealed once
+	AppealPenalty(ctx context.Context, id int64, reason string) error //perm:web,admin
+	// ResolvePenaltyAppeal resolves the pending appeal of the penalty, the points are restored and the freeze is lifted if accepted
+	ResolvePenaltyAppeal(ctx context.Context, id int64, accepted bool) error //perm:admin
+	// GetNodeTrust retrieves the trust tier of the node, the nodes of the lower tiers are validated more often
+	GetNodeTrust(ctx context.Context, nodeID string) (*types.NodeTrust, error) //perm:web,admin
+	// GetNodeTrustTransitions retrieves the transitions of the node between the trust tiers, the latest first
+	GetNodeTrustTransitions(ctx context.Context, nodeID string, limit, offset int) (*types.ListTrustTransitionRsp, error) //perm:web,admin
+	// GetPointsEpochs retrieves the closed utc days of points with the points earned by all nodes, the latest first
+	GetPointsEpochs(ctx context.Context, limit, offset int) (*types.ListPointsEpochRsp, error) //perm:web,admin
+	// GetNodePointsEpochs retrieves the points the node earned in the closed utc days [start, end] with the corrections,
+	// and the points of the node derived from all the closed days and the open day
+	GetNodePointsEpochs(ctx context.Context, nodeID string, start, end time.Time) (*types.NodePointsEpochsRsp, error) //perm:web,admin
+	// CorrectPointsEpoch corrects the points of the node in the closed utc day and returns the correction id,
+	// the closed day is not changed and the points of the correction are added to the node
+	CorrectPointsEpoch(ctx context.Context, correction *types.PointsCorrection) (int64, error) //perm:admin
+	// StartBandwidthTest starts a bandwidth and latency test of the edge against the nearest candidates and returns the test id,
+	// the result updates the bandwidth of the edge, an edge can only test itself
+	StartBandwidthTest(ctx context.Context, nodeID string) (string, error) //perm:edge,web,admin
+	// GetBandwidthTest retrieves the bandwidth test
+	GetBandwidthTest(ctx context.Context, testID string) (*types.BandwidthTest, error) //perm:edge,web,admin
+	// ListBandwidthTests retrieves the bandwidth tests of the node, the latest first
+	ListBandwidthTests(ctx context.Context, nodeID string, limit, offset int) (*types.ListBandwidthTestRsp, error) //perm:web,admin
+	// RequestNodeDiagnostics asks the node to collect a diagnostics bundle and returns the bundle id,
+	// the bundle is kept by the scheduler or uploaded by the node to the presigned url if it is not empty
+	RequestNodeDiagnostics(ctx context.Context, nodeID, uploadURL string) (string, error) //perm:web,admin
+	// GetNodeDiagnostics retrieves the diagnostics bundle without its data
+	GetNodeDiagnostics(ctx context.Context, id string) (*types.NodeDiagnostics, error) //perm:web,admin
+	// GetNodeDiagnosticsData retrieves the gzipped tar of the diagnostics bundle kept by the scheduler
+	GetNodeDiagnosticsData(ctx context.Context, id string) ([]byte, error) //perm:web,admin
+	// ListNodeDiagnostics retrieves the diagnostics bundles of the node, the latest first
+	ListNodeDiagnostics(ctx context.Context, nodeID string, limit, offset int) (*types.ListNodeDiagnosticsRsp, error) //perm:web,admin
+	// StreamNodeLogs streams the logs of the online node filtered by the level and the subsystems of the request,
+	// the channel is closed when ctx is done or the duration of the request elapses
+	StreamNodeLogs(ctx context.Context, nodeID string, req *types.LogStreamReq) (<-chan *types.LogEntry, error) //perm:web,admin
+	// AddReleaseManifest saves the release manifest signed by the release key, a manifest of the same version and node type is replaced
+	AddReleaseManifest(ctx context.Context, manifest *types.ReleaseManifest) error //perm:admin
+	// ListReleaseManifests retrieves the release manifests of the node type, the latest first
+	ListReleaseManifests(ctx context.Context, nodeType types.NodeType) ([]*types.ReleaseManifest, error) //perm:admin
+	// StartUpgradeRollout starts rolling out the release to the canary nodes and then the waves of the nodes, returns the rollout id
+	StartUpgradeRollout(ctx context.Context, req *types.UpgradeRolloutReq) (string, error) //perm:admin
+	// GetUpgradeRollout retrieves the upgrade rollout
+	GetUpgradeRollout(ctx context.Context, id string) (*types.UpgradeRollout, error) //perm:admin
+	// GetUpgradeWaveStats retrieves the nodes of the waves of the rollout by status
+	GetUpgradeWaveStats(ctx context.Context, id string) ([]*types.UpgradeWaveStats, error) //perm:admin
+	// ListUpgradeRollouts retrieves the upgrade rollouts, the latest first
+	ListUpgradeRollouts(ctx context.Context, limit, offset int) (*types.ListUpgradeRolloutRsp, error) //perm:admin
+	// ListUpgradeNodes retrieves the nodes commanded by the rollout, the latest first
+	ListUpgradeNodes(ctx context.Context, id string, limit, offset int) (*types.ListUpgradeNodeRsp, error) //perm:admin
+	// HaltUpgradeRollout halts the running rollout
+	HaltUpgradeRollout(ctx context.Context, id, reason string) error //perm:admin
+	// ResumeUpgradeRollout resumes the halted rollout from its current wave
+	ResumeUpgradeRollout(ctx context.Context, id string) error //perm:admin
+	// GetVersionAdoption retrieves the number of the nodes of the type seen in the last day by release version
+	GetVersionAdoption(ctx context.Context, nodeType types.NodeType) ([]*types.VersionAdoption, error) //perm:web,admin
+	// SetNodeConfigDefaults sets the default config of the node type, the config is pushed to the nodes of the type
+	SetNodeConfigDefaults(ctx context.Context, nodeType types.NodeType, cfg *types.NodeConfig) error //perm:admin
+	// GetNodeConfigDefaults retrieves the default config of the node type
+	GetNodeConfigDefaults(ctx context.Context, nodeType types.NodeType) (*types.NodeConfig, error) //perm:web,admin
+	// SetNodeConfigOverrides sets the config overriding the defaults on the node, the overrides are removed if the config sets nothing
+	SetNodeConfigOverrides(ctx context.Context, nodeID string, cfg *types.NodeConfig) error //perm:admin
+	// GetNodeConfigStatus retrieves the config of the node and the revision the node acknowledged
+	GetNodeConfigStatus(ctx context.Context, nodeID string) (*types.NodeConfigStatus, error) //perm:web,admin
+	// SetFeatureFlag creates or updates the feature flag, the flags of the nodes are pushed with their config
+	SetFeatureFlag(ctx context.Context, flag *types.FeatureFlag) error //perm:admin
+	// RemoveFeatureFlag removes the feature flag
+	RemoveFeatureFlag(ctx context.Context, name string) error //perm:admin
+	// ListFeatureFlags retrieves the feature flags
+	ListFeatureFlags(ctx context.Context) ([]*types.FeatureFlag, error) //perm:web,admin
+	// ListRetrievalProbes retrieves the retrieval probes of the node, the latest first
+	ListRetrievalProbes(ctx context.Context, nodeID string, limit, offset int) (*types.ListRetrievalProbeRsp, error) //perm:web,admin
+	// ListSpotChecks retrieves the spot checks of the edge disguised as client retrievals, the latest first
+	ListSpotChecks(ctx context.Context, nodeID string, limit, offset int) (*types.ListSpotCheckRsp, error) //perm:admin
+	// ListStorageProofs retrieves the latest storage proofs of the replicas of the node, the latest first
+	ListStorageProofs(ctx context.Context, nodeID string, limit, offset int) (*types.ListStorageProofRsp, error) //perm:web,admin
+	// GetRetrievalSLAReport retrieves the sla of the retrievals probed in [start, end) by node or area, the worst sla first
+	GetRetrievalSLAReport(ctx context.Context, groupBy types.RetrievalSLAGroup, start, end time.Time, limit, offset int) (*types.RetrievalSLAReport, error) //perm:web,admin
+	// SetNodeCommitment commits the node to be online in a daily window, the points earned in a kept window get the bonus multiplier
+	// and the broken windows are penalized by the broken commitment rules
+	SetNodeCommitment(ctx context.Context, commitment *types.NodeCommitment) error //perm:web,admin
+	// RemoveNodeCommitment withdraws the commitment of the node
+	RemoveNodeCommitment(ctx context.Context, nodeID string) error //perm:web,admin
+	// GetNodeCommitment retrieves the commitment of the node
+	GetNodeCommitment(ctx context.Context, nodeID string) (*types.NodeCommitment, error) //perm:web,admin
+	// GetNodeCommitmentRecords retrieves the presence of the node in its commitment windows, the latest first
+	GetNodeCommitmentRecords(ctx context.Context, nodeID string, limit, offset int) (*types.ListNodeCommitmentRecordRsp, error) //perm:web,admin
+	// AddMaintenanceWindow declares a maintenance window of the node and returns its id, the node is not selected or validated
+	// and its downtime is not penalized or counted against its score in the window
+	AddMaintenanceWindow(ctx context.Context, window *types.MaintenanceWindow) (int64, error) //perm:web,admin
+	// EndMaintenanceWindow ends the maintenance window now, a window not started yet is canceled
+	EndMaintenanceWindow(ctx context.Context, id int64) error //perm:web,admin
+	// GetNodeMaintenanceWindows retrieves the maintenance windows of the node, the latest first
+	GetNodeMaintenanceWindows(ctx context.Context, nodeID string, limit, offset int) (*types.ListMaintenanceWindowRsp, error) //perm:web,admin
+	// StartBulkJob starts an admin operation on the listed nodes or the nodes selected by the filter as an asynchronous job
+	// and returns the job id, the job runs on the scheduler it is started on
+	StartBulkJob(ctx context.Context, req *types.BulkJobReq) (string, error) //perm:admin
+	// GetBulkJob retrieves the bulk job with its progress
+	GetBulkJob(ctx context.Context, id string) (*types.BulkJob, error) //perm:web,admin
+	// ListBulkJobs retrieves the bulk jobs, the latest first
+	ListBulkJobs(ctx context.Context, limit, offset int) (*types.ListBulkJobRsp, error) //perm:web,admin
+	// ListBulkJobItems retrieves the results of the bulk job on its nodes, the nodes of all statuses if status is empty
+	ListBulkJobItems(ctx context.Context, jobID string, status types.BulkJobItemStatus, limit, offset int) (*types.ListBulkJobItemRsp, error) //perm:web,admin
+	// CancelBulkJob cancels the running bulk job, the results of the nodes processed are kept
+	CancelBulkJob(ctx context.Context, id string) error //perm:admin
+	// ListArchivedNodes retrieves the nodes archived after offline for long, the latest archived first
+	ListArchivedNodes(ctx context.Context, limit, offset int) (*types.ListArchivedNodeRsp, error) //perm:web,admin
+	// RestoreArchivedNode restores the archived node, the node can log in again
+	RestoreArchivedNode(ctx context.Context, nodeID string) error //perm:admin
+	// PurgeNode deletes the offline node with its records for a deletion request, the points and settlements ledgers are kept
+	PurgeNode(ctx context.Context, nodeID string) error //perm:admin
+	// StartDataExport starts to export the dataset for the analytics and returns the export id,
+	// the export is generated asynchronously and uploaded to the export bucket
+	StartDataExport(ctx context.Context, req *types.DataExportReq) (string, error) //perm:admin
+	// GetDataExport retrieves the export with a presigned download url once it succeeded
+	GetDataExport(ctx context.Context, id string) (*types.DataExport, error) //perm:web,admin
+	// ListDataExports retrieves the exports, the latest first
+	ListDataExports(ctx context.Context, limit, offset int) (*types.ListDataExportRsp, error) //perm:web,admin
+	// GetDataExportSchemas retrieves the current columns and schema versions of the datasets
+	GetDataExportSchemas(ctx context.Context) ([]*types.DataExportSchema, error) //perm:web,admin
+
+	// GetSchedulerPeers retrieves the other schedulers registered in etcd, discovered by the scheduler
+	GetSchedulerPeers(ctx context.Context) ([]*types.SchedulerCfg, error) //perm:admin
+	// GetJob retrieves the queued job with its progress
+	GetJob(ctx context.Context, id string) (*types.Job, error) //perm:web,admin
+	// ListJobs retrieves the queued jobs, the jobs of all kinds and statuses if kind and status are empty, the latest first
+	ListJobs(ctx context.Context, kind string, status types.JobStatus, limit, offset int) (*types.ListJobRsp, error) //perm:web,admin
+	// CancelJob cancels the pending or running queued job
+	CancelJob(ctx context.Context, id string) error //perm:admin
 	// GetEdgeDownloadInfos retrieves download information for the edge with the asset with the specified CID.
 	GetEdgeDownloadInfos(ctx context.Context, cid string) (*types.EdgeDownloadInfoList, error) //perm:default
+	// GetClientDownloadInfos retrieves download information for the edge with the asset with the specified CID, the tokens
+	// are issued for the download client, only the receipts signed by the client are accepted for them
+	GetClientDownloadInfos(ctx context.Context, cid, clientID string) (*types.EdgeDownloadInfoList, error) //perm:default
 	// GetCandidateDownloadInfos retrieves download information for the candidate with the asset with the specified CID.
 	GetCandidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) //perm:edge,candidate,web,locator
+	// GetLocationIndexStats retrieves the asset location lookups served by the location index of the retrieval routing
+	GetLocationIndexStats(ctx context.Context) (*types.LocationIndexStats, error) //perm:admin
+	// GetWebRTCConfig retrieves the stun and turn servers the browsers connect the webrtc sessions with
+	GetWebRTCConfig(ctx context.Context) (*types.WebRTCConfig, error) //perm:default
+	// WebRTCConnect relays the webrtc offer of the browser to an edge holding the asset and returns the answer of the edge,
+	// the blocks of the asset are retrieved over the data channels of the session
+	WebRTCConnect(ctx context.Context, req *types.WebRTCOfferReq) (*types.WebRTCAnswer, error) //perm:default
+	// GetGatewayNodes retrieves the nodes holding the asset to serve the ipfs gateway, in order of preference by nat type and load
+	GetGatewayNodes(ctx context.Context, cid string, limit int) ([]*types.GatewayNode, error) //perm:web,locator
+	// GetGatewayToken issues the token of the node holding the asset to serve the ipfs gateway request
+	GetGatewayToken(ctx context.Context, cid, nodeID string) (*types.Token, error) //perm:web,locator
+	// GetParallelDownloadPlan assigns the byte ranges of the file to several edges holding the asset to download in parallel
+	GetParallelDownloadPlan(ctx context.Context, req *types.ParallelDownloadReq) (*types.ParallelDownloadPlan, error) //perm:default
+	// GetTransferProtocolStats retrieves the succeeded transfers of the node by protocol for diagnostics
+	GetTransferProtocolStats(ctx context.Context, nodeID string) ([]*types.TransferProtocolStats, error) //perm:web,admin
+	// GetIPFamilyStats retrieves the online nodes and the routed retrievals by address family
+	GetIPFamilyStats(ctx context.Context) ([]*types.IPFamilyStats, error) //perm:web,admin
 	// NodeExists checks if the node with the specified ID exists.
 	NodeExists(ctx context.Context, nodeID string) error //perm:web
 	// NodeKeepalive
 	NodeKeepalive(ctx context.Context) (uuid.UUID, error) //perm:edge,candidate
 	// NodeKeepaliveV2 fix the problem of NodeKeepalive, Maintaining old device connections
 	NodeKeepaliveV2(ctx context.Context) (uuid.UUID, error) //perm:edge,candidate
+	// NodeKeepaliveV3 keepalive with the host metrics of the node
+	NodeKeepaliveV3(ctx context.Context, metrics *types.HostMetrics) (uuid.UUID, error) //perm:edge,candidate
+	// PullControlCalls waits for the control calls of the scheduler to the node, the calls are returned in batches over
+	// the connection of the node to the scheduler, so the scheduler does not dial back the node
+	PullControlCalls(ctx context.Context) ([]*types.ControlCall, error) //perm:edge,candidate
+	// SubmitControlResults returns the results of the control calls executed by the node
+	SubmitControlResults(ctx context.Context, results []*types.ControlResult) error //perm:edge,candidate
+	// PushNodeLogs pushes the logs of a log stream of the node, an error is returned if the stream is closed
+	PushNodeLogs(ctx context.Context, batch *types.LogBatch) error //perm:edge,candidate
+	// SetIdleMode requests the idle mode for the edge without traffic, or leaves it, the idle edge sends keepalive
+	// at the granted interval and is not marked offline for it
+	SetIdleMode(ctx context.Context, idle bool) (*types.IdleMode, error) //perm:edge
 	// RequestActivationCodes Get the device's encrypted activation code
 	RequestActivationCodes(ctx context.Context, nodeType types.NodeType, count int) ([]*types.NodeActivation, error) //perm:web,admin
 	// VerifyTokenWithLimitCount verify token in limit count
 	VerifyTokenWithLimitCount(ctx context.Context, token string) (*types.JWTPayload, error) //perm:edge,candidate
-	// UpdateBandwidths update node bandwidthDown and bandwidthUp
-	UpdateBandwidths(ctx context.Context, bandwidthDown, bandwidthUp int64) error //perm:edge,candidate
+	// UpdateBandwidths update node bandwidthDown and bandwidthUp, the report is signed by the node
+	UpdateBandwidths(ctx context.Context, report *types.BandwidthReport) error //perm:edge,candidate
+	// GetReportRejections retrieves the reports of the node rejected for missing or mismatched signatures by kind
+	GetReportRejections(ctx context.Context, nodeID string) ([]*types.ReportRejection, error) //perm:web,admin
+	// GetNodeTasks retrieves the tasks queued in the inbox of the node, which are delivered when the node is online
+	GetNodeTasks(ctx context.Context, nodeID string) ([]*types.NodeTask, error) //perm:web,admin
+	// GetNodeOperations retrieves the operations in flight assigned to the node, the pending pulls, the active validations,
+	// the queued tasks, the relay sessions and the control calls
+	GetNodeOperations(ctx context.Context, nodeID string) (*types.NodeOperations, error) //perm:web,admin
+	// GetNodeDiskHealth retrieves the disk health last reported by the online node and why its disk is predicted to fail
+	GetNodeDiskHealth(ctx context.Context, nodeID string) (*types.NodeDiskHealth, error) //perm:web,admin
 	// GetCandidateNodeIP get candidate ip for locator
 	GetCandidateNodeIP(ctx context.Context, nodeID string) (string, error) //perm:web,admin
 	// GetMinioConfigFromCandidate get minio config from candidate
@@ -144,6 +452,18 @@ type NodeAPI interface {
 	GetAssetsInBucket(ctx context.Context, nodeID string, bucketID int, isFromNode bool) ([]string, error) //perm:admin
 	// GetNodeOfIP get nodes
 	GetNodeOfIP(ctx context.Context, ip string) ([]string, error) //perm:admin,web,locator
+	// GetPointsLeaderboard get the nodes with the most points
+	GetPointsLeaderboard(ctx context.Context, limit int) ([]*types.NodePointsRank, error) //perm:web,admin
+	// GetLeaderboard get a page of the nodes ranked by points, traffic served or uptime in the last 24h, 7d or 30d
+	GetLeaderboard(ctx context.Context, req *types.LeaderboardReq) (*types.LeaderboardRsp, error) //perm:web,admin
+	// EstimatePoints estimates the points an edge node with the hypothetical bandwidth, nat and disk usage would earn
+	// by the live scoring formula, without registering the node
+	EstimatePoints(ctx context.Context, req *types.PointsEstimateReq) (*types.PointsEstimateRsp, error) //perm:web,admin
+	// SubscribeNodeEvents subscribes the online and offline events of nodes, the channel is closed when ctx is done
+	SubscribeNodeEvents(ctx context.Context) (<-chan *types.NodeEvent, error) //perm:web,admin
+	// SubscribeNodeTop streams the snapshots of the online nodes sorted by the request with the node events between them,
+	// the channel is closed when ctx is done
+	SubscribeNodeTop(ctx context.Context, req *types.NodeTopReq) (<-chan *types.NodeTopSnapshot, error) //perm:web,admin
 }
 
 // UserAPI is an interface for user
@@ -193,23 +513,156 @@ type UserAPI interface {
 	GetAPPKeyPermissions(ctx context.Context, userID, keyName string) ([]string, error) //perm:user,web,admin
 }
 
+// TokenAPI is an interface for api tokens and their roles
+type TokenAPI interface {
+	// CreateAPIToken creates an api token with the role, the role is one of admin, operator, readonly and web
+	CreateAPIToken(ctx context.Context, name string, role auth.Permission) (string, error) //perm:admin
+	// AssignAPITokenRole assigns the role to the api token, it takes effect on the next request of the token
+	AssignAPITokenRole(ctx context.Context, id string, role auth.Permission) error //perm:admin
+	// GetAPITokens get the api tokens issued by the scheduler
+	GetAPITokens(ctx context.Context) ([]*types.APIToken, error) //perm:admin
+	// RevokeTokens revokes all tokens of the subject (node id, user id or api token id) issued before now,
+	// a revoked node logs in again to get a new token, a revoked api token is deleted
+	RevokeTokens(ctx context.Context, subject string) error //perm:admin
+	// RotateAPIToken revokes the api token and issues a new one with the same id and role
+	RotateAPIToken(ctx context.Context, id string) (string, error) //perm:admin
+	// RotateUserTokens revokes the tokens of the user, regenerates its api keys and returns a new access token
+	RotateUserTokens(ctx context.Context, userID string) (string, error) //perm:web,admin
+	// GetActiveTokens get the subjects that have tokens issued after their revocation, an empty role gets all roles
+	GetActiveTokens(ctx context.Context, role auth.Permission, limit, offset int) (*types.ListTokenSubjectRsp, error) //perm:admin
+}
+
+// AccountAPI is an interface for node operator account
+type AccountAPI interface {
+	// BindNodeToAccount binds the node to the operator account
+	BindNodeToAccount(ctx context.Context, accountID, nodeID string) error //perm:web,admin
+	// UnbindNodeFromAccount unbinds the node from the operator account
+	UnbindNodeFromAccount(ctx context.Context, accountID, nodeID string) error //perm:web,admin
+	// GetAccountNodes get the nodes bound to the account
+	GetAccountNodes(ctx context.Context, accountID string) ([]*types.AccountNode, error) //perm:web,admin
+	// GetAccountStats get the aggregated points, online time, traffic and validation results of the account
+	GetAccountStats(ctx context.Context, accountID string) (*types.AccountStats, error) //perm:web,admin
+	// SetAccountNotificationPrefs set the notification preferences of the account
+	SetAccountNotificationPrefs(ctx context.Context, prefs *types.AccountNotificationPrefs) error //perm:web,admin
+	// GetAccountNotificationPrefs get the notification preferences of the account
+	GetAccountNotificationPrefs(ctx context.Context, accountID string) (*types.AccountNotificationPrefs, error) //perm:web,admin
+	// AddAlertSubscription subscribes the account to node offline, validation failure or disk usage alerts
+	AddAlertSubscription(ctx context.Context, sub *types.AlertSubscription) (int64, error) //perm:web,admin
+	// RemoveAlertSubscription removes the alert subscription of the account
+	RemoveAlertSubscription(ctx context.Context, accountID string, id int64) error //perm:web,admin
+	// GetAlertSubscriptions get the alert subscriptions of the account
+	GetAlertSubscriptions(ctx context.Context, accountID string) ([]*types.AlertSubscription, error) //perm:web,admin
+}
+
+// S3API is an interface for the s3 gateway of candidates, the objects of the gateway are user assets
+type S3API interface {
+	// CreateS3AccessKey creates an access key of the user for the s3 gateway
+	CreateS3AccessKey(ctx context.Context, userID string) (*types.S3AccessKey, error) //perm:user,web,admin
+	// GetS3AccessKeys get the access keys of the user, the secret keys are not returned
+	GetS3AccessKeys(ctx context.Context, userID string) ([]*types.S3AccessKey, error) //perm:user,web,admin
+	// DeleteS3AccessKey deletes the access key of the user
+	DeleteS3AccessKey(ctx context.Context, userID, accessKey string) error //perm:user,web,admin
+	// S3Authorize verifies the signature of the s3 request and returns the grant of the user of the access key
+	S3Authorize(ctx context.Context, req *types.S3AuthReq) (*types.S3Grant, error) //perm:candidate
+	// S3PutObject records the object stored by the candidate for the user of the grant, the asset of the object is replicated from the candidate
+	S3PutObject(ctx context.Context, grant *types.S3Grant, object *types.S3Object) error //perm:candidate
+	// S3GetObject get the object of the bucket
+	S3GetObject(ctx context.Context, userID, bucket, key string) (*types.S3Object, error) //perm:candidate
+	// S3ListObjects lists the objects of the bucket in key order
+	S3ListObjects(ctx context.Context, req *types.ListS3ObjectsReq) (*types.ListS3ObjectsRsp, error) //perm:candidate
+	// S3ListBuckets lists the buckets of the user
+	S3ListBuckets(ctx context.Context, userID string) ([]string, error) //perm:candidate
+}
+
+// TenantAPI is an interface for the tenant namespaces of the users
+type TenantAPI interface {
+	// SetTenant creates or updates the tenant with its quotas, rate limit and geo rules
+	SetTenant(ctx context.Context, tenant *types.Tenant) error //perm:admin
+	// RemoveTenant removes the tenant, the users of the tenant are removed first
+	RemoveTenant(ctx context.Context, tenantID string) error //perm:admin
+	// ListTenants get the tenants
+	ListTenants(ctx context.Context) ([]*types.Tenant, error) //perm:web,admin
+	// AddTenantUser adds the user to the tenant, a user is in one tenant at most
+	AddTenantUser(ctx context.Context, tenantID, userID string) error //perm:web,admin
+	// RemoveTenantUser removes the user from the tenant
+	RemoveTenantUser(ctx context.Context, tenantID, userID string) error //perm:admin
+	// ListTenantUsers get the users of the tenant
+	ListTenantUsers(ctx context.Context, tenantID string, limit, offset int) (*types.ListTenantUserRsp, error) //perm:web,admin
+	// GetTenantUsage get the users, the assets, the storage and the usage of the month of the tenant
+	GetTenantUsage(ctx context.Context, tenantID string) (*types.TenantUsage, error) //perm:web,admin
+	// GetTenantUsageReport get the daily usage of the tenant in the days from start to end
+	GetTenantUsageReport(ctx context.Context, tenantID string, start, end time.Time) (*types.TenantUsageReport, error) //perm:web,admin
+	// GetTenantQuota get the stored bytes and the egress of the month of the tenant against its quotas
+	GetTenantQuota(ctx context.Context, tenantID string) (*types.TenantQuotaStatus, error) //perm:web,admin
+	// CreateTenantToken creates a token scoped to the tenant, the token manages the users and the assets of the tenant only
+	CreateTenantToken(ctx context.Context, tenantID string) (string, error) //perm:admin
+	// CreateReplicationAgreement creates the agreement mirroring all the assets of the tenant into the schedulers of the peer area,
+	// returns the id of the agreement
+	CreateReplicationAgreement(ctx context.Context, agreement *types.ReplicationAgreement) (string, error) //perm:admin
+	// SetReplicationAgreementState pauses or resumes the agreement, a resumed agreement continues from the last asset streamed
+	SetReplicationAgreementState(ctx context.Context, id string, state types.ReplicationAgreementState) error //perm:admin
+	// RemoveReplicationAgreement removes the agreement, the assets mirrored already are kept in the peer area
+	RemoveReplicationAgreement(ctx context.Context, id string) error //perm:admin
+	// ListReplicationAgreements get the agreements of the tenant with their progress, all the agreements if tenantID is empty
+	ListReplicationAgreements(ctx context.Context, tenantID string) ([]*types.ReplicationAgreement, error) //perm:web,admin
+	// ReceiveMirrorManifests creates the assets of the manifests streamed by a scheduler of another area, the candidates pull
+	// the assets from the nodes of the area. Returns the number of the manifests accepted in their order
+	ReceiveMirrorManifests(ctx context.Context, batch *types.MirrorManifestBatch) (int, error) //perm:web
+}
+
+// WebhookAPI is an interface for the webhooks called on the lifecycle events of the assets
+type WebhookAPI interface {
+	// CreateAssetWebhook registers the url called on the events of the asset of the user, all events if events is empty.
+	// The returned secret signs the callbacks and is not returned again
+	CreateAssetWebhook(ctx context.Context, userID, assetCID, url string, events []types.WebhookEvent) (*types.Webhook, error) //perm:user,web,admin
+	// CreateTenantWebhook registers the url called on the events of the assets of the users of the tenant, all events if events is empty.
+	// The returned secret signs the callbacks and is not returned again
+	CreateTenantWebhook(ctx context.Context, tenantID, url string, events []types.WebhookEvent) (*types.Webhook, error) //perm:web,admin
+	// ListWebhooks get the asset webhooks of the user, or the webhooks of the tenant if tenantID is not empty
+	ListWebhooks(ctx context.Context, userID, tenantID string) ([]*types.Webhook, error) //perm:user,web,admin
+	// DeleteWebhook removes the webhook and its deliveries
+	DeleteWebhook(ctx context.Context, userID string, webhookID int64) error //perm:user,web,admin
+	// ListWebhookDeliveries get the callbacks of the webhook with their delivery status, the latest first
+	ListWebhookDeliveries(ctx context.Context, userID string, webhookID int64, limit, offset int) (*types.ListWebhookDeliveryRsp, error) //perm:user,web,admin
+}
+
 // Scheduler is an interface for scheduler
 type Scheduler interface {
 	Common
 	AssetAPI
 	NodeAPI
 	UserAPI
+	AccountAPI
+	TokenAPI
+	S3API
+	TenantAPI
+	WebhookAPI
 
 	// NodeValidationResult processes the validation result for a node
 	NodeValidationResult(ctx context.Context, r io.Reader, sign string) error //perm:candidate
 	// GetValidationResults retrieves a list of validation results with pagination using the specified node, page number, and page size
 	GetValidationResults(ctx context.Context, nodeID string, limit, offset int) (*types.ListValidationResultRsp, error) //perm:web,admin
+	// GetValidationProofs retrieves the validation results of the node signed by the validators and the scheduler, the latest first
+	GetValidationProofs(ctx context.Context, nodeID string, limit, offset int) (*types.ListValidationProofRsp, error) //perm:web,admin
+	// VerifyValidationProofs checks the hashes, the links and the signatures of the chain of the validation proofs of the node
+	VerifyValidationProofs(ctx context.Context, nodeID string) (*types.ValidationProofAudit, error) //perm:web,admin
 	// SubmitUserWorkloadReport submits report of workload for User Download asset
 	// r is buffer of []*types.WorkloadReport encode by gob
 	SubmitUserWorkloadReport(ctx context.Context, r io.Reader) error //perm:default
 	// SubmitNodeWorkloadReport submits report of workload for node provide Asset Download
 	// r is buffer of types.NodeWorkloadReport encode by gob
 	SubmitNodeWorkloadReport(ctx context.Context, r io.Reader) error //perm:edge,candidate
+	// SubmitWorkloadReceipts submits the receipts of the bytes the node downloaded from the other nodes, signed by the node,
+	// a sample of the receipts is cross-checked against the workloads reported by the serving nodes
+	SubmitWorkloadReceipts(ctx context.Context, receipts []*types.WorkloadReceipt) error //perm:edge,candidate
+	// SubmitClientReceipts submits the receipts of the bytes a download client received from the nodes, signed by the key of the client,
+	// the receipts are kept as independent evidence of the workloads of the nodes
+	SubmitClientReceipts(ctx context.Context, receipts []*types.WorkloadReceipt) error //perm:default
+	// ListWorkloadReceipts retrieves the sampled workload receipts of the bytes served by the node, the latest first
+	ListWorkloadReceipts(ctx context.Context, nodeID string, limit, offset int) (*types.ListWorkloadReceiptRsp, error) //perm:web,admin
+	// GetWorkloadVerificationStats retrieves the workload receipts sampled in [start, end) of the node by their verification result,
+	// of all the nodes if nodeID is empty
+	GetWorkloadVerificationStats(ctx context.Context, nodeID string, start, end time.Time) (*types.WorkloadVerificationStats, error) //perm:web,admin
 	// GetWorkloadRecords retrieves a list of workload results with pagination using the specified limit, offset, and node
 	GetWorkloadRecords(ctx context.Context, nodeID string, limit, offset int) (*types.ListWorkloadRecordRsp, error) //perm:web,admin
 	// GetWorkloadRecord retrieves result with tokenID
@@ -220,6 +673,8 @@ type Scheduler interface {
 	// Server-related methods
 	// GetSchedulerPublicKey retrieves the scheduler's public key in PEM format
 	GetSchedulerPublicKey(ctx context.Context) (string, error) //perm:edge,candidate
+	// RotateSchedulerKey replaces the scheduler private key, the retired keys are kept to decrypt the data encrypted with them
+	RotateSchedulerKey(ctx context.Context) error //perm:admin
 	// GetNodePublicKey retrieves the node's public key in PEM format
 	GetNodePublicKey(ctx context.Context, nodeID string) (string, error) //perm:web,admin
 	// TriggerElection starts a new election process
@@ -232,6 +687,23 @@ type Scheduler interface {
 	DeleteEdgeUpdateConfig(ctx context.Context, nodeType int) error //perm:admin
 	// GetValidationInfo get information related to validation and election
 	GetValidationInfo(ctx context.Context) (*types.ValidationInfo, error) //perm:web,admin
+	// GetValidatorLoads retrieves the validations of the validators connected to the scheduler
+	GetValidatorLoads(ctx context.Context) ([]*types.ValidatorLoad, error) //perm:web,admin
+	// GetHardwareProof get the latest hardware challenge proof of the node
+	GetHardwareProof(ctx context.Context, nodeID string) (*types.HardwareProof, error) //perm:web,admin
+	// GetAuditLogs get the mutations made by admin, operator and web callers, the latest first
+	GetAuditLogs(ctx context.Context, req *types.ListAuditLogsReq) (*types.ListAuditLogRsp, error) //perm:admin
 	// ElectValidators
 	ElectValidators(ctx context.Context, nodeIDs []string) error //perm:admin
+	// GetRuntimeDiagnostics get the runtime state of the scheduler with the sizes of the in-memory registries and the status of the timers
+	GetRuntimeDiagnostics(ctx context.Context) (*types.RuntimeDiagnostics, error) //perm:admin
+	// GetGoroutineDump get the stacks of all the goroutines of the scheduler
+	GetGoroutineDump(ctx context.Context) (string, error) //perm:admin
+	// GetProfile get the pprof profile of the name (cpu, heap, allocs, goroutine, mutex, block, threadcreate) read by go tool pprof,
+	// the cpu profile is collected for the seconds
+	GetProfile(ctx context.Context, name string, seconds int) ([]byte, error) //perm:admin
+	// ListSchedulingDecisions list the decisions choosing nodes to pull, validate and retrieve assets without their steps, the latest first
+	ListSchedulingDecisions(ctx context.Context, req *types.ListSchedulingDecisionsReq) (*types.ListSchedulingDecisionRsp, error) //perm:admin
+	// ReplaySchedulingDecision get the decision with the nodes in the order they were considered and why they were chosen or filtered out
+	ReplaySchedulingDecision(ctx context.Context, id int64) (*types.SchedulingDecision, error) //perm:admin
 }
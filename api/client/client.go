@@ -8,11 +8,13 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-jsonrpc"
 
@@ -154,8 +156,66 @@ func NewHTTP3Client() *http.Client {
 	}
 }
 
+// NewHTTPClient new http client over tcp, used with the nodes not serving http3
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+}
+
+// NewProxyHTTPClient new http client over tcp through the outbound proxy of the url, e.g. socks5://127.0.0.1:1080
+// or http://127.0.0.1:8080, used by the nodes in the networks that only reach the internet through a proxy.
+// http3 is not carried by the proxies, the servers are reached over tcp
+func NewProxyHTTPClient(proxyURL string) (*http.Client, error) {
+	return NewProxyHTTPClientWithTLS(proxyURL, &tls.Config{InsecureSkipVerify: true})
+}
+
+// NewProxyHTTPClientWithTLS new http client through the outbound proxy with the tls config, e.g. the client certificate of mutual tls
+func NewProxyHTTPClientWithTLS(proxyURL string, tlsConfig *tls.Config) (*http.Client, error) {
+	u, err := ParseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(u),
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// ParseProxyURL parses the url of the outbound proxy, the schemes supported are socks5, http and https
+func ParseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse proxy url %s: %w", proxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "socks5", "http", "https":
+	default:
+		return nil, xerrors.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, xerrors.Errorf("proxy url %s has no host", proxyURL)
+	}
+
+	return u, nil
+}
+
 // NewHTTP3ClientWithPacketConn new http3 client for nat trave
 func NewHTTP3ClientWithPacketConn(tansport *quic.Transport) (*http.Client, error) {
+	return NewHTTP3ClientWithTLS(tansport, &tls.Config{InsecureSkipVerify: true}), nil
+}
+
+// NewHTTP3ClientWithTLS new http3 client for nat trave with the tls config, e.g. the client certificate of mutual tls
+func NewHTTP3ClientWithTLS(tansport *quic.Transport, tlsConfig *tls.Config) *http.Client {
 	dial := func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
 		remoteAddr, err := net.ResolveUDPAddr("udp", addr)
 		if err != nil {
@@ -166,12 +226,46 @@ func NewHTTP3ClientWithPacketConn(tansport *quic.Transport) (*http.Client, error
 	}
 
 	roundTripper := &http3.RoundTripper{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
-		QuicConfig: &quic.Config{},
-		Dial:       dial,
+		TLSClientConfig: tlsConfig,
+		QuicConfig:      &quic.Config{},
+		Dial:            dial,
 	}
 
-	return &http.Client{Transport: roundTripper, Timeout: 30 * time.Second}, nil
+	return &http.Client{Transport: roundTripper, Timeout: 30 * time.Second}
+}
+
+// TokenTransport sets the bearer token of every request, the token can be replaced while the client is in use
+type TokenTransport struct {
+	base  http.RoundTripper
+	token atomic.Value
+}
+
+// NewTokenTransport wraps the transport of the http client with the token
+func NewTokenTransport(httpClient *http.Client, token string) *TokenTransport {
+	t := &TokenTransport{base: httpClient.Transport}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	t.token.Store(token)
+
+	httpClient.Transport = t
+	return t
+}
+
+// SetToken replaces the token of the following requests
+func (t *TokenTransport) SetToken(token string) {
+	t.token.Store(token)
+}
+
+// Token returns the current token
+func (t *TokenTransport) Token() string {
+	return t.token.Load().(string)
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.Token())
+
+	return t.base.RoundTrip(req)
 }
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"golang.org/x/xerrors"
+)
+
+// maxReceiptBatch the receipts submitted to the scheduler at once
+const maxReceiptBatch = 1000
+
+// ReceiptSigner signs the receipts of the bytes a download client received from the nodes with the key of the client,
+// and submits them to the scheduler as evidence of the workloads of the nodes
+type ReceiptSigner struct {
+	key       crypto.Signer
+	clientID  string
+	clientKey string
+
+	lk      sync.Mutex
+	pending []*types.WorkloadReceipt
+}
+
+// NewReceiptSigner creates a receipt signer with the key of the client, a new ed25519 key is generated if key is nil.
+// The client should keep its key, the scheduler identifies the client by the id derived from it
+func NewReceiptSigner(key crypto.Signer) (*ReceiptSigner, error) {
+	if key == nil {
+		var err error
+		if key, err = nodekey.Generate(nodekey.TypeEd25519, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	clientID, err := nodekey.NodeID(types.ClientIDPrefix, key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	pem, err := nodekey.PublicKey2Pem(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReceiptSigner{key: key, clientID: clientID, clientKey: string(pem)}, nil
+}
+
+// ClientID returns the id of the client derived from its key, the tokens signed for must be issued for it
+// by GetClientDownloadInfos
+func (r *ReceiptSigner) ClientID() string {
+	return r.clientID
+}
+
+// Sign signs the receipt of the bytes received from the node with the token, the receipt is also queued for Submit
+func (r *ReceiptSigner) Sign(tk *types.Token, nodeID string, size int64, start, end time.Time) (*types.WorkloadReceipt, error) {
+	if tk == nil {
+		return nil, xerrors.New("token can not be empty")
+	}
+
+	receipt := &types.WorkloadReceipt{
+		TokenID:      tk.ID,
+		NodeID:       nodeID,
+		ClientID:     r.clientID,
+		ClientKey:    r.clientKey,
+		DownloadSize: size,
+		StartTime:    start,
+		EndTime:      end,
+	}
+
+	sign, err := nodekey.Sign(r.key, receipt.SignContent())
+	if err != nil {
+		return nil, err
+	}
+	receipt.Sign = sign
+
+	r.lk.Lock()
+	r.pending = append(r.pending, receipt)
+	r.lk.Unlock()
+
+	return receipt, nil
+}
+
+// Submit submits the queued receipts to the scheduler, the receipts failed to submit are queued again
+func (r *ReceiptSigner) Submit(ctx context.Context, scheduler api.Scheduler) error {
+	r.lk.Lock()
+	receipts := r.pending
+	r.pending = nil
+	r.lk.Unlock()
+
+	for len(receipts) > 0 {
+		batch := receipts
+		if len(batch) > maxReceiptBatch {
+			batch = batch[:maxReceiptBatch]
+		}
+
+		if err := scheduler.SubmitClientReceipts(ctx, batch); err != nil {
+			r.lk.Lock()
+			r.pending = append(receipts, r.pending...)
+			r.lk.Unlock()
+			return xerrors.Errorf("SubmitClientReceipts err:%s", err.Error())
+		}
+
+		receipts = receipts[len(batch):]
+	}
+
+	return nil
+}
@@ -0,0 +1,596 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: api/grpc/schedulerpb/scheduler.proto
+
+package schedulerpb
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// NodeServiceClient is the client API for NodeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NodeServiceClient interface {
+	// GetNodeInfo get information for node
+	GetNodeInfo(ctx context.Context, in *GetNodeInfoRequest, opts ...grpc.CallOption) (*NodeInfo, error)
+	// ListNodes retrieves a list of nodes with pagination
+	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
+	// GetOnlineNodeCount returns the count of online nodes for a given node type
+	GetOnlineNodeCount(ctx context.Context, in *GetOnlineNodeCountRequest, opts ...grpc.CallOption) (*GetOnlineNodeCountResponse, error)
+	// WatchNodeEvents streams the online and offline events of nodes
+	WatchNodeEvents(ctx context.Context, in *WatchNodeEventsRequest, opts ...grpc.CallOption) (NodeService_WatchNodeEventsClient, error)
+}
+
+type nodeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNodeServiceClient(cc grpc.ClientConnInterface) NodeServiceClient {
+	return &nodeServiceClient{cc}
+}
+
+func (c *nodeServiceClient) GetNodeInfo(ctx context.Context, in *GetNodeInfoRequest, opts ...grpc.CallOption) (*NodeInfo, error) {
+	out := new(NodeInfo)
+	err := c.cc.Invoke(ctx, "/scheduler.v1.NodeService/GetNodeInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error) {
+	out := new(ListNodesResponse)
+	err := c.cc.Invoke(ctx, "/scheduler.v1.NodeService/ListNodes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetOnlineNodeCount(ctx context.Context, in *GetOnlineNodeCountRequest, opts ...grpc.CallOption) (*GetOnlineNodeCountResponse, error) {
+	out := new(GetOnlineNodeCountResponse)
+	err := c.cc.Invoke(ctx, "/scheduler.v1.NodeService/GetOnlineNodeCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) WatchNodeEvents(ctx context.Context, in *WatchNodeEventsRequest, opts ...grpc.CallOption) (NodeService_WatchNodeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[0], "/scheduler.v1.NodeService/WatchNodeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeServiceWatchNodeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeService_WatchNodeEventsClient interface {
+	Recv() (*NodeEvent, error)
+	grpc.ClientStream
+}
+
+type nodeServiceWatchNodeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeServiceWatchNodeEventsClient) Recv() (*NodeEvent, error) {
+	m := new(NodeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NodeServiceServer is the server API for NodeService service.
+// All implementations must embed UnimplementedNodeServiceServer
+// for forward compatibility
+type NodeServiceServer interface {
+	// GetNodeInfo get information for node
+	GetNodeInfo(context.Context, *GetNodeInfoRequest) (*NodeInfo, error)
+	// ListNodes retrieves a list of nodes with pagination
+	ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error)
+	// GetOnlineNodeCount returns the count of online nodes for a given node type
+	GetOnlineNodeCount(context.Context, *GetOnlineNodeCountRequest) (*GetOnlineNodeCountResponse, error)
+	// WatchNodeEvents streams the online and offline events of nodes
+	WatchNodeEvents(*WatchNodeEventsRequest, NodeService_WatchNodeEventsServer) error
+	mustEmbedUnimplementedNodeServiceServer()
+}
+
+// UnimplementedNodeServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedNodeServiceServer struct {
+}
+
+func (UnimplementedNodeServiceServer) GetNodeInfo(context.Context, *GetNodeInfoRequest) (*NodeInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNodeInfo not implemented")
+}
+func (UnimplementedNodeServiceServer) ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNodes not implemented")
+}
+func (UnimplementedNodeServiceServer) GetOnlineNodeCount(context.Context, *GetOnlineNodeCountRequest) (*GetOnlineNodeCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOnlineNodeCount not implemented")
+}
+func (UnimplementedNodeServiceServer) WatchNodeEvents(*WatchNodeEventsRequest, NodeService_WatchNodeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchNodeEvents not implemented")
+}
+func (UnimplementedNodeServiceServer) mustEmbedUnimplementedNodeServiceServer() {}
+
+// UnsafeNodeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NodeServiceServer will
+// result in compilation errors.
+type UnsafeNodeServiceServer interface {
+	mustEmbedUnimplementedNodeServiceServer()
+}
+
+func RegisterNodeServiceServer(s grpc.ServiceRegistrar, srv NodeServiceServer) {
+	s.RegisterService(&NodeService_ServiceDesc, srv)
+}
+
+func _NodeService_GetNodeInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetNodeInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/scheduler.v1.NodeService/GetNodeInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetNodeInfo(ctx, req.(*GetNodeInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_ListNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).ListNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/scheduler.v1.NodeService/ListNodes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).ListNodes(ctx, req.(*ListNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetOnlineNodeCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOnlineNodeCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetOnlineNodeCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/scheduler.v1.NodeService/GetOnlineNodeCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetOnlineNodeCount(ctx, req.(*GetOnlineNodeCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_WatchNodeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNodeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServiceServer).WatchNodeEvents(m, &nodeServiceWatchNodeEventsServer{stream})
+}
+
+type NodeService_WatchNodeEventsServer interface {
+	Send(*NodeEvent) error
+	grpc.ServerStream
+}
+
+type nodeServiceWatchNodeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeServiceWatchNodeEventsServer) Send(m *NodeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// NodeService_ServiceDesc is the grpc.ServiceDesc for NodeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NodeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scheduler.v1.NodeService",
+	HandlerType: (*NodeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNodeInfo",
+			Handler:    _NodeService_GetNodeInfo_Handler,
+		},
+		{
+			MethodName: "ListNodes",
+			Handler:    _NodeService_ListNodes_Handler,
+		},
+		{
+			MethodName: "GetOnlineNodeCount",
+			Handler:    _NodeService_GetOnlineNodeCount_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchNodeEvents",
+			Handler:       _NodeService_WatchNodeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/grpc/schedulerpb/scheduler.proto",
+}
+
+// AssetServiceClient is the client API for AssetService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AssetServiceClient interface {
+	// GetAssetRecord retrieves the asset record with the specified CID
+	GetAssetRecord(ctx context.Context, in *GetAssetRecordRequest, opts ...grpc.CallOption) (*AssetRecord, error)
+	// ListAssetRecords retrieves a list of asset records with pagination
+	ListAssetRecords(ctx context.Context, in *ListAssetRecordsRequest, opts ...grpc.CallOption) (*ListAssetRecordsResponse, error)
+	// WatchAssetProgress streams the pull progress of the asset until it finishes
+	WatchAssetProgress(ctx context.Context, in *GetAssetRecordRequest, opts ...grpc.CallOption) (AssetService_WatchAssetProgressClient, error)
+}
+
+type assetServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAssetServiceClient(cc grpc.ClientConnInterface) AssetServiceClient {
+	return &assetServiceClient{cc}
+}
+
+func (c *assetServiceClient) GetAssetRecord(ctx context.Context, in *GetAssetRecordRequest, opts ...grpc.CallOption) (*AssetRecord, error) {
+	out := new(AssetRecord)
+	err := c.cc.Invoke(ctx, "/scheduler.v1.AssetService/GetAssetRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *assetServiceClient) ListAssetRecords(ctx context.Context, in *ListAssetRecordsRequest, opts ...grpc.CallOption) (*ListAssetRecordsResponse, error) {
+	out := new(ListAssetRecordsResponse)
+	err := c.cc.Invoke(ctx, "/scheduler.v1.AssetService/ListAssetRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *assetServiceClient) WatchAssetProgress(ctx context.Context, in *GetAssetRecordRequest, opts ...grpc.CallOption) (AssetService_WatchAssetProgressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AssetService_ServiceDesc.Streams[0], "/scheduler.v1.AssetService/WatchAssetProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &assetServiceWatchAssetProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AssetService_WatchAssetProgressClient interface {
+	Recv() (*AssetRecord, error)
+	grpc.ClientStream
+}
+
+type assetServiceWatchAssetProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *assetServiceWatchAssetProgressClient) Recv() (*AssetRecord, error) {
+	m := new(AssetRecord)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AssetServiceServer is the server API for AssetService service.
+// All implementations must embed UnimplementedAssetServiceServer
+// for forward compatibility
+type AssetServiceServer interface {
+	// GetAssetRecord retrieves the asset record with the specified CID
+	GetAssetRecord(context.Context, *GetAssetRecordRequest) (*AssetRecord, error)
+	// ListAssetRecords retrieves a list of asset records with pagination
+	ListAssetRecords(context.Context, *ListAssetRecordsRequest) (*ListAssetRecordsResponse, error)
+	// WatchAssetProgress streams the pull progress of the asset until it finishes
+	WatchAssetProgress(*GetAssetRecordRequest, AssetService_WatchAssetProgressServer) error
+	mustEmbedUnimplementedAssetServiceServer()
+}
+
+// UnimplementedAssetServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAssetServiceServer struct {
+}
+
+func (UnimplementedAssetServiceServer) GetAssetRecord(context.Context, *GetAssetRecordRequest) (*AssetRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAssetRecord not implemented")
+}
+func (UnimplementedAssetServiceServer) ListAssetRecords(context.Context, *ListAssetRecordsRequest) (*ListAssetRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAssetRecords not implemented")
+}
+func (UnimplementedAssetServiceServer) WatchAssetProgress(*GetAssetRecordRequest, AssetService_WatchAssetProgressServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAssetProgress not implemented")
+}
+func (UnimplementedAssetServiceServer) mustEmbedUnimplementedAssetServiceServer() {}
+
+// UnsafeAssetServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AssetServiceServer will
+// result in compilation errors.
+type UnsafeAssetServiceServer interface {
+	mustEmbedUnimplementedAssetServiceServer()
+}
+
+func RegisterAssetServiceServer(s grpc.ServiceRegistrar, srv AssetServiceServer) {
+	s.RegisterService(&AssetService_ServiceDesc, srv)
+}
+
+func _AssetService_GetAssetRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAssetRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AssetServiceServer).GetAssetRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/scheduler.v1.AssetService/GetAssetRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AssetServiceServer).GetAssetRecord(ctx, req.(*GetAssetRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AssetService_ListAssetRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAssetRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AssetServiceServer).ListAssetRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/scheduler.v1.AssetService/ListAssetRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AssetServiceServer).ListAssetRecords(ctx, req.(*ListAssetRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AssetService_WatchAssetProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAssetRecordRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AssetServiceServer).WatchAssetProgress(m, &assetServiceWatchAssetProgressServer{stream})
+}
+
+type AssetService_WatchAssetProgressServer interface {
+	Send(*AssetRecord) error
+	grpc.ServerStream
+}
+
+type assetServiceWatchAssetProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *assetServiceWatchAssetProgressServer) Send(m *AssetRecord) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AssetService_ServiceDesc is the grpc.ServiceDesc for AssetService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AssetService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scheduler.v1.AssetService",
+	HandlerType: (*AssetServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAssetRecord",
+			Handler:    _AssetService_GetAssetRecord_Handler,
+		},
+		{
+			MethodName: "ListAssetRecords",
+			Handler:    _AssetService_ListAssetRecords_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAssetProgress",
+			Handler:       _AssetService_WatchAssetProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/grpc/schedulerpb/scheduler.proto",
+}
+
+// ValidationServiceClient is the client API for ValidationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ValidationServiceClient interface {
+	// GetValidationResults retrieves a list of validation results of the node with pagination
+	GetValidationResults(ctx context.Context, in *GetValidationResultsRequest, opts ...grpc.CallOption) (*GetValidationResultsResponse, error)
+	// WatchValidationResults streams the new validation results of the node
+	WatchValidationResults(ctx context.Context, in *WatchValidationResultsRequest, opts ...grpc.CallOption) (ValidationService_WatchValidationResultsClient, error)
+}
+
+type validationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewValidationServiceClient(cc grpc.ClientConnInterface) ValidationServiceClient {
+	return &validationServiceClient{cc}
+}
+
+func (c *validationServiceClient) GetValidationResults(ctx context.Context, in *GetValidationResultsRequest, opts ...grpc.CallOption) (*GetValidationResultsResponse, error) {
+	out := new(GetValidationResultsResponse)
+	err := c.cc.Invoke(ctx, "/scheduler.v1.ValidationService/GetValidationResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validationServiceClient) WatchValidationResults(ctx context.Context, in *WatchValidationResultsRequest, opts ...grpc.CallOption) (ValidationService_WatchValidationResultsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ValidationService_ServiceDesc.Streams[0], "/scheduler.v1.ValidationService/WatchValidationResults", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &validationServiceWatchValidationResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ValidationService_WatchValidationResultsClient interface {
+	Recv() (*ValidationResult, error)
+	grpc.ClientStream
+}
+
+type validationServiceWatchValidationResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *validationServiceWatchValidationResultsClient) Recv() (*ValidationResult, error) {
+	m := new(ValidationResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ValidationServiceServer is the server API for ValidationService service.
+// All implementations must embed UnimplementedValidationServiceServer
+// for forward compatibility
+type ValidationServiceServer interface {
+	// GetValidationResults retrieves a list of validation results of the node with pagination
+	GetValidationResults(context.Context, *GetValidationResultsRequest) (*GetValidationResultsResponse, error)
+	// WatchValidationResults streams the new validation results of the node
+	WatchValidationResults(*WatchValidationResultsRequest, ValidationService_WatchValidationResultsServer) error
+	mustEmbedUnimplementedValidationServiceServer()
+}
+
+// UnimplementedValidationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedValidationServiceServer struct {
+}
+
+func (UnimplementedValidationServiceServer) GetValidationResults(context.Context, *GetValidationResultsRequest) (*GetValidationResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValidationResults not implemented")
+}
+func (UnimplementedValidationServiceServer) WatchValidationResults(*WatchValidationResultsRequest, ValidationService_WatchValidationResultsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchValidationResults not implemented")
+}
+func (UnimplementedValidationServiceServer) mustEmbedUnimplementedValidationServiceServer() {}
+
+// UnsafeValidationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ValidationServiceServer will
+// result in compilation errors.
+type UnsafeValidationServiceServer interface {
+	mustEmbedUnimplementedValidationServiceServer()
+}
+
+func RegisterValidationServiceServer(s grpc.ServiceRegistrar, srv ValidationServiceServer) {
+	s.RegisterService(&ValidationService_ServiceDesc, srv)
+}
+
+func _ValidationService_GetValidationResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValidationResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidationServiceServer).GetValidationResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/scheduler.v1.ValidationService/GetValidationResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidationServiceServer).GetValidationResults(ctx, req.(*GetValidationResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidationService_WatchValidationResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchValidationResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ValidationServiceServer).WatchValidationResults(m, &validationServiceWatchValidationResultsServer{stream})
+}
+
+type ValidationService_WatchValidationResultsServer interface {
+	Send(*ValidationResult) error
+	grpc.ServerStream
+}
+
+type validationServiceWatchValidationResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *validationServiceWatchValidationResultsServer) Send(m *ValidationResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ValidationService_ServiceDesc is the grpc.ServiceDesc for ValidationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ValidationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scheduler.v1.ValidationService",
+	HandlerType: (*ValidationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetValidationResults",
+			Handler:    _ValidationService_GetValidationResults_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchValidationResults",
+			Handler:       _ValidationService_WatchValidationResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/grpc/schedulerpb/scheduler.proto",
+}
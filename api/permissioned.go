@@ -14,9 +14,14 @@ const (
 	RoleAdmin     auth.Permission = "admin" // Manage permissions
 	RoleDefault   auth.Permission = "default"
 	RoleUser      auth.Permission = "user"
+	RoleOperator  auth.Permission = "operator" // Manage nodes and assets, inherits web
+	RoleReadOnly  auth.Permission = "readonly" // Query methods of web only
 )
 
-var AllPermissions = []auth.Permission{RoleWeb, RoleCandidate, RoleEdge, RoleLocator, RoleAdmin, RoleDefault, RoleUser}
+var AllPermissions = []auth.Permission{RoleWeb, RoleCandidate, RoleEdge, RoleLocator, RoleAdmin, RoleDefault, RoleUser, RoleOperator, RoleReadOnly}
+
+// TokenRoles the roles that can be assigned to api tokens, node tokens are issued on node login
+var TokenRoles = []auth.Permission{RoleAdmin, RoleOperator, RoleReadOnly, RoleWeb}
 
 func permissionedProxies(in, out interface{}) {
 	outs := GetInternalStructs(out)
@@ -10,18 +10,46 @@ import (
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/journal/alerting"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/google/uuid"
 	xerrors "golang.org/x/xerrors"
 )
 
 var ErrNotSupported = xerrors.New("method not supported")
 
+type AccountAPIStruct struct {
+	Internal struct {
+		AddAlertSubscription func(p0 context.Context, p1 *types.AlertSubscription) (int64, error) `perm:"web,admin"`
+
+		BindNodeToAccount func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin"`
+
+		GetAccountNodes func(p0 context.Context, p1 string) ([]*types.AccountNode, error) `perm:"web,admin"`
+
+		GetAccountNotificationPrefs func(p0 context.Context, p1 string) (*types.AccountNotificationPrefs, error) `perm:"web,admin"`
+
+		GetAccountStats func(p0 context.Context, p1 string) (*types.AccountStats, error) `perm:"web,admin"`
+
+		GetAlertSubscriptions func(p0 context.Context, p1 string) ([]*types.AlertSubscription, error) `perm:"web,admin"`
+
+		RemoveAlertSubscription func(p0 context.Context, p1 string, p2 int64) error `perm:"web,admin"`
+
+		SetAccountNotificationPrefs func(p0 context.Context, p1 *types.AccountNotificationPrefs) error `perm:"web,admin"`
+
+		UnbindNodeFromAccount func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin"`
+	}
+}
+
+type AccountAPIStub struct {
+}
+
 type AssetStruct struct {
 	Internal struct {
 		AddAssetView func(p0 context.Context, p1 []string) error `perm:"admin"`
 
 		CreateAsset func(p0 context.Context, p1 *types.AuthUserUploadDownloadAsset) (string, error) `perm:"admin"`
 
+		CreateIngestToken func(p0 context.Context, p1 *types.AuthUserIngestAsset) (string, error) `perm:"admin"`
+
 		DeleteAsset func(p0 context.Context, p1 string) error `perm:"admin"`
 
 		GetAssetProgresses func(p0 context.Context, p1 []string) (*types.PullResult, error) `perm:"admin"`
@@ -32,12 +60,20 @@ type AssetStruct struct {
 
 		GetAssetsInBucket func(p0 context.Context, p1 int) ([]string, error) `perm:"admin"`
 
+		GetCacheComposition func(p0 context.Context) (*types.CacheComposition, error) `perm:"admin"`
+
 		GetPullingAssetInfo func(p0 context.Context) (*types.InProgressAsset, error) `perm:"admin"`
 
+		MarkAssetsCache func(p0 context.Context, p1 []*types.AssetCacheMark) error `perm:"admin"`
+
+		ProveStorage func(p0 context.Context, p1 *types.StorageChallenge) (*types.StorageChallengeResult, error) `perm:"admin"`
+
 		PullAsset func(p0 context.Context, p1 string, p2 []*types.CandidateDownloadInfo) error `perm:"admin"`
 
 		PullAssetFromAWS func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
 
+		SetCacheConfig func(p0 context.Context, p1 *types.CacheConfig) error `perm:"admin"`
+
 		SyncAssetViewAndData func(p0 context.Context) error `perm:"admin"`
 	}
 }
@@ -49,12 +85,28 @@ type AssetAPIStruct struct {
 	Internal struct {
 		AddAWSData func(p0 context.Context, p1 []types.AWSDataInfo) error `perm:"web,admin"`
 
+		AddGatewayAsset func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		AddToDenylist func(p0 context.Context, p1 []string, p2 string) error `perm:"admin"`
+
+		CheckAssetAccess func(p0 context.Context, p1 string, p2 string, p3 string) (bool, error) `perm:"edge,candidate"`
+
 		CreateAsset func(p0 context.Context, p1 *types.CreateAssetReq) (*types.CreateAssetRsp, error) `perm:"web,admin,user"`
 
+		CreateIngestTask func(p0 context.Context, p1 *types.IngestAssetReq) (*types.CreateAssetRsp, error) `perm:"web,admin,user"`
+
+		CreateSignedURL func(p0 context.Context, p1 string, p2 string, p3 time.Time) (*types.SignedURL, error) `perm:"web,admin,user"`
+
 		DeleteAsset func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin,user"`
 
+		GetAssetACL func(p0 context.Context, p1 string, p2 string) (*types.AssetACL, error) `perm:"web,admin,user"`
+
 		GetAssetCount func(p0 context.Context) (int, error) `perm:"web,admin"`
 
+		GetAssetEncryption func(p0 context.Context, p1 string, p2 string) (*types.AssetEncryption, error) `perm:"web,admin,user"`
+
+		GetAssetGeoPolicy func(p0 context.Context, p1 string) (*types.AssetGeoPolicy, error) `perm:"web,admin"`
+
 		GetAssetListForBucket func(p0 context.Context, p1 uint32) ([]string, error) `perm:"edge,candidate"`
 
 		GetAssetRecord func(p0 context.Context, p1 string) (*types.AssetRecord, error) `perm:"web,admin"`
@@ -63,34 +115,88 @@ type AssetAPIStruct struct {
 
 		GetAssetStatus func(p0 context.Context, p1 string, p2 string) (*types.AssetStatus, error) `perm:"web,admin"`
 
+		GetAssetVideoFormat func(p0 context.Context, p1 string) (types.AssetVideoFormat, error) `perm:"edge,candidate,web,admin"`
+
 		GetAssetsForNode func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeAssetRsp, error) `perm:"web,admin"`
 
+		GetDownloadSources func(p0 context.Context, p1 string, p2 string) (*types.DownloadSources, error) `perm:"web,admin,user"`
+
+		GetGatewayCertificate func(p0 context.Context) (*types.GatewayCertificate, error) `perm:"candidate"`
+
+		GetNodeCacheComposition func(p0 context.Context, p1 string) (*types.CacheComposition, error) `perm:"web,admin"`
+
 		GetReplicaEvents func(p0 context.Context, p1 time.Time, p2 time.Time, p3 int, p4 int) (*types.ListReplicaEventRsp, error) `perm:"web,admin"`
 
 		GetReplicaEventsForNode func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListReplicaEventRsp, error) `perm:"web,admin"`
 
+		GetReplicaProgress func(p0 context.Context, p1 string) (*types.ReplicaProgress, error) `perm:"web,admin"`
+
 		GetReplicas func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListReplicaRsp, error) `perm:"web,admin"`
 
 		GetReplicasForNode func(p0 context.Context, p1 string, p2 int, p3 int, p4 []types.ReplicaStatus) (*types.ListNodeReplicaRsp, error) `perm:"web,admin"`
 
+		GetSeedingProgress func(p0 context.Context, p1 string) (*types.SeedingProgress, error) `perm:"web,admin"`
+
+		ImportDenylist func(p0 context.Context, p1 string, p2 string, p3 string) (*types.DenylistImportResult, error) `perm:"admin"`
+
+		IngestAssetCompleted func(p0 context.Context, p1 *types.IngestAssetResult) error `perm:"candidate"`
+
+		ListAssetGeoPolicies func(p0 context.Context, p1 int, p2 int) (*types.ListAssetGeoPolicyRsp, error) `perm:"web,admin"`
+
+		ListAssetGeoViolations func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListAssetGeoViolationRsp, error) `perm:"web,admin"`
+
 		ListAssets func(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) `perm:"web,admin,user"`
 
+		ListColdAssets func(p0 context.Context, p1 types.ColdAssetState, p2 int, p3 int) (*types.ListColdAssetsRsp, error) `perm:"web,admin"`
+
+		ListDenylist func(p0 context.Context, p1 int, p2 int) (*types.ListDenylistRsp, error) `perm:"web,admin"`
+
+		ListDenylistEvents func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListDenylistEventsRsp, error) `perm:"web,admin"`
+
+		ListGatewayHosts func(p0 context.Context) ([]*types.GatewayHost, error) `perm:"web,admin"`
+
+		ListSegmentStats func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListSegmentStatsRsp, error) `perm:"web,admin"`
+
 		LoadAWSData func(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) `perm:"web,admin"`
 
+		MarkAssetCacheable func(p0 context.Context, p1 string, p2 bool) error `perm:"admin"`
+
 		MinioUploadFileEvent func(p0 context.Context, p1 *types.MinioUploadFileEvent) error `perm:"candidate"`
 
 		NodeRemoveAssetResult func(p0 context.Context, p1 types.RemoveAssetResult) error `perm:"edge,candidate"`
 
+		OffloadAsset func(p0 context.Context, p1 string) error `perm:"admin"`
+
 		PullAsset func(p0 context.Context, p1 *types.PullAssetReq) error `perm:"web,admin"`
 
 		RePullFailedAssets func(p0 context.Context, p1 []types.AssetHash) error `perm:"admin"`
 
+		RemoveAssetACL func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin,user"`
+
+		RemoveAssetGeoPolicy func(p0 context.Context, p1 string) error `perm:"admin"`
+
 		RemoveAssetRecord func(p0 context.Context, p1 string) error `perm:"admin"`
 
 		RemoveAssetReplica func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
 
+		RemoveFromDenylist func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RemoveGatewayAsset func(p0 context.Context, p1 string) error `perm:"admin"`
+
 		RemoveNodeFailedReplica func(p0 context.Context) (error) `perm:"web,admin"`
 
+		ReportSegmentHits func(p0 context.Context, p1 []*types.SegmentHits) error `perm:"edge,candidate"`
+
+		RestoreAsset func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		SearchAssets func(p0 context.Context, p1 *types.SearchAssetsReq) (*types.SearchAssetsRsp, error) `perm:"web,admin,user"`
+
+		SetAssetACL func(p0 context.Context, p1 string, p2 *types.AssetACL) error `perm:"web,admin,user"`
+
+		SetAssetGeoPolicy func(p0 context.Context, p1 string, p2 types.AssetGeoRules) error `perm:"admin"`
+
+		SetNodeCacheConfig func(p0 context.Context, p1 string, p2 *types.CacheConfig) error `perm:"admin"`
+
 		ShareAssets func(p0 context.Context, p1 string, p2 []string) (map[string]string, error) `perm:"web,admin,user"`
 
 		StopAssetRecord func(p0 context.Context, p1 []string) error `perm:"admin"`
@@ -99,6 +205,10 @@ type AssetAPIStruct struct {
 
 		UpdateAssetExpiration func(p0 context.Context, p1 string, p2 time.Time) error `perm:"admin"`
 
+		UpdateAssetQoSTier func(p0 context.Context, p1 string, p2 types.AssetQoSTier) error `perm:"admin"`
+
+		UpdateAssetVideoFormat func(p0 context.Context, p1 string, p2 types.AssetVideoFormat) error `perm:"admin"`
+
 		UpdateShareStatus func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin"`
 	}
 }
@@ -118,14 +228,26 @@ type CandidateStruct struct {
 	AssetStruct
 
 	Internal struct {
+		AddRelaySession func(p0 context.Context, p1 *types.RelaySession) error `perm:"admin"`
+
+		ApplyNodeConfig func(p0 context.Context, p1 *types.NodeConfigPush) (*types.NodeConfigAck, error) `perm:"admin"`
+
 		CheckNetworkConnectivity func(p0 context.Context, p1 string, p2 string) error `perm:"default"`
 
+		CollectDiagnostics func(p0 context.Context, p1 *types.NodeDiagnosticsReq) ([]byte, error) `perm:"admin"`
+
 		GetBlocksWithAssetCID func(p0 context.Context, p1 string, p2 int64, p3 int) ([]string, error) `perm:"admin"`
 
 		GetExternalAddress func(p0 context.Context) (string, error) `perm:"default"`
 
 		GetMinioConfig func(p0 context.Context) (*types.MinioConfig, error) `perm:"admin"`
 
+		StartLogStream func(p0 context.Context, p1 *types.LogStreamReq) error `perm:"admin"`
+
+		StopLogStream func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		Upgrade func(p0 context.Context, p1 *types.UpgradeCommand) error `perm:"admin"`
+
 		WaitQuiet func(p0 context.Context) error `perm:"admin"`
 	}
 }
@@ -185,6 +307,8 @@ type DeviceStruct struct {
 		GetNodeID func(p0 context.Context) (string, error) `perm:"admin"`
 
 		GetNodeInfo func(p0 context.Context) (types.NodeInfo, error) `perm:"admin"`
+
+		RunHardwareChallenge func(p0 context.Context, p1 *types.HardwareChallenge) (*types.HardwareChallengeResult, error) `perm:"admin"`
 	}
 }
 
@@ -203,13 +327,31 @@ type EdgeStruct struct {
 	AssetStruct
 
 	Internal struct {
+		ApplyNodeConfig func(p0 context.Context, p1 *types.NodeConfigPush) (*types.NodeConfigAck, error) `perm:"admin"`
+
+		BandwidthTest func(p0 context.Context, p1 *types.BandwidthTestReq) ([]*types.BandwidthTestReport, error) `perm:"admin"`
+
+		CollectDiagnostics func(p0 context.Context, p1 *types.NodeDiagnosticsReq) ([]byte, error) `perm:"admin"`
+
 		ExternalServiceAddress func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
 
 		GetEdgeOnlineStateFromScheduler func(p0 context.Context) (bool, error) `perm:"default"`
 
+		SetUploadLimit func(p0 context.Context, p1 int64) error `perm:"admin"`
+
+		StartBandwidthTest func(p0 context.Context) (*types.BandwidthTest, error) `perm:"admin"`
+
+		StartLogStream func(p0 context.Context, p1 *types.LogStreamReq) error `perm:"admin"`
+
+		StopLogStream func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		Upgrade func(p0 context.Context, p1 *types.UpgradeCommand) error `perm:"admin"`
+
 		UserNATPunch func(p0 context.Context, p1 string, p2 *types.NatPunchReq) error `perm:"admin"`
 
 		WaitQuiet func(p0 context.Context) error `perm:"admin"`
+
+		WebRTCOffer func(p0 context.Context, p1 *types.WebRTCOffer) (*types.WebRTCAnswer, error) `perm:"admin"`
 	}
 }
 
@@ -233,10 +375,16 @@ type LocatorStruct struct {
 
 		EdgeDownloadInfos func(p0 context.Context, p1 string) ([]*types.EdgeDownloadInfoList, error) `perm:"default"`
 
+		GatewayNodes func(p0 context.Context, p1 string) ([]*types.GatewayNode, error) `perm:"default"`
+
+		GatewayToken func(p0 context.Context, p1 string, p2 *types.GatewayNode) (*types.Token, error) `perm:"default"`
+
 		GetAccessPoints func(p0 context.Context, p1 string, p2 string) ([]string, error) `perm:"default"`
 
 		GetCandidateIP func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
 
+		GetRegionStats func(p0 context.Context, p1 string) ([]*types.RegionStats, error) `perm:"admin"`
+
 		GetSchedulerWithAPIKey func(p0 context.Context, p1 string) (string, error) `perm:"default"`
 
 		GetSchedulerWithNode func(p0 context.Context, p1 string) (string, error) `perm:"default"`
@@ -251,20 +399,44 @@ type LocatorStub struct {
 
 type NodeAPIStruct struct {
 	Internal struct {
+		AddMaintenanceWindow func(p0 context.Context, p1 *types.MaintenanceWindow) (int64, error) `perm:"web,admin"`
+
+		AddReleaseManifest func(p0 context.Context, p1 *types.ReleaseManifest) error `perm:"admin"`
+
+		AppealPenalty func(p0 context.Context, p1 int64, p2 string) error `perm:"web,admin"`
+
+		CancelBulkJob func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		CancelJob func(p0 context.Context, p1 string) error `perm:"admin"`
+
 		CandidateConnect func(p0 context.Context, p1 *types.ConnectOptions) error `perm:"candidate"`
 
 		CheckIpUsage func(p0 context.Context, p1 string) (bool, error) `perm:"admin,web,locator"`
 
+		CorrectPointsEpoch func(p0 context.Context, p1 *types.PointsCorrection) (int64, error) `perm:"admin"`
+
 		DeactivateNode func(p0 context.Context, p1 string, p2 int) error `perm:"web,admin"`
 
+		DeletePenaltyRule func(p0 context.Context, p1 int64) error `perm:"admin"`
+
 		DownloadDataResult func(p0 context.Context, p1 string, p2 string, p3 int64) error `perm:"edge,candidate"`
 
 		EdgeConnect func(p0 context.Context, p1 *types.ConnectOptions) error `perm:"edge"`
 
+		EndMaintenanceWindow func(p0 context.Context, p1 int64) error `perm:"web,admin"`
+
+		EstimatePoints func(p0 context.Context, p1 *types.PointsEstimateReq) (*types.PointsEstimateRsp, error) `perm:"web,admin"`
+
+		ExportSettlement func(p0 context.Context, p1 int64) error `perm:"admin"`
+
 		GetAssetView func(p0 context.Context, p1 string, p2 bool) (*types.AssetView, error) `perm:"admin"`
 
 		GetAssetsInBucket func(p0 context.Context, p1 string, p2 int, p3 bool) ([]string, error) `perm:"admin"`
 
+		GetBandwidthTest func(p0 context.Context, p1 string) (*types.BandwidthTest, error) `perm:"edge,web,admin"`
+
+		GetBulkJob func(p0 context.Context, p1 string) (*types.BulkJob, error) `perm:"web,admin"`
+
 		GetCandidateDownloadInfos func(p0 context.Context, p1 string) ([]*types.CandidateDownloadInfo, error) `perm:"edge,candidate,web,locator"`
 
 		GetCandidateIPs func(p0 context.Context) ([]*types.NodeIPInfo, error) `perm:"web,user,admin"`
@@ -273,26 +445,158 @@ type NodeAPIStruct struct {
 
 		GetCandidateURLsForDetectNat func(p0 context.Context) ([]string, error) `perm:"default"`
 
+		GetClientDownloadInfos func(p0 context.Context, p1 string, p2 string) (*types.EdgeDownloadInfoList, error) `perm:"default"`
+
+		GetDashboardStats func(p0 context.Context) (*types.DashboardStats, error) `perm:"web,admin"`
+
+		GetDataExport func(p0 context.Context, p1 string) (*types.DataExport, error) `perm:"web,admin"`
+
+		GetDataExportSchemas func(p0 context.Context) ([]*types.DataExportSchema, error) `perm:"web,admin"`
+
 		GetEdgeDownloadInfos func(p0 context.Context, p1 string) (*types.EdgeDownloadInfoList, error) `perm:"default"`
 
 		GetEdgeExternalServiceAddress func(p0 context.Context, p1 string, p2 string) (string, error) `perm:"admin"`
 
 		GetExternalAddress func(p0 context.Context) (string, error) `perm:"default"`
 
+		GetGatewayNodes func(p0 context.Context, p1 string, p2 int) ([]*types.GatewayNode, error) `perm:"web,locator"`
+
+		GetGatewayToken func(p0 context.Context, p1 string, p2 string) (*types.Token, error) `perm:"web,locator"`
+
+		GetIPFamilyStats func(p0 context.Context) ([]*types.IPFamilyStats, error) `perm:"web,admin"`
+
+		GetJob func(p0 context.Context, p1 string) (*types.Job, error) `perm:"web,admin"`
+
+		GetLeaderboard func(p0 context.Context, p1 *types.LeaderboardReq) (*types.LeaderboardRsp, error) `perm:"web,admin"`
+
+		GetLocationIndexStats func(p0 context.Context) (*types.LocationIndexStats, error) `perm:"admin"`
+
 		GetMinioConfigFromCandidate func(p0 context.Context, p1 string) (*types.MinioConfig, error) `perm:"default"`
 
+		GetNodeCommitment func(p0 context.Context, p1 string) (*types.NodeCommitment, error) `perm:"web,admin"`
+
+		GetNodeCommitmentRecords func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeCommitmentRecordRsp, error) `perm:"web,admin"`
+
+		GetNodeConfigDefaults func(p0 context.Context, p1 types.NodeType) (*types.NodeConfig, error) `perm:"web,admin"`
+
+		GetNodeConfigStatus func(p0 context.Context, p1 string) (*types.NodeConfigStatus, error) `perm:"web,admin"`
+
+		GetNodeDiagnostics func(p0 context.Context, p1 string) (*types.NodeDiagnostics, error) `perm:"web,admin"`
+
+		GetNodeDiagnosticsData func(p0 context.Context, p1 string) ([]byte, error) `perm:"web,admin"`
+
+		GetNodeDiskHealth func(p0 context.Context, p1 string) (*types.NodeDiskHealth, error) `perm:"web,admin"`
+
 		GetNodeInfo func(p0 context.Context, p1 string) (types.NodeInfo, error) `perm:"web,admin"`
 
+		GetNodeKeyTypes func(p0 context.Context) ([]string, error) `perm:"default"`
+
 		GetNodeList func(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) `perm:"web,admin"`
 
+		GetNodeMaintenanceWindows func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListMaintenanceWindowRsp, error) `perm:"web,admin"`
+
 		GetNodeOfIP func(p0 context.Context, p1 string) ([]string, error) `perm:"admin,web,locator"`
 
 		GetNodeOnlineState func(p0 context.Context) (bool, error) `perm:"edge"`
 
+		GetNodeOperations func(p0 context.Context, p1 string) (*types.NodeOperations, error) `perm:"web,admin"`
+
+		GetNodePenalties func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListPenaltyRsp, error) `perm:"web,admin"`
+
+		GetNodePointsEpochs func(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.NodePointsEpochsRsp, error) `perm:"web,admin"`
+
+		GetNodeTasks func(p0 context.Context, p1 string) ([]*types.NodeTask, error) `perm:"web,admin"`
+
 		GetNodeToken func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
 
+		GetNodeTrafficDaily func(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) ([]*types.NodeTrafficDaily, error) `perm:"web,admin"`
+
+		GetNodeTrafficStatement func(p0 context.Context, p1 string, p2 string) (*types.NodeTrafficStatement, error) `perm:"web,admin"`
+
+		GetNodeTrust func(p0 context.Context, p1 string) (*types.NodeTrust, error) `perm:"web,admin"`
+
+		GetNodeTrustTransitions func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListTrustTransitionRsp, error) `perm:"web,admin"`
+
+		GetNodeUploadLimit func(p0 context.Context, p1 string) (*types.UploadLimit, error) `perm:"web,admin"`
+
 		GetOnlineNodeCount func(p0 context.Context, p1 types.NodeType) (int, error) `perm:"web,admin"`
 
+		GetParallelDownloadPlan func(p0 context.Context, p1 *types.ParallelDownloadReq) (*types.ParallelDownloadPlan, error) `perm:"default"`
+
+		GetPenaltyRules func(p0 context.Context) ([]*types.PenaltyRule, error) `perm:"web,admin"`
+
+		GetPointsEpochs func(p0 context.Context, p1 int, p2 int) (*types.ListPointsEpochRsp, error) `perm:"web,admin"`
+
+		GetPointsLeaderboard func(p0 context.Context, p1 int) ([]*types.NodePointsRank, error) `perm:"web,admin"`
+
+		GetRegionStats func(p0 context.Context) (*types.RegionStats, error) `perm:"web,admin,locator"`
+
+		GetRelaySession func(p0 context.Context) (*types.RelaySession, error) `perm:"edge"`
+
+		GetRelaySessions func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRelaySessionRsp, error) `perm:"web,admin"`
+
+		GetReportRejections func(p0 context.Context, p1 string) ([]*types.ReportRejection, error) `perm:"web,admin"`
+
+		GetRetrievalSLAReport func(p0 context.Context, p1 types.RetrievalSLAGroup, p2 time.Time, p3 time.Time, p4 int, p5 int) (*types.RetrievalSLAReport, error) `perm:"web,admin"`
+
+		GetSchedulerPeers func(p0 context.Context) ([]*types.SchedulerCfg, error) `perm:"admin"`
+
+		GetSettlementEpochs func(p0 context.Context, p1 int, p2 int) (*types.ListSettlementEpochRsp, error) `perm:"web,admin"`
+
+		GetSettlementProof func(p0 context.Context, p1 int64, p2 string) (*types.SettlementProof, error) `perm:"web,admin"`
+
+		GetSettlementShares func(p0 context.Context, p1 int64, p2 int, p3 int) (*types.ListSettlementShareRsp, error) `perm:"web,admin"`
+
+		GetTrafficStatements func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeTrafficStatementRsp, error) `perm:"web,admin"`
+
+		GetTransferProtocolStats func(p0 context.Context, p1 string) ([]*types.TransferProtocolStats, error) `perm:"web,admin"`
+
+		GetUpgradeRollout func(p0 context.Context, p1 string) (*types.UpgradeRollout, error) `perm:"admin"`
+
+		GetUpgradeWaveStats func(p0 context.Context, p1 string) ([]*types.UpgradeWaveStats, error) `perm:"admin"`
+
+		GetVersionAdoption func(p0 context.Context, p1 types.NodeType) ([]*types.VersionAdoption, error) `perm:"web,admin"`
+
+		GetWebRTCConfig func(p0 context.Context) (*types.WebRTCConfig, error) `perm:"default"`
+
+		GetZoneStats func(p0 context.Context) ([]*types.RegionStats, error) `perm:"web,admin,locator"`
+
+		HaltUpgradeRollout func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
+
+		IssueNodeCertificate func(p0 context.Context, p1 string, p2 string, p3 []byte) (*types.NodeCertificate, error) `perm:"default"`
+
+		ListArchivedNodes func(p0 context.Context, p1 int, p2 int) (*types.ListArchivedNodeRsp, error) `perm:"web,admin"`
+
+		ListBandwidthTests func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListBandwidthTestRsp, error) `perm:"web,admin"`
+
+		ListBulkJobItems func(p0 context.Context, p1 string, p2 types.BulkJobItemStatus, p3 int, p4 int) (*types.ListBulkJobItemRsp, error) `perm:"web,admin"`
+
+		ListBulkJobs func(p0 context.Context, p1 int, p2 int) (*types.ListBulkJobRsp, error) `perm:"web,admin"`
+
+		ListDataExports func(p0 context.Context, p1 int, p2 int) (*types.ListDataExportRsp, error) `perm:"web,admin"`
+
+		ListFeatureFlags func(p0 context.Context) ([]*types.FeatureFlag, error) `perm:"web,admin"`
+
+		ListJobs func(p0 context.Context, p1 string, p2 types.JobStatus, p3 int, p4 int) (*types.ListJobRsp, error) `perm:"web,admin"`
+
+		ListNodeDiagnostics func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeDiagnosticsRsp, error) `perm:"web,admin"`
+
+		ListNodes func(p0 context.Context, p1 *types.ListNodesReq) (*types.ListNodesCursorRsp, error) `perm:"web,admin"`
+
+		ListReleaseManifests func(p0 context.Context, p1 types.NodeType) ([]*types.ReleaseManifest, error) `perm:"admin"`
+
+		ListRetrievalProbes func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrievalProbeRsp, error) `perm:"web,admin"`
+
+		ListSpotChecks func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListSpotCheckRsp, error) `perm:"admin"`
+
+		ListStorageProofs func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListStorageProofRsp, error) `perm:"web,admin"`
+
+		ListUpgradeNodes func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListUpgradeNodeRsp, error) `perm:"admin"`
+
+		ListUpgradeRollouts func(p0 context.Context, p1 int, p2 int) (*types.ListUpgradeRolloutRsp, error) `perm:"admin"`
+
+		MigrateNodeKey func(p0 context.Context, p1 string, p2 string, p3 string) (*types.ActivationDetail, error) `perm:"default"`
+
 		NatPunch func(p0 context.Context, p1 *types.NatPunchReq) error `perm:"default"`
 
 		NodeExists func(p0 context.Context, p1 string) error `perm:"web"`
@@ -301,27 +605,110 @@ type NodeAPIStruct struct {
 
 		NodeKeepaliveV2 func(p0 context.Context) (uuid.UUID, error) `perm:"edge,candidate"`
 
+		NodeKeepaliveV3 func(p0 context.Context, p1 *types.HostMetrics) (uuid.UUID, error) `perm:"edge,candidate"`
+
 		NodeLogin func(p0 context.Context, p1 string, p2 string) (string, error) `perm:"default"`
 
+		NodeLoginChallenge func(p0 context.Context, p1 string) (string, error) `perm:"default"`
+
+		NodeLoginV2 func(p0 context.Context, p1 *types.NodeLoginReq) (string, error) `perm:"default"`
+
+		PullControlCalls func(p0 context.Context) ([]*types.ControlCall, error) `perm:"edge,candidate"`
+
+		PurgeNode func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		PushNodeLogs func(p0 context.Context, p1 *types.LogBatch) error `perm:"edge,candidate"`
+
 		RegisterEdgeNode func(p0 context.Context, p1 string, p2 string) (*types.ActivationDetail, error) `perm:"default"`
 
 		RegisterNode func(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) `perm:"default"`
 
+		RegisterSandboxNode func(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) `perm:"default"`
+
+		RemoveFeatureFlag func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RemoveNodeCommitment func(p0 context.Context, p1 string) error `perm:"web,admin"`
+
 		RequestActivationCodes func(p0 context.Context, p1 types.NodeType, p2 int) ([]*types.NodeActivation, error) `perm:"web,admin"`
 
+		RequestNodeDiagnostics func(p0 context.Context, p1 string, p2 string) (string, error) `perm:"web,admin"`
+
+		ResolvePenaltyAppeal func(p0 context.Context, p1 int64, p2 bool) error `perm:"admin"`
+
+		RestoreArchivedNode func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		ResumeUpgradeRollout func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		SavePenaltyRule func(p0 context.Context, p1 *types.PenaltyRule) (int64, error) `perm:"admin"`
+
+		SetFeatureFlag func(p0 context.Context, p1 *types.FeatureFlag) error `perm:"admin"`
+
+		SetIdleMode func(p0 context.Context, p1 bool) (*types.IdleMode, error) `perm:"edge"`
+
+		SetNodeCommitment func(p0 context.Context, p1 *types.NodeCommitment) error `perm:"web,admin"`
+
+		SetNodeConfigDefaults func(p0 context.Context, p1 types.NodeType, p2 *types.NodeConfig) error `perm:"admin"`
+
+		SetNodeConfigOverrides func(p0 context.Context, p1 string, p2 *types.NodeConfig) error `perm:"admin"`
+
+		SetNodeUploadLimit func(p0 context.Context, p1 string, p2 int64) error `perm:"admin"`
+
+		StartBandwidthTest func(p0 context.Context, p1 string) (string, error) `perm:"edge,web,admin"`
+
+		StartBulkJob func(p0 context.Context, p1 *types.BulkJobReq) (string, error) `perm:"admin"`
+
+		StartDataExport func(p0 context.Context, p1 *types.DataExportReq) (string, error) `perm:"admin"`
+
+		StartUpgradeRollout func(p0 context.Context, p1 *types.UpgradeRolloutReq) (string, error) `perm:"admin"`
+
+		StreamNodeLogs func(p0 context.Context, p1 string, p2 *types.LogStreamReq) (<-chan *types.LogEntry, error) `perm:"web,admin"`
+
+		SubmitControlResults func(p0 context.Context, p1 []*types.ControlResult) error `perm:"edge,candidate"`
+
+		SubmitRelayTraffic func(p0 context.Context, p1 []*types.RelayTraffic) error `perm:"candidate"`
+
+		SubscribeNodeEvents func(p0 context.Context) (<-chan *types.NodeEvent, error) `perm:"web,admin"`
+
+		SubscribeNodeTop func(p0 context.Context, p1 *types.NodeTopReq) (<-chan *types.NodeTopSnapshot, error) `perm:"web,admin"`
+
 		UndoNodeDeactivation func(p0 context.Context, p1 string) error `perm:"web,admin"`
 
-		UpdateBandwidths func(p0 context.Context, p1 int64, p2 int64) error `perm:"edge,candidate"`
+		UpdateBandwidths func(p0 context.Context, p1 *types.BandwidthReport) error `perm:"edge,candidate"`
 
 		UpdateNodePort func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin"`
 
 		VerifyTokenWithLimitCount func(p0 context.Context, p1 string) (*types.JWTPayload, error) `perm:"edge,candidate"`
+
+		WebRTCConnect func(p0 context.Context, p1 *types.WebRTCOfferReq) (*types.WebRTCAnswer, error) `perm:"default"`
 	}
 }
 
 type NodeAPIStub struct {
 }
 
+type S3APIStruct struct {
+	Internal struct {
+		CreateS3AccessKey func(p0 context.Context, p1 string) (*types.S3AccessKey, error) `perm:"user,web,admin"`
+
+		DeleteS3AccessKey func(p0 context.Context, p1 string, p2 string) error `perm:"user,web,admin"`
+
+		GetS3AccessKeys func(p0 context.Context, p1 string) ([]*types.S3AccessKey, error) `perm:"user,web,admin"`
+
+		S3Authorize func(p0 context.Context, p1 *types.S3AuthReq) (*types.S3Grant, error) `perm:"candidate"`
+
+		S3GetObject func(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) `perm:"candidate"`
+
+		S3ListBuckets func(p0 context.Context, p1 string) ([]string, error) `perm:"candidate"`
+
+		S3ListObjects func(p0 context.Context, p1 *types.ListS3ObjectsReq) (*types.ListS3ObjectsRsp, error) `perm:"candidate"`
+
+		S3PutObject func(p0 context.Context, p1 *types.S3Grant, p2 *types.S3Object) error `perm:"candidate"`
+	}
+}
+
+type S3APIStub struct {
+}
+
 type SchedulerStruct struct {
 	CommonStruct
 
@@ -331,36 +718,76 @@ type SchedulerStruct struct {
 
 	UserAPIStruct
 
+	AccountAPIStruct
+
+	TokenAPIStruct
+
+	S3APIStruct
+
+	TenantAPIStruct
+
+	WebhookAPIStruct
+
 	Internal struct {
 		DeleteEdgeUpdateConfig func(p0 context.Context, p1 int) error `perm:"admin"`
 
 		ElectValidators func(p0 context.Context, p1 []string) error `perm:"admin"`
 
+		GetAuditLogs func(p0 context.Context, p1 *types.ListAuditLogsReq) (*types.ListAuditLogRsp, error) `perm:"admin"`
+
 		GetEdgeUpdateConfigs func(p0 context.Context) (map[int]*EdgeUpdateConfig, error) `perm:"edge"`
 
+		GetGoroutineDump func(p0 context.Context) (string, error) `perm:"admin"`
+
+		GetHardwareProof func(p0 context.Context, p1 string) (*types.HardwareProof, error) `perm:"web,admin"`
+
 		GetNodePublicKey func(p0 context.Context, p1 string) (string, error) `perm:"web,admin"`
 
+		GetProfile func(p0 context.Context, p1 string, p2 int) ([]byte, error) `perm:"admin"`
+
 		GetRetrieveEventRecords func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrieveEventRsp, error) `perm:"web,admin"`
 
+		GetRuntimeDiagnostics func(p0 context.Context) (*types.RuntimeDiagnostics, error) `perm:"admin"`
+
 		GetSchedulerPublicKey func(p0 context.Context) (string, error) `perm:"edge,candidate"`
 
 		GetValidationInfo func(p0 context.Context) (*types.ValidationInfo, error) `perm:"web,admin"`
 
+		GetValidationProofs func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationProofRsp, error) `perm:"web,admin"`
+
 		GetValidationResults func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationResultRsp, error) `perm:"web,admin"`
 
+		GetValidatorLoads func(p0 context.Context) ([]*types.ValidatorLoad, error) `perm:"web,admin"`
+
 		GetWorkloadRecord func(p0 context.Context, p1 string) (*types.WorkloadRecord, error) `perm:"web,admin"`
 
 		GetWorkloadRecords func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadRecordRsp, error) `perm:"web,admin"`
 
+		GetWorkloadVerificationStats func(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.WorkloadVerificationStats, error) `perm:"web,admin"`
+
+		ListSchedulingDecisions func(p0 context.Context, p1 *types.ListSchedulingDecisionsReq) (*types.ListSchedulingDecisionRsp, error) `perm:"admin"`
+
+		ListWorkloadReceipts func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadReceiptRsp, error) `perm:"web,admin"`
+
 		NodeValidationResult func(p0 context.Context, p1 io.Reader, p2 string) error `perm:"candidate"`
 
+		ReplaySchedulingDecision func(p0 context.Context, p1 int64) (*types.SchedulingDecision, error) `perm:"admin"`
+
+		RotateSchedulerKey func(p0 context.Context) error `perm:"admin"`
+
 		SetEdgeUpdateConfig func(p0 context.Context, p1 *EdgeUpdateConfig) error `perm:"admin"`
 
+		SubmitClientReceipts func(p0 context.Context, p1 []*types.WorkloadReceipt) error `perm:"default"`
+
 		SubmitNodeWorkloadReport func(p0 context.Context, p1 io.Reader) error `perm:"edge,candidate"`
 
 		SubmitUserWorkloadReport func(p0 context.Context, p1 io.Reader) error `perm:"default"`
 
+		SubmitWorkloadReceipts func(p0 context.Context, p1 []*types.WorkloadReceipt) error `perm:"edge,candidate"`
+
 		TriggerElection func(p0 context.Context) error `perm:"admin"`
+
+		VerifyValidationProofs func(p0 context.Context, p1 string) (*types.ValidationProofAudit, error) `perm:"web,admin"`
 	}
 }
 
@@ -372,6 +799,74 @@ type SchedulerStub struct {
 	NodeAPIStub
 
 	UserAPIStub
+
+	AccountAPIStub
+
+	TokenAPIStub
+
+	S3APIStub
+
+	TenantAPIStub
+
+	WebhookAPIStub
+}
+
+type TenantAPIStruct struct {
+	Internal struct {
+		AddTenantUser func(p0 context.Context, p1 string, p2 string) error `perm:"web,admin"`
+
+		CreateReplicationAgreement func(p0 context.Context, p1 *types.ReplicationAgreement) (string, error) `perm:"admin"`
+
+		CreateTenantToken func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
+
+		GetTenantQuota func(p0 context.Context, p1 string) (*types.TenantQuotaStatus, error) `perm:"web,admin"`
+
+		GetTenantUsage func(p0 context.Context, p1 string) (*types.TenantUsage, error) `perm:"web,admin"`
+
+		GetTenantUsageReport func(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.TenantUsageReport, error) `perm:"web,admin"`
+
+		ListReplicationAgreements func(p0 context.Context, p1 string) ([]*types.ReplicationAgreement, error) `perm:"web,admin"`
+
+		ListTenantUsers func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListTenantUserRsp, error) `perm:"web,admin"`
+
+		ListTenants func(p0 context.Context) ([]*types.Tenant, error) `perm:"web,admin"`
+
+		ReceiveMirrorManifests func(p0 context.Context, p1 *types.MirrorManifestBatch) (int, error) `perm:"web"`
+
+		RemoveReplicationAgreement func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RemoveTenant func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RemoveTenantUser func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
+
+		SetReplicationAgreementState func(p0 context.Context, p1 string, p2 types.ReplicationAgreementState) error `perm:"admin"`
+
+		SetTenant func(p0 context.Context, p1 *types.Tenant) error `perm:"admin"`
+	}
+}
+
+type TenantAPIStub struct {
+}
+
+type TokenAPIStruct struct {
+	Internal struct {
+		AssignAPITokenRole func(p0 context.Context, p1 string, p2 auth.Permission) error `perm:"admin"`
+
+		CreateAPIToken func(p0 context.Context, p1 string, p2 auth.Permission) (string, error) `perm:"admin"`
+
+		GetAPITokens func(p0 context.Context) ([]*types.APIToken, error) `perm:"admin"`
+
+		GetActiveTokens func(p0 context.Context, p1 auth.Permission, p2 int, p3 int) (*types.ListTokenSubjectRsp, error) `perm:"admin"`
+
+		RevokeTokens func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		RotateAPIToken func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
+
+		RotateUserTokens func(p0 context.Context, p1 string) (string, error) `perm:"web,admin"`
+	}
+}
+
+type TokenAPIStub struct {
 }
 
 type UserAPIStruct struct {
@@ -430,9 +925,125 @@ type ValidationStruct struct {
 type ValidationStub struct {
 }
 
-func (s *AssetStruct) AddAssetView(p0 context.Context, p1 []string) error {
-	if s.Internal.AddAssetView == nil {
-		return ErrNotSupported
+type WebhookAPIStruct struct {
+	Internal struct {
+		CreateAssetWebhook func(p0 context.Context, p1 string, p2 string, p3 string, p4 []types.WebhookEvent) (*types.Webhook, error) `perm:"user,web,admin"`
+
+		CreateTenantWebhook func(p0 context.Context, p1 string, p2 string, p3 []types.WebhookEvent) (*types.Webhook, error) `perm:"web,admin"`
+
+		DeleteWebhook func(p0 context.Context, p1 string, p2 int64) error `perm:"user,web,admin"`
+
+		ListWebhookDeliveries func(p0 context.Context, p1 string, p2 int64, p3 int, p4 int) (*types.ListWebhookDeliveryRsp, error) `perm:"user,web,admin"`
+
+		ListWebhooks func(p0 context.Context, p1 string, p2 string) ([]*types.Webhook, error) `perm:"user,web,admin"`
+	}
+}
+
+type WebhookAPIStub struct {
+}
+
+func (s *AccountAPIStruct) AddAlertSubscription(p0 context.Context, p1 *types.AlertSubscription) (int64, error) {
+	if s.Internal.AddAlertSubscription == nil {
+		return 0, ErrNotSupported
+	}
+	return s.Internal.AddAlertSubscription(p0, p1)
+}
+
+func (s *AccountAPIStub) AddAlertSubscription(p0 context.Context, p1 *types.AlertSubscription) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (s *AccountAPIStruct) BindNodeToAccount(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.BindNodeToAccount == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.BindNodeToAccount(p0, p1, p2)
+}
+
+func (s *AccountAPIStub) BindNodeToAccount(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *AccountAPIStruct) GetAccountNodes(p0 context.Context, p1 string) ([]*types.AccountNode, error) {
+	if s.Internal.GetAccountNodes == nil {
+		return *new([]*types.AccountNode), ErrNotSupported
+	}
+	return s.Internal.GetAccountNodes(p0, p1)
+}
+
+func (s *AccountAPIStub) GetAccountNodes(p0 context.Context, p1 string) ([]*types.AccountNode, error) {
+	return *new([]*types.AccountNode), ErrNotSupported
+}
+
+func (s *AccountAPIStruct) GetAccountNotificationPrefs(p0 context.Context, p1 string) (*types.AccountNotificationPrefs, error) {
+	if s.Internal.GetAccountNotificationPrefs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAccountNotificationPrefs(p0, p1)
+}
+
+func (s *AccountAPIStub) GetAccountNotificationPrefs(p0 context.Context, p1 string) (*types.AccountNotificationPrefs, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AccountAPIStruct) GetAccountStats(p0 context.Context, p1 string) (*types.AccountStats, error) {
+	if s.Internal.GetAccountStats == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAccountStats(p0, p1)
+}
+
+func (s *AccountAPIStub) GetAccountStats(p0 context.Context, p1 string) (*types.AccountStats, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AccountAPIStruct) GetAlertSubscriptions(p0 context.Context, p1 string) ([]*types.AlertSubscription, error) {
+	if s.Internal.GetAlertSubscriptions == nil {
+		return *new([]*types.AlertSubscription), ErrNotSupported
+	}
+	return s.Internal.GetAlertSubscriptions(p0, p1)
+}
+
+func (s *AccountAPIStub) GetAlertSubscriptions(p0 context.Context, p1 string) ([]*types.AlertSubscription, error) {
+	return *new([]*types.AlertSubscription), ErrNotSupported
+}
+
+func (s *AccountAPIStruct) RemoveAlertSubscription(p0 context.Context, p1 string, p2 int64) error {
+	if s.Internal.RemoveAlertSubscription == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAlertSubscription(p0, p1, p2)
+}
+
+func (s *AccountAPIStub) RemoveAlertSubscription(p0 context.Context, p1 string, p2 int64) error {
+	return ErrNotSupported
+}
+
+func (s *AccountAPIStruct) SetAccountNotificationPrefs(p0 context.Context, p1 *types.AccountNotificationPrefs) error {
+	if s.Internal.SetAccountNotificationPrefs == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetAccountNotificationPrefs(p0, p1)
+}
+
+func (s *AccountAPIStub) SetAccountNotificationPrefs(p0 context.Context, p1 *types.AccountNotificationPrefs) error {
+	return ErrNotSupported
+}
+
+func (s *AccountAPIStruct) UnbindNodeFromAccount(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.UnbindNodeFromAccount == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UnbindNodeFromAccount(p0, p1, p2)
+}
+
+func (s *AccountAPIStub) UnbindNodeFromAccount(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetStruct) AddAssetView(p0 context.Context, p1 []string) error {
+	if s.Internal.AddAssetView == nil {
+		return ErrNotSupported
 	}
 	return s.Internal.AddAssetView(p0, p1)
 }
@@ -452,6 +1063,17 @@ func (s *AssetStub) CreateAsset(p0 context.Context, p1 *types.AuthUserUploadDown
 	return "", ErrNotSupported
 }
 
+func (s *AssetStruct) CreateIngestToken(p0 context.Context, p1 *types.AuthUserIngestAsset) (string, error) {
+	if s.Internal.CreateIngestToken == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.CreateIngestToken(p0, p1)
+}
+
+func (s *AssetStub) CreateIngestToken(p0 context.Context, p1 *types.AuthUserIngestAsset) (string, error) {
+	return "", ErrNotSupported
+}
+
 func (s *AssetStruct) DeleteAsset(p0 context.Context, p1 string) error {
 	if s.Internal.DeleteAsset == nil {
 		return ErrNotSupported
@@ -507,6 +1129,17 @@ func (s *AssetStub) GetAssetsInBucket(p0 context.Context, p1 int) ([]string, err
 	return *new([]string), ErrNotSupported
 }
 
+func (s *AssetStruct) GetCacheComposition(p0 context.Context) (*types.CacheComposition, error) {
+	if s.Internal.GetCacheComposition == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetCacheComposition(p0)
+}
+
+func (s *AssetStub) GetCacheComposition(p0 context.Context) (*types.CacheComposition, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetStruct) GetPullingAssetInfo(p0 context.Context) (*types.InProgressAsset, error) {
 	if s.Internal.GetPullingAssetInfo == nil {
 		return nil, ErrNotSupported
@@ -518,6 +1151,28 @@ func (s *AssetStub) GetPullingAssetInfo(p0 context.Context) (*types.InProgressAs
 	return nil, ErrNotSupported
 }
 
+func (s *AssetStruct) MarkAssetsCache(p0 context.Context, p1 []*types.AssetCacheMark) error {
+	if s.Internal.MarkAssetsCache == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.MarkAssetsCache(p0, p1)
+}
+
+func (s *AssetStub) MarkAssetsCache(p0 context.Context, p1 []*types.AssetCacheMark) error {
+	return ErrNotSupported
+}
+
+func (s *AssetStruct) ProveStorage(p0 context.Context, p1 *types.StorageChallenge) (*types.StorageChallengeResult, error) {
+	if s.Internal.ProveStorage == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ProveStorage(p0, p1)
+}
+
+func (s *AssetStub) ProveStorage(p0 context.Context, p1 *types.StorageChallenge) (*types.StorageChallengeResult, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetStruct) PullAsset(p0 context.Context, p1 string, p2 []*types.CandidateDownloadInfo) error {
 	if s.Internal.PullAsset == nil {
 		return ErrNotSupported
@@ -540,6 +1195,17 @@ func (s *AssetStub) PullAssetFromAWS(p0 context.Context, p1 string, p2 string) e
 	return ErrNotSupported
 }
 
+func (s *AssetStruct) SetCacheConfig(p0 context.Context, p1 *types.CacheConfig) error {
+	if s.Internal.SetCacheConfig == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetCacheConfig(p0, p1)
+}
+
+func (s *AssetStub) SetCacheConfig(p0 context.Context, p1 *types.CacheConfig) error {
+	return ErrNotSupported
+}
+
 func (s *AssetStruct) SyncAssetViewAndData(p0 context.Context) error {
 	if s.Internal.SyncAssetViewAndData == nil {
 		return ErrNotSupported
@@ -562,6 +1228,39 @@ func (s *AssetAPIStub) AddAWSData(p0 context.Context, p1 []types.AWSDataInfo) er
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) AddGatewayAsset(p0 context.Context, p1 string) error {
+	if s.Internal.AddGatewayAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.AddGatewayAsset(p0, p1)
+}
+
+func (s *AssetAPIStub) AddGatewayAsset(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) AddToDenylist(p0 context.Context, p1 []string, p2 string) error {
+	if s.Internal.AddToDenylist == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.AddToDenylist(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) AddToDenylist(p0 context.Context, p1 []string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) CheckAssetAccess(p0 context.Context, p1 string, p2 string, p3 string) (bool, error) {
+	if s.Internal.CheckAssetAccess == nil {
+		return false, ErrNotSupported
+	}
+	return s.Internal.CheckAssetAccess(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) CheckAssetAccess(p0 context.Context, p1 string, p2 string, p3 string) (bool, error) {
+	return false, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) CreateAsset(p0 context.Context, p1 *types.CreateAssetReq) (*types.CreateAssetRsp, error) {
 	if s.Internal.CreateAsset == nil {
 		return nil, ErrNotSupported
@@ -573,6 +1272,28 @@ func (s *AssetAPIStub) CreateAsset(p0 context.Context, p1 *types.CreateAssetReq)
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) CreateIngestTask(p0 context.Context, p1 *types.IngestAssetReq) (*types.CreateAssetRsp, error) {
+	if s.Internal.CreateIngestTask == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateIngestTask(p0, p1)
+}
+
+func (s *AssetAPIStub) CreateIngestTask(p0 context.Context, p1 *types.IngestAssetReq) (*types.CreateAssetRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) CreateSignedURL(p0 context.Context, p1 string, p2 string, p3 time.Time) (*types.SignedURL, error) {
+	if s.Internal.CreateSignedURL == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateSignedURL(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) CreateSignedURL(p0 context.Context, p1 string, p2 string, p3 time.Time) (*types.SignedURL, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) DeleteAsset(p0 context.Context, p1 string, p2 string) error {
 	if s.Internal.DeleteAsset == nil {
 		return ErrNotSupported
@@ -584,6 +1305,17 @@ func (s *AssetAPIStub) DeleteAsset(p0 context.Context, p1 string, p2 string) err
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetAssetACL(p0 context.Context, p1 string, p2 string) (*types.AssetACL, error) {
+	if s.Internal.GetAssetACL == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAssetACL(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) GetAssetACL(p0 context.Context, p1 string, p2 string) (*types.AssetACL, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetAssetCount(p0 context.Context) (int, error) {
 	if s.Internal.GetAssetCount == nil {
 		return 0, ErrNotSupported
@@ -595,6 +1327,28 @@ func (s *AssetAPIStub) GetAssetCount(p0 context.Context) (int, error) {
 	return 0, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetAssetEncryption(p0 context.Context, p1 string, p2 string) (*types.AssetEncryption, error) {
+	if s.Internal.GetAssetEncryption == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAssetEncryption(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) GetAssetEncryption(p0 context.Context, p1 string, p2 string) (*types.AssetEncryption, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) GetAssetGeoPolicy(p0 context.Context, p1 string) (*types.AssetGeoPolicy, error) {
+	if s.Internal.GetAssetGeoPolicy == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAssetGeoPolicy(p0, p1)
+}
+
+func (s *AssetAPIStub) GetAssetGeoPolicy(p0 context.Context, p1 string) (*types.AssetGeoPolicy, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetAssetListForBucket(p0 context.Context, p1 uint32) ([]string, error) {
 	if s.Internal.GetAssetListForBucket == nil {
 		return *new([]string), ErrNotSupported
@@ -639,6 +1393,17 @@ func (s *AssetAPIStub) GetAssetStatus(p0 context.Context, p1 string, p2 string)
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetAssetVideoFormat(p0 context.Context, p1 string) (types.AssetVideoFormat, error) {
+	if s.Internal.GetAssetVideoFormat == nil {
+		return *new(types.AssetVideoFormat), ErrNotSupported
+	}
+	return s.Internal.GetAssetVideoFormat(p0, p1)
+}
+
+func (s *AssetAPIStub) GetAssetVideoFormat(p0 context.Context, p1 string) (types.AssetVideoFormat, error) {
+	return *new(types.AssetVideoFormat), ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetAssetsForNode(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeAssetRsp, error) {
 	if s.Internal.GetAssetsForNode == nil {
 		return nil, ErrNotSupported
@@ -650,6 +1415,39 @@ func (s *AssetAPIStub) GetAssetsForNode(p0 context.Context, p1 string, p2 int, p
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetDownloadSources(p0 context.Context, p1 string, p2 string) (*types.DownloadSources, error) {
+	if s.Internal.GetDownloadSources == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetDownloadSources(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) GetDownloadSources(p0 context.Context, p1 string, p2 string) (*types.DownloadSources, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) GetGatewayCertificate(p0 context.Context) (*types.GatewayCertificate, error) {
+	if s.Internal.GetGatewayCertificate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetGatewayCertificate(p0)
+}
+
+func (s *AssetAPIStub) GetGatewayCertificate(p0 context.Context) (*types.GatewayCertificate, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) GetNodeCacheComposition(p0 context.Context, p1 string) (*types.CacheComposition, error) {
+	if s.Internal.GetNodeCacheComposition == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeCacheComposition(p0, p1)
+}
+
+func (s *AssetAPIStub) GetNodeCacheComposition(p0 context.Context, p1 string) (*types.CacheComposition, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetReplicaEvents(p0 context.Context, p1 time.Time, p2 time.Time, p3 int, p4 int) (*types.ListReplicaEventRsp, error) {
 	if s.Internal.GetReplicaEvents == nil {
 		return nil, ErrNotSupported
@@ -672,6 +1470,17 @@ func (s *AssetAPIStub) GetReplicaEventsForNode(p0 context.Context, p1 string, p2
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetReplicaProgress(p0 context.Context, p1 string) (*types.ReplicaProgress, error) {
+	if s.Internal.GetReplicaProgress == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetReplicaProgress(p0, p1)
+}
+
+func (s *AssetAPIStub) GetReplicaProgress(p0 context.Context, p1 string) (*types.ReplicaProgress, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetReplicas(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListReplicaRsp, error) {
 	if s.Internal.GetReplicas == nil {
 		return nil, ErrNotSupported
@@ -694,92 +1503,268 @@ func (s *AssetAPIStub) GetReplicasForNode(p0 context.Context, p1 string, p2 int,
 	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) ListAssets(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) {
-	if s.Internal.ListAssets == nil {
+func (s *AssetAPIStruct) GetSeedingProgress(p0 context.Context, p1 string) (*types.SeedingProgress, error) {
+	if s.Internal.GetSeedingProgress == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.ListAssets(p0, p1, p2, p3, p4)
+	return s.Internal.GetSeedingProgress(p0, p1)
 }
 
-func (s *AssetAPIStub) ListAssets(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) {
+func (s *AssetAPIStub) GetSeedingProgress(p0 context.Context, p1 string) (*types.SeedingProgress, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) LoadAWSData(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) {
-	if s.Internal.LoadAWSData == nil {
-		return *new([]*types.AWSDataInfo), ErrNotSupported
+func (s *AssetAPIStruct) ImportDenylist(p0 context.Context, p1 string, p2 string, p3 string) (*types.DenylistImportResult, error) {
+	if s.Internal.ImportDenylist == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.LoadAWSData(p0, p1, p2, p3)
+	return s.Internal.ImportDenylist(p0, p1, p2, p3)
 }
 
-func (s *AssetAPIStub) LoadAWSData(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) {
-	return *new([]*types.AWSDataInfo), ErrNotSupported
+func (s *AssetAPIStub) ImportDenylist(p0 context.Context, p1 string, p2 string, p3 string) (*types.DenylistImportResult, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) MinioUploadFileEvent(p0 context.Context, p1 *types.MinioUploadFileEvent) error {
-	if s.Internal.MinioUploadFileEvent == nil {
+func (s *AssetAPIStruct) IngestAssetCompleted(p0 context.Context, p1 *types.IngestAssetResult) error {
+	if s.Internal.IngestAssetCompleted == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.MinioUploadFileEvent(p0, p1)
+	return s.Internal.IngestAssetCompleted(p0, p1)
 }
 
-func (s *AssetAPIStub) MinioUploadFileEvent(p0 context.Context, p1 *types.MinioUploadFileEvent) error {
+func (s *AssetAPIStub) IngestAssetCompleted(p0 context.Context, p1 *types.IngestAssetResult) error {
 	return ErrNotSupported
 }
 
-func (s *AssetAPIStruct) NodeRemoveAssetResult(p0 context.Context, p1 types.RemoveAssetResult) error {
-	if s.Internal.NodeRemoveAssetResult == nil {
-		return ErrNotSupported
+func (s *AssetAPIStruct) ListAssetGeoPolicies(p0 context.Context, p1 int, p2 int) (*types.ListAssetGeoPolicyRsp, error) {
+	if s.Internal.ListAssetGeoPolicies == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.NodeRemoveAssetResult(p0, p1)
+	return s.Internal.ListAssetGeoPolicies(p0, p1, p2)
 }
 
-func (s *AssetAPIStub) NodeRemoveAssetResult(p0 context.Context, p1 types.RemoveAssetResult) error {
-	return ErrNotSupported
+func (s *AssetAPIStub) ListAssetGeoPolicies(p0 context.Context, p1 int, p2 int) (*types.ListAssetGeoPolicyRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) PullAsset(p0 context.Context, p1 *types.PullAssetReq) error {
-	if s.Internal.PullAsset == nil {
-		return ErrNotSupported
+func (s *AssetAPIStruct) ListAssetGeoViolations(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListAssetGeoViolationRsp, error) {
+	if s.Internal.ListAssetGeoViolations == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.PullAsset(p0, p1)
+	return s.Internal.ListAssetGeoViolations(p0, p1, p2, p3)
 }
 
-func (s *AssetAPIStub) PullAsset(p0 context.Context, p1 *types.PullAssetReq) error {
-	return ErrNotSupported
+func (s *AssetAPIStub) ListAssetGeoViolations(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListAssetGeoViolationRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) RePullFailedAssets(p0 context.Context, p1 []types.AssetHash) error {
-	if s.Internal.RePullFailedAssets == nil {
-		return ErrNotSupported
+func (s *AssetAPIStruct) ListAssets(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) {
+	if s.Internal.ListAssets == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.RePullFailedAssets(p0, p1)
+	return s.Internal.ListAssets(p0, p1, p2, p3, p4)
 }
 
-func (s *AssetAPIStub) RePullFailedAssets(p0 context.Context, p1 []types.AssetHash) error {
-	return ErrNotSupported
+func (s *AssetAPIStub) ListAssets(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) RemoveAssetRecord(p0 context.Context, p1 string) error {
-	if s.Internal.RemoveAssetRecord == nil {
-		return ErrNotSupported
+func (s *AssetAPIStruct) ListColdAssets(p0 context.Context, p1 types.ColdAssetState, p2 int, p3 int) (*types.ListColdAssetsRsp, error) {
+	if s.Internal.ListColdAssets == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.RemoveAssetRecord(p0, p1)
+	return s.Internal.ListColdAssets(p0, p1, p2, p3)
 }
 
-func (s *AssetAPIStub) RemoveAssetRecord(p0 context.Context, p1 string) error {
-	return ErrNotSupported
+func (s *AssetAPIStub) ListColdAssets(p0 context.Context, p1 types.ColdAssetState, p2 int, p3 int) (*types.ListColdAssetsRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *AssetAPIStruct) RemoveAssetReplica(p0 context.Context, p1 string, p2 string) error {
-	if s.Internal.RemoveAssetReplica == nil {
-		return ErrNotSupported
+func (s *AssetAPIStruct) ListDenylist(p0 context.Context, p1 int, p2 int) (*types.ListDenylistRsp, error) {
+	if s.Internal.ListDenylist == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.RemoveAssetReplica(p0, p1, p2)
+	return s.Internal.ListDenylist(p0, p1, p2)
 }
 
-func (s *AssetAPIStub) RemoveAssetReplica(p0 context.Context, p1 string, p2 string) error {
-	return ErrNotSupported
+func (s *AssetAPIStub) ListDenylist(p0 context.Context, p1 int, p2 int) (*types.ListDenylistRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) ListDenylistEvents(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListDenylistEventsRsp, error) {
+	if s.Internal.ListDenylistEvents == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListDenylistEvents(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) ListDenylistEvents(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListDenylistEventsRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) ListGatewayHosts(p0 context.Context) ([]*types.GatewayHost, error) {
+	if s.Internal.ListGatewayHosts == nil {
+		return *new([]*types.GatewayHost), ErrNotSupported
+	}
+	return s.Internal.ListGatewayHosts(p0)
+}
+
+func (s *AssetAPIStub) ListGatewayHosts(p0 context.Context) ([]*types.GatewayHost, error) {
+	return *new([]*types.GatewayHost), ErrNotSupported
+}
+
+func (s *AssetAPIStruct) ListSegmentStats(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListSegmentStatsRsp, error) {
+	if s.Internal.ListSegmentStats == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListSegmentStats(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) ListSegmentStats(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListSegmentStatsRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) LoadAWSData(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) {
+	if s.Internal.LoadAWSData == nil {
+		return *new([]*types.AWSDataInfo), ErrNotSupported
+	}
+	return s.Internal.LoadAWSData(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) LoadAWSData(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) {
+	return *new([]*types.AWSDataInfo), ErrNotSupported
+}
+
+func (s *AssetAPIStruct) MarkAssetCacheable(p0 context.Context, p1 string, p2 bool) error {
+	if s.Internal.MarkAssetCacheable == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.MarkAssetCacheable(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) MarkAssetCacheable(p0 context.Context, p1 string, p2 bool) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) MinioUploadFileEvent(p0 context.Context, p1 *types.MinioUploadFileEvent) error {
+	if s.Internal.MinioUploadFileEvent == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.MinioUploadFileEvent(p0, p1)
+}
+
+func (s *AssetAPIStub) MinioUploadFileEvent(p0 context.Context, p1 *types.MinioUploadFileEvent) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) NodeRemoveAssetResult(p0 context.Context, p1 types.RemoveAssetResult) error {
+	if s.Internal.NodeRemoveAssetResult == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NodeRemoveAssetResult(p0, p1)
+}
+
+func (s *AssetAPIStub) NodeRemoveAssetResult(p0 context.Context, p1 types.RemoveAssetResult) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) OffloadAsset(p0 context.Context, p1 string) error {
+	if s.Internal.OffloadAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.OffloadAsset(p0, p1)
+}
+
+func (s *AssetAPIStub) OffloadAsset(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) PullAsset(p0 context.Context, p1 *types.PullAssetReq) error {
+	if s.Internal.PullAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PullAsset(p0, p1)
+}
+
+func (s *AssetAPIStub) PullAsset(p0 context.Context, p1 *types.PullAssetReq) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RePullFailedAssets(p0 context.Context, p1 []types.AssetHash) error {
+	if s.Internal.RePullFailedAssets == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RePullFailedAssets(p0, p1)
+}
+
+func (s *AssetAPIStub) RePullFailedAssets(p0 context.Context, p1 []types.AssetHash) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RemoveAssetACL(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.RemoveAssetACL == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAssetACL(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) RemoveAssetACL(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RemoveAssetGeoPolicy(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveAssetGeoPolicy == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAssetGeoPolicy(p0, p1)
+}
+
+func (s *AssetAPIStub) RemoveAssetGeoPolicy(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RemoveAssetRecord(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveAssetRecord == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAssetRecord(p0, p1)
+}
+
+func (s *AssetAPIStub) RemoveAssetRecord(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RemoveAssetReplica(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.RemoveAssetReplica == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveAssetReplica(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) RemoveAssetReplica(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RemoveFromDenylist(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveFromDenylist == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveFromDenylist(p0, p1)
+}
+
+func (s *AssetAPIStub) RemoveFromDenylist(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RemoveGatewayAsset(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveGatewayAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveGatewayAsset(p0, p1)
+}
+
+func (s *AssetAPIStub) RemoveGatewayAsset(p0 context.Context, p1 string) error {
+	return ErrNotSupported
 }
 
 func (s *AssetAPIStruct) RemoveNodeFailedReplica(p0 context.Context) (error) {
@@ -793,6 +1778,72 @@ func (s *AssetAPIStub) RemoveNodeFailedReplica(p0 context.Context) (error) {
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) ReportSegmentHits(p0 context.Context, p1 []*types.SegmentHits) error {
+	if s.Internal.ReportSegmentHits == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ReportSegmentHits(p0, p1)
+}
+
+func (s *AssetAPIStub) ReportSegmentHits(p0 context.Context, p1 []*types.SegmentHits) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RestoreAsset(p0 context.Context, p1 string) error {
+	if s.Internal.RestoreAsset == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RestoreAsset(p0, p1)
+}
+
+func (s *AssetAPIStub) RestoreAsset(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SearchAssets(p0 context.Context, p1 *types.SearchAssetsReq) (*types.SearchAssetsRsp, error) {
+	if s.Internal.SearchAssets == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SearchAssets(p0, p1)
+}
+
+func (s *AssetAPIStub) SearchAssets(p0 context.Context, p1 *types.SearchAssetsReq) (*types.SearchAssetsRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SetAssetACL(p0 context.Context, p1 string, p2 *types.AssetACL) error {
+	if s.Internal.SetAssetACL == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetAssetACL(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) SetAssetACL(p0 context.Context, p1 string, p2 *types.AssetACL) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SetAssetGeoPolicy(p0 context.Context, p1 string, p2 types.AssetGeoRules) error {
+	if s.Internal.SetAssetGeoPolicy == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetAssetGeoPolicy(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) SetAssetGeoPolicy(p0 context.Context, p1 string, p2 types.AssetGeoRules) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SetNodeCacheConfig(p0 context.Context, p1 string, p2 *types.CacheConfig) error {
+	if s.Internal.SetNodeCacheConfig == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeCacheConfig(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) SetNodeCacheConfig(p0 context.Context, p1 string, p2 *types.CacheConfig) error {
+	return ErrNotSupported
+}
+
 func (s *AssetAPIStruct) ShareAssets(p0 context.Context, p1 string, p2 []string) (map[string]string, error) {
 	if s.Internal.ShareAssets == nil {
 		return *new(map[string]string), ErrNotSupported
@@ -837,6 +1888,28 @@ func (s *AssetAPIStub) UpdateAssetExpiration(p0 context.Context, p1 string, p2 t
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) UpdateAssetQoSTier(p0 context.Context, p1 string, p2 types.AssetQoSTier) error {
+	if s.Internal.UpdateAssetQoSTier == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UpdateAssetQoSTier(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) UpdateAssetQoSTier(p0 context.Context, p1 string, p2 types.AssetQoSTier) error {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) UpdateAssetVideoFormat(p0 context.Context, p1 string, p2 types.AssetVideoFormat) error {
+	if s.Internal.UpdateAssetVideoFormat == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UpdateAssetVideoFormat(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) UpdateAssetVideoFormat(p0 context.Context, p1 string, p2 types.AssetVideoFormat) error {
+	return ErrNotSupported
+}
+
 func (s *AssetAPIStruct) UpdateShareStatus(p0 context.Context, p1 string, p2 string) error {
 	if s.Internal.UpdateShareStatus == nil {
 		return ErrNotSupported
@@ -848,6 +1921,28 @@ func (s *AssetAPIStub) UpdateShareStatus(p0 context.Context, p1 string, p2 strin
 	return ErrNotSupported
 }
 
+func (s *CandidateStruct) AddRelaySession(p0 context.Context, p1 *types.RelaySession) error {
+	if s.Internal.AddRelaySession == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.AddRelaySession(p0, p1)
+}
+
+func (s *CandidateStub) AddRelaySession(p0 context.Context, p1 *types.RelaySession) error {
+	return ErrNotSupported
+}
+
+func (s *CandidateStruct) ApplyNodeConfig(p0 context.Context, p1 *types.NodeConfigPush) (*types.NodeConfigAck, error) {
+	if s.Internal.ApplyNodeConfig == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ApplyNodeConfig(p0, p1)
+}
+
+func (s *CandidateStub) ApplyNodeConfig(p0 context.Context, p1 *types.NodeConfigPush) (*types.NodeConfigAck, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *CandidateStruct) CheckNetworkConnectivity(p0 context.Context, p1 string, p2 string) error {
 	if s.Internal.CheckNetworkConnectivity == nil {
 		return ErrNotSupported
@@ -859,6 +1954,17 @@ func (s *CandidateStub) CheckNetworkConnectivity(p0 context.Context, p1 string,
 	return ErrNotSupported
 }
 
+func (s *CandidateStruct) CollectDiagnostics(p0 context.Context, p1 *types.NodeDiagnosticsReq) ([]byte, error) {
+	if s.Internal.CollectDiagnostics == nil {
+		return *new([]byte), ErrNotSupported
+	}
+	return s.Internal.CollectDiagnostics(p0, p1)
+}
+
+func (s *CandidateStub) CollectDiagnostics(p0 context.Context, p1 *types.NodeDiagnosticsReq) ([]byte, error) {
+	return *new([]byte), ErrNotSupported
+}
+
 func (s *CandidateStruct) GetBlocksWithAssetCID(p0 context.Context, p1 string, p2 int64, p3 int) ([]string, error) {
 	if s.Internal.GetBlocksWithAssetCID == nil {
 		return *new([]string), ErrNotSupported
@@ -892,6 +1998,39 @@ func (s *CandidateStub) GetMinioConfig(p0 context.Context) (*types.MinioConfig,
 	return nil, ErrNotSupported
 }
 
+func (s *CandidateStruct) StartLogStream(p0 context.Context, p1 *types.LogStreamReq) error {
+	if s.Internal.StartLogStream == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.StartLogStream(p0, p1)
+}
+
+func (s *CandidateStub) StartLogStream(p0 context.Context, p1 *types.LogStreamReq) error {
+	return ErrNotSupported
+}
+
+func (s *CandidateStruct) StopLogStream(p0 context.Context, p1 string) error {
+	if s.Internal.StopLogStream == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.StopLogStream(p0, p1)
+}
+
+func (s *CandidateStub) StopLogStream(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *CandidateStruct) Upgrade(p0 context.Context, p1 *types.UpgradeCommand) error {
+	if s.Internal.Upgrade == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.Upgrade(p0, p1)
+}
+
+func (s *CandidateStub) Upgrade(p0 context.Context, p1 *types.UpgradeCommand) error {
+	return ErrNotSupported
+}
+
 func (s *CandidateStruct) WaitQuiet(p0 context.Context) error {
 	if s.Internal.WaitQuiet == nil {
 		return ErrNotSupported
@@ -1057,37 +2196,136 @@ func (s *DeviceStub) GetNodeInfo(p0 context.Context) (types.NodeInfo, error) {
 	return *new(types.NodeInfo), ErrNotSupported
 }
 
-func (s *EdgeStruct) ExternalServiceAddress(p0 context.Context, p1 string) (string, error) {
-	if s.Internal.ExternalServiceAddress == nil {
-		return "", ErrNotSupported
+func (s *DeviceStruct) RunHardwareChallenge(p0 context.Context, p1 *types.HardwareChallenge) (*types.HardwareChallengeResult, error) {
+	if s.Internal.RunHardwareChallenge == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.ExternalServiceAddress(p0, p1)
+	return s.Internal.RunHardwareChallenge(p0, p1)
 }
 
-func (s *EdgeStub) ExternalServiceAddress(p0 context.Context, p1 string) (string, error) {
-	return "", ErrNotSupported
+func (s *DeviceStub) RunHardwareChallenge(p0 context.Context, p1 *types.HardwareChallenge) (*types.HardwareChallengeResult, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *EdgeStruct) GetEdgeOnlineStateFromScheduler(p0 context.Context) (bool, error) {
-	if s.Internal.GetEdgeOnlineStateFromScheduler == nil {
-		return false, ErrNotSupported
+func (s *EdgeStruct) ApplyNodeConfig(p0 context.Context, p1 *types.NodeConfigPush) (*types.NodeConfigAck, error) {
+	if s.Internal.ApplyNodeConfig == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.GetEdgeOnlineStateFromScheduler(p0)
+	return s.Internal.ApplyNodeConfig(p0, p1)
 }
 
-func (s *EdgeStub) GetEdgeOnlineStateFromScheduler(p0 context.Context) (bool, error) {
-	return false, ErrNotSupported
+func (s *EdgeStub) ApplyNodeConfig(p0 context.Context, p1 *types.NodeConfigPush) (*types.NodeConfigAck, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *EdgeStruct) UserNATPunch(p0 context.Context, p1 string, p2 *types.NatPunchReq) error {
-	if s.Internal.UserNATPunch == nil {
-		return ErrNotSupported
+func (s *EdgeStruct) BandwidthTest(p0 context.Context, p1 *types.BandwidthTestReq) ([]*types.BandwidthTestReport, error) {
+	if s.Internal.BandwidthTest == nil {
+		return *new([]*types.BandwidthTestReport), ErrNotSupported
 	}
-	return s.Internal.UserNATPunch(p0, p1, p2)
+	return s.Internal.BandwidthTest(p0, p1)
 }
 
-func (s *EdgeStub) UserNATPunch(p0 context.Context, p1 string, p2 *types.NatPunchReq) error {
-	return ErrNotSupported
+func (s *EdgeStub) BandwidthTest(p0 context.Context, p1 *types.BandwidthTestReq) ([]*types.BandwidthTestReport, error) {
+	return *new([]*types.BandwidthTestReport), ErrNotSupported
+}
+
+func (s *EdgeStruct) CollectDiagnostics(p0 context.Context, p1 *types.NodeDiagnosticsReq) ([]byte, error) {
+	if s.Internal.CollectDiagnostics == nil {
+		return *new([]byte), ErrNotSupported
+	}
+	return s.Internal.CollectDiagnostics(p0, p1)
+}
+
+func (s *EdgeStub) CollectDiagnostics(p0 context.Context, p1 *types.NodeDiagnosticsReq) ([]byte, error) {
+	return *new([]byte), ErrNotSupported
+}
+
+func (s *EdgeStruct) ExternalServiceAddress(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.ExternalServiceAddress == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.ExternalServiceAddress(p0, p1)
+}
+
+func (s *EdgeStub) ExternalServiceAddress(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *EdgeStruct) GetEdgeOnlineStateFromScheduler(p0 context.Context) (bool, error) {
+	if s.Internal.GetEdgeOnlineStateFromScheduler == nil {
+		return false, ErrNotSupported
+	}
+	return s.Internal.GetEdgeOnlineStateFromScheduler(p0)
+}
+
+func (s *EdgeStub) GetEdgeOnlineStateFromScheduler(p0 context.Context) (bool, error) {
+	return false, ErrNotSupported
+}
+
+func (s *EdgeStruct) SetUploadLimit(p0 context.Context, p1 int64) error {
+	if s.Internal.SetUploadLimit == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetUploadLimit(p0, p1)
+}
+
+func (s *EdgeStub) SetUploadLimit(p0 context.Context, p1 int64) error {
+	return ErrNotSupported
+}
+
+func (s *EdgeStruct) StartBandwidthTest(p0 context.Context) (*types.BandwidthTest, error) {
+	if s.Internal.StartBandwidthTest == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StartBandwidthTest(p0)
+}
+
+func (s *EdgeStub) StartBandwidthTest(p0 context.Context) (*types.BandwidthTest, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *EdgeStruct) StartLogStream(p0 context.Context, p1 *types.LogStreamReq) error {
+	if s.Internal.StartLogStream == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.StartLogStream(p0, p1)
+}
+
+func (s *EdgeStub) StartLogStream(p0 context.Context, p1 *types.LogStreamReq) error {
+	return ErrNotSupported
+}
+
+func (s *EdgeStruct) StopLogStream(p0 context.Context, p1 string) error {
+	if s.Internal.StopLogStream == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.StopLogStream(p0, p1)
+}
+
+func (s *EdgeStub) StopLogStream(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *EdgeStruct) Upgrade(p0 context.Context, p1 *types.UpgradeCommand) error {
+	if s.Internal.Upgrade == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.Upgrade(p0, p1)
+}
+
+func (s *EdgeStub) Upgrade(p0 context.Context, p1 *types.UpgradeCommand) error {
+	return ErrNotSupported
+}
+
+func (s *EdgeStruct) UserNATPunch(p0 context.Context, p1 string, p2 *types.NatPunchReq) error {
+	if s.Internal.UserNATPunch == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UserNATPunch(p0, p1, p2)
+}
+
+func (s *EdgeStub) UserNATPunch(p0 context.Context, p1 string, p2 *types.NatPunchReq) error {
+	return ErrNotSupported
 }
 
 func (s *EdgeStruct) WaitQuiet(p0 context.Context) error {
@@ -1101,6 +2339,17 @@ func (s *EdgeStub) WaitQuiet(p0 context.Context) error {
 	return ErrNotSupported
 }
 
+func (s *EdgeStruct) WebRTCOffer(p0 context.Context, p1 *types.WebRTCOffer) (*types.WebRTCAnswer, error) {
+	if s.Internal.WebRTCOffer == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.WebRTCOffer(p0, p1)
+}
+
+func (s *EdgeStub) WebRTCOffer(p0 context.Context, p1 *types.WebRTCOffer) (*types.WebRTCAnswer, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *LocatorStruct) CandidateDownloadInfos(p0 context.Context, p1 string) ([]*types.CandidateDownloadInfo, error) {
 	if s.Internal.CandidateDownloadInfos == nil {
 		return *new([]*types.CandidateDownloadInfo), ErrNotSupported
@@ -1123,6 +2372,28 @@ func (s *LocatorStub) EdgeDownloadInfos(p0 context.Context, p1 string) ([]*types
 	return *new([]*types.EdgeDownloadInfoList), ErrNotSupported
 }
 
+func (s *LocatorStruct) GatewayNodes(p0 context.Context, p1 string) ([]*types.GatewayNode, error) {
+	if s.Internal.GatewayNodes == nil {
+		return *new([]*types.GatewayNode), ErrNotSupported
+	}
+	return s.Internal.GatewayNodes(p0, p1)
+}
+
+func (s *LocatorStub) GatewayNodes(p0 context.Context, p1 string) ([]*types.GatewayNode, error) {
+	return *new([]*types.GatewayNode), ErrNotSupported
+}
+
+func (s *LocatorStruct) GatewayToken(p0 context.Context, p1 string, p2 *types.GatewayNode) (*types.Token, error) {
+	if s.Internal.GatewayToken == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GatewayToken(p0, p1, p2)
+}
+
+func (s *LocatorStub) GatewayToken(p0 context.Context, p1 string, p2 *types.GatewayNode) (*types.Token, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *LocatorStruct) GetAccessPoints(p0 context.Context, p1 string, p2 string) ([]string, error) {
 	if s.Internal.GetAccessPoints == nil {
 		return *new([]string), ErrNotSupported
@@ -1145,6 +2416,17 @@ func (s *LocatorStub) GetCandidateIP(p0 context.Context, p1 string) (string, err
 	return "", ErrNotSupported
 }
 
+func (s *LocatorStruct) GetRegionStats(p0 context.Context, p1 string) ([]*types.RegionStats, error) {
+	if s.Internal.GetRegionStats == nil {
+		return *new([]*types.RegionStats), ErrNotSupported
+	}
+	return s.Internal.GetRegionStats(p0, p1)
+}
+
+func (s *LocatorStub) GetRegionStats(p0 context.Context, p1 string) ([]*types.RegionStats, error) {
+	return *new([]*types.RegionStats), ErrNotSupported
+}
+
 func (s *LocatorStruct) GetSchedulerWithAPIKey(p0 context.Context, p1 string) (string, error) {
 	if s.Internal.GetSchedulerWithAPIKey == nil {
 		return "", ErrNotSupported
@@ -1178,6 +2460,61 @@ func (s *LocatorStub) GetUserAccessPoint(p0 context.Context, p1 string) (*Access
 	return nil, ErrNotSupported
 }
 
+func (s *NodeAPIStruct) AddMaintenanceWindow(p0 context.Context, p1 *types.MaintenanceWindow) (int64, error) {
+	if s.Internal.AddMaintenanceWindow == nil {
+		return 0, ErrNotSupported
+	}
+	return s.Internal.AddMaintenanceWindow(p0, p1)
+}
+
+func (s *NodeAPIStub) AddMaintenanceWindow(p0 context.Context, p1 *types.MaintenanceWindow) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) AddReleaseManifest(p0 context.Context, p1 *types.ReleaseManifest) error {
+	if s.Internal.AddReleaseManifest == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.AddReleaseManifest(p0, p1)
+}
+
+func (s *NodeAPIStub) AddReleaseManifest(p0 context.Context, p1 *types.ReleaseManifest) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) AppealPenalty(p0 context.Context, p1 int64, p2 string) error {
+	if s.Internal.AppealPenalty == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.AppealPenalty(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) AppealPenalty(p0 context.Context, p1 int64, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) CancelBulkJob(p0 context.Context, p1 string) error {
+	if s.Internal.CancelBulkJob == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.CancelBulkJob(p0, p1)
+}
+
+func (s *NodeAPIStub) CancelBulkJob(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) CancelJob(p0 context.Context, p1 string) error {
+	if s.Internal.CancelJob == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.CancelJob(p0, p1)
+}
+
+func (s *NodeAPIStub) CancelJob(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
 func (s *NodeAPIStruct) CandidateConnect(p0 context.Context, p1 *types.ConnectOptions) error {
 	if s.Internal.CandidateConnect == nil {
 		return ErrNotSupported
@@ -1200,6 +2537,17 @@ func (s *NodeAPIStub) CheckIpUsage(p0 context.Context, p1 string) (bool, error)
 	return false, ErrNotSupported
 }
 
+func (s *NodeAPIStruct) CorrectPointsEpoch(p0 context.Context, p1 *types.PointsCorrection) (int64, error) {
+	if s.Internal.CorrectPointsEpoch == nil {
+		return 0, ErrNotSupported
+	}
+	return s.Internal.CorrectPointsEpoch(p0, p1)
+}
+
+func (s *NodeAPIStub) CorrectPointsEpoch(p0 context.Context, p1 *types.PointsCorrection) (int64, error) {
+	return 0, ErrNotSupported
+}
+
 func (s *NodeAPIStruct) DeactivateNode(p0 context.Context, p1 string, p2 int) error {
 	if s.Internal.DeactivateNode == nil {
 		return ErrNotSupported
@@ -1211,6 +2559,17 @@ func (s *NodeAPIStub) DeactivateNode(p0 context.Context, p1 string, p2 int) erro
 	return ErrNotSupported
 }
 
+func (s *NodeAPIStruct) DeletePenaltyRule(p0 context.Context, p1 int64) error {
+	if s.Internal.DeletePenaltyRule == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeletePenaltyRule(p0, p1)
+}
+
+func (s *NodeAPIStub) DeletePenaltyRule(p0 context.Context, p1 int64) error {
+	return ErrNotSupported
+}
+
 func (s *NodeAPIStruct) DownloadDataResult(p0 context.Context, p1 string, p2 string, p3 int64) error {
 	if s.Internal.DownloadDataResult == nil {
 		return ErrNotSupported
@@ -1233,6 +2592,39 @@ func (s *NodeAPIStub) EdgeConnect(p0 context.Context, p1 *types.ConnectOptions)
 	return ErrNotSupported
 }
 
+func (s *NodeAPIStruct) EndMaintenanceWindow(p0 context.Context, p1 int64) error {
+	if s.Internal.EndMaintenanceWindow == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.EndMaintenanceWindow(p0, p1)
+}
+
+func (s *NodeAPIStub) EndMaintenanceWindow(p0 context.Context, p1 int64) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) EstimatePoints(p0 context.Context, p1 *types.PointsEstimateReq) (*types.PointsEstimateRsp, error) {
+	if s.Internal.EstimatePoints == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EstimatePoints(p0, p1)
+}
+
+func (s *NodeAPIStub) EstimatePoints(p0 context.Context, p1 *types.PointsEstimateReq) (*types.PointsEstimateRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ExportSettlement(p0 context.Context, p1 int64) error {
+	if s.Internal.ExportSettlement == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ExportSettlement(p0, p1)
+}
+
+func (s *NodeAPIStub) ExportSettlement(p0 context.Context, p1 int64) error {
+	return ErrNotSupported
+}
+
 func (s *NodeAPIStruct) GetAssetView(p0 context.Context, p1 string, p2 bool) (*types.AssetView, error) {
 	if s.Internal.GetAssetView == nil {
 		return nil, ErrNotSupported
@@ -1255,6 +2647,28 @@ func (s *NodeAPIStub) GetAssetsInBucket(p0 context.Context, p1 string, p2 int, p
 	return *new([]string), ErrNotSupported
 }
 
+func (s *NodeAPIStruct) GetBandwidthTest(p0 context.Context, p1 string) (*types.BandwidthTest, error) {
+	if s.Internal.GetBandwidthTest == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetBandwidthTest(p0, p1)
+}
+
+func (s *NodeAPIStub) GetBandwidthTest(p0 context.Context, p1 string) (*types.BandwidthTest, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetBulkJob(p0 context.Context, p1 string) (*types.BulkJob, error) {
+	if s.Internal.GetBulkJob == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetBulkJob(p0, p1)
+}
+
+func (s *NodeAPIStub) GetBulkJob(p0 context.Context, p1 string) (*types.BulkJob, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *NodeAPIStruct) GetCandidateDownloadInfos(p0 context.Context, p1 string) ([]*types.CandidateDownloadInfo, error) {
 	if s.Internal.GetCandidateDownloadInfos == nil {
 		return *new([]*types.CandidateDownloadInfo), ErrNotSupported
@@ -1299,6 +2713,50 @@ func (s *NodeAPIStub) GetCandidateURLsForDetectNat(p0 context.Context) ([]string
 	return *new([]string), ErrNotSupported
 }
 
+func (s *NodeAPIStruct) GetClientDownloadInfos(p0 context.Context, p1 string, p2 string) (*types.EdgeDownloadInfoList, error) {
+	if s.Internal.GetClientDownloadInfos == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetClientDownloadInfos(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetClientDownloadInfos(p0 context.Context, p1 string, p2 string) (*types.EdgeDownloadInfoList, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetDashboardStats(p0 context.Context) (*types.DashboardStats, error) {
+	if s.Internal.GetDashboardStats == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetDashboardStats(p0)
+}
+
+func (s *NodeAPIStub) GetDashboardStats(p0 context.Context) (*types.DashboardStats, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetDataExport(p0 context.Context, p1 string) (*types.DataExport, error) {
+	if s.Internal.GetDataExport == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetDataExport(p0, p1)
+}
+
+func (s *NodeAPIStub) GetDataExport(p0 context.Context, p1 string) (*types.DataExport, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetDataExportSchemas(p0 context.Context) ([]*types.DataExportSchema, error) {
+	if s.Internal.GetDataExportSchemas == nil {
+		return *new([]*types.DataExportSchema), ErrNotSupported
+	}
+	return s.Internal.GetDataExportSchemas(p0)
+}
+
+func (s *NodeAPIStub) GetDataExportSchemas(p0 context.Context) ([]*types.DataExportSchema, error) {
+	return *new([]*types.DataExportSchema), ErrNotSupported
+}
+
 func (s *NodeAPIStruct) GetEdgeDownloadInfos(p0 context.Context, p1 string) (*types.EdgeDownloadInfoList, error) {
 	if s.Internal.GetEdgeDownloadInfos == nil {
 		return nil, ErrNotSupported
@@ -1332,74 +2790,338 @@ func (s *NodeAPIStub) GetExternalAddress(p0 context.Context) (string, error) {
 	return "", ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetMinioConfigFromCandidate(p0 context.Context, p1 string) (*types.MinioConfig, error) {
-	if s.Internal.GetMinioConfigFromCandidate == nil {
+func (s *NodeAPIStruct) GetGatewayNodes(p0 context.Context, p1 string, p2 int) ([]*types.GatewayNode, error) {
+	if s.Internal.GetGatewayNodes == nil {
+		return *new([]*types.GatewayNode), ErrNotSupported
+	}
+	return s.Internal.GetGatewayNodes(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetGatewayNodes(p0 context.Context, p1 string, p2 int) ([]*types.GatewayNode, error) {
+	return *new([]*types.GatewayNode), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetGatewayToken(p0 context.Context, p1 string, p2 string) (*types.Token, error) {
+	if s.Internal.GetGatewayToken == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetMinioConfigFromCandidate(p0, p1)
+	return s.Internal.GetGatewayToken(p0, p1, p2)
 }
 
-func (s *NodeAPIStub) GetMinioConfigFromCandidate(p0 context.Context, p1 string) (*types.MinioConfig, error) {
+func (s *NodeAPIStub) GetGatewayToken(p0 context.Context, p1 string, p2 string) (*types.Token, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetNodeInfo(p0 context.Context, p1 string) (types.NodeInfo, error) {
-	if s.Internal.GetNodeInfo == nil {
-		return *new(types.NodeInfo), ErrNotSupported
+func (s *NodeAPIStruct) GetIPFamilyStats(p0 context.Context) ([]*types.IPFamilyStats, error) {
+	if s.Internal.GetIPFamilyStats == nil {
+		return *new([]*types.IPFamilyStats), ErrNotSupported
 	}
-	return s.Internal.GetNodeInfo(p0, p1)
+	return s.Internal.GetIPFamilyStats(p0)
 }
 
-func (s *NodeAPIStub) GetNodeInfo(p0 context.Context, p1 string) (types.NodeInfo, error) {
-	return *new(types.NodeInfo), ErrNotSupported
+func (s *NodeAPIStub) GetIPFamilyStats(p0 context.Context) ([]*types.IPFamilyStats, error) {
+	return *new([]*types.IPFamilyStats), ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetNodeList(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) {
-	if s.Internal.GetNodeList == nil {
+func (s *NodeAPIStruct) GetJob(p0 context.Context, p1 string) (*types.Job, error) {
+	if s.Internal.GetJob == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetNodeList(p0, p1, p2)
+	return s.Internal.GetJob(p0, p1)
 }
 
-func (s *NodeAPIStub) GetNodeList(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) {
+func (s *NodeAPIStub) GetJob(p0 context.Context, p1 string) (*types.Job, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetNodeOfIP(p0 context.Context, p1 string) ([]string, error) {
-	if s.Internal.GetNodeOfIP == nil {
-		return *new([]string), ErrNotSupported
+func (s *NodeAPIStruct) GetLeaderboard(p0 context.Context, p1 *types.LeaderboardReq) (*types.LeaderboardRsp, error) {
+	if s.Internal.GetLeaderboard == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.GetNodeOfIP(p0, p1)
+	return s.Internal.GetLeaderboard(p0, p1)
 }
 
-func (s *NodeAPIStub) GetNodeOfIP(p0 context.Context, p1 string) ([]string, error) {
-	return *new([]string), ErrNotSupported
+func (s *NodeAPIStub) GetLeaderboard(p0 context.Context, p1 *types.LeaderboardReq) (*types.LeaderboardRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetNodeOnlineState(p0 context.Context) (bool, error) {
-	if s.Internal.GetNodeOnlineState == nil {
-		return false, ErrNotSupported
+func (s *NodeAPIStruct) GetLocationIndexStats(p0 context.Context) (*types.LocationIndexStats, error) {
+	if s.Internal.GetLocationIndexStats == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.GetNodeOnlineState(p0)
+	return s.Internal.GetLocationIndexStats(p0)
 }
 
-func (s *NodeAPIStub) GetNodeOnlineState(p0 context.Context) (bool, error) {
-	return false, ErrNotSupported
+func (s *NodeAPIStub) GetLocationIndexStats(p0 context.Context) (*types.LocationIndexStats, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetNodeToken(p0 context.Context, p1 string) (string, error) {
-	if s.Internal.GetNodeToken == nil {
-		return "", ErrNotSupported
+func (s *NodeAPIStruct) GetMinioConfigFromCandidate(p0 context.Context, p1 string) (*types.MinioConfig, error) {
+	if s.Internal.GetMinioConfigFromCandidate == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.GetNodeToken(p0, p1)
+	return s.Internal.GetMinioConfigFromCandidate(p0, p1)
 }
 
-func (s *NodeAPIStub) GetNodeToken(p0 context.Context, p1 string) (string, error) {
-	return "", ErrNotSupported
+func (s *NodeAPIStub) GetMinioConfigFromCandidate(p0 context.Context, p1 string) (*types.MinioConfig, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) GetOnlineNodeCount(p0 context.Context, p1 types.NodeType) (int, error) {
-	if s.Internal.GetOnlineNodeCount == nil {
+func (s *NodeAPIStruct) GetNodeCommitment(p0 context.Context, p1 string) (*types.NodeCommitment, error) {
+	if s.Internal.GetNodeCommitment == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeCommitment(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeCommitment(p0 context.Context, p1 string) (*types.NodeCommitment, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeCommitmentRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeCommitmentRecordRsp, error) {
+	if s.Internal.GetNodeCommitmentRecords == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeCommitmentRecords(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetNodeCommitmentRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeCommitmentRecordRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeConfigDefaults(p0 context.Context, p1 types.NodeType) (*types.NodeConfig, error) {
+	if s.Internal.GetNodeConfigDefaults == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeConfigDefaults(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeConfigDefaults(p0 context.Context, p1 types.NodeType) (*types.NodeConfig, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeConfigStatus(p0 context.Context, p1 string) (*types.NodeConfigStatus, error) {
+	if s.Internal.GetNodeConfigStatus == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeConfigStatus(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeConfigStatus(p0 context.Context, p1 string) (*types.NodeConfigStatus, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeDiagnostics(p0 context.Context, p1 string) (*types.NodeDiagnostics, error) {
+	if s.Internal.GetNodeDiagnostics == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeDiagnostics(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeDiagnostics(p0 context.Context, p1 string) (*types.NodeDiagnostics, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeDiagnosticsData(p0 context.Context, p1 string) ([]byte, error) {
+	if s.Internal.GetNodeDiagnosticsData == nil {
+		return *new([]byte), ErrNotSupported
+	}
+	return s.Internal.GetNodeDiagnosticsData(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeDiagnosticsData(p0 context.Context, p1 string) ([]byte, error) {
+	return *new([]byte), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeDiskHealth(p0 context.Context, p1 string) (*types.NodeDiskHealth, error) {
+	if s.Internal.GetNodeDiskHealth == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeDiskHealth(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeDiskHealth(p0 context.Context, p1 string) (*types.NodeDiskHealth, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeInfo(p0 context.Context, p1 string) (types.NodeInfo, error) {
+	if s.Internal.GetNodeInfo == nil {
+		return *new(types.NodeInfo), ErrNotSupported
+	}
+	return s.Internal.GetNodeInfo(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeInfo(p0 context.Context, p1 string) (types.NodeInfo, error) {
+	return *new(types.NodeInfo), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeKeyTypes(p0 context.Context) ([]string, error) {
+	if s.Internal.GetNodeKeyTypes == nil {
+		return *new([]string), ErrNotSupported
+	}
+	return s.Internal.GetNodeKeyTypes(p0)
+}
+
+func (s *NodeAPIStub) GetNodeKeyTypes(p0 context.Context) ([]string, error) {
+	return *new([]string), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeList(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) {
+	if s.Internal.GetNodeList == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeList(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetNodeList(p0 context.Context, p1 int, p2 int) (*types.ListNodesRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeMaintenanceWindows(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListMaintenanceWindowRsp, error) {
+	if s.Internal.GetNodeMaintenanceWindows == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeMaintenanceWindows(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetNodeMaintenanceWindows(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListMaintenanceWindowRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeOfIP(p0 context.Context, p1 string) ([]string, error) {
+	if s.Internal.GetNodeOfIP == nil {
+		return *new([]string), ErrNotSupported
+	}
+	return s.Internal.GetNodeOfIP(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeOfIP(p0 context.Context, p1 string) ([]string, error) {
+	return *new([]string), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeOnlineState(p0 context.Context) (bool, error) {
+	if s.Internal.GetNodeOnlineState == nil {
+		return false, ErrNotSupported
+	}
+	return s.Internal.GetNodeOnlineState(p0)
+}
+
+func (s *NodeAPIStub) GetNodeOnlineState(p0 context.Context) (bool, error) {
+	return false, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeOperations(p0 context.Context, p1 string) (*types.NodeOperations, error) {
+	if s.Internal.GetNodeOperations == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeOperations(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeOperations(p0 context.Context, p1 string) (*types.NodeOperations, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodePenalties(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListPenaltyRsp, error) {
+	if s.Internal.GetNodePenalties == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodePenalties(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetNodePenalties(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListPenaltyRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodePointsEpochs(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.NodePointsEpochsRsp, error) {
+	if s.Internal.GetNodePointsEpochs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodePointsEpochs(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetNodePointsEpochs(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.NodePointsEpochsRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeTasks(p0 context.Context, p1 string) ([]*types.NodeTask, error) {
+	if s.Internal.GetNodeTasks == nil {
+		return *new([]*types.NodeTask), ErrNotSupported
+	}
+	return s.Internal.GetNodeTasks(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeTasks(p0 context.Context, p1 string) ([]*types.NodeTask, error) {
+	return *new([]*types.NodeTask), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeToken(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.GetNodeToken == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.GetNodeToken(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeToken(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeTrafficDaily(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) ([]*types.NodeTrafficDaily, error) {
+	if s.Internal.GetNodeTrafficDaily == nil {
+		return *new([]*types.NodeTrafficDaily), ErrNotSupported
+	}
+	return s.Internal.GetNodeTrafficDaily(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetNodeTrafficDaily(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) ([]*types.NodeTrafficDaily, error) {
+	return *new([]*types.NodeTrafficDaily), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeTrafficStatement(p0 context.Context, p1 string, p2 string) (*types.NodeTrafficStatement, error) {
+	if s.Internal.GetNodeTrafficStatement == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeTrafficStatement(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetNodeTrafficStatement(p0 context.Context, p1 string, p2 string) (*types.NodeTrafficStatement, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeTrust(p0 context.Context, p1 string) (*types.NodeTrust, error) {
+	if s.Internal.GetNodeTrust == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeTrust(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeTrust(p0 context.Context, p1 string) (*types.NodeTrust, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeTrustTransitions(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListTrustTransitionRsp, error) {
+	if s.Internal.GetNodeTrustTransitions == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeTrustTransitions(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetNodeTrustTransitions(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListTrustTransitionRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetNodeUploadLimit(p0 context.Context, p1 string) (*types.UploadLimit, error) {
+	if s.Internal.GetNodeUploadLimit == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetNodeUploadLimit(p0, p1)
+}
+
+func (s *NodeAPIStub) GetNodeUploadLimit(p0 context.Context, p1 string) (*types.UploadLimit, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetOnlineNodeCount(p0 context.Context, p1 types.NodeType) (int, error) {
+	if s.Internal.GetOnlineNodeCount == nil {
 		return 0, ErrNotSupported
 	}
 	return s.Internal.GetOnlineNodeCount(p0, p1)
@@ -1409,303 +3131,1546 @@ func (s *NodeAPIStub) GetOnlineNodeCount(p0 context.Context, p1 types.NodeType)
 	return 0, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) NatPunch(p0 context.Context, p1 *types.NatPunchReq) error {
-	if s.Internal.NatPunch == nil {
-		return ErrNotSupported
+func (s *NodeAPIStruct) GetParallelDownloadPlan(p0 context.Context, p1 *types.ParallelDownloadReq) (*types.ParallelDownloadPlan, error) {
+	if s.Internal.GetParallelDownloadPlan == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.NatPunch(p0, p1)
+	return s.Internal.GetParallelDownloadPlan(p0, p1)
 }
 
-func (s *NodeAPIStub) NatPunch(p0 context.Context, p1 *types.NatPunchReq) error {
-	return ErrNotSupported
+func (s *NodeAPIStub) GetParallelDownloadPlan(p0 context.Context, p1 *types.ParallelDownloadReq) (*types.ParallelDownloadPlan, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) NodeExists(p0 context.Context, p1 string) error {
-	if s.Internal.NodeExists == nil {
-		return ErrNotSupported
+func (s *NodeAPIStruct) GetPenaltyRules(p0 context.Context) ([]*types.PenaltyRule, error) {
+	if s.Internal.GetPenaltyRules == nil {
+		return *new([]*types.PenaltyRule), ErrNotSupported
+	}
+	return s.Internal.GetPenaltyRules(p0)
+}
+
+func (s *NodeAPIStub) GetPenaltyRules(p0 context.Context) ([]*types.PenaltyRule, error) {
+	return *new([]*types.PenaltyRule), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetPointsEpochs(p0 context.Context, p1 int, p2 int) (*types.ListPointsEpochRsp, error) {
+	if s.Internal.GetPointsEpochs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetPointsEpochs(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetPointsEpochs(p0 context.Context, p1 int, p2 int) (*types.ListPointsEpochRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetPointsLeaderboard(p0 context.Context, p1 int) ([]*types.NodePointsRank, error) {
+	if s.Internal.GetPointsLeaderboard == nil {
+		return *new([]*types.NodePointsRank), ErrNotSupported
+	}
+	return s.Internal.GetPointsLeaderboard(p0, p1)
+}
+
+func (s *NodeAPIStub) GetPointsLeaderboard(p0 context.Context, p1 int) ([]*types.NodePointsRank, error) {
+	return *new([]*types.NodePointsRank), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetRegionStats(p0 context.Context) (*types.RegionStats, error) {
+	if s.Internal.GetRegionStats == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetRegionStats(p0)
+}
+
+func (s *NodeAPIStub) GetRegionStats(p0 context.Context) (*types.RegionStats, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetRelaySession(p0 context.Context) (*types.RelaySession, error) {
+	if s.Internal.GetRelaySession == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetRelaySession(p0)
+}
+
+func (s *NodeAPIStub) GetRelaySession(p0 context.Context) (*types.RelaySession, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetRelaySessions(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRelaySessionRsp, error) {
+	if s.Internal.GetRelaySessions == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetRelaySessions(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetRelaySessions(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRelaySessionRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetReportRejections(p0 context.Context, p1 string) ([]*types.ReportRejection, error) {
+	if s.Internal.GetReportRejections == nil {
+		return *new([]*types.ReportRejection), ErrNotSupported
+	}
+	return s.Internal.GetReportRejections(p0, p1)
+}
+
+func (s *NodeAPIStub) GetReportRejections(p0 context.Context, p1 string) ([]*types.ReportRejection, error) {
+	return *new([]*types.ReportRejection), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetRetrievalSLAReport(p0 context.Context, p1 types.RetrievalSLAGroup, p2 time.Time, p3 time.Time, p4 int, p5 int) (*types.RetrievalSLAReport, error) {
+	if s.Internal.GetRetrievalSLAReport == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetRetrievalSLAReport(p0, p1, p2, p3, p4, p5)
+}
+
+func (s *NodeAPIStub) GetRetrievalSLAReport(p0 context.Context, p1 types.RetrievalSLAGroup, p2 time.Time, p3 time.Time, p4 int, p5 int) (*types.RetrievalSLAReport, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetSchedulerPeers(p0 context.Context) ([]*types.SchedulerCfg, error) {
+	if s.Internal.GetSchedulerPeers == nil {
+		return *new([]*types.SchedulerCfg), ErrNotSupported
+	}
+	return s.Internal.GetSchedulerPeers(p0)
+}
+
+func (s *NodeAPIStub) GetSchedulerPeers(p0 context.Context) ([]*types.SchedulerCfg, error) {
+	return *new([]*types.SchedulerCfg), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetSettlementEpochs(p0 context.Context, p1 int, p2 int) (*types.ListSettlementEpochRsp, error) {
+	if s.Internal.GetSettlementEpochs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetSettlementEpochs(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetSettlementEpochs(p0 context.Context, p1 int, p2 int) (*types.ListSettlementEpochRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetSettlementProof(p0 context.Context, p1 int64, p2 string) (*types.SettlementProof, error) {
+	if s.Internal.GetSettlementProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetSettlementProof(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) GetSettlementProof(p0 context.Context, p1 int64, p2 string) (*types.SettlementProof, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetSettlementShares(p0 context.Context, p1 int64, p2 int, p3 int) (*types.ListSettlementShareRsp, error) {
+	if s.Internal.GetSettlementShares == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetSettlementShares(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetSettlementShares(p0 context.Context, p1 int64, p2 int, p3 int) (*types.ListSettlementShareRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetTrafficStatements(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeTrafficStatementRsp, error) {
+	if s.Internal.GetTrafficStatements == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetTrafficStatements(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) GetTrafficStatements(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeTrafficStatementRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetTransferProtocolStats(p0 context.Context, p1 string) ([]*types.TransferProtocolStats, error) {
+	if s.Internal.GetTransferProtocolStats == nil {
+		return *new([]*types.TransferProtocolStats), ErrNotSupported
+	}
+	return s.Internal.GetTransferProtocolStats(p0, p1)
+}
+
+func (s *NodeAPIStub) GetTransferProtocolStats(p0 context.Context, p1 string) ([]*types.TransferProtocolStats, error) {
+	return *new([]*types.TransferProtocolStats), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetUpgradeRollout(p0 context.Context, p1 string) (*types.UpgradeRollout, error) {
+	if s.Internal.GetUpgradeRollout == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetUpgradeRollout(p0, p1)
+}
+
+func (s *NodeAPIStub) GetUpgradeRollout(p0 context.Context, p1 string) (*types.UpgradeRollout, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetUpgradeWaveStats(p0 context.Context, p1 string) ([]*types.UpgradeWaveStats, error) {
+	if s.Internal.GetUpgradeWaveStats == nil {
+		return *new([]*types.UpgradeWaveStats), ErrNotSupported
+	}
+	return s.Internal.GetUpgradeWaveStats(p0, p1)
+}
+
+func (s *NodeAPIStub) GetUpgradeWaveStats(p0 context.Context, p1 string) ([]*types.UpgradeWaveStats, error) {
+	return *new([]*types.UpgradeWaveStats), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetVersionAdoption(p0 context.Context, p1 types.NodeType) ([]*types.VersionAdoption, error) {
+	if s.Internal.GetVersionAdoption == nil {
+		return *new([]*types.VersionAdoption), ErrNotSupported
+	}
+	return s.Internal.GetVersionAdoption(p0, p1)
+}
+
+func (s *NodeAPIStub) GetVersionAdoption(p0 context.Context, p1 types.NodeType) ([]*types.VersionAdoption, error) {
+	return *new([]*types.VersionAdoption), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetWebRTCConfig(p0 context.Context) (*types.WebRTCConfig, error) {
+	if s.Internal.GetWebRTCConfig == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetWebRTCConfig(p0)
+}
+
+func (s *NodeAPIStub) GetWebRTCConfig(p0 context.Context) (*types.WebRTCConfig, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) GetZoneStats(p0 context.Context) ([]*types.RegionStats, error) {
+	if s.Internal.GetZoneStats == nil {
+		return *new([]*types.RegionStats), ErrNotSupported
+	}
+	return s.Internal.GetZoneStats(p0)
+}
+
+func (s *NodeAPIStub) GetZoneStats(p0 context.Context) ([]*types.RegionStats, error) {
+	return *new([]*types.RegionStats), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) HaltUpgradeRollout(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.HaltUpgradeRollout == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.HaltUpgradeRollout(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) HaltUpgradeRollout(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) IssueNodeCertificate(p0 context.Context, p1 string, p2 string, p3 []byte) (*types.NodeCertificate, error) {
+	if s.Internal.IssueNodeCertificate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.IssueNodeCertificate(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) IssueNodeCertificate(p0 context.Context, p1 string, p2 string, p3 []byte) (*types.NodeCertificate, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListArchivedNodes(p0 context.Context, p1 int, p2 int) (*types.ListArchivedNodeRsp, error) {
+	if s.Internal.ListArchivedNodes == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListArchivedNodes(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) ListArchivedNodes(p0 context.Context, p1 int, p2 int) (*types.ListArchivedNodeRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListBandwidthTests(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListBandwidthTestRsp, error) {
+	if s.Internal.ListBandwidthTests == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListBandwidthTests(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) ListBandwidthTests(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListBandwidthTestRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListBulkJobItems(p0 context.Context, p1 string, p2 types.BulkJobItemStatus, p3 int, p4 int) (*types.ListBulkJobItemRsp, error) {
+	if s.Internal.ListBulkJobItems == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListBulkJobItems(p0, p1, p2, p3, p4)
+}
+
+func (s *NodeAPIStub) ListBulkJobItems(p0 context.Context, p1 string, p2 types.BulkJobItemStatus, p3 int, p4 int) (*types.ListBulkJobItemRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListBulkJobs(p0 context.Context, p1 int, p2 int) (*types.ListBulkJobRsp, error) {
+	if s.Internal.ListBulkJobs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListBulkJobs(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) ListBulkJobs(p0 context.Context, p1 int, p2 int) (*types.ListBulkJobRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListDataExports(p0 context.Context, p1 int, p2 int) (*types.ListDataExportRsp, error) {
+	if s.Internal.ListDataExports == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListDataExports(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) ListDataExports(p0 context.Context, p1 int, p2 int) (*types.ListDataExportRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListFeatureFlags(p0 context.Context) ([]*types.FeatureFlag, error) {
+	if s.Internal.ListFeatureFlags == nil {
+		return *new([]*types.FeatureFlag), ErrNotSupported
+	}
+	return s.Internal.ListFeatureFlags(p0)
+}
+
+func (s *NodeAPIStub) ListFeatureFlags(p0 context.Context) ([]*types.FeatureFlag, error) {
+	return *new([]*types.FeatureFlag), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListJobs(p0 context.Context, p1 string, p2 types.JobStatus, p3 int, p4 int) (*types.ListJobRsp, error) {
+	if s.Internal.ListJobs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListJobs(p0, p1, p2, p3, p4)
+}
+
+func (s *NodeAPIStub) ListJobs(p0 context.Context, p1 string, p2 types.JobStatus, p3 int, p4 int) (*types.ListJobRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListNodeDiagnostics(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeDiagnosticsRsp, error) {
+	if s.Internal.ListNodeDiagnostics == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListNodeDiagnostics(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) ListNodeDiagnostics(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeDiagnosticsRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListNodes(p0 context.Context, p1 *types.ListNodesReq) (*types.ListNodesCursorRsp, error) {
+	if s.Internal.ListNodes == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListNodes(p0, p1)
+}
+
+func (s *NodeAPIStub) ListNodes(p0 context.Context, p1 *types.ListNodesReq) (*types.ListNodesCursorRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListReleaseManifests(p0 context.Context, p1 types.NodeType) ([]*types.ReleaseManifest, error) {
+	if s.Internal.ListReleaseManifests == nil {
+		return *new([]*types.ReleaseManifest), ErrNotSupported
+	}
+	return s.Internal.ListReleaseManifests(p0, p1)
+}
+
+func (s *NodeAPIStub) ListReleaseManifests(p0 context.Context, p1 types.NodeType) ([]*types.ReleaseManifest, error) {
+	return *new([]*types.ReleaseManifest), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListRetrievalProbes(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrievalProbeRsp, error) {
+	if s.Internal.ListRetrievalProbes == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListRetrievalProbes(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) ListRetrievalProbes(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrievalProbeRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListSpotChecks(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListSpotCheckRsp, error) {
+	if s.Internal.ListSpotChecks == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListSpotChecks(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) ListSpotChecks(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListSpotCheckRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListStorageProofs(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListStorageProofRsp, error) {
+	if s.Internal.ListStorageProofs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListStorageProofs(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) ListStorageProofs(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListStorageProofRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListUpgradeNodes(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListUpgradeNodeRsp, error) {
+	if s.Internal.ListUpgradeNodes == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListUpgradeNodes(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) ListUpgradeNodes(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListUpgradeNodeRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ListUpgradeRollouts(p0 context.Context, p1 int, p2 int) (*types.ListUpgradeRolloutRsp, error) {
+	if s.Internal.ListUpgradeRollouts == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListUpgradeRollouts(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) ListUpgradeRollouts(p0 context.Context, p1 int, p2 int) (*types.ListUpgradeRolloutRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) MigrateNodeKey(p0 context.Context, p1 string, p2 string, p3 string) (*types.ActivationDetail, error) {
+	if s.Internal.MigrateNodeKey == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.MigrateNodeKey(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) MigrateNodeKey(p0 context.Context, p1 string, p2 string, p3 string) (*types.ActivationDetail, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NatPunch(p0 context.Context, p1 *types.NatPunchReq) error {
+	if s.Internal.NatPunch == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NatPunch(p0, p1)
+}
+
+func (s *NodeAPIStub) NatPunch(p0 context.Context, p1 *types.NatPunchReq) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeExists(p0 context.Context, p1 string) error {
+	if s.Internal.NodeExists == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NodeExists(p0, p1)
+}
+
+func (s *NodeAPIStub) NodeExists(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeKeepalive(p0 context.Context) (uuid.UUID, error) {
+	if s.Internal.NodeKeepalive == nil {
+		return *new(uuid.UUID), ErrNotSupported
+	}
+	return s.Internal.NodeKeepalive(p0)
+}
+
+func (s *NodeAPIStub) NodeKeepalive(p0 context.Context) (uuid.UUID, error) {
+	return *new(uuid.UUID), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeKeepaliveV2(p0 context.Context) (uuid.UUID, error) {
+	if s.Internal.NodeKeepaliveV2 == nil {
+		return *new(uuid.UUID), ErrNotSupported
+	}
+	return s.Internal.NodeKeepaliveV2(p0)
+}
+
+func (s *NodeAPIStub) NodeKeepaliveV2(p0 context.Context) (uuid.UUID, error) {
+	return *new(uuid.UUID), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeKeepaliveV3(p0 context.Context, p1 *types.HostMetrics) (uuid.UUID, error) {
+	if s.Internal.NodeKeepaliveV3 == nil {
+		return *new(uuid.UUID), ErrNotSupported
+	}
+	return s.Internal.NodeKeepaliveV3(p0, p1)
+}
+
+func (s *NodeAPIStub) NodeKeepaliveV3(p0 context.Context, p1 *types.HostMetrics) (uuid.UUID, error) {
+	return *new(uuid.UUID), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeLogin(p0 context.Context, p1 string, p2 string) (string, error) {
+	if s.Internal.NodeLogin == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.NodeLogin(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) NodeLogin(p0 context.Context, p1 string, p2 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeLoginChallenge(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.NodeLoginChallenge == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.NodeLoginChallenge(p0, p1)
+}
+
+func (s *NodeAPIStub) NodeLoginChallenge(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) NodeLoginV2(p0 context.Context, p1 *types.NodeLoginReq) (string, error) {
+	if s.Internal.NodeLoginV2 == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.NodeLoginV2(p0, p1)
+}
+
+func (s *NodeAPIStub) NodeLoginV2(p0 context.Context, p1 *types.NodeLoginReq) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) PullControlCalls(p0 context.Context) ([]*types.ControlCall, error) {
+	if s.Internal.PullControlCalls == nil {
+		return *new([]*types.ControlCall), ErrNotSupported
+	}
+	return s.Internal.PullControlCalls(p0)
+}
+
+func (s *NodeAPIStub) PullControlCalls(p0 context.Context) ([]*types.ControlCall, error) {
+	return *new([]*types.ControlCall), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) PurgeNode(p0 context.Context, p1 string) error {
+	if s.Internal.PurgeNode == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PurgeNode(p0, p1)
+}
+
+func (s *NodeAPIStub) PurgeNode(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) PushNodeLogs(p0 context.Context, p1 *types.LogBatch) error {
+	if s.Internal.PushNodeLogs == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PushNodeLogs(p0, p1)
+}
+
+func (s *NodeAPIStub) PushNodeLogs(p0 context.Context, p1 *types.LogBatch) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RegisterEdgeNode(p0 context.Context, p1 string, p2 string) (*types.ActivationDetail, error) {
+	if s.Internal.RegisterEdgeNode == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.RegisterEdgeNode(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) RegisterEdgeNode(p0 context.Context, p1 string, p2 string) (*types.ActivationDetail, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RegisterNode(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) {
+	if s.Internal.RegisterNode == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.RegisterNode(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) RegisterNode(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RegisterSandboxNode(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) {
+	if s.Internal.RegisterSandboxNode == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.RegisterSandboxNode(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) RegisterSandboxNode(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RemoveFeatureFlag(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveFeatureFlag == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveFeatureFlag(p0, p1)
+}
+
+func (s *NodeAPIStub) RemoveFeatureFlag(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RemoveNodeCommitment(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveNodeCommitment == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveNodeCommitment(p0, p1)
+}
+
+func (s *NodeAPIStub) RemoveNodeCommitment(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RequestActivationCodes(p0 context.Context, p1 types.NodeType, p2 int) ([]*types.NodeActivation, error) {
+	if s.Internal.RequestActivationCodes == nil {
+		return *new([]*types.NodeActivation), ErrNotSupported
+	}
+	return s.Internal.RequestActivationCodes(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) RequestActivationCodes(p0 context.Context, p1 types.NodeType, p2 int) ([]*types.NodeActivation, error) {
+	return *new([]*types.NodeActivation), ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RequestNodeDiagnostics(p0 context.Context, p1 string, p2 string) (string, error) {
+	if s.Internal.RequestNodeDiagnostics == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.RequestNodeDiagnostics(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) RequestNodeDiagnostics(p0 context.Context, p1 string, p2 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ResolvePenaltyAppeal(p0 context.Context, p1 int64, p2 bool) error {
+	if s.Internal.ResolvePenaltyAppeal == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ResolvePenaltyAppeal(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) ResolvePenaltyAppeal(p0 context.Context, p1 int64, p2 bool) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) RestoreArchivedNode(p0 context.Context, p1 string) error {
+	if s.Internal.RestoreArchivedNode == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RestoreArchivedNode(p0, p1)
+}
+
+func (s *NodeAPIStub) RestoreArchivedNode(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) ResumeUpgradeRollout(p0 context.Context, p1 string) error {
+	if s.Internal.ResumeUpgradeRollout == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ResumeUpgradeRollout(p0, p1)
+}
+
+func (s *NodeAPIStub) ResumeUpgradeRollout(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SavePenaltyRule(p0 context.Context, p1 *types.PenaltyRule) (int64, error) {
+	if s.Internal.SavePenaltyRule == nil {
+		return 0, ErrNotSupported
+	}
+	return s.Internal.SavePenaltyRule(p0, p1)
+}
+
+func (s *NodeAPIStub) SavePenaltyRule(p0 context.Context, p1 *types.PenaltyRule) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SetFeatureFlag(p0 context.Context, p1 *types.FeatureFlag) error {
+	if s.Internal.SetFeatureFlag == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetFeatureFlag(p0, p1)
+}
+
+func (s *NodeAPIStub) SetFeatureFlag(p0 context.Context, p1 *types.FeatureFlag) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SetIdleMode(p0 context.Context, p1 bool) (*types.IdleMode, error) {
+	if s.Internal.SetIdleMode == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SetIdleMode(p0, p1)
+}
+
+func (s *NodeAPIStub) SetIdleMode(p0 context.Context, p1 bool) (*types.IdleMode, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SetNodeCommitment(p0 context.Context, p1 *types.NodeCommitment) error {
+	if s.Internal.SetNodeCommitment == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeCommitment(p0, p1)
+}
+
+func (s *NodeAPIStub) SetNodeCommitment(p0 context.Context, p1 *types.NodeCommitment) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SetNodeConfigDefaults(p0 context.Context, p1 types.NodeType, p2 *types.NodeConfig) error {
+	if s.Internal.SetNodeConfigDefaults == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeConfigDefaults(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) SetNodeConfigDefaults(p0 context.Context, p1 types.NodeType, p2 *types.NodeConfig) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SetNodeConfigOverrides(p0 context.Context, p1 string, p2 *types.NodeConfig) error {
+	if s.Internal.SetNodeConfigOverrides == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeConfigOverrides(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) SetNodeConfigOverrides(p0 context.Context, p1 string, p2 *types.NodeConfig) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SetNodeUploadLimit(p0 context.Context, p1 string, p2 int64) error {
+	if s.Internal.SetNodeUploadLimit == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetNodeUploadLimit(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) SetNodeUploadLimit(p0 context.Context, p1 string, p2 int64) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) StartBandwidthTest(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.StartBandwidthTest == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.StartBandwidthTest(p0, p1)
+}
+
+func (s *NodeAPIStub) StartBandwidthTest(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) StartBulkJob(p0 context.Context, p1 *types.BulkJobReq) (string, error) {
+	if s.Internal.StartBulkJob == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.StartBulkJob(p0, p1)
+}
+
+func (s *NodeAPIStub) StartBulkJob(p0 context.Context, p1 *types.BulkJobReq) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) StartDataExport(p0 context.Context, p1 *types.DataExportReq) (string, error) {
+	if s.Internal.StartDataExport == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.StartDataExport(p0, p1)
+}
+
+func (s *NodeAPIStub) StartDataExport(p0 context.Context, p1 *types.DataExportReq) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) StartUpgradeRollout(p0 context.Context, p1 *types.UpgradeRolloutReq) (string, error) {
+	if s.Internal.StartUpgradeRollout == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.StartUpgradeRollout(p0, p1)
+}
+
+func (s *NodeAPIStub) StartUpgradeRollout(p0 context.Context, p1 *types.UpgradeRolloutReq) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *NodeAPIStruct) StreamNodeLogs(p0 context.Context, p1 string, p2 *types.LogStreamReq) (<-chan *types.LogEntry, error) {
+	if s.Internal.StreamNodeLogs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StreamNodeLogs(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) StreamNodeLogs(p0 context.Context, p1 string, p2 *types.LogStreamReq) (<-chan *types.LogEntry, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SubmitControlResults(p0 context.Context, p1 []*types.ControlResult) error {
+	if s.Internal.SubmitControlResults == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SubmitControlResults(p0, p1)
+}
+
+func (s *NodeAPIStub) SubmitControlResults(p0 context.Context, p1 []*types.ControlResult) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SubmitRelayTraffic(p0 context.Context, p1 []*types.RelayTraffic) error {
+	if s.Internal.SubmitRelayTraffic == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SubmitRelayTraffic(p0, p1)
+}
+
+func (s *NodeAPIStub) SubmitRelayTraffic(p0 context.Context, p1 []*types.RelayTraffic) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SubscribeNodeEvents(p0 context.Context) (<-chan *types.NodeEvent, error) {
+	if s.Internal.SubscribeNodeEvents == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SubscribeNodeEvents(p0)
+}
+
+func (s *NodeAPIStub) SubscribeNodeEvents(p0 context.Context) (<-chan *types.NodeEvent, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) SubscribeNodeTop(p0 context.Context, p1 *types.NodeTopReq) (<-chan *types.NodeTopSnapshot, error) {
+	if s.Internal.SubscribeNodeTop == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SubscribeNodeTop(p0, p1)
+}
+
+func (s *NodeAPIStub) SubscribeNodeTop(p0 context.Context, p1 *types.NodeTopReq) (<-chan *types.NodeTopSnapshot, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) UndoNodeDeactivation(p0 context.Context, p1 string) error {
+	if s.Internal.UndoNodeDeactivation == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UndoNodeDeactivation(p0, p1)
+}
+
+func (s *NodeAPIStub) UndoNodeDeactivation(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) UpdateBandwidths(p0 context.Context, p1 *types.BandwidthReport) error {
+	if s.Internal.UpdateBandwidths == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UpdateBandwidths(p0, p1)
+}
+
+func (s *NodeAPIStub) UpdateBandwidths(p0 context.Context, p1 *types.BandwidthReport) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) UpdateNodePort(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.UpdateNodePort == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.UpdateNodePort(p0, p1, p2)
+}
+
+func (s *NodeAPIStub) UpdateNodePort(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *NodeAPIStruct) VerifyTokenWithLimitCount(p0 context.Context, p1 string) (*types.JWTPayload, error) {
+	if s.Internal.VerifyTokenWithLimitCount == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.VerifyTokenWithLimitCount(p0, p1)
+}
+
+func (s *NodeAPIStub) VerifyTokenWithLimitCount(p0 context.Context, p1 string) (*types.JWTPayload, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *NodeAPIStruct) WebRTCConnect(p0 context.Context, p1 *types.WebRTCOfferReq) (*types.WebRTCAnswer, error) {
+	if s.Internal.WebRTCConnect == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.WebRTCConnect(p0, p1)
+}
+
+func (s *NodeAPIStub) WebRTCConnect(p0 context.Context, p1 *types.WebRTCOfferReq) (*types.WebRTCAnswer, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *S3APIStruct) CreateS3AccessKey(p0 context.Context, p1 string) (*types.S3AccessKey, error) {
+	if s.Internal.CreateS3AccessKey == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateS3AccessKey(p0, p1)
+}
+
+func (s *S3APIStub) CreateS3AccessKey(p0 context.Context, p1 string) (*types.S3AccessKey, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *S3APIStruct) DeleteS3AccessKey(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.DeleteS3AccessKey == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeleteS3AccessKey(p0, p1, p2)
+}
+
+func (s *S3APIStub) DeleteS3AccessKey(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *S3APIStruct) GetS3AccessKeys(p0 context.Context, p1 string) ([]*types.S3AccessKey, error) {
+	if s.Internal.GetS3AccessKeys == nil {
+		return *new([]*types.S3AccessKey), ErrNotSupported
+	}
+	return s.Internal.GetS3AccessKeys(p0, p1)
+}
+
+func (s *S3APIStub) GetS3AccessKeys(p0 context.Context, p1 string) ([]*types.S3AccessKey, error) {
+	return *new([]*types.S3AccessKey), ErrNotSupported
+}
+
+func (s *S3APIStruct) S3Authorize(p0 context.Context, p1 *types.S3AuthReq) (*types.S3Grant, error) {
+	if s.Internal.S3Authorize == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.S3Authorize(p0, p1)
+}
+
+func (s *S3APIStub) S3Authorize(p0 context.Context, p1 *types.S3AuthReq) (*types.S3Grant, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *S3APIStruct) S3GetObject(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) {
+	if s.Internal.S3GetObject == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.S3GetObject(p0, p1, p2, p3)
+}
+
+func (s *S3APIStub) S3GetObject(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *S3APIStruct) S3ListBuckets(p0 context.Context, p1 string) ([]string, error) {
+	if s.Internal.S3ListBuckets == nil {
+		return *new([]string), ErrNotSupported
+	}
+	return s.Internal.S3ListBuckets(p0, p1)
+}
+
+func (s *S3APIStub) S3ListBuckets(p0 context.Context, p1 string) ([]string, error) {
+	return *new([]string), ErrNotSupported
+}
+
+func (s *S3APIStruct) S3ListObjects(p0 context.Context, p1 *types.ListS3ObjectsReq) (*types.ListS3ObjectsRsp, error) {
+	if s.Internal.S3ListObjects == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.S3ListObjects(p0, p1)
+}
+
+func (s *S3APIStub) S3ListObjects(p0 context.Context, p1 *types.ListS3ObjectsReq) (*types.ListS3ObjectsRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *S3APIStruct) S3PutObject(p0 context.Context, p1 *types.S3Grant, p2 *types.S3Object) error {
+	if s.Internal.S3PutObject == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.S3PutObject(p0, p1, p2)
+}
+
+func (s *S3APIStub) S3PutObject(p0 context.Context, p1 *types.S3Grant, p2 *types.S3Object) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) DeleteEdgeUpdateConfig(p0 context.Context, p1 int) error {
+	if s.Internal.DeleteEdgeUpdateConfig == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeleteEdgeUpdateConfig(p0, p1)
+}
+
+func (s *SchedulerStub) DeleteEdgeUpdateConfig(p0 context.Context, p1 int) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) ElectValidators(p0 context.Context, p1 []string) error {
+	if s.Internal.ElectValidators == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ElectValidators(p0, p1)
+}
+
+func (s *SchedulerStub) ElectValidators(p0 context.Context, p1 []string) error {
+	return ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetAuditLogs(p0 context.Context, p1 *types.ListAuditLogsReq) (*types.ListAuditLogRsp, error) {
+	if s.Internal.GetAuditLogs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAuditLogs(p0, p1)
+}
+
+func (s *SchedulerStub) GetAuditLogs(p0 context.Context, p1 *types.ListAuditLogsReq) (*types.ListAuditLogRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetEdgeUpdateConfigs(p0 context.Context) (map[int]*EdgeUpdateConfig, error) {
+	if s.Internal.GetEdgeUpdateConfigs == nil {
+		return *new(map[int]*EdgeUpdateConfig), ErrNotSupported
+	}
+	return s.Internal.GetEdgeUpdateConfigs(p0)
+}
+
+func (s *SchedulerStub) GetEdgeUpdateConfigs(p0 context.Context) (map[int]*EdgeUpdateConfig, error) {
+	return *new(map[int]*EdgeUpdateConfig), ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetGoroutineDump(p0 context.Context) (string, error) {
+	if s.Internal.GetGoroutineDump == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.GetGoroutineDump(p0)
+}
+
+func (s *SchedulerStub) GetGoroutineDump(p0 context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetHardwareProof(p0 context.Context, p1 string) (*types.HardwareProof, error) {
+	if s.Internal.GetHardwareProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetHardwareProof(p0, p1)
+}
+
+func (s *SchedulerStub) GetHardwareProof(p0 context.Context, p1 string) (*types.HardwareProof, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetNodePublicKey(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.GetNodePublicKey == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.GetNodePublicKey(p0, p1)
+}
+
+func (s *SchedulerStub) GetNodePublicKey(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetProfile(p0 context.Context, p1 string, p2 int) ([]byte, error) {
+	if s.Internal.GetProfile == nil {
+		return *new([]byte), ErrNotSupported
+	}
+	return s.Internal.GetProfile(p0, p1, p2)
+}
+
+func (s *SchedulerStub) GetProfile(p0 context.Context, p1 string, p2 int) ([]byte, error) {
+	return *new([]byte), ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetRetrieveEventRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrieveEventRsp, error) {
+	if s.Internal.GetRetrieveEventRecords == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetRetrieveEventRecords(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetRetrieveEventRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrieveEventRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetRuntimeDiagnostics(p0 context.Context) (*types.RuntimeDiagnostics, error) {
+	if s.Internal.GetRuntimeDiagnostics == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetRuntimeDiagnostics(p0)
+}
+
+func (s *SchedulerStub) GetRuntimeDiagnostics(p0 context.Context) (*types.RuntimeDiagnostics, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetSchedulerPublicKey(p0 context.Context) (string, error) {
+	if s.Internal.GetSchedulerPublicKey == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.GetSchedulerPublicKey(p0)
+}
+
+func (s *SchedulerStub) GetSchedulerPublicKey(p0 context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetValidationInfo(p0 context.Context) (*types.ValidationInfo, error) {
+	if s.Internal.GetValidationInfo == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetValidationInfo(p0)
+}
+
+func (s *SchedulerStub) GetValidationInfo(p0 context.Context) (*types.ValidationInfo, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetValidationProofs(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationProofRsp, error) {
+	if s.Internal.GetValidationProofs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetValidationProofs(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetValidationProofs(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationProofRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetValidationResults(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationResultRsp, error) {
+	if s.Internal.GetValidationResults == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetValidationResults(p0, p1, p2, p3)
+}
+
+func (s *SchedulerStub) GetValidationResults(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationResultRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetValidatorLoads(p0 context.Context) ([]*types.ValidatorLoad, error) {
+	if s.Internal.GetValidatorLoads == nil {
+		return *new([]*types.ValidatorLoad), ErrNotSupported
+	}
+	return s.Internal.GetValidatorLoads(p0)
+}
+
+func (s *SchedulerStub) GetValidatorLoads(p0 context.Context) ([]*types.ValidatorLoad, error) {
+	return *new([]*types.ValidatorLoad), ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetWorkloadRecord(p0 context.Context, p1 string) (*types.WorkloadRecord, error) {
+	if s.Internal.GetWorkloadRecord == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetWorkloadRecord(p0, p1)
+}
+
+func (s *SchedulerStub) GetWorkloadRecord(p0 context.Context, p1 string) (*types.WorkloadRecord, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *SchedulerStruct) GetWorkloadRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadRecordRsp, error) {
+	if s.Internal.GetWorkloadRecords == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.NodeExists(p0, p1)
+	return s.Internal.GetWorkloadRecords(p0, p1, p2, p3)
 }
 
-func (s *NodeAPIStub) NodeExists(p0 context.Context, p1 string) error {
-	return ErrNotSupported
+func (s *SchedulerStub) GetWorkloadRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadRecordRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) NodeKeepalive(p0 context.Context) (uuid.UUID, error) {
-	if s.Internal.NodeKeepalive == nil {
-		return *new(uuid.UUID), ErrNotSupported
+func (s *SchedulerStruct) GetWorkloadVerificationStats(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.WorkloadVerificationStats, error) {
+	if s.Internal.GetWorkloadVerificationStats == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.NodeKeepalive(p0)
+	return s.Internal.GetWorkloadVerificationStats(p0, p1, p2, p3)
 }
 
-func (s *NodeAPIStub) NodeKeepalive(p0 context.Context) (uuid.UUID, error) {
-	return *new(uuid.UUID), ErrNotSupported
+func (s *SchedulerStub) GetWorkloadVerificationStats(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.WorkloadVerificationStats, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) NodeKeepaliveV2(p0 context.Context) (uuid.UUID, error) {
-	if s.Internal.NodeKeepaliveV2 == nil {
-		return *new(uuid.UUID), ErrNotSupported
+func (s *SchedulerStruct) ListSchedulingDecisions(p0 context.Context, p1 *types.ListSchedulingDecisionsReq) (*types.ListSchedulingDecisionRsp, error) {
+	if s.Internal.ListSchedulingDecisions == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.NodeKeepaliveV2(p0)
+	return s.Internal.ListSchedulingDecisions(p0, p1)
 }
 
-func (s *NodeAPIStub) NodeKeepaliveV2(p0 context.Context) (uuid.UUID, error) {
-	return *new(uuid.UUID), ErrNotSupported
+func (s *SchedulerStub) ListSchedulingDecisions(p0 context.Context, p1 *types.ListSchedulingDecisionsReq) (*types.ListSchedulingDecisionRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) NodeLogin(p0 context.Context, p1 string, p2 string) (string, error) {
-	if s.Internal.NodeLogin == nil {
-		return "", ErrNotSupported
+func (s *SchedulerStruct) ListWorkloadReceipts(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadReceiptRsp, error) {
+	if s.Internal.ListWorkloadReceipts == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.NodeLogin(p0, p1, p2)
+	return s.Internal.ListWorkloadReceipts(p0, p1, p2, p3)
 }
 
-func (s *NodeAPIStub) NodeLogin(p0 context.Context, p1 string, p2 string) (string, error) {
-	return "", ErrNotSupported
+func (s *SchedulerStub) ListWorkloadReceipts(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadReceiptRsp, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) RegisterEdgeNode(p0 context.Context, p1 string, p2 string) (*types.ActivationDetail, error) {
-	if s.Internal.RegisterEdgeNode == nil {
-		return nil, ErrNotSupported
+func (s *SchedulerStruct) NodeValidationResult(p0 context.Context, p1 io.Reader, p2 string) error {
+	if s.Internal.NodeValidationResult == nil {
+		return ErrNotSupported
 	}
-	return s.Internal.RegisterEdgeNode(p0, p1, p2)
+	return s.Internal.NodeValidationResult(p0, p1, p2)
 }
 
-func (s *NodeAPIStub) RegisterEdgeNode(p0 context.Context, p1 string, p2 string) (*types.ActivationDetail, error) {
-	return nil, ErrNotSupported
+func (s *SchedulerStub) NodeValidationResult(p0 context.Context, p1 io.Reader, p2 string) error {
+	return ErrNotSupported
 }
 
-func (s *NodeAPIStruct) RegisterNode(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) {
-	if s.Internal.RegisterNode == nil {
+func (s *SchedulerStruct) ReplaySchedulingDecision(p0 context.Context, p1 int64) (*types.SchedulingDecision, error) {
+	if s.Internal.ReplaySchedulingDecision == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.RegisterNode(p0, p1, p2, p3)
+	return s.Internal.ReplaySchedulingDecision(p0, p1)
 }
 
-func (s *NodeAPIStub) RegisterNode(p0 context.Context, p1 string, p2 string, p3 types.NodeType) (*types.ActivationDetail, error) {
+func (s *SchedulerStub) ReplaySchedulingDecision(p0 context.Context, p1 int64) (*types.SchedulingDecision, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *NodeAPIStruct) RequestActivationCodes(p0 context.Context, p1 types.NodeType, p2 int) ([]*types.NodeActivation, error) {
-	if s.Internal.RequestActivationCodes == nil {
-		return *new([]*types.NodeActivation), ErrNotSupported
+func (s *SchedulerStruct) RotateSchedulerKey(p0 context.Context) error {
+	if s.Internal.RotateSchedulerKey == nil {
+		return ErrNotSupported
 	}
-	return s.Internal.RequestActivationCodes(p0, p1, p2)
+	return s.Internal.RotateSchedulerKey(p0)
 }
 
-func (s *NodeAPIStub) RequestActivationCodes(p0 context.Context, p1 types.NodeType, p2 int) ([]*types.NodeActivation, error) {
-	return *new([]*types.NodeActivation), ErrNotSupported
+func (s *SchedulerStub) RotateSchedulerKey(p0 context.Context) error {
+	return ErrNotSupported
 }
 
-func (s *NodeAPIStruct) UndoNodeDeactivation(p0 context.Context, p1 string) error {
-	if s.Internal.UndoNodeDeactivation == nil {
+func (s *SchedulerStruct) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateConfig) error {
+	if s.Internal.SetEdgeUpdateConfig == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.UndoNodeDeactivation(p0, p1)
+	return s.Internal.SetEdgeUpdateConfig(p0, p1)
 }
 
-func (s *NodeAPIStub) UndoNodeDeactivation(p0 context.Context, p1 string) error {
+func (s *SchedulerStub) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateConfig) error {
 	return ErrNotSupported
 }
 
-func (s *NodeAPIStruct) UpdateBandwidths(p0 context.Context, p1 int64, p2 int64) error {
-	if s.Internal.UpdateBandwidths == nil {
+func (s *SchedulerStruct) SubmitClientReceipts(p0 context.Context, p1 []*types.WorkloadReceipt) error {
+	if s.Internal.SubmitClientReceipts == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.UpdateBandwidths(p0, p1, p2)
+	return s.Internal.SubmitClientReceipts(p0, p1)
 }
 
-func (s *NodeAPIStub) UpdateBandwidths(p0 context.Context, p1 int64, p2 int64) error {
+func (s *SchedulerStub) SubmitClientReceipts(p0 context.Context, p1 []*types.WorkloadReceipt) error {
 	return ErrNotSupported
 }
 
-func (s *NodeAPIStruct) UpdateNodePort(p0 context.Context, p1 string, p2 string) error {
-	if s.Internal.UpdateNodePort == nil {
+func (s *SchedulerStruct) SubmitNodeWorkloadReport(p0 context.Context, p1 io.Reader) error {
+	if s.Internal.SubmitNodeWorkloadReport == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.UpdateNodePort(p0, p1, p2)
+	return s.Internal.SubmitNodeWorkloadReport(p0, p1)
 }
 
-func (s *NodeAPIStub) UpdateNodePort(p0 context.Context, p1 string, p2 string) error {
+func (s *SchedulerStub) SubmitNodeWorkloadReport(p0 context.Context, p1 io.Reader) error {
 	return ErrNotSupported
 }
 
-func (s *NodeAPIStruct) VerifyTokenWithLimitCount(p0 context.Context, p1 string) (*types.JWTPayload, error) {
-	if s.Internal.VerifyTokenWithLimitCount == nil {
-		return nil, ErrNotSupported
+func (s *SchedulerStruct) SubmitUserWorkloadReport(p0 context.Context, p1 io.Reader) error {
+	if s.Internal.SubmitUserWorkloadReport == nil {
+		return ErrNotSupported
 	}
-	return s.Internal.VerifyTokenWithLimitCount(p0, p1)
+	return s.Internal.SubmitUserWorkloadReport(p0, p1)
 }
 
-func (s *NodeAPIStub) VerifyTokenWithLimitCount(p0 context.Context, p1 string) (*types.JWTPayload, error) {
-	return nil, ErrNotSupported
+func (s *SchedulerStub) SubmitUserWorkloadReport(p0 context.Context, p1 io.Reader) error {
+	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) DeleteEdgeUpdateConfig(p0 context.Context, p1 int) error {
-	if s.Internal.DeleteEdgeUpdateConfig == nil {
+func (s *SchedulerStruct) SubmitWorkloadReceipts(p0 context.Context, p1 []*types.WorkloadReceipt) error {
+	if s.Internal.SubmitWorkloadReceipts == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.DeleteEdgeUpdateConfig(p0, p1)
+	return s.Internal.SubmitWorkloadReceipts(p0, p1)
 }
 
-func (s *SchedulerStub) DeleteEdgeUpdateConfig(p0 context.Context, p1 int) error {
+func (s *SchedulerStub) SubmitWorkloadReceipts(p0 context.Context, p1 []*types.WorkloadReceipt) error {
 	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) ElectValidators(p0 context.Context, p1 []string) error {
-	if s.Internal.ElectValidators == nil {
+func (s *SchedulerStruct) TriggerElection(p0 context.Context) error {
+	if s.Internal.TriggerElection == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.ElectValidators(p0, p1)
+	return s.Internal.TriggerElection(p0)
 }
 
-func (s *SchedulerStub) ElectValidators(p0 context.Context, p1 []string) error {
+func (s *SchedulerStub) TriggerElection(p0 context.Context) error {
 	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetEdgeUpdateConfigs(p0 context.Context) (map[int]*EdgeUpdateConfig, error) {
-	if s.Internal.GetEdgeUpdateConfigs == nil {
-		return *new(map[int]*EdgeUpdateConfig), ErrNotSupported
+func (s *SchedulerStruct) VerifyValidationProofs(p0 context.Context, p1 string) (*types.ValidationProofAudit, error) {
+	if s.Internal.VerifyValidationProofs == nil {
+		return nil, ErrNotSupported
 	}
-	return s.Internal.GetEdgeUpdateConfigs(p0)
+	return s.Internal.VerifyValidationProofs(p0, p1)
 }
 
-func (s *SchedulerStub) GetEdgeUpdateConfigs(p0 context.Context) (map[int]*EdgeUpdateConfig, error) {
-	return *new(map[int]*EdgeUpdateConfig), ErrNotSupported
+func (s *SchedulerStub) VerifyValidationProofs(p0 context.Context, p1 string) (*types.ValidationProofAudit, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetNodePublicKey(p0 context.Context, p1 string) (string, error) {
-	if s.Internal.GetNodePublicKey == nil {
-		return "", ErrNotSupported
+func (s *TenantAPIStruct) AddTenantUser(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.AddTenantUser == nil {
+		return ErrNotSupported
 	}
-	return s.Internal.GetNodePublicKey(p0, p1)
+	return s.Internal.AddTenantUser(p0, p1, p2)
 }
 
-func (s *SchedulerStub) GetNodePublicKey(p0 context.Context, p1 string) (string, error) {
-	return "", ErrNotSupported
+func (s *TenantAPIStub) AddTenantUser(p0 context.Context, p1 string, p2 string) error {
+	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetRetrieveEventRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrieveEventRsp, error) {
-	if s.Internal.GetRetrieveEventRecords == nil {
-		return nil, ErrNotSupported
+func (s *TenantAPIStruct) CreateReplicationAgreement(p0 context.Context, p1 *types.ReplicationAgreement) (string, error) {
+	if s.Internal.CreateReplicationAgreement == nil {
+		return "", ErrNotSupported
 	}
-	return s.Internal.GetRetrieveEventRecords(p0, p1, p2, p3)
+	return s.Internal.CreateReplicationAgreement(p0, p1)
 }
 
-func (s *SchedulerStub) GetRetrieveEventRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrieveEventRsp, error) {
-	return nil, ErrNotSupported
+func (s *TenantAPIStub) CreateReplicationAgreement(p0 context.Context, p1 *types.ReplicationAgreement) (string, error) {
+	return "", ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetSchedulerPublicKey(p0 context.Context) (string, error) {
-	if s.Internal.GetSchedulerPublicKey == nil {
+func (s *TenantAPIStruct) CreateTenantToken(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.CreateTenantToken == nil {
 		return "", ErrNotSupported
 	}
-	return s.Internal.GetSchedulerPublicKey(p0)
+	return s.Internal.CreateTenantToken(p0, p1)
 }
 
-func (s *SchedulerStub) GetSchedulerPublicKey(p0 context.Context) (string, error) {
+func (s *TenantAPIStub) CreateTenantToken(p0 context.Context, p1 string) (string, error) {
 	return "", ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetValidationInfo(p0 context.Context) (*types.ValidationInfo, error) {
-	if s.Internal.GetValidationInfo == nil {
+func (s *TenantAPIStruct) GetTenantQuota(p0 context.Context, p1 string) (*types.TenantQuotaStatus, error) {
+	if s.Internal.GetTenantQuota == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetValidationInfo(p0)
+	return s.Internal.GetTenantQuota(p0, p1)
 }
 
-func (s *SchedulerStub) GetValidationInfo(p0 context.Context) (*types.ValidationInfo, error) {
+func (s *TenantAPIStub) GetTenantQuota(p0 context.Context, p1 string) (*types.TenantQuotaStatus, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetValidationResults(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationResultRsp, error) {
-	if s.Internal.GetValidationResults == nil {
+func (s *TenantAPIStruct) GetTenantUsage(p0 context.Context, p1 string) (*types.TenantUsage, error) {
+	if s.Internal.GetTenantUsage == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetValidationResults(p0, p1, p2, p3)
+	return s.Internal.GetTenantUsage(p0, p1)
 }
 
-func (s *SchedulerStub) GetValidationResults(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListValidationResultRsp, error) {
+func (s *TenantAPIStub) GetTenantUsage(p0 context.Context, p1 string) (*types.TenantUsage, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetWorkloadRecord(p0 context.Context, p1 string) (*types.WorkloadRecord, error) {
-	if s.Internal.GetWorkloadRecord == nil {
+func (s *TenantAPIStruct) GetTenantUsageReport(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.TenantUsageReport, error) {
+	if s.Internal.GetTenantUsageReport == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetWorkloadRecord(p0, p1)
+	return s.Internal.GetTenantUsageReport(p0, p1, p2, p3)
 }
 
-func (s *SchedulerStub) GetWorkloadRecord(p0 context.Context, p1 string) (*types.WorkloadRecord, error) {
+func (s *TenantAPIStub) GetTenantUsageReport(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) (*types.TenantUsageReport, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *SchedulerStruct) GetWorkloadRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadRecordRsp, error) {
-	if s.Internal.GetWorkloadRecords == nil {
+func (s *TenantAPIStruct) ListReplicationAgreements(p0 context.Context, p1 string) ([]*types.ReplicationAgreement, error) {
+	if s.Internal.ListReplicationAgreements == nil {
+		return *new([]*types.ReplicationAgreement), ErrNotSupported
+	}
+	return s.Internal.ListReplicationAgreements(p0, p1)
+}
+
+func (s *TenantAPIStub) ListReplicationAgreements(p0 context.Context, p1 string) ([]*types.ReplicationAgreement, error) {
+	return *new([]*types.ReplicationAgreement), ErrNotSupported
+}
+
+func (s *TenantAPIStruct) ListTenantUsers(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListTenantUserRsp, error) {
+	if s.Internal.ListTenantUsers == nil {
 		return nil, ErrNotSupported
 	}
-	return s.Internal.GetWorkloadRecords(p0, p1, p2, p3)
+	return s.Internal.ListTenantUsers(p0, p1, p2, p3)
 }
 
-func (s *SchedulerStub) GetWorkloadRecords(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListWorkloadRecordRsp, error) {
+func (s *TenantAPIStub) ListTenantUsers(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListTenantUserRsp, error) {
 	return nil, ErrNotSupported
 }
 
-func (s *SchedulerStruct) NodeValidationResult(p0 context.Context, p1 io.Reader, p2 string) error {
-	if s.Internal.NodeValidationResult == nil {
+func (s *TenantAPIStruct) ListTenants(p0 context.Context) ([]*types.Tenant, error) {
+	if s.Internal.ListTenants == nil {
+		return *new([]*types.Tenant), ErrNotSupported
+	}
+	return s.Internal.ListTenants(p0)
+}
+
+func (s *TenantAPIStub) ListTenants(p0 context.Context) ([]*types.Tenant, error) {
+	return *new([]*types.Tenant), ErrNotSupported
+}
+
+func (s *TenantAPIStruct) ReceiveMirrorManifests(p0 context.Context, p1 *types.MirrorManifestBatch) (int, error) {
+	if s.Internal.ReceiveMirrorManifests == nil {
+		return 0, ErrNotSupported
+	}
+	return s.Internal.ReceiveMirrorManifests(p0, p1)
+}
+
+func (s *TenantAPIStub) ReceiveMirrorManifests(p0 context.Context, p1 *types.MirrorManifestBatch) (int, error) {
+	return 0, ErrNotSupported
+}
+
+func (s *TenantAPIStruct) RemoveReplicationAgreement(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveReplicationAgreement == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.NodeValidationResult(p0, p1, p2)
+	return s.Internal.RemoveReplicationAgreement(p0, p1)
 }
 
-func (s *SchedulerStub) NodeValidationResult(p0 context.Context, p1 io.Reader, p2 string) error {
+func (s *TenantAPIStub) RemoveReplicationAgreement(p0 context.Context, p1 string) error {
 	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateConfig) error {
-	if s.Internal.SetEdgeUpdateConfig == nil {
+func (s *TenantAPIStruct) RemoveTenant(p0 context.Context, p1 string) error {
+	if s.Internal.RemoveTenant == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.SetEdgeUpdateConfig(p0, p1)
+	return s.Internal.RemoveTenant(p0, p1)
 }
 
-func (s *SchedulerStub) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateConfig) error {
+func (s *TenantAPIStub) RemoveTenant(p0 context.Context, p1 string) error {
 	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) SubmitNodeWorkloadReport(p0 context.Context, p1 io.Reader) error {
-	if s.Internal.SubmitNodeWorkloadReport == nil {
+func (s *TenantAPIStruct) RemoveTenantUser(p0 context.Context, p1 string, p2 string) error {
+	if s.Internal.RemoveTenantUser == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.SubmitNodeWorkloadReport(p0, p1)
+	return s.Internal.RemoveTenantUser(p0, p1, p2)
 }
 
-func (s *SchedulerStub) SubmitNodeWorkloadReport(p0 context.Context, p1 io.Reader) error {
+func (s *TenantAPIStub) RemoveTenantUser(p0 context.Context, p1 string, p2 string) error {
 	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) SubmitUserWorkloadReport(p0 context.Context, p1 io.Reader) error {
-	if s.Internal.SubmitUserWorkloadReport == nil {
+func (s *TenantAPIStruct) SetReplicationAgreementState(p0 context.Context, p1 string, p2 types.ReplicationAgreementState) error {
+	if s.Internal.SetReplicationAgreementState == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.SubmitUserWorkloadReport(p0, p1)
+	return s.Internal.SetReplicationAgreementState(p0, p1, p2)
 }
 
-func (s *SchedulerStub) SubmitUserWorkloadReport(p0 context.Context, p1 io.Reader) error {
+func (s *TenantAPIStub) SetReplicationAgreementState(p0 context.Context, p1 string, p2 types.ReplicationAgreementState) error {
 	return ErrNotSupported
 }
 
-func (s *SchedulerStruct) TriggerElection(p0 context.Context) error {
-	if s.Internal.TriggerElection == nil {
+func (s *TenantAPIStruct) SetTenant(p0 context.Context, p1 *types.Tenant) error {
+	if s.Internal.SetTenant == nil {
 		return ErrNotSupported
 	}
-	return s.Internal.TriggerElection(p0)
+	return s.Internal.SetTenant(p0, p1)
 }
 
-func (s *SchedulerStub) TriggerElection(p0 context.Context) error {
+func (s *TenantAPIStub) SetTenant(p0 context.Context, p1 *types.Tenant) error {
+	return ErrNotSupported
+}
+
+func (s *TokenAPIStruct) AssignAPITokenRole(p0 context.Context, p1 string, p2 auth.Permission) error {
+	if s.Internal.AssignAPITokenRole == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.AssignAPITokenRole(p0, p1, p2)
+}
+
+func (s *TokenAPIStub) AssignAPITokenRole(p0 context.Context, p1 string, p2 auth.Permission) error {
+	return ErrNotSupported
+}
+
+func (s *TokenAPIStruct) CreateAPIToken(p0 context.Context, p1 string, p2 auth.Permission) (string, error) {
+	if s.Internal.CreateAPIToken == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.CreateAPIToken(p0, p1, p2)
+}
+
+func (s *TokenAPIStub) CreateAPIToken(p0 context.Context, p1 string, p2 auth.Permission) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *TokenAPIStruct) GetAPITokens(p0 context.Context) ([]*types.APIToken, error) {
+	if s.Internal.GetAPITokens == nil {
+		return *new([]*types.APIToken), ErrNotSupported
+	}
+	return s.Internal.GetAPITokens(p0)
+}
+
+func (s *TokenAPIStub) GetAPITokens(p0 context.Context) ([]*types.APIToken, error) {
+	return *new([]*types.APIToken), ErrNotSupported
+}
+
+func (s *TokenAPIStruct) GetActiveTokens(p0 context.Context, p1 auth.Permission, p2 int, p3 int) (*types.ListTokenSubjectRsp, error) {
+	if s.Internal.GetActiveTokens == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetActiveTokens(p0, p1, p2, p3)
+}
+
+func (s *TokenAPIStub) GetActiveTokens(p0 context.Context, p1 auth.Permission, p2 int, p3 int) (*types.ListTokenSubjectRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *TokenAPIStruct) RevokeTokens(p0 context.Context, p1 string) error {
+	if s.Internal.RevokeTokens == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RevokeTokens(p0, p1)
+}
+
+func (s *TokenAPIStub) RevokeTokens(p0 context.Context, p1 string) error {
 	return ErrNotSupported
 }
 
+func (s *TokenAPIStruct) RotateAPIToken(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.RotateAPIToken == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.RotateAPIToken(p0, p1)
+}
+
+func (s *TokenAPIStub) RotateAPIToken(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *TokenAPIStruct) RotateUserTokens(p0 context.Context, p1 string) (string, error) {
+	if s.Internal.RotateUserTokens == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.RotateUserTokens(p0, p1)
+}
+
+func (s *TokenAPIStub) RotateUserTokens(p0 context.Context, p1 string) (string, error) {
+	return "", ErrNotSupported
+}
+
 func (s *UserAPIStruct) AllocateStorage(p0 context.Context, p1 string) (*types.UserInfo, error) {
 	if s.Internal.AllocateStorage == nil {
 		return nil, ErrNotSupported
@@ -1937,6 +4902,62 @@ func (s *ValidationStub) ExecuteValidation(p0 context.Context, p1 *ValidateReq)
 	return ErrNotSupported
 }
 
+func (s *WebhookAPIStruct) CreateAssetWebhook(p0 context.Context, p1 string, p2 string, p3 string, p4 []types.WebhookEvent) (*types.Webhook, error) {
+	if s.Internal.CreateAssetWebhook == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateAssetWebhook(p0, p1, p2, p3, p4)
+}
+
+func (s *WebhookAPIStub) CreateAssetWebhook(p0 context.Context, p1 string, p2 string, p3 string, p4 []types.WebhookEvent) (*types.Webhook, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *WebhookAPIStruct) CreateTenantWebhook(p0 context.Context, p1 string, p2 string, p3 []types.WebhookEvent) (*types.Webhook, error) {
+	if s.Internal.CreateTenantWebhook == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateTenantWebhook(p0, p1, p2, p3)
+}
+
+func (s *WebhookAPIStub) CreateTenantWebhook(p0 context.Context, p1 string, p2 string, p3 []types.WebhookEvent) (*types.Webhook, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *WebhookAPIStruct) DeleteWebhook(p0 context.Context, p1 string, p2 int64) error {
+	if s.Internal.DeleteWebhook == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeleteWebhook(p0, p1, p2)
+}
+
+func (s *WebhookAPIStub) DeleteWebhook(p0 context.Context, p1 string, p2 int64) error {
+	return ErrNotSupported
+}
+
+func (s *WebhookAPIStruct) ListWebhookDeliveries(p0 context.Context, p1 string, p2 int64, p3 int, p4 int) (*types.ListWebhookDeliveryRsp, error) {
+	if s.Internal.ListWebhookDeliveries == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListWebhookDeliveries(p0, p1, p2, p3, p4)
+}
+
+func (s *WebhookAPIStub) ListWebhookDeliveries(p0 context.Context, p1 string, p2 int64, p3 int, p4 int) (*types.ListWebhookDeliveryRsp, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *WebhookAPIStruct) ListWebhooks(p0 context.Context, p1 string, p2 string) ([]*types.Webhook, error) {
+	if s.Internal.ListWebhooks == nil {
+		return *new([]*types.Webhook), ErrNotSupported
+	}
+	return s.Internal.ListWebhooks(p0, p1, p2)
+}
+
+func (s *WebhookAPIStub) ListWebhooks(p0 context.Context, p1 string, p2 string) ([]*types.Webhook, error) {
+	return *new([]*types.Webhook), ErrNotSupported
+}
+
+var _ AccountAPI = new(AccountAPIStruct)
 var _ Asset = new(AssetStruct)
 var _ AssetAPI = new(AssetAPIStruct)
 var _ Candidate = new(CandidateStruct)
@@ -1946,6 +4967,10 @@ var _ Device = new(DeviceStruct)
 var _ Edge = new(EdgeStruct)
 var _ Locator = new(LocatorStruct)
 var _ NodeAPI = new(NodeAPIStruct)
+var _ S3API = new(S3APIStruct)
 var _ Scheduler = new(SchedulerStruct)
+var _ TenantAPI = new(TenantAPIStruct)
+var _ TokenAPI = new(TokenAPIStruct)
 var _ UserAPI = new(UserAPIStruct)
 var _ Validation = new(ValidationStruct)
+var _ WebhookAPI = new(WebhookAPIStruct)
@@ -12,9 +12,11 @@ import (
 
 type permKey int
 type userAccessControlKey struct{}
+type tenantKey struct{}
 
 var permCtxKey permKey
 var aclCtxKey userAccessControlKey
+var tenantCtxKey tenantKey
 
 func split(psStr auth.Permission) []auth.Permission {
 	permissions := strings.Split(string(psStr), ",")
@@ -27,6 +29,45 @@ func split(psStr auth.Permission) []auth.Permission {
 	return ps
 }
 
+// inheritedPerms the permissions granted by roles that are not tagged on the methods
+var inheritedPerms = map[auth.Permission][]auth.Permission{
+	RoleOperator: {RoleWeb},
+	RoleReadOnly: {RoleWeb},
+}
+
+// readOnlyPrefixes the method name prefixes that read-only callers can invoke
+var readOnlyPrefixes = []string{"Get", "List"}
+
+// tenantMethods the methods the tokens scoped to a tenant can invoke, the methods check that the users
+// they act on are users of the tenant
+var tenantMethods = map[string]bool{
+	"AllocateStorage":       true,
+	"GetUserInfo":           true,
+	"CreateAPIKey":          true,
+	"GetAPIKeys":            true,
+	"DeleteAPIKey":          true,
+	"GetUserAccessToken":    true,
+	"GetUserStorageStats":   true,
+	"CreateAsset":           true,
+	"CreateIngestTask":      true,
+	"ListAssets":            true,
+	"DeleteAsset":           true,
+	"ShareAssets":           true,
+	"CreateSignedURL":       true,
+	"GetDownloadSources":    true,
+	"GetAssetStatus":        true,
+	"AddTenantUser":         true,
+	"ListTenantUsers":       true,
+	"GetTenantUsage":        true,
+	"GetTenantUsageReport":  true,
+	"GetTenantQuota":        true,
+	"CreateAssetWebhook":    true,
+	"CreateTenantWebhook":   true,
+	"ListWebhooks":          true,
+	"DeleteWebhook":         true,
+	"ListWebhookDeliveries": true,
+}
+
 func WithPerm(ctx context.Context, perms []auth.Permission) context.Context {
 	return context.WithValue(ctx, permCtxKey, perms)
 }
@@ -35,6 +76,20 @@ func WithUserAccessControl(ctx context.Context, acl []types.UserAccessControl) c
 	return context.WithValue(ctx, aclCtxKey, acl)
 }
 
+// WithTenant scopes the caller to the tenant, the caller is not scoped if the tenant is empty
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantCtxKey, tenantID)
+}
+
+// GetTenant returns the tenant the caller is scoped to, empty if the caller is not scoped
+func GetTenant(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantCtxKey).(string)
+	return tenantID
+}
+
 func HasPerm(ctx context.Context, defaultPerm auth.Permission, perms auth.Permission) bool {
 	callerPerms, ok := ctx.Value(permCtxKey).([]auth.Permission)
 	if !ok {
@@ -47,7 +102,7 @@ func HasPerm(ctx context.Context, defaultPerm auth.Permission, perms auth.Permis
 			if p == defaultPerm {
 				return true
 			}
-			if p == callerPerm {
+			if p == callerPerm || inheritsPerm(callerPerm, p) {
 				return true
 			}
 		}
@@ -89,7 +144,7 @@ func PermissionedProxy(validPerms []auth.Permission, defaultPerms auth.Permissio
 			err := xerrors.Errorf("missing permission to invoke '%s' (need '%s')", field.Name, requiredPerms)
 
 			ctx := args[0].Interface().(context.Context)
-			if HasPerm(ctx, defaultPerms, requiredPerms) {
+			if HasPerm(ctx, defaultPerms, requiredPerms) && AllowRoleAccess(ctx, requiredPerms, field.Name) {
 				if AllowUserAccess(ctx, requiredPerms, field.Name) {
 					return fn.Call(args)
 				}
@@ -112,6 +167,61 @@ func PermissionedProxy(validPerms []auth.Permission, defaultPerms auth.Permissio
 	}
 }
 
+// inheritsPerm checks whether the role inherits the permission
+func inheritsPerm(role, perm auth.Permission) bool {
+	for _, p := range inheritedPerms[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowRoleAccess checks the role restrictions that perm tags can not express,
+// callers with only the read-only role can invoke the query methods only and
+// callers scoped to a tenant can invoke the tenant methods only
+func AllowRoleAccess(ctx context.Context, perms auth.Permission, funcName string) bool {
+	if GetTenant(ctx) != "" && !tenantMethods[funcName] {
+		return false
+	}
+
+	callerPerms, ok := ctx.Value(permCtxKey).([]auth.Permission)
+	if !ok {
+		return true
+	}
+
+	for _, perm := range callerPerms {
+		if perm != RoleReadOnly {
+			return true
+		}
+	}
+
+	for _, p := range split(perms) {
+		if p == RoleDefault {
+			return true
+		}
+	}
+
+	return IsQueryMethod(funcName)
+}
+
+// IsQueryMethod checks whether the method only queries, query methods are named with the read-only prefixes
+func IsQueryMethod(funcName string) bool {
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(funcName, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPerms returns the permissions of the caller, nil if the caller is not authenticated
+func GetPerms(ctx context.Context) []auth.Permission {
+	perms, _ := ctx.Value(permCtxKey).([]auth.Permission)
+	return perms
+}
+
 func AllowUserAccess(ctx context.Context, perms auth.Permission, funcName string) bool {
 	if !isNeedUserAccessControl(ctx, perms) {
 		return true
@@ -32,6 +32,18 @@ const (
 	NodeDeactivate     // node deactivate
 	NodeOffline        // node offline
 
+	AssetDenied       // the asset is in the denylist
+	AssetAccessDenied // the asset is private and the caller is not in its access control list
+
+	TenantStorageNotEnough // the storage quota of the tenant is used up
+	TenantTrafficExceeded  // the traffic quota of the tenant for the month is used up
+	TenantRateLimited      // the requests of the tenant exceed its rate limit
+	TenantAccessDenied     // the user is not a user of the tenant of the caller
+
+	WebhookLimit // the webhooks of the user or the tenant exceed the limit
+
+	AssetRestoring // the asset is archived and being restored from its storage deal, retry later
+
 	Success = 0
 	Unknown = -1
 )
@@ -0,0 +1,80 @@
+package types
+
+import "time"
+
+// AccountNode the binding between an operator account and a node
+type AccountNode struct {
+	AccountID string    `db:"account_id"`
+	NodeID    string    `db:"node_id"`
+	BindTime  time.Time `db:"bind_time"`
+}
+
+// AccountStats aggregates the statistics of all nodes bound to an account
+type AccountStats struct {
+	AccountID       string
+	NodeCount       int
+	OnlineNodeCount int
+	// total points earned by the nodes of the account
+	Profit Points
+	// online duration in minutes
+	OnlineDuration   int64
+	UploadTraffic    int64
+	DownloadTraffic  int64
+	ValidationCount  int
+	ValidationPassed int
+	ValidationFailed int
+}
+
+// AccountNotificationPrefs notification preferences of an account
+type AccountNotificationPrefs struct {
+	AccountID string `db:"account_id"`
+	// notify the account when a bound node goes offline
+	OfflineAlert bool `db:"offline_alert"`
+	// the node is considered offline after this many minutes without keepalive
+	OfflineMinutes int       `db:"offline_minutes"`
+	Email          string    `db:"email"`
+	Webhook        string    `db:"webhook"`
+	UpdatedTime    time.Time `db:"updated_time"`
+}
+
+// AlertType type of alert
+type AlertType string
+
+const (
+	// AlertTypeNodeOffline the node has been offline longer than the subscribed minutes
+	AlertTypeNodeOffline AlertType = "node_offline"
+	// AlertTypeValidationFailed the node failed the subscribed number of validations in a row
+	AlertTypeValidationFailed AlertType = "validation_failed"
+	// AlertTypeDiskUsage the disk usage of the node crossed the subscribed threshold
+	AlertTypeDiskUsage AlertType = "disk_usage"
+	// AlertTypeDiskFailure the disk of the node is predicted to fail by its SMART attributes or filesystem errors
+	AlertTypeDiskFailure AlertType = "disk_failure"
+)
+
+// AlertSubscription alert subscription of an account
+type AlertSubscription struct {
+	ID        int64  `db:"id"`
+	AccountID string `db:"account_id"`
+	// subscribe to a single node, all nodes bound to the account if empty
+	NodeID string `db:"node_id"`
+	// alert when the node is offline longer than this many minutes, 0 disables the alert
+	OfflineMinutes int `db:"offline_minutes"`
+	// alert when the node fails this many validations in a row, 0 disables the alert
+	ValidationFailures int `db:"validation_failures"`
+	// alert when the disk usage (percent) of the node crosses the threshold, 0 disables the alert
+	DiskUsageThreshold float64 `db:"disk_usage_threshold"`
+	// alert when the disk of the node is predicted to fail
+	DiskFailure bool      `db:"disk_failure"`
+	Webhook     string    `db:"webhook"`
+	Email       string    `db:"email"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// AlertEvent the payload sent to the webhook of the subscription
+type AlertEvent struct {
+	Type      AlertType
+	AccountID string
+	NodeID    string
+	Message   string
+	Time      time.Time
+}
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// ArchivedNode a node archived after offline for long, its info and registration are moved to the archive tables.
+// The node can not log in or register again until it is restored
+type ArchivedNode struct {
+	NodeID       string    `db:"node_id"`
+	NodeType     NodeType  `db:"node_type"`
+	LastSeen     time.Time `db:"last_seen"`
+	ArchivedTime time.Time `db:"archived_time"`
+}
+
+// ListArchivedNodeRsp list archived nodes
+type ListArchivedNodeRsp struct {
+	Total int64           `json:"total"`
+	Data  []*ArchivedNode `json:"data"`
+}
@@ -29,6 +29,95 @@ type PullResult struct {
 type RemoveAssetResult struct {
 	BlocksCount int
 	DiskUsage   float64
+	// AssetCID the asset evicted by the cache policy of the node, empty if the asset is removed by the scheduler
+	AssetCID string
+}
+
+// CachePolicy the order in which a node evicts its cacheable assets
+type CachePolicy string
+
+const (
+	// CachePolicyLRU evicts the least recently retrieved assets first
+	CachePolicyLRU CachePolicy = "lru"
+	// CachePolicyLFU evicts the least frequently retrieved assets first
+	CachePolicyLFU CachePolicy = "lfu"
+	// CachePolicyTTL evicts the assets that expire first
+	CachePolicyTTL CachePolicy = "ttl"
+)
+
+// IsValid returns true if the policy is known
+func (p CachePolicy) IsValid() bool {
+	switch p {
+	case CachePolicyLRU, CachePolicyLFU, CachePolicyTTL:
+		return true
+	}
+	return false
+}
+
+// AssetQoSTier the bandwidth class of an asset, it decides the nodes the replicas are placed on and the order of the nodes
+// the asset is retrieved from
+type AssetQoSTier string
+
+const (
+	// AssetQoSTierStandard places and routes the asset as before the tiers
+	AssetQoSTierStandard AssetQoSTier = "standard"
+	// AssetQoSTierStreaming places the asset on the high bandwidth nodes reachable without nat traversal,
+	// the users are routed to the fastest nodes first
+	AssetQoSTierStreaming AssetQoSTier = "streaming"
+	// AssetQoSTierBulk places the asset on the nodes with the least bandwidth, keeping the fast nodes for the streaming assets
+	AssetQoSTierBulk AssetQoSTier = "bulk"
+)
+
+// IsValid returns true if the tier is known, the empty tier is taken as standard
+func (t AssetQoSTier) IsValid() bool {
+	switch t {
+	case "", AssetQoSTierStandard, AssetQoSTierStreaming, AssetQoSTierBulk:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the tier, standard if the tier is empty
+func (t AssetQoSTier) OrDefault() AssetQoSTier {
+	if t == "" {
+		return AssetQoSTierStandard
+	}
+	return t
+}
+
+// CacheConfig the cache eviction config of a node
+type CacheConfig struct {
+	Policy CachePolicy
+	// MaxDiskUsage the disk usage percent above which the cacheable assets are evicted, 0 disables the eviction
+	MaxDiskUsage float64
+}
+
+// AssetCacheMark marks an asset on a node as cacheable or pinned, the assets are pinned unless marked cacheable
+type AssetCacheMark struct {
+	CID       string
+	Cacheable bool
+	// Expiration the expiration of the asset, used by the ttl policy
+	Expiration time.Time
+}
+
+// CachedAsset a cacheable asset of a node
+type CachedAsset struct {
+	CID         string
+	Size        int64
+	Expiration  time.Time
+	AccessCount int64 // retrievals since the node started
+	LastAccess  time.Time
+}
+
+// CacheComposition the composition of the asset cache of a node
+type CacheComposition struct {
+	CacheConfig
+	DiskUsage      float64
+	PinnedCount    int
+	CacheableCount int
+	CacheableSize  int64
+	// Cacheable the cacheable assets in eviction order
+	Cacheable []*CachedAsset
 }
 
 // AssetRecord represents information about an asset record
@@ -51,6 +140,11 @@ type AssetRecord struct {
 	ReplenishReplicas int64 `db:"replenish_replicas"`
 	ReplicaInfos      []*ReplicaInfo
 
+	QoSTier     AssetQoSTier     `db:"qos_tier"`
+	VideoFormat AssetVideoFormat `db:"video_format"`
+	// OwnerCount the users owning the asset, the asset is purged after its last owner releases it
+	OwnerCount int64 `db:"owner_count"`
+
 	SPCount int64
 }
 
@@ -65,6 +159,7 @@ type UserAssetDetail struct {
 	TotalSize   int64     `db:"total_size"`
 	Password    string    `db:"password"`
 	GroupID     int       `db:"group_id"`
+	ContentType string    `db:"content_type"`
 }
 
 type AssetOverview struct {
@@ -114,6 +209,11 @@ type PullAssetReq struct {
 
 	CandidateNodeList []string
 	EdgeNodeList      []string
+
+	// QoSTier the bandwidth class of the asset, standard if empty
+	QoSTier AssetQoSTier
+	// VideoFormat marks the asset as hls or dash, the nodes prefetch the segments of the marked assets
+	VideoFormat AssetVideoFormat
 }
 
 // AssetType represents the type of a asset
@@ -266,6 +366,47 @@ type AssetStatus struct {
 	IsVisitOutOfLimit bool
 }
 
+// DownloadSources the urls of the nodes holding the asset, a download can be resumed from any of them
+// with the Range and If-Range headers, the file served by the nodes has the same etag
+type DownloadSources struct {
+	AssetCID  string
+	AssetName string
+	ETag      string
+	URLs      []string
+	// Encryption the metadata to decrypt the downloaded file, nil if the asset is not encrypted
+	Encryption *AssetEncryption
+	// QuotaWarning set if the traffic of the tenant of the user passes its soft limit
+	QuotaWarning string `json:",omitempty"`
+}
+
+// ParallelDownloadReq the request to download the asset from multiple edges in parallel
+type ParallelDownloadReq struct {
+	AssetCID string
+	// Size the size of the file, from the Content-Length of the HEAD response
+	Size int64
+	// Parallelism the maximum number of edges to download from
+	Parallelism int
+}
+
+// DownloadSegment a byte range of the file assigned to a node, Start and End are inclusive
+type DownloadSegment struct {
+	NodeID  string
+	Address string
+	Tk      *Token
+	Start   int64
+	End     int64
+	// Protocols the transfer protocols negotiated with the node, in order of preference
+	Protocols []TransferProtocol
+}
+
+// ParallelDownloadPlan the segments of the file assigned to the edges, the segments cover the whole file in order
+type ParallelDownloadPlan struct {
+	AssetCID string
+	Size     int64
+	ETag     string
+	Segments []*DownloadSegment
+}
+
 type MinioUploadFileEvent struct {
 	AssetCID   string
 	Size       int64
@@ -278,3 +419,69 @@ type AssetView struct {
 	// key bucketID, value bucketHash
 	BucketHashes map[uint32]string
 }
+
+// SeedingProgress the progress of the edges pulling an asset from the candidates in waves
+type SeedingProgress struct {
+	CID              string
+	State            string
+	EdgeReplicas     int   // succeeded edge replicas
+	NeedEdgeReplicas int64 // edge replicas required by the asset
+	// Wave the number of the current wave, 0 if the asset is not pulled in waves
+	Wave         int
+	WaveSize     int
+	WaveSucceeds int // edge replicas pulled by the current wave
+	WaveDone     bool
+	// Sources the upload slots of the candidates taken by the current wave
+	Sources   map[string]int
+	Backoffs  int // waits for the saturated candidates since the current wave started
+	StartTime time.Time
+}
+
+// AssetManifest the blocks of an asset stored on a node, a node resuming an interrupted pull
+// compares the manifest with its pulled blocks and pulls only the missing ones
+type AssetManifest struct {
+	CID    string
+	Blocks []*ManifestBlock
+	Size   int64 // the total size of the blocks
+}
+
+// ManifestBlock a block of the asset manifest
+type ManifestBlock struct {
+	CID  string
+	Size int64
+}
+
+// PartialReplica a replica whose pull failed after some of the blocks were pulled, the node keeps the blocks
+// and resumes from them when the asset is pulled again
+type PartialReplica struct {
+	NodeID      string
+	IsCandidate bool
+	DoneSize    int64
+	Online      bool
+}
+
+// ReplicaProgress the replicas of an asset, the partial replicas are counted by the share of the asset they hold
+type ReplicaProgress struct {
+	CID                   string
+	State                 string
+	TotalSize             int64
+	NeedEdgeReplicas      int64
+	NeedCandidateReplicas int64
+	EdgeReplicas          int // succeeded edge replicas
+	CandidateReplicas     int // succeeded candidate replicas
+	// EffectiveEdgeReplicas the succeeded edge replicas plus the shares of the partial edge replicas
+	EffectiveEdgeReplicas      float64
+	EffectiveCandidateReplicas float64
+	Partials                   []*PartialReplica
+}
+
+// AssetACL the access control list of a private asset of the user, only the owners of the asset
+// and the listed users and api keys can download it
+type AssetACL struct {
+	CID string
+	// Users the ids of the users allowed to download the asset
+	Users []string
+	// APIKeys the api keys allowed to download the asset, as <user id>/<key name>
+	APIKeys     []string
+	CreatedTime time.Time
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"regexp"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// MaxAssetLabels the labels an asset of a user has at most
+	MaxAssetLabels = 16
+	// maxAssetLabelValueLength the bytes of the value of a label at most
+	maxAssetLabelValueLength = 128
+	// maxAssetContentTypeLength the bytes of the content type of an asset at most
+	maxAssetContentTypeLength = 128
+)
+
+// assetLabelName the names of the labels, eg. app, team.io/project
+var assetLabelName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/-]{0,62}$`)
+
+// AssetLabels the labels of an asset of a user, the assets of the user are searched by their labels
+type AssetLabels map[string]string
+
+// Validate checks the names, the values and the number of the labels
+func (l AssetLabels) Validate() error {
+	if len(l) > MaxAssetLabels {
+		return xerrors.Errorf("an asset has %d labels at most", MaxAssetLabels)
+	}
+
+	for name, value := range l {
+		if !assetLabelName.MatchString(name) {
+			return xerrors.Errorf("invalid label name %q", name)
+		}
+
+		if len(value) > maxAssetLabelValueLength {
+			return xerrors.Errorf("value of label %s exceeds %d bytes", name, maxAssetLabelValueLength)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAssetMetadata checks the content type and the labels attached to an asset
+func ValidateAssetMetadata(contentType string, labels AssetLabels) error {
+	if len(contentType) > maxAssetContentTypeLength {
+		return xerrors.Errorf("content type exceeds %d bytes", maxAssetContentTypeLength)
+	}
+
+	return labels.Validate()
+}
+
+// SearchAssetsReq the conditions the assets of a user are searched by, the empty conditions match all assets
+type SearchAssetsReq struct {
+	UserID string
+	// Labels the labels the assets have, an empty value matches any value of the label
+	Labels AssetLabels
+	// NamePrefix the prefix of the names of the assets
+	NamePrefix string
+	// MinSize and MaxSize the range of the sizes of the assets in bytes, 0 is unbounded
+	MinSize int64
+	MaxSize int64
+	// CreatedAfter and CreatedBefore the range of the creation time of the assets, zero is unbounded
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+	Offset        int
+}
+
+// Validate checks the labels and the ranges of the conditions
+func (r *SearchAssetsReq) Validate() error {
+	if len(r.Labels) > MaxAssetLabels {
+		return xerrors.Errorf("search by %d labels at most", MaxAssetLabels)
+	}
+
+	for name := range r.Labels {
+		if !assetLabelName.MatchString(name) {
+			return xerrors.Errorf("invalid label name %q", name)
+		}
+	}
+
+	if r.MinSize < 0 || r.MaxSize < 0 || (r.MaxSize > 0 && r.MinSize > r.MaxSize) {
+		return xerrors.Errorf("invalid size range %d-%d", r.MinSize, r.MaxSize)
+	}
+
+	if !r.CreatedAfter.IsZero() && !r.CreatedBefore.IsZero() && r.CreatedBefore.Before(r.CreatedAfter) {
+		return xerrors.New("created before is earlier than created after")
+	}
+
+	return nil
+}
+
+// UserAssetMetadata an asset of a user found by the search with its cid and labels
+type UserAssetMetadata struct {
+	UserAssetDetail
+	AssetCID string      `db:"cid"`
+	Labels   AssetLabels `db:"-"`
+}
+
+// SearchAssetsRsp the assets of a user matching the search, the latest first
+type SearchAssetsRsp struct {
+	Total int                  `json:"total"`
+	Data  []*UserAssetMetadata `json:"data"`
+}
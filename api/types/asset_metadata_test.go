@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssetLabelsValidate(t *testing.T) {
+	cases := []struct {
+		labels AssetLabels
+		valid  bool
+	}{
+		{labels: nil, valid: true},
+		{labels: AssetLabels{"app": "video", "team.io/project": "titan", "empty": ""}, valid: true},
+		{labels: AssetLabels{"": "value"}, valid: false},
+		{labels: AssetLabels{"-app": "video"}, valid: false},
+		{labels: AssetLabels{"app name": "video"}, valid: false},
+		{labels: AssetLabels{strings.Repeat("a", 64): "value"}, valid: false},
+		{labels: AssetLabels{"app": strings.Repeat("v", 129)}, valid: false},
+	}
+
+	for _, c := range cases {
+		if err := c.labels.Validate(); (err == nil) != c.valid {
+			t.Errorf("labels %v: expected valid %v, got %v", c.labels, c.valid, err)
+		}
+	}
+
+	many := make(AssetLabels)
+	for i := 0; i <= MaxAssetLabels; i++ {
+		many[fmt.Sprintf("label%d", i)] = "value"
+	}
+	if err := many.Validate(); err == nil {
+		t.Errorf("expected an error for %d labels", len(many))
+	}
+
+	if err := ValidateAssetMetadata(strings.Repeat("t", 129), nil); err == nil {
+		t.Error("expected an error for a long content type")
+	}
+}
+
+func TestSearchAssetsReqValidate(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		req   SearchAssetsReq
+		valid bool
+	}{
+		{req: SearchAssetsReq{}, valid: true},
+		{req: SearchAssetsReq{Labels: AssetLabels{"app": ""}, MinSize: 10, MaxSize: 10}, valid: true},
+		{req: SearchAssetsReq{MinSize: 10}, valid: true},
+		{req: SearchAssetsReq{MinSize: 10, MaxSize: 5}, valid: false},
+		{req: SearchAssetsReq{MinSize: -1}, valid: false},
+		{req: SearchAssetsReq{Labels: AssetLabels{"bad name": "x"}}, valid: false},
+		{req: SearchAssetsReq{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)}, valid: false},
+		{req: SearchAssetsReq{CreatedAfter: now}, valid: true},
+	}
+
+	for i, c := range cases {
+		if err := c.req.Validate(); (err == nil) != c.valid {
+			t.Errorf("case %d: expected valid %v, got %v", i, c.valid, err)
+		}
+	}
+}
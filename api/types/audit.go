@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+// AuditLog a mutation made by an admin, operator or web caller, audit logs are append only
+type AuditLog struct {
+	ID int64 `db:"id"`
+	// id of the token of the caller
+	Actor string `db:"actor"`
+	// roles of the caller, separated by comma
+	Role   string `db:"role"`
+	Method string `db:"method"`
+	// json encoded arguments of the method
+	Args string `db:"args"`
+	// error of the method, empty if succeeded
+	Result      string    `db:"result"`
+	Success     bool      `db:"success"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// ListAuditLogsReq the filters of audit logs, empty filters match all
+type ListAuditLogsReq struct {
+	Actor  string
+	Method string
+	Start  time.Time
+	End    time.Time
+	Limit  int
+	Offset int
+}
+
+// ListAuditLogRsp list audit logs
+type ListAuditLogRsp struct {
+	Data  []*AuditLog `json:"data"`
+	Total int64       `json:"total"`
+}
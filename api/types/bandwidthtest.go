@@ -0,0 +1,107 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// the query parameters of the bandwidth test url of a candidate, signed by the scheduler
+const (
+	BandwidthTestIDParam        = "test"
+	BandwidthTestNodeParam      = "node"
+	BandwidthTestCandidateParam = "candidate"
+	BandwidthTestDurationParam  = "duration"
+	BandwidthTestExpiresParam   = "expires"
+	BandwidthTestSignatureParam = "signature"
+	// BandwidthTestTrailer the trailer of the download response carrying the measurement of the candidate
+	BandwidthTestTrailer = "Titan-Bandwidth-Measurement"
+)
+
+// BandwidthTestContent returns the content of the bandwidth test url signed by the scheduler
+func BandwidthTestContent(testID, nodeID, candidateID string, duration int, expires int64) []byte {
+	return []byte(fmt.Sprintf("titan-bandwidth-test\n%s\n%s\n%s\n%d\n%d", testID, nodeID, candidateID, duration, expires))
+}
+
+// BandwidthTestStatus status of a bandwidth test
+type BandwidthTestStatus string
+
+const (
+	// BandwidthTestRunning the edge is measuring
+	BandwidthTestRunning BandwidthTestStatus = "running"
+	// BandwidthTestSucceeded the bandwidth of the node is updated by the test
+	BandwidthTestSucceeded BandwidthTestStatus = "succeeded"
+	// BandwidthTestFailed no measurement of the candidates is valid
+	BandwidthTestFailed BandwidthTestStatus = "failed"
+)
+
+// BandwidthTest an on-demand bandwidth and latency test of an edge against the nearest candidates
+type BandwidthTest struct {
+	ID     string              `db:"id"`
+	NodeID string              `db:"node_id"`
+	Status BandwidthTestStatus `db:"status"`
+	// bytes per second, the best of the candidates
+	BandwidthUp   int64 `db:"bandwidth_up"`
+	BandwidthDown int64 `db:"bandwidth_down"`
+	// milliseconds, the lowest of the candidates
+	Latency      int64     `db:"latency"`
+	Message      string    `db:"message"`
+	CreatedTime  time.Time `db:"created_time"`
+	FinishedTime time.Time `db:"finished_time"`
+}
+
+// ListBandwidthTestRsp list of the bandwidth tests of a node
+type ListBandwidthTestRsp struct {
+	Total int              `json:"total"`
+	Tests []*BandwidthTest `json:"tests"`
+}
+
+// BandwidthTestTarget a candidate the edge measures against
+type BandwidthTestTarget struct {
+	CandidateID string
+	// the bandwidth test url of the candidate signed by the scheduler
+	URL string
+}
+
+// BandwidthTestReq asks the edge to measure its bandwidth against the candidates one by one
+type BandwidthTestReq struct {
+	TestID  string
+	Targets []*BandwidthTestTarget
+	// seconds of each transfer
+	Duration int
+}
+
+// BandwidthMeasurement a transfer between the edge and a candidate timed and signed by the candidate
+type BandwidthMeasurement struct {
+	TestID      string
+	NodeID      string
+	CandidateID string
+	// Upload the edge uploads to the candidate, otherwise the edge downloads from it
+	Upload bool
+	Bytes  int64
+	// milliseconds
+	Duration int64
+	Sign     []byte
+}
+
+// SignData returns the content signed by the candidate
+func (m *BandwidthMeasurement) SignData() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%t\n%d\n%d", m.TestID, m.NodeID, m.CandidateID, m.Upload, m.Bytes, m.Duration))
+}
+
+// BytesPerSecond returns the measured bandwidth
+func (m *BandwidthMeasurement) BytesPerSecond() int64 {
+	if m.Duration <= 0 {
+		return 0
+	}
+	return m.Bytes * 1000 / m.Duration
+}
+
+// BandwidthTestReport the measurements of the edge against a candidate
+type BandwidthTestReport struct {
+	CandidateID string
+	// milliseconds, the lowest round trip time measured by the edge
+	Latency  int64
+	Upload   *BandwidthMeasurement
+	Download *BandwidthMeasurement
+	Err      string
+}
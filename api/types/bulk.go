@@ -0,0 +1,90 @@
+package types
+
+import "time"
+
+// BulkOperation an admin operation run on many nodes by a bulk job
+type BulkOperation string
+
+const (
+	// BulkDeactivate deactivates the nodes after the countdown of the job
+	BulkDeactivate BulkOperation = "deactivate"
+	// BulkBan deactivates the nodes at once, the nodes can not log in again
+	BulkBan BulkOperation = "ban"
+	// BulkRecomputeWeights distributes the select weights of the online nodes again by their current scores
+	BulkRecomputeWeights BulkOperation = "recompute_weights"
+	// BulkRevalidate validates the online nodes out of the validation rounds
+	BulkRevalidate BulkOperation = "revalidate"
+)
+
+// BulkJobReq starts a bulk job on the listed nodes, or on the nodes selected by the filter if no node is listed
+type BulkJobReq struct {
+	Operation BulkOperation
+	NodeIDs   []string
+	// Filter the conditions separated by spaces the nodes of the scheduler meet, e.g. "type=edge area=Asia-China offline>7d",
+	// the conditions are type=edge|candidate, area=<area id>, offline>duration and offline<duration, the duration is
+	// in go format with the d unit for days, offline<duration includes the online nodes
+	Filter string
+	// DeactivateHours the deactivation countdown of the deactivate operation
+	DeactivateHours int
+}
+
+// BulkJobStatus status of a bulk job
+type BulkJobStatus string
+
+const (
+	// BulkJobRunning the nodes of the job are being processed
+	BulkJobRunning BulkJobStatus = "running"
+	// BulkJobCompleted all the nodes of the job are processed
+	BulkJobCompleted BulkJobStatus = "completed"
+	// BulkJobCanceled the job was canceled before all the nodes were processed
+	BulkJobCanceled BulkJobStatus = "canceled"
+)
+
+// BulkJob an admin operation run asynchronously on the nodes by the scheduler the job is started on
+type BulkJob struct {
+	ID        string        `db:"id"`
+	Operation BulkOperation `db:"operation"`
+	Filter    string        `db:"filter"`
+	// ServerID the scheduler running the job
+	ServerID string `db:"server_id"`
+	// Total the nodes of the job, the nodes not succeeded or failed are pending
+	Total        int           `db:"total"`
+	Succeeded    int           `db:"succeeded"`
+	Failed       int           `db:"failed"`
+	Status       BulkJobStatus `db:"status"`
+	CreatedTime  time.Time     `db:"created_time"`
+	FinishedTime time.Time     `db:"finished_time"`
+}
+
+// ListBulkJobRsp list bulk jobs
+type ListBulkJobRsp struct {
+	Total int64      `json:"total"`
+	Data  []*BulkJob `json:"data"`
+}
+
+// BulkJobItemStatus status of a node of a bulk job
+type BulkJobItemStatus string
+
+const (
+	// BulkJobItemPending the node is not processed yet
+	BulkJobItemPending BulkJobItemStatus = "pending"
+	// BulkJobItemSucceeded the operation succeeded on the node
+	BulkJobItemSucceeded BulkJobItemStatus = "succeeded"
+	// BulkJobItemFailed the operation failed on the node, the message is the error
+	BulkJobItemFailed BulkJobItemStatus = "failed"
+)
+
+// BulkJobItem the result of the operation of a bulk job on a node
+type BulkJobItem struct {
+	JobID       string            `db:"job_id"`
+	NodeID      string            `db:"node_id"`
+	Status      BulkJobItemStatus `db:"status"`
+	Message     string            `db:"message"`
+	UpdatedTime time.Time         `db:"updated_time"`
+}
+
+// ListBulkJobItemRsp list the nodes of a bulk job
+type ListBulkJobItemRsp struct {
+	Total int64          `json:"total"`
+	Data  []*BulkJobItem `json:"data"`
+}
@@ -6,6 +6,25 @@ type SchedulerCfg struct {
 	AreaID       string `db:"area_id"`
 	Weight       int    `db:"weight"`
 	AccessToken  string `db:"access_token"`
+	// Zones the areas served by the scheduler besides AreaID
+	Zones []string `db:"-"`
+	// ServerID and Version the id and the version of the scheduler
+	ServerID string `db:"-"`
+	Version  string `db:"-"`
+	// Capacity the nodes the scheduler can serve, not limited if 0
+	Capacity int `db:"-"`
+	// Edges and Candidates the nodes online on the scheduler
+	Edges      int `db:"-"`
+	Candidates int `db:"-"`
+	// CPUUsage the cpu usage of the scheduler in percent
+	CPUUsage float64 `db:"-"`
+	// DBLatency the latency of the database of the scheduler in milliseconds
+	DBLatency int64 `db:"-"`
+}
+
+// Full checks if the scheduler serves as many nodes as its capacity
+func (c *SchedulerCfg) Full() bool {
+	return c.Capacity > 0 && c.Edges+c.Candidates >= c.Capacity
 }
 
 type MinioConfig struct {
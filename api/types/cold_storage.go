@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+// ColdAssetState the state of an asset offloaded into the filecoin storage deals
+type ColdAssetState string
+
+const (
+	// ColdAssetProposed the deal of the asset is proposed, the asset is served by its hot replicas
+	ColdAssetProposed ColdAssetState = "proposed"
+	// ColdAssetActive the deal of the asset is active on chain, the hot replicas are kept while the asset is retrieved
+	ColdAssetActive ColdAssetState = "active"
+	// ColdAssetArchived the hot replicas of the asset are removed, the asset is kept in the deal only
+	ColdAssetArchived ColdAssetState = "archived"
+	// ColdAssetRestoring a retrieval of the archived asset arrived, the asset is pulled back from the deal
+	ColdAssetRestoring ColdAssetState = "restoring"
+	// ColdAssetFailed the deal of the asset failed, the asset is offloaded again later
+	ColdAssetFailed ColdAssetState = "failed"
+)
+
+// ColdAsset an asset offloaded into the filecoin storage deals with the state of its deal
+type ColdAsset struct {
+	Hash string `db:"hash"`
+	CID  string `db:"cid"`
+	Size int64  `db:"size"`
+	// DealID the id the deal-making component tracks the deals of the asset by
+	DealID string         `db:"deal_id"`
+	State  ColdAssetState `db:"state"`
+	// Provider the storage provider of the deal, PieceCID the piece the asset is sealed in, ChainDealID the id of the deal on chain
+	Provider    string    `db:"provider"`
+	PieceCID    string    `db:"piece_cid"`
+	ChainDealID int64     `db:"chain_deal_id"`
+	Message     string    `db:"message"`
+	CreatedTime time.Time `db:"created_time"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
+
+// ListColdAssetsRsp the assets offloaded into the storage deals
+type ListColdAssetsRsp struct {
+	Total int          `json:"total"`
+	Data  []*ColdAsset `json:"data"`
+}
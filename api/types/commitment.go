@@ -0,0 +1,61 @@
+package types
+
+import "time"
+
+// NodeCommitment the daily window the operator commits the node to be online
+type NodeCommitment struct {
+	NodeID string `db:"node_id"`
+	// StartHour and EndHour the window [start, end) of the day in the scheduler time zone, the window may wrap midnight
+	StartHour   int       `db:"start_hour"`
+	EndHour     int       `db:"end_hour"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// Window returns the window containing t, ok is false if t is not in any window
+func (c *NodeCommitment) Window(t time.Time) (start, end time.Time, ok bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if c.EndHour <= c.StartHour && t.Hour() < c.EndHour {
+		// in the part after midnight of the window started yesterday
+		day = day.AddDate(0, 0, -1)
+	}
+
+	start = day.Add(time.Duration(c.StartHour) * time.Hour)
+	end = day.Add(time.Duration(c.EndHour) * time.Hour)
+	if c.EndHour <= c.StartHour {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	if t.Before(start) || !t.Before(end) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
+
+// NodeCommitmentRecord the presence of the node in a commitment window
+type NodeCommitmentRecord struct {
+	NodeID      string    `db:"node_id"`
+	WindowStart time.Time `db:"window_start"`
+	WindowEnd   time.Time `db:"window_end"`
+	// CheckedMinutes and PresentMinutes the minutes of the window checked and the minutes the node was online in
+	CheckedMinutes int `db:"checked_minutes"`
+	PresentMinutes int `db:"present_minutes"`
+	// Kept the node was online long enough in the window
+	Kept bool `db:"kept"`
+	// EarnedPoints points the node earned in the window, BonusPoints the bonus granted for the kept window
+	EarnedPoints Points `db:"earned_points"`
+	BonusPoints  Points `db:"bonus_points"`
+}
+
+// ListNodeCommitmentRecordRsp list commitment records
+type ListNodeCommitmentRecordRsp struct {
+	Total int64                   `json:"total"`
+	Data  []*NodeCommitmentRecord `json:"data"`
+}
+
+// NodeActivity the cumulative points and the last seen time of a node
+type NodeActivity struct {
+	NodeID   string    `db:"node_id"`
+	Profit   Points    `db:"profit"`
+	LastSeen time.Time `db:"last_seen"`
+}
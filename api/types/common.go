@@ -16,12 +16,23 @@ const (
 	EventNodeOnline EventTopics = "node_online"
 	// EventNodeOffline node offline event
 	EventNodeOffline EventTopics = "node_offline"
+	// EventValidationResult validation result of a node event, published with the *ValidationResultInfo
+	EventValidationResult EventTopics = "validation_result"
+	// EventHardwareProof hardware challenge proof of a node event, published with the *HardwareProof
+	EventHardwareProof EventTopics = "hardware_proof"
 )
 
 func (t EventTopics) String() string {
 	return string(t)
 }
 
+// NodeEvent the online or offline event of a node
+type NodeEvent struct {
+	NodeID string
+	Event  EventTopics
+	Time   time.Time
+}
+
 // ValidationInfo Validation, election related information
 type ValidationInfo struct {
 	NextElectionTime time.Time
@@ -37,6 +48,12 @@ type JWTPayload struct {
 	Extend string
 	// The sub permission of user
 	AccessControlList []UserAccessControl
+	// IssuedAt unix nano time the token is issued, tokens issued before the revocation of their ID are rejected
+	IssuedAt int64 `json:",omitempty"`
+	// Host the ip the token is issued to, a node token is only accepted from this host
+	Host string `json:",omitempty"`
+	// Tenant the tenant the token is scoped to, the token acts on the users of the tenant only
+	Tenant string `json:",omitempty"`
 }
 
 // StorageStats storage stats of user
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ControlMethod the control calls of the scheduler to the node multiplexed over the control channel
+type ControlMethod string
+
+const (
+	// ControlExecuteValidation asks the node to be validated by the validator of the request
+	ControlExecuteValidation ControlMethod = "execute_validation"
+	// ControlPullAsset asks the node to pull the asset from the sources
+	ControlPullAsset ControlMethod = "pull_asset"
+	// ControlApplyNodeConfig pushes the config to the node
+	ControlApplyNodeConfig ControlMethod = "apply_node_config"
+)
+
+// ControlCall a call of the scheduler to the node, the params are the json encoded arguments of the method
+type ControlCall struct {
+	ID     string
+	Method ControlMethod
+	Params json.RawMessage
+	// Timeout the scheduler stops waiting for the result after the timeout since the call is picked by the node
+	Timeout time.Duration
+}
+
+// ControlResult the result of a control call returned by the node, the result is the json encoded return value of the method
+type ControlResult struct {
+	ID     string
+	Result json.RawMessage
+	Error  string
+}
+
+// PullAssetParams the arguments of the pull asset control call
+type PullAssetParams struct {
+	AssetCID string
+	Sources  []*CandidateDownloadInfo
+}
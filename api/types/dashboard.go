@@ -0,0 +1,48 @@
+package types
+
+import "time"
+
+// DashboardStats the statistics of the scheduler shown by the web dashboard
+type DashboardStats struct {
+	AreaID string `json:"area_id"`
+	// Nodes the online nodes grouped by the area they report
+	Nodes []*NodeGeoStats `json:"nodes"`
+	// Assets the assets of the scheduler grouped by state
+	Assets []*AssetStateCount `json:"assets"`
+	// Validations the validations of each hour in the last day
+	Validations []*ValidationPassRate `json:"validations"`
+	// Points the nodes grouped by the magnitude of their points
+	Points []*PointsBucket `json:"points"`
+	// UpdatedTime the time the statistics were computed
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// NodeGeoStats the online nodes of an area
+type NodeGeoStats struct {
+	Geo        string `json:"geo"`
+	Edges      int    `json:"edges"`
+	Candidates int    `json:"candidates"`
+	// Abnormal the online nodes excluded from the replicas and the validations
+	Abnormal int `json:"abnormal"`
+}
+
+// AssetStateCount the assets in a state
+type AssetStateCount struct {
+	State string `json:"state" db:"state"`
+	Count int64  `json:"count" db:"count"`
+}
+
+// ValidationPassRate the validations started in an hour and the ones passed
+type ValidationPassRate struct {
+	Hour   time.Time `json:"hour"`
+	Total  int64     `json:"total"`
+	Passed int64     `json:"passed"`
+}
+
+// PointsBucket the nodes with the points in [Min, Max)
+type PointsBucket struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Nodes  int64   `json:"nodes"`
+	Points float64 `json:"points"`
+}
@@ -0,0 +1,73 @@
+package types
+
+import "time"
+
+// SchedulingDecisionKind the kind of a scheduling decision
+type SchedulingDecisionKind string
+
+const (
+	// DecisionPullCandidates candidates chosen to pull an asset
+	DecisionPullCandidates SchedulingDecisionKind = "pull_candidates"
+	// DecisionPullEdges edges chosen to pull an asset
+	DecisionPullEdges SchedulingDecisionKind = "pull_edges"
+	// DecisionValidation nodes paired with a validator in a validation round
+	DecisionValidation SchedulingDecisionKind = "validation"
+	// DecisionRetrievalEdges edges returned to a client to retrieve an asset
+	DecisionRetrievalEdges SchedulingDecisionKind = "retrieval_edges"
+	// DecisionRetrievalCandidates candidates returned to retrieve an asset
+	DecisionRetrievalCandidates SchedulingDecisionKind = "retrieval_candidates"
+)
+
+// DecisionOutcomeChosen the outcome of the steps choosing the node, the other outcomes are the reasons the node is filtered out
+const DecisionOutcomeChosen = "chosen"
+
+// DecisionStep a node considered by a scheduling decision
+type DecisionStep struct {
+	NodeID string `json:"n"`
+	// Outcome chosen or the reason the node is filtered out
+	Outcome string `json:"o"`
+	// Weight the select weight of the node at the time
+	Weight int `json:"w,omitempty"`
+	// Value the value the node is drawn or ranked by, e.g. the random number of the weighted draw, the titan disk usage
+	// or the upload bandwidth
+	Value float64 `json:"v,omitempty"`
+}
+
+// SchedulingDecision the nodes considered by a scheduling decision and why they were chosen or filtered out
+type SchedulingDecision struct {
+	ID   int64                  `db:"id"`
+	Kind SchedulingDecisionKind `db:"kind"`
+	// Subject the asset hash or the validation round id
+	Subject string `db:"subject"`
+	// Params the inputs of the decision, e.g. the number of nodes needed
+	Params     string `db:"params"`
+	Considered int    `db:"considered"`
+	Chosen     int    `db:"chosen"`
+	// Truncated the steps beyond the limit are only counted in Filtered
+	Truncated   bool      `db:"truncated"`
+	CreatedTime time.Time `db:"created_time"`
+	// Filtered the number of the nodes filtered out by reason, Steps the nodes in the order considered,
+	// they are only loaded when the decision is replayed
+	Filtered map[string]int  `db:"-"`
+	Steps    []*DecisionStep `db:"-"`
+	// Detail Filtered and Steps compressed
+	Detail []byte `db:"detail" json:"-"`
+}
+
+// ListSchedulingDecisionsReq the filters and page of the decisions, zero value filters are ignored
+type ListSchedulingDecisionsReq struct {
+	Kind    SchedulingDecisionKind
+	Subject string
+	// NodeID the decisions choosing the node
+	NodeID string
+	Start  time.Time
+	End    time.Time
+	Limit  int
+	Offset int
+}
+
+// ListSchedulingDecisionRsp list scheduling decisions
+type ListSchedulingDecisionRsp struct {
+	Total int64                 `json:"total"`
+	Data  []*SchedulingDecision `json:"data"`
+}
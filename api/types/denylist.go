@@ -0,0 +1,63 @@
+package types
+
+import "time"
+
+// DenylistEntry a banned asset, the scheduler refuses to pull it, purges its replicas from the nodes
+// and does not route the retrievals of it
+type DenylistEntry struct {
+	Hash   string `db:"hash"`
+	CID    string `db:"cid"`
+	Reason string `db:"reason"`
+	// Source "admin" if added by the admin, otherwise the name of the imported denylist
+	Source      string    `db:"source"`
+	Actor       string    `db:"actor"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// DenylistEventType the action taken on a banned asset
+type DenylistEventType string
+
+const (
+	// DenylistEventAdd the asset is added to the denylist
+	DenylistEventAdd DenylistEventType = "add"
+	// DenylistEventRemove the asset is removed from the denylist
+	DenylistEventRemove DenylistEventType = "remove"
+	// DenylistEventPurge the replicas of the asset are removed from the nodes
+	DenylistEventPurge DenylistEventType = "purge"
+	// DenylistEventRefusePull a pull or upload of the asset is refused
+	DenylistEventRefusePull DenylistEventType = "refuse_pull"
+	// DenylistEventBlockRetrieval a retrieval of the asset is not routed
+	DenylistEventBlockRetrieval DenylistEventType = "block_retrieval"
+)
+
+// DenylistEvent the audit trail of the denylist, the events are append only
+type DenylistEvent struct {
+	ID          int64             `db:"id"`
+	Hash        string            `db:"hash"`
+	CID         string            `db:"cid"`
+	Event       DenylistEventType `db:"event"`
+	Detail      string            `db:"detail"`
+	Actor       string            `db:"actor"`
+	CreatedTime time.Time         `db:"created_time"`
+}
+
+// ListDenylistRsp list the banned assets
+type ListDenylistRsp struct {
+	Total   int64            `json:"total"`
+	Entries []*DenylistEntry `json:"entries"`
+}
+
+// ListDenylistEventsRsp list the events of the denylist
+type ListDenylistEventsRsp struct {
+	Total  int64            `json:"total"`
+	Events []*DenylistEvent `json:"events"`
+}
+
+// DenylistImportResult the result of importing a denylist
+type DenylistImportResult struct {
+	Added int
+	// Skipped the lines that do not ban a cid, such as the comments, the allow rules and the hashed entries
+	Skipped int
+	// Invalid the lines that can not be parsed, truncated
+	Invalid []string
+}
@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// RuntimeDiagnostics the runtime state of the scheduler process for debugging incidents
+type RuntimeDiagnostics struct {
+	StartTime  time.Time
+	NumCPU     int
+	GOMAXPROCS int
+	Goroutines int
+	// memory statistics in bytes
+	HeapAlloc   uint64
+	HeapInuse   uint64
+	HeapObjects uint64
+	Sys         uint64
+	NumGC       uint32
+	// total stop the world pause of the garbage collections
+	GCPauseTotal time.Duration
+	Registries   []*RegistrySize
+	Timers       []*TimerStatus
+}
+
+// RegistrySize the number of entries of an in-memory registry
+type RegistrySize struct {
+	Name string
+	// Size is -1 if the registry did not answer in time, e.g. it is locked by a stuck task
+	Size int
+}
+
+// TimerStatus the runs of a periodic task
+type TimerStatus struct {
+	Name     string
+	Interval time.Duration
+	Runs     int64
+	// Running the task is running, RunningFor how long the current run has taken
+	Running      bool
+	RunningFor   time.Duration
+	LastStart    time.Time
+	LastEnd      time.Time
+	LastDuration time.Duration
+}
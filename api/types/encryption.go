@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// EncryptionAlgorithm the cipher the client encrypted the asset with
+type EncryptionAlgorithm string
+
+const (
+	// EncryptionAES256GCMChunked the file is split into chunks sealed by aes-256-gcm, see lib/assetcrypt
+	EncryptionAES256GCMChunked EncryptionAlgorithm = "aes-256-gcm-chunked"
+)
+
+// EncryptionKeyMode how the content key of the encrypted asset is held
+type EncryptionKeyMode string
+
+const (
+	// EncryptionKeyClient the content key is held by the client only, the scheduler stores no key at all
+	EncryptionKeyClient EncryptionKeyMode = "client"
+	// EncryptionKeyWrapped the scheduler stores the content key wrapped by a key of the client,
+	// it can not unwrap it
+	EncryptionKeyWrapped EncryptionKeyMode = "wrapped"
+)
+
+// AssetEncryption the metadata a downloading client needs to decrypt the asset encrypted before the upload,
+// the nodes holding the replicas only see the ciphertext
+type AssetEncryption struct {
+	Hash      string              `db:"hash"`
+	UserID    string              `db:"user_id"`
+	Algorithm EncryptionAlgorithm `db:"algorithm"`
+	// ChunkSize the size of the plaintext chunks
+	ChunkSize int64 `db:"chunk_size"`
+	// Nonce the base nonce of the chunks, base64 encoded
+	Nonce string `db:"nonce"`
+	// PlainSize the size of the file before it was encrypted
+	PlainSize int64             `db:"plain_size"`
+	KeyMode   EncryptionKeyMode `db:"key_mode"`
+	// KeyID identifies the key of the client that wrapped the content key, or the content key itself
+	KeyID string `db:"key_id"`
+	// WrapAlgorithm the algorithm the content key was wrapped with, such as aes-kw or rsa-oaep-256
+	WrapAlgorithm string `db:"wrap_algorithm"`
+	// WrappedKey the wrapped content key, base64 encoded, empty if the key is held by the client
+	WrappedKey  string    `db:"wrapped_key"`
+	CreatedTime time.Time `db:"created_time"`
+}
@@ -0,0 +1,70 @@
+package types
+
+import "time"
+
+// ExportDataset the data exported for the analytics
+type ExportDataset string
+
+const (
+	// ExportNodes the info of the nodes
+	ExportNodes ExportDataset = "nodes"
+	// ExportAssets the records of the assets
+	ExportAssets ExportDataset = "assets"
+	// ExportPointsEpochs the points of the nodes in the closed epochs
+	ExportPointsEpochs ExportDataset = "points_epochs"
+)
+
+// ExportFormat the file format of an export
+type ExportFormat string
+
+const (
+	// ExportCSV gzip compressed csv with a header row
+	ExportCSV ExportFormat = "csv"
+)
+
+// DataExportReq request to export a dataset
+type DataExportReq struct {
+	Dataset ExportDataset
+	// Format csv if empty
+	Format ExportFormat
+	// Start and End the epochs [Start, End] of the points epochs, not used by the other datasets
+	Start time.Time
+	End   time.Time
+}
+
+// DataExport an export of a dataset generated by a job of the job queue and uploaded to the export bucket
+type DataExport struct {
+	ID      string        `db:"id"`
+	Dataset ExportDataset `db:"dataset"`
+	Format  ExportFormat  `db:"format"`
+	// SchemaVersion the version of the columns of the dataset, the version is changed when the columns change
+	SchemaVersion int `db:"schema_version"`
+	// Start and End the epochs of the points epochs, the time of the snapshot for the other datasets
+	Start        time.Time `db:"start_time"`
+	End          time.Time `db:"end_time"`
+	ObjectKey    string    `db:"object_key"`
+	Rows         int64     `db:"row_count"`
+	Size         int64     `db:"size"`
+	CreatedTime  time.Time `db:"created_time"`
+	FinishedTime time.Time `db:"finished_time"`
+	// Status and Message the status and the last error of the job generating the export
+	Status  JobStatus `db:"status"`
+	Message string    `db:"message"`
+	// DownloadURL the presigned url of the export, only set when the export succeeded
+	DownloadURL string `db:"-"`
+	// URLExpiration the expiration of the download url
+	URLExpiration time.Time `db:"-"`
+}
+
+// ListDataExportRsp list data exports
+type ListDataExportRsp struct {
+	Total int64         `json:"total"`
+	Data  []*DataExport `json:"data"`
+}
+
+// DataExportSchema the columns of a dataset in the version
+type DataExportSchema struct {
+	Dataset ExportDataset
+	Version int
+	Columns []string
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"hash/fnv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// FeatureFlagNodeTypes the node types a feature flag targets, kept as json in the database
+type FeatureFlagNodeTypes []NodeType
+
+// Scan implements sql.Scanner for the json column
+func (t *FeatureFlagNodeTypes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return xerrors.Errorf("can not scan %T into node types", src)
+	}
+}
+
+// Value implements driver.Valuer, the node types are written as json
+func (t FeatureFlagNodeTypes) Value() (driver.Value, error) {
+	buf, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), nil
+}
+
+// FeatureFlag a feature enabled gradually on the schedulers and the nodes
+type FeatureFlag struct {
+	Name        string `db:"name"`
+	Description string `db:"description"`
+	// the flag is off everywhere if it is not enabled
+	Enabled bool `db:"enabled"`
+	// the percent of the nodes of the target types the feature is enabled on, the nodes are picked by the hash
+	// of the flag name and the node id so raising the percentage keeps the nodes already enabled
+	Percentage int `db:"percentage"`
+	// the types of the nodes the flag targets, all types if empty, NodeScheduler targets the schedulers
+	NodeTypes   FeatureFlagNodeTypes `db:"node_types"`
+	UpdatedTime time.Time            `db:"updated_time"`
+}
+
+// Targets returns true if the flag targets the node type
+func (f *FeatureFlag) Targets(nodeType NodeType) bool {
+	if len(f.NodeTypes) == 0 {
+		return true
+	}
+
+	for _, t := range f.NodeTypes {
+		if t == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
+// EnabledFor returns true if the feature is enabled on the node
+func (f *FeatureFlag) EnabledFor(nodeID string, nodeType NodeType) bool {
+	if !f.Enabled || !f.Targets(nodeType) {
+		return false
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(f.Name + "/" + nodeID))
+	return int(h.Sum32()%100) < f.Percentage
+}
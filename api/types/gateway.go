@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+// GatewayHost a hostname of the gateway domain and the candidates it points at
+type GatewayHost struct {
+	Hostname string
+	// AreaID the area of the region hostname, empty for the asset hostnames
+	AreaID string
+	// AssetCID the asset of the asset hostname, empty for the region hostnames
+	AssetCID    string
+	IPs         []string
+	UpdatedTime time.Time
+	// Err the error of the last update of the records, the update is retried on the next sync
+	Err string
+}
+
+// GatewayAsset an asset with its own gateway hostname
+type GatewayAsset struct {
+	Hash        string    `db:"hash"`
+	CID         string    `db:"cid"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// GatewayCertificate the certificate of *.<zone> and <zone> obtained by acme, the candidates serve the gateway hostnames over https with it
+type GatewayCertificate struct {
+	Zone string `db:"zone"`
+	// pem encoded certificate chain
+	Certificate []byte `db:"certificate"`
+	// pem encoded private key
+	PrivateKey  []byte    `db:"private_key"`
+	NotAfter    time.Time `db:"not_after"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
@@ -0,0 +1,149 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// AssetGeoRule a rule of the geo policy of an asset. The region is an area or the prefix of areas,
+// e.g. Europe matches Europe-Germany-Hesse-Frankfurt
+type AssetGeoRule struct {
+	Region string
+	// MinReplicas the replicas kept in the region at least
+	MinReplicas int
+	// Exclude no replica is placed in the region
+	Exclude bool
+}
+
+// Matches checks if the area is in the region of the rule
+func (r *AssetGeoRule) Matches(areaID string) bool {
+	if r.Region == "" || areaID == "" {
+		return false
+	}
+
+	region, area := strings.ToLower(r.Region), strings.ToLower(areaID)
+	return area == region || strings.HasPrefix(area, region+"-")
+}
+
+// AssetGeoRules the rules of the geo policy, kept as json in the database
+type AssetGeoRules []*AssetGeoRule
+
+// Scan implements sql.Scanner for the json column
+func (r *AssetGeoRules) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return xerrors.Errorf("can not scan %T into geo rules", src)
+	}
+}
+
+// Value implements driver.Valuer, the rules are written as json
+func (r AssetGeoRules) Value() (driver.Value, error) {
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), nil
+}
+
+// Validate checks the rules, a region can not both keep replicas and exclude them
+func (r AssetGeoRules) Validate() error {
+	if len(r) == 0 {
+		return xerrors.New("rules can not empty")
+	}
+
+	for _, rule := range r {
+		if rule == nil || rule.Region == "" {
+			return xerrors.New("region of the rule can not empty")
+		}
+
+		if rule.MinReplicas < 0 {
+			return xerrors.Errorf("min replicas of region %s can not be negative", rule.Region)
+		}
+
+		if rule.Exclude && rule.MinReplicas > 0 {
+			return xerrors.Errorf("region %s can not both exclude and keep replicas", rule.Region)
+		}
+
+		if !rule.Exclude && rule.MinReplicas == 0 {
+			return xerrors.Errorf("rule of region %s has no effect", rule.Region)
+		}
+	}
+
+	for _, rule := range r {
+		if !rule.Exclude {
+			continue
+		}
+
+		for _, other := range r {
+			if !other.Exclude && (rule.Matches(other.Region) || other.Matches(rule.Region)) {
+				return xerrors.Errorf("region %s overlaps the excluded region %s", other.Region, rule.Region)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Excluded checks if the area is in a region excluded by the rules
+func (r AssetGeoRules) Excluded(areaID string) bool {
+	for _, rule := range r {
+		if rule.Exclude && rule.Matches(areaID) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssetGeoPolicy the geo policy of an asset, evaluated when the replicas of the asset are placed
+type AssetGeoPolicy struct {
+	Hash        string        `db:"hash"`
+	CID         string        `db:"cid"`
+	Rules       AssetGeoRules `db:"rules"`
+	UpdatedTime time.Time     `db:"updated_time"`
+}
+
+// AssetGeoViolationKind the kind of a violation of the geo policy
+type AssetGeoViolationKind string
+
+const (
+	// AssetGeoViolationMissing the region keeps fewer replicas than the rule requires
+	AssetGeoViolationMissing AssetGeoViolationKind = "missing"
+	// AssetGeoViolationExcluded replicas are placed in the excluded region
+	AssetGeoViolationExcluded AssetGeoViolationKind = "excluded"
+)
+
+// AssetGeoViolation a rule of the geo policy the replicas of the asset violate
+type AssetGeoViolation struct {
+	Hash   string                `db:"hash"`
+	CID    string                `db:"cid"`
+	Region string                `db:"region"`
+	Kind   AssetGeoViolationKind `db:"kind"`
+	// Replicas the replicas on the online nodes of the region
+	Replicas int `db:"replicas"`
+	// Required the replicas the rule requires, 0 for the excluded regions
+	Required     int       `db:"required"`
+	DetectedTime time.Time `db:"detected_time"`
+}
+
+// ListAssetGeoPolicyRsp list geo policies
+type ListAssetGeoPolicyRsp struct {
+	Total int               `json:"total"`
+	Data  []*AssetGeoPolicy `json:"data"`
+}
+
+// ListAssetGeoViolationRsp list geo policy violations
+type ListAssetGeoViolationRsp struct {
+	Total int                  `json:"total"`
+	Data  []*AssetGeoViolation `json:"data"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// NodeTaskKind the kind of the tasks queued in the inbox of a node
+type NodeTaskKind string
+
+const (
+	// NodeTaskDeleteAsset deletes the asset of the cid in the payload from the node, e.g. a removed replica or a purged asset
+	NodeTaskDeleteAsset NodeTaskKind = "delete_asset"
+)
+
+// NodeTask a task of a node kept in its inbox until the node acknowledges it or the task expires
+type NodeTask struct {
+	ID          int64        `db:"id"`
+	NodeID      string       `db:"node_id"`
+	Kind        NodeTaskKind `db:"kind"`
+	Payload     string       `db:"payload"`
+	Attempts    int          `db:"attempts"`
+	LastError   string       `db:"last_error"`
+	CreatedTime time.Time    `db:"created_time"`
+	Expiration  time.Time    `db:"expiration"`
+}
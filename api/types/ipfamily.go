@@ -0,0 +1,43 @@
+package types
+
+import "net"
+
+// IPFamily the address family of a node or a client
+type IPFamily string
+
+const (
+	IPFamilyV4 IPFamily = "ipv4"
+	IPFamilyV6 IPFamily = "ipv6"
+)
+
+// IPFamilyOf returns the family of the ip or the host:port address, empty if the host is not an ip
+func IPFamilyOf(addr string) IPFamily {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	if ip.To4() != nil {
+		return IPFamilyV4
+	}
+
+	return IPFamilyV6
+}
+
+// IPFamilyStats the online nodes of an address family and the retrievals routed for its clients
+type IPFamilyStats struct {
+	Family     IPFamily
+	Edges      int
+	Candidates int
+	// Reachable the online nodes the clients connect to directly, without a nat or behind a full cone nat
+	Reachable int
+	// Retrievals the retrievals routed for the clients of the family since the scheduler started
+	Retrievals int64
+	// Matched the routed retrievals whose first node is of the family of the client
+	Matched int64
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobStatus status of a job of the job queue
+type JobStatus string
+
+const (
+	// JobPending the job waits for a worker, a failed attempt waits for its retry time
+	JobPending JobStatus = "pending"
+	// JobRunning the job is run by a worker
+	JobRunning JobStatus = "running"
+	// JobSucceeded the job is done
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed the job failed in all its attempts
+	JobFailed JobStatus = "failed"
+	// JobCanceled the job is canceled by the operator
+	JobCanceled JobStatus = "canceled"
+)
+
+// Finished checks if the job will not run again
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+// Job a long-running task of the schedulers persisted in the job queue and run by the workers of the schedulers
+type Job struct {
+	ID   string `db:"id"`
+	Kind string `db:"kind"`
+	// DedupKey the key of the job, a job is not enqueued if a job of the same key exists, empty if not deduplicated
+	DedupKey string          `db:"dedup_key"`
+	Payload  json.RawMessage `db:"payload"`
+	// ServerID the scheduler the job must run on, the job runs on any scheduler if it is empty
+	ServerID string    `db:"server_id"`
+	Status   JobStatus `db:"status"`
+	// Worker the scheduler running or last ran the job
+	Worker      string `db:"worker"`
+	Attempts    int    `db:"attempts"`
+	MaxAttempts int    `db:"max_attempts"`
+	// ProgressDone and ProgressTotal the progress reported by the job
+	ProgressDone  int64 `db:"progress_done"`
+	ProgressTotal int64 `db:"progress_total"`
+	// Message the error of the last attempt
+	Message string `db:"message"`
+	// RunAfter the job is not run before the time
+	RunAfter      time.Time `db:"run_after"`
+	HeartbeatTime time.Time `db:"heartbeat_time"`
+	CreatedTime   time.Time `db:"created_time"`
+	UpdatedTime   time.Time `db:"updated_time"`
+}
+
+// ListJobRsp list jobs of the job queue
+type ListJobRsp struct {
+	Total int64  `json:"total"`
+	Data  []*Job `json:"data"`
+}
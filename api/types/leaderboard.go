@@ -0,0 +1,77 @@
+package types
+
+import "time"
+
+// LeaderboardMetric the metric the nodes are ranked by
+type LeaderboardMetric string
+
+const (
+	// LeaderboardPoints points earned in the period
+	LeaderboardPoints LeaderboardMetric = "points"
+	// LeaderboardTraffic bytes served to the clients in the period
+	LeaderboardTraffic LeaderboardMetric = "traffic"
+	// LeaderboardUptime minutes online in the period
+	LeaderboardUptime LeaderboardMetric = "uptime"
+)
+
+// LeaderboardPeriod the period the metric is measured over
+type LeaderboardPeriod string
+
+const (
+	// LeaderboardDay the last 24 hours
+	LeaderboardDay LeaderboardPeriod = "24h"
+	// LeaderboardWeek the last 7 days
+	LeaderboardWeek LeaderboardPeriod = "7d"
+	// LeaderboardMonth the last 30 days
+	LeaderboardMonth LeaderboardPeriod = "30d"
+)
+
+// Days returns the number of days of the period, 0 if the period is unknown
+func (p LeaderboardPeriod) Days() int {
+	switch p {
+	case LeaderboardDay:
+		return 1
+	case LeaderboardWeek:
+		return 7
+	case LeaderboardMonth:
+		return 30
+	}
+	return 0
+}
+
+// LeaderboardReq the board, filters and page of a leaderboard query, zero value filters are ignored
+type LeaderboardReq struct {
+	Metric LeaderboardMetric
+	Period LeaderboardPeriod
+	// Region the area id of the scheduler the nodes belong to
+	Region   string
+	NodeType NodeType
+	Limit    int
+	Offset   int
+}
+
+// LeaderboardEntry a ranked node of a leaderboard
+type LeaderboardEntry struct {
+	// Rank the rank of the node in the filtered board
+	Rank     int
+	NodeID   string
+	NodeType NodeType
+	Value    float64
+}
+
+// LeaderboardRsp a page of a leaderboard
+type LeaderboardRsp struct {
+	Total int64               `json:"total"`
+	Data  []*LeaderboardEntry `json:"data"`
+	// UpdatedTime the time the board was computed
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// NodeStatsDaily the cumulative points and online minutes of a node at the start of a day
+type NodeStatsDaily struct {
+	NodeID         string    `db:"node_id"`
+	Date           time.Time `db:"date"`
+	Points         float64   `db:"points"`
+	OnlineDuration int       `db:"online_duration"`
+	NodeType       NodeType  `db:"node_type"`
+}
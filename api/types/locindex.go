@@ -0,0 +1,14 @@
+package types
+
+// LocationIndexStats the asset location lookups served by the location index of the scheduler since its start
+type LocationIndexStats struct {
+	// Entries the assets whose locations are cached
+	Entries int
+	// Hits the lookups served by the cache
+	Hits int64
+	// Misses the lookups not served by the cache
+	Misses int64
+	// Loads the reads of the database, the concurrent misses of the same asset share a read
+	Loads    int64
+	HitRatio float64
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// LogStreamReq asks the node to stream its logs to the scheduler
+type LogStreamReq struct {
+	ID string
+	// Level the lowest level of the logs streamed, debug, info, warn or error
+	Level string
+	// Subsystems the loggers whose logs are streamed, all the loggers if empty
+	Subsystems []string
+	// RateLimit the logs streamed per second, the logs over the limit are dropped and counted
+	RateLimit int
+	// Duration the stream is closed after the duration, unit: second
+	Duration int
+}
+
+// LogEntry a log of a node
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Logger  string
+	Message string
+	// Dropped the logs dropped by the rate limit of the stream before the log
+	Dropped int64
+}
+
+// LogBatch the logs of a stream pushed by the node to the scheduler
+type LogBatch struct {
+	StreamID string
+	Entries  []*LogEntry
+}
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// MaintenanceWindow the period [StartTime, EndTime) the operator takes the node down for maintenance,
+// the node is not selected or validated and its downtime is not penalized or counted against its score in the window
+type MaintenanceWindow struct {
+	ID          int64     `db:"id"`
+	NodeID      string    `db:"node_id"`
+	StartTime   time.Time `db:"start_time"`
+	EndTime     time.Time `db:"end_time"`
+	Reason      string    `db:"reason"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// In checks if t is in the window
+func (w *MaintenanceWindow) In(t time.Time) bool {
+	return !t.Before(w.StartTime) && t.Before(w.EndTime)
+}
+
+// ListMaintenanceWindowRsp list maintenance windows
+type ListMaintenanceWindowRsp struct {
+	Total int64                `json:"total"`
+	Data  []*MaintenanceWindow `json:"data"`
+}
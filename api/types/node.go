@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
@@ -17,11 +18,167 @@ type NodeSnapshot struct {
 	LastSeen           time.Time `db:"last_seen"`
 	BandwidthUp        int64     `db:"bandwidth_up"`
 	BandwidthDown      int64     `db:"bandwidth_down"`
-	Profit             float64   `db:"profit"`
+	Profit             Points    `db:"profit"`
 	AvailableDiskSpace float64   `db:"available_disk_space"`
 	TitanDiskUsage     float64   `db:"titan_disk_usage"`
 }
 
+// HostMetrics contains the host load reported by a node with its keepalive
+type HostMetrics struct {
+	CPULoad        float64 // cpu usage percent
+	MemoryPressure float64 // memory usage percent
+	DiskIOUtil     float64 // disk io utilization percent
+	Temperature    float64 // unit: celsius, 0 if the host does not provide it
+	UploadLimit    int64   // upload limit in force on the node, unit: byte per second, 0 if unlimited
+	UploadRate     int64   // average upload rate since the last keepalive, unit: byte per second
+	// DiskHealth the health of the disk holding the storage, nil if the node does not monitor it
+	DiskHealth *DiskHealth
+}
+
+// DiskHealth the SMART attributes and the filesystem errors of the disk holding the storage of a node
+type DiskHealth struct {
+	Device string
+	// SmartAvailable the SMART attributes are read, the disk or the host may not support SMART
+	SmartAvailable bool
+	// SmartPassed the overall health self-assessment of the disk
+	SmartPassed bool
+	// the sectors reallocated, pending reallocation and uncorrectable of an ata disk
+	ReallocatedSectors   int64
+	PendingSectors       int64
+	UncorrectableSectors int64
+	// MediaErrors the unrecovered data integrity errors of a nvme disk
+	MediaErrors int64
+	// PercentageUsed the estimated life of a nvme disk used, unit: percent
+	PercentageUsed int64
+	// FilesystemErrors the errors recorded by the filesystem of the storage
+	FilesystemErrors int64
+	CollectedTime    time.Time
+}
+
+// NodeDiskHealth the disk health last reported by an online node
+type NodeDiskHealth struct {
+	NodeID string
+	Health *DiskHealth
+	// Failure why the disk is predicted to fail, empty if the disk is healthy
+	Failure string
+}
+
+// IdleMode the keepalive cadence the scheduler grants an edge requesting the idle mode
+type IdleMode struct {
+	// Idle the edge is in the idle mode, false if the request is denied or the edge leaves the idle mode
+	Idle bool
+	// KeepaliveInterval the interval the edge sends keepalive at in the idle mode (Unit:second)
+	KeepaliveInterval int
+	// Reason why the idle mode is denied
+	Reason string
+}
+
+// UploadLimit the upload limit the scheduler sets on a node and its compliance
+type UploadLimit struct {
+	NodeID      string    `db:"node_id"`
+	Limit       int64     `db:"upload_limit"` // unit: byte per second, 0 if unlimited
+	UpdatedTime time.Time `db:"updated_time"`
+	// reported by the node with keepalive
+	AppliedLimit int64 `db:"-"`
+	UploadRate   int64 `db:"-"`
+	// Compliant the node applies the limit and uploads within it
+	Compliant bool `db:"-"`
+}
+
+// HardwareChallenge is a random challenge sent to a node to verify its self-reported disk and memory
+type HardwareChallenge struct {
+	ID   string
+	Seed int64
+	// DiskSize bytes written to disk, read back and hashed by the disk probe
+	DiskSize int64
+	// MemorySize bytes of the buffer used by the memory bandwidth benchmark
+	MemorySize int64
+	// MemoryRounds number of copy passes over the memory buffer
+	MemoryRounds int
+	// DigestOnly only calculate the expected digests, used by candidates to spot check the result of a node
+	DigestOnly bool
+}
+
+// HardwareChallengeResult is the result of a hardware challenge, signed by the node private key
+type HardwareChallengeResult struct {
+	ChallengeID     string
+	DiskHash        string
+	DiskDuration    int64 // unit: millisecond
+	MemoryHash      string
+	MemoryBandwidth float64 // unit: byte per second
+	Sign            []byte
+}
+
+// SignData returns the data of the result that is signed by the node
+func (r *HardwareChallengeResult) SignData() []byte {
+	return []byte(fmt.Sprintf("%s:%s:%d:%s:%f", r.ChallengeID, r.DiskHash, r.DiskDuration, r.MemoryHash, r.MemoryBandwidth))
+}
+
+// HardwareProof is the verified result of the latest hardware challenge of a node
+type HardwareProof struct {
+	NodeID          string    `db:"node_id"`
+	Passed          bool      `db:"passed"`
+	DiskSize        int64     `db:"disk_size"`
+	DiskDuration    int64     `db:"disk_duration"`
+	MemorySize      int64     `db:"memory_size"`
+	MemoryBandwidth float64   `db:"memory_bandwidth"`
+	ProofTime       time.Time `db:"proof_time"`
+	// Mismatched the digests of the result mismatched the spot check, not saved
+	Mismatched bool `db:"-"`
+}
+
+// NodeCertificate the client certificate issued to a candidate for mutual tls
+type NodeCertificate struct {
+	// pem encoded certificate
+	Certificate []byte
+	// pem encoded ca certificate, verifies the server certificate of the scheduler
+	CACertificate []byte
+	NotAfter      time.Time
+}
+
+// NodeLoginReq the challenge-response login of a node
+type NodeLoginReq struct {
+	NodeID string
+	// nonce issued by the scheduler for the login
+	Nonce string
+	// unix time the login is signed
+	Timestamp int64
+	// hex signature of SignContent by the node private key
+	Sign string
+}
+
+// SignContent returns the content signed by the node
+func (r *NodeLoginReq) SignContent() []byte {
+	return []byte(fmt.Sprintf("%s:%s:%d", r.NodeID, r.Nonce, r.Timestamp))
+}
+
+// RegionStats the aggregated statistics of the nodes of a region (area)
+type RegionStats struct {
+	AreaID string
+	// number of schedulers the statistics are rolled up from
+	SchedulerCount       int
+	OnlineEdgeCount      int
+	OnlineCandidateCount int
+	// sum of the bandwidths of the online nodes
+	BandwidthUp   int64
+	BandwidthDown int64
+	// sum of the disk space of the online nodes
+	DiskSpace          float64
+	AvailableDiskSpace float64
+	// number of succeeded replicas in the region
+	ReplicaCount int64
+	// bytes uploaded by the nodes of the region
+	TrafficServed int64
+}
+
+// NodePointsRank the rank of a node in the points leaderboard
+type NodePointsRank struct {
+	Rank           int
+	NodeID         string `db:"node_id"`
+	Profit         Points `db:"profit"`
+	OnlineDuration int    `db:"online_duration"` // unit:Minute
+}
+
 // NodeDynamicInfo Dynamic information about the node
 type NodeDynamicInfo struct {
 	NodeID          string  `json:"node_id" form:"nodeId" gorm:"column:node_id;comment:;" db:"node_id"`
@@ -32,7 +189,7 @@ type NodeDynamicInfo struct {
 	TitanDiskUsage  float64   `db:"titan_disk_usage"`
 	IncomeIncr      float64   // Base points increase every half hour (30 minute)
 	OnlineDuration  int       `db:"online_duration"` // unit:Minute
-	Profit          float64   `db:"profit"`
+	Profit          Points    `db:"profit"`
 	LastSeen        time.Time `db:"last_seen"`
 	DownloadTraffic int64     `db:"download_traffic"`
 	UploadTraffic   int64     `db:"upload_traffic"`
@@ -63,6 +220,13 @@ type NodeInfo struct {
 	SchedulerID        dtypes.ServerID `db:"scheduler_sid"`
 	DeactivateTime     int64           `db:"deactivate_time"`
 	CPUInfo            string          `json:"cpu_info" form:"cpuInfo" gorm:"column:cpu_info;comment:;" db:"cpu_info"`
+	// DataProtocols the protocols the node serves the assets with, in order of preference
+	DataProtocols []TransferProtocol
+	// ProxyScheme the scheme of the outbound proxy the node connects to the scheduler through, e.g. socks5 or http,
+	// empty if the node connects directly
+	ProxyScheme string
+	// SandboxExpiration the time the sandbox node is purged, zero if the node is not a sandbox node
+	SandboxExpiration time.Time `db:"-"`
 
 	NodeDynamicInfo
 }
@@ -151,6 +315,8 @@ type EdgeDownloadInfo struct {
 	Tk      *Token
 	NodeID  string
 	NatType string
+	// Protocols the transfer protocols negotiated with the node, in order of preference
+	Protocols []TransferProtocol
 }
 
 // EdgeDownloadInfoList represents a list of EdgeDownloadInfo structures along with
@@ -170,6 +336,22 @@ type CandidateDownloadInfo struct {
 	AWSBucket string
 	// download from aws
 	AWSKey string
+	// Protocols the transfer protocols negotiated with the node, in order of preference
+	Protocols []TransferProtocol
+}
+
+// GatewayNode a replica holding node selected to serve the ipfs gateway request
+type GatewayNode struct {
+	NodeID      string
+	Address     string
+	NatType     NatType
+	IsCandidate bool
+	// Cost the routing cost of the node by nat type and load, lower is preferred
+	Cost float64
+	// Protocols the transfer protocols negotiated with the node, in order of preference
+	Protocols []TransferProtocol
+	// SchedulerURL the scheduler issuing the token of the node, set by the locator
+	SchedulerURL string
 }
 
 // NodeIPInfo
@@ -191,6 +373,40 @@ type NodeReplicaRsp struct {
 	TotalCount int
 }
 
+// TransferProtocol the protocol of the data plane between the nodes and their clients
+type TransferProtocol string
+
+const (
+	// TransferProtocolHTTP3 http/3 over quic, preferred on lossy links
+	TransferProtocolHTTP3 TransferProtocol = "h3"
+	// TransferProtocolHTTP http/1.1 or http/2 over tcp
+	TransferProtocolHTTP TransferProtocol = "http"
+)
+
+// PreferredTransferProtocol returns the protocol to transfer with a node from the protocols negotiated by the scheduler,
+// the nodes before the negotiation only serve http3
+func PreferredTransferProtocol(protocols []TransferProtocol) TransferProtocol {
+	if len(protocols) == 0 {
+		return TransferProtocolHTTP3
+	}
+	return protocols[0]
+}
+
+// TransferProtocolOf returns the protocol of the http major version of a request or response
+func TransferProtocolOf(protoMajor int) TransferProtocol {
+	if protoMajor == 3 {
+		return TransferProtocolHTTP3
+	}
+	return TransferProtocolHTTP
+}
+
+// TransferProtocolStats the succeeded transfers of a node with a protocol
+type TransferProtocolStats struct {
+	Protocol TransferProtocol
+	Count    int64
+	Size     int64
+}
+
 // NatType represents the type of NAT of a node
 type NatType int
 
@@ -248,6 +464,32 @@ type ListNodesRsp struct {
 	Total int64      `json:"total"`
 }
 
+// ListNodesReq the filters, sorting and cursor of node listing, zero value filters are ignored
+type ListNodesReq struct {
+	// Cursor returned by the previous page, empty for the first page
+	Cursor string
+	Limit  int
+	// SortBy one of node_id, profit, online_duration, last_seen, first_login_time, disk_space, default node_id
+	SortBy string
+	Desc   bool
+
+	Type     NodeType
+	Statuses []NodeStatus
+	// Region one of the areas served by the scheduler, the zone the nodes belong to
+	Region  string
+	NATType string
+	// MinScore and MaxScore the score range (0 ~ 100) of the nodes, MaxScore 0 means no upper limit
+	MinScore int
+	MaxScore int
+}
+
+// ListNodesCursorRsp a page of node listing
+type ListNodesCursorRsp struct {
+	Data []NodeInfo `json:"data"`
+	// NextCursor the cursor of the next page, empty if there are no more nodes
+	NextCursor string `json:"next_cursor"`
+}
+
 // ListDownloadRecordRsp download record rsp
 type ListDownloadRecordRsp struct {
 	Data  []DownloadHistory `json:"data"`
@@ -279,13 +521,28 @@ type ValidationResultInfo struct {
 	Bandwidth        float64          `db:"bandwidth"`
 	StartTime        time.Time        `db:"start_time"`
 	EndTime          time.Time        `db:"end_time"`
-	Profit           float64          `db:"profit"`
+	Profit           Points           `db:"profit"`
 	CalculatedProfit bool             `db:"calculated_profit"`
 	TokenID          string           `db:"token_id"`
 	FileSaved        bool             `db:"file_saved"`
 	NodeCount        int              `db:"node_count"`
 }
 
+// ValidatorLoad the validations of a validator connected to the scheduler
+type ValidatorLoad struct {
+	NodeID string
+	// Capacity the validations the validator runs at a time at most by its downstream bandwidth
+	Capacity int
+	// Running the validations the validator is running
+	Running int
+	// Assigned the validations assigned to the validator in the current round
+	Assigned int
+	// Deferred the validations deferred to the next rounds in the current round for the validator was full
+	Deferred int
+	// Total the validations assigned to the validator since the scheduler started
+	Total int64
+}
+
 // ValidationStatus Validation Status
 type ValidationStatus int
 
@@ -333,6 +590,9 @@ type TokenPayload struct {
 	LimitRate   int64     `db:"limit_rate"`
 	CreatedTime time.Time `db:"created_time"`
 	Expiration  time.Time `db:"expiration"`
+	// RangeStart and RangeEnd the inclusive byte range the token is limited to, the whole content if RangeEnd is 0
+	RangeStart int64 `db:"-"`
+	RangeEnd   int64 `db:"-"`
 }
 
 // Token access download asset
@@ -350,6 +610,8 @@ type Workload struct {
 	StartTime     time.Time
 	EndTime       time.Time
 	BlockCount    int64
+	// Protocol the protocol the workload was transferred with
+	Protocol TransferProtocol
 }
 
 // WorkloadStatus Workload Status
@@ -401,6 +663,8 @@ type ConnectOptions struct {
 	// private minio storage only, not public storage
 	IsPrivateMinioOnly bool
 	ExternalURL        string
+	// AreaID the area of the node, the scheduler places the node in the zone of the area if it serves the area
+	AreaID string
 }
 
 type GeneratedCarInfo struct {
@@ -421,6 +685,8 @@ type ActivationDetail struct {
 	ActivationKey string   `json:"activation_key" db:"activation_key"`
 	NodeType      NodeType `json:"node_type" db:"node_type"`
 	IP            string   `json:"ip" db:"ip"`
+	// PublicKey pem of the identity key the node is registered with
+	PublicKey string `json:"-" db:"public_key"`
 }
 
 func (d *ActivationDetail) Marshal() (string, error) {
@@ -448,14 +714,14 @@ func (d *ActivationDetail) Unmarshal(code string) error {
 
 // RetrieveEvent retrieve event
 type RetrieveEvent struct {
-	TokenID     string  `db:"token_id"`
-	NodeID      string  `db:"node_id"`
-	ClientID    string  `db:"client_id"`
-	CID         string  `db:"cid"`
-	Size        int64   `db:"size"`
-	CreatedTime int64   `db:"created_time"`
-	EndTime     int64   `db:"end_time"`
-	Profit      float64 `db:"profit"`
+	TokenID     string `db:"token_id"`
+	NodeID      string `db:"node_id"`
+	ClientID    string `db:"client_id"`
+	CID         string `db:"cid"`
+	Size        int64  `db:"size"`
+	CreatedTime int64  `db:"created_time"`
+	EndTime     int64  `db:"end_time"`
+	Profit      Points `db:"profit"`
 }
 
 // ListRetrieveEventRsp list retrieve event
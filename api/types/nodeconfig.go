@@ -0,0 +1,86 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// NodeConfig the configuration the scheduler pushes to the nodes, either the defaults of a node type
+// or the overrides of a node, the nil fields are left to the node
+type NodeConfig struct {
+	// the level of all the loggers, e.g. info
+	LogLevel *string `json:",omitempty"`
+	// the eviction policy of the cacheable assets
+	CacheConfig *CacheConfig `json:",omitempty"`
+	// the upload limit of the edge, unit: byte per second, 0 removes the limit, set by SetUploadLimit rather than pushed
+	UploadLimit *int64 `json:",omitempty"`
+	// the features the node enables, the flags of the overrides replace the flags of the same names in the defaults
+	FeatureFlags map[string]bool `json:",omitempty"`
+}
+
+// Merge returns the config with the fields set by the overrides replaced
+func (c *NodeConfig) Merge(overrides *NodeConfig) *NodeConfig {
+	out := &NodeConfig{FeatureFlags: make(map[string]bool)}
+	for _, layer := range []*NodeConfig{c, overrides} {
+		if layer == nil {
+			continue
+		}
+
+		if layer.LogLevel != nil {
+			out.LogLevel = layer.LogLevel
+		}
+		if layer.CacheConfig != nil {
+			out.CacheConfig = layer.CacheConfig
+		}
+		if layer.UploadLimit != nil {
+			out.UploadLimit = layer.UploadLimit
+		}
+		for name, enabled := range layer.FeatureFlags {
+			out.FeatureFlags[name] = enabled
+		}
+	}
+
+	if len(out.FeatureFlags) == 0 {
+		out.FeatureFlags = nil
+	}
+	return out
+}
+
+// Revision returns the hash of the config, the node acknowledges the revision it applies
+func (c *NodeConfig) Revision() string {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.Sum256(buf)
+	return hex.EncodeToString(hash[:8])
+}
+
+// NodeConfigPush the config pushed to the node with its revision
+type NodeConfigPush struct {
+	Revision string
+	Config   *NodeConfig
+}
+
+// NodeConfigAck the acknowledgment of the node to the pushed config, the fields failed to apply are in errors
+type NodeConfigAck struct {
+	Revision string
+	Errors   []string
+}
+
+// NodeConfigStatus the config of the node and the revision the node acknowledged
+type NodeConfigStatus struct {
+	NodeID string
+	// the overrides of the node
+	Overrides *NodeConfig
+	// the defaults merged with the overrides
+	Effective *NodeConfig
+	Revision  string
+	// the last revision acknowledged by the node
+	AppliedRevision string
+	AppliedTime     time.Time
+	Err             string
+}
@@ -0,0 +1,59 @@
+package types
+
+import "time"
+
+// NodeDiagnosticsStatus status of a diagnostics bundle of a node
+type NodeDiagnosticsStatus string
+
+const (
+	// NodeDiagnosticsCollecting the node is collecting the bundle
+	NodeDiagnosticsCollecting NodeDiagnosticsStatus = "collecting"
+	// NodeDiagnosticsSucceeded the bundle is kept by the scheduler or uploaded to the location
+	NodeDiagnosticsSucceeded NodeDiagnosticsStatus = "succeeded"
+	// NodeDiagnosticsFailed the node failed to collect or upload the bundle
+	NodeDiagnosticsFailed NodeDiagnosticsStatus = "failed"
+)
+
+// NodeDiagnostics a diagnostics bundle of a node requested by the operator
+type NodeDiagnostics struct {
+	ID     string                `db:"id"`
+	NodeID string                `db:"node_id"`
+	Status NodeDiagnosticsStatus `db:"status"`
+	// the presigned url the node uploaded the bundle to, empty if the bundle is kept by the scheduler
+	Location string `db:"location"`
+	// bytes of the gzipped tar kept by the scheduler
+	Size         int64     `db:"size"`
+	Message      string    `db:"message"`
+	CreatedTime  time.Time `db:"created_time"`
+	FinishedTime time.Time `db:"finished_time"`
+}
+
+// ListNodeDiagnosticsRsp list of the diagnostics bundles of a node
+type ListNodeDiagnosticsRsp struct {
+	Total       int                `json:"total"`
+	Diagnostics []*NodeDiagnostics `json:"diagnostics"`
+}
+
+// NodeDiagnosticsReq asks the node to collect a diagnostics bundle
+type NodeDiagnosticsReq struct {
+	ID string
+	// the node puts the bundle to the presigned url instead of returning it to the scheduler
+	UploadURL string
+}
+
+// NATProbeResult the external address of the node seen by a candidate
+type NATProbeResult struct {
+	CandidateURL string
+	ExternalAddr string
+	Err          string
+}
+
+// DiskStat the usage of the file system of a path of the node
+type DiskStat struct {
+	Path        string
+	FSType      string
+	Total       uint64
+	Used        uint64
+	Free        uint64
+	UsedPercent float64
+}
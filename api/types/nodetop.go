@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+// NodeTopSortBy the value the nodes of the live view are sorted by
+type NodeTopSortBy string
+
+const (
+	// NodeTopBandwidthUp the upload bandwidth of the node
+	NodeTopBandwidthUp NodeTopSortBy = "bandwidth_up"
+	// NodeTopBandwidthDown the download bandwidth of the node
+	NodeTopBandwidthDown NodeTopSortBy = "bandwidth_down"
+	// NodeTopPointsRate the points the node earns per hour
+	NodeTopPointsRate NodeTopSortBy = "points_rate"
+	// NodeTopValidationFailures the validations the node failed
+	NodeTopValidationFailures NodeTopSortBy = "validation_failures"
+	// NodeTopOnlineDuration the minutes the node has been online
+	NodeTopOnlineDuration NodeTopSortBy = "online_duration"
+	// NodeTopNodeID the id of the node
+	NodeTopNodeID NodeTopSortBy = "node_id"
+)
+
+// NodeTopReq the sorting and the size of the live view of the online nodes
+type NodeTopReq struct {
+	SortBy NodeTopSortBy
+	Desc   bool
+	// Limit the nodes in each snapshot
+	Limit int
+	// Interval the seconds between the snapshots
+	Interval int
+}
+
+// NodeTopEntry the live statistics of an online node
+type NodeTopEntry struct {
+	NodeID        string
+	Type          NodeType
+	ExternalIP    string
+	BandwidthUp   int64
+	BandwidthDown int64
+	CPUUsage      float64
+	MemoryUsage   float64
+	// PointsRate the points the node earns per hour
+	PointsRate float64
+	// ValidationFailures the validations the node failed in the day before the view opened and since
+	ValidationFailures int64
+	// OnlineDuration unit:Minute
+	OnlineDuration int
+	Abnormal       bool
+}
+
+// NodeTopSnapshot a snapshot of the live view of the online nodes
+type NodeTopSnapshot struct {
+	Time             time.Time
+	OnlineEdges      int
+	OnlineCandidates int
+	// Nodes the online nodes sorted by the request, at most the limit of the request
+	Nodes []*NodeTopEntry
+	// Events the online and offline events of the nodes since the previous snapshot
+	Events []*NodeEvent
+}
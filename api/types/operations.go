@@ -0,0 +1,17 @@
+package types
+
+// NodeOperations the operations in flight assigned to a node by the managers of the scheduler
+type NodeOperations struct {
+	NodeID string
+	Online bool
+	// PendingPulls the replicas waiting or being pulled by the node
+	PendingPulls []*NodeReplicaInfo
+	// ActiveValidations the validations of the current rounds the node is validated or validates in
+	ActiveValidations []*ValidationResultInfo
+	// QueuedTasks the tasks in the inbox of the node
+	QueuedTasks []*NodeTask
+	// RelaySessions the relay sessions the node is relayed or relays in
+	RelaySessions []*RelaySession
+	// ControlCalls the control calls of the scheduler queued for or executing on the node
+	ControlCalls []*ControlCall
+}
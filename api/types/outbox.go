@@ -0,0 +1,113 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxSchemaVersion the version of the schema of the events delivered to the external consumers,
+// it changes only when a field is removed or changes its meaning, new topics and fields keep the version
+const OutboxSchemaVersion = 1
+
+// OutboxTopic the topic of an event delivered to the external consumers
+type OutboxTopic string
+
+const (
+	// OutboxNodeRegistered a node is registered, the payload is an OutboxNodePayload
+	OutboxNodeRegistered OutboxTopic = "node.registered"
+	// OutboxNodeOnline a node comes online, the payload is an OutboxNodePayload
+	OutboxNodeOnline OutboxTopic = "node.online"
+	// OutboxNodeOffline a node goes offline, the payload is an OutboxNodePayload
+	OutboxNodeOffline OutboxTopic = "node.offline"
+	// OutboxNodeDeactivation a node is scheduled for deactivation or the deactivation is cancelled, the payload is an OutboxNodePayload
+	OutboxNodeDeactivation OutboxTopic = "node.deactivation"
+	// OutboxValidationResult a validation of a node ends, the payload is an OutboxValidationPayload
+	OutboxValidationResult OutboxTopic = "validation.result"
+	// OutboxAssetState the state of an asset changes, the payload is an OutboxAssetStatePayload
+	OutboxAssetState OutboxTopic = "asset.state"
+	// OutboxReplicaAdded a node finishes pulling a replica, the payload is an OutboxReplicaPayload
+	OutboxReplicaAdded OutboxTopic = "replica.added"
+	// OutboxReplicaRemoved a replica is removed from a node, the payload is an OutboxReplicaPayload
+	OutboxReplicaRemoved OutboxTopic = "replica.removed"
+	// OutboxAssetGeoViolation the replicas of an asset start or stop violating a rule of its geo policy,
+	// the payload is an OutboxAssetGeoViolationPayload
+	OutboxAssetGeoViolation OutboxTopic = "asset.geo_violation"
+	// OutboxAssetExpired an asset expires and is being removed, the payload is an OutboxAssetLifecyclePayload
+	OutboxAssetExpired OutboxTopic = "asset.expired"
+	// OutboxAssetPurged an asset is removed with its replicas, the payload is an OutboxAssetLifecyclePayload
+	OutboxAssetPurged OutboxTopic = "asset.purged"
+)
+
+// OutboxEvent an event written to the outbox in the transaction of its state change,
+// the consumers receive every event at least once and can deduplicate by the id
+type OutboxEvent struct {
+	ID      int64       `db:"id" json:"id"`
+	Version int         `db:"-" json:"version"`
+	Topic   OutboxTopic `db:"topic" json:"topic"`
+	// Subject the node id or the asset hash
+	Subject     string          `db:"subject" json:"subject"`
+	Payload     json.RawMessage `db:"payload" json:"payload"`
+	CreatedTime time.Time       `db:"created_time" json:"created_time"`
+	Attempts    int             `db:"attempts" json:"-"`
+}
+
+// OutboxNodePayload the payload of the node events
+type OutboxNodePayload struct {
+	NodeID   string   `json:"node_id"`
+	NodeType NodeType `json:"node_type,omitempty"`
+	IP       string   `json:"ip,omitempty"`
+	// Time the time the node came online or was last seen before going offline
+	Time time.Time `json:"time,omitempty"`
+	// DeactivateTime the unix time the node is deactivated at, 0 if the deactivation is cancelled
+	DeactivateTime int64 `json:"deactivate_time,omitempty"`
+}
+
+// OutboxValidationPayload the payload of the validation events
+type OutboxValidationPayload struct {
+	RoundID     string           `json:"round_id"`
+	NodeID      string           `json:"node_id"`
+	ValidatorID string           `json:"validator_id,omitempty"`
+	Status      ValidationStatus `json:"status"`
+	BlockNumber int64            `json:"block_number,omitempty"`
+	// Duration the duration of the validation in microseconds
+	Duration  int64   `json:"duration,omitempty"`
+	Bandwidth float64 `json:"bandwidth,omitempty"`
+	Profit    Points  `json:"profit"`
+}
+
+// OutboxAssetStatePayload the payload of the asset state events
+type OutboxAssetStatePayload struct {
+	Hash        string `json:"hash"`
+	State       string `json:"state"`
+	TotalBlocks int64  `json:"total_blocks"`
+	TotalSize   int64  `json:"total_size"`
+	ServerID    string `json:"server_id"`
+}
+
+// OutboxReplicaPayload the payload of the replica events
+type OutboxReplicaPayload struct {
+	Hash   string `json:"hash"`
+	CID    string `json:"cid,omitempty"`
+	NodeID string `json:"node_id"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+// OutboxAssetGeoViolationPayload the payload of the geo policy violation events
+type OutboxAssetGeoViolationPayload struct {
+	Hash     string                `json:"hash"`
+	CID      string                `json:"cid"`
+	Region   string                `json:"region"`
+	Kind     AssetGeoViolationKind `json:"kind"`
+	Replicas int                   `json:"replicas"`
+	Required int                   `json:"required,omitempty"`
+	// Resolved the replicas comply with the rule again
+	Resolved bool `json:"resolved"`
+}
+
+// OutboxAssetLifecyclePayload the payload of the asset expired and purged events
+type OutboxAssetLifecyclePayload struct {
+	Hash string `json:"hash"`
+	CID  string `json:"cid"`
+	// Users the users of the asset when the event is written
+	Users []string `json:"users,omitempty"`
+}
@@ -0,0 +1,91 @@
+package types
+
+import "time"
+
+// PenaltyRuleType the condition a penalty rule is applied on
+type PenaltyRuleType string
+
+const (
+	// PenaltyRuleMissedValidations the node missed the threshold number of validations in a row, by timeout or offline
+	PenaltyRuleMissedValidations PenaltyRuleType = "missed_validations"
+	// PenaltyRuleFakeStorage the node was detected the threshold number of times faking its storage,
+	// by returning blocks mismatching the validator, failing the hardware challenge or losing replicas by the storage challenges
+	PenaltyRuleFakeStorage PenaltyRuleType = "fake_storage"
+	// PenaltyRuleOfflineCommittedHours the node was offline for the threshold minutes during the committed hours of the day
+	PenaltyRuleOfflineCommittedHours PenaltyRuleType = "offline_committed_hours"
+	// PenaltyRuleBrokenCommitment the node broke the threshold number of the online windows committed by its operator
+	PenaltyRuleBrokenCommitment PenaltyRuleType = "broken_commitment"
+	// PenaltyRuleSlowRetrievals the retrievals of the node probed by the scheduler were slow or failed the threshold number of times in a row
+	PenaltyRuleSlowRetrievals PenaltyRuleType = "slow_retrievals"
+	// PenaltyRuleWorkloadDiscrepancy the node reported more bytes than the downloaders received in the threshold number
+	// of the sampled workload receipts
+	PenaltyRuleWorkloadDiscrepancy PenaltyRuleType = "workload_discrepancy"
+)
+
+// PenaltyRule the definition of a penalty applied to the nodes meeting its condition
+type PenaltyRule struct {
+	ID   int64           `db:"id"`
+	Name string          `db:"name"`
+	Type PenaltyRuleType `db:"rule_type"`
+	// Threshold number of missed validations or fake storage detections, or offline minutes in the committed hours
+	Threshold int `db:"threshold"`
+	// CommittedStartHour and CommittedEndHour the committed hours [start, end) of the day in the scheduler time zone,
+	// the hours may wrap midnight, only used by the offline committed hours rule
+	CommittedStartHour int `db:"committed_start_hour"`
+	CommittedEndHour   int `db:"committed_end_hour"`
+	// DeductPoints points deducted from the node
+	DeductPoints Points `db:"deduct_points"`
+	// FreezeHours hours the rewards of the node are frozen, the points earned while frozen are settled after the freeze
+	FreezeHours int  `db:"freeze_hours"`
+	Enabled     bool `db:"enabled"`
+	// AppliedCount number of times the rule has been applied
+	AppliedCount    int64     `db:"applied_count"`
+	LastAppliedTime time.Time `db:"last_applied_time"`
+	CreatedTime     time.Time `db:"created_time"`
+}
+
+// InCommittedHours checks if t is in the committed hours of the rule
+func (r *PenaltyRule) InCommittedHours(t time.Time) bool {
+	hour := t.Hour()
+	if r.CommittedStartHour <= r.CommittedEndHour {
+		return hour >= r.CommittedStartHour && hour < r.CommittedEndHour
+	}
+
+	return hour >= r.CommittedStartHour || hour < r.CommittedEndHour
+}
+
+// PenaltyAppealStatus the appeal status of a penalty
+type PenaltyAppealStatus string
+
+const (
+	// PenaltyAppealNone the penalty is not appealed
+	PenaltyAppealNone PenaltyAppealStatus = ""
+	// PenaltyAppealPending the appeal is waiting to be resolved
+	PenaltyAppealPending PenaltyAppealStatus = "pending"
+	// PenaltyAppealAccepted the appeal is accepted, the points are restored and the freeze is lifted
+	PenaltyAppealAccepted PenaltyAppealStatus = "accepted"
+	// PenaltyAppealRejected the appeal is rejected
+	PenaltyAppealRejected PenaltyAppealStatus = "rejected"
+)
+
+// Penalty a penalty applied to a node
+type Penalty struct {
+	ID       int64           `db:"id"`
+	NodeID   string          `db:"node_id"`
+	RuleID   int64           `db:"rule_id"`
+	RuleType PenaltyRuleType `db:"rule_type"`
+	Reason   string          `db:"reason"`
+	// DeductedPoints points deducted from the node, less than the points of the rule if the node had not enough
+	DeductedPoints Points `db:"deducted_points"`
+	// FrozenUntil the rewards of the node are frozen until the time, not frozen if it is not after the created time
+	FrozenUntil  time.Time           `db:"frozen_until"`
+	Appeal       PenaltyAppealStatus `db:"appeal"`
+	AppealReason string              `db:"appeal_reason"`
+	CreatedTime  time.Time           `db:"created_time"`
+}
+
+// ListPenaltyRsp list penalties
+type ListPenaltyRsp struct {
+	Total int64      `json:"total"`
+	Data  []*Penalty `json:"data"`
+}
@@ -0,0 +1,256 @@
+package types
+
+import (
+	"database/sql/driver"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// PointsScale the number of the decimals the points are kept with, the same as the points columns of the database
+const PointsScale = 12
+
+// MaxPointDecimals the maximum number of the decimals the points can be rounded to
+const MaxPointDecimals = PointsScale
+
+var pointsUnit = pow10(PointsScale)
+
+// Points a fixed-point amount of points kept with PointsScale decimals, it never overflows and sums without rounding.
+// The zero value is 0, the operations return new values. It is encoded as a json number and a decimal string in the database.
+type Points struct {
+	v *big.Int
+}
+
+// PointsFromInt returns the points of the integer
+func PointsFromInt(n int64) Points {
+	return Points{v: new(big.Int).Mul(big.NewInt(n), pointsUnit)}
+}
+
+// PointsFromFloat returns the points of the shortest decimal representing the float, rounded to PointsScale decimals
+func PointsFromFloat(f float64) Points {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Points{}
+	}
+
+	p, err := ParsePoints(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		return Points{}
+	}
+
+	return p
+}
+
+// ParsePoints parses a decimal string, the decimals beyond PointsScale are rounded half away from zero
+func ParsePoints(s string) (Points, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Points{}, xerrors.Errorf("invalid points %q", s)
+	}
+
+	return Points{v: roundQuo(new(big.Int).Mul(r.Num(), pointsUnit), r.Denom())}, nil
+}
+
+func (p Points) int() *big.Int {
+	if p.v == nil {
+		return new(big.Int)
+	}
+
+	return p.v
+}
+
+// Add returns p+q
+func (p Points) Add(q Points) Points {
+	return Points{v: new(big.Int).Add(p.int(), q.int())}
+}
+
+// Sub returns p-q
+func (p Points) Sub(q Points) Points {
+	return Points{v: new(big.Int).Sub(p.int(), q.int())}
+}
+
+// Cmp returns -1, 0 or +1 if p is less than, equal to or greater than q
+func (p Points) Cmp(q Points) int {
+	return p.int().Cmp(q.int())
+}
+
+// Sign returns -1, 0 or +1 if p is negative, zero or positive
+func (p Points) Sign() int {
+	return p.int().Sign()
+}
+
+// IsZero returns true if p is 0
+func (p Points) IsZero() bool {
+	return p.Sign() == 0
+}
+
+// Mul returns p multiplied by the float, rounded to PointsScale decimals
+func (p Points) Mul(f float64) Points {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Points{}
+	}
+
+	r := new(big.Rat).SetFloat64(f)
+	return Points{v: roundQuo(new(big.Int).Mul(p.int(), r.Num()), r.Denom())}
+}
+
+// MulDiv returns p*num/den rounded to PointsScale decimals, 0 if den is 0
+func (p Points) MulDiv(num, den Points) Points {
+	if den.IsZero() {
+		return Points{}
+	}
+
+	return Points{v: roundQuo(new(big.Int).Mul(p.int(), num.int()), den.int())}
+}
+
+// Ratio returns p/q as a float, 0 if q is 0
+func (p Points) Ratio(q Points) float64 {
+	if q.IsZero() {
+		return 0
+	}
+
+	f, _ := new(big.Rat).SetFrac(p.int(), q.int()).Float64()
+	return f
+}
+
+// Round returns p rounded half away from zero to the decimals
+func (p Points) Round(decimals int) Points {
+	if decimals >= PointsScale {
+		return p
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	factor := pow10(PointsScale - decimals)
+	q := roundQuo(p.int(), factor)
+	return Points{v: q.Mul(q, factor)}
+}
+
+// Float64 returns the nearest float of p
+func (p Points) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(p.int(), pointsUnit).Float64()
+	return f
+}
+
+// String returns the decimal representation of p without the trailing zeros
+func (p Points) String() string {
+	s := p.StringFixed(PointsScale)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	return s
+}
+
+// StringFixed returns the decimal representation of p rounded to the decimals, with exactly the decimals
+func (p Points) StringFixed(decimals int) string {
+	if decimals > PointsScale {
+		decimals = PointsScale
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	v := p.Round(decimals).int()
+
+	digits := new(big.Int).Abs(v).String()
+	if len(digits) <= PointsScale {
+		digits = strings.Repeat("0", PointsScale-len(digits)+1) + digits
+	}
+
+	integer, fraction := digits[:len(digits)-PointsScale], digits[len(digits)-PointsScale:]
+
+	s := integer
+	if decimals > 0 {
+		s += "." + fraction[:decimals]
+	}
+
+	if v.Sign() < 0 {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// MarshalJSON encodes p as a json number
+func (p Points) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON decodes p from a json number or string
+func (p *Points) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" || s == "" {
+		*p = Points{}
+		return nil
+	}
+
+	v, err := ParsePoints(s)
+	if err != nil {
+		return err
+	}
+
+	*p = v
+	return nil
+}
+
+// Scan implements sql.Scanner for the decimal columns
+func (p *Points) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*p = Points{}
+	case []byte:
+		return p.scanString(string(v))
+	case string:
+		return p.scanString(v)
+	case int64:
+		*p = PointsFromInt(v)
+	case float64:
+		*p = PointsFromFloat(v)
+	default:
+		return xerrors.Errorf("can not scan %T into points", src)
+	}
+
+	return nil
+}
+
+func (p *Points) scanString(s string) error {
+	v, err := ParsePoints(s)
+	if err != nil {
+		return err
+	}
+
+	*p = v
+	return nil
+}
+
+// Value implements driver.Valuer, the points are written as a decimal string
+func (p Points) Value() (driver.Value, error) {
+	return p.StringFixed(PointsScale), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundQuo returns num/den rounded half away from zero
+func roundQuo(num, den *big.Int) *big.Int {
+	if den.Sign() < 0 {
+		num = new(big.Int).Neg(num)
+		den = new(big.Int).Neg(den)
+	}
+
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPoints(t *testing.T) {
+	a, err := ParsePoints("0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 0.1 is not exact in float64, the sum of ten is
+	sum := Points{}
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(a)
+	}
+	if sum.Cmp(PointsFromInt(1)) != 0 {
+		t.Errorf("expected 1, got %s", sum)
+	}
+
+	// far beyond the range of DECIMAL(14, 6) and the exact integers of float64
+	large, _ := ParsePoints("123456789012345678901.000000000001")
+	if s := large.Add(large).String(); s != "246913578024691357802.000000000002" {
+		t.Errorf("unexpected sum %s", s)
+	}
+
+	cases := []struct {
+		in       string
+		decimals int
+		out      string
+	}{
+		{"1.2345675", 6, "1.234568"},
+		{"-1.2345675", 6, "-1.234568"},
+		{"0.0000004", 6, "0.000000"},
+		{"-0.5", 0, "-1"},
+		{"42", 2, "42.00"},
+	}
+	for _, c := range cases {
+		p, err := ParsePoints(c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := p.StringFixed(c.decimals); s != c.out {
+			t.Errorf("%s rounded to %d decimals: expected %s, got %s", c.in, c.decimals, c.out, s)
+		}
+	}
+
+	if s := PointsFromFloat(0.3).String(); s != "0.3" {
+		t.Errorf("expected 0.3, got %s", s)
+	}
+
+	if s := PointsFromInt(10).MulDiv(PointsFromInt(1), PointsFromInt(3)).String(); s != "3.333333333333" {
+		t.Errorf("unexpected quotient %s", s)
+	}
+}
+
+func TestPointsEncoding(t *testing.T) {
+	p, _ := ParsePoints("12.5")
+
+	buf, err := json.Marshal(struct{ P Points }{p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != `{"P":12.5}` {
+		t.Errorf("unexpected json %s", buf)
+	}
+
+	// decodable as a float by the old clients
+	var f struct{ P float64 }
+	if err = json.Unmarshal(buf, &f); err != nil || f.P != 12.5 {
+		t.Errorf("unexpected float %v %v", f.P, err)
+	}
+
+	var out struct{ P Points }
+	if err = json.Unmarshal(buf, &out); err != nil || out.P.Cmp(p) != 0 {
+		t.Errorf("unexpected points %s %v", out.P, err)
+	}
+
+	v, _ := p.Value()
+	var scanned Points
+	if err = scanned.Scan([]byte(v.(string))); err != nil || scanned.Cmp(p) != 0 {
+		t.Errorf("unexpected scanned points %s %v", scanned, err)
+	}
+	if err = scanned.Scan(int64(7)); err != nil || scanned.Cmp(PointsFromInt(7)) != 0 {
+		t.Errorf("unexpected scanned integer %s %v", scanned, err)
+	}
+}
@@ -0,0 +1,52 @@
+package types
+
+import "time"
+
+// PointsEpochSummary a closed epoch of points, an epoch is a utc day
+type PointsEpochSummary struct {
+	Epoch time.Time `db:"epoch"`
+	// NodeCount the nodes that earned or lost points in the epoch
+	NodeCount int `db:"node_count"`
+	// Points the points earned by all nodes in the epoch, the corrections are not included
+	Points     Points    `db:"points"`
+	ClosedTime time.Time `db:"closed_time"`
+}
+
+// PointsEpoch the points a node earned in a closed epoch, the epoch is immutable once closed
+// and the later changes are made by the corrections of the epoch
+type PointsEpoch struct {
+	Epoch  time.Time `db:"epoch"`
+	NodeID string    `db:"node_id"`
+	Points Points    `db:"points"`
+	// Total the cumulative points of the node at the close of the epoch
+	Total Points `db:"total"`
+	// Correction the sum of the corrections made to the epoch, set when loaded
+	Correction Points `db:"-"`
+}
+
+// PointsCorrection a correction of the points of a node in a closed epoch, the points are added to the node
+type PointsCorrection struct {
+	ID     int64     `db:"id"`
+	Epoch  time.Time `db:"epoch"`
+	NodeID string    `db:"node_id"`
+	// Points the points added to the node, negative to deduct
+	Points      Points    `db:"points"`
+	Reason      string    `db:"reason"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// NodePointsEpochsRsp the epochs of a node in a period with the totals derived from the epochs
+type NodePointsEpochsRsp struct {
+	Epochs      []*PointsEpoch
+	Corrections []*PointsCorrection
+	// ClosedPoints the points of all the closed epochs of the node with the corrections
+	ClosedPoints Points
+	// OpenPoints the points earned in the epoch not closed yet
+	OpenPoints Points
+}
+
+// ListPointsEpochRsp list closed points epochs
+type ListPointsEpochRsp struct {
+	Total int64                 `json:"total"`
+	Data  []*PointsEpochSummary `json:"data"`
+}
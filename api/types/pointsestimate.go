@@ -0,0 +1,29 @@
+package types
+
+// PointsEstimateReq the hypothetical parameters of an edge node to estimate the points of
+type PointsEstimateReq struct {
+	// BandwidthUp the upload bandwidth in bytes per second
+	BandwidthUp int64
+	NATType     NatType
+	// TitanDiskUsage the bytes of the disk used to store the assets
+	TitanDiskUsage float64
+	// NodesOnIP the nodes sharing the external ip, including the node, 1 if not set
+	NodesOnIP int
+}
+
+// PointsEstimateRsp the points the node would earn by the live scoring formula
+type PointsEstimateRsp struct {
+	// ValidationPoints the points earned by a passed validation
+	ValidationPoints Points
+	// OnlinePointsPerHour the points earned by staying online for an hour
+	OnlinePointsPerHour Points
+
+	// the factors of the formula
+	BandwidthFactor float64
+	NATFactor       float64
+	// NetworkFactor the weighting by the number of edges in the network
+	NetworkFactor float64
+	NetworkEdges  int
+	// StoragePoints the part of the validation points earned by the disk usage
+	StoragePoints Points
+}
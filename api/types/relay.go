@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+// RelaySession a session set up by the scheduler in which the candidate relays the retrievals
+// of the edge behind symmetric nat, the relayed bytes are accounted to both of them
+type RelaySession struct {
+	ID          string `db:"id"`
+	EdgeID      string `db:"edge_id"`
+	CandidateID string `db:"candidate_id"`
+	// CandidateAddress the address the edge connects to the candidate with
+	CandidateAddress string `db:"-"`
+	// Key the secret the edge authenticates to the candidate with
+	Key          string    `db:"-"`
+	RelayedBytes int64     `db:"relayed_bytes"`
+	CreatedTime  time.Time `db:"created_time"`
+	UpdatedTime  time.Time `db:"updated_time"`
+}
+
+// RelayTraffic the bytes relayed in the session since the last report of the candidate
+type RelayTraffic struct {
+	SessionID string
+	EdgeID    string
+	Bytes     int64
+}
+
+// ListRelaySessionRsp list relay sessions
+type ListRelaySessionRsp struct {
+	Total int64           `json:"total"`
+	Data  []*RelaySession `json:"data"`
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ReplicationAgreementState the state of a replication agreement
+type ReplicationAgreementState string
+
+const (
+	// ReplicationAgreementActive the assets of the tenant are streamed to the peer area
+	ReplicationAgreementActive ReplicationAgreementState = "active"
+	// ReplicationAgreementPaused the streaming is paused, it continues from its cursor once the agreement is active again
+	ReplicationAgreementPaused ReplicationAgreementState = "paused"
+)
+
+// ReplicationAgreement mirrors all the assets of a tenant into the schedulers of another area, the schedulers of
+// the area of the tenant stream the manifests of the assets to a scheduler of the peer area in the order they were
+// created, and the nodes of the peer area pull the assets from the nodes of the area of the tenant
+type ReplicationAgreement struct {
+	ID         string `db:"id"`
+	TenantID   string `db:"tenant_id"`
+	PeerAreaID string `db:"peer_area_id"`
+	// Replicas the edge replicas of the assets in the peer area, the default of the peer scheduler if 0
+	Replicas int64                     `db:"replicas"`
+	State    ReplicationAgreementState `db:"state"`
+	// CursorTime and CursorHash the creation time and the hash of the last asset streamed
+	CursorTime time.Time `db:"cursor_time"`
+	CursorHash string    `db:"cursor_hash"`
+	// Streamed the manifests accepted by the peer area
+	Streamed    int64     `db:"streamed"`
+	Message     string    `db:"message"`
+	SyncedTime  time.Time `db:"synced_time"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// Validate checks the tenant and the peer area of the agreement
+func (a *ReplicationAgreement) Validate() error {
+	if a.TenantID == "" {
+		return xerrors.New("tenant can not empty")
+	}
+
+	if a.PeerAreaID == "" {
+		return xerrors.New("peer area can not empty")
+	}
+
+	if a.Replicas < 0 {
+		return xerrors.Errorf("invalid replicas %d", a.Replicas)
+	}
+
+	return nil
+}
+
+// MirrorManifest the asset streamed to the peer area, the nodes of the peer area pull the blocks of the asset by its cid
+type MirrorManifest struct {
+	Hash        string    `db:"hash"`
+	CID         string    `db:"cid"`
+	Size        int64     `db:"total_size"`
+	Expiration  time.Time `db:"expiration"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// MirrorManifestBatch the manifests of an agreement streamed to a scheduler of the peer area in a call
+type MirrorManifestBatch struct {
+	AgreementID string
+	TenantID    string
+	// SourceServerID and SourceAreaID the scheduler streaming the manifests, the nodes of the peer area pull the assets
+	// from the nodes of the scheduler
+	SourceServerID string
+	SourceAreaID   string
+	Replicas       int64
+	Manifests      []*MirrorManifest
+}
+
+// MirroredAsset an asset mirrored from another area by a replication agreement
+type MirroredAsset struct {
+	Hash           string    `db:"hash"`
+	CID            string    `db:"cid"`
+	AgreementID    string    `db:"agreement_id"`
+	TenantID       string    `db:"tenant_id"`
+	SourceServerID string    `db:"source_server_id"`
+	SourceAreaID   string    `db:"source_area_id"`
+	CreatedTime    time.Time `db:"created_time"`
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReportKind the kind of the reports the nodes submit to the scheduler
+type ReportKind string
+
+const (
+	// ReportWorkload the workload report of the bytes the node served
+	ReportWorkload ReportKind = "workload"
+	// ReportWorkloadReceipt the receipt of the bytes the node downloaded from the other nodes
+	ReportWorkloadReceipt ReportKind = "workload_receipt"
+	// ReportBandwidth the bandwidths the node measured
+	ReportBandwidth ReportKind = "bandwidth"
+	// ReportValidation the validation result of the validator
+	ReportValidation ReportKind = "validation"
+)
+
+// BandwidthReport the bandwidths measured by the node, signed by the node private key
+type BandwidthReport struct {
+	// unit: byte per second, not reported if 0
+	BandwidthDown int64
+	BandwidthUp   int64
+	ReportTime    time.Time
+	Sign          []byte
+}
+
+// SignData returns the data of the report that is signed by the node
+func (r *BandwidthReport) SignData() []byte {
+	return []byte(fmt.Sprintf("%d:%d:%d", r.BandwidthDown, r.BandwidthUp, r.ReportTime.Unix()))
+}
+
+// ReportRejection the reports of the kind of a node rejected by the scheduler for missing or mismatched signatures
+type ReportRejection struct {
+	NodeID      string     `db:"node_id"`
+	Kind        ReportKind `db:"kind"`
+	Count       int64      `db:"count"`
+	LastReason  string     `db:"last_reason"`
+	UpdatedTime time.Time  `db:"updated_time"`
+}
@@ -0,0 +1,101 @@
+package types
+
+import "time"
+
+// RetrievalProbe a ranged retrieval of an asset from a replica by the scheduler
+type RetrievalProbe struct {
+	ID       int64    `db:"id"`
+	NodeID   string   `db:"node_id"`
+	NodeType NodeType `db:"node_type"`
+	AreaID   string   `db:"area_id"`
+	CID      string   `db:"cid"`
+	Success  bool     `db:"success"`
+	// the probe took more than the ttfb threshold to the first byte or transferred below the minimum throughput
+	Slow bool `db:"slow"`
+	// time to first byte (Unit:millisecond)
+	TTFB int64 `db:"ttfb"`
+	// bytes received
+	Bytes int64 `db:"bytes"`
+	// duration of the whole retrieval (Unit:millisecond)
+	Duration int64 `db:"duration"`
+	// unit: byte per second
+	Throughput  int64     `db:"throughput"`
+	Message     string    `db:"message"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// ListRetrievalProbeRsp the retrieval probes of a node
+type ListRetrievalProbeRsp struct {
+	Total  int               `json:"total"`
+	Probes []*RetrievalProbe `json:"probes"`
+}
+
+// RetrievalSLAGroup the key the retrieval probes are grouped by in the sla report
+type RetrievalSLAGroup string
+
+const (
+	// RetrievalSLAByNode groups the probes by node id
+	RetrievalSLAByNode RetrievalSLAGroup = "node"
+	// RetrievalSLAByArea groups the probes by area id
+	RetrievalSLAByArea RetrievalSLAGroup = "area"
+)
+
+// RetrievalSLAEntry the retrieval probes of a node or an area in the period of the report
+type RetrievalSLAEntry struct {
+	Key      string `db:"group_key"`
+	Probes   int    `db:"probes"`
+	Failures int    `db:"failures"`
+	Slow     int    `db:"slow"`
+	// average time to first byte of the successful probes (Unit:millisecond)
+	AvgTTFB int64 `db:"avg_ttfb"`
+	// the slowest time to first byte of the successful probes (Unit:millisecond)
+	MaxTTFB int64 `db:"max_ttfb"`
+	// average throughput of the successful probes, unit: byte per second
+	AvgThroughput int64 `db:"avg_throughput"`
+	// the percent of the probes that succeeded and were not slow
+	SLA float64 `db:"-"`
+}
+
+// RetrievalSLAReport the sla of the retrievals probed in the period
+type RetrievalSLAReport struct {
+	Start   time.Time
+	End     time.Time
+	GroupBy RetrievalSLAGroup
+	Total   int
+	Entries []*RetrievalSLAEntry
+}
+
+// SpotCheck a retrieval of a random range of a replica on an edge disguised as a client, the bytes are compared with the
+// same range retrieved from a candidate
+type SpotCheck struct {
+	ID     int64  `db:"id"`
+	NodeID string `db:"node_id"`
+	AreaID string `db:"area_id"`
+	CID    string `db:"cid"`
+	// the range retrieved
+	RangeStart int64 `db:"range_start"`
+	RangeSize  int64 `db:"range_size"`
+	// the proxy of the designated probe the check was sent through, empty if sent from the scheduler
+	Proxy   string `db:"proxy"`
+	Success bool   `db:"success"`
+	// Verified the bytes were compared with the bytes of a candidate
+	Verified bool `db:"verified"`
+	// Mismatched the bytes differ from the bytes of the candidate
+	Mismatched bool `db:"mismatched"`
+	// the throughput is below the ratio of the upload bandwidth claimed by the edge
+	Slow bool `db:"slow"`
+	// time to first byte (Unit:millisecond)
+	TTFB int64 `db:"ttfb"`
+	// unit: byte per second
+	Throughput int64 `db:"throughput"`
+	// the upload bandwidth the edge claimed in the validations (Unit:byte per second)
+	ClaimedBandwidth int64     `db:"claimed_bandwidth"`
+	Message          string    `db:"message"`
+	CreatedTime      time.Time `db:"created_time"`
+}
+
+// ListSpotCheckRsp the spot checks of a node
+type ListSpotCheckRsp struct {
+	Total  int          `json:"total"`
+	Checks []*SpotCheck `json:"checks"`
+}
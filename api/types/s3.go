@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// S3AccessKey the credential of the user to access the s3 gateway of candidates
+type S3AccessKey struct {
+	AccessKey   string    `db:"access_key"`
+	SecretKey   string    `db:"secret_key"`
+	UserID      string    `db:"user_id"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// S3AuthReq the signed s3 request to be authorized by the scheduler, the secret key never leaves the scheduler
+type S3AuthReq struct {
+	AccessKey    string
+	StringToSign string
+	Signature    string
+}
+
+// S3Grant the user authorized by the scheduler for a signed s3 request served by the candidate,
+// the objects put by the candidate are recorded for the user of the grant
+type S3Grant struct {
+	UserID     string
+	NodeID     string
+	Expiration time.Time
+	// signature of the scheduler over the SignContent
+	Sign string
+}
+
+// SignContent returns the content of the grant signed by the scheduler
+func (g *S3Grant) SignContent() []byte {
+	return []byte(fmt.Sprintf("%s,%s,%d", g.UserID, g.NodeID, g.Expiration.Unix()))
+}
+
+// S3Object an object of the s3 gateway, the content of the object is the asset with the cid
+type S3Object struct {
+	UserID      string    `db:"user_id"`
+	Bucket      string    `db:"bucket"`
+	Key         string    `db:"object_key"`
+	AssetCID    string    `db:"cid"`
+	Size        int64     `db:"size"`
+	CarSize     int64     `db:"car_size"`
+	ETag        string    `db:"etag"`
+	ContentType string    `db:"content_type"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// ListS3ObjectsReq the request to list the objects of the bucket in key order
+type ListS3ObjectsReq struct {
+	UserID string
+	Bucket string
+	Prefix string
+	// list the keys after the key
+	StartAfter string
+	Limit      int
+}
+
+// ListS3ObjectsRsp the objects of the bucket
+type ListS3ObjectsRsp struct {
+	Objects     []*S3Object
+	IsTruncated bool
+}
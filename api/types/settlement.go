@@ -0,0 +1,72 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// SettlementEpoch an epoch of points frozen and settled to the accounts
+type SettlementEpoch struct {
+	Epoch     int64     `db:"epoch"`
+	StartTime time.Time `db:"start_time"`
+	EndTime   time.Time `db:"end_time"`
+	// TotalPoints points earned by the nodes bound to accounts in the epoch
+	TotalPoints Points `db:"total_points"`
+	// TotalReward reward distributed to the accounts in proportion to the points
+	TotalReward  float64 `db:"total_reward"`
+	AccountCount int     `db:"account_count"`
+	// MerkleRoot hex of the merkle root of the shares, see SettlementShare.Leaf
+	MerkleRoot string `db:"merkle_root"`
+	// Signature hex of the scheduler signature of the SignContent
+	Signature string `db:"signature"`
+	// Exported the epoch has been handed to the payout hook
+	Exported    bool      `db:"exported"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// SignContent returns the content of the epoch signed by the scheduler
+func (e *SettlementEpoch) SignContent() []byte {
+	return []byte(fmt.Sprintf("%d,%d,%d,%s,%.6f,%d,%s",
+		e.Epoch, e.StartTime.Unix(), e.EndTime.Unix(), e.TotalPoints.StringFixed(6), e.TotalReward, e.AccountCount, e.MerkleRoot))
+}
+
+// SettlementShare the share of the account in a settlement epoch
+type SettlementShare struct {
+	Epoch     int64  `db:"epoch"`
+	AccountID string `db:"account_id"`
+	Points    Points `db:"points"`
+	// Share ratio of the points of the account to the total points of the epoch
+	Share  float64 `db:"share"`
+	Amount float64 `db:"amount"`
+}
+
+// Leaf returns the content of the merkle leaf of the share, the leaf hash is the sha256 of the content
+func (s *SettlementShare) Leaf() []byte {
+	return []byte(fmt.Sprintf("%d,%s,%.6f", s.Epoch, s.AccountID, s.Amount))
+}
+
+// SettlementReport the signed report of a settlement epoch handed to the payout hook
+type SettlementReport struct {
+	Epoch  *SettlementEpoch
+	Shares []*SettlementShare
+}
+
+// SettlementProof the merkle proof of the share of the account for the on-chain distributor
+type SettlementProof struct {
+	Share      *SettlementShare
+	MerkleRoot string
+	// Proof hex of the sibling hashes from the leaf to the root, the pairs are hashed in sorted order
+	Proof []string
+}
+
+// ListSettlementEpochRsp list settlement epochs
+type ListSettlementEpochRsp struct {
+	Total int64              `json:"total"`
+	Data  []*SettlementEpoch `json:"data"`
+}
+
+// ListSettlementShareRsp list settlement shares
+type ListSettlementShareRsp struct {
+	Total int64              `json:"total"`
+	Data  []*SettlementShare `json:"data"`
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// SignedURLExpiresParam the query parameter of the signed url holding the unix time it expires at
+	SignedURLExpiresParam = "expires"
+	// SignedURLSignatureParam the query parameter of the signed url holding the hex signature of the scheduler
+	SignedURLSignatureParam = "signature"
+)
+
+// SignedURL a time-limited url to share the asset publicly, the nodes verify the signature and the expiration
+// with the public key of the scheduler, so the url carries no token
+type SignedURL struct {
+	AssetCID string
+	// Path the path and the query of the url, valid on any node holding the asset
+	Path string
+	// URLs the urls on the candidates holding the asset
+	URLs       []string
+	Expiration time.Time
+	// QuotaWarning set if the traffic of the tenant of the user passes its soft limit
+	QuotaWarning string `json:",omitempty"`
+}
+
+// SignedURLContent returns the content the scheduler signs for the signed url of the asset
+func SignedURLContent(assetCID string, expires int64) []byte {
+	return []byte(fmt.Sprintf("titan-signed-url\n%s\n%d", assetCID, expires))
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StorageChallenge is a random challenge sent to a node to prove it holds a replica. The node picks the blocks
+// of the asset by the seed and returns the hashes of the samples at the random offsets of the blocks
+type StorageChallenge struct {
+	ID   string
+	CID  string
+	Seed int64
+	// BlockCount number of the blocks of the asset sampled
+	BlockCount int
+	// SampleSize bytes of a block hashed from its random offset
+	SampleSize int
+}
+
+// StorageChallengeResult is the result of a storage challenge, signed by the node private key
+type StorageChallengeResult struct {
+	ChallengeID string
+	// Hashes the hashes of the samples of the blocks, in the order the blocks are picked
+	Hashes []string
+	Sign   []byte
+}
+
+// SignData returns the data of the result that is signed by the node
+func (r *StorageChallengeResult) SignData() []byte {
+	return []byte(fmt.Sprintf("%s:%s", r.ChallengeID, strings.Join(r.Hashes, ",")))
+}
+
+// StorageProof is the verified result of the latest storage challenge of a replica
+type StorageProof struct {
+	Hash   string `db:"hash"`
+	CID    string `db:"cid"`
+	NodeID string `db:"node_id"`
+	Passed bool   `db:"passed"`
+	// Failures the challenges of the replica failed in a row, the replica is lost once they reach the limit
+	Failures int `db:"failures"`
+	// Checker the node holding the replica that calculated the expected hashes
+	Checker string `db:"checker"`
+	// duration of the challenge (Unit:millisecond)
+	Duration  int64     `db:"duration"`
+	Message   string    `db:"message"`
+	ProofTime time.Time `db:"proof_time"`
+}
+
+// ListStorageProofRsp the storage proofs of the replicas of a node
+type ListStorageProofRsp struct {
+	Total  int             `json:"total"`
+	Proofs []*StorageProof `json:"proofs"`
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Tenant a namespace of users, e.g. the customers of a cdn reseller. The assets, the storage, the traffic
+// and the requests of the users of a tenant are counted and limited together
+type Tenant struct {
+	ID   string `db:"tenant_id"`
+	Name string `db:"name"`
+	// StorageQuota the bytes the users of the tenant store at most, unlimited if 0
+	StorageQuota int64 `db:"storage_quota"`
+	// TrafficQuota the bytes the users of the tenant download each month at most, unlimited if 0
+	TrafficQuota int64 `db:"traffic_quota"`
+	// RateLimit the requests the users of the tenant make each second at most, unlimited if 0
+	RateLimit int `db:"rate_limit"`
+	// SoftLimit the percent of the quotas the users are warned at, DefaultTenantSoftLimit if 0
+	SoftLimit int `db:"soft_limit"`
+	// GeoRules the geo policy of the assets uploaded by the users of the tenant, the replicas are placed anywhere if empty
+	GeoRules    AssetGeoRules `db:"geo_rules"`
+	CreatedTime time.Time     `db:"created_time"`
+	UpdatedTime time.Time     `db:"updated_time"`
+}
+
+// DefaultTenantSoftLimit the percent of the quotas the users of a tenant are warned at if the tenant does not set it
+const DefaultTenantSoftLimit = 80
+
+// SoftLimitPercent returns the percent of the quotas the users of the tenant are warned at
+func (t *Tenant) SoftLimitPercent() int {
+	if t.SoftLimit <= 0 {
+		return DefaultTenantSoftLimit
+	}
+	return t.SoftLimit
+}
+
+// Validate checks the quotas and the geo rules of the tenant
+func (t *Tenant) Validate() error {
+	if t.ID == "" {
+		return xerrors.New("tenant id can not empty")
+	}
+
+	if t.StorageQuota < 0 || t.TrafficQuota < 0 || t.RateLimit < 0 {
+		return xerrors.New("quotas and rate limit of the tenant can not be negative")
+	}
+
+	if t.SoftLimit < 0 || t.SoftLimit > 100 {
+		return xerrors.New("soft limit of the tenant must be a percent between 0 and 100")
+	}
+
+	if len(t.GeoRules) > 0 {
+		return t.GeoRules.Validate()
+	}
+
+	return nil
+}
+
+// TenantUser a user of a tenant
+type TenantUser struct {
+	TenantID    string    `db:"tenant_id"`
+	UserID      string    `db:"user_id"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// ListTenantUserRsp list the users of a tenant
+type ListTenantUserRsp struct {
+	Total int           `json:"total"`
+	Data  []*TenantUser `json:"data"`
+}
+
+// TenantUsageDaily the usage of a tenant in a day
+type TenantUsageDaily struct {
+	TenantID string    `db:"tenant_id"`
+	Day      time.Time `db:"day"`
+	// Requests the requests of the users admitted
+	Requests int64 `db:"requests"`
+	// Throttled the requests of the users rejected by the rate limit
+	Throttled int64 `db:"throttled"`
+	// Uploaded the bytes of the assets the users uploaded
+	Uploaded int64 `db:"uploaded"`
+	// Traffic the bytes the users downloaded
+	Traffic int64 `db:"traffic"`
+}
+
+// TenantUsage the current usage of a tenant against its quotas
+type TenantUsage struct {
+	Tenant *Tenant
+	Users  int64
+	Assets int64
+	// StorageUsed the bytes the users of the tenant store
+	StorageUsed int64
+	// Month the usage of the month so far
+	Month *TenantUsageDaily
+}
+
+// TenantUsageReport the daily usage of a tenant in a period, the days without usage are omitted
+type TenantUsageReport struct {
+	TenantID string
+	Start    time.Time
+	End      time.Time
+	Days     []*TenantUsageDaily
+	// Total the sum of the days
+	Total *TenantUsageDaily
+}
+
+// TenantQuota the quota counters of a tenant, updated in the transactions recording the assets
+// and the downloads of its users
+type TenantQuota struct {
+	TenantID string `db:"tenant_id"`
+	// StoredBytes the bytes of the assets the users of the tenant store
+	StoredBytes int64 `db:"stored_bytes"`
+	// EgressMonth the month EgressBytes is counted in
+	EgressMonth time.Time `db:"egress_month"`
+	// EgressBytes the bytes the users of the tenant downloaded in EgressMonth
+	EgressBytes int64     `db:"egress_bytes"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
+
+// TenantQuotaStatus the counters of a tenant against its quotas, a counter passing the soft limit is warned
+// and a counter reaching the quota is enforced
+type TenantQuotaStatus struct {
+	Tenant          *Tenant
+	StoredBytes     int64
+	EgressBytes     int64
+	StorageWarning  bool
+	StorageExceeded bool
+	TrafficWarning  bool
+	TrafficExceeded bool
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+)
+
+// APIToken an api token issued by the scheduler, the role of the token is assigned by the scheduler
+type APIToken struct {
+	ID          string          `db:"id"`
+	Name        string          `db:"name"`
+	Role        auth.Permission `db:"role"`
+	CreatedTime time.Time       `db:"created_time"`
+}
+
+// TokenSubject the issuing and revocation state of the tokens of a subject,
+// the subject is the id of the tokens: node id, user id or api token id
+type TokenSubject struct {
+	Subject string          `db:"subject"`
+	Role    auth.Permission `db:"role"`
+	// unix nano time the latest token is issued
+	IssuedAt int64 `db:"issued_at"`
+	// unix nano time the tokens are revoked, 0 if never revoked
+	RevokedAt int64 `db:"revoked_at"`
+}
+
+// ListTokenSubjectRsp list token subjects
+type ListTokenSubjectRsp struct {
+	Data  []*TokenSubject `json:"data"`
+	Total int64           `json:"total"`
+}
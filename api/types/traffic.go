@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// NodeTrafficDaily the bytes the node uploaded and downloaded in a day
+type NodeTrafficDaily struct {
+	NodeID        string    `db:"node_id"`
+	Date          time.Time `db:"date"`
+	UploadBytes   int64     `db:"upload_bytes"`
+	DownloadBytes int64     `db:"download_bytes"`
+}
+
+// NodeTrafficStatement the traffic statement of the node in a month
+type NodeTrafficStatement struct {
+	NodeID string `db:"node_id"`
+	// Month formatted as 2006-01
+	Month         string `db:"month"`
+	UploadBytes   int64  `db:"upload_bytes"`
+	DownloadBytes int64  `db:"download_bytes"`
+	// ActiveDays number of days the node has traffic
+	ActiveDays int `db:"active_days"`
+	// Final the statement of a closed month, the statement of the current month changes until the month closes
+	Final bool `db:"-"`
+	// Days the daily traffic of the month
+	Days []*NodeTrafficDaily `db:"-"`
+}
+
+// ListNodeTrafficStatementRsp list traffic statements
+type ListNodeTrafficStatementRsp struct {
+	Total int64                   `json:"total"`
+	Data  []*NodeTrafficStatement `json:"data"`
+}
@@ -0,0 +1,49 @@
+package types
+
+import "time"
+
+// TrustTier the trust the scheduler has in a node, the nodes of the lower tiers are validated more often
+type TrustTier string
+
+const (
+	// TrustTierProbation the nodes registered or penalized recently
+	TrustTierProbation TrustTier = "probation"
+	// TrustTierStandard the nodes neither on probation nor trusted
+	TrustTierStandard TrustTier = "standard"
+	// TrustTierTrusted the nodes registered long ago and not penalized for long
+	TrustTierTrusted TrustTier = "trusted"
+)
+
+// NodeTrust the trust tier of a node and the reason of its last transition
+type NodeTrust struct {
+	NodeID      string    `db:"node_id"`
+	Tier        TrustTier `db:"tier"`
+	Reason      string    `db:"reason"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
+
+// TrustTransition a transition of a node between the trust tiers
+type TrustTransition struct {
+	ID          int64     `db:"id"`
+	NodeID      string    `db:"node_id"`
+	FromTier    TrustTier `db:"from_tier"`
+	ToTier      TrustTier `db:"to_tier"`
+	Reason      string    `db:"reason"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// ListTrustTransitionRsp list the trust transitions of a node
+type ListTrustTransitionRsp struct {
+	Total int64              `json:"total"`
+	Data  []*TrustTransition `json:"data"`
+}
+
+// NodeTrustFacts the facts the trust tier of a node is evaluated on
+type NodeTrustFacts struct {
+	NodeID    string    `db:"node_id"`
+	FirstTime time.Time `db:"first_login_time"`
+	// LastPenaltyTime the time of the last penalty of the node not overturned by an appeal, nil if the node was never penalized
+	LastPenaltyTime *time.Time `db:"last_penalty_time"`
+	// Tier the current tier of the node, empty if the node was never evaluated
+	Tier TrustTier `db:"tier"`
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ReleaseArtifact the binary of a release for a platform
+type ReleaseArtifact struct {
+	// <os>/<arch>, e.g. linux/amd64
+	Platform string
+	URL      string
+	// sha256 of the binary in hex
+	Hash string
+}
+
+// ReleaseArtifacts the binaries of a release, kept as json in the database
+type ReleaseArtifacts []*ReleaseArtifact
+
+// Scan implements sql.Scanner for the json column
+func (a *ReleaseArtifacts) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return xerrors.Errorf("can not scan %T into release artifacts", src)
+	}
+}
+
+// Value implements driver.Valuer, the artifacts are written as json
+func (a ReleaseArtifacts) Value() (driver.Value, error) {
+	buf, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), nil
+}
+
+// Artifact returns the binary for the platform
+func (a ReleaseArtifacts) Artifact(platform string) *ReleaseArtifact {
+	for _, artifact := range a {
+		if artifact.Platform == platform {
+			return artifact
+		}
+	}
+	return nil
+}
+
+// CurrentPlatform returns the platform of the running binary
+func CurrentPlatform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// ReleaseManifest a release of the node app, signed by the release key configured on the scheduler
+type ReleaseManifest struct {
+	// e.g. 0.1.17
+	Version     string           `db:"version"`
+	NodeType    NodeType         `db:"node_type"`
+	Artifacts   ReleaseArtifacts `db:"artifacts"`
+	Sign        []byte           `db:"sign"`
+	CreatedTime time.Time        `db:"created_time"`
+}
+
+// SignData returns the content signed by the release key
+func (m *ReleaseManifest) SignData() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "titan-release\n%s\n%d", m.Version, m.NodeType)
+	for _, artifact := range m.Artifacts {
+		fmt.Fprintf(&b, "\n%s %s %s", artifact.Platform, artifact.URL, artifact.Hash)
+	}
+	return []byte(b.String())
+}
+
+// ReleaseVersion returns the release version of the system version of a node, e.g. 0.1.16 of 0.1.16+git.abc+api1.0.0
+func ReleaseVersion(systemVersion string) string {
+	return strings.SplitN(systemVersion, "+", 2)[0]
+}
+
+// UpgradeRolloutStatus status of an upgrade rollout
+type UpgradeRolloutStatus string
+
+const (
+	// UpgradeRolloutRunning the nodes of the current wave are commanded to upgrade
+	UpgradeRolloutRunning UpgradeRolloutStatus = "running"
+	// UpgradeRolloutHalted the rollout is halted by the operator or by the offline rate of a wave
+	UpgradeRolloutHalted UpgradeRolloutStatus = "halted"
+	// UpgradeRolloutCompleted all the waves are rolled out
+	UpgradeRolloutCompleted UpgradeRolloutStatus = "completed"
+)
+
+// UpgradeRolloutReq starts rolling out a release, the canary nodes first and then the waves of the percentages of the nodes
+type UpgradeRolloutReq struct {
+	Version     string
+	NodeType    NodeType
+	CanaryCount int
+	// the cumulative percentages of the nodes of the waves after the canary, e.g. 10,50,100
+	Waves []int
+	// the rollout halts if the rate of the nodes of a wave not back online with the release exceeds it
+	MaxOfflineRate float64
+	// minutes a wave is observed before the next wave
+	WaveInterval int
+}
+
+// UpgradeRollout a rollout of a release to the nodes of a type
+type UpgradeRollout struct {
+	ID          string   `db:"id"`
+	Version     string   `db:"version"`
+	NodeType    NodeType `db:"node_type"`
+	CanaryCount int      `db:"canary_count"`
+	// comma separated cumulative percentages of the waves
+	Waves          string  `db:"waves"`
+	MaxOfflineRate float64 `db:"max_offline_rate"`
+	// minutes
+	WaveInterval int `db:"wave_interval"`
+	// 0 is the canary wave
+	Wave            int                  `db:"wave"`
+	WaveStartedTime time.Time            `db:"wave_started_time"`
+	Status          UpgradeRolloutStatus `db:"status"`
+	Message         string               `db:"message"`
+	CreatedTime     time.Time            `db:"created_time"`
+	UpdatedTime     time.Time            `db:"updated_time"`
+}
+
+// ListUpgradeRolloutRsp list of the upgrade rollouts
+type ListUpgradeRolloutRsp struct {
+	Total    int               `json:"total"`
+	Rollouts []*UpgradeRollout `json:"rollouts"`
+}
+
+// UpgradeNodeStatus status of a node commanded to upgrade
+type UpgradeNodeStatus string
+
+const (
+	// UpgradeNodeCommanded the node replaced its binary and is restarting
+	UpgradeNodeCommanded UpgradeNodeStatus = "commanded"
+	// UpgradeNodeUpgraded the node is back online with the release
+	UpgradeNodeUpgraded UpgradeNodeStatus = "upgraded"
+	// UpgradeNodeFailed the node failed to download or verify the release
+	UpgradeNodeFailed UpgradeNodeStatus = "failed"
+)
+
+// UpgradeNode a node commanded to upgrade by a rollout
+type UpgradeNode struct {
+	RolloutID     string            `db:"rollout_id"`
+	NodeID        string            `db:"node_id"`
+	Wave          int               `db:"wave"`
+	FromVersion   string            `db:"from_version"`
+	Status        UpgradeNodeStatus `db:"status"`
+	Message       string            `db:"message"`
+	CommandedTime time.Time         `db:"commanded_time"`
+	UpgradedTime  time.Time         `db:"upgraded_time"`
+}
+
+// ListUpgradeNodeRsp list of the nodes commanded by a rollout
+type ListUpgradeNodeRsp struct {
+	Total int            `json:"total"`
+	Nodes []*UpgradeNode `json:"nodes"`
+}
+
+// UpgradeWaveStats the nodes of a wave of a rollout by status
+type UpgradeWaveStats struct {
+	Wave   int               `db:"wave"`
+	Status UpgradeNodeStatus `db:"status"`
+	Count  int               `db:"count"`
+}
+
+// UpgradeCommand commands the node to upgrade to the release, signed by the scheduler
+type UpgradeCommand struct {
+	RolloutID string
+	Manifest  *ReleaseManifest
+	// unix seconds
+	Expiration int64
+	Sign       []byte
+}
+
+// SignData returns the content signed by the scheduler
+func (c *UpgradeCommand) SignData() []byte {
+	return []byte(fmt.Sprintf("titan-upgrade\n%s\n%d\n%s", c.RolloutID, c.Expiration, c.Manifest.SignData()))
+}
+
+// VersionAdoption the number of the nodes running a version
+type VersionAdoption struct {
+	Version string `db:"version"`
+	Count   int    `db:"count"`
+}
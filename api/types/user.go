@@ -10,17 +10,26 @@ type AssetProperty struct {
 	NodeID    string
 	Password  string
 	GroupID   int
+	// ContentType and Labels the metadata of the asset, the assets of the user are searched by them
+	ContentType string
+	Labels      AssetLabels
 }
 
 type CreateAssetReq struct {
 	UserID string
 	AssetProperty
+	// Encryption the metadata of the asset encrypted by the client before the upload, nil if it is not encrypted
+	Encryption *AssetEncryption
+	// GeoRules the geo policy of the tenant of the user, set by the scheduler
+	GeoRules AssetGeoRules `json:"-"`
 }
 
 type CreateAssetRsp struct {
 	UploadURL     string
 	Token         string
 	AlreadyExists bool
+	// QuotaWarning set if the storage of the tenant of the user passes its soft limit
+	QuotaWarning string `json:",omitempty"`
 }
 
 type AuthUserUploadDownloadAsset struct {
@@ -30,6 +39,35 @@ type AuthUserUploadDownloadAsset struct {
 	Expiration time.Time
 }
 
+// IngestAssetReq the request to ingest an asset whose cid is known after it is uploaded,
+// the candidate converts the uploaded file to car if it is not a car
+type IngestAssetReq struct {
+	UserID    string
+	AssetName string
+	AssetSize int64
+	AssetType string
+	Password  string
+	GroupID   int
+	// ContentType and Labels the metadata of the asset, the assets of the user are searched by them
+	ContentType string
+	Labels      AssetLabels
+}
+
+// AuthUserIngestAsset the payload of the token to ingest an asset on the candidate
+type AuthUserIngestAsset struct {
+	IngestID   string
+	UserID     string
+	AssetSize  int64
+	Expiration time.Time
+}
+
+// IngestAssetResult the asset ingested by the candidate
+type IngestAssetResult struct {
+	IngestID  string
+	AssetCID  string
+	AssetSize int64
+}
+
 type UploadProgress struct {
 	TotalSize int64
 	DoneSize  int64
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// ValidationProof the record of a validation result signed by the validator and countersigned by the scheduler,
+// the records of a node are chained by the hash of the previous record in the order of Seq
+type ValidationProof struct {
+	ID          int64            `db:"id"`
+	NodeID      string           `db:"node_id"`
+	Seq         int64            `db:"seq"`
+	RoundID     string           `db:"round_id"`
+	ValidatorID string           `db:"validator_id"`
+	Status      ValidationStatus `db:"status"`
+	BlockNumber int64            `db:"block_number"`
+	Bandwidth   float64          `db:"bandwidth"`
+	// Report the result reported by the validator, empty if the validator reported nothing, e.g. the node timed out
+	Report []byte `db:"report"`
+	// ValidatorSign hex of the validator signature of the Report
+	ValidatorSign string `db:"validator_sign"`
+	// PrevHash the Hash of the previous record of the node, empty for the first record
+	PrevHash string `db:"prev_hash"`
+	// Hash hex of the sha256 of the SignContent
+	Hash string `db:"hash"`
+	// SchedulerSign hex of the scheduler signature of the SignContent
+	SchedulerSign string    `db:"scheduler_sign"`
+	CreatedTime   time.Time `db:"created_time"`
+}
+
+// SignContent returns the content of the record countersigned by the scheduler
+func (p *ValidationProof) SignContent() []byte {
+	return []byte(fmt.Sprintf("%s,%d,%s,%s,%d,%d,%.2f,%x,%s,%d,%s",
+		p.NodeID, p.Seq, p.RoundID, p.ValidatorID, p.Status, p.BlockNumber, p.Bandwidth, sha256.Sum256(p.Report), p.ValidatorSign,
+		p.CreatedTime.Unix(), p.PrevHash))
+}
+
+// ContentHash returns the hex of the sha256 of the SignContent
+func (p *ValidationProof) ContentHash() string {
+	return fmt.Sprintf("%x", sha256.Sum256(p.SignContent()))
+}
+
+// ListValidationProofRsp list the validation proofs of a node
+type ListValidationProofRsp struct {
+	Total int64              `json:"total"`
+	Data  []*ValidationProof `json:"data"`
+}
+
+// ValidationProofAudit the result of checking the chain of the validation proofs of a node
+type ValidationProofAudit struct {
+	NodeID string
+	// Records the records checked
+	Records int64
+	// Valid the hashes, the links and the signatures of the records checked are all valid
+	Valid bool
+	// BrokenSeq the seq of the first invalid record
+	BrokenSeq int64
+	Reason    string
+}
@@ -0,0 +1,47 @@
+package types
+
+import "time"
+
+// AssetVideoFormat the streaming format of a video asset, the nodes serving the marked assets parse their playlists
+// to prefetch the next segments and count the retrievals of the segments
+type AssetVideoFormat string
+
+const (
+	// AssetVideoFormatNone the asset is not streamed by segments
+	AssetVideoFormatNone AssetVideoFormat = ""
+	// AssetVideoFormatHLS the asset is a directory of m3u8 playlists and their segments
+	AssetVideoFormatHLS AssetVideoFormat = "hls"
+	// AssetVideoFormatDASH the asset is a directory of mpd manifests and their segments
+	AssetVideoFormatDASH AssetVideoFormat = "dash"
+)
+
+// IsValid returns true if the format is known
+func (f AssetVideoFormat) IsValid() bool {
+	switch f {
+	case AssetVideoFormatNone, AssetVideoFormatHLS, AssetVideoFormatDASH:
+		return true
+	}
+	return false
+}
+
+// SegmentHits the retrievals of a segment of a video asset counted by a node since its last report
+type SegmentHits struct {
+	AssetCID string
+	// Segment the path of the segment in the asset, e.g. 720p/seg_005.ts
+	Segment string
+	Hits    int64
+}
+
+// SegmentStats the retrievals of a segment of a video asset counted by all the nodes
+type SegmentStats struct {
+	Hash        string    `db:"hash"`
+	Segment     string    `db:"segment"`
+	Hits        int64     `db:"hits"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
+
+// ListSegmentStatsRsp list the segments of a video asset, the most retrieved first
+type ListSegmentStatsRsp struct {
+	Total    int             `json:"total"`
+	Segments []*SegmentStats `json:"segments"`
+}
@@ -0,0 +1,184 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// WebhookEvent a milestone of the lifecycle of an asset the webhooks are called on
+type WebhookEvent string
+
+const (
+	// WebhookReplicationComplete the asset reaches the servicing state with its replicas pulled
+	WebhookReplicationComplete WebhookEvent = "replication_complete"
+	// WebhookReplicaLost a replica of the asset is removed from a node while the asset is kept
+	WebhookReplicaLost WebhookEvent = "replica_lost"
+	// WebhookAssetExpired the asset expires and is removed
+	WebhookAssetExpired WebhookEvent = "asset_expired"
+	// WebhookAssetPurged the asset and its replicas are removed
+	WebhookAssetPurged WebhookEvent = "asset_purged"
+)
+
+// WebhookEventsAll the events a webhook without events is called on
+var WebhookEventsAll = WebhookEvents{WebhookReplicationComplete, WebhookReplicaLost, WebhookAssetExpired, WebhookAssetPurged}
+
+// WebhookEvents the events of a webhook, kept as a comma separated list in the database
+type WebhookEvents []WebhookEvent
+
+// Scan implements the sql.Scanner interface
+func (e *WebhookEvents) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+	default:
+		return xerrors.Errorf("unsupported type %T for webhook events", src)
+	}
+
+	*e = nil
+	for _, event := range strings.Split(s, ",") {
+		if event != "" {
+			*e = append(*e, WebhookEvent(event))
+		}
+	}
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (e WebhookEvents) Value() (driver.Value, error) {
+	events := make([]string, 0, len(e))
+	for _, event := range e {
+		events = append(events, string(event))
+	}
+	return strings.Join(events, ","), nil
+}
+
+// Has checks if the webhook is called on the event, a webhook without events is called on all events
+func (e WebhookEvents) Has(event WebhookEvent) bool {
+	if len(e) == 0 {
+		return true
+	}
+
+	for _, ev := range e {
+		if ev == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the events are known
+func (e WebhookEvents) Validate() error {
+	for _, event := range e {
+		if !WebhookEventsAll.Has(event) {
+			return xerrors.Errorf("unknown webhook event %s", event)
+		}
+	}
+	return nil
+}
+
+// Webhook a url called with the signed callbacks on the lifecycle events of an asset of a user,
+// or of all the assets of the users of a tenant
+type Webhook struct {
+	ID int64 `db:"id"`
+	// UserID the user of the asset of an asset webhook
+	UserID string `db:"user_id"`
+	// TenantID the tenant of a tenant webhook
+	TenantID string `db:"tenant_id"`
+	// Hash the asset of an asset webhook
+	Hash     string        `db:"hash"`
+	AssetCID string        `db:"cid"`
+	URL      string        `db:"url"`
+	Events   WebhookEvents `db:"events"`
+	// Secret the key the callbacks are signed with, returned only when the webhook is created
+	Secret      string    `db:"secret" json:",omitempty"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// ValidateURL checks the url of the webhook is an absolute http or https url and its host is not a local address,
+// the addresses the host names resolve to are checked when the callbacks are sent
+func (w *Webhook) ValidateURL() error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return xerrors.Errorf("invalid webhook url: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return xerrors.Errorf("webhook url %s must be an absolute http or https url", w.URL)
+	}
+
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return xerrors.Errorf("webhook url %s must not call a local address", w.URL)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !WebhookIPAllowed(ip) {
+		return xerrors.Errorf("webhook url %s must not call a local address", w.URL)
+	}
+
+	return nil
+}
+
+// WebhookIPAllowed checks the webhooks may call the ip, the loopback, private, link-local, multicast and
+// unspecified addresses reach the network of the scheduler and are rejected
+func WebhookIPAllowed(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// WebhookDeliveryStatus the status of the delivery of a callback
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending the callback is not delivered yet and is retried
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered the url accepted the callback
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed the callback is not delivered in the attempts
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery a callback of a webhook and its delivery
+type WebhookDelivery struct {
+	ID        int64                 `db:"id"`
+	WebhookID int64                 `db:"webhook_id"`
+	Event     WebhookEvent          `db:"event"`
+	Hash      string                `db:"hash"`
+	Payload   json.RawMessage       `db:"payload"`
+	Status    WebhookDeliveryStatus `db:"status"`
+	Attempts  int                   `db:"attempts"`
+	// NextTime the time the pending callback is sent at
+	NextTime time.Time `db:"next_time"`
+	// LastError the error of the last failed attempt
+	LastError   string    `db:"last_error"`
+	CreatedTime time.Time `db:"created_time"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
+
+// ListWebhookDeliveryRsp list the deliveries of a webhook
+type ListWebhookDeliveryRsp struct {
+	Total int                `json:"total"`
+	Data  []*WebhookDelivery `json:"data"`
+}
+
+// WebhookPayload the body of a callback, the callbacks are delivered at least once and can be deduplicated by the
+// delivery id in the X-Titan-Delivery header
+type WebhookPayload struct {
+	WebhookID int64        `json:"webhook_id"`
+	Event     WebhookEvent `json:"event"`
+	Hash      string       `json:"hash"`
+	CID       string       `json:"cid,omitempty"`
+	// NodeID the node the replica is lost on
+	NodeID string    `json:"node_id,omitempty"`
+	Time   time.Time `json:"time"`
+}
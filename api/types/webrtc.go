@@ -0,0 +1,56 @@
+package types
+
+import "time"
+
+// WebRTCICEServer a stun or turn server of the ice negotiation, in the shape of RTCIceServer of the browsers
+type WebRTCICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// WebRTCConfig the ice servers the browsers create their peer connections with
+type WebRTCConfig struct {
+	ICEServers []*WebRTCICEServer
+}
+
+// WebRTCOfferReq the offer of a browser to retrieve the blocks of an asset over a data channel,
+// the sdp carries the gathered ice candidates of the browser
+type WebRTCOfferReq struct {
+	AssetCID string
+	SDP      string
+}
+
+// WebRTCOffer the offer of a browser relayed by the scheduler to an edge holding the asset,
+// the blocks of the other assets are not served in the session
+type WebRTCOffer struct {
+	SessionID  string
+	AssetCID   string
+	SDP        string
+	ICEServers []*WebRTCICEServer
+	// the offer is refused after the expiration
+	Expiration time.Time
+}
+
+// WebRTCAnswer the answer of the edge to the offer, the sdp carries the gathered ice candidates of the edge
+type WebRTCAnswer struct {
+	SessionID string
+	NodeID    string
+	SDP       string
+}
+
+// WebRTCBlockReq a request of the browser on the data channel, sent as a json text message,
+// the edge answers with binary frames of the block: the 4 bytes big endian id of the request, 1 byte of flags
+// and the payload, the last frame of the block has WebRTCFrameLast set, a failed request is answered by one frame
+// with WebRTCFrameError set and the error as the payload
+type WebRTCBlockReq struct {
+	ID  uint32 `json:"id"`
+	CID string `json:"cid"`
+}
+
+const (
+	// WebRTCFrameLast the frame is the last of the block
+	WebRTCFrameLast byte = 1 << iota
+	// WebRTCFrameError the payload of the frame is the error of the request
+	WebRTCFrameError
+)
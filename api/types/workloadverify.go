@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkloadVerifyStatus the result of the cross-check of a workload receipt against the workload reported by the node
+type WorkloadVerifyStatus string
+
+const (
+	// WorkloadVerifyPending the receipt is sampled and waits for the workload record of its token to be processed
+	WorkloadVerifyPending WorkloadVerifyStatus = "pending"
+	// WorkloadVerifyMatched the node reported the bytes of the receipt within the tolerance
+	WorkloadVerifyMatched WorkloadVerifyStatus = "matched"
+	// WorkloadVerifyOverreported the node reported more bytes than the downloader received beyond the tolerance
+	WorkloadVerifyOverreported WorkloadVerifyStatus = "overreported"
+	// WorkloadVerifyUnderreported the node reported fewer bytes than the downloader received beyond the tolerance
+	WorkloadVerifyUnderreported WorkloadVerifyStatus = "underreported"
+	// WorkloadVerifyUnreported the node reported no workload for the token
+	WorkloadVerifyUnreported WorkloadVerifyStatus = "unreported"
+)
+
+// ClientIDPrefix the prefix of the ids of the download clients, derived from their public keys like the node ids
+const ClientIDPrefix = "d_"
+
+// WorkloadReceipt the bytes a downloader received from a node with a token, signed by the downloader.
+// The scheduler cross-checks a sample of the receipts against the workloads reported by the nodes
+type WorkloadReceipt struct {
+	TokenID string `db:"token_id"`
+	// NodeID the node served the bytes
+	NodeID string `db:"node_id"`
+	// ClientID the downloader signed the receipt
+	ClientID     string    `db:"client_id"`
+	DownloadSize int64     `db:"download_size"`
+	StartTime    time.Time `db:"start_time"`
+	EndTime      time.Time `db:"end_time"`
+	// Sign signs the content of the receipt by the private key of the downloader
+	Sign []byte `db:"sign"`
+	// ClientKey the pem public key of a download client, the ClientID is derived from it. Empty if the downloader is a node
+	ClientKey string `db:"client_key"`
+
+	// the result of the verification, set by the scheduler
+	Status WorkloadVerifyStatus `db:"status"`
+	// ReportedSize the bytes the node reported for the token
+	ReportedSize int64     `db:"reported_size"`
+	CreatedTime  time.Time `db:"created_time"`
+}
+
+// SignContent returns the content of the receipt signed by the downloader, the times are signed in seconds
+func (r *WorkloadReceipt) SignContent() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%d\n%d\n%d", r.TokenID, r.NodeID, r.ClientID, r.DownloadSize, r.StartTime.Unix(), r.EndTime.Unix()))
+}
+
+// ListWorkloadReceiptRsp the sampled workload receipts of a node
+type ListWorkloadReceiptRsp struct {
+	Total    int                `json:"total"`
+	Receipts []*WorkloadReceipt `json:"receipts"`
+}
+
+// WorkloadVerificationStats the sampled workload receipts of a node, or of all the nodes, in a period by their verification result
+type WorkloadVerificationStats struct {
+	NodeID        string    `db:"-"`
+	Start         time.Time `db:"-"`
+	End           time.Time `db:"-"`
+	Sampled       int       `db:"sampled"`
+	Pending       int       `db:"pending"`
+	Matched       int       `db:"matched"`
+	Overreported  int       `db:"overreported"`
+	Underreported int       `db:"underreported"`
+	Unreported    int       `db:"unreported"`
+	// ReceiptBytes the bytes received by the downloaders of the verified receipts
+	ReceiptBytes int64 `db:"receipt_bytes"`
+	// ReportedBytes the bytes reported by the nodes of the verified receipts
+	ReportedBytes int64 `db:"reported_bytes"`
+}
@@ -33,12 +33,20 @@ var assetCmds = &cli.Command{
 		removeAssetRecordCmd,
 		stopAssetRecordCmd,
 		removeAssetReplicaCmd,
+		markAssetCacheableCmd,
+		seedingProgressCmd,
+		replicaProgressCmd,
 		resetExpirationCmd,
+		setQoSTierCmd,
+		setVideoFormatCmd,
+		segmentStatsCmd,
+		locationIndexStatsCmd,
 		restartAssetCmd,
 		addAWSDataCmd,
 		switchFillDiskTimerCmd,
 		listAWSDataCmd,
 		assetViewCmd,
+		coldStorageCmds,
 	},
 }
 
@@ -169,6 +177,30 @@ var resetExpirationCmd = &cli.Command{
 	},
 }
 
+var setQoSTierCmd = &cli.Command{
+	Name:  "set-qos-tier",
+	Usage: "Set the qos tier of the asset record, the replicas placed from then on follow the tier",
+	Flags: []cli.Flag{
+		cidFlag,
+		qosTierFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return xerrors.New("cid is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.UpdateAssetQoSTier(ctx, cid, types.AssetQoSTier(cctx.String("qos-tier")))
+	},
+}
+
 var stopAssetRecordCmd = &cli.Command{
 	Name:  "stop",
 	Usage: "stop the asset record",
@@ -237,6 +269,121 @@ var removeAssetReplicaCmd = &cli.Command{
 	},
 }
 
+var markAssetCacheableCmd = &cli.Command{
+	Name:  "cacheable",
+	Usage: "Mark the replicas of the asset on the edges as cacheable or pinned",
+	Flags: []cli.Flag{
+		cidFlag,
+		&cli.BoolFlag{
+			Name:  "pin",
+			Usage: "pin the replicas instead",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.MarkAssetCacheable(ctx, cid, !cctx.Bool("pin"))
+	},
+}
+
+var seedingProgressCmd = &cli.Command{
+	Name:  "seeding",
+	Usage: "Show the progress of the edges pulling the asset from the candidates in waves",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		p, err := schedulerAPI.GetSeedingProgress(ctx, cid)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("State: %s\n", p.State)
+		fmt.Printf("Edge replicas: %d/%d\n", p.EdgeReplicas, p.NeedEdgeReplicas)
+		if p.Wave == 0 && p.Backoffs == 0 {
+			fmt.Println("The asset is not pulled in waves")
+			return nil
+		}
+
+		fmt.Printf("Wave: %d, size: %d, pulled: %d, done: %v\n", p.Wave, p.WaveSize, p.WaveSucceeds, p.WaveDone)
+		fmt.Printf("Started: %s\n", p.StartTime.Format(defaultDateTimeLayout))
+		fmt.Printf("Backoffs: %d\n", p.Backoffs)
+		for nodeID, slots := range p.Sources {
+			fmt.Printf("Candidate %s: %d slots\n", nodeID, slots)
+		}
+		return nil
+	},
+}
+
+var replicaProgressCmd = &cli.Command{
+	Name:  "replica-progress",
+	Usage: "Show the replicas of the asset, the partial replicas are counted by the share of the asset they hold",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		p, err := schedulerAPI.GetReplicaProgress(ctx, cid)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("State: %s\n", p.State)
+		fmt.Printf("Candidate replicas: %d (effective %.2f)/%d\n", p.CandidateReplicas, p.EffectiveCandidateReplicas, p.NeedCandidateReplicas)
+		fmt.Printf("Edge replicas: %d (effective %.2f)/%d\n", p.EdgeReplicas, p.EffectiveEdgeReplicas, p.NeedEdgeReplicas)
+
+		tw := tablewriter.New(
+			tablewriter.Col("NodeID"),
+			tablewriter.Col("Type"),
+			tablewriter.Col("Done"),
+			tablewriter.Col("Online"),
+		)
+
+		for _, partial := range p.Partials {
+			nodeType := "edge"
+			if partial.IsCandidate {
+				nodeType = "candidate"
+			}
+
+			tw.Write(map[string]interface{}{
+				"NodeID": partial.NodeID,
+				"Type":   nodeType,
+				"Done":   fmt.Sprintf("%s/%s", units.BytesSize(float64(partial.DoneSize)), units.BytesSize(float64(p.TotalSize))),
+				"Online": partial.Online,
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
 var showAssetInfoCmd = &cli.Command{
 	Name:  "info",
 	Usage: "Show the asset record info",
@@ -265,6 +412,11 @@ var showAssetInfoCmd = &cli.Command{
 		fmt.Printf("Size:\t%s\n", units.BytesSize(float64(info.TotalSize)))
 		fmt.Printf("NeedEdgeReplica:\t%d\n", info.NeedEdgeReplica)
 		fmt.Printf("Expiration:\t%v\n", info.Expiration.Format(defaultDateTimeLayout))
+		fmt.Printf("Owners:\t%d\n", info.OwnerCount)
+		fmt.Printf("QoSTier:\t%s\n", info.QoSTier.OrDefault())
+		if info.VideoFormat != types.AssetVideoFormatNone {
+			fmt.Printf("VideoFormat:\t%s\n", info.VideoFormat)
+		}
 
 		fmt.Printf("--------\nProcesses:\n")
 		succeed := 0
@@ -328,6 +480,8 @@ var pullAssetCmd = &cli.Command{
 		replicaCountFlag,
 		expirationDateFlag,
 		bandwidthFlag,
+		qosTierFlag,
+		videoFormatFlag,
 	},
 	Action: func(cctx *cli.Context) error {
 		cid := cctx.String("cid")
@@ -360,6 +514,8 @@ var pullAssetCmd = &cli.Command{
 		info.Expiration = eTime
 		info.Replicas = replicaCount
 		info.Bandwidth = bandwidth
+		info.QoSTier = types.AssetQoSTier(cctx.String("qos-tier"))
+		info.VideoFormat = types.AssetVideoFormat(cctx.String("video-format"))
 
 		err = schedulerAPI.PullAsset(ctx, info)
 		if err != nil {
@@ -650,3 +806,28 @@ var assetViewCmd = &cli.Command{
 		return nil
 	},
 }
+
+var locationIndexStatsCmd = &cli.Command{
+	Name:  "location-index-stats",
+	Usage: "Show the asset location lookups served by the location index of the retrieval routing",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		stats, err := schedulerAPI.GetLocationIndexStats(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Entries: %d\n", stats.Entries)
+		fmt.Printf("Hits: %d\n", stats.Hits)
+		fmt.Printf("Misses: %d\n", stats.Misses)
+		fmt.Printf("Loads: %d\n", stats.Loads)
+		fmt.Printf("Hit ratio: %.2f%%\n", stats.HitRatio*100)
+		return nil
+	},
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var auditCmds = &cli.Command{
+	Name:  "audit",
+	Usage: "Query the audit log of admin mutations",
+	Subcommands: []*cli.Command{
+		listAuditLogs,
+	},
+}
+
+var listAuditLogs = &cli.Command{
+	Name:  "list",
+	Usage: "list audit logs, the latest first",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "actor",
+			Usage: "id of the token that made the mutations",
+		},
+		&cli.StringFlag{
+			Name:  "method",
+			Usage: "api method of the mutations",
+		},
+		&cli.StringFlag{
+			Name:  "start",
+			Usage: "start time, format: 2006-01-02 15:04:05",
+		},
+		&cli.StringFlag{
+			Name:  "end",
+			Usage: "end time, format: 2006-01-02 15:04:05",
+		},
+		limitFlag,
+		offsetFlag,
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		req := &types.ListAuditLogsReq{
+			Actor:  cctx.String("actor"),
+			Method: cctx.String("method"),
+			Limit:  cctx.Int("limit"),
+			Offset: cctx.Int("offset"),
+		}
+
+		if start := cctx.String("start"); start != "" {
+			if req.Start, err = time.ParseInLocation(defaultDateTimeLayout, start, time.Local); err != nil {
+				return err
+			}
+		}
+
+		if end := cctx.String("end"); end != "" {
+			if req.End, err = time.ParseInLocation(defaultDateTimeLayout, end, time.Local); err != nil {
+				return err
+			}
+		}
+
+		ctx := ReqContext(cctx)
+		rsp, err := schedulerAPI.GetAuditLogs(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("Actor"),
+			tablewriter.Col("Role"),
+			tablewriter.Col("Method"),
+			tablewriter.Col("Args"),
+			tablewriter.Col("Result"),
+		)
+
+		for _, l := range rsp.Data {
+			result := "ok"
+			if !l.Success {
+				result = l.Result
+			}
+
+			tw.Write(map[string]interface{}{
+				"Time":   l.CreatedTime.Format(defaultDateTimeLayout),
+				"Actor":  l.Actor,
+				"Role":   l.Role,
+				"Method": l.Method,
+				"Args":   l.Args,
+				"Result": result,
+			})
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("Total:%d\n", rsp.Total)
+		return nil
+	},
+}
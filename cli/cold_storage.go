@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/docker/go-units"
+	"github.com/urfave/cli/v2"
+)
+
+var coldStorageCmds = &cli.Command{
+	Name:  "cold",
+	Usage: "Manage the assets offloaded into the filecoin storage deals",
+	Subcommands: []*cli.Command{
+		offloadAssetCmd,
+		restoreAssetCmd,
+		listColdAssetsCmd,
+	},
+}
+
+var offloadAssetCmd = &cli.Command{
+	Name:  "offload",
+	Usage: "propose the storage deal of the asset now, or remove its hot replicas if its deal is active",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.OffloadAsset(ReqContext(cctx), cctx.String("cid"))
+	},
+}
+
+var restoreAssetCmd = &cli.Command{
+	Name:  "restore",
+	Usage: "pull the archived asset back from its storage deal",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RestoreAsset(ReqContext(cctx), cctx.String("cid"))
+	},
+}
+
+var listColdAssetsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the assets offloaded into the storage deals and the states of their deals",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "state",
+			Usage: "proposed, active, archived, restoring or failed, all the states if not set",
+		},
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.ListColdAssets(ReqContext(cctx), types.ColdAssetState(cctx.String("state")), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("CID"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("State"),
+			tablewriter.Col("DealID"),
+			tablewriter.Col("Provider"),
+			tablewriter.Col("ChainDealID"),
+			tablewriter.Col("UpdatedTime"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, asset := range rsp.Data {
+			tw.Write(map[string]interface{}{
+				"CID":         asset.CID,
+				"Size":        units.BytesSize(float64(asset.Size)),
+				"State":       asset.State,
+				"DealID":      asset.DealID,
+				"Provider":    asset.Provider,
+				"ChainDealID": asset.ChainDealID,
+				"UpdatedTime": asset.UpdatedTime.Format(defaultDateTimeLayout),
+				"Message":     asset.Message,
+			})
+		}
+
+		fmt.Printf("total %d\n", rsp.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
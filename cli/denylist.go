@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var denylistCmds = &cli.Command{
+	Name:  "denylist",
+	Usage: "Manage the banned assets",
+	Subcommands: []*cli.Command{
+		addDenylistCmd,
+		removeDenylistCmd,
+		importDenylistCmd,
+		listDenylistCmd,
+		listDenylistEventsCmd,
+	},
+}
+
+var reasonFlag = &cli.StringFlag{
+	Name:  "reason",
+	Usage: "the reason of the ban",
+}
+
+var addDenylistCmd = &cli.Command{
+	Name:      "add",
+	Usage:     "Ban the assets, the replicas are purged from the nodes",
+	ArgsUsage: "<cid> [cid...]",
+	Flags: []cli.Flag{
+		reasonFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() == 0 {
+			return xerrors.New("cid is empty")
+		}
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.AddToDenylist(ctx, cctx.Args().Slice(), cctx.String("reason"))
+	},
+}
+
+var removeDenylistCmd = &cli.Command{
+	Name:  "remove",
+	Usage: "Lift the ban of the asset",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveFromDenylist(ctx, cctx.String("cid"))
+	},
+}
+
+var importDenylistCmd = &cli.Command{
+	Name:  "import",
+	Usage: "Ban the assets listed by a denylist file, in the compact denylist or one cid per line format",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "file",
+			Usage:    "path of the denylist file",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "source",
+			Usage:    "name of the denylist",
+			Required: true,
+		},
+		reasonFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		content, err := os.ReadFile(cctx.String("file"))
+		if err != nil {
+			return err
+		}
+
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		result, err := schedulerAPI.ImportDenylist(ctx, cctx.String("source"), cctx.String("reason"), string(content))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Added: %d, skipped: %d, invalid: %d\n", result.Added, result.Skipped, len(result.Invalid))
+		for _, line := range result.Invalid {
+			fmt.Printf("Invalid: %s\n", line)
+		}
+		return nil
+	},
+}
+
+var listDenylistCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the banned assets, the latest first",
+	Flags: []cli.Flag{
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.ListDenylist(ctx, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("CID"),
+			tablewriter.Col("Source"),
+			tablewriter.Col("Reason"),
+			tablewriter.Col("Actor"),
+			tablewriter.Col("Time"),
+		)
+
+		for _, entry := range rsp.Entries {
+			tw.Write(map[string]interface{}{
+				"CID":    entry.CID,
+				"Source": entry.Source,
+				"Reason": entry.Reason,
+				"Actor":  entry.Actor,
+				"Time":   entry.CreatedTime.Format(defaultDateTimeLayout),
+			})
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("Total:%d\n", rsp.Total)
+		return nil
+	},
+}
+
+var listDenylistEventsCmd = &cli.Command{
+	Name:  "events",
+	Usage: "List the audit trail of the denylist, the latest first",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "cid",
+			Usage: "only the events of the asset",
+		},
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.ListDenylistEvents(ctx, cctx.String("cid"), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("CID"),
+			tablewriter.Col("Event"),
+			tablewriter.Col("Detail"),
+			tablewriter.Col("Actor"),
+		)
+
+		for _, event := range rsp.Events {
+			tw.Write(map[string]interface{}{
+				"Time":   event.CreatedTime.Format(defaultDateTimeLayout),
+				"CID":    event.CID,
+				"Event":  event.Event,
+				"Detail": event.Detail,
+				"Actor":  event.Actor,
+			})
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("Total:%d\n", rsp.Total)
+		return nil
+	},
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var nodeDiagnosticsCmds = &cli.Command{
+	Name:  "diagnostics",
+	Usage: "Collect the diagnostics bundles of the nodes",
+	Subcommands: []*cli.Command{
+		requestNodeDiagnosticsCmd,
+		nodeDiagnosticsStatusCmd,
+		listNodeDiagnosticsCmd,
+		downloadNodeDiagnosticsCmd,
+	},
+}
+
+var requestNodeDiagnosticsCmd = &cli.Command{
+	Name:  "request",
+	Usage: "Ask the node to collect a diagnostics bundle",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		&cli.StringFlag{
+			Name:  "upload-url",
+			Usage: "the presigned url the node puts the bundle to, the bundle is kept by the scheduler if empty",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		id, err := schedulerAPI.RequestNodeDiagnostics(ctx, nodeID, cctx.String("upload-url"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var nodeDiagnosticsStatusCmd = &cli.Command{
+	Name:      "status",
+	Usage:     "Show the status of the diagnostics bundle",
+	ArgsUsage: "<id>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("diagnostics id is required")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		diag, err := schedulerAPI.GetNodeDiagnostics(ctx, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ID: %s\n", diag.ID)
+		fmt.Printf("Node: %s\n", diag.NodeID)
+		fmt.Printf("Status: %s\n", diag.Status)
+		fmt.Printf("Size: %d\n", diag.Size)
+		if diag.Location != "" {
+			fmt.Printf("Location: %s\n", diag.Location)
+		}
+		if diag.Message != "" {
+			fmt.Printf("Message: %s\n", diag.Message)
+		}
+		fmt.Printf("Created: %s\n", diag.CreatedTime.Format(defaultDateTimeLayout))
+		return nil
+	},
+}
+
+var listNodeDiagnosticsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the diagnostics bundles of the node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListNodeDiagnostics(ctx, cctx.String("node-id"), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Created"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, diag := range list.Diagnostics {
+			m := map[string]interface{}{
+				"ID":      diag.ID,
+				"Status":  diag.Status,
+				"Size":    diag.Size,
+				"Created": diag.CreatedTime.Format(defaultDateTimeLayout),
+			}
+			if diag.Message != "" {
+				m["Message"] = diag.Message
+			}
+			tw.Write(m)
+		}
+
+		fmt.Printf("Total: %d\n", list.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var downloadNodeDiagnosticsCmd = &cli.Command{
+	Name:      "download",
+	Usage:     "Download the diagnostics bundle kept by the scheduler",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "the file the gzipped tar is written to, default <id>.tar.gz",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("diagnostics id is required")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		id := cctx.Args().First()
+		data, err := schedulerAPI.GetNodeDiagnosticsData(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		output := cctx.String("output")
+		if output == "" {
+			output = id + ".tar.gz"
+		}
+
+		return os.WriteFile(output, data, 0o644)
+	},
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto"
-	"crypto/rsa"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -23,11 +22,10 @@ import (
 	"github.com/Filecoin-Titan/titan/api/client"
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	"github.com/Filecoin-Titan/titan/node/repo"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/docker/go-units"
 	"github.com/filecoin-project/go-jsonrpc"
-	"github.com/google/uuid"
 	"github.com/mitchellh/go-homedir"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
@@ -44,6 +42,38 @@ var EdgeCmds = []*cli.Command{
 	signCmd,
 	bindCmd,
 	syncDataCmd,
+	bandwidthTestCmd,
+}
+
+var bandwidthTestCmd = &cli.Command{
+	Name:  "bandwidth-test",
+	Usage: "Test the bandwidth and latency of the node against the nearest candidates, the result updates the bandwidth of the node",
+	Action: func(cctx *cli.Context) error {
+		edgeAPI, closer, err := getEdgeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		fmt.Println("Testing, this takes about a minute...")
+		test, err := edgeAPI.StartBandwidthTest(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("status: %s\n", test.Status)
+		if test.Status != types.BandwidthTestSucceeded {
+			fmt.Printf("message: %s\n", test.Message)
+			return nil
+		}
+
+		fmt.Printf("upload bandwidth: %s/s\n", units.BytesSize(float64(test.BandwidthUp)))
+		fmt.Printf("download bandwidth: %s/s\n", units.BytesSize(float64(test.BandwidthDown)))
+		fmt.Printf("latency: %d ms\n", test.Latency)
+		return nil
+	},
 }
 
 var showCmds = &cli.Command{
@@ -247,23 +277,29 @@ var keyCmds = &cli.Command{
 		showKey,
 		importKey,
 		exportKey,
+		migrateKey,
 	},
 }
 
 var generateRsaKey = &cli.Command{
 	Name:  "generate",
-	Usage: "Generate rsa key",
+	Usage: "Generate rsa or ed25519 key",
 	Flags: []cli.Flag{
 		&cli.IntFlag{
 			Name:  "bits",
 			Usage: "rsa bit: 1024,2048,4096",
 			Value: 1024,
 		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "key type: rsa or ed25519",
+			Value: nodekey.TypeRSA,
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		bits := cctx.Int("bits")
 
-		if bits < 1024 {
+		if cctx.String("type") == nodekey.TypeRSA && bits < 1024 {
 			return fmt.Errorf("rsa bits is 1024,2048,4096")
 		}
 
@@ -273,12 +309,16 @@ var generateRsaKey = &cli.Command{
 		}
 		defer lr.Close() //nolint:errcheck  // ignore error
 
-		privateKey, err := titanrsa.GeneratePrivateKey(bits)
+		privateKey, err := nodekey.Generate(cctx.String("type"), bits)
+		if err != nil {
+			return err
+		}
+
+		privatePem, err := nodekey.PrivateKey2Pem(privateKey)
 		if err != nil {
 			return err
 		}
 
-		privatePem := titanrsa.PrivateKey2Pem(privateKey)
 		if err := lr.SetPrivateKey(privatePem); err != nil {
 			return err
 		}
@@ -286,6 +326,102 @@ var generateRsaKey = &cli.Command{
 	},
 }
 
+var migrateKey = &cli.Command{
+	Name:  "migrate",
+	Usage: "Migrate the identity key of the registered node to a new key, the node must be stopped",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "key type: rsa or ed25519",
+			Value: nodekey.TypeEd25519,
+		},
+		&cli.IntFlag{
+			Name:  "bits",
+			Usage: "rsa bit: 1024,2048,4096",
+			Value: 1024,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		r, lr, err := openRepoAndLock(cctx)
+		if err != nil {
+			return err
+		}
+		defer lr.Close() //nolint:errcheck  // ignore error
+
+		nodeID, err := r.NodeID()
+		if err != nil {
+			return err
+		}
+
+		privatePem, err := r.PrivateKey()
+		if err != nil {
+			return err
+		}
+
+		privateKey, err := nodekey.Pem2PrivateKey(privatePem)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := lr.Config()
+		if err != nil {
+			return err
+		}
+
+		edgeCfg, ok := cfg.(*config.EdgeCfg)
+		if !ok {
+			candidateCfg, ok := cfg.(*config.CandidateCfg)
+			if !ok {
+				return fmt.Errorf("invalid config type %T", cfg)
+			}
+			edgeCfg = &candidateCfg.EdgeCfg
+		}
+
+		newKey, err := nodekey.Generate(cctx.String("type"), cctx.Int("bits"))
+		if err != nil {
+			return err
+		}
+
+		publicPem, err := nodekey.PublicKey2Pem(newKey.Public())
+		if err != nil {
+			return err
+		}
+
+		newPrivatePem, err := nodekey.PrivateKey2Pem(newKey)
+		if err != nil {
+			return err
+		}
+
+		// proves the ownership of the registered key
+		sign, err := nodekey.Sign(privateKey, publicPem)
+		if err != nil {
+			return err
+		}
+
+		schedulerURL, err := getUserAccessPoint(cctx, client.NewHTTP3Client(), edgeCfg.Network.LocatorURL)
+		if err != nil {
+			return err
+		}
+
+		schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, nil, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if _, err := schedulerAPI.MigrateNodeKey(cctx.Context, string(nodeID), string(publicPem), hex.EncodeToString(sign)); err != nil {
+			return err
+		}
+
+		if err := lr.SetPrivateKey(newPrivatePem); err != nil {
+			// the scheduler already accepted the new key, keep it to import later
+			fmt.Println(string(newPrivatePem))
+			return xerrors.Errorf("save the new key error %w, import the key above with 'key import'", err)
+		}
+		return nil
+	},
+}
+
 var showKey = &cli.Command{
 	Name:  "show",
 	Usage: "Show key",
@@ -335,7 +471,11 @@ var importKey = &cli.Command{
 			return err
 		}
 
-		privatePem := titanrsa.PrivateKey2Pem(privateKey)
+		privatePem, err := nodekey.PrivateKey2Pem(privateKey)
+		if err != nil {
+			return err
+		}
+
 		if err := lr.SetPrivateKey(privatePem); err != nil {
 			return err
 		}
@@ -391,12 +531,15 @@ var exportKey = &cli.Command{
 			return nil
 		}
 
-		privateKey, err := titanrsa.Pem2PrivateKey(privatePem)
+		privateKey, err := nodekey.Pem2PrivateKey(privatePem)
 		if err != nil {
 			return err
 		}
 
-		publicPem := titanrsa.PublicKey2Pem(&privateKey.PublicKey)
+		publicPem, err := nodekey.PublicKey2Pem(privateKey.Public())
+		if err != nil {
+			return err
+		}
 
 		return os.WriteFile(pkPath, publicPem, 0o600)
 	},
@@ -478,13 +621,13 @@ func getRepoType(cctx *cli.Context) (repo.RepoType, error) {
 	return t, nil
 
 }
-func readPrivateKey(path string) (*rsa.PrivateKey, error) {
+func readPrivateKey(path string) (crypto.Signer, error) {
 	pem, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return titanrsa.Pem2PrivateKey(pem)
+	return nodekey.Pem2PrivateKey(pem)
 }
 
 func getEdgeAPI(ctx *cli.Context) (api.Edge, jsonrpc.ClientCloser, error) {
@@ -550,7 +693,7 @@ var setConfigCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:  "listen-address",
 			Usage: "set local listen address, example: --listen-address=local_server_ip:port",
-			Value: "0.0.0.0:1234",
+			Value: "[::]:1234",
 		},
 		&cli.StringFlag{
 			Name:  "storage-path",
@@ -749,32 +892,55 @@ func RegitsterNode(cctx *cli.Context, lr repo.LockedRepo, locatorURL string, nod
 		bits = 1024
 	}
 
-	privateKey, err := titanrsa.GeneratePrivateKey(bits)
+	keyType := cctx.String("key-type")
+	if keyType == "" {
+		keyType = negotiateKeyType(schedulerAPI)
+	}
+
+	privateKey, err := nodekey.Generate(keyType, bits)
 	if err != nil {
 		return err
 	}
 
-	pem := titanrsa.PublicKey2Pem(&privateKey.PublicKey)
-	var nodeID string
+	pem, err := nodekey.PublicKey2Pem(privateKey.Public())
+	if err != nil {
+		return err
+	}
+	var prefix string
 	if nodeType == types.NodeEdge {
-		nodeID = fmt.Sprintf("e_%s", uuid.NewString())
+		prefix = "e_"
 	} else if nodeType == types.NodeCandidate {
-		nodeID = fmt.Sprintf("c_%s", uuid.NewString())
+		prefix = "c_"
 	} else {
 		return fmt.Errorf("invalid node type %s", nodeType.String())
 	}
 
-	info, err := schedulerAPI.RegisterNode(context.Background(), nodeID, string(pem), nodeType)
+	// the node id is derived from the key, registering again never leaves a ghost node
+	nodeID, err := nodekey.NodeID(prefix, privateKey.Public())
+	if err != nil {
+		return err
+	}
+
+	register := schedulerAPI.RegisterNode
+	if cctx.Bool("sandbox") {
+		register = schedulerAPI.RegisterSandboxNode
+	}
+
+	info, err := register(context.Background(), nodeID, string(pem), nodeType)
+	if err != nil {
+		return err
+	}
+
+	privatePem, err := nodekey.PrivateKey2Pem(privateKey)
 	if err != nil {
 		return err
 	}
 
-	privatePem := titanrsa.PrivateKey2Pem(privateKey)
 	if err := lr.SetPrivateKey(privatePem); err != nil {
 		return err
 	}
 
-	if err := lr.SetNodeID([]byte(nodeID)); err != nil {
+	if err := lr.SetNodeID([]byte(info.NodeID)); err != nil {
 		return err
 	}
 
@@ -852,13 +1018,12 @@ var signCmd = &cli.Command{
 			return err
 		}
 
-		privateKey, err := titanrsa.Pem2PrivateKey(privateKeyPem)
+		privateKey, err := nodekey.Pem2PrivateKey(privateKeyPem)
 		if err != nil {
 			return err
 		}
 
-		titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-		sign, err := titanRsa.Sign(privateKey, []byte(hash))
+		sign, err := nodekey.Sign(privateKey, []byte(hash))
 		if err != nil {
 			return err
 		}
@@ -900,13 +1065,12 @@ var bindCmd = &cli.Command{
 			return err
 		}
 
-		privateKey, err := titanrsa.Pem2PrivateKey(privateKeyPem)
+		privateKey, err := nodekey.Pem2PrivateKey(privateKeyPem)
 		if err != nil {
 			return err
 		}
 
-		titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-		sign, err := titanRsa.Sign(privateKey, []byte(hash))
+		sign, err := nodekey.Sign(privateKey, []byte(hash))
 		if err != nil {
 			return err
 		}
@@ -986,6 +1150,26 @@ var bindCmd = &cli.Command{
 }
 
 // getNodeAccessPoint get scheduler url by user ip
+// negotiateKeyType returns the first key type accepted by the scheduler which is supported by the node,
+// schedulers without ed25519 support only accept rsa
+func negotiateKeyType(schedulerAPI api.Scheduler) string {
+	accepted, err := schedulerAPI.GetNodeKeyTypes(context.Background())
+	if err != nil {
+		log.Warnf("GetNodeKeyTypes error %s, use rsa key", err.Error())
+		return nodekey.TypeRSA
+	}
+
+	for _, t := range accepted {
+		for _, supported := range nodekey.Types {
+			if t == supported {
+				return t
+			}
+		}
+	}
+
+	return nodekey.TypeRSA
+}
+
 func getUserAccessPoint(cctx *cli.Context, httpClient *http.Client, locatorURL string) (string, error) {
 	locator, close, err := client.NewLocator(cctx.Context, locatorURL, nil, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var featureFlagCmds = &cli.Command{
+	Name:  "feature-flag",
+	Usage: "Manage the feature flags rolled out to the schedulers and the nodes",
+	Subcommands: []*cli.Command{
+		setFeatureFlagCmd,
+		removeFeatureFlagCmd,
+		listFeatureFlagsCmd,
+	},
+}
+
+var setFeatureFlagCmd = &cli.Command{
+	Name:      "set",
+	Usage:     "Create or update the feature flag",
+	ArgsUsage: "<name>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "enable",
+			Usage: "enable the flag, the flag is off everywhere if not enabled",
+		},
+		&cli.IntFlag{
+			Name:  "percentage",
+			Usage: "percent of the nodes of the target types the feature is enabled on",
+			Value: 100,
+		},
+		&cli.StringFlag{
+			Name:  "node-types",
+			Usage: "comma separated types of the nodes the flag targets, 1:Edge 2:Candidate 4:Scheduler, all types if empty",
+		},
+		&cli.StringFlag{
+			Name:  "description",
+			Usage: "description of the feature",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("flag name is required")
+		}
+
+		flag := &types.FeatureFlag{
+			Name:        cctx.Args().First(),
+			Description: cctx.String("description"),
+			Enabled:     cctx.Bool("enable"),
+			Percentage:  cctx.Int("percentage"),
+		}
+
+		for _, s := range strings.Split(cctx.String("node-types"), ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+
+			t, err := strconv.Atoi(s)
+			if err != nil {
+				return xerrors.Errorf("invalid node type %s", s)
+			}
+			flag.NodeTypes = append(flag.NodeTypes, types.NodeType(t))
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetFeatureFlag(ctx, flag)
+	},
+}
+
+var removeFeatureFlagCmd = &cli.Command{
+	Name:      "remove",
+	Usage:     "Remove the feature flag",
+	ArgsUsage: "<name>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("flag name is required")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveFeatureFlag(ctx, cctx.Args().First())
+	},
+}
+
+var listFeatureFlagsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the feature flags",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		flags, err := schedulerAPI.ListFeatureFlags(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Name"),
+			tablewriter.Col("Enabled"),
+			tablewriter.Col("Percentage"),
+			tablewriter.Col("NodeTypes"),
+			tablewriter.Col("Updated"),
+			tablewriter.NewLineCol("Description"),
+		)
+
+		for _, flag := range flags {
+			nodeTypes := make([]string, 0, len(flag.NodeTypes))
+			for _, t := range flag.NodeTypes {
+				nodeTypes = append(nodeTypes, t.String())
+			}
+			if len(nodeTypes) == 0 {
+				nodeTypes = append(nodeTypes, "all")
+			}
+
+			m := map[string]interface{}{
+				"Name":       flag.Name,
+				"Enabled":    flag.Enabled,
+				"Percentage": fmt.Sprintf("%d%%", flag.Percentage),
+				"NodeTypes":  strings.Join(nodeTypes, ","),
+				"Updated":    flag.UpdatedTime.Format(defaultDateTimeLayout),
+			}
+			if flag.Description != "" {
+				m["Description"] = flag.Description
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var gatewayCmds = &cli.Command{
+	Name:  "gateway",
+	Usage: "Manage the gateway hostnames routed by dns",
+	Subcommands: []*cli.Command{
+		addGatewayAssetCmd,
+		removeGatewayAssetCmd,
+		listGatewayHostsCmd,
+	},
+}
+
+var addGatewayAssetCmd = &cli.Command{
+	Name:  "add",
+	Usage: "Give the asset its own gateway hostname",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.AddGatewayAsset(ctx, cctx.String("cid"))
+	},
+}
+
+var removeGatewayAssetCmd = &cli.Command{
+	Name:  "remove",
+	Usage: "Remove the gateway hostname of the asset",
+	Flags: []cli.Flag{
+		cidFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveGatewayAsset(ctx, cctx.String("cid"))
+	},
+}
+
+var listGatewayHostsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the gateway hostnames published by the scheduler",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		hosts, err := schedulerAPI.ListGatewayHosts(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Hostname"),
+			tablewriter.Col("Area"),
+			tablewriter.Col("CID"),
+			tablewriter.Col("IPs"),
+			tablewriter.Col("Updated"),
+			tablewriter.NewLineCol("Error"),
+		)
+
+		for _, host := range hosts {
+			m := map[string]interface{}{
+				"Hostname": host.Hostname,
+				"Area":     host.AreaID,
+				"CID":      host.AssetCID,
+				"IPs":      strings.Join(host.IPs, ","),
+				"Updated":  host.UpdatedTime.Format(defaultDateTimeLayout),
+			}
+			if host.Err != "" {
+				m["Error"] = host.Err
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
@@ -17,6 +17,8 @@ var nodeCmds = &cli.Command{
 	Usage: "Manage node",
 	Subcommands: []*cli.Command{
 		onlineNodeCountCmd,
+		regionStatsCmd,
+		nodeTopCmd,
 		requestActivationCodesCmd,
 		showNodeInfoCmd,
 		nodeQuitCmd,
@@ -29,6 +31,18 @@ var nodeCmds = &cli.Command{
 		listReplicaCmd,
 		nodeCleanReplicasCmd,
 		listValidationResultsCmd,
+		listValidationProofsCmd,
+		verifyValidationProofsCmd,
+		validatorLoadsCmd,
+		setUploadLimitCmd,
+		uploadLimitCmd,
+		setCacheConfigCmd,
+		cacheCompositionCmd,
+		nodeDiagnosticsCmds,
+		nodeConfigCmds,
+		listRetrievalProbesCmd,
+		listSpotChecksCmd,
+		retrievalSLACmd,
 	},
 }
 
@@ -101,12 +115,74 @@ var onlineNodeCountCmd = &cli.Command{
 	},
 }
 
+var regionStatsCmd = &cli.Command{
+	Name:  "region-stats",
+	Usage: "aggregated statistics of the region of the scheduler",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		stats, err := schedulerAPI.GetRegionStats(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Area: %s\n", stats.AreaID)
+		fmt.Printf("Online edges: %d\n", stats.OnlineEdgeCount)
+		fmt.Printf("Online candidates: %d\n", stats.OnlineCandidateCount)
+		fmt.Printf("Bandwidth up: %s/s\n", units.BytesSize(float64(stats.BandwidthUp)))
+		fmt.Printf("Bandwidth down: %s/s\n", units.BytesSize(float64(stats.BandwidthDown)))
+		fmt.Printf("Disk space: %s\n", units.BytesSize(stats.DiskSpace))
+		fmt.Printf("Available disk space: %s\n", units.BytesSize(stats.AvailableDiskSpace))
+		fmt.Printf("Replicas: %d\n", stats.ReplicaCount)
+		fmt.Printf("Traffic served: %s\n", units.BytesSize(float64(stats.TrafficServed)))
+		return nil
+	},
+}
+
 var listNodeCmd = &cli.Command{
 	Name:  "list",
-	Usage: "list node",
+	Usage: "list node, filtered and paginated by cursor if any of the cursor, sorting or filter flags is set",
 	Flags: []cli.Flag{
 		limitFlag,
 		offsetFlag,
+		nodeTypeFlag,
+		&cli.StringFlag{
+			Name:  "cursor",
+			Usage: "the next cursor of the previous page",
+		},
+		&cli.StringFlag{
+			Name:  "sort-by",
+			Usage: "node_id, profit, online_duration, last_seen, first_login_time or disk_space",
+		},
+		&cli.BoolFlag{
+			Name:  "desc",
+			Usage: "sort in descending order",
+		},
+		&cli.IntSliceFlag{
+			Name:  "status",
+			Usage: "node status, can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "region",
+			Usage: "area served by the scheduler, the zone of the nodes",
+		},
+		&cli.StringFlag{
+			Name:  "nat-type",
+			Usage: "nat type of the node",
+		},
+		&cli.IntFlag{
+			Name:  "min-score",
+			Usage: "minimum score of the node",
+		},
+		&cli.IntFlag{
+			Name:  "max-score",
+			Usage: "maximum score of the node",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		ctx := ReqContext(cctx)
@@ -119,9 +195,45 @@ var listNodeCmd = &cli.Command{
 		limit := cctx.Int("limit")
 		offset := cctx.Int("offset")
 
-		r, err := schedulerAPI.GetNodeList(ctx, offset, limit)
-		if err != nil {
-			return err
+		var nodes []types.NodeInfo
+		var footer string
+
+		filtered := false
+		for _, name := range []string{"node-type", "cursor", "sort-by", "desc", "status", "region", "nat-type", "min-score", "max-score"} {
+			filtered = filtered || cctx.IsSet(name)
+		}
+
+		if filtered {
+			req := &types.ListNodesReq{
+				Cursor:   cctx.String("cursor"),
+				Limit:    limit,
+				SortBy:   cctx.String("sort-by"),
+				Desc:     cctx.Bool("desc"),
+				Type:     types.NodeType(cctx.Int("node-type")),
+				Region:   cctx.String("region"),
+				NATType:  cctx.String("nat-type"),
+				MinScore: cctx.Int("min-score"),
+				MaxScore: cctx.Int("max-score"),
+			}
+			for _, status := range cctx.IntSlice("status") {
+				req.Statuses = append(req.Statuses, types.NodeStatus(status))
+			}
+
+			r, err := schedulerAPI.ListNodes(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			nodes = r.Data
+			footer = fmt.Sprintf("\n Next cursor:%s ", r.NextCursor)
+		} else {
+			r, err := schedulerAPI.GetNodeList(ctx, offset, limit)
+			if err != nil {
+				return err
+			}
+
+			nodes = r.Data
+			footer = fmt.Sprintf("\n Total:%d ", r.Total)
 		}
 
 		tw := tablewriter.New(
@@ -132,8 +244,8 @@ var listNodeCmd = &cli.Command{
 			tablewriter.Col("ExternalIP"),
 		)
 
-		for w := 0; w < len(r.Data); w++ {
-			info := r.Data[w]
+		for w := 0; w < len(nodes); w++ {
+			info := nodes[w]
 
 			m := map[string]interface{}{
 				"NodeID":     info.NodeID,
@@ -147,7 +259,7 @@ var listNodeCmd = &cli.Command{
 		}
 		err = tw.Flush(os.Stdout)
 
-		fmt.Printf(color.YellowString("\n Total:%d ", r.Total))
+		fmt.Printf(color.YellowString(footer))
 
 		return err
 	},
@@ -186,6 +298,130 @@ var listValidationResultsCmd = &cli.Command{
 	},
 }
 
+var listValidationProofsCmd = &cli.Command{
+	Name:  "proofs",
+	Usage: "list the validation results of the node signed by the validators and the scheduler",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.GetValidationProofs(ctx, cctx.String("node-id"), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Seq"),
+			tablewriter.Col("Round"),
+			tablewriter.Col("Validator"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Hash"),
+			tablewriter.Col("CreatedTime"),
+		)
+
+		for _, proof := range list.Data {
+			tw.Write(map[string]interface{}{
+				"Seq":         proof.Seq,
+				"Round":       proof.RoundID,
+				"Validator":   proof.ValidatorID,
+				"Status":      proof.Status,
+				"Hash":        proof.Hash,
+				"CreatedTime": proof.CreatedTime.Format(defaultDateTimeLayout),
+			})
+		}
+
+		if err = tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf(color.YellowString("\n Total:%d ", list.Total))
+		return nil
+	},
+}
+
+var verifyValidationProofsCmd = &cli.Command{
+	Name:  "verify-proofs",
+	Usage: "check the chain of the validation proofs of the node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		audit, err := schedulerAPI.VerifyValidationProofs(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+
+		if audit.Valid {
+			fmt.Printf("%d records of node %s are valid\n", audit.Records, nodeID)
+			return nil
+		}
+
+		fmt.Printf("record %d of node %s is invalid: %s\n", audit.BrokenSeq, nodeID, audit.Reason)
+		return nil
+	},
+}
+
+var validatorLoadsCmd = &cli.Command{
+	Name:  "validator-loads",
+	Usage: "list the validations of the validators connected to the scheduler",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		loads, err := schedulerAPI.GetValidatorLoads(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("NodeID"),
+			tablewriter.Col("Capacity"),
+			tablewriter.Col("Running"),
+			tablewriter.Col("Assigned"),
+			tablewriter.Col("Deferred"),
+			tablewriter.Col("Total"),
+		)
+
+		for _, load := range loads {
+			tw.Write(map[string]interface{}{
+				"NodeID":   load.NodeID,
+				"Capacity": load.Capacity,
+				"Running":  load.Running,
+				"Assigned": load.Assigned,
+				"Deferred": load.Deferred,
+				"Total":    load.Total,
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
 var listReplicaCmd = &cli.Command{
 	Name:  "lr",
 	Usage: "list node replica",
@@ -439,3 +675,154 @@ var edgeExternalAddrCmd = &cli.Command{
 		return nil
 	},
 }
+
+var setUploadLimitCmd = &cli.Command{
+	Name:  "set-upload-limit",
+	Usage: "set the upload limit of the edge node, 0 removes the limit",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		&cli.StringFlag{
+			Name:  "limit",
+			Usage: "upload limit per second, eg. 10MiB",
+			Value: "0",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		limit, err := units.RAMInBytes(cctx.String("limit"))
+		if err != nil {
+			return xerrors.Errorf("parse limit: %w", err)
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetNodeUploadLimit(ctx, nodeID, limit)
+	},
+}
+
+var uploadLimitCmd = &cli.Command{
+	Name:  "upload-limit",
+	Usage: "show the upload limit of the node and its compliance",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		limit, err := schedulerAPI.GetNodeUploadLimit(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Limit: %s/s\n", units.BytesSize(float64(limit.Limit)))
+		fmt.Printf("Applied limit: %s/s\n", units.BytesSize(float64(limit.AppliedLimit)))
+		fmt.Printf("Upload rate: %s/s\n", units.BytesSize(float64(limit.UploadRate)))
+		fmt.Printf("Compliant: %v\n", limit.Compliant)
+		return nil
+	},
+}
+
+var setCacheConfigCmd = &cli.Command{
+	Name:  "set-cache-config",
+	Usage: "set the cache eviction policy of the edge node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		&cli.StringFlag{
+			Name:  "policy",
+			Usage: "eviction order of the cacheable assets: lru, lfu or ttl",
+			Value: string(types.CachePolicyLRU),
+		},
+		&cli.Float64Flag{
+			Name:  "max-disk-usage",
+			Usage: "disk usage percent above which the cacheable assets are evicted, 0 disables the eviction",
+			Value: 90,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		cfg := &types.CacheConfig{Policy: types.CachePolicy(cctx.String("policy")), MaxDiskUsage: cctx.Float64("max-disk-usage")}
+		return schedulerAPI.SetNodeCacheConfig(ctx, nodeID, cfg)
+	},
+}
+
+var cacheCompositionCmd = &cli.Command{
+	Name:  "cache",
+	Usage: "show the pinned and cacheable assets of the edge node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		c, err := schedulerAPI.GetNodeCacheComposition(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Policy: %s\n", c.Policy)
+		fmt.Printf("Max disk usage: %.2f%%\n", c.MaxDiskUsage)
+		fmt.Printf("Disk usage: %.2f%%\n", c.DiskUsage)
+		fmt.Printf("Pinned assets: %d\n", c.PinnedCount)
+		fmt.Printf("Cacheable assets: %d, %s\n", c.CacheableCount, units.BytesSize(float64(c.CacheableSize)))
+
+		tw := tablewriter.New(
+			tablewriter.Col("CID"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Access"),
+			tablewriter.Col("LastAccess"),
+			tablewriter.Col("Expiration"),
+		)
+
+		for _, asset := range c.Cacheable {
+			tw.Write(map[string]interface{}{
+				"CID":        asset.CID,
+				"Size":       units.BytesSize(float64(asset.Size)),
+				"Access":     asset.AccessCount,
+				"LastAccess": asset.LastAccess.Format(defaultDateTimeLayout),
+				"Expiration": asset.Expiration.Format(defaultDateTimeLayout),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
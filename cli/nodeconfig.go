@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var nodeConfigCmds = &cli.Command{
+	Name:  "config",
+	Usage: "Manage the config the scheduler pushes to the nodes",
+	Subcommands: []*cli.Command{
+		setNodeConfigDefaultsCmd,
+		nodeConfigDefaultsCmd,
+		setNodeConfigOverridesCmd,
+		nodeConfigStatusCmd,
+	},
+}
+
+// nodeConfigFlags the fields of the node config, the fields not given are left to the node
+var nodeConfigFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "log-level",
+		Usage: "the level of all the loggers, e.g. info",
+	},
+	&cli.StringFlag{
+		Name:  "cache-policy",
+		Usage: "eviction order of the cacheable assets: lru, lfu or ttl",
+	},
+	&cli.Float64Flag{
+		Name:  "max-disk-usage",
+		Usage: "disk usage percent above which the cacheable assets are evicted, requires cache-policy",
+		Value: 90,
+	},
+	&cli.Int64Flag{
+		Name:  "upload-limit",
+		Usage: "upload limit of the edge, unit: byte per second, 0 removes the limit",
+	},
+	&cli.StringSliceFlag{
+		Name:  "flag",
+		Usage: "feature flag as name=true or name=false, can be repeated",
+	},
+}
+
+func nodeConfigFromFlags(cctx *cli.Context) (*types.NodeConfig, error) {
+	cfg := &types.NodeConfig{}
+	if cctx.IsSet("log-level") {
+		level := cctx.String("log-level")
+		cfg.LogLevel = &level
+	}
+
+	if cctx.IsSet("cache-policy") {
+		cfg.CacheConfig = &types.CacheConfig{Policy: types.CachePolicy(cctx.String("cache-policy")), MaxDiskUsage: cctx.Float64("max-disk-usage")}
+	}
+
+	if cctx.IsSet("upload-limit") {
+		limit := cctx.Int64("upload-limit")
+		cfg.UploadLimit = &limit
+	}
+
+	for _, flag := range cctx.StringSlice("flag") {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok {
+			return nil, xerrors.Errorf("invalid feature flag %s", flag)
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid feature flag %s", flag)
+		}
+
+		if cfg.FeatureFlags == nil {
+			cfg.FeatureFlags = make(map[string]bool)
+		}
+		cfg.FeatureFlags[name] = enabled
+	}
+
+	return cfg, nil
+}
+
+func printNodeConfig(cfg *types.NodeConfig) error {
+	buf, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(buf))
+	return nil
+}
+
+var setNodeConfigDefaultsCmd = &cli.Command{
+	Name:  "set-defaults",
+	Usage: "Set the default config of the node type, the config replaces the previous defaults",
+	Flags: append([]cli.Flag{releaseNodeTypeFlag}, nodeConfigFlags...),
+	Action: func(cctx *cli.Context) error {
+		cfg, err := nodeConfigFromFlags(cctx)
+		if err != nil {
+			return err
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetNodeConfigDefaults(ctx, types.NodeType(cctx.Int("node-type")), cfg)
+	},
+}
+
+var nodeConfigDefaultsCmd = &cli.Command{
+	Name:  "defaults",
+	Usage: "Show the default config of the node type",
+	Flags: []cli.Flag{
+		releaseNodeTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		cfg, err := schedulerAPI.GetNodeConfigDefaults(ctx, types.NodeType(cctx.Int("node-type")))
+		if err != nil {
+			return err
+		}
+
+		return printNodeConfig(cfg)
+	},
+}
+
+var setNodeConfigOverridesCmd = &cli.Command{
+	Name:  "set",
+	Usage: "Set the config overriding the defaults on the node, the overrides are removed if no field is given",
+	Flags: append([]cli.Flag{nodeIDFlag}, nodeConfigFlags...),
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		cfg, err := nodeConfigFromFlags(cctx)
+		if err != nil {
+			return err
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetNodeConfigOverrides(ctx, nodeID, cfg)
+	},
+}
+
+var nodeConfigStatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "Show the config of the node and the revision the node acknowledged",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		status, err := schedulerAPI.GetNodeConfigStatus(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Revision: %s\n", status.Revision)
+		fmt.Printf("Applied revision: %s\n", status.AppliedRevision)
+		if !status.AppliedTime.IsZero() {
+			fmt.Printf("Applied time: %s\n", status.AppliedTime.Format(defaultDateTimeLayout))
+		}
+		if status.Err != "" {
+			fmt.Printf("Errors: %s\n", status.Err)
+		}
+
+		fmt.Println("Effective config:")
+		return printNodeConfig(status.Effective)
+	},
+}
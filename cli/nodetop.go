@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/docker/go-units"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+// the node events shown under the live view
+const nodeTopEvents = 8
+
+// the keys that sort the live view
+var nodeTopKeys = map[rune]types.NodeTopSortBy{
+	'u': types.NodeTopBandwidthUp,
+	'd': types.NodeTopBandwidthDown,
+	'p': types.NodeTopPointsRate,
+	'f': types.NodeTopValidationFailures,
+	'o': types.NodeTopOnlineDuration,
+	'n': types.NodeTopNodeID,
+}
+
+var nodeTopCmd = &cli.Command{
+	Name:  "top",
+	Usage: "live view of the online nodes",
+	Description: "Type a key and enter to sort the view: u upload bandwidth, d download bandwidth, p points rate, " +
+		"f validation failures, o online duration, n node id, r to reverse the order and q to quit",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sort",
+			Usage: "bandwidth_up, bandwidth_down, points_rate, validation_failures, online_duration or node_id",
+			Value: string(types.NodeTopBandwidthUp),
+		},
+		&cli.BoolFlag{
+			Name:  "asc",
+			Usage: "sort in ascending order",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "the nodes shown",
+			Value: 30,
+		},
+		&cli.IntFlag{
+			Name:  "interval",
+			Usage: "the seconds between the updates",
+			Value: 2,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		req := &types.NodeTopReq{
+			SortBy:   types.NodeTopSortBy(cctx.String("sort")),
+			Desc:     !cctx.Bool("asc"),
+			Limit:    cctx.Int("limit"),
+			Interval: cctx.Int("interval"),
+		}
+
+		keys := make(chan rune)
+		go readNodeTopKeys(keys)
+
+		var events []*types.NodeEvent
+		for {
+			subCtx, cancel := context.WithCancel(ctx)
+			snapshots, err := schedulerAPI.SubscribeNodeTop(subCtx, req)
+			if err != nil {
+				cancel()
+				return err
+			}
+
+			quit, err := runNodeTop(ctx, snapshots, keys, req, &events)
+			cancel()
+
+			if err != nil || quit {
+				return err
+			}
+		}
+	},
+}
+
+// runNodeTop renders the snapshots until a key changes the sorting of the view, it returns true if the view quits
+func runNodeTop(ctx context.Context, snapshots <-chan *types.NodeTopSnapshot, keys <-chan rune, req *types.NodeTopReq, events *[]*types.NodeEvent) (bool, error) {
+	for {
+		select {
+		case snapshot, ok := <-snapshots:
+			if !ok {
+				return true, xerrors.New("the scheduler closed the live view")
+			}
+
+			*events = append(*events, snapshot.Events...)
+			if len(*events) > nodeTopEvents {
+				*events = (*events)[len(*events)-nodeTopEvents:]
+			}
+
+			if err := renderNodeTop(snapshot, req, *events); err != nil {
+				return true, err
+			}
+		case key := <-keys:
+			switch key {
+			case 'q':
+				return true, nil
+			case 'r':
+				req.Desc = !req.Desc
+				return false, nil
+			}
+
+			if sortBy, ok := nodeTopKeys[key]; ok {
+				req.SortBy = sortBy
+				return false, nil
+			}
+		case <-ctx.Done():
+			return true, nil
+		}
+	}
+}
+
+// readNodeTopKeys reads the keys typed by the operator, the terminal delivers them once enter is typed
+func readNodeTopKeys(keys chan<- rune) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return
+		}
+
+		if r != '\n' && r != '\r' {
+			keys <- r
+		}
+	}
+}
+
+func renderNodeTop(snapshot *types.NodeTopSnapshot, req *types.NodeTopReq, events []*types.NodeEvent) error {
+	order := "desc"
+	if !req.Desc {
+		order = "asc"
+	}
+
+	// clear the screen and move the cursor to the top left
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf("%s  online edges: %d  online candidates: %d  sorted by %s %s\n\n",
+		snapshot.Time.Format("15:04:05"), snapshot.OnlineEdges, snapshot.OnlineCandidates, req.SortBy, order)
+
+	tw := tablewriter.New(
+		tablewriter.Col("NodeID"),
+		tablewriter.Col("Type"),
+		tablewriter.Col("ExternalIP"),
+		tablewriter.Col("Up"),
+		tablewriter.Col("Down"),
+		tablewriter.Col("CPU"),
+		tablewriter.Col("Memory"),
+		tablewriter.Col("Points/h"),
+		tablewriter.Col("Failures"),
+		tablewriter.Col("Online"),
+	)
+
+	for _, n := range snapshot.Nodes {
+		nodeID := n.NodeID
+		if n.Abnormal {
+			nodeID = color.YellowString(nodeID)
+		}
+
+		failures := fmt.Sprintf("%d", n.ValidationFailures)
+		if n.ValidationFailures > 0 {
+			failures = color.RedString(failures)
+		}
+
+		tw.Write(map[string]interface{}{
+			"NodeID":     nodeID,
+			"Type":       n.Type.String(),
+			"ExternalIP": n.ExternalIP,
+			"Up":         units.BytesSize(float64(n.BandwidthUp)) + "/s",
+			"Down":       units.BytesSize(float64(n.BandwidthDown)) + "/s",
+			"CPU":        fmt.Sprintf("%.1f%%", n.CPUUsage),
+			"Memory":     fmt.Sprintf("%.1f%%", n.MemoryUsage),
+			"Points/h":   fmt.Sprintf("%.2f", n.PointsRate),
+			"Failures":   failures,
+			"Online":     fmt.Sprintf("%dh%02dm", n.OnlineDuration/60, n.OnlineDuration%60),
+		})
+	}
+
+	if err := tw.Flush(os.Stdout); err != nil {
+		return err
+	}
+
+	if len(events) > 0 {
+		fmt.Println()
+		for _, event := range events {
+			line := fmt.Sprintf("%s %s %s", event.Time.Format("15:04:05"), event.NodeID, event.Event)
+			if event.Event == types.EventNodeOffline {
+				line = color.RedString(line)
+			} else {
+				line = color.GreenString(line)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	keys := []string{"u up", "d down", "p points", "f failures", "o online", "n node", "r reverse", "q quit"}
+	fmt.Printf("\n%s (type a key and enter)\n", strings.Join(keys, "  "))
+	return nil
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var replicationCmds = &cli.Command{
+	Name:  "replication",
+	Usage: "Manage the agreements mirroring the assets of the tenants into the other areas",
+	Subcommands: []*cli.Command{
+		createReplicationCmd,
+		pauseReplicationCmd,
+		resumeReplicationCmd,
+		removeReplicationCmd,
+		listReplicationsCmd,
+	},
+}
+
+var agreementIDFlag = &cli.StringFlag{
+	Name:     "id",
+	Usage:    "id of the agreement",
+	Required: true,
+}
+
+var createReplicationCmd = &cli.Command{
+	Name:  "create",
+	Usage: "mirror all the assets of the tenant into the schedulers of the peer area",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+		&cli.StringFlag{
+			Name:     "peer-area",
+			Usage:    "the area the assets are mirrored into",
+			Required: true,
+		},
+		&cli.Int64Flag{
+			Name:  "replicas",
+			Usage: "the edge replicas of the assets in the peer area, the default of the peer area if 0",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		id, err := schedulerAPI.CreateReplicationAgreement(ReqContext(cctx), &types.ReplicationAgreement{
+			TenantID:   cctx.String("tenant-id"),
+			PeerAreaID: cctx.String("peer-area"),
+			Replicas:   cctx.Int64("replicas"),
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var pauseReplicationCmd = &cli.Command{
+	Name:  "pause",
+	Usage: "pause the streaming of the agreement",
+	Flags: []cli.Flag{
+		agreementIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetReplicationAgreementState(ReqContext(cctx), cctx.String("id"), types.ReplicationAgreementPaused)
+	},
+}
+
+var resumeReplicationCmd = &cli.Command{
+	Name:  "resume",
+	Usage: "resume the streaming of the agreement from the last asset streamed",
+	Flags: []cli.Flag{
+		agreementIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetReplicationAgreementState(ReqContext(cctx), cctx.String("id"), types.ReplicationAgreementActive)
+	},
+}
+
+var removeReplicationCmd = &cli.Command{
+	Name:  "remove",
+	Usage: "remove the agreement, the assets mirrored already are kept in the peer area",
+	Flags: []cli.Flag{
+		agreementIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveReplicationAgreement(ReqContext(cctx), cctx.String("id"))
+	},
+}
+
+var listReplicationsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the agreements with their progress",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "tenant-id",
+			Usage: "id of the tenant, all the tenants if not set",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListReplicationAgreements(ReqContext(cctx), cctx.String("tenant-id"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Tenant"),
+			tablewriter.Col("PeerArea"),
+			tablewriter.Col("Replicas"),
+			tablewriter.Col("State"),
+			tablewriter.Col("Streamed"),
+			tablewriter.Col("Cursor"),
+			tablewriter.Col("SyncedTime"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, a := range list {
+			tw.Write(map[string]interface{}{
+				"ID":         a.ID,
+				"Tenant":     a.TenantID,
+				"PeerArea":   a.PeerAreaID,
+				"Replicas":   a.Replicas,
+				"State":      a.State,
+				"Streamed":   a.Streamed,
+				"Cursor":     a.CursorTime.Format(defaultDateTimeLayout),
+				"SyncedTime": a.SyncedTime.Format(defaultDateTimeLayout),
+				"Message":    a.Message,
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/docker/go-units"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var listRetrievalProbesCmd = &cli.Command{
+	Name:  "retrieval-probes",
+	Usage: "List the retrieval probes of the node",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListRetrievalProbes(ctx, nodeID, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("CID"),
+			tablewriter.Col("Success"),
+			tablewriter.Col("Slow"),
+			tablewriter.Col("TTFB"),
+			tablewriter.Col("Throughput"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, probe := range list.Probes {
+			m := map[string]interface{}{
+				"Time":       probe.CreatedTime.Format(defaultDateTimeLayout),
+				"CID":        probe.CID,
+				"Success":    probe.Success,
+				"Slow":       probe.Slow,
+				"TTFB":       fmt.Sprintf("%dms", probe.TTFB),
+				"Throughput": fmt.Sprintf("%s/s", units.BytesSize(float64(probe.Throughput))),
+			}
+			if probe.Message != "" {
+				m["Message"] = probe.Message
+			}
+			tw.Write(m)
+		}
+
+		fmt.Printf("Total: %d\n", list.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var listSpotChecksCmd = &cli.Command{
+	Name:  "spot-checks",
+	Usage: "List the spot checks of the edge disguised as client retrievals",
+	Flags: []cli.Flag{
+		nodeIDFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeID := cctx.String("node-id")
+		if nodeID == "" {
+			return xerrors.New("node-id is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListSpotChecks(ctx, nodeID, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("CID"),
+			tablewriter.Col("Range"),
+			tablewriter.Col("Success"),
+			tablewriter.Col("Verified"),
+			tablewriter.Col("Mismatched"),
+			tablewriter.Col("Slow"),
+			tablewriter.Col("Throughput"),
+			tablewriter.Col("Claimed"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, check := range list.Checks {
+			m := map[string]interface{}{
+				"Time":       check.CreatedTime.Format(defaultDateTimeLayout),
+				"CID":        check.CID,
+				"Range":      fmt.Sprintf("%d-%d", check.RangeStart, check.RangeStart+check.RangeSize-1),
+				"Success":    check.Success,
+				"Verified":   check.Verified,
+				"Mismatched": check.Mismatched,
+				"Slow":       check.Slow,
+				"Throughput": fmt.Sprintf("%s/s", units.BytesSize(float64(check.Throughput))),
+				"Claimed":    fmt.Sprintf("%s/s", units.BytesSize(float64(check.ClaimedBandwidth))),
+			}
+			if check.Message != "" {
+				m["Message"] = check.Message
+			}
+			tw.Write(m)
+		}
+
+		fmt.Printf("Total: %d\n", list.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var retrievalSLACmd = &cli.Command{
+	Name:  "retrieval-sla",
+	Usage: "Show the sla of the retrievals probed by the scheduler, the worst first",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "group-by",
+			Usage: "group the probes by node or area",
+			Value: string(types.RetrievalSLAByNode),
+		},
+		&cli.DurationFlag{
+			Name:  "period",
+			Usage: "the period of the report till now",
+			Value: 24 * time.Hour,
+		},
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		end := time.Now()
+		report, err := schedulerAPI.GetRetrievalSLAReport(ctx, types.RetrievalSLAGroup(cctx.String("group-by")), end.Add(-cctx.Duration("period")), end, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Key"),
+			tablewriter.Col("Probes"),
+			tablewriter.Col("Failures"),
+			tablewriter.Col("Slow"),
+			tablewriter.Col("SLA"),
+			tablewriter.Col("AvgTTFB"),
+			tablewriter.Col("MaxTTFB"),
+			tablewriter.Col("AvgThroughput"),
+		)
+
+		for _, entry := range report.Entries {
+			tw.Write(map[string]interface{}{
+				"Key":           entry.Key,
+				"Probes":        entry.Probes,
+				"Failures":      entry.Failures,
+				"Slow":          entry.Slow,
+				"SLA":           fmt.Sprintf("%.2f%%", entry.SLA),
+				"AvgTTFB":       fmt.Sprintf("%dms", entry.AvgTTFB),
+				"MaxTTFB":       fmt.Sprintf("%dms", entry.MaxTTFB),
+				"AvgThroughput": fmt.Sprintf("%s/s", units.BytesSize(float64(entry.AvgThroughput))),
+			})
+		}
+
+		fmt.Printf("Total: %d\n", report.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
@@ -16,6 +16,14 @@ var SchedulerCMDs = []*cli.Command{
 	WithCategory("asset", assetCmds),
 	WithCategory("config", sConfigCmds),
 	WithCategory("user", userCmds),
+	WithCategory("token", apiTokenCmds),
+	WithCategory("tenant", tenantCmds),
+	WithCategory("webhook", webhookCmds),
+	WithCategory("audit", auditCmds),
+	WithCategory("denylist", denylistCmds),
+	WithCategory("gateway", gatewayCmds),
+	WithCategory("upgrade", upgradeCmds),
+	WithCategory("feature-flag", featureFlagCmds),
 	startElectionCmd,
 	// other
 	edgeUpdaterCmd,
@@ -86,6 +94,18 @@ var (
 		Usage: "bandwidth (unit:MiB/s)",
 		Value: 0,
 	}
+
+	qosTierFlag = &cli.StringFlag{
+		Name:  "qos-tier",
+		Usage: "qos tier of the asset: standard, streaming or bulk",
+		Value: "",
+	}
+
+	videoFormatFlag = &cli.StringFlag{
+		Name:  "video-format",
+		Usage: "video format of the asset: hls, dash or empty for none",
+		Value: "",
+	}
 )
 
 var setNodePortCmd = &cli.Command{
@@ -327,6 +347,22 @@ var sConfigCmds = &cli.Command{
 	Subcommands: []*cli.Command{
 		sConfigSetCmd,
 		sConfigShowCmd,
+		sRotateKeyCmd,
+	},
+}
+
+var sRotateKeyCmd = &cli.Command{
+	Name:  "rotate-key",
+	Usage: "rotate scheduler private key",
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		return schedulerAPI.RotateSchedulerKey(ctx)
 	},
 }
 
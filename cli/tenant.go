@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/docker/go-units"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+const defaultDateLayout = "2006-01-02"
+
+var tenantCmds = &cli.Command{
+	Name:  "tenant",
+	Usage: "Manage tenant namespaces, their users, quotas and usage",
+	Subcommands: []*cli.Command{
+		setTenantCmd,
+		removeTenantCmd,
+		listTenantsCmd,
+		addTenantUserCmd,
+		removeTenantUserCmd,
+		listTenantUsersCmd,
+		tenantUsageCmd,
+		tenantQuotaCmd,
+		tenantReportCmd,
+		createTenantTokenCmd,
+		replicationCmds,
+	},
+}
+
+var tenantIDFlag = &cli.StringFlag{
+	Name:     "tenant-id",
+	Usage:    "id of the tenant",
+	Required: true,
+}
+
+var setTenantCmd = &cli.Command{
+	Name:  "set",
+	Usage: "create or update the tenant",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "name of the tenant",
+		},
+		&cli.StringFlag{
+			Name:  "storage-quota",
+			Usage: "storage of the users of the tenant, eg. 10TiB, 0 is unlimited",
+			Value: "0",
+		},
+		&cli.StringFlag{
+			Name:  "traffic-quota",
+			Usage: "download traffic of the users of the tenant each month, eg. 100TiB, 0 is unlimited",
+			Value: "0",
+		},
+		&cli.IntFlag{
+			Name:  "rate-limit",
+			Usage: "requests of the users of the tenant per second, 0 is unlimited",
+		},
+		&cli.IntFlag{
+			Name:  "soft-limit",
+			Usage: "percent of the quotas the users of the tenant are warned at, 0 is the default 80",
+		},
+		&cli.StringFlag{
+			Name:  "geo-rules",
+			Usage: `geo policy of the assets of the tenant in json, eg. [{"Region":"Asia-China","MinReplicas":2}]`,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		storage, err := units.RAMInBytes(cctx.String("storage-quota"))
+		if err != nil {
+			return xerrors.Errorf("parse storage-quota: %w", err)
+		}
+
+		traffic, err := units.RAMInBytes(cctx.String("traffic-quota"))
+		if err != nil {
+			return xerrors.Errorf("parse traffic-quota: %w", err)
+		}
+
+		tenant := &types.Tenant{
+			ID:           cctx.String("tenant-id"),
+			Name:         cctx.String("name"),
+			StorageQuota: storage,
+			TrafficQuota: traffic,
+			RateLimit:    cctx.Int("rate-limit"),
+			SoftLimit:    cctx.Int("soft-limit"),
+		}
+
+		if rules := cctx.String("geo-rules"); rules != "" {
+			if err := json.Unmarshal([]byte(rules), &tenant.GeoRules); err != nil {
+				return xerrors.Errorf("parse geo-rules: %w", err)
+			}
+		}
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetTenant(ReqContext(cctx), tenant)
+	},
+}
+
+var removeTenantCmd = &cli.Command{
+	Name:  "remove",
+	Usage: "remove the tenant without users",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveTenant(ReqContext(cctx), cctx.String("tenant-id"))
+	},
+}
+
+var listTenantsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the tenants",
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		tenants, err := schedulerAPI.ListTenants(ReqContext(cctx))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Name"),
+			tablewriter.Col("StorageQuota"),
+			tablewriter.Col("TrafficQuota"),
+			tablewriter.Col("RateLimit"),
+			tablewriter.Col("SoftLimit"),
+			tablewriter.Col("GeoRules"),
+		)
+
+		for _, t := range tenants {
+			tw.Write(map[string]interface{}{
+				"ID":           t.ID,
+				"Name":         t.Name,
+				"StorageQuota": quotaString(t.StorageQuota),
+				"TrafficQuota": quotaString(t.TrafficQuota),
+				"RateLimit":    t.RateLimit,
+				"SoftLimit":    fmt.Sprintf("%d%%", t.SoftLimitPercent()),
+				"GeoRules":     len(t.GeoRules),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var addTenantUserCmd = &cli.Command{
+	Name:  "add-user",
+	Usage: "add the user to the tenant",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+		&cli.StringFlag{
+			Name:     "user-id",
+			Usage:    "id of the user",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.AddTenantUser(ReqContext(cctx), cctx.String("tenant-id"), cctx.String("user-id"))
+	},
+}
+
+var removeTenantUserCmd = &cli.Command{
+	Name:  "remove-user",
+	Usage: "remove the user from the tenant",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+		&cli.StringFlag{
+			Name:     "user-id",
+			Usage:    "id of the user",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveTenantUser(ReqContext(cctx), cctx.String("tenant-id"), cctx.String("user-id"))
+	},
+}
+
+var listTenantUsersCmd = &cli.Command{
+	Name:  "users",
+	Usage: "list the users of the tenant",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.ListTenantUsers(ReqContext(cctx), cctx.String("tenant-id"), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("UserID"),
+			tablewriter.Col("CreatedTime"),
+		)
+
+		for _, u := range rsp.Data {
+			tw.Write(map[string]interface{}{
+				"UserID":      u.UserID,
+				"CreatedTime": u.CreatedTime.Format(defaultDateTimeLayout),
+			})
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("Total:%d\n", rsp.Total)
+		return nil
+	},
+}
+
+var tenantUsageCmd = &cli.Command{
+	Name:  "usage",
+	Usage: "show the storage and the usage of the month of the tenant against its quotas",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		usage, err := schedulerAPI.GetTenantUsage(ReqContext(cctx), cctx.String("tenant-id"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tenant:\t\t%s(%s)\n", usage.Tenant.ID, usage.Tenant.Name)
+		fmt.Printf("Users:\t\t%d\n", usage.Users)
+		fmt.Printf("Assets:\t\t%d\n", usage.Assets)
+		fmt.Printf("Storage:\t%s/%s\n", units.BytesSize(float64(usage.StorageUsed)), quotaString(usage.Tenant.StorageQuota))
+		fmt.Printf("Traffic:\t%s/%s\n", units.BytesSize(float64(usage.Month.Traffic)), quotaString(usage.Tenant.TrafficQuota))
+		fmt.Printf("Uploaded:\t%s\n", units.BytesSize(float64(usage.Month.Uploaded)))
+		fmt.Printf("Requests:\t%d\n", usage.Month.Requests)
+		fmt.Printf("Throttled:\t%d\n", usage.Month.Throttled)
+		return nil
+	},
+}
+
+var tenantQuotaCmd = &cli.Command{
+	Name:  "quota",
+	Usage: "show the stored bytes and the egress of the month of the tenant against its quotas",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		status, err := schedulerAPI.GetTenantQuota(ReqContext(cctx), cctx.String("tenant-id"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tenant:\t\t%s(%s)\n", status.Tenant.ID, status.Tenant.Name)
+		fmt.Printf("Storage:\t%s/%s\t%s\n", units.BytesSize(float64(status.StoredBytes)), quotaString(status.Tenant.StorageQuota),
+			quotaState(status.StorageWarning, status.StorageExceeded))
+		fmt.Printf("Egress:\t\t%s/%s\t%s\n", units.BytesSize(float64(status.EgressBytes)), quotaString(status.Tenant.TrafficQuota),
+			quotaState(status.TrafficWarning, status.TrafficExceeded))
+		fmt.Printf("SoftLimit:\t%d%%\n", status.Tenant.SoftLimitPercent())
+		return nil
+	},
+}
+
+var tenantReportCmd = &cli.Command{
+	Name:  "report",
+	Usage: "show the daily usage of the tenant",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+		&cli.StringFlag{
+			Name:  "start",
+			Usage: "the first day of the report, eg. 2024-01-01, the default is 30 days ago",
+		},
+		&cli.StringFlag{
+			Name:  "end",
+			Usage: "the last day of the report, eg. 2024-01-31, the default is today",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		end := time.Now()
+		start := end.AddDate(0, 0, -30)
+
+		var err error
+		if s := cctx.String("start"); s != "" {
+			if start, err = time.ParseInLocation(defaultDateLayout, s, time.Local); err != nil {
+				return xerrors.Errorf("parse start: %w", err)
+			}
+		}
+
+		if e := cctx.String("end"); e != "" {
+			if end, err = time.ParseInLocation(defaultDateLayout, e, time.Local); err != nil {
+				return xerrors.Errorf("parse end: %w", err)
+			}
+			end = end.Add(24*time.Hour - time.Second)
+		}
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		report, err := schedulerAPI.GetTenantUsageReport(ReqContext(cctx), cctx.String("tenant-id"), start, end)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Day"),
+			tablewriter.Col("Requests"),
+			tablewriter.Col("Throttled"),
+			tablewriter.Col("Uploaded"),
+			tablewriter.Col("Traffic"),
+		)
+
+		for _, d := range append(report.Days, report.Total) {
+			day := d.Day.Format(defaultDateLayout)
+			if d == report.Total {
+				day = "Total"
+			}
+
+			tw.Write(map[string]interface{}{
+				"Day":       day,
+				"Requests":  d.Requests,
+				"Throttled": d.Throttled,
+				"Uploaded":  units.BytesSize(float64(d.Uploaded)),
+				"Traffic":   units.BytesSize(float64(d.Traffic)),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var createTenantTokenCmd = &cli.Command{
+	Name:  "token",
+	Usage: "create a token scoped to the tenant for its web portal",
+	Flags: []cli.Flag{
+		tenantIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		token, err := schedulerAPI.CreateTenantToken(ReqContext(cctx), cctx.String("tenant-id"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(token)
+		return nil
+	},
+}
+
+func quotaString(quota int64) string {
+	if quota <= 0 {
+		return "unlimited"
+	}
+	return units.BytesSize(float64(quota))
+}
+
+func quotaState(warning, exceeded bool) string {
+	switch {
+	case exceeded:
+		return "exceeded"
+	case warning:
+		return "warning"
+	}
+	return "ok"
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/urfave/cli/v2"
+)
+
+var apiTokenCmds = &cli.Command{
+	Name:  "api-token",
+	Usage: "Manage api tokens and their roles",
+	Subcommands: []*cli.Command{
+		createAPIToken,
+		assignAPITokenRole,
+		listAPITokens,
+		rotateAPIToken,
+		revokeTokens,
+		listActiveTokens,
+	},
+}
+
+var createAPIToken = &cli.Command{
+	Name:  "create",
+	Usage: "create api token with role",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "special a name for token",
+		},
+		&cli.StringFlag{
+			Name:     "role",
+			Usage:    "role of the token, one of: admin, operator, readonly, web",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		token, err := schedulerAPI.CreateAPIToken(ctx, cctx.String("name"), auth.Permission(cctx.String("role")))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(token)
+		return nil
+	},
+}
+
+var assignAPITokenRole = &cli.Command{
+	Name:  "assign",
+	Usage: "assign role to api token",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "id",
+			Usage:    "id of the token",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "role",
+			Usage:    "role of the token, one of: admin, operator, readonly, web",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		return schedulerAPI.AssignAPITokenRole(ctx, cctx.String("id"), auth.Permission(cctx.String("role")))
+	},
+}
+
+var listAPITokens = &cli.Command{
+	Name:  "list",
+	Usage: "list api tokens",
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		tokens, err := schedulerAPI.GetAPITokens(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Name"),
+			tablewriter.Col("Role"),
+			tablewriter.Col("CreatedTime"),
+		)
+
+		for _, tk := range tokens {
+			tw.Write(map[string]interface{}{
+				"ID":          tk.ID,
+				"Name":        tk.Name,
+				"Role":        tk.Role,
+				"CreatedTime": tk.CreatedTime.Format(defaultDateTimeLayout),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var rotateAPIToken = &cli.Command{
+	Name:  "rotate",
+	Usage: "revoke api token and issue a new one with the same id and role",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "id",
+			Usage:    "id of the token",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		token, err := schedulerAPI.RotateAPIToken(ctx, cctx.String("id"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(token)
+		return nil
+	},
+}
+
+var revokeTokens = &cli.Command{
+	Name:  "revoke",
+	Usage: "revoke all tokens of node, user or api token",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "subject",
+			Usage:    "node id, user id or api token id",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		return schedulerAPI.RevokeTokens(ctx, cctx.String("subject"))
+	},
+}
+
+var listActiveTokens = &cli.Command{
+	Name:  "active",
+	Usage: "list subjects with active tokens",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "role",
+			Usage: "role of the tokens, empty for all roles",
+		},
+		limitFlag,
+		offsetFlag,
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		rsp, err := schedulerAPI.GetActiveTokens(ctx, auth.Permission(cctx.String("role")), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Subject"),
+			tablewriter.Col("Role"),
+			tablewriter.Col("IssuedTime"),
+		)
+
+		for _, sub := range rsp.Data {
+			tw.Write(map[string]interface{}{
+				"Subject":    sub.Subject,
+				"Role":       sub.Role,
+				"IssuedTime": time.Unix(0, sub.IssuedAt).Format(defaultDateTimeLayout),
+			})
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("Total:%d\n", rsp.Total)
+		return nil
+	},
+}
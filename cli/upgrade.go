@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var upgradeCmds = &cli.Command{
+	Name:  "upgrade",
+	Usage: "Roll out the releases to the nodes",
+	Subcommands: []*cli.Command{
+		addReleaseCmd,
+		listReleasesCmd,
+		startRolloutCmd,
+		listRolloutsCmd,
+		rolloutStatusCmd,
+		listRolloutNodesCmd,
+		haltRolloutCmd,
+		resumeRolloutCmd,
+		versionAdoptionCmd,
+	},
+}
+
+var releaseNodeTypeFlag = &cli.IntFlag{
+	Name:  "node-type",
+	Usage: "node type 1:Edge 2:Candidate",
+	Value: int(types.NodeEdge),
+}
+
+var addReleaseCmd = &cli.Command{
+	Name:  "add-release",
+	Usage: "Sign the release manifest with the release key and save it to the scheduler",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "version",
+			Usage:    "release version, e.g. 0.1.17",
+			Required: true,
+		},
+		releaseNodeTypeFlag,
+		&cli.StringSliceFlag{
+			Name:     "artifact",
+			Usage:    "binary of a platform, <os>/<arch>,<url>,<sha256>, e.g. linux/amd64,https://example.com/titan-edge,ab12...",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "release-key",
+			Usage:    "path of the pem rsa private key of the release",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		manifest := &types.ReleaseManifest{Version: cctx.String("version"), NodeType: types.NodeType(cctx.Int("node-type"))}
+		for _, s := range cctx.StringSlice("artifact") {
+			parts := strings.Split(s, ",")
+			if len(parts) != 3 {
+				return xerrors.Errorf("invalid artifact %s", s)
+			}
+			manifest.Artifacts = append(manifest.Artifacts, &types.ReleaseArtifact{Platform: parts[0], URL: parts[1], Hash: strings.ToLower(parts[2])})
+		}
+
+		pem, err := os.ReadFile(cctx.String("release-key"))
+		if err != nil {
+			return err
+		}
+
+		privateKey, err := titanrsa.Pem2PrivateKey(pem)
+		if err != nil {
+			return err
+		}
+
+		manifest.Sign, err = titanrsa.New(crypto.SHA256, crypto.SHA256.New()).Sign(privateKey, manifest.SignData())
+		if err != nil {
+			return err
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.AddReleaseManifest(ctx, manifest)
+	},
+}
+
+var listReleasesCmd = &cli.Command{
+	Name:  "releases",
+	Usage: "List the release manifests of the node type",
+	Flags: []cli.Flag{
+		releaseNodeTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		manifests, err := schedulerAPI.ListReleaseManifests(ctx, types.NodeType(cctx.Int("node-type")))
+		if err != nil {
+			return err
+		}
+
+		for _, manifest := range manifests {
+			fmt.Printf("%s %s\n", manifest.Version, manifest.CreatedTime.Format(defaultDateTimeLayout))
+			for _, artifact := range manifest.Artifacts {
+				fmt.Printf("  %s %s %s\n", artifact.Platform, artifact.URL, artifact.Hash)
+			}
+		}
+
+		return nil
+	},
+}
+
+var startRolloutCmd = &cli.Command{
+	Name:  "start",
+	Usage: "Start rolling out the release to the canary nodes and then the waves of the nodes",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "version",
+			Usage:    "release version",
+			Required: true,
+		},
+		releaseNodeTypeFlag,
+		&cli.IntFlag{
+			Name:  "canary",
+			Usage: "number of the canary nodes",
+			Value: 5,
+		},
+		&cli.StringFlag{
+			Name:  "waves",
+			Usage: "cumulative percentages of the nodes of the waves after the canary",
+			Value: "10,50,100",
+		},
+		&cli.Float64Flag{
+			Name:  "max-offline-rate",
+			Usage: "the rollout halts if the rate of the nodes of a wave not back online with the release exceeds it",
+			Value: 0.2,
+		},
+		&cli.IntFlag{
+			Name:  "wave-interval",
+			Usage: "minutes a wave is observed before the next wave",
+			Value: 60,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		req := &types.UpgradeRolloutReq{
+			Version:        cctx.String("version"),
+			NodeType:       types.NodeType(cctx.Int("node-type")),
+			CanaryCount:    cctx.Int("canary"),
+			MaxOfflineRate: cctx.Float64("max-offline-rate"),
+			WaveInterval:   cctx.Int("wave-interval"),
+		}
+
+		for _, s := range strings.Split(cctx.String("waves"), ",") {
+			w, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return xerrors.Errorf("invalid waves %s", cctx.String("waves"))
+			}
+			req.Waves = append(req.Waves, w)
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		id, err := schedulerAPI.StartUpgradeRollout(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var listRolloutsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the upgrade rollouts",
+	Flags: []cli.Flag{
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListUpgradeRollouts(ctx, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Version"),
+			tablewriter.Col("NodeType"),
+			tablewriter.Col("Wave"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Created"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, rollout := range list.Rollouts {
+			m := map[string]interface{}{
+				"ID":       rollout.ID,
+				"Version":  rollout.Version,
+				"NodeType": rollout.NodeType.String(),
+				"Wave":     fmt.Sprintf("%d/%s", rollout.Wave, rollout.Waves),
+				"Status":   rollout.Status,
+				"Created":  rollout.CreatedTime.Format(defaultDateTimeLayout),
+			}
+			if rollout.Message != "" {
+				m["Message"] = rollout.Message
+			}
+			tw.Write(m)
+		}
+
+		fmt.Printf("Total: %d\n", list.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var rolloutStatusCmd = &cli.Command{
+	Name:      "status",
+	Usage:     "Show the upgrade rollout with the nodes of its waves",
+	ArgsUsage: "<id>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("rollout id is required")
+		}
+		id := cctx.Args().First()
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rollout, err := schedulerAPI.GetUpgradeRollout(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		stats, err := schedulerAPI.GetUpgradeWaveStats(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ID: %s\n", rollout.ID)
+		fmt.Printf("Version: %s\n", rollout.Version)
+		fmt.Printf("NodeType: %s\n", rollout.NodeType.String())
+		fmt.Printf("Canary: %d\n", rollout.CanaryCount)
+		fmt.Printf("Waves: %s\n", rollout.Waves)
+		fmt.Printf("Wave: %d, started at %s\n", rollout.Wave, rollout.WaveStartedTime.Format(defaultDateTimeLayout))
+		fmt.Printf("MaxOfflineRate: %.2f\n", rollout.MaxOfflineRate)
+		fmt.Printf("Status: %s\n", rollout.Status)
+		if rollout.Message != "" {
+			fmt.Printf("Message: %s\n", rollout.Message)
+		}
+
+		for _, s := range stats {
+			fmt.Printf("wave %d %s: %d\n", s.Wave, s.Status, s.Count)
+		}
+
+		return nil
+	},
+}
+
+var listRolloutNodesCmd = &cli.Command{
+	Name:      "nodes",
+	Usage:     "List the nodes commanded by the rollout",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("rollout id is required")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListUpgradeNodes(ctx, cctx.Args().First(), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("NodeID"),
+			tablewriter.Col("Wave"),
+			tablewriter.Col("From"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Commanded"),
+			tablewriter.NewLineCol("Message"),
+		)
+
+		for _, node := range list.Nodes {
+			m := map[string]interface{}{
+				"NodeID":    node.NodeID,
+				"Wave":      node.Wave,
+				"From":      node.FromVersion,
+				"Status":    node.Status,
+				"Commanded": node.CommandedTime.Format(defaultDateTimeLayout),
+			}
+			if node.Message != "" {
+				m["Message"] = node.Message
+			}
+			tw.Write(m)
+		}
+
+		fmt.Printf("Total: %d\n", list.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var haltRolloutCmd = &cli.Command{
+	Name:      "halt",
+	Usage:     "Halt the running rollout",
+	ArgsUsage: "<id>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "reason",
+			Usage: "reason of the halt",
+			Value: "halted by the operator",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("rollout id is required")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.HaltUpgradeRollout(ctx, cctx.Args().First(), cctx.String("reason"))
+	},
+}
+
+var resumeRolloutCmd = &cli.Command{
+	Name:      "resume",
+	Usage:     "Resume the halted rollout from its current wave",
+	ArgsUsage: "<id>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return xerrors.New("rollout id is required")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.ResumeUpgradeRollout(ctx, cctx.Args().First())
+	},
+}
+
+var versionAdoptionCmd = &cli.Command{
+	Name:  "adoption",
+	Usage: "Show the number of the nodes seen in the last day by release version",
+	Flags: []cli.Flag{
+		releaseNodeTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		adoption, err := schedulerAPI.GetVersionAdoption(ctx, types.NodeType(cctx.Int("node-type")))
+		if err != nil {
+			return err
+		}
+
+		for _, a := range adoption {
+			fmt.Printf("%s: %d\n", a.Version, a.Count)
+		}
+
+		return nil
+	},
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/lib/tablewriter"
 	"github.com/docker/go-units"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
 )
 
 var userCmds = &cli.Command{
@@ -46,8 +49,11 @@ var userAssetCmds = &cli.Command{
 	Usage: "Manage user asset",
 	Subcommands: []*cli.Command{
 		listAssets,
+		searchAssets,
 		removeAsset,
 		shareLink,
+		signedURL,
+		assetACLCmds,
 	},
 }
 
@@ -305,6 +311,134 @@ var listAssets = &cli.Command{
 	},
 }
 
+var searchAssets = &cli.Command{
+	Name:  "search",
+	Usage: "search assets of user by labels, name prefix, size and creation time",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "label of the assets, name=value or name to match any value",
+		},
+		&cli.StringFlag{
+			Name:  "name-prefix",
+			Usage: "prefix of the asset names",
+		},
+		&cli.StringFlag{
+			Name:  "min-size",
+			Usage: "min size of the assets, eg. 1MiB",
+		},
+		&cli.StringFlag{
+			Name:  "max-size",
+			Usage: "max size of the assets, eg. 1GiB",
+		},
+		&cli.StringFlag{
+			Name:  "created-after",
+			Usage: "the assets created on or after the date, eg. 2024-01-02",
+		},
+		&cli.StringFlag{
+			Name:  "created-before",
+			Usage: "the assets created before the date, eg. 2024-01-02",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "count of list",
+			Value: 50,
+		},
+		&cli.IntFlag{
+			Name:  "offset",
+			Usage: "offset of list",
+			Value: 0,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		req := &types.SearchAssetsReq{
+			UserID:     cctx.String("user"),
+			NamePrefix: cctx.String("name-prefix"),
+			Limit:      cctx.Int("limit"),
+			Offset:     cctx.Int("offset"),
+		}
+
+		if labels := cctx.StringSlice("label"); len(labels) > 0 {
+			req.Labels = make(types.AssetLabels)
+			for _, label := range labels {
+				name, value, _ := strings.Cut(label, "=")
+				req.Labels[name] = value
+			}
+		}
+
+		var err error
+		if size := cctx.String("min-size"); size != "" {
+			if req.MinSize, err = units.RAMInBytes(size); err != nil {
+				return xerrors.Errorf("parse min-size: %w", err)
+			}
+		}
+
+		if size := cctx.String("max-size"); size != "" {
+			if req.MaxSize, err = units.RAMInBytes(size); err != nil {
+				return xerrors.Errorf("parse max-size: %w", err)
+			}
+		}
+
+		if date := cctx.String("created-after"); date != "" {
+			if req.CreatedAfter, err = time.ParseInLocation(defaultDateLayout, date, time.Local); err != nil {
+				return xerrors.Errorf("parse created-after: %w", err)
+			}
+		}
+
+		if date := cctx.String("created-before"); date != "" {
+			if req.CreatedBefore, err = time.ParseInLocation(defaultDateLayout, date, time.Local); err != nil {
+				return xerrors.Errorf("parse created-before: %w", err)
+			}
+		}
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.SearchAssets(ReqContext(cctx), req)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("CID"),
+			tablewriter.Col("Name"),
+			tablewriter.Col("ContentType"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("CreatedTime"),
+			tablewriter.Col("Labels"),
+		)
+
+		for _, asset := range rsp.Data {
+			labels := make([]string, 0, len(asset.Labels))
+			for name, value := range asset.Labels {
+				labels = append(labels, name+"="+value)
+			}
+			sort.Strings(labels)
+
+			tw.Write(map[string]interface{}{
+				"CID":         asset.AssetCID,
+				"Name":        asset.AssetName,
+				"ContentType": asset.ContentType,
+				"Size":        units.BytesSize(float64(asset.TotalSize)),
+				"CreatedTime": asset.CreatedTime.Format(defaultDateTimeLayout),
+				"Labels":      strings.Join(labels, ","),
+			})
+		}
+
+		fmt.Printf("total %d\n", rsp.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
+
 var removeAsset = &cli.Command{
 	Name:  "remove",
 	Usage: "remove assets of user",
@@ -381,6 +515,169 @@ var shareLink = &cli.Command{
 	},
 }
 
+var signedURL = &cli.Command{
+	Name:  "sign-url",
+	Usage: "sign a time-limited url to share the asset of user",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Usage:    "Specify the asset cid",
+			Required: true,
+		},
+		&cli.DurationFlag{
+			Name:  "expire",
+			Usage: "how long the url is valid for, at most 168h",
+			Value: 24 * time.Hour,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		signed, err := schedulerAPI.CreateSignedURL(ctx, cctx.String("user"), cctx.String("cid"), time.Now().Add(cctx.Duration("expire")))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Expiration: %s\n", signed.Expiration.Format(defaultDateTimeLayout))
+		fmt.Printf("Path: %s\n", signed.Path)
+		for _, u := range signed.URLs {
+			fmt.Println(u)
+		}
+		return nil
+	},
+}
+
+var assetACLCmds = &cli.Command{
+	Name:  "acl",
+	Usage: "Manage the access control list of the private asset of user",
+	Subcommands: []*cli.Command{
+		setAssetACL,
+		removeAssetACL,
+		showAssetACL,
+	},
+}
+
+var setAssetACL = &cli.Command{
+	Name:  "set",
+	Usage: "make the asset private, only the owners and the users and api keys in the list can download it",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Usage:    "Specify the asset cid",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "allow-user",
+			Usage: "the user id allowed to download the asset",
+		},
+		&cli.StringSliceFlag{
+			Name:  "allow-key",
+			Usage: "the api key allowed to download the asset, as <user id>/<key name>",
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		acl := &types.AssetACL{
+			CID:     cctx.String("cid"),
+			Users:   cctx.StringSlice("allow-user"),
+			APIKeys: cctx.StringSlice("allow-key"),
+		}
+
+		ctx := ReqContext(cctx)
+		return schedulerAPI.SetAssetACL(ctx, cctx.String("user"), acl)
+	},
+}
+
+var removeAssetACL = &cli.Command{
+	Name:  "remove",
+	Usage: "make the asset public",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Usage:    "Specify the asset cid",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		return schedulerAPI.RemoveAssetACL(ctx, cctx.String("user"), cctx.String("cid"))
+	},
+}
+
+var showAssetACL = &cli.Command{
+	Name:  "show",
+	Usage: "show the access control list of the asset",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Usage:    "Specify the asset cid",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		acl, err := schedulerAPI.GetAssetACL(ctx, cctx.String("user"), cctx.String("cid"))
+		if err != nil {
+			return err
+		}
+
+		if acl == nil {
+			fmt.Println("The asset is public")
+			return nil
+		}
+
+		fmt.Printf("Users: %s\n", strings.Join(acl.Users, ","))
+		fmt.Printf("API keys: %s\n", strings.Join(acl.APIKeys, ","))
+		return nil
+	},
+}
+
 var changeVIP = &cli.Command{
 	Name:  "vip",
 	Usage: "change user vip",
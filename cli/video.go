@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var setVideoFormatCmd = &cli.Command{
+	Name:  "set-video-format",
+	Usage: "Mark the asset as hls or dash, the nodes prefetch the segments of the marked assets",
+	Flags: []cli.Flag{
+		cidFlag,
+		videoFormatFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return xerrors.New("cid is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.UpdateAssetVideoFormat(ctx, cid, types.AssetVideoFormat(cctx.String("video-format")))
+	},
+}
+
+var segmentStatsCmd = &cli.Command{
+	Name:  "segment-stats",
+	Usage: "List the retrievals of the segments of the video asset, the most retrieved first",
+	Flags: []cli.Flag{
+		cidFlag,
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		cid := cctx.String("cid")
+		if cid == "" {
+			return xerrors.New("cid is nil")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		list, err := schedulerAPI.ListSegmentStats(ctx, cid, cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Segment"),
+			tablewriter.Col("Hits"),
+			tablewriter.Col("Updated"),
+		)
+
+		for _, s := range list.Segments {
+			tw.Write(map[string]interface{}{
+				"Segment": s.Segment,
+				"Hits":    s.Hits,
+				"Updated": s.UpdatedTime.Format(defaultDateTimeLayout),
+			})
+		}
+
+		fmt.Printf("Total: %d\n", list.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
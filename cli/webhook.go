@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var webhookCmds = &cli.Command{
+	Name:  "webhook",
+	Usage: "Manage the webhooks called on the asset lifecycle events",
+	Subcommands: []*cli.Command{
+		createWebhookCmd,
+		listWebhooksCmd,
+		deleteWebhookCmd,
+		listWebhookDeliveriesCmd,
+	},
+}
+
+var createWebhookCmd = &cli.Command{
+	Name:  "create",
+	Usage: "register a webhook of the asset of the user, or of the tenant",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "url",
+			Usage:    "http or https url the signed callbacks are posted to",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "user-id",
+			Usage: "id of the user of the asset",
+		},
+		&cli.StringFlag{
+			Name:  "cid",
+			Usage: "cid of the asset, the webhook of the tenant is created if it is empty",
+		},
+		&cli.StringFlag{
+			Name:  "tenant-id",
+			Usage: "id of the tenant",
+		},
+		&cli.StringSliceFlag{
+			Name:  "event",
+			Usage: "replication_complete, replica_lost, asset_expired or asset_purged, all events if not set",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		var events []types.WebhookEvent
+		for _, e := range cctx.StringSlice("event") {
+			events = append(events, types.WebhookEvent(e))
+		}
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		var w *types.Webhook
+		switch {
+		case cctx.String("cid") != "":
+			w, err = schedulerAPI.CreateAssetWebhook(ctx, cctx.String("user-id"), cctx.String("cid"), cctx.String("url"), events)
+		case cctx.String("tenant-id") != "":
+			w, err = schedulerAPI.CreateTenantWebhook(ctx, cctx.String("tenant-id"), cctx.String("url"), events)
+		default:
+			return xerrors.New("cid or tenant-id is required")
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("webhook %d created, callbacks are signed with the secret:\n%s\n", w.ID, w.Secret)
+		return nil
+	},
+}
+
+var listWebhooksCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the asset webhooks of the user, or the webhooks of the tenant",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "user-id",
+			Usage: "id of the user",
+		},
+		&cli.StringFlag{
+			Name:  "tenant-id",
+			Usage: "id of the tenant",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		webhooks, err := schedulerAPI.ListWebhooks(ReqContext(cctx), cctx.String("user-id"), cctx.String("tenant-id"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Owner"),
+			tablewriter.Col("CID"),
+			tablewriter.Col("URL"),
+			tablewriter.Col("Events"),
+			tablewriter.Col("CreatedTime"),
+		)
+
+		for _, w := range webhooks {
+			owner := w.UserID
+			if w.TenantID != "" {
+				owner = "tenant:" + w.TenantID
+			}
+
+			events := "all"
+			if len(w.Events) > 0 {
+				list := make([]string, 0, len(w.Events))
+				for _, e := range w.Events {
+					list = append(list, string(e))
+				}
+				events = strings.Join(list, ",")
+			}
+
+			tw.Write(map[string]interface{}{
+				"ID":          w.ID,
+				"Owner":       owner,
+				"CID":         w.AssetCID,
+				"URL":         w.URL,
+				"Events":      events,
+				"CreatedTime": w.CreatedTime.Format(defaultDateTimeLayout),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var webhookIDFlag = &cli.Int64Flag{
+	Name:     "id",
+	Usage:    "id of the webhook",
+	Required: true,
+}
+
+var deleteWebhookCmd = &cli.Command{
+	Name:  "delete",
+	Usage: "delete the webhook and its deliveries",
+	Flags: []cli.Flag{
+		webhookIDFlag,
+		&cli.StringFlag{
+			Name:  "user-id",
+			Usage: "id of the user of the asset webhook",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.DeleteWebhook(ReqContext(cctx), cctx.String("user-id"), cctx.Int64("id"))
+	},
+}
+
+var listWebhookDeliveriesCmd = &cli.Command{
+	Name:  "deliveries",
+	Usage: "list the callbacks of the webhook and their delivery status",
+	Flags: []cli.Flag{
+		webhookIDFlag,
+		&cli.StringFlag{
+			Name:  "user-id",
+			Usage: "id of the user of the asset webhook",
+		},
+		limitFlag,
+		offsetFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rsp, err := schedulerAPI.ListWebhookDeliveries(ReqContext(cctx), cctx.String("user-id"), cctx.Int64("id"), cctx.Int("limit"), cctx.Int("offset"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Event"),
+			tablewriter.Col("Hash"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Attempts"),
+			tablewriter.Col("NextTime"),
+			tablewriter.Col("LastError"),
+		)
+
+		for _, d := range rsp.Data {
+			tw.Write(map[string]interface{}{
+				"ID":        d.ID,
+				"Event":     d.Event,
+				"Hash":      d.Hash,
+				"Status":    d.Status,
+				"Attempts":  d.Attempts,
+				"NextTime":  d.NextTime.Format(defaultDateTimeLayout),
+				"LastError": d.LastError,
+			})
+		}
+
+		fmt.Printf("total %d\n", rsp.Total)
+		return tw.Flush(os.Stdout)
+	},
+}
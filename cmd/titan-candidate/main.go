@@ -21,8 +21,11 @@ import (
 
 	"github.com/Filecoin-Titan/titan/node"
 	"github.com/Filecoin-Titan/titan/node/asset"
+	"github.com/Filecoin-Titan/titan/node/candidate"
+	"github.com/Filecoin-Titan/titan/node/device"
 	"github.com/Filecoin-Titan/titan/node/httpserver"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/relay"
 	"github.com/Filecoin-Titan/titan/node/validation"
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/quic-go/quic-go"
@@ -35,10 +38,12 @@ import (
 	"github.com/Filecoin-Titan/titan/build"
 	lcli "github.com/Filecoin-Titan/titan/cli"
 	"github.com/Filecoin-Titan/titan/lib/titanlog"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/control"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	"github.com/Filecoin-Titan/titan/node/repo"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 
@@ -154,6 +159,15 @@ var daemonStartCmd = &cli.Command{
 			Usage: "--url=https://titan-server-domain/rpc/v0",
 			Value: "",
 		},
+		&cli.StringFlag{
+			Name:  "key-type",
+			Usage: "identity key type at first run: rsa or ed25519, negotiated with the scheduler if empty",
+			Value: "",
+		},
+		&cli.BoolFlag{
+			Name:  "sandbox",
+			Usage: "register at first run as an ephemeral test node of a scheduler in the sandbox mode",
+		},
 	},
 
 	Before: func(cctx *cli.Context) error {
@@ -169,6 +183,13 @@ var daemonStartCmd = &cli.Command{
 			log.Fatalf("Cannot register the view: %v", err)
 		}
 
+		// the traces of the scheduler are continued by the node if OTEL_EXPORTER_OTLP_ENDPOINT is set
+		shutdownTracing, err := tracing.Setup(cctx.Context, "titan-candidate", "", 1)
+		if err != nil {
+			return xerrors.Errorf("setup tracing: %w", err)
+		}
+		defer shutdownTracing(context.Background()) //nolint:errcheck
+
 		repoPath := cctx.String(FlagCandidateRepo)
 		r, err := repo.NewFS(repoPath)
 		if err != nil {
@@ -247,14 +268,14 @@ var daemonStartCmd = &cli.Command{
 
 		schedulerURL, _, _ := lcli.GetRawAPI(cctx, repo.Scheduler, "v0")
 		if len(schedulerURL) == 0 {
-			schedulerURL, err = getAccessPoint(cctx, candidateCfg.Network.LocatorURL, nodeID, candidateCfg.AreaID)
+			schedulerURL, err = getAccessPoint(cctx, candidateCfg.Network.LocatorURL, nodeID, candidateCfg.AreaID, candidateCfg.Network.Proxy)
 			if err != nil {
 				return err
 			}
 		}
 
 		// Connect to scheduler
-		schedulerAPI, closer, err := newSchedulerAPI(cctx, transport, schedulerURL, nodeID, privateKey)
+		schedulerAPI, closer, refreshToken, err := newSchedulerAPI(cctx, transport, schedulerURL, nodeID, privateKey, candidateCfg.EnableMTLS, candidateCfg.Network.Proxy)
 		if err != nil {
 			return err
 		}
@@ -317,21 +338,26 @@ var daemonStartCmd = &cli.Command{
 				defer conn.Close() //nolint:errcheck
 				localAddr := conn.LocalAddr().(*net.TCPAddr)
 
-				return dtypes.InternalIP(strings.Split(localAddr.IP.String(), ":")[0]), nil
+				return dtypes.InternalIP(localAddr.IP.String()), nil
 			}),
-			node.Override(node.RunGateway, func(assetMgr *asset.Manager, validation *validation.Validation, apiSecret *jwt.HMACSHA) error {
+			node.Override(node.RunGateway, func(assetMgr *asset.Manager, validation *validation.Validation, apiSecret *jwt.HMACSHA, relayServer *relay.Server) error {
 				opts := &httpserver.HttpServerOptions{
-					Asset: assetMgr, Scheduler: schedulerAPI,
+					NodeID: nodeID,
+					Asset:  assetMgr, Scheduler: schedulerAPI,
 					PrivateKey:          privateKey,
 					Validation:          validation,
 					APISecret:           apiSecret,
 					MaxSizeOfUploadFile: candidateCfg.MaxSizeOfUploadFile,
 					WebRedirect:         candidateCfg.WebRedirect,
+					S3Gateway:           true,
+					Relay:               relayServer,
+
+					VideoPrefetchSegments: candidateCfg.VideoPrefetchSegments,
 				}
 				httpServer = httpserver.NewHttpServer(opts)
 				return nil
 			}),
-			node.Override(new(*rsa.PrivateKey), func() *rsa.PrivateKey {
+			node.Override(new(crypto.Signer), func() crypto.Signer {
 				return privateKey
 			}),
 			node.Override(node.SetApiEndpointKey, func(lr repo.LockedRepo) error {
@@ -362,6 +388,13 @@ var daemonStartCmd = &cli.Command{
 
 		go startHTTP3Server(transport, handler, candidateCfg)
 
+		if len(candidateCfg.GatewayTLSListenAddress) > 0 {
+			go startGatewayTLSServer(ctx, schedulerAPI, handler, candidateCfg.GatewayTLSListenAddress)
+		}
+
+		// the control calls of the scheduler are polled over the connection to the scheduler
+		go control.NewClient(schedulerAPI, candidateAPI).Run(ctx)
+
 		go func() {
 			<-ctx.Done()
 			log.Warn("Shutting down...")
@@ -422,6 +455,8 @@ var daemonStartCmd = &cli.Command{
 			heartbeats := time.NewTicker(HeartbeatInterval)
 			defer heartbeats.Stop()
 
+			metricsCollector := device.NewMetricsCollector()
+
 			var readyCh chan struct{}
 			for {
 				// TODO: we could get rid of this, but that requires tracking resources for restarted tasks correctly
@@ -433,7 +468,7 @@ var daemonStartCmd = &cli.Command{
 				for {
 					select {
 					case <-readyCh:
-						opts := &types.ConnectOptions{ExternalURL: candidateCfg.ExternalURL, Token: token, TcpServerPort: tcpServerPort, IsPrivateMinioOnly: isPrivateMinioOnly(candidateCfg)}
+						opts := &types.ConnectOptions{ExternalURL: candidateCfg.ExternalURL, Token: token, TcpServerPort: tcpServerPort, IsPrivateMinioOnly: isPrivateMinioOnly(candidateCfg), AreaID: candidateCfg.AreaID}
 						err := schedulerAPI.CandidateConnect(ctx, opts)
 						if err != nil {
 							log.Errorf("Registering candidate failed: %s", err.Error())
@@ -451,7 +486,7 @@ var daemonStartCmd = &cli.Command{
 						return
 					}
 
-					curSession, err := keepalive(schedulerAPI, connectTimeout)
+					curSession, err := keepalive(schedulerAPI, metricsCollector, connectTimeout)
 					if err != nil {
 						log.Errorf("heartbeat: keepalive failed: %+v", err)
 						errNode, ok := err.(*api.ErrNode)
@@ -460,8 +495,16 @@ var daemonStartCmd = &cli.Command{
 								cancel()
 								return
 							} else if errNode.Code == int(terrors.NodeIPInconsistent) {
+								// the token is bound to the old ip
+								if err := refreshToken(); err != nil {
+									log.Errorf("refresh token failed: %s", err.Error())
+								}
 								break
 							} else if errNode.Code == int(terrors.NodeOffline) && readyCh == nil {
+								// the ip may change while the node is offline
+								if err := refreshToken(); err != nil {
+									log.Errorf("refresh token failed: %s", err.Error())
+								}
 								break
 							}
 
@@ -492,11 +535,17 @@ func isPrivateMinioOnly(config *config.CandidateCfg) bool {
 	return false
 }
 
-func keepalive(api api.Scheduler, timeout time.Duration) (uuid.UUID, error) {
+func keepalive(schedulerAPI api.Scheduler, collector *device.MetricsCollector, timeout time.Duration) (uuid.UUID, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	return api.NodeKeepaliveV2(ctx)
+	session, err := schedulerAPI.NodeKeepaliveV3(ctx, collector.Collect())
+	if api.IsMethodNotSupported(err) {
+		// the scheduler predates the host metrics
+		return schedulerAPI.NodeKeepaliveV2(ctx)
+	}
+
+	return session, err
 }
 
 func getSchedulerVersion(api api.Scheduler, timeout time.Duration) (api.APIVersion, error) {
@@ -506,25 +555,57 @@ func getSchedulerVersion(api api.Scheduler, timeout time.Duration) (api.APIVersi
 	return api.Version(ctx)
 }
 
-func newAuthTokenFromScheduler(schedulerURL, nodeID string, privateKey *rsa.PrivateKey) (string, error) {
-	schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, nil, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+func newAuthTokenFromScheduler(schedulerURL, nodeID string, privateKey crypto.Signer, proxyURL string) (string, error) {
+	httpClient, err := newHTTPClient(proxyURL)
+	if err != nil {
+		return "", err
+	}
+
+	schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, nil, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
 		return "", err
 	}
 
 	defer closer()
 
-	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	sign, err := rsa.Sign(privateKey, []byte(nodeID))
+	nonce, err := schedulerAPI.NodeLoginChallenge(context.Background(), nodeID)
+	if err != nil {
+		// the scheduler does not support the login challenge yet
+		log.Warnf("NodeLoginChallenge error %s, login without challenge", err.Error())
+
+		sign, err := nodekey.Sign(privateKey, []byte(nodeID))
+		if err != nil {
+			return "", err
+		}
+
+		return schedulerAPI.NodeLogin(context.Background(), nodeID, hex.EncodeToString(sign))
+	}
+
+	req := &types.NodeLoginReq{NodeID: nodeID, Nonce: nonce, Timestamp: time.Now().Unix()}
+	sign, err := nodekey.Sign(privateKey, req.SignContent())
 	if err != nil {
 		return "", err
 	}
+	req.Sign = hex.EncodeToString(sign)
 
-	return schedulerAPI.NodeLogin(context.Background(), nodeID, hex.EncodeToString(sign))
+	return schedulerAPI.NodeLoginV2(context.Background(), req)
+}
+
+// newHTTPClient returns the http3 client, or the tcp client of the outbound proxy if the node connects through a proxy
+func newHTTPClient(proxyURL string) (*http.Client, error) {
+	if proxyURL != "" {
+		return client.NewProxyHTTPClient(proxyURL)
+	}
+	return client.NewHTTP3Client(), nil
 }
 
-func getAccessPoint(cctx *cli.Context, locatorURL, nodeID, areaID string) (string, error) {
-	locator, close, err := client.NewLocator(cctx.Context, locatorURL, nil, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+func getAccessPoint(cctx *cli.Context, locatorURL, nodeID, areaID, proxyURL string) (string, error) {
+	httpClient, err := newHTTPClient(proxyURL)
+	if err != nil {
+		return "", err
+	}
+
+	locator, close, err := client.NewLocator(cctx.Context, locatorURL, nil, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
 		return "", err
 	}
@@ -542,24 +623,79 @@ func getAccessPoint(cctx *cli.Context, locatorURL, nodeID, areaID string) (strin
 	return schedulerURLs[0], nil
 }
 
-func newSchedulerAPI(cctx *cli.Context, tansport *quic.Transport, schedulerURL, nodeID string, privateKey *rsa.PrivateKey) (api.Scheduler, jsonrpc.ClientCloser, error) {
-	token, err := newAuthTokenFromScheduler(schedulerURL, nodeID, privateKey)
+func issueCertFromScheduler(schedulerURL, proxyURL string) candidate.IssueCertFunc {
+	return func(ctx context.Context, nodeID, sign string, csr []byte) (*types.NodeCertificate, error) {
+		httpClient, err := newHTTPClient(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+
+		schedulerAPI, closer, err := client.NewScheduler(ctx, schedulerURL, nil, jsonrpc.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, err
+		}
+		defer closer()
+
+		return schedulerAPI.IssueNodeCertificate(ctx, nodeID, sign, csr)
+	}
+}
+
+func newSchedulerAPI(cctx *cli.Context, tansport *quic.Transport, schedulerURL, nodeID string, privateKey crypto.Signer, enableMTLS bool, proxyURL string) (api.Scheduler, jsonrpc.ClientCloser, func() error, error) {
+	token, err := newAuthTokenFromScheduler(schedulerURL, nodeID, privateKey, proxyURL)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	httpClient, err := client.NewHTTP3ClientWithPacketConn(tansport)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+
+	// the scheduler is reached over tcp through the outbound proxy instead of the quic transport
+	if proxyURL != "" {
+		if httpClient, err = client.NewProxyHTTPClient(proxyURL); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if enableMTLS {
+		certMgr, err := candidate.NewCertManager(cctx.Context, nodeID, privateKey, issueCertFromScheduler(schedulerURL, proxyURL))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		go certMgr.Run(cctx.Context)
+
+		if proxyURL != "" {
+			httpClient, err = client.NewProxyHTTPClientWithTLS(proxyURL, certMgr.TLSConfig())
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		} else {
+			httpClient = client.NewHTTP3ClientWithTLS(tansport, certMgr.TLSConfig())
+		}
 	}
 
 	headers := http.Header{}
-	headers.Add("Authorization", "Bearer "+token)
 	headers.Add("Node-ID", nodeID)
 
+	// the token is bound to the host, it is replaced by login again after the ip of the node changes
+	tokenTransport := client.NewTokenTransport(httpClient, token)
+	refreshToken := func() error {
+		token, err := newAuthTokenFromScheduler(schedulerURL, nodeID, privateKey, proxyURL)
+		if err != nil {
+			return err
+		}
+		tokenTransport.SetToken(token)
+
+		if err := os.Setenv("SCHEDULER_API_INFO", token+":"+schedulerURL); err != nil {
+			log.Errorf("set env error:%s", err.Error())
+		}
+		return nil
+	}
+
 	schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, headers, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	log.Debugf("scheduler url:%s, token:%s", schedulerURL, token)
@@ -568,7 +704,7 @@ func newSchedulerAPI(cctx *cli.Context, tansport *quic.Transport, schedulerURL,
 		log.Errorf("set env error:%s", err.Error())
 	}
 
-	return schedulerAPI, closer, nil
+	return schedulerAPI, closer, refreshToken, nil
 }
 
 func isEnableTLS(candidateCfg *config.CandidateCfg) bool {
@@ -620,12 +756,12 @@ func defaultTLSConfig() (*tls.Config, error) {
 	}, nil
 }
 
-func loadPrivateKey(r *repo.FsRepo) (*rsa.PrivateKey, error) {
+func loadPrivateKey(r *repo.FsRepo) (crypto.Signer, error) {
 	pem, err := r.PrivateKey()
 	if err != nil {
 		return nil, err
 	}
-	return titanrsa.Pem2PrivateKey(pem)
+	return nodekey.Pem2PrivateKey(pem)
 }
 
 func setEndpointAPI(lr repo.LockedRepo, address string) error {
@@ -681,3 +817,33 @@ func startHTTP3Server(transport *quic.Transport, handler http.Handler, config *c
 	}
 	return srv.ServeListener(ln)
 }
+
+// startGatewayTLSServer serves the gateway hostnames over https with the certificate obtained by the scheduler
+func startGatewayTLSServer(ctx context.Context, schedulerAPI api.Scheduler, handler http.Handler, address string) {
+	certMgr := candidate.NewGatewayCertManager(schedulerAPI.GetGatewayCertificate)
+	go certMgr.Run(ctx)
+
+	srv := &http.Server{
+		ReadHeaderTimeout: 30 * time.Second,
+		Handler:           handler,
+		TLSConfig:         certMgr.TLSConfig(),
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.TODO()); err != nil {
+			log.Errorf("shutting down gateway https server failed: %s", err)
+		}
+	}()
+
+	nl, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Errorf("listen gateway https server err:%s", err.Error())
+		return
+	}
+
+	log.Infof("Candidate gateway https listen on %s", address)
+	if err := srv.ServeTLS(nl, "", ""); err != nil && err != http.ErrServerClosed {
+		log.Errorf("gateway https server err:%s", err.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+)
+
+// idleMode requests the idle mode from the scheduler after the edge has no traffic for the idle period,
+// and leaves it once the traffic comes back. The scheduler only grants the idle mode to the edges without replicas
+type idleMode struct {
+	after    time.Duration // disabled if 0
+	spinDown []string
+
+	lastActive time.Time
+	// interval the keepalive interval granted by the scheduler, 0 if not idle
+	interval time.Duration
+}
+
+func newIdleMode(minutes int, spinDownCommand string) *idleMode {
+	return &idleMode{
+		after:      time.Duration(minutes) * time.Minute,
+		spinDown:   strings.Fields(spinDownCommand),
+		lastActive: time.Now(),
+	}
+}
+
+// heartbeatInterval returns the interval the edge sends keepalive at
+func (i *idleMode) heartbeatInterval() time.Duration {
+	if i.interval > 0 {
+		return i.interval
+	}
+	return HeartbeatInterval
+}
+
+// reset leaves the idle mode without telling the scheduler, the scheduler forgets the idle mode when the edge reconnects
+func (i *idleMode) reset() {
+	i.interval = 0
+	i.lastActive = time.Now()
+}
+
+// update enters or leaves the idle mode by the upload rate reported with the keepalive,
+// returns true if the heartbeat interval changes
+func (i *idleMode) update(scheduler api.Scheduler, uploadRate int64, timeout time.Duration) bool {
+	if i.after <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	if uploadRate > 0 {
+		i.lastActive = now
+		if i.interval == 0 {
+			return false
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if _, err := scheduler.SetIdleMode(ctx, false); err != nil {
+			log.Errorf("leave idle mode: %s", err.Error())
+		}
+
+		log.Info("traffic resumed, leave idle mode")
+		i.interval = 0
+		return true
+	}
+
+	if i.interval > 0 || now.Sub(i.lastActive) < i.after {
+		return false
+	}
+
+	// requested at most once an idle period
+	i.lastActive = now
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	mode, err := scheduler.SetIdleMode(ctx, true)
+	if err != nil {
+		log.Errorf("request idle mode: %s", err.Error())
+		return false
+	}
+
+	if !mode.Idle || mode.KeepaliveInterval <= 0 {
+		log.Infof("idle mode denied: %s", mode.Reason)
+		return false
+	}
+
+	i.interval = time.Duration(mode.KeepaliveInterval) * time.Second
+	log.Infof("enter idle mode, keepalive interval %s", i.interval)
+
+	i.spinDownDisk()
+	return true
+}
+
+func (i *idleMode) spinDownDisk() {
+	if len(i.spinDown) == 0 {
+		return
+	}
+
+	if output, err := exec.Command(i.spinDown[0], i.spinDown[1:]...).CombinedOutput(); err != nil {
+		log.Errorf("spin down disk: %s %s", err.Error(), string(output))
+	}
+}
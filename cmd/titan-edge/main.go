@@ -18,10 +18,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Filecoin-Titan/titan/lib/limiter"
 	"github.com/Filecoin-Titan/titan/node"
 	"github.com/Filecoin-Titan/titan/node/asset"
+	"github.com/Filecoin-Titan/titan/node/device"
 	"github.com/Filecoin-Titan/titan/node/httpserver"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/relay"
 	"github.com/Filecoin-Titan/titan/node/validation"
 	"github.com/gbrlsnchs/jwt/v3"
 
@@ -32,10 +35,12 @@ import (
 	"github.com/Filecoin-Titan/titan/build"
 	lcli "github.com/Filecoin-Titan/titan/cli"
 	"github.com/Filecoin-Titan/titan/lib/titanlog"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/control"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	"github.com/Filecoin-Titan/titan/node/repo"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/quic-go/quic-go"
@@ -147,6 +152,15 @@ var daemonStartCmd = &cli.Command{
 			Usage: "--url=https://titan-server-domain/rpc/v0",
 			Value: "",
 		},
+		&cli.StringFlag{
+			Name:  "key-type",
+			Usage: "identity key type at first run: rsa or ed25519, negotiated with the scheduler if empty",
+			Value: "",
+		},
+		&cli.BoolFlag{
+			Name:  "sandbox",
+			Usage: "register at first run as an ephemeral test node of a scheduler in the sandbox mode",
+		},
 	},
 
 	Before: func(cctx *cli.Context) error {
@@ -162,6 +176,13 @@ var daemonStartCmd = &cli.Command{
 			log.Fatalf("Cannot register the view: %v", err)
 		}
 
+		// the traces of the scheduler are continued by the node if OTEL_EXPORTER_OTLP_ENDPOINT is set
+		shutdownTracing, err := tracing.Setup(cctx.Context, "titan-edge", "", 1)
+		if err != nil {
+			return xerrors.Errorf("setup tracing: %w", err)
+		}
+		defer shutdownTracing(context.Background()) //nolint:errcheck
+
 		repoPath := cctx.String(FlagEdgeRepo)
 
 		r, err := repo.NewFS(repoPath)
@@ -243,13 +264,13 @@ var daemonStartCmd = &cli.Command{
 
 		schedulerURL, _, _ := lcli.GetRawAPI(cctx, repo.Scheduler, "v0")
 		if len(schedulerURL) == 0 {
-			schedulerURL, err = getAccessPoint(cctx, edgeCfg.Network.LocatorURL, nodeID, edgeCfg.AreaID)
+			schedulerURL, err = getAccessPoint(cctx, edgeCfg.Network.LocatorURL, nodeID, edgeCfg.AreaID, edgeCfg.Network.Proxy)
 			if err != nil {
 				return err
 			}
 		}
 
-		schedulerAPI, closer, err := newSchedulerAPI(cctx, transport, schedulerURL, nodeID, privateKey)
+		schedulerAPI, closer, refreshToken, err := newSchedulerAPI(cctx, transport, schedulerURL, nodeID, privateKey, edgeCfg.Network.Proxy)
 		if err != nil {
 			return xerrors.Errorf("new scheduler api: %w", err)
 		}
@@ -271,6 +292,7 @@ var daemonStartCmd = &cli.Command{
 
 		var shutdownChan = make(chan struct{})
 		var httpServer *httpserver.HttpServer
+		var uploadShaper *limiter.Shaper
 		var edgeAPI api.Edge
 		stop, err := node.New(cctx.Context,
 			node.Edge(&edgeAPI),
@@ -308,22 +330,30 @@ var daemonStartCmd = &cli.Command{
 				defer conn.Close() //nolint:errcheck
 				localAddr := conn.LocalAddr().(*net.TCPAddr)
 
-				return dtypes.InternalIP(strings.Split(localAddr.IP.String(), ":")[0]), nil
+				return dtypes.InternalIP(localAddr.IP.String()), nil
 			}),
 
-			node.Override(node.RunGateway, func(assetMgr *asset.Manager, validation *validation.Validation, apiSecret *jwt.HMACSHA) error {
+			node.Override(node.RunGateway, func(assetMgr *asset.Manager, validation *validation.Validation, apiSecret *jwt.HMACSHA, shaper *limiter.Shaper) error {
 				opts := &httpserver.HttpServerOptions{
-					Asset: assetMgr, Scheduler: schedulerAPI,
+					NodeID: nodeID,
+					Asset:  assetMgr, Scheduler: schedulerAPI,
 					PrivateKey:          privateKey,
 					Validation:          validation,
 					APISecret:           apiSecret,
 					MaxSizeOfUploadFile: edgeCfg.MaxSizeOfUploadFile,
+					Shaper:              shaper,
+
+					VideoPrefetchSegments: edgeCfg.VideoPrefetchSegments,
 				}
 				httpServer = httpserver.NewHttpServer(opts)
+				uploadShaper = shaper
 
 				return err
 			}),
 
+			node.Override(new(crypto.Signer), func() crypto.Signer {
+				return privateKey
+			}),
 			node.Override(node.SetApiEndpointKey, func(lr repo.LockedRepo) error {
 				return setEndpointAPI(lr, edgeCfg.Network.ListenAddress)
 			}),
@@ -346,6 +376,12 @@ var daemonStartCmd = &cli.Command{
 
 		go startHTTP3Server(transport, handler, edgeCfg)
 
+		// the retrievals of the edge behind symmetric nat are relayed by a candidate
+		go relay.NewClient(schedulerAPI, handler).Run(ctx)
+
+		// the control calls of the scheduler are polled over the connection to the scheduler
+		go control.NewClient(schedulerAPI, edgeAPI).Run(ctx)
+
 		go func() {
 			<-ctx.Done()
 			log.Warn("Shutting down...")
@@ -392,10 +428,20 @@ var daemonStartCmd = &cli.Command{
 			return out
 		}
 
+		// the health of the disk holding the assets is reported with keepalive
+		storagePath := edgeCfg.Storage.Path
+		if len(storagePath) == 0 {
+			storagePath = path.Join(lr.Path(), DefaultStorageDir)
+		}
+
 		go func() {
 			heartbeats := time.NewTicker(HeartbeatInterval)
 			defer heartbeats.Stop()
 
+			metricsCollector := device.NewMetricsCollector()
+			metricsCollector.WatchDiskHealth(storagePath)
+			idle := newIdleMode(edgeCfg.IdleMinutes, edgeCfg.IdleSpinDownCommand)
+
 			var readyCh chan struct{}
 			for {
 				// TODO: we could get rid of this, but that requires tracking resources for restarted tasks correctly
@@ -407,7 +453,7 @@ var daemonStartCmd = &cli.Command{
 				for {
 					select {
 					case <-readyCh:
-						opts := &types.ConnectOptions{Token: token}
+						opts := &types.ConnectOptions{Token: token, AreaID: edgeCfg.AreaID}
 						if err := schedulerAPI.EdgeConnect(ctx, opts); err != nil {
 							log.Errorf("Registering edge failed: %s", err.Error())
 							cancel()
@@ -416,6 +462,9 @@ var daemonStartCmd = &cli.Command{
 
 						log.Info("Edge registered successfully, waiting for tasks")
 						readyCh = nil
+
+						idle.reset()
+						heartbeats.Reset(idle.heartbeatInterval())
 					case <-heartbeats.C:
 					case <-ctx.Done():
 						return // graceful shutdown
@@ -424,7 +473,8 @@ var daemonStartCmd = &cli.Command{
 						return
 					}
 
-					curSession, err := keepalive(schedulerAPI, connectTimeout)
+					curSession, err := keepalive(schedulerAPI, metricsCollector, uploadShaper, idle, connectTimeout)
+					heartbeats.Reset(idle.heartbeatInterval())
 					if err != nil {
 						log.Errorf("heartbeat: keepalive failed: %+v", err)
 						errNode, ok := err.(*api.ErrNode)
@@ -433,8 +483,16 @@ var daemonStartCmd = &cli.Command{
 								cancel()
 								return
 							} else if errNode.Code == int(terrors.NodeIPInconsistent) {
+								// the token is bound to the old ip
+								if err := refreshToken(); err != nil {
+									log.Errorf("refresh token failed: %s", err.Error())
+								}
 								break
 							} else if errNode.Code == int(terrors.NodeOffline) && readyCh == nil {
+								// the ip may change while the node is offline
+								if err := refreshToken(); err != nil {
+									log.Errorf("refresh token failed: %s", err.Error())
+								}
 								break
 							}
 						}
@@ -454,11 +512,30 @@ var daemonStartCmd = &cli.Command{
 	},
 }
 
-func keepalive(api api.Scheduler, timeout time.Duration) (uuid.UUID, error) {
+func keepalive(schedulerAPI api.Scheduler, collector *device.MetricsCollector, shaper *limiter.Shaper, idle *idleMode, timeout time.Duration) (uuid.UUID, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	return api.NodeKeepaliveV2(ctx)
+	// report the upload limit in force and the upload rate, so the scheduler can check the compliance
+	metrics := collector.Collect()
+	metrics.UploadLimit = shaper.Limit()
+	metrics.UploadRate = shaper.Rate()
+
+	session, err := schedulerAPI.NodeKeepaliveV3(ctx, metrics)
+	if api.IsMethodNotSupported(err) {
+		// the scheduler predates the host metrics
+		session, err = schedulerAPI.NodeKeepaliveV2(ctx)
+	}
+	if err != nil {
+		// the idle mode is not kept by the scheduler once the edge goes offline
+		idle.reset()
+		return session, err
+	}
+
+	// the idle mode follows the upload rate of the keepalive
+	idle.update(schedulerAPI, metrics.UploadRate, timeout)
+
+	return session, nil
 }
 
 func getSchedulerVersion(api api.Scheduler, timeout time.Duration) (api.APIVersion, error) {
@@ -468,25 +545,57 @@ func getSchedulerVersion(api api.Scheduler, timeout time.Duration) (api.APIVersi
 	return api.Version(ctx)
 }
 
-func newAuthTokenFromScheduler(schedulerURL, nodeID string, privateKey *rsa.PrivateKey) (string, error) {
-	schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, nil, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+func newAuthTokenFromScheduler(schedulerURL, nodeID string, privateKey crypto.Signer, proxyURL string) (string, error) {
+	httpClient, err := newHTTPClient(proxyURL)
+	if err != nil {
+		return "", err
+	}
+
+	schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, nil, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
 		return "", err
 	}
 
 	defer closer()
 
-	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	sign, err := rsa.Sign(privateKey, []byte(nodeID))
+	nonce, err := schedulerAPI.NodeLoginChallenge(context.Background(), nodeID)
+	if err != nil {
+		// the scheduler does not support the login challenge yet
+		log.Warnf("NodeLoginChallenge error %s, login without challenge", err.Error())
+
+		sign, err := nodekey.Sign(privateKey, []byte(nodeID))
+		if err != nil {
+			return "", err
+		}
+
+		return schedulerAPI.NodeLogin(context.Background(), nodeID, hex.EncodeToString(sign))
+	}
+
+	req := &types.NodeLoginReq{NodeID: nodeID, Nonce: nonce, Timestamp: time.Now().Unix()}
+	sign, err := nodekey.Sign(privateKey, req.SignContent())
 	if err != nil {
 		return "", err
 	}
+	req.Sign = hex.EncodeToString(sign)
+
+	return schedulerAPI.NodeLoginV2(context.Background(), req)
+}
 
-	return schedulerAPI.NodeLogin(context.Background(), nodeID, hex.EncodeToString(sign))
+// newHTTPClient returns the http3 client, or the tcp client of the outbound proxy if the node connects through a proxy
+func newHTTPClient(proxyURL string) (*http.Client, error) {
+	if proxyURL != "" {
+		return client.NewProxyHTTPClient(proxyURL)
+	}
+	return client.NewHTTP3Client(), nil
 }
 
-func getAccessPoint(cctx *cli.Context, locatorURL, nodeID, areaID string) (string, error) {
-	locator, close, err := client.NewLocator(cctx.Context, locatorURL, nil, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+func getAccessPoint(cctx *cli.Context, locatorURL, nodeID, areaID, proxyURL string) (string, error) {
+	httpClient, err := newHTTPClient(proxyURL)
+	if err != nil {
+		return "", err
+	}
+
+	locator, close, err := client.NewLocator(cctx.Context, locatorURL, nil, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
 		return "", err
 	}
@@ -504,28 +613,45 @@ func getAccessPoint(cctx *cli.Context, locatorURL, nodeID, areaID string) (strin
 	return schedulerURLs[0], nil
 }
 
-func newSchedulerAPI(cctx *cli.Context, transport *quic.Transport, schedulerURL, nodeID string, privateKey *rsa.PrivateKey) (api.Scheduler, jsonrpc.ClientCloser, error) {
-	token, err := newAuthTokenFromScheduler(schedulerURL, nodeID, privateKey)
+func newSchedulerAPI(cctx *cli.Context, transport *quic.Transport, schedulerURL, nodeID string, privateKey crypto.Signer, proxyURL string) (api.Scheduler, jsonrpc.ClientCloser, func() error, error) {
+	token, err := newAuthTokenFromScheduler(schedulerURL, nodeID, privateKey, proxyURL)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	httpClient, err := client.NewHTTP3ClientWithPacketConn(transport)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+
+	// the scheduler is reached over tcp through the outbound proxy instead of the quic transport
+	if proxyURL != "" {
+		if httpClient, err = client.NewProxyHTTPClient(proxyURL); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	headers := http.Header{}
-	headers.Add("Authorization", "Bearer "+token)
 	headers.Add("Node-ID", nodeID)
 
+	// the token is bound to the host, it is replaced by login again after the ip of the node changes
+	tokenTransport := client.NewTokenTransport(httpClient, token)
+	refreshToken := func() error {
+		token, err := newAuthTokenFromScheduler(schedulerURL, nodeID, privateKey, proxyURL)
+		if err != nil {
+			return err
+		}
+		tokenTransport.SetToken(token)
+		return nil
+	}
+
 	schedulerAPI, closer, err := client.NewScheduler(context.Background(), schedulerURL, headers, jsonrpc.WithHTTPClient(httpClient))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	log.Debugf("scheduler url:%s, token:%s", schedulerURL, token)
 
-	return schedulerAPI, closer, nil
+	return schedulerAPI, closer, refreshToken, nil
 }
 
 func defaultTLSConfig() (*tls.Config, error) {
@@ -553,12 +679,12 @@ func defaultTLSConfig() (*tls.Config, error) {
 	}, nil
 }
 
-func loadPrivateKey(r *repo.FsRepo) (*rsa.PrivateKey, error) {
+func loadPrivateKey(r *repo.FsRepo) (crypto.Signer, error) {
 	pem, err := r.PrivateKey()
 	if err != nil {
 		return nil, err
 	}
-	return titanrsa.Pem2PrivateKey(pem)
+	return nodekey.Pem2PrivateKey(pem)
 }
 
 func setEndpointAPI(lr repo.LockedRepo, address string) error {
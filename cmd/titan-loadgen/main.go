@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/build"
+	"github.com/Filecoin-Titan/titan/lib/titanlog"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("main")
+
+const (
+	keepaliveInterval = 10 * time.Second
+	churnInterval     = 10 * time.Second
+)
+
+func main() {
+	titanlog.SetupLogLevels()
+
+	app := &cli.App{
+		Name:    "titan-loadgen",
+		Usage:   "Connect synthetic edges and candidates to a scheduler and report how it copes, to size deployments",
+		Version: build.UserVersion(),
+		Description: "The synthetic nodes register, log in, connect and keep alive like real nodes and answer the calls of the scheduler.\n" +
+			"All the nodes share the ip of this host, add it to IPWhitelist and raise IPLimit in the scheduler config before a run.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "scheduler-url",
+				Usage:    "rpc url of the scheduler, e.g. https://127.0.0.1:3456/rpc/v0",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "admin-token",
+				Usage: "admin token of the scheduler to read its runtime diagnostics, the scheduler side of the report is skipped if empty",
+			},
+			&cli.IntFlag{
+				Name:  "edges",
+				Usage: "number of synthetic edges",
+				Value: 1000,
+			},
+			&cli.IntFlag{
+				Name:  "candidates",
+				Usage: "number of synthetic candidates, at least 2 are needed for the scheduler to detect the nat type of the edges",
+				Value: 10,
+			},
+			&cli.IntFlag{
+				Name:  "connect-rate",
+				Usage: "nodes connected per second while ramping up",
+				Value: 50,
+			},
+			&cli.Float64Flag{
+				Name:  "churn",
+				Usage: "fraction of the online nodes disconnecting per minute",
+				Value: 0.02,
+			},
+			&cli.DurationFlag{
+				Name:  "offline-time",
+				Usage: "average time the disconnected nodes stay offline before connecting again",
+				Value: 2 * time.Minute,
+			},
+			&cli.StringFlag{
+				Name:  "bandwidth",
+				Usage: "range of the download bandwidth of the nodes in MiB/s, the nodes are spread uniformly",
+				Value: "10-200",
+			},
+			&cli.StringFlag{
+				Name:  "nat",
+				Usage: "weights of the nat types of the edges, types: none, fullcone, restricted, symmetric",
+				Value: "none=4,fullcone=3,restricted=2,symmetric=1",
+			},
+			&cli.StringFlag{
+				Name:  "listen-ip",
+				Usage: "ip the synthetic nodes listen on, it must be reachable from the scheduler",
+				Value: "0.0.0.0",
+			},
+			&cli.DurationFlag{
+				Name:  "report-interval",
+				Usage: "interval of the reports",
+				Value: 30 * time.Second,
+			},
+			&cli.DurationFlag{
+				Name:  "duration",
+				Usage: "how long to run, until interrupted if 0",
+			},
+		},
+		Action: run,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+}
+
+func run(cctx *cli.Context) error {
+	minBandwidth, maxBandwidth, err := parseRange(cctx.String("bandwidth"))
+	if err != nil {
+		return xerrors.Errorf("parse bandwidth: %w", err)
+	}
+
+	nats, err := parseNATWeights(cctx.String("nat"))
+	if err != nil {
+		return xerrors.Errorf("parse nat: %w", err)
+	}
+
+	tlsConfig, err := defaultTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(cctx.Context)
+	defer cancel()
+
+	if d := cctx.Duration("duration"); d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	f := newFleet(cctx.String("scheduler-url"), cctx.String("listen-ip"), tlsConfig)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	nodes := make([]*simNode, 0, cctx.Int("edges")+cctx.Int("candidates"))
+	// the candidates first, they are needed by the nat detection of the edges
+	for i := 0; i < cctx.Int("candidates"); i++ {
+		n, err := f.newNode(types.NodeCandidate, types.NatTypeNo, randomBandwidth(r, minBandwidth, maxBandwidth))
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+	}
+	for i := 0; i < cctx.Int("edges"); i++ {
+		n, err := f.newNode(types.NodeEdge, nats.pick(r), randomBandwidth(r, minBandwidth, maxBandwidth))
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+	}
+
+	rep, err := newReporter(ctx, f, cctx.String("scheduler-url"), cctx.String("admin-token"))
+	if err != nil {
+		return err
+	}
+	go rep.run(ctx, cctx.Duration("report-interval"))
+
+	wg := &sync.WaitGroup{}
+	rate := cctx.Int("connect-rate")
+	if rate <= 0 {
+		rate = 1
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	for _, n := range nodes {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		}
+
+		wg.Add(1)
+		go func(n *simNode) {
+			defer wg.Done()
+			n.run(ctx)
+		}(n)
+	}
+
+	log.Infof("%d nodes started", len(nodes))
+
+	f.churn(ctx, cctx.Float64("churn"), cctx.Duration("offline-time"))
+
+	wg.Wait()
+	rep.report(context.Background())
+	return nil
+}
+
+// parseRange parses min-max
+func parseRange(s string) (int64, int64, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("range %s is not min-max", s)
+	}
+
+	min, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	max, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("range %s is invalid", s)
+	}
+
+	return min, max, nil
+}
+
+func randomBandwidth(r *rand.Rand, min, max int64) int64 {
+	mib := min
+	if max > min {
+		mib += r.Int63n(max - min + 1)
+	}
+
+	return mib << 20
+}
+
+type natWeight struct {
+	natType types.NatType
+	weight  int
+}
+
+type natWeights []natWeight
+
+var natNames = map[string]types.NatType{
+	"none":       types.NatTypeNo,
+	"fullcone":   types.NatTypeFullCone,
+	"restricted": types.NatTypeRestricted,
+	"symmetric":  types.NatTypeSymmetric,
+}
+
+// parseNATWeights parses type=weight pairs separated by commas
+func parseNATWeights(s string) (natWeights, error) {
+	weights := make(natWeights, 0)
+	total := 0
+
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.Split(strings.TrimSpace(pair), "=")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("nat weight %s is not type=weight", pair)
+		}
+
+		natType, ok := natNames[kv[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown nat type %s", kv[0])
+		}
+
+		weight, err := strconv.Atoi(kv[1])
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("nat weight %s is invalid", kv[1])
+		}
+
+		weights = append(weights, natWeight{natType: natType, weight: weight})
+		total += weight
+	}
+
+	if total == 0 {
+		return nil, fmt.Errorf("the nat weights sum to 0")
+	}
+
+	return weights, nil
+}
+
+func (w natWeights) pick(r *rand.Rand) types.NatType {
+	total := 0
+	for _, nw := range w {
+		total += nw.weight
+	}
+
+	n := r.Intn(total)
+	for _, nw := range w {
+		if n < nw.weight {
+			return nw.natType
+		}
+		n -= nw.weight
+	}
+
+	return w[len(w)-1].natType
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/xerrors"
+)
+
+// fleet the synthetic nodes sharing the scheduler and the stats
+type fleet struct {
+	schedulerURL string
+	listenIP     string
+	tlsConfig    *tls.Config
+	stats        *stats
+
+	lk     sync.RWMutex
+	nodes  []*simNode
+	byAddr map[string]*simNode // the nodes by the external address the scheduler sees
+}
+
+func newFleet(schedulerURL, listenIP string, tlsConfig *tls.Config) *fleet {
+	return &fleet{
+		schedulerURL: schedulerURL,
+		listenIP:     listenIP,
+		tlsConfig:    tlsConfig,
+		stats:        newStats(),
+		byAddr:       make(map[string]*simNode),
+	}
+}
+
+func (f *fleet) newNode(nodeType types.NodeType, natType types.NatType, bandwidthDown int64) (*simNode, error) {
+	key, err := nodekey.Generate(nodekey.TypeEd25519, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "e_"
+	if nodeType == types.NodeCandidate {
+		prefix = "c_"
+	}
+	nodeID, err := nodekey.NodeID(prefix, key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	n := &simNode{
+		fleet:    f,
+		nodeID:   nodeID,
+		nodeType: nodeType,
+		natType:  natType,
+		key:      key,
+		info: types.NodeInfo{
+			Type:               nodeType,
+			NodeName:           "loadgen",
+			SystemVersion:      "loadgen",
+			DiskSpace:          500 << 30,
+			AvailableDiskSpace: 400 << 30,
+			Memory:             8 << 30,
+			CPUCores:           4,
+			BandwidthDown:      bandwidthDown,
+			NodeDynamicInfo:    types.NodeDynamicInfo{NodeID: nodeID},
+		},
+	}
+
+	f.lk.Lock()
+	f.nodes = append(f.nodes, n)
+	f.lk.Unlock()
+
+	return n, nil
+}
+
+func (f *fleet) setAddr(n *simNode, addr string) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	f.byAddr[addr] = n
+}
+
+func (f *fleet) removeAddr(addr string) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	delete(f.byAddr, addr)
+}
+
+func (f *fleet) nodeOfAddr(addr string) *simNode {
+	f.lk.RLock()
+	defer f.lk.RUnlock()
+
+	return f.byAddr[addr]
+}
+
+func (f *fleet) online() int {
+	f.lk.RLock()
+	defer f.lk.RUnlock()
+
+	count := 0
+	for _, n := range f.nodes {
+		if n.isOnline() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// churn disconnects the online nodes at the rate per minute until ctx is done,
+// the nodes stay offline for a random time around offlineTime
+func (f *fleet) churn(ctx context.Context, rate float64, offlineTime time.Duration) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	p := rate * float64(churnInterval) / float64(time.Minute)
+
+	ticker := time.NewTicker(churnInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if p <= 0 {
+			continue
+		}
+
+		f.lk.RLock()
+		nodes := append([]*simNode(nil), f.nodes...)
+		f.lk.RUnlock()
+
+		for _, n := range nodes {
+			if r.Float64() < p {
+				offline := offlineTime/2 + time.Duration(r.Int63n(int64(offlineTime)+1))
+				n.disconnect(offline)
+			}
+		}
+	}
+}
+
+// simNode a synthetic node, it serves the calls of the scheduler on the udp socket it connects to the scheduler with,
+// like the real nodes do
+type simNode struct {
+	fleet    *fleet
+	nodeID   string
+	nodeType types.NodeType
+	natType  types.NatType
+	key      crypto.Signer
+	info     types.NodeInfo
+
+	registered bool
+
+	lk           sync.Mutex
+	conn         net.PacketConn
+	transport    *quic.Transport
+	srv          *http3.Server
+	closer       jsonrpc.ClientCloser
+	externalAddr string
+	stop         chan struct{}
+	offlineTime  time.Duration
+	connected    bool
+}
+
+// run connects the node and keeps it alive until ctx is done, it connects again after it is disconnected
+func (n *simNode) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		schedulerAPI, err := n.connect(ctx)
+		if err != nil {
+			log.Warnf("node %s connect: %s", n.nodeID, err.Error())
+			n.close()
+			sleep(ctx, keepaliveInterval)
+			continue
+		}
+
+		n.keepalive(ctx, schedulerAPI)
+		n.close()
+
+		n.lk.Lock()
+		offline := n.offlineTime
+		n.offlineTime = 0
+		n.lk.Unlock()
+
+		sleep(ctx, offline)
+	}
+}
+
+func (n *simNode) connect(ctx context.Context) (api.Scheduler, error) {
+	start := time.Now()
+
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(n.fleet.listenIP, "0"))
+	if err != nil {
+		return nil, err
+	}
+	transport := &quic.Transport{Conn: conn}
+
+	ln, err := transport.ListenEarly(n.fleet.tlsConfig, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	srv := &http3.Server{TLSConfig: n.fleet.tlsConfig, Handler: n.handler()}
+	go srv.ServeListener(ln) //nolint:errcheck // closed on disconnect
+
+	n.lk.Lock()
+	n.conn, n.transport, n.srv = conn, transport, srv
+	n.stop = make(chan struct{})
+	n.lk.Unlock()
+
+	httpClient, err := client.NewHTTP3ClientWithPacketConn(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := n.login(ctx, httpClient)
+	if err != nil {
+		n.fleet.stats.connected(time.Since(start), err)
+		return nil, xerrors.Errorf("login: %w", err)
+	}
+
+	client.NewTokenTransport(httpClient, token)
+
+	headers := http.Header{}
+	headers.Add("Node-ID", n.nodeID)
+
+	schedulerAPI, closer, err := client.NewScheduler(ctx, n.fleet.schedulerURL, headers, jsonrpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+
+	n.lk.Lock()
+	n.closer = closer
+	n.lk.Unlock()
+
+	addr, err := schedulerAPI.GetExternalAddress(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get external address: %w", err)
+	}
+
+	n.lk.Lock()
+	n.externalAddr = addr
+	n.lk.Unlock()
+	n.fleet.setAddr(n, addr)
+
+	opts := &types.ConnectOptions{Token: "loadgen"}
+	if n.nodeType == types.NodeCandidate {
+		err = schedulerAPI.CandidateConnect(ctx, opts)
+	} else {
+		err = schedulerAPI.EdgeConnect(ctx, opts)
+	}
+	n.fleet.stats.connected(time.Since(start), err)
+	if err != nil {
+		return nil, xerrors.Errorf("connect: %w", err)
+	}
+
+	n.lk.Lock()
+	n.connected = true
+	n.lk.Unlock()
+
+	return schedulerAPI, nil
+}
+
+// login registers the node on its first login and returns the token signed by the scheduler
+func (n *simNode) login(ctx context.Context, httpClient *http.Client) (string, error) {
+	schedulerAPI, closer, err := client.NewScheduler(ctx, n.fleet.schedulerURL, nil, jsonrpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return "", err
+	}
+	defer closer()
+
+	if !n.registered {
+		pem, err := nodekey.PublicKey2Pem(n.key.Public())
+		if err != nil {
+			return "", err
+		}
+
+		if _, err = schedulerAPI.RegisterNode(ctx, n.nodeID, string(pem), n.nodeType); err != nil {
+			return "", xerrors.Errorf("register: %w", err)
+		}
+		n.registered = true
+	}
+
+	nonce, err := schedulerAPI.NodeLoginChallenge(ctx, n.nodeID)
+	if err != nil {
+		// the scheduler does not support the login challenge
+		sign, err := nodekey.Sign(n.key, []byte(n.nodeID))
+		if err != nil {
+			return "", err
+		}
+
+		return schedulerAPI.NodeLogin(ctx, n.nodeID, hex.EncodeToString(sign))
+	}
+
+	req := &types.NodeLoginReq{NodeID: n.nodeID, Nonce: nonce, Timestamp: time.Now().Unix()}
+	sign, err := nodekey.Sign(n.key, req.SignContent())
+	if err != nil {
+		return "", err
+	}
+	req.Sign = hex.EncodeToString(sign)
+
+	return schedulerAPI.NodeLoginV2(ctx, req)
+}
+
+// keepalive sends the keepalives until the node is disconnected, the scheduler takes it offline or ctx is done
+func (n *simNode) keepalive(ctx context.Context, schedulerAPI api.Scheduler) {
+	n.lk.Lock()
+	stop := n.stop
+	n.lk.Unlock()
+
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		start := time.Now()
+		_, err := schedulerAPI.NodeKeepaliveV3(ctx, &types.HostMetrics{CPULoad: 20, MemoryPressure: 30})
+		n.fleet.stats.keptAlive(time.Since(start), err)
+		if err != nil {
+			log.Debugf("node %s keepalive: %s", n.nodeID, err.Error())
+			if errNode, ok := err.(*api.ErrNode); ok && errNode.Code != 0 {
+				// the scheduler took the node offline, connect again
+				return
+			}
+		}
+	}
+}
+
+// disconnect stops the keepalives of the online node, it connects again after the offline time
+func (n *simNode) disconnect(offline time.Duration) {
+	n.lk.Lock()
+	defer n.lk.Unlock()
+
+	if !n.connected {
+		return
+	}
+
+	select {
+	case <-n.stop:
+		return
+	default:
+	}
+
+	n.offlineTime = offline
+	close(n.stop)
+	n.fleet.stats.disconnected()
+}
+
+func (n *simNode) isOnline() bool {
+	n.lk.Lock()
+	defer n.lk.Unlock()
+
+	if !n.connected {
+		return false
+	}
+
+	select {
+	case <-n.stop:
+		return false
+	default:
+		return true
+	}
+}
+
+// close releases the connection to the scheduler and the socket of the node
+func (n *simNode) close() {
+	n.lk.Lock()
+	defer n.lk.Unlock()
+
+	if n.closer != nil {
+		n.closer()
+		n.closer = nil
+	}
+	if n.srv != nil {
+		n.srv.Close() //nolint:errcheck // ignore error
+		n.srv = nil
+	}
+	if n.transport != nil {
+		n.transport.Close() //nolint:errcheck // ignore error
+		n.transport = nil
+	}
+	if n.conn != nil {
+		n.conn.Close() //nolint:errcheck // ignore error
+		n.conn = nil
+	}
+	if n.externalAddr != "" {
+		n.fleet.removeAddr(n.externalAddr)
+		n.externalAddr = ""
+	}
+	n.stop = nil
+	n.connected = false
+}
+
+// handler serves the calls of the scheduler, the calls not simulated return api.ErrNotSupported
+func (n *simNode) handler() http.Handler {
+	rpcServer := jsonrpc.NewServer(jsonrpc.WithServerErrors(api.RPCErrors))
+
+	getNodeInfo := func(ctx context.Context) (types.NodeInfo, error) {
+		return n.info, nil
+	}
+	getNodeID := func(ctx context.Context) (string, error) {
+		return n.nodeID, nil
+	}
+
+	if n.nodeType == types.NodeCandidate {
+		s := new(api.CandidateStruct)
+		s.DeviceStruct.Internal.GetNodeInfo = getNodeInfo
+		s.DeviceStruct.Internal.GetNodeID = getNodeID
+		s.Internal.CheckNetworkConnectivity = n.checkNetworkConnectivity
+		rpcServer.Register("titan", s)
+	} else {
+		s := new(api.EdgeStruct)
+		s.DeviceStruct.Internal.GetNodeInfo = getNodeInfo
+		s.DeviceStruct.Internal.GetNodeID = getNodeID
+		s.Internal.ExternalServiceAddress = n.externalServiceAddress
+		rpcServer.Register("titan", s)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/rpc/v0", rpcServer)
+	return mux
+}
+
+// externalServiceAddress returns the address the candidate sees, the symmetric nats map it to another port
+func (n *simNode) externalServiceAddress(ctx context.Context, candidateURL string) (string, error) {
+	n.lk.Lock()
+	addr := n.externalAddr
+	n.lk.Unlock()
+
+	if n.natType != types.NatTypeSymmetric {
+		return addr, nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(p%65535+1)), nil
+}
+
+// checkNetworkConnectivity answers the nat detection of the scheduler by the nat type of the target node
+func (n *simNode) checkNetworkConnectivity(ctx context.Context, network, targetURL string) error {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return err
+	}
+
+	target := n.fleet.nodeOfAddr(u.Host)
+	if target == nil {
+		return fmt.Errorf("%s is not reachable", u.Host)
+	}
+
+	switch target.natType {
+	case types.NatTypeNo:
+		return nil
+	case types.NatTypeFullCone:
+		if network == "udp" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s %s is not reachable", network, u.Host)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
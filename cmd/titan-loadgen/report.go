@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/filecoin-project/go-jsonrpc"
+)
+
+// latency the count, the failures, the sum and the max of the durations of the calls
+type latency struct {
+	count    int64
+	failures int64
+	sum      time.Duration
+	max      time.Duration
+}
+
+func (l *latency) observe(d time.Duration, err error) {
+	l.count++
+	if err != nil {
+		l.failures++
+	}
+	l.sum += d
+	if d > l.max {
+		l.max = d
+	}
+}
+
+func (l latency) String() string {
+	avg := time.Duration(0)
+	if l.count > 0 {
+		avg = l.sum / time.Duration(l.count)
+	}
+
+	return fmt.Sprintf("%d calls, %d failed, avg %s, max %s", l.count, l.failures, avg.Round(time.Millisecond), l.max.Round(time.Millisecond))
+}
+
+// snapshot the calls of the synthetic nodes since the last report
+type snapshot struct {
+	connects      latency
+	keepalives    latency
+	disconnects   int64
+	sinceTime     time.Time
+	totalConnects int64
+}
+
+// stats collects the calls of the synthetic nodes
+type stats struct {
+	lk sync.Mutex
+	snapshot
+}
+
+func newStats() *stats {
+	return &stats{snapshot: snapshot{sinceTime: time.Now()}}
+}
+
+func (s *stats) connected(d time.Duration, err error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.connects.observe(d, err)
+	if err == nil {
+		s.totalConnects++
+	}
+}
+
+func (s *stats) keptAlive(d time.Duration, err error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.keepalives.observe(d, err)
+}
+
+func (s *stats) disconnected() {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.disconnects++
+}
+
+// reset returns the stats since the last reset
+func (s *stats) reset() snapshot {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	snapshot := s.snapshot
+
+	s.connects = latency{}
+	s.keepalives = latency{}
+	s.disconnects = 0
+	s.sinceTime = time.Now()
+
+	return snapshot
+}
+
+// reporter prints the stats of the nodes and the runtime diagnostics of the scheduler
+type reporter struct {
+	fleet *fleet
+	admin api.Scheduler
+}
+
+func newReporter(ctx context.Context, f *fleet, schedulerURL, adminToken string) (*reporter, error) {
+	r := &reporter{fleet: f}
+	if adminToken == "" {
+		return r, nil
+	}
+
+	headers := http.Header{}
+	headers.Add("Authorization", "Bearer "+adminToken)
+
+	admin, _, err := client.NewScheduler(ctx, schedulerURL, headers, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+	if err != nil {
+		return nil, err
+	}
+	r.admin = admin
+
+	return r, nil
+}
+
+func (r *reporter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *reporter) report(ctx context.Context) {
+	s := r.fleet.stats.reset()
+
+	fmt.Printf("==== %s, %s since the last report\n", time.Now().Format(time.RFC3339), time.Since(s.sinceTime).Round(time.Second))
+	fmt.Printf("nodes online: %d, connected in total: %d, disconnected by churn: %d\n", r.fleet.online(), s.totalConnects, s.disconnects)
+	fmt.Printf("connects:   %s\n", s.connects)
+	fmt.Printf("keepalives: %s\n", s.keepalives)
+
+	if r.admin == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	d, err := r.admin.GetRuntimeDiagnostics(ctx)
+	if err != nil {
+		fmt.Printf("scheduler diagnostics: %s\n", err.Error())
+		return
+	}
+
+	online := 0
+	for _, size := range d.Registries {
+		if (size.Name == "node.edges" || size.Name == "node.candidates") && size.Size > 0 {
+			online += size.Size
+		}
+	}
+
+	fmt.Printf("scheduler nodes online: %d, goroutines: %d, heap in use: %d MiB, sys: %d MiB, gc pause: %s\n",
+		online, d.Goroutines, d.HeapInuse>>20, d.Sys>>20, d.GCPauseTotal.Round(time.Millisecond))
+
+	for _, t := range d.Timers {
+		switch t.Name {
+		case "node.keepalive":
+			fmt.Printf("keepalive sweep: last %s, runs %d\n", t.LastDuration.Round(time.Millisecond), t.Runs)
+		case "node.save_snapshots":
+			fmt.Printf("node info save: last %s, %s\n", t.LastDuration.Round(time.Millisecond), throughput(online, t))
+		}
+	}
+}
+
+// throughput returns the rows written per second by the last save of the node information
+func throughput(rows int, t *types.TimerStatus) string {
+	if t.LastDuration <= 0 {
+		return "no save yet"
+	}
+
+	return fmt.Sprintf("%.0f rows/s", float64(rows)/t.LastDuration.Seconds())
+}
+
+func defaultTLSConfig() (*tls.Config, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	template := x509.Certificate{SerialNumber: big.NewInt(1)}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+
+	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		Certificates:       []tls.Certificate{tlsCert},
+		NextProtos:         []string{"h2", "h3"},
+		InsecureSkipVerify: true, //nolint:gosec // skip verify in default config
+	}, nil
+}
@@ -167,7 +167,7 @@ var runCmd = &cli.Command{
 		}
 
 		// Instantiate the locator node handler.
-		handler, err := node.LocatorHandler(locatorAPI, true)
+		handler, err := node.LocatorHandler(locatorAPI, true, locatorCfg.GatewayRedirect, locatorCfg.GatewayRateLimit)
 		if err != nil {
 			return xerrors.Errorf("failed to instantiate rpc handler: %w", err)
 		}
@@ -20,15 +20,22 @@ import (
 	"github.com/Filecoin-Titan/titan/build"
 	lcli "github.com/Filecoin-Titan/titan/cli"
 	"github.com/Filecoin-Titan/titan/lib/titanlog"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/repo"
+	"github.com/Filecoin-Titan/titan/node/scheduler"
+	"github.com/Filecoin-Titan/titan/node/scheduler/audit"
+	"github.com/Filecoin-Titan/titan/node/scheduler/ca"
+	"github.com/Filecoin-Titan/titan/node/scheduler/grpcserver"
 	"github.com/Filecoin-Titan/titan/node/secret"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
+	"go.opencensus.io/stats/view"
 	"golang.org/x/xerrors"
 
 	logging "github.com/ipfs/go-log/v2"
@@ -181,6 +188,18 @@ var runCmd = &cli.Command{
 	Action: func(cctx *cli.Context) error {
 		log.Info("Starting titan scheduler node")
 
+		// Register all metric views
+		if err := view.Register(
+			metrics.DefaultViews...,
+		); err != nil {
+			log.Fatalf("Cannot register the view: %v", err)
+		}
+		if err := view.Register(
+			metrics.SchedulerViews...,
+		); err != nil {
+			log.Fatalf("Cannot register the view: %v", err)
+		}
+
 		repoPath := cctx.String(FlagSchedulerRepo)
 		r, err := repo.NewFS(repoPath)
 		if err != nil {
@@ -214,6 +233,12 @@ var runCmd = &cli.Command{
 			return err
 		}
 
+		shutdownTracing, err := tracing.Setup(cctx.Context, "titan-scheduler", schedulerCfg.TracingEndpoint, schedulerCfg.TracingSampleRatio)
+		if err != nil {
+			return xerrors.Errorf("setup tracing: %w", err)
+		}
+		defer shutdownTracing(context.Background()) //nolint:errcheck
+
 		udpPacketConn, err := net.ListenPacket("udp", schedulerCfg.ListenAddress)
 		if err != nil {
 			return err
@@ -250,7 +275,9 @@ var runCmd = &cli.Command{
 		}
 
 		// Instantiate the scheduler handler.
-		h, err := node.SchedulerHandler(schedulerAPI, true, serverOptions...)
+		// the mutations of admin callers are recorded to the audit log
+		auditedAPI := audit.SchedulerAPI(schedulerAPI, schedulerAPI.(*scheduler.Scheduler).NodeManager)
+		h, err := node.SchedulerHandler(auditedAPI, true, schedulerCfg.EnableWebUI, serverOptions...)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate rpc handler: %s", err.Error())
 		}
@@ -259,7 +286,12 @@ var runCmd = &cli.Command{
 			return transport.Close()
 		}
 
-		go startHTTP3Server(transport, h, schedulerCfg) //nolint:errcheck
+		var certAuthority *ca.Authority
+		if schedulerCfg.EnableCandidateMTLS {
+			certAuthority = schedulerAPI.(*scheduler.Scheduler).CertAuthority
+		}
+
+		go startHTTP3Server(transport, h, schedulerCfg, certAuthority) //nolint:errcheck
 
 		// Serve the RPC.
 		rpcStopper, err := node.ServeRPC(h, "scheduler", schedulerCfg.ListenAddress)
@@ -269,12 +301,25 @@ var runCmd = &cli.Command{
 
 		log.Info("titan scheduler listen with:", schedulerCfg.ListenAddress)
 
+		handlers := []node.ShutdownHandler{
+			{Component: "rpc server", StopFunc: rpcStopper},
+			{Component: "node", StopFunc: stop},
+			{Component: "http3 server", StopFunc: stopHTTP3Server},
+		}
+
+		if len(schedulerCfg.GRPCListenAddress) > 0 {
+			grpcServer := grpcserver.New(schedulerAPI)
+			go func() {
+				if err := grpcServer.Serve(schedulerCfg.GRPCListenAddress); err != nil {
+					log.Errorf("grpc server err:%s", err.Error())
+				}
+			}()
+
+			handlers = append(handlers, node.ShutdownHandler{Component: "grpc server", StopFunc: grpcServer.Stop})
+		}
+
 		// Monitor for shutdown.
-		finishCh := node.MonitorShutdown(shutdownChan,
-			node.ShutdownHandler{Component: "rpc server", StopFunc: rpcStopper},
-			node.ShutdownHandler{Component: "node", StopFunc: stop},
-			node.ShutdownHandler{Component: "http3 server", StopFunc: stopHTTP3Server},
-		)
+		finishCh := node.MonitorShutdown(shutdownChan, handlers...)
 		<-finishCh // fires when shutdown is complete.
 		return nil
 	},
@@ -305,7 +350,8 @@ func openRepo(cctx *cli.Context) (repo.LockedRepo, error) {
 	return lr, nil
 }
 
-func startHTTP3Server(transport *quic.Transport, handler http.Handler, schedulerCfg *config.SchedulerCfg) error {
+// startHTTP3Server serves the handler over http3, client certificates issued by certAuthority are verified if it is not nil
+func startHTTP3Server(transport *quic.Transport, handler http.Handler, schedulerCfg *config.SchedulerCfg, certAuthority *ca.Authority) error {
 	var tlsConfig *tls.Config
 	if len(schedulerCfg.CertificatePath) == 0 || len(schedulerCfg.PrivateKeyPath) == 0 {
 		config, err := defaultTLSConfig()
@@ -314,6 +360,11 @@ func startHTTP3Server(transport *quic.Transport, handler http.Handler, scheduler
 			return err
 		}
 		tlsConfig = config
+
+		if certAuthority != nil {
+			// the server certificate is issued by the ca, candidates verify it
+			tlsConfig.Certificates = nil
+		}
 	} else {
 		cert, err := tls.LoadX509KeyPair(schedulerCfg.CertificatePath, schedulerCfg.PrivateKeyPath)
 		if err != nil {
@@ -329,6 +380,15 @@ func startHTTP3Server(transport *quic.Transport, handler http.Handler, scheduler
 		}
 	}
 
+	if certAuthority != nil {
+		config, err := certAuthority.ServerTLSConfig(tlsConfig)
+		if err != nil {
+			log.Errorf("startUDPServer, ServerTLSConfig error:%s", err.Error())
+			return err
+		}
+		tlsConfig = config
+	}
+
 	ln, err := transport.ListenEarly(tlsConfig, nil)
 	if err != nil {
 		return err
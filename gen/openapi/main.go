@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Filecoin-Titan/titan/node/scheduler/restapi"
+)
+
+// generates the openapi spec of the scheduler rest api
+func main() {
+	out := "documentation/en/openapi.json"
+	if len(os.Args) > 1 {
+		out = os.Args[1]
+	}
+
+	buf, err := json.MarshalIndent(restapi.New(nil).Spec(), "", "  ")
+	if err != nil {
+		fmt.Println("marshal spec:", err)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(out, append(buf, '\n'), 0o644); err != nil {
+		fmt.Println("write spec:", err)
+		os.Exit(1)
+	}
+}
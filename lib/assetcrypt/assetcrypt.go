@@ -0,0 +1,291 @@
+// Package assetcrypt encrypts a file on the client before it is packed into a car and uploaded,
+// so the nodes holding the replicas only see the ciphertext.
+// The file is split into chunks sealed by aes-256-gcm, the nonce of a chunk is the base nonce
+// xor the index of the chunk, and the last chunk is sealed with a different additional data,
+// so the chunks can not be reordered, dropped or truncated without failing the decryption.
+package assetcrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// KeySize the size of the content key
+	KeySize = 32
+	// DefaultChunkSize the default size of the plaintext chunks
+	DefaultChunkSize = 64 << 10
+
+	minChunkSize = 1 << 10
+	maxChunkSize = 16 << 20
+	nonceSize    = 12
+	tagSize      = 16
+)
+
+var (
+	adChunk     = []byte{0}
+	adLastChunk = []byte{1}
+)
+
+// NewKey returns a random content key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// CiphertextSize returns the size of the encrypted file
+func CiphertextSize(plainSize, chunkSize int64) int64 {
+	chunks := (plainSize + chunkSize - 1) / chunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return plainSize + chunks*tagSize
+}
+
+// Encrypt encrypts src to dst with the content key, returns the metadata the downloading clients need,
+// the key mode and the wrapped key are filled by the caller
+func Encrypt(dst io.Writer, src io.Reader, key []byte, chunkSize int64) (*types.AssetEncryption, error) {
+	if chunkSize < minChunkSize || chunkSize > maxChunkSize {
+		return nil, xerrors.Errorf("chunk size %d out of range [%d, %d]", chunkSize, minChunkSize, maxChunkSize)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// a chunk is sealed once the next one is read, so the last chunk is known
+	cur := make([]byte, chunkSize)
+	next := make([]byte, chunkSize)
+	out := make([]byte, 0, chunkSize+tagSize)
+
+	n, err := readChunk(src, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	plainSize := int64(0)
+	for index := uint64(0); ; index++ {
+		m := 0
+		if n == len(cur) {
+			if m, err = readChunk(src, next); err != nil {
+				return nil, err
+			}
+		}
+
+		ad := adChunk
+		if m == 0 {
+			ad = adLastChunk
+		}
+
+		out = aead.Seal(out[:0], chunkNonce(nonce, index), cur[:n], ad)
+		if _, err := dst.Write(out); err != nil {
+			return nil, err
+		}
+		plainSize += int64(n)
+
+		if m == 0 {
+			break
+		}
+
+		cur, next = next, cur
+		n = m
+	}
+
+	return &types.AssetEncryption{
+		Algorithm: types.EncryptionAES256GCMChunked,
+		ChunkSize: chunkSize,
+		Nonce:     base64.StdEncoding.EncodeToString(nonce),
+		PlainSize: plainSize,
+	}, nil
+}
+
+// NewDecryptReader returns a reader of the plaintext of src
+func NewDecryptReader(src io.Reader, key []byte, info *types.AssetEncryption) (io.Reader, error) {
+	if err := checkCipher(info); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, _ := base64.StdEncoding.DecodeString(info.Nonce)
+	return &decryptReader{
+		src:   bufio.NewReader(src),
+		aead:  aead,
+		nonce: nonce,
+		buf:   make([]byte, info.ChunkSize+tagSize),
+	}, nil
+}
+
+// Validate checks the metadata of an encrypted asset, the content key must never be sent unwrapped
+func Validate(info *types.AssetEncryption) error {
+	if info == nil {
+		return xerrors.New("encryption is nil")
+	}
+
+	if err := checkCipher(info); err != nil {
+		return err
+	}
+
+	if info.PlainSize < 0 {
+		return xerrors.Errorf("invalid plain size %d", info.PlainSize)
+	}
+
+	switch info.KeyMode {
+	case types.EncryptionKeyClient:
+		if info.WrappedKey != "" || info.WrapAlgorithm != "" {
+			return xerrors.New("the key is held by the client, the wrapped key must be empty")
+		}
+	case types.EncryptionKeyWrapped:
+		if info.WrapAlgorithm == "" {
+			return xerrors.New("wrap algorithm is empty")
+		}
+
+		wrapped, err := base64.StdEncoding.DecodeString(info.WrappedKey)
+		if err != nil {
+			return xerrors.Errorf("decode wrapped key %w", err)
+		}
+
+		// a wrapped key is always longer than the content key, the unwrapped key is refused
+		if len(wrapped) <= KeySize {
+			return xerrors.Errorf("wrapped key of %d bytes looks like an unwrapped content key", len(wrapped))
+		}
+	default:
+		return xerrors.Errorf("unsupported key mode %q", info.KeyMode)
+	}
+
+	return nil
+}
+
+func checkCipher(info *types.AssetEncryption) error {
+	if info.Algorithm != types.EncryptionAES256GCMChunked {
+		return xerrors.Errorf("unsupported algorithm %q", info.Algorithm)
+	}
+
+	if info.ChunkSize < minChunkSize || info.ChunkSize > maxChunkSize {
+		return xerrors.Errorf("chunk size %d out of range [%d, %d]", info.ChunkSize, minChunkSize, maxChunkSize)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(info.Nonce)
+	if err != nil {
+		return xerrors.Errorf("decode nonce %w", err)
+	}
+
+	if len(nonce) != nonceSize {
+		return xerrors.Errorf("nonce size %d, expect %d", len(nonce), nonceSize)
+	}
+
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, xerrors.Errorf("key size %d, expect %d", len(key), KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the chunk, the base nonce xor the index of the chunk
+func chunkNonce(base []byte, index uint64) []byte {
+	nonce := make([]byte, nonceSize)
+	copy(nonce, base)
+
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], index)
+	for i := range b {
+		nonce[nonceSize-8+i] ^= b[i]
+	}
+	return nonce
+}
+
+// readChunk reads a full chunk, fewer bytes only at the end of r
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, nil
+	}
+	return n, err
+}
+
+type decryptReader struct {
+	src   *bufio.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	buf   []byte
+	index uint64
+	plain []byte
+	done  bool
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next decrypts the next chunk
+func (d *decryptReader) next() error {
+	n, err := readChunk(d.src, d.buf)
+	if err != nil {
+		return err
+	}
+
+	last := n < len(d.buf)
+	if !last {
+		if _, err := d.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	ad := adChunk
+	if last {
+		ad = adLastChunk
+	}
+
+	plain, err := d.aead.Open(d.buf[:0], chunkNonce(d.nonce, d.index), d.buf[:n], ad)
+	if err != nil {
+		return xerrors.Errorf("decrypt chunk %d %w", d.index, err)
+	}
+
+	d.index++
+	d.plain = plain
+	d.done = last
+	return nil
+}
@@ -0,0 +1,93 @@
+package assetcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunkSize := int64(minChunkSize)
+	for _, size := range []int64{0, 1, chunkSize, 2 * chunkSize, 3*chunkSize + 7} {
+		plain := make([]byte, size)
+		rand.Read(plain) //nolint:errcheck
+
+		var ciphertext bytes.Buffer
+		info, err := Encrypt(&ciphertext, bytes.NewReader(plain), key, chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.PlainSize != size {
+			t.Fatalf("expected plain size %d, got %d", size, info.PlainSize)
+		}
+
+		if int64(ciphertext.Len()) != CiphertextSize(size, chunkSize) {
+			t.Fatalf("size %d: expected ciphertext size %d, got %d", size, CiphertextSize(size, chunkSize), ciphertext.Len())
+		}
+
+		r, err := NewDecryptReader(bytes.NewReader(ciphertext.Bytes()), key, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("size %d: %s", size, err.Error())
+		}
+
+		if !bytes.Equal(out, plain) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+
+		// dropping the last chunk must fail the decryption
+		if size > chunkSize {
+			truncated := ciphertext.Bytes()[:chunkSize+tagSize]
+			r, _ := NewDecryptReader(bytes.NewReader(truncated), key, info)
+			if _, err := io.ReadAll(r); err == nil {
+				t.Fatalf("size %d: expected the truncated ciphertext to fail", size)
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	nonce := base64.StdEncoding.EncodeToString(make([]byte, nonceSize))
+	base := types.AssetEncryption{Algorithm: types.EncryptionAES256GCMChunked, ChunkSize: DefaultChunkSize, Nonce: nonce}
+
+	client := base
+	client.KeyMode = types.EncryptionKeyClient
+	if err := Validate(&client); err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped := base
+	wrapped.KeyMode = types.EncryptionKeyWrapped
+	wrapped.WrapAlgorithm = "aes-kw"
+	wrapped.WrappedKey = base64.StdEncoding.EncodeToString(make([]byte, KeySize+8))
+	if err := Validate(&wrapped); err != nil {
+		t.Fatal(err)
+	}
+
+	// the unwrapped content key is refused
+	unwrapped := wrapped
+	unwrapped.WrappedKey = base64.StdEncoding.EncodeToString(make([]byte, KeySize))
+	if err := Validate(&unwrapped); err == nil {
+		t.Fatal("expected the unwrapped key to be refused")
+	}
+
+	leaked := client
+	leaked.WrappedKey = wrapped.WrappedKey
+	if err := Validate(&leaked); err == nil {
+		t.Fatal("expected a key to be refused in client mode")
+	}
+}
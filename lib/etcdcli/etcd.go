@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -14,15 +13,11 @@ import (
 )
 
 const (
-	connectServerTimeoutTime = 5  // Second
-	serverKeepAliveDuration  = 10 // Second
+	connectServerTimeoutTime = 5 // Second
 
 	masterAliveDuration = 60 * 5 // Second
 
 	masterName = "/master/%s/%s"
-
-	edgeCountKey         = "/edgeCount"
-	edgeCountKeyDuration = 60 * 6 // Second
 )
 
 // Client etcd client
@@ -60,135 +55,54 @@ func New(addrs []string) (*Client, error) {
 	return client, nil
 }
 
-func (c *Client) PutEdgeCount(serverID string, count int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+// RegisterServerLease registers the server with a lease of ttl seconds and returns the lease id,
+// the lease is not kept alive automatically and expires unless it is refreshed. If already registered, return an error
+func (c *Client) RegisterServerLease(serverID, nodeType, value string, ttl int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectServerTimeoutTime*time.Second)
 	defer cancel()
 
-	leaseResp, err := c.cli.Grant(ctx, edgeCountKeyDuration)
-	if err != nil {
-		return err
-	}
-
-	_, err = c.cli.Put(ctx, fmt.Sprintf("%s/%s", edgeCountKey, serverID), strconv.Itoa(count), clientv3.WithLease(leaseResp.ID))
-	return err
-}
-
-func (c *Client) getEdgeCount(key string) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+	serverKey := fmt.Sprintf("/%s/%s", nodeType, serverID)
 
-	resp, err := c.cli.Get(ctx, key)
+	leaseRsp, err := c.cli.Grant(ctx, ttl)
 	if err != nil {
-		return 0, err
+		return 0, xerrors.Errorf("Grant lease err:%s", err.Error())
 	}
 
-	for _, kv := range resp.Kvs {
-		retrievedValue, err := strconv.Atoi(string(kv.Value))
-		if err != nil {
-			return 0, err
-		}
-
-		return retrievedValue, nil
-	}
-
-	return 0, nil
-}
-
-func (c *Client) GetEdgeCounts(selfServerID string) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-
-	resp, err := c.cli.Get(ctx, edgeCountKey, clientv3.WithPrefix())
+	resp, err := c.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(serverKey), "=", 0)).
+		Then(clientv3.OpPut(serverKey, value, clientv3.WithLease(leaseRsp.ID))).
+		Commit()
 	if err != nil {
 		return 0, err
 	}
 
-	var keys []string
-	for _, kv := range resp.Kvs {
-		keys = append(keys, string(kv.Key))
-	}
-
-	var tErr error
-	total := 0
-	for _, key := range keys {
-		fmt.Printf("GetEdgeCounts key %s \n", key)
-		if key != fmt.Sprintf("%s/%s", edgeCountKey, selfServerID) {
-			count, err := c.getEdgeCount(key)
-			if err != nil {
-				fmt.Printf("GetEdgeCounts %s err: %s \n", key, err.Error())
-				tErr = err
-				continue
-			}
-			total += count
+	if !resp.Succeeded {
+		if _, err := c.cli.Revoke(ctx, leaseRsp.ID); err != nil {
+			return 0, xerrors.Errorf("key already exists, revoke lease err:%s", err.Error())
 		}
+		return 0, xerrors.Errorf("key already exists")
 	}
 
-	return total, tErr
+	return int64(leaseRsp.ID), nil
 }
 
-// ServerRegister register to etcd , If already register in, return an error
-func (c *Client) ServerRegister(t context.Context, serverID, nodeType, value string) error {
+// RefreshServerLease keeps the lease of the server alive for another ttl and updates the value of the server,
+// the value is not updated if it is empty
+func (c *Client) RefreshServerLease(serverID, nodeType, value string, leaseID int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), connectServerTimeoutTime*time.Second)
 	defer cancel()
 
-	serverKey := fmt.Sprintf("/%s/%s", nodeType, serverID)
-
-	// get a lease
-	leaseRsp, err := c.cli.Grant(ctx, serverKeepAliveDuration)
-	if err != nil {
-		return xerrors.Errorf("Grant lease err:%s", err.Error())
-	}
-
-	leaseID := leaseRsp.ID
-
-	txn := c.cli.Txn(context.Background())
-	resp, err := txn.
-		If(clientv3.Compare(clientv3.CreateRevision(serverKey), "=", 0)).
-		Then(clientv3.OpPut(serverKey, value, clientv3.WithLease(leaseID))).
-		Else().
-		Commit()
-	if err != nil {
+	if _, err := c.cli.KeepAliveOnce(ctx, clientv3.LeaseID(leaseID)); err != nil {
 		return err
 	}
 
-	if !resp.Succeeded {
-		return xerrors.Errorf("key already exists")
+	if value == "" {
+		return nil
 	}
 
-	// KeepAlive
-	keepRespChan, err := c.cli.KeepAlive(context.TODO(), leaseID)
-	if err != nil {
-		return err
-	}
-	// lease keepalive response queue capacity only 16 , so need to read it
-	go func() {
-		for {
-			ch := <-keepRespChan
-			if ch == nil {
-				c.startRegisterTimer(serverID, nodeType, value)
-				return
-			}
-		}
-	}()
-
-	return nil
-}
-
-func (c *Client) startRegisterTimer(serverID, nodeType, value string) {
-	interval := 10 * time.Second
-
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		<-ticker.C
-		err := c.ServerRegister(context.Background(), serverID, nodeType, value)
-		if err != nil {
-			fmt.Printf("register to etcd server err: %v \n", err.Error())
-		} else {
-			return
-		}
-	}
+	serverKey := fmt.Sprintf("/%s/%s", nodeType, serverID)
+	_, err := c.cli.Put(ctx, serverKey, value, clientv3.WithLease(clientv3.LeaseID(leaseID)))
+	return err
 }
 
 // WatchServers watch server login and logout
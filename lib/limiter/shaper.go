@@ -0,0 +1,93 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Shaper limits the upload rate of a node with a token bucket, each token represents one byte.
+// The limit can be changed while the data is served, 0 means unlimited
+type Shaper struct {
+	limiter *rate.Limiter
+	limit   int64 // unit: byte per second
+
+	lk   sync.Mutex
+	sent int64 // bytes sent since the last sample
+	last time.Time
+}
+
+// NewShaper returns an unlimited Shaper
+func NewShaper() *Shaper {
+	return &Shaper{limiter: rate.NewLimiter(rate.Inf, 0), last: time.Now()}
+}
+
+// SetLimit sets the upload limit in bytes per second, 0 removes the limit
+func (s *Shaper) SetLimit(bytesPerSec int64) {
+	if bytesPerSec < 0 {
+		bytesPerSec = 0
+	}
+
+	atomic.StoreInt64(&s.limit, bytesPerSec)
+
+	if bytesPerSec == 0 {
+		s.limiter.SetLimit(rate.Inf)
+		return
+	}
+
+	// one second of data may be sent in a burst
+	s.limiter.SetBurst(int(bytesPerSec))
+	s.limiter.SetLimit(rate.Limit(bytesPerSec))
+}
+
+// Limit returns the upload limit in bytes per second, 0 if unlimited
+func (s *Shaper) Limit() int64 {
+	return atomic.LoadInt64(&s.limit)
+}
+
+// WaitN blocks until n bytes may be sent, the bytes are counted to the upload rate
+func (s *Shaper) WaitN(ctx context.Context, n int) error {
+	s.lk.Lock()
+	s.sent += int64(n)
+	s.lk.Unlock()
+
+	if s.Limit() == 0 {
+		return nil
+	}
+
+	for n > 0 {
+		chunk := n
+		if burst := s.limiter.Burst(); burst > 0 && chunk > burst {
+			chunk = burst
+		}
+
+		if err := s.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+
+	return nil
+}
+
+// Rate returns the average upload rate in bytes per second since the last call
+func (s *Shaper) Rate() int64 {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(s.last)
+	sent := s.sent
+
+	s.sent = 0
+	s.last = now
+
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return int64(float64(sent) / elapsed.Seconds())
+}
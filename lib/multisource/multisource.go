@@ -0,0 +1,190 @@
+// Package multisource downloads a file from multiple nodes in parallel,
+// each node serves the byte range assigned to it by the scheduler.
+package multisource
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// number of attempts to download a segment, the later attempts resume from the received bytes
+const segmentAttempts = 3
+
+// Range an inclusive byte range
+type Range struct {
+	Start int64
+	End   int64
+}
+
+// SplitRanges splits size bytes into consecutive ranges in proportion to the weights,
+// the ranges hold at least minSegment bytes, so fewer ranges than weights may be returned for small files
+func SplitRanges(size, minSegment int64, weights []int64) []Range {
+	if size <= 0 || len(weights) == 0 {
+		return nil
+	}
+
+	count := len(weights)
+	if minSegment > 0 {
+		if max := (size + minSegment - 1) / minSegment; int64(count) > max {
+			count = int(max)
+		}
+	}
+
+	total := int64(0)
+	for i := 0; i < count; i++ {
+		if weights[i] > 0 {
+			total += weights[i]
+		} else {
+			total++
+		}
+	}
+
+	ranges := make([]Range, 0, count)
+	offset := int64(0)
+	for i := 0; i < count && offset < size; i++ {
+		remaining := size - offset
+		length := remaining
+		if i < count-1 {
+			weight := weights[i]
+			if weight <= 0 {
+				weight = 1
+			}
+
+			length = int64(float64(size) * float64(weight) / float64(total))
+			if length < minSegment {
+				length = minSegment
+			}
+			if length > remaining {
+				length = remaining
+			}
+		}
+
+		ranges = append(ranges, Range{Start: offset, End: offset + length - 1})
+		offset += length
+	}
+
+	return ranges
+}
+
+// Download downloads the segments of the plan in parallel and writes them to w at their offsets,
+// a broken segment is resumed from the same node, returns the workload reports of the segments
+// to be submitted to the scheduler
+func Download(ctx context.Context, client *http.Client, plan *types.ParallelDownloadPlan, w io.WriterAt) ([]*types.WorkloadReport, error) {
+	reports := make([]*types.WorkloadReport, len(plan.Segments))
+	errs := make([]error, len(plan.Segments))
+
+	wg := &sync.WaitGroup{}
+	for i, segment := range plan.Segments {
+		wg.Add(1)
+
+		go func(i int, segment *types.DownloadSegment) {
+			defer wg.Done()
+			reports[i], errs[i] = downloadSegment(ctx, client, plan, segment, w)
+		}(i, segment)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return reports, xerrors.Errorf("segment %d-%d from node %s: %w", plan.Segments[i].Start, plan.Segments[i].End, plan.Segments[i].NodeID, err)
+		}
+	}
+
+	return reports, nil
+}
+
+// downloadSegment downloads the segment, the received bytes are kept between the attempts
+func downloadSegment(ctx context.Context, client *http.Client, plan *types.ParallelDownloadPlan, segment *types.DownloadSegment, w io.WriterAt) (*types.WorkloadReport, error) {
+	workload := &types.Workload{StartTime: time.Now()}
+	report := &types.WorkloadReport{TokenID: segment.Tk.ID, NodeID: segment.NodeID, Workload: workload}
+
+	offset := segment.Start
+	var err error
+	for i := 0; i < segmentAttempts && offset <= segment.End; i++ {
+		var n int64
+		n, err = fetchRange(ctx, client, plan, segment, offset, w)
+		offset += n
+		workload.DownloadSize += n
+
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+	}
+
+	workload.EndTime = time.Now()
+	if duration := workload.EndTime.Sub(workload.StartTime); duration > 0 {
+		workload.DownloadSpeed = int64(float64(workload.DownloadSize) / float64(duration) * float64(time.Second))
+	}
+
+	if offset <= segment.End {
+		if err == nil {
+			err = fmt.Errorf("received %d of %d bytes", offset-segment.Start, segment.End-segment.Start+1)
+		}
+		return report, err
+	}
+
+	return report, nil
+}
+
+// fetchRange requests the bytes from offset to the end of the segment, returns the number of bytes written
+func fetchRange(ctx context.Context, client *http.Client, plan *types.ParallelDownloadPlan, segment *types.DownloadSegment, offset int64, w io.WriterAt) (int64, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(segment.Tk); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/ipfs/%s", segment.Address, plan.AssetCID), &buf)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, segment.End))
+	// a node serving other content answers with the whole file instead of the range
+	if plan.ETag != "" {
+		req.Header.Set("If-Range", plan.ETag)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var start, end, size int64
+	if _, err = fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return 0, xerrors.Errorf("parse Content-Range %s: %w", resp.Header.Get("Content-Range"), err)
+	}
+
+	if start != offset || end > segment.End || (plan.Size > 0 && size != plan.Size) {
+		return 0, fmt.Errorf("unexpected Content-Range %s", resp.Header.Get("Content-Range"))
+	}
+
+	return io.Copy(&offsetWriter{w: w, offset: offset}, io.LimitReader(resp.Body, end-start+1))
+}
+
+// offsetWriter writes to the WriterAt sequentially from the offset
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
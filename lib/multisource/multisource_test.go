@@ -0,0 +1,83 @@
+package multisource
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestSplitRanges(t *testing.T) {
+	ranges := SplitRanges(100, 10, []int64{1, 3})
+	if len(ranges) != 2 || ranges[0] != (Range{0, 24}) || ranges[1] != (Range{25, 99}) {
+		t.Fatalf("unexpected ranges %v", ranges)
+	}
+
+	// the small file is not split below the minimum segment
+	ranges = SplitRanges(15, 10, []int64{1, 1, 1, 1})
+	if len(ranges) != 2 || ranges[0] != (Range{0, 9}) || ranges[1] != (Range{10, 14}) {
+		t.Fatalf("unexpected ranges %v", ranges)
+	}
+
+	ranges = SplitRanges(1000, 1, []int64{0, 0, 0})
+	if len(ranges) != 3 || ranges[2].End != 999 {
+		t.Fatalf("unexpected ranges %v", ranges)
+	}
+}
+
+type buffer struct {
+	data []byte
+}
+
+func (b *buffer) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b.data[off:], p), nil
+}
+
+func TestDownload(t *testing.T) {
+	const root = "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4"
+
+	content := make([]byte, 1<<16)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Etag", `"`+root+`"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "https://")
+	plan := &types.ParallelDownloadPlan{AssetCID: root, Size: int64(len(content)), ETag: `"` + root + `"`}
+	for i, r := range SplitRanges(plan.Size, 1024, []int64{1, 2, 3}) {
+		plan.Segments = append(plan.Segments, &types.DownloadSegment{NodeID: string(rune('a' + i)), Address: address, Tk: &types.Token{ID: "token"}, Start: r.Start, End: r.End})
+	}
+
+	out := &buffer{data: make([]byte, len(content))}
+	reports, err := Download(context.Background(), server.Client(), plan, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out.data, content) {
+		t.Fatal("downloaded content mismatch")
+	}
+
+	for i, report := range reports {
+		if report.Workload.DownloadSize != plan.Segments[i].End-plan.Segments[i].Start+1 {
+			t.Fatalf("segment %d reports %d bytes", i, report.Workload.DownloadSize)
+		}
+	}
+
+	// the node serving other content is detected by If-Range
+	plan.ETag = `"other"`
+	if _, err = Download(context.Background(), server.Client(), plan, out); err == nil {
+		t.Fatal("expect error with mismatched etag")
+	}
+}
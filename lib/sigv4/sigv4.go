@@ -0,0 +1,266 @@
+package sigv4
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Algorithm the signing algorithm of aws signature version 4
+	Algorithm = "AWS4-HMAC-SHA256"
+	// UnsignedPayload the payload hash of the request whose payload is not signed
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// TimeFormat the format of x-amz-date
+	TimeFormat = "20060102T150405Z"
+	// MaxClockSkew maximum difference between the request time and the current time
+	MaxClockSkew = 15 * time.Minute
+
+	scopeTerminator = "aws4_request"
+)
+
+// Authorization the parsed authorization header of a signed request
+type Authorization struct {
+	AccessKey     string
+	Scope         string // date/region/service/aws4_request
+	SignedHeaders []string
+	Signature     string
+}
+
+// ParseAuthorization parses the authorization header of a signed request
+func ParseAuthorization(header string) (*Authorization, error) {
+	if !strings.HasPrefix(header, Algorithm+" ") {
+		return nil, xerrors.Errorf("unsupported authorization algorithm")
+	}
+
+	auth := &Authorization{}
+	for _, field := range strings.Split(strings.TrimPrefix(header, Algorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, xerrors.Errorf("invalid authorization field %s", field)
+		}
+
+		switch kv[0] {
+		case "Credential":
+			parts := strings.SplitN(kv[1], "/", 2)
+			if len(parts) != 2 {
+				return nil, xerrors.Errorf("invalid credential %s", kv[1])
+			}
+			auth.AccessKey, auth.Scope = parts[0], parts[1]
+		case "SignedHeaders":
+			auth.SignedHeaders = strings.Split(kv[1], ";")
+		case "Signature":
+			auth.Signature = kv[1]
+		}
+	}
+
+	if auth.AccessKey == "" || auth.Signature == "" || len(auth.SignedHeaders) == 0 {
+		return nil, xerrors.Errorf("incomplete authorization header")
+	}
+
+	if _, err := parseScope(auth.Scope); err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// StringToSign builds the string to sign of the request, the payload hash is read from x-amz-content-sha256
+func StringToSign(r *http.Request, auth *Authorization) (string, error) {
+	amzDate := r.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		return "", xerrors.Errorf("missing x-amz-date header")
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return "", xerrors.Errorf("missing x-amz-content-sha256 header")
+	}
+
+	canonicalURI := r.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI,
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders(r, auth.SignedHeaders),
+		strings.Join(auth.SignedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	return strings.Join([]string{Algorithm, amzDate, auth.Scope, hexSHA256([]byte(canonicalRequest))}, "\n"), nil
+}
+
+// Sign computes the signature of the string to sign with the secret key, the scope is read from the string to sign
+func Sign(secretKey, stringToSign string) (string, error) {
+	lines := strings.Split(stringToSign, "\n")
+	if len(lines) != 4 || lines[0] != Algorithm {
+		return "", xerrors.Errorf("invalid string to sign")
+	}
+
+	scope, err := parseScope(lines[2])
+	if err != nil {
+		return "", err
+	}
+
+	key := []byte("AWS4" + secretKey)
+	for _, s := range scope {
+		key = hmacSHA256(key, s)
+	}
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign)), nil
+}
+
+// Verify checks the signature of the string to sign with the secret key, the request time must be close to now
+func Verify(secretKey, stringToSign, signature string, now time.Time) error {
+	lines := strings.Split(stringToSign, "\n")
+	if len(lines) != 4 {
+		return xerrors.Errorf("invalid string to sign")
+	}
+
+	requestTime, err := time.Parse(TimeFormat, lines[1])
+	if err != nil {
+		return xerrors.Errorf("invalid request time %s", lines[1])
+	}
+
+	if skew := now.Sub(requestTime); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return xerrors.Errorf("request time %s is too skewed", lines[1])
+	}
+
+	expected, err := Sign(secretKey, stringToSign)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return xerrors.Errorf("signature does not match")
+	}
+
+	return nil
+}
+
+// NewPayloadReader returns a reader that fails at the end of the payload if its sha256 is not the payload hash,
+// the payload is not verified if the payload hash is UNSIGNED-PAYLOAD
+func NewPayloadReader(r io.Reader, payloadHash string) io.Reader {
+	if payloadHash == UnsignedPayload {
+		return r
+	}
+
+	return &payloadReader{r: r, hasher: sha256.New(), expected: payloadHash}
+}
+
+type payloadReader struct {
+	r        io.Reader
+	hasher   hash.Hash
+	expected string
+}
+
+func (p *payloadReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.hasher.Write(b[:n])
+
+	if err == io.EOF && hex.EncodeToString(p.hasher.Sum(nil)) != p.expected {
+		return n, fmt.Errorf("payload sha256 does not match x-amz-content-sha256")
+	}
+
+	return n, err
+}
+
+// parseScope returns the date, region, service and terminator of the credential scope
+func parseScope(scope string) ([]string, error) {
+	parts := strings.Split(scope, "/")
+	if len(parts) != 4 || parts[3] != scopeTerminator {
+		return nil, xerrors.Errorf("invalid credential scope %s", scope)
+	}
+
+	return parts, nil
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(values))
+	for _, k := range keys {
+		vs := append([]string{}, values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var buf bytes.Buffer
+	for _, name := range signedHeaders {
+		var value string
+		switch name {
+		case "host":
+			value = r.Host
+		case "content-length":
+			value = r.Header.Get("Content-Length")
+			if value == "" && r.ContentLength >= 0 {
+				value = strconv.FormatInt(r.ContentLength, 10)
+			}
+		default:
+			vs := make([]string, 0)
+			for _, v := range r.Header.Values(name) {
+				vs = append(vs, strings.Join(strings.Fields(v), " "))
+			}
+			value = strings.Join(vs, ",")
+		}
+
+		buf.WriteString(name)
+		buf.WriteString(":")
+		buf.WriteString(value)
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// uriEncode encodes the string as the aws signature, only the unreserved characters are not encoded
+func uriEncode(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+
+	return buf.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
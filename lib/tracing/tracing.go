@@ -0,0 +1,127 @@
+// Package tracing traces the flows across the scheduler and the nodes with OpenTelemetry,
+// the trace context is propagated in the W3C traceparent header of the rpc requests.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("tracing")
+
+const (
+	tracerName = "github.com/Filecoin-Titan/titan"
+	// envEndpoint the standard environment variable of the otlp endpoint, used if the endpoint is not configured
+	envEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+func init() {
+	// the trace context is propagated even if the spans are not exported
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Setup exports the spans of the service to the otlp http endpoint, e.g. http://localhost:4318, and samples the root spans
+// at the ratio, the spans with a propagated parent follow the decision of the parent. Tracing is disabled if the endpoint
+// is empty and OTEL_EXPORTER_OTLP_ENDPOINT is not set. The returned function flushes the spans and stops the export.
+func Setup(ctx context.Context, serviceName, endpoint string, sampleRatio float64) (func(context.Context) error, error) {
+	if endpoint == "" {
+		endpoint = os.Getenv(envEndpoint)
+	}
+
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, xerrors.Errorf("invalid otlp endpoint %s", endpoint)
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("new otlp exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Infof("tracing %s to %s, sample ratio %f", serviceName, endpoint, sampleRatio)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of the name, the span is a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error if any and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Detach returns a background context with the span of ctx, for the calls traced in ctx but not canceled with it
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// HasRemoteParent checks if the span in ctx is propagated from the caller
+func HasRemoteParent(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsRemote()
+}
+
+// Extract returns ctx with the trace context propagated in the request headers
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Transport injects the trace context of the request context into the request headers
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if trace.SpanContextFromContext(req.Context()).IsValid() {
+		req = req.Clone(req.Context())
+		otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	}
+
+	return t.Base.RoundTrip(req)
+}
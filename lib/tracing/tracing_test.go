@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPropagation(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background()) //nolint:errcheck
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "caller")
+	defer span.End()
+
+	var received trace.SpanContext
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = trace.SpanContextFromContext(Extract(r.Context(), r.Header))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close() //nolint:errcheck
+
+	if !received.IsRemote() || received.TraceID() != span.SpanContext().TraceID() || received.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("trace context not propagated, got %+v", received)
+	}
+
+	if HasRemoteParent(ctx) {
+		t.Errorf("local span reported as remote")
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	t.Setenv(envEndpoint, "")
+
+	shutdown, err := Setup(context.Background(), "test", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
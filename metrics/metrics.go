@@ -24,6 +24,12 @@ var (
 
 	Endpoint, _     = tag.NewKey("endpoint")
 	APIInterface, _ = tag.NewKey("api") // to distinguish between gateway api and full node api endpoint calls
+
+	// scheduler
+	Topic, _      = tag.NewKey("topic")
+	Subscriber, _ = tag.NewKey("subscriber")
+	Table, _      = tag.NewKey("table")
+	ReportKind, _ = tag.NewKey("report_kind")
 )
 
 // Measures
@@ -31,6 +37,21 @@ var (
 	// common
 	TitanInfo          = stats.Int64("info", "Arbitrary counter to tag titan info to", stats.UnitDimensionless)
 	APIRequestDuration = stats.Float64("api/request_duration_ms", "Duration of API requests", stats.UnitMilliseconds)
+
+	// scheduler
+	SchedulerShedding     = stats.Int64("scheduler/shedding", "1 if the scheduler is shedding low priority work", stats.UnitDimensionless)
+	SchedulerDBLatency    = stats.Float64("scheduler/db_latency_ms", "Latency of the scheduler database", stats.UnitMilliseconds)
+	SchedulerGoroutines   = stats.Int64("scheduler/goroutines", "Number of goroutines of the scheduler", stats.UnitDimensionless)
+	SchedulerShedRequests = stats.Int64("scheduler/shed_requests", "Requests rejected while the scheduler is shedding", stats.UnitDimensionless)
+
+	SchedulerDroppedEvents = stats.Int64("scheduler/dropped_events", "Events dropped because the queue of the subscriber is full", stats.UnitDimensionless)
+	SchedulerPrunedRows    = stats.Int64("scheduler/pruned_rows", "Rows deleted by the retention policies of the tables", stats.UnitDimensionless)
+
+	SchedulerRepairBacklog    = stats.Int64("scheduler/replica_repair_backlog", "Under-replicated assets awaiting repair", stats.UnitDimensionless)
+	SchedulerReplicaRepairs   = stats.Int64("scheduler/replica_repairs", "Repairs scheduled for the under-replicated assets", stats.UnitDimensionless)
+	SchedulerTrimmedReplicas  = stats.Int64("scheduler/trimmed_replicas", "Replicas trimmed from the over-replicated assets", stats.UnitDimensionless)
+	SchedulerRejectedReports  = stats.Int64("scheduler/rejected_reports", "Reports of the nodes rejected for missing or mismatched signatures", stats.UnitDimensionless)
+	SchedulerMigratedReplicas = stats.Int64("scheduler/migrated_replicas", "Replicas migrated off the nodes whose disks are predicted to fail", stats.UnitDimensionless)
 )
 
 var (
@@ -49,6 +70,72 @@ var (
 	}
 )
 
+var (
+	SchedulerSheddingView = &view.View{
+		Measure:     SchedulerShedding,
+		Aggregation: view.LastValue(),
+	}
+	SchedulerDBLatencyView = &view.View{
+		Measure:     SchedulerDBLatency,
+		Aggregation: view.LastValue(),
+	}
+	SchedulerGoroutinesView = &view.View{
+		Measure:     SchedulerGoroutines,
+		Aggregation: view.LastValue(),
+	}
+	SchedulerShedRequestsView = &view.View{
+		Measure:     SchedulerShedRequests,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Endpoint},
+	}
+	SchedulerDroppedEventsView = &view.View{
+		Measure:     SchedulerDroppedEvents,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Topic, Subscriber},
+	}
+	SchedulerPrunedRowsView = &view.View{
+		Measure:     SchedulerPrunedRows,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{Table},
+	}
+	SchedulerRepairBacklogView = &view.View{
+		Measure:     SchedulerRepairBacklog,
+		Aggregation: view.LastValue(),
+	}
+	SchedulerReplicaRepairsView = &view.View{
+		Measure:     SchedulerReplicaRepairs,
+		Aggregation: view.Sum(),
+	}
+	SchedulerTrimmedReplicasView = &view.View{
+		Measure:     SchedulerTrimmedReplicas,
+		Aggregation: view.Sum(),
+	}
+	SchedulerMigratedReplicasView = &view.View{
+		Measure:     SchedulerMigratedReplicas,
+		Aggregation: view.Sum(),
+	}
+	SchedulerRejectedReportsView = &view.View{
+		Measure:     SchedulerRejectedReports,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{ReportKind},
+	}
+)
+
+// SchedulerViews is an array of OpenCensus views of the scheduler load
+var SchedulerViews = []*view.View{
+	SchedulerSheddingView,
+	SchedulerDBLatencyView,
+	SchedulerGoroutinesView,
+	SchedulerShedRequestsView,
+	SchedulerDroppedEventsView,
+	SchedulerPrunedRowsView,
+	SchedulerRepairBacklogView,
+	SchedulerReplicaRepairsView,
+	SchedulerTrimmedReplicasView,
+	SchedulerRejectedReportsView,
+	SchedulerMigratedReplicasView,
+}
+
 // DefaultViews is an array of OpenCensus views for metric gathering purposes
 var DefaultViews = func() []*view.View {
 	views := []*view.View{
@@ -7,6 +7,7 @@ import (
 	"go.opencensus.io/tag"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/metrics"
 )
 
@@ -50,9 +51,24 @@ func proxy(in interface{}, outstr interface{}) {
 				ctx, _ = tag.New(ctx, tag.Upsert(metrics.Endpoint, field.Name))
 				stop := metrics.Timer(ctx, metrics.APIRequestDuration)
 				defer stop()
-				// pass tagged ctx back into function call
+				// the call is traced if the caller propagated its trace
+				if !tracing.HasRemoteParent(ctx) {
+					// pass tagged ctx back into function call
+					args[0] = reflect.ValueOf(ctx)
+					return fn.Call(args)
+				}
+
+				ctx, span := tracing.Start(ctx, "titan."+field.Name)
 				args[0] = reflect.ValueOf(ctx)
-				return fn.Call(args)
+				results = fn.Call(args)
+
+				var err error
+				if len(results) > 0 {
+					err, _ = results[len(results)-1].Interface().(error)
+				}
+				tracing.End(span, err)
+
+				return results
 			}))
 		}
 	}
@@ -0,0 +1,319 @@
+package asset
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	cacheEvictInterval        = 5 * time.Minute
+	defaultCacheMaxDiskUsage  = 90.0 // percent
+	reportEvictedAssetTimeout = 10 * time.Second
+	defaultCachePolicy        = types.CachePolicyLRU
+)
+
+// accessStat the retrievals of an asset since the node started
+type accessStat struct {
+	count int64
+	last  time.Time
+}
+
+// assetCache keeps the cacheable assets marked by the scheduler and evicts them by the cache policy
+// when the disk usage is high, the assets that are not marked cacheable are pinned
+type assetCache struct {
+	lk     sync.Mutex
+	config types.CacheConfig
+	marks  map[string]*types.AssetCacheMark // key: asset hash
+	access map[string]*accessStat           // key: asset hash
+}
+
+// loadCache loads the cache config and marks of the node from the storage
+func (m *Manager) loadCache() (*assetCache, error) {
+	c := &assetCache{
+		config: types.CacheConfig{Policy: defaultCachePolicy, MaxDiskUsage: defaultCacheMaxDiskUsage},
+		marks:  make(map[string]*types.AssetCacheMark),
+		access: make(map[string]*accessStat),
+	}
+
+	ctx := context.Background()
+	data, err := m.Storage.GetCacheConfig(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get cache config %w", err)
+	}
+
+	if data != nil {
+		if err := json.Unmarshal(data, &c.config); err != nil {
+			return nil, xerrors.Errorf("unmarshal cache config %w", err)
+		}
+	}
+
+	marks, err := m.Storage.GetCacheMarks(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get cache marks %w", err)
+	}
+
+	for hash, data := range marks {
+		mark := &types.AssetCacheMark{}
+		if err := json.Unmarshal(data, mark); err != nil {
+			log.Errorf("unmarshal cache mark %s error %s", hash, err.Error())
+			continue
+		}
+		c.marks[hash] = mark
+	}
+
+	return c, nil
+}
+
+// recordAccess counts a retrieval of the asset
+func (c *assetCache) recordAccess(root cid.Cid) {
+	hash := root.Hash().String()
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	stat, ok := c.access[hash]
+	if !ok {
+		stat = &accessStat{}
+		c.access[hash] = stat
+	}
+	stat.count++
+	stat.last = time.Now()
+}
+
+// SetCacheConfig sets the cache policy of the node and the disk usage above which the cacheable assets are evicted
+func (m *Manager) SetCacheConfig(cfg *types.CacheConfig) error {
+	if !cfg.Policy.IsValid() {
+		return xerrors.Errorf("unknown cache policy %s", cfg.Policy)
+	}
+
+	if cfg.MaxDiskUsage < 0 || cfg.MaxDiskUsage > 100 {
+		return xerrors.Errorf("invalid max disk usage %f", cfg.MaxDiskUsage)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Storage.StoreCacheConfig(context.Background(), data); err != nil {
+		return err
+	}
+
+	m.cache.lk.Lock()
+	m.cache.config = *cfg
+	m.cache.lk.Unlock()
+
+	return nil
+}
+
+// MarkAssets marks the assets as cacheable or pinned
+func (m *Manager) MarkAssets(marks []*types.AssetCacheMark) error {
+	ctx := context.Background()
+
+	for _, mark := range marks {
+		root, err := cid.Decode(mark.CID)
+		if err != nil {
+			return xerrors.Errorf("decode cid %s %w", mark.CID, err)
+		}
+
+		if !mark.Cacheable {
+			if err := m.unmarkAsset(root); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := json.Marshal(mark)
+		if err != nil {
+			return err
+		}
+
+		if err := m.Storage.StoreCacheMark(ctx, root, data); err != nil {
+			return err
+		}
+
+		m.cache.lk.Lock()
+		m.cache.marks[root.Hash().String()] = mark
+		m.cache.lk.Unlock()
+	}
+
+	return nil
+}
+
+// unmarkAsset pins the asset
+func (m *Manager) unmarkAsset(root cid.Cid) error {
+	if err := m.Storage.DeleteCacheMark(context.Background(), root); err != nil {
+		return err
+	}
+
+	m.cache.lk.Lock()
+	delete(m.cache.marks, root.Hash().String())
+	delete(m.cache.access, root.Hash().String())
+	m.cache.lk.Unlock()
+
+	return nil
+}
+
+// CacheComposition returns the pinned and cacheable assets of the node, the cacheable assets in eviction order
+func (m *Manager) CacheComposition() (*types.CacheComposition, error) {
+	count, err := m.AssetCount()
+	if err != nil {
+		return nil, err
+	}
+
+	out := &types.CacheComposition{}
+	_, out.DiskUsage = m.GetDiskUsageStat()
+
+	m.cache.lk.Lock()
+	out.CacheConfig = m.cache.config
+	m.cache.lk.Unlock()
+
+	out.Cacheable = m.cachedAssets()
+	for _, asset := range out.Cacheable {
+		out.CacheableSize += asset.Size
+	}
+	out.CacheableCount = len(out.Cacheable)
+	out.PinnedCount = count - out.CacheableCount
+	if out.PinnedCount < 0 {
+		out.PinnedCount = 0
+	}
+
+	return out, nil
+}
+
+// cachedAssets returns the cacheable assets stored on the node in eviction order
+func (m *Manager) cachedAssets() []*types.CachedAsset {
+	m.cache.lk.Lock()
+	policy := m.cache.config.Policy
+	assets := make([]*types.CachedAsset, 0, len(m.cache.marks))
+	for hash, mark := range m.cache.marks {
+		asset := &types.CachedAsset{CID: mark.CID, Expiration: mark.Expiration}
+		if stat, ok := m.cache.access[hash]; ok {
+			asset.AccessCount = stat.count
+			asset.LastAccess = stat.last
+		}
+		assets = append(assets, asset)
+	}
+	m.cache.lk.Unlock()
+
+	out := make([]*types.CachedAsset, 0, len(assets))
+	for _, asset := range assets {
+		root, err := cid.Decode(asset.CID)
+		if err != nil {
+			continue
+		}
+
+		size, err := m.assetSize(root)
+		if err != nil {
+			// the asset is not pulled yet or already deleted
+			continue
+		}
+		asset.Size = size
+		out = append(out, asset)
+	}
+
+	sortForEviction(policy, out)
+	return out
+}
+
+func (m *Manager) assetSize(root cid.Cid) (int64, error) {
+	reader, err := m.GetAsset(root)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close() //nolint:errcheck
+
+	return reader.Seek(0, io.SeekEnd)
+}
+
+// sortForEviction sorts the assets in the order the policy evicts them
+func sortForEviction(policy types.CachePolicy, assets []*types.CachedAsset) {
+	sort.SliceStable(assets, func(i, j int) bool {
+		a, b := assets[i], assets[j]
+		switch policy {
+		case types.CachePolicyLFU:
+			if a.AccessCount != b.AccessCount {
+				return a.AccessCount < b.AccessCount
+			}
+		case types.CachePolicyTTL:
+			// the assets without expiration are evicted last
+			if !a.Expiration.Equal(b.Expiration) {
+				if a.Expiration.IsZero() || b.Expiration.IsZero() {
+					return b.Expiration.IsZero()
+				}
+				return a.Expiration.Before(b.Expiration)
+			}
+		}
+
+		if !a.LastAccess.Equal(b.LastAccess) {
+			return a.LastAccess.Before(b.LastAccess)
+		}
+		return a.CID < b.CID
+	})
+}
+
+// startCacheEviction evicts the cacheable assets periodically
+func (m *Manager) startCacheEviction() {
+	ticker := time.NewTicker(cacheEvictInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.evictCache()
+	}
+}
+
+// evictCache evicts the cacheable assets by the cache policy until the disk usage is below the limit
+func (m *Manager) evictCache() {
+	m.cache.lk.Lock()
+	maxDiskUsage := m.cache.config.MaxDiskUsage
+	m.cache.lk.Unlock()
+
+	if maxDiskUsage <= 0 {
+		return
+	}
+
+	_, diskUsage := m.GetDiskUsageStat()
+	if diskUsage <= maxDiskUsage {
+		return
+	}
+
+	for _, asset := range m.cachedAssets() {
+		root, err := cid.Decode(asset.CID)
+		if err != nil {
+			continue
+		}
+
+		if err := m.DeleteAsset(root); err != nil {
+			log.Errorf("evict asset %s error %s", asset.CID, err.Error())
+			continue
+		}
+
+		_, diskUsage = m.GetDiskUsageStat()
+		log.Infof("evict asset %s, size %d, disk usage %.2f%%", asset.CID, asset.Size, diskUsage)
+
+		m.reportEvictedAsset(asset.CID, diskUsage)
+
+		if diskUsage <= maxDiskUsage {
+			return
+		}
+	}
+}
+
+// reportEvictedAsset tells the scheduler the replica of the asset is removed from the node
+func (m *Manager) reportEvictedAsset(assetCID string, diskUsage float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportEvictedAssetTimeout)
+	defer cancel()
+
+	ret := types.RemoveAssetResult{DiskUsage: diskUsage, AssetCID: assetCID}
+	if err := m.NodeRemoveAssetResult(ctx, ret); err != nil {
+		log.Errorf("report evicted asset %s error %s", assetCID, err.Error())
+	}
+}
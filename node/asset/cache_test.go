@@ -0,0 +1,40 @@
+package asset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestSortForEviction(t *testing.T) {
+	now := time.Now()
+	assets := func() []*types.CachedAsset {
+		return []*types.CachedAsset{
+			{CID: "a", AccessCount: 5, LastAccess: now.Add(-time.Hour), Expiration: now.Add(48 * time.Hour)},
+			{CID: "b", AccessCount: 1, LastAccess: now, Expiration: now.Add(24 * time.Hour)},
+			{CID: "c", AccessCount: 3, LastAccess: now.Add(-2 * time.Hour)},
+		}
+	}
+
+	cases := []struct {
+		policy types.CachePolicy
+		expect []string
+	}{
+		{policy: types.CachePolicyLRU, expect: []string{"c", "a", "b"}},
+		{policy: types.CachePolicyLFU, expect: []string{"b", "c", "a"}},
+		// the asset without expiration is evicted last
+		{policy: types.CachePolicyTTL, expect: []string{"b", "a", "c"}},
+	}
+
+	for _, c := range cases {
+		out := assets()
+		sortForEviction(c.policy, out)
+
+		for i, asset := range out {
+			if asset.CID != c.expect[i] {
+				t.Fatalf("policy %s: expected order %v, got %s at %d", c.policy, c.expect, asset.CID, i)
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Filecoin-Titan/titan/api/client"
 	"github.com/Filecoin-Titan/titan/api/types"
 
 	"github.com/ipfs/go-cid"
@@ -20,11 +21,52 @@ import (
 // CandidateFetcher
 type CandidateFetcher struct {
 	httpClient *http.Client
+	// tcpClient fetches from the nodes negotiated to transfer over tcp
+	tcpClient *http.Client
+	// tcpOnly fetches over tcp from all the nodes, e.g. through an outbound proxy which does not carry http3
+	tcpOnly bool
 }
 
 // NewCandidateFetcher creates a new CandidateFetcher with the specified timeout and retry count
 func NewCandidateFetcher(httpClient *http.Client) *CandidateFetcher {
-	return &CandidateFetcher{httpClient: httpClient}
+	return &CandidateFetcher{httpClient: httpClient, tcpClient: client.NewHTTPClient()}
+}
+
+// NewProxiedCandidateFetcher creates a new CandidateFetcher fetching over tcp with the client of the outbound proxy
+func NewProxiedCandidateFetcher(proxyClient *http.Client) *CandidateFetcher {
+	return &CandidateFetcher{httpClient: proxyClient, tcpClient: proxyClient, tcpOnly: true}
+}
+
+// clientOf returns the http client of the protocol
+func (c *CandidateFetcher) clientOf(protocol types.TransferProtocol) *http.Client {
+	if protocol == types.TransferProtocolHTTP {
+		return c.tcpClient
+	}
+	return c.httpClient
+}
+
+// fetchBlock fetches a block with the preferred protocol of the download source,
+// falls back to tcp if the http3 request failed and the source serves tcp too, returns the protocol fetched with
+func (c *CandidateFetcher) fetchBlock(ctx context.Context, downloadSource *types.CandidateDownloadInfo, cidStr string) (blocks.Block, types.TransferProtocol, error) {
+	protocol := types.PreferredTransferProtocol(downloadSource.Protocols)
+	if c.tcpOnly {
+		protocol = types.TransferProtocolHTTP
+	}
+
+	b, err := c.fetchSingleBlock(ctx, c.clientOf(protocol), downloadSource, cidStr)
+	if err == nil || protocol != types.TransferProtocolHTTP3 || ctx.Err() != nil {
+		return b, protocol, err
+	}
+
+	for _, p := range downloadSource.Protocols {
+		if p == types.TransferProtocolHTTP {
+			log.Debugf("fetch block %s from %s over http3 error %s, fall back to tcp", cidStr, downloadSource.NodeID, err.Error())
+			b, err = c.fetchSingleBlock(ctx, c.tcpClient, downloadSource, cidStr)
+			return b, types.TransferProtocolHTTP, err
+		}
+	}
+
+	return nil, protocol, err
 }
 
 // FetchBlocks fetches blocks for the given cids and candidate download info
@@ -33,7 +75,7 @@ func (c *CandidateFetcher) FetchBlocks(ctx context.Context, cids []string, dss [
 }
 
 // fetchSingleBlock fetches a single block for the given candidate download info and cid string
-func (c *CandidateFetcher) fetchSingleBlock(ctx context.Context, downloadSource *types.CandidateDownloadInfo, cidStr string) (blocks.Block, error) {
+func (c *CandidateFetcher) fetchSingleBlock(ctx context.Context, httpClient *http.Client, downloadSource *types.CandidateDownloadInfo, cidStr string) (blocks.Block, error) {
 	if len(downloadSource.Address) == 0 {
 		return nil, fmt.Errorf("candidate address can not empty")
 	}
@@ -54,7 +96,7 @@ func (c *CandidateFetcher) fetchSingleBlock(ctx context.Context, downloadSource
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("doRequest %s", err.Error())
 	}
@@ -109,7 +151,7 @@ func (c *CandidateFetcher) retrieveBlocks(ctx context.Context, cids []string, ds
 		go func() {
 			defer wg.Done()
 			startTime := time.Now()
-			b, err := c.fetchSingleBlock(ctx, ds, cidStr)
+			b, protocol, err := c.fetchBlock(ctx, ds, cidStr)
 			if err != nil {
 				errMsgs = append(errMsgs, &ErrMsg{Cid: cidStr, Source: ds.NodeID, Msg: err.Error()})
 				return
@@ -121,7 +163,7 @@ func (c *CandidateFetcher) retrieveBlocks(ctx context.Context, cids []string, ds
 				downloadSpeed = float64(len(b.RawData())) / float64(duration) * float64(time.Second)
 			}
 
-			workload := &types.Workload{DownloadSpeed: int64(downloadSpeed), DownloadSize: int64(len(b.RawData())), StartTime: startTime, EndTime: time.Now()}
+			workload := &types.Workload{DownloadSpeed: int64(downloadSpeed), DownloadSize: int64(len(b.RawData())), StartTime: startTime, EndTime: time.Now(), Protocol: protocol}
 			workloadReport := &types.WorkloadReport{TokenID: ds.Tk.ID, NodeID: ds.NodeID, Workload: workload}
 
 			lock.Lock()
@@ -0,0 +1,82 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// FetchManifest fetches the manifest of the asset from the first download source serving it
+func (c *CandidateFetcher) FetchManifest(ctx context.Context, root string, dss []*types.CandidateDownloadInfo) (*types.AssetManifest, error) {
+	var lastErr error
+	for _, ds := range dss {
+		manifest, err := c.fetchManifest(ctx, ds, root)
+		if err == nil {
+			return manifest, nil
+		}
+
+		log.Debugf("fetch manifest %s from %s error %s", root, ds.NodeID, err.Error())
+		lastErr = err
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("download infos can not empty")
+	}
+	return nil, lastErr
+}
+
+func (c *CandidateFetcher) fetchManifest(ctx context.Context, downloadSource *types.CandidateDownloadInfo, root string) (*types.AssetManifest, error) {
+	if len(downloadSource.Address) == 0 {
+		return nil, fmt.Errorf("candidate address can not empty")
+	}
+
+	if downloadSource.Tk == nil {
+		return nil, fmt.Errorf("token can not empty")
+	}
+
+	buf, err := encode(downloadSource.Tk)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s", err.Error())
+	}
+	url := fmt.Sprintf("https://%s/ipfs/%s?format=manifest", downloadSource.Address, root)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, buf)
+	if err != nil {
+		return nil, fmt.Errorf("newRequest %s", err.Error())
+	}
+
+	protocol := types.PreferredTransferProtocol(downloadSource.Protocols)
+	resp, err := c.clientOf(protocol).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doRequest %s", err.Error())
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body %s", err.Error())
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status code: %d, error msg: %s", resp.StatusCode, string(data))
+	}
+
+	manifest := &types.AssetManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest %s", err.Error())
+	}
+
+	if manifest.CID != root {
+		return nil, fmt.Errorf("manifest of %s, expect %s", manifest.CID, root)
+	}
+
+	return manifest, nil
+}
@@ -2,6 +2,7 @@ package asset
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"time"
 
@@ -23,16 +24,18 @@ type Asset struct {
 	mgr             *Manager
 	TotalBlockCount int
 	apiSecret       *jwt.HMACSHA
+	privateKey      crypto.Signer // used to sign the storage challenge results
 	AWS
 }
 
 // NewAsset creates a new Asset instance
-func NewAsset(storageMgr *storage.Manager, scheduler api.Scheduler, assetMgr *Manager, apiSecret *jwt.HMACSHA) *Asset {
+func NewAsset(storageMgr *storage.Manager, scheduler api.Scheduler, assetMgr *Manager, apiSecret *jwt.HMACSHA, privateKey crypto.Signer) *Asset {
 	return &Asset{
-		scheduler: scheduler,
-		mgr:       assetMgr,
-		apiSecret: apiSecret,
-		AWS:       NewAWS(scheduler, storageMgr),
+		scheduler:  scheduler,
+		mgr:        assetMgr,
+		apiSecret:  apiSecret,
+		privateKey: privateKey,
+		AWS:        NewAWS(scheduler, storageMgr),
 	}
 }
 
@@ -179,6 +182,20 @@ func (a *Asset) CreateAsset(ctx context.Context, tokenPayload *types.AuthUserUpl
 	return string(tk), nil
 }
 
+// CreateIngestToken notify candidate that user ingest asset, return auth token of candidate
+func (a *Asset) CreateIngestToken(ctx context.Context, tokenPayload *types.AuthUserIngestAsset) (string, error) {
+	if len(tokenPayload.IngestID) == 0 {
+		return "", fmt.Errorf("ingest id can not empty")
+	}
+
+	tk, err := jwt.Sign(&tokenPayload, a.apiSecret)
+	if err != nil {
+		return "", err
+	}
+
+	return string(tk), nil
+}
+
 // GetAssetProgresses returns the progress of the given list of assets.
 func (a *Asset) GetAssetProgresses(ctx context.Context, assetCIDs []string) (*types.PullResult, error) {
 	progresses := make([]*types.AssetPullProgress, 0, len(assetCIDs))
@@ -318,3 +335,18 @@ func (a *Asset) GetAssetsInBucket(ctx context.Context, bucketID int) ([]string,
 func (a *Asset) SyncAssetViewAndData(ctx context.Context) error {
 	return a.mgr.syncDataWithAssetView()
 }
+
+// SetCacheConfig sets the policy by which the node evicts its cacheable assets
+func (a *Asset) SetCacheConfig(ctx context.Context, cfg *types.CacheConfig) error {
+	return a.mgr.SetCacheConfig(cfg)
+}
+
+// MarkAssetsCache marks the assets as cacheable or pinned
+func (a *Asset) MarkAssetsCache(ctx context.Context, marks []*types.AssetCacheMark) error {
+	return a.mgr.MarkAssets(marks)
+}
+
+// GetCacheComposition returns the pinned and cacheable assets of the node
+func (a *Asset) GetCacheComposition(ctx context.Context) (*types.CacheComposition, error) {
+	return a.mgr.CacheComposition()
+}
@@ -0,0 +1,124 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/google/uuid"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-car/v2"
+)
+
+// IngestUserAsset saves the uploaded data as the asset of the user and reports it to the scheduler,
+// the data is converted to car if it is not a car, returns the root cid of the asset
+func (m *Manager) IngestUserAsset(ctx context.Context, payload *types.AuthUserIngestAsset, r io.Reader, isCar bool) (cid.Cid, error) {
+	root, carSize, existed, err := m.importUserAsset(ctx, payload.UserID, r, isCar)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+
+	result := &types.IngestAssetResult{IngestID: payload.IngestID, AssetCID: root.String(), AssetSize: carSize}
+	if err = m.IngestAssetCompleted(ctx, result); err != nil {
+		m.deleteImportedAsset(root, existed)
+		return cid.Cid{}, err
+	}
+
+	log.Debugf("ingested asset %s of user %s, size %d", root.String(), payload.UserID, carSize)
+	return root, nil
+}
+
+// importUserAsset saves the data as the asset of the user, the data is converted to car if it is not a car,
+// returns the root cid, the size of the car and whether the asset existed before
+func (m *Manager) importUserAsset(ctx context.Context, userID string, r io.Reader, isCar bool) (cid.Cid, int64, bool, error) {
+	tempFilePath := path.Join(os.TempDir(), uuid.NewString())
+	if err := saveToFile(tempFilePath, r); err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+	defer os.RemoveAll(tempFilePath)
+
+	carFilePath := tempFilePath
+	if !isCar {
+		carFilePath = path.Join(os.TempDir(), uuid.NewString())
+		if _, err := createCar(tempFilePath, carFilePath); err != nil {
+			return cid.Cid{}, 0, false, err
+		}
+		defer os.RemoveAll(carFilePath)
+	}
+
+	f, err := os.Open(carFilePath)
+	if err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+	defer f.Close()
+
+	root, err := carRoot(f)
+	if err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+
+	fInfo, err := f.Stat()
+	if err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+
+	existed, err := m.AssetExists(root)
+	if err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+
+	if err = m.SaveUserAsset(ctx, userID, root, fInfo.Size(), f); err != nil {
+		return cid.Cid{}, 0, false, err
+	}
+
+	return root, fInfo.Size(), existed, nil
+}
+
+// deleteImportedAsset removes the imported asset rejected by the scheduler, the asset existed before is kept
+func (m *Manager) deleteImportedAsset(root cid.Cid, existed bool) {
+	if existed {
+		return
+	}
+
+	if err := m.DeleteAsset(root); err != nil {
+		log.Errorf("delete imported asset %s error %s", root.String(), err.Error())
+	}
+}
+
+func saveToFile(filePath string, r io.Reader) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// carRoot returns the single root of the car
+func carRoot(r io.ReaderAt) (cid.Cid, error) {
+	cr, err := car.NewReader(r)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	defer cr.Close()
+
+	roots, err := cr.Roots()
+	if err != nil {
+		return cid.Cid{}, err
+	}
+
+	if len(roots) != 1 {
+		return cid.Cid{}, fmt.Errorf("car must have one root, but got %d", len(roots))
+	}
+
+	return roots[0], nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"sync"
 	"syscall"
@@ -19,6 +20,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/asset/index"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
 	"github.com/Filecoin-Titan/titan/node/ipld"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	validate "github.com/Filecoin-Titan/titan/node/validation"
 	"github.com/ipfs/go-cid"
@@ -49,11 +51,16 @@ type Manager struct {
 	pullCh       chan bool
 	ipfsAPIURL   string
 	lru          *lruCache
+	cache        *assetCache
 	storage.Storage
 	api.Scheduler
 	pullParallel int
 	pullTimeout  int
 	pullRetry    int
+	nodeID       string
+	privateKey   crypto.Signer
+	// proxyClient pulls the assets through the outbound proxy of the node, nil if pulled directly
+	proxyClient *http.Client
 
 	// save asset upload status
 	uploadingAssets *sync.Map
@@ -70,6 +77,11 @@ type ManagerOptions struct {
 	PullParallel int
 	PullTimeout  int
 	PullRetry    int
+	// NodeID and PrivateKey sign the workload receipts of the bytes pulled from the other nodes
+	NodeID     string
+	PrivateKey crypto.Signer
+	// ProxyURL the outbound proxy the assets are pulled through, pulled directly if empty
+	ProxyURL string
 }
 
 // NewManager creates a new instance of Manager
@@ -79,7 +91,15 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		return nil, err
 	}
 
+	var proxyClient *http.Client
+	if opts.ProxyURL != "" {
+		if proxyClient, err = client.NewProxyHTTPClient(opts.ProxyURL); err != nil {
+			return nil, err
+		}
+	}
+
 	m := &Manager{
+		proxyClient:  proxyClient,
 		waitList:     make([]*assetWaiter, 0),
 		waitListLock: &sync.Mutex{},
 		pullCh:       make(chan bool),
@@ -90,14 +110,23 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		pullParallel: opts.PullParallel,
 		pullTimeout:  opts.PullTimeout,
 		pullRetry:    opts.PullRetry,
+		nodeID:       opts.NodeID,
+		privateKey:   opts.PrivateKey,
 
 		uploadingAssets:  &sync.Map{},
 		pullAssetErrMsgs: &sync.Map{},
 	}
 
+	cache, err := m.loadCache()
+	if err != nil {
+		return nil, err
+	}
+	m.cache = cache
+
 	m.restoreWaitListFromStore()
 
 	go m.start()
+	go m.startCacheEviction()
 
 	go m.syncDataWithAssetView()
 
@@ -172,6 +201,11 @@ func (m *Manager) doPullAsset() {
 		timeout:    m.pullTimeout,
 		retry:      m.pullRetry,
 		httpClient: client.NewHTTP3Client(),
+		proxied:    m.proxyClient != nil,
+	}
+
+	if opts.proxied {
+		opts.httpClient = m.proxyClient
 	}
 
 	assetPuller, err := m.restoreAssetPullerOrNew(opts)
@@ -224,6 +258,19 @@ func (m *Manager) removeAssetFromWaitList(root cid.Cid) *assetWaiter {
 	return nil
 }
 
+// isInWaitList returns true if the asset is waiting to be pulled or pulling, the asset is removed from the waitList if it is deleted
+func (m *Manager) isInWaitList(root cid.Cid) bool {
+	m.waitListLock.Lock()
+	defer m.waitListLock.Unlock()
+
+	for _, waiter := range m.waitList {
+		if waiter.Root.Hash().String() == root.Hash().String() {
+			return true
+		}
+	}
+	return false
+}
+
 // addToWaitList adds an assetWaiter to waitList if the asset with the root CID is not already waiting to be downloaded
 func (m *Manager) addToWaitList(root cid.Cid, dss []*types.CandidateDownloadInfo, isSyncData bool) {
 	m.waitListLock.Lock()
@@ -281,6 +328,12 @@ func (m *Manager) onPullAssetFinish(puller *assetPuller, isSyncData bool) {
 			}
 		}
 
+	} else if puller.doneSize > 0 && m.isInWaitList(puller.root) {
+		// keep the pulled blocks, the next pull of the asset resumes from them
+		log.Infof("pull asset failed, keep %d pulled blocks of %s", len(puller.blocksPulledSuccessList), puller.root.String())
+		if err := m.savePuller(puller); err != nil {
+			log.Errorf("save puller error:%s", err.Error())
+		}
 	} else {
 		log.Infof("pull asset failed, remove %s", puller.root.String())
 		if err := m.DeleteAsset(puller.root); err != nil {
@@ -288,8 +341,10 @@ func (m *Manager) onPullAssetFinish(puller *assetPuller, isSyncData bool) {
 		}
 	}
 
-	if err := m.DeletePuller(puller.root); err != nil && !os.IsNotExist(err) {
-		log.Errorf("remove asset puller error:%s", err.Error())
+	if puller.isPulledComplete() {
+		if err := m.DeletePuller(puller.root); err != nil && !os.IsNotExist(err) {
+			log.Errorf("remove asset puller error:%s", err.Error())
+		}
 	}
 
 	if err := m.submitPullerWorkloadReport(puller); err != nil {
@@ -299,7 +354,7 @@ func (m *Manager) onPullAssetFinish(puller *assetPuller, isSyncData bool) {
 	speed := float64(puller.totalSize) / float64(time.Since(puller.startTime)) * float64(time.Second)
 	if speed > 0 {
 		log.Debugf("UpdateBandwidths, bandwidthDown %d", int64(speed))
-		m.Scheduler.UpdateBandwidths(context.Background(), int64(speed), 0)
+		m.updateBandwidths(int64(speed), 0)
 	}
 
 	if len(puller.errMsgs) > 0 {
@@ -388,6 +443,10 @@ func (m *Manager) DeleteAsset(root cid.Cid) error {
 		log.Errorf("DeleteBlockCount error %s", err.Error())
 	}
 
+	if err := m.unmarkAsset(root); err != nil {
+		log.Errorf("unmarkAsset error %s", err.Error())
+	}
+
 	return m.RemoveAssetFromView(context.Background(), root)
 }
 
@@ -525,6 +584,9 @@ func (m *Manager) progressForAssetPulledFailed(root cid.Cid) (*types.AssetPullPr
 
 // GetBlock returns the block with the given CID from the LRU cache
 func (m *Manager) GetBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	if root.Equals(block) {
+		m.cache.recordAccess(root)
+	}
 	return m.lru.getBlock(ctx, root, block)
 }
 
@@ -712,7 +774,67 @@ func (m *Manager) submitPullerWorkloadReport(puller *assetPuller) error {
 		return err
 	}
 
-	return m.SubmitUserWorkloadReport(context.Background(), bytes.NewBuffer(cipherText))
+	if err = m.SubmitUserWorkloadReport(context.Background(), bytes.NewBuffer(cipherText)); err != nil {
+		return err
+	}
+
+	return m.submitWorkloadReceipts(puller)
+}
+
+// updateBandwidths reports the bandwidths measured by the node to the scheduler, signed by the node
+func (m *Manager) updateBandwidths(bandwidthDown, bandwidthUp int64) {
+	if m.privateKey == nil {
+		return
+	}
+
+	report := &types.BandwidthReport{BandwidthDown: bandwidthDown, BandwidthUp: bandwidthUp, ReportTime: time.Now()}
+	sign, err := nodekey.Sign(m.privateKey, report.SignData())
+	if err != nil {
+		log.Errorf("sign bandwidth report error %s", err.Error())
+		return
+	}
+	report.Sign = sign
+
+	if err = m.UpdateBandwidths(context.Background(), report); err != nil {
+		log.Errorf("UpdateBandwidths error %s", err.Error())
+	}
+}
+
+// submitWorkloadReceipts submits the receipts of the bytes the puller downloaded from the other nodes, signed by the node
+func (m *Manager) submitWorkloadReceipts(puller *assetPuller) error {
+	if m.privateKey == nil || len(puller.workloadReports) == 0 {
+		return nil
+	}
+
+	receipts := make([]*types.WorkloadReceipt, 0, len(puller.workloadReports))
+	for _, report := range puller.workloadReports {
+		if report.NodeID == "" || report.Workload.DownloadSize == 0 {
+			continue
+		}
+
+		receipt := &types.WorkloadReceipt{
+			TokenID:      report.TokenID,
+			NodeID:       report.NodeID,
+			ClientID:     m.nodeID,
+			DownloadSize: report.Workload.DownloadSize,
+			StartTime:    report.Workload.StartTime,
+			EndTime:      report.Workload.EndTime,
+		}
+
+		sign, err := nodekey.Sign(m.privateKey, receipt.SignContent())
+		if err != nil {
+			return err
+		}
+		receipt.Sign = sign
+
+		receipts = append(receipts, receipt)
+	}
+
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	return m.SubmitWorkloadReceipts(context.Background(), receipts)
 }
 
 func (m *Manager) SaveUserAsset(ctx context.Context, userID string, root cid.Cid, assetSize int64, r io.Reader) error {
@@ -0,0 +1,45 @@
+package asset
+
+import (
+	"context"
+	"io"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	carv2 "github.com/ipld/go-car/v2"
+	"golang.org/x/xerrors"
+)
+
+// GetAssetManifest returns the blocks of the asset stored on the node
+func (m *Manager) GetAssetManifest(ctx context.Context, root cid.Cid) (*types.AssetManifest, error) {
+	reader, err := m.GetAsset(root)
+	if err != nil {
+		return nil, xerrors.Errorf("get asset %s %w", root.String(), err)
+	}
+	defer reader.Close() //nolint:errcheck
+
+	br, err := carv2.NewBlockReader(reader, carv2.ZeroLengthSectionAsEOF(true))
+	if err != nil {
+		return nil, xerrors.Errorf("new block reader %w", err)
+	}
+
+	manifest := &types.AssetManifest{CID: root.String(), Blocks: make([]*types.ManifestBlock, 0)}
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		meta, err := br.SkipNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("read block %w", err)
+		}
+
+		manifest.Blocks = append(manifest.Blocks, &types.ManifestBlock{CID: meta.Cid.String(), Size: int64(meta.Size)})
+		manifest.Size += int64(meta.Size)
+	}
+
+	return manifest, nil
+}
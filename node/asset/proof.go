@@ -0,0 +1,89 @@
+package asset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/docker/go-units"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// upper limits of a storage challenge, protect the node from oversized challenges
+	maxChallengeBlocks     = 32
+	maxChallengeSampleSize = 64 * units.KiB
+)
+
+// ProveStorage picks the blocks of the asset by the seed of the challenge, hashes the sample at a random offset
+// of each block and signs the result. The scheduler compares the hashes with the ones of another node holding the asset
+func (a *Asset) ProveStorage(ctx context.Context, challenge *types.StorageChallenge) (*types.StorageChallengeResult, error) {
+	if challenge.BlockCount <= 0 || challenge.BlockCount > maxChallengeBlocks {
+		return nil, xerrors.Errorf("invalid challenge block count %d", challenge.BlockCount)
+	}
+
+	if challenge.SampleSize <= 0 || challenge.SampleSize > maxChallengeSampleSize {
+		return nil, xerrors.Errorf("invalid challenge sample size %d", challenge.SampleSize)
+	}
+
+	root, err := cid.Decode(challenge.CID)
+	if err != nil {
+		return nil, err
+	}
+
+	cids, err := a.mgr.GetBlocksOfAsset(root, challenge.Seed, challenge.BlockCount)
+	if err != nil {
+		return nil, xerrors.Errorf("get blocks of asset: %w", err)
+	}
+
+	data := make([][]byte, 0, len(cids))
+	for _, str := range cids {
+		c, err := cid.Decode(str)
+		if err != nil {
+			return nil, err
+		}
+
+		blk, err := a.mgr.GetBlock(ctx, root, c)
+		if err != nil {
+			return nil, xerrors.Errorf("get block %s: %w", str, err)
+		}
+		data = append(data, blk.RawData())
+	}
+
+	result := &types.StorageChallengeResult{ChallengeID: challenge.ID, Hashes: sampleHashes(challenge, data)}
+	if a.privateKey != nil {
+		result.Sign, err = nodekey.Sign(a.privateKey, result.SignData())
+		if err != nil {
+			return nil, xerrors.Errorf("sign result: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// sampleHashes returns the hashes of the samples of the blocks, the offsets of the samples are drawn from the seed
+// of the challenge so the nodes holding the same blocks return the same hashes
+func sampleHashes(challenge *types.StorageChallenge, blocks [][]byte) []string {
+	r := rand.New(rand.NewSource(challenge.Seed)) //nolint:gosec // the offsets only need to be reproducible
+
+	hashes := make([]string, 0, len(blocks))
+	for _, data := range blocks {
+		offset := 0
+		if len(data) > 0 {
+			offset = r.Intn(len(data))
+		}
+		end := offset + challenge.SampleSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		sum := sha256.Sum256(data[offset:end])
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+	}
+
+	return hashes
+}
@@ -0,0 +1,36 @@
+package asset
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestSampleHashes(t *testing.T) {
+	blocks := [][]byte{bytes.Repeat([]byte("a"), 1000), []byte("b"), bytes.Repeat([]byte("c"), 10)}
+	challenge := &types.StorageChallenge{Seed: 42, SampleSize: 100}
+
+	hashes := sampleHashes(challenge, blocks)
+	if len(hashes) != len(blocks) {
+		t.Fatalf("expected %d hashes, got %d", len(blocks), len(hashes))
+	}
+
+	// the nodes holding the same blocks return the same hashes
+	again := sampleHashes(challenge, blocks)
+	for i := range hashes {
+		if hashes[i] != again[i] {
+			t.Fatalf("hash %d is not reproducible", i)
+		}
+	}
+
+	blocks[0] = bytes.Repeat([]byte("x"), 1000)
+	if sampleHashes(challenge, blocks)[0] == hashes[0] {
+		t.Fatal("expected the hash of the changed block to change")
+	}
+
+	// an empty block is hashed without a sample offset
+	if hashes = sampleHashes(challenge, [][]byte{{}}); len(hashes) != 1 {
+		t.Fatalf("expected 1 hash of the empty block, got %d", len(hashes))
+	}
+}
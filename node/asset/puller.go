@@ -64,6 +64,8 @@ type pullerOptions struct {
 	// retry times of pull block on failed
 	retry      int
 	httpClient *http.Client
+	// the blocks are fetched over tcp through the outbound proxy of the http client
+	proxied bool
 }
 
 // newAssetPuller creates a new asset puller with the given options
@@ -74,7 +76,11 @@ func newAssetPuller(opts *pullerOptions) (*assetPuller, error) {
 
 	var blockFetcher fetcher.BlockFetcher
 	if len(opts.dss) != 0 {
-		blockFetcher = fetcher.NewCandidateFetcher(opts.httpClient)
+		if opts.proxied {
+			blockFetcher = fetcher.NewProxiedCandidateFetcher(opts.httpClient)
+		} else {
+			blockFetcher = fetcher.NewCandidateFetcher(opts.httpClient)
+		}
 	} else {
 		blockFetcher = fetcher.NewIPFSClient(opts.ipfsAPIURL)
 	}
@@ -118,6 +124,10 @@ func (ap *assetPuller) pullAsset() error {
 		log.Errorf("pull asset from aws %s", err.Error())
 	}
 
+	if ok, err := ap.pullMissingBlocks(); ok || err != nil {
+		return err
+	}
+
 	nextLayerCIDs := ap.blocksWaitList
 	if len(nextLayerCIDs) == 0 {
 		nextLayerCIDs = append(nextLayerCIDs, ap.root.String())
@@ -142,6 +152,85 @@ func (ap *assetPuller) pullAsset() error {
 	return nil
 }
 
+// pullMissingBlocks resumes an interrupted pull, the blocks of the asset are listed by the manifest
+// of a download source and only the blocks missing on the node are pulled.
+// It returns false if the node has no blocks of the asset or no download source serves the manifest
+func (ap *assetPuller) pullMissingBlocks() (bool, error) {
+	cFetcher, ok := ap.bFetcher.(*fetcher.CandidateFetcher)
+	if !ok || len(ap.downloadSources) == 0 {
+		return false, nil
+	}
+
+	pulled, err := ap.storage.GetPulledBlocks(context.Background(), ap.root)
+	if err != nil || len(pulled) == 0 {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ap.timeout)*time.Second)
+	ap.cancel = cancel
+	manifest, err := cFetcher.FetchManifest(ctx, ap.root.String(), ap.downloadSources)
+	cancel()
+	if err != nil {
+		log.Warnf("fetch manifest of %s error %s, resume without manifest", ap.root.String(), err.Error())
+		return false, nil
+	}
+
+	missing, done, doneSize := diffManifest(manifest, pulled)
+
+	ap.totalSize = uint64(manifest.Size)
+	ap.doneSize = uint64(doneSize)
+	ap.blocksPulledSuccessList = done
+	ap.blocksWaitList = missing
+	ap.nextLayerCIDs = make([]string, 0)
+
+	if ap.totalSize-ap.doneSize >= uint64(ap.usableDiskSpace()) {
+		return true, fmt.Errorf("not enough disk space, need %d, usable %d, pull asset %s", ap.totalSize-ap.doneSize, ap.usableDiskSpace(), ap.root.String())
+	}
+
+	log.Infof("resume asset %s, pull %d of %d blocks, %d of %d bytes", ap.root.String(), len(missing), len(manifest.Blocks), manifest.Size-doneSize, manifest.Size)
+
+	for len(ap.blocksWaitList) > 0 {
+		doLen := len(ap.blocksWaitList)
+		if doLen > ap.parallel {
+			doLen = ap.parallel
+		}
+
+		blocks := ap.getBlocksFromWaitList(doLen)
+		ret, err := ap.pullBlocks(blocks)
+		if err != nil {
+			return true, err
+		}
+
+		ap.doneSize += ret.doneSize
+		ap.blocksPulledSuccessList = append(ap.blocksPulledSuccessList, blocks...)
+		ap.removeBlocksFromWaitList(doLen)
+	}
+
+	return true, nil
+}
+
+// diffManifest compares the manifest with the blocks pulled by the node, the pulled blocks are keyed by block hash.
+// It returns the blocks missing on the node, the blocks pulled already and their size
+func diffManifest(manifest *types.AssetManifest, pulled map[string]int64) (missing, done []string, doneSize int64) {
+	missing = make([]string, 0)
+	done = make([]string, 0, len(pulled))
+
+	for _, block := range manifest.Blocks {
+		c, err := cid.Decode(block.CID)
+		if err == nil {
+			if size, ok := pulled[c.Hash().String()]; ok && size == block.Size {
+				done = append(done, block.CID)
+				doneSize += block.Size
+				continue
+			}
+		}
+
+		missing = append(missing, block.CID)
+	}
+
+	return missing, done, doneSize
+}
+
 // pullBlocksWithBreadthFirst pulls blocks with breadth first algorithm.
 func (ap *assetPuller) pullBlocksWithBreadthFirst(layerCIDs []string) (result *pulledResult, err error) {
 	ap.blocksWaitList = layerCIDs
@@ -3,6 +3,7 @@ package asset
 import (
 	"testing"
 
+	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
@@ -46,3 +47,31 @@ func TestCache(t *testing.T) {
 		return
 	}
 }
+
+func TestDiffManifest(t *testing.T) {
+	root, _ := cid.Decode("QmTcAg1KeDYJFpTJh3rkZGLhnnVKeXWNtjwPufjVvwPTpG")
+	leaf, _ := cid.Decode("bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku")
+	other, _ := cid.Decode("QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG")
+
+	manifest := &types.AssetManifest{
+		CID: root.String(),
+		Blocks: []*types.ManifestBlock{
+			{CID: root.String(), Size: 10},
+			{CID: leaf.String(), Size: 20},
+			{CID: other.String(), Size: 30},
+		},
+		Size: 60,
+	}
+
+	// the leaf block is truncated
+	pulled := map[string]int64{root.Hash().String(): 10, leaf.Hash().String(): 5}
+
+	missing, done, doneSize := diffManifest(manifest, pulled)
+	if len(missing) != 2 || missing[0] != leaf.String() || missing[1] != other.String() {
+		t.Errorf("missing %v", missing)
+	}
+
+	if len(done) != 1 || done[0] != root.String() || doneSize != 10 {
+		t.Errorf("done %v %d", done, doneSize)
+	}
+}
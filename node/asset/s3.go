@@ -0,0 +1,46 @@
+package asset
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// objectHasher computes the etag and the size of the object
+type objectHasher struct {
+	hash.Hash
+	size int64
+}
+
+func (o *objectHasher) Write(p []byte) (int, error) {
+	o.size += int64(len(p))
+	return o.Hash.Write(p)
+}
+
+// PutS3Object saves the content of the s3 object as the asset of the user and records the object on the scheduler
+// for the user of the grant, the content is always converted to car, returns the recorded object
+func (m *Manager) PutS3Object(ctx context.Context, grant *types.S3Grant, object *types.S3Object, r io.Reader) (*types.S3Object, error) {
+	hasher := &objectHasher{Hash: md5.New()}
+
+	root, carSize, existed, err := m.importUserAsset(ctx, object.UserID, io.TeeReader(r, hasher), false)
+	if err != nil {
+		return nil, err
+	}
+
+	object.AssetCID = root.String()
+	object.Size = hasher.size
+	object.CarSize = carSize
+	object.ETag = hex.EncodeToString(hasher.Sum(nil))
+
+	if err = m.S3PutObject(ctx, grant, object); err != nil {
+		m.deleteImportedAsset(root, existed)
+		return nil, err
+	}
+
+	log.Debugf("put s3 object %s/%s of user %s, cid %s", object.Bucket, object.Key, object.UserID, object.AssetCID)
+	return object, nil
+}
@@ -59,6 +59,34 @@ func (a *asset) storeBlocks(ctx context.Context, root cid.Cid, blks []blocks.Blo
 	return nil
 }
 
+// pulledBlocks returns the sizes of the blocks of the asset pulled but not stored to car yet, the key is the block hash.
+func (a *asset) pulledBlocks(root cid.Cid) (map[string]int64, error) {
+	out := make(map[string]int64)
+
+	baseDir, err := a.assetsPaths.findPath(root)
+	if err != nil {
+		return out, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(baseDir, root.Hash().String()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return out, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		out[entry.Name()] = info.Size()
+	}
+
+	return out, nil
+}
+
 // storeBlocksToCar stores the asset to the file system.
 func (a *asset) storeBlocksToCar(ctx context.Context, root cid.Cid) error {
 	baseDir, err := a.assetsPaths.findPath(root)
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+var (
+	cacheMarkPrefix = ds.NewKey("mark")
+	cacheConfigKey  = ds.NewKey("config")
+)
+
+// cache stores the cache marks of the assets and the cache config of the node
+type cache struct {
+	ds ds.Batching
+}
+
+// newCache initializes a new cache store with the given base directory
+func newCache(baseDir string) (*cache, error) {
+	ds, err := createDatastore(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache{ds: ds}, nil
+}
+
+func (c *cache) storeMark(ctx context.Context, root cid.Cid, data []byte) error {
+	return c.ds.Put(ctx, cacheMarkPrefix.ChildString(root.Hash().String()), data)
+}
+
+func (c *cache) deleteMark(ctx context.Context, root cid.Cid) error {
+	err := c.ds.Delete(ctx, cacheMarkPrefix.ChildString(root.Hash().String()))
+	if err == ds.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// marks returns the marks of the assets, the key is the hash of the asset
+func (c *cache) marks(ctx context.Context) (map[string][]byte, error) {
+	results, err := c.ds.Query(ctx, query.Query{Prefix: cacheMarkPrefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close() //nolint:errcheck
+
+	out := make(map[string][]byte)
+	for result := range results.Next() {
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		out[ds.NewKey(result.Key).BaseNamespace()] = result.Value
+	}
+
+	return out, nil
+}
+
+func (c *cache) storeConfig(ctx context.Context, data []byte) error {
+	return c.ds.Put(ctx, cacheConfigKey, data)
+}
+
+func (c *cache) config(ctx context.Context) ([]byte, error) {
+	data, err := c.ds.Get(ctx, cacheConfigKey)
+	if err == ds.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
@@ -24,6 +24,7 @@ const (
 	countDir      = "count"
 	assetSuffix   = ".car"
 	assetsViewDir = "assets-view"
+	cacheDir      = "cache"
 	sizeOfBucket  = 128
 )
 
@@ -35,6 +36,7 @@ type Manager struct {
 	puller       *puller
 	blockCount   *blockCount
 	assetsView   *assetsView
+	cache        *cache
 	minioService IMinioService
 }
 
@@ -78,10 +80,16 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		return nil, err
 	}
 
+	cache, err := newCache(filepath.Join(opts.MetaDataPath, cacheDir))
+	if err != nil {
+		return nil, err
+	}
+
 	waitList := newWaitList(filepath.Join(opts.MetaDataPath, waitListFile))
 	return &Manager{
 		asset:        asset,
 		assetsView:   assetsView,
+		cache:        cache,
 		wl:           waitList,
 		puller:       puller,
 		blockCount:   blockCount,
@@ -116,6 +124,11 @@ func (m *Manager) StoreBlocks(ctx context.Context, root cid.Cid, blks []blocks.B
 	return m.asset.storeBlocks(ctx, root, blks)
 }
 
+// GetPulledBlocks returns the sizes of the blocks of an asset pulled but not stored to car yet, the key is the block hash
+func (m *Manager) GetPulledBlocks(ctx context.Context, root cid.Cid) (map[string]int64, error) {
+	return m.asset.pulledBlocks(root)
+}
+
 // StoreBlocksToCar stores a single asset
 func (m *Manager) StoreBlocksToCar(ctx context.Context, root cid.Cid) error {
 	return m.asset.storeBlocksToCar(ctx, root)
@@ -216,6 +229,33 @@ func (m *Manager) GetWaitList() ([]byte, error) {
 	return m.wl.get()
 }
 
+// cache API
+
+// StoreCacheMark stores the cache mark of the asset
+func (m *Manager) StoreCacheMark(ctx context.Context, root cid.Cid, data []byte) error {
+	return m.cache.storeMark(ctx, root, data)
+}
+
+// DeleteCacheMark removes the cache mark of the asset
+func (m *Manager) DeleteCacheMark(ctx context.Context, root cid.Cid) error {
+	return m.cache.deleteMark(ctx, root)
+}
+
+// GetCacheMarks returns the cache marks of the assets, the key is the hash of the asset
+func (m *Manager) GetCacheMarks(ctx context.Context) (map[string][]byte, error) {
+	return m.cache.marks(ctx)
+}
+
+// StoreCacheConfig stores the cache config of the node
+func (m *Manager) StoreCacheConfig(ctx context.Context, data []byte) error {
+	return m.cache.storeConfig(ctx, data)
+}
+
+// GetCacheConfig returns the cache config of the node, nil if not set
+func (m *Manager) GetCacheConfig(ctx context.Context) ([]byte, error) {
+	return m.cache.config(ctx)
+}
+
 // DiskStat API
 
 // GetDiskUsageStat retrieves the disk usage statistics
@@ -237,6 +277,15 @@ func (m *Manager) GetDiskUsageStat() (totalSpace, usage float64) {
 	return float64(usageStat.Total), usageStat.UsedPercent
 }
 
+// GetProbePath returns the directory used by the disk probe of hardware challenges
+func (m *Manager) GetProbePath() string {
+	if len(m.opts.AssetsPaths) > 0 {
+		return m.opts.AssetsPaths[0]
+	}
+
+	return m.opts.MetaDataPath
+}
+
 // GetFileSystemType retrieves the type of the file system
 func (m *Manager) GetFileSystemType() string {
 	return "not implement"
@@ -16,6 +16,7 @@ type Storage interface {
 	DeletePuller(c cid.Cid) error
 
 	StoreBlocks(ctx context.Context, root cid.Cid, blks []blocks.Block) error
+	GetPulledBlocks(ctx context.Context, root cid.Cid) (map[string]int64, error)
 
 	StoreBlocksToCar(ctx context.Context, root cid.Cid) error
 	StoreUserAsset(ctx context.Context, userID string, root cid.Cid, assetSize int64, r io.Reader) error
@@ -36,6 +37,13 @@ type Storage interface {
 	AddAssetToView(ctx context.Context, root cid.Cid) error
 	RemoveAssetFromView(ctx context.Context, root cid.Cid) error
 
+	// cache marks and config
+	StoreCacheMark(ctx context.Context, root cid.Cid, data []byte) error
+	DeleteCacheMark(ctx context.Context, root cid.Cid) error
+	GetCacheMarks(ctx context.Context) (map[string][]byte, error)
+	StoreCacheConfig(ctx context.Context, data []byte) error
+	GetCacheConfig(ctx context.Context) ([]byte, error)
+
 	StoreWaitList(data []byte) error
 	GetWaitList() ([]byte, error)
 
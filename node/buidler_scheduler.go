@@ -1,7 +1,6 @@
 package node
 
 import (
-	"crypto/rsa"
 	"errors"
 
 	"github.com/Filecoin-Titan/titan/api"
@@ -11,16 +10,51 @@ import (
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/repo"
 	"github.com/Filecoin-Titan/titan/node/scheduler"
+	"github.com/Filecoin-Titan/titan/node/scheduler/alert"
 	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/bulk"
+	"github.com/Filecoin-Titan/titan/node/scheduler/ca"
+	"github.com/Filecoin-Titan/titan/node/scheduler/coldstorage"
+	"github.com/Filecoin-Titan/titan/node/scheduler/commitment"
+	"github.com/Filecoin-Titan/titan/node/scheduler/configpush"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/decision"
+	"github.com/Filecoin-Titan/titan/node/scheduler/denylist"
+	"github.com/Filecoin-Titan/titan/node/scheduler/discovery"
+	"github.com/Filecoin-Titan/titan/node/scheduler/dnsrouting"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/export"
+	"github.com/Filecoin-Titan/titan/node/scheduler/featureflag"
 	"github.com/Filecoin-Titan/titan/node/scheduler/filelogger"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leaderboard"
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/locindex"
 	"github.com/Filecoin-Titan/titan/node/scheduler/nat"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/nodediag"
+	"github.com/Filecoin-Titan/titan/node/scheduler/outbox"
+	"github.com/Filecoin-Titan/titan/node/scheduler/overload"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	"github.com/Filecoin-Titan/titan/node/scheduler/pointsepoch"
+	"github.com/Filecoin-Titan/titan/node/scheduler/relay"
+	"github.com/Filecoin-Titan/titan/node/scheduler/replication"
+	"github.com/Filecoin-Titan/titan/node/scheduler/retention"
+	"github.com/Filecoin-Titan/titan/node/scheduler/retrievalprobe"
+	"github.com/Filecoin-Titan/titan/node/scheduler/settlement"
+	"github.com/Filecoin-Titan/titan/node/scheduler/signaling"
+	"github.com/Filecoin-Titan/titan/node/scheduler/speedtest"
+	"github.com/Filecoin-Titan/titan/node/scheduler/storageproof"
 	"github.com/Filecoin-Titan/titan/node/scheduler/sync"
+	"github.com/Filecoin-Titan/titan/node/scheduler/tenant"
+	"github.com/Filecoin-Titan/titan/node/scheduler/token"
+	"github.com/Filecoin-Titan/titan/node/scheduler/traffic"
+	"github.com/Filecoin-Titan/titan/node/scheduler/upgrade"
 	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
+	"github.com/Filecoin-Titan/titan/node/scheduler/video"
+	"github.com/Filecoin-Titan/titan/node/scheduler/webhook"
 	"github.com/Filecoin-Titan/titan/node/scheduler/workload"
-	"github.com/filecoin-project/pubsub"
 	"github.com/jmoiron/sqlx"
 
 	"go.uber.org/fx"
@@ -57,26 +91,57 @@ func ConfigScheduler(c interface{}) Option {
 	return Options(
 		Override(new(dtypes.ServerID), modules.NewServerID),
 		Override(new(*config.SchedulerCfg), cfg),
-		Override(new(*etcdcli.Client), modules.RegisterToEtcd),
+		Override(new(*etcdcli.Client), modules.NewEtcdClient),
 		Override(new(*leadership.Manager), leadership.NewManager),
 		Override(new(*filelogger.Manager), filelogger.NewManager),
 		Override(new(*sqlx.DB), modules.NewDB),
 		Override(new(*db.SQLDB), db.NewSQLDB),
-		Override(new(*pubsub.PubSub), modules.NewPubSub),
+		Override(new(*eventbus.Bus), modules.NewEventBus),
 		Override(InitDataTables, db.InitTables),
+		Override(new(*overload.Manager), overload.NewManager),
+		Override(new(*jobqueue.Queue), jobqueue.NewQueue),
 		Override(new(*node.Manager), node.NewManager),
+		Override(new(*traffic.Manager), traffic.NewManager),
+		Override(new(*settlement.Manager), settlement.NewManager),
+		Override(new(*penalty.Manager), penalty.NewManager),
+		Override(new(*pointsepoch.Manager), pointsepoch.NewManager),
+		Override(new(*commitment.Manager), commitment.NewManager),
+		Override(new(*leaderboard.Manager), leaderboard.NewManager),
+		Override(new(*decision.Manager), decision.NewManager),
+		Override(new(*denylist.Manager), denylist.NewManager),
+		Override(new(*dnsrouting.Manager), dnsrouting.NewManager),
+		Override(new(*speedtest.Manager), speedtest.NewManager),
+		Override(new(*nodediag.Manager), nodediag.NewManager),
+		Override(new(*upgrade.Manager), upgrade.NewManager),
+		Override(new(*configpush.Manager), configpush.NewManager),
+		Override(new(*featureflag.Manager), featureflag.NewManager),
+		Override(new(*retrievalprobe.Manager), retrievalprobe.NewManager),
+		Override(new(*storageproof.Manager), storageproof.NewManager),
+		Override(new(*video.Manager), video.NewManager),
+		Override(new(*signaling.Manager), signaling.NewManager),
+		Override(new(*outbox.Manager), outbox.NewManager),
 		Override(new(*workload.Manager), workload.NewManager),
+		Override(new(*alert.Manager), alert.NewManager),
+		Override(new(*token.Manager), token.NewManager),
+		Override(new(*tenant.Manager), tenant.NewManager),
+		Override(new(*webhook.Manager), webhook.NewManager),
+		Override(new(*coldstorage.Manager), coldstorage.NewManager),
+		Override(new(*replication.Manager), replication.NewManager),
+		Override(new(*ca.Authority), ca.NewAuthority),
 		Override(new(dtypes.MetadataDS), modules.Datastore),
 		Override(new(*assets.Manager), modules.NewStorageManager),
+		Override(new(*locindex.Index), modules.NewLocationIndex),
 		Override(new(*sync.DataSync), sync.NewDataSync),
 		Override(new(*validation.Manager), modules.NewValidation),
+		Override(new(*bulk.Manager), bulk.NewManager),
+		Override(new(*retention.Manager), retention.NewManager),
+		Override(new(*export.Manager), export.NewManager),
+		Override(new(*discovery.Manager), modules.NewDiscovery),
 		Override(new(*nat.Manager), nat.NewManager),
+		Override(new(*relay.Manager), relay.NewManager),
 		Override(new(*scheduler.EdgeUpdateManager), scheduler.NewEdgeUpdateManager),
 		Override(new(dtypes.SetSchedulerConfigFunc), modules.NewSetSchedulerConfigFunc),
 		Override(new(dtypes.GetSchedulerConfigFunc), modules.NewGetSchedulerConfigFunc),
-		Override(new(*rsa.PrivateKey), modules.NewPrivateKey),
-		// func() (*rsa.PrivateKey, error) {
-		// return rsa.GenerateKey(rand.Reader, units.KiB) //nolint:gosec   // need smaller key
-		// }),
+		Override(new(*keys.Ring), modules.NewKeyRing),
 	)
 }
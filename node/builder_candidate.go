@@ -9,8 +9,11 @@ import (
 	"github.com/Filecoin-Titan/titan/node/candidate"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/device"
+	"github.com/Filecoin-Titan/titan/node/diagnostics"
 	"github.com/Filecoin-Titan/titan/node/modules"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/nodeconfig"
+	"github.com/Filecoin-Titan/titan/node/relay"
 	"github.com/Filecoin-Titan/titan/node/repo"
 	datasync "github.com/Filecoin-Titan/titan/node/sync"
 	"github.com/Filecoin-Titan/titan/node/validation"
@@ -48,15 +51,19 @@ func ConfigCandidate(c interface{}) Option {
 
 	return Options(
 		Override(new(*config.CandidateCfg), cfg),
-		Override(new(*device.Device), modules.NewDevice(&cfg.CPU, &cfg.Memory, &cfg.Storage, &cfg.Bandwidth)),
+		Override(new(*device.Device), modules.NewDevice(&cfg.CPU, &cfg.Memory, &cfg.Storage, &cfg.Bandwidth, &cfg.Network, len(cfg.CertificatePath) > 0 && len(cfg.PrivateKeyPath) > 0)),
 		Override(new(dtypes.NodeMetadataPath), dtypes.NodeMetadataPath(cfg.MetadataPath)),
 		Override(new(*config.MinioConfig), &cfg.MinioConfig),
 		Override(new(*storage.Manager), modules.NewNodeStorageManager),
-		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.PullBlockParallel, cfg.PullBlockTimeout, cfg.PullBlockRetry, cfg.IPFSAPIURL)),
+		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.PullBlockParallel, cfg.PullBlockTimeout, cfg.PullBlockRetry, cfg.IPFSAPIURL, cfg.Network.Proxy)),
 		Override(new(*validation.Validation), modules.NewNodeValidation),
 		Override(new(*rate.Limiter), modules.NewRateLimiter),
 		Override(new(*asset.Asset), asset.NewAsset),
 		Override(new(*datasync.DataSync), modules.NewDataSync),
 		Override(new(*candidate.TCPServer), modules.NewTCPServer),
+		Override(new(*relay.Server), relay.NewServer),
+		Override(new(*diagnostics.LogBuffer), diagnostics.NewLogBuffer),
+		Override(new(*diagnostics.LogStreams), diagnostics.NewLogStreams),
+		Override(new(*nodeconfig.Flags), nodeconfig.NewFlags),
 	)
 }
@@ -4,13 +4,17 @@ import (
 	"errors"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/lib/limiter"
 	"github.com/Filecoin-Titan/titan/node/asset"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/device"
+	"github.com/Filecoin-Titan/titan/node/diagnostics"
 	"github.com/Filecoin-Titan/titan/node/edge"
 	"github.com/Filecoin-Titan/titan/node/modules"
+	"github.com/Filecoin-Titan/titan/node/nodeconfig"
 	"github.com/Filecoin-Titan/titan/node/repo"
+	"github.com/Filecoin-Titan/titan/node/rtc"
 	datasync "github.com/Filecoin-Titan/titan/node/sync"
 	"github.com/Filecoin-Titan/titan/node/validation"
 	"go.uber.org/fx"
@@ -47,12 +51,17 @@ func ConfigEdge(c interface{}) Option {
 
 	return Options(
 		Override(new(*config.EdgeCfg), cfg),
-		Override(new(*device.Device), modules.NewDevice(&cfg.CPU, &cfg.Memory, &cfg.Storage, &cfg.Bandwidth)),
+		Override(new(*device.Device), modules.NewDevice(&cfg.CPU, &cfg.Memory, &cfg.Storage, &cfg.Bandwidth, &cfg.Network, false)),
 		Override(new(*config.MinioConfig), &config.MinioConfig{}),
 		Override(new(*storage.Manager), modules.NewNodeStorageManager),
-		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.PullBlockParallel, cfg.PullBlockTimeout, cfg.PullBlockRetry, cfg.IPFSAPIURL)),
+		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.PullBlockParallel, cfg.PullBlockTimeout, cfg.PullBlockRetry, cfg.IPFSAPIURL, cfg.Network.Proxy)),
 		Override(new(*validation.Validation), modules.NewNodeValidation),
 		Override(new(*rate.Limiter), modules.NewRateLimiter),
+		Override(new(*limiter.Shaper), limiter.NewShaper),
+		Override(new(*diagnostics.LogBuffer), diagnostics.NewLogBuffer),
+		Override(new(*diagnostics.LogStreams), diagnostics.NewLogStreams),
+		Override(new(*nodeconfig.Flags), nodeconfig.NewFlags),
+		Override(new(*rtc.Server), rtc.NewServer),
 		Override(new(*asset.Asset), asset.NewAsset),
 		Override(new(*datasync.DataSync), modules.NewDataSync),
 	)
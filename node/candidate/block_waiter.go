@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto"
-	"crypto/rsa"
 	"encoding/gob"
 	"encoding/hex"
 	"fmt"
@@ -12,7 +11,7 @@ import (
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	"github.com/ipfs/go-cid"
 	mh "github.com/multiformats/go-multihash"
 	"golang.org/x/xerrors"
@@ -24,7 +23,7 @@ type blockWaiter struct {
 	result   *api.ValidationResult
 	duration int
 	NodeValidatedResulter
-	privateKey *rsa.PrivateKey
+	privateKey crypto.Signer
 }
 
 type blockWaiterOptions struct {
@@ -32,7 +31,7 @@ type blockWaiterOptions struct {
 	ch         chan tcpMsg
 	duration   int
 	resulter   NodeValidatedResulter
-	privateKey *rsa.PrivateKey
+	privateKey crypto.Signer
 }
 
 // NodeValidatedResulter is the interface to return the validation result
@@ -104,8 +103,7 @@ func (bw *blockWaiter) sendValidateResult() error {
 		return err
 	}
 
-	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	sign, err := titanRsa.Sign(bw.privateKey, buffer.Bytes())
+	sign, err := nodekey.Sign(bw.privateKey, buffer.Bytes())
 	if err != nil {
 		return xerrors.Errorf("sign validate result error: %w", err.Error())
 	}
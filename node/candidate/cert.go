@@ -0,0 +1,151 @@
+package candidate
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/ca"
+	"golang.org/x/xerrors"
+)
+
+// retry interval of a failed certificate renewal
+const renewRetryInterval = time.Minute
+
+// IssueCertFunc requests a client certificate from the scheduler
+type IssueCertFunc func(ctx context.Context, nodeID, sign string, csr []byte) (*types.NodeCertificate, error)
+
+// CertManager obtains the client certificate of mutual tls from the scheduler
+// and renews it after two thirds of its validity
+type CertManager struct {
+	nodeID     string
+	privateKey crypto.Signer
+	issue      IssueCertFunc
+
+	lk        sync.RWMutex
+	cert      *tls.Certificate
+	caPEM     []byte
+	renewTime time.Time
+	renewCh   chan struct{}
+}
+
+// NewCertManager creates the cert manager and obtains the first certificate
+func NewCertManager(ctx context.Context, nodeID string, privateKey crypto.Signer, issue IssueCertFunc) (*CertManager, error) {
+	m := &CertManager{nodeID: nodeID, privateKey: privateKey, issue: issue, renewCh: make(chan struct{}, 1)}
+	if err := m.renew(ctx); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// TLSConfig returns the client tls config which presents the latest certificate and verifies the scheduler by its ca
+func (m *CertManager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the host name is not checked, the certificate chain is verified against the scheduler ca
+		InsecureSkipVerify:    true, //nolint:gosec
+		VerifyPeerCertificate: m.verifyPeer,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			m.lk.RLock()
+			defer m.lk.RUnlock()
+			return m.cert, nil
+		},
+	}
+}
+
+// Run renews the certificate before it expires until ctx is done
+func (m *CertManager) Run(ctx context.Context) {
+	for {
+		m.lk.RLock()
+		wait := time.Until(m.renewTime)
+		m.lk.RUnlock()
+
+		select {
+		case <-time.After(wait):
+		case <-m.renewCh:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := m.renew(ctx); err != nil {
+			log.Errorf("renew certificate err:%s", err.Error())
+
+			m.lk.Lock()
+			m.renewTime = time.Now().Add(renewRetryInterval)
+			m.lk.Unlock()
+		}
+	}
+}
+
+func (m *CertManager) verifyPeer(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+	m.lk.RLock()
+	caPEM := m.caPEM
+	m.lk.RUnlock()
+
+	err := ca.VerifyPeer(caPEM)(rawCerts, chains)
+	if err != nil {
+		// the scheduler key may be rotated, renew to get the new ca certificate
+		select {
+		case m.renewCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return err
+}
+
+func (m *CertManager) renew(ctx context.Context) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: m.nodeID}}, key)
+	if err != nil {
+		return err
+	}
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	sign, err := nodekey.Sign(m.privateKey, csr)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := m.issue(ctx, m.nodeID, hex.EncodeToString(sign), csr)
+	if err != nil {
+		return xerrors.Errorf("IssueNodeCertificate err:%s", err.Error())
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.X509KeyPair(rsp.Certificate, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	m.lk.Lock()
+	m.cert = &cert
+	m.caPEM = rsp.CACertificate
+	m.renewTime = now.Add(rsp.NotAfter.Sub(now) * 2 / 3)
+	m.lk.Unlock()
+
+	log.Infof("certificate renewed, expires at %s", rsp.NotAfter.String())
+	return nil
+}
@@ -0,0 +1,49 @@
+package candidate
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/diagnostics"
+)
+
+// CollectDiagnostics collects the diagnostics bundle of the candidate, the gzipped tar is returned
+// or uploaded to the upload url of the request
+func (c *Candidate) CollectDiagnostics(ctx context.Context, req *types.NodeDiagnosticsReq) ([]byte, error) {
+	src := &diagnostics.Source{
+		Device:    c.Device,
+		Config:    c.Config,
+		Logs:      c.Logs,
+		DiskPaths: append([]string{c.Config.Storage.Path, c.Config.MetadataPath}, c.Config.AssetsPaths...),
+		ProbeNAT:  c.probeNAT,
+	}
+
+	data, err := diagnostics.Collect(ctx, req, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnostics.Deliver(ctx, req, data)
+}
+
+// probeNAT asks the other candidates for the external address of the candidate
+func (c *Candidate) probeNAT(ctx context.Context) ([]*types.NATProbeResult, error) {
+	urls, err := c.Scheduler.GetCandidateURLsForDetectNat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnostics.ProbeNAT(ctx, urls, client.NewHTTP3Client()), nil
+}
+
+// StartLogStream streams the logs of the candidate filtered by the level and the subsystems of the request to the scheduler,
+// rate limited and until the duration of the request elapses
+func (c *Candidate) StartLogStream(ctx context.Context, req *types.LogStreamReq) error {
+	return c.LogStreams.Start(req, c.Scheduler.PushNodeLogs)
+}
+
+// StopLogStream closes the log stream
+func (c *Candidate) StopLogStream(ctx context.Context, id string) error {
+	return c.LogStreams.Stop(id)
+}
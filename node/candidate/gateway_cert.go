@@ -0,0 +1,99 @@
+package candidate
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// the scheduler renews the certificate weeks before it expires, the candidates pick the renewed one up within the interval
+const gatewayCertRefreshInterval = time.Hour
+
+// GetGatewayCertFunc requests the certificate of the gateway domain from the scheduler
+type GetGatewayCertFunc func(ctx context.Context) (*types.GatewayCertificate, error)
+
+// GatewayCertManager keeps the certificate of the gateway domain obtained by the scheduler with acme
+type GatewayCertManager struct {
+	get GetGatewayCertFunc
+
+	lk       sync.RWMutex
+	cert     *tls.Certificate
+	notAfter time.Time
+}
+
+// NewGatewayCertManager creates the gateway cert manager, the certificate is fetched by Run
+func NewGatewayCertManager(get GetGatewayCertFunc) *GatewayCertManager {
+	return &GatewayCertManager{get: get}
+}
+
+// TLSConfig returns the server tls config which presents the latest certificate of the gateway domain
+func (m *GatewayCertManager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: m.getCertificate,
+	}
+}
+
+// Run refreshes the certificate from the scheduler until ctx is done
+func (m *GatewayCertManager) Run(ctx context.Context) {
+	for {
+		wait := gatewayCertRefreshInterval
+		if err := m.refresh(ctx); err != nil {
+			log.Errorf("refresh gateway certificate err:%s", err.Error())
+
+			m.lk.RLock()
+			if m.cert == nil {
+				wait = renewRetryInterval
+			}
+			m.lk.RUnlock()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *GatewayCertManager) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	if m.cert == nil {
+		return nil, xerrors.New("the gateway certificate is not obtained yet")
+	}
+	return m.cert, nil
+}
+
+func (m *GatewayCertManager) refresh(ctx context.Context) error {
+	rsp, err := m.get(ctx)
+	if err != nil {
+		return xerrors.Errorf("GetGatewayCertificate err:%s", err.Error())
+	}
+
+	m.lk.RLock()
+	unchanged := m.cert != nil && rsp.NotAfter.Equal(m.notAfter)
+	m.lk.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.X509KeyPair(rsp.Certificate, rsp.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	m.lk.Lock()
+	m.cert = &cert
+	m.notAfter = rsp.NotAfter
+	m.lk.Unlock()
+
+	log.Infof("gateway certificate of %s updated, expires at %s", rsp.Zone, rsp.NotAfter.String())
+	return nil
+}
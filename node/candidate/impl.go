@@ -12,12 +12,15 @@ import (
 	"github.com/Filecoin-Titan/titan/node/asset"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/Filecoin-Titan/titan/node/relay"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/node/common"
 	"github.com/Filecoin-Titan/titan/node/device"
+	"github.com/Filecoin-Titan/titan/node/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/nodeconfig"
 	datasync "github.com/Filecoin-Titan/titan/node/sync"
 
 	vd "github.com/Filecoin-Titan/titan/node/validation"
@@ -42,9 +45,13 @@ type Candidate struct {
 	*vd.Validation
 	*datasync.DataSync
 
-	Scheduler api.Scheduler
-	Config    *config.CandidateCfg
-	TCPSrv    *TCPServer
+	Scheduler  api.Scheduler
+	Config     *config.CandidateCfg
+	TCPSrv     *TCPServer
+	Relay      *relay.Server
+	Logs       *diagnostics.LogBuffer
+	LogStreams *diagnostics.LogStreams
+	Flags      *nodeconfig.Flags
 }
 
 // WaitQuiet does nothing and returns nil error.
@@ -85,6 +92,11 @@ func (c *Candidate) GetMinioConfig(ctx context.Context) (*types.MinioConfig, err
 	}, nil
 }
 
+// AddRelaySession sets up the session to relay the retrievals of the edge
+func (c *Candidate) AddRelaySession(ctx context.Context, session *types.RelaySession) error {
+	return c.Relay.AddSession(session)
+}
+
 func (c *Candidate) verifyTCPConnectivity(targetURL string) error {
 	url, err := url.ParseRequestURI(targetURL)
 	if err != nil {
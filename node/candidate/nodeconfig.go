@@ -0,0 +1,16 @@
+package candidate
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodeconfig"
+)
+
+// ApplyNodeConfig applies the config pushed by the scheduler and acknowledges its revision
+func (c *Candidate) ApplyNodeConfig(ctx context.Context, push *types.NodeConfigPush) (*types.NodeConfigAck, error) {
+	return nodeconfig.Apply(ctx, push, &nodeconfig.Target{
+		SetCacheConfig: c.Asset.SetCacheConfig,
+		Flags:          c.Flags,
+	}), nil
+}
@@ -3,7 +3,7 @@ package candidate
 import (
 	"bytes"
 	"context"
-	"crypto/rsa"
+	"crypto"
 	"encoding/binary"
 	"fmt"
 	"net"
@@ -26,11 +26,11 @@ type TCPServer struct {
 	config         *config.CandidateCfg
 	blockWaiterMap *sync.Map
 	listen         *net.TCPListener
-	privateKey     *rsa.PrivateKey
+	privateKey     crypto.Signer
 }
 
 // NewTCPServer initializes a new instance of TCPServer.
-func NewTCPServer(cfg *config.CandidateCfg, schedulerAPI api.Scheduler, key *rsa.PrivateKey) *TCPServer {
+func NewTCPServer(cfg *config.CandidateCfg, schedulerAPI api.Scheduler, key crypto.Signer) *TCPServer {
 	return &TCPServer{
 		config:         cfg,
 		blockWaiterMap: &sync.Map{},
@@ -29,7 +29,7 @@ func init() {
 func DefaultEdgeCfg() *EdgeCfg {
 	return &EdgeCfg{
 		Network: Network{
-			ListenAddress: "0.0.0.0:1234",
+			ListenAddress: "[::]:1234",
 			Timeout:       "30s",
 			LocatorURL:    "https://localhost:5000/rpc/v0",
 		},
@@ -43,6 +43,9 @@ func DefaultEdgeCfg() *EdgeCfg {
 		PullBlockRetry:    5,
 		PullBlockParallel: 5,
 
+		VideoPrefetchSegments: 3,
+		IdleMinutes:           60,
+
 		Storage: Storage{
 			StorageGB: 2,
 		},
@@ -67,7 +70,7 @@ func DefaultEdgeCfg() *EdgeCfg {
 func DefaultCandidateCfg() *CandidateCfg {
 	edgeCfg := EdgeCfg{
 		Network: Network{
-			ListenAddress: "0.0.0.0:2345",
+			ListenAddress: "[::]:2345",
 			Timeout:       "30s",
 			LocatorURL:    "https://localhost:5000/rpc/v0",
 		},
@@ -85,6 +88,8 @@ func DefaultCandidateCfg() *CandidateCfg {
 		ValidateDuration:    10,
 		MaxSizeOfUploadFile: 104857600, // 100 MB
 
+		VideoPrefetchSegments: 3,
+
 		Storage: Storage{
 			StorageGB: 64,
 			Path:      "./",
@@ -126,6 +131,7 @@ func DefaultLocatorCfg() *LocatorCfg {
 		EtcdAddresses:      []string{"127.0.0.1:2379"},
 		DefaultAreaID:      "Asia-China-Guangdong-Shenzhen",
 		DNSServerAddress:   "0.0.0.0:53",
+		GatewayRateLimit:   10,
 	}
 }
 
@@ -143,8 +149,12 @@ func DefaultSchedulerCfg() *SchedulerCfg {
 		EnableValidation:        true,
 		EtcdAddresses:           []string{},
 		CandidateReplicas:       0,
+		SeedingBackoff:          30,
+		ReplicaTrimSlack:        2,
 		ValidatorRatio:          1,
 		ValidatorBaseBwDn:       100,
+		ValidatorTaskBwDn:       10,
+		ValidationRoundSpread:   20,
 		ValidationProfit:        0,
 		WorkloadProfit:          0,
 		ElectionCycle:           5,
@@ -154,6 +164,9 @@ func DefaultSchedulerCfg() *SchedulerCfg {
 		AssetPullTaskLimit:      100,
 		UploadAssetReplicaCount: 20,
 		UploadAssetExpiration:   150,
+		CandidateCertValidity:   30,
+		KeyBackend:              "local",
+		MaxRetiredKeys:          2,
 		IPLimit:                 5,
 		FillAssetEdgeCount:      4000,
 		NodeScoreLevel: map[string][]int{
@@ -174,7 +187,64 @@ func DefaultSchedulerCfg() *SchedulerCfg {
 		Weight:                   100,
 		MaxAPIKey:                5,
 		// Maximum number of node registrations for the same IP on the same day
-		MaxNumberOfRegistrations: 15,
+		MaxNumberOfRegistrations:     15,
+		NodeKeyTypes:                 []string{"ed25519", "rsa"},
+		NodeLoginWindow:              120,
+		CommitmentBonusMultiplier:    1.2,
+		CommitmentMinPresence:        0.95,
+		OverloadDBLatencyMs:          500,
+		OverloadGoroutines:           50000,
+		OverloadKeepaliveSaveStretch: 3,
+		TracingSampleRatio:           0.1,
+		DecisionLogRetentionDays:     7,
+		OutboxRetentionDays:          7,
+		PointDecimals:                6,
+		DNSNodePort:                  80,
+		DNSMaxRecords:                8,
+		DNSRecordTTL:                 60,
+		ACMEDirectory:                "https://acme-v02.api.letsencrypt.org/directory",
+		RetrievalProbeInterval:       10,
+		RetrievalProbeSampleSize:     20,
+		RetrievalProbeSlowTTFB:       2000,
+		RetrievalProbeMinThroughput:  256 << 10,
+		RetrievalProbeRetentionDays:  30,
+		SpotChecksPerHour:            30,
+		SpotCheckProxies:             []string{},
+		SpotCheckMinSpeedRatio:       0.1,
+		StorageProofInterval:         30,
+		StorageProofSampleSize:       20,
+		StorageProofMaxFailures:      3,
+		NodeTaskExpiration:           72,
+		StreamingMinBandwidthUp:      0,
+		SegmentStatsRetentionDays:    30,
+		WebRTCICEServers:             []string{"stun:stun.l.google.com:19302"},
+		LocationIndexMaxConns:        16,
+		LocationIndexCacheSize:       100000,
+		LocationIndexCacheTTL:        60,
+		WorkloadVerifySampleRate:     0.1,
+		WorkloadVerifyTolerance:      0.05,
+		WorkloadReceiptRetentionDays: 30,
+		IdleKeepaliveInterval:        300,
+		MaxMaintenanceHours:          72,
+		JobQueueWorkers:              4,
+		NodeArchiveDays:              90,
+		SandboxNodeTTL:               24,
+
+		ValidationResultRetentionDays: 30,
+		ReplicaEventRetentionDays:     90,
+		RetrieveEventRetentionDays:    90,
+		WorkloadRecordRetentionDays:   90,
+		RelaySessionRetentionDays:     30,
+		WebhookDeliveryRetentionDays:  7,
+
+		ColdStorageIdleDays: 90,
+		ColdStorageMinSize:  1 << 30,
+
+		TrustProbationDays:         7,
+		TrustedAfterDays:           30,
+		ValidationPercentProbation: 100,
+		ValidationPercentStandard:  50,
+		ValidationPercentTrusted:   25,
 	}
 }
 
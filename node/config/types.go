@@ -5,12 +5,16 @@ package config
 // // After making edits here, run 'make cfgdoc-gen' (or 'make gen')
 
 type Network struct {
-	// host address and port the edge node api will listen on
+	// host address and port the edge node api will listen on, the unspecified ipv6 address [::] listens on ipv4 and ipv6
 	ListenAddress string
 	// used when 'ListenAddress' is unspecified. must be a valid duration recognized by golang's time.ParseDuration function
 	Timeout string
 	// the url of locator
 	LocatorURL string
+	// the url of the outbound proxy the node connects to the locator and the scheduler and pulls the assets through,
+	// e.g. socks5://127.0.0.1:1080 or http://127.0.0.1:8080, connects directly if empty. The proxied node is reached
+	// over tcp only and does not serve the retrievals of the clients directly
+	Proxy string
 }
 
 type Storage struct {
@@ -67,6 +71,12 @@ type EdgeCfg struct {
 	// seconds
 	ValidateDuration    int
 	MaxSizeOfUploadFile int
+	// the segments prefetched after the retrieved segment of a hls or dash asset, disabled if 0
+	VideoPrefetchSegments int
+	// minutes without traffic before the edge requests the idle mode with a longer keepalive interval, disabled if 0
+	IdleMinutes int
+	// command run to spin down the disk when the edge enters the idle mode, e.g. hdparm -y /dev/sdb
+	IdleSpinDownCommand string
 
 	Bandwidth Bandwidth
 	Storage   Storage
@@ -91,6 +101,11 @@ type CandidateCfg struct {
 	MinioConfig
 	WebRedirect string
 	ExternalURL string
+	// connect to the scheduler with a client certificate issued by the scheduler ca
+	EnableMTLS bool
+	// host address and port the https server of the gateway domain listens on, the certificate is obtained
+	// from the scheduler and renewed by acme, disabled if empty
+	GatewayTLSListenAddress string
 }
 
 // LocatorCfg locator config
@@ -117,6 +132,10 @@ type LocatorCfg struct {
 	DNSServerAddress       string
 	DNSRecords             map[string]string
 	LoadBalanceExcludeArea []string
+	// GatewayRedirect redirects the ipfs gateway requests to the selected node instead of reverse proxying them
+	GatewayRedirect bool
+	// GatewayRateLimit the ipfs gateway requests per second of a client ip, no limit if it is 0
+	GatewayRateLimit int
 }
 
 // SchedulerCfg scheduler config
@@ -125,10 +144,18 @@ type SchedulerCfg struct {
 	ExternalURL string
 	// host address and port the edge node api will listen on
 	ListenAddress string
+	// host address and port the grpc api will listen on, the grpc api is disabled if empty
+	GRPCListenAddress string
+	// serve the web dashboard of the scheduler at /ui/ on the listen address, the dashboard reads the rest api
+	// with a web or admin token entered by the operator
+	EnableWebUI bool
 	// database address
 	DatabaseAddress string
 	// area id
 	AreaID string
+	// Zones the areas served by the scheduler besides AreaID, the nodes of each zone have their own
+	// select weights, validation rounds and statistics, and the locator routes the nodes of the zones to the scheduler
+	Zones []string
 	// InsecureSkipVerify skip tls verify
 	InsecureSkipVerify bool
 	// used for http3 server
@@ -139,16 +166,44 @@ type SchedulerCfg struct {
 	PrivateKeyPath string
 	// self sign certificate, use for client
 	CaCertificatePath string
+	// require candidates to present a client certificate issued by the scheduler ca
+	EnableCandidateMTLS bool
+	// validity of the candidate client certificates (Unit:day)
+	CandidateCertValidity int
+	// storage of the scheduler private key, local keeps it in the repo keystore,
+	// remote routes the rsa operations to a signer service in front of a kms or hsm
+	KeyBackend string
+	// environment variable of the passphrase that encrypts the keys in the local keystore, not encrypted if empty
+	KeyPassphraseEnv string
+	// url of the signer service, used if KeyBackend is remote
+	RemoteSignerURL string
+	// bearer token of the signer service
+	RemoteSignerToken string
+	// number of keys retired by rotation kept to decrypt the data encrypted with them
+	MaxRetiredKeys int
 	// config to enabled node validation, default: true
 	EnableValidation bool
 	// etcd server addresses
 	EtcdAddresses []string
 	// Number of candidate node replicas (does not contain 'seed')
 	CandidateReplicas int
+	// upload bandwidth of a candidate taken by an edge pulling a new asset from it (unit: MiB/s), the edges pull
+	// the new assets from the candidates in waves sized by the upload bandwidth of the candidates, 0 disables the waves
+	SeedingEdgeBandwidth int
+	// seconds the next seeding wave waits while the candidates holding the asset are saturated
+	SeedingBackoff int
+	// the edge replicas an asset keeps beyond its desired replicas before the extra replicas are trimmed
+	// by the replica reconciler, the replicas are not trimmed if negative
+	ReplicaTrimSlack int
 	// Proportion of validator in candidate nodes (0 ~ 1)
 	ValidatorRatio float64
 	// The base downstream bandwidth per validator window (unit : MiB)
 	ValidatorBaseBwDn int
+	// The downstream bandwidth a validation takes from the validator (unit : MiB), a validator runs
+	// downstream bandwidth / ValidatorTaskBwDn validations at a time at most
+	ValidatorTaskBwDn int
+	// Minutes the validations of a round are spread over, the validations not fitting in the minutes wait for the next rounds
+	ValidationRoundSpread int
 	// Increased profit after node validation passes
 	ValidationProfit float64
 	// Increased profit after node workload passes
@@ -188,7 +243,195 @@ type SchedulerCfg struct {
 	MaxAPIKey                int
 	IPWhitelist              []string
 	MaxNumberOfRegistrations int
+	// key types accepted for node identity at registration, rsa or ed25519, the preferred type first
+	NodeKeyTypes []string
+	// maximum difference in seconds between the time a node login is signed and the scheduler time
+	NodeLoginWindow int
+	// reject the node login without challenge, its signature never changes and can be replayed
+	RequireNodeLoginChallenge bool
 
 	IPLimit            int
 	FillAssetEdgeCount int64
+
+	// SMTP server address (host:port) used to send alert emails, alerts are only sent by webhook if empty
+	AlertSMTPAddress  string
+	AlertSMTPUsername string
+	AlertSMTPPassword string
+	// Sender address of the alert emails
+	AlertSMTPFrom string
+
+	// hours of a settlement epoch, the points earned in an epoch are settled to the accounts when it ends, settlement is disabled if 0
+	SettlementEpochHours int
+	// reward distributed to the accounts in each settlement epoch in proportion to the points they earned
+	SettlementRewardPerEpoch float64
+	// directory the payout files of the settled epochs are written to, the shares as csv and the signed report as json
+	SettlementPayoutDir string
+	// url the signed report of the settled epochs is posted to, e.g. the service submitting the merkle root to the on-chain distributor
+	SettlementPayoutWebhook string
+
+	// points earned by a node in its commitment window are multiplied by the multiplier if the commitment is kept
+	CommitmentBonusMultiplier float64
+	// minimum ratio of the minutes a node must be online in its commitment window to keep the commitment
+	CommitmentMinPresence float64
+
+	// the scheduler sheds low priority work when the latency of the database exceeds the milliseconds, the check is disabled if 0
+	OverloadDBLatencyMs int
+	// the scheduler sheds low priority work when the number of goroutines exceeds the threshold, the check is disabled if 0
+	OverloadGoroutines int
+	// the interval of saving the node information on keepalive is multiplied by the factor when the scheduler sheds work
+	OverloadKeepaliveSaveStretch int
+
+	// otlp http endpoint the traces are exported to, e.g. http://localhost:4318, OTEL_EXPORTER_OTLP_ENDPOINT is used if empty,
+	// tracing is disabled if both are empty
+	TracingEndpoint string
+	// ratio of the flows started by the scheduler to trace
+	TracingSampleRatio float64
+
+	// days the scheduling decisions are kept in the decision log, decisions are not recorded if 0
+	DecisionLogRetentionDays int
+
+	// sinks the node, validation and asset events of the outbox are delivered to, e.g. https://indexer/events,
+	// kafka://broker1:9092,broker2:9092/titan.{topic} or nats://host:4222/subject-prefix (jetstream), ?topics=node.,asset.
+	// limits a sink to the topics with the prefixes, the events stay in the outbox until all the sinks accept them,
+	// see documentation/en/event_export.md for the schema
+	OutboxSinks []string
+	// days the delivered events are kept in the outbox, the undelivered events are also pruned if no sink is configured
+	OutboxRetentionDays int
+
+	// decimals the points earned by the nodes are rounded to, at most 12, the points are summed without rounding
+	PointDecimals int
+
+	// dns provider of the gateway domains, route53 or cloudflare, the scheduler points <area>.<DNSZone> at the healthy
+	// candidates of its areas and <cid>.<DNSZone> at the candidates holding the gateway assets, disabled if empty
+	DNSProvider string
+	// domain the gateway hostnames are created under, e.g. gw.example.com
+	DNSZone string
+	// id of the hosted zone of route53 or of the zone of cloudflare
+	DNSZoneID string
+	// api token of cloudflare, or <access key id>:<secret key> of route53, the default aws credentials are used if empty
+	DNSCredential string
+	// only the candidates serving http on the port are published, the a records carry no port
+	DNSNodePort int
+	// maximum number of candidates a gateway hostname points at
+	DNSMaxRecords int
+	// ttl of the gateway records (Unit:second)
+	DNSRecordTTL int
+	// contact email of the acme account, the scheduler obtains a certificate of *.<DNSZone> by the dns-01 challenges
+	// of the dns provider and hands it to the candidates serving https, disabled if empty
+	ACMEEmail string
+	// directory url of the acme ca
+	ACMEDirectory string
+	// path of the pem rsa public key the release manifests are signed with, the upgrade rollouts are disabled if empty
+	ReleasePublicKeyPath string
+	// interval of the retrieval probes of the replicas on the nodes connected to the scheduler (Unit:minute), disabled if 0
+	RetrievalProbeInterval int
+	// number of the nodes probed in each round
+	RetrievalProbeSampleSize int
+	// a probe is slow if its time to first byte exceeds the threshold (Unit:millisecond)
+	RetrievalProbeSlowTTFB int
+	// a probe is slow if its throughput is below the minimum (Unit:byte per second)
+	RetrievalProbeMinThroughput int64
+	// days the retrieval probes are kept
+	RetrievalProbeRetentionDays int
+	// average spot checks per hour of the replicas on the edges connected to the scheduler, disabled if 0. A spot check retrieves
+	// a random range of a replica as a browser holding a signed url would, at random times, and compares the bytes with a candidate
+	SpotChecksPerHour int
+	// http proxies of the designated probes the spot checks are sent through, a proxy is picked at random for each check,
+	// the checks are sent from the scheduler if empty
+	SpotCheckProxies []string
+	// a spot check is slow if its throughput is below the ratio of the upload bandwidth the edge claimed in the validations
+	SpotCheckMinSpeedRatio float64
+	// interval of the storage challenges of the replicas on the nodes connected to the scheduler (Unit:minute), disabled if 0
+	StorageProofInterval int
+	// number of the replicas challenged in each round
+	StorageProofSampleSize int
+	// a replica failing the challenges the times in a row is lost, it is removed and the node is penalized for fake storage
+	StorageProofMaxFailures int
+	// hours the tasks of the offline nodes are kept in their inboxes, e.g. the deletes of the replicas, the task is dropped after it
+	NodeTaskExpiration int
+	// the replicas of the streaming assets are only placed on the nodes with at least the upload bandwidth
+	// (Unit:byte per second), 0 places them on the fastest nodes without the minimum
+	StreamingMinBandwidthUp int64
+	// days the retrieval stats of the segments of the video assets are kept after their last retrieval
+	SegmentStatsRetentionDays int
+	// the stun and turn servers the browsers and the edges connect the webrtc sessions with, e.g. stun:stun.l.google.com:19302
+	WebRTCICEServers []string
+	// the credential of the turn servers
+	WebRTCICEUsername   string
+	WebRTCICECredential string
+	// the database the location index reads the locations of the assets from for the retrieval routing, usually a read replica
+	// of the database, empty reads from the database of the scheduler with the pool of the index
+	LocationIndexDatabaseAddress string
+	// the connections of the pool of the location index
+	LocationIndexMaxConns int
+	// the assets whose locations are cached by the location index
+	LocationIndexCacheSize int
+	// seconds the cached locations are served, the replica changes of the other schedulers are seen after
+	LocationIndexCacheTTL int
+	// the fraction of the workload receipts signed by the downloaders that are sampled to verify the workloads of the nodes,
+	// 0 disables the verification
+	WorkloadVerifySampleRate float64
+	// the fraction of the bytes of a receipt the workload reported by the node may differ by
+	WorkloadVerifyTolerance float64
+	// days the sampled workload receipts are kept
+	WorkloadReceiptRetentionDays int
+
+	// keepalive interval granted to the edges in the idle mode, the edges without replicas request the idle mode
+	// after no traffic for a while, disabled if 0 (Unit:second)
+	IdleKeepaliveInterval int
+
+	// the longest maintenance window an operator can declare, not limited if 0 (Unit:hour)
+	MaxMaintenanceHours int
+
+	// the workers running the jobs of the job queue on the scheduler
+	JobQueueWorkers int
+
+	// the nodes offline longer than the days are archived, not archived if 0
+	NodeArchiveDays int
+
+	// accept the ephemeral test nodes registered in the sandbox without the registration limits, the sandbox nodes
+	// are excluded from the settlements and purged once their ttl elapses
+	SandboxMode bool
+	// the hours a sandbox node lives after it registers
+	SandboxNodeTTL int
+
+	// days the rows of the high-volume tables are kept, the rows of a table are not pruned if its days are 0
+	ValidationResultRetentionDays int
+	ReplicaEventRetentionDays     int
+	RetrieveEventRetentionDays    int
+	WorkloadRecordRetentionDays   int
+	RelaySessionRetentionDays     int
+	WebhookDeliveryRetentionDays  int
+
+	// s3 bucket the data exports are uploaded to, the exports are disabled if empty
+	ExportBucket string
+	// endpoint of the s3 compatible storage of the bucket, aws s3 if empty
+	ExportEndpoint string
+	// region of the bucket, us-east-1 if empty
+	ExportRegion string
+	// <access key id>:<secret key> of the bucket, the default aws credentials are used if empty
+	ExportCredential string
+
+	// the nodes the scheduler can serve, advertised to the locators as its capacity, not limited if 0
+	MaxNodes int
+
+	// url of the deal-making component the cold assets are offloaded into the filecoin storage deals through,
+	// e.g. https://dealmaker/api, the cold storage tier is disabled if empty, see documentation/en/cold_storage.md
+	ColdStorageDealEndpoint string
+	// bearer token of the deal-making component
+	ColdStorageDealToken string
+	// the assets not retrieved for the days are offloaded into the storage deals and their hot replicas are removed
+	// once the deals are active, the assets are not offloaded if 0
+	ColdStorageIdleDays int
+	// the assets smaller than the size are kept hot (Unit:byte)
+	ColdStorageMinSize int64
+
+	// the nodes registered or penalized in the days are in the probation trust tier
+	TrustProbationDays int
+	// the nodes registered and not penalized in the days are in the trusted tier, the others in the standard tier
+	TrustedAfterDays int
+	// the percent of the validation rounds the nodes of the probation, standard and trusted tiers are validated in
+	ValidationPercentProbation int
+	ValidationPercentStandard  int
+	ValidationPercentTrusted   int
 }
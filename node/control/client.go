@@ -0,0 +1,171 @@
+// Package control executes the control calls of the scheduler on the node.
+//
+// The node polls the scheduler for the control calls over its connection to the scheduler, so the scheduler does not
+// dial back the node behind nat. The calls of a poll are executed concurrently and the results are submitted in batches.
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("control")
+
+const (
+	// the node polls again after the interval if the poll failed, e.g. the scheduler does not support the control calls
+	retryInterval = time.Minute
+	// the results are submitted within the timeout
+	submitTimeout = 10 * time.Second
+	// the calls executed at once
+	maxExecuting = 16
+)
+
+// Handler executes the control calls on the node
+type Handler interface {
+	ExecuteValidation(ctx context.Context, req *api.ValidateReq) error
+	PullAsset(ctx context.Context, assetCID string, sources []*types.CandidateDownloadInfo) error
+	ApplyNodeConfig(ctx context.Context, push *types.NodeConfigPush) (*types.NodeConfigAck, error)
+}
+
+// Client polls the control calls of the scheduler and executes them with the handler
+type Client struct {
+	scheduler api.Scheduler
+	handler   Handler
+	results   chan *types.ControlResult
+	executing chan struct{}
+}
+
+// NewClient creates a control client of the node
+func NewClient(scheduler api.Scheduler, handler Handler) *Client {
+	return &Client{
+		scheduler: scheduler,
+		handler:   handler,
+		results:   make(chan *types.ControlResult, maxExecuting),
+		executing: make(chan struct{}, maxExecuting),
+	}
+}
+
+// Run polls the control calls until the context is done
+func (c *Client) Run(ctx context.Context) {
+	go c.submitResults(ctx)
+
+	for {
+		calls, err := c.scheduler.PullControlCalls(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Debugf("PullControlCalls error %s", err.Error())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+
+		for _, call := range calls {
+			select {
+			case c.executing <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(call *types.ControlCall) {
+				defer func() { <-c.executing }()
+				c.results <- c.execute(ctx, call)
+			}(call)
+		}
+	}
+}
+
+// execute executes the call within its timeout
+func (c *Client) execute(ctx context.Context, call *types.ControlCall) *types.ControlResult {
+	if call.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, call.Timeout)
+		defer cancel()
+	}
+
+	result := &types.ControlResult{ID: call.ID}
+
+	out, err := c.dispatch(ctx, call)
+	if err == nil && out != nil {
+		result.Result, err = json.Marshal(out)
+	}
+
+	if err != nil {
+		log.Warnf("control call %s %s error %s", call.Method, call.ID, err.Error())
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// dispatch decodes the params of the call and calls the method of the handler
+func (c *Client) dispatch(ctx context.Context, call *types.ControlCall) (interface{}, error) {
+	switch call.Method {
+	case types.ControlExecuteValidation:
+		req := &api.ValidateReq{}
+		if err := json.Unmarshal(call.Params, req); err != nil {
+			return nil, err
+		}
+		return nil, c.handler.ExecuteValidation(ctx, req)
+	case types.ControlPullAsset:
+		params := &types.PullAssetParams{}
+		if err := json.Unmarshal(call.Params, params); err != nil {
+			return nil, err
+		}
+		return nil, c.handler.PullAsset(ctx, params.AssetCID, params.Sources)
+	case types.ControlApplyNodeConfig:
+		push := &types.NodeConfigPush{}
+		if err := json.Unmarshal(call.Params, push); err != nil {
+			return nil, err
+		}
+
+		ack, err := c.handler.ApplyNodeConfig(ctx, push)
+		if err != nil {
+			return nil, err
+		}
+		return ack, nil
+	default:
+		return nil, xerrors.Errorf("unsupported control method %s", call.Method)
+	}
+}
+
+// submitResults submits the results of the executed calls, the results completed meanwhile are submitted together
+func (c *Client) submitResults(ctx context.Context) {
+	for {
+		var results []*types.ControlResult
+		select {
+		case r := <-c.results:
+			results = append(results, r)
+		case <-ctx.Done():
+			return
+		}
+
+	drain:
+		for {
+			select {
+			case r := <-c.results:
+				results = append(results, r)
+			default:
+				break drain
+			}
+		}
+
+		sctx, cancel := context.WithTimeout(ctx, submitTimeout)
+		if err := c.scheduler.SubmitControlResults(sctx, results); err != nil {
+			log.Errorf("SubmitControlResults error %s", err.Error())
+		}
+		cancel()
+	}
+}
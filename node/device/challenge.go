@@ -0,0 +1,153 @@
+package device
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/docker/go-units"
+	"golang.org/x/xerrors"
+)
+
+const (
+	challengeBlockSize = units.MiB
+
+	// upper limits of a challenge, protect the node from oversized challenges
+	maxChallengeDiskSize     = units.GiB
+	maxChallengeMemorySize   = 512 * units.MiB
+	maxChallengeMemoryRounds = 16
+)
+
+// RunHardwareChallenge runs the disk probe and memory benchmark of the challenge and signs the result
+func (device *Device) RunHardwareChallenge(ctx context.Context, challenge *types.HardwareChallenge) (*types.HardwareChallengeResult, error) {
+	if challenge.DiskSize <= 0 || challenge.DiskSize > maxChallengeDiskSize {
+		return nil, xerrors.Errorf("invalid challenge disk size %d", challenge.DiskSize)
+	}
+
+	if challenge.MemorySize <= 0 || challenge.MemorySize > maxChallengeMemorySize {
+		return nil, xerrors.Errorf("invalid challenge memory size %d", challenge.MemorySize)
+	}
+
+	if challenge.MemoryRounds <= 0 || challenge.MemoryRounds > maxChallengeMemoryRounds {
+		return nil, xerrors.Errorf("invalid challenge memory rounds %d", challenge.MemoryRounds)
+	}
+
+	result := &types.HardwareChallengeResult{ChallengeID: challenge.ID}
+
+	var err error
+	if challenge.DigestOnly {
+		result.DiskHash = diskDigest(challenge)
+	} else {
+		result.DiskHash, result.DiskDuration, err = device.diskProbe(challenge)
+		if err != nil {
+			return nil, xerrors.Errorf("disk probe: %w", err)
+		}
+	}
+
+	result.MemoryHash, result.MemoryBandwidth = memoryBenchmark(challenge)
+
+	if device.privateKey != nil {
+		result.Sign, err = nodekey.Sign(device.privateKey, result.SignData())
+		if err != nil {
+			return nil, xerrors.Errorf("sign result: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// writeChallengeData writes the deterministic data of the disk probe to w
+func writeChallengeData(w io.Writer, challenge *types.HardwareChallenge) error {
+	r := rand.New(rand.NewSource(challenge.Seed)) //nolint:gosec // the data only needs to be reproducible
+	buf := make([]byte, challengeBlockSize)
+
+	for remain := challenge.DiskSize; remain > 0; remain -= int64(len(buf)) {
+		if remain < int64(len(buf)) {
+			buf = buf[:remain]
+		}
+
+		r.Read(buf) //nolint:errcheck // never returns an error
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diskDigest calculates the expected digest of the disk probe without touching the disk
+func diskDigest(challenge *types.HardwareChallenge) string {
+	h := sha256.New()
+	writeChallengeData(h, challenge) //nolint:errcheck // hash never returns an error
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// diskProbe writes the challenge data to disk, reads it back and hashes it
+func (device *Device) diskProbe(challenge *types.HardwareChallenge) (string, int64, error) {
+	f, err := os.CreateTemp(device.storage.GetProbePath(), "hardware-challenge-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	defer f.Close()           //nolint:errcheck
+
+	start := time.Now()
+
+	if err = writeChallengeData(f, challenge); err != nil {
+		return "", 0, err
+	}
+
+	if err = f.Sync(); err != nil {
+		return "", 0, err
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), time.Since(start).Milliseconds(), nil
+}
+
+// memoryBenchmark rotates a buffer filled with the challenge data in memory,
+// returns the hash of the final buffer and the measured memory bandwidth
+func memoryBenchmark(challenge *types.HardwareChallenge) (string, float64) {
+	r := rand.New(rand.NewSource(challenge.Seed)) //nolint:gosec // the data only needs to be reproducible
+	size := int(challenge.MemorySize)
+
+	src := make([]byte, size)
+	dst := make([]byte, size)
+	r.Read(src) //nolint:errcheck // never returns an error
+
+	start := time.Now()
+
+	for i := 0; i < challenge.MemoryRounds; i++ {
+		offset := r.Intn(size)
+		n := copy(dst, src[offset:])
+		copy(dst[n:], src[:offset])
+		src, dst = dst, src
+	}
+
+	elapsed := time.Since(start).Seconds()
+
+	h := sha256.New()
+	h.Write(src) //nolint:errcheck // hash never returns an error
+
+	bandwidth := 0.0
+	if elapsed > 0 {
+		// every round reads and writes the whole buffer
+		bandwidth = float64(2*size*challenge.MemoryRounds) / elapsed
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), bandwidth
+}
@@ -0,0 +1,231 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/shirou/gopsutil/v3/disk"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the disk health is read once in the interval, reading SMART may wake up the disk and take a while
+	diskHealthInterval = time.Hour
+	smartctlTimeout    = 30 * time.Second
+
+	// ata SMART attribute ids
+	smartReallocatedSectors   = 5
+	smartPendingSectors       = 197
+	smartUncorrectableSectors = 198
+)
+
+// diskHealthMonitor reads the SMART attributes and the filesystem errors of the disk holding the path periodically
+type diskHealthMonitor struct {
+	path string
+
+	lock   sync.Mutex
+	health *types.DiskHealth
+}
+
+// WatchDiskHealth monitors the health of the disk holding the path, the last health read is collected with the host metrics
+func (c *MetricsCollector) WatchDiskHealth(path string) {
+	m := &diskHealthMonitor{path: path}
+
+	c.lock.Lock()
+	c.diskHealth = m
+	c.lock.Unlock()
+
+	go m.run()
+}
+
+func (m *diskHealthMonitor) run() {
+	ticker := time.NewTicker(diskHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		health, err := readDiskHealth(m.path)
+		if err != nil {
+			log.Warnf("read disk health of %s: %s", m.path, err.Error())
+		} else {
+			m.lock.Lock()
+			m.health = health
+			m.lock.Unlock()
+		}
+
+		<-ticker.C
+	}
+}
+
+func (m *diskHealthMonitor) get() *types.DiskHealth {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.health
+}
+
+// readDiskHealth reads the SMART attributes of the disk holding the path with smartctl and the errors of its filesystem,
+// the SMART attributes are marked unavailable if smartctl is missing or the disk does not support SMART
+func readDiskHealth(path string) (*types.DiskHealth, error) {
+	partition, err := partitionOf(path)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &types.DiskHealth{Device: diskOfPartition(partition.Device), CollectedTime: time.Now()}
+
+	if err := readSMART(health); err != nil {
+		log.Debugf("read SMART of %s: %s", health.Device, err.Error())
+	}
+
+	if partition.Fstype == "ext4" {
+		health.FilesystemErrors = ext4Errors(partition.Device)
+	}
+
+	return health, nil
+}
+
+// partitionOf returns the mounted partition holding the path
+func partitionOf(path string) (*disk.PartitionStat, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out *disk.PartitionStat
+	for i := range partitions {
+		p := &partitions[i]
+		if !strings.HasPrefix(path, p.Mountpoint) {
+			continue
+		}
+
+		if out == nil || len(p.Mountpoint) > len(out.Mountpoint) {
+			out = p
+		}
+	}
+
+	if out == nil {
+		return nil, xerrors.Errorf("no partition holds %s", path)
+	}
+
+	return out, nil
+}
+
+// diskOfPartition returns the disk of the partition device, e.g. /dev/sda for /dev/sda1 and /dev/nvme0n1 for /dev/nvme0n1p1
+func diskOfPartition(device string) string {
+	dir, name := filepath.Split(device)
+
+	if strings.HasPrefix(name, "nvme") || strings.HasPrefix(name, "mmcblk") {
+		if i := strings.LastIndex(name, "p"); i > 0 && isDigits(name[i+1:]) {
+			return dir + name[:i]
+		}
+		return device
+	}
+
+	return dir + strings.TrimRight(name, "0123456789")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// smartctlOutput the parts of the json output of smartctl read by the node
+type smartctlOutput struct {
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	ATAAttributes *struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		PercentageUsed int64 `json:"percentage_used"`
+		MediaErrors    int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+func readSMART(health *types.DiskHealth) error {
+	ctx, cancel := context.WithTimeout(context.Background(), smartctlTimeout)
+	defer cancel()
+
+	// smartctl exits with a bit mask of the problems found, the output is parsed whatever the exit status
+	out, err := exec.CommandContext(ctx, "smartctl", "--json", "-H", "-A", health.Device).Output()
+	if len(out) == 0 {
+		if err == nil {
+			err = xerrors.New("no output")
+		}
+		return err
+	}
+
+	return parseSMART(out, health)
+}
+
+func parseSMART(out []byte, health *types.DiskHealth) error {
+	var output smartctlOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return err
+	}
+
+	if output.SmartStatus == nil {
+		return xerrors.New("SMART is not supported")
+	}
+
+	health.SmartAvailable = true
+	health.SmartPassed = output.SmartStatus.Passed
+
+	if output.ATAAttributes != nil {
+		for _, attr := range output.ATAAttributes.Table {
+			switch attr.ID {
+			case smartReallocatedSectors:
+				health.ReallocatedSectors = attr.Raw.Value
+			case smartPendingSectors:
+				health.PendingSectors = attr.Raw.Value
+			case smartUncorrectableSectors:
+				health.UncorrectableSectors = attr.Raw.Value
+			}
+		}
+	}
+
+	if output.NVMeLog != nil {
+		health.PercentageUsed = output.NVMeLog.PercentageUsed
+		health.MediaErrors = output.NVMeLog.MediaErrors
+	}
+
+	return nil
+}
+
+// ext4Errors returns the errors recorded by the ext4 filesystem of the partition device
+func ext4Errors(device string) int64 {
+	buf, err := os.ReadFile(filepath.Join("/sys/fs/ext4", filepath.Base(device), "errors_count"))
+	if err != nil {
+		return 0
+	}
+
+	count, err := strconv.ParseInt(strings.TrimSpace(string(buf)), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
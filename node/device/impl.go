@@ -2,12 +2,13 @@ package device
 
 import (
 	"context"
+	"crypto"
 	"net"
-	"strings"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/build"
 	"github.com/Filecoin-Titan/titan/node/config"
@@ -31,6 +32,7 @@ type Device struct {
 	internalIP string
 	resources  *Resources
 	storage    Storage
+	privateKey crypto.Signer // used to sign the hardware challenge results
 }
 
 type Resources struct {
@@ -42,21 +44,28 @@ type Resources struct {
 	Storage *config.Storage
 	// Bandwidth Limit bandwidth usage
 	Bandwidth *config.Bandwidth
+	// Network the outbound proxy of the node is reported to the scheduler
+	Network *config.Network
+	// TCPTLS the tcp server serves tls, the other nodes and the gateway reach the node over tcp with https only
+	TCPTLS bool
 }
 
 // Storage represents a storage system and its properties.
 type Storage interface {
 	GetDiskUsageStat() (totalSpace, usage float64)
 	GetFileSystemType() string
+	// GetProbePath returns the directory used by the disk probe of hardware challenges
+	GetProbePath() string
 }
 
 // NewDevice creates a new Device instance with the specified properties.
-func NewDevice(nodeID, internalIP string, res *Resources, storage Storage) *Device {
+func NewDevice(nodeID, internalIP string, res *Resources, storage Storage, privateKey crypto.Signer) *Device {
 	device := &Device{
 		nodeID:     nodeID,
 		internalIP: internalIP,
 		resources:  res,
 		storage:    storage,
+		privateKey: privateKey,
 	}
 
 	if _, err := cpu.Percent(0, false); err != nil {
@@ -89,6 +98,19 @@ func (device *Device) GetNodeInfo(ctx context.Context) (types.NodeInfo, error) {
 	info.ExternalIP = device.publicIP
 	info.SystemVersion = version.String()
 	info.InternalIP = device.internalIP
+	// the nodes serve the assets with the http3 server, and with the tcp server if it serves tls
+	info.DataProtocols = []types.TransferProtocol{types.TransferProtocolHTTP3}
+	if device.resources.TCPTLS {
+		info.DataProtocols = append(info.DataProtocols, types.TransferProtocolHTTP)
+	}
+
+	if device.resources.Network != nil && device.resources.Network.Proxy != "" {
+		u, err := client.ParseProxyURL(device.resources.Network.Proxy)
+		if err != nil {
+			return types.NodeInfo{}, err
+		}
+		info.ProxyScheme = u.Scheme
+	}
 
 	if device.resources.Bandwidth != nil {
 		info.BandwidthDown = device.resources.Bandwidth.BandwidthDown * bandwidthUnit
@@ -153,10 +175,8 @@ func getMacAddr(ip string) (string, error) {
 		}
 
 		for _, addr := range addrs {
-			localAddr := addr.(*net.IPNet)
-			localIP := strings.Split(localAddr.IP.String(), ":")[0]
-
-			if localIP == ip {
+			localAddr, ok := addr.(*net.IPNet)
+			if ok && localAddr.IP.String() == ip {
 				return ifa.HardwareAddr.String(), nil
 			}
 		}
@@ -0,0 +1,109 @@
+package device
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// MetricsCollector collects the host metrics that the node reports with keepalive
+type MetricsCollector struct {
+	lock sync.Mutex
+	// disk io time of the last collection, used to calculate the io utilization
+	lastIOTime    uint64
+	lastCollected time.Time
+	// diskHealth monitors the disk of the storage, nil if not watched
+	diskHealth *diskHealthMonitor
+}
+
+// NewMetricsCollector creates a new MetricsCollector instance
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{}
+}
+
+// Collect returns the current host metrics
+func (c *MetricsCollector) Collect() *types.HostMetrics {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	metrics := &types.HostMetrics{}
+
+	if cpuPercent, err := cpu.Percent(0, false); err != nil {
+		log.Debugf("get cpu percent: %s", err.Error())
+	} else if len(cpuPercent) > 0 {
+		metrics.CPULoad = cpuPercent[0]
+	}
+
+	if vmStat, err := mem.VirtualMemory(); err != nil {
+		log.Debugf("get virtual memory: %s", err.Error())
+	} else {
+		metrics.MemoryPressure = vmStat.UsedPercent
+	}
+
+	metrics.DiskIOUtil = c.diskIOUtil()
+	metrics.Temperature = maxTemperature()
+
+	if c.diskHealth != nil {
+		metrics.DiskHealth = c.diskHealth.get()
+	}
+
+	return metrics
+}
+
+// diskIOUtil returns the percentage of time the disks were busy since the last collection
+func (c *MetricsCollector) diskIOUtil() float64 {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		log.Debugf("get disk io counters: %s", err.Error())
+		return 0
+	}
+
+	var ioTime uint64
+	for _, counter := range counters {
+		ioTime += counter.IoTime
+	}
+
+	now := time.Now()
+	defer func() {
+		c.lastIOTime = ioTime
+		c.lastCollected = now
+	}()
+
+	if c.lastCollected.IsZero() || ioTime < c.lastIOTime || len(counters) == 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(c.lastCollected).Milliseconds() * int64(len(counters))
+	if elapsed <= 0 {
+		return 0
+	}
+
+	util := float64(ioTime-c.lastIOTime) / float64(elapsed) * 100
+	if util > 100 {
+		util = 100
+	}
+
+	return util
+}
+
+// maxTemperature returns the highest sensor temperature of the host
+func maxTemperature() float64 {
+	temps, err := host.SensorsTemperatures()
+	if err != nil && len(temps) == 0 {
+		return 0
+	}
+
+	max := 0.0
+	for _, t := range temps {
+		if t.Temperature > max {
+			max = t.Temperature
+		}
+	}
+
+	return max
+}
@@ -0,0 +1,275 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/build"
+	"github.com/Filecoin-Titan/titan/node/device"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/shirou/gopsutil/v3/disk"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// MaxBundleSize the largest bundle the node returns to the scheduler, larger bundles are uploaded to a presigned url
+	MaxBundleSize = 12 << 20
+	// timeout of asking a candidate for the external address of the node
+	natProbeTimeout = 10 * time.Second
+	uploadTimeout   = 5 * time.Minute
+	maskedValue     = "******"
+)
+
+// the config fields whose names contain the words or end with key are masked in the bundle
+var secretWords = []string{"secret", "password", "token"}
+
+// Source the parts of the diagnostics bundle provided by the node
+type Source struct {
+	Device *device.Device
+	// the config of the node, the secrets are masked
+	Config interface{}
+	Logs   *LogBuffer
+	// the storage paths whose disk usage is collected
+	DiskPaths []string
+	// ProbeNAT asks the candidates for the external address of the node
+	ProbeNAT func(ctx context.Context) ([]*types.NATProbeResult, error)
+}
+
+// manifest describes the bundle, the parts failed to collect are listed with their errors
+type manifest struct {
+	ID          string
+	NodeID      string
+	Version     string
+	CreatedTime time.Time
+	Errors      map[string]string
+}
+
+// Collect builds the gzipped tar bundle of the recent logs, the config, the nat probe results and the disk stats,
+// a part failed to collect is recorded in the manifest instead of failing the bundle
+func Collect(ctx context.Context, req *types.NodeDiagnosticsReq, src *Source) ([]byte, error) {
+	m := &manifest{ID: req.ID, Version: build.UserVersion(), CreatedTime: time.Now(), Errors: make(map[string]string)}
+	files := make(map[string][]byte)
+
+	addJSON := func(name string, v interface{}, err error) {
+		if err != nil {
+			m.Errors[name] = err.Error()
+			return
+		}
+
+		buf, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			m.Errors[name] = err.Error()
+			return
+		}
+		files[name] = buf
+	}
+
+	info, err := src.Device.GetNodeInfo(ctx)
+	m.NodeID = info.NodeID
+	addJSON("node_info.json", info, err)
+
+	cfg, err := maskConfig(src.Config)
+	addJSON("config.json", cfg, err)
+
+	addJSON("disk.json", diskStats(src.DiskPaths), nil)
+
+	if src.ProbeNAT != nil {
+		results, err := src.ProbeNAT(ctx)
+		addJSON("nat.json", results, err)
+	}
+
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 1); err != nil {
+		m.Errors["goroutines.txt"] = err.Error()
+	} else {
+		files["goroutines.txt"] = goroutines.Bytes()
+	}
+
+	if src.Logs != nil {
+		files["logs.txt"] = src.Logs.Bytes()
+	}
+
+	addJSON("manifest.json", m, nil)
+
+	return writeBundle(files)
+}
+
+// Deliver puts the bundle to the upload url of the request and returns nothing,
+// or returns the bundle to the scheduler if the request has no upload url
+func Deliver(ctx context.Context, req *types.NodeDiagnosticsReq, data []byte) ([]byte, error) {
+	if req.UploadURL == "" {
+		if len(data) > MaxBundleSize {
+			return nil, xerrors.Errorf("bundle size %d exceeds %d, upload it to a presigned url", len(data), MaxBundleSize)
+		}
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, req.UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.ContentLength = int64(len(data))
+	httpReq.Header.Set("Content-Type", "application/gzip")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, xerrors.Errorf("upload bundle err:%s", err.Error())
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, xerrors.Errorf("upload bundle status %d", resp.StatusCode)
+	}
+
+	return nil, nil
+}
+
+// ProbeNAT asks the candidates of the urls for the external address of the node with the http client
+func ProbeNAT(ctx context.Context, urls []string, httpClient *http.Client) []*types.NATProbeResult {
+	results := make([]*types.NATProbeResult, 0, len(urls))
+	for _, url := range urls {
+		result := &types.NATProbeResult{CandidateURL: url}
+		addr, err := externalAddress(ctx, url, httpClient)
+		if err != nil {
+			result.Err = err.Error()
+		}
+		result.ExternalAddr = addr
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func externalAddress(ctx context.Context, url string, httpClient *http.Client) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, natProbeTimeout)
+	defer cancel()
+
+	candidateAPI, closer, err := client.NewCandidate(ctx, url, nil, jsonrpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return "", err
+	}
+	defer closer()
+
+	return candidateAPI.GetExternalAddress(ctx)
+}
+
+func diskStats(paths []string) []*types.DiskStat {
+	stats := make([]*types.DiskStat, 0, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		usage, err := disk.Usage(path)
+		if err != nil {
+			log.Warnf("disk usage of %s err:%s", path, err.Error())
+			continue
+		}
+
+		stats = append(stats, &types.DiskStat{
+			Path:        path,
+			FSType:      usage.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+
+	return stats
+}
+
+// maskConfig converts the config to a map with the non-empty secrets masked
+func maskConfig(cfg interface{}) (interface{}, error) {
+	buf, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(buf, &out); err != nil {
+		return nil, err
+	}
+
+	maskSecrets(out)
+	return out, nil
+}
+
+func maskSecrets(v interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, field := range value {
+			if s, ok := field.(string); ok && s != "" && isSecret(k) {
+				value[k] = maskedValue
+				continue
+			}
+			maskSecrets(field)
+		}
+	case []interface{}:
+		for _, item := range value {
+			maskSecrets(item)
+		}
+	}
+}
+
+func isSecret(name string) bool {
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, "key") {
+		return true
+	}
+
+	for _, word := range secretWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeBundle(files map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		data := files[name]
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, xerrors.Errorf("write header of %s %w", name, err)
+		}
+
+		if _, err := tw.Write(data); err != nil {
+			return nil, xerrors.Errorf("write %s %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package diagnostics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+func TestMaskConfig(t *testing.T) {
+	cfg := &config.CandidateCfg{}
+	cfg.Secret = "node-secret"
+	cfg.SecretAccessKey = "minio-secret"
+	cfg.AccessKeyID = "minio-id"
+	cfg.PrivateKeyPath = "/path/to/key"
+	cfg.AreaID = "Asia-China"
+
+	out, err := maskConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := out.(map[string]interface{})
+	if m["Secret"] != maskedValue {
+		t.Errorf("secret is not masked: %v", m["Secret"])
+	}
+	if m["SecretAccessKey"] != maskedValue {
+		t.Errorf("secret access key is not masked: %v", m["SecretAccessKey"])
+	}
+	if m["AccessKeyID"] != "minio-id" || m["PrivateKeyPath"] != "/path/to/key" || m["AreaID"] != "Asia-China" {
+		t.Errorf("unexpected masked fields: %v", out)
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	b := &LogBuffer{size: 64}
+
+	for i := 0; i < 100; i++ {
+		if _, err := b.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs := b.Bytes()
+	if len(logs) > 64 {
+		t.Fatalf("logs size %d exceeds the buffer size", len(logs))
+	}
+
+	for _, line := range strings.Split(string(bytes.TrimSuffix(logs, []byte("\n"))), "\n") {
+		if line != "0123456789" {
+			t.Fatalf("partial line %q", line)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package diagnostics
+
+import (
+	"bytes"
+	"sync"
+
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("diagnostics")
+
+// bytes of the recent logs kept by the node
+const logBufferSize = 4 << 20
+
+// LogBuffer keeps the recent logs of all the loggers of the node in memory for the diagnostics bundles
+type LogBuffer struct {
+	lk   sync.Mutex
+	buf  []byte
+	size int
+}
+
+// NewLogBuffer starts keeping the recent logs of the node
+func NewLogBuffer() *LogBuffer {
+	b := &LogBuffer{size: logBufferSize}
+
+	reader := logging.NewPipeReader(logging.PipeFormat(logging.PlaintextOutput))
+	go b.read(reader)
+
+	return b
+}
+
+// read copies the logs from the pipe, the pipe is synchronous so it is read without delay
+func (b *LogBuffer) read(reader *logging.PipeReader) {
+	data := make([]byte, 32<<10)
+	for {
+		n, err := reader.Read(data)
+		if n > 0 {
+			b.Write(data[:n])
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Write appends the logs, the oldest are dropped when the buffer is full
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	b.buf = append(b.buf, p...)
+	// compact at twice the size so the logs are not copied on every write
+	if len(b.buf) > 2*b.size {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.size:]...)
+	}
+
+	return len(p), nil
+}
+
+// Bytes returns a copy of the recent logs starting at a whole line
+func (b *LogBuffer) Bytes() []byte {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	data := b.buf
+	if len(data) > b.size {
+		data = data[len(data)-b.size:]
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	return append([]byte(nil), data...)
+}
@@ -0,0 +1,238 @@
+package diagnostics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the node streams at most the logs per second and for the duration whatever the scheduler asks
+	maxLogStreamRate     = 200
+	maxLogStreamDuration = 30 * time.Minute
+	// the streams open at once on the node
+	maxLogStreams = 2
+	// the logs waiting to be pushed are sent to the scheduler once in the interval
+	logPushInterval = time.Second
+	logPushTimeout  = 10 * time.Second
+	// the logs waiting to be pushed, the logs over it are dropped and counted
+	logQueueSize = 1000
+	// the time layout of the json logs
+	logTimeLayout = "2006-01-02T15:04:05.000Z0700"
+)
+
+// PushLogsFunc pushes the logs of a stream to the scheduler
+type PushLogsFunc func(ctx context.Context, batch *types.LogBatch) error
+
+// LogStreams streams the logs of the node to the scheduler on demand, the logs of a stream are filtered by the level
+// and the subsystems of its request, rate limited and streamed until the duration of the request elapses
+type LogStreams struct {
+	lk      sync.Mutex
+	streams map[string]*logStream
+}
+
+type logStream struct {
+	id         string
+	reader     *logging.PipeReader
+	subsystems map[string]bool
+	limiter    *rate.Limiter
+	queue      chan *types.LogEntry
+	// the logs dropped since the last log queued, only the read goroutine touches it
+	dropped int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewLogStreams returns the log streams of the node
+func NewLogStreams() *LogStreams {
+	return &LogStreams{streams: make(map[string]*logStream)}
+}
+
+// Start opens the log stream of the request, the logs are pushed with the push func until the stream is stopped,
+// its duration elapses or a push fails
+func (s *LogStreams) Start(req *types.LogStreamReq, push PushLogsFunc) error {
+	if req == nil || req.ID == "" {
+		return xerrors.New("stream id can not be empty")
+	}
+
+	level, err := logging.LevelFromString(req.Level)
+	if err != nil {
+		return xerrors.Errorf("invalid log level %s", req.Level)
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	if duration <= 0 || duration > maxLogStreamDuration {
+		duration = maxLogStreamDuration
+	}
+
+	limit := req.RateLimit
+	if limit <= 0 || limit > maxLogStreamRate {
+		limit = maxLogStreamRate
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if _, ok := s.streams[req.ID]; ok {
+		return xerrors.Errorf("log stream %s already exists", req.ID)
+	}
+
+	if len(s.streams) >= maxLogStreams {
+		return xerrors.Errorf("%d log streams are open, try again later", len(s.streams))
+	}
+
+	st := &logStream{
+		id:         req.ID,
+		reader:     logging.NewPipeReader(logging.PipeFormat(logging.JSONOutput), logging.PipeLevel(level)),
+		subsystems: make(map[string]bool, len(req.Subsystems)),
+		limiter:    rate.NewLimiter(rate.Limit(limit), limit),
+		queue:      make(chan *types.LogEntry, logQueueSize),
+		stop:       make(chan struct{}),
+	}
+	for _, subsystem := range req.Subsystems {
+		st.subsystems[subsystem] = true
+	}
+
+	s.streams[req.ID] = st
+
+	go st.read()
+	go func() {
+		st.push(push, duration)
+
+		s.lk.Lock()
+		delete(s.streams, st.id)
+		s.lk.Unlock()
+	}()
+
+	return nil
+}
+
+// Stop closes the log stream
+func (s *LogStreams) Stop(id string) error {
+	s.lk.Lock()
+	st, ok := s.streams[id]
+	s.lk.Unlock()
+
+	if !ok {
+		return xerrors.Errorf("log stream %s not found", id)
+	}
+
+	st.stopOnce.Do(func() { close(st.stop) })
+	return nil
+}
+
+// read queues the logs from the pipe until the pipe is closed, the pipe is synchronous so it is read without delay
+// and the logs are dropped if the queue is full
+func (st *logStream) read() {
+	reader := bufio.NewReader(st.reader)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			st.add(line)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (st *logStream) add(line []byte) {
+	entry := parseLogEntry(line)
+	if entry == nil {
+		return
+	}
+
+	if len(st.subsystems) > 0 && !st.subsystems[entry.Logger] {
+		return
+	}
+
+	if !st.limiter.Allow() {
+		st.dropped++
+		return
+	}
+
+	entry.Dropped = st.dropped
+	select {
+	case st.queue <- entry:
+		st.dropped = 0
+	default:
+		st.dropped++
+	}
+}
+
+// push sends the queued logs to the scheduler in batches, the pipe is closed when the stream ends
+func (st *logStream) push(push PushLogsFunc, duration time.Duration) {
+	defer st.reader.Close() //nolint:errcheck // ignore error
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(logPushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return
+		case <-st.stop:
+			return
+		}
+
+		entries := st.take()
+		if len(entries) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), logPushTimeout)
+		err := push(ctx, &types.LogBatch{StreamID: st.id, Entries: entries})
+		cancel()
+
+		if err != nil {
+			log.Infof("log stream %s is closed: %s", st.id, err.Error())
+			return
+		}
+	}
+}
+
+func (st *logStream) take() []*types.LogEntry {
+	var entries []*types.LogEntry
+	for {
+		select {
+		case entry := <-st.queue:
+			entries = append(entries, entry)
+		default:
+			return entries
+		}
+	}
+}
+
+// parseLogEntry parses the json log, nil if it is malformed
+func parseLogEntry(line []byte) *types.LogEntry {
+	var l struct {
+		Level  string `json:"level"`
+		Time   string `json:"ts"`
+		Logger string `json:"logger"`
+		Msg    string `json:"msg"`
+	}
+
+	if err := json.Unmarshal(line, &l); err != nil {
+		return nil
+	}
+
+	entry := &types.LogEntry{Level: l.Level, Logger: l.Logger, Message: l.Msg}
+	if t, err := time.Parse(logTimeLayout, l.Time); err == nil {
+		entry.Time = t
+	}
+
+	return entry
+}
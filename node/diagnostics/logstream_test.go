@@ -0,0 +1,49 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/time/rate"
+)
+
+func TestParseLogEntry(t *testing.T) {
+	line := []byte(`{"level":"warn","ts":"2023-08-01T10:20:30.123+0800","logger":"edge","caller":"edge/impl.go:10","msg":"disk is full"}`)
+
+	entry := parseLogEntry(line)
+	if entry == nil {
+		t.Fatal("log is not parsed")
+	}
+
+	if entry.Level != "warn" || entry.Logger != "edge" || entry.Message != "disk is full" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Time.IsZero() || entry.Time.UTC().Hour() != 2 {
+		t.Errorf("unexpected time %s", entry.Time)
+	}
+
+	if parseLogEntry([]byte("not a json log")) != nil {
+		t.Error("malformed log is parsed")
+	}
+}
+
+func TestLogStreamAdd(t *testing.T) {
+	st := &logStream{
+		subsystems: map[string]bool{"edge": true},
+		limiter:    rate.NewLimiter(rate.Limit(1), 2),
+		queue:      make(chan *types.LogEntry, 10),
+	}
+
+	st.add([]byte(`{"level":"info","logger":"asset","msg":"filtered"}`))
+	for i := 0; i < 5; i++ {
+		st.add([]byte(`{"level":"info","logger":"edge","msg":"streamed"}`))
+	}
+
+	entries := st.take()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 logs within the rate, got %d", len(entries))
+	}
+	if st.dropped != 3 {
+		t.Errorf("expected 3 logs dropped, got %d", st.dropped)
+	}
+}
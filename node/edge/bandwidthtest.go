@@ -0,0 +1,206 @@
+package edge
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the lowest round trip time of the probes is the latency
+	latencyProbes = 3
+	probeTimeout  = 10 * time.Second
+	// the longest transfer the candidates serve
+	maxBandwidthTestDuration = 30
+	// the scheduler finishes a test within its timeout
+	waitBandwidthTestTimeout  = 3 * time.Minute
+	pollBandwidthTestInterval = 3 * time.Second
+)
+
+// BandwidthTest measures the latency and the bandwidth of the edge against the candidates one by one,
+// the transfers are timed and signed by the candidates
+func (edge *Edge) BandwidthTest(ctx context.Context, req *types.BandwidthTestReq) ([]*types.BandwidthTestReport, error) {
+	if req.Duration <= 0 || req.Duration > maxBandwidthTestDuration {
+		return nil, xerrors.Errorf("invalid duration %d", req.Duration)
+	}
+
+	client := &http.Client{}
+	duration := time.Duration(req.Duration) * time.Second
+
+	reports := make([]*types.BandwidthTestReport, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		report := &types.BandwidthTestReport{CandidateID: target.CandidateID}
+		reports = append(reports, report)
+
+		if err := measureCandidate(ctx, client, target.URL, duration, report); err != nil {
+			log.Warnf("bandwidth test %s against %s err:%s", req.TestID, target.CandidateID, err.Error())
+			report.Err = err.Error()
+		}
+	}
+
+	return reports, nil
+}
+
+// StartBandwidthTest asks the scheduler to test the bandwidth of the edge and waits for the result
+func (edge *Edge) StartBandwidthTest(ctx context.Context) (*types.BandwidthTest, error) {
+	testID, err := edge.SchedulerAPI.StartBandwidthTest(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.After(waitBandwidthTestTimeout)
+	ticker := time.NewTicker(pollBandwidthTestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return nil, xerrors.Errorf("bandwidth test %s is not finished in %s", testID, waitBandwidthTestTimeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		test, err := edge.SchedulerAPI.GetBandwidthTest(ctx, testID)
+		if err != nil {
+			return nil, err
+		}
+
+		if test.Status != types.BandwidthTestRunning {
+			return test, nil
+		}
+	}
+}
+
+func measureCandidate(ctx context.Context, client *http.Client, url string, duration time.Duration, report *types.BandwidthTestReport) error {
+	latency, err := measureLatency(ctx, client, url)
+	if err != nil {
+		return xerrors.Errorf("latency: %w", err)
+	}
+	report.Latency = latency
+
+	if report.Upload, err = measureUpload(ctx, client, url, duration); err != nil {
+		return xerrors.Errorf("upload: %w", err)
+	}
+
+	if report.Download, err = measureDownload(ctx, client, url, duration); err != nil {
+		return xerrors.Errorf("download: %w", err)
+	}
+
+	return nil
+}
+
+// measureLatency returns the lowest round trip time of the probes in milliseconds, at least 1
+func measureLatency(ctx context.Context, client *http.Client, url string) (int64, error) {
+	var lowest time.Duration
+	for i := 0; i < latencyProbes; i++ {
+		start := time.Now()
+		if _, err := doBandwidthTestRequest(ctx, client, http.MethodHead, url, nil, probeTimeout); err != nil {
+			return 0, err
+		}
+
+		if rtt := time.Since(start); lowest == 0 || rtt < lowest {
+			lowest = rtt
+		}
+	}
+
+	if lowest < time.Millisecond {
+		return 1, nil
+	}
+	return lowest.Milliseconds(), nil
+}
+
+func measureUpload(ctx context.Context, client *http.Client, url string, duration time.Duration) (*types.BandwidthMeasurement, error) {
+	body := &timedReader{deadline: time.Now().Add(duration)}
+	buf, err := doBandwidthTestRequest(ctx, client, http.MethodPost, url, body, duration+probeTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := &types.BandwidthMeasurement{}
+	if err := json.Unmarshal(buf, measurement); err != nil {
+		return nil, err
+	}
+	return measurement, nil
+}
+
+func measureDownload(ctx context.Context, client *http.Client, url string, duration time.Duration) (*types.BandwidthMeasurement, error) {
+	ctx, cancel := context.WithTimeout(ctx, duration+probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	// the trailer is available after the body is read
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return nil, err
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(resp.Trailer.Get(types.BandwidthTestTrailer))
+	if err != nil || len(buf) == 0 {
+		return nil, fmt.Errorf("no measurement in the response")
+	}
+
+	measurement := &types.BandwidthMeasurement{}
+	if err := json.Unmarshal(buf, measurement); err != nil {
+		return nil, err
+	}
+	return measurement, nil
+}
+
+func doBandwidthTestRequest(ctx context.Context, client *http.Client, method, url string, body io.Reader, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(buf))
+	}
+	return buf, nil
+}
+
+// timedReader produces data until the deadline
+type timedReader struct {
+	deadline time.Time
+}
+
+func (r *timedReader) Read(p []byte) (int, error) {
+	if time.Now().After(r.deadline) {
+		return 0, io.EOF
+	}
+	return len(p), nil
+}
@@ -0,0 +1,55 @@
+package edge
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/diagnostics"
+)
+
+// CollectDiagnostics collects the diagnostics bundle of the edge, the gzipped tar is returned
+// or uploaded to the upload url of the request
+func (edge *Edge) CollectDiagnostics(ctx context.Context, req *types.NodeDiagnosticsReq) ([]byte, error) {
+	src := &diagnostics.Source{
+		Device:    edge.Device,
+		Config:    edge.Config,
+		Logs:      edge.Logs,
+		DiskPaths: []string{edge.Config.Storage.Path},
+		ProbeNAT:  edge.probeNAT,
+	}
+
+	data, err := diagnostics.Collect(ctx, req, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnostics.Deliver(ctx, req, data)
+}
+
+// probeNAT asks the candidates for the external address of the edge over the transport of the edge,
+// so the candidates see the address mapped by the nat of the edge
+func (edge *Edge) probeNAT(ctx context.Context) ([]*types.NATProbeResult, error) {
+	urls, err := edge.SchedulerAPI.GetCandidateURLsForDetectNat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := client.NewHTTP3ClientWithPacketConn(edge.Transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnostics.ProbeNAT(ctx, urls, httpClient), nil
+}
+
+// StartLogStream streams the logs of the edge filtered by the level and the subsystems of the request to the scheduler,
+// rate limited and until the duration of the request elapses
+func (edge *Edge) StartLogStream(ctx context.Context, req *types.LogStreamReq) error {
+	return edge.LogStreams.Start(req, edge.SchedulerAPI.PushNodeLogs)
+}
+
+// StopLogStream closes the log stream
+func (edge *Edge) StopLogStream(ctx context.Context, id string) error {
+	return edge.LogStreams.Stop(id)
+}
@@ -7,9 +7,14 @@ import (
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/client"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/limiter"
 	"github.com/Filecoin-Titan/titan/node/asset"
 	"github.com/Filecoin-Titan/titan/node/common"
+	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/device"
+	"github.com/Filecoin-Titan/titan/node/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/nodeconfig"
+	"github.com/Filecoin-Titan/titan/node/rtc"
 	datasync "github.com/Filecoin-Titan/titan/node/sync"
 	validate "github.com/Filecoin-Titan/titan/node/validation"
 	"github.com/filecoin-project/go-jsonrpc"
@@ -33,6 +38,12 @@ type Edge struct {
 
 	Transport    *quic.Transport
 	SchedulerAPI api.Scheduler
+	Shaper       *limiter.Shaper
+	Config       *config.EdgeCfg
+	Logs         *diagnostics.LogBuffer
+	LogStreams   *diagnostics.LogStreams
+	Flags        *nodeconfig.Flags
+	WebRTC       *rtc.Server
 }
 
 // WaitQuiet waits for the edge device to become idle.
@@ -88,3 +99,14 @@ func (edge *Edge) GetEdgeOnlineStateFromScheduler(ctx context.Context) (bool, er
 	}
 	return online, nil
 }
+
+// SetUploadLimit limits the upload rate of the data server, 0 removes the limit
+func (edge *Edge) SetUploadLimit(ctx context.Context, bytesPerSec int64) error {
+	if bytesPerSec < 0 {
+		return xerrors.Errorf("invalid upload limit %d", bytesPerSec)
+	}
+
+	log.Infof("set upload limit %d B/s", bytesPerSec)
+	edge.Shaper.SetLimit(bytesPerSec)
+	return nil
+}
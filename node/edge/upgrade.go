@@ -0,0 +1,25 @@
+package edge
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/node/upgrader"
+)
+
+// Upgrade verifies the upgrade command signed by the scheduler and replaces the binary of the edge with the release,
+// the edge exits after and is restarted by its supervisor
+func (edge *Edge) Upgrade(ctx context.Context, cmd *types.UpgradeCommand) error {
+	pem, err := edge.SchedulerAPI.GetSchedulerPublicKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := titanrsa.Pem2PublicKey([]byte(pem))
+	if err != nil {
+		return err
+	}
+
+	return upgrader.Apply(ctx, cmd, publicKey, edge.ShutdownChan)
+}
@@ -0,0 +1,12 @@
+package edge
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// WebRTCOffer answers the webrtc offer of a browser relayed by the scheduler
+func (edge *Edge) WebRTCOffer(ctx context.Context, offer *types.WebRTCOffer) (*types.WebRTCAnswer, error) {
+	return edge.WebRTC.Offer(ctx, offer)
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"strings"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	logging "github.com/ipfs/go-log/v2"
 )
 
@@ -17,6 +19,10 @@ type (
 	RemoteAddr struct{}
 	// user id (node id)
 	ID struct{}
+	// common name of the verified client certificate
+	PeerCertID struct{}
+	// name of the api key of the user
+	APIKeyName struct{}
 )
 
 // Handler represents an HTTP handler that also adds remote client address and node ID to the request context
@@ -51,6 +57,24 @@ func GetNodeID(ctx context.Context) string {
 	return v
 }
 
+// GetCallerID returns the id of the token of the client, whatever its role is
+func GetCallerID(ctx context.Context) string {
+	v, ok := ctx.Value(ID{}).(string)
+	if !ok {
+		return ""
+	}
+	return v
+}
+
+// GetPeerCertID returns the common name of the client certificate verified by tls, empty if none
+func GetPeerCertID(ctx context.Context) string {
+	v, ok := ctx.Value(PeerCertID{}).(string)
+	if !ok {
+		return ""
+	}
+	return v
+}
+
 // GetUserID returns the user ID of the client
 func GetUserID(ctx context.Context) string {
 	// check role
@@ -65,6 +89,19 @@ func GetUserID(ctx context.Context) string {
 	return v
 }
 
+// GetAPIKeyName returns the name of the api key the user calls with, empty if the user calls with a login token
+func GetAPIKeyName(ctx context.Context) string {
+	if !api.HasPerm(ctx, api.RoleDefault, api.RoleUser) {
+		return ""
+	}
+
+	v, ok := ctx.Value(APIKeyName{}).(string)
+	if !ok {
+		return ""
+	}
+	return v
+}
+
 // New returns a new HTTP handler with the given auth handler and additional request context fields
 func New(verify func(ctx context.Context, token string) (*types.JWTPayload, error), next http.HandlerFunc) http.Handler {
 	return &Handler{verify, next}
@@ -79,6 +116,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, RemoteAddr{}, remoteAddr)
+	// continue the trace of the caller
+	ctx = tracing.Extract(ctx, r.Header)
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		ctx = context.WithValue(ctx, PeerCertID{}, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+	}
 
 	token := r.Header.Get("Authorization")
 	if token == "" {
@@ -103,9 +146,21 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// the token is bound to the host it is issued to, reject the token replayed from other hosts
+		if payload.Host != "" {
+			if ip, _, err := net.SplitHostPort(remoteAddr); err != nil || ip != payload.Host {
+				log.Warnf("token of %s is issued to %s, not %s", payload.ID, payload.Host, remoteAddr)
+				w.WriteHeader(401)
+				return
+			}
+		}
+
 		ctx = context.WithValue(ctx, ID{}, payload.ID)
 		ctx = api.WithPerm(ctx, payload.Allow)
 		ctx = api.WithUserAccessControl(ctx, payload.AccessControlList)
+		ctx = api.WithTenant(ctx, payload.Tenant)
+		// the api keys of the users carry the key name
+		ctx = context.WithValue(ctx, APIKeyName{}, payload.Extend)
 	}
 
 	h.next(w, r.WithContext(ctx))
@@ -0,0 +1,61 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// how long the node caches the access of a user to an asset checked by the scheduler
+	assetAccessTTL = time.Minute
+	// the expired accesses are removed every so many checks
+	assetAccessSweep = 1000
+)
+
+type assetAccess struct {
+	allowed    bool
+	expiration time.Time
+}
+
+// checkAssetAccess asks the scheduler whether the user can download the asset, userID is empty for anonymous requests.
+// The requests carrying a token issued by the scheduler are checked when the token is issued
+func (hs *HttpServer) checkAssetAccess(assetCID, userID, apiKeyName string) error {
+	key := fmt.Sprintf("%s/%s/%s", assetCID, userID, apiKeyName)
+	if v, ok := hs.accessCache.Load(key); ok {
+		access := v.(*assetAccess)
+		if access.expiration.After(time.Now()) {
+			if !access.allowed {
+				return fmt.Errorf("asset %s is private", assetCID)
+			}
+			return nil
+		}
+		hs.accessCache.Delete(key)
+	}
+
+	allowed, err := hs.scheduler.CheckAssetAccess(context.Background(), assetCID, userID, apiKeyName)
+	if err != nil {
+		return err
+	}
+
+	hs.accessCache.Store(key, &assetAccess{allowed: allowed, expiration: time.Now().Add(assetAccessTTL)})
+	if atomic.AddInt64(&hs.accessStores, 1)%assetAccessSweep == 0 {
+		hs.sweepAssetAccess()
+	}
+
+	if !allowed {
+		return fmt.Errorf("asset %s is private", assetCID)
+	}
+	return nil
+}
+
+func (hs *HttpServer) sweepAssetAccess() {
+	now := time.Now()
+	hs.accessCache.Range(func(key, value any) bool {
+		if value.(*assetAccess).expiration.Before(now) {
+			hs.accessCache.Delete(key)
+		}
+		return true
+	})
+}
@@ -19,10 +19,16 @@ type Asset interface {
 	HasBlock(ctx context.Context, root, block cid.Cid) (bool, error)
 	// GetBlock retrieves a block with the given CID from the asset data for a given root CID.
 	GetBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error)
+	// GetAssetManifest returns the blocks of the asset for a given root CID.
+	GetAssetManifest(ctx context.Context, root cid.Cid) (*types.AssetManifest, error)
 	// SaveUserAsset save user asset to local
 	SaveUserAsset(ctx context.Context, userID string, root cid.Cid, assetSize int64, r io.Reader) error
 	// SetAssetUploadProgress set progress of upload for asset
 	SetAssetUploadProgress(ctx context.Context, root cid.Cid, progress *types.UploadProgress) error
 	// GetUploadingAsset get asset which uploading
 	GetUploadingAsset(ctx context.Context, root cid.Cid) (*types.UploadingAsset, error)
+	// IngestUserAsset save the uploaded data as user asset and report it to scheduler, returns the root cid
+	IngestUserAsset(ctx context.Context, payload *types.AuthUserIngestAsset, r io.Reader, isCar bool) (cid.Cid, error)
+	// PutS3Object save the content of s3 object as user asset and record the object on scheduler for the user of the grant
+	PutS3Object(ctx context.Context, grant *types.S3Grant, object *types.S3Object, r io.Reader) (*types.S3Object, error)
 }
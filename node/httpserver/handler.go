@@ -19,7 +19,10 @@ var log = logging.Logger("httpserver")
 const (
 	ipfsPathPrefix        = "/ipfs/"
 	uploadPathPrefix      = "/upload"
+	ingestPathPrefix      = "/ingest"
+	s3PathPrefix          = "/s3"
 	rpcPathPrefix         = "/rpc"
+	relayPathPrefix       = "/relay/"
 	immutableCacheControl = "public, max-age=29030400, immutable"
 	domainFields          = 4
 )
@@ -54,15 +57,27 @@ func resetPath(r *http.Request) {
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if !strings.Contains(r.URL.Path, ipfsPathPrefix) &&
 		!strings.Contains(r.URL.Path, uploadPathPrefix) &&
-		!strings.Contains(r.URL.Path, rpcPathPrefix) {
+		!strings.Contains(r.URL.Path, ingestPathPrefix) &&
+		!h.hs.isS3Request(r) &&
+		!strings.Contains(r.URL.Path, rpcPathPrefix) &&
+		!strings.HasPrefix(r.URL.Path, relayPathPrefix) &&
+		!strings.HasPrefix(r.URL.Path, bandwidthTestPath) {
 		resetPath(r)
 	}
 
 	switch {
+	case h.hs.relay != nil && strings.HasPrefix(r.URL.Path, relayPathPrefix):
+		h.hs.relay.ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, ipfsPathPrefix):
 		h.hs.handler(w, r)
 	case strings.HasPrefix(r.URL.Path, uploadPathPrefix):
 		h.hs.uploadHandler(w, r)
+	case strings.HasPrefix(r.URL.Path, ingestPathPrefix):
+		h.hs.ingestHandler(w, r)
+	case strings.HasPrefix(r.URL.Path, bandwidthTestPath):
+		h.hs.bandwidthTestHandler(w, r)
+	case h.hs.isS3Request(r):
+		h.hs.s3Handler(w, r)
 	default:
 		h.handler.ServeHTTP(w, r)
 	}
@@ -0,0 +1,196 @@
+package httpserver
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+)
+
+const (
+	bandwidthTestPath = "/bandwidth-test"
+	// the longest transfer the node serves, the duration is signed by the scheduler
+	maxBandwidthTestDuration = 30
+	bandwidthTestBufferSize  = 32 << 10
+)
+
+type bandwidthTestParams struct {
+	testID      string
+	nodeID      string
+	candidateID string
+	duration    time.Duration
+}
+
+// bandwidthTestHandler times the transfers of the bandwidth test of an edge, the edge uploads by post and downloads by get,
+// the measurements are signed by the node so the scheduler can trust them
+func (hs *HttpServer) bandwidthTestHandler(w http.ResponseWriter, r *http.Request) {
+	params, err := hs.verifyBandwidthTest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		// the latency probe
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		hs.bandwidthTestUpload(w, r, params)
+	case http.MethodGet:
+		hs.bandwidthTestDownload(w, params)
+	default:
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+	}
+}
+
+// verifyBandwidthTest checks the parameters of the bandwidth test signed by the scheduler
+func (hs *HttpServer) verifyBandwidthTest(r *http.Request) (*bandwidthTestParams, error) {
+	query := r.URL.Query()
+
+	expires, err := strconv.ParseInt(query.Get(types.BandwidthTestExpiresParam), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration %s", query.Get(types.BandwidthTestExpiresParam))
+	}
+
+	if time.Now().After(time.Unix(expires, 0)) {
+		return nil, fmt.Errorf("bandwidth test expired")
+	}
+
+	duration, err := strconv.Atoi(query.Get(types.BandwidthTestDurationParam))
+	if err != nil || duration <= 0 || duration > maxBandwidthTestDuration {
+		return nil, fmt.Errorf("invalid duration %s", query.Get(types.BandwidthTestDurationParam))
+	}
+
+	sign, err := hex.DecodeString(query.Get(types.BandwidthTestSignatureParam))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature %w", err)
+	}
+
+	params := &bandwidthTestParams{
+		testID:      query.Get(types.BandwidthTestIDParam),
+		nodeID:      query.Get(types.BandwidthTestNodeParam),
+		candidateID: query.Get(types.BandwidthTestCandidateParam),
+		duration:    time.Duration(duration) * time.Second,
+	}
+
+	content := types.BandwidthTestContent(params.testID, params.nodeID, params.candidateID, duration, expires)
+	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	if err := hs.verifySchedulerSign(rsa, sign, content); err != nil {
+		return nil, fmt.Errorf("verify bandwidth test %w", err)
+	}
+
+	return params, nil
+}
+
+// bandwidthTestUpload reads the upload of the edge and responds the signed measurement,
+// the upload is timed from its first byte and cut at twice the duration
+func (hs *HttpServer) bandwidthTestUpload(w http.ResponseWriter, r *http.Request, params *bandwidthTestParams) {
+	buf := make([]byte, bandwidthTestBufferSize)
+	deadline := time.Now().Add(2 * params.duration)
+
+	var start time.Time
+	var total int64
+	for time.Now().Before(deadline) {
+		n, err := r.Body.Read(buf)
+		if n > 0 {
+			if start.IsZero() {
+				start = time.Now()
+			}
+			total += int64(n)
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if start.IsZero() {
+		http.Error(w, "empty upload", http.StatusBadRequest)
+		return
+	}
+
+	measurement, err := hs.signBandwidthMeasurement(params, true, total, time.Since(start))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(measurement); err != nil {
+		log.Errorf("bandwidth test write measurement err:%s", err.Error())
+	}
+}
+
+// bandwidthTestDownload sends data to the edge for the duration, the signed measurement is sent in the trailer
+func (hs *HttpServer) bandwidthTestDownload(w http.ResponseWriter, params *bandwidthTestParams) {
+	buf := make([]byte, bandwidthTestBufferSize)
+	if _, err := rand.Read(buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Trailer", types.BandwidthTestTrailer)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+
+	start := time.Now()
+	deadline := start.Add(params.duration)
+
+	var total int64
+	for time.Now().Before(deadline) {
+		n, err := w.Write(buf)
+		total += int64(n)
+		if err != nil {
+			log.Debugf("bandwidth test download of %s err:%s", params.nodeID, err.Error())
+			return
+		}
+	}
+
+	measurement, err := hs.signBandwidthMeasurement(params, false, total, time.Since(start))
+	if err != nil {
+		log.Errorf("sign bandwidth measurement err:%s", err.Error())
+		return
+	}
+
+	buf, err = json.Marshal(measurement)
+	if err != nil {
+		log.Errorf("marshal bandwidth measurement err:%s", err.Error())
+		return
+	}
+
+	w.Header().Set(types.BandwidthTestTrailer, base64.StdEncoding.EncodeToString(buf))
+}
+
+func (hs *HttpServer) signBandwidthMeasurement(params *bandwidthTestParams, upload bool, total int64, elapsed time.Duration) (*types.BandwidthMeasurement, error) {
+	measurement := &types.BandwidthMeasurement{
+		TestID:      params.testID,
+		NodeID:      params.nodeID,
+		CandidateID: params.candidateID,
+		Upload:      upload,
+		Bytes:       total,
+		Duration:    elapsed.Milliseconds(),
+	}
+
+	sign, err := nodekey.Sign(hs.privateKey, measurement.SignData())
+	if err != nil {
+		return nil, err
+	}
+	measurement.Sign = sign
+
+	return measurement, nil
+}
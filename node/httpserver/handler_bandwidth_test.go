@@ -0,0 +1,101 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+)
+
+func TestBandwidthTestHandler(t *testing.T) {
+	schedulerKey, err := titanrsa.GeneratePrivateKey(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeKey, err := nodekey.Generate(nodekey.TypeEd25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := &HttpServer{schedulerPublicKey: &schedulerKey.PublicKey, publicKeyUpdateTime: time.Now(), privateKey: nodeKey}
+
+	expires := time.Now().Add(time.Minute).Unix()
+	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	sign, err := rsa.Sign(schedulerKey, types.BandwidthTestContent("test", "e_1", "c_1", 1, expires))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := url.Values{}
+	query.Set(types.BandwidthTestIDParam, "test")
+	query.Set(types.BandwidthTestNodeParam, "e_1")
+	query.Set(types.BandwidthTestCandidateParam, "c_1")
+	query.Set(types.BandwidthTestDurationParam, "1")
+	query.Set(types.BandwidthTestExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(types.BandwidthTestSignatureParam, hex.EncodeToString(sign))
+	target := bandwidthTestPath + "?" + query.Encode()
+
+	verify := func(m *types.BandwidthMeasurement, upload bool) {
+		if m.TestID != "test" || m.NodeID != "e_1" || m.CandidateID != "c_1" || m.Upload != upload || m.Bytes <= 0 {
+			t.Fatalf("unexpected measurement %+v", m)
+		}
+
+		if err := nodekey.Verify(nodeKey.Public(), m.Sign, m.SignData()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	hs.bandwidthTestHandler(w, httptest.NewRequest(http.MethodPost, target, bytes.NewReader(make([]byte, 1<<20))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: status %d %s", w.Code, w.Body.String())
+	}
+
+	upload := &types.BandwidthMeasurement{}
+	if err := json.Unmarshal(w.Body.Bytes(), upload); err != nil {
+		t.Fatal(err)
+	}
+	verify(upload, true)
+
+	if upload.Bytes != 1<<20 {
+		t.Fatalf("expected the upload of %d bytes, got %d", 1<<20, upload.Bytes)
+	}
+
+	w = httptest.NewRecorder()
+	hs.bandwidthTestHandler(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	buf, err := base64.StdEncoding.DecodeString(w.Result().Trailer.Get(types.BandwidthTestTrailer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	download := &types.BandwidthMeasurement{}
+	if err := json.Unmarshal(buf, download); err != nil {
+		t.Fatal(err)
+	}
+	verify(download, false)
+
+	if download.Bytes != int64(w.Body.Len()) {
+		t.Fatalf("expected the download of %d bytes, got %d", w.Body.Len(), download.Bytes)
+	}
+
+	// the duration is signed by the scheduler
+	query.Set(types.BandwidthTestDurationParam, "10")
+	w = httptest.NewRecorder()
+	hs.bandwidthTestHandler(w, httptest.NewRequest(http.MethodHead, bandwidthTestPath+"?"+query.Encode(), nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the tampered test to be refused, got status %d", w.Code)
+	}
+}
@@ -11,11 +11,13 @@ import (
 	"io"
 	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"golang.org/x/xerrors"
 )
@@ -28,6 +30,8 @@ const (
 	formatDagCbor = "application/vnd.ipld.dag-cbor"
 	formatJSON    = "application/json"
 	formatCbor    = "application/cbor"
+	// formatManifest lists the blocks of the asset, a node resuming a pull fetches only the blocks it misses
+	formatManifest = "application/vnd.titan.manifest+json"
 )
 
 func (hs *HttpServer) isNeedRedirect(r *http.Request) bool {
@@ -64,6 +68,11 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 		}()
 	}
 
+	if err = limitTokenRange(r, tkPayload); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
 	respFormat, formatParams, err := customResponseFormat(r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("processing the Accept header error: %s", err.Error()), http.StatusBadRequest)
@@ -71,7 +80,7 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	assetCID := tkPayload.AssetCID
-	speedCountWriter := &SpeedCountWriter{w: w, startTime: time.Now()}
+	speedCountWriter := &SpeedCountWriter{w: w, startTime: time.Now(), protocol: types.TransferProtocolOf(r.ProtoMajor), shaper: hs.shaper, ctx: r.Context()}
 	var statusCode int
 	var isDirectory bool
 
@@ -89,6 +98,8 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 		statusCode, err = hs.serveTAR(speedCountWriter, r, assetCID)
 	case formatDagJSON, formatDagCbor:
 		statusCode, err = hs.serveCodec(speedCountWriter, r, assetCID)
+	case formatManifest:
+		statusCode, err = hs.serveManifest(speedCountWriter, r, assetCID)
 	default: // catch-all for unsuported application/vnd.*
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("unsupported format %s", respFormat)
@@ -114,12 +125,16 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 
 // verifyToken checks the request's token to make sure it was authorized
 func (hs *HttpServer) verifyToken(w http.ResponseWriter, r *http.Request) (*types.TokenPayload, error) {
-	if hs.schedulerPublicKey == nil {
+	if hs.getSchedulerPublicKey() == nil {
 		return nil, fmt.Errorf("scheduler public key not exist, can not verify sign")
 	}
 
+	if r.URL.Query().Get(types.SignedURLSignatureParam) != "" {
+		return hs.verifySignedURL(r)
+	}
+
 	if token := r.Header.Get("User-Token"); len(token) > 0 {
-		_, err := hs.scheduler.AuthVerify(context.TODO(), token)
+		payload, err := hs.scheduler.AuthVerify(context.TODO(), token)
 		if err != nil {
 			return nil, err
 		}
@@ -128,6 +143,10 @@ func (hs *HttpServer) verifyToken(w http.ResponseWriter, r *http.Request) (*type
 		if err != nil {
 			return nil, err
 		}
+
+		if err := hs.checkAssetAccess(rootCID.String(), payload.ID, payload.Extend); err != nil {
+			return nil, err
+		}
 		return &types.TokenPayload{AssetCID: rootCID.String()}, nil
 	}
 
@@ -146,6 +165,10 @@ func (hs *HttpServer) verifyToken(w http.ResponseWriter, r *http.Request) (*type
 		if err != nil {
 			return nil, err
 		}
+
+		if err := hs.checkAssetAccess(rootCID.String(), "", ""); err != nil {
+			return nil, err
+		}
 		return &types.TokenPayload{AssetCID: rootCID.String()}, nil
 	}
 
@@ -169,12 +192,12 @@ func (hs *HttpServer) verifyToken(w http.ResponseWriter, r *http.Request) (*type
 	}
 
 	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	err = rsa.VerifySign(hs.schedulerPublicKey, sign, cipherText)
+	err = hs.verifySchedulerSign(rsa, sign, cipherText)
 	if err != nil {
 		return nil, err
 	}
 
-	mgs, err := rsa.Decrypt(cipherText, hs.privateKey)
+	mgs, err := nodekey.Decrypt(hs.privateKey, cipherText)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +210,12 @@ func (hs *HttpServer) verifyToken(w http.ResponseWriter, r *http.Request) (*type
 	if err != nil {
 		return nil, err
 	}
+
+	// the payload is not encrypted for ed25519 keys, any node could decode a token issued for another node
+	if tkPayload.NodeID != hs.nodeID {
+		return nil, xerrors.Errorf("token is issued for node %s", tkPayload.NodeID)
+	}
+
 	return tkPayload, nil
 }
 
@@ -213,6 +242,50 @@ func (hs *HttpServer) parseJWTToken(token string, r *http.Request) (*types.Token
 	return &types.TokenPayload{AssetCID: payload.AssetCID, ClientID: payload.UserID, Expiration: payload.Expiration}, nil
 }
 
+// limitTokenRange restricts the request to the byte range of the token, a request without Range gets the whole
+// assigned range, so the node serves and reports only the bytes the scheduler assigned to it
+func limitTokenRange(r *http.Request, tkPayload *types.TokenPayload) error {
+	if tkPayload.RangeEnd == 0 {
+		return nil
+	}
+
+	// a mismatched If-Range would turn the range into the whole content
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != fmt.Sprintf("%q", tkPayload.AssetCID) {
+		return fmt.Errorf("If-Range %s does not match the asset %s", ifRange, tkPayload.AssetCID)
+	}
+
+	header := r.Header.Get("Range")
+	if header == "" {
+		r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", tkPayload.RangeStart, tkPayload.RangeEnd))
+		return nil
+	}
+
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return fmt.Errorf("unsupported range %s", header)
+	}
+
+	startStr, endStr, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("unsupported range %s", header)
+	}
+
+	end := tkPayload.RangeEnd
+	if endStr != "" {
+		if end, err = strconv.ParseInt(endStr, 10, 64); err != nil {
+			return fmt.Errorf("unsupported range %s", header)
+		}
+	}
+
+	if start < tkPayload.RangeStart || start > end || end > tkPayload.RangeEnd {
+		return fmt.Errorf("range %s is out of the assigned range %d-%d", header, tkPayload.RangeStart, tkPayload.RangeEnd)
+	}
+
+	r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	return nil
+}
+
 // customResponseFormat checks the request's Accept header and query parameters to determine the desired response format
 func customResponseFormat(r *http.Request) (mediaType string, params map[string]string, err error) {
 	if formatParam := r.URL.Query().Get("format"); formatParam != "" {
@@ -232,6 +305,8 @@ func customResponseFormat(r *http.Request) (mediaType string, params map[string]
 			return formatDagJSON, nil, nil
 		case "dag-cbor":
 			return formatDagCbor, nil, nil
+		case "manifest":
+			return formatManifest, nil, nil
 		}
 	}
 	// Browsers and other user agents will send Accept header with generic types like:
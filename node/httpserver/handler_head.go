@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/files"
+	"github.com/ipfs/interface-go-ipfs-core/path"
 )
 
 // headHandler handles HTTP HEAD requests by checking if the given CID exists in the asset store.
@@ -22,9 +25,41 @@ func (hs *HttpServer) headHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hs.setFileHeaders(w, r, c)
 	w.WriteHeader(http.StatusOK)
 }
 
+// setFileHeaders sets the size and the etag of the unixfs file, the clients check them before the resumable download
+func (hs *HttpServer) setFileHeaders(w http.ResponseWriter, r *http.Request, root cid.Cid) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	resolvedPath, err := hs.resolvePath(ctx, path.New(r.URL.Path), root)
+	if err != nil {
+		return
+	}
+
+	node, err := hs.getUnixFsNode(ctx, resolvedPath, root)
+	if err != nil {
+		return
+	}
+	defer node.Close() //nolint:errcheck // ignore error
+
+	f, ok := node.(files.File)
+	if !ok {
+		return
+	}
+
+	size, err := f.Size()
+	if err != nil {
+		return
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Etag", getEtag(r, resolvedPath.Cid()))
+}
+
 // getCIDFromURLPath extracts the CID from the URL path of an IPFS request.
 // path=/ipfs/{cid}[/{path}].
 func getCIDFromURLPath(path string) (cid.Cid, error) {
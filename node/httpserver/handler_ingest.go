@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/gbrlsnchs/jwt/v3"
+)
+
+// ingestHandler saves the uploaded car or file as user asset, the file is converted to car,
+// the root cid of the asset is reported to the scheduler to start the replication
+func (hs *HttpServer) ingestHandler(w http.ResponseWriter, r *http.Request) {
+	log.Debug("ingestHandler")
+	setAccessControlAllowForHeader(w)
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		ingestResult(w, -1, fmt.Sprintf("only allow post method, http status code %d", http.StatusMethodNotAllowed), "")
+		return
+	}
+
+	payload, err := hs.verifyIngestToken(r)
+	if err != nil {
+		log.Errorf("verfiy ingest token error: %s", err.Error())
+		ingestResult(w, -1, fmt.Sprintf("%s, http status code %d", err.Error(), http.StatusUnauthorized), "")
+		return
+	}
+
+	if payload.AssetSize > int64(hs.maxSizeOfUploadFile) {
+		log.Errorf("max file size is: %d, current file size:%d", hs.maxSizeOfUploadFile, payload.AssetSize)
+		ingestResult(w, -1, fmt.Sprintf("asset Size %d, out of max size %d, http status code %d", payload.AssetSize, hs.maxSizeOfUploadFile, http.StatusBadRequest), "")
+		return
+	}
+
+	// limit max concurrent
+	semaphore <- struct{}{}
+	defer func() { <-semaphore }()
+
+	// limit size
+	r.Body = http.MaxBytesReader(w, r.Body, int64(hs.maxSizeOfUploadFile))
+
+	assetCID, statusCode, err := hs.ingestAsset(r, payload)
+	if err != nil {
+		log.Errorf("ingest asset error: %s", err.Error())
+		ingestResult(w, -1, fmt.Sprintf("%s, http status code %d", err.Error(), statusCode), "")
+		return
+	}
+
+	if err = ingestResult(w, 0, "Ingest succeeded", assetCID); err != nil {
+		log.Errorf("ingestResult %s", err.Error())
+	}
+}
+
+func (hs *HttpServer) ingestAsset(r *http.Request, payload *types.AuthUserIngestAsset) (string, int, error) {
+	var reader io.Reader
+	var isCar bool
+
+	contentType := getContentType(r)
+	switch contentType {
+	case "multipart/form-data":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return "", http.StatusBadRequest, err
+		}
+		defer file.Close()
+
+		reader = file
+	case "application/car":
+		reader = r.Body
+		isCar = true
+	default:
+		return "", http.StatusBadRequest, fmt.Errorf("unsupported Content-type %s", contentType)
+	}
+
+	root, err := hs.asset.IngestUserAsset(context.Background(), payload, reader, isCar)
+	if err != nil {
+		return "", http.StatusInternalServerError, fmt.Errorf("ingest user asset error %s", err.Error())
+	}
+
+	return root.String(), http.StatusOK, nil
+}
+
+func (hs *HttpServer) verifyIngestToken(r *http.Request) (*types.AuthUserIngestAsset, error) {
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+		if token != "" {
+			token = "Bearer " + token
+		}
+	}
+
+	if !strings.HasPrefix(token, "Bearer ") {
+		return nil, fmt.Errorf("missing Bearer prefix in auth header")
+	}
+	token = strings.TrimPrefix(token, "Bearer ")
+
+	payload := &types.AuthUserIngestAsset{}
+	if _, err := jwt.Verify([]byte(token), hs.apiSecret, payload); err != nil {
+		return nil, err
+	}
+
+	// the upload token of user is signed with the same secret, but has no ingest id
+	if len(payload.IngestID) == 0 {
+		return nil, fmt.Errorf("token is not an ingest token")
+	}
+
+	if time.Now().After(payload.Expiration) {
+		return nil, fmt.Errorf("token is expire, userID %s, ingestID %s", payload.UserID, payload.IngestID)
+	}
+
+	return payload, nil
+}
+
+func ingestResult(w http.ResponseWriter, code int, msg, assetCID string) error {
+	type Result struct {
+		Code int    `json:"code"`
+		Err  int    `json:"err"`
+		Msg  string `json:"msg"`
+		CID  string `json:"cid,omitempty"`
+	}
+
+	ret := Result{Code: code, Err: 0, Msg: msg, CID: assetCID}
+	buf, err := json.Marshal(ret)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(buf)
+	return err
+}
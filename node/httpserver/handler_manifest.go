@@ -0,0 +1,34 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ipfs/go-cid"
+)
+
+// serveManifest serves the list of the blocks of the asset
+func (hs *HttpServer) serveManifest(w http.ResponseWriter, r *http.Request, assetCID string) (int, error) {
+	root, err := cid.Decode(assetCID)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("decode root cid %s error: %s", assetCID, err.Error())
+	}
+
+	manifest, err := hs.asset.GetAssetManifest(r.Context(), root)
+	if err != nil {
+		return http.StatusNotFound, fmt.Errorf("can not get manifest of %s, %s", assetCID, err.Error())
+	}
+
+	buf, err := json.Marshal(manifest)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("marshal manifest error: %s", err.Error())
+	}
+
+	w.Header().Set("Content-Type", formatManifest)
+	if _, err := w.Write(buf); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("write manifest error: %s", err.Error())
+	}
+
+	return 0, nil
+}
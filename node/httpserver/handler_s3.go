@@ -0,0 +1,423 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/sigv4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/files"
+	dag "github.com/ipfs/go-merkledag"
+	unixfile "github.com/ipfs/go-unixfs/file"
+)
+
+const (
+	// maximum number of keys returned by ListObjects
+	s3MaxKeys = 1000
+	// time format of the s3 xml responses
+	s3TimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// s3Error the error response of the s3 api
+type s3Error struct {
+	XMLName    xml.Name `xml:"Error"`
+	Code       string   `xml:"Code"`
+	Message    string   `xml:"Message"`
+	Resource   string   `xml:"Resource"`
+	statusCode int
+}
+
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func newS3Error(statusCode int, code, message string) *s3Error {
+	return &s3Error{Code: code, Message: message, statusCode: statusCode}
+}
+
+type s3Owner struct {
+	ID string `xml:"ID"`
+}
+
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListAllMyBucketsResult"`
+	Owner   s3Owner    `xml:"Owner"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+type s3Content struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name         `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Marker                string           `xml:"Marker,omitempty"`
+	NextMarker            string           `xml:"NextMarker,omitempty"`
+	KeyCount              int              `xml:"KeyCount,omitempty"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	Contents              []s3Content      `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+// isS3Request checks whether the request is sent to the s3 gateway
+func (hs *HttpServer) isS3Request(r *http.Request) bool {
+	return hs.s3Gateway && (r.URL.Path == s3PathPrefix || strings.HasPrefix(r.URL.Path, s3PathPrefix+"/"))
+}
+
+// s3Handler serves the path style s3 api, /s3/ lists the buckets, /s3/{bucket} lists the objects
+// and /s3/{bucket}/{key} puts or gets the object
+func (hs *HttpServer) s3Handler(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("s3Handler %s %s", r.Method, r.URL.Path)
+
+	grant, err := hs.s3Authorize(r)
+	if err != nil {
+		writeS3Error(w, r, err)
+		return
+	}
+	userID := grant.UserID
+
+	bucket, key := parseS3Path(r.URL.Path)
+
+	switch {
+	case bucket == "" && r.Method == http.MethodGet:
+		err = hs.s3ListBuckets(w, userID)
+	case key == "" && r.Method == http.MethodGet:
+		err = hs.s3ListObjects(w, r, userID, bucket)
+	case key == "" && (r.Method == http.MethodPut || r.Method == http.MethodHead):
+		// the buckets are created with their first object
+		w.WriteHeader(http.StatusOK)
+	case key != "" && r.Method == http.MethodPut:
+		err = hs.s3PutObject(w, r, grant, bucket, key)
+	case key != "" && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+		err = hs.s3GetObject(w, r, userID, bucket, key)
+	default:
+		err = newS3Error(http.StatusNotImplemented, "NotImplemented", fmt.Sprintf("%s %s is not implemented", r.Method, r.URL.Path))
+	}
+
+	if err != nil {
+		log.Debugf("s3 %s %s error %s", r.Method, r.URL.Path, err.Error())
+		writeS3Error(w, r, err)
+	}
+}
+
+// s3Authorize verifies the signature of the request by the scheduler, returns the grant of the user of the access key
+func (hs *HttpServer) s3Authorize(r *http.Request) (*types.S3Grant, error) {
+	auth, err := sigv4.ParseAuthorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", err.Error())
+	}
+
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return nil, newS3Error(http.StatusNotImplemented, "NotImplemented", "streaming payload signature is not supported")
+	}
+
+	stringToSign, err := sigv4.StringToSign(r, auth)
+	if err != nil {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", err.Error())
+	}
+
+	grant, err := hs.scheduler.S3Authorize(r.Context(), &types.S3AuthReq{AccessKey: auth.AccessKey, StringToSign: stringToSign, Signature: auth.Signature})
+	if err != nil {
+		return nil, newS3Error(http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+	}
+
+	return grant, nil
+}
+
+func (hs *HttpServer) s3ListBuckets(w http.ResponseWriter, userID string) error {
+	buckets, err := hs.scheduler.S3ListBuckets(context.Background(), userID)
+	if err != nil {
+		return err
+	}
+
+	result := listAllMyBucketsResult{Owner: s3Owner{ID: userID}}
+	for _, bucket := range buckets {
+		result.Buckets = append(result.Buckets, s3Bucket{Name: bucket, CreationDate: time.Unix(0, 0).UTC().Format(s3TimeFormat)})
+	}
+
+	return writeS3XML(w, result)
+}
+
+// s3ListObjects lists the objects of the bucket with ListObjects or ListObjectsV2,
+// the keys containing the delimiter after the prefix are rolled up into common prefixes
+func (hs *HttpServer) s3ListObjects(w http.ResponseWriter, r *http.Request, userID, bucket string) error {
+	query := r.URL.Query()
+	isV2 := query.Get("list-type") == "2"
+
+	maxKeys := s3MaxKeys
+	if v, err := strconv.Atoi(query.Get("max-keys")); err == nil && v >= 0 && v < s3MaxKeys {
+		maxKeys = v
+	}
+
+	result := listBucketResult{Name: bucket, Prefix: query.Get("prefix"), Delimiter: query.Get("delimiter"), MaxKeys: maxKeys}
+
+	startAfter := query.Get("marker")
+	if isV2 {
+		result.StartAfter = query.Get("start-after")
+		result.ContinuationToken = query.Get("continuation-token")
+		startAfter = result.StartAfter
+		if result.ContinuationToken != "" {
+			startAfter = result.ContinuationToken
+		}
+	} else {
+		result.Marker = startAfter
+	}
+
+	req := &types.ListS3ObjectsReq{UserID: userID, Bucket: bucket, Prefix: result.Prefix, StartAfter: startAfter, Limit: maxKeys}
+	rsp, err := hs.scheduler.S3ListObjects(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	prefixes := make(map[string]struct{})
+	for _, object := range rsp.Objects {
+		if result.Delimiter != "" {
+			rest := strings.TrimPrefix(object.Key, result.Prefix)
+			if i := strings.Index(rest, result.Delimiter); i >= 0 {
+				prefix := result.Prefix + rest[:i+len(result.Delimiter)]
+				if _, ok := prefixes[prefix]; !ok {
+					prefixes[prefix] = struct{}{}
+					result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: prefix})
+				}
+				continue
+			}
+		}
+
+		result.Contents = append(result.Contents, s3Content{
+			Key:          object.Key,
+			LastModified: object.CreatedTime.UTC().Format(s3TimeFormat),
+			ETag:         strconv.Quote(object.ETag),
+			Size:         object.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	result.IsTruncated = rsp.IsTruncated
+	if rsp.IsTruncated && len(rsp.Objects) > 0 {
+		lastKey := rsp.Objects[len(rsp.Objects)-1].Key
+		if isV2 {
+			result.NextContinuationToken = lastKey
+		} else {
+			result.NextMarker = lastKey
+		}
+	}
+
+	if isV2 {
+		result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	}
+
+	return writeS3XML(w, result)
+}
+
+func (hs *HttpServer) s3PutObject(w http.ResponseWriter, r *http.Request, grant *types.S3Grant, bucket, key string) error {
+	if r.ContentLength > int64(hs.maxSizeOfUploadFile) {
+		return newS3Error(http.StatusBadRequest, "EntityTooLarge", fmt.Sprintf("object size %d, out of max size %d", r.ContentLength, hs.maxSizeOfUploadFile))
+	}
+
+	// limit max concurrent
+	semaphore <- struct{}{}
+	defer func() { <-semaphore }()
+
+	body := sigv4.NewPayloadReader(http.MaxBytesReader(w, r.Body, int64(hs.maxSizeOfUploadFile)), r.Header.Get("X-Amz-Content-Sha256"))
+
+	object := &types.S3Object{UserID: grant.UserID, Bucket: bucket, Key: key, ContentType: r.Header.Get("Content-Type")}
+	object, err := hs.asset.PutS3Object(context.Background(), grant, object, body)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", strconv.Quote(object.ETag))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// s3GetObject serves the content of the object, the content is proxied from a candidate holding the asset
+// if the asset is not stored locally
+func (hs *HttpServer) s3GetObject(w http.ResponseWriter, r *http.Request, userID, bucket, key string) error {
+	object, err := hs.scheduler.S3GetObject(context.Background(), userID, bucket, key)
+	if err != nil {
+		var webErr *api.ErrWeb
+		if errors.As(err, &webErr) && webErr.Code == terrors.NotFound.Int() {
+			return newS3Error(http.StatusNotFound, "NoSuchKey", webErr.Message)
+		}
+		return err
+	}
+
+	root, err := cid.Decode(object.AssetCID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", strconv.Quote(object.ETag))
+	w.Header().Set("Last-Modified", object.CreatedTime.UTC().Format(http.TimeFormat))
+	if object.ContentType != "" {
+		w.Header().Set("Content-Type", object.ContentType)
+	}
+
+	if ok, err := hs.asset.AssetExists(root); err != nil {
+		return err
+	} else if !ok {
+		return hs.proxyS3Object(w, r, root)
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	node, err := (&nodeGetter{hs, root}).Get(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	file, err := unixfile.NewUnixfsFile(ctx, dag.NewReadOnlyDagService(&nodeGetter{hs, root}), node)
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck // ignore error
+
+	f, ok := file.(files.File)
+	if !ok {
+		return newS3Error(http.StatusInternalServerError, "InternalError", "the asset of the object is not a file")
+	}
+
+	http.ServeContent(w, r, key, object.CreatedTime, &lazySeeker{size: object.Size, reader: f})
+	return nil
+}
+
+// proxyS3Object copies the content of the asset from a candidate holding it
+func (hs *HttpServer) proxyS3Object(w http.ResponseWriter, r *http.Request, root cid.Cid) error {
+	infos, err := hs.scheduler.GetCandidateDownloadInfos(context.Background(), root.String())
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if info.Tk == nil || len(info.Address) == 0 {
+			continue
+		}
+
+		resp, err := hs.requestCandidateAsset(r, info, root)
+		if err != nil {
+			log.Warnf("request asset %s from %s error %s", root.String(), info.NodeID, err.Error())
+			continue
+		}
+		defer resp.Body.Close() //nolint:errcheck // ignore error
+
+		for _, header := range []string{"Content-Length", "Content-Range", "Accept-Ranges"} {
+			if v := resp.Header.Get(header); v != "" {
+				w.Header().Set(header, v)
+			}
+		}
+
+		w.WriteHeader(resp.StatusCode)
+		if r.Method != http.MethodHead {
+			_, err = io.Copy(w, resp.Body)
+		}
+		return err
+	}
+
+	return newS3Error(http.StatusServiceUnavailable, "ServiceUnavailable", fmt.Sprintf("no candidate can serve asset %s", root.String()))
+}
+
+func (hs *HttpServer) requestCandidateAsset(r *http.Request, info *types.CandidateDownloadInfo, root cid.Cid) (*http.Response, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(info.Tk); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, fmt.Sprintf("https://%s/ipfs/%s", info.Address, root.String()), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := r.Header.Get("Range"); v != "" {
+		req.Header.Set("Range", v)
+	}
+
+	resp, err := hs.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close() //nolint:errcheck // ignore error
+		return nil, fmt.Errorf("http status code: %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+// parseS3Path returns the bucket and the key of the path style request
+func parseS3Path(urlPath string) (string, string) {
+	p := strings.TrimPrefix(strings.TrimPrefix(urlPath, s3PathPrefix), "/")
+	parts := strings.SplitN(p, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+func writeS3XML(w http.ResponseWriter, v interface{}) error {
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(append([]byte(xml.Header), buf...))
+	return err
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, err error) {
+	var s3Err *s3Error
+	if !errors.As(err, &s3Err) {
+		s3Err = newS3Error(http.StatusInternalServerError, "InternalError", err.Error())
+	}
+	s3Err.Resource = r.URL.Path
+
+	buf, e := xml.Marshal(s3Err)
+	if e != nil {
+		log.Errorf("marshal s3 error %s", e.Error())
+		w.WriteHeader(s3Err.statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(s3Err.statusCode)
+	if r.Method != http.MethodHead {
+		w.Write(append([]byte(xml.Header), buf...)) //nolint:errcheck // ignore error
+	}
+}
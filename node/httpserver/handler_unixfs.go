@@ -35,6 +35,9 @@ func (hs *HttpServer) serveUnixFS(w http.ResponseWriter, r *http.Request, assetC
 	// Handling Unixfs file
 	if f, ok := node.(files.File); ok {
 		log.Debugw("serving unixfs file", "path", contentPath)
+		if hs.video != nil {
+			go hs.video.fileServed(root, assetSubPath(r.URL.Path))
+		}
 		statusCode, err := hs.serveFile(w, r, assetCID, f)
 		return false, statusCode, err
 	}
@@ -87,12 +87,8 @@ func (hs *HttpServer) serveFile(w http.ResponseWriter, r *http.Request, assetCID
 	// Setting explicit Content-Type to avoid mime-type sniffing on the client
 	// (unifies behavior across gateways and web browsers)
 	w.Header().Set("Content-Type", ctype)
+	// ServeContent serves the range requests, the If-Range with the etag of the cid
+	// resumes the download started from any node holding the file
 	http.ServeContent(w, r, name, modtime, content)
-
-	if countWrite, ok := w.(*SpeedCountWriter); ok {
-		if countWrite.dataSize == 0 {
-			countWrite.dataSize = size
-		}
-	}
 	return 0, nil
 }
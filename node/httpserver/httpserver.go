@@ -2,12 +2,17 @@ package httpserver
 
 import (
 	"context"
+	"crypto"
 	"crypto/rsa"
 	"fmt"
+	"net/http"
 	gopath "path"
 	"sync"
+	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/lib/limiter"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/ipfs/go-blockservice"
@@ -27,36 +32,60 @@ import (
 	"github.com/ipld/go-ipld-prime/schema"
 )
 
+// minimum interval to update the scheduler public key on signature verification failures
+const publicKeyUpdateInterval = time.Minute
+
 type Validation interface {
 	SetFunc(func() string)
 }
 
 type HttpServer struct {
+	nodeID              string
 	asset               Asset
 	scheduler           api.Scheduler
-	privateKey          *rsa.PrivateKey
+	privateKey          crypto.Signer
 	schedulerPublicKey  *rsa.PublicKey
+	publicKeyLk         sync.RWMutex
+	publicKeyUpdateTime time.Time
 	reporter            *reporter
 	validation          Validation
 	tokens              *sync.Map
 	apiSecret           *jwt.HMACSHA
 	maxSizeOfUploadFile int
 	webRedirect         string
+	s3Gateway           bool
+	relay               http.Handler
+	httpClient          *http.Client
+	shaper              *limiter.Shaper
+	accessCache         *sync.Map
+	accessStores        int64
+	video               *videoCache
 }
 
 type HttpServerOptions struct {
+	// NodeID the id of the node, the tokens issued for other nodes are rejected
+	NodeID              string
 	Asset               Asset
 	Scheduler           api.Scheduler
-	PrivateKey          *rsa.PrivateKey
+	PrivateKey          crypto.Signer
 	Validation          Validation
 	APISecret           *jwt.HMACSHA
 	MaxSizeOfUploadFile int
 	WebRedirect         string
+	// S3Gateway serves the s3 api on the candidate
+	S3Gateway bool
+	// Relay relays the retrievals of the edges behind symmetric nat on the candidate
+	Relay http.Handler
+	// Shaper limits the upload rate of the data server, set by the scheduler
+	Shaper *limiter.Shaper
+	// VideoPrefetchSegments the segments prefetched after the retrieved segment of a hls or dash asset
+	VideoPrefetchSegments int
 }
 
 // NewHttpServer creates a new HttpServer with the given Asset, Scheduler, and RSA private key.
 func NewHttpServer(opts *HttpServerOptions) *HttpServer {
 	hs := &HttpServer{
+		nodeID:              opts.NodeID,
 		asset:               opts.Asset,
 		scheduler:           opts.Scheduler,
 		privateKey:          opts.PrivateKey,
@@ -65,8 +94,14 @@ func NewHttpServer(opts *HttpServerOptions) *HttpServer {
 		tokens:              &sync.Map{},
 		maxSizeOfUploadFile: opts.MaxSizeOfUploadFile,
 		webRedirect:         opts.WebRedirect,
+		s3Gateway:           opts.S3Gateway,
+		relay:               opts.Relay,
+		httpClient:          client.NewHTTP3Client(),
+		shaper:              opts.Shaper,
+		accessCache:         &sync.Map{},
 	}
 	hs.reporter = newReporter(hs)
+	hs.video = newVideoCache(hs, opts.VideoPrefetchSegments)
 
 	if hs.validation != nil {
 		hs.validation.SetFunc(hs.FirstToken)
@@ -79,7 +114,6 @@ func NewHttpServer(opts *HttpServerOptions) *HttpServer {
 	return hs
 }
 
-// TODO: need to update public key on reconnect
 // updateSchedulerPublicKey update the public key of the scheduler.
 func (hs *HttpServer) updateSchedulerPublicKey() error {
 	pem, err := hs.scheduler.GetSchedulerPublicKey(context.Background())
@@ -92,10 +126,46 @@ func (hs *HttpServer) updateSchedulerPublicKey() error {
 		return err
 	}
 
+	hs.publicKeyLk.Lock()
 	hs.schedulerPublicKey = publicKey
+	hs.publicKeyUpdateTime = time.Now()
+	hs.publicKeyLk.Unlock()
+
 	return nil
 }
 
+// getSchedulerPublicKey returns the cached public key of the scheduler
+func (hs *HttpServer) getSchedulerPublicKey() *rsa.PublicKey {
+	hs.publicKeyLk.RLock()
+	defer hs.publicKeyLk.RUnlock()
+
+	return hs.schedulerPublicKey
+}
+
+// verifySchedulerSign verifies the signature of the scheduler, the public key is updated
+// and the signature is verified again on failure because the scheduler key may be rotated
+func (hs *HttpServer) verifySchedulerSign(titanRsa *titanrsa.Rsa, sign, content []byte) error {
+	err := titanRsa.VerifySign(hs.getSchedulerPublicKey(), sign, content)
+	if err == nil {
+		return nil
+	}
+
+	hs.publicKeyLk.RLock()
+	updated := time.Since(hs.publicKeyUpdateTime) < publicKeyUpdateInterval
+	hs.publicKeyLk.RUnlock()
+
+	if updated {
+		return err
+	}
+
+	if e := hs.updateSchedulerPublicKey(); e != nil {
+		log.Errorf("updateSchedulerPublicKey error %s", e.Error())
+		return err
+	}
+
+	return titanRsa.VerifySign(hs.getSchedulerPublicKey(), sign, content)
+}
+
 // GetDownloadThreadCount get download thread count of httpserver
 func (hs *HttpServer) FirstToken() string {
 	token := ""
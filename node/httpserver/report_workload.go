@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 )
 
@@ -50,13 +51,27 @@ func (r *reporter) startTicker() {
 		}
 
 		if r.bandwidthUp > 0 {
-			r.server.scheduler.UpdateBandwidths(context.Background(), 0, r.bandwidthUp)
+			if err := r.updateBandwidthUp(r.bandwidthUp); err != nil {
+				log.Errorf("updateBandwidthUp error:%s", err.Error())
+			}
 			r.bandwidthUp = 0
 		}
 	}
 
 }
 
+// updateBandwidthUp reports the upload bandwidth of the node to the scheduler, signed by the node
+func (r *reporter) updateBandwidthUp(bandwidthUp int64) error {
+	report := &types.BandwidthReport{BandwidthUp: bandwidthUp, ReportTime: time.Now()}
+	sign, err := nodekey.Sign(r.server.privateKey, report.SignData())
+	if err != nil {
+		return err
+	}
+	report.Sign = sign
+
+	return r.server.scheduler.UpdateBandwidths(context.Background(), report)
+}
+
 func (r *reporter) addReport(report *report) {
 	if report == nil {
 		return
@@ -150,12 +165,12 @@ func (r *reporter) doSendReports(rps []*types.WorkloadReport) error {
 	}
 
 	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	cipherText, err := titanRsa.Encrypt(buffer.Bytes(), r.server.schedulerPublicKey)
+	cipherText, err := titanRsa.Encrypt(buffer.Bytes(), r.server.getSchedulerPublicKey())
 	if err != nil {
 		return err
 	}
 
-	sign, err := titanRsa.Sign(r.server.privateKey, cipherText)
+	sign, err := nodekey.Sign(r.server.privateKey, cipherText)
 	if err != nil {
 		return err
 	}
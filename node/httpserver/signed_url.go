@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+)
+
+// verifySignedURL checks the signature and the expiration of the signed url with the public key of the scheduler,
+// the url is verified on the node, the scheduler is only asked for its public key if the cached one fails
+func (hs *HttpServer) verifySignedURL(r *http.Request) (*types.TokenPayload, error) {
+	query := r.URL.Query()
+
+	expires, err := strconv.ParseInt(query.Get(types.SignedURLExpiresParam), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed url expiration %s", query.Get(types.SignedURLExpiresParam))
+	}
+
+	expiration := time.Unix(expires, 0)
+	if time.Now().After(expiration) {
+		return nil, fmt.Errorf("signed url expired at %s", expiration.String())
+	}
+
+	sign, err := hex.DecodeString(query.Get(types.SignedURLSignatureParam))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed url signature %w", err)
+	}
+
+	rootCID, err := getCIDFromURLPath(r.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	if err := hs.verifySchedulerSign(rsa, sign, types.SignedURLContent(rootCID.String(), expires)); err != nil {
+		return nil, fmt.Errorf("verify signed url %w", err)
+	}
+
+	return &types.TokenPayload{AssetCID: rootCID.String(), Expiration: expiration}, nil
+}
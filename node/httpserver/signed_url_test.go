@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+)
+
+func TestVerifySignedURL(t *testing.T) {
+	key, err := titanrsa.GeneratePrivateKey(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cached key is fresh, so the scheduler is not asked for it
+	hs := &HttpServer{schedulerPublicKey: &key.PublicKey, publicKeyUpdateTime: time.Now()}
+
+	assetCID := "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	otherCID := "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+
+	signedPath := func(expires int64) string {
+		rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+		sign, err := rsa.Sign(key, types.SignedURLContent(assetCID, expires))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("?%s=%d&%s=%s", types.SignedURLExpiresParam, expires, types.SignedURLSignatureParam, hex.EncodeToString(sign))
+	}
+
+	valid := signedPath(time.Now().Add(time.Hour).Unix())
+	payload, err := hs.verifySignedURL(httptest.NewRequest("GET", "/ipfs/"+assetCID+"/file"+valid, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.AssetCID != assetCID {
+		t.Fatalf("expected asset %s, got %s", assetCID, payload.AssetCID)
+	}
+
+	if _, err := hs.verifySignedURL(httptest.NewRequest("GET", "/ipfs/"+otherCID+valid, nil)); err == nil {
+		t.Fatal("expected the url of another asset to be refused")
+	}
+
+	expired := signedPath(time.Now().Add(-time.Minute).Unix())
+	if _, err := hs.verifySignedURL(httptest.NewRequest("GET", "/ipfs/"+assetCID+expired, nil)); err == nil {
+		t.Fatal("expected the expired url to be refused")
+	}
+}
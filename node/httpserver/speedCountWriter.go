@@ -1,16 +1,23 @@
 package httpserver
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/limiter"
 )
 
 type SpeedCountWriter struct {
 	w         http.ResponseWriter
 	dataSize  int64
 	startTime time.Time
+	// the protocol of the request, reported to the scheduler for diagnostics
+	protocol types.TransferProtocol
+	// shaper limits the upload rate of the node, nil if not limited
+	shaper *limiter.Shaper
+	ctx    context.Context
 }
 
 func (w *SpeedCountWriter) Header() http.Header {
@@ -22,6 +29,12 @@ func (w *SpeedCountWriter) Write(bytes []byte) (int, error) {
 		w.startTime = time.Now()
 	}
 
+	if w.shaper != nil {
+		if err := w.shaper.WaitN(w.ctx, len(bytes)); err != nil {
+			return 0, err
+		}
+	}
+
 	w.dataSize += int64(len(bytes))
 	return w.w.Write(bytes)
 }
@@ -59,6 +72,7 @@ func (w *SpeedCountWriter) generateReport(payload *types.TokenPayload) *report {
 		DownloadSize:  int64(w.dataSize),
 		StartTime:     w.startTime,
 		EndTime:       time.Now(),
+		Protocol:      w.protocol,
 	}
 
 	return &report{
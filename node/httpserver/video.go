@@ -0,0 +1,437 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	gopath "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/files"
+	ipfspath "github.com/ipfs/go-path"
+	"github.com/ipfs/interface-go-ipfs-core/path"
+)
+
+const (
+	// how long the node caches the video format of an asset checked by the scheduler
+	videoFormatTTL = 5 * time.Minute
+	// the assets whose segment order is kept, the least recently retrieved asset is dropped first
+	maxVideoIndexes = 256
+	// the playlists larger than the size are not parsed
+	maxPlaylistSize = 4 << 20
+	// a segment is not prefetched again within the interval
+	prefetchTTL     = time.Minute
+	prefetchTimeout = 30 * time.Second
+	// the segments prefetched at once
+	maxPrefetching = 8
+	// the interval the segment hits are reported to the scheduler
+	segmentHitsInterval = time.Minute
+	// the segments counted between two reports, the hits of the other segments are dropped
+	maxSegmentHits = 10000
+	// the segments reported at once
+	segmentHitsBatch = 1000
+)
+
+type videoFormat struct {
+	format     types.AssetVideoFormat
+	expiration time.Time
+}
+
+type segmentRef struct {
+	// the segments of the playlist in their play order
+	list []string
+	pos  int
+}
+
+// videoIndex the play order of the segments of a video asset, taken from its playlists
+type videoIndex struct {
+	segments map[string]*segmentRef
+	// the directories whose playlists are parsed
+	scanned  map[string]struct{}
+	usedTime time.Time
+}
+
+func newVideoIndex() *videoIndex {
+	return &videoIndex{segments: make(map[string]*segmentRef), scanned: make(map[string]struct{}), usedTime: time.Now()}
+}
+
+// add adds the segments of a playlist
+func (idx *videoIndex) add(list []string) {
+	for i, p := range list {
+		idx.segments[p] = &segmentRef{list: list, pos: i}
+	}
+}
+
+// next returns the n segments played after the segment, nil if the segment is in no playlist
+func (idx *videoIndex) next(p string, n int) []string {
+	ref, ok := idx.segments[p]
+	if !ok {
+		return nil
+	}
+
+	end := ref.pos + 1 + n
+	if end > len(ref.list) {
+		end = len(ref.list)
+	}
+	return ref.list[ref.pos+1 : end]
+}
+
+type segmentKey struct {
+	assetCID string
+	segment  string
+}
+
+// videoCache understands the playlists of the assets the scheduler marks as hls or dash, prefetches the segments
+// following the retrieved segments and counts the retrievals of the segments for the scheduler
+type videoCache struct {
+	hs *HttpServer
+	// the segments prefetched after a retrieved segment, disabled if 0
+	prefetchSegments int
+
+	lk         sync.Mutex
+	formats    map[string]*videoFormat
+	indexes    map[string]*videoIndex
+	prefetched map[segmentKey]time.Time
+	hits       map[segmentKey]int64
+
+	prefetching chan struct{}
+}
+
+func newVideoCache(hs *HttpServer, prefetchSegments int) *videoCache {
+	v := &videoCache{
+		hs:               hs,
+		prefetchSegments: prefetchSegments,
+		formats:          make(map[string]*videoFormat),
+		indexes:          make(map[string]*videoIndex),
+		prefetched:       make(map[segmentKey]time.Time),
+		hits:             make(map[segmentKey]int64),
+		prefetching:      make(chan struct{}, maxPrefetching),
+	}
+
+	go v.startReportTimer()
+
+	return v
+}
+
+// assetSubPath returns the path of the file in the asset from the url path, e.g. 720p/seg_005.ts of /ipfs/<cid>/720p/seg_005.ts
+func assetSubPath(urlPath string) string {
+	segments := ipfspath.Path(urlPath).Segments()
+	if len(segments) <= 2 {
+		return ""
+	}
+
+	return strings.TrimPrefix(gopath.Clean("/"+strings.Join(segments[2:], "/")), "/")
+}
+
+// fileServed is called when a file of the asset is retrieved, the playlists of the video assets are parsed,
+// the segments are counted and the segments following them are prefetched
+func (v *videoCache) fileServed(root cid.Cid, p string) {
+	if p == "" {
+		return
+	}
+
+	format, err := v.format(root.String())
+	if err != nil {
+		log.Debugf("get video format of %s err:%s", root.String(), err.Error())
+		return
+	}
+
+	if format == types.AssetVideoFormatNone {
+		return
+	}
+
+	if isPlaylist(p) {
+		v.indexPlaylist(root, p)
+		return
+	}
+
+	v.addHit(root.String(), p)
+
+	if v.prefetchSegments <= 0 {
+		return
+	}
+
+	next := v.nextSegments(root, p)
+	for _, segment := range next {
+		v.prefetch(root, segment)
+	}
+}
+
+// format returns the video format of the asset, checked by the scheduler
+func (v *videoCache) format(assetCID string) (types.AssetVideoFormat, error) {
+	v.lk.Lock()
+	f, ok := v.formats[assetCID]
+	v.lk.Unlock()
+
+	if ok && f.expiration.After(time.Now()) {
+		return f.format, nil
+	}
+
+	format, err := v.hs.scheduler.GetAssetVideoFormat(context.Background(), assetCID)
+	if err != nil {
+		return types.AssetVideoFormatNone, err
+	}
+
+	now := time.Now()
+	v.lk.Lock()
+	v.formats[assetCID] = &videoFormat{format: format, expiration: now.Add(videoFormatTTL)}
+	for key, f := range v.formats {
+		if f.expiration.Before(now) {
+			delete(v.formats, key)
+		}
+	}
+	v.lk.Unlock()
+
+	return format, nil
+}
+
+// index returns the segment order of the asset, created if the asset has none
+func (v *videoCache) index(assetCID string) *videoIndex {
+	idx, ok := v.indexes[assetCID]
+	if ok {
+		idx.usedTime = time.Now()
+		return idx
+	}
+
+	if len(v.indexes) >= maxVideoIndexes {
+		var oldest string
+		for key, i := range v.indexes {
+			if oldest == "" || i.usedTime.Before(v.indexes[oldest].usedTime) {
+				oldest = key
+			}
+		}
+		delete(v.indexes, oldest)
+	}
+
+	idx = newVideoIndex()
+	v.indexes[assetCID] = idx
+	return idx
+}
+
+// indexPlaylist parses the playlist of the asset and adds its segments to the segment order of the asset
+func (v *videoCache) indexPlaylist(root cid.Cid, p string) {
+	ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+	defer cancel()
+
+	data, err := v.readFile(ctx, root, p, maxPlaylistSize)
+	if err != nil {
+		log.Debugf("read playlist %s of %s err:%s", p, root.String(), err.Error())
+		return
+	}
+
+	dir := gopath.Dir(p)
+	var lists [][]string
+	if strings.EqualFold(gopath.Ext(p), ".mpd") {
+		if lists, err = parseDASHManifest(dir, data); err != nil {
+			log.Debugf("parse manifest %s of %s err:%s", p, root.String(), err.Error())
+			return
+		}
+	} else {
+		lists = [][]string{parseHLSPlaylist(dir, data)}
+	}
+
+	v.lk.Lock()
+	defer v.lk.Unlock()
+
+	idx := v.index(root.String())
+	for _, list := range lists {
+		idx.add(list)
+	}
+}
+
+// nextSegments returns the segments played after the segment, the playlists next to the segment are parsed
+// if the segment is in none of the parsed playlists, e.g. the playlist was retrieved from another node
+func (v *videoCache) nextSegments(root cid.Cid, p string) []string {
+	dir := gopath.Dir(p)
+
+	v.lk.Lock()
+	idx := v.index(root.String())
+	next := idx.next(p, v.prefetchSegments)
+	_, scanned := idx.scanned[dir]
+	if next == nil && !scanned {
+		idx.scanned[dir] = struct{}{}
+	}
+	v.lk.Unlock()
+
+	if next != nil || scanned {
+		return next
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+	defer cancel()
+
+	names, err := v.listDir(ctx, root, dir)
+	if err != nil {
+		log.Debugf("list %s of %s err:%s", dir, root.String(), err.Error())
+		return nil
+	}
+
+	for _, name := range names {
+		if isPlaylist(name) {
+			v.indexPlaylist(root, gopath.Join(dir, name))
+		}
+	}
+
+	v.lk.Lock()
+	defer v.lk.Unlock()
+
+	return v.index(root.String()).next(p, v.prefetchSegments)
+}
+
+// prefetch reads the segment in the background, the blocks read are kept warm by the page cache for the next retrieval,
+// the segment is skipped if it was prefetched recently or too many segments are being prefetched
+func (v *videoCache) prefetch(root cid.Cid, p string) {
+	key := segmentKey{assetCID: root.String(), segment: p}
+	now := time.Now()
+
+	v.lk.Lock()
+	if t, ok := v.prefetched[key]; ok && now.Sub(t) < prefetchTTL {
+		v.lk.Unlock()
+		return
+	}
+	v.prefetched[key] = now
+	for k, t := range v.prefetched {
+		if now.Sub(t) >= prefetchTTL {
+			delete(v.prefetched, k)
+		}
+	}
+	v.lk.Unlock()
+
+	select {
+	case v.prefetching <- struct{}{}:
+	default:
+		return
+	}
+
+	go func() {
+		defer func() {
+			<-v.prefetching
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		defer cancel()
+
+		if _, err := v.readFile(ctx, root, p, -1); err != nil {
+			log.Debugf("prefetch segment %s of %s err:%s", p, root.String(), err.Error())
+		}
+	}()
+}
+
+// readFile reads the file of the asset, the content is returned if the file is not larger than the limit,
+// the content is discarded if the limit is negative
+func (v *videoCache) readFile(ctx context.Context, root cid.Cid, p string, limit int64) ([]byte, error) {
+	resolvedPath, err := v.hs.resolvePath(ctx, path.New(fmt.Sprintf("/ipfs/%s/%s", root.String(), p)), root)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := v.hs.getUnixFsNode(ctx, resolvedPath, root)
+	if err != nil {
+		return nil, err
+	}
+	defer node.Close() //nolint:errcheck // ignore error
+
+	f, ok := node.(files.File)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a file", p)
+	}
+
+	if limit < 0 {
+		_, err = io.Copy(io.Discard, f)
+		return nil, err
+	}
+
+	size, err := f.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	if size > limit {
+		return nil, fmt.Errorf("size %d exceeds the limit %d", size, limit)
+	}
+
+	return io.ReadAll(f)
+}
+
+// listDir returns the names of the entries of the directory of the asset
+func (v *videoCache) listDir(ctx context.Context, root cid.Cid, dir string) ([]string, error) {
+	p := fmt.Sprintf("/ipfs/%s", root.String())
+	if dir != "." && dir != "" {
+		p = fmt.Sprintf("%s/%s", p, dir)
+	}
+
+	resolvedPath, err := v.hs.resolvePath(ctx, path.New(p), root)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := v.hs.lsUnixFsDir(ctx, resolvedPath, root)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := d.Links(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(links))
+	for _, l := range links {
+		names = append(names, l.Name)
+	}
+	return names, nil
+}
+
+func (v *videoCache) addHit(assetCID, segment string) {
+	key := segmentKey{assetCID: assetCID, segment: segment}
+
+	v.lk.Lock()
+	defer v.lk.Unlock()
+
+	if _, ok := v.hits[key]; !ok && len(v.hits) >= maxSegmentHits {
+		return
+	}
+	v.hits[key]++
+}
+
+func (v *videoCache) startReportTimer() {
+	ticker := time.NewTicker(segmentHitsInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		v.reportHits()
+	}
+}
+
+// reportHits reports the segment hits counted since the last report to the scheduler
+func (v *videoCache) reportHits() {
+	v.lk.Lock()
+	hits := v.hits
+	v.hits = make(map[segmentKey]int64)
+	v.lk.Unlock()
+
+	batch := make([]*types.SegmentHits, 0, segmentHitsBatch)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := v.hs.scheduler.ReportSegmentHits(context.Background(), batch); err != nil {
+			log.Errorf("ReportSegmentHits err:%s", err.Error())
+		}
+		batch = make([]*types.SegmentHits, 0, segmentHitsBatch)
+	}
+
+	for key, count := range hits {
+		batch = append(batch, &types.SegmentHits{AssetCID: key.assetCID, Segment: key.segment, Hits: count})
+		if len(batch) >= segmentHitsBatch {
+			send()
+		}
+	}
+	send()
+}
@@ -0,0 +1,282 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	gopath "path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPlaylistSegments the segments taken from a playlist, the longer playlists are cut
+const maxPlaylistSegments = 100000
+
+// isPlaylist returns true if the path is a hls playlist or a dash manifest
+func isPlaylist(p string) bool {
+	switch strings.ToLower(gopath.Ext(p)) {
+	case ".m3u8", ".mpd":
+		return true
+	}
+	return false
+}
+
+// segmentPath returns the path of the segment referenced by the playlist in dir, empty if the reference
+// leaves the asset, e.g. an absolute url
+func segmentPath(dir, uri string) string {
+	if uri == "" || strings.Contains(uri, "://") {
+		return ""
+	}
+
+	if i := strings.IndexAny(uri, "?#"); i >= 0 {
+		uri = uri[:i]
+	}
+
+	p := gopath.Clean(gopath.Join("/", dir, uri))
+	return strings.TrimPrefix(p, "/")
+}
+
+// parseHLSPlaylist returns the media segments of the hls playlist in their play order, the master playlists have no segments,
+// their variant playlists are parsed when they are retrieved
+func parseHLSPlaylist(dir string, data []byte) []string {
+	var segments []string
+	variant := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "#EXT-X-STREAM-INF") {
+				variant = true
+			}
+			continue
+		}
+
+		// the uri of a variant playlist
+		if variant {
+			variant = false
+			continue
+		}
+
+		if p := segmentPath(dir, line); p != "" {
+			segments = append(segments, p)
+		}
+
+		if len(segments) >= maxPlaylistSegments {
+			break
+		}
+	}
+
+	return segments
+}
+
+type mpd struct {
+	MediaPresentationDuration string      `xml:"mediaPresentationDuration,attr"`
+	Periods                   []mpdPeriod `xml:"Period"`
+}
+
+type mpdPeriod struct {
+	Duration       string             `xml:"duration,attr"`
+	AdaptationSets []mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpdAdaptationSet struct {
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+	Representations []mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	ID              string              `xml:"id,attr"`
+	Bandwidth       int64               `xml:"bandwidth,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+}
+
+type mpdSegmentTemplate struct {
+	Media       string              `xml:"media,attr"`
+	StartNumber *int64              `xml:"startNumber,attr"`
+	Duration    int64               `xml:"duration,attr"`
+	Timescale   int64               `xml:"timescale,attr"`
+	Timeline    *mpdSegmentTimeline `xml:"SegmentTimeline"`
+}
+
+type mpdSegmentTimeline struct {
+	S []struct {
+		T *int64 `xml:"t,attr"`
+		D int64  `xml:"d,attr"`
+		R int64  `xml:"r,attr"`
+	} `xml:"S"`
+}
+
+type mpdSegmentList struct {
+	SegmentURLs []struct {
+		Media string `xml:"media,attr"`
+	} `xml:"SegmentURL"`
+}
+
+// parseDASHManifest returns the media segments of each representation of the dash manifest in their play order
+func parseDASHManifest(dir string, data []byte) ([][]string, error) {
+	m := &mpd{}
+	if err := xml.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+
+	var out [][]string
+	for _, period := range m.Periods {
+		duration := period.Duration
+		if duration == "" {
+			duration = m.MediaPresentationDuration
+		}
+		seconds, _ := parseISODuration(duration)
+
+		for _, set := range period.AdaptationSets {
+			for _, r := range set.Representations {
+				repDir := dir
+				if r.BaseURL != "" && !strings.Contains(r.BaseURL, "://") {
+					repDir = gopath.Join(dir, r.BaseURL)
+				}
+
+				list := r.SegmentList
+				if list == nil {
+					list = set.SegmentList
+				}
+
+				tmpl := r.SegmentTemplate
+				if tmpl == nil {
+					tmpl = set.SegmentTemplate
+				}
+
+				var segments []string
+				switch {
+				case list != nil:
+					for _, u := range list.SegmentURLs {
+						if p := segmentPath(repDir, u.Media); p != "" {
+							segments = append(segments, p)
+						}
+					}
+				case tmpl != nil:
+					segments = templateSegments(repDir, tmpl, &r, seconds)
+				}
+
+				if len(segments) > 0 {
+					out = append(out, segments)
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+var templateVar = regexp.MustCompile(`\$(RepresentationID|Number|Time|Bandwidth)(%0(\d+)d)?\$`)
+
+// templateSegments expands the media template of the representation by the segment timeline,
+// or by the segment duration over the period duration without the timeline
+func templateSegments(dir string, tmpl *mpdSegmentTemplate, r *mpdRepresentation, periodSeconds float64) []string {
+	if tmpl.Media == "" {
+		return nil
+	}
+
+	number := int64(1)
+	if tmpl.StartNumber != nil {
+		number = *tmpl.StartNumber
+	}
+
+	type segment struct{ number, time int64 }
+	var segments []segment
+
+	if tmpl.Timeline != nil {
+		var t int64
+		for _, s := range tmpl.Timeline.S {
+			if s.T != nil {
+				t = *s.T
+			}
+			// the negative repeats last to the end of the period, which the manifests of the static assets do not use
+			for i := int64(0); i <= s.R && len(segments) < maxPlaylistSegments; i++ {
+				segments = append(segments, segment{number: number, time: t})
+				number++
+				t += s.D
+			}
+		}
+	} else if tmpl.Duration > 0 && periodSeconds > 0 {
+		timescale := tmpl.Timescale
+		if timescale <= 0 {
+			timescale = 1
+		}
+
+		count := int64(math.Ceil(periodSeconds * float64(timescale) / float64(tmpl.Duration)))
+		if count > maxPlaylistSegments {
+			count = maxPlaylistSegments
+		}
+
+		for i := int64(0); i < count; i++ {
+			segments = append(segments, segment{number: number + i, time: i * tmpl.Duration})
+		}
+	}
+
+	out := make([]string, 0, len(segments))
+	for _, s := range segments {
+		media := templateVar.ReplaceAllStringFunc(tmpl.Media, func(v string) string {
+			match := templateVar.FindStringSubmatch(v)
+
+			var value interface{}
+			switch match[1] {
+			case "RepresentationID":
+				return r.ID
+			case "Number":
+				value = s.number
+			case "Time":
+				value = s.time
+			case "Bandwidth":
+				value = r.Bandwidth
+			}
+
+			if match[3] != "" {
+				return fmt.Sprintf("%0"+match[3]+"d", value)
+			}
+			return fmt.Sprintf("%d", value)
+		})
+
+		if p := segmentPath(dir, media); p != "" {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration parses the durations of the dash manifests, e.g. PT1H2M3.5S, to seconds
+func parseISODuration(s string) (float64, error) {
+	match := isoDuration.FindStringSubmatch(s)
+	if match == nil || s == "P" || s == "PT" {
+		return 0, fmt.Errorf("invalid duration %s", s)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var seconds float64
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, err
+		}
+		seconds += v * unit.Seconds()
+	}
+
+	return seconds, nil
+}
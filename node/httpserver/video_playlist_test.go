@@ -0,0 +1,126 @@
+package httpserver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHLSPlaylist(t *testing.T) {
+	master := []byte(`#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=1280000,RESOLUTION=1280x720
+720p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=640000
+360p/index.m3u8
+`)
+	if segments := parseHLSPlaylist(".", master); len(segments) != 0 {
+		t.Fatalf("expected no segment of the master playlist, got %v", segments)
+	}
+
+	media := []byte(`#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:6.0,
+seg_000.ts
+#EXTINF:6.0,
+seg_001.ts?token=abc
+#EXTINF:6.0,
+../shared/seg_002.ts
+#EXTINF:6.0,
+https://cdn.example.com/seg_003.ts
+#EXT-X-ENDLIST
+`)
+	expected := []string{"720p/seg_000.ts", "720p/seg_001.ts", "shared/seg_002.ts"}
+	if segments := parseHLSPlaylist("720p", media); !reflect.DeepEqual(segments, expected) {
+		t.Fatalf("expected %v, got %v", expected, segments)
+	}
+}
+
+func TestParseDASHManifest(t *testing.T) {
+	manifest := []byte(`<?xml version="1.0"?>
+<MPD mediaPresentationDuration="PT10S">
+  <Period>
+    <AdaptationSet>
+      <SegmentTemplate media="$RepresentationID$/chunk_$Number%03d$.m4s" startNumber="1" duration="4" timescale="1"/>
+      <Representation id="video_720" bandwidth="3000000"/>
+    </AdaptationSet>
+    <AdaptationSet>
+      <Representation id="audio" bandwidth="128000">
+        <SegmentTemplate media="audio/$Time$.m4s">
+          <SegmentTimeline>
+            <S t="0" d="100" r="1"/>
+            <S d="50"/>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet>
+      <Representation id="subtitles">
+        <SegmentList>
+          <SegmentURL media="subs/1.vtt"/>
+          <SegmentURL media="subs/2.vtt"/>
+        </SegmentList>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`)
+
+	lists, err := parseDASHManifest("dash", manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"dash/video_720/chunk_001.m4s", "dash/video_720/chunk_002.m4s", "dash/video_720/chunk_003.m4s"},
+		{"dash/audio/0.m4s", "dash/audio/100.m4s", "dash/audio/200.m4s"},
+		{"dash/subs/1.vtt", "dash/subs/2.vtt"},
+	}
+	if !reflect.DeepEqual(lists, expected) {
+		t.Fatalf("expected %v, got %v", expected, lists)
+	}
+}
+
+func TestParseISODuration(t *testing.T) {
+	cases := map[string]float64{"PT10S": 10, "PT1H2M3.5S": 3723.5, "P1DT1S": 86401}
+	for s, expected := range cases {
+		if v, err := parseISODuration(s); err != nil || v != expected {
+			t.Fatalf("%s: expected %f, got %f, %v", s, expected, v, err)
+		}
+	}
+
+	if _, err := parseISODuration("PT"); err == nil {
+		t.Fatal("expected the empty duration invalid")
+	}
+}
+
+func TestVideoIndexNext(t *testing.T) {
+	idx := newVideoIndex()
+	idx.add([]string{"s0.ts", "s1.ts", "s2.ts", "s3.ts"})
+
+	if next := idx.next("s1.ts", 2); !reflect.DeepEqual(next, []string{"s2.ts", "s3.ts"}) {
+		t.Fatalf("unexpected next %v", next)
+	}
+
+	if next := idx.next("s3.ts", 2); next == nil || len(next) != 0 {
+		t.Fatalf("expected no segment after the last, got %v", next)
+	}
+
+	if next := idx.next("unknown.ts", 2); next != nil {
+		t.Fatalf("expected nil for the unknown segment, got %v", next)
+	}
+}
+
+func TestAssetSubPath(t *testing.T) {
+	cases := map[string]string{
+		"/ipfs/bafy":                    "",
+		"/ipfs/bafy/":                   "",
+		"/ipfs/bafy/720p/seg_005.ts":    "720p/seg_005.ts",
+		"/ipfs/bafy/720p/../seg_005.ts": "seg_005.ts",
+		"/ipfs/bafy/index.m3u8":         "index.m3u8",
+	}
+
+	for urlPath, expected := range cases {
+		if p := assetSubPath(urlPath); p != expected {
+			t.Fatalf("%s: expected %s, got %s", urlPath, expected, p)
+		}
+	}
+}
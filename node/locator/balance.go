@@ -0,0 +1,61 @@
+package locator
+
+import (
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// maxDBLatency the db latency of a fully loaded scheduler (milliseconds)
+const maxDBLatency = 500
+
+// schedulerLoad returns the load of the scheduler in [0, 1] reported through etcd,
+// the highest of its online nodes relative to its capacity, its cpu usage and its db latency
+func schedulerLoad(config *types.SchedulerCfg) float64 {
+	load := config.CPUUsage / 100
+	if config.Capacity > 0 {
+		load = max(load, float64(config.Edges+config.Candidates)/float64(config.Capacity))
+	}
+	load = max(load, float64(config.DBLatency)/maxDBLatency)
+
+	return min(max(load, 0), 1)
+}
+
+// balanceWeight returns the weight of the scheduler for the new nodes and clients, its weight scaled by its spare load
+func balanceWeight(config *types.SchedulerCfg) float64 {
+	return float64(config.Weight) * (1 - schedulerLoad(config))
+}
+
+// balanceSchedulers orders the schedulers for the new nodes and clients, the first one is picked at random
+// weighted by the balance weight and the others follow from the least loaded
+func (l *Locator) balanceSchedulers(configs []*types.SchedulerCfg) []string {
+	configs = append([]*types.SchedulerCfg(nil), configs...)
+	sort.SliceStable(configs, func(i, j int) bool {
+		return schedulerLoad(configs[i]) < schedulerLoad(configs[j])
+	})
+
+	total := 0.0
+	for _, config := range configs {
+		total += balanceWeight(config)
+	}
+
+	if total > 0 {
+		r := l.Rand.Float64() * total
+		for i, config := range configs {
+			r -= balanceWeight(config)
+			if r < 0 {
+				picked := configs[i]
+				copy(configs[1:i+1], configs[:i])
+				configs[0] = picked
+				break
+			}
+		}
+	}
+
+	urls := make([]string, 0, len(configs))
+	for _, config := range configs {
+		urls = append(urls, config.SchedulerURL)
+	}
+
+	return urls
+}
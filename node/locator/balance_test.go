@@ -0,0 +1,55 @@
+package locator
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestSchedulerLoad(t *testing.T) {
+	config := &types.SchedulerCfg{Weight: 100, Capacity: 1000, Edges: 400, Candidates: 100, CPUUsage: 20, DBLatency: 50}
+	if load := schedulerLoad(config); load != 0.5 {
+		t.Fatalf("expect the load of the nodes, got %f", load)
+	}
+
+	config.DBLatency = 1000
+	if load := schedulerLoad(config); load != 1 {
+		t.Fatalf("expect the load capped, got %f", load)
+	}
+
+	if weight := balanceWeight(config); weight != 0 {
+		t.Fatalf("expect no weight of a fully loaded scheduler, got %f", weight)
+	}
+
+	// the schedulers without load reported keep their weights
+	if weight := balanceWeight(&types.SchedulerCfg{Weight: 100}); weight != 100 {
+		t.Fatalf("expect the weight kept, got %f", weight)
+	}
+}
+
+func TestBalanceSchedulers(t *testing.T) {
+	configs := []*types.SchedulerCfg{
+		{SchedulerURL: "https://s1", Weight: 100, Capacity: 100, Edges: 100},
+		{SchedulerURL: "https://s2", Weight: 100, CPUUsage: 50},
+		{SchedulerURL: "https://s3", Weight: 100, CPUUsage: 10},
+	}
+
+	locator := &Locator{Rand: rand.New(rand.NewSource(1))}
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		urls := locator.balanceSchedulers(configs)
+		if len(urls) != 3 {
+			t.Fatalf("expect all the schedulers, got %v", urls)
+		}
+
+		if urls[2] != "https://s1" {
+			t.Fatalf("expect the fully loaded scheduler last, got %v", urls)
+		}
+		counts[urls[0]]++
+	}
+
+	if counts["https://s1"] != 0 || counts["https://s3"] <= counts["https://s2"] {
+		t.Fatalf("expect the less loaded scheduler picked more, got %v", counts)
+	}
+}
@@ -3,14 +3,18 @@ package locator
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/lib/etcdcli"
 	"go.etcd.io/etcd/api/v3/mvccpb"
 )
 
+// EtcdClient keeps the configs of the schedulers registered in etcd, the schedulers register themselves
+// and refresh their configs while they are alive
 type EtcdClient struct {
 	cli *etcdcli.Client
+	lk  sync.RWMutex
 	// key is areaID, value is array of types.SchedulerCfg pointer
 	schedulerConfigs map[string][]*types.SchedulerCfg
 	// key is etcd key, value is types.SchedulerCfg pointer
@@ -44,6 +48,9 @@ func (ec *EtcdClient) loadSchedulerConfigs() error {
 		return err
 	}
 
+	ec.lk.Lock()
+	defer ec.lk.Unlock()
+
 	schedulerConfigs := make(map[string][]*types.SchedulerCfg)
 
 	for _, kv := range resp.Kvs {
@@ -53,13 +60,9 @@ func (ec *EtcdClient) loadSchedulerConfigs() error {
 			return err
 		}
 
-		configs, ok := schedulerConfigs[config.AreaID]
-		if !ok {
-			configs = make([]*types.SchedulerCfg, 0)
+		for _, areaID := range configAreas(config) {
+			schedulerConfigs[areaID] = append(schedulerConfigs[areaID], config)
 		}
-		configs = append(configs, config)
-
-		schedulerConfigs[config.AreaID] = configs
 		ec.configMap[string(kv.Key)] = config
 	}
 
@@ -68,10 +71,6 @@ func (ec *EtcdClient) loadSchedulerConfigs() error {
 }
 
 func (ec *EtcdClient) watch() {
-	if ec.schedulerConfigs == nil {
-		ec.schedulerConfigs = make(map[string][]*types.SchedulerCfg)
-	}
-
 	watchChan := ec.cli.WatchServers(context.Background(), types.NodeScheduler.String())
 	for {
 		resp, ok := <-watchChan
@@ -103,50 +102,85 @@ func (ec *EtcdClient) onPut(kv *mvccpb.KeyValue) error {
 		return err
 	}
 
-	configs, ok := ec.schedulerConfigs[config.AreaID]
-	if !ok {
-		configs = make([]*types.SchedulerCfg, 0)
+	ec.lk.Lock()
+	defer ec.lk.Unlock()
+
+	// the config is put again on each refresh of the scheduler, the previous one is replaced
+	if err := ec.remove(string(kv.Key)); err != nil {
+		return err
 	}
-	configs = append(configs, config)
 
-	ec.schedulerConfigs[config.AreaID] = configs
+	for _, areaID := range configAreas(config) {
+		ec.schedulerConfigs[areaID] = append(ec.schedulerConfigs[areaID], config)
+	}
 	ec.configMap[string(kv.Key)] = config
 	return nil
 }
 
 func (ec *EtcdClient) onDelete(kv *mvccpb.KeyValue) error {
 	log.Debugf("onDelete key: %s", string(kv.Key))
-	config, ok := ec.configMap[string(kv.Key)]
+
+	ec.lk.Lock()
+	defer ec.lk.Unlock()
+
+	return ec.remove(string(kv.Key))
+}
+
+// remove removes the config of the key from the areas it is listed in
+func (ec *EtcdClient) remove(key string) error {
+	config, ok := ec.configMap[key]
 	if !ok {
 		return nil
 	}
 
-	configs, ok := ec.schedulerConfigs[config.AreaID]
-	if !ok {
-		return fmt.Errorf("no config in area %s", config.AreaID)
-	}
+	for _, areaID := range configAreas(config) {
+		configs, ok := ec.schedulerConfigs[areaID]
+		if !ok {
+			return fmt.Errorf("no config in area %s", areaID)
+		}
 
-	// remove config
-	for i, cfg := range configs {
-		if cfg.SchedulerURL == config.SchedulerURL {
-			configs = append(configs[:i], configs[i+1:]...)
-			break
+		// remove config
+		for i, cfg := range configs {
+			if cfg.SchedulerURL == config.SchedulerURL {
+				configs = append(configs[:i], configs[i+1:]...)
+				break
+			}
 		}
+
+		ec.schedulerConfigs[areaID] = configs
 	}
 
-	ec.schedulerConfigs[config.AreaID] = configs
-	delete(ec.configMap, string(kv.Key))
+	delete(ec.configMap, key)
 	return nil
 }
 
+// configAreas returns the area of the scheduler and the zones it serves, the scheduler is listed in each of them
+func configAreas(config *types.SchedulerCfg) []string {
+	areas := []string{config.AreaID}
+	for _, areaID := range config.Zones {
+		if areaID != "" && areaID != config.AreaID {
+			areas = append(areas, areaID)
+		}
+	}
+
+	return areas
+}
+
 func (ec *EtcdClient) GetSchedulerConfigs(areaID string) ([]*types.SchedulerCfg, error) {
-	return ec.schedulerConfigs[areaID], nil
+	ec.lk.RLock()
+	defer ec.lk.RUnlock()
+
+	return append([]*types.SchedulerCfg(nil), ec.schedulerConfigs[areaID]...), nil
 }
 
+// GetAllSchedulerConfigs returns the configs of all schedulers, a scheduler serving zones is listed once
 func (ec *EtcdClient) GetAllSchedulerConfigs() []*types.SchedulerCfg {
-	schedulerConfigs := make([]*types.SchedulerCfg, 0)
-	for _, configs := range ec.schedulerConfigs {
-		schedulerConfigs = append(schedulerConfigs, configs...)
+	ec.lk.RLock()
+	defer ec.lk.RUnlock()
+
+	schedulerConfigs := make([]*types.SchedulerCfg, 0, len(ec.configMap))
+	for _, config := range ec.configMap {
+		schedulerConfigs = append(schedulerConfigs, config)
 	}
 
 	return schedulerConfigs
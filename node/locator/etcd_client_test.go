@@ -6,6 +6,7 @@ import (
 
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/lib/etcdcli"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 )
 
 const (
@@ -36,3 +37,57 @@ func TestEtcd(t *testing.T) {
 		t.Logf("k:%s v:%s", kv.Key, kv.Value)
 	}
 }
+
+func TestZoneConfigs(t *testing.T) {
+	ec := &EtcdClient{
+		schedulerConfigs: make(map[string][]*types.SchedulerCfg),
+		configMap:        make(map[string]*types.SchedulerCfg),
+	}
+
+	value, err := etcdcli.SCMarshal(&types.SchedulerCfg{SchedulerURL: "https://s1", AreaID: "a1", Zones: []string{"a2", "a3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kv := &mvccpb.KeyValue{Key: []byte("/scheduler/s1"), Value: value}
+	if err := ec.onPut(kv); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, areaID := range []string{"a1", "a2", "a3"} {
+		if configs, _ := ec.GetSchedulerConfigs(areaID); len(configs) != 1 || configs[0].SchedulerURL != "https://s1" {
+			t.Fatalf("expect the scheduler listed in area %s", areaID)
+		}
+	}
+
+	if len(ec.GetAllSchedulerConfigs()) != 1 {
+		t.Fatal("expect the scheduler listed once in all configs")
+	}
+
+	// the refresh of the scheduler puts its config again
+	value, err = etcdcli.SCMarshal(&types.SchedulerCfg{SchedulerURL: "https://s1", AreaID: "a1", Zones: []string{"a2"}, Edges: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kv = &mvccpb.KeyValue{Key: []byte("/scheduler/s1"), Value: value}
+	if err := ec.onPut(kv); err != nil {
+		t.Fatal(err)
+	}
+
+	if configs, _ := ec.GetSchedulerConfigs("a1"); len(configs) != 1 || configs[0].Edges != 10 {
+		t.Fatal("expect the config of the scheduler replaced")
+	}
+
+	if configs, _ := ec.GetSchedulerConfigs("a3"); len(configs) != 0 {
+		t.Fatal("expect the scheduler removed from the zone it no longer serves")
+	}
+
+	if err := ec.onDelete(kv); err != nil {
+		t.Fatal(err)
+	}
+
+	if configs, _ := ec.GetSchedulerConfigs("a2"); len(configs) != 0 {
+		t.Fatal("expect the scheduler removed from the zones")
+	}
+}
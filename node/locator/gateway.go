@@ -0,0 +1,220 @@
+package locator
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// GatewayPathPrefix the path prefix of the ipfs gateway
+	GatewayPathPrefix = "/ipfs/"
+
+	immutableCacheControl = "public, max-age=29030400, immutable"
+	// maximum number of nodes tried to proxy a gateway request
+	gatewayProxyAttempts = 3
+	// the limiters are dropped when the clients exceed it, the clients start over with full bursts
+	gatewayMaxLimiters = 10000
+)
+
+// request headers forwarded to the node
+var gatewayForwardHeaders = []string{"Accept", "Range", "If-Range", "If-None-Match", "If-Modified-Since"}
+
+// Gateway serves /ipfs/{cid} by the nodes holding the asset, the nodes are selected by
+// the schedulers of the client area, the request is reverse proxied or redirected to the node
+type Gateway struct {
+	locator  api.Locator
+	redirect bool
+	client   *http.Client
+	// tcpClient requests the nodes negotiated to transfer over tcp
+	tcpClient *http.Client
+
+	// requests per second of a client ip, no limit if it is 0
+	rateLimit int
+	lk        sync.Mutex
+	limiters  map[string]*rate.Limiter
+}
+
+// NewGateway creates a gateway, the requests are redirected to the selected node if redirect is true,
+// the requests of a client ip are limited to rateLimit per second
+func NewGateway(locator api.Locator, redirect bool, rateLimit int) *Gateway {
+	return &Gateway{
+		locator:   locator,
+		redirect:  redirect,
+		client:    client.NewHTTP3Client(),
+		tcpClient: client.NewHTTPClient(),
+		rateLimit: rateLimit,
+		limiters:  make(map[string]*rate.Limiter),
+	}
+}
+
+// ServeHTTP serves the gateway request
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	root, err := gatewayRootCID(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	remoteAddr := r.Header.Get("X-Remote-Addr")
+	if remoteAddr == "" {
+		remoteAddr = r.RemoteAddr
+	}
+
+	if !g.allow(remoteAddr) {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), handler.RemoteAddr{}, remoteAddr)
+
+	nodes, err := g.locator.GatewayNodes(ctx, root.String())
+	if err != nil {
+		log.Errorf("GatewayNodes %s error %s", root.String(), err.Error())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if len(nodes) == 0 {
+		http.Error(w, fmt.Sprintf("no node holds %s", root.String()), http.StatusNotFound)
+		return
+	}
+
+	if g.redirect {
+		// the selection depends on the client and the load of the nodes, never cache it
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, fmt.Sprintf("https://%s%s", nodes[0].Address, r.URL.RequestURI()), http.StatusTemporaryRedirect)
+		return
+	}
+
+	for i, node := range nodes {
+		if i >= gatewayProxyAttempts {
+			break
+		}
+
+		// the token is issued only for the node requested
+		tk, err := g.locator.GatewayToken(ctx, root.String(), node)
+		if err != nil {
+			log.Warnf("gateway token of node %s error %s", node.NodeID, err.Error())
+			continue
+		}
+
+		resp, err := g.requestNode(r, node, tk)
+		if err != nil {
+			log.Warnf("gateway request %s from node %s error %s", r.URL.Path, node.NodeID, err.Error())
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			resp.Body.Close() //nolint:errcheck // ignore error
+			log.Warnf("gateway request %s from node %s status %d", r.URL.Path, node.NodeID, resp.StatusCode)
+			continue
+		}
+
+		copyGatewayResponse(w, resp)
+		return
+	}
+
+	http.Error(w, fmt.Sprintf("can not get %s from the nodes", r.URL.Path), http.StatusBadGateway)
+}
+
+// allow reports whether the request of the client is within the rate limit
+func (g *Gateway) allow(remoteAddr string) bool {
+	if g.rateLimit <= 0 {
+		return true
+	}
+
+	ip, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		ip = remoteAddr
+	}
+
+	g.lk.Lock()
+	defer g.lk.Unlock()
+
+	l, ok := g.limiters[ip]
+	if !ok {
+		if len(g.limiters) >= gatewayMaxLimiters {
+			g.limiters = make(map[string]*rate.Limiter)
+		}
+
+		l = rate.NewLimiter(rate.Limit(g.rateLimit), g.rateLimit)
+		g.limiters[ip] = l
+	}
+
+	return l.Allow()
+}
+
+// requestNode sends the request to the node with the token issued by the scheduler
+func (g *Gateway) requestNode(r *http.Request, node *types.GatewayNode, tk *types.Token) (*http.Response, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(tk); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, fmt.Sprintf("https://%s%s", node.Address, r.URL.RequestURI()), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range gatewayForwardHeaders {
+		if v := r.Header.Get(key); v != "" {
+			req.Header.Set(key, v)
+		}
+	}
+
+	if types.PreferredTransferProtocol(node.Protocols) == types.TransferProtocolHTTP {
+		return g.tcpClient.Do(req)
+	}
+	return g.client.Do(req)
+}
+
+// copyGatewayResponse copies the response of the node, the successful responses of the immutable content are cached forever
+func copyGatewayResponse(w http.ResponseWriter, resp *http.Response) {
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	for key, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusNotModified:
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	}
+
+	w.WriteHeader(resp.StatusCode)
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Warnf("copy gateway response error %s", err.Error())
+	}
+}
+
+// gatewayRootCID parses the root cid of the path /ipfs/{cid}[/{path}]
+func gatewayRootCID(path string) (cid.Cid, error) {
+	rest := strings.TrimPrefix(path, GatewayPathPrefix)
+	if rest == path || rest == "" {
+		return cid.Cid{}, fmt.Errorf("invalid path %s", path)
+	}
+
+	return cid.Decode(strings.SplitN(rest, "/", 2)[0])
+}
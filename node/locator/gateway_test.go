@@ -0,0 +1,47 @@
+package locator
+
+import "testing"
+
+func TestGatewayRootCID(t *testing.T) {
+	const root = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+	for _, path := range []string{"/ipfs/" + root, "/ipfs/" + root + "/", "/ipfs/" + root + "/dir/file.txt"} {
+		c, err := gatewayRootCID(path)
+		if err != nil {
+			t.Fatalf("parse %s error %s", path, err.Error())
+		}
+
+		if c.String() != root {
+			t.Fatalf("parse %s expect %s, got %s", path, root, c.String())
+		}
+	}
+
+	for _, path := range []string{"/ipfs/", "/ipns/" + root, "/ipfs/not-a-cid"} {
+		if _, err := gatewayRootCID(path); err == nil {
+			t.Fatalf("parse %s expect error", path)
+		}
+	}
+}
+
+func TestGatewayAllow(t *testing.T) {
+	g := NewGateway(nil, false, 2)
+
+	for i := 0; i < 2; i++ {
+		if !g.allow("192.0.2.1:1000") {
+			t.Fatalf("request %d within the burst rejected", i)
+		}
+	}
+
+	// the port of the client does not matter
+	if g.allow("192.0.2.1:2000") {
+		t.Fatal("request exceeding the rate limit allowed")
+	}
+
+	if !g.allow("192.0.2.2:1000") {
+		t.Fatal("request of another client rejected")
+	}
+
+	if !NewGateway(nil, false, 0).allow("192.0.2.1:1000") {
+		t.Fatal("request rejected without rate limit")
+	}
+}
@@ -358,6 +358,104 @@ func (l *Locator) getCandidateDownloadInfoFromBestScheduler(apis []*SchedulerAPI
 
 }
 
+// GatewayNodes get the nodes holding the asset from the schedulers of the client area,
+// the schedulers of the other areas are queried if no node holds the asset in the area
+func (l *Locator) GatewayNodes(ctx context.Context, cid string) ([]*types.GatewayNode, error) {
+	remoteAddr := handler.GetRemoteAddr(ctx)
+	areaID, err := l.getAreaID(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	configs, err := l.GetSchedulerConfigs(areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := l.getGatewayNodesFromSchedulers(configs, cid)
+	if len(nodes) > 0 {
+		return nodes, nil
+	}
+
+	others := make([]*types.SchedulerCfg, 0)
+	for _, config := range l.GetAllSchedulerConfigs() {
+		if config.AreaID != areaID {
+			others = append(others, config)
+		}
+	}
+
+	return l.getGatewayNodesFromSchedulers(others, cid), nil
+}
+
+// getGatewayNodesFromSchedulers queries the schedulers concurrently, the nodes are ordered by their cost
+func (l *Locator) getGatewayNodesFromSchedulers(configs []*types.SchedulerCfg, cid string) []*types.GatewayNode {
+	schedulerAPIs, err := l.getOrNewSchedulerAPIs(configs)
+	if err != nil || len(schedulerAPIs) == 0 {
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(l.Timeout)
+	if err != nil {
+		log.Errorf("parse timeout %s error %s", l.Timeout, err.Error())
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	nodes := make([]*types.GatewayNode, 0)
+	lock := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for _, api := range schedulerAPIs {
+		wg.Add(1)
+
+		go func(s *SchedulerAPI) {
+			defer wg.Done()
+
+			infos, err := s.GetGatewayNodes(ctx, cid, 0)
+			if err != nil {
+				log.Errorf("GetGatewayNodes cid %s from %s, error: %s", cid, s.config.SchedulerURL, err.Error())
+				return
+			}
+
+			for _, info := range infos {
+				info.SchedulerURL = s.config.SchedulerURL
+			}
+
+			lock.Lock()
+			nodes = append(nodes, infos...)
+			lock.Unlock()
+		}(api)
+	}
+
+	wg.Wait()
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Cost < nodes[j].Cost
+	})
+
+	return nodes
+}
+
+// GatewayToken get the token of the node to serve the gateway request from the scheduler of the node,
+// the scheduler must be one of the configured schedulers
+func (l *Locator) GatewayToken(ctx context.Context, cid string, node *types.GatewayNode) (*types.Token, error) {
+	for _, config := range l.GetAllSchedulerConfigs() {
+		if config.SchedulerURL != node.SchedulerURL {
+			continue
+		}
+
+		api, err := l.getOrNewSchedulerAPI(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return api.GetGatewayToken(ctx, cid, node.NodeID)
+	}
+
+	return nil, fmt.Errorf("scheduler %s not found", node.SchedulerURL)
+}
+
 // GetUserAccessPoint get user access point for special user ip
 func (l *Locator) GetUserAccessPoint(ctx context.Context, userIP string) (*api.AccessPoint, error) {
 	areaID := l.DefaultAreaID
@@ -389,28 +487,27 @@ func (l *Locator) GetUserAccessPoint(ctx context.Context, userIP string) (*api.A
 	}
 
 	exculdeAreas := convertAreasToMap(l.LocatorCfg.LoadBalanceExcludeArea)
-	schedulerURLs := make(map[string][]string)
+	areaConfigs := make(map[string][]*types.SchedulerCfg)
 	for _, config := range configs {
 		if _, ok := exculdeAreas[config.AreaID]; ok {
 			continue
 		}
 
-		urls, ok := schedulerURLs[config.AreaID]
-		if !ok {
-			urls = make([]string, 0)
-		}
-
-		urls = append(urls, config.SchedulerURL)
-		schedulerURLs[config.AreaID] = urls
+		areaConfigs[config.AreaID] = append(areaConfigs[config.AreaID], config)
 	}
 
-	if len(schedulerURLs) == 0 {
+	if len(areaConfigs) == 0 {
 		return &api.AccessPoint{AreaID: areaID, SchedulerURLs: make([]string, 0)}, nil
 	}
 
+	// the schedulers of the user area are preferred, the schedulers are ordered by their load
+	if schedulers, ok := areaConfigs[areaID]; ok {
+		return &api.AccessPoint{AreaID: areaID, SchedulerURLs: l.balanceSchedulers(schedulers)}, nil
+	}
+
 	// get scheduler configs of first areaID
-	for area, schedulers := range schedulerURLs {
-		return &api.AccessPoint{AreaID: area, SchedulerURLs: schedulers}, nil
+	for area, schedulers := range areaConfigs {
+		return &api.AccessPoint{AreaID: area, SchedulerURLs: l.balanceSchedulers(schedulers)}, nil
 	}
 	return &api.AccessPoint{AreaID: areaID, SchedulerURLs: make([]string, 0)}, nil
 }
@@ -593,3 +690,120 @@ func convertAreasToMap(areas []string) map[string]struct{} {
 
 	return areaMap
 }
+
+// GetRegionStats rolls up the statistics of the schedulers per region, all regions if areaID is empty
+func (l *Locator) GetRegionStats(ctx context.Context, areaID string) ([]*types.RegionStats, error) {
+	configs := l.GetAllSchedulerConfigs()
+	if len(areaID) > 0 {
+		var err error
+		configs, err = l.GetSchedulerConfigs(areaID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	schedulerAPIs, err := l.getOrNewSchedulerAPIs(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := time.ParseDuration(l.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout*time.Second)
+	defer cancel()
+
+	lk := &sync.Mutex{}
+	stats := make([]*types.RegionStats, 0, len(schedulerAPIs))
+
+	wg := &sync.WaitGroup{}
+	for _, api := range schedulerAPIs {
+		wg.Add(1)
+
+		go func(ctx context.Context, s *SchedulerAPI) {
+			defer wg.Done()
+
+			st, err := schedulerStats(ctx, s, areaID)
+			if err != nil {
+				log.Warnf("GetRegionStats of %s err:%s", s.config.SchedulerURL, err.Error())
+				return
+			}
+
+			lk.Lock()
+			stats = append(stats, st...)
+			lk.Unlock()
+		}(ctx, api)
+	}
+	wg.Wait()
+
+	return rollupRegionStats(stats), nil
+}
+
+// schedulerStats returns the statistics of the scheduler, per zone if it serves zones,
+// only the zone of the area if areaID is not empty
+func schedulerStats(ctx context.Context, s *SchedulerAPI, areaID string) ([]*types.RegionStats, error) {
+	if len(s.config.Zones) == 0 {
+		st, err := s.GetRegionStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		st.AreaID = s.config.AreaID
+
+		return []*types.RegionStats{st}, nil
+	}
+
+	zones, err := s.GetZoneStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*types.RegionStats, 0, len(zones))
+	for _, st := range zones {
+		if areaID == "" || st.AreaID == areaID {
+			stats = append(stats, st)
+		}
+	}
+
+	return stats, nil
+}
+
+// rollupRegionStats sums the statistics of the schedulers of the same region, the node statistics
+// are summed, the replica and traffic counters are shared by the schedulers of the region through their database
+func rollupRegionStats(stats []*types.RegionStats) []*types.RegionStats {
+	regions := make(map[string]*types.RegionStats)
+	for _, st := range stats {
+		region, ok := regions[st.AreaID]
+		if !ok {
+			region = &types.RegionStats{AreaID: st.AreaID}
+			regions[st.AreaID] = region
+		}
+
+		region.SchedulerCount += st.SchedulerCount
+		region.OnlineEdgeCount += st.OnlineEdgeCount
+		region.OnlineCandidateCount += st.OnlineCandidateCount
+		region.BandwidthUp += st.BandwidthUp
+		region.BandwidthDown += st.BandwidthDown
+		region.DiskSpace += st.DiskSpace
+		region.AvailableDiskSpace += st.AvailableDiskSpace
+
+		if st.ReplicaCount > region.ReplicaCount {
+			region.ReplicaCount = st.ReplicaCount
+		}
+		if st.TrafficServed > region.TrafficServed {
+			region.TrafficServed = st.TrafficServed
+		}
+	}
+
+	out := make([]*types.RegionStats, 0, len(regions))
+	for _, region := range regions {
+		out = append(out, region)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].AreaID < out[j].AreaID
+	})
+
+	return out
+}
@@ -55,3 +55,25 @@ func TestRandomSchedulerConfigWithWeight(t *testing.T) {
 	}
 
 }
+
+func TestRollupRegionStats(t *testing.T) {
+	stats := []*types.RegionStats{
+		{AreaID: "Asia-China-Guangdong-Shenzhen", SchedulerCount: 1, OnlineEdgeCount: 10, BandwidthUp: 100, ReplicaCount: 5, TrafficServed: 1000},
+		{AreaID: "Asia-China-Guangdong-Shenzhen", SchedulerCount: 1, OnlineEdgeCount: 20, BandwidthUp: 200, ReplicaCount: 6, TrafficServed: 900},
+		{AreaID: "Asia-HongKong", SchedulerCount: 1, OnlineCandidateCount: 3},
+	}
+
+	regions := rollupRegionStats(stats)
+	if len(regions) != 2 {
+		t.Fatalf("expected 2 regions, got %d", len(regions))
+	}
+
+	sz := regions[0]
+	if sz.SchedulerCount != 2 || sz.OnlineEdgeCount != 30 || sz.BandwidthUp != 300 || sz.ReplicaCount != 6 || sz.TrafficServed != 1000 {
+		t.Fatalf("unexpected region stats %+v", sz)
+	}
+
+	if regions[1].AreaID != "Asia-HongKong" || regions[1].OnlineCandidateCount != 3 {
+		t.Fatalf("unexpected region stats %+v", regions[1])
+	}
+}
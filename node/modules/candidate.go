@@ -2,7 +2,7 @@ package modules
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/node/candidate"
@@ -11,7 +11,7 @@ import (
 )
 
 // NewTCPServer returns a new TCP server instance.
-func NewTCPServer(lc fx.Lifecycle, cfg *config.CandidateCfg, schedulerAPI api.Scheduler, key *rsa.PrivateKey) *candidate.TCPServer {
+func NewTCPServer(lc fx.Lifecycle, cfg *config.CandidateCfg, schedulerAPI api.Scheduler, key crypto.Signer) *candidate.TCPServer {
 	srv := candidate.NewTCPServer(cfg, schedulerAPI, key)
 
 	lc.Append(fx.Hook{
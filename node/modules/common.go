@@ -2,17 +2,13 @@ package modules
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
 	"errors"
 
 	"github.com/Filecoin-Titan/titan/build"
 	"github.com/Filecoin-Titan/titan/lib/ulimit"
-	"github.com/docker/go-units"
 
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/repo"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
 	"github.com/Filecoin-Titan/titan/node/types"
@@ -76,38 +72,6 @@ func NewServerID(lr repo.LockedRepo) (dtypes.ServerID, error) {
 	return dtypes.ServerID(key.PrivateKey), nil
 }
 
-// NewPrivateKey generates and returns the private key
-func NewPrivateKey(lr repo.LockedRepo) (*rsa.PrivateKey, error) {
-	keystore, err := lr.KeyStore()
-	if err != nil {
-		return nil, err
-	}
-
-	key, err := keystore.Get(PrivateKeyName)
-
-	if errors.Is(err, types.ErrKeyInfoNotFound) {
-		log.Warn("Generating new private key")
-
-		privateKey, err := rsa.GenerateKey(rand.Reader, units.KiB)
-		if err != nil {
-			return nil, xerrors.Errorf("GenerateKey: %w", err)
-		}
-
-		key = types.KeyInfo{
-			Type:       PrivateKeyName,
-			PrivateKey: titanrsa.PrivateKey2Pem(privateKey),
-		}
-
-		if err := keystore.Put(PrivateKeyName, key); err != nil {
-			return nil, xerrors.Errorf("writing private key: %w", err)
-		}
-	} else if err != nil {
-		return nil, xerrors.Errorf("could not get private key: %w", err)
-	}
-
-	return titanrsa.Pem2PrivateKey(key.PrivateKey)
-}
-
 // Datastore returns a new metadata datastore
 func Datastore(db *db.SQLDB, serverID dtypes.ServerID) (dtypes.MetadataDS, error) {
 	return assets.NewDatastore(db, serverID), nil
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"crypto"
+
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/node/asset"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
@@ -12,11 +14,11 @@ import (
 	"golang.org/x/time/rate"
 )
 
-// NewDevice creates a function that generates new instances of device.Device.
-func NewDevice(cpu *config.CPU, memory *config.Memory, storageCfg *config.Storage, bandwidth *config.Bandwidth) func(nodeID dtypes.NodeID, internalIP dtypes.InternalIP, storageMgr *storage.Manager) *device.Device {
-	return func(nodeID dtypes.NodeID, internalIP dtypes.InternalIP, storageMgr *storage.Manager) *device.Device {
-		res := &device.Resources{CPU: cpu, Memory: memory, Storage: storageCfg, Bandwidth: bandwidth}
-		return device.NewDevice(string(nodeID), string(internalIP), res, storageMgr)
+// NewDevice creates a function that generates new instances of device.Device, tcpTLS tells the tcp server of the node serves tls.
+func NewDevice(cpu *config.CPU, memory *config.Memory, storageCfg *config.Storage, bandwidth *config.Bandwidth, network *config.Network, tcpTLS bool) func(nodeID dtypes.NodeID, internalIP dtypes.InternalIP, storageMgr *storage.Manager, privateKey crypto.Signer) *device.Device {
+	return func(nodeID dtypes.NodeID, internalIP dtypes.InternalIP, storageMgr *storage.Manager, privateKey crypto.Signer) *device.Device {
+		res := &device.Resources{CPU: cpu, Memory: memory, Storage: storageCfg, Bandwidth: bandwidth, Network: network, TCPTLS: tcpTLS}
+		return device.NewDevice(string(nodeID), string(internalIP), res, storageMgr, privateKey)
 	}
 }
 
@@ -37,8 +39,8 @@ func NewNodeStorageManager(metadataPaths dtypes.NodeMetadataPath, assetsPaths dt
 }
 
 // NewAssetsManager creates a function that generates new instances of asset.Manager.
-func NewAssetsManager(pullParallel int, pullTimeout int, pullRetry int, ipfsAPIURL string) func(storageMgr *storage.Manager, schedulerAPI api.Scheduler) (*asset.Manager, error) {
-	return func(storageMgr *storage.Manager, schedulerAPI api.Scheduler) (*asset.Manager, error) {
+func NewAssetsManager(pullParallel int, pullTimeout int, pullRetry int, ipfsAPIURL string, proxyURL string) func(storageMgr *storage.Manager, schedulerAPI api.Scheduler, nodeID dtypes.NodeID, privateKey crypto.Signer) (*asset.Manager, error) {
+	return func(storageMgr *storage.Manager, schedulerAPI api.Scheduler, nodeID dtypes.NodeID, privateKey crypto.Signer) (*asset.Manager, error) {
 		opts := &asset.ManagerOptions{
 			Storage:      storageMgr,
 			IPFSAPIURL:   ipfsAPIURL,
@@ -46,6 +48,9 @@ func NewAssetsManager(pullParallel int, pullTimeout int, pullRetry int, ipfsAPIU
 			PullParallel: pullParallel,
 			PullTimeout:  pullTimeout,
 			PullRetry:    pullRetry,
+			NodeID:       string(nodeID),
+			PrivateKey:   privateKey,
+			ProxyURL:     proxyURL,
 		}
 		return asset.NewManager(opts)
 	}
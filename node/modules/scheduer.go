@@ -2,6 +2,8 @@ package modules
 
 import (
 	"context"
+	"os"
+	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
@@ -12,11 +14,19 @@ import (
 	"github.com/Filecoin-Titan/titan/node/repo"
 	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/decision"
+	"github.com/Filecoin-Titan/titan/node/scheduler/denylist"
+	"github.com/Filecoin-Titan/titan/node/scheduler/discovery"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/locindex"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	"github.com/Filecoin-Titan/titan/node/scheduler/traffic"
 	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
 	"github.com/Filecoin-Titan/titan/node/sqldb"
 	"github.com/filecoin-project/go-jsonrpc/auth"
-	"github.com/filecoin-project/pubsub"
 	"github.com/google/uuid"
 	logging "github.com/ipfs/go-log/v2"
 	"go.uber.org/fx"
@@ -34,6 +44,38 @@ func NewDB(cfg *config.SchedulerCfg) (*sqlx.DB, error) {
 	return sqldb.NewDB(cfg.DatabaseAddress)
 }
 
+// NewKeyRing returns the scheduler keys from the configured backend
+func NewKeyRing(lr repo.LockedRepo, cfg *config.SchedulerCfg) (*keys.Ring, error) {
+	var store keys.Store
+	switch cfg.KeyBackend {
+	case "", "local":
+		keystore, err := lr.KeyStore()
+		if err != nil {
+			return nil, err
+		}
+
+		passphrase := ""
+		if cfg.KeyPassphraseEnv != "" {
+			passphrase = os.Getenv(cfg.KeyPassphraseEnv)
+			if passphrase == "" {
+				return nil, xerrors.Errorf("passphrase env %s is empty", cfg.KeyPassphraseEnv)
+			}
+		}
+
+		store = keys.NewLocalStore(keystore, PrivateKeyName, passphrase)
+	case "remote":
+		if cfg.RemoteSignerURL == "" {
+			return nil, xerrors.New("remote signer url is empty")
+		}
+
+		store = keys.NewRemoteStore(cfg.RemoteSignerURL, cfg.RemoteSignerToken)
+	default:
+		return nil, xerrors.Errorf("unknown key backend %s", cfg.KeyBackend)
+	}
+
+	return keys.NewRing(store, cfg.MaxRetiredKeys)
+}
+
 // GenerateTokenWithWebPermission create a new token based on the given permissions
 func GenerateTokenWithWebPermission(ca *common.CommonAPI) (dtypes.PermissionWebToken, error) {
 	token, err := ca.AuthNew(context.Background(), &types.JWTPayload{Allow: []auth.Permission{api.RoleWeb}, ID: uuid.NewString()})
@@ -53,6 +95,10 @@ type StorageManagerParams struct {
 	NodeManger *node.Manager
 	dtypes.GetSchedulerConfigFunc
 	*db.SQLDB
+	TrafficManager  *traffic.Manager
+	DecisionManager *decision.Manager
+	DenylistManager *denylist.Manager
+	JobQueue        *jobqueue.Queue
 }
 
 // NewStorageManager creates a new storage manager instance
@@ -67,7 +113,7 @@ func NewStorageManager(params StorageManagerParams) *assets.Manager {
 	)
 
 	ctx := helpers.LifecycleCtx(mctx, lc)
-	m := assets.NewManager(nodeMgr, ds, cfgFunc, sdb)
+	m := assets.NewManager(nodeMgr, ds, cfgFunc, sdb, params.TrafficManager, params.DecisionManager, params.DenylistManager, params.JobQueue)
 
 	lc.Append(fx.Hook{
 		OnStart: func(context.Context) error {
@@ -80,9 +126,50 @@ func NewStorageManager(params StorageManagerParams) *assets.Manager {
 	return m
 }
 
+// NewLocationIndex creates the location index of the retrieval routing with a connection pool of its own,
+// the cached locations are dropped by the replica changes of the asset manager
+func NewLocationIndex(lc fx.Lifecycle, cfg *config.SchedulerCfg, am *assets.Manager) (*locindex.Index, error) {
+	address := cfg.LocationIndexDatabaseAddress
+	if address == "" {
+		address = cfg.DatabaseAddress
+	}
+
+	client, err := sqldb.NewDB(address)
+	if err != nil {
+		return nil, xerrors.Errorf("open location index database err:%s", err.Error())
+	}
+
+	if cfg.LocationIndexMaxConns > 0 {
+		client.SetMaxOpenConns(cfg.LocationIndexMaxConns)
+		client.SetMaxIdleConns(cfg.LocationIndexMaxConns)
+	}
+
+	reader, err := db.NewSQLDB(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	idx, err := locindex.NewIndex(reader, cfg.LocationIndexCacheSize, time.Duration(cfg.LocationIndexCacheTTL)*time.Second)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	am.SubscribeReplicaChanges(idx.Invalidate)
+
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+
+	return idx, nil
+}
+
 // NewValidation creates a new validation manager instance
-func NewValidation(mctx helpers.MetricsCtx, l fx.Lifecycle, nm *node.Manager, am *assets.Manager, configFunc dtypes.GetSchedulerConfigFunc, p *pubsub.PubSub, lmgr *leadership.Manager) *validation.Manager {
-	v := validation.NewManager(nm, am, configFunc, p, lmgr)
+func NewValidation(mctx helpers.MetricsCtx, l fx.Lifecycle, nm *node.Manager, am *assets.Manager, configFunc dtypes.GetSchedulerConfigFunc, p *eventbus.Bus, lmgr *leadership.Manager, dmgr *decision.Manager, pmgr *penalty.Manager, keyRing *keys.Ring) *validation.Manager {
+	v := validation.NewManager(nm, am, configFunc, p, lmgr, dmgr, pmgr, keyRing)
 
 	ctx := helpers.LifecycleCtx(mctx, l)
 	l.Append(fx.Hook{
@@ -127,44 +214,28 @@ func NewGetSchedulerConfigFunc(r repo.LockedRepo) func() (config.SchedulerCfg, e
 	}
 }
 
-// NewPubSub returns a new pubsub instance with a buffer of 50
-func NewPubSub() *pubsub.PubSub {
-	return pubsub.New(50)
+// NewEventBus returns a new event bus
+func NewEventBus() *eventbus.Bus {
+	return eventbus.New()
 }
 
-// RegisterToEtcd registers the server to etcd
-func RegisterToEtcd(mctx helpers.MetricsCtx, lc fx.Lifecycle, configFunc dtypes.GetSchedulerConfigFunc, serverID dtypes.ServerID, token dtypes.PermissionWebToken) (*etcdcli.Client, error) {
+// NewEtcdClient returns the etcd client of the scheduler
+func NewEtcdClient(configFunc dtypes.GetSchedulerConfigFunc) (*etcdcli.Client, error) {
 	cfg, err := configFunc()
 	if err != nil {
 		return nil, err
 	}
 
-	sCfg := &types.SchedulerCfg{
-		AreaID:       cfg.AreaID,
-		SchedulerURL: cfg.ExternalURL,
-		AccessToken:  string(token),
-		Weight:       cfg.Weight,
-	}
-
-	value, err := etcdcli.SCMarshal(sCfg)
-	if err != nil {
-		return nil, xerrors.Errorf("cfg SCMarshal err:%s", err.Error())
-	}
-
-	eCli, err := etcdcli.New(cfg.EtcdAddresses)
-	if err != nil {
-		return nil, err
-	}
+	return etcdcli.New(cfg.EtcdAddresses)
+}
 
-	ctx := helpers.LifecycleCtx(mctx, lc)
-	lc.Append(fx.Hook{
-		OnStart: func(context.Context) error {
-			return eCli.ServerRegister(ctx, string(serverID), types.RunningNodeType.String(), string(value))
-		},
-		OnStop: func(context.Context) error {
-			return eCli.ServerUnRegister(ctx, string(serverID), types.RunningNodeType.String())
-		},
+// NewDiscovery registers the scheduler into etcd on start, and removes the registration on stop
+func NewDiscovery(l fx.Lifecycle, ec *etcdcli.Client, sdb *db.SQLDB, nm *node.Manager, configFunc dtypes.GetSchedulerConfigFunc, serverID dtypes.ServerID, token dtypes.PermissionWebToken) *discovery.Manager {
+	m := discovery.NewManager(ec, sdb, nm, configFunc, serverID, token)
+	l.Append(fx.Hook{
+		OnStart: m.Start,
+		OnStop:  m.Stop,
 	})
 
-	return eCli, nil
+	return m
 }
@@ -0,0 +1,86 @@
+package nodeconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("nodeconfig")
+
+// Flags the feature flags pushed by the scheduler, the flags not pushed are disabled
+type Flags struct {
+	lk    sync.RWMutex
+	flags map[string]bool
+}
+
+// NewFlags creates the feature flags of the node
+func NewFlags() *Flags {
+	return &Flags{flags: make(map[string]bool)}
+}
+
+// Enabled returns true if the feature is enabled
+func (f *Flags) Enabled(name string) bool {
+	f.lk.RLock()
+	defer f.lk.RUnlock()
+
+	return f.flags[name]
+}
+
+// All returns a copy of the flags
+func (f *Flags) All() map[string]bool {
+	f.lk.RLock()
+	defer f.lk.RUnlock()
+
+	out := make(map[string]bool, len(f.flags))
+	for name, enabled := range f.flags {
+		out[name] = enabled
+	}
+	return out
+}
+
+func (f *Flags) set(flags map[string]bool) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	f.flags = make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		f.flags[name] = enabled
+	}
+}
+
+// Target the settings of the node the pushed config applies to
+type Target struct {
+	SetCacheConfig func(ctx context.Context, cfg *types.CacheConfig) error
+	Flags          *Flags
+}
+
+// Apply applies the fields set by the pushed config, the fields failed to apply are reported in the acknowledgment
+// and the rest are still applied. The upload limit is not pushed, the scheduler sets it by SetUploadLimit
+func Apply(ctx context.Context, push *types.NodeConfigPush, t *Target) *types.NodeConfigAck {
+	ack := &types.NodeConfigAck{Revision: push.Revision}
+	cfg := push.Config
+	if cfg == nil {
+		cfg = &types.NodeConfig{}
+	}
+
+	if cfg.LogLevel != nil {
+		if err := logging.SetLogLevel("*", *cfg.LogLevel); err != nil {
+			ack.Errors = append(ack.Errors, fmt.Sprintf("log level: %s", err.Error()))
+		}
+	}
+
+	if cfg.CacheConfig != nil {
+		if err := t.SetCacheConfig(ctx, cfg.CacheConfig); err != nil {
+			ack.Errors = append(ack.Errors, fmt.Sprintf("cache config: %s", err.Error()))
+		}
+	}
+
+	t.Flags.set(cfg.FeatureFlags)
+
+	log.Infof("config %s applied with %d errors", push.Revision, len(ack.Errors))
+	return ack
+}
@@ -0,0 +1,47 @@
+package nodeconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestApply(t *testing.T) {
+	var cacheCfg *types.CacheConfig
+	target := &Target{
+		SetCacheConfig: func(ctx context.Context, cfg *types.CacheConfig) error {
+			cacheCfg = cfg
+			return nil
+		},
+		Flags: NewFlags(),
+	}
+	target.Flags.set(map[string]bool{"old": true})
+
+	level := "no-such-level"
+	push := &types.NodeConfigPush{
+		Revision: "r1",
+		Config: &types.NodeConfig{
+			LogLevel:     &level,
+			CacheConfig:  &types.CacheConfig{MaxDiskUsage: 80},
+			FeatureFlags: map[string]bool{"new": true},
+		},
+	}
+
+	ack := Apply(context.Background(), push, target)
+	if ack.Revision != "r1" {
+		t.Fatalf("expected revision r1, got %s", ack.Revision)
+	}
+
+	if len(ack.Errors) != 1 {
+		t.Fatalf("expected the error of the log level, got %v", ack.Errors)
+	}
+
+	if cacheCfg == nil || cacheCfg.MaxDiskUsage != 80 {
+		t.Fatalf("cache config not applied")
+	}
+
+	if target.Flags.Enabled("old") || !target.Flags.Enabled("new") {
+		t.Fatalf("expected the flags replaced, got %v", target.Flags.All())
+	}
+}
@@ -0,0 +1,209 @@
+// Package nodekey handles the identity keys of edge and candidate nodes, rsa and ed25519 keys are supported.
+// Ed25519 keys cut the signature size and the cpu cost on constrained edge hardware,
+// rsa keys are kept for the nodes registered before.
+package nodekey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+)
+
+const (
+	// TypeRSA rsa key with PKCS #1 v1.5 sha256 signatures
+	TypeRSA = "rsa"
+	// TypeEd25519 ed25519 key
+	TypeEd25519 = "ed25519"
+
+	// nodeIDHashLen the length of the hex of the key hash in the node id, the same as a uuid without dashes
+	nodeIDHashLen = 32
+
+	pkcs8PrivateKeyType = "PRIVATE KEY"
+	pkixPublicKeyType   = "PUBLIC KEY"
+)
+
+// Types the supported key types, the preferred type first
+var Types = []string{TypeEd25519, TypeRSA}
+
+// Generate generates a private key of the type, bits is only used by rsa keys
+func Generate(keyType string, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case TypeRSA:
+		return titanrsa.GeneratePrivateKey(bits)
+	case TypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", keyType)
+}
+
+// Type returns the type of the public key
+func Type(pub crypto.PublicKey) string {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return TypeRSA
+	case ed25519.PublicKey:
+		return TypeEd25519
+	}
+
+	return ""
+}
+
+// PrivateKey2Pem encodes the private key, rsa keys keep the PKCS #1 encoding of titanrsa
+func PrivateKey2Pem(key crypto.Signer) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return titanrsa.PrivateKey2Pem(k), nil
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pkcs8PrivateKeyType, Bytes: der}), nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key %T", key)
+}
+
+// Pem2PrivateKey decodes the private key
+func Pem2PrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key")
+	}
+
+	if block.Type != pkcs8PrivateKeyType {
+		return titanrsa.Pem2PrivateKey(data)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key, %s", err.Error())
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key %T", key)
+}
+
+// PublicKey2Pem encodes the public key, rsa keys keep the PKCS #1 encoding of titanrsa
+func PublicKey2Pem(pub crypto.PublicKey) ([]byte, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return titanrsa.PublicKey2Pem(k), nil
+	case ed25519.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pkixPublicKeyType, Bytes: der}), nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key %T", pub)
+}
+
+// Pem2PublicKey decodes the public key
+func Pem2PublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode public key")
+	}
+
+	if block.Type != pkixPublicKeyType {
+		return titanrsa.Pem2PublicKey(data)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key, %s", err.Error())
+	}
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return k, nil
+	case ed25519.PublicKey:
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key %T", pub)
+}
+
+// NodeID derives the node id from the public key, the prefix (e_ or c_) followed by the hex of the sha256 of
+// the PKIX encoding of the key, so a node registering the same key again always gets the same id
+func NodeID(prefix string, pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(der)
+	return prefix + hex.EncodeToString(sum[:])[:nodeIDHashLen], nil
+}
+
+// Sign signs the content, rsa keys sign the sha256 sum with PKCS #1 v1.5
+func Sign(key crypto.Signer, content []byte) ([]byte, error) {
+	if k, ok := key.(*rsa.PrivateKey); ok {
+		return titanrsa.New(crypto.SHA256, crypto.SHA256.New()).Sign(k, content)
+	}
+
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		return key.Sign(rand.Reader, content, crypto.Hash(0))
+	}
+
+	return nil, fmt.Errorf("unsupported private key %T", key)
+}
+
+// Verify verifies the signature of the content
+func Verify(pub crypto.PublicKey, sign, content []byte) error {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return titanrsa.New(crypto.SHA256, crypto.SHA256.New()).VerifySign(k, sign, content)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, content, sign) {
+			return fmt.Errorf("ed25519 verification error")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported public key %T", pub)
+}
+
+// Encrypt encrypts the message for the node. Ed25519 keys can not encrypt,
+// the message is returned as it is and must be protected by a signature
+func Encrypt(pub crypto.PublicKey, msg []byte) ([]byte, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return titanrsa.New(crypto.SHA256, crypto.SHA256.New()).Encrypt(msg, k)
+	case ed25519.PublicKey:
+		return msg, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key %T", pub)
+}
+
+// Decrypt decrypts the message of Encrypt
+func Decrypt(key crypto.Signer, ciphertext []byte) ([]byte, error) {
+	if k, ok := key.(*rsa.PrivateKey); ok {
+		return titanrsa.New(crypto.SHA256, crypto.SHA256.New()).Decrypt(ciphertext, k)
+	}
+
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		return ciphertext, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key %T", key)
+}
@@ -0,0 +1,107 @@
+package nodekey
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	for _, keyType := range Types {
+		key, err := Generate(keyType, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		privatePem, err := PrivateKey2Pem(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key, err = Pem2PrivateKey(privatePem); err != nil {
+			t.Fatal(err)
+		}
+
+		publicPem, err := PublicKey2Pem(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub, err := Pem2PublicKey(publicPem)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if Type(pub) != keyType {
+			t.Fatalf("expected key type %s, got %s", keyType, Type(pub))
+		}
+
+		sign, err := Sign(key, []byte("node id"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Verify(pub, sign, []byte("node id")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Verify(pub, sign, []byte("other")); err == nil {
+			t.Fatalf("%s: expected verification error", keyType)
+		}
+
+		ciphertext, err := Encrypt(pub, []byte("token payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := Decrypt(key, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(data, []byte("token payload")) {
+			t.Fatalf("%s: unexpected data %s", keyType, data)
+		}
+	}
+}
+
+func TestNodeID(t *testing.T) {
+	for _, keyType := range Types {
+		key, err := Generate(keyType, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		publicPem, err := PublicKey2Pem(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub, err := Pem2PublicKey(publicPem)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := NodeID("e_", key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the id is derived from the key, not from its encoding
+		if again, err := NodeID("e_", pub); err != nil || again != id {
+			t.Fatalf("%s: expected id %s, got %s", keyType, id, again)
+		}
+
+		if len(id) != len("e_")+nodeIDHashLen {
+			t.Fatalf("%s: unexpected id %s", keyType, id)
+		}
+
+		other, err := Generate(keyType, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if otherID, err := NodeID("e_", other.Public()); err != nil || otherID == id {
+			t.Fatalf("%s: expected a different id for another key", keyType)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// Client connects the edge to the relay designated by the scheduler and serves the relayed requests with the handler
+type Client struct {
+	scheduler api.Scheduler
+	handler   http.Handler
+	// the connections are upgraded over http/1.1, so tcp is always used
+	httpClient *http.Client
+}
+
+// NewClient creates a relay client of the edge
+func NewClient(scheduler api.Scheduler, handler http.Handler) *Client {
+	return &Client{scheduler: scheduler, handler: handler, httpClient: client.NewHTTPClient()}
+}
+
+// Run keeps the edge connected to its relay until the context is done,
+// the edge not behind symmetric nat gets no relay session and keeps asking in case its nat changes
+func (c *Client) Run(ctx context.Context) {
+	for {
+		session, err := c.scheduler.GetRelaySession(ctx)
+		if err != nil {
+			log.Warnf("GetRelaySession error %s", err.Error())
+		} else if session != nil {
+			if err = c.serve(ctx, session); err != nil {
+				log.Errorf("relay session %s error %s", session.ID, err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectInterval):
+		}
+	}
+}
+
+// connect opens a connection to the relay and upgrades it to the relay protocol
+func (c *Client) connect(ctx context.Context, session *types.RelaySession, path, streamID string) (io.ReadWriteCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s%s", session.CandidateAddress, path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", upgradeProtocol)
+	req.Header.Set(sessionHeader, session.ID)
+	req.Header.Set(keyHeader, session.Key)
+	if streamID != "" {
+		req.Header.Set(streamHeader, streamID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close() //nolint:errcheck // ignore error
+		return nil, fmt.Errorf("relay %s status %d", session.CandidateID, resp.StatusCode)
+	}
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close() //nolint:errcheck // ignore error
+		return nil, xerrors.New("upgraded connection is not writable")
+	}
+
+	return rwc, nil
+}
+
+// serve keeps the control connection and opens the streams requested by the relay
+func (c *Client) serve(ctx context.Context, session *types.RelaySession) error {
+	control, err := c.connect(ctx, session, controlPath, "")
+	if err != nil {
+		return err
+	}
+	defer control.Close() //nolint:errcheck // ignore error
+
+	go func() {
+		<-ctx.Done()
+		control.Close() //nolint:errcheck // ignore error
+	}()
+
+	log.Infof("connected to relay %s %s", session.CandidateID, session.CandidateAddress)
+
+	scanner := bufio.NewScanner(control)
+	for scanner.Scan() {
+		go c.serveStream(ctx, session, scanner.Text())
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	return xerrors.New("control connection closed by relay")
+}
+
+// serveStream opens the stream and serves the relayed requests on it until it is closed
+func (c *Client) serveStream(ctx context.Context, session *types.RelaySession, streamID string) {
+	rwc, err := c.connect(ctx, session, streamPath, streamID)
+	if err != nil {
+		log.Errorf("open relay stream %s error %s", streamID, err.Error())
+		return
+	}
+
+	l := newConnListener(&streamConn{ReadWriteCloser: rwc})
+	srv := &http.Server{
+		Handler:           c.handler,
+		ReadHeaderTimeout: 30 * time.Second,
+		IdleTimeout:       90 * time.Second,
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				l.Close() //nolint:errcheck // ignore error
+			}
+		},
+	}
+
+	if err = srv.Serve(l); err != nil && err != net.ErrClosed {
+		log.Debugf("relay stream %s closed: %s", streamID, err.Error())
+	}
+}
+
+// streamConn a stream connection upgraded from http
+type streamConn struct {
+	io.ReadWriteCloser
+}
+
+func (c *streamConn) LocalAddr() net.Addr                { return relayAddr{} }
+func (c *streamConn) RemoteAddr() net.Addr               { return relayAddr{} }
+func (c *streamConn) SetDeadline(t time.Time) error      { return nil }
+func (c *streamConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *streamConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// connListener accepts a single connection and is closed with the connection
+type connListener struct {
+	conn net.Conn
+	once sync.Once
+	done chan struct{}
+
+	lk       sync.Mutex
+	accepted bool
+}
+
+func newConnListener(conn net.Conn) *connListener {
+	return &connListener{conn: conn, done: make(chan struct{})}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	l.lk.Lock()
+	accepted := l.accepted
+	l.accepted = true
+	l.lk.Unlock()
+
+	if !accepted {
+		return l.conn, nil
+	}
+
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+func (l *connListener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return relayAddr{}
+}
@@ -0,0 +1,51 @@
+// Package relay relays the retrievals of the edges behind symmetric nat through candidates.
+//
+// The edge keeps a control connection to the candidate designated by the scheduler, the candidate
+// asks the edge to open a stream connection for each connection it needs to the edge, and reverse
+// proxies the requests of /relay/{edgeID}/ipfs/ to the edge over the streams. The connections are
+// opened by the edge over tcp and upgraded to raw connections, so no inbound connection is needed.
+package relay
+
+import (
+	"net"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("relay")
+
+const (
+	// PathPrefix the path prefix of the relayed requests, followed by the edge id
+	PathPrefix = "/relay/"
+
+	controlPath = PathPrefix + "control"
+	streamPath  = PathPrefix + "stream"
+
+	upgradeProtocol = "titan-relay"
+
+	sessionHeader = "Relay-Session"
+	keyHeader     = "Relay-Key"
+	streamHeader  = "Relay-Stream"
+
+	// time to wait the edge to open a requested stream
+	openStreamTimeout = 10 * time.Second
+	// interval to report the relayed bytes to the scheduler
+	reportTrafficInterval = time.Minute
+	// interval to request the relay session again after the control connection closed
+	reconnectInterval = 30 * time.Second
+)
+
+// Address returns the address the clients retrieve from the edge through the candidate with,
+// the clients request https://{address}/ipfs/{cid} as they do with the edge
+func Address(candidateAddress, edgeID string) string {
+	return candidateAddress + PathPrefix + edgeID
+}
+
+// relayAddr the address of the stream connections
+type relayAddr struct{}
+
+func (relayAddr) Network() string { return upgradeProtocol }
+func (relayAddr) String() string  { return upgradeProtocol }
+
+var _ net.Addr = relayAddr{}
@@ -0,0 +1,79 @@
+package relay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestRelay(t *testing.T) {
+	edge := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "relayed "+r.URL.Path) //nolint:errcheck // test
+	})
+
+	s := &Server{sessions: make(map[string]*session)}
+	candidate := httptest.NewUnstartedServer(s)
+	candidate.StartTLS()
+	defer candidate.Close()
+
+	info := &types.RelaySession{ID: "s1", EdgeID: "e_1", CandidateID: "c_1", Key: "key", CandidateAddress: strings.TrimPrefix(candidate.URL, "https://")}
+	if err := s.AddSession(info); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &Client{handler: edge, httpClient: candidate.Client()}
+	go c.serve(ctx, info) //nolint:errcheck // test
+
+	for i := 0; i < 100 && !s.getSession("e_1").connected(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := candidate.Client().Get("https://" + Address(info.CandidateAddress, info.EdgeID) + "/ipfs/cid")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close() //nolint:errcheck // test
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != "relayed /ipfs/cid" {
+			t.Fatalf("unexpected body %s", body)
+		}
+	}
+
+	if atomic.LoadInt64(&s.getSession("e_1").relayed) == 0 {
+		t.Fatal("relayed bytes are not counted")
+	}
+
+	// only the retrievals are relayed
+	resp, err := candidate.Client().Get("https://" + Address(info.CandidateAddress, info.EdgeID) + "/rpc/v0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint:errcheck // test
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+
+	// the edge with wrong key can not connect
+	bad := *info
+	bad.Key = "wrong"
+	if err = c.serve(ctx, &bad); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,366 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// Server relays the retrievals of the edges on the candidate
+type Server struct {
+	scheduler api.Scheduler
+
+	lk       sync.Mutex
+	sessions map[string]*session // by edge id
+	// traffics waiting to be reported
+	traffics []*types.RelayTraffic
+}
+
+// session a relay session with the control connection of the edge
+type session struct {
+	info  *types.RelaySession
+	proxy *httputil.ReverseProxy
+
+	lk      sync.Mutex
+	control net.Conn
+	pending map[string]chan net.Conn
+
+	// bytes relayed since the last report
+	relayed int64
+}
+
+// NewServer creates a relay server, the relayed bytes are reported to the scheduler
+func NewServer(scheduler api.Scheduler) *Server {
+	s := &Server{scheduler: scheduler, sessions: make(map[string]*session)}
+
+	go s.startReportTrafficTimer()
+
+	return s
+}
+
+// AddSession sets up the relay session designated by the scheduler, the previous session of the edge is closed
+func (s *Server) AddSession(info *types.RelaySession) error {
+	if info.ID == "" || info.EdgeID == "" || info.Key == "" {
+		return xerrors.New("invalid relay session")
+	}
+
+	ss := &session{info: info, pending: make(map[string]chan net.Conn)}
+	ss.proxy = &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			r.URL.Scheme = "http"
+			r.URL.Host = info.EdgeID
+		},
+		Transport: &http.Transport{
+			DialContext:         ss.openStream,
+			MaxIdleConnsPerHost: 16,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+
+	s.lk.Lock()
+	old := s.sessions[info.EdgeID]
+	s.sessions[info.EdgeID] = ss
+	if old != nil {
+		// keep the bytes of the old session to be reported
+		s.addTraffic(old)
+	}
+	s.lk.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+
+	log.Infof("add relay session %s of edge %s", info.ID, info.EdgeID)
+	return nil
+}
+
+func (s *Server) getSession(edgeID string) *session {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	return s.sessions[edgeID]
+}
+
+// ServeHTTP serves the connections of the edges and the relayed requests of the clients
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case controlPath:
+		s.serveControl(w, r)
+	case streamPath:
+		s.serveStream(w, r)
+	default:
+		s.serveRelay(w, r)
+	}
+}
+
+// authorize returns the session of the request of the edge
+func (s *Server) authorize(r *http.Request) (*session, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), upgradeProtocol) {
+		return nil, fmt.Errorf("upgrade to %s is required", upgradeProtocol)
+	}
+
+	sessionID := r.Header.Get(sessionHeader)
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	for _, ss := range s.sessions {
+		if ss.info.ID != sessionID {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(ss.info.Key), []byte(r.Header.Get(keyHeader))) != 1 {
+			break
+		}
+		return ss, nil
+	}
+
+	return nil, fmt.Errorf("relay session %s not exist", sessionID)
+}
+
+// upgrade hijacks the connection of the edge and switches it to the relay protocol
+func upgrade(w http.ResponseWriter) (net.Conn, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, xerrors.New("connection can not be hijacked")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	if rw.Reader.Buffered() > 0 {
+		conn.Close() //nolint:errcheck // ignore error
+		return nil, xerrors.New("unexpected data before upgrade")
+	}
+
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: %s\r\n\r\n", upgradeProtocol)
+	if err != nil {
+		conn.Close() //nolint:errcheck // ignore error
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// serveControl keeps the control connection of the edge until it is closed
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request) {
+	ss, err := s.authorize(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := upgrade(w)
+	if err != nil {
+		log.Errorf("upgrade control connection of edge %s error %s", ss.info.EdgeID, err.Error())
+		return
+	}
+
+	ss.lk.Lock()
+	old := ss.control
+	ss.control = conn
+	ss.lk.Unlock()
+
+	if old != nil {
+		old.Close() //nolint:errcheck // ignore error
+	}
+
+	log.Infof("edge %s connected to relay session %s", ss.info.EdgeID, ss.info.ID)
+
+	// the edge sends nothing on the control connection, read until it is closed
+	_, err = bufio.NewReader(conn).ReadByte()
+	log.Infof("edge %s disconnected from relay session %s: %v", ss.info.EdgeID, ss.info.ID, err)
+
+	ss.lk.Lock()
+	if ss.control == conn {
+		ss.control = nil
+	}
+	ss.lk.Unlock()
+
+	conn.Close() //nolint:errcheck // ignore error
+}
+
+// serveStream hands the stream connection opened by the edge to the waiting request
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	ss, err := s.authorize(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	streamID := r.Header.Get(streamHeader)
+
+	ss.lk.Lock()
+	ch, ok := ss.pending[streamID]
+	delete(ss.pending, streamID)
+	ss.lk.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("stream %s not requested", streamID), http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgrade(w)
+	if err != nil {
+		log.Errorf("upgrade stream connection of edge %s error %s", ss.info.EdgeID, err.Error())
+		return
+	}
+
+	// the channel is buffered and only written once
+	ch <- &countConn{Conn: conn, counter: &ss.relayed}
+}
+
+// serveRelay reverse proxies the retrieval of the client to the edge
+func (s *Server) serveRelay(w http.ResponseWriter, r *http.Request) {
+	edgeID, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, PathPrefix), "/")
+	rest = "/" + rest
+
+	// only the retrievals are relayed
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !strings.HasPrefix(rest, "/ipfs/") {
+		http.Error(w, fmt.Sprintf("can not relay %s %s", r.Method, rest), http.StatusForbidden)
+		return
+	}
+
+	ss := s.getSession(edgeID)
+	if ss == nil || !ss.connected() {
+		http.Error(w, fmt.Sprintf("edge %s is not connected", edgeID), http.StatusBadGateway)
+		return
+	}
+
+	r.URL.Path = rest
+	r.URL.RawPath = ""
+	ss.proxy.ServeHTTP(w, r)
+}
+
+func (ss *session) connected() bool {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	return ss.control != nil
+}
+
+// openStream asks the edge to open a stream connection and waits for it
+func (ss *session) openStream(ctx context.Context, _, _ string) (net.Conn, error) {
+	streamID := uuid.NewString()
+	ch := make(chan net.Conn, 1)
+
+	ss.lk.Lock()
+	control := ss.control
+	if control != nil {
+		ss.pending[streamID] = ch
+	}
+	ss.lk.Unlock()
+
+	if control == nil {
+		return nil, fmt.Errorf("edge %s is not connected", ss.info.EdgeID)
+	}
+
+	defer func() {
+		ss.lk.Lock()
+		delete(ss.pending, streamID)
+		ss.lk.Unlock()
+
+		// close the stream arrived after the timeout
+		select {
+		case conn := <-ch:
+			conn.Close() //nolint:errcheck // ignore error
+		default:
+		}
+	}()
+
+	if _, err := fmt.Fprintf(control, "%s\n", streamID); err != nil {
+		return nil, xerrors.Errorf("request stream from edge %s: %w", ss.info.EdgeID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, openStreamTimeout)
+	defer cancel()
+
+	select {
+	case conn := <-ch:
+		return conn, nil
+	case <-ctx.Done():
+		return nil, xerrors.Errorf("wait stream from edge %s: %w", ss.info.EdgeID, ctx.Err())
+	}
+}
+
+func (ss *session) close() {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	if ss.control != nil {
+		ss.control.Close() //nolint:errcheck // ignore error
+		ss.control = nil
+	}
+}
+
+// countConn counts the bytes relayed in both directions
+type countConn struct {
+	net.Conn
+	counter *int64
+}
+
+func (c *countConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	atomic.AddInt64(c.counter, int64(n))
+	return n, err
+}
+
+func (c *countConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	atomic.AddInt64(c.counter, int64(n))
+	return n, err
+}
+
+// addTraffic moves the relayed bytes of the session to the traffics waiting to be reported, the caller holds the lock
+func (s *Server) addTraffic(ss *session) {
+	if bytes := atomic.SwapInt64(&ss.relayed, 0); bytes > 0 {
+		s.traffics = append(s.traffics, &types.RelayTraffic{SessionID: ss.info.ID, EdgeID: ss.info.EdgeID, Bytes: bytes})
+	}
+}
+
+func (s *Server) startReportTrafficTimer() {
+	ticker := time.NewTicker(reportTrafficInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.reportTraffic()
+	}
+}
+
+// reportTraffic reports the relayed bytes of the sessions to the scheduler, the bytes are reported again on failure
+func (s *Server) reportTraffic() {
+	s.lk.Lock()
+	for _, ss := range s.sessions {
+		s.addTraffic(ss)
+	}
+	traffics := s.traffics
+	s.traffics = nil
+	s.lk.Unlock()
+
+	if len(traffics) == 0 {
+		return
+	}
+
+	if err := s.scheduler.SubmitRelayTraffic(context.Background(), traffics); err != nil {
+		log.Errorf("SubmitRelayTraffic error %s", err.Error())
+
+		s.lk.Lock()
+		s.traffics = append(traffics, s.traffics...)
+		s.lk.Unlock()
+	}
+}
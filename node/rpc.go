@@ -13,6 +13,9 @@ import (
 	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/metrics/proxy"
 	mhandler "github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/Filecoin-Titan/titan/node/locator"
+	"github.com/Filecoin-Titan/titan/node/scheduler/restapi"
+	"github.com/Filecoin-Titan/titan/node/scheduler/webui"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/gorilla/mux"
 	logging "github.com/ipfs/go-log/v2"
@@ -56,7 +59,8 @@ func ServeRPC(h http.Handler, id string, addr string) (StopFunc, error) {
 }
 
 // SchedulerHandler returns a scheduler handler, to be mounted as-is on the server.
-func SchedulerHandler(a api.Scheduler, permission bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
+// The web dashboard is served if webUI is true.
+func SchedulerHandler(a api.Scheduler, permission bool, webUI bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
 	m := mux.NewRouter()
 	readerHandler, readerServerOpt := rpcenc.ReaderParamDecoder()
 	opts = append(opts, readerServerOpt)
@@ -80,19 +84,54 @@ func SchedulerHandler(a api.Scheduler, permission bool, opts ...jsonrpc.ServerOp
 
 	serveRPC("/rpc/v0", fnapi)
 
-	// debugging
-	m.Handle("/debug/metrics", metrics.Exporter())
-	m.Handle("/debug/pprof-set/mutex", handleFractionOpt("MutexProfileFraction", func(x int) {
+	// read-only rest api, shares the permissions of json-rpc
+	restRouter := mux.NewRouter()
+	restapi.New(fnapi).Register(restRouter)
+
+	var restHandler http.Handler = restRouter
+	if permission {
+		restHandler = mhandler.New(a.AuthVerify, restRouter.ServeHTTP)
+	}
+	m.PathPrefix(restapi.PathPrefix).Handler(restHandler)
+
+	// the static files of the dashboard are public, the data is read from the rest api with the token of the operator
+	if webUI {
+		m.PathPrefix(webui.PathPrefix).Handler(webui.Handler())
+	}
+
+	// debugging, pprof is only served to admin
+	var mutexHandler http.Handler = handleFractionOpt("MutexProfileFraction", func(x int) {
 		runtime.SetMutexProfileFraction(x)
-	}))
+	})
+	var pprofHandler http.Handler = http.DefaultServeMux
+	if permission {
+		mutexHandler = mhandler.New(a.AuthVerify, adminOnly(mutexHandler))
+		pprofHandler = mhandler.New(a.AuthVerify, adminOnly(pprofHandler))
+	}
+
+	m.Handle("/debug/metrics", metrics.Exporter())
+	m.Handle("/debug/pprof-set/mutex", mutexHandler)
 	m.Handle("/rpc/streams/v0/push/{uuid}", readerHandler)
-	m.PathPrefix("/").Handler(http.DefaultServeMux) // pprof
+	m.PathPrefix("/").Handler(pprofHandler) // pprof
 
 	return m, nil
 }
 
+// adminOnly serves the handler to the admin callers only, the permissions of the caller are set by the auth handler
+func adminOnly(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !api.HasPerm(r.Context(), api.RoleDefault, api.RoleAdmin) {
+			http.Error(w, "permission denied", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
 // LocatorHandler returns a locator handler, to be mounted as-is on the server.
-func LocatorHandler(a api.Locator, permission bool) (http.Handler, error) {
+// The ipfs gateway is public, it is served without permission.
+func LocatorHandler(a api.Locator, permission bool, gatewayRedirect bool, gatewayRateLimit int) (http.Handler, error) {
 	mapi := proxy.MetricedLocatorAPI(a)
 	if permission {
 		mapi = api.PermissionedLocationAPI(mapi)
@@ -103,6 +142,7 @@ func LocatorHandler(a api.Locator, permission bool) (http.Handler, error) {
 	rpcServer.Register("titan", mapi)
 
 	rootMux := mux.NewRouter()
+	rootMux.PathPrefix(locator.GatewayPathPrefix).Handler(locator.NewGateway(mapi, gatewayRedirect, gatewayRateLimit))
 
 	// local APIs
 	{
@@ -0,0 +1,318 @@
+package rtc
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/limiter"
+	"github.com/Filecoin-Titan/titan/node/asset"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/pion/webrtc/v3"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("rtc")
+
+const (
+	// the sessions served at once
+	maxSessions = 32
+	// the answer waits for the ice candidates of the edge to be gathered for the timeout
+	gatherTimeout = 10 * time.Second
+	// the session is closed if the browser requests no block for the timeout
+	idleTimeout = time.Minute
+	// the session is closed after the lifetime, the browser offers again to continue
+	sessionLifetime = 30 * time.Minute
+	checkInterval   = 10 * time.Second
+	// the payload of a frame, the browsers receive the messages of the size without fragmentation
+	framePayloadSize = 16 << 10
+	frameHeaderSize  = 5
+	// the frames are not sent while the data channel buffers more than the amount
+	maxBufferedAmount = 1 << 20
+	lowBufferedAmount = 256 << 10
+	// the requests queued in a session, the more requests are refused
+	maxQueuedRequests = 64
+)
+
+// Server serves the blocks of the assets to the browsers over webrtc data channels, the offers of the browsers
+// are relayed by the scheduler, which reaches the edges behind nat without a public http address
+type Server struct {
+	assetMgr *asset.Manager
+	shaper   *limiter.Shaper
+
+	lk       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewServer return new webrtc server instance
+func NewServer(assetMgr *asset.Manager, shaper *limiter.Shaper) *Server {
+	return &Server{
+		assetMgr: assetMgr,
+		shaper:   shaper,
+		sessions: make(map[string]*session),
+	}
+}
+
+// Offer answers the offer of a browser relayed by the scheduler, the data channels the browser opens
+// in the session serve the blocks of the asset of the offer
+func (s *Server) Offer(ctx context.Context, offer *types.WebRTCOffer) (*types.WebRTCAnswer, error) {
+	if time.Now().After(offer.Expiration) {
+		return nil, xerrors.Errorf("offer %s expired", offer.SessionID)
+	}
+
+	root, err := cid.Decode(offer.AssetCID)
+	if err != nil {
+		return nil, xerrors.Errorf("decode asset cid %s err:%s", offer.AssetCID, err.Error())
+	}
+
+	if exists, err := s.assetMgr.AssetExists(root); err != nil || !exists {
+		return nil, xerrors.Errorf("asset %s not found", offer.AssetCID)
+	}
+
+	s.lk.Lock()
+	if len(s.sessions) >= maxSessions {
+		s.lk.Unlock()
+		return nil, xerrors.Errorf("sessions exceed the limit %d", maxSessions)
+	}
+	s.lk.Unlock()
+
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{ICEServers: iceServers(offer.ICEServers)})
+	if err != nil {
+		return nil, err
+	}
+
+	sess := newSession(s, offer.SessionID, root, pc)
+	pc.OnDataChannel(sess.addChannel)
+	pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		switch state {
+		case webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed, webrtc.PeerConnectionStateDisconnected:
+			sess.close()
+		}
+	})
+
+	answer, err := answer(ctx, pc, offer.SDP)
+	if err != nil {
+		pc.Close() //nolint:errcheck // ignore error
+		return nil, err
+	}
+
+	s.lk.Lock()
+	s.sessions[sess.id] = sess
+	s.lk.Unlock()
+
+	go sess.run()
+
+	return &types.WebRTCAnswer{SessionID: offer.SessionID, SDP: answer}, nil
+}
+
+// answer sets the offer on the peer connection and returns the answer with the gathered ice candidates
+func answer(ctx context.Context, pc *webrtc.PeerConnection, sdp string) (string, error) {
+	if err := pc.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: sdp}); err != nil {
+		return "", xerrors.Errorf("set offer err:%s", err.Error())
+	}
+
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		return "", err
+	}
+
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(answer); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gatherTimeout)
+	defer cancel()
+
+	select {
+	case <-gathered:
+	case <-ctx.Done():
+		return "", xerrors.New("gather ice candidates timeout")
+	}
+
+	return pc.LocalDescription().SDP, nil
+}
+
+func iceServers(servers []*types.WebRTCICEServer) []webrtc.ICEServer {
+	out := make([]webrtc.ICEServer, 0, len(servers))
+	for _, s := range servers {
+		server := webrtc.ICEServer{URLs: s.URLs}
+		if s.Username != "" {
+			server.Username = s.Username
+			server.Credential = s.Credential
+		}
+		out = append(out, server)
+	}
+	return out
+}
+
+func (s *Server) removeSession(id string) {
+	s.lk.Lock()
+	delete(s.sessions, id)
+	s.lk.Unlock()
+}
+
+type session struct {
+	server *Server
+	id     string
+	root   cid.Cid
+	pc     *webrtc.PeerConnection
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	requests   chan *request
+	activeTime int64
+	bytes      int64
+}
+
+type request struct {
+	dc *webrtc.DataChannel
+	*types.WebRTCBlockReq
+	// the buffered amount of the data channel dropped below the threshold
+	low chan struct{}
+}
+
+func newSession(server *Server, id string, root cid.Cid, pc *webrtc.PeerConnection) *session {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionLifetime)
+	return &session{
+		server:     server,
+		id:         id,
+		root:       root,
+		pc:         pc,
+		ctx:        ctx,
+		cancel:     cancel,
+		requests:   make(chan *request, maxQueuedRequests),
+		activeTime: time.Now().Unix(),
+	}
+}
+
+// addChannel receives the block requests of the data channel opened by the browser
+func (sess *session) addChannel(dc *webrtc.DataChannel) {
+	low := make(chan struct{}, 1)
+	dc.SetBufferedAmountLowThreshold(lowBufferedAmount)
+	dc.OnBufferedAmountLow(func() {
+		select {
+		case low <- struct{}{}:
+		default:
+		}
+	})
+
+	dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+		atomic.StoreInt64(&sess.activeTime, time.Now().Unix())
+
+		req := &types.WebRTCBlockReq{}
+		if !msg.IsString || json.Unmarshal(msg.Data, req) != nil {
+			log.Debugf("session %s invalid request", sess.id)
+			return
+		}
+
+		select {
+		case sess.requests <- &request{dc: dc, WebRTCBlockReq: req, low: low}:
+		default:
+			dc.Send(encodeFrame(req.ID, types.WebRTCFrameError, []byte("too many requests"))) //nolint:errcheck // ignore error
+		}
+	})
+}
+
+// run serves the requests of the session until the session is idle, expires or its connection closes
+func (sess *session) run() {
+	defer sess.close()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case req := <-sess.requests:
+			if err := sess.serve(req); err != nil {
+				log.Debugf("session %s serve block %s err:%s", sess.id, req.CID, err.Error())
+			}
+		case <-ticker.C:
+			if time.Since(time.Unix(atomic.LoadInt64(&sess.activeTime), 0)) > idleTimeout {
+				return
+			}
+		case <-sess.ctx.Done():
+			return
+		}
+	}
+}
+
+// serve sends the block of the request in frames, the failed requests are answered with an error frame
+func (sess *session) serve(req *request) error {
+	c, err := cid.Decode(req.CID)
+	if err != nil {
+		return req.dc.Send(encodeFrame(req.ID, types.WebRTCFrameError, []byte("invalid cid")))
+	}
+
+	block, err := sess.server.assetMgr.GetBlock(sess.ctx, sess.root, c)
+	if err != nil {
+		return req.dc.Send(encodeFrame(req.ID, types.WebRTCFrameError, []byte("block not found")))
+	}
+
+	for _, frame := range splitFrames(req.ID, block.RawData()) {
+		if err := sess.waitBuffer(req); err != nil {
+			return err
+		}
+
+		if sess.server.shaper != nil {
+			if err := sess.server.shaper.WaitN(sess.ctx, len(frame)); err != nil {
+				return err
+			}
+		}
+
+		if err := req.dc.Send(frame); err != nil {
+			return err
+		}
+	}
+
+	atomic.AddInt64(&sess.bytes, int64(len(block.RawData())))
+	return nil
+}
+
+// waitBuffer waits for the data channel to send the buffered frames
+func (sess *session) waitBuffer(req *request) error {
+	for req.dc.BufferedAmount() > maxBufferedAmount {
+		select {
+		case <-req.low:
+		case <-time.After(checkInterval):
+		case <-sess.ctx.Done():
+			return sess.ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (sess *session) close() {
+	sess.cancel()
+	sess.server.removeSession(sess.id)
+	if err := sess.pc.Close(); err != nil {
+		log.Debugf("close session %s err:%s", sess.id, err.Error())
+	}
+}
+
+// encodeFrame returns the frame of the payload of the request
+func encodeFrame(id uint32, flags byte, payload []byte) []byte {
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame, id)
+	frame[4] = flags
+	copy(frame[frameHeaderSize:], payload)
+	return frame
+}
+
+// splitFrames returns the frames of the block, the last frame is flagged
+func splitFrames(id uint32, data []byte) [][]byte {
+	frames := make([][]byte, 0, len(data)/framePayloadSize+1)
+	for start := 0; ; start += framePayloadSize {
+		end := start + framePayloadSize
+		if end >= len(data) {
+			return append(frames, encodeFrame(id, types.WebRTCFrameLast, data[start:]))
+		}
+		frames = append(frames, encodeFrame(id, 0, data[start:end]))
+	}
+}
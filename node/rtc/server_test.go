@@ -0,0 +1,39 @@
+package rtc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestSplitFrames(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 2*framePayloadSize+10)
+
+	frames := splitFrames(7, data)
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(frames))
+	}
+
+	var payload []byte
+	for i, frame := range frames {
+		if id := binary.BigEndian.Uint32(frame); id != 7 {
+			t.Fatalf("frame %d: expected id 7, got %d", i, id)
+		}
+
+		last := frame[4]&types.WebRTCFrameLast != 0
+		if last != (i == len(frames)-1) {
+			t.Fatalf("frame %d: unexpected last flag %v", i, last)
+		}
+		payload = append(payload, frame[frameHeaderSize:]...)
+	}
+
+	if !bytes.Equal(payload, data) {
+		t.Fatal("the payload of the frames differs from the data")
+	}
+
+	if frames := splitFrames(1, nil); len(frames) != 1 || len(frames[0]) != frameHeaderSize || frames[0][4] != types.WebRTCFrameLast {
+		t.Fatalf("expected a single empty last frame, got %v", frames)
+	}
+}
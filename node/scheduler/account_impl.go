@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// BindNodeToAccount binds the node to the operator account
+func (s *Scheduler) BindNodeToAccount(ctx context.Context, accountID, nodeID string) error {
+	if accountID == "" {
+		return xerrors.New("account id can not empty")
+	}
+
+	if _, err := s.NodeManager.LoadNodeType(nodeID); err != nil {
+		return xerrors.Errorf("load node %s type err:%s", nodeID, err.Error())
+	}
+
+	boundID, err := s.NodeManager.LoadAccountOfNode(nodeID)
+	if err != nil && err != sql.ErrNoRows {
+		return xerrors.Errorf("LoadAccountOfNode %s err:%s", nodeID, err.Error())
+	}
+
+	if boundID == accountID {
+		return nil
+	}
+
+	if boundID != "" {
+		return xerrors.Errorf("node %s has been bound to other account", nodeID)
+	}
+
+	return s.NodeManager.SaveAccountNode(accountID, nodeID)
+}
+
+// UnbindNodeFromAccount unbinds the node from the operator account
+func (s *Scheduler) UnbindNodeFromAccount(ctx context.Context, accountID, nodeID string) error {
+	return s.NodeManager.DeleteAccountNode(accountID, nodeID)
+}
+
+// GetAccountNodes get the nodes bound to the account
+func (s *Scheduler) GetAccountNodes(ctx context.Context, accountID string) ([]*types.AccountNode, error) {
+	return s.NodeManager.LoadAccountNodes(accountID)
+}
+
+// GetAccountStats get the aggregated statistics of the nodes bound to the account
+func (s *Scheduler) GetAccountStats(ctx context.Context, accountID string) (*types.AccountStats, error) {
+	if err := s.OverloadManager.Admit(ctx, "GetAccountStats"); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.NodeManager.LoadAccountStats(accountID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAccountStats %s err:%s", accountID, err.Error())
+	}
+
+	nodes, err := s.NodeManager.LoadAccountNodes(accountID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAccountNodes %s err:%s", accountID, err.Error())
+	}
+
+	for _, n := range nodes {
+		if s.NodeManager.GetNode(n.NodeID) != nil {
+			stats.OnlineNodeCount++
+		}
+	}
+
+	return stats, nil
+}
+
+// SetAccountNotificationPrefs set the notification preferences of the account
+func (s *Scheduler) SetAccountNotificationPrefs(ctx context.Context, prefs *types.AccountNotificationPrefs) error {
+	if prefs == nil || prefs.AccountID == "" {
+		return xerrors.New("account id can not empty")
+	}
+
+	if prefs.OfflineMinutes < 0 {
+		return xerrors.Errorf("invalid offline minutes %d", prefs.OfflineMinutes)
+	}
+
+	return s.NodeManager.SaveAccountNotificationPrefs(prefs)
+}
+
+// GetAccountNotificationPrefs get the notification preferences of the account
+func (s *Scheduler) GetAccountNotificationPrefs(ctx context.Context, accountID string) (*types.AccountNotificationPrefs, error) {
+	prefs, err := s.NodeManager.LoadAccountNotificationPrefs(accountID)
+	if err == sql.ErrNoRows {
+		return &types.AccountNotificationPrefs{AccountID: accountID}, nil
+	}
+
+	return prefs, err
+}
+
+// AddAlertSubscription subscribes the account to node offline, validation failure or disk usage alerts
+func (s *Scheduler) AddAlertSubscription(ctx context.Context, sub *types.AlertSubscription) (int64, error) {
+	if sub == nil {
+		return 0, xerrors.New("subscription can not empty")
+	}
+
+	return s.AlertManager.AddSubscription(sub)
+}
+
+// RemoveAlertSubscription removes the alert subscription of the account
+func (s *Scheduler) RemoveAlertSubscription(ctx context.Context, accountID string, id int64) error {
+	return s.AlertManager.DeleteAlertSubscription(accountID, id)
+}
+
+// GetAlertSubscriptions get the alert subscriptions of the account
+func (s *Scheduler) GetAlertSubscriptions(ctx context.Context, accountID string) ([]*types.AlertSubscription, error) {
+	return s.AlertManager.LoadAlertSubscriptions(accountID)
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"golang.org/x/xerrors"
+)
+
+// SetAssetACL makes the asset of the user private, only the owners and the users and api keys in the list can download it
+func (s *Scheduler) SetAssetACL(ctx context.Context, userID string, acl *types.AssetACL) error {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	if acl == nil {
+		return xerrors.New("acl is nil")
+	}
+
+	return s.newUser(userID).SetAssetACL(acl)
+}
+
+// RemoveAssetACL makes the asset of the user public
+func (s *Scheduler) RemoveAssetACL(ctx context.Context, userID, assetCID string) error {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	return s.newUser(userID).RemoveAssetACL(assetCID)
+}
+
+// GetAssetACL retrieves the access control list of the asset of the user, nil if the asset is public
+func (s *Scheduler) GetAssetACL(ctx context.Context, userID, assetCID string) (*types.AssetACL, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	return s.newUser(userID).GetAssetACL(assetCID)
+}
+
+// CheckAssetAccess checks whether the user or the api key of the user can download the asset,
+// the nodes check the requests that do not carry a token issued by the scheduler, userID is empty for anonymous requests
+func (s *Scheduler) CheckAssetAccess(ctx context.Context, cid, userID, apiKeyName string) (bool, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return false, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	return s.assetAccessAllowed(hash, userID, apiKeyName)
+}
+
+// checkAssetAccess refuses to issue download tokens of the private asset to the callers not in its acl,
+// the nodes and the web and admin are trusted
+func (s *Scheduler) checkAssetAccess(ctx context.Context, hash, cid string) error {
+	for _, role := range []auth.Permission{api.RoleAdmin, api.RoleWeb, api.RoleEdge, api.RoleCandidate} {
+		if api.HasPerm(ctx, api.RoleDefault, role) {
+			return nil
+		}
+	}
+
+	allowed, err := s.assetAccessAllowed(hash, handler.GetUserID(ctx), handler.GetAPIKeyName(ctx))
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if !allowed {
+		return &api.ErrWeb{Code: terrors.AssetAccessDenied.Int(), Message: fmt.Sprintf("the asset %s is private", cid)}
+	}
+	return nil
+}
+
+// assetAccessAllowed returns true if the asset is public, or the user is an owner of the asset,
+// or the user or the api key is in the acl of an owner. The asset is public while any of its owners keeps it public
+func (s *Scheduler) assetAccessAllowed(hash, userID, apiKeyName string) (bool, error) {
+	acls, err := s.db.LoadAssetACLs(hash)
+	if err != nil {
+		return false, err
+	}
+
+	if len(acls) == 0 {
+		return true, nil
+	}
+
+	owners, err := s.db.ListUsersForAsset(hash)
+	if err != nil {
+		return false, err
+	}
+
+	for _, owner := range owners {
+		if _, ok := acls[owner]; !ok {
+			return true, nil
+		}
+	}
+
+	if userID == "" {
+		return false, nil
+	}
+
+	if _, ok := acls[userID]; ok {
+		return true, nil
+	}
+
+	apiKey := fmt.Sprintf("%s/%s", userID, apiKeyName)
+	for _, acl := range acls {
+		for _, user := range acl.Users {
+			if user == userID {
+				return true, nil
+			}
+		}
+
+		if apiKeyName == "" {
+			continue
+		}
+
+		for _, key := range acl.APIKeys {
+			if key == apiKey {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
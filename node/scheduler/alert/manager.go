@@ -0,0 +1,319 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("alert")
+
+const (
+	checkAlertInterval = time.Minute
+	webhookTimeout     = 10 * time.Second
+
+	// Maximum number of consecutive validation failures that can be subscribed
+	maxValidationFailures = 100
+)
+
+// Manager checks the alert subscriptions and notifies the accounts by webhook or email
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	nodeMgr       *node.Manager
+	*db.SQLDB
+
+	httpClient *http.Client
+
+	// alerts that have been fired, an alert is fired again only after its condition clears
+	firedLock sync.Mutex
+	fired     map[string]struct{}
+}
+
+// NewManager return new alert manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager, nmgr *node.Manager) *Manager {
+	manager := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		nodeMgr:       nmgr,
+		SQLDB:         sdb,
+		httpClient:    &http.Client{Timeout: webhookTimeout},
+		fired:         make(map[string]struct{}),
+	}
+
+	go manager.startCheckAlertsTimer()
+
+	return manager
+}
+
+// AddSubscription adds an alert subscription for the account
+func (m *Manager) AddSubscription(sub *types.AlertSubscription) (int64, error) {
+	if sub.AccountID == "" {
+		return 0, xerrors.New("account id can not empty")
+	}
+
+	if sub.Webhook == "" && sub.Email == "" {
+		return 0, xerrors.New("webhook and email can not both be empty")
+	}
+
+	if sub.OfflineMinutes <= 0 && sub.ValidationFailures <= 0 && sub.DiskUsageThreshold <= 0 && !sub.DiskFailure {
+		return 0, xerrors.New("no alert condition is set")
+	}
+
+	if sub.OfflineMinutes < 0 || sub.ValidationFailures < 0 || sub.ValidationFailures > maxValidationFailures {
+		return 0, xerrors.Errorf("invalid alert condition")
+	}
+
+	if sub.DiskUsageThreshold < 0 || sub.DiskUsageThreshold > 100 {
+		return 0, xerrors.Errorf("invalid disk usage threshold %.2f", sub.DiskUsageThreshold)
+	}
+
+	if sub.NodeID != "" {
+		accountID, err := m.LoadAccountOfNode(sub.NodeID)
+		if err != nil || accountID != sub.AccountID {
+			return 0, xerrors.Errorf("node %s is not bound to account %s", sub.NodeID, sub.AccountID)
+		}
+	}
+
+	return m.SaveAlertSubscription(sub)
+}
+
+func (m *Manager) startCheckAlertsTimer() {
+	ticker := time.NewTicker(checkAlertInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		if !m.leadershipMgr.RequestAndBecomeMaster() {
+			continue
+		}
+
+		m.checkAlerts()
+	}
+}
+
+// checkAlerts checks all subscriptions, the offline alert preferences of the accounts are checked as subscriptions too
+func (m *Manager) checkAlerts() {
+	subs, err := m.LoadAllAlertSubscriptions()
+	if err != nil {
+		log.Errorf("LoadAllAlertSubscriptions err:%s", err.Error())
+		return
+	}
+
+	prefs, err := m.LoadOfflineAlertPrefs()
+	if err != nil {
+		log.Errorf("LoadOfflineAlertPrefs err:%s", err.Error())
+	}
+
+	for _, pref := range prefs {
+		subs = append(subs, &types.AlertSubscription{
+			AccountID:      pref.AccountID,
+			OfflineMinutes: pref.OfflineMinutes,
+			Webhook:        pref.Webhook,
+			Email:          pref.Email,
+		})
+	}
+
+	for _, sub := range subs {
+		nodeIDs, err := m.subscribedNodes(sub)
+		if err != nil {
+			log.Errorf("load subscribed nodes of %s err:%s", sub.AccountID, err.Error())
+			continue
+		}
+
+		for _, nodeID := range nodeIDs {
+			m.checkNode(sub, nodeID)
+		}
+	}
+}
+
+// subscribedNodes returns the nodes of the subscription
+func (m *Manager) subscribedNodes(sub *types.AlertSubscription) ([]string, error) {
+	if sub.NodeID != "" {
+		return []string{sub.NodeID}, nil
+	}
+
+	nodes, err := m.LoadAccountNodes(sub.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeIDs := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		nodeIDs = append(nodeIDs, n.NodeID)
+	}
+
+	return nodeIDs, nil
+}
+
+func (m *Manager) checkNode(sub *types.AlertSubscription, nodeID string) {
+	cNode := m.nodeMgr.GetNode(nodeID)
+
+	if sub.OfflineMinutes > 0 {
+		offline, err := m.isOffline(cNode, nodeID, sub.OfflineMinutes)
+		if err != nil {
+			log.Errorf("check node %s offline err:%s", nodeID, err.Error())
+		} else {
+			m.updateAlert(sub, nodeID, types.AlertTypeNodeOffline, offline,
+				fmt.Sprintf("node %s has been offline for more than %d minutes", nodeID, sub.OfflineMinutes))
+		}
+	}
+
+	if sub.ValidationFailures > 0 {
+		failed, err := m.isValidationFailed(nodeID, sub.ValidationFailures)
+		if err != nil {
+			log.Errorf("check node %s validation err:%s", nodeID, err.Error())
+		} else {
+			m.updateAlert(sub, nodeID, types.AlertTypeValidationFailed, failed,
+				fmt.Sprintf("node %s failed %d validations in a row", nodeID, sub.ValidationFailures))
+		}
+	}
+
+	if sub.DiskUsageThreshold > 0 && cNode != nil {
+		m.updateAlert(sub, nodeID, types.AlertTypeDiskUsage, cNode.DiskUsage >= sub.DiskUsageThreshold,
+			fmt.Sprintf("disk usage of node %s is %.2f%%, crossed the threshold %.2f%%", nodeID, cNode.DiskUsage, sub.DiskUsageThreshold))
+	}
+
+	if sub.DiskFailure && cNode != nil {
+		health, failure := cNode.DiskHealth()
+		device := ""
+		if health != nil {
+			device = health.Device
+		}
+
+		m.updateAlert(sub, nodeID, types.AlertTypeDiskFailure, failure != "",
+			fmt.Sprintf("disk %s of node %s is predicted to fail: %s, its replicas are migrated to other nodes", device, nodeID, failure))
+	}
+}
+
+func (m *Manager) isOffline(cNode *node.Node, nodeID string, minutes int) (bool, error) {
+	if cNode != nil {
+		return false, nil
+	}
+
+	lastSeen, err := m.LoadNodeLastSeenTime(nodeID)
+	if err != nil {
+		return false, err
+	}
+
+	return time.Since(lastSeen) > time.Duration(minutes)*time.Minute, nil
+}
+
+func (m *Manager) isValidationFailed(nodeID string, count int) (bool, error) {
+	statuses, err := m.LoadLatestValidationStatuses(nodeID, count)
+	if err != nil {
+		return false, err
+	}
+
+	if len(statuses) < count {
+		return false, nil
+	}
+
+	for _, status := range statuses {
+		if status != types.ValidationStatusNodeTimeOut && status != types.ValidationStatusValidateFail && status != types.ValidationStatusNodeOffline {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// updateAlert fires the alert when its condition is met the first time, and clears it when the condition is gone
+func (m *Manager) updateAlert(sub *types.AlertSubscription, nodeID string, alertType types.AlertType, active bool, message string) {
+	key := fmt.Sprintf("%d:%s:%s:%s", sub.ID, sub.AccountID, nodeID, alertType)
+
+	m.firedLock.Lock()
+	_, exist := m.fired[key]
+	if active {
+		m.fired[key] = struct{}{}
+	} else {
+		delete(m.fired, key)
+	}
+	m.firedLock.Unlock()
+
+	if !active || exist {
+		return
+	}
+
+	event := &types.AlertEvent{
+		Type:      alertType,
+		AccountID: sub.AccountID,
+		NodeID:    nodeID,
+		Message:   message,
+		Time:      time.Now(),
+	}
+
+	go m.notify(sub, event)
+}
+
+func (m *Manager) notify(sub *types.AlertSubscription, event *types.AlertEvent) {
+	if sub.Webhook != "" {
+		if err := m.sendWebhook(sub.Webhook, event); err != nil {
+			log.Errorf("send webhook to %s err:%s", sub.AccountID, err.Error())
+		}
+	}
+
+	if sub.Email != "" {
+		if err := m.sendEmail(sub.Email, event); err != nil {
+			log.Errorf("send email to %s err:%s", sub.AccountID, err.Error())
+		}
+	}
+}
+
+func (m *Manager) sendWebhook(url string, event *types.AlertEvent) error {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := m.httpClient.Post(url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close() //nolint:errcheck
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return xerrors.Errorf("webhook status code %d", rsp.StatusCode)
+	}
+
+	return nil
+}
+
+func (m *Manager) sendEmail(to string, event *types.AlertEvent) error {
+	cfg, err := m.config()
+	if err != nil {
+		return err
+	}
+
+	// email alerts are optional
+	if cfg.AlertSMTPAddress == "" {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(cfg.AlertSMTPAddress)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if cfg.AlertSMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.AlertSMTPUsername, cfg.AlertSMTPPassword, host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: [Titan] %s\r\n\r\n%s\r\n", cfg.AlertSMTPFrom, to, event.Type, event.Message)
+	return smtp.SendMail(cfg.AlertSMTPAddress, auth, cfg.AlertSMTPFrom, []string{to}, []byte(msg))
+}
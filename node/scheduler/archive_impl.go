@@ -0,0 +1,22 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// ListArchivedNodes retrieves the nodes archived after offline for long, the latest archived first
+func (s *Scheduler) ListArchivedNodes(ctx context.Context, limit, offset int) (*types.ListArchivedNodeRsp, error) {
+	return s.NodeManager.LoadArchivedNodes(limit, offset)
+}
+
+// RestoreArchivedNode restores the archived node, the node can log in again
+func (s *Scheduler) RestoreArchivedNode(ctx context.Context, nodeID string) error {
+	return s.NodeManager.RestoreArchivedNode(nodeID)
+}
+
+// PurgeNode deletes the offline node with its records for a deletion request, the points and settlements ledgers are kept
+func (s *Scheduler) PurgeNode(ctx context.Context, nodeID string) error {
+	return s.NodeManager.PurgeNode(nodeID)
+}
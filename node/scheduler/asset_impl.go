@@ -8,6 +8,8 @@ import (
 	"math"
 	"time"
 
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
 	"github.com/Filecoin-Titan/titan/node/handler"
@@ -21,6 +23,18 @@ func (s *Scheduler) NodeRemoveAssetResult(ctx context.Context, resultInfo types.
 
 	// update node info
 	s.NodeManager.UpdateNodeDiskUsage(nodeID, resultInfo.DiskUsage)
+
+	// the replica is evicted by the cache policy of the node
+	if resultInfo.AssetCID != "" {
+		hash, err := cidutil.CIDToHash(resultInfo.AssetCID)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("node %s evicted asset %s", nodeID, resultInfo.AssetCID)
+		return s.AssetManager.RemoveEvictedReplica(resultInfo.AssetCID, hash, nodeID)
+	}
+
 	return nil
 }
 
@@ -38,6 +52,11 @@ func (s *Scheduler) UpdateAssetExpiration(ctx context.Context, cid string, t tim
 	return s.AssetManager.UpdateAssetExpiration(cid, t)
 }
 
+// UpdateAssetQoSTier updates the qos tier of the asset
+func (s *Scheduler) UpdateAssetQoSTier(ctx context.Context, cid string, tier types.AssetQoSTier) error {
+	return s.AssetManager.UpdateAssetQoSTier(cid, tier)
+}
+
 // GetAssetRecord retrieves an asset record by its CID.
 func (s *Scheduler) GetAssetRecord(ctx context.Context, cid string) (*types.AssetRecord, error) {
 	hash, err := cidutil.CIDToHash(cid)
@@ -151,6 +170,60 @@ func (s *Scheduler) RemoveAssetReplica(ctx context.Context, cid, nodeID string)
 	return s.AssetManager.RemoveReplica(cid, hash, nodeID)
 }
 
+// GetSeedingProgress returns the progress of the edges pulling the asset from the candidates in waves
+func (s *Scheduler) GetSeedingProgress(ctx context.Context, cid string) (*types.SeedingProgress, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.AssetManager.SeedingProgress(cid, hash)
+}
+
+// GetReplicaProgress returns the replicas of the asset, the partial replicas are counted by the share of the asset they hold
+func (s *Scheduler) GetReplicaProgress(ctx context.Context, cid string) (*types.ReplicaProgress, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.AssetManager.ReplicaProgress(cid, hash)
+}
+
+// MarkAssetCacheable marks the replicas of the asset on the edges as cacheable or pinned
+func (s *Scheduler) MarkAssetCacheable(ctx context.Context, cid string, cacheable bool) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return err
+	}
+
+	return s.AssetManager.MarkAssetCacheable(cid, hash, cacheable)
+}
+
+// SetNodeCacheConfig sets the cache eviction policy of the edge node
+func (s *Scheduler) SetNodeCacheConfig(ctx context.Context, nodeID string, cfg *types.CacheConfig) error {
+	if cfg == nil || !cfg.Policy.IsValid() {
+		return xerrors.New("invalid cache config")
+	}
+
+	node := s.NodeManager.GetEdgeNode(nodeID)
+	if node == nil {
+		return xerrors.Errorf("node %s not found", nodeID)
+	}
+
+	return node.SetCacheConfig(ctx, cfg)
+}
+
+// GetNodeCacheComposition returns the pinned and cacheable assets of the edge node
+func (s *Scheduler) GetNodeCacheComposition(ctx context.Context, nodeID string) (*types.CacheComposition, error) {
+	node := s.NodeManager.GetEdgeNode(nodeID)
+	if node == nil {
+		return nil, xerrors.Errorf("node %s not found", nodeID)
+	}
+
+	return node.GetCacheComposition(ctx)
+}
+
 // PullAsset pull an asset based on the provided PullAssetReq structure.
 func (s *Scheduler) PullAsset(ctx context.Context, info *types.PullAssetReq) error {
 	if info.CID == "" {
@@ -254,20 +327,146 @@ func (s *Scheduler) GetReplicaEvents(ctx context.Context, start, end time.Time,
 
 // CreateAsset creates an asset with car CID, car name, and car size.
 func (s *Scheduler) CreateAsset(ctx context.Context, req *types.CreateAssetReq) (*types.CreateAssetRsp, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		req.UserID = uID
+	userID, tenant, err := s.admitUser(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	req.UserID = userID
+
+	if err := types.ValidateAssetMetadata(req.ContentType, req.Labels); err != nil {
+		return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	var warning string
+	if tenant != nil {
+		if warning, err = s.TenantManager.CheckStorage(tenant, req.AssetSize); err != nil {
+			return nil, err
+		}
+		req.GeoRules = tenant.GeoRules
+	}
+
+	u := s.newUser(req.UserID)
+	rsp, err := u.CreateAsset(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant != nil && !rsp.AlreadyExists {
+		s.TenantManager.AddUploaded(tenant.ID, req.AssetSize)
+	}
+	rsp.QuotaWarning = warning
+
+	return rsp, nil
+}
+
+// SearchAssets searches the assets of the user by their labels, name prefix, size and creation time
+func (s *Scheduler) SearchAssets(ctx context.Context, req *types.SearchAssetsReq) (*types.SearchAssetsRsp, error) {
+	userID, _, err := s.admitUser(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	req.UserID = userID
+
+	if err := req.Validate(); err != nil {
+		return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	rsp, err := s.db.SearchUserAssets(req)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	for _, asset := range rsp.Data {
+		asset.Password = ""
+	}
+
+	return rsp, nil
+}
+
+// CreateIngestTask chooses a candidate to ingest the asset of the user, returns the ingest url and token of the candidate
+func (s *Scheduler) CreateIngestTask(ctx context.Context, req *types.IngestAssetReq) (*types.CreateAssetRsp, error) {
+	userID, tenant, err := s.admitUser(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	req.UserID = userID
+
+	if err := types.ValidateAssetMetadata(req.ContentType, req.Labels); err != nil {
+		return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	var warning string
+	if tenant != nil {
+		if warning, err = s.TenantManager.CheckStorage(tenant, req.AssetSize); err != nil {
+			return nil, err
+		}
+	}
+
+	u := s.newUser(req.UserID)
+	rsp, err := u.CreateIngestTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rsp.QuotaWarning = warning
+
+	return rsp, nil
+}
+
+// IngestAssetCompleted records the root cid of the asset ingested by the candidate and starts the replication
+func (s *Scheduler) IngestAssetCompleted(ctx context.Context, result *types.IngestAssetResult) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	req, err := s.AssetManager.LoadIngestTask(result.IngestID, nodeID)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("IngestAssetCompleted nodeID:%s, assetCID:%s, size:%d", nodeID, result.AssetCID, result.AssetSize)
+
+	createReq := &types.CreateAssetReq{
+		UserID: req.UserID,
+		AssetProperty: types.AssetProperty{
+			AssetCID:    result.AssetCID,
+			AssetName:   req.AssetName,
+			AssetSize:   result.AssetSize,
+			AssetType:   req.AssetType,
+			NodeID:      nodeID,
+			Password:    req.Password,
+			GroupID:     req.GroupID,
+			ContentType: req.ContentType,
+			Labels:      req.Labels,
+		},
+	}
+
+	tenant, err := s.TenantManager.TenantOfUser(req.UserID)
+	if err != nil {
+		return err
+	}
+
+	if tenant != nil {
+		if _, err := s.TenantManager.CheckStorage(tenant, result.AssetSize); err != nil {
+			return err
+		}
+		createReq.GeoRules = tenant.GeoRules
 	}
 
 	u := s.newUser(req.UserID)
-	return u.CreateAsset(ctx, req)
+	if err := u.CreateIngestedAsset(ctx, createReq); err != nil {
+		return err
+	}
+
+	if tenant != nil {
+		s.TenantManager.AddUploaded(tenant.ID, result.AssetSize)
+	}
+
+	return nil
 }
 
 // ListAssets lists the assets of the user.
 func (s *Scheduler) ListAssets(ctx context.Context, userID string, limit, offset, groupID int) (*types.ListAssetRecordRsp, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	u := s.newUser(userID)
@@ -281,9 +480,9 @@ func (s *Scheduler) ListAssets(ctx context.Context, userID string, limit, offset
 
 // DeleteAsset deletes the assets of the user.
 func (s *Scheduler) DeleteAsset(ctx context.Context, userID, assetCID string) error {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return err
 	}
 
 	u := s.newUser(userID)
@@ -292,9 +491,15 @@ func (s *Scheduler) DeleteAsset(ctx context.Context, userID, assetCID string) er
 
 // ShareAssets shares the assets of the user.
 func (s *Scheduler) ShareAssets(ctx context.Context, userID string, assetCIDs []string) (map[string]string, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, tenant, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant != nil {
+		if _, err := s.TenantManager.CheckTraffic(tenant); err != nil {
+			return nil, err
+		}
 	}
 
 	u := s.newUser(userID)
@@ -306,13 +511,77 @@ func (s *Scheduler) ShareAssets(ctx context.Context, userID string, assetCIDs []
 	return info, nil
 }
 
-// GetAssetStatus retrieves a asset status
-func (s *Scheduler) GetAssetStatus(ctx context.Context, userID, assetCID string) (*types.AssetStatus, error) {
+// CreateSignedURL signs a url to share the asset of the user until the expiration, the nodes verify it without the scheduler
+func (s *Scheduler) CreateSignedURL(ctx context.Context, userID, assetCID string, expiration time.Time) (*types.SignedURL, error) {
+	userID, tenant, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var warning string
+	if tenant != nil {
+		if warning, err = s.TenantManager.CheckTraffic(tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	u := s.newUser(userID)
+	signed, err := u.CreateSignedURL(ctx, assetCID, expiration, s, s.NodeManager, s.KeyRing)
+	if err != nil {
+		return nil, err
+	}
+	signed.QuotaWarning = warning
+
+	return signed, nil
+}
+
+// GetDownloadSources retrieves the urls of the nodes holding the asset of the user
+func (s *Scheduler) GetDownloadSources(ctx context.Context, userID, assetCID string) (*types.DownloadSources, error) {
+	userID, tenant, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var warning string
+	if tenant != nil {
+		if warning, err = s.TenantManager.CheckTraffic(tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	u := s.newUser(userID)
+	sources, err := u.GetDownloadSources(ctx, assetCID, s, s.NodeManager)
+	if err != nil {
+		return nil, xerrors.Errorf("GetDownloadSources err:%s", err.Error())
+	}
+	sources.QuotaWarning = warning
+
+	return sources, nil
+}
+
+// GetAssetEncryption retrieves the metadata to decrypt the asset encrypted by the user before the upload
+func (s *Scheduler) GetAssetEncryption(ctx context.Context, userID, assetCID string) (*types.AssetEncryption, error) {
 	uID := handler.GetUserID(ctx)
 	if len(uID) > 0 {
 		userID = uID
 	}
 
+	u := s.newUser(userID)
+	info, err := u.GetAssetEncryption(assetCID)
+	if err != nil {
+		return nil, xerrors.Errorf("GetAssetEncryption err:%s", err.Error())
+	}
+
+	return info, nil
+}
+
+// GetAssetStatus retrieves a asset status
+func (s *Scheduler) GetAssetStatus(ctx context.Context, userID, assetCID string) (*types.AssetStatus, error) {
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	u := s.newUser(userID)
 	status, err := u.GetAssetStatus(ctx, assetCID, s.SchedulerCfg)
 	if err != nil {
@@ -0,0 +1,60 @@
+package assets
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const markAssetCacheTimeout = 10 * time.Second
+
+// MarkAssetCacheable marks the replicas of the asset on the online edges as cacheable or pinned
+func (m *Manager) MarkAssetCacheable(cid, hash string, cacheable bool) error {
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("LoadAssetRecord %s err:%s", hash, err.Error())
+	}
+
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return xerrors.Errorf("LoadReplicasByStatus %s err:%s", hash, err.Error())
+	}
+
+	mark := &types.AssetCacheMark{CID: cid, Cacheable: cacheable, Expiration: record.Expiration}
+	for _, replica := range replicas {
+		if replica.IsCandidate {
+			continue
+		}
+
+		node := m.nodeMgr.GetEdgeNode(replica.NodeID)
+		if node == nil {
+			log.Warnf("MarkAssetCacheable %s edge %s is offline", hash, replica.NodeID)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), markAssetCacheTimeout)
+		err := node.MarkAssetsCache(ctx, []*types.AssetCacheMark{mark})
+		cancel()
+		if err != nil {
+			log.Errorf("MarkAssetCacheable %s node %s err:%s", hash, replica.NodeID, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// RemoveEvictedReplica removes the replica evicted by the cache policy of the node
+func (m *Manager) RemoveEvictedReplica(cid, hash, nodeID string) error {
+	if err := m.DeleteAssetReplica(hash, nodeID); err != nil {
+		return xerrors.Errorf("RemoveEvictedReplica %s DeleteAssetReplica err: %s", hash, err.Error())
+	}
+	m.notifyReplicaChanged(hash)
+
+	if err := m.removeAssetFromView(nodeID, cid); err != nil {
+		return xerrors.Errorf("RemoveEvictedReplica %s removeAssetFromView err: %s", hash, err.Error())
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"sort"
 
+	types "github.com/Filecoin-Titan/titan/api/types"
 	cid "github.com/ipfs/go-cid"
 	cbg "github.com/whyrusleeping/cbor-gen"
 	xerrors "golang.org/x/xerrors"
@@ -26,7 +27,7 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{177}); err != nil {
+	if _, err := cw.Write([]byte{178}); err != nil {
 		return err
 	}
 
@@ -189,6 +190,29 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.QoSTier (types.AssetQoSTier) (string)
+	if len("QoSTier") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"QoSTier\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("QoSTier"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("QoSTier")); err != nil {
+		return err
+	}
+
+	if len(t.QoSTier) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.QoSTier was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.QoSTier))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.QoSTier)); err != nil {
+		return err
+	}
+
 	// t.Bandwidth (int64) (int64)
 	if len("Bandwidth") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"Bandwidth\" was too long")
@@ -577,6 +601,17 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Details = string(sval)
 			}
+			// t.QoSTier (types.AssetQoSTier) (string)
+		case "QoSTier":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.QoSTier = types.AssetQoSTier(sval)
+			}
 			// t.Bandwidth (int64) (int64)
 		case "Bandwidth":
 			{
@@ -0,0 +1,43 @@
+package assets
+
+import (
+	"golang.org/x/xerrors"
+)
+
+// ArchiveAsset removes the hot replicas of the servicing asset offloaded into the filecoin storage deal,
+// the asset record is kept in the archived state
+func (m *Manager) ArchiveAsset(hash string) error {
+	if exist, _ := m.assetStateMachines.Has(AssetHash(hash)); !exist {
+		return xerrors.Errorf("No operation rights, the asset belongs to another scheduler %s", hash)
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("LoadAssetRecord %s err:%s", hash, err.Error())
+	}
+
+	if record.State != Servicing.String() {
+		return xerrors.Errorf("asset state is %s , only the servicing asset can be archived", record.State)
+	}
+
+	return m.assetStateMachines.Send(AssetHash(hash), AssetForceState{State: Archived})
+}
+
+// RestoreAsset pulls the archived asset back from the storage deal, the asset is seeded again by the candidates
+// from the provider of the deal and replicated as many times as before it was archived
+func (m *Manager) RestoreAsset(hash string) error {
+	if exist, _ := m.assetStateMachines.Has(AssetHash(hash)); !exist {
+		return xerrors.Errorf("No operation rights, the asset belongs to another scheduler %s", hash)
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return xerrors.Errorf("LoadAssetRecord %s err:%s", hash, err.Error())
+	}
+
+	if record.State != Archived.String() {
+		return xerrors.Errorf("asset state is %s , only the archived asset can be restored", record.State)
+	}
+
+	return m.replenishAssetReplicas(record, 0, "", "restore from the storage deal", SeedSelect, "")
+}
@@ -0,0 +1,304 @@
+package assets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the replicas of the deactivated nodes are deleted once migrated to other nodes or after the timeout of the cleanup,
+	// the assets still under-replicated then are repaired by the reconciler
+	deactivateMigrateTimeout  = 6 * time.Hour
+	deactivateMigrateInterval = time.Minute
+	// the assets of the deactivated nodes migrated at once
+	maxMigratingAssets = 20
+	// the repairs requested for an asset of the deactivated nodes
+	maxMigrateAttempts = 3
+	// the deactivated nodes whose assets are loaded or whose replicas are deleted at once
+	maxCleanupWorkers = 8
+)
+
+// migration an asset on the deactivated nodes migrating to replacement nodes
+type migration struct {
+	// nodes the deactivated nodes holding the asset
+	nodes map[string]bool
+	// attempts the repairs requested
+	attempts int
+}
+
+// runDeactivateCleanup migrates the replicas of the nodes whose deactivation is in effect to replacement nodes,
+// an asset held by several of the nodes is migrated once. The replicas of a node are deleted when its assets are
+// migrated or after the overall timeout, the nodes failed are retried in the next attempt
+func (m *Manager) runDeactivateCleanup(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	nodes, err := m.LoadDeactivatedNodes(time.Now().Unix())
+	if err != nil {
+		return xerrors.Errorf("LoadDeactivatedNodes err:%s", err.Error())
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(deactivateMigrateTimeout)
+
+	migrations, pending := m.loadMigrations(nodes)
+	failed := len(nodes) - len(pending)
+	done := failed
+
+	log.Infof("migrate %d assets off %d deactivated nodes", len(migrations), len(pending))
+
+	ticker := time.NewTicker(deactivateMigrateInterval)
+	defer ticker.Stop()
+
+	for {
+		m.stepMigrations(migrations, time.Now())
+
+		expired := time.Now().After(deadline)
+		if expired && len(migrations) > 0 {
+			log.Warnf("%d assets are not migrated off the deactivated nodes in %s, they are left to the reconciler",
+				len(migrations), deactivateMigrateTimeout)
+		}
+
+		free := freeNodes(pending, migrations, expired)
+		failed += m.deleteReplicasOfNodes(free)
+		done += len(free)
+		progress(int64(done), int64(len(nodes)))
+
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	if failed > 0 {
+		return xerrors.Errorf("failed to clean %d of %d deactivated nodes", failed, len(nodes))
+	}
+
+	return nil
+}
+
+// loadMigrations loads the assets of the deactivated nodes by maxCleanupWorkers workers, and returns the assets
+// to migrate by hash and the nodes loaded
+func (m *Manager) loadMigrations(nodes []string) (map[string]*migration, map[string]bool) {
+	migrations := make(map[string]*migration)
+	loaded := make(map[string]bool, len(nodes))
+
+	var lk sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxCleanupWorkers)
+
+	for _, nodeID := range nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(nodeID string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			hashes, err := m.LoadAllHashesOfNode(nodeID)
+			if err != nil {
+				log.Errorf("clean deactivated node %s LoadAllHashesOfNode err:%s", nodeID, err.Error())
+				return
+			}
+
+			lk.Lock()
+			defer lk.Unlock()
+
+			addMigrations(migrations, nodeID, hashes)
+			loaded[nodeID] = true
+		}(nodeID)
+	}
+	wg.Wait()
+
+	return migrations, loaded
+}
+
+// addMigrations adds the assets of the deactivated node to the migrations, the assets of several nodes are merged
+func addMigrations(migrations map[string]*migration, nodeID string, hashes []string) {
+	for _, hash := range hashes {
+		mg, ok := migrations[hash]
+		if !ok {
+			mg = &migration{nodes: make(map[string]bool)}
+			migrations[hash] = mg
+		}
+		mg.nodes[nodeID] = true
+	}
+}
+
+// freeNodes removes the nodes holding none of the assets migrating from the pending nodes and returns them,
+// all the pending nodes are returned once the migrations are expired
+func freeNodes(pending map[string]bool, migrations map[string]*migration, expired bool) []string {
+	busy := make(map[string]bool)
+	if !expired {
+		for _, mg := range migrations {
+			for nodeID := range mg.nodes {
+				busy[nodeID] = true
+			}
+		}
+	}
+
+	var free []string
+	for nodeID := range pending {
+		if busy[nodeID] {
+			continue
+		}
+
+		free = append(free, nodeID)
+		delete(pending, nodeID)
+	}
+
+	return free
+}
+
+// deleteReplicasOfNodes deletes the replicas of the deactivated nodes by maxCleanupWorkers workers,
+// and returns the number of the nodes failed
+func (m *Manager) deleteReplicasOfNodes(nodes []string) int {
+	var lk sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxCleanupWorkers)
+	failed := 0
+
+	for _, nodeID := range nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(nodeID string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := m.DeleteAssetRecordsOfNode(nodeID); err != nil {
+				log.Errorf("clean deactivated node %s DeleteAssetRecordsOfNode err:%s", nodeID, err.Error())
+
+				lk.Lock()
+				failed++
+				lk.Unlock()
+				return
+			}
+
+			log.Infof("cleaned deactivated node %s", nodeID)
+		}(nodeID)
+	}
+	wg.Wait()
+
+	return failed
+}
+
+// stepMigrations forgets the assets migrated off the deactivated nodes and requests the repairs of the others,
+// at most maxMigratingAssets assets of all the nodes are pulled at once
+func (m *Manager) stepMigrations(migrations map[string]*migration, now time.Time) {
+	// the assets to repair with the edge replicas they miss
+	pending := make(map[*types.AssetRecord]int64)
+	migrating := 0
+
+	for hash, mg := range migrations {
+		record, err := m.LoadAssetRecord(hash)
+		if err == sql.ErrNoRows {
+			delete(migrations, hash)
+			continue
+		} else if err != nil {
+			log.Errorf("LoadAssetRecord %s err:%s", hash, err.Error())
+			continue
+		}
+
+		// the assets of the other schedulers are repaired by their reconcilers
+		if record.ServerID != m.nodeMgr.ServerID {
+			delete(migrations, hash)
+			continue
+		}
+
+		if isPulling(record.State) {
+			migrating++
+			continue
+		}
+
+		if record.State != Servicing.String() && record.State != EdgesFailed.String() {
+			delete(migrations, hash)
+			continue
+		}
+
+		// the replicas on all the deactivated nodes holding the asset are not counted
+		missingEdges, missingCandidates, err := m.missingReplicasWithout(record, mg.nodes, now)
+		if err != nil {
+			log.Errorf("count replicas of %s err:%s", hash, err.Error())
+			continue
+		}
+
+		if missingEdges <= 0 && missingCandidates <= 0 {
+			delete(migrations, hash)
+			continue
+		}
+
+		if mg.attempts >= maxMigrateAttempts {
+			log.Warnf("asset %s is not migrated off deactivated nodes %s after %d repairs", record.CID, nodesOf(mg), maxMigrateAttempts)
+			delete(migrations, hash)
+			continue
+		}
+
+		pending[record] = max(missingEdges, 0)
+	}
+
+	for record, missingEdges := range pending {
+		if migrating >= maxMigratingAssets {
+			return
+		}
+
+		mg := migrations[record.Hash]
+		details := fmt.Sprintf("migrate off deactivated nodes %s", nodesOf(mg))
+		if err := m.replenishAssetReplicas(record, missingEdges, string(m.nodeMgr.ServerID), details, CandidatesSelect, ""); err != nil {
+			log.Errorf("migrate %s replenishAssetReplicas err:%s", record.Hash, err.Error())
+			continue
+		}
+
+		mg.attempts++
+		migrating++
+	}
+}
+
+// missingReplicasWithout returns the edge and candidate replicas the asset misses without the replicas on the nodes
+func (m *Manager) missingReplicasWithout(record *types.AssetRecord, nodes map[string]bool, now time.Time) (int64, int64, error) {
+	counts, err := m.countReplicas(record.Hash, now, nodes)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return record.NeedEdgeReplica - int64(counts.edges), record.NeedCandidateReplicas - int64(counts.candidates), nil
+}
+
+// nodesOf returns the deactivated nodes holding the asset joined by commas
+func nodesOf(mg *migration) string {
+	nodes := make([]string, 0, len(mg.nodes))
+	for nodeID := range mg.nodes {
+		nodes = append(nodes, nodeID)
+	}
+	sort.Strings(nodes)
+
+	return strings.Join(nodes, ",")
+}
+
+func isPulling(state string) bool {
+	for _, s := range PullingStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package assets
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestDeactivateMigrations(t *testing.T) {
+	migrations := make(map[string]*migration)
+	addMigrations(migrations, "e_1", []string{"a", "b"})
+	addMigrations(migrations, "e_2", []string{"b", "c"})
+
+	// the asset shared by the nodes is migrated once
+	if len(migrations) != 3 {
+		t.Fatalf("expect 3 migrations, got %d", len(migrations))
+	}
+	if nodes := nodesOf(migrations["b"]); nodes != "e_1,e_2" {
+		t.Errorf("expect the shared asset held by e_1,e_2, got %s", nodes)
+	}
+
+	pending := map[string]bool{"e_1": true, "e_2": true, "e_3": true}
+	if free := freeNodes(pending, migrations, false); len(free) != 1 || free[0] != "e_3" {
+		t.Errorf("expect only e_3 to be free, got %v", free)
+	}
+
+	delete(migrations, "a")
+	delete(migrations, "b")
+	if free := freeNodes(pending, migrations, false); len(free) != 1 || free[0] != "e_1" {
+		t.Errorf("expect e_1 to be free after its assets are migrated, got %v", free)
+	}
+
+	// the nodes still holding assets are freed once the migrations are expired
+	free := freeNodes(pending, migrations, true)
+	sort.Strings(free)
+	if len(free) != 1 || free[0] != "e_2" || len(pending) != 0 {
+		t.Errorf("expect e_2 to be free after the timeout, got %v", free)
+	}
+}
@@ -0,0 +1,52 @@
+package assets
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// checkDenied refuses to pull or upload the banned asset
+func (m *Manager) checkDenied(hash, cid string) error {
+	if m.denylistMgr == nil || !m.denylistMgr.IsDenied(hash) {
+		return nil
+	}
+
+	m.denylistMgr.RecordBlocked(hash, cid, types.DenylistEventRefusePull, "")
+	return &api.ErrWeb{Code: terrors.AssetDenied.Int(), Message: fmt.Sprintf("the asset %s is denied", cid)}
+}
+
+// purgeDeniedAsset removes the replicas of the banned asset from the nodes
+func (m *Manager) purgeDeniedAsset(hash string) {
+	if exist, _ := m.assetStateMachines.Has(AssetHash(hash)); !exist {
+		return
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Errorf("purgeDeniedAsset %s LoadAssetRecord err:%s", hash, err.Error())
+		}
+		return
+	}
+
+	if record.State == Remove.String() {
+		return
+	}
+
+	replicas, err := m.LoadReplicasByStatus(hash, types.ReplicaStatusAll)
+	if err != nil {
+		log.Errorf("purgeDeniedAsset %s LoadReplicasByStatus err:%s", hash, err.Error())
+	}
+
+	if err := m.RemoveAsset(hash, false); err != nil {
+		log.Errorf("purgeDeniedAsset %s RemoveAsset err:%s", hash, err.Error())
+		return
+	}
+
+	log.Infof("purge the denied asset %s from %d nodes", record.CID, len(replicas))
+	m.denylistMgr.RecordEvent(hash, record.CID, types.DenylistEventPurge, fmt.Sprintf("replicas:%d", len(replicas)), "")
+}
@@ -43,6 +43,8 @@ type AssetPullingInfo struct {
 	SeedNodeID string
 
 	Note string
+
+	QoSTier types.AssetQoSTier
 }
 
 // ToAssetRecord converts AssetPullingInfo to types.AssetRecord
@@ -58,6 +60,7 @@ func (state *AssetPullingInfo) ToAssetRecord() *types.AssetRecord {
 		RetryCount:            state.RetryCount,
 		ReplenishReplicas:     state.ReplenishReplicas,
 		Note:                  state.Note,
+		QoSTier:               state.QoSTier,
 	}
 }
 
@@ -75,6 +78,7 @@ func assetPullingInfoFrom(info *types.AssetRecord, assetDB *db.SQLDB) *AssetPull
 		ReplenishReplicas: info.ReplenishReplicas,
 		Bandwidth:         info.NeedBandwidth,
 		Note:              info.Note,
+		QoSTier:           info.QoSTier,
 	}
 
 	for _, r := range info.ReplicaInfos {
@@ -2,21 +2,37 @@ package assets
 
 import (
 	"context"
+	"encoding/json"
 	"math/rand"
 	"sort"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
 	"github.com/docker/go-units"
+	"golang.org/x/xerrors"
 )
 
 const (
 	// Interval to dispatch tasks to populate node disks
 	fillDiskInterval = 5 * time.Minute
+
+	// jobPullFanout the kind of the jobs requesting the nodes to pull an asset from aws,
+	// run by the scheduler the nodes are connected to
+	jobPullFanout = "assets.pull_fanout"
 )
 
+// pullFanoutPayload the payload of the pull fan-out jobs
+type pullFanoutPayload struct {
+	Bucket         string
+	Cid            string
+	Size           float64
+	EdgeCount      int64
+	CandidateCount int64
+}
+
 // initFillDiskTimer dispatch tasks to populate node disks
 func (m *Manager) initFillDiskTimer() {
 	ticker := time.NewTicker(fillDiskInterval)
@@ -170,11 +186,25 @@ func (m *Manager) pullAssetFromAWSs(edgeCount, candidateCount int64) bool {
 
 	m.updateFillAssetInfo(info.Bucket, candidateCount, info.Replicas)
 
-	go m.requestNodePullAsset(info.Bucket, info.Cid, info.Size, edgeCount, candidateCount)
+	payload := &pullFanoutPayload{Bucket: info.Bucket, Cid: info.Cid, Size: info.Size, EdgeCount: edgeCount, CandidateCount: candidateCount}
+	if _, err := m.jobs.Enqueue(jobPullFanout, payload, jobqueue.Options{Local: true}); err != nil {
+		log.Errorf("pullAssetFromAWS enqueue %s err:%s", info.Bucket, err.Error())
+		return false
+	}
 
 	return true
 }
 
+func (m *Manager) runPullFanout(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	var payload pullFanoutPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return xerrors.Errorf("unmarshal payload err:%s", err.Error())
+	}
+
+	m.requestNodePullAsset(payload.Bucket, payload.Cid, payload.Size, payload.EdgeCount, payload.CandidateCount)
+	return nil
+}
+
 func (m *Manager) fillDiskTasks(edgeCount, candidateCount int64) {
 	pullCount := m.getPullingAssetLen()
 	limitCount := m.getAssetPullTaskLimit()
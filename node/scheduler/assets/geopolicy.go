@@ -0,0 +1,331 @@
+package assets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Interval to reconcile the replicas of the assets with their geo policies
+	geoReconcileInterval = 30 * time.Minute
+	// the geo policies reconciled by a query
+	geoReconcileBatch = 100
+
+	// jobGeoReconcile the kind of the jobs reconciling the replicas with the geo policies,
+	// run by the scheduler the assets belong to
+	jobGeoReconcile = "assets.geo_reconcile"
+)
+
+// geoPlacement the geo policy of an asset evaluated against its replicas while the nodes are chosen,
+// the nodes of the excluded regions are filtered and the nodes of the regions short of replicas go first
+type geoPlacement struct {
+	rules types.AssetGeoRules
+	// missing the replicas each rule still needs, by the index of the rule
+	missing []int
+}
+
+// loadGeoPlacement returns the placement of the asset with the replicas on the nodes, nil if the asset has no geo policy
+func (m *Manager) loadGeoPlacement(hash string, replicaNodes []string) *geoPlacement {
+	policy, err := m.LoadAssetGeoPolicy(hash)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Errorf("LoadAssetGeoPolicy %s err:%s", hash, err.Error())
+		}
+		return nil
+	}
+
+	p := &geoPlacement{rules: policy.Rules, missing: make([]int, len(policy.Rules))}
+	for i, rule := range policy.Rules {
+		p.missing[i] = rule.MinReplicas
+	}
+
+	for _, nodeID := range replicaNodes {
+		if n := m.nodeMgr.GetNode(nodeID); n != nil {
+			p.placed(n)
+		}
+	}
+
+	return p
+}
+
+// excluded checks if the node is in a region the replicas are excluded from
+func (p *geoPlacement) excluded(n *node.Node) bool {
+	return p != nil && p.rules.Excluded(n.Geo)
+}
+
+// needed checks if the node is in a region short of replicas
+func (p *geoPlacement) needed(n *node.Node) bool {
+	if p == nil {
+		return false
+	}
+
+	for i, rule := range p.rules {
+		if p.missing[i] > 0 && rule.Matches(n.Geo) {
+			return true
+		}
+	}
+	return false
+}
+
+// placed counts the replica placed on the node, the missing replicas of a region go negative with its surplus
+func (p *geoPlacement) placed(n *node.Node) {
+	if p == nil {
+		return
+	}
+
+	for i, rule := range p.rules {
+		if !rule.Exclude && rule.Matches(n.Geo) {
+			p.missing[i]--
+		}
+	}
+}
+
+// removable checks if the replica on the node can be removed without its regions falling short of replicas
+func (p *geoPlacement) removable(n *node.Node) bool {
+	if p == nil {
+		return true
+	}
+
+	for i, rule := range p.rules {
+		if !rule.Exclude && rule.Matches(n.Geo) && p.missing[i] >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// removed counts the replica removed from the node
+func (p *geoPlacement) removed(n *node.Node) {
+	if p == nil {
+		return
+	}
+
+	for i, rule := range p.rules {
+		if !rule.Exclude && rule.Matches(n.Geo) {
+			p.missing[i]++
+		}
+	}
+}
+
+// sort orders the nodes of the regions short of replicas first, the order is kept otherwise
+func (p *geoPlacement) sort(nodes []*node.Node) {
+	if p == nil {
+		return
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return p.needed(nodes[i]) && !p.needed(nodes[j])
+	})
+}
+
+// evaluateGeoRules returns the rules the replicas on the nodes of the areas violate, and the nodes of the excluded regions
+func evaluateGeoRules(rules types.AssetGeoRules, replicaAreas map[string]string) ([]*types.AssetGeoViolation, []string) {
+	violations := make([]*types.AssetGeoViolation, 0)
+	excluded := make([]string, 0)
+
+	for _, rule := range rules {
+		count := 0
+		for nodeID, areaID := range replicaAreas {
+			if !rule.Matches(areaID) {
+				continue
+			}
+
+			count++
+			if rule.Exclude {
+				excluded = append(excluded, nodeID)
+			}
+		}
+
+		if rule.Exclude && count > 0 {
+			violations = append(violations, &types.AssetGeoViolation{Region: rule.Region, Kind: types.AssetGeoViolationExcluded, Replicas: count})
+		} else if !rule.Exclude && count < rule.MinReplicas {
+			violations = append(violations, &types.AssetGeoViolation{Region: rule.Region, Kind: types.AssetGeoViolationMissing, Replicas: count, Required: rule.MinReplicas})
+		}
+	}
+
+	sort.Strings(excluded)
+	return violations, excluded
+}
+
+// SetAssetGeoPolicy sets the geo policy of the asset, the replicas are reconciled with the policy at once
+func (m *Manager) SetAssetGeoPolicy(cid string, rules types.AssetGeoRules) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("CIDToHash %s err:%s", cid, err.Error())
+	}
+
+	if _, err := m.LoadAssetRecord(hash); err != nil {
+		if err == sql.ErrNoRows {
+			return xerrors.Errorf("asset %s not found", cid)
+		}
+		return err
+	}
+
+	policy := &types.AssetGeoPolicy{Hash: hash, CID: cid, Rules: rules, UpdatedTime: time.Now()}
+	if err := m.SaveAssetGeoPolicy(policy); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := m.reconcileGeoPolicy(policy); err != nil {
+			log.Errorf("reconcile geo policy of %s err:%s", cid, err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// RemoveAssetGeoPolicy removes the geo policy of the asset, the replicas placed are kept
+func (m *Manager) RemoveAssetGeoPolicy(cid string) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("CIDToHash %s err:%s", cid, err.Error())
+	}
+
+	return m.DeleteAssetGeoPolicy(hash)
+}
+
+func (m *Manager) startGeoReconcileTimer() {
+	ticker := time.NewTicker(geoReconcileInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// the assets belong to the scheduler, the job runs on it once an interval
+		key := fmt.Sprintf("%s:%s:%s", jobGeoReconcile, m.nodeMgr.ServerID, time.Now().UTC().Truncate(geoReconcileInterval).Format(time.RFC3339))
+		if _, err := m.jobs.Enqueue(jobGeoReconcile, struct{}{}, jobqueue.Options{DedupKey: key, Local: true}); err != nil && err != jobqueue.ErrDuplicateJob {
+			log.Errorf("enqueue %s err:%s", jobGeoReconcile, err.Error())
+		}
+	}
+}
+
+// runGeoReconcile reconciles the replicas of the servicing assets of the scheduler with their geo policies,
+// the node populations shift as the nodes go online and offline
+func (m *Manager) runGeoReconcile(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	after := ""
+	done := int64(0)
+
+	for {
+		policies, err := m.LoadServerAssetGeoPolicies(m.nodeMgr.ServerID, Servicing.String(), after, geoReconcileBatch)
+		if err != nil {
+			return xerrors.Errorf("LoadServerAssetGeoPolicies err:%s", err.Error())
+		}
+
+		for _, policy := range policies {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err := m.reconcileGeoPolicy(policy); err != nil {
+				log.Errorf("reconcile geo policy of %s err:%s", policy.CID, err.Error())
+			}
+
+			after = policy.Hash
+			done++
+			progress(done, 0)
+		}
+
+		if len(policies) < geoReconcileBatch {
+			return nil
+		}
+	}
+}
+
+// reconcileGeoPolicy saves the violations of the geo policy by the replicas on the online nodes,
+// removes the edge replicas of the excluded regions and pulls replicas to the regions short of them
+func (m *Manager) reconcileGeoPolicy(policy *types.AssetGeoPolicy) error {
+	replicas, err := m.LoadReplicasByStatus(policy.Hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return xerrors.Errorf("LoadReplicasByStatus err:%s", err.Error())
+	}
+
+	replicaAreas := make(map[string]string, len(replicas))
+	replicaNodes := make([]string, 0, len(replicas))
+	candidates := make(map[string]struct{})
+	for _, replica := range replicas {
+		replicaNodes = append(replicaNodes, replica.NodeID)
+		if replica.IsCandidate {
+			candidates[replica.NodeID] = struct{}{}
+		}
+
+		// the replicas on the offline nodes are not counted
+		if n := m.nodeMgr.GetNode(replica.NodeID); n != nil {
+			replicaAreas[replica.NodeID] = n.Geo
+		}
+	}
+
+	now := time.Now()
+	violations, excluded := evaluateGeoRules(policy.Rules, replicaAreas)
+	for _, v := range violations {
+		v.Hash, v.CID, v.DetectedTime = policy.Hash, policy.CID, now
+	}
+
+	if err := m.UpdateAssetGeoViolations(policy.Hash, policy.CID, violations); err != nil {
+		return xerrors.Errorf("UpdateAssetGeoViolations err:%s", err.Error())
+	}
+
+	// the candidates keep the replicas as the sources of the edges, their violations are only reported
+	for _, nodeID := range excluded {
+		if _, ok := candidates[nodeID]; ok {
+			continue
+		}
+
+		log.Infof("remove replica of %s on node %s in the excluded region", policy.CID, nodeID)
+		if err := m.RemoveReplica(policy.CID, policy.Hash, nodeID); err != nil {
+			log.Errorf("RemoveReplica err:%s", err.Error())
+		}
+	}
+
+	missing := m.geoMissingReplicas(policy.Hash, replicaNodes)
+	if missing <= 0 {
+		return nil
+	}
+
+	record, err := m.LoadAssetRecord(policy.Hash)
+	if err != nil {
+		return xerrors.Errorf("LoadAssetRecord err:%s", err.Error())
+	}
+
+	if record.State != Servicing.String() {
+		return nil
+	}
+
+	return m.replenishAssetReplicas(record, int64(missing), string(m.nodeMgr.ServerID), "geo policy", CandidatesSelect, "")
+}
+
+// geoMissingReplicas returns the replicas the regions short of them can be given by the online edges
+func (m *Manager) geoMissingReplicas(hash string, replicaNodes []string) int {
+	p := m.loadGeoPlacement(hash, replicaNodes)
+	if p == nil {
+		return 0
+	}
+
+	holders := make(map[string]struct{}, len(replicaNodes))
+	for _, nodeID := range replicaNodes {
+		holders[nodeID] = struct{}{}
+	}
+
+	count := 0
+	for _, n := range m.nodeMgr.GetAllEdgeNode() {
+		if _, ok := holders[n.NodeID]; ok || !p.needed(n) {
+			continue
+		}
+
+		p.placed(n)
+		count++
+	}
+
+	return count
+}
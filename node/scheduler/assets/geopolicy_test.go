@@ -0,0 +1,91 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+func TestEvaluateGeoRules(t *testing.T) {
+	rules := types.AssetGeoRules{
+		{Region: "Europe", MinReplicas: 2},
+		{Region: "Asia-China", Exclude: true},
+	}
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	replicaAreas := map[string]string{
+		"e1": "Europe-Germany-Hesse-Frankfurt",
+		"e2": "Asia-China-Guangdong-Shenzhen",
+		"e3": "NorthAmerica-UnitedStates-California-LosAngeles",
+	}
+
+	violations, excluded := evaluateGeoRules(rules, replicaAreas)
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %d", len(violations))
+	}
+
+	if v := violations[0]; v.Kind != types.AssetGeoViolationMissing || v.Replicas != 1 || v.Required != 2 {
+		t.Fatalf("unexpected violation %+v", v)
+	}
+
+	if v := violations[1]; v.Kind != types.AssetGeoViolationExcluded || v.Replicas != 1 {
+		t.Fatalf("unexpected violation %+v", v)
+	}
+
+	if len(excluded) != 1 || excluded[0] != "e2" {
+		t.Fatalf("expected e2 excluded, got %v", excluded)
+	}
+
+	replicaAreas["e2"] = "Europe-France-IleDeFrance-Paris"
+	if violations, excluded = evaluateGeoRules(rules, replicaAreas); len(violations) != 0 || len(excluded) != 0 {
+		t.Fatalf("expected the policy met, got %v %v", violations, excluded)
+	}
+}
+
+func TestGeoPlacement(t *testing.T) {
+	p := &geoPlacement{rules: types.AssetGeoRules{{Region: "Europe", MinReplicas: 1}, {Region: "Asia", Exclude: true}}, missing: []int{1, 0}}
+
+	asia := &node.Node{NodeID: "a", Geo: "Asia-Japan-Tokyo-Tokyo"}
+	europe := &node.Node{NodeID: "e", Geo: "Europe-Germany-Hesse-Frankfurt"}
+	other := &node.Node{NodeID: "o", Geo: "Oceania-Australia-Victoria-Melbourne"}
+
+	if !p.excluded(asia) || p.excluded(europe) {
+		t.Fatal("expected only the asia node excluded")
+	}
+
+	nodes := []*node.Node{other, asia, europe}
+	p.sort(nodes)
+	if nodes[0] != europe {
+		t.Fatalf("expected the europe node first, got %s", nodes[0].NodeID)
+	}
+
+	p.placed(europe)
+	if p.needed(europe) {
+		t.Fatal("expected the region met after the replica placed")
+	}
+
+	// the assets without geo policies are placed as before
+	var none *geoPlacement
+	if none.excluded(asia) || none.needed(europe) {
+		t.Fatal("expected no placement without policy")
+	}
+}
+
+func TestGeoRulesValidate(t *testing.T) {
+	invalid := []types.AssetGeoRules{
+		nil,
+		{{Region: ""}},
+		{{Region: "Europe", MinReplicas: 1, Exclude: true}},
+		{{Region: "Europe"}},
+		{{Region: "Europe", Exclude: true}, {Region: "Europe-Germany", MinReplicas: 1}},
+	}
+
+	for i, rules := range invalid {
+		if err := rules.Validate(); err == nil {
+			t.Fatalf("expected rules %d invalid", i)
+		}
+	}
+}
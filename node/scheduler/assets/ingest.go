@@ -0,0 +1,128 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// expiration of the ingest token and task
+const ingestTaskExpiration = time.Hour
+
+// ingestTask the asset waiting to be ingested by the candidate
+type ingestTask struct {
+	nodeID     string
+	req        *types.IngestAssetReq
+	expiration time.Time
+}
+
+// CreateAssetIngestTask chooses a candidate to ingest the asset of the user, returns the ingest url and token of the candidate
+func (m *Manager) CreateAssetIngestTask(req *types.IngestAssetReq) (*types.CreateAssetRsp, error) {
+	if m.getPullingAssetLen() >= m.getAssetPullTaskLimit() {
+		return nil, &api.ErrWeb{Code: terrors.BusyServer.Int(), Message: fmt.Sprintf("The task has reached its limit. Please try again later.")}
+	}
+
+	m.removeExpiredIngestTasks()
+
+	cNodes, str := m.chooseCandidateNodes("", 1, nil, types.AssetQoSTierStandard)
+	if len(cNodes) == 0 {
+		return nil, &api.ErrWeb{Code: terrors.NotFoundNode.Int(), Message: fmt.Sprintf("not found node :%s", str)}
+	}
+
+	for _, cNode := range cNodes {
+		payload := &types.AuthUserIngestAsset{
+			IngestID:   uuid.NewString(),
+			UserID:     req.UserID,
+			AssetSize:  req.AssetSize,
+			Expiration: time.Now().Add(ingestTaskExpiration),
+		}
+
+		token, err := cNode.API.CreateIngestToken(context.Background(), payload)
+		if err != nil {
+			return nil, &api.ErrWeb{Code: terrors.RequestNodeErr.Int(), Message: err.Error()}
+		}
+
+		m.ingestTasks.Store(payload.IngestID, &ingestTask{nodeID: cNode.NodeID, req: req, expiration: payload.Expiration})
+
+		return &types.CreateAssetRsp{UploadURL: nodeURL(cNode, "/ingest"), Token: token}, nil
+	}
+
+	return nil, &api.ErrWeb{Code: terrors.NotFoundNode.Int(), Message: terrors.NotFoundNode.String()}
+}
+
+// LoadIngestTask removes and returns the ingest request of the task, the task must be ingested by the node
+func (m *Manager) LoadIngestTask(ingestID, nodeID string) (*types.IngestAssetReq, error) {
+	v, ok := m.ingestTasks.Load(ingestID)
+	if !ok {
+		return nil, xerrors.Errorf("ingest task %s not found", ingestID)
+	}
+
+	task := v.(*ingestTask)
+	if task.nodeID != nodeID {
+		return nil, xerrors.Errorf("ingest task %s is not assigned to node %s", ingestID, nodeID)
+	}
+
+	m.ingestTasks.Delete(ingestID)
+
+	if task.expiration.Before(time.Now()) {
+		return nil, xerrors.Errorf("ingest task %s is expired", ingestID)
+	}
+
+	return task.req, nil
+}
+
+// CreateIngestedAssetTask records the asset ingested by the candidate and starts the replication,
+// the candidate is the seed of the asset
+func (m *Manager) CreateIngestedAssetTask(req *types.CreateAssetReq) error {
+	// Waiting for state machine initialization
+	m.stateMachineWait.Wait()
+	log.Infof("asset event: %s, add ingested asset from %s", req.AssetCID, req.NodeID)
+
+	hash, err := cidutil.CIDToHash(req.AssetCID)
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.CidToHashFiled.Int(), Message: err.Error()}
+	}
+
+	if err := m.checkDenied(hash, req.AssetCID); err != nil {
+		return err
+	}
+
+	alreadyExists, err := m.saveUserAssetRecord(hash, req)
+	if err != nil {
+		return err
+	}
+
+	if alreadyExists {
+		return nil
+	}
+
+	rInfo := AssetForceState{
+		State:      UploadInit,
+		SeedNodeID: req.NodeID,
+	}
+
+	// create asset task
+	err = m.assetStateMachines.Send(AssetHash(hash), rInfo)
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.NotFound.Int(), Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (m *Manager) removeExpiredIngestTasks() {
+	now := time.Now()
+	m.ingestTasks.Range(func(key, value interface{}) bool {
+		if value.(*ingestTask).expiration.Before(now) {
+			m.ingestTasks.Delete(key)
+		}
+		return true
+	})
+}
@@ -2,7 +2,6 @@ package assets
 
 import (
 	"context"
-	"crypto"
 	"database/sql"
 	"fmt"
 	"math/rand"
@@ -13,15 +12,20 @@ import (
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/terrors"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/filecoin-project/go-statemachine"
 	"github.com/ipfs/go-datastore"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 
 	"github.com/Filecoin-Titan/titan/node/cidutil"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/decision"
+	"github.com/Filecoin-Titan/titan/node/scheduler/denylist"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/traffic"
 	logging "github.com/ipfs/go-log/v2"
 	"golang.org/x/xerrors"
 )
@@ -51,6 +55,8 @@ const (
 	assetTimeoutLimit = 3
 
 	checkAssetReplicaLimit = 100
+	// the pending pulls of a node listed at most
+	loadPendingPullsLimit = 100
 )
 
 // Manager manages asset replicas
@@ -60,6 +66,10 @@ type Manager struct {
 	assetStateMachines *statemachine.StateGroup
 	pullingAssets      sync.Map                      // map[string]int                // Assignments where assets are being pulled
 	config             dtypes.GetSchedulerConfigFunc // scheduler config
+	trafficMgr         *traffic.Manager              // accounts the bytes downloaded by the replications
+	decisionMgr        *decision.Manager             // records the nodes chosen to pull the assets
+	denylistMgr        *denylist.Manager             // the banned assets are not pulled
+	jobs               *jobqueue.Queue               // runs the pulls fanned out to the nodes
 	*db.SQLDB
 	assetRemoveWaitGroup map[string]*sync.WaitGroup
 	removeMapLock        sync.Mutex
@@ -70,6 +80,18 @@ type Manager struct {
 	fillAssetNodes sync.Map // The node that is downloading data from aws
 
 	isPullSpecifyAsset bool
+
+	ingestTasks sync.Map // map[string]*ingestTask, the assets waiting to be ingested by candidates
+
+	seeding *seedingTracker // the waves of the edges pulling the new assets from the candidates
+
+	reconciler *replicaReconciler // the repairs of the under-replicated assets
+
+	replicaSubsLk    sync.RWMutex
+	onReplicaChanged []func(hash string)
+
+	seedSourcesLk sync.RWMutex
+	seedSources   []SeedSourceFunc
 }
 
 type pullingAssetsInfo struct {
@@ -78,20 +100,36 @@ type pullingAssetsInfo struct {
 }
 
 // NewManager returns a new AssetManager instance
-func NewManager(nodeManager *node.Manager, ds datastore.Batching, configFunc dtypes.GetSchedulerConfigFunc, sdb *db.SQLDB) *Manager {
+func NewManager(nodeManager *node.Manager, ds datastore.Batching, configFunc dtypes.GetSchedulerConfigFunc, sdb *db.SQLDB, tmgr *traffic.Manager, dmgr *decision.Manager, denylistMgr *denylist.Manager, jq *jobqueue.Queue) *Manager {
 	m := &Manager{
-		nodeMgr: nodeManager,
+		nodeMgr:     nodeManager,
+		trafficMgr:  tmgr,
+		decisionMgr: dmgr,
+		denylistMgr: denylistMgr,
+		jobs:        jq,
 		// pullingAssets:        make(map[string]int),
 		config:               configFunc,
 		SQLDB:                sdb,
 		assetRemoveWaitGroup: make(map[string]*sync.WaitGroup),
 		fillSwitch:           true,
+		seeding:              newSeedingTracker(),
+		reconciler:           newReplicaReconciler(),
 	}
 
 	// state machine initialization
 	m.stateMachineWait.Add(1)
 	m.assetStateMachines = statemachine.New(ds, m, AssetPullingInfo{})
 
+	jq.Register(jobPullFanout, m.runPullFanout)
+	jq.Register(jobGeoReconcile, m.runGeoReconcile)
+	jq.Register(node.JobDeactivateCleanup, m.runDeactivateCleanup)
+
+	if denylistMgr != nil {
+		denylistMgr.Subscribe(func(hash string) {
+			go m.purgeDeniedAsset(hash)
+		})
+	}
+
 	return m
 }
 
@@ -103,6 +141,8 @@ func (m *Manager) Start(ctx context.Context) {
 
 	// go m.startCheckAssetsTimer()
 	go m.startCheckPullProgressesTimer()
+	go m.startGeoReconcileTimer()
+	go m.startReconcileTimer()
 	// go m.startCheckCandidateBackupTimer()
 	go m.initFillDiskTimer()
 }
@@ -184,8 +224,11 @@ func (m *Manager) setAssetTimeout(hash, msg string) {
 			if node.PullAssetCount < 0 {
 				node.PullAssetCount = 0
 			}
+		}
 
-			go node.DeleteAsset(context.Background(), cid)
+		// the partial replicas of the offline nodes are deleted after they connect again
+		if err := m.nodeMgr.DeleteAssetOfNode(nodeID, cid); err != nil {
+			log.Errorf("setAssetTimeout DeleteAssetOfNode %s err:%s", nodeID, err.Error())
 		}
 	}
 
@@ -317,17 +360,74 @@ func (m *Manager) CreateAssetUploadTask(hash string, req *types.CreateAssetReq)
 	// Waiting for state machine initialization
 	m.stateMachineWait.Wait()
 	log.Infof("asset event: %s, add asset ", req.AssetCID)
+
+	if err := m.checkDenied(hash, req.AssetCID); err != nil {
+		return nil, err
+	}
+
+	alreadyExists, err := m.saveUserAssetRecord(hash, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if alreadyExists {
+		return &types.CreateAssetRsp{AlreadyExists: true}, nil
+	}
+
+	cNode := m.nodeMgr.GetCandidateNode(req.NodeID)
+	if cNode == nil {
+		cNodes, str := m.chooseCandidateNodes(hash, 1, nil, types.AssetQoSTierStandard)
+		if len(cNodes) == 0 {
+			return nil, &api.ErrWeb{Code: terrors.NotFoundNode.Int(), Message: fmt.Sprintf("not found node :%s", str)}
+		}
+
+		for _, n := range cNodes {
+			cNode = n
+			break
+		}
+	}
+
+	payload := &types.AuthUserUploadDownloadAsset{
+		UserID:     req.UserID,
+		AssetCID:   req.AssetCID,
+		AssetSize:  req.AssetSize,
+		Expiration: time.Now().Add(time.Hour),
+	}
+
+	token, err := cNode.API.CreateAsset(context.Background(), payload)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.RequestNodeErr.Int(), Message: err.Error()}
+	}
+
+	rInfo := AssetForceState{
+		State: UploadInit,
+		// Requester:  req.UserID,
+		SeedNodeID: cNode.NodeID,
+	}
+
+	// create asset task
+	err = m.assetStateMachines.Send(AssetHash(hash), rInfo)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: err.Error()}
+	}
+
+	return &types.CreateAssetRsp{UploadURL: nodeURL(cNode, "/upload"), Token: token}, nil
+}
+
+// saveUserAssetRecord saves the asset of the user and the asset record waiting for upload,
+// returns true if the asset record already exists and does not need to be uploaded again
+func (m *Manager) saveUserAssetRecord(hash string, req *types.CreateAssetReq) (bool, error) {
 	exist, err := m.AssetExistsOfUser(hash, req.UserID)
 	if err != nil {
-		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+		return false, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
 	}
 
 	if exist {
-		return nil, &api.ErrWeb{Code: terrors.NoDuplicateUploads.Int(), Message: fmt.Sprintf("Asset is exist, no duplicate uploads")}
+		return false, &api.ErrWeb{Code: terrors.NoDuplicateUploads.Int(), Message: fmt.Sprintf("Asset is exist, no duplicate uploads")}
 	}
 
 	if m.getPullingAssetLen() >= m.getAssetPullTaskLimit() {
-		return nil, &api.ErrWeb{Code: terrors.BusyServer.Int(), Message: fmt.Sprintf("The task has reached its limit. Please try again later.")}
+		return false, &api.ErrWeb{Code: terrors.BusyServer.Int(), Message: fmt.Sprintf("The task has reached its limit. Please try again later.")}
 	}
 
 	replicaCount := int64(10)
@@ -342,20 +442,40 @@ func (m *Manager) CreateAssetUploadTask(hash string, req *types.CreateAssetReq)
 
 	assetRecord, err := m.LoadAssetRecord(hash)
 	if err != nil && err != sql.ErrNoRows {
-		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+		return false, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	exists := assetRecord != nil && assetRecord.State != "" && assetRecord.State != Remove.String() && assetRecord.State != UploadFailed.String()
+
+	// every owner of an asset stored once is charged the size of the stored asset
+	if exists && assetRecord.TotalSize > 0 {
+		req.AssetSize = assetRecord.TotalSize
 	}
 
-	err = m.SaveAssetUser(hash, req.UserID, req.AssetName, req.AssetType, req.AssetSize, expiration, req.Password, req.GroupID)
+	err = m.SaveAssetUser(hash, req.UserID, req.AssetName, req.AssetType, req.AssetSize, expiration, req.Password, req.GroupID, req.ContentType, req.Labels)
 	if err != nil {
-		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+		return false, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
 	}
 
-	if assetRecord != nil && assetRecord.State != "" && assetRecord.State != Remove.String() && assetRecord.State != UploadFailed.String() {
-		info := &types.CreateAssetRsp{AlreadyExists: true}
+	if req.Encryption != nil {
+		req.Encryption.Hash = hash
+		req.Encryption.UserID = req.UserID
+		if err = m.SaveAssetEncryption(req.Encryption); err != nil {
+			return false, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+		}
+	}
 
+	if exists {
 		m.UpdateAssetRecordExpiration(hash, expiration)
+		return true, nil
+	}
 
-		return info, nil
+	// the replicas of the asset of a tenant are placed by the geo policy of the tenant
+	if len(req.GeoRules) > 0 {
+		policy := &types.AssetGeoPolicy{Hash: hash, CID: req.AssetCID, Rules: req.GeoRules, UpdatedTime: time.Now()}
+		if err = m.SaveAssetGeoPolicy(policy); err != nil {
+			return false, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+		}
 	}
 
 	record := &types.AssetRecord{
@@ -369,56 +489,24 @@ func (m *Manager) CreateAssetUploadTask(hash string, req *types.CreateAssetReq)
 		State:                 UploadInit.String(),
 		TotalSize:             req.AssetSize,
 		CreatedTime:           time.Now(),
+		QoSTier:               types.AssetQoSTierStandard,
 	}
 
 	err = m.SaveAssetRecord(record)
 	if err != nil {
-		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
-	}
-
-	cNode := m.nodeMgr.GetCandidateNode(req.NodeID)
-	if cNode == nil {
-		cNodes, str := m.chooseCandidateNodes(1, nil)
-		if len(cNodes) == 0 {
-			return nil, &api.ErrWeb{Code: terrors.NotFoundNode.Int(), Message: fmt.Sprintf("not found node :%s", str)}
-		}
-
-		for _, n := range cNodes {
-			cNode = n
-			break
-		}
-	}
-
-	payload := &types.AuthUserUploadDownloadAsset{
-		UserID:     req.UserID,
-		AssetCID:   req.AssetCID,
-		AssetSize:  req.AssetSize,
-		Expiration: time.Now().Add(time.Hour),
-	}
-
-	token, err := cNode.API.CreateAsset(context.Background(), payload)
-	if err != nil {
-		return nil, &api.ErrWeb{Code: terrors.RequestNodeErr.Int(), Message: err.Error()}
-	}
-
-	rInfo := AssetForceState{
-		State: UploadInit,
-		// Requester:  req.UserID,
-		SeedNodeID: cNode.NodeID,
+		return false, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
 	}
 
-	// create asset task
-	err = m.assetStateMachines.Send(AssetHash(hash), rInfo)
-	if err != nil {
-		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: err.Error()}
-	}
+	return false, nil
+}
 
-	uploadURL := fmt.Sprintf("http://%s/upload", cNode.RemoteAddr)
-	if len(cNode.ExternalURL) > 0 {
-		uploadURL = fmt.Sprintf("%s/upload", cNode.ExternalURL)
+// nodeURL returns the url of the path on the node, the external url is preferred
+func nodeURL(n *node.Node, path string) string {
+	if len(n.ExternalURL) > 0 {
+		return fmt.Sprintf("%s%s", n.ExternalURL, path)
 	}
 
-	return &types.CreateAssetRsp{UploadURL: uploadURL, Token: token}, nil
+	return fmt.Sprintf("http://%s%s", n.RemoteAddr, path)
 }
 
 func (m *Manager) CreateBaseAsset(cid, nodeID string, size, replicas int64) error {
@@ -439,6 +527,10 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 	// Waiting for state machine initialization
 	m.stateMachineWait.Wait()
 
+	if err := m.checkDenied(info.Hash, info.CID); err != nil {
+		return err
+	}
+
 	if m.getPullingAssetLen() >= m.getAssetPullTaskLimit() {
 		return xerrors.Errorf("The asset in the pulling exceeds the limit %d, please wait", m.getAssetPullTaskLimit())
 	}
@@ -451,6 +543,14 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 		return xerrors.Errorf("The number of bandwidthDown %d exceeds the limit %d", info.Bandwidth, assetBandwidthLimit)
 	}
 
+	if !info.QoSTier.IsValid() {
+		return xerrors.Errorf("invalid qos tier %s", info.QoSTier)
+	}
+
+	if !info.VideoFormat.IsValid() {
+		return xerrors.Errorf("invalid video format %s", info.VideoFormat)
+	}
+
 	log.Infof("asset event: %s, add asset replica: %d,expiration: %s", info.CID, info.Replicas, info.Expiration.String())
 
 	assetRecord, err := m.LoadAssetRecord(info.Hash)
@@ -474,6 +574,8 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 			State:                 SeedSelect.String(),
 			CreatedTime:           time.Now(),
 			Note:                  info.Bucket,
+			QoSTier:               info.QoSTier.OrDefault(),
+			VideoFormat:           info.VideoFormat,
 		}
 
 		err = m.SaveAssetRecord(record)
@@ -513,6 +615,12 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 	assetRecord.Expiration = info.Expiration
 	assetRecord.NeedBandwidth = info.Bandwidth
 	assetRecord.NeedCandidateReplicas = info.CandidateReplicas
+	if info.QoSTier != "" {
+		assetRecord.QoSTier = info.QoSTier
+	}
+	if info.VideoFormat != types.AssetVideoFormatNone {
+		assetRecord.VideoFormat = info.VideoFormat
+	}
 
 	return m.replenishAssetReplicas(assetRecord, 0, info.Bucket, "", SeedSelect, info.SeedNodeID)
 }
@@ -521,6 +629,10 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 func (m *Manager) replenishAssetReplicas(assetRecord *types.AssetRecord, replenishReplicas int64, note, details string, state AssetState, seedNodeID string) error {
 	log.Debugf("replenishAssetReplicas : %d", replenishReplicas)
 
+	if err := m.checkDenied(assetRecord.Hash, assetRecord.CID); err != nil {
+		return err
+	}
+
 	record := &types.AssetRecord{
 		Hash:                  assetRecord.Hash,
 		CID:                   assetRecord.CID,
@@ -534,6 +646,8 @@ func (m *Manager) replenishAssetReplicas(assetRecord *types.AssetRecord, repleni
 		TotalSize:             assetRecord.TotalSize,
 		CreatedTime:           assetRecord.CreatedTime,
 		Note:                  note,
+		QoSTier:               assetRecord.QoSTier.OrDefault(),
+		VideoFormat:           assetRecord.VideoFormat,
 	}
 
 	err := m.SaveAssetRecord(record)
@@ -572,6 +686,10 @@ func (m *Manager) RestartPullAssets(hashes []types.AssetHash) error {
 			continue
 		}
 
+		if m.denylistMgr != nil && m.denylistMgr.IsDenied(hash.String()) {
+			continue
+		}
+
 		err := m.assetStateMachines.Send(AssetHash(hash), PullAssetRestart{})
 		if err != nil {
 			log.Errorf("RestartPullAssets send err:%s", err.Error())
@@ -581,12 +699,23 @@ func (m *Manager) RestartPullAssets(hashes []types.AssetHash) error {
 	return nil
 }
 
+// PendingPullsOfNode returns the replicas waiting or being pulled by the node
+func (m *Manager) PendingPullsOfNode(nodeID string) ([]*types.NodeReplicaInfo, error) {
+	rsp, err := m.LoadAllReplicasByNodeID(nodeID, loadPendingPullsLimit, 0, []types.ReplicaStatus{types.ReplicaStatusWaiting, types.ReplicaStatusPulling})
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.NodeReplicaInfos, nil
+}
+
 // RemoveReplica remove a replica for node
 func (m *Manager) RemoveReplica(cid, hash, nodeID string) error {
 	err := m.DeleteAssetReplica(hash, nodeID)
 	if err != nil {
 		return xerrors.Errorf("RemoveReplica %s DeleteAssetReplica err: %s", hash, err.Error())
 	}
+	m.notifyReplicaChanged(hash)
 
 	// asset view
 	err = m.removeAssetFromView(nodeID, cid)
@@ -594,7 +723,9 @@ func (m *Manager) RemoveReplica(cid, hash, nodeID string) error {
 		return xerrors.Errorf("RemoveReplica %s removeAssetFromView err: %s", hash, err.Error())
 	}
 
-	go m.requestAssetDelete(nodeID, cid)
+	if err = m.requestAssetDelete(nodeID, cid); err != nil {
+		log.Errorf("RemoveReplica %s requestAssetDelete %s err:%s", hash, nodeID, err.Error())
+	}
 
 	return nil
 }
@@ -707,6 +838,10 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 			continue
 		}
 
+		if progress.Status != types.ReplicaStatusPulling {
+			m.notifyReplicaChanged(hash)
+		}
+
 		if progress.Status == types.ReplicaStatusPulling {
 			err = m.assetStateMachines.Send(AssetHash(hash), InfoUpdate{
 				Blocks: int64(progress.BlocksCount),
@@ -729,6 +864,7 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 				continue
 			}
 			cids = append(cids, record.CID)
+			m.trafficMgr.AddDownload(nodeID, cInfo.DoneSize)
 
 			err = m.SaveReplicaEvent(cInfo.Hash, record.CID, cInfo.NodeID, cInfo.DoneSize, record.Expiration, types.ReplicaEventAdd)
 			if err != nil {
@@ -816,6 +952,50 @@ func (m *Manager) UpdateAssetExpiration(cid string, t time.Time) error {
 	return m.UpdateAssetRecordExpiration(hash, t)
 }
 
+// UpdateAssetQoSTier updates the qos tier of the asset, the replicas placed before are kept
+func (m *Manager) UpdateAssetQoSTier(cid string, tier types.AssetQoSTier) error {
+	if tier == "" || !tier.IsValid() {
+		return xerrors.Errorf("invalid qos tier %s", tier)
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("asset event %s, reset asset qos tier:%s", cid, tier)
+
+	if err := m.UpdateAssetRecordQoSTier(hash, tier); err != nil {
+		if err == sql.ErrNoRows {
+			return xerrors.Errorf("asset %s not found", cid)
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateAssetVideoFormat marks the asset as hls or dash, the none format removes the mark
+func (m *Manager) UpdateAssetVideoFormat(cid string, format types.AssetVideoFormat) error {
+	if !format.IsValid() {
+		return xerrors.Errorf("invalid video format %s", format)
+	}
+
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("asset event %s, reset asset video format:%s", cid, format)
+
+	if err := m.UpdateAssetRecordVideoFormat(hash, format); err != nil {
+		if err == sql.ErrNoRows {
+			return xerrors.Errorf("asset %s not found", cid)
+		}
+		return err
+	}
+	return nil
+}
+
 // processMissingAssetReplicas checks for missing replicas of assets and adds missing replicas
 func (m *Manager) processMissingAssetReplicas(offset int) int {
 	aRows, err := m.LoadAllAssetRecords(m.nodeMgr.ServerID, checkAssetReplicaLimit, offset, []string{Servicing.String(), EdgesFailed.String()})
@@ -910,20 +1090,32 @@ func (m *Manager) processExpiredAssets() {
 	}
 
 	for _, record := range records {
+		// the asset is kept for its owners whose assets have not expired
+		kept, err := m.ExpireAssetOwners(record.Hash, record.CID)
+		if err != nil {
+			log.Errorf("ExpireAssetOwners %s err:%s", record.Hash, err.Error())
+			continue
+		}
+
+		if kept > 0 {
+			log.Infof("the asset cid(%s) has expired for some of its owners, kept for %d owners", record.CID, kept)
+			continue
+		}
+
+		if err = m.SaveAssetLifecycleEvent(types.OutboxAssetExpired, record.Hash, record.CID); err != nil {
+			log.Errorf("SaveAssetLifecycleEvent %s err:%s", record.Hash, err.Error())
+		}
+
 		// do remove
 		err = m.RemoveAsset(record.Hash, false)
 		log.Infof("the asset cid(%s) has expired, being removed, err: %v", record.CID, err)
 	}
 }
 
-// requestAssetDelete notifies a node to delete an asset by its CID
+// requestAssetDelete notifies a node to delete an asset by its CID, the delete is kept in the inbox of the node until
+// the node acknowledges it
 func (m *Manager) requestAssetDelete(nodeID, cid string) error {
-	node := m.nodeMgr.GetNode(nodeID)
-	if node != nil {
-		return node.DeleteAsset(context.Background(), cid)
-	}
-
-	return xerrors.Errorf("node %s not found", nodeID)
+	return m.nodeMgr.DeleteAssetOfNode(nodeID, cid)
 }
 
 // GetCandidateReplicaCount get the candidate replica count from the configuration
@@ -965,7 +1157,30 @@ func (m *Manager) saveReplicaInformation(nodes map[string]*node.Node, hash strin
 		node.PullAssetCount++
 	}
 
-	return m.SaveReplicasStatus(replicaInfos)
+	if err := m.SaveReplicasStatus(replicaInfos); err != nil {
+		return err
+	}
+
+	m.notifyReplicaChanged(hash)
+	return nil
+}
+
+// SubscribeReplicaChanges registers the function called for the assets whose replicas are added, finished or removed
+func (m *Manager) SubscribeReplicaChanges(fn func(hash string)) {
+	m.replicaSubsLk.Lock()
+	defer m.replicaSubsLk.Unlock()
+
+	m.onReplicaChanged = append(m.onReplicaChanged, fn)
+}
+
+func (m *Manager) notifyReplicaChanged(hash string) {
+	m.replicaSubsLk.RLock()
+	fns := m.onReplicaChanged
+	m.replicaSubsLk.RUnlock()
+
+	for _, fn := range fns {
+		fn(hash)
+	}
 }
 
 // getDownloadSources gets download sources for a given CID
@@ -1005,6 +1220,7 @@ func (m *Manager) getDownloadSources(hash, bucket string) []*types.CandidateDown
 			NodeID:    nodeID,
 			Address:   cNode.DownloadAddr(),
 			AWSBucket: bucket,
+			Protocols: cNode.TransferProtocols(),
 		}
 
 		sources = append(sources, source)
@@ -1013,8 +1229,9 @@ func (m *Manager) getDownloadSources(hash, bucket string) []*types.CandidateDown
 	return sources
 }
 
-// chooseCandidateNodes selects candidate nodes to pull asset replicas
-func (m *Manager) chooseCandidateNodes(count int, filterNodes []string) (map[string]*node.Node, string) {
+// chooseCandidateNodes selects candidate nodes to pull asset replicas,
+// the candidates of the streaming assets are chosen by their upload bandwidth rather than randomly
+func (m *Manager) chooseCandidateNodes(hash string, count int, filterNodes []string, tier types.AssetQoSTier) (map[string]*node.Node, string) {
 	str := fmt.Sprintf("need node:%d , filter node:%d , cur node:%d , randNum : ", count, len(filterNodes), m.nodeMgr.Candidates)
 
 	selectMap := make(map[string]*node.Node)
@@ -1031,26 +1248,76 @@ func (m *Manager) chooseCandidateNodes(count int, filterNodes []string) (map[str
 		filterMap[nodeID] = struct{}{}
 	}
 
+	rec := m.decisionMgr.Begin(types.DecisionPullCandidates, hash, fmt.Sprintf("count:%d,filter:%d,candidates:%d", count, len(filterNodes), m.nodeMgr.Candidates))
+	defer rec.Commit()
+
 	num := count * selectNodeRetryLimit
+	pick := func(i int) (*node.Node, int) {
+		return m.nodeMgr.GetRandomCandidate()
+	}
+
+	if tier == types.AssetQoSTierStreaming {
+		_, candidates := m.nodeMgr.GetAllValidCandidateNodes()
+		sortByQoSTier(candidates, tier)
+		m.loadGeoPlacement(hash, filterNodes).sort(candidates)
+
+		num = len(candidates)
+		pick = func(i int) (*node.Node, int) {
+			return candidates[i], i
+		}
+	}
+	minBandwidthUp := m.streamingMinBandwidthUp()
+	geo := m.loadGeoPlacement(hash, filterNodes)
 
 	for i := 0; i < num; i++ {
-		node, rNum := m.nodeMgr.GetRandomCandidate()
+		node, rNum := pick(i)
 		str = fmt.Sprintf("%s%d,", str, rNum)
 
 		if node == nil {
+			rec.Filter("", "not_found", 0, float64(rNum))
 			continue
 		}
 		nodeID := node.NodeID
+		weight := len(node.SelectWeights())
 
 		if _, exist := filterMap[nodeID]; exist {
+			rec.Filter(nodeID, "has_replica", weight, float64(rNum))
 			continue
 		}
 
 		if node.DiskUsage > maxNodeDiskUsage {
+			rec.Filter(nodeID, "disk_usage", weight, float64(rNum))
+			continue
+		}
+
+		if reason := qosFiltered(node, tier, minBandwidthUp); reason != "" {
+			rec.Filter(nodeID, reason, weight, float64(rNum))
+			continue
+		}
+
+		if node.IsOverloaded() {
+			rec.Filter(nodeID, "overloaded", weight, float64(rNum))
+			continue
+		}
+
+		if node.IsDiskFailing() {
+			rec.Filter(nodeID, "disk_failing", weight, float64(rNum))
+			continue
+		}
+
+		if geo.excluded(node) {
+			rec.Filter(nodeID, "geo_excluded", weight, float64(rNum))
+			continue
+		}
+
+		if _, exist := selectMap[nodeID]; exist {
+			rec.Filter(nodeID, "duplicate", weight, float64(rNum))
 			continue
 		}
 
 		selectMap[nodeID] = node
+		geo.placed(node)
+		rec.Choose(nodeID, weight, float64(rNum))
 		if len(selectMap) >= count {
 			break
 		}
@@ -1064,7 +1331,8 @@ func (m *Manager) chooseCandidateNodes(count int, filterNodes []string) (map[str
 // bandwidthDown: required cumulative bandwidth among selected nodes
 // filterNodes: exclude nodes that have already been considered
 // size: the minimum free storage space required for each selected node
-func (m *Manager) chooseEdgeNodes(count int, bandwidthDown int64, filterNodes []string, size float64) (map[string]*node.Node, string) {
+// tier: the qos tier of the asset, deciding the order and the eligibility of the nodes
+func (m *Manager) chooseEdgeNodes(hash string, count int, bandwidthDown int64, filterNodes []string, size float64, tier types.AssetQoSTier) (map[string]*node.Node, string) {
 	str := fmt.Sprintf("need node:%d , filter node:%d , cur node:%d , randNum : ", count, len(filterNodes), m.nodeMgr.Edges)
 
 	selectMap := make(map[string]*node.Node)
@@ -1083,6 +1351,12 @@ func (m *Manager) chooseEdgeNodes(count int, bandwidthDown int64, filterNodes []
 		filterMap[nodeID] = struct{}{}
 	}
 
+	rec := m.decisionMgr.Begin(types.DecisionPullEdges, hash, fmt.Sprintf("count:%d,bandwidth:%d,filter:%d,size:%.0f", count, bandwidthDown, len(filterNodes), size))
+	defer rec.Commit()
+
+	minBandwidthUp := m.streamingMinBandwidthUp()
+	geo := m.loadGeoPlacement(hash, filterNodes)
+
 	// shouldSelectNode determines whether a given node should be selected based on specific criteria.
 	// It calculates the node's residual capacity and compares it with thresholds and limits to make a decision.
 	//
@@ -1096,21 +1370,46 @@ func (m *Manager) chooseEdgeNodes(count int, bandwidthDown int64, filterNodes []
 			return false
 		}
 		nodeID := node.NodeID
+		weight := len(node.SelectWeights())
 
 		if _, exist := filterMap[nodeID]; exist {
+			rec.Filter(nodeID, "has_replica", weight, node.TitanDiskUsage)
 			return false
 		}
 
 		// Calculate node residual capacity
 		if !node.DiskEnough(size) {
+			rec.Filter(nodeID, "disk_not_enough", weight, node.TitanDiskUsage)
 			return false
 		}
 
 		if _, exist := selectMap[nodeID]; exist {
+			rec.Filter(nodeID, "duplicate", weight, node.TitanDiskUsage)
 			return false
 		}
 
 		if node.PullAssetCount > 0 {
+			rec.Filter(nodeID, "pulling", weight, node.TitanDiskUsage)
+			return false
+		}
+
+		if reason := qosFiltered(node, tier, minBandwidthUp); reason != "" {
+			rec.Filter(nodeID, reason, weight, node.TitanDiskUsage)
+			return false
+		}
+
+		if node.IsOverloaded() {
+			rec.Filter(nodeID, "overloaded", weight, node.TitanDiskUsage)
+			return false
+		}
+
+		if node.IsDiskFailing() {
+			rec.Filter(nodeID, "disk_failing", weight, node.TitanDiskUsage)
+			return false
+		}
+
+		if geo.excluded(node) {
+			rec.Filter(nodeID, "geo_excluded", weight, node.TitanDiskUsage)
 			return false
 		}
 		// pCount, err := m.nodeMgr.GetNodePullingCount(node.NodeID)
@@ -1119,6 +1418,8 @@ func (m *Manager) chooseEdgeNodes(count int, bandwidthDown int64, filterNodes []
 
 		bandwidthDown -= int64(node.BandwidthDown)
 		selectMap[nodeID] = node
+		geo.placed(node)
+		rec.Choose(nodeID, weight, node.TitanDiskUsage)
 		if len(selectMap) >= count && bandwidthDown <= 0 {
 			return true
 		}
@@ -1138,6 +1439,9 @@ func (m *Manager) chooseEdgeNodes(count int, bandwidthDown int64, filterNodes []
 	sort.Slice(nodes, func(i, j int) bool {
 		return nodes[i].TitanDiskUsage < nodes[j].TitanDiskUsage
 	})
+	sortByQoSTier(nodes, tier)
+	// the regions short of replicas by the geo policy of the asset go first
+	geo.sort(nodes)
 
 	for i := 0; i < len(nodes); i++ {
 		node := nodes[i]
@@ -1164,7 +1468,7 @@ func (m *Manager) GetAssetCount() (int, error) {
 	return m.LoadAssetCount(m.nodeMgr.ServerID, Remove.String())
 }
 
-func (m *Manager) generateTokenForDownloadSources(sources []*types.CandidateDownloadInfo, titanRsa *titanrsa.Rsa, assetCID string, clientID string) ([]*types.CandidateDownloadInfo, []*types.TokenPayload, error) {
+func (m *Manager) generateTokenForDownloadSources(sources []*types.CandidateDownloadInfo, assetCID string, clientID string) ([]*types.CandidateDownloadInfo, []*types.TokenPayload, error) {
 	payloads := make([]*types.TokenPayload, 0)
 	downloadSources := make([]*types.CandidateDownloadInfo, 0, len(sources))
 
@@ -1174,7 +1478,7 @@ func (m *Manager) generateTokenForDownloadSources(sources []*types.CandidateDown
 			continue
 		}
 
-		tk, payload, err := node.Token(assetCID, clientID, titanRsa, m.nodeMgr.PrivateKey)
+		tk, payload, err := node.Token(assetCID, clientID, m.nodeMgr.KeyRing)
 		if err != nil {
 			continue
 		}
@@ -1189,8 +1493,24 @@ func (m *Manager) generateTokenForDownloadSources(sources []*types.CandidateDown
 	return downloadSources, payloads, nil
 }
 
+// sendPullRequests sends the pull requests of the asset to the nodes selected in the stage, the dispatch is traced
+// and continued by the nodes
+func (m *Manager) sendPullRequests(ctx context.Context, stage, cid string, nodes map[string]*node.Node, sources map[string][]*types.CandidateDownloadInfo) {
+	ctx, span := tracing.Start(ctx, "assets.pull_dispatch", attribute.String("stage", stage), attribute.String("cid", cid), attribute.Int("nodes", len(nodes)))
+	defer span.End()
+
+	for _, node := range nodes {
+		nodeCtx, nodeSpan := tracing.Start(ctx, "assets.pull_request", attribute.String("node_id", node.NodeID))
+		err := node.PullAsset(nodeCtx, cid, sources[node.NodeID])
+		tracing.End(nodeSpan, err)
+		if err != nil {
+			log.Errorf("%s pull asset err:%s", node.NodeID, err.Error())
+			continue
+		}
+	}
+}
+
 func (m *Manager) GenerateToken(assetCID string, sources []*types.CandidateDownloadInfo, nodes map[string]*node.Node) (map[string][]*types.CandidateDownloadInfo, []*types.TokenPayload, error) {
-	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
 	downloadSources := make(map[string][]*types.CandidateDownloadInfo)
 	tkPayloads := make([]*types.TokenPayload, 0)
 
@@ -1206,7 +1526,7 @@ func (m *Manager) GenerateToken(assetCID string, sources []*types.CandidateDownl
 		}
 		// ss := sources[index]
 
-		newSources, payloads, err := m.generateTokenForDownloadSources(ts, titanRsa, assetCID, node.NodeID)
+		newSources, payloads, err := m.generateTokenForDownloadSources(ts, assetCID, node.NodeID)
 		if err != nil {
 			continue
 		}
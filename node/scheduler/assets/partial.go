@@ -0,0 +1,153 @@
+package assets
+
+import (
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+// partialReplicas returns the replicas of the asset whose pull failed after some of the blocks were pulled,
+// the nodes keep the blocks and resume from them, the replicas holding the most data first
+func (m *Manager) partialReplicas(hash string) ([]*types.ReplicaInfo, error) {
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusFailed})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*types.ReplicaInfo, 0, len(replicas))
+	for _, replica := range replicas {
+		if replica.DoneSize > 0 {
+			out = append(out, replica)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].DoneSize > out[j].DoneSize
+	})
+
+	return out, nil
+}
+
+// choosePartialNodes returns up to count online nodes resuming the partial replicas of the asset,
+// the partial replicas are pulled again before the other nodes are chosen
+func (m *Manager) choosePartialNodes(hash string, count int, isCandidate bool) map[string]*node.Node {
+	out := make(map[string]*node.Node)
+	if count <= 0 {
+		return out
+	}
+
+	replicas, err := m.partialReplicas(hash)
+	if err != nil {
+		log.Errorf("choosePartialNodes %s partialReplicas err:%s", hash, err.Error())
+		return out
+	}
+
+	geo := m.loadGeoPlacement(hash, nil)
+
+	for _, replica := range replicas {
+		if replica.IsCandidate != isCandidate {
+			continue
+		}
+
+		var n *node.Node
+		if isCandidate {
+			n = m.nodeMgr.GetCandidateNode(replica.NodeID)
+		} else {
+			n = m.nodeMgr.GetEdgeNode(replica.NodeID)
+		}
+
+		if n == nil || n.IsOverloaded() || n.IsDiskFailing() || (!isCandidate && n.PullAssetCount > 0) || geo.excluded(n) {
+			continue
+		}
+
+		out[n.NodeID] = n
+		if len(out) >= count {
+			break
+		}
+	}
+
+	if len(out) > 0 {
+		log.Infof("%s resume %d partial replicas", hash, len(out))
+	}
+
+	return out
+}
+
+// ReplicaProgress returns the replicas of the asset, the partial replicas are counted toward the replica targets
+// by the share of the asset they hold
+func (m *Manager) ReplicaProgress(cid, hash string) (*types.ReplicaProgress, error) {
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAssetRecord %s err:%s", hash, err.Error())
+	}
+
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return nil, xerrors.Errorf("LoadReplicasByStatus %s err:%s", hash, err.Error())
+	}
+
+	partials, err := m.partialReplicas(hash)
+	if err != nil {
+		return nil, xerrors.Errorf("partialReplicas %s err:%s", hash, err.Error())
+	}
+
+	out := &types.ReplicaProgress{
+		CID:                   cid,
+		State:                 record.State,
+		TotalSize:             record.TotalSize,
+		NeedEdgeReplicas:      record.NeedEdgeReplica,
+		NeedCandidateReplicas: record.NeedCandidateReplicas,
+		Partials:              make([]*types.PartialReplica, 0, len(partials)),
+	}
+
+	for _, replica := range replicas {
+		if replica.IsCandidate {
+			out.CandidateReplicas++
+		} else {
+			out.EdgeReplicas++
+		}
+	}
+	out.EffectiveEdgeReplicas = float64(out.EdgeReplicas)
+	out.EffectiveCandidateReplicas = float64(out.CandidateReplicas)
+
+	for _, replica := range partials {
+		out.Partials = append(out.Partials, &types.PartialReplica{
+			NodeID:      replica.NodeID,
+			IsCandidate: replica.IsCandidate,
+			DoneSize:    replica.DoneSize,
+			Online:      m.nodeMgr.GetNode(replica.NodeID) != nil,
+		})
+
+		share := partialShare(replica.DoneSize, record.TotalSize)
+		if replica.IsCandidate {
+			out.EffectiveCandidateReplicas += share
+		} else {
+			out.EffectiveEdgeReplicas += share
+		}
+	}
+
+	return out, nil
+}
+
+// partialShare returns the share of the asset held by a partial replica
+func partialShare(doneSize, totalSize int64) float64 {
+	if totalSize <= 0 || doneSize <= 0 {
+		return 0
+	}
+
+	if doneSize >= totalSize {
+		return 1
+	}
+
+	return float64(doneSize) / float64(totalSize)
+}
+
+func mapKeys(nodes map[string]*node.Node) []string {
+	out := make([]string, 0, len(nodes))
+	for nodeID := range nodes {
+		out = append(out, nodeID)
+	}
+	return out
+}
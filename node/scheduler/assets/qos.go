@@ -0,0 +1,51 @@
+package assets
+
+import (
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+// qosFiltered returns the reason the node can not hold the replicas of the tier, empty if it can,
+// the streaming replicas need the upload bandwidth and the edges the users reach without nat traversal
+func qosFiltered(n *node.Node, tier types.AssetQoSTier, minBandwidthUp int64) string {
+	if tier != types.AssetQoSTierStreaming {
+		return ""
+	}
+
+	if n.BandwidthUp < minBandwidthUp {
+		return "low_bandwidth"
+	}
+
+	if n.Type == types.NodeEdge && n.NATType != types.NatTypeNo && n.NATType != types.NatTypeFullCone {
+		return "nat"
+	}
+
+	return ""
+}
+
+// sortByQoSTier orders the nodes the replicas of the tier are placed on, the fastest nodes first for the streaming assets
+// and the slowest first for the bulk assets, the order of the standard assets is kept
+func sortByQoSTier(nodes []*node.Node, tier types.AssetQoSTier) {
+	switch tier {
+	case types.AssetQoSTierStreaming:
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].BandwidthUp > nodes[j].BandwidthUp
+		})
+	case types.AssetQoSTierBulk:
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].BandwidthUp < nodes[j].BandwidthUp
+		})
+	}
+}
+
+func (m *Manager) streamingMinBandwidthUp() int64 {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return 0
+	}
+
+	return cfg.StreamingMinBandwidthUp
+}
@@ -0,0 +1,61 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+func TestQoSFiltered(t *testing.T) {
+	fast := &node.Node{Type: types.NodeEdge, NATType: types.NatTypeNo, BandwidthUp: 100 << 20}
+	slow := &node.Node{Type: types.NodeEdge, NATType: types.NatTypeNo, BandwidthUp: 1 << 20}
+	symmetric := &node.Node{Type: types.NodeEdge, NATType: types.NatTypeSymmetric, BandwidthUp: 100 << 20}
+
+	if reason := qosFiltered(fast, types.AssetQoSTierStreaming, 10<<20); reason != "" {
+		t.Fatalf("expected the fast edge eligible, got %s", reason)
+	}
+
+	if reason := qosFiltered(slow, types.AssetQoSTierStreaming, 10<<20); reason != "low_bandwidth" {
+		t.Fatalf("expected low_bandwidth, got %s", reason)
+	}
+
+	if reason := qosFiltered(symmetric, types.AssetQoSTierStreaming, 0); reason != "nat" {
+		t.Fatalf("expected nat, got %s", reason)
+	}
+
+	// the other tiers take any node
+	for _, tier := range []types.AssetQoSTier{"", types.AssetQoSTierStandard, types.AssetQoSTierBulk} {
+		if reason := qosFiltered(symmetric, tier, 10<<20); reason != "" {
+			t.Fatalf("expected %s eligible, got %s", tier, reason)
+		}
+	}
+}
+
+func TestSortByQoSTier(t *testing.T) {
+	nodes := func() []*node.Node {
+		return []*node.Node{{NodeID: "e_1", BandwidthUp: 2}, {NodeID: "e_2", BandwidthUp: 3}, {NodeID: "e_3", BandwidthUp: 1}}
+	}
+
+	ids := func(nodes []*node.Node) string {
+		out := ""
+		for _, n := range nodes {
+			out += n.NodeID + ","
+		}
+		return out
+	}
+
+	cases := map[types.AssetQoSTier]string{
+		types.AssetQoSTierStandard:  "e_1,e_2,e_3,",
+		types.AssetQoSTierStreaming: "e_2,e_1,e_3,",
+		types.AssetQoSTierBulk:      "e_3,e_1,e_2,",
+	}
+
+	for tier, expected := range cases {
+		list := nodes()
+		sortByQoSTier(list, tier)
+		if got := ids(list); got != expected {
+			t.Fatalf("%s: expected %s, got %s", tier, expected, got)
+		}
+	}
+}
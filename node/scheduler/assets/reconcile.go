@@ -0,0 +1,329 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/docker/go-units"
+	"go.opencensus.io/stats"
+)
+
+const (
+	// Interval to reconcile a batch of the assets, the assets are swept continuously batch by batch
+	reconcileInterval = time.Minute
+	// the backoff before the next repair of an asset still under-replicated, doubled with each repair
+	minRepairBackoff = 10 * time.Minute
+	maxRepairBackoff = 12 * time.Hour
+)
+
+// repairState the repairs of an under-replicated asset
+type repairState struct {
+	attempts int
+	next     time.Time
+}
+
+// replicaReconciler compares the desired replicas of the assets with the replicas on the nodes not offline for long,
+// repairs the under-replicated assets with per-asset backoff and trims the over-replicated ones
+type replicaReconciler struct {
+	lk      sync.Mutex
+	repairs map[string]*repairState // under-replicated assets by hash
+	offset  int
+}
+
+func newReplicaReconciler() *replicaReconciler {
+	return &replicaReconciler{repairs: make(map[string]*repairState)}
+}
+
+// due checks if the repair of the asset is not backing off
+func (r *replicaReconciler) due(hash string, now time.Time) bool {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	state, ok := r.repairs[hash]
+	return !ok || !now.Before(state.next)
+}
+
+// repaired records a repair of the asset, the next repair backs off if the asset is still under-replicated then
+func (r *replicaReconciler) repaired(hash string, now time.Time) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	state, ok := r.repairs[hash]
+	if !ok {
+		state = &repairState{}
+		r.repairs[hash] = state
+	}
+
+	state.attempts++
+	state.next = now.Add(repairBackoff(state.attempts))
+}
+
+// healthy forgets the repairs of the asset
+func (r *replicaReconciler) healthy(hash string) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	delete(r.repairs, hash)
+}
+
+// prune forgets the assets not repaired for long, they are removed or no longer reconciled by the scheduler
+func (r *replicaReconciler) prune(now time.Time) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	for hash, state := range r.repairs {
+		if now.Sub(state.next) > maxRepairBackoff {
+			delete(r.repairs, hash)
+		}
+	}
+}
+
+// backlog returns the under-replicated assets awaiting repair
+func (r *replicaReconciler) backlog() int {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	return len(r.repairs)
+}
+
+// repairBackoff returns the delay before the next repair after the repairs
+func repairBackoff(attempts int) time.Duration {
+	delay := minRepairBackoff
+	for i := 1; i < attempts && delay < maxRepairBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRepairBackoff {
+		delay = maxRepairBackoff
+	}
+
+	return delay
+}
+
+// replicaCounts the replicas of an asset on the nodes not offline for long
+type replicaCounts struct {
+	edges      int
+	candidates int
+	// online the edges holding the replicas online, the replicas can be trimmed from them
+	online []*node.Node
+	// offline the nodes holding the replicas offline for less than maxNodeOfflineTime
+	offline []string
+	// failing the online nodes holding the replicas whose disks are predicted to fail, the replicas are not counted
+	failing []*node.Node
+}
+
+func (m *Manager) startReconcileTimer() {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("assets.reconcile", reconcileInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.reconcileReplicas(time.Now())
+		done()
+	}
+}
+
+// reconcileReplicas reconciles a batch of the servicing assets, the backlog is recorded after each batch
+func (m *Manager) reconcileReplicas(now time.Time) {
+	r := m.reconciler
+	aRows, err := m.LoadAllAssetRecords(m.nodeMgr.ServerID, checkAssetReplicaLimit, r.offset, []string{Servicing.String(), EdgesFailed.String()})
+	if err != nil {
+		log.Errorf("LoadAllAssetRecords err:%s", err.Error())
+		return
+	}
+	defer aRows.Close()
+
+	size := 0
+	for aRows.Next() {
+		size++
+
+		record := &types.AssetRecord{}
+		if err = aRows.StructScan(record); err != nil {
+			log.Errorf("asset StructScan err: %s", err.Error())
+			continue
+		}
+
+		m.reconcileAsset(record, now)
+	}
+
+	if size == checkAssetReplicaLimit {
+		r.offset += size
+	} else {
+		// a sweep of the assets ends
+		r.offset = 0
+		r.prune(now)
+	}
+
+	stats.Record(context.Background(), metrics.SchedulerRepairBacklog.M(int64(r.backlog())))
+}
+
+// reconcileAsset repairs the asset if it has fewer replicas than desired and trims the extra edge replicas otherwise
+func (m *Manager) reconcileAsset(record *types.AssetRecord, now time.Time) {
+	counts, err := m.countReplicas(record.Hash, now, nil)
+	if err != nil {
+		log.Errorf("countReplicas %s err:%s", record.Hash, err.Error())
+		return
+	}
+
+	missingEdges := record.NeedEdgeReplica - int64(counts.edges)
+	missingCandidates := record.NeedCandidateReplicas - int64(counts.candidates)
+
+	if missingEdges <= 0 && missingCandidates <= 0 && record.State == Servicing.String() {
+		m.reconciler.healthy(record.Hash)
+		m.migrateReplicas(record, counts.failing)
+		m.trimReplicas(record, counts)
+		return
+	}
+
+	if !m.reconciler.due(record.Hash, now) {
+		return
+	}
+
+	details := fmt.Sprintf("reconcile edges:%d/%d candidates:%d/%d offline:%v", counts.edges, record.NeedEdgeReplica,
+		counts.candidates, record.NeedCandidateReplicas, counts.offline)
+	if err := m.replenishAssetReplicas(record, max(missingEdges, 0), string(m.nodeMgr.ServerID), details, CandidatesSelect, ""); err != nil {
+		log.Errorf("reconcile %s replenishAssetReplicas err:%s", record.Hash, err.Error())
+		return
+	}
+
+	m.reconciler.repaired(record.Hash, now)
+	stats.Record(context.Background(), metrics.SchedulerReplicaRepairs.M(1))
+	log.Infof("repair asset %s, %s", record.CID, details)
+}
+
+// countReplicas counts the succeeded replicas of the asset, the replicas on the nodes offline for long,
+// on the nodes whose disks are predicted to fail and on the excluded nodes are not counted
+func (m *Manager) countReplicas(hash string, now time.Time, exclude map[string]bool) (*replicaCounts, error) {
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &replicaCounts{}
+	for _, replica := range replicas {
+		if exclude[replica.NodeID] {
+			continue
+		}
+
+		n := m.nodeMgr.GetNode(replica.NodeID)
+		if n == nil {
+			lastSeen, err := m.LoadNodeLastSeenTime(replica.NodeID)
+			if err != nil || lastSeen.Add(maxNodeOfflineTime).Before(now) {
+				continue
+			}
+			counts.offline = append(counts.offline, replica.NodeID)
+		}
+
+		if n != nil && n.IsDiskFailing() {
+			counts.failing = append(counts.failing, n)
+			continue
+		}
+
+		if replica.IsCandidate {
+			counts.candidates++
+			continue
+		}
+
+		counts.edges++
+		if n != nil {
+			counts.online = append(counts.online, n)
+		}
+	}
+
+	return counts, nil
+}
+
+// migrateReplicas removes the replicas on the nodes whose disks are predicted to fail, the asset is repaired
+// on other nodes before since the replicas on the failing nodes are not counted
+func (m *Manager) migrateReplicas(record *types.AssetRecord, failing []*node.Node) {
+	for _, n := range failing {
+		if err := m.RemoveReplica(record.CID, record.Hash, n.NodeID); err != nil {
+			log.Errorf("migrate %s RemoveReplica err:%s", record.Hash, err.Error())
+			continue
+		}
+
+		stats.Record(context.Background(), metrics.SchedulerMigratedReplicas.M(1))
+		log.Infof("migrated replica of asset %s off node %s, its disk is predicted to fail", record.CID, n.NodeID)
+	}
+}
+
+// trimReplicas removes the edge replicas beyond the desired replicas and the slack of the asset,
+// the replicas on the fullest nodes go first while the bandwidth and the geo policy of the asset are kept.
+// The assets filled from aws are not trimmed, they fill the disks of the nodes on purpose
+func (m *Manager) trimReplicas(record *types.AssetRecord, counts *replicaCounts) {
+	if record.Note != "" {
+		return
+	}
+
+	slack := m.replicaTrimSlack()
+	if slack < 0 {
+		return
+	}
+
+	extra := counts.edges - int(record.NeedEdgeReplica) - slack
+	if extra <= 0 {
+		return
+	}
+
+	online := append([]*node.Node(nil), counts.online...)
+	sort.SliceStable(online, func(i, j int) bool {
+		return online[i].TitanDiskUsage > online[j].TitanDiskUsage
+	})
+
+	nodeIDs := make([]string, 0, len(online))
+	bandwidthUp := int64(0)
+	for _, n := range online {
+		nodeIDs = append(nodeIDs, n.NodeID)
+		bandwidthUp += n.BandwidthUp
+	}
+	geo := m.loadGeoPlacement(record.Hash, nodeIDs)
+
+	trimmed := 0
+	for _, n := range online {
+		if trimmed >= extra {
+			break
+		}
+
+		if int64(math.Ceil(float64(bandwidthUp-n.BandwidthUp)/float64(units.MiB))) < record.NeedBandwidth {
+			continue
+		}
+
+		if !geo.removable(n) {
+			continue
+		}
+
+		if err := m.RemoveReplica(record.CID, record.Hash, n.NodeID); err != nil {
+			log.Errorf("trim %s RemoveReplica err:%s", record.Hash, err.Error())
+			continue
+		}
+
+		geo.removed(n)
+		bandwidthUp -= n.BandwidthUp
+		trimmed++
+	}
+
+	if trimmed > 0 {
+		stats.Record(context.Background(), metrics.SchedulerTrimmedReplicas.M(int64(trimmed)))
+		log.Infof("trimmed %d replicas of asset %s, edges:%d desired:%d", trimmed, record.CID, counts.edges, record.NeedEdgeReplica)
+	}
+}
+
+func (m *Manager) replicaTrimSlack() int {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return -1
+	}
+
+	return cfg.ReplicaTrimSlack
+}
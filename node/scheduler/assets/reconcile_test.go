@@ -0,0 +1,44 @@
+package assets
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepairBackoff(t *testing.T) {
+	r := newReplicaReconciler()
+	now := time.Now()
+
+	if !r.due("h1", now) {
+		t.Fatal("expected the first repair due")
+	}
+
+	r.repaired("h1", now)
+	if r.due("h1", now.Add(minRepairBackoff-time.Second)) || !r.due("h1", now.Add(minRepairBackoff)) {
+		t.Fatal("expected the repair backing off")
+	}
+
+	r.repaired("h1", now)
+	if r.due("h1", now.Add(minRepairBackoff)) {
+		t.Fatal("expected the backoff doubled")
+	}
+
+	if r.backlog() != 1 {
+		t.Fatalf("expected backlog 1, got %d", r.backlog())
+	}
+
+	r.healthy("h1")
+	if r.backlog() != 0 || !r.due("h1", now) {
+		t.Fatal("expected the repairs forgotten once healthy")
+	}
+
+	r.repaired("h2", now)
+	r.prune(now.Add(minRepairBackoff + maxRepairBackoff + time.Second))
+	if r.backlog() != 0 {
+		t.Fatal("expected the stale repairs pruned")
+	}
+
+	if repairBackoff(100) != maxRepairBackoff {
+		t.Fatal("expected the backoff capped")
+	}
+}
@@ -0,0 +1,40 @@
+package assets
+
+import (
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+// SeedSourceFunc returns the sources the seed of the asset is pulled from, nil if the seed is pulled from ipfs
+type SeedSourceFunc func(hash, cid string) []*types.CandidateDownloadInfo
+
+// RegisterSeedSources registers the function finding the sources of the seeds, e.g. the nodes of another area
+func (m *Manager) RegisterSeedSources(fn SeedSourceFunc) {
+	m.seedSourcesLk.Lock()
+	defer m.seedSourcesLk.Unlock()
+
+	m.seedSources = append(m.seedSources, fn)
+}
+
+// seedSourcesOf returns the sources of the seed of the asset for each of the nodes, the first registered function
+// finding sources wins
+func (m *Manager) seedSourcesOf(hash, cid string, nodes map[string]*node.Node) map[string][]*types.CandidateDownloadInfo {
+	m.seedSourcesLk.RLock()
+	fns := m.seedSources
+	m.seedSourcesLk.RUnlock()
+
+	for _, fn := range fns {
+		sources := fn(hash, cid)
+		if len(sources) == 0 {
+			continue
+		}
+
+		out := make(map[string][]*types.CandidateDownloadInfo, len(nodes))
+		for nodeID := range nodes {
+			out[nodeID] = sources
+		}
+		return out
+	}
+
+	return nil
+}
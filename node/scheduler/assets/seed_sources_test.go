@@ -0,0 +1,39 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+func TestSeedSourcesOf(t *testing.T) {
+	m := &Manager{}
+	nodes := map[string]*node.Node{"c1": nil, "c2": nil}
+
+	if sources := m.seedSourcesOf("h1", "cid1", nodes); sources != nil {
+		t.Fatalf("expected no sources without finders, got %v", sources)
+	}
+
+	m.RegisterSeedSources(func(hash, cid string) []*types.CandidateDownloadInfo { return nil })
+	m.RegisterSeedSources(func(hash, cid string) []*types.CandidateDownloadInfo {
+		if hash != "h1" {
+			return nil
+		}
+		return []*types.CandidateDownloadInfo{{NodeID: "remote", Address: "1.2.3.4:1234"}}
+	})
+
+	sources := m.seedSourcesOf("h1", "cid1", nodes)
+	if len(sources) != len(nodes) {
+		t.Fatalf("expected the sources of %d nodes, got %d", len(nodes), len(sources))
+	}
+	for nodeID, list := range sources {
+		if len(list) != 1 || list[0].NodeID != "remote" {
+			t.Errorf("unexpected sources %v of node %s", list, nodeID)
+		}
+	}
+
+	if sources := m.seedSourcesOf("h2", "cid2", nodes); sources != nil {
+		t.Errorf("expected no sources of the asset not found, got %v", sources)
+	}
+}
@@ -0,0 +1,327 @@
+package assets
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/alecthomas/units"
+	"github.com/filecoin-project/go-statemachine"
+	"golang.org/x/xerrors"
+)
+
+// the asset fails to select the edges if the candidates stay saturated after the backoffs
+const seedingMaxBackoffs = 20
+
+// seedingWave a wave of edges pulling an asset from the candidates holding it
+type seedingWave struct {
+	number    int
+	size      int
+	succeeds  int            // edge replicas when the wave started
+	slots     map[string]int // upload slots of the candidates taken by the wave
+	done      bool
+	backoffs  int
+	startTime time.Time
+}
+
+// seedingTracker tracks the seeding waves of the assets and the upload slots of the candidates they take
+type seedingTracker struct {
+	lk    sync.Mutex
+	waves map[string]*seedingWave // key: asset hash
+	inUse map[string]int          // key: candidate id
+}
+
+func newSeedingTracker() *seedingTracker {
+	return &seedingTracker{
+		waves: make(map[string]*seedingWave),
+		inUse: make(map[string]int),
+	}
+}
+
+// freeSlots returns the upload slots of the candidate not taken by the waves
+func (t *seedingTracker) freeSlots(nodeID string, slots int) int {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	return slots - t.inUse[nodeID]
+}
+
+// start starts the next wave of the asset, the edges of the wave are spread over the candidates with the most free slots
+func (t *seedingTracker) start(hash string, size, succeeds int, free map[string]int) *seedingWave {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	wave, ok := t.waves[hash]
+	if !ok {
+		wave = &seedingWave{}
+		t.waves[hash] = wave
+	}
+	t.release(wave)
+
+	wave.number++
+	wave.size = size
+	wave.succeeds = succeeds
+	wave.slots = allocateSlots(size, free)
+	wave.done = false
+	wave.backoffs = 0
+	wave.startTime = time.Now()
+
+	for nodeID, n := range wave.slots {
+		t.inUse[nodeID] += n
+	}
+
+	return wave
+}
+
+// allocateSlots takes the slots of the wave from the candidates, the candidates with the most free slots first
+// and the fewest slots taken on a tie
+func allocateSlots(size int, free map[string]int) map[string]int {
+	nodeIDs := make([]string, 0, len(free))
+	for nodeID, n := range free {
+		if n > 0 {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+
+	out := make(map[string]int)
+	for size > 0 && len(nodeIDs) > 0 {
+		sort.Slice(nodeIDs, func(i, j int) bool {
+			fi, fj := free[nodeIDs[i]]-out[nodeIDs[i]], free[nodeIDs[j]]-out[nodeIDs[j]]
+			if fi != fj {
+				return fi > fj
+			}
+			if out[nodeIDs[i]] != out[nodeIDs[j]] {
+				return out[nodeIDs[i]] < out[nodeIDs[j]]
+			}
+			return nodeIDs[i] < nodeIDs[j]
+		})
+
+		nodeID := nodeIDs[0]
+		if free[nodeID]-out[nodeID] <= 0 {
+			break
+		}
+
+		out[nodeID]++
+		size--
+	}
+
+	return out
+}
+
+// backoff counts a wait of the asset for the saturated candidates, it returns the number of the waits
+func (t *seedingTracker) backoff(hash string) int {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	wave, ok := t.waves[hash]
+	if !ok {
+		wave = &seedingWave{done: true}
+		t.waves[hash] = wave
+	}
+	wave.backoffs++
+
+	return wave.backoffs
+}
+
+// end ends the current wave of the asset and releases its slots, nil if the asset is not pulled in waves
+func (t *seedingTracker) end(hash string) *seedingWave {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	wave, ok := t.waves[hash]
+	if !ok || wave.number == 0 {
+		return nil
+	}
+
+	t.release(wave)
+	wave.done = true
+
+	out := *wave
+	return &out
+}
+
+// finish forgets the waves of the asset
+func (t *seedingTracker) finish(hash string) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	if wave, ok := t.waves[hash]; ok {
+		t.release(wave)
+		delete(t.waves, hash)
+	}
+}
+
+func (t *seedingTracker) release(wave *seedingWave) {
+	for nodeID, n := range wave.slots {
+		t.inUse[nodeID] -= n
+		if t.inUse[nodeID] <= 0 {
+			delete(t.inUse, nodeID)
+		}
+	}
+	wave.slots = nil
+}
+
+func (t *seedingTracker) wave(hash string) *seedingWave {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	wave, ok := t.waves[hash]
+	if !ok {
+		return nil
+	}
+
+	out := *wave
+	out.slots = make(map[string]int, len(wave.slots))
+	for nodeID, n := range wave.slots {
+		out.slots[nodeID] = n
+	}
+	return &out
+}
+
+// seedingConfig returns the upload bandwidth of a candidate an edge of a wave takes (unit: B/s)
+// and the wait for the saturated candidates, the bandwidth is 0 if the waves are disabled
+func (m *Manager) seedingConfig() (int64, time.Duration) {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return 0, 0
+	}
+
+	return int64(cfg.SeedingEdgeBandwidth) * int64(units.MiB), time.Duration(cfg.SeedingBackoff) * time.Second
+}
+
+// seedingSources returns the online candidates holding the asset that have free upload slots and their free slots
+func (m *Manager) seedingSources(hash string, edgeBandwidth int64) ([]*types.CandidateDownloadInfo, map[string]int) {
+	sources := make([]*types.CandidateDownloadInfo, 0)
+	free := make(map[string]int)
+
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		log.Errorf("seedingSources %s LoadReplicasByStatus err:%s", hash, err.Error())
+		return sources, free
+	}
+
+	for _, replica := range replicas {
+		if !replica.IsCandidate {
+			continue
+		}
+
+		cNode := m.nodeMgr.GetCandidateNode(replica.NodeID)
+		if cNode == nil || cNode.IsOverloaded() {
+			continue
+		}
+
+		n := m.seeding.freeSlots(cNode.NodeID, int(cNode.BandwidthUp/edgeBandwidth))
+		if n <= 0 {
+			continue
+		}
+
+		free[cNode.NodeID] = n
+		sources = append(sources, &types.CandidateDownloadInfo{
+			NodeID:    cNode.NodeID,
+			Address:   cNode.DownloadAddr(),
+			Protocols: cNode.TransferProtocols(),
+		})
+	}
+
+	return sources, free
+}
+
+// handleSeedingWaveSelect selects the edges of the next wave pulling the asset from the candidates,
+// the wave is sized by the free upload slots of the candidates and waits while they are saturated
+func (m *Manager) handleSeedingWaveSelect(ctx statemachine.Context, info AssetPullingInfo, needCount int, edgeBandwidth int64, backoff time.Duration) error {
+	hash := info.Hash.String()
+
+	sources, free := m.seedingSources(hash, edgeBandwidth)
+	size := 0
+	for _, n := range free {
+		size += n
+	}
+
+	if size < 1 {
+		backoffs := m.seeding.backoff(hash)
+		if backoffs > seedingMaxBackoffs {
+			m.seeding.finish(hash)
+			return ctx.Send(SelectFailed{error: xerrors.New("the candidates of the asset are saturated")})
+		}
+
+		log.Infof("seeding %s: the candidates are saturated, wait %s (%d)", hash, backoff, backoffs)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Context().Done():
+			return ctx.Context().Err()
+		}
+
+		return ctx.Send(SeedingBackoff{})
+	}
+
+	if needCount > 0 && size > needCount {
+		size = needCount
+	}
+
+	// the bandwidth of the asset is met by the next waves
+	nodes, str := m.chooseEdgeNodes(hash, size, 0, info.EdgeReplicaSucceeds, float64(info.Size), info.QoSTier)
+	if len(nodes) < 1 {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
+	}
+
+	downloadSources, payloads, err := m.GenerateToken(info.CID, sources, nodes)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("GenerateToken; %s", err.Error())})
+	}
+
+	err = m.saveReplicaInformation(nodes, hash, false)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("saveReplicaInformation; %s", err.Error())})
+	}
+
+	wave := m.seeding.start(hash, len(nodes), len(info.EdgeReplicaSucceeds), free)
+	log.Infof("seeding %s: wave %d pulls %d edges from %d candidates", hash, wave.number, len(nodes), len(wave.slots))
+
+	m.startAssetTimeoutCounting(hash, 0, info.Size)
+
+	// send a pull request to the node
+	go func() {
+		err = m.SaveTokenPayload(payloads)
+		if err != nil {
+			log.Errorf("%s len:%d SaveTokenPayload err:%s", info.Hash, len(payloads), err.Error())
+		}
+
+		m.sendPullRequests(ctx.Context(), "edges", info.CID, nodes, downloadSources)
+	}()
+
+	return ctx.Send(PullRequestSent{})
+}
+
+// SeedingProgress returns the progress of the edges pulling the asset in waves
+func (m *Manager) SeedingProgress(cid, hash string) (*types.SeedingProgress, error) {
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAssetRecord %s err:%s", hash, err.Error())
+	}
+
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return nil, xerrors.Errorf("LoadReplicasByStatus %s err:%s", hash, err.Error())
+	}
+
+	out := &types.SeedingProgress{CID: cid, State: record.State, NeedEdgeReplicas: record.NeedEdgeReplica}
+	for _, replica := range replicas {
+		if !replica.IsCandidate {
+			out.EdgeReplicas++
+		}
+	}
+
+	if wave := m.seeding.wave(hash); wave != nil {
+		out.Wave = wave.number
+		out.WaveSize = wave.size
+		out.WaveSucceeds = out.EdgeReplicas - wave.succeeds
+		out.WaveDone = wave.done
+		out.Sources = wave.slots
+		out.Backoffs = wave.backoffs
+		out.StartTime = wave.startTime
+	}
+
+	return out, nil
+}
@@ -0,0 +1,45 @@
+package assets
+
+import "testing"
+
+func TestSeedingSlots(t *testing.T) {
+	tracker := newSeedingTracker()
+
+	free := map[string]int{"c_1": 3, "c_2": 1, "c_3": 0}
+	wave := tracker.start("h1", 3, 0, free)
+	if wave.number != 1 {
+		t.Fatalf("expected wave 1, got %d", wave.number)
+	}
+
+	// the edges are spread over the candidates with the most free slots
+	if wave.slots["c_1"] != 2 || wave.slots["c_2"] != 1 || wave.slots["c_3"] != 0 {
+		t.Fatalf("unexpected slots %v", wave.slots)
+	}
+
+	if n := tracker.freeSlots("c_1", 3); n != 1 {
+		t.Fatalf("expected 1 free slot of c_1, got %d", n)
+	}
+
+	ended := tracker.end("h1")
+	if ended == nil || !ended.done {
+		t.Fatalf("expected the wave to be done")
+	}
+
+	if n := tracker.freeSlots("c_1", 3); n != 3 {
+		t.Fatalf("expected the slots of c_1 to be released, got %d free", n)
+	}
+
+	wave = tracker.start("h1", 10, 3, free)
+	if wave.number != 2 || wave.slots["c_1"] != 3 || wave.slots["c_2"] != 1 {
+		t.Fatalf("unexpected wave %d slots %v", wave.number, wave.slots)
+	}
+
+	tracker.finish("h1")
+	if tracker.wave("h1") != nil || len(tracker.inUse) != 0 {
+		t.Fatalf("expected the waves of h1 to be forgotten")
+	}
+
+	if tracker.end("h2") != nil {
+		t.Fatalf("expected no wave of h2")
+	}
+}
@@ -35,6 +35,8 @@ const (
 	Remove AssetState = "Remove"
 	// Stop Stop
 	Stop AssetState = "Stop"
+	// Archived the hot replicas are removed, the asset is kept in the filecoin storage deal
+	Archived AssetState = "Archived"
 )
 
 // String returns the string representation of the AssetState.
@@ -64,5 +66,5 @@ var (
 	}
 
 	// ActiveStates contains a list of asset pull states that represent active.
-	ActiveStates = append(append([]string{Servicing.String(), Stop.String()}, FailedStates...), PullingStates...)
+	ActiveStates = append(append([]string{Servicing.String(), Stop.String(), Archived.String()}, FailedStates...), PullingStates...)
 )
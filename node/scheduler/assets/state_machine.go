@@ -63,10 +63,12 @@ var planners = map[AssetState]func(events []statemachine.Event, state *AssetPull
 		on(PullRequestSent{}, EdgesPulling),
 		on(SelectFailed{}, EdgesFailed),
 		on(SkipStep{}, Servicing),
+		on(SeedingBackoff{}, EdgesSelect),
 	),
 	EdgesPulling: planOne(
 		on(PullFailed{}, EdgesFailed),
 		on(PullSucceed{}, Servicing),
+		on(NextWave{}, EdgesSelect),
 		apply(PulledResult{}),
 	),
 	SeedFailed: planOne(
@@ -82,6 +84,7 @@ var planners = map[AssetState]func(events []statemachine.Event, state *AssetPull
 	Remove:       planOne(),
 	Stop:         planOne(),
 	Servicing:    planOne(),
+	Archived:     planOne(),
 }
 
 // plan creates a plan for the next asset pulling action based on the given events and asset state
@@ -135,6 +138,8 @@ func (m *Manager) plan(events []statemachine.Event, state *AssetPullingInfo) (fu
 		return m.handleRemove, processed, nil
 	case Stop:
 		return m.handleStop, processed, nil
+	case Archived:
+		return m.handleArchived, processed, nil
 	// Fatal errors
 	default:
 		log.Errorf("unexpected asset update state: %s", state.State)
@@ -215,7 +220,7 @@ func (m *Manager) initStateMachines(ctx context.Context) error {
 	}
 
 	for _, asset := range list {
-		if asset.State == Remove || asset.State == Servicing || asset.State == Stop {
+		if asset.State == Remove || asset.State == Servicing || asset.State == Stop || asset.State == Archived {
 			continue
 		}
 
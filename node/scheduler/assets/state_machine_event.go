@@ -124,6 +124,16 @@ func (evt PullSucceed) apply(state *AssetPullingInfo) {
 func (evt PullSucceed) Ignore() {
 }
 
+// NextWave starts the next seeding wave after the edges of the current wave finish pulling
+type NextWave struct{}
+
+func (evt NextWave) apply(state *AssetPullingInfo) {}
+
+// SeedingBackoff retries the seeding wave after the candidates were saturated
+type SeedingBackoff struct{}
+
+func (evt SeedingBackoff) apply(state *AssetPullingInfo) {}
+
 // SkipStep skips the current step
 type SkipStep struct{}
 
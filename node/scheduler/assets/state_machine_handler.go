@@ -58,7 +58,7 @@ func (m *Manager) handleSeedSelect(ctx statemachine.Context, info AssetPullingIn
 		} else {
 			// find nodes
 			str := ""
-			nodes, str = m.chooseCandidateNodes(seedReplicaCount, info.CandidateReplicaSucceeds)
+			nodes, str = m.chooseCandidateNodes(info.Hash.String(), seedReplicaCount, info.CandidateReplicaSucceeds, info.QoSTier)
 			if len(nodes) < 1 {
 				return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
 			}
@@ -75,13 +75,7 @@ func (m *Manager) handleSeedSelect(ctx statemachine.Context, info AssetPullingIn
 
 	// send a cache request to the node
 	go func() {
-		for _, node := range nodes {
-			err := node.PullAsset(ctx.Context(), info.CID, nil)
-			if err != nil {
-				log.Errorf("%s pull asset err:%s", node.NodeID, err.Error())
-				continue
-			}
-		}
+		m.sendPullRequests(ctx.Context(), "seed", info.CID, nodes, m.seedSourcesOf(info.Hash.String(), info.CID, nodes))
 	}()
 
 	return ctx.Send(PullRequestSent{})
@@ -177,11 +171,22 @@ func (m *Manager) handleCandidatesSelect(ctx statemachine.Context, info AssetPul
 
 		m.removeNodesFromFillAsset(info.CID, true)
 	} else {
-		// find nodes
-		str := ""
-		nodes, str = m.chooseCandidateNodes(int(needCount), info.CandidateReplicaSucceeds)
-		if len(nodes) < 1 {
-			return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
+		// the partial replicas are resumed first
+		nodes = m.choosePartialNodes(info.Hash.String(), int(needCount), true)
+
+		if remaining := int(needCount) - len(nodes); remaining > 0 {
+			// find nodes
+			filterNodes := append([]string{}, info.CandidateReplicaSucceeds...)
+			filterNodes = append(filterNodes, mapKeys(nodes)...)
+
+			chosen, str := m.chooseCandidateNodes(info.Hash.String(), remaining, filterNodes, info.QoSTier)
+			for nodeID, n := range chosen {
+				nodes[nodeID] = n
+			}
+
+			if len(nodes) < 1 {
+				return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
+			}
 		}
 	}
 
@@ -210,13 +215,7 @@ func (m *Manager) handleCandidatesSelect(ctx statemachine.Context, info AssetPul
 			log.Errorf("%s len:%d SaveTokenPayload err:%s", info.Hash, len(payloads), err.Error())
 		}
 
-		for _, node := range nodes {
-			err := node.PullAsset(ctx.Context(), info.CID, downloadSources[node.NodeID])
-			if err != nil {
-				log.Errorf("%s pull asset err:%s", node.NodeID, err.Error())
-				continue
-			}
-		}
+		m.sendPullRequests(ctx.Context(), "candidates", info.CID, nodes, downloadSources)
 	}()
 
 	return ctx.Send(PullRequestSent{})
@@ -270,9 +269,17 @@ func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingI
 	needBandwidth := info.Bandwidth - m.getCurBandwidthUp(info.EdgeReplicaSucceeds)
 	if needCount < 1 && needBandwidth <= 0 {
 		// The number of edge node replications and the total downlink bandwidth are met
+		m.seeding.finish(info.Hash.String())
 		return ctx.Send(SkipStep{})
 	}
 
+	nodeInfo := m.getNodesFromFillAsset(info.CID)
+
+	// the new assets are pulled from the candidates in waves, the assets filled from aws are pulled directly
+	if edgeBandwidth, backoff := m.seedingConfig(); edgeBandwidth > 0 && info.Note == "" && nodeInfo == nil {
+		return m.handleSeedingWaveSelect(ctx, info, int(needCount), edgeBandwidth, backoff)
+	}
+
 	sources := m.getDownloadSources(info.Hash.String(), info.Note)
 	if len(sources) < 1 && info.Note == "" {
 		return ctx.Send(SelectFailed{error: xerrors.New("source node not found")})
@@ -280,7 +287,6 @@ func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingI
 
 	nodes := make(map[string]*node.Node)
 
-	nodeInfo := m.getNodesFromFillAsset(info.CID)
 	if nodeInfo != nil && nodeInfo.edgeList != nil && len(nodeInfo.edgeList) > 0 {
 		for _, n := range nodeInfo.edgeList {
 			nodes[n.NodeID] = n
@@ -294,11 +300,24 @@ func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingI
 		// 		needCount = sLen
 		// 	}
 		// }
-		// find nodes
-		str := ""
-		nodes, str = m.chooseEdgeNodes(int(needCount), needBandwidth, info.EdgeReplicaSucceeds, float64(info.Size))
-		if len(nodes) < 1 {
-			return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
+		// the partial replicas are resumed first
+		nodes = m.choosePartialNodes(info.Hash.String(), int(needCount), false)
+
+		remaining := int(needCount) - len(nodes)
+		remainingBandwidth := needBandwidth - m.getCurBandwidthUp(mapKeys(nodes))
+		if remaining > 0 || remainingBandwidth > 0 {
+			// find nodes
+			filterNodes := append([]string{}, info.EdgeReplicaSucceeds...)
+			filterNodes = append(filterNodes, mapKeys(nodes)...)
+
+			chosen, str := m.chooseEdgeNodes(info.Hash.String(), remaining, remainingBandwidth, filterNodes, float64(info.Size), info.QoSTier)
+			for nodeID, n := range chosen {
+				nodes[nodeID] = n
+			}
+
+			if len(nodes) < 1 {
+				return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
+			}
 		}
 	}
 
@@ -322,13 +341,7 @@ func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingI
 			log.Errorf("%s len:%d SaveTokenPayload err:%s", info.Hash, len(payloads), err.Error())
 		}
 
-		for _, node := range nodes {
-			err := node.PullAsset(ctx.Context(), info.CID, downloadSources[node.NodeID])
-			if err != nil {
-				log.Errorf("%s pull asset err:%s", node.NodeID, err.Error())
-				continue
-			}
-		}
+		m.sendPullRequests(ctx.Context(), "edges", info.CID, nodes, downloadSources)
 	}()
 
 	return ctx.Send(PullRequestSent{})
@@ -340,10 +353,17 @@ func (m *Manager) handleEdgesPulling(ctx statemachine.Context, info AssetPulling
 	log.Debugf("handle edges pulling, %s ; %d>=%d , %d", info.Hash, int64(len(info.EdgeReplicaSucceeds)), info.EdgeReplicas, needBandwidth)
 
 	if int64(len(info.EdgeReplicaSucceeds)) >= info.EdgeReplicas && needBandwidth <= 0 {
+		m.seeding.finish(info.Hash.String())
 		return ctx.Send(PullSucceed{})
 	}
 
 	if info.EdgeWaitings == 0 {
+		// the seeding wave made progress, the next wave pulls the remaining replicas
+		if wave := m.seeding.end(info.Hash.String()); wave != nil && len(info.EdgeReplicaSucceeds) > wave.succeeds {
+			return ctx.Send(NextWave{})
+		}
+
+		m.seeding.finish(info.Hash.String())
 		return ctx.Send(PullFailed{error: xerrors.New("node pull failed")})
 	}
 
@@ -363,6 +383,7 @@ func (m *Manager) handleServicing(ctx statemachine.Context, info AssetPullingInf
 // handlePullsFailed handles the failed state of asset pulling and retries if necessary
 func (m *Manager) handlePullsFailed(ctx statemachine.Context, info AssetPullingInfo) error {
 	m.stopAssetTimeoutCounting(info.Hash.String())
+	m.seeding.finish(info.Hash.String())
 
 	if info.RetryCount >= int64(MaxRetryCount) {
 		log.Infof("handle pulls failed: %s, retry count: %d", info.Hash.String(), info.RetryCount)
@@ -394,6 +415,7 @@ func (m *Manager) handleUploadFailed(ctx statemachine.Context, info AssetPulling
 func (m *Manager) handleRemove(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Infof("handle remove: %s", info.Hash)
 	m.stopAssetTimeoutCounting(info.Hash.String())
+	m.seeding.finish(info.Hash.String())
 	defer m.AssetRemoveDone(info.Hash.String())
 
 	hash := info.Hash.String()
@@ -412,6 +434,19 @@ func (m *Manager) handleRemove(ctx statemachine.Context, info AssetPullingInfo)
 		}
 	}
 
+	// the purged event keeps the users of the asset for the webhooks of their tenants
+	if err = m.SaveAssetLifecycleEvent(types.OutboxAssetPurged, hash, cid); err != nil {
+		log.Errorf("SaveAssetLifecycleEvent %s err:%s", hash, err.Error())
+	}
+
+	if err = m.DeleteColdAsset(hash); err != nil {
+		log.Errorf("DeleteColdAsset %s err:%s", hash, err.Error())
+	}
+
+	if err = m.DeleteMirroredAsset(hash); err != nil {
+		log.Errorf("DeleteMirroredAsset %s err:%s", hash, err.Error())
+	}
+
 	// remove user asset
 	users, err := m.ListUsersForAsset(hash)
 	for _, user := range users {
@@ -427,8 +462,32 @@ func (m *Manager) handleRemove(ctx statemachine.Context, info AssetPullingInfo)
 func (m *Manager) handleStop(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Infof("handle stop: %s", info.Hash)
 	m.stopAssetTimeoutCounting(info.Hash.String())
+	m.seeding.finish(info.Hash.String())
 
 	// m.DeleteUnfinishedReplicas(info.Hash.String())
 
 	return nil
 }
+
+// handleArchived removes the hot replicas of the asset offloaded into the filecoin storage deal,
+// the asset is pulled back from the deal when it is retrieved again
+func (m *Manager) handleArchived(ctx statemachine.Context, info AssetPullingInfo) error {
+	log.Infof("handle archived: %s", info.Hash)
+	m.stopAssetTimeoutCounting(info.Hash.String())
+	m.seeding.finish(info.Hash.String())
+
+	hash := info.Hash.String()
+
+	cInfos, err := m.LoadReplicasByStatus(hash, types.ReplicaStatusAll)
+	if err != nil {
+		return xerrors.Errorf("ArchiveAsset %s LoadAssetReplicas err:%s", hash, err.Error())
+	}
+
+	for _, cInfo := range cInfos {
+		if err = m.RemoveReplica(info.CID, hash, cInfo.NodeID); err != nil {
+			return xerrors.Errorf("ArchiveAsset %s RemoveReplica err: %s", hash, err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("audit")
+
+const (
+	// maximum length of the recorded arguments and result
+	maxArgsLength   = 4096
+	maxResultLength = 1024
+)
+
+// the roles of the callers whose mutations are recorded
+var auditedRoles = []auth.Permission{api.RoleAdmin, api.RoleOperator, api.RoleWeb}
+
+// Store persists the audit logs
+type Store interface {
+	SaveAuditLog(log *types.AuditLog) error
+}
+
+// SchedulerAPI wraps the scheduler api to record the mutations made by admin, operator and web callers,
+// the mutations are the methods that admin can invoke except the queries
+func SchedulerAPI(a api.Scheduler, store Store) api.Scheduler {
+	var out api.SchedulerStruct
+	proxy(a, &out, store)
+	return &out
+}
+
+func proxy(in interface{}, outstr interface{}, store Store) {
+	outs := api.GetInternalStructs(outstr)
+	for _, out := range outs {
+		rint := reflect.ValueOf(out).Elem()
+		ra := reflect.ValueOf(in)
+
+		for f := 0; f < rint.NumField(); f++ {
+			field := rint.Type().Field(f)
+			fn := ra.MethodByName(field.Name)
+
+			if !isMutation(field.Name, auth.Permission(field.Tag.Get("perm"))) {
+				rint.Field(f).Set(fn)
+				continue
+			}
+
+			rint.Field(f).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) (results []reflect.Value) {
+				results = fn.Call(args)
+
+				ctx := args[0].Interface().(context.Context)
+				if callerRoles := auditedCallerRoles(ctx); len(callerRoles) > 0 {
+					record(store, newAuditLog(ctx, field.Name, callerRoles, args[1:], results[len(results)-1]))
+				}
+
+				return results
+			}))
+		}
+	}
+}
+
+// isMutation checks whether the method can be invoked by admin and is not a query
+func isMutation(funcName string, perms auth.Permission) bool {
+	if api.IsQueryMethod(funcName) {
+		return false
+	}
+
+	isAdmin := false
+	for _, p := range strings.Split(string(perms), ",") {
+		if auth.Permission(p) == api.RoleDefault {
+			return false
+		}
+
+		if auth.Permission(p) == api.RoleAdmin {
+			isAdmin = true
+		}
+	}
+
+	return isAdmin
+}
+
+// auditedCallerRoles returns the roles of the caller that are audited
+func auditedCallerRoles(ctx context.Context) []string {
+	var roles []string
+	for _, perm := range api.GetPerms(ctx) {
+		for _, r := range auditedRoles {
+			if perm == r {
+				roles = append(roles, string(perm))
+			}
+		}
+	}
+
+	return roles
+}
+
+func newAuditLog(ctx context.Context, method string, roles []string, args []reflect.Value, errValue reflect.Value) *types.AuditLog {
+	auditLog := &types.AuditLog{
+		Actor:   handler.GetCallerID(ctx),
+		Role:    strings.Join(roles, ","),
+		Method:  method,
+		Args:    encodeArgs(args),
+		Success: true,
+	}
+
+	if err, ok := errValue.Interface().(error); ok && err != nil {
+		auditLog.Success = false
+		auditLog.Result = truncate(err.Error(), maxResultLength)
+	}
+
+	return auditLog
+}
+
+// encodeArgs encodes the arguments as json array
+func encodeArgs(args []reflect.Value) string {
+	params := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		params = append(params, arg.Interface())
+	}
+
+	buf, err := json.Marshal(params)
+	if err != nil {
+		return truncate(fmt.Sprintf("%v", params), maxArgsLength)
+	}
+
+	return truncate(string(buf), maxArgsLength)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// record saves the audit log, the failure is logged and does not fail the mutation
+func record(store Store, auditLog *types.AuditLog) {
+	if err := store.SaveAuditLog(auditLog); err != nil {
+		log.Errorf("save audit log of %s by %s err:%s", auditLog.Method, auditLog.Actor, err.Error())
+	}
+}
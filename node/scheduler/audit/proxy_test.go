@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"golang.org/x/xerrors"
+)
+
+type memStore struct {
+	logs []*types.AuditLog
+}
+
+func (m *memStore) SaveAuditLog(log *types.AuditLog) error {
+	m.logs = append(m.logs, log)
+	return nil
+}
+
+func callerContext(id string, perms ...auth.Permission) context.Context {
+	ctx := context.WithValue(context.Background(), handler.ID{}, id)
+	return api.WithPerm(ctx, perms)
+}
+
+func TestSchedulerAPI(t *testing.T) {
+	scheduler := &api.SchedulerStruct{}
+	scheduler.NodeAPIStruct.Internal.DeactivateNode = func(ctx context.Context, nodeID string, hours int) error {
+		if nodeID == "" {
+			return xerrors.New("node id can not empty")
+		}
+		return nil
+	}
+	scheduler.NodeAPIStruct.Internal.GetNodeInfo = func(ctx context.Context, nodeID string) (types.NodeInfo, error) {
+		return types.NodeInfo{}, nil
+	}
+
+	store := &memStore{}
+	audited := SchedulerAPI(scheduler, store)
+
+	if err := audited.DeactivateNode(callerContext("admin-token", api.RoleAdmin), "e_1", 24); err != nil {
+		t.Fatal(err)
+	}
+	if err := audited.DeactivateNode(callerContext("operator-token", api.RoleOperator), "", 24); err == nil {
+		t.Fatal("expected error")
+	}
+	// queries and the mutations of other roles are not recorded
+	if _, err := audited.GetNodeInfo(callerContext("admin-token", api.RoleAdmin), "e_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := audited.DeactivateNode(callerContext("e_1", api.RoleEdge), "e_1", 24); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.logs) != 2 {
+		t.Fatalf("expected 2 audit logs, got %d", len(store.logs))
+	}
+
+	first := store.logs[0]
+	if first.Actor != "admin-token" || first.Role != "admin" || first.Method != "DeactivateNode" || first.Args != `["e_1",24]` || !first.Success {
+		t.Fatalf("unexpected audit log %+v", first)
+	}
+
+	second := store.logs[1]
+	if second.Actor != "operator-token" || second.Success || second.Result != "node id can not empty" {
+		t.Fatalf("unexpected audit log %+v", second)
+	}
+}
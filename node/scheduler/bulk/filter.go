@@ -0,0 +1,88 @@
+package bulk
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// filter the conditions the nodes selected by a bulk job meet
+type filter struct {
+	nodeType types.NodeType
+	areaID   string
+	// offlineLonger and offlineShorter the bounds of the time since the node was last seen, not bounded if 0
+	offlineLonger  time.Duration
+	offlineShorter time.Duration
+}
+
+// parseFilter parses the conditions separated by spaces, e.g. "type=edge area=Asia-China offline>7d"
+func parseFilter(expr string) (*filter, error) {
+	f := &filter{}
+
+	conditions := strings.Fields(expr)
+	if len(conditions) == 0 {
+		return nil, xerrors.New("filter is empty")
+	}
+
+	for _, condition := range conditions {
+		i := strings.IndexAny(condition, "=<>")
+		if i <= 0 || i == len(condition)-1 {
+			return nil, xerrors.Errorf("invalid condition %s", condition)
+		}
+
+		key, op, value := condition[:i], condition[i], condition[i+1:]
+		switch {
+		case key == "type" && op == '=':
+			switch value {
+			case types.NodeEdge.String():
+				f.nodeType = types.NodeEdge
+			case types.NodeCandidate.String():
+				f.nodeType = types.NodeCandidate
+			default:
+				return nil, xerrors.Errorf("invalid node type %s", value)
+			}
+		case key == "area" && op == '=':
+			f.areaID = value
+		case key == "offline" && op != '=':
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, err
+			}
+
+			if op == '>' {
+				f.offlineLonger = d
+			} else {
+				f.offlineShorter = d
+			}
+		default:
+			return nil, xerrors.Errorf("unsupported condition %s", condition)
+		}
+	}
+
+	if f.offlineLonger > 0 && f.offlineShorter > 0 && f.offlineShorter <= f.offlineLonger {
+		return nil, xerrors.New("no node is offline in the period")
+	}
+
+	return f, nil
+}
+
+// parseDuration parses the go duration with the d unit for days, e.g. 7d or 12h
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, xerrors.Errorf("invalid duration %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, xerrors.Errorf("invalid duration %s", s)
+	}
+
+	return d, nil
+}
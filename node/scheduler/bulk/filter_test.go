@@ -0,0 +1,34 @@
+package bulk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter("type=edge area=Asia-China-Guangdong offline>7d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.nodeType != types.NodeEdge || f.areaID != "Asia-China-Guangdong" || f.offlineLonger != 7*24*time.Hour || f.offlineShorter != 0 {
+		t.Fatalf("unexpected filter %+v", f)
+	}
+
+	f, err = parseFilter("type=candidate offline<12h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.nodeType != types.NodeCandidate || f.offlineShorter != 12*time.Hour {
+		t.Fatalf("unexpected filter %+v", f)
+	}
+
+	for _, expr := range []string{"", "type=scheduler", "offline=7d", "offline>0d", "version=1", "area=", "offline>2d offline<1d"} {
+		if _, err := parseFilter(expr); err == nil {
+			t.Errorf("expected %q to be invalid", expr)
+		}
+	}
+}
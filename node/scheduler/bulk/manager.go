@@ -0,0 +1,309 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("bulk")
+
+const (
+	// the most nodes a job runs on
+	maxJobNodes = 100000
+	// the length of the message column of the results
+	maxMessageLen = 512
+
+	// jobKind the kind of the queued jobs running the bulk jobs
+	jobKind = "bulk"
+)
+
+// jobPayload the payload of the queued job running a bulk job, the nodes are the pending items of the bulk job
+type jobPayload struct {
+	DeactivateHours int
+}
+
+// Manager runs the admin operations on the nodes listed or selected by a filter as queued jobs,
+// the progress of a job and the result on each node are saved as the nodes are processed.
+// A job runs on the scheduler it is started on, the online nodes are the nodes connected to the scheduler.
+// The job resumes from its pending nodes when the scheduler restarts
+type Manager struct {
+	nodeMgr       *node.Manager
+	assetMgr      *assets.Manager
+	validationMgr *validation.Manager
+	jobs          *jobqueue.Queue
+	*db.SQLDB
+}
+
+// NewManager return new bulk job manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, amgr *assets.Manager, vmgr *validation.Manager, jq *jobqueue.Queue) *Manager {
+	m := &Manager{
+		nodeMgr:       nmgr,
+		assetMgr:      amgr,
+		validationMgr: vmgr,
+		jobs:          jq,
+		SQLDB:         sdb,
+	}
+
+	jq.Register(jobKind, m.run)
+
+	return m
+}
+
+// StartJob starts the job on the listed nodes or the nodes selected by the filter and returns the job id
+func (m *Manager) StartJob(req *types.BulkJobReq) (string, error) {
+	switch req.Operation {
+	case types.BulkDeactivate:
+		if req.DeactivateHours < 0 {
+			return "", xerrors.New("deactivate hours can not be negative")
+		}
+	case types.BulkBan, types.BulkRecomputeWeights, types.BulkRevalidate:
+	default:
+		return "", xerrors.Errorf("unsupported operation %s", req.Operation)
+	}
+
+	nodeIDs := dedup(req.NodeIDs)
+	if len(nodeIDs) == 0 {
+		f, err := parseFilter(req.Filter)
+		if err != nil {
+			return "", err
+		}
+
+		if nodeIDs, err = m.selectNodes(f, time.Now()); err != nil {
+			return "", err
+		}
+	}
+
+	if len(nodeIDs) == 0 {
+		return "", xerrors.New("no node selected")
+	}
+
+	if len(nodeIDs) > maxJobNodes {
+		return "", xerrors.Errorf("the job can not run on more than %d nodes", maxJobNodes)
+	}
+
+	now := time.Now()
+	job := &types.BulkJob{
+		ID:           uuid.NewString(),
+		Operation:    req.Operation,
+		Filter:       req.Filter,
+		ServerID:     string(m.nodeMgr.ServerID),
+		Total:        len(nodeIDs),
+		Status:       types.BulkJobRunning,
+		CreatedTime:  now,
+		FinishedTime: now,
+	}
+
+	if err := m.SaveBulkJob(job, nodeIDs); err != nil {
+		return "", err
+	}
+
+	if _, err := m.jobs.EnqueueWithID(job.ID, jobKind, &jobPayload{DeactivateHours: req.DeactivateHours}, jobqueue.Options{Local: true, MaxAttempts: 3}); err != nil {
+		if err := m.FinishBulkJob(job.ID, types.BulkJobCanceled, time.Now()); err != nil {
+			log.Errorf("FinishBulkJob %s err:%s", job.ID, err.Error())
+		}
+		return "", err
+	}
+
+	log.Infof("bulk job %s started, %s on %d nodes", job.ID, job.Operation, job.Total)
+
+	return job.ID, nil
+}
+
+// CancelJob cancels the running job, the nodes processed are kept
+func (m *Manager) CancelJob(id string) error {
+	job, err := m.LoadBulkJob(id)
+	if err != nil {
+		return err
+	}
+
+	if job.Status != types.BulkJobRunning {
+		return xerrors.Errorf("job %s is %s", id, job.Status)
+	}
+
+	if err := m.jobs.Cancel(id); err != nil {
+		return err
+	}
+
+	return m.FinishBulkJob(id, types.BulkJobCanceled, time.Now())
+}
+
+// selectNodes returns the nodes of the scheduler meeting the filter, the offline nodes are in the area of the scheduler
+func (m *Manager) selectNodes(f *filter, now time.Time) ([]string, error) {
+	var lastSeenBefore, lastSeenAfter time.Time
+	if f.offlineLonger > 0 {
+		lastSeenBefore = now.Add(-f.offlineLonger)
+	}
+	if f.offlineShorter > 0 {
+		lastSeenAfter = now.Add(-f.offlineShorter)
+	}
+
+	nodeIDs, err := m.LoadSchedulerNodeIDs(string(m.nodeMgr.ServerID), f.nodeType, lastSeenBefore, lastSeenAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultArea := m.nodeMgr.Zones()[0]
+
+	out := make([]string, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		areaID := defaultArea
+		if n := m.nodeMgr.GetNode(nodeID); n != nil {
+			if f.offlineLonger > 0 {
+				// the last seen time is saved at intervals, the node is online
+				continue
+			}
+			areaID = n.AreaID
+		}
+
+		if f.areaID != "" && f.areaID != areaID {
+			continue
+		}
+
+		out = append(out, nodeID)
+	}
+
+	return out, nil
+}
+
+// run processes the pending nodes of the bulk job one by one, the context is canceled when the job is canceled
+func (m *Manager) run(ctx context.Context, queued *types.Job, progress jobqueue.Progress) error {
+	var payload jobPayload
+	if err := json.Unmarshal(queued.Payload, &payload); err != nil {
+		return xerrors.Errorf("unmarshal payload err:%s", err.Error())
+	}
+
+	job, err := m.LoadBulkJob(queued.ID)
+	if err != nil {
+		return xerrors.Errorf("LoadBulkJob err:%s", err.Error())
+	}
+
+	if job.Status != types.BulkJobRunning {
+		return nil
+	}
+
+	done := int64(job.Succeeded + job.Failed)
+	for {
+		// the items processed leave the pending items, the next page starts at 0
+		items, err := m.LoadBulkJobItems(job.ID, types.BulkJobItemPending, 0, 0)
+		if err != nil {
+			return xerrors.Errorf("LoadBulkJobItems err:%s", err.Error())
+		}
+
+		if len(items.Data) == 0 {
+			break
+		}
+
+		for _, pending := range items.Data {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			item := &types.BulkJobItem{JobID: job.ID, NodeID: pending.NodeID, Status: types.BulkJobItemSucceeded}
+
+			message, err := m.execute(job.Operation, pending.NodeID, payload.DeactivateHours)
+			if err != nil {
+				item.Status = types.BulkJobItemFailed
+				message = err.Error()
+			}
+
+			if len(message) > maxMessageLen {
+				message = message[:maxMessageLen]
+			}
+			item.Message = message
+			item.UpdatedTime = time.Now()
+
+			if err := m.UpdateBulkJobItem(item); err != nil {
+				return xerrors.Errorf("UpdateBulkJobItem %s err:%s", pending.NodeID, err.Error())
+			}
+
+			done++
+			progress(done, int64(job.Total))
+		}
+	}
+
+	if err := m.FinishBulkJob(job.ID, types.BulkJobCompleted, time.Now()); err != nil {
+		return xerrors.Errorf("FinishBulkJob err:%s", err.Error())
+	}
+
+	log.Infof("bulk job %s completed", job.ID)
+	return nil
+}
+
+// execute runs the operation on the node and returns the message of the result
+func (m *Manager) execute(operation types.BulkOperation, nodeID string, deactivateHours int) (string, error) {
+	switch operation {
+	case types.BulkDeactivate:
+		deactivateTime, err := m.LoadDeactivateNodeTime(nodeID)
+		if err != nil {
+			return "", xerrors.Errorf("LoadDeactivateNodeTime err:%s", err.Error())
+		}
+
+		if deactivateTime > 0 {
+			return "", xerrors.Errorf("node %s is waiting to deactivate", nodeID)
+		}
+
+		return "", m.deactivate(nodeID, time.Now().Add(time.Duration(deactivateHours)*time.Hour).Unix())
+	case types.BulkBan:
+		deactivateTime, err := m.LoadDeactivateNodeTime(nodeID)
+		if err != nil {
+			return "", xerrors.Errorf("LoadDeactivateNodeTime err:%s", err.Error())
+		}
+
+		now := time.Now().Unix()
+		if deactivateTime > 0 && deactivateTime < now {
+			return "already deactivated", nil
+		}
+
+		// the keepalive and the login of the node are refused from now on
+		return "", m.deactivate(nodeID, now-1)
+	case types.BulkRecomputeWeights:
+		return "", m.nodeMgr.RecomputeNodeWeight(nodeID)
+	case types.BulkRevalidate:
+		roundID, err := m.validationMgr.ValidateNode(nodeID)
+		if err != nil {
+			return "", err
+		}
+		return "round " + roundID, nil
+	}
+
+	return "", xerrors.Errorf("unsupported operation %s", operation)
+}
+
+// deactivate deactivates the node at the time, the assets of a candidate are backed up
+func (m *Manager) deactivate(nodeID string, deactivateTime int64) error {
+	if err := m.nodeMgr.DeactivateNode(nodeID, deactivateTime); err != nil {
+		return err
+	}
+
+	if err := m.NodeExists(nodeID, types.NodeCandidate); err == nil {
+		return m.assetMgr.CandidateDeactivate(nodeID)
+	}
+
+	return nil
+}
+
+// dedup returns the ids in order without the duplicates and the empty ids
+func dedup(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+
+	return out
+}
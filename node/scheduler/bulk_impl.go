@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// StartBulkJob starts an admin operation on the listed nodes or the nodes selected by the filter as an asynchronous job
+// and returns the job id, the job runs on the scheduler it is started on
+func (s *Scheduler) StartBulkJob(ctx context.Context, req *types.BulkJobReq) (string, error) {
+	if req == nil {
+		return "", xerrors.New("request can not empty")
+	}
+
+	return s.BulkManager.StartJob(req)
+}
+
+// GetBulkJob retrieves the bulk job with its progress
+func (s *Scheduler) GetBulkJob(ctx context.Context, id string) (*types.BulkJob, error) {
+	return s.BulkManager.LoadBulkJob(id)
+}
+
+// ListBulkJobs retrieves the bulk jobs, the latest first
+func (s *Scheduler) ListBulkJobs(ctx context.Context, limit, offset int) (*types.ListBulkJobRsp, error) {
+	return s.BulkManager.LoadBulkJobs(limit, offset)
+}
+
+// ListBulkJobItems retrieves the results of the bulk job on its nodes, the nodes of all statuses if status is empty
+func (s *Scheduler) ListBulkJobItems(ctx context.Context, jobID string, status types.BulkJobItemStatus, limit, offset int) (*types.ListBulkJobItemRsp, error) {
+	return s.BulkManager.LoadBulkJobItems(jobID, status, limit, offset)
+}
+
+// CancelBulkJob cancels the running bulk job, the results of the nodes processed are kept
+func (s *Scheduler) CancelBulkJob(ctx context.Context, id string) error {
+	return s.BulkManager.CancelJob(id)
+}
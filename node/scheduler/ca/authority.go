@@ -0,0 +1,283 @@
+package ca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"golang.org/x/xerrors"
+)
+
+const (
+	caCommonName = "titan scheduler ca"
+	caValidity   = 10 * 365 * 24 * time.Hour
+	// the certificates are valid a while before they are issued, tolerates clock skew
+	clockSkew = time.Hour
+
+	serverCertValidity = 365 * 24 * time.Hour
+)
+
+// Authority is the certificate authority run by the scheduler, it issues the client certificates
+// of candidate nodes and the server certificate of the scheduler for mutual tls.
+// The ca certificates are self-signed by the scheduler keys, they can be recreated at any time
+// and the certificates issued before stay valid as long as their key is kept in the key ring.
+type Authority struct {
+	keyRing  *keys.Ring
+	serverID dtypes.ServerID
+
+	lk         sync.Mutex
+	current    keys.Key
+	certs      []*x509.Certificate // ca certificates of the current key and the retired keys
+	certPEM    []byte
+	serverCert *tls.Certificate
+}
+
+// NewAuthority creates the certificate authority with the scheduler keys
+func NewAuthority(keyRing *keys.Ring, serverID dtypes.ServerID) (*Authority, error) {
+	a := &Authority{keyRing: keyRing, serverID: serverID}
+	if _, _, err := a.refresh(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// refresh recreates the ca certificates after the scheduler key is rotated,
+// it returns the ca certificate of the current key and the key
+func (a *Authority) refresh() (*x509.Certificate, keys.Key, error) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	ringKeys := a.keyRing.Keys()
+	if a.current == ringKeys[0] {
+		return a.certs[0], a.current, nil
+	}
+
+	certs := make([]*x509.Certificate, 0, len(ringKeys))
+	certPEM := make([]byte, 0)
+	for _, key := range ringKeys {
+		cert, err := newCACertificate(key, a.serverID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		certs = append(certs, cert)
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+
+	a.current = ringKeys[0]
+	a.certs = certs
+	a.certPEM = certPEM
+	a.serverCert = nil
+
+	return certs[0], a.current, nil
+}
+
+func newCACertificate(key keys.Key, serverID dtypes.ServerID) (*x509.Certificate, error) {
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: caCommonName, OrganizationalUnit: []string{string(serverID)}},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, xerrors.Errorf("create ca certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// CertPEM returns the ca certificates of the current key and the retired keys in pem format
+func (a *Authority) CertPEM() []byte {
+	a.refresh() //nolint:errcheck // the previous certificates are kept on error
+
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	return a.certPEM
+}
+
+// CertPool returns a pool contains the ca certificates
+func (a *Authority) CertPool() *x509.CertPool {
+	a.refresh() //nolint:errcheck // the previous certificates are kept on error
+
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	pool := x509.NewCertPool()
+	for _, cert := range a.certs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// IssueClientCertificate verifies the csr and issues a client certificate for the node,
+// the common name of the certificate is the node id
+func (a *Authority) IssueClientCertificate(nodeID string, csrPEM []byte, validity time.Duration) ([]byte, time.Time, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, time.Time{}, xerrors.New("invalid csr pem")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, time.Time{}, xerrors.Errorf("parse csr: %w", err)
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return nil, time.Time{}, xerrors.Errorf("check csr signature: %w", err)
+	}
+
+	if csr.Subject.CommonName != nodeID {
+		return nil, time.Time{}, xerrors.Errorf("csr common name %s mismatch node %s", csr.Subject.CommonName, nodeID)
+	}
+
+	caCert, caKey, err := a.refresh()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject:      pkix.Name{CommonName: nodeID},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, csr.PublicKey, caKey)
+	if err != nil {
+		return nil, time.Time{}, xerrors.Errorf("create certificate: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), template.NotAfter, nil
+}
+
+// ServerTLSConfig returns the tls config of the scheduler server which verifies the client certificates
+// issued by the authority. The server certificate is issued by the authority if base has none,
+// both follow the rotation of the scheduler key.
+func (a *Authority) ServerTLSConfig(base *tls.Config) (*tls.Config, error) {
+	if _, err := a.serverConfig(base); err != nil {
+		return nil, err
+	}
+
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return a.serverConfig(base)
+	}
+
+	return cfg, nil
+}
+
+func (a *Authority) serverConfig(base *tls.Config) (*tls.Config, error) {
+	cfg := base.Clone()
+	if len(cfg.Certificates) == 0 {
+		cert, err := a.getServerCertificate()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+
+	cfg.ClientCAs = a.CertPool()
+	// edge nodes and users connect without client certificates
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+
+	return cfg, nil
+}
+
+func (a *Authority) getServerCertificate() (*tls.Certificate, error) {
+	if _, _, err := a.refresh(); err != nil {
+		return nil, err
+	}
+
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	if a.serverCert != nil {
+		return a.serverCert, nil
+	}
+	caCert := a.certs[0]
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject:      pkix.Name{CommonName: string(a.serverID)},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(serverCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), a.current)
+	if err != nil {
+		return nil, xerrors.Errorf("create server certificate: %w", err)
+	}
+
+	a.serverCert = &tls.Certificate{Certificate: [][]byte{der, caCert.Raw}, PrivateKey: key}
+	return a.serverCert, nil
+}
+
+// VerifyPeer verifies the certificate chain of the peer against the ca certificates without checking the host name,
+// nodes connect to the scheduler by the address the locator returns
+func VerifyPeer(caPEM []byte) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return xerrors.New("no peer certificate")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		return err
+	}
+}
+
+func newSerialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
@@ -0,0 +1,78 @@
+package ca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+)
+
+func newCSR(t *testing.T, cn string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+type staticStore struct {
+	key *rsa.PrivateKey
+}
+
+func (s *staticStore) Load() ([]keys.Key, error) { return []keys.Key{s.key}, nil }
+
+func (s *staticStore) Rotate(int) error { return nil }
+
+func TestIssueClientCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyRing, err := keys.NewRing(&staticStore{key: key}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authority, err := NewAuthority(keyRing, "s_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := authority.IssueClientCertificate("c_1", newCSR(t, "c_2"), time.Hour); err == nil {
+		t.Fatal("expected error for mismatched common name")
+	}
+
+	certPEM, notAfter, err := authority.IssueClientCertificate("c_1", newCSR(t, "c_1"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cert.Subject.CommonName != "c_1" || cert.NotAfter.Unix() != notAfter.Unix() {
+		t.Fatalf("unexpected certificate %s %s", cert.Subject.CommonName, cert.NotAfter)
+	}
+
+	opts := x509.VerifyOptions{Roots: authority.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}
+	if _, err := cert.Verify(opts); err != nil {
+		t.Fatal(err)
+	}
+}
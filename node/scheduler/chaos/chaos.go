@@ -0,0 +1,23 @@
+// Package chaos injects faults into the scheduler to test its failure handling.
+// The faults are only compiled in with the chaos build tag, e.g. go test -tags chaos ./...,
+// otherwise the hooks are no-ops the compiler removes.
+package chaos
+
+import "errors"
+
+// Point a place in the scheduler a fault is injected into
+type Point string
+
+const (
+	// DBWrite the writes of the node, validation and workload information fail
+	DBWrite Point = "db_write"
+	// KeepaliveDelay the keepalives of the nodes are handled late
+	KeepaliveDelay Point = "keepalive_delay"
+	// NodeDisconnect the online nodes stop sending keepalives and are taken offline by the next keepalive check
+	NodeDisconnect Point = "node_disconnect"
+	// PubsubBacklog the node events are published late as if the subscribers fell behind
+	PubsubBacklog Point = "pubsub_backlog"
+)
+
+// ErrInjected the error returned by the injected failures
+var ErrInjected = errors.New("chaos: injected fault")
@@ -0,0 +1,15 @@
+//go:build !chaos
+
+package chaos
+
+// Enabled reports whether the faults are compiled in
+const Enabled = false
+
+// Fail returns nil without the chaos build tag
+func Fail(p Point) error { return nil }
+
+// Hit returns false without the chaos build tag
+func Hit(p Point) bool { return false }
+
+// Sleep returns immediately without the chaos build tag
+func Sleep(p Point) {}
@@ -0,0 +1,108 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Enabled reports whether the faults are compiled in
+const Enabled = true
+
+// Fault how a fault is injected at a point
+type Fault struct {
+	// Rate the probability in [0, 1] the fault is hit each time the point is reached
+	Rate float64
+	// Delay the time the point is held up when the fault is hit, used by the delay points
+	Delay time.Duration
+	// Limit the number of times the fault is hit before it is cleared, unlimited if 0
+	Limit int
+}
+
+type state struct {
+	Fault
+	hits int
+}
+
+var (
+	lk     sync.Mutex
+	faults = make(map[Point]*state)
+	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Set injects the fault at the point, replacing the fault set before
+func Set(p Point, f Fault) {
+	lk.Lock()
+	defer lk.Unlock()
+
+	faults[p] = &state{Fault: f}
+}
+
+// Clear removes the fault at the point
+func Clear(p Point) {
+	lk.Lock()
+	defer lk.Unlock()
+
+	delete(faults, p)
+}
+
+// Reset removes all the faults
+func Reset() {
+	lk.Lock()
+	defer lk.Unlock()
+
+	faults = make(map[Point]*state)
+}
+
+// Hits returns the number of times the fault at the point is hit
+func Hits(p Point) int {
+	lk.Lock()
+	defer lk.Unlock()
+
+	if s, ok := faults[p]; ok {
+		return s.hits
+	}
+	return 0
+}
+
+// hit rolls the fault at the point, it returns the fault if it is hit
+func hit(p Point) (Fault, bool) {
+	lk.Lock()
+	defer lk.Unlock()
+
+	s, ok := faults[p]
+	if !ok || random.Float64() >= s.Rate {
+		return Fault{}, false
+	}
+
+	s.hits++
+	if s.Limit > 0 && s.hits >= s.Limit {
+		// keep the hits readable until the fault is set again
+		s.Rate = 0
+	}
+
+	return s.Fault, true
+}
+
+// Fail returns ErrInjected if the fault at the point is hit
+func Fail(p Point) error {
+	if _, ok := hit(p); ok {
+		return ErrInjected
+	}
+	return nil
+}
+
+// Hit returns true if the fault at the point is hit
+func Hit(p Point) bool {
+	_, ok := hit(p)
+	return ok
+}
+
+// Sleep holds up the caller for the delay of the fault at the point if it is hit
+func Sleep(p Point) {
+	if f, ok := hit(p); ok {
+		time.Sleep(f.Delay)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFaults(t *testing.T) {
+	defer Reset()
+
+	if err := Fail(DBWrite); err != nil {
+		t.Fatalf("expected no failure without fault, got %v", err)
+	}
+
+	Set(DBWrite, Fault{Rate: 1, Limit: 2})
+	for i := 0; i < 3; i++ {
+		err := Fail(DBWrite)
+		if i < 2 && err != ErrInjected {
+			t.Fatalf("expected injected failure %d, got %v", i, err)
+		}
+		if i == 2 && err != nil {
+			t.Fatalf("expected the fault cleared after the limit, got %v", err)
+		}
+	}
+	if Hits(DBWrite) != 2 {
+		t.Fatalf("expected 2 hits, got %d", Hits(DBWrite))
+	}
+
+	Set(NodeDisconnect, Fault{Rate: 0})
+	if Hit(NodeDisconnect) {
+		t.Fatal("expected no hit with rate 0")
+	}
+
+	Set(KeepaliveDelay, Fault{Rate: 1, Delay: 20 * time.Millisecond})
+	start := time.Now()
+	Sleep(KeepaliveDelay)
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("expected the keepalive delayed")
+	}
+
+	Clear(KeepaliveDelay)
+	start = time.Now()
+	Sleep(KeepaliveDelay)
+	if time.Since(start) >= 20*time.Millisecond {
+		t.Fatal("expected no delay after the fault is cleared")
+	}
+}
@@ -0,0 +1,143 @@
+package coldstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// maxResponseSize the bytes of a response of the deal-making component read at most
+const maxResponseSize = 1 << 20
+
+// Proposal the asset offloaded into the storage deals, the deal-making component fetches the asset by its cid
+type Proposal struct {
+	CID  string `json:"cid"`
+	Size int64  `json:"size"`
+}
+
+// DealStatus the state of a deal reported by the deal-making component
+type DealStatus struct {
+	// State proposed, active or failed
+	State       types.ColdAssetState `json:"state"`
+	Provider    string               `json:"provider"`
+	PieceCID    string               `json:"piece_cid"`
+	ChainDealID int64                `json:"chain_deal_id"`
+	Message     string               `json:"message"`
+}
+
+// DealMaker the component making the filecoin storage deals of the cold assets and retrieving the assets from the deals
+type DealMaker interface {
+	// MakeDeal proposes the storage deals of the asset and returns the id the deals are tracked by
+	MakeDeal(ctx context.Context, proposal *Proposal) (string, error)
+	// DealStatus returns the state of the deals
+	DealStatus(ctx context.Context, dealID string) (*DealStatus, error)
+	// Retrieve asks the provider of the deals to serve the asset again, returns true once the candidates can pull the asset
+	// by its cid, the retrieval is started by the first call and polled by the others
+	Retrieve(ctx context.Context, dealID string) (bool, error)
+}
+
+// httpDealMaker calls the deal-making component over http, see documentation/en/cold_storage.md
+type httpDealMaker struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewHTTPDealMaker returns the deal maker calling the deal-making component at the endpoint with the bearer token
+func NewHTTPDealMaker(endpoint, token string) DealMaker {
+	return &httpDealMaker{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// MakeDeal posts the proposal to /deals
+func (d *httpDealMaker) MakeDeal(ctx context.Context, proposal *Proposal) (string, error) {
+	var rsp struct {
+		DealID string `json:"deal_id"`
+	}
+
+	if err := d.call(ctx, http.MethodPost, "/deals", proposal, &rsp); err != nil {
+		return "", err
+	}
+
+	if rsp.DealID == "" {
+		return "", xerrors.New("deal maker returned no deal id")
+	}
+
+	return rsp.DealID, nil
+}
+
+// DealStatus gets /deals/{id}
+func (d *httpDealMaker) DealStatus(ctx context.Context, dealID string) (*DealStatus, error) {
+	status := new(DealStatus)
+	if err := d.call(ctx, http.MethodGet, "/deals/"+url.PathEscape(dealID), nil, status); err != nil {
+		return nil, err
+	}
+
+	switch status.State {
+	case types.ColdAssetProposed, types.ColdAssetActive, types.ColdAssetFailed:
+	default:
+		return nil, xerrors.Errorf("deal maker returned unknown deal state %q", status.State)
+	}
+
+	return status, nil
+}
+
+// Retrieve posts to /deals/{id}/retrieve
+func (d *httpDealMaker) Retrieve(ctx context.Context, dealID string) (bool, error) {
+	var rsp struct {
+		Ready bool `json:"ready"`
+	}
+
+	if err := d.call(ctx, http.MethodPost, "/deals/"+url.PathEscape(dealID)+"/retrieve", nil, &rsp); err != nil {
+		return false, err
+	}
+
+	return rsp.Ready, nil
+}
+
+func (d *httpDealMaker) call(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, d.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.token)
+	}
+
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close() //nolint:errcheck
+
+	buf, err := io.ReadAll(io.LimitReader(rsp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return xerrors.Errorf("deal maker %s %s status code %d: %s", method, path, rsp.StatusCode, strings.TrimSpace(string(buf)))
+	}
+
+	return json.Unmarshal(buf, out)
+}
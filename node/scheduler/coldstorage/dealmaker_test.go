@@ -0,0 +1,76 @@
+package coldstorage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestHTTPDealMaker(t *testing.T) {
+	var proposal Proposal
+	state := "active"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/deals":
+			if err := json.NewDecoder(r.Body).Decode(&proposal); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"deal_id":"d1"}`)) //nolint:errcheck
+		case r.Method == http.MethodGet && r.URL.Path == "/api/deals/d1":
+			w.Write([]byte(`{"state":"` + state + `","provider":"f01234","chain_deal_id":42}`)) //nolint:errcheck
+		case r.Method == http.MethodPost && r.URL.Path == "/api/deals/d1/retrieve":
+			w.Write([]byte(`{"ready":true}`)) //nolint:errcheck
+		default:
+			http.Error(w, "deal not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	maker := NewHTTPDealMaker(srv.URL+"/api/", "secret")
+
+	dealID, err := maker.MakeDeal(ctx, &Proposal{CID: "bafy", Size: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dealID != "d1" || proposal.CID != "bafy" || proposal.Size != 1024 {
+		t.Errorf("unexpected deal %s of proposal %+v", dealID, proposal)
+	}
+
+	status, err := maker.DealStatus(ctx, dealID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != types.ColdAssetActive || status.Provider != "f01234" || status.ChainDealID != 42 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	state = "archived"
+	if _, err = maker.DealStatus(ctx, dealID); err == nil {
+		t.Error("expected an error for a state the deal maker does not report")
+	}
+
+	ready, err := maker.Retrieve(ctx, dealID)
+	if err != nil || !ready {
+		t.Errorf("expected the retrieval ready, got %v %v", ready, err)
+	}
+
+	if _, err = maker.DealStatus(ctx, "d2"); err == nil {
+		t.Error("expected an error for an unknown deal")
+	}
+
+	if _, err = NewHTTPDealMaker(srv.URL+"/api", "").MakeDeal(ctx, &Proposal{CID: "bafy"}); err == nil {
+		t.Error("expected an error without the token")
+	}
+}
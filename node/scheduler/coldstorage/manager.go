@@ -0,0 +1,324 @@
+package coldstorage
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("coldstorage")
+
+const (
+	requestTimeout = 30 * time.Second
+	// the idle assets are offloaded and the proposed deals are tracked once a round
+	roundInterval = 10 * time.Minute
+	// the retrievals of the restoring assets are polled once a minute, the retrieved assets are saved as often
+	restoreInterval = time.Minute
+	flushInterval   = time.Minute
+	// the assets offloaded or tracked in a round at most
+	batchSize = 100
+	// the failed deals of the idle assets are proposed again after the delay
+	retryFailedDelay = 24 * time.Hour
+	maxMessageLen    = 512
+)
+
+// Manager offloads the assets of the scheduler not retrieved for a while into the filecoin storage deals through the
+// deal-making component, and removes their hot replicas once the deals are active. A retrieval of an archived asset
+// asks the provider of the deal to serve it again, and the asset is seeded again once the provider is ready
+type Manager struct {
+	config   dtypes.GetSchedulerConfigFunc
+	assetMgr *assets.Manager
+	serverID dtypes.ServerID
+	*db.SQLDB
+
+	lk        sync.Mutex
+	retrieved map[string]struct{}
+}
+
+// NewManager return new cold storage manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, amgr *assets.Manager, serverID dtypes.ServerID) *Manager {
+	m := &Manager{
+		config:    configFunc,
+		assetMgr:  amgr,
+		serverID:  serverID,
+		SQLDB:     sdb,
+		retrieved: make(map[string]struct{}),
+	}
+
+	go m.startFlushTimer()
+	go m.startRoundTimer()
+	go m.startRestoreTimer()
+
+	return m
+}
+
+// Retrieved records the retrieval of the asset, the assets retrieved are not offloaded until they are idle again
+func (m *Manager) Retrieved(hash string) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	m.retrieved[hash] = struct{}{}
+}
+
+// Restore asks the provider of the deal of the archived asset to serve it again, returns true if the asset is archived
+// and being restored, the scheduler of the asset seeds it again once the provider is ready
+func (m *Manager) Restore(hash string) (bool, error) {
+	asset, err := m.LoadColdAsset(hash)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch asset.State {
+	case types.ColdAssetRestoring:
+		return true, nil
+	case types.ColdAssetArchived:
+		if _, err = m.SwitchColdAssetState(hash, types.ColdAssetArchived, types.ColdAssetRestoring); err != nil {
+			return false, err
+		}
+
+		log.Infof("restore archived asset %s from deal %s", asset.CID, asset.DealID)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Offload proposes the deal of the asset regardless of its retrievals, or removes the hot replicas of the asset
+// if its deal is already active
+func (m *Manager) Offload(ctx context.Context, hash string) error {
+	maker, _, err := m.dealMaker()
+	if err != nil {
+		return err
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return err
+	}
+
+	asset, err := m.LoadColdAsset(hash)
+	if err == sql.ErrNoRows {
+		return m.propose(ctx, maker, &types.ColdAsset{Hash: hash, CID: record.CID, Size: record.TotalSize})
+	}
+	if err != nil {
+		return err
+	}
+
+	switch asset.State {
+	case types.ColdAssetFailed:
+		return m.propose(ctx, maker, asset)
+	case types.ColdAssetActive:
+		return m.archive(asset)
+	}
+
+	return xerrors.Errorf("asset %s is %s in the cold storage", record.CID, asset.State)
+}
+
+// dealMaker returns the deal maker of the config, an error is returned if the cold storage tier is disabled
+func (m *Manager) dealMaker() (DealMaker, *config.SchedulerCfg, error) {
+	cfg, err := m.config()
+	if err != nil {
+		return nil, nil, xerrors.Errorf("get scheduler config err:%s", err.Error())
+	}
+
+	if cfg.ColdStorageDealEndpoint == "" {
+		return nil, nil, xerrors.New("cold storage is disabled")
+	}
+
+	return NewHTTPDealMaker(cfg.ColdStorageDealEndpoint, cfg.ColdStorageDealToken), &cfg, nil
+}
+
+func (m *Manager) startFlushTimer() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.flush()
+	}
+}
+
+// flush saves the assets retrieved since the last flush
+func (m *Manager) flush() {
+	m.lk.Lock()
+	hashes := make([]string, 0, len(m.retrieved))
+	for hash := range m.retrieved {
+		hashes = append(hashes, hash)
+	}
+	m.retrieved = make(map[string]struct{})
+	m.lk.Unlock()
+
+	for len(hashes) > 0 {
+		n := len(hashes)
+		if n > batchSize {
+			n = batchSize
+		}
+
+		if err := m.SaveAssetAccesses(hashes[:n], time.Now()); err != nil {
+			log.Errorf("SaveAssetAccesses err:%s", err.Error())
+		}
+		hashes = hashes[n:]
+	}
+}
+
+func (m *Manager) startRoundTimer() {
+	ticker := time.NewTicker(roundInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		maker, cfg, err := m.dealMaker()
+		if err != nil {
+			continue
+		}
+
+		m.track(maker)
+		m.offload(maker, cfg)
+	}
+}
+
+// offload proposes the deals of the idle assets and archives the idle assets whose deals are active
+func (m *Manager) offload(maker DealMaker, cfg *config.SchedulerCfg) {
+	if cfg.ColdStorageIdleDays <= 0 {
+		return
+	}
+
+	now := time.Now()
+	idle, err := m.LoadIdleAssets(m.serverID, []string{assets.Servicing.String()}, now.AddDate(0, 0, -cfg.ColdStorageIdleDays),
+		cfg.ColdStorageMinSize, now.Add(-retryFailedDelay), batchSize)
+	if err != nil {
+		log.Errorf("LoadIdleAssets err:%s", err.Error())
+		return
+	}
+
+	for _, asset := range idle {
+		if asset.State == types.ColdAssetActive {
+			err = m.archive(asset)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			err = m.propose(ctx, maker, asset)
+			cancel()
+		}
+
+		if err != nil {
+			log.Errorf("offload asset %s err:%s", asset.CID, err.Error())
+		}
+	}
+}
+
+// propose makes the deal of the asset and saves the asset in the proposed state
+func (m *Manager) propose(ctx context.Context, maker DealMaker, asset *types.ColdAsset) error {
+	dealID, err := maker.MakeDeal(ctx, &Proposal{CID: asset.CID, Size: asset.Size})
+	if err != nil {
+		return xerrors.Errorf("make deal: %w", err)
+	}
+
+	log.Infof("propose deal %s of asset %s", dealID, asset.CID)
+
+	asset.DealID = dealID
+	asset.State = types.ColdAssetProposed
+	asset.Message = ""
+	return m.SaveColdAsset(asset)
+}
+
+// archive removes the hot replicas of the asset whose deal is active
+func (m *Manager) archive(asset *types.ColdAsset) error {
+	if err := m.assetMgr.ArchiveAsset(asset.Hash); err != nil {
+		return err
+	}
+
+	log.Infof("archive asset %s into deal %s", asset.CID, asset.DealID)
+
+	_, err := m.SwitchColdAssetState(asset.Hash, types.ColdAssetActive, types.ColdAssetArchived)
+	return err
+}
+
+// track saves the states of the proposed deals reported by the deal-making component
+func (m *Manager) track(maker DealMaker) {
+	list, err := m.LoadColdAssetsOfServer(m.serverID, types.ColdAssetProposed, batchSize)
+	if err != nil {
+		log.Errorf("LoadColdAssetsOfServer err:%s", err.Error())
+		return
+	}
+
+	for _, asset := range list {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		status, err := maker.DealStatus(ctx, asset.DealID)
+		cancel()
+		if err != nil {
+			log.Errorf("deal status %s of asset %s err:%s", asset.DealID, asset.CID, err.Error())
+			continue
+		}
+
+		asset.State = status.State
+		asset.Provider = status.Provider
+		asset.PieceCID = status.PieceCID
+		asset.ChainDealID = status.ChainDealID
+		asset.Message = status.Message
+		if len(asset.Message) > maxMessageLen {
+			asset.Message = asset.Message[:maxMessageLen]
+		}
+
+		if err = m.UpdateColdAsset(asset); err != nil {
+			log.Errorf("UpdateColdAsset %s err:%s", asset.CID, err.Error())
+		}
+	}
+}
+
+func (m *Manager) startRestoreTimer() {
+	ticker := time.NewTicker(restoreInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		maker, _, err := m.dealMaker()
+		if err != nil {
+			continue
+		}
+
+		m.restore(maker)
+	}
+}
+
+// restore polls the retrievals of the restoring assets of the scheduler and seeds the assets the providers are ready to serve
+func (m *Manager) restore(maker DealMaker) {
+	list, err := m.LoadColdAssetsOfServer(m.serverID, types.ColdAssetRestoring, batchSize)
+	if err != nil {
+		log.Errorf("LoadColdAssetsOfServer err:%s", err.Error())
+		return
+	}
+
+	for _, asset := range list {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		ready, err := maker.Retrieve(ctx, asset.DealID)
+		cancel()
+		if err != nil {
+			log.Errorf("retrieve deal %s of asset %s err:%s", asset.DealID, asset.CID, err.Error())
+			continue
+		}
+
+		if !ready {
+			continue
+		}
+
+		if err = m.assetMgr.RestoreAsset(asset.Hash); err != nil {
+			log.Errorf("RestoreAsset %s err:%s", asset.CID, err.Error())
+			continue
+		}
+
+		log.Infof("asset %s is seeded again from deal %s", asset.CID, asset.DealID)
+
+		if _, err = m.SwitchColdAssetState(asset.Hash, types.ColdAssetRestoring, types.ColdAssetActive); err != nil {
+			log.Errorf("SwitchColdAssetState %s err:%s", asset.CID, err.Error())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"golang.org/x/xerrors"
+)
+
+// OffloadAsset proposes the storage deal of the asset now, or removes the hot replicas of the asset if its deal is active
+func (s *Scheduler) OffloadAsset(ctx context.Context, cid string) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	return s.ColdStorageManager.Offload(ctx, hash)
+}
+
+// RestoreAsset pulls the archived asset back from its storage deal
+func (s *Scheduler) RestoreAsset(ctx context.Context, cid string) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	restoring, err := s.ColdStorageManager.Restore(hash)
+	if err != nil {
+		return err
+	}
+
+	if !restoring {
+		return xerrors.Errorf("asset %s is not archived", cid)
+	}
+
+	return nil
+}
+
+// ListColdAssets lists the assets offloaded into the storage deals, the latest updated first
+func (s *Scheduler) ListColdAssets(ctx context.Context, state types.ColdAssetState, limit, offset int) (*types.ListColdAssetsRsp, error) {
+	return s.db.ListColdAssets(state, limit, offset)
+}
+
+// restoreColdAsset starts restoring the asset retrieved without replicas if it is archived,
+// the caller is asked to retry while the asset is pulled back from its storage deal
+func (s *Scheduler) restoreColdAsset(hash, cid string) error {
+	restoring, err := s.ColdStorageManager.Restore(hash)
+	if err != nil {
+		return err
+	}
+
+	if restoring {
+		return &api.ErrWeb{Code: terrors.AssetRestoring.Int(), Message: fmt.Sprintf("asset %s is being restored from the cold storage, retry later", cid)}
+	}
+
+	return nil
+}
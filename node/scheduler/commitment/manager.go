@@ -0,0 +1,221 @@
+package commitment
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("commitment")
+
+const (
+	checkInterval = time.Minute
+	// a node is present if it has been seen in the duration, the last seen time is saved every minute
+	presenceTimeout = 3 * time.Minute
+)
+
+// Manager checks the presence of the nodes in the windows committed by their operators, the points earned in a kept window
+// are multiplied by the bonus multiplier, and the broken windows are counted by the broken commitment penalty rules.
+// The presence is checked with the last seen time saved by the scheduler the node is connected to,
+// the minutes the node is in its maintenance windows are not checked.
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	nodeMgr       *node.Manager
+	penaltyMgr    *penalty.Manager
+	*db.SQLDB
+
+	lk sync.Mutex
+	// the windows being checked by node id
+	windows map[string]*window
+}
+
+// window a commitment window being checked
+type window struct {
+	record *types.NodeCommitmentRecord
+	// points of the node when the window started to be checked
+	startPoints types.Points
+	// maintenanceMinutes the minutes of the window the node was in maintenance, they are not checked
+	maintenanceMinutes int
+}
+
+// NewManager return new commitment manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager, nmgr *node.Manager, pmgr *penalty.Manager) *Manager {
+	m := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		nodeMgr:       nmgr,
+		penaltyMgr:    pmgr,
+		SQLDB:         sdb,
+		windows:       make(map[string]*window),
+	}
+
+	diagnostics.RegisterSize("commitment.windows", func() int {
+		m.lk.Lock()
+		defer m.lk.Unlock()
+		return len(m.windows)
+	})
+
+	go m.startCheckTimer()
+
+	return m
+}
+
+// SetCommitment commits the node to be online in the window [startHour, endHour) of each day
+func (m *Manager) SetCommitment(c *types.NodeCommitment) error {
+	if c.StartHour < 0 || c.StartHour > 23 || c.EndHour < 1 || c.EndHour > 24 || c.StartHour == c.EndHour {
+		return xerrors.Errorf("invalid commitment window [%d, %d)", c.StartHour, c.EndHour)
+	}
+
+	if _, err := m.LoadNodeType(c.NodeID); err != nil {
+		return xerrors.Errorf("load node %s type: %w", c.NodeID, err)
+	}
+
+	return m.SaveNodeCommitment(c)
+}
+
+func (m *Manager) startCheckTimer() {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("commitment.check", checkInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.check(time.Now())
+		done()
+	}
+}
+
+// check checks the presence of the committed nodes in their windows and closes the windows ended
+func (m *Manager) check(now time.Time) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		// the windows are checked by the new master
+		m.windows = make(map[string]*window)
+		return
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	commitments, err := m.LoadNodeCommitments()
+	if err != nil {
+		log.Errorf("LoadNodeCommitments err:%s", err.Error())
+		return
+	}
+
+	committed := make(map[string]*types.NodeCommitment, len(commitments))
+	nodeIDs := make([]string, 0, len(commitments))
+	for _, c := range commitments {
+		committed[c.NodeID] = c
+		nodeIDs = append(nodeIDs, c.NodeID)
+	}
+
+	activities, err := m.LoadNodeActivities(nodeIDs)
+	if err != nil {
+		log.Errorf("LoadNodeActivities err:%s", err.Error())
+		return
+	}
+
+	for nodeID, w := range m.windows {
+		if _, ok := committed[nodeID]; !ok {
+			// the commitment is withdrawn
+			delete(m.windows, nodeID)
+			continue
+		}
+
+		if now.Before(w.record.WindowEnd) {
+			continue
+		}
+
+		delete(m.windows, nodeID)
+		if activity, ok := activities[nodeID]; ok {
+			m.close(w, activity.Profit, cfg.CommitmentBonusMultiplier, cfg.CommitmentMinPresence, cfg.PointDecimals)
+		}
+	}
+
+	for _, c := range commitments {
+		activity, ok := activities[c.NodeID]
+		if !ok {
+			continue
+		}
+
+		start, end, ok := c.Window(now)
+		if !ok {
+			continue
+		}
+
+		w, ok := m.windows[c.NodeID]
+		if !ok || !w.record.WindowStart.Equal(start) {
+			w = &window{
+				record:      &types.NodeCommitmentRecord{NodeID: c.NodeID, WindowStart: start, WindowEnd: end},
+				startPoints: activity.Profit,
+			}
+			m.windows[c.NodeID] = w
+		}
+
+		if m.nodeMgr.InMaintenance(c.NodeID, now) {
+			w.maintenanceMinutes++
+			continue
+		}
+
+		w.record.CheckedMinutes++
+		if now.Sub(activity.LastSeen) <= presenceTimeout {
+			w.record.PresentMinutes++
+		}
+	}
+}
+
+// close saves the record of the window, the bonus of the kept window is added to the node
+// and the broken window is counted by the penalty rules
+func (m *Manager) close(w *window, endPoints types.Points, multiplier, minPresence float64, decimals int) {
+	record := w.record
+	if record.CheckedMinutes == 0 && w.maintenanceMinutes > 0 {
+		log.Infof("node %s was in maintenance in the whole commitment window started at %s", record.NodeID, record.WindowStart.Format(time.RFC3339))
+		return
+	}
+
+	record.EarnedPoints = endPoints.Sub(w.startPoints)
+	if record.EarnedPoints.Sign() < 0 {
+		record.EarnedPoints = types.Points{}
+	}
+	record.Kept = isKept(record.CheckedMinutes, record.PresentMinutes, minPresence)
+	if record.Kept && multiplier > 1 {
+		record.BonusPoints = record.EarnedPoints.Mul(multiplier - 1).Round(decimals)
+	}
+
+	if err := m.SaveNodeCommitmentRecord(record); err != nil {
+		log.Errorf("SaveNodeCommitmentRecord %s err:%s", record.NodeID, err.Error())
+		return
+	}
+
+	if !record.Kept {
+		log.Infof("node %s broke the commitment window started at %s, present %d of %d minutes",
+			record.NodeID, record.WindowStart.Format(time.RFC3339), record.PresentMinutes, record.CheckedMinutes)
+		m.penaltyMgr.BrokenCommitment(record.NodeID, record.WindowStart)
+	}
+}
+
+// isKept checks if the node was present long enough in the window
+func isKept(checked, present int, minPresence float64) bool {
+	if checked == 0 {
+		return false
+	}
+
+	return float64(present) >= float64(checked)*minPresence
+}
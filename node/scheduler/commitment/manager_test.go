@@ -0,0 +1,53 @@
+package commitment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestWindow(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, 1, day, hour, 30, 0, 0, time.Local)
+	}
+
+	evening := &types.NodeCommitment{StartHour: 20, EndHour: 24}
+	night := &types.NodeCommitment{StartHour: 22, EndHour: 6}
+
+	cases := []struct {
+		c     *types.NodeCommitment
+		t     time.Time
+		ok    bool
+		start time.Time
+	}{
+		{evening, at(2, 19), false, time.Time{}},
+		{evening, at(2, 20), true, time.Date(2024, 1, 2, 20, 0, 0, 0, time.Local)},
+		{evening, at(2, 23), true, time.Date(2024, 1, 2, 20, 0, 0, 0, time.Local)},
+		{night, at(2, 23), true, time.Date(2024, 1, 2, 22, 0, 0, 0, time.Local)},
+		{night, at(3, 5), true, time.Date(2024, 1, 2, 22, 0, 0, 0, time.Local)},
+		{night, at(3, 6), false, time.Time{}},
+		{night, at(3, 21), false, time.Time{}},
+	}
+
+	for i, c := range cases {
+		start, end, ok := c.c.Window(c.t)
+		if ok != c.ok || !start.Equal(c.start) {
+			t.Fatalf("case %d expect %v %s, got %v %s", i, c.ok, c.start, ok, start)
+		}
+
+		if ok && end.Sub(start) != time.Duration((c.c.EndHour-c.c.StartHour+24)%24)*time.Hour {
+			t.Fatalf("case %d unexpected window end %s", i, end)
+		}
+	}
+}
+
+func TestIsKept(t *testing.T) {
+	if isKept(0, 0, 0.95) {
+		t.Fatal("window never checked should not be kept")
+	}
+
+	if !isKept(240, 228, 0.95) || isKept(240, 227, 0.95) {
+		t.Fatal("unexpected presence check")
+	}
+}
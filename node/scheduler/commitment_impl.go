@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SetNodeCommitment commits the node to be online in a daily window, the points earned in a kept window get the bonus multiplier
+// and the broken windows are penalized by the broken commitment rules
+func (s *Scheduler) SetNodeCommitment(ctx context.Context, commitment *types.NodeCommitment) error {
+	if commitment == nil {
+		return xerrors.New("commitment can not empty")
+	}
+
+	return s.CommitmentManager.SetCommitment(commitment)
+}
+
+// RemoveNodeCommitment withdraws the commitment of the node
+func (s *Scheduler) RemoveNodeCommitment(ctx context.Context, nodeID string) error {
+	return s.CommitmentManager.DeleteNodeCommitment(nodeID)
+}
+
+// GetNodeCommitment retrieves the commitment of the node
+func (s *Scheduler) GetNodeCommitment(ctx context.Context, nodeID string) (*types.NodeCommitment, error) {
+	return s.CommitmentManager.LoadNodeCommitment(nodeID)
+}
+
+// GetNodeCommitmentRecords retrieves the presence of the node in its commitment windows, the latest first
+func (s *Scheduler) GetNodeCommitmentRecords(ctx context.Context, nodeID string, limit, offset int) (*types.ListNodeCommitmentRecordRsp, error) {
+	return s.CommitmentManager.LoadNodeCommitmentRecords(nodeID, limit, offset)
+}
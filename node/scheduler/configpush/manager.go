@@ -0,0 +1,317 @@
+package configpush
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/featureflag"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("configpush")
+
+const (
+	// the nodes not acknowledging the revision of their config are pushed again in the interval,
+	// the configs changed on the other schedulers reach the nodes of this scheduler by the same timer
+	pushInterval = 5 * time.Minute
+	pushTimeout  = 10 * time.Second
+	// the nodes pushed at once
+	maxPushing = 20
+)
+
+// Manager pushes the config of the nodes, the feature flags of the nodes and the defaults of the node types
+// merged with the overrides of the nodes, to the nodes connected to the scheduler and records the revisions the nodes acknowledge
+type Manager struct {
+	nodeMgr *node.Manager
+	flags   *featureflag.Manager
+	*db.SQLDB
+
+	pushing chan struct{}
+
+	lk sync.Mutex
+	// the revisions acknowledged by the nodes by node id
+	acked map[string]string
+}
+
+// NewManager return new config push manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, flags *featureflag.Manager) *Manager {
+	m := &Manager{
+		nodeMgr: nmgr,
+		flags:   flags,
+		SQLDB:   sdb,
+		pushing: make(chan struct{}, maxPushing),
+		acked:   make(map[string]string),
+	}
+
+	go m.startPushTimer()
+
+	return m
+}
+
+func defaultsScope(nodeType types.NodeType) string {
+	return "defaults:" + nodeType.String()
+}
+
+// SetDefaults sets the default config of the node type and pushes it to the nodes of the type
+func (m *Manager) SetDefaults(nodeType types.NodeType, cfg *types.NodeConfig) error {
+	if err := validate(nodeType, cfg); err != nil {
+		return err
+	}
+
+	if err := m.SaveNodeConfig(defaultsScope(nodeType), cfg); err != nil {
+		return xerrors.Errorf("SaveNodeConfig err:%s", err.Error())
+	}
+
+	go m.pushNodes(m.localNodes(nodeType))
+	return nil
+}
+
+// GetDefaults returns the default config of the node type
+func (m *Manager) GetDefaults(nodeType types.NodeType) (*types.NodeConfig, error) {
+	if nodeType != types.NodeEdge && nodeType != types.NodeCandidate {
+		return nil, xerrors.Errorf("invalid node type %d", nodeType)
+	}
+
+	cfg, err := m.LoadNodeConfig(defaultsScope(nodeType))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		cfg = &types.NodeConfig{}
+	}
+	return cfg, nil
+}
+
+// SetOverrides sets the config overriding the defaults on the node and pushes the merged config to the node,
+// the overrides are removed if the config sets nothing
+func (m *Manager) SetOverrides(nodeID string, cfg *types.NodeConfig) error {
+	nodeType, err := m.LoadNodeType(nodeID)
+	if err != nil {
+		return xerrors.Errorf("load type of node %s err:%s", nodeID, err.Error())
+	}
+
+	if cfg == nil || isEmpty(cfg) {
+		err = m.DeleteNodeConfig(nodeID)
+	} else if err = validate(nodeType, cfg); err == nil {
+		err = m.SaveNodeConfig(nodeID, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if n := m.nodeMgr.GetNode(nodeID); n != nil {
+		go m.pushNodes([]*node.Node{n})
+	}
+	return nil
+}
+
+// GetStatus returns the config of the node and the revision the node acknowledged
+func (m *Manager) GetStatus(nodeID string) (*types.NodeConfigStatus, error) {
+	nodeType, err := m.LoadNodeType(nodeID)
+	if err != nil {
+		return nil, xerrors.Errorf("load type of node %s err:%s", nodeID, err.Error())
+	}
+
+	defaults, err := m.LoadNodeConfig(defaultsScope(nodeType))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := m.LoadNodeConfig(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := m.effective(nodeID, nodeType, defaults, overrides)
+	status := &types.NodeConfigStatus{NodeID: nodeID, Overrides: overrides, Effective: effective, Revision: effective.Revision()}
+	if err := m.LoadNodeConfigAck(nodeID, status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// PushAll pushes the config to the nodes connected to the scheduler, e.g. after the feature flags change
+func (m *Manager) PushAll() {
+	m.pushNodes(append(m.localNodes(types.NodeEdge), m.localNodes(types.NodeCandidate)...))
+}
+
+// NodeConnected pushes the config to the node that connects to the scheduler, the node restarted lost the config it applied
+func (m *Manager) NodeConnected(nodeID string) {
+	n := m.nodeMgr.GetNode(nodeID)
+	if n == nil {
+		return
+	}
+
+	m.lk.Lock()
+	delete(m.acked, nodeID)
+	m.lk.Unlock()
+
+	m.pushNodes([]*node.Node{n})
+}
+
+func (m *Manager) startPushTimer() {
+	ticker := time.NewTicker(pushInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		m.PushAll()
+	}
+}
+
+func (m *Manager) localNodes(nodeType types.NodeType) []*node.Node {
+	if nodeType == types.NodeEdge {
+		return m.nodeMgr.GetAllEdgeNode()
+	}
+
+	_, nodes := m.nodeMgr.GetAllValidCandidateNodes()
+	return nodes
+}
+
+// pushNodes pushes the config to the nodes that have not acknowledged its revision
+func (m *Manager) pushNodes(nodes []*node.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	configs, err := m.LoadNodeConfigs()
+	if err != nil {
+		log.Errorf("LoadNodeConfigs err:%s", err.Error())
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		cfg := m.effective(n.NodeID, n.Type, configs[defaultsScope(n.Type)], configs[n.NodeID])
+		revision := cfg.Revision()
+
+		m.lk.Lock()
+		acked := m.acked[n.NodeID] == revision
+		m.lk.Unlock()
+
+		if acked {
+			continue
+		}
+
+		m.pushing <- struct{}{}
+		wg.Add(1)
+		go func(n *node.Node) {
+			defer func() {
+				<-m.pushing
+				wg.Done()
+			}()
+
+			if err := m.push(n, cfg, revision); err != nil {
+				log.Errorf("push config %s to %s err:%s", revision, n.NodeID, err.Error())
+			}
+		}(n)
+	}
+
+	wg.Wait()
+}
+
+// effective returns the config of the node, the feature flags rolled out to the node are overridden by the flags of the defaults
+// and then by the overrides of the node
+func (m *Manager) effective(nodeID string, nodeType types.NodeType, defaults, overrides *types.NodeConfig) *types.NodeConfig {
+	flags := &types.NodeConfig{FeatureFlags: m.flags.NodeFlags(nodeID, nodeType)}
+	return flags.Merge(defaults).Merge(overrides)
+}
+
+// push pushes the config to the node, the upload limit of the edge is set by the node manager,
+// which pushes it again when the edge reports another limit with keepalive
+func (m *Manager) push(n *node.Node, cfg *types.NodeConfig, revision string) error {
+	if n.API == nil || n.API.ApplyNodeConfig == nil {
+		return xerrors.Errorf("node %s does not support the config push", n.NodeID)
+	}
+
+	if cfg.UploadLimit != nil && n.Type == types.NodeEdge {
+		limit, err := m.nodeMgr.GetNodeUploadLimit(n.NodeID)
+		if err != nil {
+			return err
+		}
+
+		if limit.Limit != *cfg.UploadLimit {
+			if err := m.nodeMgr.SetNodeUploadLimit(n.NodeID, *cfg.UploadLimit); err != nil {
+				return err
+			}
+		}
+	}
+
+	pushed := *cfg
+	pushed.UploadLimit = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+
+	ack, err := n.ApplyNodeConfig(ctx, &types.NodeConfigPush{Revision: revision, Config: &pushed})
+	if err != nil {
+		return err
+	}
+
+	if ack.Revision != revision {
+		return xerrors.Errorf("node acknowledged revision %s", ack.Revision)
+	}
+
+	if len(ack.Errors) > 0 {
+		log.Warnf("node %s applied config %s with errors: %s", n.NodeID, revision, strings.Join(ack.Errors, "; "))
+	}
+
+	m.lk.Lock()
+	m.acked[n.NodeID] = revision
+	m.lk.Unlock()
+
+	return m.SaveNodeConfigAck(n.NodeID, ack)
+}
+
+// validate checks the config of the node type, only the edges have the upload limit
+func validate(nodeType types.NodeType, cfg *types.NodeConfig) error {
+	if nodeType != types.NodeEdge && nodeType != types.NodeCandidate {
+		return xerrors.Errorf("invalid node type %d", nodeType)
+	}
+
+	if cfg == nil {
+		return xerrors.New("config is nil")
+	}
+
+	if cfg.LogLevel != nil {
+		if _, err := logging.LevelFromString(*cfg.LogLevel); err != nil {
+			return xerrors.Errorf("invalid log level %s", *cfg.LogLevel)
+		}
+	}
+
+	if cfg.CacheConfig != nil {
+		if !cfg.CacheConfig.Policy.IsValid() || cfg.CacheConfig.MaxDiskUsage < 0 || cfg.CacheConfig.MaxDiskUsage > 100 {
+			return xerrors.New("invalid cache config")
+		}
+	}
+
+	if cfg.UploadLimit != nil {
+		if nodeType != types.NodeEdge {
+			return xerrors.Errorf("%s has no upload limit", nodeType.String())
+		}
+
+		if *cfg.UploadLimit < 0 {
+			return xerrors.Errorf("invalid upload limit %d", *cfg.UploadLimit)
+		}
+	}
+
+	for name := range cfg.FeatureFlags {
+		if name == "" || len(name) > 64 {
+			return xerrors.Errorf("invalid feature flag name %s", name)
+		}
+	}
+
+	return nil
+}
+
+func isEmpty(cfg *types.NodeConfig) bool {
+	return cfg.LogLevel == nil && cfg.CacheConfig == nil && cfg.UploadLimit == nil && len(cfg.FeatureFlags) == 0
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SetNodeConfigDefaults sets the default config of the node type, the config is pushed to the nodes of the type
+func (s *Scheduler) SetNodeConfigDefaults(ctx context.Context, nodeType types.NodeType, cfg *types.NodeConfig) error {
+	return s.ConfigPushManager.SetDefaults(nodeType, cfg)
+}
+
+// GetNodeConfigDefaults retrieves the default config of the node type
+func (s *Scheduler) GetNodeConfigDefaults(ctx context.Context, nodeType types.NodeType) (*types.NodeConfig, error) {
+	return s.ConfigPushManager.GetDefaults(nodeType)
+}
+
+// SetNodeConfigOverrides sets the config overriding the defaults on the node, the overrides are removed if the config sets nothing
+func (s *Scheduler) SetNodeConfigOverrides(ctx context.Context, nodeID string, cfg *types.NodeConfig) error {
+	return s.ConfigPushManager.SetOverrides(nodeID, cfg)
+}
+
+// GetNodeConfigStatus retrieves the config of the node and the revision the node acknowledged
+func (s *Scheduler) GetNodeConfigStatus(ctx context.Context, nodeID string) (*types.NodeConfigStatus, error) {
+	return s.ConfigPushManager.GetStatus(nodeID)
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// the validations shown by the dashboard
+const dashboardValidationPeriod = 24 * time.Hour
+
+// GetDashboardStats returns the statistics of the scheduler shown by the web dashboard
+func (s *Scheduler) GetDashboardStats(ctx context.Context) (*types.DashboardStats, error) {
+	if err := s.OverloadManager.Admit(ctx, "GetDashboardStats"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	assets, err := s.NodeManager.LoadAssetStateCounts(s.ServerID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAssetStateCounts err:%s", err.Error())
+	}
+
+	validations, err := s.NodeManager.LoadValidationPassRates(now.Add(-dashboardValidationPeriod).Truncate(time.Hour))
+	if err != nil {
+		return nil, xerrors.Errorf("LoadValidationPassRates err:%s", err.Error())
+	}
+
+	points, err := s.NodeManager.LoadPointsDistribution(s.ServerID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadPointsDistribution err:%s", err.Error())
+	}
+
+	return &types.DashboardStats{
+		AreaID:      s.SchedulerCfg.AreaID,
+		Nodes:       s.NodeManager.GetNodeGeoStats(),
+		Assets:      assets,
+		Validations: validations,
+		Points:      points,
+		UpdatedTime: now,
+	}, nil
+}
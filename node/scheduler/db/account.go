@@ -0,0 +1,191 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SaveAccountNode binds the node to the account, a node can only be bound to one account
+func (n *SQLDB) SaveAccountNode(accountID, nodeID string) error {
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, account_id) VALUES (?, ?)`, accountNodeTable)
+	_, err := n.db.Exec(query, nodeID, accountID)
+	return err
+}
+
+// DeleteAccountNode unbinds the node from the account
+func (n *SQLDB) DeleteAccountNode(accountID, nodeID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=? AND account_id=?`, accountNodeTable)
+	result, err := n.db.Exec(query, nodeID, accountID)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if r < 1 {
+		return xerrors.Errorf("node %s is not bound to account %s", nodeID, accountID)
+	}
+
+	return nil
+}
+
+// LoadAccountOfNode load the account id the node is bound to
+func (n *SQLDB) LoadAccountOfNode(nodeID string) (string, error) {
+	var accountID string
+	query := fmt.Sprintf(`SELECT account_id FROM %s WHERE node_id=?`, accountNodeTable)
+	err := n.db.Get(&accountID, query, nodeID)
+	return accountID, err
+}
+
+// LoadAccountNodes load the nodes bound to the account
+func (n *SQLDB) LoadAccountNodes(accountID string) ([]*types.AccountNode, error) {
+	var out []*types.AccountNode
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE account_id=? order by bind_time asc`, accountNodeTable)
+	if err := n.db.Select(&out, query, accountID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadAccountStats aggregates the node infos and validation results of the nodes bound to the account
+func (n *SQLDB) LoadAccountStats(accountID string) (*types.AccountStats, error) {
+	stats := &types.AccountStats{AccountID: accountID}
+
+	var nodeStats struct {
+		NodeCount       int          `db:"node_count"`
+		Profit          types.Points `db:"profit"`
+		OnlineDuration  int64        `db:"online_duration"`
+		UploadTraffic   int64        `db:"upload_traffic"`
+		DownloadTraffic int64        `db:"download_traffic"`
+	}
+
+	query := fmt.Sprintf(`SELECT COUNT(a.node_id) AS node_count, IFNULL(SUM(b.profit),0) AS profit, IFNULL(SUM(b.online_duration),0) AS online_duration,
+	    IFNULL(SUM(b.upload_traffic),0) AS upload_traffic, IFNULL(SUM(b.download_traffic),0) AS download_traffic
+		FROM %s a LEFT JOIN %s b ON a.node_id = b.node_id WHERE a.account_id=?`, accountNodeTable, nodeInfoTable)
+	if err := n.db.Get(&nodeStats, query, accountID); err != nil {
+		return nil, err
+	}
+
+	stats.NodeCount = nodeStats.NodeCount
+	stats.Profit = nodeStats.Profit
+	stats.OnlineDuration = nodeStats.OnlineDuration
+	stats.UploadTraffic = nodeStats.UploadTraffic
+	stats.DownloadTraffic = nodeStats.DownloadTraffic
+
+	var validationStats struct {
+		Total  int `db:"total"`
+		Passed int `db:"passed"`
+		Failed int `db:"failed"`
+	}
+
+	query = fmt.Sprintf(`SELECT COUNT(v.id) AS total, IFNULL(SUM(v.status=?),0) AS passed, IFNULL(SUM(v.status IN (?,?,?)),0) AS failed
+		FROM %s v INNER JOIN %s a ON v.node_id = a.node_id WHERE a.account_id=?`, validationResultTable, accountNodeTable)
+	err := n.db.Get(&validationStats, query, types.ValidationStatusSuccess, types.ValidationStatusNodeTimeOut,
+		types.ValidationStatusValidateFail, types.ValidationStatusNodeOffline, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.ValidationCount = validationStats.Total
+	stats.ValidationPassed = validationStats.Passed
+	stats.ValidationFailed = validationStats.Failed
+
+	return stats, nil
+}
+
+// SaveAccountNotificationPrefs saves the notification preferences of the account
+func (n *SQLDB) SaveAccountNotificationPrefs(prefs *types.AccountNotificationPrefs) error {
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, offline_alert, offline_minutes, email, webhook, updated_time)
+				VALUES (:account_id, :offline_alert, :offline_minutes, :email, :webhook, NOW())
+				ON DUPLICATE KEY UPDATE offline_alert=:offline_alert, offline_minutes=:offline_minutes,
+				email=:email, webhook=:webhook, updated_time=NOW()`, accountNotifyTable)
+
+	_, err := n.db.NamedExec(query, prefs)
+	return err
+}
+
+// LoadAccountNotificationPrefs load the notification preferences of the account
+func (n *SQLDB) LoadAccountNotificationPrefs(accountID string) (*types.AccountNotificationPrefs, error) {
+	var prefs types.AccountNotificationPrefs
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE account_id=?`, accountNotifyTable)
+	if err := n.db.Get(&prefs, query, accountID); err != nil {
+		return nil, err
+	}
+
+	return &prefs, nil
+}
+
+// LoadOfflineAlertPrefs load the notification preferences of the accounts that enabled offline alerts
+func (n *SQLDB) LoadOfflineAlertPrefs() ([]*types.AccountNotificationPrefs, error) {
+	var out []*types.AccountNotificationPrefs
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE offline_alert=true`, accountNotifyTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// alertSubscriptionColumns the columns added to the alert subscriptions after their creation
+var alertSubscriptionColumns = []tableColumn{
+	{"disk_failure", "BOOLEAN DEFAULT false"},
+}
+
+// SaveAlertSubscription saves the alert subscription and returns its id
+func (n *SQLDB) SaveAlertSubscription(sub *types.AlertSubscription) (int64, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, node_id, offline_minutes, validation_failures, disk_usage_threshold, disk_failure, webhook, email)
+				VALUES (:account_id, :node_id, :offline_minutes, :validation_failures, :disk_usage_threshold, :disk_failure, :webhook, :email)`, alertSubscribeTable)
+
+	result, err := n.db.NamedExec(query, sub)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// DeleteAlertSubscription deletes the alert subscription of the account
+func (n *SQLDB) DeleteAlertSubscription(accountID string, id int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=? AND account_id=?`, alertSubscribeTable)
+	_, err := n.db.Exec(query, id, accountID)
+	return err
+}
+
+// LoadAlertSubscriptions load the alert subscriptions of the account
+func (n *SQLDB) LoadAlertSubscriptions(accountID string) ([]*types.AlertSubscription, error) {
+	var out []*types.AlertSubscription
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE account_id=?`, alertSubscribeTable)
+	if err := n.db.Select(&out, query, accountID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadAllAlertSubscriptions load all alert subscriptions
+func (n *SQLDB) LoadAllAlertSubscriptions() ([]*types.AlertSubscription, error) {
+	var out []*types.AlertSubscription
+	query := fmt.Sprintf(`SELECT * FROM %s`, alertSubscribeTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadLatestValidationStatuses load the statuses of the latest finished validations of the node
+func (n *SQLDB) LoadLatestValidationStatuses(nodeID string, limit int) ([]types.ValidationStatus, error) {
+	var out []types.ValidationStatus
+	query := fmt.Sprintf(`SELECT status FROM %s WHERE node_id=? AND status!=? order by start_time desc LIMIT ?`, validationResultTable)
+	if err := n.db.Select(&out, query, nodeID, types.ValidationStatusCreate, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
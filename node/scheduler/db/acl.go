@@ -0,0 +1,71 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// assetACLRow the access control list of a private user asset, the users and the api keys are comma separated
+type assetACLRow struct {
+	Hash        string    `db:"hash"`
+	UserID      string    `db:"user_id"`
+	Users       string    `db:"users"`
+	APIKeys     string    `db:"api_keys"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+func (r *assetACLRow) toACL(cid string) *types.AssetACL {
+	return &types.AssetACL{CID: cid, Users: splitList(r.Users), APIKeys: splitList(r.APIKeys), CreatedTime: r.CreatedTime}
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// SaveAssetACL makes the user asset private, only the owners and the users and api keys in the list can download it
+func (n *SQLDB) SaveAssetACL(hash, userID string, acl *types.AssetACL) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, user_id, users, api_keys) VALUES (?, ?, ?, ?) 
+				ON DUPLICATE KEY UPDATE users=VALUES(users), api_keys=VALUES(api_keys)`, assetACLTable)
+	_, err := n.db.Exec(query, hash, userID, strings.Join(acl.Users, ","), strings.Join(acl.APIKeys, ","))
+	return err
+}
+
+// DeleteAssetACL makes the user asset public
+func (n *SQLDB) DeleteAssetACL(hash, userID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND user_id=?`, assetACLTable)
+	_, err := n.db.Exec(query, hash, userID)
+	return err
+}
+
+// LoadAssetACL loads the access control list of the user asset
+func (n *SQLDB) LoadAssetACL(hash, userID, cid string) (*types.AssetACL, error) {
+	var row assetACLRow
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=? AND user_id=?", assetACLTable)
+	if err := n.db.Get(&row, query, hash, userID); err != nil {
+		return nil, err
+	}
+
+	return row.toACL(cid), nil
+}
+
+// LoadAssetACLs loads the access control lists of the asset set by its owners, keyed by the owner
+func (n *SQLDB) LoadAssetACLs(hash string) (map[string]*types.AssetACL, error) {
+	var rows []*assetACLRow
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=?", assetACLTable)
+	if err := n.db.Select(&rows, query, hash); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*types.AssetACL, len(rows))
+	for _, row := range rows {
+		out[row.UserID] = row.toACL("")
+	}
+	return out, nil
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
+)
+
+// purgeNodeTables the tables of the records of the nodes deleted when a node is purged.
+// The ledgers of the points and the settlements are kept, the totals of the closed epochs are derived from them
+var purgeNodeTables = []string{
+	replicaInfoTable,
+	validationResultTable,
+	validatorsTable,
+	assetsViewTable,
+	workloadRecordTable,
+	replicaEventTable,
+	retrieveEventTable,
+	hardwareProofTable,
+	accountNodeTable,
+	alertSubscribeTable,
+	nodeLoginTable,
+	nodeLoginNonceTable,
+	nodeTrafficDailyTable,
+	commitmentTable,
+	commitmentRecordTable,
+	nodeStatsDailyTable,
+	decisionNodeTable,
+	uploadLimitTable,
+	bandwidthTestTable,
+	nodeDiagnosticsTable,
+	upgradeNodeTable,
+	nodeConfigAckTable,
+	retrievalProbeTable,
+	spotCheckTable,
+	maintenanceTable,
+	bulkJobItemTable,
+	nodeTaskTable,
+	sandboxNodeTable,
+}
+
+// LoadNodesToArchive load the ids of the nodes last seen before the time
+func (n *SQLDB) LoadNodesToArchive(lastSeenBefore time.Time, limit int) ([]string, error) {
+	var out []string
+	query := fmt.Sprintf(`SELECT node_id FROM %s WHERE last_seen<? ORDER BY last_seen LIMIT ?`, nodeInfoTable)
+	if err := n.db.Select(&out, query, lastSeenBefore, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ArchiveNode moves the info and the registration of the node last seen before the time to the archive tables,
+// returns false if the node is not found or seen since then
+func (n *SQLDB) ArchiveNode(nodeID string, lastSeenBefore, t time.Time) (bool, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ArchiveNode Rollback err:%s", err.Error())
+		}
+	}()
+
+	var lastSeen time.Time
+	query := fmt.Sprintf(`SELECT last_seen FROM %s WHERE node_id=? FOR UPDATE`, nodeInfoTable)
+	if err = tx.Get(&lastSeen, query, nodeID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !lastSeen.Before(lastSeenBefore) {
+		return false, nil
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, node_type, last_seen, archived_time)
+			SELECT a.node_id, IFNULL(b.node_type,''), a.last_seen, ? FROM %s a LEFT JOIN %s b ON a.node_id=b.node_id WHERE a.node_id=?`,
+		nodeArchiveTable, nodeInfoTable, nodeRegisterTable)
+	if _, err = tx.Exec(query, t, nodeID); err != nil {
+		return false, err
+	}
+
+	if err = moveNodeRows(tx, nodeInfoTable, nodeInfoArchive, nodeID); err != nil {
+		return false, err
+	}
+
+	if err = moveNodeRows(tx, nodeRegisterTable, nodeRegisterArchive, nodeID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RestoreArchivedNode moves the info and the registration of the archived node back
+func (n *SQLDB) RestoreArchivedNode(nodeID string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("RestoreArchivedNode Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, nodeArchiveTable)
+	result, err := tx.Exec(query, nodeID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return xerrors.Errorf("node %s is not archived", nodeID)
+	}
+
+	if err = moveNodeRows(tx, nodeInfoArchive, nodeInfoTable, nodeID); err != nil {
+		return err
+	}
+
+	if err = moveNodeRows(tx, nodeRegisterArchive, nodeRegisterTable, nodeID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeArchivedNode deletes the archived node with its records
+func (n *SQLDB) PurgeArchivedNode(nodeID string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("PurgeArchivedNode Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, nodeArchiveTable)
+	result, err := tx.Exec(query, nodeID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return xerrors.Errorf("node %s is not archived", nodeID)
+	}
+
+	for _, table := range append([]string{nodeInfoArchive, nodeRegisterArchive}, purgeNodeTables...) {
+		query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, table)
+		if _, err = tx.Exec(query, nodeID); err != nil {
+			return xerrors.Errorf("delete from %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// moveNodeRows moves the rows of the node from the table to the table of the same columns
+func moveNodeRows(tx *sqlx.Tx, from, to, nodeID string) error {
+	query := fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s WHERE node_id=?`, to, from)
+	if _, err := tx.Exec(query, nodeID); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, from)
+	_, err := tx.Exec(query, nodeID)
+	return err
+}
+
+// IsNodeArchived checks if the node is archived
+func (n *SQLDB) IsNodeArchived(nodeID string) (bool, error) {
+	var count int
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE node_id=?`, nodeArchiveTable)
+	if err := n.db.Get(&count, query, nodeID); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// LoadArchivedNodes load the archived nodes, the latest archived first
+func (n *SQLDB) LoadArchivedNodes(limit, offset int) (*types.ListArchivedNodeRsp, error) {
+	res := new(types.ListArchivedNodeRsp)
+
+	if limit > loadArchivedNodesDefaultLimit || limit <= 0 {
+		limit = loadArchivedNodesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", nodeArchiveTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY archived_time DESC LIMIT ? OFFSET ?", nodeArchiveTable)
+	if err := n.db.Select(&res.Data, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
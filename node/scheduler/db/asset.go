@@ -63,6 +63,19 @@ func (n *SQLDB) SaveReplicaEvent(hash, cid, nodeID string, size int64, expiratio
 		return err
 	}
 
+	if event == types.ReplicaEventAdd {
+		err = incrCounter(tx, replicaCountCounter, 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	payload := &types.OutboxReplicaPayload{Hash: hash, CID: cid, NodeID: nodeID, Size: size}
+	err = saveOutboxEvent(tx, types.OutboxReplicaAdded, hash, payload)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -143,9 +156,136 @@ func (n *SQLDB) UpdateAssetInfo(hash, state string, totalBlock, totalSize, retry
 		return err
 	}
 
+	payload := &types.OutboxAssetStatePayload{Hash: hash, State: state, TotalBlocks: totalBlock, TotalSize: totalSize, ServerID: string(serverID)}
+	err = saveOutboxEvent(tx, types.OutboxAssetState, hash, payload)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// LoadAssetQoSTier load the qos tier of the asset
+func (n *SQLDB) LoadAssetQoSTier(hash string) (types.AssetQoSTier, error) {
+	var tier types.AssetQoSTier
+	query := fmt.Sprintf("SELECT qos_tier FROM %s WHERE hash=?", assetRecordTable)
+	err := n.db.Get(&tier, query, hash)
+	return tier, err
+}
+
+// UpdateAssetRecordQoSTier update the qos tier of the asset, the placed replicas are kept
+func (n *SQLDB) UpdateAssetRecordQoSTier(hash string, tier types.AssetQoSTier) error {
+	query := fmt.Sprintf(`UPDATE %s SET qos_tier=? WHERE hash=?`, assetRecordTable)
+	result, err := n.db.Exec(query, tier, hash)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if r < 1 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// LoadAssetVideoFormat load the video format of the asset
+func (n *SQLDB) LoadAssetVideoFormat(hash string) (types.AssetVideoFormat, error) {
+	var format types.AssetVideoFormat
+	query := fmt.Sprintf("SELECT video_format FROM %s WHERE hash=?", assetRecordTable)
+	err := n.db.Get(&format, query, hash)
+	return format, err
+}
+
+// UpdateAssetRecordVideoFormat update the video format of the asset
+func (n *SQLDB) UpdateAssetRecordVideoFormat(hash string, format types.AssetVideoFormat) error {
+	query := fmt.Sprintf(`UPDATE %s SET video_format=? WHERE hash=?`, assetRecordTable)
+	result, err := n.db.Exec(query, format, hash)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if r < 1 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// tableColumn a column added to a table after its creation with its definition
+type tableColumn struct {
+	name       string
+	definition string
+}
+
+// assetRecordColumns the columns added to the asset records after their creation
+var assetRecordColumns = []tableColumn{
+	{"qos_tier", fmt.Sprintf("VARCHAR(16) DEFAULT '%s'", types.AssetQoSTierStandard)},
+	{"video_format", "VARCHAR(8) DEFAULT ''"},
+}
+
+// migrateColumns adds the columns missing from the table created before the columns,
+// the existing rows get the defaults of the columns
+func migrateColumns(tx *sqlx.Tx, table string, columns []tableColumn) error {
+	for _, column := range columns {
+		var count int
+		query := `SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND COLUMN_NAME=?`
+		if err := tx.Get(&count, query, table, column.name); err != nil {
+			return xerrors.Errorf("load column %s.%s: %w", table, column.name, err)
+		}
+
+		if count > 0 {
+			continue
+		}
+
+		log.Infof("migrate %s add column %s", table, column.name)
+
+		query = fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column.name, column.definition)
+		if _, err := tx.Exec(query); err != nil {
+			return xerrors.Errorf("migrate column %s.%s: %w", table, column.name, err)
+		}
+	}
+
+	return nil
+}
+
+// tableIndex an index added to a table after its creation with its columns
+type tableIndex struct {
+	name    string
+	columns string
+}
+
+// migrateIndexes adds the indexes missing from the table created before the indexes
+func migrateIndexes(tx *sqlx.Tx, table string, indexes []tableIndex) error {
+	for _, index := range indexes {
+		var count int
+		query := `SELECT COUNT(*) FROM information_schema.STATISTICS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND INDEX_NAME=?`
+		if err := tx.Get(&count, query, table, index.name); err != nil {
+			return xerrors.Errorf("load index %s.%s: %w", table, index.name, err)
+		}
+
+		if count > 0 {
+			continue
+		}
+
+		log.Infof("migrate %s add index %s", table, index.name)
+
+		query = fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s)", table, index.name, index.columns)
+		if _, err := tx.Exec(query); err != nil {
+			return xerrors.Errorf("migrate index %s.%s: %w", table, index.name, err)
+		}
+	}
+
+	return nil
+}
+
 // LoadAssetRecord load asset record information
 func (n *SQLDB) LoadAssetRecord(hash string) (*types.AssetRecord, error) {
 	var info types.AssetRecord
@@ -401,13 +541,25 @@ func (n *SQLDB) DeleteAssetReplica(hash, nodeID string) error {
 		}
 	}()
 
+	var succeeded int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE hash=? AND node_id=? AND status=?`, replicaInfoTable)
+	err = tx.Get(&succeeded, query, hash, nodeID, types.ReplicaStatusSucceeded)
+	if err != nil {
+		return err
+	}
+
 	// replica info
-	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND node_id=?`, replicaInfoTable)
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND node_id=?`, replicaInfoTable)
 	_, err = tx.Exec(query, hash, nodeID)
 	if err != nil {
 		return err
 	}
 
+	err = incrCounter(tx, replicaCountCounter, -succeeded)
+	if err != nil {
+		return err
+	}
+
 	// replica event
 	query = fmt.Sprintf(
 		`INSERT INTO %s (hash, event, node_id) 
@@ -418,6 +570,11 @@ func (n *SQLDB) DeleteAssetReplica(hash, nodeID string) error {
 		return err
 	}
 
+	err = saveOutboxEvent(tx, types.OutboxReplicaRemoved, hash, &types.OutboxReplicaPayload{Hash: hash, NodeID: nodeID})
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -527,15 +684,20 @@ func (n *SQLDB) SaveAssetRecord(rInfo *types.AssetRecord) error {
 
 	// asset record
 	query := fmt.Sprintf(
-		`INSERT INTO %s (hash, scheduler_sid, cid, edge_replicas, candidate_replicas, expiration, bandwidth, total_size, created_time, note) 
-		        VALUES (:hash, :scheduler_sid, :cid, :edge_replicas, :candidate_replicas, :expiration, :bandwidth, :total_size, :created_time, :note)
+		`INSERT INTO %s (hash, scheduler_sid, cid, edge_replicas, candidate_replicas, expiration, bandwidth, total_size, created_time, note, qos_tier, video_format) 
+		        VALUES (:hash, :scheduler_sid, :cid, :edge_replicas, :candidate_replicas, :expiration, :bandwidth, :total_size, :created_time, :note, :qos_tier, :video_format)
 				ON DUPLICATE KEY UPDATE scheduler_sid=:scheduler_sid, edge_replicas=:edge_replicas, created_time=:created_time,
-				candidate_replicas=:candidate_replicas, expiration=:expiration, bandwidth=:bandwidth, total_size=:total_size`, assetRecordTable)
+				candidate_replicas=:candidate_replicas, expiration=:expiration, bandwidth=:bandwidth, total_size=:total_size, qos_tier=:qos_tier, video_format=:video_format`, assetRecordTable)
 	_, err = tx.NamedExec(query, rInfo)
 	if err != nil {
 		return err
 	}
 
+	// the owners saved before the record are counted when the record is created
+	if err = refreshAssetOwners(tx, rInfo.Hash); err != nil {
+		return err
+	}
+
 	query = fmt.Sprintf(
 		`INSERT INTO %s (hash, state, replenish_replicas) 
 		        VALUES (?, ?, ?) 
@@ -666,12 +828,24 @@ func (n *SQLDB) DeleteAssetRecordsOfNode(nodeID string) error {
 		}
 	}()
 
-	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=? `, replicaInfoTable)
+	var succeeded int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE node_id=? AND status=?`, replicaInfoTable)
+	err = tx.Get(&succeeded, query, nodeID, types.ReplicaStatusSucceeded)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=? `, replicaInfoTable)
 	_, err = tx.Exec(query, nodeID)
 	if err != nil {
 		return err
 	}
 
+	err = incrCounter(tx, replicaCountCounter, -succeeded)
+	if err != nil {
+		return err
+	}
+
 	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, assetsViewTable)
 	_, err = tx.Exec(query, nodeID)
 	if err != nil {
@@ -687,10 +861,13 @@ func (n *SQLDB) DeleteAssetRecordsOfNode(nodeID string) error {
 	return tx.Commit()
 }
 
-// DeleteReplicaEvents delete events
-func (n *SQLDB) DeleteReplicaEvents() error {
-	query := fmt.Sprintf(`DELETE FROM %s WHERE end_time<DATE_SUB(NOW(), INTERVAL 3 MONTH) `, replicaEventTable)
-	_, err := n.db.Exec(query)
+// CountNodeReplicas counts the replicas assigned to the node in any status
+func (n *SQLDB) CountNodeReplicas(nodeID string) (int64, error) {
+	var total int64
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE node_id=?`, replicaInfoTable)
+	if err := n.db.Get(&total, query, nodeID); err != nil {
+		return 0, err
+	}
 
-	return err
+	return total, nil
 }
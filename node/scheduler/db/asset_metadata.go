@@ -0,0 +1,161 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// the assets found by a search at most if the request does not ask for less
+const searchAssetsDefaultLimit = 500
+
+// userAssetColumns the columns added to the assets of the users after their creation
+var userAssetColumns = []tableColumn{
+	{"content_type", "VARCHAR(128) DEFAULT ''"},
+}
+
+// userAssetIndexes the indexes the assets of the users are searched by, added after their creation
+var userAssetIndexes = []tableIndex{
+	{"idx_user_name", "user_id, asset_name"},
+	{"idx_user_created", "user_id, created_time"},
+	{"idx_user_size", "user_id, total_size"},
+}
+
+// assetLabel a label of an asset of a user
+type assetLabel struct {
+	Hash  string `db:"hash"`
+	Name  string `db:"name"`
+	Value string `db:"value"`
+}
+
+// saveAssetLabels saves the labels of the asset of the user in the transaction of the asset
+func saveAssetLabels(tx sqlx.Execer, userID, hash string, labels types.AssetLabels) error {
+	for name, value := range labels {
+		query := fmt.Sprintf(`INSERT INTO %s (user_id, hash, name, value) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE value=?`, userAssetLabelTable)
+		if _, err := tx.Exec(query, userID, hash, name, value, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SearchUserAssets load the assets of the user matching the search with their cids and labels, the latest first
+func (n *SQLDB) SearchUserAssets(req *types.SearchAssetsReq) (*types.SearchAssetsRsp, error) {
+	limit := req.Limit
+	if limit > searchAssetsDefaultLimit || limit <= 0 {
+		limit = searchAssetsDefaultLimit
+	}
+
+	where, args := searchAssetsCondition(req)
+
+	res := new(types.SearchAssetsRsp)
+	query := fmt.Sprintf(`SELECT count(*) FROM %s ua WHERE %s`, userAssetTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`SELECT ua.*, IFNULL(r.cid, '') AS cid FROM %s ua LEFT JOIN %s r ON r.hash=ua.hash
+				WHERE %s ORDER BY ua.created_time DESC LIMIT ? OFFSET ?`, userAssetTable, assetRecordTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, req.Offset)...); err != nil {
+		return nil, err
+	}
+
+	if len(res.Data) == 0 {
+		return res, nil
+	}
+
+	hashes := make([]string, 0, len(res.Data))
+	for _, asset := range res.Data {
+		hashes = append(hashes, asset.Hash)
+	}
+
+	labels, err := n.loadAssetLabels(req.UserID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, asset := range res.Data {
+		asset.Labels = labels[asset.Hash]
+	}
+
+	return res, nil
+}
+
+// searchAssetsCondition returns the where clause of the search on the user asset table aliased ua and its arguments,
+// a label without value matches the assets with the label of any value
+func searchAssetsCondition(req *types.SearchAssetsReq) (string, []interface{}) {
+	conditions := []string{"ua.user_id=?"}
+	args := []interface{}{req.UserID}
+
+	if req.NamePrefix != "" {
+		conditions = append(conditions, "ua.asset_name LIKE ?")
+		args = append(args, escapeLike(req.NamePrefix)+"%")
+	}
+
+	if req.MinSize > 0 {
+		conditions = append(conditions, "ua.total_size>=?")
+		args = append(args, req.MinSize)
+	}
+
+	if req.MaxSize > 0 {
+		conditions = append(conditions, "ua.total_size<=?")
+		args = append(args, req.MaxSize)
+	}
+
+	if !req.CreatedAfter.IsZero() {
+		conditions = append(conditions, "ua.created_time>=?")
+		args = append(args, req.CreatedAfter)
+	}
+
+	if !req.CreatedBefore.IsZero() {
+		conditions = append(conditions, "ua.created_time<?")
+		args = append(args, req.CreatedBefore)
+	}
+
+	names := make([]string, 0, len(req.Labels))
+	for name := range req.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		condition := fmt.Sprintf("EXISTS (SELECT 1 FROM %s l WHERE l.user_id=ua.user_id AND l.hash=ua.hash AND l.name=?", userAssetLabelTable)
+		args = append(args, name)
+
+		if value := req.Labels[name]; value != "" {
+			condition += " AND l.value=?"
+			args = append(args, value)
+		}
+
+		conditions = append(conditions, condition+")")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// loadAssetLabels load the labels of the assets of the user by the hashes of the assets
+func (n *SQLDB) loadAssetLabels(userID string, hashes []string) (map[string]types.AssetLabels, error) {
+	query, args, err := sqlx.In(fmt.Sprintf(`SELECT hash, name, value FROM %s WHERE user_id=? AND hash IN (?)`, userAssetLabelTable), userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*assetLabel
+	if err = n.db.Select(&list, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]types.AssetLabels)
+	for _, label := range list {
+		if out[label.Hash] == nil {
+			out[label.Hash] = make(types.AssetLabels)
+		}
+		out[label.Hash][label.Name] = label.Value
+	}
+
+	return out, nil
+}
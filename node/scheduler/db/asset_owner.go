@@ -0,0 +1,181 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
+)
+
+// migrateAssetOwnerCount adds the owner count to the asset records created before it and counts the owners of the
+// existing assets
+func migrateAssetOwnerCount(tx *sqlx.Tx) error {
+	var count int
+	query := `SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND COLUMN_NAME='owner_count'`
+	if err := tx.Get(&count, query, assetRecordTable); err != nil {
+		return xerrors.Errorf("load column %s.owner_count: %w", assetRecordTable, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	log.Infof("migrate %s add column owner_count", assetRecordTable)
+
+	query = fmt.Sprintf("ALTER TABLE %s ADD COLUMN owner_count INT DEFAULT 0", assetRecordTable)
+	if _, err := tx.Exec(query); err != nil {
+		return xerrors.Errorf("migrate column %s.owner_count: %w", assetRecordTable, err)
+	}
+
+	query = fmt.Sprintf(`UPDATE %s r SET owner_count=(SELECT COUNT(*) FROM %s ua WHERE ua.hash=r.hash)`, assetRecordTable, userAssetTable)
+	if _, err := tx.Exec(query); err != nil {
+		return xerrors.Errorf("count owners of %s: %w", assetRecordTable, err)
+	}
+
+	return nil
+}
+
+// lockAssetRecord locks the record of the asset in the transaction, so the owners of the asset change one at a time,
+// the asset without record is not locked
+func lockAssetRecord(tx *sqlx.Tx, hash string) error {
+	var count int64
+	query := fmt.Sprintf(`SELECT owner_count FROM %s WHERE hash=? FOR UPDATE`, assetRecordTable)
+	err := tx.Get(&count, query, hash)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	return err
+}
+
+// refreshAssetOwners counts the owners of the asset into the record of the asset
+func refreshAssetOwners(tx *sqlx.Tx, hash string) error {
+	query := fmt.Sprintf(`UPDATE %s SET owner_count=(SELECT COUNT(*) FROM %s WHERE hash=?) WHERE hash=?`, assetRecordTable, userAssetTable)
+	_, err := tx.Exec(query, hash, hash)
+	return err
+}
+
+// ReleaseAssetOwner removes the user from the owners of the asset and refunds the storage charged to the user.
+// The last owner is kept and true is returned, the replicas of the asset are purged with its last owner
+func (n *SQLDB) ReleaseAssetOwner(hash, userID string) (bool, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ReleaseAssetOwner Rollback err:%s", err.Error())
+		}
+	}()
+
+	if err = lockAssetRecord(tx, hash); err != nil {
+		return false, err
+	}
+
+	var owners []string
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE hash=?", userAssetTable)
+	if err = tx.Select(&owners, query, hash); err != nil {
+		return false, err
+	}
+
+	owned := false
+	for _, owner := range owners {
+		if owner == userID {
+			owned = true
+			break
+		}
+	}
+
+	if !owned {
+		return false, xerrors.Errorf("user %s does not own the asset %s", userID, hash)
+	}
+
+	if len(owners) == 1 {
+		return true, nil
+	}
+
+	if err = deleteAssetUser(tx, hash, userID); err != nil {
+		return false, err
+	}
+
+	if err = refreshAssetOwners(tx, hash); err != nil {
+		return false, err
+	}
+
+	return false, tx.Commit()
+}
+
+// ExpireAssetOwners removes the owners of the asset whose assets expired and keeps the asset until the latest
+// expiration of the other owners, the expired event of the removed owners is written to the outbox.
+// Returns the number of the owners kept, the asset expires with all its owners if none is kept
+func (n *SQLDB) ExpireAssetOwners(hash, cid string) (int, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ExpireAssetOwners Rollback err:%s", err.Error())
+		}
+	}()
+
+	if err = lockAssetRecord(tx, hash); err != nil {
+		return 0, err
+	}
+
+	var owners []*types.UserAssetDetail
+	query := fmt.Sprintf("SELECT user_id, expiration FROM %s WHERE hash=?", userAssetTable)
+	if err = tx.Select(&owners, query, hash); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var expired []string
+	var latest time.Time
+	for _, owner := range owners {
+		if !owner.Expiration.After(now) {
+			expired = append(expired, owner.UserID)
+			continue
+		}
+
+		if owner.Expiration.After(latest) {
+			latest = owner.Expiration
+		}
+	}
+
+	kept := len(owners) - len(expired)
+	if kept == 0 {
+		return 0, nil
+	}
+
+	for _, userID := range expired {
+		if err = deleteAssetUser(tx, hash, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(expired) > 0 {
+		payload := &types.OutboxAssetLifecyclePayload{Hash: hash, CID: cid, Users: expired}
+		if err = saveOutboxEvent(tx, types.OutboxAssetExpired, hash, payload); err != nil {
+			return 0, err
+		}
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET expiration=? WHERE hash=?`, assetRecordTable)
+	if _, err = tx.Exec(query, latest, hash); err != nil {
+		return 0, err
+	}
+
+	if err = refreshAssetOwners(tx, hash); err != nil {
+		return 0, err
+	}
+
+	return kept, tx.Commit()
+}
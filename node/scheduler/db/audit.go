@@ -0,0 +1,55 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveAuditLog appends the audit log
+func (n *SQLDB) SaveAuditLog(log *types.AuditLog) error {
+	query := fmt.Sprintf(`INSERT INTO %s (actor, role, method, args, result, success) VALUES (:actor, :role, :method, :args, :result, :success)`, auditLogTable)
+	_, err := n.db.NamedExec(query, log)
+	return err
+}
+
+// LoadAuditLogs load the audit logs matching the filters, the latest first
+func (n *SQLDB) LoadAuditLogs(req *types.ListAuditLogsReq) (*types.ListAuditLogRsp, error) {
+	res := new(types.ListAuditLogRsp)
+
+	limit := req.Limit
+	if limit > loadAuditLogsDefaultLimit || limit <= 0 {
+		limit = loadAuditLogsDefaultLimit
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	if req.Actor != "" {
+		where += " AND actor=?"
+		args = append(args, req.Actor)
+	}
+	if req.Method != "" {
+		where += " AND method=?"
+		args = append(args, req.Method)
+	}
+	if !req.Start.IsZero() {
+		where += " AND created_time>=?"
+		args = append(args, req.Start)
+	}
+	if !req.End.IsZero() {
+		where += " AND created_time<?"
+		args = append(args, req.End)
+	}
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s %s", auditLogTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s %s order by id desc LIMIT ? OFFSET ?", auditLogTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, req.Offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveBandwidthTest saves the started bandwidth test
+func (n *SQLDB) SaveBandwidthTest(test *types.BandwidthTest) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, node_id, status) VALUES (:id, :node_id, :status)`, bandwidthTestTable)
+	_, err := n.db.NamedExec(query, test)
+	return err
+}
+
+// UpdateBandwidthTest saves the result of the finished bandwidth test
+func (n *SQLDB) UpdateBandwidthTest(test *types.BandwidthTest) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=:status, bandwidth_up=:bandwidth_up, bandwidth_down=:bandwidth_down, latency=:latency,
+	        message=:message, finished_time=NOW() WHERE id=:id`, bandwidthTestTable)
+	_, err := n.db.NamedExec(query, test)
+	return err
+}
+
+// LoadBandwidthTest loads the bandwidth test, returns sql.ErrNoRows if it does not exist
+func (n *SQLDB) LoadBandwidthTest(id string) (*types.BandwidthTest, error) {
+	var out types.BandwidthTest
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=?`, bandwidthTestTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CountBandwidthTestsSince counts the bandwidth tests of the node started since the time
+func (n *SQLDB) CountBandwidthTestsSince(nodeID string, since time.Time) (int, error) {
+	var count int
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE node_id=? AND created_time>?`, bandwidthTestTable)
+	err := n.db.Get(&count, query, nodeID, since)
+	return count, err
+}
+
+// LoadBandwidthTests loads the bandwidth tests of the node, the latest first
+func (n *SQLDB) LoadBandwidthTests(nodeID string, limit, offset int) (*types.ListBandwidthTestRsp, error) {
+	res := new(types.ListBandwidthTestRsp)
+
+	if limit > loadBandwidthTestsDefaultLimit || limit <= 0 {
+		limit = loadBandwidthTestsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", bandwidthTestTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY created_time DESC LIMIT ? OFFSET ?", bandwidthTestTable)
+	if err := n.db.Select(&res.Tests, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveBulkJob saves the bulk job with its nodes pending
+func (n *SQLDB) SaveBulkJob(job *types.BulkJob, nodeIDs []string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveBulkJob Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, operation, filter, server_id, total, status, created_time, finished_time)
+			VALUES (:id, :operation, :filter, :server_id, :total, :status, :created_time, :finished_time)`, bulkJobTable)
+	if _, err = tx.NamedExec(query, job); err != nil {
+		return err
+	}
+
+	items := make([]*types.BulkJobItem, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		items = append(items, &types.BulkJobItem{JobID: job.ID, NodeID: nodeID, Status: types.BulkJobItemPending, UpdatedTime: job.CreatedTime})
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (job_id, node_id, status, updated_time) VALUES (:job_id, :node_id, :status, :updated_time)`, bulkJobItemTable)
+	for len(items) > 0 {
+		batch := items
+		if len(batch) > loadBulkJobItemsDefaultLimit {
+			batch = items[:loadBulkJobItemsDefaultLimit]
+		}
+		items = items[len(batch):]
+
+		if _, err = tx.NamedExec(query, batch); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateBulkJobItem saves the result of the job on the node and counts it in the progress of the job
+func (n *SQLDB) UpdateBulkJobItem(item *types.BulkJobItem) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("UpdateBulkJobItem Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`UPDATE %s SET status=:status, message=:message, updated_time=:updated_time WHERE job_id=:job_id AND node_id=:node_id`, bulkJobItemTable)
+	if _, err = tx.NamedExec(query, item); err != nil {
+		return err
+	}
+
+	column := "succeeded"
+	if item.Status == types.BulkJobItemFailed {
+		column = "failed"
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET %s=%s+1 WHERE id=?`, bulkJobTable, column, column)
+	if _, err = tx.Exec(query, item.JobID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FinishBulkJob sets the status of the running job
+func (n *SQLDB) FinishBulkJob(id string, status types.BulkJobStatus, t time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, finished_time=? WHERE id=? AND status=?`, bulkJobTable)
+	_, err := n.db.Exec(query, status, t, id, types.BulkJobRunning)
+	return err
+}
+
+// LoadBulkJob load the bulk job
+func (n *SQLDB) LoadBulkJob(id string) (*types.BulkJob, error) {
+	var out types.BulkJob
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?", bulkJobTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadBulkJobs load the bulk jobs, the latest first
+func (n *SQLDB) LoadBulkJobs(limit, offset int) (*types.ListBulkJobRsp, error) {
+	res := new(types.ListBulkJobRsp)
+
+	if limit > loadBulkJobsDefaultLimit || limit <= 0 {
+		limit = loadBulkJobsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", bulkJobTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY created_time DESC LIMIT ? OFFSET ?", bulkJobTable)
+	if err := n.db.Select(&res.Data, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadBulkJobItems load the nodes of the bulk job, the nodes of all statuses if status is empty
+func (n *SQLDB) LoadBulkJobItems(jobID string, status types.BulkJobItemStatus, limit, offset int) (*types.ListBulkJobItemRsp, error) {
+	res := new(types.ListBulkJobItemRsp)
+
+	if limit > loadBulkJobItemsDefaultLimit || limit <= 0 {
+		limit = loadBulkJobItemsDefaultLimit
+	}
+
+	where := "WHERE job_id=?"
+	args := []interface{}{jobID}
+	if status != "" {
+		where += " AND status=?"
+		args = append(args, status)
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s %s", bulkJobItemTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s %s ORDER BY node_id LIMIT ? OFFSET ?", bulkJobItemTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadSchedulerNodeIDs load the ids of the nodes last connected to the scheduler, the nodes of all types if the type is unknown,
+// the nodes last seen before and after the times if they are not zero
+func (n *SQLDB) LoadSchedulerNodeIDs(serverID string, nodeType types.NodeType, lastSeenBefore, lastSeenAfter time.Time) ([]string, error) {
+	conditions := []string{"a.scheduler_sid=?"}
+	args := []interface{}{serverID}
+
+	if nodeType != types.NodeUnknown {
+		conditions = append(conditions, "b.node_type=?")
+		args = append(args, nodeType)
+	}
+
+	if !lastSeenBefore.IsZero() {
+		conditions = append(conditions, "a.last_seen<?")
+		args = append(args, lastSeenBefore)
+	}
+
+	if !lastSeenAfter.IsZero() {
+		conditions = append(conditions, "a.last_seen>?")
+		args = append(args, lastSeenAfter)
+	}
+
+	var out []string
+	query := fmt.Sprintf(`SELECT a.node_id FROM %s a LEFT JOIN %s b ON a.node_id=b.node_id WHERE %s`,
+		nodeInfoTable, nodeRegisterTable, strings.Join(conditions, " AND "))
+	if err := n.db.Select(&out, query, args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
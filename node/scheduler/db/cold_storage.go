@@ -0,0 +1,154 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/jmoiron/sqlx"
+)
+
+// the cold assets listed at most if the request does not ask for less
+const loadColdAssetsDefaultLimit = 500
+
+// SaveAssetAccesses records the time the assets were last retrieved at
+func (n *SQLDB) SaveAssetAccesses(hashes []string, retrieved time.Time) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(hashes))
+	args := make([]interface{}, 0, len(hashes)*2)
+	for _, hash := range hashes {
+		values = append(values, "(?, ?)")
+		args = append(args, hash, retrieved)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (hash, last_retrieved) VALUES %s
+				ON DUPLICATE KEY UPDATE last_retrieved=GREATEST(last_retrieved, VALUES(last_retrieved))`, assetAccessTable, strings.Join(values, ","))
+	_, err := n.db.Exec(query, args...)
+	return err
+}
+
+// LoadIdleAssets load the assets of the scheduler in the states created and last retrieved before the time and not smaller
+// than the size, which are not offloaded yet, whose deals are active or whose deals failed before the time failedBefore,
+// the state of the deal is empty if the asset is not offloaded
+func (n *SQLDB) LoadIdleAssets(serverID dtypes.ServerID, states []string, before time.Time, minSize int64, failedBefore time.Time, limit int) ([]*types.ColdAsset, error) {
+	query := fmt.Sprintf(`SELECT r.hash, r.cid, r.total_size AS size, IFNULL(c.deal_id, '') AS deal_id, IFNULL(c.state, '') AS state
+				FROM %s r JOIN %s s ON s.hash=r.hash LEFT JOIN %s a ON a.hash=r.hash LEFT JOIN %s c ON c.hash=r.hash
+				WHERE s.state IN (?) AND r.created_time<? AND r.total_size>=? AND (a.last_retrieved IS NULL OR a.last_retrieved<?)
+				AND (c.state IS NULL OR c.state=? OR (c.state=? AND c.updated_time<?)) LIMIT ?`,
+		assetRecordTable, assetStateTable(serverID), assetAccessTable, coldAssetTable)
+	query, args, err := sqlx.In(query, states, before, minSize, before, types.ColdAssetActive, types.ColdAssetFailed, failedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.ColdAsset
+	if err = n.db.Select(&out, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SaveColdAsset saves the asset offloaded into a deal, the failed deal of the asset is replaced
+func (n *SQLDB) SaveColdAsset(asset *types.ColdAsset) error {
+	query := fmt.Sprintf(`INSERT INTO %s (hash, cid, size, deal_id, state, message) VALUES (:hash, :cid, :size, :deal_id, :state, :message)
+				ON DUPLICATE KEY UPDATE deal_id=:deal_id, state=:state, provider='', piece_cid='', chain_deal_id=0, message=:message,
+				created_time=NOW(), updated_time=NOW()`, coldAssetTable)
+	_, err := n.db.NamedExec(query, asset)
+	return err
+}
+
+// LoadColdAsset load the asset offloaded into a deal
+func (n *SQLDB) LoadColdAsset(hash string) (*types.ColdAsset, error) {
+	var asset types.ColdAsset
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=?`, coldAssetTable)
+	if err := n.db.Get(&asset, query, hash); err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// LoadColdAssetsOfServer load the assets of the scheduler offloaded into the deals in the state, the least recently
+// updated first
+func (n *SQLDB) LoadColdAssetsOfServer(serverID dtypes.ServerID, state types.ColdAssetState, limit int) ([]*types.ColdAsset, error) {
+	query := fmt.Sprintf(`SELECT c.* FROM %s c JOIN %s s ON s.hash=c.hash WHERE c.state=? ORDER BY c.updated_time LIMIT ?`,
+		coldAssetTable, assetStateTable(serverID))
+
+	var out []*types.ColdAsset
+	if err := n.db.Select(&out, query, state, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateColdAsset saves the state of the deal of the asset reported by the deal-making component
+func (n *SQLDB) UpdateColdAsset(asset *types.ColdAsset) error {
+	query := fmt.Sprintf(`UPDATE %s SET state=:state, provider=:provider, piece_cid=:piece_cid, chain_deal_id=:chain_deal_id,
+				message=:message, updated_time=NOW() WHERE hash=:hash`, coldAssetTable)
+	_, err := n.db.NamedExec(query, asset)
+	return err
+}
+
+// SwitchColdAssetState changes the state of the asset if the asset is in the state from,
+// returns false if the asset is not in the state, e.g. it was changed by another scheduler
+func (n *SQLDB) SwitchColdAssetState(hash string, from, to types.ColdAssetState) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET state=?, updated_time=NOW() WHERE hash=? AND state=?`, coldAssetTable)
+	result, err := n.db.Exec(query, to, hash, from)
+	if err != nil {
+		return false, err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return r > 0, nil
+}
+
+// ListColdAssets load the assets offloaded into the deals, the latest updated first
+func (n *SQLDB) ListColdAssets(state types.ColdAssetState, limit, offset int) (*types.ListColdAssetsRsp, error) {
+	res := new(types.ListColdAssetsRsp)
+
+	if limit > loadColdAssetsDefaultLimit || limit <= 0 {
+		limit = loadColdAssetsDefaultLimit
+	}
+
+	where := "1=1"
+	var args []interface{}
+	if state != "" {
+		where = "state=?"
+		args = append(args, state)
+	}
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE %s`, coldAssetTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY updated_time DESC LIMIT ? OFFSET ?`, coldAssetTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteColdAsset deletes the deal and the retrievals of the asset removed
+func (n *SQLDB) DeleteColdAsset(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, coldAssetTable)
+	if _, err := n.db.Exec(query, hash); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetAccessTable)
+	_, err := n.db.Exec(query, hash)
+	return err
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// SaveNodeCommitment saves the commitment of the node, the previous commitment is replaced
+func (n *SQLDB) SaveNodeCommitment(c *types.NodeCommitment) error {
+	query := fmt.Sprintf(`REPLACE INTO %s (node_id, start_hour, end_hour) VALUES (:node_id, :start_hour, :end_hour)`, commitmentTable)
+	_, err := n.db.NamedExec(query, c)
+	return err
+}
+
+// DeleteNodeCommitment deletes the commitment of the node
+func (n *SQLDB) DeleteNodeCommitment(nodeID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, commitmentTable)
+	_, err := n.db.Exec(query, nodeID)
+	return err
+}
+
+// LoadNodeCommitment load the commitment of the node
+func (n *SQLDB) LoadNodeCommitment(nodeID string) (*types.NodeCommitment, error) {
+	var out types.NodeCommitment
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=?", commitmentTable)
+	if err := n.db.Get(&out, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadNodeCommitments load the commitments of all nodes
+func (n *SQLDB) LoadNodeCommitments() ([]*types.NodeCommitment, error) {
+	var out []*types.NodeCommitment
+	query := fmt.Sprintf("SELECT * FROM %s", commitmentTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadNodeActivities load the points and last seen time of the nodes
+func (n *SQLDB) LoadNodeActivities(nodeIDs []string) (map[string]*types.NodeActivity, error) {
+	out := make(map[string]*types.NodeActivity, len(nodeIDs))
+	if len(nodeIDs) == 0 {
+		return out, nil
+	}
+
+	sQuery := fmt.Sprintf("SELECT node_id, profit, last_seen FROM %s WHERE node_id in (?)", nodeInfoTable)
+	query, args, err := sqlx.In(sQuery, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*types.NodeActivity
+	if err = n.db.Select(&list, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	for _, a := range list {
+		out[a.NodeID] = a
+	}
+
+	return out, nil
+}
+
+// SaveNodeCommitmentRecord saves the record of the window and adds the bonus points to the node
+func (n *SQLDB) SaveNodeCommitmentRecord(record *types.NodeCommitmentRecord) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveNodeCommitmentRecord Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`REPLACE INTO %s (node_id, window_start, window_end, checked_minutes, present_minutes, kept, earned_points, bonus_points)
+	        VALUES (:node_id, :window_start, :window_end, :checked_minutes, :present_minutes, :kept, :earned_points, :bonus_points)`, commitmentRecordTable)
+	if _, err = tx.NamedExec(query, record); err != nil {
+		return err
+	}
+
+	if record.BonusPoints.Sign() > 0 {
+		query = fmt.Sprintf("UPDATE %s SET profit=profit+%s WHERE node_id=?", nodeInfoTable, pointsParam)
+		if _, err = tx.Exec(query, record.BonusPoints, record.NodeID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadNodeCommitmentRecords load the records of the commitment windows of the node, the latest first
+func (n *SQLDB) LoadNodeCommitmentRecords(nodeID string, limit, offset int) (*types.ListNodeCommitmentRecordRsp, error) {
+	res := new(types.ListNodeCommitmentRecordRsp)
+
+	if limit > loadCommitmentRecordsDefaultLimit || limit <= 0 {
+		limit = loadCommitmentRecordsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", commitmentRecordTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY window_start DESC LIMIT ? OFFSET ?", commitmentRecordTable)
+	if err := n.db.Select(&res.Data, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
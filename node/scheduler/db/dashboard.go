@@ -0,0 +1,88 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/jmoiron/sqlx"
+)
+
+// the validations failed by the node, the validations failed by the validator or the scheduler
+// do not count against the nodes
+var nodeValidationFailures = []types.ValidationStatus{
+	types.ValidationStatusNodeTimeOut,
+	types.ValidationStatusValidateFail,
+	types.ValidationStatusNodeOffline,
+}
+
+// validation statuses counted in the pass rate of the nodes
+var nodeValidationStatuses = append([]types.ValidationStatus{types.ValidationStatusSuccess}, nodeValidationFailures...)
+
+// LoadAssetStateCounts load the number of assets of the scheduler in each state
+func (n *SQLDB) LoadAssetStateCounts(serverID dtypes.ServerID) ([]*types.AssetStateCount, error) {
+	var out []*types.AssetStateCount
+	query := fmt.Sprintf(`SELECT state, COUNT(*) AS count FROM %s GROUP BY state ORDER BY state`, assetStateTable(serverID))
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadValidationPassRates load the validations started since the time and the ones passed of each hour
+func (n *SQLDB) LoadValidationPassRates(since time.Time) ([]*types.ValidationPassRate, error) {
+	var rows []struct {
+		Hour   int64 `db:"hour"`
+		Total  int64 `db:"total"`
+		Passed int64 `db:"passed"`
+	}
+
+	sQuery := fmt.Sprintf(`SELECT FLOOR(UNIX_TIMESTAMP(start_time)/3600) AS hour, COUNT(*) AS total, IFNULL(SUM(status=?),0) AS passed
+	    FROM %s WHERE start_time>=? AND status IN (?) GROUP BY hour ORDER BY hour`, validationResultTable)
+	query, args, err := sqlx.In(sQuery, types.ValidationStatusSuccess, since, nodeValidationStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	query = n.db.Rebind(query)
+	if err := n.db.Select(&rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	out := make([]*types.ValidationPassRate, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, &types.ValidationPassRate{Hour: time.Unix(row.Hour*3600, 0), Total: row.Total, Passed: row.Passed})
+	}
+
+	return out, nil
+}
+
+// LoadPointsDistribution load the nodes of the scheduler grouped by the order of magnitude of their points,
+// the nodes with less than 10 points are in the first bucket
+func (n *SQLDB) LoadPointsDistribution(serverID dtypes.ServerID) ([]*types.PointsBucket, error) {
+	var rows []struct {
+		Magnitude int     `db:"magnitude"`
+		Nodes     int64   `db:"nodes"`
+		Points    float64 `db:"points"`
+	}
+
+	query := fmt.Sprintf(`SELECT FLOOR(LOG10(GREATEST(profit,1))) AS magnitude, COUNT(*) AS nodes, IFNULL(SUM(profit),0) AS points
+	    FROM %s WHERE scheduler_sid=? GROUP BY magnitude ORDER BY magnitude`, nodeInfoTable)
+	if err := n.db.Select(&rows, query, serverID); err != nil {
+		return nil, err
+	}
+
+	out := make([]*types.PointsBucket, 0, len(rows))
+	for _, row := range rows {
+		bucket := &types.PointsBucket{Max: math.Pow10(row.Magnitude + 1), Nodes: row.Nodes, Points: row.Points}
+		if row.Magnitude > 0 {
+			bucket.Min = math.Pow10(row.Magnitude)
+		}
+		out = append(out, bucket)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// decisionColumns the columns of the decisions without the detail
+const decisionColumns = "id, kind, subject, params, considered, chosen, truncated, created_time"
+
+// SaveSchedulingDecisions saves the decisions and indexes the nodes they chose
+func (n *SQLDB) SaveSchedulingDecisions(decisions []*types.SchedulingDecision) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveSchedulingDecisions Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (kind, subject, params, considered, chosen, truncated, detail, created_time)
+		VALUES (:kind, :subject, :params, :considered, :chosen, :truncated, :detail, :created_time)`, decisionTable)
+	nodeQuery := fmt.Sprintf(`INSERT IGNORE INTO %s (node_id, decision_id) VALUES (?, ?)`, decisionNodeTable)
+
+	for _, d := range decisions {
+		ret, err := tx.NamedExec(query, d)
+		if err != nil {
+			return err
+		}
+
+		id, err := ret.LastInsertId()
+		if err != nil {
+			return err
+		}
+		d.ID = id
+
+		for _, step := range d.Steps {
+			if step.Outcome != types.DecisionOutcomeChosen {
+				continue
+			}
+
+			if _, err := tx.Exec(nodeQuery, step.NodeID, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadSchedulingDecisions load the decisions matching the filters without their detail, the latest first
+func (n *SQLDB) LoadSchedulingDecisions(req *types.ListSchedulingDecisionsReq) (*types.ListSchedulingDecisionRsp, error) {
+	res := new(types.ListSchedulingDecisionRsp)
+
+	limit := req.Limit
+	if limit > loadDecisionsDefaultLimit || limit <= 0 {
+		limit = loadDecisionsDefaultLimit
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	if req.Kind != "" {
+		where += " AND kind=?"
+		args = append(args, req.Kind)
+	}
+	if req.Subject != "" {
+		where += " AND subject=?"
+		args = append(args, req.Subject)
+	}
+	if req.NodeID != "" {
+		where += fmt.Sprintf(" AND id IN (SELECT decision_id FROM %s WHERE node_id=?)", decisionNodeTable)
+		args = append(args, req.NodeID)
+	}
+	if !req.Start.IsZero() {
+		where += " AND created_time>=?"
+		args = append(args, req.Start)
+	}
+	if !req.End.IsZero() {
+		where += " AND created_time<?"
+		args = append(args, req.End)
+	}
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s %s", decisionTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT %s FROM %s %s order by id desc LIMIT ? OFFSET ?", decisionColumns, decisionTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, req.Offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadSchedulingDecision load the decision with its detail
+func (n *SQLDB) LoadSchedulingDecision(id int64) (*types.SchedulingDecision, error) {
+	var info types.SchedulingDecision
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?", decisionTable)
+	if err := n.db.Get(&info, query, id); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// DeleteSchedulingDecisionsBefore deletes the decisions created before the time
+func (n *SQLDB) DeleteSchedulingDecisionsBefore(t time.Time) error {
+	var maxID sql.NullInt64
+	query := fmt.Sprintf("SELECT max(id) FROM %s WHERE created_time<?", decisionTable)
+	if err := n.db.Get(&maxID, query, t); err != nil {
+		return err
+	}
+	if !maxID.Valid {
+		return nil
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE decision_id<=?", decisionNodeTable)
+	if _, err := n.db.Exec(query, maxID.Int64); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE id<=?", decisionTable)
+	_, err := n.db.Exec(query, maxID.Int64)
+	return err
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveDenylistEntries adds the assets to the denylist and records the add events, it returns the assets newly added
+func (n *SQLDB) SaveDenylistEntries(entries []*types.DenylistEntry) ([]*types.DenylistEntry, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveDenylistEntries Rollback err:%s", err.Error())
+		}
+	}()
+
+	added := make([]*types.DenylistEntry, 0, len(entries))
+	for _, entry := range entries {
+		query := fmt.Sprintf(`INSERT IGNORE INTO %s (hash, cid, reason, source, actor) VALUES (:hash, :cid, :reason, :source, :actor)`, denylistTable)
+		result, err := tx.NamedExec(query, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if r, err := result.RowsAffected(); err != nil || r < 1 {
+			continue
+		}
+
+		query = fmt.Sprintf(`INSERT INTO %s (hash, cid, event, detail, actor) VALUES (?, ?, ?, ?, ?)`, denylistEventTable)
+		if _, err := tx.Exec(query, entry.Hash, entry.CID, types.DenylistEventAdd, fmt.Sprintf("%s: %s", entry.Source, entry.Reason), entry.Actor); err != nil {
+			return nil, err
+		}
+
+		added = append(added, entry)
+	}
+
+	return added, tx.Commit()
+}
+
+// DeleteDenylistEntry removes the asset from the denylist and records the remove event
+func (n *SQLDB) DeleteDenylistEntry(hash, cid, actor string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteDenylistEntry Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE hash=?", denylistTable)
+	result, err := tx.Exec(query, hash)
+	if err != nil {
+		return err
+	}
+
+	if r, err := result.RowsAffected(); err != nil || r < 1 {
+		return sql.ErrNoRows
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (hash, cid, event, actor) VALUES (?, ?, ?, ?)`, denylistEventTable)
+	if _, err := tx.Exec(query, hash, cid, types.DenylistEventRemove, actor); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadDenylistHashes load the hashes of all the banned assets
+func (n *SQLDB) LoadDenylistHashes() ([]string, error) {
+	var out []string
+	query := fmt.Sprintf("SELECT hash FROM %s", denylistTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadDenylist load the banned assets, the latest first
+func (n *SQLDB) LoadDenylist(limit, offset int) (*types.ListDenylistRsp, error) {
+	res := new(types.ListDenylistRsp)
+
+	if limit > loadDenylistDefaultLimit || limit <= 0 {
+		limit = loadDenylistDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(hash) FROM %s", denylistTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s order by created_time desc LIMIT ? OFFSET ?", denylistTable)
+	if err := n.db.Select(&res.Entries, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SaveDenylistEvent appends an event to the audit trail of the denylist
+func (n *SQLDB) SaveDenylistEvent(event *types.DenylistEvent) error {
+	query := fmt.Sprintf(`INSERT INTO %s (hash, cid, event, detail, actor) VALUES (:hash, :cid, :event, :detail, :actor)`, denylistEventTable)
+	_, err := n.db.NamedExec(query, event)
+	return err
+}
+
+// LoadDenylistEvents load the events of the denylist, the latest first, all the assets if hash is empty
+func (n *SQLDB) LoadDenylistEvents(hash string, limit, offset int) (*types.ListDenylistEventsRsp, error) {
+	res := new(types.ListDenylistEventsRsp)
+
+	if limit > loadDenylistDefaultLimit || limit <= 0 {
+		limit = loadDenylistDefaultLimit
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	if hash != "" {
+		where += " AND hash=?"
+		args = append(args, hash)
+	}
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s %s", denylistEventTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s %s order by id desc LIMIT ? OFFSET ?", denylistEventTable, where)
+	if err := n.db.Select(&res.Events, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
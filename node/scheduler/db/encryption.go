@@ -0,0 +1,30 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveAssetEncryption saves the encryption metadata of the user asset
+func (n *SQLDB) SaveAssetEncryption(info *types.AssetEncryption) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, user_id, algorithm, chunk_size, nonce, plain_size, key_mode, key_id, wrap_algorithm, wrapped_key) 
+		        VALUES (:hash, :user_id, :algorithm, :chunk_size, :nonce, :plain_size, :key_mode, :key_id, :wrap_algorithm, :wrapped_key)
+				ON DUPLICATE KEY UPDATE algorithm=VALUES(algorithm), chunk_size=VALUES(chunk_size), nonce=VALUES(nonce), plain_size=VALUES(plain_size),
+				key_mode=VALUES(key_mode), key_id=VALUES(key_id), wrap_algorithm=VALUES(wrap_algorithm), wrapped_key=VALUES(wrapped_key)`, assetEncryptionTable)
+	_, err := n.db.NamedExec(query, info)
+	return err
+}
+
+// LoadAssetEncryption loads the encryption metadata of the user asset
+func (n *SQLDB) LoadAssetEncryption(hash, userID string) (*types.AssetEncryption, error) {
+	var info types.AssetEncryption
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=? AND user_id=?", assetEncryptionTable)
+	err := n.db.Get(&info, query, hash, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
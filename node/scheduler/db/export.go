@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// dataExportColumns the columns of the exports with the status and the error of their jobs
+var dataExportColumns = fmt.Sprintf(`a.*, IFNULL(b.status,'') AS status, IFNULL(b.message,'') AS message
+		FROM %s a LEFT JOIN %s b ON a.id=b.id`, dataExportTable, jobQueueTable)
+
+// SaveDataExport saves the export before its job is enqueued
+func (n *SQLDB) SaveDataExport(export *types.DataExport) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, dataset, format, schema_version, start_time, end_time, object_key, created_time, finished_time)
+			VALUES (:id, :dataset, :format, :schema_version, :start_time, :end_time, :object_key, :created_time, :finished_time)`, dataExportTable)
+	_, err := n.db.NamedExec(query, export)
+	return err
+}
+
+// FinishDataExport saves the rows and the size of the export uploaded
+func (n *SQLDB) FinishDataExport(id string, rows, size int64, t time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET row_count=?, size=?, finished_time=? WHERE id=?`, dataExportTable)
+	_, err := n.db.Exec(query, rows, size, t, id)
+	return err
+}
+
+// LoadDataExport load the export
+func (n *SQLDB) LoadDataExport(id string) (*types.DataExport, error) {
+	var out types.DataExport
+	query := fmt.Sprintf("SELECT %s WHERE a.id=?", dataExportColumns)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadDataExports load the exports, the latest first
+func (n *SQLDB) LoadDataExports(limit, offset int) (*types.ListDataExportRsp, error) {
+	res := new(types.ListDataExportRsp)
+
+	if limit > loadDataExportsDefaultLimit || limit <= 0 {
+		limit = loadDataExportsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", dataExportTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT %s ORDER BY a.created_time DESC LIMIT ? OFFSET ?", dataExportColumns)
+	if err := n.db.Select(&res.Data, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadNodeExportRows load the columns of the nodes, the node type is joined from the registrations
+func (n *SQLDB) LoadNodeExportRows(ctx context.Context, columns []string) (*sqlx.Rows, error) {
+	query := fmt.Sprintf(`SELECT %s FROM (SELECT a.*, IFNULL(b.node_type,'') AS node_type FROM %s a LEFT JOIN %s b ON a.node_id=b.node_id) t
+			ORDER BY node_id`, strings.Join(columns, ", "), nodeInfoTable, nodeRegisterTable)
+	return n.db.QueryxContext(ctx, query)
+}
+
+// LoadAssetExportRows load the columns of the asset records
+func (n *SQLDB) LoadAssetExportRows(ctx context.Context, columns []string) (*sqlx.Rows, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY hash`, strings.Join(columns, ", "), assetRecordTable)
+	return n.db.QueryxContext(ctx, query)
+}
+
+// LoadPointsEpochExportRows load the columns of the points of the nodes in the epochs [start, end]
+func (n *SQLDB) LoadPointsEpochExportRows(ctx context.Context, columns []string, start, end time.Time) (*sqlx.Rows, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE epoch>=? AND epoch<=? ORDER BY epoch, node_id`, strings.Join(columns, ", "), pointsEpochTable)
+	return n.db.QueryxContext(ctx, query, start, end)
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveFeatureFlag saves the feature flag
+func (n *SQLDB) SaveFeatureFlag(flag *types.FeatureFlag) error {
+	query := fmt.Sprintf(`INSERT INTO %s (name, description, enabled, percentage, node_types, updated_time)
+				VALUES (:name, :description, :enabled, :percentage, :node_types, NOW())
+				ON DUPLICATE KEY UPDATE description=:description, enabled=:enabled, percentage=:percentage, node_types=:node_types, updated_time=NOW()`, featureFlagTable)
+	_, err := n.db.NamedExec(query, flag)
+	return err
+}
+
+// DeleteFeatureFlag removes the feature flag
+func (n *SQLDB) DeleteFeatureFlag(name string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE name=?", featureFlagTable)
+	_, err := n.db.Exec(query, name)
+	return err
+}
+
+// LoadFeatureFlags load all the feature flags
+func (n *SQLDB) LoadFeatureFlags() ([]*types.FeatureFlag, error) {
+	var out []*types.FeatureFlag
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY name", featureFlagTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveGatewayAsset gives the asset its own gateway hostname
+func (n *SQLDB) SaveGatewayAsset(hash, cid string) error {
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (hash, cid) VALUES (?, ?)`, gatewayAssetTable)
+	_, err := n.db.Exec(query, hash, cid)
+	return err
+}
+
+// DeleteGatewayAsset removes the gateway hostname of the asset, returns sql.ErrNoRows if the asset has none
+func (n *SQLDB) DeleteGatewayAsset(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, gatewayAssetTable)
+	result, err := n.db.Exec(query, hash)
+	if err != nil {
+		return err
+	}
+
+	if r, err := result.RowsAffected(); err == nil && r == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// LoadGatewayAssets loads the assets with their own gateway hostnames
+func (n *SQLDB) LoadGatewayAssets() ([]*types.GatewayAsset, error) {
+	var out []*types.GatewayAsset
+	query := fmt.Sprintf(`SELECT * FROM %s`, gatewayAssetTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SaveGatewayCertificate saves the certificate of the gateway zone, the previous certificate is replaced
+func (n *SQLDB) SaveGatewayCertificate(cert *types.GatewayCertificate) error {
+	query := fmt.Sprintf(`INSERT INTO %s (zone, certificate, private_key, not_after, updated_time) VALUES (?, ?, ?, ?, NOW())
+	        ON DUPLICATE KEY UPDATE certificate=VALUES(certificate), private_key=VALUES(private_key), not_after=VALUES(not_after), updated_time=NOW()`, gatewayCertTable)
+	_, err := n.db.Exec(query, cert.Zone, cert.Certificate, cert.PrivateKey, cert.NotAfter)
+	return err
+}
+
+// LoadGatewayCertificate loads the certificate of the gateway zone, returns sql.ErrNoRows if none is obtained yet
+func (n *SQLDB) LoadGatewayCertificate(zone string) (*types.GatewayCertificate, error) {
+	var out types.GatewayCertificate
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE zone=?`, gatewayCertTable)
+	if err := n.db.Get(&out, query, zone); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveACMEAccountKey saves the pem encoded key of the acme account of the directory if the directory has none,
+// the key saved first is kept when several schedulers register at once
+func (n *SQLDB) SaveACMEAccountKey(directory string, key []byte) error {
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (directory, private_key) VALUES (?, ?)`, acmeAccountTable)
+	_, err := n.db.Exec(query, directory, key)
+	return err
+}
+
+// LoadACMEAccountKey loads the pem encoded key of the acme account of the directory, returns sql.ErrNoRows if none
+func (n *SQLDB) LoadACMEAccountKey(directory string) ([]byte, error) {
+	var key []byte
+	query := fmt.Sprintf(`SELECT private_key FROM %s WHERE directory=?`, acmeAccountTable)
+	if err := n.db.Get(&key, query, directory); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
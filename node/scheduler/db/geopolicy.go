@@ -0,0 +1,184 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+)
+
+// SaveAssetGeoPolicy saves the geo policy of the asset, the previous policy of the asset is replaced
+func (n *SQLDB) SaveAssetGeoPolicy(policy *types.AssetGeoPolicy) error {
+	query := fmt.Sprintf(`INSERT INTO %s (hash, cid, rules, updated_time) VALUES (:hash, :cid, :rules, :updated_time)
+			ON DUPLICATE KEY UPDATE rules=VALUES(rules), updated_time=VALUES(updated_time)`, assetGeoPolicyTable)
+	_, err := n.db.NamedExec(query, policy)
+	return err
+}
+
+// DeleteAssetGeoPolicy deletes the geo policy of the asset and its violations
+func (n *SQLDB) DeleteAssetGeoPolicy(hash string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteAssetGeoPolicy Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetGeoPolicyTable)
+	if _, err = tx.Exec(query, hash); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, geoViolationTable)
+	if _, err = tx.Exec(query, hash); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadAssetGeoPolicy load the geo policy of the asset
+func (n *SQLDB) LoadAssetGeoPolicy(hash string) (*types.AssetGeoPolicy, error) {
+	var out types.AssetGeoPolicy
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=?", assetGeoPolicyTable)
+	if err := n.db.Get(&out, query, hash); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadAssetGeoPolicies load the geo policies, the latest updated first
+func (n *SQLDB) LoadAssetGeoPolicies(limit, offset int) (*types.ListAssetGeoPolicyRsp, error) {
+	res := new(types.ListAssetGeoPolicyRsp)
+
+	if limit > loadAssetGeoPoliciesDefaultLimit || limit <= 0 {
+		limit = loadAssetGeoPoliciesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", assetGeoPolicyTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY updated_time DESC LIMIT ? OFFSET ?", assetGeoPolicyTable)
+	if err := n.db.Select(&res.Data, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadServerAssetGeoPolicies load the geo policies of the assets of the scheduler in the state, ordered by hash after the hash
+func (n *SQLDB) LoadServerAssetGeoPolicies(serverID dtypes.ServerID, state, after string, limit int) ([]*types.AssetGeoPolicy, error) {
+	var out []*types.AssetGeoPolicy
+	query := fmt.Sprintf(`SELECT a.* FROM %s a JOIN %s b ON a.hash=b.hash WHERE b.state=? AND a.hash>? ORDER BY a.hash LIMIT ?`,
+		assetGeoPolicyTable, assetStateTable(serverID))
+	if err := n.db.Select(&out, query, state, after, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateAssetGeoViolations replaces the violations of the asset with the violations found now,
+// the violations found first and the violations resolved are written to the outbox
+func (n *SQLDB) UpdateAssetGeoViolations(hash, cid string, violations []*types.AssetGeoViolation) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("UpdateAssetGeoViolations Rollback err:%s", err.Error())
+		}
+	}()
+
+	var previous []*types.AssetGeoViolation
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=? FOR UPDATE", geoViolationTable)
+	if err = tx.Select(&previous, query, hash); err != nil {
+		return err
+	}
+
+	key := func(v *types.AssetGeoViolation) string { return fmt.Sprintf("%s/%s", v.Region, v.Kind) }
+
+	found := make(map[string]struct{}, len(violations))
+	for _, v := range violations {
+		found[key(v)] = struct{}{}
+	}
+
+	existing := make(map[string]struct{}, len(previous))
+	for _, v := range previous {
+		existing[key(v)] = struct{}{}
+		if _, ok := found[key(v)]; ok {
+			continue
+		}
+
+		query = fmt.Sprintf("DELETE FROM %s WHERE hash=? AND region=? AND kind=?", geoViolationTable)
+		if _, err = tx.Exec(query, hash, v.Region, v.Kind); err != nil {
+			return err
+		}
+
+		payload := &types.OutboxAssetGeoViolationPayload{Hash: hash, CID: cid, Region: v.Region, Kind: v.Kind, Required: v.Required, Resolved: true}
+		if err = saveOutboxEvent(tx, types.OutboxAssetGeoViolation, hash, payload); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range violations {
+		if _, ok := existing[key(v)]; ok {
+			query = fmt.Sprintf("UPDATE %s SET replicas=?, required=? WHERE hash=? AND region=? AND kind=?", geoViolationTable)
+			if _, err = tx.Exec(query, v.Replicas, v.Required, hash, v.Region, v.Kind); err != nil {
+				return err
+			}
+			continue
+		}
+
+		query = fmt.Sprintf(`INSERT INTO %s (hash, cid, region, kind, replicas, required, detected_time)
+				VALUES (:hash, :cid, :region, :kind, :replicas, :required, :detected_time)`, geoViolationTable)
+		if _, err = tx.NamedExec(query, v); err != nil {
+			return err
+		}
+
+		payload := &types.OutboxAssetGeoViolationPayload{Hash: hash, CID: cid, Region: v.Region, Kind: v.Kind, Replicas: v.Replicas, Required: v.Required}
+		if err = saveOutboxEvent(tx, types.OutboxAssetGeoViolation, hash, payload); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadAssetGeoViolations load the violations of the geo policies, of the asset if the hash is not empty, the latest first
+func (n *SQLDB) LoadAssetGeoViolations(hash string, limit, offset int) (*types.ListAssetGeoViolationRsp, error) {
+	res := new(types.ListAssetGeoViolationRsp)
+
+	if limit > loadAssetGeoViolationsDefaultLimit || limit <= 0 {
+		limit = loadAssetGeoViolationsDefaultLimit
+	}
+
+	where, args := "", []interface{}{}
+	if hash != "" {
+		where, args = "WHERE hash=?", append(args, hash)
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s %s", geoViolationTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s %s ORDER BY detected_time DESC LIMIT ? OFFSET ?", geoViolationTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveNodeTask queues the task in the inbox of the node
+func (n *SQLDB) SaveNodeTask(task *types.NodeTask) error {
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, kind, payload, created_time, expiration) VALUES (?, ?, ?, ?, ?)`, nodeTaskTable)
+	_, err := n.db.Exec(query, task.NodeID, task.Kind, task.Payload, task.CreatedTime, task.Expiration)
+	return err
+}
+
+// LoadNodeTasks loads the tasks of the node not expired at the time in the order they were queued
+func (n *SQLDB) LoadNodeTasks(nodeID string, t time.Time) ([]*types.NodeTask, error) {
+	var out []*types.NodeTask
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE node_id=? AND expiration>? ORDER BY id LIMIT ?`, nodeTaskTable)
+	if err := n.db.Select(&out, query, nodeID, t, loadNodeTasksDefaultLimit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadNodeIDsOfTasks loads the ids of the nodes with the tasks not expired at the time
+func (n *SQLDB) LoadNodeIDsOfTasks(t time.Time) ([]string, error) {
+	var out []string
+	query := fmt.Sprintf(`SELECT DISTINCT node_id FROM %s WHERE expiration>?`, nodeTaskTable)
+	if err := n.db.Select(&out, query, t); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeleteNodeTask deletes the task acknowledged by the node
+func (n *SQLDB) DeleteNodeTask(id int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, nodeTaskTable)
+	_, err := n.db.Exec(query, id)
+	return err
+}
+
+// UpdateNodeTaskAttempt counts a failed delivery of the task with its error
+func (n *SQLDB) UpdateNodeTaskAttempt(id int64, lastError string) error {
+	query := fmt.Sprintf(`UPDATE %s SET attempts=attempts+1, last_error=? WHERE id=?`, nodeTaskTable)
+	_, err := n.db.Exec(query, lastError, id)
+	return err
+}
+
+// DeleteExpiredNodeTasks deletes the tasks expired before the time
+func (n *SQLDB) DeleteExpiredNodeTasks(t time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE expiration<=?`, nodeTaskTable)
+	result, err := n.db.Exec(query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
+)
+
+// jobColumns the columns of the jobs, the dedup key is null for the jobs not deduplicated
+const jobColumns = `id, kind, IFNULL(dedup_key,'') AS dedup_key, payload, server_id, status, worker, attempts, max_attempts,
+		progress_done, progress_total, message, run_after, heartbeat_time, created_time, updated_time`
+
+// SaveJob saves the pending job, false if a job of its dedup key exists
+func (n *SQLDB) SaveJob(job *types.Job) (bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (id, kind, dedup_key, payload, server_id, status, max_attempts, run_after, heartbeat_time, created_time, updated_time)
+			VALUES (:id, :kind, NULLIF(:dedup_key,''), :payload, :server_id, :status, :max_attempts, :run_after, :heartbeat_time, :created_time, :updated_time)
+			ON DUPLICATE KEY UPDATE id=id`, jobQueueTable)
+	result, err := n.db.NamedExec(query, job)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// LoadRunnableJobIDs load the ids of the pending jobs of the kinds the scheduler can run at the time, the earliest first
+func (n *SQLDB) LoadRunnableJobIDs(kinds []string, serverID string, t time.Time, limit int) ([]string, error) {
+	sQuery := fmt.Sprintf(`SELECT id FROM %s WHERE status=? AND run_after<=? AND kind IN (?) AND (server_id='' OR server_id=?)
+			ORDER BY run_after LIMIT ?`, jobQueueTable)
+	query, args, err := sqlx.In(sQuery, types.JobPending, t, kinds, serverID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	if err := n.db.Select(&out, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ClaimJob sets the pending job running by the worker, false if it is claimed by another worker
+func (n *SQLDB) ClaimJob(id, worker string, t time.Time) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, worker=?, attempts=attempts+1, heartbeat_time=?, updated_time=? WHERE id=? AND status=?`, jobQueueTable)
+	result, err := n.db.Exec(query, types.JobRunning, worker, t, t, id, types.JobPending)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// UpdateJobHeartbeat saves the progress of the running job and returns the status of the job,
+// the job is canceled if the status is not running
+func (n *SQLDB) UpdateJobHeartbeat(id string, done, total int64, t time.Time) (types.JobStatus, error) {
+	query := fmt.Sprintf(`UPDATE %s SET progress_done=?, progress_total=?, heartbeat_time=? WHERE id=? AND status=?`, jobQueueTable)
+	if _, err := n.db.Exec(query, done, total, t, id, types.JobRunning); err != nil {
+		return "", err
+	}
+
+	var status types.JobStatus
+	query = fmt.Sprintf(`SELECT status FROM %s WHERE id=?`, jobQueueTable)
+	if err := n.db.Get(&status, query, id); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// FinishJobAttempt saves the result of the attempt of the running job, the job runs again after runAfter if the status is pending
+func (n *SQLDB) FinishJobAttempt(job *types.Job) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=:status, message=:message, run_after=:run_after, progress_done=:progress_done,
+			progress_total=:progress_total, updated_time=:updated_time WHERE id=:id AND status='running'`, jobQueueTable)
+	_, err := n.db.NamedExec(query, job)
+	return err
+}
+
+// CancelJob cancels the pending or running job
+func (n *SQLDB) CancelJob(id string, t time.Time) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, updated_time=? WHERE id=? AND status IN (?, ?)`, jobQueueTable)
+	result, err := n.db.Exec(query, types.JobCanceled, t, id, types.JobPending, types.JobRunning)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return xerrors.Errorf("job %s not found or finished", id)
+	}
+
+	return nil
+}
+
+// RequeueJobs sets the running jobs of the worker and the running jobs without heartbeat since staleBefore pending,
+// they are run again by the workers
+func (n *SQLDB) RequeueJobs(worker string, staleBefore, t time.Time) (int64, error) {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, message=?, run_after=?, updated_time=? WHERE status=? AND (worker=? OR heartbeat_time<?)`, jobQueueTable)
+	result, err := n.db.Exec(query, types.JobPending, "requeued after the worker stopped", t, t, types.JobRunning, worker, staleBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// LoadJob load the job
+func (n *SQLDB) LoadJob(id string) (*types.Job, error) {
+	var out types.Job
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id=?", jobColumns, jobQueueTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadJobs load the jobs of the kind and the status, all kinds or statuses if empty, the latest first
+func (n *SQLDB) LoadJobs(kind string, status types.JobStatus, limit, offset int) (*types.ListJobRsp, error) {
+	res := new(types.ListJobRsp)
+
+	if limit > loadJobsDefaultLimit || limit <= 0 {
+		limit = loadJobsDefaultLimit
+	}
+
+	where := "WHERE 1=1"
+	var args []interface{}
+	if kind != "" {
+		where += " AND kind=?"
+		args = append(args, kind)
+	}
+	if status != "" {
+		where += " AND status=?"
+		args = append(args, status)
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s %s", jobQueueTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT %s FROM %s %s ORDER BY created_time DESC LIMIT ? OFFSET ?", jobColumns, jobQueueTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// IsNodeStatsSnapshotted checks if the stats of the nodes have been snapshotted at the date
+func (n *SQLDB) IsNodeStatsSnapshotted(date time.Time) (bool, error) {
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE date=? LIMIT 1", nodeStatsDailyTable)
+	if err := n.db.Get(&count, query, date); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// SnapshotNodeStats snapshots the cumulative points and online minutes of the nodes at the date,
+// the snapshots before the expiration date are removed
+func (n *SQLDB) SnapshotNodeStats(date, expiration time.Time) error {
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (node_id, date, points, online_duration) SELECT node_id, ?, profit, online_duration FROM %s`,
+		nodeStatsDailyTable, nodeInfoTable)
+	if _, err := n.db.Exec(query, date); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE date<?", nodeStatsDailyTable)
+	_, err := n.db.Exec(query, expiration)
+	return err
+}
+
+// LoadNodeStats load the current cumulative points and online minutes of the nodes with their types
+func (n *SQLDB) LoadNodeStats() ([]*types.NodeStatsDaily, error) {
+	var out []*types.NodeStatsDaily
+	query := fmt.Sprintf(`SELECT a.node_id, a.profit AS points, a.online_duration, IFNULL(b.node_type,0) AS node_type FROM %s a LEFT JOIN %s b ON a.node_id = b.node_id`,
+		nodeInfoTable, nodeRegisterTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadNodeStatsDaily load the snapshots of the nodes at the date, keyed by node id
+func (n *SQLDB) LoadNodeStatsDaily(date time.Time) (map[string]*types.NodeStatsDaily, error) {
+	var list []*types.NodeStatsDaily
+	query := fmt.Sprintf("SELECT * FROM %s WHERE date=?", nodeStatsDailyTable)
+	if err := n.db.Select(&list, query, date); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*types.NodeStatsDaily, len(list))
+	for _, stats := range list {
+		out[stats.NodeID] = stats
+	}
+
+	return out, nil
+}
+
+// SumNodeUploadTraffic sums the bytes uploaded by the nodes since the date, keyed by node id
+func (n *SQLDB) SumNodeUploadTraffic(since time.Time) (map[string]int64, error) {
+	var list []*types.NodeTrafficDaily
+	query := fmt.Sprintf("SELECT node_id, SUM(upload_bytes) AS upload_bytes FROM %s WHERE date>=? GROUP BY node_id", nodeTrafficDailyTable)
+	if err := n.db.Select(&list, query, since); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]int64, len(list))
+	for _, traffic := range list {
+		out[traffic.NodeID] = traffic.UploadBytes
+	}
+
+	return out, nil
+}
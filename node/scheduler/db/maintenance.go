@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SaveMaintenanceWindow saves the maintenance window of the node and returns its id,
+// the window can not overlap the other windows of the node
+func (n *SQLDB) SaveMaintenanceWindow(w *types.MaintenanceWindow) (int64, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveMaintenanceWindow Rollback err:%s", err.Error())
+		}
+	}()
+
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=? AND start_time<? AND end_time>? FOR UPDATE", maintenanceTable)
+	if err = tx.Get(&count, query, w.NodeID, w.EndTime, w.StartTime); err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		return 0, xerrors.Errorf("the window overlaps the other maintenance windows of node %s", w.NodeID)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, start_time, end_time, reason, created_time)
+			VALUES (:node_id, :start_time, :end_time, :reason, :created_time)`, maintenanceTable)
+	result, err := tx.NamedExec(query, w)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// EndMaintenanceWindow ends the maintenance window at the time, a window not started yet is left empty
+func (n *SQLDB) EndMaintenanceWindow(id int64, t time.Time) error {
+	query := fmt.Sprintf("UPDATE %s SET end_time=GREATEST(start_time, ?) WHERE id=? AND end_time>?", maintenanceTable)
+	result, err := n.db.Exec(query, t, id, t)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return xerrors.Errorf("maintenance window %d not found or ended", id)
+	}
+
+	return nil
+}
+
+// LoadMaintenanceWindow load the maintenance window
+func (n *SQLDB) LoadMaintenanceWindow(id int64) (*types.MaintenanceWindow, error) {
+	var out types.MaintenanceWindow
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?", maintenanceTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadUnendedMaintenanceWindows load the maintenance windows of all nodes not ended at the time
+func (n *SQLDB) LoadUnendedMaintenanceWindows(t time.Time) ([]*types.MaintenanceWindow, error) {
+	var out []*types.MaintenanceWindow
+	query := fmt.Sprintf("SELECT * FROM %s WHERE end_time>? AND end_time>start_time", maintenanceTable)
+	if err := n.db.Select(&out, query, t); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadNodeMaintenanceWindows load the maintenance windows of the node, the latest first
+func (n *SQLDB) LoadNodeMaintenanceWindows(nodeID string, limit, offset int) (*types.ListMaintenanceWindowRsp, error) {
+	res := new(types.ListMaintenanceWindowRsp)
+
+	if limit > loadMaintenanceWindowsDefaultLimit || limit <= 0 {
+		limit = loadMaintenanceWindowsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", maintenanceTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY start_time DESC LIMIT ? OFFSET ?", maintenanceTable)
+	if err := n.db.Select(&res.Data, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadMaintenanceMinutes load the minutes of the maintenance windows of the node in [start, end)
+func (n *SQLDB) LoadMaintenanceMinutes(nodeID string, start, end time.Time) (int, error) {
+	var minutes int
+	query := fmt.Sprintf(`SELECT IFNULL(SUM(TIMESTAMPDIFF(MINUTE, GREATEST(start_time, ?), LEAST(end_time, ?))),0) FROM %s
+			WHERE node_id=? AND start_time<? AND end_time>?`, maintenanceTable)
+	if err := n.db.Get(&minutes, query, start, end, nodeID, end, start); err != nil {
+		return 0, err
+	}
+
+	return minutes, nil
+}
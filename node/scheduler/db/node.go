@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/chaos"
 	"github.com/jmoiron/sqlx"
 	"golang.org/x/xerrors"
 )
@@ -23,6 +25,10 @@ func (n *SQLDB) UpdatePortMapping(nodeID, port string) error {
 
 // SaveValidationResultInfos inserts validation result information.
 func (n *SQLDB) SaveValidationResultInfos(infos []*types.ValidationResultInfo) error {
+	if err := chaos.Fail(chaos.DBWrite); err != nil {
+		return err
+	}
+
 	tx, err := n.db.Beginx()
 	if err != nil {
 		return err
@@ -93,12 +99,27 @@ func (n *SQLDB) UpdateValidationResultInfo(info *types.ValidationResultInfo) err
 		return err
 	}
 
-	iQuery = fmt.Sprintf(`UPDATE %s SET upload_traffic=upload_traffic+?,profit=profit+? WHERE node_id=?`, nodeInfoTable)
+	iQuery = fmt.Sprintf(`UPDATE %s SET upload_traffic=upload_traffic+?,profit=profit+%s WHERE node_id=?`, nodeInfoTable, pointsParam)
 	_, err = tx.Exec(iQuery, bandwidth, info.Profit, info.NodeID)
 	if err != nil {
 		return err
 	}
 
+	payload := &types.OutboxValidationPayload{
+		RoundID:     info.RoundID,
+		NodeID:      info.NodeID,
+		ValidatorID: info.ValidatorID,
+		Status:      info.Status,
+		BlockNumber: info.BlockNumber,
+		Duration:    info.Duration,
+		Bandwidth:   info.Bandwidth,
+		Profit:      info.Profit,
+	}
+	err = saveOutboxEvent(tx, types.OutboxValidationResult, info.NodeID, payload)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -118,9 +139,31 @@ func (n *SQLDB) UpdateNodeUploadTraffic(nodeID string, incSize int64) error {
 
 // UpdateValidationResultsTimeout sets the validation results' status as timeout.
 func (n *SQLDB) UpdateValidationResultStatus(roundID, nodeID string, status types.ValidationStatus) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("UpdateValidationResultStatus Rollback err:%s", err.Error())
+		}
+	}()
+
 	query := fmt.Sprintf(`UPDATE %s SET status=?, end_time=NOW() WHERE round_id=? AND node_id=?`, validationResultTable)
-	_, err := n.db.Exec(query, status, roundID, nodeID)
-	return err
+	_, err = tx.Exec(query, status, roundID, nodeID)
+	if err != nil {
+		return err
+	}
+
+	payload := &types.OutboxValidationPayload{RoundID: roundID, NodeID: nodeID, Status: status}
+	err = saveOutboxEvent(tx, types.OutboxValidationResult, nodeID, payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // LoadCreateValidationResultInfos load validation results.
@@ -136,6 +179,45 @@ func (n *SQLDB) LoadCreateValidationResultInfos() ([]*types.ValidationResultInfo
 	return infos, nil
 }
 
+// LoadActiveValidationsOfNode loads the validations not finished the node is validated or validates in
+func (n *SQLDB) LoadActiveValidationsOfNode(nodeID string) ([]*types.ValidationResultInfo, error) {
+	var infos []*types.ValidationResultInfo
+	query := fmt.Sprintf("SELECT * FROM %s WHERE status=? AND (node_id=? OR validator_id=?) ORDER BY start_time DESC LIMIT ?", validationResultTable)
+
+	err := n.db.Select(&infos, query, types.ValidationStatusCreate, nodeID, nodeID, loadValidationResultsDefaultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// LoadValidationFailureCounts load the validations failed by each node since the time
+func (n *SQLDB) LoadValidationFailureCounts(since time.Time) (map[string]int64, error) {
+	var rows []struct {
+		NodeID string `db:"node_id"`
+		Count  int64  `db:"count"`
+	}
+
+	sQuery := fmt.Sprintf(`SELECT node_id, COUNT(*) AS count FROM %s WHERE start_time>=? AND status IN (?) GROUP BY node_id`, validationResultTable)
+	query, args, err := sqlx.In(sQuery, since, nodeValidationFailures)
+	if err != nil {
+		return nil, err
+	}
+
+	query = n.db.Rebind(query)
+	if err := n.db.Select(&rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		out[row.NodeID] = row.Count
+	}
+
+	return out, nil
+}
+
 // LoadValidationResultInfos load validation results.
 func (n *SQLDB) LoadValidationResultInfos(nodeID string, limit, offset int) (*types.ListValidationResultRsp, error) {
 	res := new(types.ListValidationResultRsp)
@@ -262,6 +344,10 @@ func (n *SQLDB) UpdateValidatorInfo(serverID dtypes.ServerID, nodeID string) err
 
 // SaveNodeInfo Insert or update node info
 func (n *SQLDB) SaveNodeInfo(info *types.NodeInfo) error {
+	if err := chaos.Fail(chaos.DBWrite); err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(
 		`INSERT INTO %s (node_id, mac_location, cpu_cores, memory, node_name, cpu_info, available_disk_space, titan_disk_usage,
 			    disk_type, io_system, system_version, nat_type, disk_space, bandwidth_up, bandwidth_down, scheduler_sid) 
@@ -270,12 +356,71 @@ func (n *SQLDB) SaveNodeInfo(info *types.NodeInfo) error {
 				ON DUPLICATE KEY UPDATE node_id=:node_id, scheduler_sid=:scheduler_sid, system_version=:system_version, cpu_cores=:cpu_cores, titan_disk_usage=:titan_disk_usage,
 				memory=:memory, node_name=:node_name, disk_space=:disk_space, cpu_info=:cpu_info, available_disk_space=:available_disk_space, available_disk_space=:available_disk_space `, nodeInfoTable)
 
-	_, err := n.db.NamedExec(query, info)
-	return err
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveNodeInfo Rollback err:%s", err.Error())
+		}
+	}()
+
+	_, err = tx.NamedExec(query, info)
+	if err != nil {
+		return err
+	}
+
+	event := &types.OutboxNodePayload{NodeID: info.NodeID, NodeType: info.Type, IP: info.ExternalIP, Time: info.LastSeen}
+	err = saveOutboxEvent(tx, types.OutboxNodeOnline, info.NodeID, event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveNodeOffline updates the last seen time of the node gone offline
+func (n *SQLDB) SaveNodeOffline(nodeID string, lastSeen time.Time) error {
+	if err := chaos.Fail(chaos.DBWrite); err != nil {
+		return err
+	}
+
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveNodeOffline Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`UPDATE %s SET last_seen=? WHERE node_id=?`, nodeInfoTable)
+	_, err = tx.Exec(query, lastSeen, nodeID)
+	if err != nil {
+		return err
+	}
+
+	event := &types.OutboxNodePayload{NodeID: nodeID, Time: lastSeen}
+	err = saveOutboxEvent(tx, types.OutboxNodeOffline, nodeID, event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // UpdateOnlineDuration update node online time , last time , disk usage
 func (n *SQLDB) UpdateOnlineDuration(infos []*types.NodeSnapshot) error {
+	if err := chaos.Fail(chaos.DBWrite); err != nil {
+		return err
+	}
+
 	tx, err := n.db.Beginx()
 	if err != nil {
 		return err
@@ -289,7 +434,7 @@ func (n *SQLDB) UpdateOnlineDuration(infos []*types.NodeSnapshot) error {
 	}()
 
 	for _, info := range infos {
-		query := fmt.Sprintf(`UPDATE %s SET last_seen=?,online_duration=?,disk_usage=?,bandwidth_up=?,bandwidth_down=?,profit=profit+?,titan_disk_usage=?,available_disk_space=? WHERE node_id=?`, nodeInfoTable)
+		query := fmt.Sprintf(`UPDATE %s SET last_seen=?,online_duration=?,disk_usage=?,bandwidth_up=?,bandwidth_down=?,profit=profit+%s,titan_disk_usage=?,available_disk_space=? WHERE node_id=?`, nodeInfoTable, pointsParam)
 		tx.Exec(query, info.LastSeen, info.OnlineDuration, info.DiskUsage, info.BandwidthUp, info.BandwidthDown, info.Profit, info.TitanDiskUsage, info.AvailableDiskSpace, info.NodeID)
 	}
 
@@ -299,13 +444,36 @@ func (n *SQLDB) UpdateOnlineDuration(infos []*types.NodeSnapshot) error {
 
 // SaveNodeRegisterInfos Insert Node register info
 func (n *SQLDB) SaveNodeRegisterInfos(details []*types.ActivationDetail) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveNodeRegisterInfos Rollback err:%s", err.Error())
+		}
+	}()
+
 	query := fmt.Sprintf(
-		`INSERT INTO %s (node_id, created_time, node_type, activation_key, ip)
-				VALUES (:node_id, NOW(), :node_type, :activation_key, :ip)`, nodeRegisterTable)
+		`INSERT INTO %s (node_id, created_time, node_type, activation_key, ip, public_key)
+				VALUES (:node_id, NOW(), :node_type, :activation_key, :ip, :public_key)`, nodeRegisterTable)
 
-	_, err := n.db.NamedExec(query, details)
+	_, err = tx.NamedExec(query, details)
+	if err != nil {
+		return err
+	}
 
-	return err
+	for _, detail := range details {
+		payload := &types.OutboxNodePayload{NodeID: detail.NodeID, NodeType: detail.NodeType, IP: detail.IP}
+		err = saveOutboxEvent(tx, types.OutboxNodeRegistered, detail.NodeID, payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // SaveNodePublicKey update node public key
@@ -353,6 +521,31 @@ func (n *SQLDB) LoadNodePublicKey(nodeID string) (string, error) {
 	return pKey, nil
 }
 
+// LoadNodeRegisterInfo load register info of node.
+func (n *SQLDB) LoadNodeRegisterInfo(nodeID string) (*types.ActivationDetail, error) {
+	detail := &types.ActivationDetail{}
+
+	query := fmt.Sprintf(`SELECT node_id, node_type, activation_key, ip, public_key FROM %s WHERE node_id=?`, nodeRegisterTable)
+	if err := n.db.Get(detail, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+// LoadNodeIDOfPublicKey load the id of the node the public key is bound to, the earliest one if
+// the key was registered more than once before the node ids were derived from the keys.
+func (n *SQLDB) LoadNodeIDOfPublicKey(pKey string) (string, error) {
+	var nodeID string
+
+	query := fmt.Sprintf(`SELECT node_id FROM %s WHERE public_key=? ORDER BY created_time LIMIT 1`, nodeRegisterTable)
+	if err := n.db.Get(&nodeID, query, pKey); err != nil {
+		return nodeID, err
+	}
+
+	return nodeID, nil
+}
+
 // LoadNodeType load type of node.
 func (n *SQLDB) LoadNodeType(nodeID string) (types.NodeType, error) {
 	var nodeType types.NodeType
@@ -384,7 +577,7 @@ func (n *SQLDB) NodeExists(nodeID string, nodeType types.NodeType) error {
 // TodayRegisterCount get the number of registrations for this ip today
 func (n *SQLDB) RegisterCount(ip string) (int, error) {
 	var count int
-	cQuery := fmt.Sprintf(`SELECT count(*) FROM %s WHERE ip=?`, nodeRegisterTable)
+	cQuery := fmt.Sprintf(`SELECT count(*) FROM %s WHERE ip=? AND node_id NOT IN (SELECT node_id FROM %s)`, nodeRegisterTable, sandboxNodeTable)
 	err := n.db.Get(&count, cQuery, ip)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -413,6 +606,77 @@ func (n *SQLDB) LoadNodeInfos(limit, offset int) (*sqlx.Rows, int64, error) {
 	return rows, total, err
 }
 
+// NodeSortColumns the node_info columns that node listing can be sorted by
+var NodeSortColumns = map[string]struct{}{
+	"node_id":          {},
+	"profit":           {},
+	"online_duration":  {},
+	"last_seen":        {},
+	"first_login_time": {},
+	"disk_space":       {},
+}
+
+// LoadNodeInfosAfter load nodes information ordered by the sort column and node id,
+// starting after the given sort value and node id; an empty afterID starts from the beginning.
+func (n *SQLDB) LoadNodeInfosAfter(sortBy string, desc bool, nodeType types.NodeType, afterValue interface{}, afterID string, limit int) ([]*types.NodeInfo, error) {
+	if _, ok := NodeSortColumns[sortBy]; !ok {
+		return nil, xerrors.Errorf("invalid sort column %s", sortBy)
+	}
+
+	if limit > loadNodeInfosDefaultLimit || limit <= 0 {
+		limit = loadNodeInfosDefaultLimit
+	}
+
+	order, cmp := "asc", ">"
+	if desc {
+		order, cmp = "desc", "<"
+	}
+
+	var conditions []string
+	var args []interface{}
+
+	if nodeType != types.NodeUnknown {
+		conditions = append(conditions, "b.node_type=?")
+		args = append(args, nodeType)
+	}
+
+	if afterID != "" {
+		if sortBy == "node_id" {
+			conditions = append(conditions, fmt.Sprintf("a.node_id %s ?", cmp))
+			args = append(args, afterID)
+		} else {
+			// the points are bound as decimal strings and compared as decimals
+			param := "?"
+			if sortBy == "profit" {
+				param = pointsParam
+			}
+			conditions = append(conditions, fmt.Sprintf("(a.%s %s %s OR (a.%s = %s AND a.node_id %s ?))", sortBy, cmp, param, sortBy, param, cmp))
+			args = append(args, afterValue, afterValue, afterID)
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := fmt.Sprintf("a.node_id %s", order)
+	if sortBy != "node_id" {
+		orderBy = fmt.Sprintf("a.%s %s, a.node_id %s", sortBy, order, order)
+	}
+
+	query := fmt.Sprintf(`SELECT a.*,IFNULL(b.node_type,0) as type FROM %s a LEFT JOIN %s b ON a.node_id = b.node_id %s order by %s LIMIT ?`,
+		nodeInfoTable, nodeRegisterTable, where, orderBy)
+	args = append(args, limit)
+
+	var out []*types.NodeInfo
+	if err := n.db.Select(&out, query, args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
 // LoadNodeInfo load node information.
 func (n *SQLDB) LoadNodeInfo(nodeID string) (*types.NodeInfo, error) {
 	query := fmt.Sprintf(`SELECT * FROM %s WHERE node_id=?`, nodeInfoTable)
@@ -426,6 +690,25 @@ func (n *SQLDB) LoadNodeInfo(nodeID string) (*types.NodeInfo, error) {
 	return &out, nil
 }
 
+// LoadPointsLeaderboard load the nodes with the most points
+func (n *SQLDB) LoadPointsLeaderboard(limit int) ([]*types.NodePointsRank, error) {
+	if limit > loadLeaderboardDefaultLimit || limit <= 0 {
+		limit = loadLeaderboardDefaultLimit
+	}
+
+	var out []*types.NodePointsRank
+	query := fmt.Sprintf(`SELECT node_id, profit, online_duration FROM %s order by profit desc LIMIT ?`, nodeInfoTable)
+	if err := n.db.Select(&out, query, limit); err != nil {
+		return nil, err
+	}
+
+	for i, rank := range out {
+		rank.Rank = i + 1
+	}
+
+	return out, nil
+}
+
 // LoadNodeLastSeenTime loads the last seen time of a node
 func (n *SQLDB) LoadNodeLastSeenTime(nodeID string) (time.Time, error) {
 	var t time.Time
@@ -544,6 +827,10 @@ func (n *SQLDB) DeleteBucket(bucketID string) error {
 
 // SaveWorkloadRecord save workload record
 func (n *SQLDB) SaveWorkloadRecord(records []*types.WorkloadRecord) error {
+	if err := chaos.Fail(chaos.DBWrite); err != nil {
+		return err
+	}
+
 	tx, err := n.db.Beginx()
 	if err != nil {
 		return err
@@ -696,7 +983,7 @@ func (n *SQLDB) UpdateFileSavedStatus(ids []int) error {
 }
 
 // UpdateNodeInfosByValidationResult Update the info value of the node, and set the calculated flag to the validation record
-func (n *SQLDB) UpdateNodeInfosByValidationResult(sIDs []int, nodeProfits map[string]float64) error {
+func (n *SQLDB) UpdateNodeInfosByValidationResult(sIDs []int, nodeProfits map[string]types.Points) error {
 	tx, err := n.db.Beginx()
 	if err != nil {
 		return err
@@ -803,6 +1090,11 @@ func (n *SQLDB) SaveRetrieveEventInfo(cInfo *types.RetrieveEvent) error {
 		return err
 	}
 
+	err = incrCounter(tx, trafficServedCounter, cInfo.Size)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -850,9 +1142,30 @@ func (n *SQLDB) LoadRetrieveEventRecords(nodeID string, limit, offset int) (*typ
 
 // SaveDeactivateNode save deactivate node time
 func (n *SQLDB) SaveDeactivateNode(nodeID string, time int64) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveDeactivateNode Rollback err:%s", err.Error())
+		}
+	}()
+
 	query := fmt.Sprintf(`UPDATE %s SET deactivate_time=? WHERE node_id=?`, nodeInfoTable)
-	_, err := n.db.Exec(query, time, nodeID)
-	return err
+	_, err = tx.Exec(query, time, nodeID)
+	if err != nil {
+		return err
+	}
+
+	err = saveOutboxEvent(tx, types.OutboxNodeDeactivation, nodeID, &types.OutboxNodePayload{NodeID: nodeID, DeactivateTime: time})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // LoadDeactivateNodeTime Get node deactivate time
@@ -868,13 +1181,36 @@ func (n *SQLDB) LoadDeactivateNodeTime(nodeID string) (int64, error) {
 	return time, nil
 }
 
-// LoadDeactivateNodes load all deactivate node.
-func (n *SQLDB) LoadDeactivateNodes() ([]string, error) {
+// LoadDeactivatedNodes load the nodes whose deactivation is in effect at the time, unit: second
+func (n *SQLDB) LoadDeactivatedNodes(now int64) ([]string, error) {
 	var out []string
-	query := fmt.Sprintf(`SELECT node_id FROM %s WHERE deactivate_time>0`, nodeInfoTable)
-	if err := n.db.Select(&out, query); err != nil {
+	query := fmt.Sprintf(`SELECT node_id FROM %s WHERE deactivate_time>0 AND deactivate_time<=?`, nodeInfoTable)
+	if err := n.db.Select(&out, query, now); err != nil {
 		return nil, err
 	}
 
 	return out, nil
 }
+
+// SaveHardwareProof saves the latest hardware challenge proof of the node
+func (n *SQLDB) SaveHardwareProof(proof *types.HardwareProof) error {
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, passed, disk_size, disk_duration, memory_size, memory_bandwidth, proof_time) 
+				VALUES (:node_id, :passed, :disk_size, :disk_duration, :memory_size, :memory_bandwidth, :proof_time) 
+				ON DUPLICATE KEY UPDATE passed=:passed, disk_size=:disk_size, disk_duration=:disk_duration, 
+				memory_size=:memory_size, memory_bandwidth=:memory_bandwidth, proof_time=:proof_time`, hardwareProofTable)
+
+	_, err := n.db.NamedExec(query, proof)
+	return err
+}
+
+// LoadHardwareProof load the latest hardware challenge proof of the node
+func (n *SQLDB) LoadHardwareProof(nodeID string) (*types.HardwareProof, error) {
+	var proof types.HardwareProof
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=?", hardwareProofTable)
+	err := n.db.Get(&proof, query, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proof, nil
+}
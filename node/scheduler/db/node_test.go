@@ -0,0 +1,57 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/harness"
+)
+
+func TestLoadNodeInfosAfterProfit(t *testing.T) {
+	sdb, closeDB, err := harness.NewDB("test-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+
+	// the profits differ beyond the precision of a float
+	profits := map[string]string{"e_1": "1000000.000000000001", "e_2": "1000000.000000000002", "e_3": "1000000.000000000003"}
+
+	var snapshots []*types.NodeSnapshot
+	for nodeID, s := range profits {
+		if err := sdb.SaveNodeInfo(&types.NodeInfo{NodeDynamicInfo: types.NodeDynamicInfo{NodeID: nodeID}}); err != nil {
+			t.Fatal(err)
+		}
+
+		profit, err := types.ParsePoints(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snapshots = append(snapshots, &types.NodeSnapshot{NodeID: nodeID, LastSeen: time.Now(), Profit: profit})
+	}
+
+	if err := sdb.UpdateOnlineDuration(snapshots); err != nil {
+		t.Fatal(err)
+	}
+
+	var afterID string
+	var afterValue interface{}
+	var order []string
+	for i := 0; i < len(profits)+1; i++ {
+		infos, err := sdb.LoadNodeInfosAfter("profit", false, types.NodeUnknown, afterValue, afterID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(infos) == 0 {
+			break
+		}
+
+		afterID, afterValue = infos[0].NodeID, infos[0].Profit
+		order = append(order, afterID)
+	}
+
+	if len(order) != 3 || order[0] != "e_1" || order[1] != "e_2" || order[2] != "e_3" {
+		t.Errorf("expect the nodes paged by exact profits, got %v", order)
+	}
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveNodeConfig saves the config of the scope, the defaults of a node type or the overrides of a node
+func (n *SQLDB) SaveNodeConfig(scope string, cfg *types.NodeConfig) error {
+	buf, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (scope, config, updated_time) VALUES (?, ?, NOW())
+				ON DUPLICATE KEY UPDATE config=?, updated_time=NOW()`, nodeConfigTable)
+	_, err = n.db.Exec(query, scope, string(buf), string(buf))
+	return err
+}
+
+// DeleteNodeConfig removes the config of the scope
+func (n *SQLDB) DeleteNodeConfig(scope string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE scope=?", nodeConfigTable)
+	_, err := n.db.Exec(query, scope)
+	return err
+}
+
+// LoadNodeConfig load the config of the scope, nil if the scope has no config
+func (n *SQLDB) LoadNodeConfig(scope string) (*types.NodeConfig, error) {
+	var buf string
+	query := fmt.Sprintf("SELECT config FROM %s WHERE scope=?", nodeConfigTable)
+	if err := n.db.Get(&buf, query, scope); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cfg := &types.NodeConfig{}
+	return cfg, json.Unmarshal([]byte(buf), cfg)
+}
+
+// LoadNodeConfigs load the configs of all the scopes
+func (n *SQLDB) LoadNodeConfigs() (map[string]*types.NodeConfig, error) {
+	query := fmt.Sprintf("SELECT scope, config FROM %s", nodeConfigTable)
+	rows, err := n.db.Queryx(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]*types.NodeConfig)
+	for rows.Next() {
+		var scope, buf string
+		if err := rows.Scan(&scope, &buf); err != nil {
+			return nil, err
+		}
+
+		cfg := &types.NodeConfig{}
+		if err := json.Unmarshal([]byte(buf), cfg); err != nil {
+			return nil, err
+		}
+		out[scope] = cfg
+	}
+
+	return out, rows.Err()
+}
+
+// SaveNodeConfigAck saves the config revision acknowledged by the node
+func (n *SQLDB) SaveNodeConfigAck(nodeID string, ack *types.NodeConfigAck) error {
+	errs := strings.Join(ack.Errors, "; ")
+	if len(errs) > 1024 {
+		errs = errs[:1024]
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, revision, errors, ack_time) VALUES (?, ?, ?, NOW())
+				ON DUPLICATE KEY UPDATE revision=?, errors=?, ack_time=NOW()`, nodeConfigAckTable)
+	_, err := n.db.Exec(query, nodeID, ack.Revision, errs, ack.Revision, errs)
+	return err
+}
+
+// LoadNodeConfigAck load the config revision acknowledged by the node into the status
+func (n *SQLDB) LoadNodeConfigAck(nodeID string, status *types.NodeConfigStatus) error {
+	var out struct {
+		Revision string    `db:"revision"`
+		Errors   string    `db:"errors"`
+		AckTime  time.Time `db:"ack_time"`
+	}
+
+	query := fmt.Sprintf("SELECT revision, errors, ack_time FROM %s WHERE node_id=?", nodeConfigAckTable)
+	if err := n.db.Get(&out, query, nodeID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	status.AppliedRevision = out.Revision
+	status.AppliedTime = out.AckTime
+	status.Err = out.Errors
+	return nil
+}
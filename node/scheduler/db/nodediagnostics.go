@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// the columns of the diagnostics bundle without its data
+const nodeDiagnosticsColumns = "id, node_id, status, location, size, message, created_time, finished_time"
+
+// SaveNodeDiagnostics saves the requested diagnostics bundle
+func (n *SQLDB) SaveNodeDiagnostics(diag *types.NodeDiagnostics) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, node_id, status, location) VALUES (:id, :node_id, :status, :location)`, nodeDiagnosticsTable)
+	_, err := n.db.NamedExec(query, diag)
+	return err
+}
+
+// UpdateNodeDiagnostics saves the result of the diagnostics bundle with the data kept by the scheduler
+func (n *SQLDB) UpdateNodeDiagnostics(diag *types.NodeDiagnostics, data []byte) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, size=?, data=?, message=?, finished_time=NOW() WHERE id=?`, nodeDiagnosticsTable)
+	_, err := n.db.Exec(query, diag.Status, diag.Size, data, diag.Message, diag.ID)
+	return err
+}
+
+// LoadNodeDiagnostics loads the diagnostics bundle without its data, returns sql.ErrNoRows if it does not exist
+func (n *SQLDB) LoadNodeDiagnostics(id string) (*types.NodeDiagnostics, error) {
+	var out types.NodeDiagnostics
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id=?`, nodeDiagnosticsColumns, nodeDiagnosticsTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LoadNodeDiagnosticsData loads the data of the diagnostics bundle, returns sql.ErrNoRows if it does not exist
+func (n *SQLDB) LoadNodeDiagnosticsData(id string) ([]byte, error) {
+	var data []byte
+	query := fmt.Sprintf(`SELECT data FROM %s WHERE id=?`, nodeDiagnosticsTable)
+	err := n.db.Get(&data, query, id)
+	return data, err
+}
+
+// CountNodeDiagnosticsSince counts the diagnostics bundles of the node requested since the time
+func (n *SQLDB) CountNodeDiagnosticsSince(nodeID string, since time.Time) (int, error) {
+	var count int
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE node_id=? AND created_time>?`, nodeDiagnosticsTable)
+	err := n.db.Get(&count, query, nodeID, since)
+	return count, err
+}
+
+// LoadNodeDiagnosticsList loads the diagnostics bundles of the node without their data, the latest first
+func (n *SQLDB) LoadNodeDiagnosticsList(nodeID string, limit, offset int) (*types.ListNodeDiagnosticsRsp, error) {
+	res := new(types.ListNodeDiagnosticsRsp)
+
+	if limit > loadNodeDiagnosticsDefaultLimit || limit <= 0 {
+		limit = loadNodeDiagnosticsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", nodeDiagnosticsTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT %s FROM %s WHERE node_id=? ORDER BY created_time DESC LIMIT ? OFFSET ?", nodeDiagnosticsColumns, nodeDiagnosticsTable)
+	if err := n.db.Select(&res.Diagnostics, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteNodeDiagnosticsBefore deletes the diagnostics bundles requested before the time
+func (n *SQLDB) DeleteNodeDiagnosticsBefore(before time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, nodeDiagnosticsTable)
+	_, err := n.db.Exec(query, before)
+	return err
+}
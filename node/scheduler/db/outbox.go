@@ -0,0 +1,94 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// saveOutboxEvent writes an event to the outbox in the transaction of its state change
+func saveOutboxEvent(tx sqlx.Execer, topic types.OutboxTopic, subject string, payload interface{}) error {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (topic, subject, payload) VALUES (?, ?, ?)`, outboxTable)
+	_, err = tx.Exec(query, topic, subject, buf)
+	return err
+}
+
+// LoadPendingOutboxEvents load the undelivered events in the order they were written
+func (n *SQLDB) LoadPendingOutboxEvents(limit int) ([]*types.OutboxEvent, error) {
+	var out []*types.OutboxEvent
+	query := fmt.Sprintf(`SELECT id, topic, subject, payload, attempts, created_time FROM %s WHERE dispatched=false ORDER BY id LIMIT ?`, outboxTable)
+	if err := n.db.Select(&out, query, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadOutboxEventsAfter load the events of the topics written after the event, in the order they were written
+func (n *SQLDB) LoadOutboxEventsAfter(id int64, topics []types.OutboxTopic, limit int) ([]*types.OutboxEvent, error) {
+	query, args, err := sqlx.In(fmt.Sprintf(`SELECT id, topic, subject, payload, attempts, created_time FROM %s
+				WHERE id>? AND topic IN (?) ORDER BY id LIMIT ?`, outboxTable), id, topics, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.OutboxEvent
+	if err = n.db.Select(&out, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// CountPendingOutboxEvents returns the number of the undelivered events
+func (n *SQLDB) CountPendingOutboxEvents() (int, error) {
+	var count int
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE dispatched=false`, outboxTable)
+	err := n.db.Get(&count, query)
+	return count, err
+}
+
+// MarkOutboxEventsDispatched marks the events as delivered to all the consumers
+func (n *SQLDB) MarkOutboxEventsDispatched(ids []int64) error {
+	query, args, err := sqlx.In(fmt.Sprintf(`UPDATE %s SET dispatched=true, attempts=attempts+1 WHERE id IN (?)`, outboxTable), ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.db.Exec(n.db.Rebind(query), args...)
+	return err
+}
+
+// IncrOutboxEventAttempts counts a failed delivery of the events
+func (n *SQLDB) IncrOutboxEventAttempts(ids []int64) error {
+	query, args, err := sqlx.In(fmt.Sprintf(`UPDATE %s SET attempts=attempts+1 WHERE id IN (?)`, outboxTable), ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.db.Exec(n.db.Rebind(query), args...)
+	return err
+}
+
+// DeleteOutboxEventsBefore deletes the events written before the time, the undelivered events are kept unless all is true
+func (n *SQLDB) DeleteOutboxEventsBefore(t time.Time, all bool) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, outboxTable)
+	if !all {
+		query += " AND dispatched=true"
+	}
+
+	ret, err := n.db.Exec(query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return ret.RowsAffected()
+}
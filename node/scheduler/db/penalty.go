@@ -0,0 +1,220 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SavePenaltyRule saves the penalty rule, the rule is updated if its id is set
+func (n *SQLDB) SavePenaltyRule(rule *types.PenaltyRule) (int64, error) {
+	if rule.ID > 0 {
+		query := fmt.Sprintf(`UPDATE %s SET name=:name, rule_type=:rule_type, threshold=:threshold, committed_start_hour=:committed_start_hour,
+		        committed_end_hour=:committed_end_hour, deduct_points=:deduct_points, freeze_hours=:freeze_hours, enabled=:enabled WHERE id=:id`, penaltyRuleTable)
+		_, err := n.db.NamedExec(query, rule)
+		return rule.ID, err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (name, rule_type, threshold, committed_start_hour, committed_end_hour, deduct_points, freeze_hours, enabled)
+	        VALUES (:name, :rule_type, :threshold, :committed_start_hour, :committed_end_hour, :deduct_points, :freeze_hours, :enabled)`, penaltyRuleTable)
+	result, err := n.db.NamedExec(query, rule)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// DeletePenaltyRule deletes the penalty rule, the penalties applied by the rule are kept
+func (n *SQLDB) DeletePenaltyRule(id int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, penaltyRuleTable)
+	_, err := n.db.Exec(query, id)
+	return err
+}
+
+// LoadPenaltyRules load all penalty rules
+func (n *SQLDB) LoadPenaltyRules() ([]*types.PenaltyRule, error) {
+	var out []*types.PenaltyRule
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY id", penaltyRuleTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ApplyPenalty deducts the points of the rule from the node, at most the points the node has,
+// saves the penalty and counts the rule applied
+func (n *SQLDB) ApplyPenalty(penalty *types.Penalty, points types.Points) (int64, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ApplyPenalty Rollback err:%s", err.Error())
+		}
+	}()
+
+	var profit types.Points
+	query := fmt.Sprintf("SELECT profit FROM %s WHERE node_id=? FOR UPDATE", nodeInfoTable)
+	if err = tx.Get(&profit, query, penalty.NodeID); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	penalty.DeductedPoints = points
+	if profit.Cmp(points) < 0 {
+		penalty.DeductedPoints = profit
+	}
+	if penalty.DeductedPoints.Sign() < 0 {
+		penalty.DeductedPoints = types.Points{}
+	}
+
+	if penalty.DeductedPoints.Sign() > 0 {
+		query = fmt.Sprintf("UPDATE %s SET profit=profit-%s WHERE node_id=?", nodeInfoTable, pointsParam)
+		if _, err = tx.Exec(query, penalty.DeductedPoints, penalty.NodeID); err != nil {
+			return 0, err
+		}
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, rule_id, rule_type, reason, deducted_points, frozen_until, created_time)
+	        VALUES (:node_id, :rule_id, :rule_type, :reason, :deducted_points, :frozen_until, :created_time)`, penaltyTable)
+	result, err := tx.NamedExec(query, penalty)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET applied_count=applied_count+1, last_applied_time=? WHERE id=?", penaltyRuleTable)
+	if _, err = tx.Exec(query, penalty.CreatedTime, penalty.RuleID); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// LoadPenalty load the penalty
+func (n *SQLDB) LoadPenalty(id int64) (*types.Penalty, error) {
+	var out types.Penalty
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?", penaltyTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadNodePenalties load the penalties applied to the node, the latest first
+func (n *SQLDB) LoadNodePenalties(nodeID string, limit, offset int) (*types.ListPenaltyRsp, error) {
+	res := new(types.ListPenaltyRsp)
+
+	if limit > loadPenaltiesDefaultLimit || limit <= 0 {
+		limit = loadPenaltiesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", penaltyTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY id DESC LIMIT ? OFFSET ?", penaltyTable)
+	if err := n.db.Select(&res.Data, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// AppealPenalty marks the penalty as appealed, a penalty can only be appealed once
+func (n *SQLDB) AppealPenalty(id int64, reason string) error {
+	query := fmt.Sprintf(`UPDATE %s SET appeal=?, appeal_reason=? WHERE id=? AND appeal=?`, penaltyTable)
+	result, err := n.db.Exec(query, types.PenaltyAppealPending, reason, id, types.PenaltyAppealNone)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if r < 1 {
+		return xerrors.Errorf("penalty %d not exist or has been appealed", id)
+	}
+
+	return nil
+}
+
+// ResolvePenaltyAppeal resolves the pending appeal of the penalty,
+// the deducted points are restored and the freeze is lifted if the appeal is accepted
+func (n *SQLDB) ResolvePenaltyAppeal(id int64, accepted bool) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ResolvePenaltyAppeal Rollback err:%s", err.Error())
+		}
+	}()
+
+	var penalty types.Penalty
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=? FOR UPDATE", penaltyTable)
+	if err = tx.Get(&penalty, query, id); err != nil {
+		return err
+	}
+
+	if penalty.Appeal != types.PenaltyAppealPending {
+		return xerrors.Errorf("penalty %d has no pending appeal", id)
+	}
+
+	if !accepted {
+		query = fmt.Sprintf("UPDATE %s SET appeal=? WHERE id=?", penaltyTable)
+		if _, err = tx.Exec(query, types.PenaltyAppealRejected, id); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET appeal=?, frozen_until=created_time WHERE id=?", penaltyTable)
+	if _, err = tx.Exec(query, types.PenaltyAppealAccepted, id); err != nil {
+		return err
+	}
+
+	if penalty.DeductedPoints.Sign() > 0 {
+		query = fmt.Sprintf("UPDATE %s SET profit=profit+%s WHERE node_id=?", nodeInfoTable, pointsParam)
+		if _, err = tx.Exec(query, penalty.DeductedPoints, penalty.NodeID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadRewardFrozenNodes load the nodes whose rewards are frozen at the time
+func (n *SQLDB) LoadRewardFrozenNodes(t time.Time) (map[string]bool, error) {
+	var nodeIDs []string
+	query := fmt.Sprintf("SELECT DISTINCT node_id FROM %s WHERE frozen_until>?", penaltyTable)
+	if err := n.db.Select(&nodeIDs, query, t); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]bool, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		out[nodeID] = true
+	}
+
+	return out, nil
+}
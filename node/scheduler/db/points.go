@@ -0,0 +1,67 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// pointsColumnType the type of the points columns, see types.PointsScale
+	pointsColumnType = "DECIMAL(38, 12)"
+	// pointsParam the placeholder of the points added to a points column, the decimal string of the points
+	// would be converted to a double in the arithmetic without the cast
+	pointsParam = "CAST(? AS " + pointsColumnType + ")"
+)
+
+// pointColumns the columns keeping points, by table
+var pointColumns = map[string][]string{
+	nodeInfoTable:         {"profit"},
+	validationResultTable: {"profit"},
+	retrieveEventTable:    {"profit"},
+	settlementEpochTable:  {"total_points"},
+	settlementShareTable:  {"points"},
+	settlementPointsTable: {"points"},
+	penaltyRuleTable:      {"deduct_points"},
+	penaltyTable:          {"deducted_points"},
+	commitmentRecordTable: {"earned_points", "bonus_points"},
+	nodeStatsDailyTable:   {"points"},
+	pointsEpochCloseTable: {"points"},
+	pointsEpochTable:      {"points", "total"},
+	pointsEpochTotalTable: {"total"},
+	pointsCorrectionTable: {"points"},
+}
+
+// migratePointColumns widens the points columns created with fewer digits or as integers to pointsColumnType,
+// the existing values are kept as they are
+func migratePointColumns(tx *sqlx.Tx) error {
+	for table, columns := range pointColumns {
+		for _, column := range columns {
+			var col struct {
+				DataType  string `db:"DATA_TYPE"`
+				Precision int    `db:"NUMERIC_PRECISION"`
+				Scale     int    `db:"NUMERIC_SCALE"`
+			}
+
+			query := `SELECT DATA_TYPE, IFNULL(NUMERIC_PRECISION, 0) AS NUMERIC_PRECISION, IFNULL(NUMERIC_SCALE, 0) AS NUMERIC_SCALE
+				FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND COLUMN_NAME=?`
+			if err := tx.Get(&col, query, table, column); err != nil {
+				return xerrors.Errorf("load column %s.%s: %w", table, column, err)
+			}
+
+			if col.DataType == "decimal" && col.Precision >= 38 && col.Scale >= 12 {
+				continue
+			}
+
+			log.Infof("migrate points column %s.%s from %s(%d, %d) to %s", table, column, col.DataType, col.Precision, col.Scale, pointsColumnType)
+
+			query = fmt.Sprintf("ALTER TABLE %s MODIFY %s %s DEFAULT 0", table, column, pointsColumnType)
+			if _, err := tx.Exec(query); err != nil {
+				return xerrors.Errorf("migrate column %s.%s: %w", table, column, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// LoadLatestPointsEpoch load the latest closed points epoch
+func (n *SQLDB) LoadLatestPointsEpoch() (*types.PointsEpochSummary, error) {
+	var out types.PointsEpochSummary
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY epoch DESC LIMIT 1", pointsEpochCloseTable)
+	if err := n.db.Get(&out, query); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// ClosePointsEpoch closes the epoch with the points the nodes earned since the previous close,
+// which is the change of the cumulative points from the points of the closed epochs.
+// Only the nodes whose points changed are saved
+func (n *SQLDB) ClosePointsEpoch(epoch, closedTime time.Time) (*types.PointsEpochSummary, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ClosePointsEpoch Rollback err:%s", err.Error())
+		}
+	}()
+
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE epoch>=?", pointsEpochCloseTable)
+	if err = tx.Get(&count, query, epoch); err != nil {
+		return nil, err
+	}
+
+	if count > 0 {
+		return nil, xerrors.Errorf("epoch %s is already closed", epoch.Format("2006-01-02"))
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, epoch, points, total)
+			SELECT a.node_id, ?, a.profit-IFNULL(b.total,0), a.profit FROM %s a LEFT JOIN %s b ON a.node_id=b.node_id
+			WHERE a.profit<>IFNULL(b.total,0)`, pointsEpochTable, nodeInfoTable, pointsEpochTotalTable)
+	if _, err = tx.Exec(query, epoch); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, total) SELECT node_id, total FROM %s WHERE epoch=?
+			ON DUPLICATE KEY UPDATE total=VALUES(total)`, pointsEpochTotalTable, pointsEpochTable)
+	if _, err = tx.Exec(query, epoch); err != nil {
+		return nil, err
+	}
+
+	summary := &types.PointsEpochSummary{Epoch: epoch, ClosedTime: closedTime}
+	query = fmt.Sprintf("SELECT count(*), IFNULL(SUM(points),0) FROM %s WHERE epoch=?", pointsEpochTable)
+	if err = tx.QueryRow(query, epoch).Scan(&summary.NodeCount, &summary.Points); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (epoch, node_count, points, closed_time) VALUES (:epoch, :node_count, :points, :closed_time)`, pointsEpochCloseTable)
+	if _, err = tx.NamedExec(query, summary); err != nil {
+		return nil, err
+	}
+
+	return summary, tx.Commit()
+}
+
+// CorrectPointsEpoch saves the correction of the closed epoch and adds its points to the node,
+// the points are also added to the points of the closed epochs so the next close does not count them again
+func (n *SQLDB) CorrectPointsEpoch(correction *types.PointsCorrection) (int64, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("CorrectPointsEpoch Rollback err:%s", err.Error())
+		}
+	}()
+
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE epoch=?", pointsEpochCloseTable)
+	if err = tx.Get(&count, query, correction.Epoch); err != nil {
+		return 0, err
+	}
+
+	if count == 0 {
+		return 0, xerrors.Errorf("epoch %s is not closed", correction.Epoch.Format("2006-01-02"))
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET profit=profit+%s WHERE node_id=?", nodeInfoTable, pointsParam)
+	result, err := tx.Exec(query, correction.Points, correction.NodeID)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rows == 0 {
+		return 0, xerrors.Errorf("node %s not found", correction.NodeID)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, total) VALUES (?, %s) ON DUPLICATE KEY UPDATE total=total+VALUES(total)`,
+		pointsEpochTotalTable, pointsParam)
+	if _, err = tx.Exec(query, correction.NodeID, correction.Points); err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (epoch, node_id, points, reason, created_time) VALUES (:epoch, :node_id, :points, :reason, :created_time)`,
+		pointsCorrectionTable)
+	result, err = tx.NamedExec(query, correction)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// LoadNodePointsEpochs load the epochs of the node in [start, end] with the corrections made to them,
+// and the points of the node in all the closed epochs and in the open epoch
+func (n *SQLDB) LoadNodePointsEpochs(nodeID string, start, end time.Time) (*types.NodePointsEpochsRsp, error) {
+	res := new(types.NodePointsEpochsRsp)
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=? AND epoch>=? AND epoch<=? ORDER BY epoch", pointsEpochTable)
+	if err := n.db.Select(&res.Epochs, query, nodeID, start, end); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? AND epoch>=? AND epoch<=? ORDER BY id", pointsCorrectionTable)
+	if err := n.db.Select(&res.Corrections, query, nodeID, start, end); err != nil {
+		return nil, err
+	}
+
+	epochs := make(map[int64]*types.PointsEpoch, len(res.Epochs))
+	for _, epoch := range res.Epochs {
+		epochs[epoch.Epoch.Unix()] = epoch
+	}
+	for _, correction := range res.Corrections {
+		if epoch, ok := epochs[correction.Epoch.Unix()]; ok {
+			epoch.Correction = epoch.Correction.Add(correction.Points)
+		}
+	}
+
+	query = fmt.Sprintf("SELECT total FROM %s WHERE node_id=?", pointsEpochTotalTable)
+	if err := n.db.Get(&res.ClosedPoints, query, nodeID); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var profit types.Points
+	query = fmt.Sprintf("SELECT profit FROM %s WHERE node_id=?", nodeInfoTable)
+	if err := n.db.Get(&profit, query, nodeID); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	res.OpenPoints = profit.Sub(res.ClosedPoints)
+
+	return res, nil
+}
+
+// LoadPointsEpochs load the closed epochs, the latest first
+func (n *SQLDB) LoadPointsEpochs(limit, offset int) (*types.ListPointsEpochRsp, error) {
+	res := new(types.ListPointsEpochRsp)
+
+	if limit > loadPointsEpochsDefaultLimit || limit <= 0 {
+		limit = loadPointsEpochsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", pointsEpochCloseTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY epoch DESC LIMIT ? OFFSET ?", pointsEpochCloseTable)
+	if err := n.db.Select(&res.Data, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveRelaySession saves the relay session
+func (n *SQLDB) SaveRelaySession(session *types.RelaySession) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, edge_id, candidate_id, created_time, updated_time) VALUES (:id, :edge_id, :candidate_id, :created_time, :updated_time)`, relaySessionTable)
+	_, err := n.db.NamedExec(query, session)
+	return err
+}
+
+// AddRelayedBytes adds the bytes relayed by the candidate to the session
+func (n *SQLDB) AddRelayedBytes(sessionID, candidateID string, bytes int64) error {
+	query := fmt.Sprintf("UPDATE %s SET relayed_bytes=relayed_bytes+?, updated_time=NOW() WHERE id=? AND candidate_id=?", relaySessionTable)
+	result, err := n.db.Exec(query, bytes, sessionID, candidateID)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if r < 1 {
+		return fmt.Errorf("relay session %s of candidate %s not found", sessionID, candidateID)
+	}
+
+	return nil
+}
+
+// LoadRelaySessions load the relay sessions the node takes part in as the edge or the relay, the latest first
+func (n *SQLDB) LoadRelaySessions(nodeID string, limit, offset int) (*types.ListRelaySessionRsp, error) {
+	res := new(types.ListRelaySessionRsp)
+
+	if limit > loadRelaySessionsDefaultLimit || limit <= 0 {
+		limit = loadRelaySessionsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s WHERE edge_id=? OR candidate_id=?", relaySessionTable)
+	if err := n.db.Get(&res.Total, query, nodeID, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE edge_id=? OR candidate_id=? ORDER BY created_time DESC LIMIT ? OFFSET ?", relaySessionTable)
+	if err := n.db.Select(&res.Data, query, nodeID, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SaveReplicationAgreement creates the agreement, a tenant has an agreement with an area at most
+func (n *SQLDB) SaveReplicationAgreement(agreement *types.ReplicationAgreement) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, tenant_id, peer_area_id, replicas, state, cursor_time, cursor_hash)
+				VALUES (:id, :tenant_id, :peer_area_id, :replicas, :state, :cursor_time, :cursor_hash)`, replicationTable)
+	_, err := n.db.NamedExec(query, agreement)
+	return err
+}
+
+// LoadReplicationAgreement load the agreement
+func (n *SQLDB) LoadReplicationAgreement(id string) (*types.ReplicationAgreement, error) {
+	var agreement types.ReplicationAgreement
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=?`, replicationTable)
+	if err := n.db.Get(&agreement, query, id); err != nil {
+		return nil, err
+	}
+
+	return &agreement, nil
+}
+
+// LoadReplicationAgreements load the agreements of the tenant, all the agreements if the tenant is empty
+func (n *SQLDB) LoadReplicationAgreements(tenantID string) ([]*types.ReplicationAgreement, error) {
+	where := "1=1"
+	var args []interface{}
+	if tenantID != "" {
+		where = "tenant_id=?"
+		args = append(args, tenantID)
+	}
+
+	var out []*types.ReplicationAgreement
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY created_time`, replicationTable, where)
+	if err := n.db.Select(&out, query, args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateReplicationAgreementState pauses or resumes the agreement
+func (n *SQLDB) UpdateReplicationAgreementState(id string, state types.ReplicationAgreementState) error {
+	query := fmt.Sprintf(`UPDATE %s SET state=? WHERE id=?`, replicationTable)
+	result, err := n.db.Exec(query, state, id)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if r == 0 {
+		return xerrors.Errorf("replication agreement %s not found", id)
+	}
+
+	return nil
+}
+
+// DeleteReplicationAgreement deletes the agreement, the assets mirrored already are kept in the peer area
+func (n *SQLDB) DeleteReplicationAgreement(id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, replicationTable)
+	_, err := n.db.Exec(query, id)
+	return err
+}
+
+// UpdateReplicationProgress moves the cursor of the agreement to the last manifest accepted by the peer area and saves
+// the message of the streaming, the cursor is kept if last is nil
+func (n *SQLDB) UpdateReplicationProgress(id string, last *types.MirrorManifest, accepted int, message string) error {
+	if last == nil {
+		query := fmt.Sprintf(`UPDATE %s SET message=?, synced_time=NOW() WHERE id=?`, replicationTable)
+		_, err := n.db.Exec(query, message, id)
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET cursor_time=?, cursor_hash=?, streamed=streamed+?, message=?, synced_time=NOW() WHERE id=?`, replicationTable)
+	_, err := n.db.Exec(query, last.CreatedTime, last.Hash, accepted, message, id)
+	return err
+}
+
+// LoadTenantManifests load the manifests of the assets of the users of the tenant created after the cursor and
+// before the time, in the order they were created. The private assets are not mirrored and not loaded
+func (n *SQLDB) LoadTenantManifests(tenantID string, cursorTime time.Time, cursorHash string, before time.Time, limit int) ([]*types.MirrorManifest, error) {
+	query := fmt.Sprintf(`SELECT u.hash, r.cid, r.total_size, r.expiration, u.created_time FROM %s u
+				JOIN %s t ON t.user_id=u.user_id JOIN %s r ON r.hash=u.hash
+				WHERE t.tenant_id=? AND (u.created_time>? OR (u.created_time=? AND u.hash>?)) AND u.created_time<?
+				AND NOT EXISTS (SELECT 1 FROM %s a WHERE a.hash=u.hash)
+				ORDER BY u.created_time, u.hash LIMIT ?`, userAssetTable, tenantUserTable, assetRecordTable, assetACLTable)
+
+	var out []*types.MirrorManifest
+	if err := n.db.Select(&out, query, tenantID, cursorTime, cursorTime, cursorHash, before, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SaveMirroredAsset records the asset mirrored from another area, the nodes pull the asset from the source scheduler
+func (n *SQLDB) SaveMirroredAsset(asset *types.MirroredAsset) error {
+	query := fmt.Sprintf(`INSERT INTO %s (hash, cid, agreement_id, tenant_id, source_server_id, source_area_id)
+				VALUES (:hash, :cid, :agreement_id, :tenant_id, :source_server_id, :source_area_id)
+				ON DUPLICATE KEY UPDATE agreement_id=:agreement_id, tenant_id=:tenant_id, source_server_id=:source_server_id,
+				source_area_id=:source_area_id`, mirroredAssetTable)
+	_, err := n.db.NamedExec(query, asset)
+	return err
+}
+
+// LoadMirroredAsset load the asset mirrored from another area
+func (n *SQLDB) LoadMirroredAsset(hash string) (*types.MirroredAsset, error) {
+	var asset types.MirroredAsset
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=?`, mirroredAssetTable)
+	if err := n.db.Get(&asset, query, hash); err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// DeleteMirroredAsset forgets the source of the asset removed
+func (n *SQLDB) DeleteMirroredAsset(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, mirroredAssetTable)
+	_, err := n.db.Exec(query, hash)
+	return err
+}
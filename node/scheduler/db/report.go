@@ -0,0 +1,27 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// IncrReportRejection counts a report of the kind of the node rejected for the reason
+func (n *SQLDB) IncrReportRejection(nodeID string, kind types.ReportKind, reason string, t time.Time) error {
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, kind, count, last_reason, updated_time) VALUES (?, ?, 1, ?, ?)
+			ON DUPLICATE KEY UPDATE count=count+1, last_reason=VALUES(last_reason), updated_time=VALUES(updated_time)`, reportRejectionTable)
+	_, err := n.db.Exec(query, nodeID, kind, reason, t)
+	return err
+}
+
+// LoadReportRejections loads the rejected reports of the node by kind
+func (n *SQLDB) LoadReportRejections(nodeID string) ([]*types.ReportRejection, error) {
+	var out []*types.ReportRejection
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY kind", reportRejectionTable)
+	if err := n.db.Select(&out, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
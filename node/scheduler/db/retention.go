@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// deleteBefore deletes at most limit rows of the table whose column is before the value,
+// the rows are deleted in batches to keep the locks short
+func (n *SQLDB) deleteBefore(table, column string, before interface{}, limit int) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s<? LIMIT ?`, table, column)
+	result, err := n.db.Exec(query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteValidationResultsBefore deletes at most limit validation results started before the time
+func (n *SQLDB) DeleteValidationResultsBefore(t time.Time, limit int) (int64, error) {
+	return n.deleteBefore(validationResultTable, "start_time", t, limit)
+}
+
+// DeleteReplicaEventsBefore deletes at most limit replica events ended before the time
+func (n *SQLDB) DeleteReplicaEventsBefore(t time.Time, limit int) (int64, error) {
+	return n.deleteBefore(replicaEventTable, "end_time", t, limit)
+}
+
+// DeleteRetrieveEventsBefore deletes at most limit retrieve events created before the time
+func (n *SQLDB) DeleteRetrieveEventsBefore(t time.Time, limit int) (int64, error) {
+	return n.deleteBefore(retrieveEventTable, "created_time", t.Unix(), limit)
+}
+
+// DeleteWorkloadRecordsBefore deletes at most limit workload records created before the time
+func (n *SQLDB) DeleteWorkloadRecordsBefore(t time.Time, limit int) (int64, error) {
+	return n.deleteBefore(workloadRecordTable, "created_time", t, limit)
+}
+
+// DeleteRelaySessionsBefore deletes at most limit relay sessions last updated before the time
+func (n *SQLDB) DeleteRelaySessionsBefore(t time.Time, limit int) (int64, error) {
+	return n.deleteBefore(relaySessionTable, "updated_time", t, limit)
+}
+
+// DeleteWebhookDeliveriesBefore deletes at most limit webhook deliveries last updated before the time
+func (n *SQLDB) DeleteWebhookDeliveriesBefore(t time.Time, limit int) (int64, error) {
+	return n.deleteBefore(webhookDeliveryTable, "updated_time", t, limit)
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SaveRetrievalProbes saves the retrieval probes of a round
+func (n *SQLDB) SaveRetrievalProbes(probes []*types.RetrievalProbe) error {
+	if len(probes) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, node_type, area_id, cid, success, slow, ttfb, bytes, duration, throughput, message, created_time)
+				VALUES (:node_id, :node_type, :area_id, :cid, :success, :slow, :ttfb, :bytes, :duration, :throughput, :message, :created_time)`, retrievalProbeTable)
+	_, err := n.db.NamedExec(query, probes)
+	return err
+}
+
+// LoadRetrievalProbes loads the retrieval probes of the node, the latest first
+func (n *SQLDB) LoadRetrievalProbes(nodeID string, limit, offset int) (*types.ListRetrievalProbeRsp, error) {
+	res := new(types.ListRetrievalProbeRsp)
+
+	if limit > loadRetrievalProbesDefaultLimit || limit <= 0 {
+		limit = loadRetrievalProbesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", retrievalProbeTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY created_time DESC LIMIT ? OFFSET ?", retrievalProbeTable)
+	if err := n.db.Select(&res.Probes, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadRetrievalSLA aggregates the retrieval probes in [start, end) by node or area, the worst sla first
+func (n *SQLDB) LoadRetrievalSLA(groupBy types.RetrievalSLAGroup, start, end time.Time, limit, offset int) (*types.RetrievalSLAReport, error) {
+	var column string
+	switch groupBy {
+	case types.RetrievalSLAByNode:
+		column = "node_id"
+	case types.RetrievalSLAByArea:
+		column = "area_id"
+	default:
+		return nil, xerrors.Errorf("invalid group %s", groupBy)
+	}
+
+	if limit > loadRetrievalProbesDefaultLimit || limit <= 0 {
+		limit = loadRetrievalProbesDefaultLimit
+	}
+
+	res := &types.RetrievalSLAReport{Start: start, End: end, GroupBy: groupBy}
+	query := fmt.Sprintf("SELECT count(DISTINCT %s) FROM %s WHERE created_time>=? AND created_time<?", column, retrievalProbeTable)
+	if err := n.db.Get(&res.Total, query, start, end); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`SELECT %s AS group_key, count(*) AS probes, SUM(NOT success) AS failures, SUM(success AND slow) AS slow,
+				CAST(COALESCE(AVG(IF(success, ttfb, NULL)), 0) AS SIGNED) AS avg_ttfb, COALESCE(MAX(IF(success, ttfb, NULL)), 0) AS max_ttfb,
+				CAST(COALESCE(AVG(IF(success, throughput, NULL)), 0) AS SIGNED) AS avg_throughput
+				FROM %s WHERE created_time>=? AND created_time<? GROUP BY %s
+				ORDER BY (SUM(NOT success) + SUM(success AND slow)) / count(*) DESC, group_key LIMIT ? OFFSET ?`, column, retrievalProbeTable, column)
+	if err := n.db.Select(&res.Entries, query, start, end, limit, offset); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range res.Entries {
+		if entry.Probes > 0 {
+			entry.SLA = float64(entry.Probes-entry.Failures-entry.Slow) * 100 / float64(entry.Probes)
+		}
+	}
+
+	return res, nil
+}
+
+// DeleteRetrievalProbesBefore deletes the retrieval probes before the time
+func (n *SQLDB) DeleteRetrievalProbesBefore(before time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, retrievalProbeTable)
+	_, err := n.db.Exec(query, before)
+	return err
+}
+
+// SaveSpotCheck saves the spot check
+func (n *SQLDB) SaveSpotCheck(check *types.SpotCheck) error {
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, area_id, cid, range_start, range_size, proxy, success, verified, mismatched, slow, ttfb,
+				throughput, claimed_bandwidth, message, created_time) VALUES (:node_id, :area_id, :cid, :range_start, :range_size, :proxy,
+				:success, :verified, :mismatched, :slow, :ttfb, :throughput, :claimed_bandwidth, :message, :created_time)`, spotCheckTable)
+	_, err := n.db.NamedExec(query, check)
+	return err
+}
+
+// LoadSpotChecks loads the spot checks of the node, the latest first
+func (n *SQLDB) LoadSpotChecks(nodeID string, limit, offset int) (*types.ListSpotCheckRsp, error) {
+	res := new(types.ListSpotCheckRsp)
+
+	if limit > loadRetrievalProbesDefaultLimit || limit <= 0 {
+		limit = loadRetrievalProbesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", spotCheckTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY created_time DESC LIMIT ? OFFSET ?", spotCheckTable)
+	if err := n.db.Select(&res.Checks, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteSpotChecksBefore deletes the spot checks before the time
+func (n *SQLDB) DeleteSpotChecksBefore(before time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, spotCheckTable)
+	_, err := n.db.Exec(query, before)
+	return err
+}
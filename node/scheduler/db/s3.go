@@ -0,0 +1,101 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveS3AccessKey saves the access key of the user
+func (n *SQLDB) SaveS3AccessKey(key *types.S3AccessKey) error {
+	query := fmt.Sprintf(`INSERT INTO %s (access_key, secret_key, user_id, created_time) VALUES (:access_key, :secret_key, :user_id, :created_time)`, s3AccessKeyTable)
+	_, err := n.db.NamedExec(query, key)
+	return err
+}
+
+// LoadS3AccessKey load the access key
+func (n *SQLDB) LoadS3AccessKey(accessKey string) (*types.S3AccessKey, error) {
+	var key types.S3AccessKey
+	query := fmt.Sprintf("SELECT * FROM %s WHERE access_key=?", s3AccessKeyTable)
+	if err := n.db.Get(&key, query, accessKey); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// LoadS3AccessKeysOfUser load the access keys of the user
+func (n *SQLDB) LoadS3AccessKeysOfUser(userID string) ([]*types.S3AccessKey, error) {
+	var keys []*types.S3AccessKey
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id=? ORDER BY created_time", s3AccessKeyTable)
+	if err := n.db.Select(&keys, query, userID); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// DeleteS3AccessKey deletes the access key of the user
+func (n *SQLDB) DeleteS3AccessKey(userID, accessKey string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id=? AND access_key=?", s3AccessKeyTable)
+	_, err := n.db.Exec(query, userID, accessKey)
+	return err
+}
+
+// SaveS3Object saves the object, the object with the same key is replaced
+func (n *SQLDB) SaveS3Object(object *types.S3Object) error {
+	query := fmt.Sprintf(`REPLACE INTO %s (user_id, bucket, object_key, cid, size, car_size, etag, content_type, created_time)
+	        VALUES (:user_id, :bucket, :object_key, :cid, :size, :car_size, :etag, :content_type, :created_time)`, s3ObjectTable)
+	_, err := n.db.NamedExec(query, object)
+	return err
+}
+
+// LoadS3Object load the object of the bucket
+func (n *SQLDB) LoadS3Object(userID, bucket, key string) (*types.S3Object, error) {
+	var object types.S3Object
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id=? AND bucket=? AND object_key=?", s3ObjectTable)
+	if err := n.db.Get(&object, query, userID, bucket, key); err != nil {
+		return nil, err
+	}
+
+	return &object, nil
+}
+
+// LoadS3Objects load the objects of the bucket in key order, one more object than the limit is loaded to check truncation
+func (n *SQLDB) LoadS3Objects(req *types.ListS3ObjectsReq) (*types.ListS3ObjectsRsp, error) {
+	limit := req.Limit
+	if limit > loadS3ObjectsDefaultLimit || limit <= 0 {
+		limit = loadS3ObjectsDefaultLimit
+	}
+
+	var objects []*types.S3Object
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id=? AND bucket=? AND object_key LIKE ? AND object_key>? ORDER BY object_key LIMIT ?", s3ObjectTable)
+	if err := n.db.Select(&objects, query, req.UserID, req.Bucket, escapeLike(req.Prefix)+"%", req.StartAfter, limit+1); err != nil {
+		return nil, err
+	}
+
+	rsp := &types.ListS3ObjectsRsp{Objects: objects}
+	if len(objects) > limit {
+		rsp.Objects = objects[:limit]
+		rsp.IsTruncated = true
+	}
+
+	return rsp, nil
+}
+
+// LoadS3Buckets load the buckets of the user
+func (n *SQLDB) LoadS3Buckets(userID string) ([]string, error) {
+	var buckets []string
+	query := fmt.Sprintf("SELECT DISTINCT bucket FROM %s WHERE user_id=? ORDER BY bucket", s3ObjectTable)
+	if err := n.db.Select(&buckets, query, userID); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// escapeLike escapes the wildcards of the like pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
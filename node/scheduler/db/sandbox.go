@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SaveSandboxNodeRegisterInfo save the registration of a sandbox node, the node is purged after the expiration
+func (n *SQLDB) SaveSandboxNodeRegisterInfo(detail *types.ActivationDetail, expiration time.Time) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveSandboxNodeRegisterInfo Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s (node_id, created_time, node_type, activation_key, ip, public_key)
+				VALUES (:node_id, NOW(), :node_type, :activation_key, :ip, :public_key)`, nodeRegisterTable)
+	if _, err = tx.NamedExec(query, detail); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, expiration) VALUES (?, ?)`, sandboxNodeTable)
+	if _, err = tx.Exec(query, detail.NodeID, expiration); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadSandboxExpiration load the expiration of the sandbox node, the zero time if the node is not a sandbox node
+func (n *SQLDB) LoadSandboxExpiration(nodeID string) (time.Time, error) {
+	var expiration time.Time
+	query := fmt.Sprintf(`SELECT expiration FROM %s WHERE node_id=?`, sandboxNodeTable)
+	if err := n.db.Get(&expiration, query, nodeID); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return expiration, nil
+}
+
+// LoadExpiredSandboxNodes load the ids of the sandbox nodes expired before the time
+func (n *SQLDB) LoadExpiredSandboxNodes(before time.Time, limit int) ([]string, error) {
+	var out []string
+	query := fmt.Sprintf(`SELECT node_id FROM %s WHERE expiration<? ORDER BY expiration LIMIT ?`, sandboxNodeTable)
+	if err := n.db.Select(&out, query, before, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// PurgeSandboxNode deletes the sandbox node with its info, its registration and its records
+func (n *SQLDB) PurgeSandboxNode(nodeID string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("PurgeSandboxNode Rollback err:%s", err.Error())
+		}
+	}()
+
+	for _, table := range append([]string{nodeInfoTable, nodeRegisterTable}, purgeNodeTables...) {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, table)
+		if _, err = tx.Exec(query, nodeID); err != nil {
+			return xerrors.Errorf("delete from %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// AddSegmentHits adds the retrievals of the segments reported by a node to the stats of the segments
+func (n *SQLDB) AddSegmentHits(stats []*types.SegmentStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("AddSegmentHits Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (hash, segment, hits, updated_time) VALUES (?, ?, ?, NOW())
+				ON DUPLICATE KEY UPDATE hits=hits+?, updated_time=NOW()`, segmentStatsTable)
+	for _, s := range stats {
+		if _, err := tx.Exec(query, s.Hash, s.Segment, s.Hits, s.Hits); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadSegmentStats loads the stats of the segments of the asset, the most retrieved first
+func (n *SQLDB) LoadSegmentStats(hash string, limit, offset int) (*types.ListSegmentStatsRsp, error) {
+	res := new(types.ListSegmentStatsRsp)
+
+	if limit > loadSegmentStatsDefaultLimit || limit <= 0 {
+		limit = loadSegmentStatsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE hash=?", segmentStatsTable)
+	if err := n.db.Get(&res.Total, query, hash); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE hash=? ORDER BY hits DESC LIMIT ? OFFSET ?", segmentStatsTable)
+	if err := n.db.Select(&res.Segments, query, hash, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteSegmentStatsBefore removes the stats of the segments not retrieved since the time
+func (n *SQLDB) DeleteSegmentStatsBefore(t time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE updated_time<?", segmentStatsTable)
+	_, err := n.db.Exec(query, t)
+	return err
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// LoadNodePoints load the cumulative points of the nodes, the sandbox nodes are not rewarded
+func (n *SQLDB) LoadNodePoints() (map[string]types.Points, error) {
+	query := fmt.Sprintf("SELECT node_id, profit FROM %s WHERE node_id NOT IN (SELECT node_id FROM %s)", nodeInfoTable, sandboxNodeTable)
+	return n.loadPoints(query)
+}
+
+// LoadSettlementPoints load the points of the nodes frozen at the end of the epoch
+func (n *SQLDB) LoadSettlementPoints(epoch int64) (map[string]types.Points, error) {
+	query := fmt.Sprintf("SELECT node_id, points FROM %s WHERE epoch=?", settlementPointsTable)
+	return n.loadPoints(query, epoch)
+}
+
+func (n *SQLDB) loadPoints(query string, args ...interface{}) (map[string]types.Points, error) {
+	rows, err := n.db.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]types.Points)
+	for rows.Next() {
+		var nodeID string
+		var points types.Points
+		if err := rows.Scan(&nodeID, &points); err != nil {
+			return nil, err
+		}
+		out[nodeID] = points
+	}
+
+	return out, rows.Err()
+}
+
+// LoadAllAccountNodes load the accounts of the bound nodes, keyed by node id
+func (n *SQLDB) LoadAllAccountNodes() (map[string]string, error) {
+	var list []*types.AccountNode
+	query := fmt.Sprintf("SELECT * FROM %s", accountNodeTable)
+	if err := n.db.Select(&list, query); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(list))
+	for _, an := range list {
+		out[an.NodeID] = an.AccountID
+	}
+
+	return out, nil
+}
+
+// SaveSettlement saves the epoch with the shares of the accounts and the frozen points of the nodes,
+// the points frozen in the earlier epochs are no longer needed and removed
+func (n *SQLDB) SaveSettlement(epoch *types.SettlementEpoch, shares []*types.SettlementShare, points map[string]types.Points) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveSettlement Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (epoch, start_time, end_time, total_points, total_reward, account_count, merkle_root, signature)
+	        VALUES (:epoch, :start_time, :end_time, :total_points, :total_reward, :account_count, :merkle_root, :signature)`, settlementEpochTable)
+	if _, err = tx.NamedExec(query, epoch); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (epoch, account_id, points, share, amount) VALUES (:epoch, :account_id, :points, :share, :amount)`, settlementShareTable)
+	for _, share := range shares {
+		if _, err = tx.NamedExec(query, share); err != nil {
+			return err
+		}
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (epoch, node_id, points) VALUES (?, ?, ?)`, settlementPointsTable)
+	for nodeID, p := range points {
+		if _, err = tx.Exec(query, epoch.Epoch, nodeID, p); err != nil {
+			return err
+		}
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE epoch<?`, settlementPointsTable)
+	if _, err = tx.Exec(query, epoch.Epoch); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetSettlementExported marks the epoch as handed to the payout hook
+func (n *SQLDB) SetSettlementExported(epoch int64) error {
+	query := fmt.Sprintf(`UPDATE %s SET exported=true WHERE epoch=?`, settlementEpochTable)
+	_, err := n.db.Exec(query, epoch)
+	return err
+}
+
+// LoadLatestSettlementEpoch load the latest settlement epoch
+func (n *SQLDB) LoadLatestSettlementEpoch() (*types.SettlementEpoch, error) {
+	var out types.SettlementEpoch
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY epoch DESC LIMIT 1", settlementEpochTable)
+	if err := n.db.Get(&out, query); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadSettlementEpoch load the settlement epoch
+func (n *SQLDB) LoadSettlementEpoch(epoch int64) (*types.SettlementEpoch, error) {
+	var out types.SettlementEpoch
+	query := fmt.Sprintf("SELECT * FROM %s WHERE epoch=?", settlementEpochTable)
+	if err := n.db.Get(&out, query, epoch); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// LoadSettlementEpochs load the settlement epochs, the latest first
+func (n *SQLDB) LoadSettlementEpochs(limit, offset int) (*types.ListSettlementEpochRsp, error) {
+	res := new(types.ListSettlementEpochRsp)
+
+	if limit > loadSettlementDefaultLimit || limit <= 0 {
+		limit = loadSettlementDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", settlementEpochTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY epoch DESC LIMIT ? OFFSET ?", settlementEpochTable)
+	if err := n.db.Select(&res.Data, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadSettlementShares load the shares of the accounts in the epoch
+func (n *SQLDB) LoadSettlementShares(epoch int64, limit, offset int) (*types.ListSettlementShareRsp, error) {
+	res := new(types.ListSettlementShareRsp)
+
+	if limit > loadSettlementDefaultLimit || limit <= 0 {
+		limit = loadSettlementDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE epoch=?", settlementShareTable)
+	if err := n.db.Get(&res.Total, query, epoch); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE epoch=? ORDER BY account_id LIMIT ? OFFSET ?", settlementShareTable)
+	if err := n.db.Select(&res.Data, query, epoch, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadAllSettlementShares load all shares of the accounts in the epoch
+func (n *SQLDB) LoadAllSettlementShares(epoch int64) ([]*types.SettlementShare, error) {
+	var out []*types.SettlementShare
+	query := fmt.Sprintf("SELECT * FROM %s WHERE epoch=?", settlementShareTable)
+	if err := n.db.Select(&out, query, epoch); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -25,6 +26,12 @@ func NewSQLDB(db *sqlx.DB) (*SQLDB, error) {
 	return s, nil
 }
 
+// Ping runs a trivial query, its duration includes the wait for a free connection
+func (n *SQLDB) Ping(ctx context.Context) error {
+	var one int
+	return n.db.GetContext(ctx, &one, "SELECT 1")
+}
+
 const (
 	// Database table names.
 	assetRecordTable      = "asset_record"
@@ -45,6 +52,85 @@ const (
 	replenishBackupTable  = "replenish_backup"
 	userAssetGroupTable   = "user_asset_group"
 	awsDataTable          = "aws_data"
+	hardwareProofTable    = "hardware_proof"
+	accountNodeTable      = "account_node"
+	accountNotifyTable    = "account_notification"
+	alertSubscribeTable   = "alert_subscription"
+	apiTokenTable         = "api_token"
+	tokenSubjectTable     = "token_subject"
+	nodeLoginTable        = "node_login"
+	nodeLoginNonceTable   = "node_login_nonce"
+	auditLogTable         = "audit_log"
+	statsCounterTable     = "stats_counter"
+	s3AccessKeyTable      = "s3_access_key"
+	s3ObjectTable         = "s3_object"
+	relaySessionTable     = "relay_session"
+	nodeTrafficDailyTable = "node_traffic_daily"
+	nodeTrafficMonthTable = "node_traffic_monthly"
+	settlementEpochTable  = "settlement_epoch"
+	settlementShareTable  = "settlement_share"
+	settlementPointsTable = "settlement_points"
+	penaltyRuleTable      = "penalty_rule"
+	penaltyTable          = "penalty"
+	commitmentTable       = "node_commitment"
+	commitmentRecordTable = "node_commitment_record"
+	nodeStatsDailyTable   = "node_stats_daily"
+	decisionTable         = "scheduling_decision"
+	decisionNodeTable     = "scheduling_decision_node"
+	outboxTable           = "outbox"
+	uploadLimitTable      = "upload_limit"
+	denylistTable         = "denylist"
+	denylistEventTable    = "denylist_event"
+	assetEncryptionTable  = "user_asset_encryption"
+	assetACLTable         = "user_asset_acl"
+	gatewayAssetTable     = "gateway_asset"
+	gatewayCertTable      = "gateway_certificate"
+	acmeAccountTable      = "acme_account"
+	bandwidthTestTable    = "bandwidth_test"
+	nodeDiagnosticsTable  = "node_diagnostics"
+	releaseManifestTable  = "release_manifest"
+	upgradeRolloutTable   = "upgrade_rollout"
+	upgradeNodeTable      = "upgrade_node"
+	nodeConfigTable       = "node_config"
+	nodeConfigAckTable    = "node_config_ack"
+	featureFlagTable      = "feature_flag"
+	retrievalProbeTable   = "retrieval_probe"
+	segmentStatsTable     = "segment_stats"
+	workloadReceiptTable  = "workload_receipt"
+	pointsEpochCloseTable = "points_epoch_close"
+	pointsEpochTable      = "points_epoch"
+	pointsEpochTotalTable = "points_epoch_total"
+	pointsCorrectionTable = "points_correction"
+	maintenanceTable      = "node_maintenance"
+	bulkJobTable          = "bulk_job"
+	bulkJobItemTable      = "bulk_job_item"
+	jobQueueTable         = "job_queue"
+	nodeArchiveTable      = "node_archive"
+	nodeInfoArchive       = "node_info_archive"
+	nodeRegisterArchive   = "node_register_info_archive"
+	dataExportTable       = "data_export"
+	assetGeoPolicyTable   = "asset_geo_policy"
+	geoViolationTable     = "asset_geo_violation"
+	storageProofTable     = "storage_proof"
+	reportRejectionTable  = "report_rejection"
+	nodeTaskTable         = "node_task"
+	sandboxNodeTable      = "sandbox_node"
+	tenantTable           = "tenant"
+	tenantUserTable       = "tenant_user"
+	tenantUsageTable      = "tenant_usage_daily"
+	tenantQuotaTable      = "tenant_quota"
+	webhookTable          = "asset_webhook"
+	webhookDeliveryTable  = "webhook_delivery"
+	webhookCursorTable    = "webhook_cursor"
+	userAssetLabelTable   = "user_asset_label"
+	coldAssetTable        = "cold_asset"
+	assetAccessTable      = "asset_access"
+	replicationTable      = "replication_agreement"
+	mirroredAssetTable    = "mirrored_asset"
+	nodeTrustTable        = "node_trust"
+	trustTransitionTable  = "node_trust_transition"
+	validationProofTable  = "validation_proof"
+	spotCheckTable        = "spot_check"
 
 	// Default limits for loading table entries.
 	loadNodeInfosDefaultLimit           = 1000
@@ -56,6 +142,36 @@ const (
 	loadRetrieveDefaultLimit            = 100
 	loadReplicaDefaultLimit             = 100
 	loadUserDefaultLimit                = 100
+	loadLeaderboardDefaultLimit         = 100
+	loadTokenSubjectDefaultLimit        = 500
+	loadAuditLogsDefaultLimit           = 500
+	loadS3ObjectsDefaultLimit           = 1000
+	loadRelaySessionsDefaultLimit       = 500
+	loadTrafficStatementsDefaultLimit   = 1000
+	loadSettlementDefaultLimit          = 1000
+	loadPenaltiesDefaultLimit           = 500
+	loadDecisionsDefaultLimit           = 500
+	loadCommitmentRecordsDefaultLimit   = 500
+	loadDenylistDefaultLimit            = 1000
+	loadBandwidthTestsDefaultLimit      = 100
+	loadNodeDiagnosticsDefaultLimit     = 100
+	loadUpgradeRolloutsDefaultLimit     = 100
+	loadUpgradeNodesDefaultLimit        = 1000
+	loadRetrievalProbesDefaultLimit     = 500
+	loadSegmentStatsDefaultLimit        = 500
+	loadWorkloadReceiptsDefaultLimit    = 500
+	loadPointsEpochsDefaultLimit        = 500
+	loadMaintenanceWindowsDefaultLimit  = 500
+	loadBulkJobsDefaultLimit            = 500
+	loadBulkJobItemsDefaultLimit        = 1000
+	loadJobsDefaultLimit                = 500
+	loadArchivedNodesDefaultLimit       = 500
+	loadDataExportsDefaultLimit         = 500
+	loadAssetGeoPoliciesDefaultLimit    = 500
+	loadAssetGeoViolationsDefaultLimit  = 500
+	loadStorageProofsDefaultLimit       = 500
+	loadNodeTasksDefaultLimit           = 500
+	loadTenantUsersDefaultLimit         = 1000
 )
 
 // assetStateTable returns the asset state table name for the given serverID.
@@ -99,6 +215,119 @@ func InitTables(d *SQLDB, serverID dtypes.ServerID) error {
 	tx.MustExec(fmt.Sprintf(cReplenishBackupTable, replenishBackupTable))
 	tx.MustExec(fmt.Sprintf(cUserAssetGroupTable, userAssetGroupTable))
 	tx.MustExec(fmt.Sprintf(cAWSDataTable, awsDataTable))
+	tx.MustExec(fmt.Sprintf(cHardwareProofTable, hardwareProofTable))
+	tx.MustExec(fmt.Sprintf(cAccountNodeTable, accountNodeTable))
+	tx.MustExec(fmt.Sprintf(cAccountNotificationTable, accountNotifyTable))
+	tx.MustExec(fmt.Sprintf(cAlertSubscriptionTable, alertSubscribeTable))
+	tx.MustExec(fmt.Sprintf(cAPITokenTable, apiTokenTable))
+	tx.MustExec(fmt.Sprintf(cTokenSubjectTable, tokenSubjectTable))
+	tx.MustExec(fmt.Sprintf(cNodeLoginTable, nodeLoginTable))
+	tx.MustExec(fmt.Sprintf(cNodeLoginNonceTable, nodeLoginNonceTable))
+	tx.MustExec(fmt.Sprintf(cAuditLogTable, auditLogTable))
+	tx.MustExec(fmt.Sprintf(cStatsCounterTable, statsCounterTable))
+	tx.MustExec(fmt.Sprintf(cS3AccessKeyTable, s3AccessKeyTable))
+	tx.MustExec(fmt.Sprintf(cS3ObjectTable, s3ObjectTable))
+	tx.MustExec(fmt.Sprintf(cRelaySessionTable, relaySessionTable))
+	tx.MustExec(fmt.Sprintf(cNodeTrafficDailyTable, nodeTrafficDailyTable))
+	tx.MustExec(fmt.Sprintf(cNodeTrafficMonthlyTable, nodeTrafficMonthTable))
+	tx.MustExec(fmt.Sprintf(cSettlementEpochTable, settlementEpochTable))
+	tx.MustExec(fmt.Sprintf(cSettlementShareTable, settlementShareTable))
+	tx.MustExec(fmt.Sprintf(cSettlementPointsTable, settlementPointsTable))
+	tx.MustExec(fmt.Sprintf(cPenaltyRuleTable, penaltyRuleTable))
+	tx.MustExec(fmt.Sprintf(cPenaltyTable, penaltyTable))
+	tx.MustExec(fmt.Sprintf(cNodeCommitmentTable, commitmentTable))
+	tx.MustExec(fmt.Sprintf(cNodeCommitmentRecordTable, commitmentRecordTable))
+	tx.MustExec(fmt.Sprintf(cNodeStatsDailyTable, nodeStatsDailyTable))
+	tx.MustExec(fmt.Sprintf(cSchedulingDecisionTable, decisionTable))
+	tx.MustExec(fmt.Sprintf(cSchedulingDecisionNodeTable, decisionNodeTable))
+	tx.MustExec(fmt.Sprintf(cOutboxTable, outboxTable))
+	tx.MustExec(fmt.Sprintf(cUploadLimitTable, uploadLimitTable))
+	tx.MustExec(fmt.Sprintf(cDenylistTable, denylistTable))
+	tx.MustExec(fmt.Sprintf(cDenylistEventTable, denylistEventTable))
+	tx.MustExec(fmt.Sprintf(cAssetEncryptionTable, assetEncryptionTable))
+	tx.MustExec(fmt.Sprintf(cAssetACLTable, assetACLTable))
+	tx.MustExec(fmt.Sprintf(cGatewayAssetTable, gatewayAssetTable))
+	tx.MustExec(fmt.Sprintf(cGatewayCertTable, gatewayCertTable))
+	tx.MustExec(fmt.Sprintf(cACMEAccountTable, acmeAccountTable))
+	tx.MustExec(fmt.Sprintf(cBandwidthTestTable, bandwidthTestTable))
+	tx.MustExec(fmt.Sprintf(cNodeDiagnosticsTable, nodeDiagnosticsTable))
+	tx.MustExec(fmt.Sprintf(cReleaseManifestTable, releaseManifestTable))
+	tx.MustExec(fmt.Sprintf(cUpgradeRolloutTable, upgradeRolloutTable))
+	tx.MustExec(fmt.Sprintf(cUpgradeNodeTable, upgradeNodeTable))
+	tx.MustExec(fmt.Sprintf(cNodeConfigTable, nodeConfigTable))
+	tx.MustExec(fmt.Sprintf(cNodeConfigAckTable, nodeConfigAckTable))
+	tx.MustExec(fmt.Sprintf(cFeatureFlagTable, featureFlagTable))
+	tx.MustExec(fmt.Sprintf(cRetrievalProbeTable, retrievalProbeTable))
+	tx.MustExec(fmt.Sprintf(cSegmentStatsTable, segmentStatsTable))
+	tx.MustExec(fmt.Sprintf(cWorkloadReceiptTable, workloadReceiptTable))
+	tx.MustExec(fmt.Sprintf(cPointsEpochCloseTable, pointsEpochCloseTable))
+	tx.MustExec(fmt.Sprintf(cPointsEpochTable, pointsEpochTable))
+	tx.MustExec(fmt.Sprintf(cPointsEpochTotalTable, pointsEpochTotalTable))
+	tx.MustExec(fmt.Sprintf(cPointsCorrectionTable, pointsCorrectionTable))
+	tx.MustExec(fmt.Sprintf(cMaintenanceTable, maintenanceTable))
+	tx.MustExec(fmt.Sprintf(cBulkJobTable, bulkJobTable))
+	tx.MustExec(fmt.Sprintf(cBulkJobItemTable, bulkJobItemTable))
+	tx.MustExec(fmt.Sprintf(cJobQueueTable, jobQueueTable))
+	tx.MustExec(fmt.Sprintf(cNodeArchiveTable, nodeArchiveTable))
+	tx.MustExec(fmt.Sprintf(cDataExportTable, dataExportTable))
+	tx.MustExec(fmt.Sprintf(cAssetGeoPolicyTable, assetGeoPolicyTable))
+	tx.MustExec(fmt.Sprintf(cAssetGeoViolationTable, geoViolationTable))
+	tx.MustExec(fmt.Sprintf(cStorageProofTable, storageProofTable))
+	tx.MustExec(fmt.Sprintf(cReportRejectionTable, reportRejectionTable))
+	tx.MustExec(fmt.Sprintf(cNodeTaskTable, nodeTaskTable))
+	tx.MustExec(fmt.Sprintf(cSandboxNodeTable, sandboxNodeTable))
+	tx.MustExec(fmt.Sprintf(cTenantTable, tenantTable))
+	tx.MustExec(fmt.Sprintf(cTenantUserTable, tenantUserTable))
+	tx.MustExec(fmt.Sprintf(cTenantUsageTable, tenantUsageTable))
+	tx.MustExec(fmt.Sprintf(cTenantQuotaTable, tenantQuotaTable))
+	tx.MustExec(fmt.Sprintf(cWebhookTable, webhookTable))
+	tx.MustExec(fmt.Sprintf(cWebhookDeliveryTable, webhookDeliveryTable))
+	tx.MustExec(fmt.Sprintf(cWebhookCursorTable, webhookCursorTable))
+	tx.MustExec(fmt.Sprintf(cUserAssetLabelTable, userAssetLabelTable))
+	tx.MustExec(fmt.Sprintf(cColdAssetTable, coldAssetTable))
+	tx.MustExec(fmt.Sprintf(cAssetAccessTable, assetAccessTable))
+	tx.MustExec(fmt.Sprintf(cReplicationTable, replicationTable))
+	tx.MustExec(fmt.Sprintf(cMirroredAssetTable, mirroredAssetTable))
+	tx.MustExec(fmt.Sprintf(cNodeTrustTable, nodeTrustTable))
+	tx.MustExec(fmt.Sprintf(cTrustTransitionTable, trustTransitionTable))
+	tx.MustExec(fmt.Sprintf(cValidationProofTable, validationProofTable))
+	tx.MustExec(fmt.Sprintf(cSpotCheckTable, spotCheckTable))
+
+	if err = migratePointColumns(tx); err != nil {
+		return err
+	}
+
+	if err = migrateColumns(tx, assetRecordTable, assetRecordColumns); err != nil {
+		return err
+	}
+
+	if err = migrateAssetOwnerCount(tx); err != nil {
+		return err
+	}
+
+	if err = migrateColumns(tx, alertSubscribeTable, alertSubscriptionColumns); err != nil {
+		return err
+	}
+
+	if err = migrateColumns(tx, tenantTable, tenantColumns); err != nil {
+		return err
+	}
+
+	if err = migrateColumns(tx, userAssetTable, userAssetColumns); err != nil {
+		return err
+	}
+
+	if err = migrateIndexes(tx, userAssetTable, userAssetIndexes); err != nil {
+		return err
+	}
+
+	if err = initTenantQuotas(tx); err != nil {
+		return err
+	}
+
+	// the archive tables are created after the migrations of the tables they archive
+	tx.MustExec(fmt.Sprintf(cArchiveTable, nodeInfoArchive, nodeInfoTable))
+	tx.MustExec(fmt.Sprintf(cArchiveTable, nodeRegisterArchive, nodeRegisterTable))
 
 	return tx.Commit()
 }
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// names of the statistics counters, the counters are shared by the schedulers of the region
+const (
+	replicaCountCounter  = "replica_count"
+	trafficServedCounter = "traffic_served"
+)
+
+// incrCounter adds n to the counter in the transaction of the change it counts
+func incrCounter(tx sqlx.Execer, name string, n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (name, value) VALUES (?, ?) ON DUPLICATE KEY UPDATE value=value+?`, statsCounterTable)
+	_, err := tx.Exec(query, name, n, n)
+	return err
+}
+
+// countersQuery returns the statement that computes the counters from the replicas and retrieve events
+func countersQuery(verb string) string {
+	return fmt.Sprintf(`%s INTO %s (name, value)
+		SELECT ?, COUNT(*) FROM %s WHERE status=?
+		UNION ALL
+		SELECT ?, IFNULL(SUM(size),0) FROM %s`, verb, statsCounterTable, replicaInfoTable, retrieveEventTable)
+}
+
+// InitStatsCounters computes the counters that do not exist yet
+func (n *SQLDB) InitStatsCounters() error {
+	_, err := n.db.Exec(countersQuery("INSERT IGNORE"), replicaCountCounter, types.ReplicaStatusSucceeded, trafficServedCounter)
+	return err
+}
+
+// ResetStatsCounters recomputes the counters, it corrects the drift of the counters from the changes they miss
+func (n *SQLDB) ResetStatsCounters() error {
+	_, err := n.db.Exec(countersQuery("REPLACE"), replicaCountCounter, types.ReplicaStatusSucceeded, trafficServedCounter)
+	return err
+}
+
+// LoadStatsCounters load the replica count and the traffic served of the region
+func (n *SQLDB) LoadStatsCounters() (replicaCount, trafficServed int64, err error) {
+	var counters []struct {
+		Name  string `db:"name"`
+		Value int64  `db:"value"`
+	}
+
+	query := fmt.Sprintf(`SELECT name, value FROM %s`, statsCounterTable)
+	if err := n.db.Select(&counters, query); err != nil {
+		return 0, 0, err
+	}
+
+	for _, c := range counters {
+		switch c.Name {
+		case replicaCountCounter:
+			replicaCount = c.Value
+		case trafficServedCounter:
+			trafficServed = c.Value
+		}
+	}
+
+	return replicaCount, trafficServed, nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveStorageProof saves the latest storage proof of the replica, the failures of the proof are counted
+// from the failures of the previous proof and reset by a passed proof
+func (n *SQLDB) SaveStorageProof(proof *types.StorageProof) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveStorageProof Rollback err:%s", err.Error())
+		}
+	}()
+
+	var failures int
+	query := fmt.Sprintf("SELECT failures FROM %s WHERE hash=? AND node_id=? FOR UPDATE", storageProofTable)
+	if err = tx.Get(&failures, query, proof.Hash, proof.NodeID); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	proof.Failures = 0
+	if !proof.Passed {
+		proof.Failures = failures + 1
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (hash, node_id, cid, passed, failures, checker, duration, message, proof_time)
+			VALUES (:hash, :node_id, :cid, :passed, :failures, :checker, :duration, :message, :proof_time)
+			ON DUPLICATE KEY UPDATE passed=VALUES(passed), failures=VALUES(failures), checker=VALUES(checker),
+			duration=VALUES(duration), message=VALUES(message), proof_time=VALUES(proof_time)`, storageProofTable)
+	if _, err = tx.NamedExec(query, proof); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteStorageProof deletes the storage proof of the replica
+func (n *SQLDB) DeleteStorageProof(hash, nodeID string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE hash=? AND node_id=?", storageProofTable)
+	_, err := n.db.Exec(query, hash, nodeID)
+	return err
+}
+
+// LoadStorageProofs loads the storage proofs of the replicas of the node, the latest first
+func (n *SQLDB) LoadStorageProofs(nodeID string, limit, offset int) (*types.ListStorageProofRsp, error) {
+	res := new(types.ListStorageProofRsp)
+
+	if limit > loadStorageProofsDefaultLimit || limit <= 0 {
+		limit = loadStorageProofsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", storageProofTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY proof_time DESC LIMIT ? OFFSET ?", storageProofTable)
+	if err := n.db.Select(&res.Proofs, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
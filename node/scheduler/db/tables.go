@@ -44,7 +44,7 @@ var cNodeInfoTable = `
 	    scheduler_sid        VARCHAR(128)    NOT NULL,
 		first_login_time     DATETIME        DEFAULT CURRENT_TIMESTAMP,
 	    online_duration      INT             DEFAULT 0,
-	    profit               DECIMAL(14, 6)  DEFAULT 0,
+	    profit               DECIMAL(38, 12) DEFAULT 0,
 	    last_seen            DATETIME        DEFAULT CURRENT_TIMESTAMP,
 	    disk_usage           FLOAT           DEFAULT 0,
     	upload_traffic       BIGINT          DEFAULT 0,
@@ -68,7 +68,7 @@ var cValidationResultsTable = `
 	    bandwidth         FLOAT          DEFAULT 0,
 	    start_time        DATETIME       DEFAULT NULL,
 	    end_time          DATETIME       DEFAULT NULL,
-		profit            DECIMAL(14, 6) DEFAULT 0,
+		profit            DECIMAL(38, 12) DEFAULT 0,
 		calculated_profit BOOLEAN,
 		token_id          VARCHAR(128)   DEFAULT '',
 		file_saved        BOOLEAN,
@@ -105,6 +105,9 @@ var cAssetRecordTable = `
 		end_time           DATETIME     DEFAULT CURRENT_TIMESTAMP,
 		bandwidth          INT          DEFAULT 0,
 		note               VARCHAR(128) DEFAULT '',
+		qos_tier           VARCHAR(16)  DEFAULT 'standard',
+		video_format       VARCHAR(8)   DEFAULT '',
+		owner_count        INT          DEFAULT 0,
 		PRIMARY KEY (hash)
 	) ENGINE=InnoDB COMMENT='asset record';`
 
@@ -173,9 +176,13 @@ var cUserAssetTable = `
 		expiration        DATETIME     DEFAULT CURRENT_TIMESTAMP,
 		password          VARCHAR(128) DEFAULT '' ,		
 		group_id          INT          DEFAULT 0,
+		content_type      VARCHAR(128) DEFAULT '' ,
 		PRIMARY KEY (hash,user_id),
 		KEY idx_user_id (user_id),
-		KEY idx_group_id (group_id)
+		KEY idx_group_id (group_id),
+		KEY idx_user_name (user_id, asset_name),
+		KEY idx_user_created (user_id, created_time),
+		KEY idx_user_size (user_id, total_size)
     ) ENGINE=InnoDB COMMENT='user asset';`
 
 var cUserInfoTable = `
@@ -215,7 +222,7 @@ var cRetrieveEventTable = `
 		size            INT            DEFAULT 0,
 		created_time    INT            DEFAULT 0,
 		end_time        INT            DEFAULT 0,
-	    profit          DECIMAL(14, 6) DEFAULT 0,
+	    profit          DECIMAL(38, 12) DEFAULT 0,
 		PRIMARY KEY (token_id),
 		KEY idx_node_id (node_id),
 		KEY idx_created_time (created_time)
@@ -256,3 +263,960 @@ var cAWSDataTable = `
 		size            FLOAT        DEFAULT 0,
 		PRIMARY KEY (bucket)
     ) ENGINE=InnoDB COMMENT='aws data';`
+
+var cHardwareProofTable = `
+    CREATE TABLE if not exists %s (
+	    node_id          VARCHAR(128) NOT NULL,
+		passed           BOOLEAN      DEFAULT false,
+		disk_size        BIGINT       DEFAULT 0,
+		disk_duration    BIGINT       DEFAULT 0,
+		memory_size      BIGINT       DEFAULT 0,
+		memory_bandwidth FLOAT        DEFAULT 0,
+		proof_time       DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id)
+    ) ENGINE=InnoDB COMMENT='hardware challenge proof of node';`
+
+var cAccountNodeTable = `
+    CREATE TABLE if not exists %s (
+	    node_id     VARCHAR(128) NOT NULL,
+	    account_id  VARCHAR(128) NOT NULL,
+		bind_time   DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id),
+	    KEY idx_account_id (account_id)
+    ) ENGINE=InnoDB COMMENT='nodes bound to operator accounts';`
+
+var cAccountNotificationTable = `
+    CREATE TABLE if not exists %s (
+	    account_id       VARCHAR(128) NOT NULL,
+		offline_alert    BOOLEAN      DEFAULT false,
+		offline_minutes  INT          DEFAULT 0,
+		email            VARCHAR(128) DEFAULT '',
+		webhook          VARCHAR(256) DEFAULT '',
+		updated_time     DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (account_id)
+    ) ENGINE=InnoDB COMMENT='notification preferences of operator accounts';`
+
+var cAlertSubscriptionTable = `
+    CREATE TABLE if not exists %s (
+		id                   INT UNSIGNED AUTO_INCREMENT,
+	    account_id           VARCHAR(128) NOT NULL,
+	    node_id              VARCHAR(128) DEFAULT '',
+		offline_minutes      INT          DEFAULT 0,
+		validation_failures  INT          DEFAULT 0,
+		disk_usage_threshold FLOAT        DEFAULT 0,
+		disk_failure         BOOLEAN      DEFAULT false,
+		webhook              VARCHAR(256) DEFAULT '',
+		email                VARCHAR(128) DEFAULT '',
+		created_time         DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+	    KEY idx_account_id (account_id)
+    ) ENGINE=InnoDB COMMENT='alert subscriptions of operator accounts';`
+
+var cAPITokenTable = `
+    CREATE TABLE if not exists %s (
+	    id           VARCHAR(128) NOT NULL,
+	    name         VARCHAR(128) DEFAULT '',
+		role         VARCHAR(32)  NOT NULL,
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id)
+    ) ENGINE=InnoDB COMMENT='api tokens and their roles';`
+
+var cTokenSubjectTable = `
+    CREATE TABLE if not exists %s (
+	    subject    VARCHAR(128) NOT NULL,
+	    role       VARCHAR(32)  DEFAULT '',
+		issued_at  BIGINT       DEFAULT 0,
+		revoked_at BIGINT       DEFAULT 0,
+		PRIMARY KEY (subject),
+	    KEY idx_role (role)
+    ) ENGINE=InnoDB COMMENT='issuing and revocation state of tokens';`
+
+var cNodeLoginTable = `
+    CREATE TABLE if not exists %s (
+	    node_id         VARCHAR(128) NOT NULL,
+		last_login_time BIGINT       DEFAULT 0,
+		PRIMARY KEY (node_id)
+    ) ENGINE=InnoDB COMMENT='last logins of nodes';`
+
+var cNodeLoginNonceTable = `
+    CREATE TABLE if not exists %s (
+	    node_id     VARCHAR(128) NOT NULL,
+	    nonce       VARCHAR(64)  NOT NULL,
+		expire_time BIGINT       DEFAULT 0,
+		PRIMARY KEY (node_id, nonce)
+    ) ENGINE=InnoDB COMMENT='login challenges of nodes';`
+
+var cAuditLogTable = `
+    CREATE TABLE if not exists %s (
+	    id           BIGINT        NOT NULL AUTO_INCREMENT,
+	    actor        VARCHAR(128)  DEFAULT '',
+	    role         VARCHAR(64)   DEFAULT '',
+		method       VARCHAR(128)  NOT NULL,
+		args         TEXT,
+		result       VARCHAR(1024) DEFAULT '',
+		success      BOOLEAN       DEFAULT false,
+		created_time DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+	    KEY idx_actor (actor),
+	    KEY idx_method (method),
+	    KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='audit logs of admin mutations';`
+
+var cStatsCounterTable = `
+    CREATE TABLE if not exists %s (
+	    name  VARCHAR(64) NOT NULL,
+	    value BIGINT      DEFAULT 0,
+		PRIMARY KEY (name)
+    ) ENGINE=InnoDB COMMENT='statistics counters of the region';`
+
+var cS3AccessKeyTable = `
+    CREATE TABLE if not exists %s (
+	    access_key   VARCHAR(32)  NOT NULL,
+	    secret_key   VARCHAR(64)  NOT NULL,
+		user_id      VARCHAR(128) NOT NULL,
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (access_key),
+	    KEY idx_user_id (user_id)
+    ) ENGINE=InnoDB COMMENT='access keys of the s3 gateway';`
+
+var cS3ObjectTable = `
+    CREATE TABLE if not exists %s (
+	    user_id      VARCHAR(128) NOT NULL,
+	    bucket       VARCHAR(64)  NOT NULL,
+		object_key   VARCHAR(255) NOT NULL,
+		cid          VARCHAR(128) NOT NULL,
+		size         BIGINT       DEFAULT 0,
+		car_size     BIGINT       DEFAULT 0,
+		etag         VARCHAR(64)  DEFAULT '',
+		content_type VARCHAR(128) DEFAULT '',
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, bucket, object_key)
+    ) ENGINE=InnoDB COMMENT='objects of the s3 gateway';`
+
+var cRelaySessionTable = `
+    CREATE TABLE if not exists %s (
+	    id            VARCHAR(128) NOT NULL,
+	    edge_id       VARCHAR(128) NOT NULL,
+		candidate_id  VARCHAR(128) NOT NULL,
+		relayed_bytes BIGINT       DEFAULT 0,
+		created_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		updated_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+	    KEY idx_edge_id (edge_id),
+	    KEY idx_candidate_id (candidate_id)
+    ) ENGINE=InnoDB COMMENT='relay sessions of the edges behind symmetric nat';`
+
+var cNodeTrafficDailyTable = `
+    CREATE TABLE if not exists %s (
+	    node_id        VARCHAR(128) NOT NULL,
+	    date           DATE         NOT NULL,
+		upload_bytes   BIGINT       DEFAULT 0,
+		download_bytes BIGINT       DEFAULT 0,
+		PRIMARY KEY (node_id, date),
+	    KEY idx_date (date)
+    ) ENGINE=InnoDB COMMENT='daily traffic of nodes';`
+
+var cNodeTrafficMonthlyTable = `
+    CREATE TABLE if not exists %s (
+	    node_id        VARCHAR(128) NOT NULL,
+	    month          CHAR(7)      NOT NULL,
+		upload_bytes   BIGINT       DEFAULT 0,
+		download_bytes BIGINT       DEFAULT 0,
+		active_days    INT          DEFAULT 0,
+		PRIMARY KEY (month, node_id),
+	    KEY idx_node_id (node_id)
+    ) ENGINE=InnoDB COMMENT='monthly traffic statements of nodes';`
+
+var cSettlementEpochTable = `
+    CREATE TABLE if not exists %s (
+	    epoch          BIGINT         NOT NULL,
+	    start_time     DATETIME       NOT NULL,
+	    end_time       DATETIME       NOT NULL,
+		total_points   DECIMAL(38, 12) DEFAULT 0,
+		total_reward   DECIMAL(20, 6) DEFAULT 0,
+		account_count  INT            DEFAULT 0,
+		merkle_root    VARCHAR(64)    DEFAULT '',
+		signature      TEXT           NOT NULL,
+		exported       BOOLEAN        DEFAULT false,
+		created_time   DATETIME       DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (epoch)
+    ) ENGINE=InnoDB COMMENT='settlement epochs of points';`
+
+var cSettlementShareTable = `
+    CREATE TABLE if not exists %s (
+	    epoch       BIGINT         NOT NULL,
+	    account_id  VARCHAR(128)   NOT NULL,
+		points      DECIMAL(38, 12) DEFAULT 0,
+		share       DECIMAL(20, 12) DEFAULT 0,
+		amount      DECIMAL(20, 6) DEFAULT 0,
+		PRIMARY KEY (epoch, account_id),
+	    KEY idx_account_id (account_id)
+    ) ENGINE=InnoDB COMMENT='shares of accounts in settlement epochs';`
+
+var cSettlementPointsTable = `
+    CREATE TABLE if not exists %s (
+	    epoch    BIGINT         NOT NULL,
+	    node_id  VARCHAR(128)   NOT NULL,
+		points   DECIMAL(38, 12) DEFAULT 0,
+		PRIMARY KEY (epoch, node_id)
+    ) ENGINE=InnoDB COMMENT='points of nodes frozen at the end of settlement epochs';`
+
+var cPenaltyRuleTable = `
+    CREATE TABLE if not exists %s (
+	    id                    BIGINT         NOT NULL AUTO_INCREMENT,
+	    name                  VARCHAR(64)    DEFAULT '',
+	    rule_type             VARCHAR(32)    NOT NULL,
+		threshold             INT            DEFAULT 0,
+		committed_start_hour  INT            DEFAULT 0,
+		committed_end_hour    INT            DEFAULT 0,
+		deduct_points         DECIMAL(38, 12) DEFAULT 0,
+		freeze_hours          INT            DEFAULT 0,
+		enabled               BOOLEAN        DEFAULT true,
+		applied_count         BIGINT         DEFAULT 0,
+		last_applied_time     DATETIME       DEFAULT CURRENT_TIMESTAMP,
+		created_time          DATETIME       DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id)
+    ) ENGINE=InnoDB COMMENT='penalty rules';`
+
+var cPenaltyTable = `
+    CREATE TABLE if not exists %s (
+	    id               BIGINT         NOT NULL AUTO_INCREMENT,
+	    node_id          VARCHAR(128)   NOT NULL,
+	    rule_id          BIGINT         NOT NULL,
+	    rule_type        VARCHAR(32)    NOT NULL,
+		reason           VARCHAR(256)   DEFAULT '',
+		deducted_points  DECIMAL(38, 12) DEFAULT 0,
+		frozen_until     DATETIME       NOT NULL,
+		appeal           VARCHAR(16)    DEFAULT '',
+		appeal_reason    VARCHAR(512)   DEFAULT '',
+		created_time     DATETIME       NOT NULL,
+		PRIMARY KEY (id),
+	    KEY idx_node_id (node_id),
+	    KEY idx_frozen_until (frozen_until)
+    ) ENGINE=InnoDB COMMENT='penalties applied to nodes';`
+
+var cNodeCommitmentTable = `
+    CREATE TABLE if not exists %s (
+	    node_id       VARCHAR(128) NOT NULL,
+	    start_hour    INT          NOT NULL,
+	    end_hour      INT          NOT NULL,
+		created_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id)
+    ) ENGINE=InnoDB COMMENT='daily online windows committed by nodes';`
+
+var cNodeCommitmentRecordTable = `
+    CREATE TABLE if not exists %s (
+	    node_id          VARCHAR(128)   NOT NULL,
+	    window_start     DATETIME       NOT NULL,
+	    window_end       DATETIME       NOT NULL,
+		checked_minutes  INT            DEFAULT 0,
+		present_minutes  INT            DEFAULT 0,
+		kept             BOOLEAN        DEFAULT false,
+		earned_points    DECIMAL(38, 12) DEFAULT 0,
+		bonus_points     DECIMAL(38, 12) DEFAULT 0,
+		PRIMARY KEY (node_id, window_start)
+    ) ENGINE=InnoDB COMMENT='presence of nodes in their commitment windows';`
+
+var cNodeStatsDailyTable = `
+    CREATE TABLE if not exists %s (
+	    node_id          VARCHAR(128)   NOT NULL,
+	    date             DATE           NOT NULL,
+		points           DECIMAL(38, 12) DEFAULT 0,
+		online_duration  INT            DEFAULT 0,
+		PRIMARY KEY (date, node_id)
+    ) ENGINE=InnoDB COMMENT='cumulative points and online minutes of nodes at the start of days';`
+
+var cSchedulingDecisionTable = `
+    CREATE TABLE if not exists %s (
+	    id            BIGINT       NOT NULL AUTO_INCREMENT,
+	    kind          VARCHAR(32)  NOT NULL,
+	    subject       VARCHAR(128) DEFAULT '',
+	    params        VARCHAR(256) DEFAULT '',
+	    considered    INT          DEFAULT 0,
+	    chosen        INT          DEFAULT 0,
+	    truncated     BOOLEAN      DEFAULT false,
+	    detail        MEDIUMBLOB,
+		created_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_kind_time (kind, created_time),
+		KEY idx_subject (subject),
+		KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='nodes considered by scheduling decisions';`
+
+var cSchedulingDecisionNodeTable = `
+    CREATE TABLE if not exists %s (
+	    node_id      VARCHAR(128) NOT NULL,
+	    decision_id  BIGINT       NOT NULL,
+		PRIMARY KEY (node_id, decision_id),
+		KEY idx_decision_id (decision_id)
+    ) ENGINE=InnoDB COMMENT='nodes chosen by scheduling decisions';`
+
+var cOutboxTable = `
+    CREATE TABLE if not exists %s (
+	    id            BIGINT       NOT NULL AUTO_INCREMENT,
+	    topic         VARCHAR(32)  NOT NULL,
+	    subject       VARCHAR(128) DEFAULT '',
+	    payload       BLOB,
+	    dispatched    BOOLEAN      DEFAULT false,
+	    attempts      INT          DEFAULT 0,
+		created_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_dispatched (dispatched, id),
+		KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='events delivered to the external consumers';`
+
+var cUploadLimitTable = `
+    CREATE TABLE if not exists %s (
+	    node_id      VARCHAR(128) NOT NULL,
+	    upload_limit BIGINT       DEFAULT 0,
+		updated_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id)
+    ) ENGINE=InnoDB COMMENT='upload limits of the nodes set by the scheduler';`
+
+var cDenylistTable = `
+    CREATE TABLE if not exists %s (
+	    hash         VARCHAR(128) NOT NULL,
+	    cid          VARCHAR(128) NOT NULL,
+	    reason       VARCHAR(256) DEFAULT '',
+	    source       VARCHAR(128) DEFAULT '',
+	    actor        VARCHAR(128) DEFAULT '',
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash),
+		KEY idx_source (source)
+    ) ENGINE=InnoDB COMMENT='assets banned from pulling and retrieval';`
+
+var cDenylistEventTable = `
+    CREATE TABLE if not exists %s (
+	    id           BIGINT       NOT NULL AUTO_INCREMENT,
+	    hash         VARCHAR(128) NOT NULL,
+	    cid          VARCHAR(128) NOT NULL,
+	    event        VARCHAR(32)  NOT NULL,
+	    detail       VARCHAR(256) DEFAULT '',
+	    actor        VARCHAR(128) DEFAULT '',
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_hash (hash),
+		KEY idx_event_time (event, created_time)
+    ) ENGINE=InnoDB COMMENT='audit trail of the denylist';`
+
+var cAssetEncryptionTable = `
+    CREATE TABLE if not exists %s (
+	    hash           VARCHAR(128)  NOT NULL,
+	    user_id        VARCHAR(128)  NOT NULL,
+	    algorithm      VARCHAR(32)   NOT NULL,
+	    chunk_size     BIGINT        DEFAULT 0,
+	    nonce          VARCHAR(64)   DEFAULT '',
+	    plain_size     BIGINT        DEFAULT 0,
+	    key_mode       VARCHAR(16)   NOT NULL,
+	    key_id         VARCHAR(128)  DEFAULT '',
+	    wrap_algorithm VARCHAR(32)   DEFAULT '',
+	    wrapped_key    VARCHAR(1024) DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash,user_id)
+    ) ENGINE=InnoDB COMMENT='encryption metadata of the user assets, the content keys are never stored unwrapped';`
+
+var cAssetACLTable = `
+    CREATE TABLE if not exists %s (
+	    hash         VARCHAR(128)  NOT NULL,
+	    user_id      VARCHAR(128)  NOT NULL,
+	    users        TEXT          NOT NULL,
+	    api_keys     TEXT          NOT NULL,
+		created_time DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash,user_id)
+    ) ENGINE=InnoDB COMMENT='access control lists of the private user assets';`
+
+var cGatewayAssetTable = `
+    CREATE TABLE if not exists %s (
+	    hash         VARCHAR(128) NOT NULL,
+	    cid          VARCHAR(128) NOT NULL,
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash)
+    ) ENGINE=InnoDB COMMENT='assets with their own gateway hostnames';`
+
+var cGatewayCertTable = `
+    CREATE TABLE if not exists %s (
+	    zone         VARCHAR(255) NOT NULL,
+	    certificate  TEXT         NOT NULL,
+	    private_key  TEXT         NOT NULL,
+	    not_after    DATETIME     NOT NULL,
+		updated_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (zone)
+    ) ENGINE=InnoDB COMMENT='certificates of the gateway domains obtained by acme';`
+
+var cACMEAccountTable = `
+    CREATE TABLE if not exists %s (
+	    directory    VARCHAR(255) NOT NULL,
+	    private_key  TEXT         NOT NULL,
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (directory)
+    ) ENGINE=InnoDB COMMENT='acme account keys by ca directory';`
+
+var cBandwidthTestTable = `
+    CREATE TABLE if not exists %s (
+	    id             VARCHAR(128) NOT NULL,
+	    node_id        VARCHAR(128) NOT NULL,
+	    status         VARCHAR(16)  NOT NULL,
+	    bandwidth_up   BIGINT       DEFAULT 0,
+	    bandwidth_down BIGINT       DEFAULT 0,
+	    latency        BIGINT       DEFAULT 0,
+	    message        VARCHAR(512) DEFAULT '',
+		created_time   DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		finished_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, created_time)
+    ) ENGINE=InnoDB COMMENT='on-demand bandwidth tests of the nodes';`
+
+var cNodeDiagnosticsTable = `
+    CREATE TABLE if not exists %s (
+	    id             VARCHAR(128)  NOT NULL,
+	    node_id        VARCHAR(128)  NOT NULL,
+	    status         VARCHAR(16)   NOT NULL,
+	    location       VARCHAR(1024) DEFAULT '',
+	    size           BIGINT        DEFAULT 0,
+	    data           MEDIUMBLOB,
+	    message        VARCHAR(512)  DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		finished_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, created_time),
+		KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='diagnostics bundles of the nodes';`
+
+var cReleaseManifestTable = `
+    CREATE TABLE if not exists %s (
+	    version        VARCHAR(32)   NOT NULL,
+	    node_type      INT           NOT NULL,
+	    artifacts      TEXT          NOT NULL,
+	    sign           BLOB          NOT NULL,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (version, node_type)
+    ) ENGINE=InnoDB COMMENT='signed release manifests of the node apps';`
+
+var cUpgradeRolloutTable = `
+    CREATE TABLE if not exists %s (
+	    id                 VARCHAR(128)  NOT NULL,
+	    version            VARCHAR(32)   NOT NULL,
+	    node_type          INT           NOT NULL,
+	    canary_count       INT           DEFAULT 0,
+	    waves              VARCHAR(128)  DEFAULT '',
+	    max_offline_rate   DOUBLE        DEFAULT 0,
+	    wave_interval      INT           DEFAULT 0,
+	    wave               INT           DEFAULT 0,
+		wave_started_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+	    status             VARCHAR(16)   NOT NULL,
+	    message            VARCHAR(512)  DEFAULT '',
+		created_time       DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		updated_time       DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_status (status)
+    ) ENGINE=InnoDB COMMENT='rollouts of the releases to the nodes';`
+
+var cUpgradeNodeTable = `
+    CREATE TABLE if not exists %s (
+	    rollout_id      VARCHAR(128)  NOT NULL,
+	    node_id         VARCHAR(128)  NOT NULL,
+	    wave            INT           DEFAULT 0,
+	    from_version    VARCHAR(64)   DEFAULT '',
+	    status          VARCHAR(16)   NOT NULL,
+	    message         VARCHAR(512)  DEFAULT '',
+		commanded_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		upgraded_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (rollout_id, node_id),
+		KEY idx_node_id (node_id)
+    ) ENGINE=InnoDB COMMENT='nodes commanded to upgrade by the rollouts';`
+
+var cNodeConfigTable = `
+    CREATE TABLE if not exists %s (
+	    scope          VARCHAR(128)  NOT NULL,
+	    config         TEXT          NOT NULL,
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (scope)
+    ) ENGINE=InnoDB COMMENT='config pushed to the nodes, the defaults of the node types and the overrides of the nodes';`
+
+var cNodeConfigAckTable = `
+    CREATE TABLE if not exists %s (
+	    node_id        VARCHAR(128)  NOT NULL,
+	    revision       VARCHAR(32)   DEFAULT '',
+	    errors         VARCHAR(1024) DEFAULT '',
+		ack_time       DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id)
+    ) ENGINE=InnoDB COMMENT='config revisions acknowledged by the nodes';`
+
+var cFeatureFlagTable = `
+    CREATE TABLE if not exists %s (
+	    name           VARCHAR(64)   NOT NULL,
+	    description    VARCHAR(256)  DEFAULT '',
+	    enabled        BOOLEAN       DEFAULT false,
+	    percentage     INT           DEFAULT 0,
+	    node_types     VARCHAR(64)   DEFAULT '[]',
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (name)
+    ) ENGINE=InnoDB COMMENT='feature flags rolled out to the schedulers and the nodes';`
+
+var cRetrievalProbeTable = `
+    CREATE TABLE if not exists %s (
+	    id             BIGINT        NOT NULL AUTO_INCREMENT,
+	    node_id        VARCHAR(128)  NOT NULL,
+	    node_type      INT           DEFAULT 0,
+	    area_id        VARCHAR(128)  DEFAULT '',
+	    cid            VARCHAR(128)  DEFAULT '',
+	    success        BOOLEAN       DEFAULT false,
+	    slow           BOOLEAN       DEFAULT false,
+	    ttfb           BIGINT        DEFAULT 0,
+	    bytes          BIGINT        DEFAULT 0,
+	    duration       BIGINT        DEFAULT 0,
+	    throughput     BIGINT        DEFAULT 0,
+	    message        VARCHAR(512)  DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, created_time),
+		KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='retrieval probes of the replicas by the scheduler';`
+
+var cSegmentStatsTable = `
+	CREATE TABLE if not exists %s (
+		hash         VARCHAR(128) NOT NULL,
+		segment      VARCHAR(255) NOT NULL,
+		hits         BIGINT       DEFAULT 0,
+		updated_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash, segment),
+		KEY idx_hits (hash, hits)
+	) ENGINE=InnoDB COMMENT='retrievals of the segments of the video assets';`
+
+var cWorkloadReceiptTable = `
+	CREATE TABLE if not exists %s (
+		token_id      VARCHAR(128) NOT NULL,
+		node_id       VARCHAR(128) NOT NULL,
+		client_id     VARCHAR(128) NOT NULL,
+		download_size BIGINT       DEFAULT 0,
+		start_time    DATETIME     NOT NULL,
+		end_time      DATETIME     NOT NULL,
+		sign          BLOB,
+		client_key    TEXT,
+		status        VARCHAR(16)  DEFAULT 'pending',
+		reported_size BIGINT       DEFAULT 0,
+		created_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (token_id),
+		KEY idx_node_id (node_id, created_time),
+		KEY idx_created_time (created_time)
+	) ENGINE=InnoDB COMMENT='workload receipts signed by the downloaders, sampled to verify the workloads of the nodes';`
+
+var cPointsEpochCloseTable = `
+	CREATE TABLE if not exists %s (
+		epoch        DATE            NOT NULL,
+		node_count   INT             DEFAULT 0,
+		points       DECIMAL(38, 12) DEFAULT 0,
+		closed_time  DATETIME        NOT NULL,
+		PRIMARY KEY (epoch)
+	) ENGINE=InnoDB COMMENT='closed utc days of points';`
+
+var cPointsEpochTable = `
+	CREATE TABLE if not exists %s (
+		node_id  VARCHAR(128)    NOT NULL,
+		epoch    DATE            NOT NULL,
+		points   DECIMAL(38, 12) DEFAULT 0,
+		total    DECIMAL(38, 12) DEFAULT 0,
+		PRIMARY KEY (node_id, epoch),
+		KEY idx_epoch (epoch)
+	) ENGINE=InnoDB COMMENT='immutable points of nodes earned in the closed utc days';`
+
+var cPointsEpochTotalTable = `
+	CREATE TABLE if not exists %s (
+		node_id  VARCHAR(128)    NOT NULL,
+		total    DECIMAL(38, 12) DEFAULT 0,
+		PRIMARY KEY (node_id)
+	) ENGINE=InnoDB COMMENT='points of nodes in all the closed utc days with the corrections';`
+
+var cPointsCorrectionTable = `
+	CREATE TABLE if not exists %s (
+		id            BIGINT          NOT NULL AUTO_INCREMENT,
+		epoch         DATE            NOT NULL,
+		node_id       VARCHAR(128)    NOT NULL,
+		points        DECIMAL(38, 12) DEFAULT 0,
+		reason        VARCHAR(256)    DEFAULT '',
+		created_time  DATETIME        NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, epoch)
+	) ENGINE=InnoDB COMMENT='corrections of the points of nodes in the closed utc days';`
+
+var cMaintenanceTable = `
+	CREATE TABLE if not exists %s (
+		id            BIGINT          NOT NULL AUTO_INCREMENT,
+		node_id       VARCHAR(128)    NOT NULL,
+		start_time    DATETIME        NOT NULL,
+		end_time      DATETIME        NOT NULL,
+		reason        VARCHAR(256)    DEFAULT '',
+		created_time  DATETIME        NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, end_time),
+		KEY idx_end_time (end_time)
+	) ENGINE=InnoDB COMMENT='maintenance windows of nodes';`
+
+var cBulkJobTable = `
+	CREATE TABLE if not exists %s (
+		id             VARCHAR(128)  NOT NULL,
+		operation      VARCHAR(32)   NOT NULL,
+		filter         VARCHAR(512)  DEFAULT '',
+		server_id      VARCHAR(128)  NOT NULL,
+		total          INT           DEFAULT 0,
+		succeeded      INT           DEFAULT 0,
+		failed         INT           DEFAULT 0,
+		status         VARCHAR(16)   NOT NULL,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		finished_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_server_id (server_id, status)
+	) ENGINE=InnoDB COMMENT='bulk admin jobs on the nodes';`
+
+var cBulkJobItemTable = `
+	CREATE TABLE if not exists %s (
+		job_id         VARCHAR(128)  NOT NULL,
+		node_id        VARCHAR(128)  NOT NULL,
+		status         VARCHAR(16)   NOT NULL,
+		message        VARCHAR(512)  DEFAULT '',
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, node_id),
+		KEY idx_status (job_id, status)
+	) ENGINE=InnoDB COMMENT='results of the bulk admin jobs on the nodes';`
+
+var cJobQueueTable = `
+	CREATE TABLE if not exists %s (
+		id              VARCHAR(128)  NOT NULL,
+		kind            VARCHAR(64)   NOT NULL,
+		dedup_key       VARCHAR(191)  DEFAULT NULL,
+		payload         TEXT          NOT NULL,
+		server_id       VARCHAR(128)  DEFAULT '',
+		status          VARCHAR(16)   NOT NULL,
+		worker          VARCHAR(128)  DEFAULT '',
+		attempts        INT           DEFAULT 0,
+		max_attempts    INT           DEFAULT 1,
+		progress_done   BIGINT        DEFAULT 0,
+		progress_total  BIGINT        DEFAULT 0,
+		message         VARCHAR(512)  DEFAULT '',
+		run_after       DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		heartbeat_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		created_time    DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		updated_time    DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		UNIQUE KEY uk_dedup_key (dedup_key),
+		KEY idx_status (status, run_after),
+		KEY idx_kind (kind, created_time)
+	) ENGINE=InnoDB COMMENT='persistent jobs of the long-running tasks of the schedulers';`
+
+var cNodeArchiveTable = `
+	CREATE TABLE if not exists %s (
+		node_id        VARCHAR(128)  NOT NULL,
+		node_type      VARCHAR(64)   DEFAULT '',
+		last_seen      DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		archived_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id),
+		KEY idx_archived_time (archived_time)
+	) ENGINE=InnoDB COMMENT='nodes archived after offline for long';`
+
+// cArchiveTable creates the archive table with the columns of the table it archives
+var cArchiveTable = `CREATE TABLE if not exists %s LIKE %s;`
+
+var cDataExportTable = `
+	CREATE TABLE if not exists %s (
+		id              VARCHAR(128)  NOT NULL,
+		dataset         VARCHAR(32)   NOT NULL,
+		format          VARCHAR(16)   NOT NULL,
+		schema_version  INT           DEFAULT 0,
+		start_time      DATETIME      NOT NULL,
+		end_time        DATETIME      NOT NULL,
+		object_key      VARCHAR(255)  DEFAULT '',
+		row_count       BIGINT        DEFAULT 0,
+		size            BIGINT        DEFAULT 0,
+		created_time    DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		finished_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_created_time (created_time)
+	) ENGINE=InnoDB COMMENT='exports of the datasets for the analytics';`
+
+var cAssetGeoPolicyTable = `
+	CREATE TABLE if not exists %s (
+		hash          VARCHAR(128)  NOT NULL,
+		cid           VARCHAR(128)  NOT NULL,
+		rules         TEXT          NOT NULL,
+		updated_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash)
+	) ENGINE=InnoDB COMMENT='geo policies of the asset replicas';`
+
+var cAssetGeoViolationTable = `
+	CREATE TABLE if not exists %s (
+		hash           VARCHAR(128)  NOT NULL,
+		cid            VARCHAR(128)  NOT NULL,
+		region         VARCHAR(128)  NOT NULL,
+		kind           VARCHAR(16)   NOT NULL,
+		replicas       INT           DEFAULT 0,
+		required       INT           DEFAULT 0,
+		detected_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash, region, kind),
+		KEY idx_detected_time (detected_time)
+	) ENGINE=InnoDB COMMENT='violations of the geo policies of the asset replicas';`
+
+var cStorageProofTable = `
+	CREATE TABLE if not exists %s (
+		hash           VARCHAR(128)  NOT NULL,
+		node_id        VARCHAR(128)  NOT NULL,
+		cid            VARCHAR(128)  NOT NULL,
+		passed         BOOLEAN       DEFAULT false,
+		failures       INT           DEFAULT 0,
+		checker        VARCHAR(128)  DEFAULT '',
+		duration       BIGINT        DEFAULT 0,
+		message        VARCHAR(512)  DEFAULT '',
+		proof_time     DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash, node_id),
+		KEY idx_node_id (node_id, proof_time)
+	) ENGINE=InnoDB COMMENT='latest storage proofs of the replicas';`
+
+var cReportRejectionTable = `
+	CREATE TABLE if not exists %s (
+		node_id        VARCHAR(128)  NOT NULL,
+		kind           VARCHAR(32)   NOT NULL,
+		count          BIGINT        DEFAULT 0,
+		last_reason    VARCHAR(512)  DEFAULT '',
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id, kind)
+	) ENGINE=InnoDB COMMENT='reports of the nodes rejected for missing or mismatched signatures';`
+
+var cNodeTaskTable = `
+	CREATE TABLE if not exists %s (
+		id             BIGINT        NOT NULL AUTO_INCREMENT,
+		node_id        VARCHAR(128)  NOT NULL,
+		kind           VARCHAR(32)   NOT NULL,
+		payload        VARCHAR(1024) DEFAULT '',
+		attempts       INT           DEFAULT 0,
+		last_error     VARCHAR(512)  DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		expiration     DATETIME      NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, id),
+		KEY idx_expiration (expiration)
+	) ENGINE=InnoDB COMMENT='tasks of the nodes delivered when the nodes are online';`
+
+var cSandboxNodeTable = `
+	CREATE TABLE if not exists %s (
+		node_id        VARCHAR(128)  NOT NULL,
+		expiration     DATETIME      NOT NULL,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id),
+		KEY idx_expiration (expiration)
+	) ENGINE=InnoDB COMMENT='ephemeral test nodes registered in the sandbox';`
+
+var cTenantTable = `
+	CREATE TABLE if not exists %s (
+		tenant_id      VARCHAR(128)  NOT NULL,
+		name           VARCHAR(128)  DEFAULT '',
+		storage_quota  BIGINT        DEFAULT 0,
+		traffic_quota  BIGINT        DEFAULT 0,
+		rate_limit     INT           DEFAULT 0,
+		soft_limit     INT           DEFAULT 0,
+		geo_rules      TEXT          NOT NULL,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id)
+	) ENGINE=InnoDB COMMENT='tenant namespaces of the users';`
+
+var cTenantUserTable = `
+	CREATE TABLE if not exists %s (
+		user_id        VARCHAR(128)  NOT NULL,
+		tenant_id      VARCHAR(128)  NOT NULL,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id),
+		KEY idx_tenant_id (tenant_id)
+	) ENGINE=InnoDB COMMENT='users of the tenants';`
+
+var cTenantUsageTable = `
+	CREATE TABLE if not exists %s (
+		tenant_id      VARCHAR(128)  NOT NULL,
+		day            DATE          NOT NULL,
+		requests       BIGINT        DEFAULT 0,
+		throttled      BIGINT        DEFAULT 0,
+		uploaded       BIGINT        DEFAULT 0,
+		traffic        BIGINT        DEFAULT 0,
+		PRIMARY KEY (tenant_id, day)
+	) ENGINE=InnoDB COMMENT='daily usage of the tenants';`
+
+var cTenantQuotaTable = `
+	CREATE TABLE if not exists %s (
+		tenant_id      VARCHAR(128)  NOT NULL,
+		stored_bytes   BIGINT        DEFAULT 0,
+		egress_month   DATE          NOT NULL,
+		egress_bytes   BIGINT        DEFAULT 0,
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id)
+	) ENGINE=InnoDB COMMENT='quota counters of the tenants';`
+
+var cWebhookTable = `
+	CREATE TABLE if not exists %s (
+		id             BIGINT        NOT NULL AUTO_INCREMENT,
+		user_id        VARCHAR(128)  DEFAULT '',
+		tenant_id      VARCHAR(128)  DEFAULT '',
+		hash           VARCHAR(128)  DEFAULT '',
+		cid            VARCHAR(128)  DEFAULT '',
+		url            VARCHAR(512)  NOT NULL,
+		events         VARCHAR(256)  DEFAULT '',
+		secret         VARCHAR(128)  NOT NULL,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_user_id (user_id),
+		KEY idx_tenant_id (tenant_id),
+		KEY idx_hash (hash)
+	) ENGINE=InnoDB COMMENT='webhooks of the asset lifecycle events';`
+
+var cWebhookDeliveryTable = `
+	CREATE TABLE if not exists %s (
+		id             BIGINT        NOT NULL AUTO_INCREMENT,
+		webhook_id     BIGINT        NOT NULL,
+		event          VARCHAR(32)   NOT NULL,
+		hash           VARCHAR(128)  DEFAULT '',
+		payload        BLOB,
+		status         VARCHAR(16)   DEFAULT 'pending',
+		attempts       INT           DEFAULT 0,
+		next_time      DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		last_error     VARCHAR(512)  DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_status_next_time (status, next_time),
+		KEY idx_webhook_id (webhook_id),
+		KEY idx_updated_time (updated_time)
+	) ENGINE=InnoDB COMMENT='callbacks of the webhooks and their deliveries';`
+
+var cWebhookCursorTable = `
+	CREATE TABLE if not exists %s (
+		name           VARCHAR(32)   NOT NULL,
+		outbox_id      BIGINT        DEFAULT 0,
+		PRIMARY KEY (name)
+	) ENGINE=InnoDB COMMENT='the outbox events the webhooks followed';`
+
+var cUserAssetLabelTable = `
+	CREATE TABLE if not exists %s (
+		user_id        VARCHAR(128)  NOT NULL,
+		hash           VARCHAR(128)  NOT NULL,
+		name           VARCHAR(64)   NOT NULL,
+		value          VARCHAR(128)  DEFAULT '',
+		PRIMARY KEY (user_id, hash, name),
+		KEY idx_label (user_id, name, value)
+	) ENGINE=InnoDB COMMENT='the labels of the assets of the users';`
+
+var cColdAssetTable = `
+	CREATE TABLE if not exists %s (
+		hash           VARCHAR(128)  NOT NULL,
+		cid            VARCHAR(128)  NOT NULL,
+		size           BIGINT        DEFAULT 0,
+		deal_id        VARCHAR(128)  DEFAULT '',
+		state          VARCHAR(16)   DEFAULT 'proposed',
+		provider       VARCHAR(64)   DEFAULT '',
+		piece_cid      VARCHAR(128)  DEFAULT '',
+		chain_deal_id  BIGINT        DEFAULT 0,
+		message        VARCHAR(512)  DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash),
+		KEY idx_state (state)
+	) ENGINE=InnoDB COMMENT='the assets offloaded into the filecoin storage deals';`
+
+var cAssetAccessTable = `
+	CREATE TABLE if not exists %s (
+		hash           VARCHAR(128)  NOT NULL,
+		last_retrieved DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash),
+		KEY idx_last_retrieved (last_retrieved)
+	) ENGINE=InnoDB COMMENT='the last retrievals of the assets the cold assets are found by';`
+
+var cReplicationTable = `
+	CREATE TABLE if not exists %s (
+		id             VARCHAR(128)  NOT NULL,
+		tenant_id      VARCHAR(128)  NOT NULL,
+		peer_area_id   VARCHAR(128)  NOT NULL,
+		replicas       INT           DEFAULT 0,
+		state          VARCHAR(16)   DEFAULT 'active',
+		cursor_time    DATETIME      DEFAULT '1970-01-01 00:00:00',
+		cursor_hash    VARCHAR(128)  DEFAULT '',
+		streamed       BIGINT        DEFAULT 0,
+		message        VARCHAR(512)  DEFAULT '',
+		synced_time    DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		UNIQUE KEY uniq_tenant_area (tenant_id, peer_area_id)
+	) ENGINE=InnoDB COMMENT='the agreements mirroring the assets of the tenants into the other areas';`
+
+var cMirroredAssetTable = `
+	CREATE TABLE if not exists %s (
+		hash             VARCHAR(128)  NOT NULL,
+		cid              VARCHAR(128)  NOT NULL,
+		agreement_id     VARCHAR(128)  NOT NULL,
+		tenant_id        VARCHAR(128)  NOT NULL,
+		source_server_id VARCHAR(128)  NOT NULL,
+		source_area_id   VARCHAR(128)  NOT NULL,
+		created_time     DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash),
+		KEY idx_agreement_id (agreement_id)
+	) ENGINE=InnoDB COMMENT='the assets mirrored from the other areas';`
+
+var cNodeTrustTable = `
+	CREATE TABLE if not exists %s (
+		node_id        VARCHAR(128)  NOT NULL,
+		tier           VARCHAR(16)   NOT NULL,
+		reason         VARCHAR(256)  DEFAULT '',
+		updated_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (node_id),
+		KEY idx_tier (tier)
+	) ENGINE=InnoDB COMMENT='the trust tiers of the nodes';`
+
+var cTrustTransitionTable = `
+	CREATE TABLE if not exists %s (
+		id             BIGINT        NOT NULL AUTO_INCREMENT,
+		node_id        VARCHAR(128)  NOT NULL,
+		from_tier      VARCHAR(16)   DEFAULT '',
+		to_tier        VARCHAR(16)   NOT NULL,
+		reason         VARCHAR(256)  DEFAULT '',
+		created_time   DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, created_time)
+	) ENGINE=InnoDB COMMENT='the transitions of the nodes between the trust tiers';`
+
+var cValidationProofTable = `
+	CREATE TABLE if not exists %s (
+		id             BIGINT        NOT NULL AUTO_INCREMENT,
+		node_id        VARCHAR(128)  NOT NULL,
+		seq            BIGINT        NOT NULL,
+		round_id       VARCHAR(128)  NOT NULL,
+		validator_id   VARCHAR(128)  DEFAULT '',
+		status         TINYINT       DEFAULT 0,
+		block_number   INT           DEFAULT 0,
+		bandwidth      DOUBLE        DEFAULT 0,
+		report         BLOB,
+		validator_sign VARCHAR(1024) DEFAULT '',
+		prev_hash      VARCHAR(64)   DEFAULT '',
+		hash           VARCHAR(64)   NOT NULL,
+		scheduler_sign VARCHAR(1024) NOT NULL,
+		created_time   DATETIME      NOT NULL,
+		PRIMARY KEY (id),
+		UNIQUE KEY uk_node_seq (node_id, seq)
+	) ENGINE=InnoDB COMMENT='the validation results signed by the validators and the scheduler, hash-chained by node';`
+
+var cSpotCheckTable = `
+	CREATE TABLE if not exists %s (
+		id                BIGINT        NOT NULL AUTO_INCREMENT,
+		node_id           VARCHAR(128)  NOT NULL,
+		area_id           VARCHAR(128)  DEFAULT '',
+		cid               VARCHAR(128)  DEFAULT '',
+		range_start       BIGINT        DEFAULT 0,
+		range_size        BIGINT        DEFAULT 0,
+		proxy             VARCHAR(256)  DEFAULT '',
+		success           BOOLEAN       DEFAULT false,
+		verified          BOOLEAN       DEFAULT false,
+		mismatched        BOOLEAN       DEFAULT false,
+		slow              BOOLEAN       DEFAULT false,
+		ttfb              BIGINT        DEFAULT 0,
+		throughput        BIGINT        DEFAULT 0,
+		claimed_bandwidth BIGINT        DEFAULT 0,
+		message           VARCHAR(512)  DEFAULT '',
+		created_time      DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_node_id (node_id, created_time),
+		KEY idx_created_time (created_time)
+	) ENGINE=InnoDB COMMENT='the retrievals of the replicas on the edges disguised as clients';`
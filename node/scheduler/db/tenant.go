@@ -0,0 +1,313 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
+)
+
+// tenantColumns the columns added to the tenants after their creation
+var tenantColumns = []tableColumn{
+	{"soft_limit", "INT DEFAULT 0"},
+}
+
+// SaveTenant creates or updates the tenant, the quota counters of a new tenant are created with it
+func (n *SQLDB) SaveTenant(tenant *types.Tenant) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveTenant Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (tenant_id, name, storage_quota, traffic_quota, rate_limit, soft_limit, geo_rules, created_time, updated_time)
+				VALUES (:tenant_id, :name, :storage_quota, :traffic_quota, :rate_limit, :soft_limit, :geo_rules, NOW(), NOW())
+				ON DUPLICATE KEY UPDATE name=:name, storage_quota=:storage_quota, traffic_quota=:traffic_quota, rate_limit=:rate_limit,
+				soft_limit=:soft_limit, geo_rules=:geo_rules, updated_time=NOW()`, tenantTable)
+	if _, err = tx.NamedExec(query, tenant); err != nil {
+		return err
+	}
+
+	if err = initTenantQuotas(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// initTenantQuotas creates the quota counters of the tenants without them, the stored bytes start from
+// the storage used by the users of the tenant
+func initTenantQuotas(tx *sqlx.Tx) error {
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (tenant_id, stored_bytes, egress_month, egress_bytes)
+				SELECT t.tenant_id, IFNULL(SUM(i.used_storage_size),0), CAST(? AS DATE), 0 FROM %s t
+				LEFT JOIN %s u ON t.tenant_id=u.tenant_id LEFT JOIN %s i ON u.user_id=i.user_id
+				GROUP BY t.tenant_id`, tenantQuotaTable, tenantTable, tenantUserTable, userInfoTable)
+	_, err := tx.Exec(query, egressMonth(time.Now()))
+	return err
+}
+
+// DeleteTenant deletes the tenant without users, its usage is kept
+func (n *SQLDB) DeleteTenant(tenantID string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteTenant Rollback err:%s", err.Error())
+		}
+	}()
+
+	var users int
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE tenant_id=?`, tenantUserTable)
+	if err = tx.Get(&users, query, tenantID); err != nil {
+		return err
+	}
+
+	if users > 0 {
+		return xerrors.Errorf("tenant %s still has %d users", tenantID, users)
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE tenant_id=?`, tenantTable)
+	result, err := tx.Exec(query, tenantID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return xerrors.Errorf("tenant %s not found", tenantID)
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE tenant_id=?`, tenantQuotaTable)
+	if _, err = tx.Exec(query, tenantID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadTenants load all the tenants
+func (n *SQLDB) LoadTenants() ([]*types.Tenant, error) {
+	var out []*types.Tenant
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY tenant_id", tenantTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SaveTenantUser adds the user to the tenant, a user is in one tenant at most.
+// The storage used by the user is added to the stored bytes of the tenant
+func (n *SQLDB) SaveTenantUser(tenantID, userID string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveTenantUser Rollback err:%s", err.Error())
+		}
+	}()
+
+	var current string
+	query := fmt.Sprintf(`SELECT tenant_id FROM %s WHERE user_id=? FOR UPDATE`, tenantUserTable)
+	err = tx.Get(&current, query, userID)
+	if err == nil {
+		if current != tenantID {
+			return xerrors.Errorf("user %s is a user of tenant %s", userID, current)
+		}
+		return nil
+	}
+
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (user_id, tenant_id) VALUES (?, ?)`, tenantUserTable)
+	if _, err = tx.Exec(query, userID, tenantID); err != nil {
+		return err
+	}
+
+	var used int64
+	query = fmt.Sprintf(`SELECT IFNULL(SUM(used_storage_size),0) FROM %s WHERE user_id=?`, userInfoTable)
+	if err = tx.Get(&used, query, userID); err != nil {
+		return err
+	}
+
+	if err = addTenantStorage(tx, userID, used); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTenantUser removes the user from the tenant, the storage used by the user is removed from the stored bytes of the tenant
+func (n *SQLDB) DeleteTenantUser(tenantID, userID string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteTenantUser Rollback err:%s", err.Error())
+		}
+	}()
+
+	var used int64
+	query := fmt.Sprintf(`SELECT IFNULL(SUM(used_storage_size),0) FROM %s WHERE user_id=?`, userInfoTable)
+	if err = tx.Get(&used, query, userID); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET stored_bytes=GREATEST(stored_bytes-?,0), updated_time=NOW() WHERE tenant_id=?`, tenantQuotaTable)
+	if _, err = tx.Exec(query, used, tenantID); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE tenant_id=? AND user_id=?`, tenantUserTable)
+	result, err := tx.Exec(query, tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return xerrors.Errorf("user %s is not a user of tenant %s", userID, tenantID)
+	}
+
+	return tx.Commit()
+}
+
+// LoadTenantOfUser load the id of the tenant of the user, empty if the user is not in a tenant
+func (n *SQLDB) LoadTenantOfUser(userID string) (string, error) {
+	var tenantID string
+	query := fmt.Sprintf(`SELECT tenant_id FROM %s WHERE user_id=?`, tenantUserTable)
+	if err := n.db.Get(&tenantID, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return tenantID, nil
+}
+
+// LoadTenantUsers load the users of the tenant
+func (n *SQLDB) LoadTenantUsers(tenantID string, limit, offset int) (*types.ListTenantUserRsp, error) {
+	res := new(types.ListTenantUserRsp)
+
+	if limit > loadTenantUsersDefaultLimit || limit <= 0 {
+		limit = loadTenantUsersDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE tenant_id=?", tenantUserTable)
+	if err := n.db.Get(&res.Total, query, tenantID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT tenant_id, user_id, created_time FROM %s WHERE tenant_id=? ORDER BY user_id LIMIT ? OFFSET ?", tenantUserTable)
+	if err := n.db.Select(&res.Data, query, tenantID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadTenantStorage load the users, the assets and the storage used by the users of the tenant
+func (n *SQLDB) LoadTenantStorage(tenantID string) (users, assets, used int64, err error) {
+	query := fmt.Sprintf(`SELECT count(*), IFNULL(SUM(b.used_storage_size),0) FROM %s a LEFT JOIN %s b ON a.user_id=b.user_id WHERE a.tenant_id=?`,
+		tenantUserTable, userInfoTable)
+	if err = n.db.QueryRow(query, tenantID).Scan(&users, &used); err != nil {
+		return 0, 0, 0, err
+	}
+
+	query = fmt.Sprintf(`SELECT count(*) FROM %s a JOIN %s b ON a.user_id=b.user_id WHERE b.tenant_id=?`, userAssetTable, tenantUserTable)
+	if err = n.db.Get(&assets, query, tenantID); err != nil {
+		return 0, 0, 0, err
+	}
+
+	return users, assets, used, nil
+}
+
+// AddTenantUsage adds the usage to the day of the tenant
+func (n *SQLDB) AddTenantUsage(usage *types.TenantUsageDaily) error {
+	query := fmt.Sprintf(`INSERT INTO %s (tenant_id, day, requests, throttled, uploaded, traffic)
+				VALUES (:tenant_id, :day, :requests, :throttled, :uploaded, :traffic)
+				ON DUPLICATE KEY UPDATE requests=requests+VALUES(requests), throttled=throttled+VALUES(throttled),
+				uploaded=uploaded+VALUES(uploaded), traffic=traffic+VALUES(traffic)`, tenantUsageTable)
+	_, err := n.db.NamedExec(query, usage)
+	return err
+}
+
+// LoadTenantUsage load the daily usage of the tenant in [start, end), ordered by day
+func (n *SQLDB) LoadTenantUsage(tenantID string, start, end time.Time) ([]*types.TenantUsageDaily, error) {
+	var out []*types.TenantUsageDaily
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE tenant_id=? AND day>=? AND day<? ORDER BY day`, tenantUsageTable)
+	if err := n.db.Select(&out, query, tenantID, start, end); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadTenantQuota load the quota counters of the tenant
+func (n *SQLDB) LoadTenantQuota(tenantID string) (*types.TenantQuota, error) {
+	var out types.TenantQuota
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE tenant_id=?`, tenantQuotaTable)
+	if err := n.db.Get(&out, query, tenantID); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// addTenantStorage adds the size to the stored bytes of the tenant of the user in the transaction,
+// nothing is changed if the user is not in a tenant
+func addTenantStorage(tx *sqlx.Tx, userID string, size int64) error {
+	query := fmt.Sprintf(`UPDATE %s q JOIN %s u ON q.tenant_id=u.tenant_id
+				SET q.stored_bytes=GREATEST(q.stored_bytes+?,0), q.updated_time=NOW() WHERE u.user_id=?`, tenantQuotaTable, tenantUserTable)
+	_, err := tx.Exec(query, size, userID)
+	return err
+}
+
+// addTenantEgress adds the bytes downloaded by the user to the egress of the month of its tenant in the transaction,
+// the egress of the previous month is reset. Nothing is changed if the user is not in a tenant
+func addTenantEgress(tx *sqlx.Tx, userID string, bytes int64) error {
+	month := egressMonth(time.Now())
+	query := fmt.Sprintf(`UPDATE %s q JOIN %s u ON q.tenant_id=u.tenant_id
+				SET q.egress_bytes=IF(q.egress_month=?, q.egress_bytes, 0)+?, q.egress_month=?, q.updated_time=NOW() WHERE u.user_id=?`,
+		tenantQuotaTable, tenantUserTable)
+	_, err := tx.Exec(query, month, bytes, month, userID)
+	return err
+}
+
+// egressMonth returns the first day of the month the egress is counted in, formatted as a date to be bound
+// to the egress_month column
+func egressMonth(t time.Time) string {
+	return t.Format("2006-01") + "-01"
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"golang.org/x/xerrors"
+)
+
+// SaveAPIToken save the api token
+func (n *SQLDB) SaveAPIToken(token *types.APIToken) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, name, role) VALUES (:id, :name, :role)`, apiTokenTable)
+	_, err := n.db.NamedExec(query, token)
+	return err
+}
+
+// UpdateAPITokenRole update the role of the api token
+func (n *SQLDB) UpdateAPITokenRole(id string, role auth.Permission) error {
+	query := fmt.Sprintf(`UPDATE %s SET role=? WHERE id=?`, apiTokenTable)
+	result, err := n.db.Exec(query, role, id)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if r < 1 {
+		return xerrors.Errorf("api token %s not found", id)
+	}
+
+	return nil
+}
+
+// LoadAPITokens load all api tokens
+func (n *SQLDB) LoadAPITokens() ([]*types.APIToken, error) {
+	var out []*types.APIToken
+	query := fmt.Sprintf(`SELECT * FROM %s order by created_time asc`, apiTokenTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeleteAPIToken delete the api token
+func (n *SQLDB) DeleteAPIToken(id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, apiTokenTable)
+	_, err := n.db.Exec(query, id)
+	return err
+}
+
+// SaveTokenIssued records the latest time a token of the subject is issued
+func (n *SQLDB) SaveTokenIssued(subject string, role auth.Permission, issuedAt int64) error {
+	query := fmt.Sprintf(`INSERT INTO %s (subject, role, issued_at) VALUES (?, ?, ?)
+				ON DUPLICATE KEY UPDATE role=?, issued_at=?`, tokenSubjectTable)
+	_, err := n.db.Exec(query, subject, role, issuedAt, role, issuedAt)
+	return err
+}
+
+// SaveTokenRevoked records the time the tokens of the subject are revoked
+func (n *SQLDB) SaveTokenRevoked(subject string, revokedAt int64) error {
+	query := fmt.Sprintf(`INSERT INTO %s (subject, revoked_at) VALUES (?, ?)
+				ON DUPLICATE KEY UPDATE revoked_at=?`, tokenSubjectTable)
+	_, err := n.db.Exec(query, subject, revokedAt, revokedAt)
+	return err
+}
+
+// LoadRevokedTokenSubjects load the subjects whose tokens have been revoked
+func (n *SQLDB) LoadRevokedTokenSubjects() ([]*types.TokenSubject, error) {
+	var out []*types.TokenSubject
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE revoked_at>0`, tokenSubjectTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadActiveTokenSubjects load the subjects that have tokens issued after their revocation, an empty role loads all roles
+func (n *SQLDB) LoadActiveTokenSubjects(role auth.Permission, limit, offset int) (*types.ListTokenSubjectRsp, error) {
+	res := new(types.ListTokenSubjectRsp)
+
+	if limit > loadTokenSubjectDefaultLimit || limit <= 0 {
+		limit = loadTokenSubjectDefaultLimit
+	}
+
+	where := "WHERE issued_at>revoked_at"
+	args := []interface{}{}
+	if role != "" {
+		where += " AND role=?"
+		args = append(args, role)
+	}
+
+	query := fmt.Sprintf("SELECT count(subject) FROM %s %s", tokenSubjectTable, where)
+	if err := n.db.Get(&res.Total, query, args...); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s %s order by issued_at desc LIMIT ? OFFSET ?", tokenSubjectTable, where)
+	if err := n.db.Select(&res.Data, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SaveNodeLoginNonce saves a login challenge of the node, the challenges issued to the callers are kept until
+// they are used or expired so the challenges of others do not replace the challenge of the node
+func (n *SQLDB) SaveNodeLoginNonce(nodeID, nonce string, expireTime, now int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=? AND expire_time<?`, nodeLoginNonceTable)
+	if _, err := n.db.Exec(query, nodeID, now); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, nonce, expire_time) VALUES (?, ?, ?)`, nodeLoginNonceTable)
+	_, err := n.db.Exec(query, nodeID, nonce, expireTime)
+	return err
+}
+
+// ConsumeNodeLoginNonce uses up the login challenge of the node, it fails if the nonce is not issued,
+// expired or already used, or the login is signed before the last login of the node
+func (n *SQLDB) ConsumeNodeLoginNonce(nodeID, nonce string, loginTime, now int64) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ConsumeNodeLoginNonce Rollback err:%s", err.Error())
+		}
+	}()
+
+	// the nonce is deleted when it is used, it can not be used again
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=? AND nonce=? AND expire_time>=?`, nodeLoginNonceTable)
+	result, err := tx.Exec(query, nodeID, nonce, now)
+	if err != nil {
+		return err
+	}
+
+	r, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if r < 1 {
+		return xerrors.Errorf("login nonce of node %s is invalid or replayed", nodeID)
+	}
+
+	var lastLoginTime int64
+	query = fmt.Sprintf(`SELECT last_login_time FROM %s WHERE node_id=? FOR UPDATE`, nodeLoginTable)
+	if err = tx.Get(&lastLoginTime, query, nodeID); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if loginTime < lastLoginTime {
+		return xerrors.Errorf("login of node %s is signed before its last login", nodeID)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, last_login_time) VALUES (?, ?) ON DUPLICATE KEY UPDATE last_login_time=?`, nodeLoginTable)
+	if _, err = tx.Exec(query, nodeID, loginTime, loginTime); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
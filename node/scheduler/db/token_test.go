@@ -0,0 +1,50 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/node/scheduler/harness"
+)
+
+func TestNodeLoginNonce(t *testing.T) {
+	sdb, closeDB, err := harness.NewDB("test-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+
+	now := int64(1000)
+	nodeID := "e_1"
+
+	// the challenge requested by another caller does not replace the challenge of the node
+	if err := sdb.SaveNodeLoginNonce(nodeID, "node", now+120, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := sdb.SaveNodeLoginNonce(nodeID, "other", now+120, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sdb.ConsumeNodeLoginNonce(nodeID, "node", now, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := sdb.ConsumeNodeLoginNonce(nodeID, "node", now, now); err == nil {
+		t.Error("expect the used nonce to be rejected")
+	}
+
+	// the login signed before the last login is rejected and its nonce is kept
+	if err := sdb.ConsumeNodeLoginNonce(nodeID, "other", now-1, now); err == nil {
+		t.Error("expect the login signed before the last login to be rejected")
+	}
+
+	if err := sdb.ConsumeNodeLoginNonce(nodeID, "other", now+200, now+200); err == nil {
+		t.Error("expect the expired nonce to be rejected")
+	}
+
+	// the expired challenges are deleted when a new one is saved
+	if err := sdb.SaveNodeLoginNonce(nodeID, "next", now+320, now+200); err != nil {
+		t.Fatal(err)
+	}
+	if err := sdb.ConsumeNodeLoginNonce(nodeID, "next", now+201, now+201); err != nil {
+		t.Fatal(err)
+	}
+}
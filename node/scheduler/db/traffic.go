@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// AddNodeTraffics adds the bytes to the daily traffic of the nodes
+func (n *SQLDB) AddNodeTraffics(traffics []*types.NodeTrafficDaily) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("AddNodeTraffics Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s (node_id, date, upload_bytes, download_bytes) VALUES (:node_id, :date, :upload_bytes, :download_bytes)
+			ON DUPLICATE KEY UPDATE upload_bytes=upload_bytes+VALUES(upload_bytes), download_bytes=download_bytes+VALUES(download_bytes)`, nodeTrafficDailyTable)
+
+	for _, traffic := range traffics {
+		if _, err := tx.NamedExec(query, traffic); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadNodeTrafficDaily load the daily traffic of the node in the date range [start, end)
+func (n *SQLDB) LoadNodeTrafficDaily(nodeID string, start, end time.Time) ([]*types.NodeTrafficDaily, error) {
+	var out []*types.NodeTrafficDaily
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=? AND date>=? AND date<? ORDER BY date", nodeTrafficDailyTable)
+	if err := n.db.Select(&out, query, nodeID, start, end); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// IsNodeTrafficRolledUp checks if the daily traffic of the month has been rolled up to the statements
+func (n *SQLDB) IsNodeTrafficRolledUp(month string) (bool, error) {
+	var count int
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE month=? LIMIT 1", nodeTrafficMonthTable)
+	if err := n.db.Get(&count, query, month); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RollupNodeTraffic rolls up the daily traffic in the date range [start, end) to the statements of the month
+func (n *SQLDB) RollupNodeTraffic(month string, start, end time.Time) error {
+	query := fmt.Sprintf(`REPLACE INTO %s (node_id, month, upload_bytes, download_bytes, active_days)
+	        SELECT node_id, ?, SUM(upload_bytes), SUM(download_bytes), COUNT(*) FROM %s WHERE date>=? AND date<? GROUP BY node_id`,
+		nodeTrafficMonthTable, nodeTrafficDailyTable)
+	_, err := n.db.Exec(query, month, start, end)
+	return err
+}
+
+// LoadNodeTrafficStatement load the statement of the node in the month
+func (n *SQLDB) LoadNodeTrafficStatement(nodeID, month string) (*types.NodeTrafficStatement, error) {
+	var statement types.NodeTrafficStatement
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=? AND month=?", nodeTrafficMonthTable)
+	if err := n.db.Get(&statement, query, nodeID, month); err != nil {
+		return nil, err
+	}
+
+	return &statement, nil
+}
+
+// LoadNodeTrafficStatements load the statements of the nodes in the month
+func (n *SQLDB) LoadNodeTrafficStatements(month string, limit, offset int) (*types.ListNodeTrafficStatementRsp, error) {
+	res := new(types.ListNodeTrafficStatementRsp)
+
+	if limit > loadTrafficStatementsDefaultLimit || limit <= 0 {
+		limit = loadTrafficStatementsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE month=?", nodeTrafficMonthTable)
+	if err := n.db.Get(&res.Total, query, month); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE month=? ORDER BY node_id LIMIT ? OFFSET ?", nodeTrafficMonthTable)
+	if err := n.db.Select(&res.Data, query, month, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SumNodeTrafficStatements sums the daily traffic of the nodes in the date range [start, end) as the statements of the month,
+// used for the month not rolled up yet
+func (n *SQLDB) SumNodeTrafficStatements(month string, start, end time.Time, limit, offset int) (*types.ListNodeTrafficStatementRsp, error) {
+	res := new(types.ListNodeTrafficStatementRsp)
+
+	if limit > loadTrafficStatementsDefaultLimit || limit <= 0 {
+		limit = loadTrafficStatementsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(DISTINCT node_id) FROM %s WHERE date>=? AND date<?", nodeTrafficDailyTable)
+	if err := n.db.Get(&res.Total, query, start, end); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`SELECT node_id, ? AS month, SUM(upload_bytes) AS upload_bytes, SUM(download_bytes) AS download_bytes, COUNT(*) AS active_days
+	        FROM %s WHERE date>=? AND date<? GROUP BY node_id ORDER BY node_id LIMIT ? OFFSET ?`, nodeTrafficDailyTable)
+	if err := n.db.Select(&res.Data, query, month, start, end, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// LoadNodeTrustFacts load the registration time, the last penalty not overturned by an appeal and the current trust tier of the nodes
+func (n *SQLDB) LoadNodeTrustFacts(nodeIDs []string) ([]*types.NodeTrustFacts, error) {
+	if len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`SELECT i.node_id, i.first_login_time, MAX(p.created_time) AS last_penalty_time, IFNULL(t.tier, '') AS tier
+				FROM %s i LEFT JOIN %s p ON p.node_id=i.node_id AND p.appeal<>? LEFT JOIN %s t ON t.node_id=i.node_id
+				WHERE i.node_id IN (?) GROUP BY i.node_id, i.first_login_time, t.tier`, nodeInfoTable, penaltyTable, nodeTrustTable)
+	query, args, err := sqlx.In(query, types.PenaltyAppealAccepted, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.NodeTrustFacts
+	if err = n.db.Select(&out, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadNodeTrust load the trust tier of the node
+func (n *SQLDB) LoadNodeTrust(nodeID string) (*types.NodeTrust, error) {
+	var out types.NodeTrust
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=?", nodeTrustTable)
+	if err := n.db.Get(&out, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// SaveTrustTransition moves the node to the tier of the transition and logs the transition
+func (n *SQLDB) SaveTrustTransition(transition *types.TrustTransition) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveTrustTransition Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, tier, reason, updated_time) VALUES (?, ?, ?, NOW())
+				ON DUPLICATE KEY UPDATE tier=VALUES(tier), reason=VALUES(reason), updated_time=NOW()`, nodeTrustTable)
+	if _, err = tx.Exec(query, transition.NodeID, transition.ToTier, transition.Reason); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (node_id, from_tier, to_tier, reason) VALUES (?, ?, ?, ?)`, trustTransitionTable)
+	if _, err = tx.Exec(query, transition.NodeID, transition.FromTier, transition.ToTier, transition.Reason); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadTrustTransitions load the transitions of the node between the trust tiers, the latest first
+func (n *SQLDB) LoadTrustTransitions(nodeID string, limit, offset int) (*types.ListTrustTransitionRsp, error) {
+	res := new(types.ListTrustTransitionRsp)
+
+	if limit > loadPenaltiesDefaultLimit || limit <= 0 {
+		limit = loadPenaltiesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", trustTransitionTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY id DESC LIMIT ? OFFSET ?", trustTransitionTable)
+	if err := n.db.Select(&res.Data, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveReleaseManifest saves the release manifest, a manifest of the same version and node type is replaced
+func (n *SQLDB) SaveReleaseManifest(manifest *types.ReleaseManifest) error {
+	query := fmt.Sprintf(`INSERT INTO %s (version, node_type, artifacts, sign) VALUES (:version, :node_type, :artifacts, :sign)
+	        ON DUPLICATE KEY UPDATE artifacts=:artifacts, sign=:sign, created_time=NOW()`, releaseManifestTable)
+	_, err := n.db.NamedExec(query, manifest)
+	return err
+}
+
+// LoadReleaseManifest loads the release manifest, returns sql.ErrNoRows if it does not exist
+func (n *SQLDB) LoadReleaseManifest(version string, nodeType types.NodeType) (*types.ReleaseManifest, error) {
+	var out types.ReleaseManifest
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE version=? AND node_type=?`, releaseManifestTable)
+	if err := n.db.Get(&out, query, version, nodeType); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LoadReleaseManifests loads the release manifests of the node type, the latest first
+func (n *SQLDB) LoadReleaseManifests(nodeType types.NodeType) ([]*types.ReleaseManifest, error) {
+	var out []*types.ReleaseManifest
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE node_type=? ORDER BY created_time DESC`, releaseManifestTable)
+	if err := n.db.Select(&out, query, nodeType); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SaveUpgradeRollout saves the started upgrade rollout
+func (n *SQLDB) SaveUpgradeRollout(rollout *types.UpgradeRollout) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, version, node_type, canary_count, waves, max_offline_rate, wave_interval, wave, status)
+	        VALUES (:id, :version, :node_type, :canary_count, :waves, :max_offline_rate, :wave_interval, :wave, :status)`, upgradeRolloutTable)
+	_, err := n.db.NamedExec(query, rollout)
+	return err
+}
+
+// UpdateUpgradeRollout saves the wave and the status of the upgrade rollout
+func (n *SQLDB) UpdateUpgradeRollout(rollout *types.UpgradeRollout) error {
+	query := fmt.Sprintf(`UPDATE %s SET wave=:wave, wave_started_time=:wave_started_time, status=:status, message=:message,
+	        updated_time=NOW() WHERE id=:id`, upgradeRolloutTable)
+	_, err := n.db.NamedExec(query, rollout)
+	return err
+}
+
+// LoadUpgradeRollout loads the upgrade rollout, returns sql.ErrNoRows if it does not exist
+func (n *SQLDB) LoadUpgradeRollout(id string) (*types.UpgradeRollout, error) {
+	var out types.UpgradeRollout
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=?`, upgradeRolloutTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LoadUpgradeRolloutsByStatus loads the upgrade rollouts of the status
+func (n *SQLDB) LoadUpgradeRolloutsByStatus(status types.UpgradeRolloutStatus) ([]*types.UpgradeRollout, error) {
+	var out []*types.UpgradeRollout
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE status=?`, upgradeRolloutTable)
+	if err := n.db.Select(&out, query, status); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoadUpgradeRollouts loads the upgrade rollouts, the latest first
+func (n *SQLDB) LoadUpgradeRollouts(limit, offset int) (*types.ListUpgradeRolloutRsp, error) {
+	res := new(types.ListUpgradeRolloutRsp)
+
+	if limit > loadUpgradeRolloutsDefaultLimit || limit <= 0 {
+		limit = loadUpgradeRolloutsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", upgradeRolloutTable)
+	if err := n.db.Get(&res.Total, query); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY created_time DESC LIMIT ? OFFSET ?", upgradeRolloutTable)
+	if err := n.db.Select(&res.Rollouts, query, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SaveUpgradeNode records the node commanded by the rollout, returns false if the node is already recorded
+func (n *SQLDB) SaveUpgradeNode(node *types.UpgradeNode) (bool, error) {
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (rollout_id, node_id, wave, from_version, status) VALUES (:rollout_id, :node_id, :wave, :from_version, :status)`, upgradeNodeTable)
+	result, err := n.db.NamedExec(query, node)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+// UpdateUpgradeNodeStatus updates the status of the node commanded by the rollout
+func (n *SQLDB) UpdateUpgradeNodeStatus(rolloutID, nodeID string, status types.UpgradeNodeStatus, message string) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, message=? WHERE rollout_id=? AND node_id=?`, upgradeNodeTable)
+	_, err := n.db.Exec(query, status, message, rolloutID, nodeID)
+	return err
+}
+
+// MarkNodeUpgraded marks the node commanded by the rollouts of the version upgraded
+func (n *SQLDB) MarkNodeUpgraded(nodeID, version string) error {
+	query := fmt.Sprintf(`UPDATE %s u JOIN %s r ON u.rollout_id=r.id SET u.status=?, u.upgraded_time=NOW()
+	        WHERE u.node_id=? AND u.status=? AND r.version=?`, upgradeNodeTable, upgradeRolloutTable)
+	_, err := n.db.Exec(query, types.UpgradeNodeUpgraded, nodeID, types.UpgradeNodeCommanded, version)
+	return err
+}
+
+// LoadUpgradeNodeIDs loads the ids of the nodes commanded by the rollout
+func (n *SQLDB) LoadUpgradeNodeIDs(rolloutID string) ([]string, error) {
+	var out []string
+	query := fmt.Sprintf(`SELECT node_id FROM %s WHERE rollout_id=?`, upgradeNodeTable)
+	if err := n.db.Select(&out, query, rolloutID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoadUpgradeWaveStats counts the nodes of the waves of the rollout by status
+func (n *SQLDB) LoadUpgradeWaveStats(rolloutID string) ([]*types.UpgradeWaveStats, error) {
+	var out []*types.UpgradeWaveStats
+	query := fmt.Sprintf(`SELECT wave, status, count(*) AS count FROM %s WHERE rollout_id=? GROUP BY wave, status`, upgradeNodeTable)
+	if err := n.db.Select(&out, query, rolloutID); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CountUpgradeNodesCommandedBefore counts the nodes of the wave commanded before the time,
+// and those of them not back online with the release
+func (n *SQLDB) CountUpgradeNodesCommandedBefore(rolloutID string, wave int, before time.Time) (total, offline int, err error) {
+	query := fmt.Sprintf(`SELECT count(*), COALESCE(SUM(status=?), 0) FROM %s WHERE rollout_id=? AND wave=? AND commanded_time<?`, upgradeNodeTable)
+	err = n.db.QueryRow(query, types.UpgradeNodeCommanded, rolloutID, wave, before).Scan(&total, &offline)
+	return total, offline, err
+}
+
+// LoadUpgradeNodes loads the nodes commanded by the rollout, the latest first
+func (n *SQLDB) LoadUpgradeNodes(rolloutID string, limit, offset int) (*types.ListUpgradeNodeRsp, error) {
+	res := new(types.ListUpgradeNodeRsp)
+
+	if limit > loadUpgradeNodesDefaultLimit || limit <= 0 {
+		limit = loadUpgradeNodesDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE rollout_id=?", upgradeNodeTable)
+	if err := n.db.Get(&res.Total, query, rolloutID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE rollout_id=? ORDER BY commanded_time DESC LIMIT ? OFFSET ?", upgradeNodeTable)
+	if err := n.db.Select(&res.Nodes, query, rolloutID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadVersionAdoption counts the nodes of the type seen since the time by release version, the most adopted first
+func (n *SQLDB) LoadVersionAdoption(nodeType types.NodeType, since time.Time) ([]*types.VersionAdoption, error) {
+	var out []*types.VersionAdoption
+	query := fmt.Sprintf(`SELECT SUBSTRING_INDEX(i.system_version, '+', 1) AS version, count(*) AS count FROM %s i
+	        JOIN %s r ON i.node_id=r.node_id WHERE r.node_type=? AND i.last_seen>? GROUP BY version ORDER BY count DESC`, nodeInfoTable, nodeRegisterTable)
+	if err := n.db.Select(&out, query, nodeType, since); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
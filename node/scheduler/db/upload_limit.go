@@ -0,0 +1,36 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveUploadLimit saves the upload limit of the node, the limit is removed if it is 0
+func (n *SQLDB) SaveUploadLimit(nodeID string, bytesPerSec int64) error {
+	if bytesPerSec <= 0 {
+		query := fmt.Sprintf("DELETE FROM %s WHERE node_id=?", uploadLimitTable)
+		_, err := n.db.Exec(query, nodeID)
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, upload_limit, updated_time) VALUES (?, ?, NOW())
+				ON DUPLICATE KEY UPDATE upload_limit=?, updated_time=NOW()`, uploadLimitTable)
+	_, err := n.db.Exec(query, nodeID, bytesPerSec, bytesPerSec)
+	return err
+}
+
+// LoadUploadLimit load the upload limit of the node, nil if the node is not limited
+func (n *SQLDB) LoadUploadLimit(nodeID string) (*types.UploadLimit, error) {
+	var out types.UploadLimit
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=?", uploadLimitTable)
+	if err := n.db.Get(&out, query, nodeID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
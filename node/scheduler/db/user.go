@@ -11,7 +11,7 @@ import (
 )
 
 // SaveAssetUser save asset and user info
-func (n *SQLDB) SaveAssetUser(hash, userID, assetName, assetType string, size int64, expiration time.Time, password string, groupID int) error {
+func (n *SQLDB) SaveAssetUser(hash, userID, assetName, assetType string, size int64, expiration time.Time, password string, groupID int, contentType string, labels types.AssetLabels) error {
 	tx, err := n.db.Beginx()
 	if err != nil {
 		return err
@@ -24,14 +24,22 @@ func (n *SQLDB) SaveAssetUser(hash, userID, assetName, assetType string, size in
 		}
 	}()
 
+	if err = lockAssetRecord(tx, hash); err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(
-		`INSERT INTO %s (hash, user_id, asset_name, total_size, asset_type, expiration, password, group_id) 
-		        VALUES (?, ?, ?, ?, ?, ?, ?, ?) `, userAssetTable)
-	_, err = tx.Exec(query, hash, userID, assetName, size, assetType, expiration, password, groupID)
+		`INSERT INTO %s (hash, user_id, asset_name, total_size, asset_type, expiration, password, group_id, content_type) 
+		        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) `, userAssetTable)
+	_, err = tx.Exec(query, hash, userID, assetName, size, assetType, expiration, password, groupID, contentType)
 	if err != nil {
 		return err
 	}
 
+	if err = saveAssetLabels(tx, userID, hash, labels); err != nil {
+		return err
+	}
+
 	query = fmt.Sprintf(
 		`UPDATE %s SET used_storage_size=used_storage_size+? WHERE user_id=?`, userInfoTable)
 	_, err = tx.Exec(query, size, userID)
@@ -39,6 +47,16 @@ func (n *SQLDB) SaveAssetUser(hash, userID, assetName, assetType string, size in
 		return err
 	}
 
+	if size > 0 {
+		if err = addTenantStorage(tx, userID, size); err != nil {
+			return err
+		}
+	}
+
+	if err = refreshAssetOwners(tx, hash); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -64,9 +82,26 @@ func (n *SQLDB) DeleteAssetUser(hash, userID string) error {
 		}
 	}()
 
+	if err = lockAssetRecord(tx, hash); err != nil {
+		return err
+	}
+
+	if err = deleteAssetUser(tx, hash, userID); err != nil {
+		return err
+	}
+
+	if err = refreshAssetOwners(tx, hash); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteAssetUser deletes the asset of the user in the transaction and refunds the storage charged to the user
+func deleteAssetUser(tx *sqlx.Tx, hash, userID string) error {
 	var size int64
 	query := fmt.Sprintf("SELECT total_size FROM %s WHERE hash=? AND user_id=?", userAssetTable)
-	err = tx.Get(&size, query, hash, userID)
+	err := tx.Get(&size, query, hash, userID)
 	if err != nil {
 		return err
 	}
@@ -82,6 +117,24 @@ func (n *SQLDB) DeleteAssetUser(hash, userID string) error {
 		return xerrors.New("nothing to update")
 	}
 
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND user_id=? `, assetEncryptionTable)
+	_, err = tx.Exec(query, hash, userID)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND user_id=? `, assetACLTable)
+	_, err = tx.Exec(query, hash, userID)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND user_id=? `, userAssetLabelTable)
+	_, err = tx.Exec(query, hash, userID)
+	if err != nil {
+		return err
+	}
+
 	query = fmt.Sprintf(
 		`UPDATE %s SET used_storage_size=used_storage_size-? WHERE user_id=?`, userInfoTable)
 	_, err = tx.Exec(query, size, userID)
@@ -89,7 +142,13 @@ func (n *SQLDB) DeleteAssetUser(hash, userID string) error {
 		return err
 	}
 
-	return tx.Commit()
+	if size > 0 {
+		if err = addTenantStorage(tx, userID, -size); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // ListUsersForAsset Get a list of users by asset
@@ -182,6 +241,21 @@ func (n *SQLDB) GetAssetName(hash, userID string) (string, error) {
 	return assetName, nil
 }
 
+// LoadAnyAssetName load the name an owner of the asset gave it, empty if the asset has no owner
+func (n *SQLDB) LoadAnyAssetName(hash string) (string, error) {
+	var names []string
+	query := fmt.Sprintf("SELECT asset_name FROM %s WHERE hash=? LIMIT 1", userAssetTable)
+	if err := n.db.Select(&names, query, hash); err != nil {
+		return "", err
+	}
+
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	return names[0], nil
+}
+
 func (n *SQLDB) GetAssetExpiration(hash, userID string) (time.Time, error) {
 	var expiration time.Time
 	query := fmt.Sprintf("SELECT expiration FROM %s WHERE hash=? AND user_id=?", userAssetTable)
@@ -273,12 +347,33 @@ func (n *SQLDB) ListStorageStatsOfUsers(limit, offset int) (*types.ListStorageSt
 	return res, nil
 }
 
-// UpdateUserInfo update user info
+// UpdateUserInfo update the download traffic of the user and the egress of its tenant
 func (n *SQLDB) UpdateUserInfo(userID string, incSize, incCount int64) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("UpdateUserInfo Rollback err:%s", err.Error())
+		}
+	}()
+
 	query := fmt.Sprintf(
 		`UPDATE %s SET total_traffic=total_traffic+?,download_count=download_count+? WHERE user_id=?`, userInfoTable)
-	_, err := n.db.Exec(query, incSize, incCount, userID)
-	return err
+	_, err = tx.Exec(query, incSize, incCount, userID)
+	if err != nil {
+		return err
+	}
+
+	// the download traffic of the user is counted against the traffic quota of its tenant
+	if err = addTenantEgress(tx, userID, incSize); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // UpdateUserPeakSize update user peakSize
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// LoadLastValidationProof load the last validation proof of the node, nil if the node has no proof
+func (n *SQLDB) LoadLastValidationProof(nodeID string) (*types.ValidationProof, error) {
+	var out types.ValidationProof
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY seq DESC LIMIT 1", validationProofTable)
+	if err := n.db.Get(&out, query, nodeID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// SaveValidationProof appends the proof to the chain of the node, a proof of the seq of the node saved already fails the save
+func (n *SQLDB) SaveValidationProof(proof *types.ValidationProof) error {
+	query := fmt.Sprintf(`INSERT INTO %s (node_id, seq, round_id, validator_id, status, block_number, bandwidth, report, validator_sign,
+				prev_hash, hash, scheduler_sign, created_time) VALUES (:node_id, :seq, :round_id, :validator_id, :status, :block_number,
+				:bandwidth, :report, :validator_sign, :prev_hash, :hash, :scheduler_sign, :created_time)`, validationProofTable)
+	_, err := n.db.NamedExec(query, proof)
+	return err
+}
+
+// LoadValidationProofs load the validation proofs of the node, the latest first
+func (n *SQLDB) LoadValidationProofs(nodeID string, limit, offset int) (*types.ListValidationProofRsp, error) {
+	res := new(types.ListValidationProofRsp)
+
+	if limit > loadValidationResultsDefaultLimit || limit <= 0 {
+		limit = loadValidationResultsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", validationProofTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY seq DESC LIMIT ? OFFSET ?", validationProofTable)
+	if err := n.db.Select(&res.Data, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadValidationProofChain load the validation proofs of the node after the seq in the order of the chain
+func (n *SQLDB) LoadValidationProofChain(nodeID string, afterSeq int64, limit int) ([]*types.ValidationProof, error) {
+	var out []*types.ValidationProof
+	query := fmt.Sprintf("SELECT * FROM %s WHERE node_id=? AND seq>? ORDER BY seq LIMIT ?", validationProofTable)
+	if err := n.db.Select(&out, query, nodeID, afterSeq, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
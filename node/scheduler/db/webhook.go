@@ -0,0 +1,189 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// the webhook deliveries loaded at most if the request does not ask for less
+const loadWebhookDeliveriesDefaultLimit = 500
+
+// SaveWebhook saves the webhook and returns its id
+func (n *SQLDB) SaveWebhook(w *types.Webhook) (int64, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, tenant_id, hash, cid, url, events, secret)
+				VALUES (:user_id, :tenant_id, :hash, :cid, :url, :events, :secret)`, webhookTable)
+	result, err := n.db.NamedExec(query, w)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// LoadWebhook load the webhook
+func (n *SQLDB) LoadWebhook(id int64) (*types.Webhook, error) {
+	var out types.Webhook
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=?`, webhookTable)
+	if err := n.db.Get(&out, query, id); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// DeleteWebhook deletes the webhook and its deliveries
+func (n *SQLDB) DeleteWebhook(id int64) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteWebhook Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, webhookTable)
+	if _, err = tx.Exec(query, id); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE webhook_id=?`, webhookDeliveryTable)
+	if _, err = tx.Exec(query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadWebhooks load the asset webhooks of the user, or the tenant webhooks of the tenant if the user is empty
+func (n *SQLDB) LoadWebhooks(userID, tenantID string) ([]*types.Webhook, error) {
+	var out []*types.Webhook
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=? AND tenant_id=? ORDER BY id`, webhookTable)
+	if err := n.db.Select(&out, query, userID, tenantID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadWebhooksOfAsset load the webhooks of the asset registered by its users and the webhooks of the tenants of its users
+func (n *SQLDB) LoadWebhooksOfAsset(hash string, users []string) ([]*types.Webhook, error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(`SELECT DISTINCT w.* FROM %s w LEFT JOIN %s t ON w.tenant_id=t.tenant_id
+				WHERE (w.hash=? AND w.user_id IN (?)) OR (w.hash='' AND t.user_id IN (?))`, webhookTable, tenantUserTable), hash, users, users)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.Webhook
+	if err = n.db.Select(&out, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadWebhookCursor load the id of the last outbox event the webhooks followed,
+// the webhooks start following after the latest outbox event
+func (n *SQLDB) LoadWebhookCursor() (int64, error) {
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (name, outbox_id) SELECT 'asset', IFNULL(MAX(id),0) FROM %s`, webhookCursorTable, outboxTable)
+	if _, err := n.db.Exec(query); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	query = fmt.Sprintf(`SELECT outbox_id FROM %s WHERE name='asset'`, webhookCursorTable)
+	err := n.db.Get(&id, query)
+	return id, err
+}
+
+// SaveWebhookDeliveries saves the callbacks of the outbox events and moves the cursor past the events in a transaction
+func (n *SQLDB) SaveWebhookDeliveries(deliveries []*types.WebhookDelivery, cursor int64) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveWebhookDeliveries Rollback err:%s", err.Error())
+		}
+	}()
+
+	for _, d := range deliveries {
+		query := fmt.Sprintf(`INSERT INTO %s (webhook_id, event, hash, payload, status, next_time)
+					VALUES (:webhook_id, :event, :hash, :payload, :status, :next_time)`, webhookDeliveryTable)
+		if _, err = tx.NamedExec(query, d); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (name, outbox_id) VALUES ('asset', ?) ON DUPLICATE KEY UPDATE outbox_id=?`, webhookCursorTable)
+	if _, err = tx.Exec(query, cursor, cursor); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadDueWebhookDeliveries load the pending callbacks due before the time, the earliest due first
+func (n *SQLDB) LoadDueWebhookDeliveries(before time.Time, limit int) ([]*types.WebhookDelivery, error) {
+	var out []*types.WebhookDelivery
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE status=? AND next_time<=? ORDER BY next_time LIMIT ?`, webhookDeliveryTable)
+	if err := n.db.Select(&out, query, types.WebhookDeliveryPending, before, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateWebhookDelivery updates the status, the attempts and the next time of the callback
+func (n *SQLDB) UpdateWebhookDelivery(d *types.WebhookDelivery) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=:status, attempts=:attempts, next_time=:next_time, last_error=:last_error,
+				updated_time=NOW() WHERE id=:id`, webhookDeliveryTable)
+	_, err := n.db.NamedExec(query, d)
+	return err
+}
+
+// LoadWebhookDeliveries load the callbacks of the webhook, the latest first
+func (n *SQLDB) LoadWebhookDeliveries(webhookID int64, limit, offset int) (*types.ListWebhookDeliveryRsp, error) {
+	res := new(types.ListWebhookDeliveryRsp)
+
+	if limit > loadWebhookDeliveriesDefaultLimit || limit <= 0 {
+		limit = loadWebhookDeliveriesDefaultLimit
+	}
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE webhook_id=?`, webhookDeliveryTable)
+	if err := n.db.Get(&res.Total, query, webhookID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`SELECT * FROM %s WHERE webhook_id=? ORDER BY id DESC LIMIT ? OFFSET ?`, webhookDeliveryTable)
+	if err := n.db.Select(&res.Data, query, webhookID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SaveAssetLifecycleEvent writes the expired or purged event of the asset to the outbox with the users of the asset,
+// the event is written before the users of the asset are removed
+func (n *SQLDB) SaveAssetLifecycleEvent(topic types.OutboxTopic, hash, cid string) error {
+	users, err := n.ListUsersForAsset(hash)
+	if err != nil {
+		return err
+	}
+
+	return saveOutboxEvent(n.db, topic, hash, &types.OutboxAssetLifecyclePayload{Hash: hash, CID: cid, Users: users})
+}
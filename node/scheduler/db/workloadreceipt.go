@@ -0,0 +1,100 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// SaveWorkloadReceipts saves the sampled workload receipts, the receipts of the tokens already sampled are ignored
+func (n *SQLDB) SaveWorkloadReceipts(receipts []*types.WorkloadReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT IGNORE INTO %s (token_id, node_id, client_id, download_size, start_time, end_time, sign, client_key, status)
+				VALUES (:token_id, :node_id, :client_id, :download_size, :start_time, :end_time, :sign, :client_key, :status)`, workloadReceiptTable)
+	_, err := n.db.NamedExec(query, receipts)
+	return err
+}
+
+// LoadPendingWorkloadReceipts loads the pending receipts of the tokens
+func (n *SQLDB) LoadPendingWorkloadReceipts(tokenIDs []string) ([]*types.WorkloadReceipt, error) {
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(`SELECT * FROM %s WHERE token_id in (?) AND status=?`, workloadReceiptTable), tokenIDs, types.WorkloadVerifyPending)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.WorkloadReceipt
+	if err := n.db.Select(&out, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UpdateWorkloadReceiptResult updates the verification result of the receipt
+func (n *SQLDB) UpdateWorkloadReceiptResult(tokenID string, status types.WorkloadVerifyStatus, reportedSize int64) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=?, reported_size=? WHERE token_id=?`, workloadReceiptTable)
+	_, err := n.db.Exec(query, status, reportedSize, tokenID)
+	return err
+}
+
+// LoadWorkloadReceipts loads the sampled workload receipts of the node, the latest first
+func (n *SQLDB) LoadWorkloadReceipts(nodeID string, limit, offset int) (*types.ListWorkloadReceiptRsp, error) {
+	res := new(types.ListWorkloadReceiptRsp)
+
+	if limit > loadWorkloadReceiptsDefaultLimit || limit <= 0 {
+		limit = loadWorkloadReceiptsDefaultLimit
+	}
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE node_id=?", workloadReceiptTable)
+	if err := n.db.Get(&res.Total, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE node_id=? ORDER BY created_time DESC LIMIT ? OFFSET ?", workloadReceiptTable)
+	if err := n.db.Select(&res.Receipts, query, nodeID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadWorkloadVerificationStats counts the receipts sampled in [start, end) of the node by their verification result, of all the nodes if nodeID is empty
+func (n *SQLDB) LoadWorkloadVerificationStats(nodeID string, start, end time.Time) (*types.WorkloadVerificationStats, error) {
+	where := "created_time>=? AND created_time<?"
+	args := []interface{}{start, end}
+	if nodeID != "" {
+		where += " AND node_id=?"
+		args = append(args, nodeID)
+	}
+
+	query := fmt.Sprintf(`SELECT count(*) AS sampled, COALESCE(SUM(status='pending'), 0) AS pending, COALESCE(SUM(status='matched'), 0) AS matched,
+				COALESCE(SUM(status='overreported'), 0) AS overreported, COALESCE(SUM(status='underreported'), 0) AS underreported,
+				COALESCE(SUM(status='unreported'), 0) AS unreported,
+				CAST(COALESCE(SUM(IF(status<>'pending', download_size, 0)), 0) AS SIGNED) AS receipt_bytes,
+				CAST(COALESCE(SUM(IF(status<>'pending', reported_size, 0)), 0) AS SIGNED) AS reported_bytes
+				FROM %s WHERE %s`, workloadReceiptTable, where)
+
+	stats := &types.WorkloadVerificationStats{}
+	if err := n.db.Get(stats, query, args...); err != nil {
+		return nil, err
+	}
+
+	stats.NodeID, stats.Start, stats.End = nodeID, start, end
+	return stats, nil
+}
+
+// DeleteWorkloadReceiptsBefore deletes the workload receipts sampled before the time
+func (n *SQLDB) DeleteWorkloadReceiptsBefore(before time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, workloadReceiptTable)
+	_, err := n.db.Exec(query, before)
+	return err
+}
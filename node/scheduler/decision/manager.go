@@ -0,0 +1,240 @@
+package decision
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("decision")
+
+const (
+	// the number of the filtered steps kept per decision, the chosen steps are always kept
+	maxFilteredSteps = 256
+	queueSize        = 4096
+	batchSize        = 100
+	flushInterval    = 2 * time.Second
+	pruneInterval    = 10 * time.Minute
+)
+
+// Manager writes the scheduling decisions to the decision log in batches and prunes the decisions older than the retention,
+// the decisions are dropped rather than blocking the scheduling when the log falls behind
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	*db.SQLDB
+
+	queue chan *types.SchedulingDecision
+
+	lk            sync.RWMutex
+	retentionDays int
+	dropped       int64
+}
+
+// NewManager return new decision manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager) *Manager {
+	m := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		SQLDB:         sdb,
+		queue:         make(chan *types.SchedulingDecision, queueSize),
+	}
+
+	m.loadRetention()
+
+	diagnostics.RegisterSize("decision.queue", func() int { return len(m.queue) })
+
+	go m.startWriter()
+	go m.startPruneTimer()
+
+	return m
+}
+
+func (m *Manager) loadRetention() {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	m.lk.Lock()
+	m.retentionDays = cfg.DecisionLogRetentionDays
+	m.lk.Unlock()
+}
+
+func (m *Manager) enabled() bool {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	return m.retentionDays > 0
+}
+
+// Begin starts recording a decision, it returns nil if the decision log is disabled
+func (m *Manager) Begin(kind types.SchedulingDecisionKind, subject, params string) *Recorder {
+	if m == nil || !m.enabled() {
+		return nil
+	}
+
+	return &Recorder{
+		m: m,
+		d: &types.SchedulingDecision{
+			Kind:     kind,
+			Subject:  subject,
+			Params:   params,
+			Filtered: make(map[string]int),
+		},
+	}
+}
+
+func (m *Manager) enqueue(d *types.SchedulingDecision) {
+	select {
+	case m.queue <- d:
+	default:
+		m.lk.Lock()
+		m.dropped++
+		dropped := m.dropped
+		m.lk.Unlock()
+
+		if dropped%1000 == 1 {
+			log.Warnf("decision log falls behind, %d decisions dropped", dropped)
+		}
+	}
+}
+
+func (m *Manager) startWriter() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*types.SchedulingDecision, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := m.SaveSchedulingDecisions(batch); err != nil {
+			log.Errorf("SaveSchedulingDecisions err:%s", err.Error())
+		}
+		batch = make([]*types.SchedulingDecision, 0, batchSize)
+	}
+
+	for {
+		select {
+		case d := <-m.queue:
+			detail, err := encodeDetail(d)
+			if err != nil {
+				log.Errorf("encode decision detail err:%s", err.Error())
+				continue
+			}
+			d.Detail = detail
+
+			batch = append(batch, d)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (m *Manager) startPruneTimer() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("decision.prune", pruneInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.loadRetention()
+		m.prune(time.Now())
+		done()
+	}
+}
+
+// prune deletes the decisions older than the retention, the decisions are kept if the log is disabled
+func (m *Manager) prune(now time.Time) {
+	m.lk.RLock()
+	days := m.retentionDays
+	m.lk.RUnlock()
+
+	if days <= 0 || !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	if err := m.DeleteSchedulingDecisionsBefore(now.AddDate(0, 0, -days)); err != nil {
+		log.Errorf("DeleteSchedulingDecisionsBefore err:%s", err.Error())
+	}
+}
+
+// Replay loads the decision with the steps in the order the nodes were considered
+func (m *Manager) Replay(id int64) (*types.SchedulingDecision, error) {
+	d, err := m.LoadSchedulingDecision(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = decodeDetail(d); err != nil {
+		return nil, err
+	}
+
+	d.Detail = nil
+	return d, nil
+}
+
+type detail struct {
+	Filtered map[string]int        `json:"f,omitempty"`
+	Steps    []*types.DecisionStep `json:"s"`
+}
+
+// encodeDetail compresses the filtered counts and the steps of the decision
+func encodeDetail(d *types.SchedulingDecision) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+
+	if err := json.NewEncoder(w).Encode(&detail{Filtered: d.Filtered, Steps: d.Steps}); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeDetail restores the filtered counts and the steps of the decision
+func decodeDetail(d *types.SchedulingDecision) error {
+	if len(d.Detail) == 0 {
+		return nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(d.Detail))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var v detail
+	if err = json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	d.Filtered = v.Filtered
+	d.Steps = v.Steps
+	return nil
+}
@@ -0,0 +1,61 @@
+package decision
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestRecorder(t *testing.T) {
+	m := &Manager{queue: make(chan *types.SchedulingDecision, 1), retentionDays: 1}
+
+	r := m.Begin(types.DecisionPullEdges, "hash", "count:1")
+	for i := 0; i < maxFilteredSteps+10; i++ {
+		r.Filter("e_filtered", "pulling", 1, float64(i))
+	}
+	r.Choose("e_chosen", 3, 0.5)
+	r.Commit()
+
+	d := <-m.queue
+	if d.Considered != maxFilteredSteps+11 || d.Chosen != 1 || !d.Truncated {
+		t.Fatalf("unexpected counts considered:%d chosen:%d truncated:%v", d.Considered, d.Chosen, d.Truncated)
+	}
+	if d.Filtered["pulling"] != maxFilteredSteps+10 {
+		t.Fatalf("expected %d filtered, got %d", maxFilteredSteps+10, d.Filtered["pulling"])
+	}
+	if len(d.Steps) != maxFilteredSteps+1 || d.Steps[maxFilteredSteps].NodeID != "e_chosen" {
+		t.Fatalf("expected the chosen step kept after the truncated steps, got %d steps", len(d.Steps))
+	}
+
+	var nilRec *Recorder
+	nilRec.Choose("e_chosen", 1, 0)
+	nilRec.Commit()
+
+	if (&Manager{}).Begin(types.DecisionValidation, "", "") != nil {
+		t.Fatal("expected nil recorder when the log is disabled")
+	}
+}
+
+func TestDetail(t *testing.T) {
+	d := &types.SchedulingDecision{
+		Filtered: map[string]int{"overloaded": 2},
+		Steps: []*types.DecisionStep{
+			{NodeID: "c_1", Outcome: "overloaded", Weight: 2, Value: 10},
+			{NodeID: "c_2", Outcome: types.DecisionOutcomeChosen, Weight: 5, Value: 3},
+		},
+	}
+
+	detail, err := encodeDetail(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := &types.SchedulingDecision{Detail: detail}
+	if err = decodeDetail(replayed); err != nil {
+		t.Fatal(err)
+	}
+
+	if replayed.Filtered["overloaded"] != 2 || len(replayed.Steps) != 2 || *replayed.Steps[1] != *d.Steps[1] {
+		t.Fatalf("unexpected replayed decision %+v", replayed)
+	}
+}
@@ -0,0 +1,56 @@
+package decision
+
+import (
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// Recorder collects the nodes considered by a decision in order, the methods of a nil recorder do nothing
+// so the scheduling code records unconditionally
+type Recorder struct {
+	m *Manager
+	d *types.SchedulingDecision
+
+	filteredSteps int
+}
+
+// Choose records the node is chosen
+func (r *Recorder) Choose(nodeID string, weight int, value float64) {
+	if r == nil {
+		return
+	}
+
+	r.d.Considered++
+	r.d.Chosen++
+	r.d.Steps = append(r.d.Steps, &types.DecisionStep{NodeID: nodeID, Outcome: types.DecisionOutcomeChosen, Weight: weight, Value: value})
+}
+
+// Filter records the node is filtered out for the reason, only the first filtered steps are kept
+// and the rest are counted by reason
+func (r *Recorder) Filter(nodeID, reason string, weight int, value float64) {
+	if r == nil {
+		return
+	}
+
+	r.d.Considered++
+	r.d.Filtered[reason]++
+
+	if r.filteredSteps >= maxFilteredSteps {
+		r.d.Truncated = true
+		return
+	}
+
+	r.filteredSteps++
+	r.d.Steps = append(r.d.Steps, &types.DecisionStep{NodeID: nodeID, Outcome: reason, Weight: weight, Value: value})
+}
+
+// Commit queues the decision to be written to the log
+func (r *Recorder) Commit() {
+	if r == nil {
+		return
+	}
+
+	r.d.CreatedTime = time.Now()
+	r.m.enqueue(r.d)
+}
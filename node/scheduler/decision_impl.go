@@ -0,0 +1,17 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// ListSchedulingDecisions list the decisions without their steps, the latest first
+func (s *Scheduler) ListSchedulingDecisions(ctx context.Context, req *types.ListSchedulingDecisionsReq) (*types.ListSchedulingDecisionRsp, error) {
+	return s.db.LoadSchedulingDecisions(req)
+}
+
+// ReplaySchedulingDecision get the decision with the nodes in the order they were considered
+func (s *Scheduler) ReplaySchedulingDecision(ctx context.Context, id int64) (*types.SchedulingDecision, error) {
+	return s.DecisionManager.Replay(id)
+}
@@ -0,0 +1,222 @@
+package denylist
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("denylist")
+
+const (
+	// the denylist is reloaded to pick up the changes made by the other schedulers
+	reloadInterval = time.Minute
+	// the blocked pulls and retrievals of an asset are recorded once per interval
+	blockedEventInterval = 10 * time.Minute
+	// SourceAdmin the source of the assets banned by the admin
+	SourceAdmin = "admin"
+)
+
+// Manager keeps the banned assets in memory for the scheduling and the retrieval routing to check
+type Manager struct {
+	*db.SQLDB
+
+	lk      sync.RWMutex
+	hashes  map[string]struct{}
+	onAdded []func(hash string) // called for the assets newly banned, by this scheduler or the others
+	loaded  bool
+
+	blockedLk sync.Mutex
+	blocked   map[string]time.Time // key: event type and asset hash
+}
+
+// NewManager return new denylist manager instance
+func NewManager(sdb *db.SQLDB) *Manager {
+	m := &Manager{
+		SQLDB:   sdb,
+		hashes:  make(map[string]struct{}),
+		blocked: make(map[string]time.Time),
+	}
+
+	if err := m.reload(); err != nil {
+		log.Errorf("load denylist err:%s", err.Error())
+	}
+
+	go m.startReloadTimer()
+
+	return m
+}
+
+func (m *Manager) startReloadTimer() {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := m.reload(); err != nil {
+			log.Errorf("reload denylist err:%s", err.Error())
+		}
+	}
+}
+
+func (m *Manager) reload() error {
+	list, err := m.LoadDenylistHashes()
+	if err != nil {
+		return err
+	}
+
+	hashes := make(map[string]struct{}, len(list))
+	for _, hash := range list {
+		hashes[hash] = struct{}{}
+	}
+
+	m.lk.Lock()
+	added := make([]string, 0)
+	for hash := range hashes {
+		if _, ok := m.hashes[hash]; !ok {
+			added = append(added, hash)
+		}
+	}
+	loaded := m.loaded
+	m.hashes = hashes
+	m.loaded = true
+	m.lk.Unlock()
+
+	// the assets banned before the scheduler started are purged by the scheduler that banned them
+	if loaded {
+		m.notifyAdded(added)
+	}
+
+	return nil
+}
+
+// Subscribe registers the function called for the assets newly banned
+func (m *Manager) Subscribe(fn func(hash string)) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	m.onAdded = append(m.onAdded, fn)
+}
+
+func (m *Manager) notifyAdded(hashes []string) {
+	m.lk.RLock()
+	fns := m.onAdded
+	m.lk.RUnlock()
+
+	for _, hash := range hashes {
+		for _, fn := range fns {
+			fn(hash)
+		}
+	}
+}
+
+// IsDenied checks whether the asset is banned
+func (m *Manager) IsDenied(hash string) bool {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	_, ok := m.hashes[hash]
+	return ok
+}
+
+// Add bans the assets, it returns the assets newly banned
+func (m *Manager) Add(cids []string, reason, source, actor string) ([]*types.DenylistEntry, error) {
+	entries := make([]*types.DenylistEntry, 0, len(cids))
+	for _, cid := range cids {
+		hash, err := cidutil.CIDToHash(cid)
+		if err != nil {
+			return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+		}
+
+		entries = append(entries, &types.DenylistEntry{Hash: hash, CID: cid, Reason: reason, Source: source, Actor: actor})
+	}
+
+	added, err := m.SaveDenylistEntries(entries)
+	if err != nil {
+		return nil, xerrors.Errorf("SaveDenylistEntries err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	for _, entry := range entries {
+		m.hashes[entry.Hash] = struct{}{}
+	}
+	m.lk.Unlock()
+
+	hashes := make([]string, 0, len(added))
+	for _, entry := range added {
+		hashes = append(hashes, entry.Hash)
+	}
+	m.notifyAdded(hashes)
+
+	return added, nil
+}
+
+// Remove lifts the ban of the asset
+func (m *Manager) Remove(cid, actor string) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	if err := m.DeleteDenylistEntry(hash, cid, actor); err != nil {
+		return xerrors.Errorf("DeleteDenylistEntry %s err:%s", cid, err.Error())
+	}
+
+	m.lk.Lock()
+	delete(m.hashes, hash)
+	m.lk.Unlock()
+
+	return nil
+}
+
+// Import bans the assets listed by the denylist, it returns the assets newly banned
+func (m *Manager) Import(r io.Reader, source, reason, actor string) ([]*types.DenylistEntry, *types.DenylistImportResult, error) {
+	cids, skipped, invalid, err := Parse(r)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("parse denylist err:%s", err.Error())
+	}
+
+	added, err := m.Add(cids, reason, source, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return added, &types.DenylistImportResult{Added: len(added), Skipped: skipped, Invalid: invalid}, nil
+}
+
+// RecordEvent appends the event to the audit trail, the failure is logged
+func (m *Manager) RecordEvent(hash, cid string, event types.DenylistEventType, detail, actor string) {
+	e := &types.DenylistEvent{Hash: hash, CID: cid, Event: event, Detail: detail, Actor: actor}
+	if err := m.SaveDenylistEvent(e); err != nil {
+		log.Errorf("save denylist event %s of %s err:%s", event, cid, err.Error())
+	}
+}
+
+// RecordBlocked records a refused pull or a blocked retrieval of the asset,
+// the events of an asset are recorded once per interval to keep the repeated requests out of the audit trail
+func (m *Manager) RecordBlocked(hash, cid string, event types.DenylistEventType, detail string) {
+	key := string(event) + hash
+
+	m.blockedLk.Lock()
+	last, ok := m.blocked[key]
+	if ok && time.Since(last) < blockedEventInterval {
+		m.blockedLk.Unlock()
+		return
+	}
+	m.blocked[key] = time.Now()
+
+	// forget the assets not requested recently
+	for k, t := range m.blocked {
+		if time.Since(t) >= blockedEventInterval {
+			delete(m.blocked, k)
+		}
+	}
+	m.blockedLk.Unlock()
+
+	m.RecordEvent(hash, cid, event, detail, "")
+}
@@ -0,0 +1,88 @@
+package denylist
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+)
+
+// the invalid lines kept in the import result
+const maxInvalidLines = 20
+
+// Parse parses a denylist, one cid per line, either bare or as /ipfs/<cid>.
+// The compact denylist format is accepted, its header is skipped, and so are the comments,
+// the allow rules, the double-hashed entries, the paths under a cid and the ipns names,
+// since they can not be matched to the assets
+func Parse(r io.Reader) (cids []string, skipped int, invalid []string, err error) {
+	cids = make([]string, 0)
+
+	// the lines before the first "---" may be the header of a compact denylist
+	headerDone := false
+	headerCIDs, headerLines, headerInvalid := 0, 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !headerDone {
+			headerLines++
+		}
+
+		if line == "---" {
+			if !headerDone {
+				// the lines above are the header, not the entries
+				cids = cids[:len(cids)-headerCIDs]
+				invalid = invalid[:len(invalid)-headerInvalid]
+				skipped = headerLines
+				headerDone = true
+				continue
+			}
+
+			skipped++
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "//") {
+			skipped++
+			continue
+		}
+
+		// the hints follow the entry
+		line = strings.Fields(line)[0]
+
+		if strings.HasPrefix(line, "/ipns/") {
+			skipped++
+			continue
+		}
+
+		s := strings.TrimSuffix(strings.TrimPrefix(line, "/ipfs/"), "/")
+		if strings.Contains(s, "/") {
+			skipped++
+			continue
+		}
+
+		c, err := cid.Decode(s)
+		if err != nil {
+			if len(invalid) < maxInvalidLines {
+				invalid = append(invalid, line)
+				if !headerDone {
+					headerInvalid++
+				}
+			}
+			continue
+		}
+
+		cids = append(cids, c.String())
+		if !headerDone {
+			headerCIDs++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	return cids, skipped, invalid, nil
+}
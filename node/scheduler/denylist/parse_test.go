@@ -0,0 +1,57 @@
+package denylist
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testCID1 = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	testCID2 = "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+)
+
+func TestParse(t *testing.T) {
+	content := strings.Join([]string{
+		"version: 1",
+		"name: test",
+		"---",
+		"# comment",
+		"/ipfs/" + testCID1,
+		testCID2 + " reason=abuse",
+		"!/ipfs/" + testCID1,
+		"//d9d295bde21f422d471a90f2a37ec53049fdf3e5fa3ee2e8f20e10003da429e7",
+		"/ipfs/" + testCID1 + "/path/to/file",
+		"/ipns/example.com",
+		"not-a-cid",
+		"",
+	}, "\n")
+
+	cids, skipped, invalid, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cids) != 2 || cids[0] != testCID1 || cids[1] != testCID2 {
+		t.Fatalf("unexpected cids %v", cids)
+	}
+
+	// the header, the comment, the allow rule, the hashed entry, the path and the ipns name
+	if skipped != 8 {
+		t.Fatalf("expected 8 skipped lines, got %d", skipped)
+	}
+
+	if len(invalid) != 1 || invalid[0] != "not-a-cid" {
+		t.Fatalf("unexpected invalid lines %v", invalid)
+	}
+}
+
+func TestParseWithoutHeader(t *testing.T) {
+	cids, _, invalid, err := Parse(strings.NewReader(testCID1 + "\n" + testCID2 + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cids) != 2 || len(invalid) != 0 {
+		t.Fatalf("unexpected cids %v invalid %v", cids, invalid)
+	}
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/Filecoin-Titan/titan/node/scheduler/denylist"
+	"golang.org/x/xerrors"
+)
+
+// AddToDenylist bans the assets, the replicas of the assets are purged from the nodes
+func (s *Scheduler) AddToDenylist(ctx context.Context, cids []string, reason string) error {
+	if len(cids) == 0 {
+		return xerrors.New("cids is empty")
+	}
+
+	_, err := s.DenylistManager.Add(cids, reason, denylist.SourceAdmin, handler.GetCallerID(ctx))
+	return err
+}
+
+// RemoveFromDenylist lifts the ban of the asset, the purged replicas are not restored
+func (s *Scheduler) RemoveFromDenylist(ctx context.Context, cid string) error {
+	err := s.DenylistManager.Remove(cid, handler.GetCallerID(ctx))
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("asset %s is not in the denylist", cid)
+	}
+	return err
+}
+
+// ImportDenylist bans the assets listed by an external denylist
+func (s *Scheduler) ImportDenylist(ctx context.Context, source, reason, content string) (*types.DenylistImportResult, error) {
+	if source == "" || source == denylist.SourceAdmin {
+		return nil, xerrors.Errorf("invalid source %q", source)
+	}
+
+	_, result, err := s.DenylistManager.Import(strings.NewReader(content), source, reason, handler.GetCallerID(ctx))
+	return result, err
+}
+
+// ListDenylist list the banned assets, the latest first
+func (s *Scheduler) ListDenylist(ctx context.Context, limit, offset int) (*types.ListDenylistRsp, error) {
+	return s.DenylistManager.LoadDenylist(limit, offset)
+}
+
+// ListDenylistEvents list the audit trail of the denylist, the latest first, all the assets if cid is empty
+func (s *Scheduler) ListDenylistEvents(ctx context.Context, cid string, limit, offset int) (*types.ListDenylistEventsRsp, error) {
+	hash := ""
+	if cid != "" {
+		var err error
+		if hash, err = cidutil.CIDToHash(cid); err != nil {
+			return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+		}
+	}
+
+	return s.DenylistManager.LoadDenylistEvents(hash, limit, offset)
+}
+
+// checkRetrievalDenied refuses to route the retrieval of the banned asset
+func (s *Scheduler) checkRetrievalDenied(hash, cid string) error {
+	if !s.DenylistManager.IsDenied(hash) {
+		return nil
+	}
+
+	s.DenylistManager.RecordBlocked(hash, cid, types.DenylistEventBlockRetrieval, "")
+	return &api.ErrWeb{Code: terrors.AssetDenied.Int(), Message: fmt.Sprintf("the asset %s is denied", cid)}
+}
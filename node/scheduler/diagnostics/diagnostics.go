@@ -0,0 +1,221 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maximum time to wait for the size of a registry
+	sizeTimeout = time.Second
+	// maximum duration of a cpu profile
+	maxCPUProfileDuration = 60 * time.Second
+)
+
+var startTime = time.Now()
+
+var (
+	lk     sync.Mutex
+	timers = make(map[string]*Timer)
+	sizes  = make(map[string]func() int)
+)
+
+// Timer tracks the runs of a periodic task
+type Timer struct {
+	name     string
+	interval time.Duration
+
+	lk           sync.Mutex
+	runs         int64
+	running      bool
+	lastStart    time.Time
+	lastEnd      time.Time
+	lastDuration time.Duration
+}
+
+// NewTimer registers the periodic task running at the interval, a registered timer of the same name is replaced
+func NewTimer(name string, interval time.Duration) *Timer {
+	t := &Timer{name: name, interval: interval}
+
+	lk.Lock()
+	timers[name] = t
+	lk.Unlock()
+
+	return t
+}
+
+// Start marks a run of the task started, the returned function marks it ended
+func (t *Timer) Start() func() {
+	t.lk.Lock()
+	t.runs++
+	t.running = true
+	t.lastStart = time.Now()
+	t.lk.Unlock()
+
+	return func() {
+		t.lk.Lock()
+		t.running = false
+		t.lastEnd = time.Now()
+		t.lastDuration = t.lastEnd.Sub(t.lastStart)
+		t.lk.Unlock()
+	}
+}
+
+func (t *Timer) status(now time.Time) *types.TimerStatus {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	status := &types.TimerStatus{
+		Name:         t.name,
+		Interval:     t.interval,
+		Runs:         t.runs,
+		Running:      t.running,
+		LastStart:    t.lastStart,
+		LastEnd:      t.lastEnd,
+		LastDuration: t.lastDuration,
+	}
+	if t.running {
+		status.RunningFor = now.Sub(t.lastStart)
+	}
+
+	return status
+}
+
+// RegisterSize registers an in-memory registry with the function returning its number of entries
+func RegisterSize(name string, size func() int) {
+	lk.Lock()
+	defer lk.Unlock()
+
+	sizes[name] = size
+}
+
+// Runtime returns the runtime state of the process with the registries and the timers
+func Runtime() *types.RuntimeDiagnostics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return &types.RuntimeDiagnostics{
+		StartTime:    startTime,
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		GCPauseTotal: time.Duration(mem.PauseTotalNs),
+		Registries:   registrySizes(),
+		Timers:       timerStatuses(),
+	}
+}
+
+func timerStatuses() []*types.TimerStatus {
+	lk.Lock()
+	list := make([]*Timer, 0, len(timers))
+	for _, t := range timers {
+		list = append(list, t)
+	}
+	lk.Unlock()
+
+	now := time.Now()
+	out := make([]*types.TimerStatus, 0, len(list))
+	for _, t := range list {
+		out = append(out, t.status(now))
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// registrySizes gets the sizes of the registries concurrently, a registry not answering in time is reported as -1
+func registrySizes() []*types.RegistrySize {
+	lk.Lock()
+	names := make([]string, 0, len(sizes))
+	chs := make([]chan int, 0, len(sizes))
+	for name, size := range sizes {
+		ch := make(chan int, 1)
+		go func(size func() int) {
+			ch <- size()
+		}(size)
+
+		names = append(names, name)
+		chs = append(chs, ch)
+	}
+	lk.Unlock()
+
+	deadline := time.NewTimer(sizeTimeout)
+	defer deadline.Stop()
+
+	out := make([]*types.RegistrySize, 0, len(names))
+	for i, name := range names {
+		size := -1
+		select {
+		case size = <-chs[i]:
+		case <-deadline.C:
+			// the rest are only taken if they have answered
+			select {
+			case size = <-chs[i]:
+			default:
+			}
+			deadline.Reset(0)
+		}
+
+		out = append(out, &types.RegistrySize{Name: name, Size: size})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// GoroutineDump returns the stacks of all the goroutines in the format of an unrecovered panic
+func GoroutineDump() (string, error) {
+	buf := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(buf, 2); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Profile returns the pprof profile of the name in the gzipped protobuf format read by go tool pprof,
+// the cpu profile is collected for the duration
+func Profile(ctx context.Context, name string, duration time.Duration) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if name == "cpu" {
+		if duration <= 0 || duration > maxCPUProfileDuration {
+			return nil, xerrors.Errorf("cpu profile duration must be in (0, %s]", maxCPUProfileDuration)
+		}
+
+		if err := pprof.StartCPUProfile(buf); err != nil {
+			return nil, err
+		}
+		select {
+		case <-time.After(duration):
+		case <-ctx.Done():
+		}
+		pprof.StopCPUProfile()
+
+		return buf.Bytes(), nil
+	}
+
+	p := pprof.Lookup(name)
+	if p == nil {
+		return nil, xerrors.Errorf("unknown profile %s", name)
+	}
+
+	if err := p.WriteTo(buf, 0); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,48 @@
+package diagnostics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	timer := NewTimer("test.timer", time.Minute)
+
+	done := timer.Start()
+	status := timer.status(time.Now().Add(time.Second))
+	if !status.Running || status.Runs != 1 || status.RunningFor < time.Second {
+		t.Errorf("unexpected running status %+v", status)
+	}
+
+	done()
+	status = timer.status(time.Now())
+	if status.Running || status.RunningFor != 0 || status.LastEnd.Before(status.LastStart) {
+		t.Errorf("unexpected ended status %+v", status)
+	}
+}
+
+func TestRegistrySizes(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	RegisterSize("test.ok", func() int { return 3 })
+	RegisterSize("test.stuck", func() int {
+		<-block
+		return 1
+	})
+	defer func() {
+		lk.Lock()
+		delete(sizes, "test.ok")
+		delete(sizes, "test.stuck")
+		lk.Unlock()
+	}()
+
+	got := make(map[string]int)
+	for _, size := range registrySizes() {
+		got[size.Name] = size.Size
+	}
+
+	if got["test.ok"] != 3 || got["test.stuck"] != -1 {
+		t.Errorf("unexpected sizes %v", got)
+	}
+}
@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+)
+
+// GetRuntimeDiagnostics get the runtime state of the scheduler with the sizes of the in-memory registries and the status of the timers
+func (s *Scheduler) GetRuntimeDiagnostics(ctx context.Context) (*types.RuntimeDiagnostics, error) {
+	return diagnostics.Runtime(), nil
+}
+
+// GetGoroutineDump get the stacks of all the goroutines of the scheduler
+func (s *Scheduler) GetGoroutineDump(ctx context.Context) (string, error) {
+	return diagnostics.GoroutineDump()
+}
+
+// GetProfile get the pprof profile of the name read by go tool pprof, the cpu profile is collected for the seconds
+func (s *Scheduler) GetProfile(ctx context.Context, name string, seconds int) ([]byte, error) {
+	return diagnostics.Profile(ctx, name, time.Duration(seconds)*time.Second)
+}
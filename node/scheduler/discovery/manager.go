@@ -0,0 +1,224 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/build"
+	"github.com/Filecoin-Titan/titan/lib/etcdcli"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("discovery")
+
+const (
+	// leaseTTL the registration of the scheduler expires if it is not refreshed in the ttl (seconds)
+	leaseTTL        = 30
+	refreshInterval = 10 * time.Second
+	livenessTimeout = 5 * time.Second
+)
+
+// Manager registers the scheduler into etcd with a lease and refreshes the registration while the scheduler is alive,
+// the locators and the other schedulers discover the scheduler by its registration, which reports its load as well.
+// The registrations of the other schedulers are loaded as the peers of the scheduler
+type Manager struct {
+	etcdcli *etcdcli.Client
+	*db.SQLDB
+	nodeMgr  *node.Manager
+	config   dtypes.GetSchedulerConfigFunc
+	serverID dtypes.ServerID
+	token    dtypes.PermissionWebToken
+
+	leaseID int64
+	// dbLatency the latency of the last liveness check of the database
+	dbLatency time.Duration
+
+	lk    sync.RWMutex
+	peers map[string]*types.SchedulerCfg
+
+	close chan struct{}
+}
+
+// NewManager return new discovery manager instance
+func NewManager(ec *etcdcli.Client, sdb *db.SQLDB, nmgr *node.Manager, configFunc dtypes.GetSchedulerConfigFunc, serverID dtypes.ServerID, token dtypes.PermissionWebToken) *Manager {
+	return &Manager{
+		etcdcli:  ec,
+		SQLDB:    sdb,
+		nodeMgr:  nmgr,
+		config:   configFunc,
+		serverID: serverID,
+		token:    token,
+		peers:    make(map[string]*types.SchedulerCfg),
+		close:    make(chan struct{}),
+	}
+}
+
+// Start registers the scheduler and starts the refresh of the registration
+func (m *Manager) Start(ctx context.Context) error {
+	if err := m.register(); err != nil {
+		return err
+	}
+
+	m.loadPeers()
+
+	go m.startRefreshTimer()
+	return nil
+}
+
+// Stop stops the refresh and removes the registration of the scheduler
+func (m *Manager) Stop(ctx context.Context) error {
+	close(m.close)
+	return m.etcdcli.ServerUnRegister(ctx, string(m.serverID), types.RunningNodeType.String())
+}
+
+func (m *Manager) startRefreshTimer() {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.close:
+			return
+		}
+
+		m.refresh()
+		m.loadPeers()
+	}
+}
+
+// self returns the registration of the scheduler
+func (m *Manager) self() ([]byte, error) {
+	cfg, err := m.config()
+	if err != nil {
+		return nil, err
+	}
+
+	sCfg := &types.SchedulerCfg{
+		ServerID:     string(m.serverID),
+		AreaID:       cfg.AreaID,
+		SchedulerURL: cfg.ExternalURL,
+		AccessToken:  string(m.token),
+		Weight:       cfg.Weight,
+		Zones:        cfg.Zones,
+		Version:      build.UserVersion(),
+		Capacity:     cfg.MaxNodes,
+		Edges:        m.nodeMgr.Edges,
+		Candidates:   m.nodeMgr.Candidates,
+		DBLatency:    m.dbLatency.Milliseconds(),
+	}
+
+	if usage, err := cpu.Percent(0, false); err != nil {
+		log.Debugf("get cpu percent err:%s", err.Error())
+	} else if len(usage) > 0 {
+		sCfg.CPUUsage = usage[0]
+	}
+
+	value, err := etcdcli.SCMarshal(sCfg)
+	if err != nil {
+		return nil, xerrors.Errorf("cfg SCMarshal err:%s", err.Error())
+	}
+
+	return value, nil
+}
+
+func (m *Manager) register() error {
+	value, err := m.self()
+	if err != nil {
+		return err
+	}
+
+	leaseID, err := m.etcdcli.RegisterServerLease(string(m.serverID), types.RunningNodeType.String(), string(value), leaseTTL)
+	if err != nil {
+		return xerrors.Errorf("register scheduler %s err:%s", m.serverID, err.Error())
+	}
+
+	m.leaseID = leaseID
+	return nil
+}
+
+// refresh keeps the lease of the registration alive if the scheduler is alive, the registration expires otherwise.
+// The scheduler registers again if its lease is lost
+func (m *Manager) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), livenessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := m.Ping(ctx); err != nil {
+		log.Errorf("liveness check err:%s, registration not refreshed", err.Error())
+		return
+	}
+	m.dbLatency = time.Since(start)
+
+	value, err := m.self()
+	if err != nil {
+		log.Errorf("registration err:%s", err.Error())
+		return
+	}
+
+	err = m.etcdcli.RefreshServerLease(string(m.serverID), types.RunningNodeType.String(), string(value), m.leaseID)
+	if err == nil {
+		return
+	}
+
+	log.Warnf("refresh registration err:%s, register again", err.Error())
+	if err := m.register(); err != nil {
+		log.Errorf("register err:%s", err.Error())
+	}
+}
+
+// loadPeers loads the registrations of the other schedulers, the edges online on them are counted in the network
+func (m *Manager) loadPeers() {
+	resp, err := m.etcdcli.GetServers(types.RunningNodeType.String())
+	if err != nil {
+		log.Errorf("GetServers err:%s", err.Error())
+		return
+	}
+
+	selfKey := fmt.Sprintf("/%s/%s", types.RunningNodeType.String(), m.serverID)
+	peers := make(map[string]*types.SchedulerCfg, len(resp.Kvs))
+	edges := 0
+
+	for _, kv := range resp.Kvs {
+		cfg := &types.SchedulerCfg{}
+		if err := etcdcli.SCUnmarshal(kv.Value, cfg); err != nil {
+			log.Errorf("SCUnmarshal %s err:%s", string(kv.Key), err.Error())
+			continue
+		}
+
+		if string(kv.Key) == selfKey {
+			continue
+		}
+
+		peers[string(kv.Key)] = cfg
+		edges += cfg.Edges
+	}
+
+	m.lk.Lock()
+	m.peers = peers
+	m.lk.Unlock()
+
+	m.nodeMgr.SetPeerEdges(edges)
+}
+
+// Peers returns the other schedulers registered
+func (m *Manager) Peers() []*types.SchedulerCfg {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	peers := make([]*types.SchedulerCfg, 0, len(m.peers))
+	for _, peer := range m.peers {
+		p := *peer
+		peers = append(peers, &p)
+	}
+
+	return peers
+}
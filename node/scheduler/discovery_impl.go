@@ -0,0 +1,17 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// GetSchedulerPeers retrieves the other schedulers registered in etcd, their access tokens are not returned
+func (s *Scheduler) GetSchedulerPeers(ctx context.Context) ([]*types.SchedulerCfg, error) {
+	peers := s.DiscoveryManager.Peers()
+	for _, peer := range peers {
+		peer.AccessToken = ""
+	}
+
+	return peers, nil
+}
@@ -0,0 +1,266 @@
+package dnsrouting
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/xerrors"
+)
+
+const (
+	checkCertInterval = time.Hour
+	// the certificate is renewed in the days before it expires
+	certRenewBefore = 30 * 24 * time.Hour
+	// wait for the txt records of the challenges to reach the authoritative name servers
+	dnsPropagationDelay = time.Minute
+	challengeRecordTTL  = 60
+	obtainCertTimeout   = 10 * time.Minute
+)
+
+// GetGatewayCertificate returns the certificate of the gateway domain with its private key
+func (m *Manager) GetGatewayCertificate() (*types.GatewayCertificate, error) {
+	cfg, err := m.config()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.provider == nil || cfg.ACMEEmail == "" {
+		return nil, xerrors.New("acme is disabled")
+	}
+
+	zone := strings.Trim(cfg.DNSZone, ".")
+	cert, err := m.LoadGatewayCertificate(zone)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("the certificate of %s is not obtained yet", zone)
+	}
+
+	return cert, err
+}
+
+func (m *Manager) startCheckCertificateTimer() {
+	ticker := time.NewTicker(checkCertInterval)
+	defer ticker.Stop()
+
+	for {
+		m.checkCertificate()
+		<-ticker.C
+	}
+}
+
+// checkCertificate obtains the certificate of the gateway domain if it is missing or about to expire,
+// only the leader scheduler talks to the acme ca
+func (m *Manager) checkCertificate() {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get scheduler config err:%s", err.Error())
+		return
+	}
+
+	if cfg.ACMEEmail == "" || !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	zone := strings.Trim(cfg.DNSZone, ".")
+	cert, err := m.LoadGatewayCertificate(zone)
+	if err != nil && err != sql.ErrNoRows {
+		log.Errorf("LoadGatewayCertificate err:%s", err.Error())
+		return
+	}
+
+	if cert != nil && time.Until(cert.NotAfter) > certRenewBefore {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), obtainCertTimeout)
+	defer cancel()
+
+	cert, err = m.obtainCertificate(ctx, &cfg, zone)
+	if err != nil {
+		log.Errorf("obtain certificate of %s err:%s", zone, err.Error())
+		return
+	}
+
+	if err := m.SaveGatewayCertificate(cert); err != nil {
+		log.Errorf("SaveGatewayCertificate err:%s", err.Error())
+		return
+	}
+
+	log.Infof("certificate of %s obtained, expires at %s", zone, cert.NotAfter.String())
+}
+
+// obtainCertificate orders the certificate of *.<zone> and <zone>, the dns-01 challenges are answered by the txt records
+// of the dns provider, the wildcard and the apex share the record of _acme-challenge.<zone>
+func (m *Manager) obtainCertificate(ctx context.Context, cfg *config.SchedulerCfg, zone string) (*types.GatewayCertificate, error) {
+	client, err := m.acmeClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := []string{"*." + zone, zone}
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return nil, xerrors.Errorf("AuthorizeOrder err:%s", err.Error())
+	}
+
+	records := make(map[string][]string)
+	challenges := make(map[string]*acme.Challenge)
+	for _, authzURL := range order.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, authzURL)
+		if err != nil {
+			return nil, xerrors.Errorf("GetAuthorization err:%s", err.Error())
+		}
+
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		var challenge *acme.Challenge
+		for _, c := range authz.Challenges {
+			if c.Type == "dns-01" {
+				challenge = c
+				break
+			}
+		}
+
+		if challenge == nil {
+			return nil, xerrors.Errorf("no dns-01 challenge for %s", authz.Identifier.Value)
+		}
+
+		value, err := client.DNS01ChallengeRecord(challenge.Token)
+		if err != nil {
+			return nil, err
+		}
+
+		hostname := "_acme-challenge." + authz.Identifier.Value
+		records[hostname] = append(records[hostname], value)
+		challenges[authzURL] = challenge
+	}
+
+	defer func() {
+		for hostname := range records {
+			if err := m.provider.SetTXTRecords(context.Background(), hostname, nil, challengeRecordTTL); err != nil {
+				log.Errorf("remove challenge records of %s err:%s", hostname, err.Error())
+			}
+		}
+	}()
+
+	for hostname, values := range records {
+		if err := m.provider.SetTXTRecords(ctx, hostname, values, challengeRecordTTL); err != nil {
+			return nil, xerrors.Errorf("set challenge records of %s err:%s", hostname, err.Error())
+		}
+	}
+
+	if len(challenges) > 0 {
+		select {
+		case <-time.After(dnsPropagationDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for authzURL, challenge := range challenges {
+		if _, err := client.Accept(ctx, challenge); err != nil {
+			return nil, xerrors.Errorf("accept challenge err:%s", err.Error())
+		}
+
+		if _, err := client.WaitAuthorization(ctx, authzURL); err != nil {
+			return nil, xerrors.Errorf("WaitAuthorization err:%s", err.Error())
+		}
+	}
+
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return nil, xerrors.Errorf("WaitOrder err:%s", err.Error())
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: domains}, key)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, xerrors.Errorf("CreateOrderCert err:%s", err.Error())
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	return &types.GatewayCertificate{
+		Zone:        zone,
+		Certificate: certPEM,
+		PrivateKey:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		NotAfter:    leaf.NotAfter,
+	}, nil
+}
+
+// acmeClient returns the client of the acme account of the directory, the account key is kept in the database
+// so the schedulers taking over the leadership renew with the same account
+func (m *Manager) acmeClient(ctx context.Context, cfg *config.SchedulerCfg) (*acme.Client, error) {
+	keyPEM, err := m.LoadACMEAccountKey(cfg.ACMEDirectory)
+	if err == sql.ErrNoRows {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.SaveACMEAccountKey(cfg.ACMEDirectory, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})); err != nil {
+			return nil, err
+		}
+
+		keyPEM, err = m.LoadACMEAccountKey(cfg.ACMEDirectory)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, xerrors.New("invalid acme account key")
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &acme.Client{Key: key, DirectoryURL: cfg.ACMEDirectory}
+	if _, err := client.Register(ctx, &acme.Account{Contact: []string{"mailto:" + cfg.ACMEEmail}}, acme.AcceptTOS); err != nil && err != acme.ErrAccountAlreadyExists {
+		return nil, xerrors.Errorf("register acme account err:%s", err.Error())
+	}
+
+	return client, nil
+}
@@ -0,0 +1,145 @@
+package dnsrouting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const cloudflareAPI = "https://api.cloudflare.com/client/v4"
+
+// cloudflareProvider keeps one record per ip or txt value, cloudflare has no record sets
+type cloudflareProvider struct {
+	baseURL string
+	zoneID  string
+	token   string
+	client  *http.Client
+}
+
+type cloudflareRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied"`
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result json.RawMessage `json:"result"`
+}
+
+func newCloudflareProvider(baseURL, zoneID, token string) (*cloudflareProvider, error) {
+	if zoneID == "" || token == "" {
+		return nil, xerrors.New("cloudflare zone id and api token are required")
+	}
+
+	return &cloudflareProvider{baseURL: baseURL, zoneID: zoneID, token: token, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// SetRecords creates the a records of the missing ips and deletes the a records of the ips not in the list
+func (p *cloudflareProvider) SetRecords(ctx context.Context, hostname string, ips []string, ttl int) error {
+	return p.setRecords(ctx, "A", hostname, ips, ttl)
+}
+
+// SetTXTRecords creates the txt records of the missing values and deletes the txt records of the values not in the list
+func (p *cloudflareProvider) SetTXTRecords(ctx context.Context, hostname string, values []string, ttl int) error {
+	return p.setRecords(ctx, "TXT", hostname, values, ttl)
+}
+
+func (p *cloudflareProvider) setRecords(ctx context.Context, recordType, hostname string, values []string, ttl int) error {
+	query := url.Values{}
+	query.Set("type", recordType)
+	query.Set("name", hostname)
+	query.Set("per_page", "100")
+
+	var records []*cloudflareRecord
+	if err := p.do(ctx, http.MethodGet, "/dns_records?"+query.Encode(), nil, &records); err != nil {
+		return xerrors.Errorf("list records of %s %w", hostname, err)
+	}
+
+	want := make(map[string]bool, len(values))
+	for _, value := range values {
+		want[value] = true
+	}
+
+	for _, record := range records {
+		// cloudflare may return the content of the txt records quoted
+		content := strings.Trim(record.Content, `"`)
+		if want[content] && record.TTL == ttl {
+			delete(want, content)
+			continue
+		}
+
+		if err := p.do(ctx, http.MethodDelete, "/dns_records/"+record.ID, nil, nil); err != nil {
+			return xerrors.Errorf("delete record %s of %s %w", record.Content, hostname, err)
+		}
+	}
+
+	for _, value := range values {
+		if !want[value] {
+			continue
+		}
+
+		record := &cloudflareRecord{Type: recordType, Name: hostname, Content: value, TTL: ttl}
+		if err := p.do(ctx, http.MethodPost, "/dns_records", record, nil); err != nil {
+			return xerrors.Errorf("create record %s of %s %w", value, hostname, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *cloudflareProvider) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/zones/%s%s", p.baseURL, p.zoneID, path), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	rsp := &cloudflareResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(rsp); err != nil {
+		return xerrors.Errorf("decode response, status %d %w", resp.StatusCode, err)
+	}
+
+	if !rsp.Success {
+		if len(rsp.Errors) > 0 {
+			return xerrors.Errorf("cloudflare error %d: %s", rsp.Errors[0].Code, rsp.Errors[0].Message)
+		}
+		return xerrors.Errorf("cloudflare request failed, status %d", resp.StatusCode)
+	}
+
+	if result != nil {
+		return json.Unmarshal(rsp.Result, result)
+	}
+	return nil
+}
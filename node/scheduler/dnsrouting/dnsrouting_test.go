@@ -0,0 +1,157 @@
+package dnsrouting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAreaLabel(t *testing.T) {
+	cases := map[string]string{
+		"Asia-China-Guangdong-Shenzhen": "asia-china-guangdong-shenzhen",
+		"NorthAmerica-UnitedStates":     "northamerica-unitedstates",
+		"Europe Germany_Hesse":          "europe-germany-hesse",
+		"--":                            "",
+	}
+
+	for areaID, expected := range cases {
+		if label := areaLabel(areaID); label != expected {
+			t.Fatalf("area %s: expected %s, got %s", areaID, expected, label)
+		}
+	}
+}
+
+func TestAssetLabel(t *testing.T) {
+	label, err := assetLabel("QmPZ9gcCEpqKTo6aq61g2nXGUhM4iCL3ewB6LDXZCtioEB")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if label != strings.ToLower(label) || !strings.HasPrefix(label, "bafy") {
+		t.Fatalf("expected a base32 cid v1, got %s", label)
+	}
+}
+
+func TestDownloadIP(t *testing.T) {
+	if ip, ok := downloadIP("1.2.3.4:80", 80); !ok || ip != "1.2.3.4" {
+		t.Fatalf("expected 1.2.3.4, got %s", ip)
+	}
+
+	for _, addr := range []string{"1.2.3.4:2345", "[::1]:80", "example.com:80", "1.2.3.4"} {
+		if _, ok := downloadIP(addr, 80); ok {
+			t.Fatalf("expected %s to be skipped", addr)
+		}
+	}
+}
+
+func TestCloudflareSetRecords(t *testing.T) {
+	var lk sync.Mutex
+	records := map[string]*cloudflareRecord{
+		"1": {ID: "1", Type: "A", Name: "cn.example.com", Content: "1.1.1.1", TTL: 60},
+		"2": {ID: "2", Type: "A", Name: "cn.example.com", Content: "2.2.2.2", TTL: 60},
+	}
+	nextID := 3
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		defer lk.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "errors": []map[string]interface{}{{"code": 10000, "message": "auth"}}}) //nolint:errcheck
+			return
+		}
+
+		var result interface{}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/zones/zone/dns_records":
+			list := make([]*cloudflareRecord, 0)
+			for _, record := range records {
+				if record.Name == r.URL.Query().Get("name") && record.Type == r.URL.Query().Get("type") {
+					list = append(list, record)
+				}
+			}
+			result = list
+		case r.Method == http.MethodPost && r.URL.Path == "/zones/zone/dns_records":
+			record := &cloudflareRecord{}
+			json.NewDecoder(r.Body).Decode(record) //nolint:errcheck
+			record.ID = fmt.Sprint(nextID)
+			nextID++
+			records[record.ID] = record
+			result = record
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/zones/zone/dns_records/"):
+			delete(records, strings.TrimPrefix(r.URL.Path, "/zones/zone/dns_records/"))
+			result = map[string]string{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": result}) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	contents := func() []string {
+		lk.Lock()
+		defer lk.Unlock()
+
+		out := make([]string, 0, len(records))
+		for _, record := range records {
+			out = append(out, record.Content)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	p, err := newCloudflareProvider(server.URL, "zone", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.SetRecords(context.Background(), "cn.example.com", []string{"2.2.2.2", "3.3.3.3"}, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := contents(); !equalIPs(got, []string{"2.2.2.2", "3.3.3.3"}) {
+		t.Fatalf("expected the records 2.2.2.2 and 3.3.3.3, got %v", got)
+	}
+
+	if err := p.SetRecords(context.Background(), "cn.example.com", nil, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := contents(); len(got) != 0 {
+		t.Fatalf("expected the records to be removed, got %v", got)
+	}
+
+	// the challenge records do not touch the a records of the same name
+	if err := p.SetRecords(context.Background(), "cn.example.com", []string{"1.1.1.1"}, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.SetTXTRecords(context.Background(), "cn.example.com", []string{"token1", "token2"}, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := contents(); !equalIPs(got, []string{"1.1.1.1", "token1", "token2"}) {
+		t.Fatalf("expected the a record and the two txt records, got %v", got)
+	}
+
+	if err := p.SetTXTRecords(context.Background(), "cn.example.com", nil, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := contents(); !equalIPs(got, []string{"1.1.1.1"}) {
+		t.Fatalf("expected the txt records to be removed, got %v", got)
+	}
+
+	bad, _ := newCloudflareProvider(server.URL, "zone", "wrong")
+	if err := bad.SetRecords(context.Background(), "cn.example.com", nil, 60); err == nil {
+		t.Fatal("expected the request with a wrong token to fail")
+	}
+}
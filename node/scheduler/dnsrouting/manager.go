@@ -0,0 +1,404 @@
+package dnsrouting
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("dnsrouting")
+
+const (
+	// the nodes reconnect to a restarted scheduler before the records are synced, so the records are not emptied
+	startupDelay = 2 * time.Minute
+	// interval of the full sync of the records
+	syncInterval = 5 * time.Minute
+	// the node state changes within the delay are synced together
+	dirtySyncDelay = 10 * time.Second
+	// timeout of updating the records of all the hostnames
+	syncTimeout = 2 * time.Minute
+)
+
+// Manager points the gateway hostnames at the healthy candidates, <area>.<zone> at the candidates of the area
+// and <cid>.<zone> at the candidates holding the gateway asset, and obtains the certificate of the gateway domain by acme.
+// Only the leader scheduler updates the records
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	nodeMgr       *node.Manager
+	notify        *eventbus.Bus
+	*db.SQLDB
+
+	provider Provider
+	dirty    chan struct{}
+
+	lk sync.Mutex
+	// the published hosts by hostname
+	hosts map[string]*types.GatewayHost
+}
+
+// NewManager return new dns routing manager instance, the manager is disabled if no dns provider is configured
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, p *eventbus.Bus, lmgr *leadership.Manager, configFunc dtypes.GetSchedulerConfigFunc) (*Manager, error) {
+	m := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		nodeMgr:       nmgr,
+		notify:        p,
+		SQLDB:         sdb,
+		dirty:         make(chan struct{}, 1),
+		hosts:         make(map[string]*types.GatewayHost),
+	}
+
+	cfg, err := configFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DNSProvider == "" {
+		return m, nil
+	}
+
+	if cfg.DNSZone == "" {
+		return nil, xerrors.New("dns zone of the gateway hostnames is required")
+	}
+
+	m.provider, err = newProvider(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m.subscribeEvents()
+	go m.run()
+	go m.startCheckCertificateTimer()
+
+	return m, nil
+}
+
+// AddGatewayAsset gives the asset its own gateway hostname <cid>.<zone>
+func (m *Manager) AddGatewayAsset(assetCID string) error {
+	if m.provider == nil {
+		return xerrors.New("dns routing is disabled")
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", assetCID, err.Error())
+	}
+
+	exist, err := m.AssetExists(hash, m.nodeMgr.ServerID)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return xerrors.Errorf("asset %s not exist", assetCID)
+	}
+
+	if err := m.SaveGatewayAsset(hash, assetCID); err != nil {
+		return err
+	}
+
+	m.markDirty()
+	return nil
+}
+
+// RemoveGatewayAsset removes the gateway hostname of the asset
+func (m *Manager) RemoveGatewayAsset(assetCID string) error {
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", assetCID, err.Error())
+	}
+
+	if err := m.DeleteGatewayAsset(hash); err != nil {
+		return err
+	}
+
+	m.markDirty()
+	return nil
+}
+
+// ListGatewayHosts lists the hostnames published by the scheduler, empty on the schedulers that are not the leader
+func (m *Manager) ListGatewayHosts() []*types.GatewayHost {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	out := make([]*types.GatewayHost, 0, len(m.hosts))
+	for _, host := range m.hosts {
+		h := *host
+		out = append(out, &h)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Hostname < out[j].Hostname
+	})
+
+	return out
+}
+
+func (m *Manager) subscribeEvents() {
+	subOnline := m.notify.Sub("dnsrouting", types.EventNodeOnline.String(), eventbus.DefaultQueueSize, eventbus.DropOldest)
+	subOffline := m.notify.Sub("dnsrouting", types.EventNodeOffline.String(), eventbus.DefaultQueueSize, eventbus.DropOldest)
+
+	go func() {
+		defer m.notify.Unsub(subOnline)
+		defer m.notify.Unsub(subOffline)
+
+		for {
+			select {
+			case u, ok := <-subOnline:
+				if !ok {
+					return
+				}
+				m.onNodeStateChange(u.(*node.Node))
+			case u, ok := <-subOffline:
+				if !ok {
+					return
+				}
+				m.onNodeStateChange(u.(*node.Node))
+			}
+		}
+	}()
+}
+
+func (m *Manager) onNodeStateChange(n *node.Node) {
+	if n.Type == types.NodeCandidate {
+		m.markDirty()
+	}
+}
+
+func (m *Manager) markDirty() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) run() {
+	time.Sleep(startupDelay)
+	m.sync()
+
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.dirty:
+			time.Sleep(dirtySyncDelay)
+		}
+
+		m.sync()
+	}
+}
+
+// sync updates the records of the hostnames whose candidates changed and removes the records of the dropped hostnames
+func (m *Manager) sync() {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get scheduler config err:%s", err.Error())
+		return
+	}
+
+	desired, err := m.desiredHosts(&cfg)
+	if err != nil {
+		log.Errorf("desired gateway hosts err:%s", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	for hostname, host := range desired {
+		if published, ok := m.hosts[hostname]; ok && published.Err == "" && equalIPs(published.IPs, host.IPs) {
+			continue
+		}
+
+		host.UpdatedTime = time.Now()
+		if err := m.provider.SetRecords(ctx, hostname, host.IPs, cfg.DNSRecordTTL); err != nil {
+			log.Errorf("set records of %s err:%s", hostname, err.Error())
+			host.Err = err.Error()
+		}
+		m.hosts[hostname] = host
+	}
+
+	for hostname, host := range m.hosts {
+		if _, ok := desired[hostname]; ok {
+			continue
+		}
+
+		if err := m.provider.SetRecords(ctx, hostname, nil, cfg.DNSRecordTTL); err != nil {
+			log.Errorf("remove records of %s err:%s", hostname, err.Error())
+			host.Err = err.Error()
+			continue
+		}
+		delete(m.hosts, hostname)
+	}
+}
+
+// desiredHosts returns the hostnames of the areas and the gateway assets with the ips of their healthy candidates
+func (m *Manager) desiredHosts(cfg *config.SchedulerCfg) (map[string]*types.GatewayHost, error) {
+	zone := strings.Trim(cfg.DNSZone, ".")
+	hosts := make(map[string]*types.GatewayHost)
+
+	for _, areaID := range m.nodeMgr.Zones() {
+		label := areaLabel(areaID)
+		if label == "" {
+			continue
+		}
+
+		_, candidates := m.nodeMgr.GetZoneNodes(areaID)
+		hostname := label + "." + zone
+		hosts[hostname] = &types.GatewayHost{Hostname: hostname, AreaID: areaID, IPs: pickIPs(candidates, cfg.DNSNodePort, cfg.DNSMaxRecords)}
+	}
+
+	assets, err := m.LoadGatewayAssets()
+	if err != nil {
+		return nil, xerrors.Errorf("LoadGatewayAssets err:%s", err.Error())
+	}
+
+	for _, asset := range assets {
+		label, err := assetLabel(asset.CID)
+		if err != nil {
+			log.Errorf("asset label of %s err:%s", asset.CID, err.Error())
+			continue
+		}
+
+		replicas, err := m.LoadReplicasByStatus(asset.Hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+		if err != nil {
+			return nil, xerrors.Errorf("LoadReplicasByStatus %s err:%s", asset.CID, err.Error())
+		}
+
+		candidates := make([]*node.Node, 0, len(replicas))
+		for _, replica := range replicas {
+			if n := m.nodeMgr.GetCandidateNode(replica.NodeID); n != nil && !n.IsAbnormal() {
+				candidates = append(candidates, n)
+			}
+		}
+
+		hostname := label + "." + zone
+		hosts[hostname] = &types.GatewayHost{Hostname: hostname, AssetCID: asset.CID, IPs: pickIPs(candidates, cfg.DNSNodePort, cfg.DNSMaxRecords)}
+	}
+
+	return hosts, nil
+}
+
+// pickIPs returns the ipv4 addresses of the candidates serving downloads on the port, the candidates behind a symmetric nat
+// and the overloaded candidates are skipped, the candidates with the most upload bandwidth are preferred
+func pickIPs(candidates []*node.Node, port, maxRecords int) []string {
+	picked := make([]*node.Node, 0, len(candidates))
+	for _, n := range candidates {
+		if n.NATType == types.NatTypeSymmetric || n.IsOverloaded() {
+			continue
+		}
+
+		if _, ok := downloadIP(n.DownloadAddr(), port); ok {
+			picked = append(picked, n)
+		}
+	}
+
+	sort.SliceStable(picked, func(i, j int) bool {
+		return picked[i].BandwidthUp > picked[j].BandwidthUp
+	})
+
+	ips := make([]string, 0, maxRecords)
+	seen := make(map[string]bool)
+	for _, n := range picked {
+		if len(ips) >= maxRecords {
+			break
+		}
+
+		ip, _ := downloadIP(n.DownloadAddr(), port)
+		if seen[ip] {
+			continue
+		}
+		seen[ip] = true
+		ips = append(ips, ip)
+	}
+
+	sort.Strings(ips)
+	return ips
+}
+
+// downloadIP returns the ipv4 address of the download address if it listens on the port
+func downloadIP(addr string, port int) (string, bool) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil || p != strconv.Itoa(port) {
+		return "", false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.To4() == nil {
+		return "", false
+	}
+
+	return ip.String(), true
+}
+
+// areaLabel converts the area id to a dns label, e.g. Asia-China-Guangdong-Shenzhen to asia-china-guangdong-shenzhen
+func areaLabel(areaID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(areaID) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+
+	label := strings.Trim(b.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+// assetLabel returns the cid v1 in base32 of the asset, which is case insensitive as dns labels are
+func assetLabel(assetCID string) (string, error) {
+	c, err := cid.Decode(assetCID)
+	if err != nil {
+		return "", err
+	}
+
+	label := cid.NewCidV1(c.Type(), c.Hash()).String()
+	if len(label) > 63 {
+		return "", xerrors.Errorf("cid %s is too long for a dns label", label)
+	}
+	return label, nil
+}
+
+func equalIPs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
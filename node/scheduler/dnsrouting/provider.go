@@ -0,0 +1,34 @@
+package dnsrouting
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/node/config"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ProviderRoute53 the hosted zones of aws route53
+	ProviderRoute53 = "route53"
+	// ProviderCloudflare the zones of cloudflare
+	ProviderCloudflare = "cloudflare"
+)
+
+// Provider maintains the a records of the gateway hostnames and the txt records of the acme challenges in a dns zone
+type Provider interface {
+	// SetRecords replaces the a records of the hostname with the ips, the records are removed if ips is empty
+	SetRecords(ctx context.Context, hostname string, ips []string, ttl int) error
+	// SetTXTRecords replaces the txt records of the hostname with the values, the records are removed if values is empty
+	SetTXTRecords(ctx context.Context, hostname string, values []string, ttl int) error
+}
+
+func newProvider(cfg *config.SchedulerCfg) (Provider, error) {
+	switch cfg.DNSProvider {
+	case ProviderRoute53:
+		return newRoute53Provider(cfg.DNSZoneID, cfg.DNSCredential)
+	case ProviderCloudflare:
+		return newCloudflareProvider(cloudflareAPI, cfg.DNSZoneID, cfg.DNSCredential)
+	default:
+		return nil, xerrors.Errorf("unsupported dns provider %s", cfg.DNSProvider)
+	}
+}
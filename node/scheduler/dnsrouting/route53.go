@@ -0,0 +1,106 @@
+package dnsrouting
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"golang.org/x/xerrors"
+)
+
+type route53Provider struct {
+	client *route53.Route53
+	zoneID string
+}
+
+// newRoute53Provider returns the provider of the hosted zone, credential is <access key id>:<secret key>,
+// the default aws credentials such as the environment variables and the instance role are used if it is empty
+func newRoute53Provider(zoneID, credential string) (*route53Provider, error) {
+	if zoneID == "" {
+		return nil, xerrors.New("route53 hosted zone id is empty")
+	}
+
+	cfg := aws.NewConfig()
+	if credential != "" {
+		keyID, secret, ok := strings.Cut(credential, ":")
+		if !ok {
+			return nil, xerrors.New("route53 credential must be <access key id>:<secret key>")
+		}
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(keyID, secret, ""))
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &route53Provider{client: route53.New(sess), zoneID: zoneID}, nil
+}
+
+// SetRecords upserts the a record set of the hostname, the record set is deleted if ips is empty
+func (p *route53Provider) SetRecords(ctx context.Context, hostname string, ips []string, ttl int) error {
+	return p.setRecords(ctx, route53.RRTypeA, hostname, ips, ttl)
+}
+
+// SetTXTRecords upserts the txt record set of the hostname, the record set is deleted if values is empty
+func (p *route53Provider) SetTXTRecords(ctx context.Context, hostname string, values []string, ttl int) error {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+
+	return p.setRecords(ctx, route53.RRTypeTxt, hostname, quoted, ttl)
+}
+
+func (p *route53Provider) setRecords(ctx context.Context, rrType, hostname string, values []string, ttl int) error {
+	if len(values) == 0 {
+		return p.deleteRecords(ctx, rrType, hostname)
+	}
+
+	records := make([]*route53.ResourceRecord, 0, len(values))
+	for _, value := range values {
+		records = append(records, &route53.ResourceRecord{Value: aws.String(value)})
+	}
+
+	return p.change(ctx, route53.ChangeActionUpsert, &route53.ResourceRecordSet{
+		Name:            aws.String(hostname),
+		Type:            aws.String(rrType),
+		TTL:             aws.Int64(int64(ttl)),
+		ResourceRecords: records,
+	})
+}
+
+// deleteRecords deletes the record set of the hostname, route53 only deletes a record set matching the current one
+func (p *route53Provider) deleteRecords(ctx context.Context, rrType, hostname string) error {
+	out, err := p.client.ListResourceRecordSetsWithContext(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(p.zoneID),
+		StartRecordName: aws.String(hostname),
+		StartRecordType: aws.String(rrType),
+		MaxItems:        aws.String("1"),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, set := range out.ResourceRecordSets {
+		if strings.TrimSuffix(aws.StringValue(set.Name), ".") == hostname && aws.StringValue(set.Type) == rrType {
+			return p.change(ctx, route53.ChangeActionDelete, set)
+		}
+	}
+
+	return nil
+}
+
+func (p *route53Provider) change(ctx context.Context, action string, set *route53.ResourceRecordSet) error {
+	_, err := p.client.ChangeResourceRecordSetsWithContext(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(p.zoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{{Action: aws.String(action), ResourceRecordSet: set}},
+		},
+	})
+	return err
+}
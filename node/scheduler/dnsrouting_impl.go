@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// AddGatewayAsset gives the asset its own gateway hostname <cid>.<zone> pointed at the candidates holding it
+func (s *Scheduler) AddGatewayAsset(ctx context.Context, cid string) error {
+	return s.DNSRoutingManager.AddGatewayAsset(cid)
+}
+
+// RemoveGatewayAsset removes the gateway hostname of the asset
+func (s *Scheduler) RemoveGatewayAsset(ctx context.Context, cid string) error {
+	return s.DNSRoutingManager.RemoveGatewayAsset(cid)
+}
+
+// ListGatewayHosts lists the gateway hostnames published by the scheduler with the candidate ips they point at
+func (s *Scheduler) ListGatewayHosts(ctx context.Context) ([]*types.GatewayHost, error) {
+	return s.DNSRoutingManager.ListGatewayHosts(), nil
+}
+
+// GetGatewayCertificate returns the certificate of the gateway domain obtained by acme with its private key
+func (s *Scheduler) GetGatewayCertificate(ctx context.Context) (*types.GatewayCertificate, error) {
+	return s.DNSRoutingManager.GetGatewayCertificate()
+}
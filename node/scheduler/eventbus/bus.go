@@ -0,0 +1,200 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Filecoin-Titan/titan/metrics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+var log = logging.Logger("eventbus")
+
+// DropPolicy decides which event is dropped when the queue of a subscriber is full
+type DropPolicy int
+
+const (
+	// DropNewest drops the event being published, the queued events are kept
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest queued event to make room for the event being published
+	DropOldest
+)
+
+// DefaultQueueSize is the queue size of the subscribers
+const DefaultQueueSize = 1024
+
+// Bus delivers the published events to a bounded queue per subscriber, the publishers never wait for a slow subscriber,
+// the events that do not fit in the queue are dropped by the policy of the subscriber and counted
+type Bus struct {
+	lk     sync.RWMutex
+	topics map[string][]*subscriber
+	subs   map[<-chan interface{}]*subscriber
+	closed bool
+}
+
+type subscriber struct {
+	name    string
+	topic   string
+	policy  DropPolicy
+	queue   chan interface{}
+	dropped int64
+}
+
+// New returns a new event bus
+func New() *Bus {
+	return &Bus{
+		topics: make(map[string][]*subscriber),
+		subs:   make(map[<-chan interface{}]*subscriber),
+	}
+}
+
+// Sub subscribes to the events of the topic with a queue of the size, the name identifies the subscriber in the metrics
+// and diagnostics, the returned channel is closed on Unsub or Shutdown
+func (b *Bus) Sub(name, topic string, size int, policy DropPolicy) <-chan interface{} {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+
+	s := &subscriber{
+		name:   name,
+		topic:  topic,
+		policy: policy,
+		queue:  make(chan interface{}, size),
+	}
+
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if b.closed {
+		close(s.queue)
+		return s.queue
+	}
+
+	b.topics[topic] = append(b.topics[topic], s)
+	b.subs[s.queue] = s
+
+	diagnostics.RegisterSize("eventbus."+name+"."+topic, func() int { return b.queued(name, topic) })
+
+	return s.queue
+}
+
+// Unsub removes the subscription of the channel and closes it
+func (b *Bus) Unsub(ch <-chan interface{}) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	s, ok := b.subs[ch]
+	if !ok {
+		return
+	}
+
+	delete(b.subs, ch)
+
+	subs := b.topics[s.topic]
+	for i, sub := range subs {
+		if sub == s {
+			b.topics[s.topic] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	close(s.queue)
+}
+
+// Pub publishes the event to the subscribers of the topic without blocking
+func (b *Bus) Pub(event interface{}, topic string) {
+	b.lk.RLock()
+	defer b.lk.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, s := range b.topics[topic] {
+		s.deliver(event)
+	}
+}
+
+// Shutdown closes the channels of all the subscribers, the events published afterwards are discarded
+func (b *Bus) Shutdown() {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	for _, s := range b.subs {
+		close(s.queue)
+	}
+
+	b.topics = make(map[string][]*subscriber)
+	b.subs = make(map[<-chan interface{}]*subscriber)
+}
+
+// Dropped returns the number of the dropped events by subscriber name
+func (b *Bus) Dropped() map[string]int64 {
+	b.lk.RLock()
+	defer b.lk.RUnlock()
+
+	out := make(map[string]int64)
+	for _, s := range b.subs {
+		out[s.name] += atomic.LoadInt64(&s.dropped)
+	}
+
+	return out
+}
+
+// queued returns the number of the events queued for the subscribers of the name to the topic
+func (b *Bus) queued(name, topic string) int {
+	b.lk.RLock()
+	defer b.lk.RUnlock()
+
+	n := 0
+	for _, s := range b.topics[topic] {
+		if s.name == name {
+			n += len(s.queue)
+		}
+	}
+
+	return n
+}
+
+func (s *subscriber) deliver(event interface{}) {
+	select {
+	case s.queue <- event:
+		return
+	default:
+	}
+
+	if s.policy == DropOldest {
+		// other publishers may fill the queue again before the event is sent
+		select {
+		case <-s.queue:
+			s.drop()
+		default:
+		}
+
+		select {
+		case s.queue <- event:
+			return
+		default:
+		}
+	}
+
+	s.drop()
+}
+
+func (s *subscriber) drop() {
+	if atomic.AddInt64(&s.dropped, 1) == 1 {
+		log.Warnf("subscriber %s is too slow for the events of %s, dropping events", s.name, s.topic)
+	}
+
+	ctx, _ := tag.New(context.Background(), tag.Upsert(metrics.Subscriber, s.name), tag.Upsert(metrics.Topic, s.topic))
+	stats.Record(ctx, metrics.SchedulerDroppedEvents.M(1))
+}
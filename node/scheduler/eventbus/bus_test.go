@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowSubscriber(t *testing.T) {
+	b := New()
+	defer b.Shutdown()
+
+	newest := b.Sub("newest", "topic", 2, DropNewest)
+	oldest := b.Sub("oldest", "topic", 2, DropOldest)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			b.Pub(i, "topic")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher blocked by the slow subscribers")
+	}
+
+	if v := <-newest; v != 0 {
+		t.Errorf("drop newest: expected 0, got %v", v)
+	}
+	if v := <-oldest; v != 3 {
+		t.Errorf("drop oldest: expected 3, got %v", v)
+	}
+
+	dropped := b.Dropped()
+	if dropped["newest"] != 3 || dropped["oldest"] != 3 {
+		t.Errorf("unexpected dropped events %v", dropped)
+	}
+}
+
+func TestUnsub(t *testing.T) {
+	b := New()
+
+	ch := b.Sub("sub", "topic", 1, DropNewest)
+	b.Unsub(ch)
+
+	if _, ok := <-ch; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+
+	b.Pub(1, "topic")
+	b.Shutdown()
+
+	if _, ok := <-b.Sub("sub", "topic", 1, DropNewest); ok {
+		t.Fatal("expected the channel of a shut down bus to be closed")
+	}
+}
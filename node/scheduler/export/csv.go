@@ -0,0 +1,89 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
+)
+
+// progressRows the rows written between the progress reports
+const progressRows = 10000
+
+// writeCSV writes the rows as gzip compressed csv with a header row of the columns and returns the rows written
+func writeCSV(w io.Writer, rows *sqlx.Rows, columns []string, progress func(rows int64)) (int64, error) {
+	zw := gzip.NewWriter(w)
+	cw := csv.NewWriter(zw)
+
+	if err := cw.Write(columns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	record := make([]string, len(columns))
+	for rows.Next() {
+		values, err := rows.SliceScan()
+		if err != nil {
+			return count, err
+		}
+
+		if len(values) != len(columns) {
+			return count, xerrors.Errorf("expect %d columns, got %d", len(columns), len(values))
+		}
+
+		for i, v := range values {
+			record[i] = formatValue(v)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return count, err
+		}
+
+		count++
+		if count%progressRows == 0 {
+			progress(count)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return count, err
+	}
+
+	progress(count)
+	return count, zw.Close()
+}
+
+// formatValue formats the value scanned from the database, the times are in utc
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// countWriter counts the bytes written
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatValue(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, ""},
+		{[]byte("12.5"), "12.5"},
+		{int64(42), "42"},
+		{time.Date(2024, 3, 2, 9, 30, 0, 0, time.FixedZone("UTC+8", 8*3600)), "2024-03-02T01:30:00Z"},
+	}
+
+	for _, c := range cases {
+		if v := formatValue(c.value); v != c.expected {
+			t.Errorf("formatValue(%v) expected %q, got %q", c.value, c.expected, v)
+		}
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	schemas := Schemas()
+	if len(schemas) != len(datasets) {
+		t.Fatalf("expected %d schemas, got %d", len(datasets), len(schemas))
+	}
+
+	for _, s := range schemas {
+		if s.Version <= 0 || len(s.Columns) == 0 {
+			t.Errorf("invalid schema of %s", s.Dataset)
+		}
+	}
+}
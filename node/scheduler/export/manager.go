@@ -0,0 +1,213 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("export")
+
+const (
+	// jobExport the kind of the jobs generating the exports, run by any scheduler
+	jobExport = "export.data"
+	// the lifetime of the download urls of the exports
+	linkLifetime = 24 * time.Hour
+	// the longest period of the epochs exported at once
+	maxExportDays = 366
+)
+
+// Manager exports the nodes, the assets and the points epochs as compressed files for the external analytics.
+// The exports are generated by the jobs of the job queue and uploaded to the export bucket,
+// the operators download them by presigned urls
+type Manager struct {
+	config dtypes.GetSchedulerConfigFunc
+	jobs   *jobqueue.Queue
+	*db.SQLDB
+}
+
+// NewManager return new export manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, jq *jobqueue.Queue) *Manager {
+	m := &Manager{
+		config: configFunc,
+		jobs:   jq,
+		SQLDB:  sdb,
+	}
+
+	jq.Register(jobExport, m.run)
+
+	return m
+}
+
+// Start saves the export of the dataset and enqueues the job generating it, returns the export id
+func (m *Manager) Start(req *types.DataExportReq) (string, error) {
+	d, ok := datasets[req.Dataset]
+	if !ok {
+		return "", xerrors.Errorf("unsupported dataset %s", req.Dataset)
+	}
+
+	if req.Format == "" {
+		req.Format = types.ExportCSV
+	}
+
+	if req.Format != types.ExportCSV {
+		return "", xerrors.Errorf("unsupported format %s", req.Format)
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return "", xerrors.Errorf("get config err:%s", err.Error())
+	}
+
+	if cfg.ExportBucket == "" {
+		return "", xerrors.New("the export bucket is not configured")
+	}
+
+	now := time.Now()
+	export := &types.DataExport{
+		ID:            uuid.NewString(),
+		Dataset:       req.Dataset,
+		Format:        req.Format,
+		SchemaVersion: d.version,
+		Start:         now,
+		End:           now,
+		CreatedTime:   now,
+		FinishedTime:  now,
+	}
+
+	if req.Dataset == types.ExportPointsEpochs {
+		export.Start, export.End = utcDay(req.Start), utcDay(req.End)
+		if req.Start.IsZero() || req.End.IsZero() || export.End.Before(export.Start) {
+			return "", xerrors.New("the epochs to export are invalid")
+		}
+
+		if export.End.Sub(export.Start) > maxExportDays*24*time.Hour {
+			return "", xerrors.Errorf("the period can not be longer than %d days", maxExportDays)
+		}
+	}
+
+	export.ObjectKey = fmt.Sprintf("exports/%s/v%d/%s.%s.gz", export.Dataset, export.SchemaVersion, export.ID, export.Format)
+
+	if err := m.SaveDataExport(export); err != nil {
+		return "", err
+	}
+
+	if _, err := m.jobs.EnqueueWithID(export.ID, jobExport, struct{}{}, jobqueue.Options{MaxAttempts: 3}); err != nil {
+		return "", err
+	}
+
+	log.Infof("export %s of %s started", export.ID, export.Dataset)
+	return export.ID, nil
+}
+
+// Get returns the export with the presigned download url if it succeeded
+func (m *Manager) Get(id string) (*types.DataExport, error) {
+	export, err := m.LoadDataExport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status != types.JobSucceeded {
+		return export, nil
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return nil, xerrors.Errorf("get config err:%s", err.Error())
+	}
+
+	st, err := newStorage(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.DownloadURL, err = st.presign(export.ObjectKey, linkLifetime); err != nil {
+		return nil, xerrors.Errorf("presign err:%s", err.Error())
+	}
+	export.URLExpiration = time.Now().Add(linkLifetime)
+
+	return export, nil
+}
+
+// run generates the export and uploads it, the upload is started again by the retry of a failed attempt
+func (m *Manager) run(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	export, err := m.LoadDataExport(job.ID)
+	if err != nil {
+		return xerrors.Errorf("LoadDataExport err:%s", err.Error())
+	}
+
+	d, ok := datasets[export.Dataset]
+	if !ok {
+		return xerrors.Errorf("unsupported dataset %s", export.Dataset)
+	}
+
+	if d.version != export.SchemaVersion {
+		return xerrors.Errorf("schema version %d of %s is replaced by %d", export.SchemaVersion, export.Dataset, d.version)
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return xerrors.Errorf("get config err:%s", err.Error())
+	}
+
+	st, err := newStorage(&cfg)
+	if err != nil {
+		return err
+	}
+
+	rows, err := d.load(ctx, m.SQLDB, d.columns, export)
+	if err != nil {
+		return xerrors.Errorf("load %s err:%s", export.Dataset, err.Error())
+	}
+	defer rows.Close()
+
+	pr, pw := io.Pipe()
+	counter := &countWriter{w: pw}
+
+	type result struct {
+		rows int64
+		err  error
+	}
+	written := make(chan result, 1)
+
+	go func() {
+		count, err := writeCSV(counter, rows, d.columns, func(rows int64) { progress(rows, 0) })
+		pw.CloseWithError(err)
+		written <- result{count, err}
+	}()
+
+	err = st.upload(ctx, export.ObjectKey, "application/gzip", pr)
+	// unblocks the writer if the upload stopped reading
+	pr.CloseWithError(io.ErrClosedPipe)
+
+	w := <-written
+	if err != nil {
+		return xerrors.Errorf("upload %s err:%s", export.ObjectKey, err.Error())
+	}
+
+	if w.err != nil {
+		return xerrors.Errorf("write %s err:%s", export.Dataset, w.err.Error())
+	}
+
+	if err := m.FinishDataExport(export.ID, w.rows, counter.n, time.Now()); err != nil {
+		return xerrors.Errorf("FinishDataExport err:%s", err.Error())
+	}
+
+	log.Infof("export %s of %s uploaded, %d rows, %d bytes", export.ID, export.Dataset, w.rows, counter.n)
+	return nil
+}
+
+// utcDay returns the start of the utc day of the time
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
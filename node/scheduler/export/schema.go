@@ -0,0 +1,63 @@
+package export
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/jmoiron/sqlx"
+)
+
+// dataset the columns of a dataset, the version is increased whenever the columns are changed
+// so the consumers can tell the layouts of the exports apart
+type dataset struct {
+	version int
+	columns []string
+	load    func(ctx context.Context, sdb *db.SQLDB, columns []string, export *types.DataExport) (*sqlx.Rows, error)
+}
+
+var datasets = map[types.ExportDataset]*dataset{
+	types.ExportNodes: {
+		version: 1,
+		columns: []string{
+			"node_id", "node_type", "scheduler_sid", "first_login_time", "last_seen", "online_duration", "profit",
+			"disk_space", "titan_disk_usage", "bandwidth_up", "bandwidth_down", "upload_traffic", "download_traffic",
+			"retrieve_count", "asset_count", "deactivate_time",
+		},
+		load: func(ctx context.Context, sdb *db.SQLDB, columns []string, export *types.DataExport) (*sqlx.Rows, error) {
+			return sdb.LoadNodeExportRows(ctx, columns)
+		},
+	},
+	types.ExportAssets: {
+		version: 1,
+		columns: []string{
+			"hash", "cid", "scheduler_sid", "total_size", "total_blocks", "edge_replicas", "candidate_replicas",
+			"expiration", "created_time", "qos_tier",
+		},
+		load: func(ctx context.Context, sdb *db.SQLDB, columns []string, export *types.DataExport) (*sqlx.Rows, error) {
+			return sdb.LoadAssetExportRows(ctx, columns)
+		},
+	},
+	types.ExportPointsEpochs: {
+		version: 1,
+		columns: []string{"epoch", "node_id", "points", "total"},
+		load: func(ctx context.Context, sdb *db.SQLDB, columns []string, export *types.DataExport) (*sqlx.Rows, error) {
+			return sdb.LoadPointsEpochExportRows(ctx, columns, export.Start, export.End)
+		},
+	},
+}
+
+// Schemas returns the current columns of the datasets
+func Schemas() []*types.DataExportSchema {
+	out := make([]*types.DataExportSchema, 0, len(datasets))
+	for name, d := range datasets {
+		out = append(out, &types.DataExportSchema{Dataset: name, Version: d.version, Columns: d.columns})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Dataset < out[j].Dataset
+	})
+
+	return out
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"golang.org/x/xerrors"
+)
+
+const defaultRegion = "us-east-1"
+
+// storage the s3 bucket the exports are uploaded to
+type storage struct {
+	client *s3.S3
+	bucket string
+}
+
+// newStorage returns the storage of the export bucket of the config
+func newStorage(cfg *config.SchedulerCfg) (*storage, error) {
+	if cfg.ExportBucket == "" {
+		return nil, xerrors.New("the export bucket is not configured")
+	}
+
+	region := cfg.ExportRegion
+	if region == "" {
+		region = defaultRegion
+	}
+
+	awsCfg := aws.NewConfig().WithRegion(region)
+	if cfg.ExportEndpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.ExportEndpoint).WithS3ForcePathStyle(true)
+	}
+
+	if cfg.ExportCredential != "" {
+		keyID, secret, ok := strings.Cut(cfg.ExportCredential, ":")
+		if !ok {
+			return nil, xerrors.New("export credential must be <access key id>:<secret key>")
+		}
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(keyID, secret, ""))
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storage{client: s3.New(sess), bucket: cfg.ExportBucket}, nil
+}
+
+// upload uploads the object of the key from the reader
+func (s *storage) upload(ctx context.Context, key, contentType string, body io.Reader) error {
+	uploader := s3manager.NewUploaderWithClient(s.client)
+	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        body,
+	})
+	return err
+}
+
+// presign returns the url downloading the object of the key until the lifetime passes
+func (s *storage) presign(key string, lifetime time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return req.Presign(lifetime)
+}
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/export"
+	"golang.org/x/xerrors"
+)
+
+// StartDataExport starts to export the dataset for the analytics and returns the export id,
+// the export is generated asynchronously and uploaded to the export bucket
+func (s *Scheduler) StartDataExport(ctx context.Context, req *types.DataExportReq) (string, error) {
+	if req == nil {
+		return "", xerrors.New("request can not empty")
+	}
+
+	return s.ExportManager.Start(req)
+}
+
+// GetDataExport retrieves the export with a presigned download url once it succeeded
+func (s *Scheduler) GetDataExport(ctx context.Context, id string) (*types.DataExport, error) {
+	return s.ExportManager.Get(id)
+}
+
+// ListDataExports retrieves the exports, the latest first
+func (s *Scheduler) ListDataExports(ctx context.Context, limit, offset int) (*types.ListDataExportRsp, error) {
+	return s.ExportManager.LoadDataExports(limit, offset)
+}
+
+// GetDataExportSchemas retrieves the current columns and schema versions of the datasets
+func (s *Scheduler) GetDataExportSchemas(ctx context.Context) ([]*types.DataExportSchema, error) {
+	return export.Schemas(), nil
+}
@@ -0,0 +1,142 @@
+package featureflag
+
+import (
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("featureflag")
+
+// the flags changed on the other schedulers are loaded in the interval
+const reloadInterval = time.Minute
+
+var flagNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// Manager keeps the feature flags, the scheduler code paths consult Enabled and the nodes receive their flags
+// by the config push
+type Manager struct {
+	serverID dtypes.ServerID
+	*db.SQLDB
+
+	lk    sync.RWMutex
+	flags map[string]*types.FeatureFlag
+}
+
+// NewManager return new feature flag manager instance
+func NewManager(sdb *db.SQLDB, serverID dtypes.ServerID) *Manager {
+	m := &Manager{
+		serverID: serverID,
+		SQLDB:    sdb,
+		flags:    make(map[string]*types.FeatureFlag),
+	}
+
+	m.reload()
+	go m.startReloadTimer()
+
+	return m
+}
+
+// Enabled returns true if the feature is enabled on this scheduler
+func (m *Manager) Enabled(name string) bool {
+	return m.EnabledFor(name, string(m.serverID), types.NodeScheduler)
+}
+
+// EnabledFor returns true if the feature is enabled on the node
+func (m *Manager) EnabledFor(name, nodeID string, nodeType types.NodeType) bool {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	flag, ok := m.flags[name]
+	return ok && flag.EnabledFor(nodeID, nodeType)
+}
+
+// NodeFlags returns the flags targeting the node type with whether they are enabled on the node
+func (m *Manager) NodeFlags(nodeID string, nodeType types.NodeType) map[string]bool {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	out := make(map[string]bool)
+	for name, flag := range m.flags {
+		if flag.Targets(nodeType) {
+			out[name] = flag.EnabledFor(nodeID, nodeType)
+		}
+	}
+	return out
+}
+
+// SetFlag creates or updates the feature flag
+func (m *Manager) SetFlag(flag *types.FeatureFlag) error {
+	if flag == nil || !flagNameRegexp.MatchString(flag.Name) {
+		return xerrors.New("invalid feature flag name")
+	}
+
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return xerrors.Errorf("invalid percentage %d", flag.Percentage)
+	}
+
+	if len(flag.Description) > 256 {
+		return xerrors.New("description is too long")
+	}
+
+	for _, t := range flag.NodeTypes {
+		if t != types.NodeEdge && t != types.NodeCandidate && t != types.NodeScheduler {
+			return xerrors.Errorf("invalid node type %d", t)
+		}
+	}
+
+	if err := m.SaveFeatureFlag(flag); err != nil {
+		return xerrors.Errorf("SaveFeatureFlag err:%s", err.Error())
+	}
+
+	m.reload()
+	return nil
+}
+
+// RemoveFlag removes the feature flag, the feature is off everywhere after
+func (m *Manager) RemoveFlag(name string) error {
+	if err := m.DeleteFeatureFlag(name); err != nil {
+		return xerrors.Errorf("DeleteFeatureFlag err:%s", err.Error())
+	}
+
+	m.reload()
+	return nil
+}
+
+// ListFlags returns the feature flags
+func (m *Manager) ListFlags() ([]*types.FeatureFlag, error) {
+	return m.LoadFeatureFlags()
+}
+
+func (m *Manager) startReloadTimer() {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		m.reload()
+	}
+}
+
+func (m *Manager) reload() {
+	list, err := m.LoadFeatureFlags()
+	if err != nil {
+		log.Errorf("LoadFeatureFlags err:%s", err.Error())
+		return
+	}
+
+	flags := make(map[string]*types.FeatureFlag, len(list))
+	for _, flag := range list {
+		flags[flag.Name] = flag
+	}
+
+	m.lk.Lock()
+	m.flags = flags
+	m.lk.Unlock()
+}
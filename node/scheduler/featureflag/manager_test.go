@@ -0,0 +1,55 @@
+package featureflag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestPercentageRollout(t *testing.T) {
+	flag := &types.FeatureFlag{Name: "new-transport", Enabled: true, NodeTypes: types.FeatureFlagNodeTypes{types.NodeEdge}}
+
+	enabled := make(map[string]bool)
+	for _, pct := range []int{10, 50, 100} {
+		flag.Percentage = pct
+
+		count := 0
+		for i := 0; i < 1000; i++ {
+			nodeID := fmt.Sprintf("e_%d", i)
+			on := flag.EnabledFor(nodeID, types.NodeEdge)
+			if enabled[nodeID] && !on {
+				t.Fatalf("node %s disabled when raising the percentage to %d", nodeID, pct)
+			}
+			enabled[nodeID] = on
+			if on {
+				count++
+			}
+		}
+
+		if count < pct*10-80 || count > pct*10+80 {
+			t.Errorf("%d nodes enabled at %d%%", count, pct)
+		}
+	}
+
+	if flag.EnabledFor("c_1", types.NodeCandidate) {
+		t.Errorf("flag should not target the candidates")
+	}
+}
+
+func TestNodeFlags(t *testing.T) {
+	m := &Manager{serverID: "s_1", flags: map[string]*types.FeatureFlag{
+		"all":       {Name: "all", Enabled: true, Percentage: 100},
+		"off":       {Name: "off", Enabled: false, Percentage: 100},
+		"scheduler": {Name: "scheduler", Enabled: true, Percentage: 100, NodeTypes: types.FeatureFlagNodeTypes{types.NodeScheduler}},
+	}}
+
+	flags := m.NodeFlags("e_1", types.NodeEdge)
+	if len(flags) != 2 || !flags["all"] || flags["off"] {
+		t.Fatalf("unexpected flags %v", flags)
+	}
+
+	if !m.Enabled("scheduler") || m.EnabledFor("scheduler", "e_1", types.NodeEdge) {
+		t.Fatalf("the scheduler flag should be enabled on the scheduler only")
+	}
+}
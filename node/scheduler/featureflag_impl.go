@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SetFeatureFlag creates or updates the feature flag, the flags of the nodes are pushed with their config
+func (s *Scheduler) SetFeatureFlag(ctx context.Context, flag *types.FeatureFlag) error {
+	if err := s.FeatureFlagManager.SetFlag(flag); err != nil {
+		return err
+	}
+
+	go s.ConfigPushManager.PushAll()
+	return nil
+}
+
+// RemoveFeatureFlag removes the feature flag
+func (s *Scheduler) RemoveFeatureFlag(ctx context.Context, name string) error {
+	if err := s.FeatureFlagManager.RemoveFlag(name); err != nil {
+		return err
+	}
+
+	go s.ConfigPushManager.PushAll()
+	return nil
+}
+
+// ListFeatureFlags retrieves the feature flags
+func (s *Scheduler) ListFeatureFlags(ctx context.Context) ([]*types.FeatureFlag, error) {
+	return s.FeatureFlagManager.ListFlags()
+}
@@ -118,7 +118,7 @@ func (m *Manager) loadResults() ([]int, map[string]string, error) {
 		ids = append(ids, vInfo.ID)
 
 		str := fmt.Sprintf("RoundID:%s, NodeID:%s, ValidatorID:%s, Profit:%.2f, ValidationCID:%s,EndTime:%s \n",
-			vInfo.RoundID, vInfo.NodeID, vInfo.ValidatorID, vInfo.Profit, vInfo.Cid, vInfo.EndTime.String())
+			vInfo.RoundID, vInfo.NodeID, vInfo.ValidatorID, vInfo.Profit.Float64(), vInfo.Cid, vInfo.EndTime.String())
 
 		filename := vInfo.EndTime.Format("20060102")
 		data := ds[filename]
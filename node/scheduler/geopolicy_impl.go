@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+)
+
+// SetAssetGeoPolicy sets the geo policy of the asset
+func (s *Scheduler) SetAssetGeoPolicy(ctx context.Context, cid string, rules types.AssetGeoRules) error {
+	return s.AssetManager.SetAssetGeoPolicy(cid, rules)
+}
+
+// RemoveAssetGeoPolicy removes the geo policy of the asset
+func (s *Scheduler) RemoveAssetGeoPolicy(ctx context.Context, cid string) error {
+	return s.AssetManager.RemoveAssetGeoPolicy(cid)
+}
+
+// GetAssetGeoPolicy retrieves the geo policy of the asset
+func (s *Scheduler) GetAssetGeoPolicy(ctx context.Context, cid string) (*types.AssetGeoPolicy, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.db.LoadAssetGeoPolicy(hash)
+}
+
+// ListAssetGeoPolicies retrieves the geo policies, the latest updated first
+func (s *Scheduler) ListAssetGeoPolicies(ctx context.Context, limit, offset int) (*types.ListAssetGeoPolicyRsp, error) {
+	return s.db.LoadAssetGeoPolicies(limit, offset)
+}
+
+// ListAssetGeoViolations retrieves the violations of the geo policies, of the asset if the cid is not empty
+func (s *Scheduler) ListAssetGeoViolations(ctx context.Context, cid string, limit, offset int) (*types.ListAssetGeoViolationRsp, error) {
+	hash := ""
+	if cid != "" {
+		var err error
+		if hash, err = cidutil.CIDToHash(cid); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.db.LoadAssetGeoViolations(hash, limit, offset)
+}
@@ -0,0 +1,123 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/grpc/schedulerpb"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Interval of checking the asset pull progress
+const assetProgressInterval = 5 * time.Second
+
+type assetService struct {
+	schedulerpb.UnimplementedAssetServiceServer
+	scheduler api.Scheduler
+}
+
+func (s *assetService) GetAssetRecord(ctx context.Context, req *schedulerpb.GetAssetRecordRequest) (*schedulerpb.AssetRecord, error) {
+	record, err := s.scheduler.GetAssetRecord(ctx, req.Cid)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return toPBAssetRecord(record), nil
+}
+
+func (s *assetService) ListAssetRecords(ctx context.Context, req *schedulerpb.ListAssetRecordsRequest) (*schedulerpb.ListAssetRecordsResponse, error) {
+	states := req.States
+	if len(states) == 0 {
+		states = assets.ActiveStates
+	}
+
+	// empty server id is the current scheduler
+	records, err := s.scheduler.GetAssetRecords(ctx, int(req.Limit), int(req.Offset), states, "")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	out := &schedulerpb.ListAssetRecordsResponse{}
+	for _, record := range records {
+		out.Records = append(out.Records, toPBAssetRecord(record))
+	}
+
+	return out, nil
+}
+
+func (s *assetService) WatchAssetProgress(req *schedulerpb.GetAssetRecordRequest, stream schedulerpb.AssetService_WatchAssetProgressServer) error {
+	ticker := time.NewTicker(assetProgressInterval)
+	defer ticker.Stop()
+
+	var last *schedulerpb.AssetRecord
+	for {
+		record, err := s.scheduler.GetAssetRecord(stream.Context(), req.Cid)
+		if err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+
+		current := toPBAssetRecord(record)
+		if last == nil || !proto.Equal(last, current) {
+			if err = stream.Send(current); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		if isFinalState(record.State) {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// isFinalState returns true if the asset will not be pulled any more
+func isFinalState(state string) bool {
+	switch state {
+	case assets.Servicing.String(), assets.Remove.String(), assets.Stop.String(), assets.Archived.String():
+		return true
+	}
+
+	for _, s := range assets.FailedStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toPBAssetRecord(record *types.AssetRecord) *schedulerpb.AssetRecord {
+	out := &schedulerpb.AssetRecord{
+		Cid:               record.CID,
+		Hash:              record.Hash,
+		State:             record.State,
+		TotalSize:         record.TotalSize,
+		TotalBlocks:       record.TotalBlocks,
+		EdgeReplicas:      record.NeedEdgeReplica,
+		CandidateReplicas: record.NeedCandidateReplicas,
+		Expiration:        unixTime(record.Expiration),
+		CreatedTime:       unixTime(record.CreatedTime),
+	}
+
+	for _, replica := range record.ReplicaInfos {
+		out.Replicas = append(out.Replicas, &schedulerpb.ReplicaInfo{
+			NodeId:      replica.NodeID,
+			Status:      int32(replica.Status),
+			IsCandidate: replica.IsCandidate,
+			DoneSize:    replica.DoneSize,
+		})
+	}
+
+	return out
+}
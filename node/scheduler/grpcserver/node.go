@@ -0,0 +1,119 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/grpc/schedulerpb"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type nodeService struct {
+	schedulerpb.UnimplementedNodeServiceServer
+	scheduler api.Scheduler
+}
+
+func (s *nodeService) GetNodeInfo(ctx context.Context, req *schedulerpb.GetNodeInfoRequest) (*schedulerpb.NodeInfo, error) {
+	info, err := s.scheduler.GetNodeInfo(ctx, req.NodeId)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return toPBNodeInfo(&info), nil
+}
+
+func (s *nodeService) ListNodes(ctx context.Context, req *schedulerpb.ListNodesRequest) (*schedulerpb.ListNodesResponse, error) {
+	listReq := &types.ListNodesReq{
+		Cursor:   req.Cursor,
+		Limit:    int(req.Limit),
+		SortBy:   req.SortBy,
+		Desc:     req.Desc,
+		Type:     types.NodeType(req.Type),
+		Region:   req.Region,
+		NATType:  req.NatType,
+		MinScore: int(req.MinScore),
+		MaxScore: int(req.MaxScore),
+	}
+	for _, st := range req.Statuses {
+		listReq.Statuses = append(listReq.Statuses, types.NodeStatus(st))
+	}
+
+	rsp, err := s.scheduler.ListNodes(ctx, listReq)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	out := &schedulerpb.ListNodesResponse{NextCursor: rsp.NextCursor}
+	for i := range rsp.Data {
+		out.Nodes = append(out.Nodes, toPBNodeInfo(&rsp.Data[i]))
+	}
+
+	return out, nil
+}
+
+func (s *nodeService) GetOnlineNodeCount(ctx context.Context, req *schedulerpb.GetOnlineNodeCountRequest) (*schedulerpb.GetOnlineNodeCountResponse, error) {
+	count, err := s.scheduler.GetOnlineNodeCount(ctx, types.NodeType(req.Type))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &schedulerpb.GetOnlineNodeCountResponse{Count: int64(count)}, nil
+}
+
+func (s *nodeService) WatchNodeEvents(req *schedulerpb.WatchNodeEventsRequest, stream schedulerpb.NodeService_WatchNodeEventsServer) error {
+	events, err := s.scheduler.SubscribeNodeEvents(stream.Context())
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	filter := make(map[string]struct{}, len(req.NodeIds))
+	for _, nodeID := range req.NodeIds {
+		filter[nodeID] = struct{}{}
+	}
+
+	for event := range events {
+		if len(filter) > 0 {
+			if _, ok := filter[event.NodeID]; !ok {
+				continue
+			}
+		}
+
+		err = stream.Send(&schedulerpb.NodeEvent{
+			NodeId: event.NodeID,
+			Event:  event.Event.String(),
+			Time:   unixTime(event.Time),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toPBNodeInfo(info *types.NodeInfo) *schedulerpb.NodeInfo {
+	return &schedulerpb.NodeInfo{
+		NodeId:             info.NodeID,
+		Type:               int32(info.Type),
+		Status:             int32(info.Status),
+		ExternalIp:         info.ExternalIP,
+		NatType:            info.NATType,
+		CpuCores:           int32(info.CPUCores),
+		CpuUsage:           info.CPUUsage,
+		Memory:             info.Memory,
+		MemoryUsage:        info.MemoryUsage,
+		DiskSpace:          info.DiskSpace,
+		AvailableDiskSpace: info.AvailableDiskSpace,
+		DiskUsage:          info.DiskUsage,
+		BandwidthUp:        info.BandwidthUp,
+		BandwidthDown:      info.BandwidthDown,
+		OnlineDuration:     int64(info.OnlineDuration),
+		Profit:             info.Profit.Float64(),
+		UploadTraffic:      info.UploadTraffic,
+		DownloadTraffic:    info.DownloadTraffic,
+		SystemVersion:      info.SystemVersion,
+		LastSeen:           unixTime(info.LastSeen),
+	}
+}
@@ -0,0 +1,126 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/grpc/schedulerpb"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	logging "github.com/ipfs/go-log/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var log = logging.Logger("grpcserver")
+
+// permissions allowed to call the grpc api
+var allowedPermissions = []auth.Permission{api.RoleWeb, api.RoleAdmin, api.RoleOperator, api.RoleReadOnly}
+
+// Server serves the node, asset and validation api of the scheduler over grpc
+type Server struct {
+	scheduler api.Scheduler
+	srv       *grpc.Server
+}
+
+// New creates a new grpc server for the scheduler api
+func New(scheduler api.Scheduler) *Server {
+	s := &Server{scheduler: scheduler}
+	s.srv = grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+
+	schedulerpb.RegisterNodeServiceServer(s.srv, &nodeService{scheduler: scheduler})
+	schedulerpb.RegisterAssetServiceServer(s.srv, &assetService{scheduler: scheduler})
+	schedulerpb.RegisterValidationServiceServer(s.srv, &validationService{scheduler: scheduler})
+
+	return s
+}
+
+// Serve listens on the address and serves the grpc api, it blocks until the server stops
+func (s *Server) Serve(address string) error {
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("grpc server listen on %s", address)
+	return s.srv.Serve(ln)
+}
+
+// Stop stops the server gracefully
+func (s *Server) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.srv.Stop()
+	}
+
+	return nil
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.verify(ctx); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.verify(ss.Context()); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// verify checks the jwt token in the authorization metadata, the same token as json-rpc is accepted
+func (s *Server) verify(ctx context.Context) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "missing authorization")
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+
+	payload, err := s.scheduler.AuthVerify(ctx, token)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "verify token: %s", err.Error())
+	}
+
+	for _, perm := range payload.Allow {
+		for _, allowed := range allowedPermissions {
+			if perm == allowed {
+				return nil
+			}
+		}
+	}
+
+	return status.Error(codes.PermissionDenied, "permission denied")
+}
+
+// unixTime returns the unix timestamp of t, 0 if t is zero
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
@@ -0,0 +1,84 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/grpc/schedulerpb"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T) schedulerpb.NodeServiceClient {
+	scheduler := &api.SchedulerStruct{}
+	scheduler.CommonStruct.Internal.AuthVerify = func(ctx context.Context, token string) (*types.JWTPayload, error) {
+		switch token {
+		case "web":
+			return &types.JWTPayload{Allow: []auth.Permission{api.RoleWeb}}, nil
+		case "edge":
+			return &types.JWTPayload{Allow: []auth.Permission{api.RoleEdge}}, nil
+		}
+		return nil, xerrors.New("invalid token")
+	}
+	scheduler.NodeAPIStruct.Internal.GetNodeInfo = func(ctx context.Context, nodeID string) (types.NodeInfo, error) {
+		info := types.NodeInfo{Type: types.NodeEdge}
+		info.NodeID = nodeID
+		info.Status = types.NodeServicing
+		return info, nil
+	}
+
+	ln := bufconn.Listen(1024 * 1024)
+	s := New(scheduler)
+	go s.srv.Serve(ln) //nolint:errcheck
+	t.Cleanup(s.srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+
+	return schedulerpb.NewNodeServiceClient(conn)
+}
+
+func TestGetNodeInfo(t *testing.T) {
+	client := newTestClient(t)
+
+	tests := []struct {
+		token string
+		code  codes.Code
+	}{
+		{"", codes.Unauthenticated},
+		{"invalid", codes.Unauthenticated},
+		{"edge", codes.PermissionDenied},
+		{"web", codes.OK},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+		}
+
+		info, err := client.GetNodeInfo(ctx, &schedulerpb.GetNodeInfoRequest{NodeId: "e_1"})
+		if code := status.Code(err); code != tt.code {
+			t.Fatalf("token %q: expected code %s, got %s", tt.token, tt.code, code)
+		}
+
+		if err == nil && (info.NodeId != "e_1" || info.Type != int32(types.NodeEdge) || info.Status != int32(types.NodeServicing)) {
+			t.Fatalf("unexpected node info %v", info)
+		}
+	}
+}
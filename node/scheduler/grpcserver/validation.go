@@ -0,0 +1,104 @@
+package grpcserver
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/grpc/schedulerpb"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Interval of checking the new validation results
+	validationResultInterval = 30 * time.Second
+	// Maximum number of validation results loaded each check
+	validationResultLimit = 100
+)
+
+type validationService struct {
+	schedulerpb.UnimplementedValidationServiceServer
+	scheduler api.Scheduler
+}
+
+func (s *validationService) GetValidationResults(ctx context.Context, req *schedulerpb.GetValidationResultsRequest) (*schedulerpb.GetValidationResultsResponse, error) {
+	rsp, err := s.scheduler.GetValidationResults(ctx, req.NodeId, int(req.Limit), int(req.Offset))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	out := &schedulerpb.GetValidationResultsResponse{Total: int64(rsp.Total)}
+	for i := range rsp.ValidationResultInfos {
+		out.Results = append(out.Results, toPBValidationResult(&rsp.ValidationResultInfos[i]))
+	}
+
+	return out, nil
+}
+
+func (s *validationService) WatchValidationResults(req *schedulerpb.WatchValidationResultsRequest, stream schedulerpb.ValidationService_WatchValidationResultsServer) error {
+	ticker := time.NewTicker(validationResultInterval)
+	defer ticker.Stop()
+
+	// the results that existed before the watch started are not streamed
+	seen := make(map[int]struct{})
+	first := true
+
+	for {
+		rsp, err := s.scheduler.GetValidationResults(stream.Context(), req.NodeId, validationResultLimit, 0)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		results := rsp.ValidationResultInfos
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].ID < results[j].ID
+		})
+
+		current := make(map[int]struct{}, len(results))
+		for i := range results {
+			result := &results[i]
+			// unfinished results are checked again next time
+			if result.Status == types.ValidationStatusCreate {
+				continue
+			}
+
+			current[result.ID] = struct{}{}
+			if _, ok := seen[result.ID]; ok || first {
+				continue
+			}
+
+			if err = stream.Send(toPBValidationResult(result)); err != nil {
+				return err
+			}
+		}
+
+		seen = current
+		first = false
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func toPBValidationResult(result *types.ValidationResultInfo) *schedulerpb.ValidationResult {
+	return &schedulerpb.ValidationResult{
+		Id:          int64(result.ID),
+		RoundId:     result.RoundID,
+		NodeId:      result.NodeID,
+		ValidatorId: result.ValidatorID,
+		Cid:         result.Cid,
+		Status:      int32(result.Status),
+		BlockNumber: result.BlockNumber,
+		Duration:    result.Duration,
+		Bandwidth:   result.Bandwidth,
+		StartTime:   unixTime(result.StartTime),
+		EndTime:     unixTime(result.EndTime),
+		Profit:      result.Profit.Float64(),
+	}
+}
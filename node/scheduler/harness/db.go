@@ -0,0 +1,96 @@
+package harness
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+const dbName = "titan"
+
+// NewDB starts an in-process database speaking the mysql protocol and creates the scheduler tables in it,
+// the data is kept in memory and lost when the returned close function is called
+func NewDB(serverID dtypes.ServerID) (*db.SQLDB, func(), error) {
+	mdb := memory.NewDatabase(dbName)
+	mdb.EnablePrimaryKeyIndexes()
+
+	engine := sqle.NewDefault(memory.NewDBProvider(mdb))
+
+	addr, err := freeAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	srv, err := server.NewDefaultServer(server.Config{Protocol: "tcp", Address: addr}, engine)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go func() {
+		if err := srv.Start(); err != nil {
+			log.Debugf("database server stopped: %s", err.Error())
+		}
+	}()
+
+	client, err := sqlx.Open("mysql", fmt.Sprintf("root@tcp(%s)/%s?parseTime=true&loc=Local", addr, dbName))
+	if err != nil {
+		srv.Close()
+		return nil, nil, err
+	}
+
+	if err = ping(client); err != nil {
+		client.Close()
+		srv.Close()
+		return nil, nil, err
+	}
+
+	closeFunc := func() {
+		client.Close()
+		srv.Close()
+	}
+
+	sdb, err := db.NewSQLDB(client)
+	if err != nil {
+		closeFunc()
+		return nil, nil, err
+	}
+
+	if err = db.InitTables(sdb, serverID); err != nil {
+		closeFunc()
+		return nil, nil, err
+	}
+
+	return sdb, closeFunc, nil
+}
+
+// freeAddress returns a local address no one listens on
+func freeAddress() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+
+	return l.Addr().String(), nil
+}
+
+// ping waits for the server to accept connections
+func ping(client *sqlx.DB) error {
+	var err error
+	for i := 0; i < 50; i++ {
+		if err = client.Ping(); err == nil {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return err
+}
@@ -0,0 +1,171 @@
+// Package harness runs the scheduler managers against an in-memory database and simulated nodes
+// for integration tests of the manager behavior at scale.
+package harness
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/overload"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("harness")
+
+const (
+	serverID = dtypes.ServerID("harness-scheduler")
+	// expiredKeepalive is older than the keepalive timeout of the node manager
+	expiredKeepalive = 2 * time.Minute
+)
+
+// Options the nodes the harness starts with and the scheduler config, the default config is used if nil
+type Options struct {
+	Edges      int
+	Candidates int
+	Config     *config.SchedulerCfg
+}
+
+// Harness a scheduler node manager backed by an in-memory database with simulated nodes online
+type Harness struct {
+	DB          *db.SQLDB
+	Notify      *eventbus.Bus
+	Config      *config.SchedulerCfg
+	NodeManager *node.Manager
+
+	lk    sync.Mutex
+	nodes map[string]*SimNode
+	seq   int
+
+	closeDB func()
+}
+
+// New starts the harness and brings the nodes of the options online
+func New(opts Options) (*Harness, error) {
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = config.DefaultSchedulerCfg()
+	}
+
+	sdb, closeDB, err := NewDB(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	configFunc := dtypes.GetSchedulerConfigFunc(func() (config.SchedulerCfg, error) {
+		return *cfg, nil
+	})
+
+	h := &Harness{
+		DB:      sdb,
+		Notify:  eventbus.New(),
+		Config:  cfg,
+		nodes:   make(map[string]*SimNode),
+		closeDB: closeDB,
+	}
+	h.NodeManager = node.NewManager(sdb, serverID, nil, h.Notify, configFunc, overload.NewManager(sdb, configFunc),
+		jobqueue.NewQueue(sdb, serverID, configFunc))
+
+	if _, err = h.AddNodes(types.NodeEdge, opts.Edges); err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	if _, err = h.AddNodes(types.NodeCandidate, opts.Candidates); err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Close stops the database, the timers of the managers keep running until the process exits
+func (h *Harness) Close() {
+	h.Notify.Shutdown()
+	h.closeDB()
+}
+
+// AddNodes brings count new simulated nodes of the type online
+func (h *Harness) AddNodes(nodeType types.NodeType, count int) ([]*SimNode, error) {
+	nodes := make([]*SimNode, 0, count)
+
+	for i := 0; i < count; i++ {
+		h.lk.Lock()
+		h.seq++
+		nodeID := fmt.Sprintf("%s_sim_%d", nodeIDPrefix(nodeType), h.seq)
+		h.lk.Unlock()
+
+		n := newSimNode(nodeID, nodeType)
+		if err := h.Online(n); err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+// Online registers the node with the node manager as the connect flow does
+func (h *Harness) Online(n *SimNode) error {
+	n.SetLastRequestTime(time.Now())
+	if err := h.NodeManager.NodeOnline(n.Node, n.Info); err != nil {
+		return err
+	}
+
+	h.lk.Lock()
+	h.nodes[n.NodeID] = n
+	h.lk.Unlock()
+
+	return nil
+}
+
+// Nodes returns the simulated nodes of the type, the offline nodes included
+func (h *Harness) Nodes(nodeType types.NodeType) []*SimNode {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+
+	nodes := make([]*SimNode, 0)
+	for _, n := range h.nodes {
+		if n.Type == nodeType {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes
+}
+
+// Keepalive sends a keepalive from each of the nodes
+func (h *Harness) Keepalive(nodes []*SimNode) {
+	now := time.Now()
+	for _, n := range nodes {
+		h.NodeManager.KeepaliveNode(n.Node, now)
+	}
+}
+
+// Expire moves the last keepalive of the nodes back beyond the timeout, the next keepalive check takes them offline
+func (h *Harness) Expire(nodes []*SimNode) {
+	t := time.Now().Add(-expiredKeepalive)
+	for _, n := range nodes {
+		h.NodeManager.KeepaliveNode(n.Node, t)
+	}
+}
+
+// CheckKeepalives runs the keepalive check of the node manager, the information of the online nodes is saved if save is true
+func (h *Harness) CheckKeepalives(save bool) {
+	h.NodeManager.CheckKeepalives(save)
+}
+
+func nodeIDPrefix(nodeType types.NodeType) string {
+	if nodeType == types.NodeEdge {
+		return "e"
+	}
+	return "c"
+}
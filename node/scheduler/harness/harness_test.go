@@ -0,0 +1,79 @@
+package harness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestKeepaliveAtScale(t *testing.T) {
+	h, err := New(Options{Edges: 200, Candidates: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	edges := h.Nodes(types.NodeEdge)
+	if count := h.NodeManager.GetOnlineNodeCount(types.NodeEdge); count != 200 {
+		t.Fatalf("expected 200 edges online, got %d", count)
+	}
+
+	h.Expire(edges[:50])
+	h.Keepalive(edges[50:])
+	h.CheckKeepalives(true)
+
+	if count := h.NodeManager.GetOnlineNodeCount(types.NodeEdge); count != 150 {
+		t.Fatalf("expected 150 edges online, got %d", count)
+	}
+	if h.NodeManager.GetEdgeNode(edges[0].NodeID) != nil {
+		t.Fatalf("expected %s offline", edges[0].NodeID)
+	}
+	if count := h.NodeManager.GetOnlineNodeCount(types.NodeCandidate); count != 20 {
+		t.Fatalf("expected 20 candidates online, got %d", count)
+	}
+
+	info, err := h.DB.LoadNodeInfo(edges[60].NodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.OnlineDuration == 0 {
+		t.Fatal("expected the online duration saved by the keepalive check")
+	}
+}
+
+func TestSimNodeCalls(t *testing.T) {
+	h, err := New(Options{Candidates: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	ctx := context.Background()
+	c := h.Nodes(types.NodeCandidate)[0]
+	n := h.NodeManager.GetCandidateNode(c.NodeID)
+
+	if err = n.PullAsset(ctx, "cid_1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := n.GetAssetProgresses(ctx, []string{"cid_1", "cid_2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Progresses[0].Status != types.ReplicaStatusSucceeded || result.Progresses[1].Status != types.ReplicaStatusFailed {
+		t.Fatalf("unexpected progresses %+v %+v", result.Progresses[0], result.Progresses[1])
+	}
+
+	if err = n.ExecuteValidation(ctx, &api.ValidateReq{RandomSeed: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Validations()) != 1 {
+		t.Fatalf("expected 1 validation, got %d", len(c.Validations()))
+	}
+
+	if _, err = n.GetAssetStats(ctx); err == nil {
+		t.Fatal("expected the calls not simulated to fail")
+	}
+}
@@ -0,0 +1,137 @@
+package harness
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+// SimNode a simulated edge or candidate, the scheduler calls it through the generated api structs,
+// the pulls finish at once and the validations are recorded, the calls it does not simulate return api.ErrNotSupported
+type SimNode struct {
+	*node.Node
+	Info *types.NodeInfo
+
+	lk          sync.Mutex
+	assets      map[string]struct{}
+	validations []*api.ValidateReq
+
+	// PullErr and ValidateErr are returned by the pulls and the validations if set
+	PullErr     error
+	ValidateErr error
+}
+
+func newSimNode(nodeID string, nodeType types.NodeType) *SimNode {
+	n := &SimNode{assets: make(map[string]struct{})}
+
+	n.Node = node.New()
+	n.NodeID = nodeID
+	n.Type = nodeType
+	n.ClientCloser = func() {}
+	n.DiskSpace = 100 << 30
+	n.AvailableDiskSpace = n.DiskSpace
+	n.BandwidthDown = 100 << 20
+	n.BandwidthUp = 100 << 20
+	n.NATType = types.NatTypeNo
+
+	switch nodeType {
+	case types.NodeEdge:
+		s := new(api.EdgeStruct)
+		n.bindAsset(&s.AssetStruct)
+		n.bindValidation(&s.ValidationStruct)
+		n.API = node.APIFromEdge(s)
+	default:
+		s := new(api.CandidateStruct)
+		n.bindAsset(&s.AssetStruct)
+		n.bindValidation(&s.ValidationStruct)
+		n.API = node.APIFromCandidate(s)
+	}
+
+	n.Info = &types.NodeInfo{
+		Type:               nodeType,
+		DiskSpace:          n.DiskSpace,
+		AvailableDiskSpace: n.AvailableDiskSpace,
+		BandwidthDown:      n.BandwidthDown,
+		BandwidthUp:        n.BandwidthUp,
+		NATType:            n.NATType.String(),
+		NodeDynamicInfo:    types.NodeDynamicInfo{NodeID: nodeID},
+	}
+
+	return n
+}
+
+func (n *SimNode) bindAsset(s *api.AssetStruct) {
+	s.Internal.PullAsset = func(ctx context.Context, cid string, sources []*types.CandidateDownloadInfo) error {
+		n.lk.Lock()
+		defer n.lk.Unlock()
+
+		if n.PullErr != nil {
+			return n.PullErr
+		}
+
+		n.assets[cid] = struct{}{}
+		return nil
+	}
+
+	s.Internal.DeleteAsset = func(ctx context.Context, cid string) error {
+		n.lk.Lock()
+		defer n.lk.Unlock()
+
+		delete(n.assets, cid)
+		return nil
+	}
+
+	s.Internal.GetAssetProgresses = func(ctx context.Context, cids []string) (*types.PullResult, error) {
+		n.lk.Lock()
+		defer n.lk.Unlock()
+
+		result := &types.PullResult{AssetCount: len(n.assets)}
+		for _, cid := range cids {
+			status := types.ReplicaStatusFailed
+			if _, ok := n.assets[cid]; ok {
+				status = types.ReplicaStatusSucceeded
+			}
+			result.Progresses = append(result.Progresses, &types.AssetPullProgress{CID: cid, Status: status})
+		}
+
+		return result, nil
+	}
+}
+
+func (n *SimNode) bindValidation(s *api.ValidationStruct) {
+	s.Internal.ExecuteValidation = func(ctx context.Context, req *api.ValidateReq) error {
+		n.lk.Lock()
+		defer n.lk.Unlock()
+
+		if n.ValidateErr != nil {
+			return n.ValidateErr
+		}
+
+		n.validations = append(n.validations, req)
+		return nil
+	}
+}
+
+// Assets returns the cids of the assets pulled by the node
+func (n *SimNode) Assets() []string {
+	n.lk.Lock()
+	defer n.lk.Unlock()
+
+	cids := make([]string, 0, len(n.assets))
+	for cid := range n.assets {
+		cids = append(cids, cid)
+	}
+
+	return cids
+}
+
+// Validations returns the validation requests the node received
+func (n *SimNode) Validations() []*api.ValidateReq {
+	n.lk.Lock()
+	defer n.lk.Unlock()
+
+	return append([]*api.ValidateReq(nil), n.validations...)
+}
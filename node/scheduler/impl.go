@@ -3,8 +3,6 @@ package scheduler
 import (
 	"bytes"
 	"context"
-	"crypto"
-	"crypto/rsa"
 	"database/sql"
 	"encoding/gob"
 	"encoding/hex"
@@ -13,9 +11,46 @@ import (
 	"net"
 	"time"
 
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/alert"
+	"github.com/Filecoin-Titan/titan/node/scheduler/bulk"
+	"github.com/Filecoin-Titan/titan/node/scheduler/ca"
+	"github.com/Filecoin-Titan/titan/node/scheduler/coldstorage"
+	"github.com/Filecoin-Titan/titan/node/scheduler/commitment"
+	"github.com/Filecoin-Titan/titan/node/scheduler/configpush"
+	"github.com/Filecoin-Titan/titan/node/scheduler/decision"
+	"github.com/Filecoin-Titan/titan/node/scheduler/denylist"
+	"github.com/Filecoin-Titan/titan/node/scheduler/discovery"
+	"github.com/Filecoin-Titan/titan/node/scheduler/dnsrouting"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/export"
+	"github.com/Filecoin-Titan/titan/node/scheduler/featureflag"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leaderboard"
+	"github.com/Filecoin-Titan/titan/node/scheduler/locindex"
 	"github.com/Filecoin-Titan/titan/node/scheduler/nat"
+	"github.com/Filecoin-Titan/titan/node/scheduler/nodediag"
+	"github.com/Filecoin-Titan/titan/node/scheduler/outbox"
+	"github.com/Filecoin-Titan/titan/node/scheduler/overload"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	"github.com/Filecoin-Titan/titan/node/scheduler/pointsepoch"
+	"github.com/Filecoin-Titan/titan/node/scheduler/relay"
+	"github.com/Filecoin-Titan/titan/node/scheduler/replication"
+	"github.com/Filecoin-Titan/titan/node/scheduler/retention"
+	"github.com/Filecoin-Titan/titan/node/scheduler/retrievalprobe"
+	"github.com/Filecoin-Titan/titan/node/scheduler/settlement"
+	"github.com/Filecoin-Titan/titan/node/scheduler/signaling"
+	"github.com/Filecoin-Titan/titan/node/scheduler/speedtest"
+	"github.com/Filecoin-Titan/titan/node/scheduler/storageproof"
+	"github.com/Filecoin-Titan/titan/node/scheduler/tenant"
+	"github.com/Filecoin-Titan/titan/node/scheduler/token"
+	"github.com/Filecoin-Titan/titan/node/scheduler/traffic"
+	"github.com/Filecoin-Titan/titan/node/scheduler/upgrade"
 	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
+	"github.com/Filecoin-Titan/titan/node/scheduler/video"
+	"github.com/Filecoin-Titan/titan/node/scheduler/webhook"
 	"github.com/Filecoin-Titan/titan/node/scheduler/workload"
 	"github.com/docker/go-units"
 	"github.com/quic-go/quic-go"
@@ -32,6 +67,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
 	logging "github.com/ipfs/go-log/v2"
 
+	"github.com/Filecoin-Titan/titan/node/nodekey"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	sSync "github.com/Filecoin-Titan/titan/node/scheduler/sync"
 	"golang.org/x/xerrors"
@@ -58,14 +94,49 @@ type Scheduler struct {
 	ValidationMgr          *validation.Manager
 	AssetManager           *assets.Manager
 	NatManager             *nat.Manager
+	RelayManager           *relay.Manager
+	TrafficManager         *traffic.Manager
+	SettlementManager      *settlement.Manager
+	PenaltyManager         *penalty.Manager
+	PointsEpochManager     *pointsepoch.Manager
+	CommitmentManager      *commitment.Manager
+	LeaderboardManager     *leaderboard.Manager
+	OverloadManager        *overload.Manager
+	DecisionManager        *decision.Manager
+	DenylistManager        *denylist.Manager
+	DNSRoutingManager      *dnsrouting.Manager
+	SpeedTestManager       *speedtest.Manager
+	NodeDiagManager        *nodediag.Manager
+	UpgradeManager         *upgrade.Manager
+	ConfigPushManager      *configpush.Manager
+	FeatureFlagManager     *featureflag.Manager
+	RetrievalProbeManager  *retrievalprobe.Manager
+	StorageProofManager    *storageproof.Manager
+	VideoManager           *video.Manager
+	SignalingManager       *signaling.Manager
+	LocationIndex          *locindex.Index
+	OutboxManager          *outbox.Manager
 	DataSync               *sSync.DataSync
 	SchedulerCfg           *config.SchedulerCfg
 	SetSchedulerConfigFunc dtypes.SetSchedulerConfigFunc
 	GetSchedulerConfigFunc dtypes.GetSchedulerConfigFunc
 	WorkloadManager        *workload.Manager
-
-	PrivateKey *rsa.PrivateKey
-	Transport  *quic.Transport
+	AlertManager           *alert.Manager
+	TokenManager           *token.Manager
+	TenantManager          *tenant.Manager
+	WebhookManager         *webhook.Manager
+	ColdStorageManager     *coldstorage.Manager
+	ReplicationManager     *replication.Manager
+	BulkManager            *bulk.Manager
+	JobQueue               *jobqueue.Queue
+	RetentionManager       *retention.Manager
+	ExportManager          *export.Manager
+	DiscoveryManager       *discovery.Manager
+	CertAuthority          *ca.Authority
+	Notify                 *eventbus.Bus
+
+	KeyRing   *keys.Ring
+	Transport *quic.Transport
 }
 
 var _ api.Scheduler = &Scheduler{}
@@ -80,9 +151,14 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 	cNode := s.NodeManager.GetNode(nodeID)
 	if cNode == nil {
 		if err := s.NodeManager.NodeExists(nodeID, nodeType); err != nil {
+			if archived, aErr := s.NodeManager.IsNodeArchived(nodeID); aErr == nil && archived {
+				return xerrors.Errorf("node %s is archived after offline for long, ask the operator to restore it", nodeID)
+			}
 			return xerrors.Errorf("node: %s, type: %d, error: %w", nodeID, nodeType, err)
 		}
 		cNode = node.New()
+		// the zone is kept while the node is online, the weights and statistics of the node are in the zone
+		cNode.AreaID = s.NodeManager.ServedZone(opts.AreaID)
 		alreadyConnect = false
 	}
 
@@ -110,6 +186,7 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 	cNode.ExternalURL = opts.ExternalURL
 	cNode.TCPPort = opts.TcpServerPort
 	cNode.IsPrivateMinioOnly = opts.IsPrivateMinioOnly
+	cNode.Geo = opts.AreaID
 
 	log.Infof("node connected %s, address:%s , %v", nodeID, remoteAddr, alreadyConnect)
 
@@ -119,7 +196,7 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 	}
 
 	// init node info
-	nodeInfo, err := cNode.API.GetNodeInfo(context.Background())
+	nodeInfo, err := cNode.API.GetNodeInfo(tracing.Detach(ctx))
 	if err != nil {
 		log.Errorf("nodeConnect NodeInfo err:%s", err.Error())
 		return err
@@ -187,11 +264,21 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 		}
 	}
 
+	sandboxExpiration, err := s.NodeManager.LoadSandboxExpiration(nodeID)
+	if err != nil {
+		return xerrors.Errorf("LoadSandboxExpiration %s err:%s", nodeID, err.Error())
+	}
+
+	if !sandboxExpiration.IsZero() && !time.Now().Before(sandboxExpiration) {
+		return xerrors.Errorf("the sandbox node %s has expired", nodeID)
+	}
+
 	cNode.OnlineDuration = nodeInfo.OnlineDuration
 	cNode.BandwidthDown = nodeInfo.BandwidthDown
 	cNode.BandwidthUp = nodeInfo.BandwidthUp
 	cNode.PortMapping = nodeInfo.PortMapping
 	cNode.DeactivateTime = nodeInfo.DeactivateTime
+	cNode.SandboxExpiration = sandboxExpiration
 	cNode.AvailableDiskSpace = nodeInfo.AvailableDiskSpace
 	cNode.NodeID = nodeInfo.NodeID
 	cNode.Type = nodeInfo.Type
@@ -199,7 +286,11 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 	cNode.DiskSpace = nodeInfo.DiskSpace
 	cNode.TitanDiskUsage = nodeInfo.TitanDiskUsage
 	cNode.DiskUsage = nodeInfo.DiskUsage
+	cNode.DataProtocols = nodeInfo.DataProtocols
+	cNode.ProxyScheme = nodeInfo.ProxyScheme
+	cNode.Version = types.ReleaseVersion(nodeInfo.SystemVersion)
 	cNode.IncomeIncr = (cNode.CalculateMCx(s.NodeManager.TotalNetworkEdges) * 360)
+	s.NodeManager.LoadHardwareVerdict(cNode)
 
 	pCount, err := s.db.GetNodePullingCount(nodeID)
 	if err == nil {
@@ -212,7 +303,7 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 			return xerrors.Errorf("load node port %s err : %s", nodeID, err.Error())
 		}
 
-		publicKey, err := titanrsa.Pem2PublicKey([]byte(pStr))
+		publicKey, err := nodekey.Pem2PublicKey([]byte(pStr))
 		if err != nil {
 			return xerrors.Errorf("load node port %s err : %s", nodeID, err.Error())
 		}
@@ -225,10 +316,18 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 		}
 	}
 
-	if nodeType == types.NodeEdge {
+	if cNode.IsProxied() {
+		// the nat of the proxy would be detected instead of the nat of the node
+		log.Infof("node %s connected through %s proxy %s", nodeID, cNode.ProxyScheme, remoteAddr)
+	} else if nodeType == types.NodeEdge {
 		go s.NatManager.DetermineEdgeNATType(context.Background(), nodeID)
 	}
 
+	go s.UpgradeManager.NodeConnected(nodeID, cNode.Version)
+	go s.ConfigPushManager.NodeConnected(nodeID)
+	// the tasks queued while the node was offline
+	go s.NodeManager.DeliverTasks(nodeID)
+
 	s.DataSync.AddNodeToList(nodeID)
 
 	return nil
@@ -292,8 +391,7 @@ func (s *Scheduler) NodeValidationResult(ctx context.Context, r io.Reader, sign
 		return err
 	}
 
-	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	err = rsa.VerifySign(node.PublicKey, signBuf, data)
+	err = s.NodeManager.VerifyReport(node, types.ReportValidation, signBuf, data)
 	if err != nil {
 		return err
 	}
@@ -307,7 +405,7 @@ func (s *Scheduler) NodeValidationResult(ctx context.Context, r io.Reader, sign
 	}
 
 	result.Validator = validator
-	s.ValidationMgr.PushResult(result)
+	s.ValidationMgr.PushResult(result, data, signBuf)
 
 	return nil
 }
@@ -328,17 +426,31 @@ func (s *Scheduler) GetValidationResults(ctx context.Context, nodeID string, lim
 	return svm, nil
 }
 
+// GetValidationProofs retrieves the validation results of the node signed by the validators and the scheduler, the latest first
+func (s *Scheduler) GetValidationProofs(ctx context.Context, nodeID string, limit, offset int) (*types.ListValidationProofRsp, error) {
+	return s.NodeManager.LoadValidationProofs(nodeID, limit, offset)
+}
+
+// VerifyValidationProofs checks the hashes, the links and the signatures of the chain of the validation proofs of the node
+func (s *Scheduler) VerifyValidationProofs(ctx context.Context, nodeID string) (*types.ValidationProofAudit, error) {
+	return s.ValidationMgr.VerifyProofs(nodeID)
+}
+
 // GetSchedulerPublicKey get server publicKey
 func (s *Scheduler) GetSchedulerPublicKey(ctx context.Context) (string, error) {
-	if s.PrivateKey == nil {
+	if s.KeyRing == nil {
 		return "", fmt.Errorf("scheduler private key not exist")
 	}
 
-	publicKey := s.PrivateKey.PublicKey
-	pem := titanrsa.PublicKey2Pem(&publicKey)
+	pem := titanrsa.PublicKey2Pem(s.KeyRing.PublicKey())
 	return string(pem), nil
 }
 
+// RotateSchedulerKey replaces the scheduler private key
+func (s *Scheduler) RotateSchedulerKey(ctx context.Context) error {
+	return s.KeyRing.Rotate()
+}
+
 // GetNodePublicKey get node publicKey
 func (s *Scheduler) GetNodePublicKey(ctx context.Context, nodeID string) (string, error) {
 	pem, err := s.NodeManager.LoadNodePublicKey(nodeID)
@@ -349,6 +461,11 @@ func (s *Scheduler) GetNodePublicKey(ctx context.Context, nodeID string) (string
 	return string(pem), nil
 }
 
+// GetHardwareProof get the latest hardware challenge proof of the node
+func (s *Scheduler) GetHardwareProof(ctx context.Context, nodeID string) (*types.HardwareProof, error) {
+	return s.NodeManager.LoadHardwareProof(nodeID)
+}
+
 // GetValidationInfo  get information related to validation and election
 func (s *Scheduler) GetValidationInfo(ctx context.Context) (*types.ValidationInfo, error) {
 	eTime := s.ValidationMgr.GetNextElectionTime()
@@ -358,6 +475,11 @@ func (s *Scheduler) GetValidationInfo(ctx context.Context) (*types.ValidationInf
 	}, nil
 }
 
+// GetValidatorLoads retrieves the validations of the validators connected to the scheduler
+func (s *Scheduler) GetValidatorLoads(ctx context.Context) ([]*types.ValidatorLoad, error) {
+	return s.ValidationMgr.ValidatorLoads(), nil
+}
+
 // SubmitUserWorkloadReport submits report of workload for User Asset Download
 func (s *Scheduler) SubmitUserWorkloadReport(ctx context.Context, r io.Reader) error {
 	return nil
@@ -370,7 +492,7 @@ func (s *Scheduler) SubmitUserWorkloadReport(ctx context.Context, r io.Reader) e
 	// }
 
 	// titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	// data, err := titanRsa.Decrypt(cipherText, s.PrivateKey)
+	// data, err := s.KeyRing.Decrypt(cipherText)
 	// if err != nil {
 	// 	return xerrors.Errorf("decrypt error: %w", err)
 	// }
@@ -393,12 +515,11 @@ func (s *Scheduler) SubmitNodeWorkloadReport(ctx context.Context, r io.Reader) e
 		return xerrors.Errorf("decode data to NodeWorkloadReport error: %w", err)
 	}
 
-	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	if err = titanRsa.VerifySign(node.PublicKey, report.Sign, report.CipherText); err != nil {
+	if err = s.NodeManager.VerifyReport(node, types.ReportWorkload, report.Sign, report.CipherText); err != nil {
 		return xerrors.Errorf("verify sign error: %w", err)
 	}
 
-	data, err := titanRsa.Decrypt(report.CipherText, s.PrivateKey)
+	data, err := s.KeyRing.Decrypt(report.CipherText)
 	if err != nil {
 		return xerrors.Errorf("decrypt error: %w", err)
 	}
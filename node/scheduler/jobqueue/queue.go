@@ -0,0 +1,396 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("jobqueue")
+
+const (
+	pollInterval      = 5 * time.Second
+	heartbeatInterval = 10 * time.Second
+	// a running job without heartbeat for the duration is run again, its worker stopped
+	staleTimeout    = 2 * time.Minute
+	requeueInterval = time.Minute
+	// the backoff before the retry of a failed attempt, doubled with each attempt
+	minBackoff = time.Minute
+	maxBackoff = time.Hour
+	// the length of the message column of the jobs
+	maxMessageLen = 512
+)
+
+// ErrDuplicateJob the job is not enqueued, a job of its dedup key exists
+var ErrDuplicateJob = xerrors.New("duplicate job")
+
+// Handler runs a job, the job is retried if the handler returns an error and the attempts of the job are not used up.
+// The context is canceled when the job is canceled, the handler reports its progress with the progress function
+type Handler func(ctx context.Context, job *types.Job, progress Progress) error
+
+// Progress reports the items of the job done and the total items
+type Progress func(done, total int64)
+
+// Options the options of an enqueued job
+type Options struct {
+	// DedupKey the job is not enqueued if a job of the key exists
+	DedupKey string
+	// Local the job runs on the scheduler enqueuing it, for the jobs on the nodes connected to it
+	Local bool
+	// MaxAttempts the attempts of the job, 1 if 0
+	MaxAttempts int
+	// RunAfter the job is not run before the time
+	RunAfter time.Time
+}
+
+// Queue persists the long-running tasks of the schedulers as jobs and runs them by the workers of the schedulers.
+// A job is claimed by one worker, retried with backoff when it fails, canceled by the operator, and run again
+// by another worker when its worker stops
+type Queue struct {
+	*db.SQLDB
+	serverID dtypes.ServerID
+
+	lk       sync.RWMutex
+	handlers map[string]Handler
+
+	wake    chan struct{}
+	running sync.Map // job id -> context.CancelFunc
+	active  int64
+}
+
+// NewQueue return new job queue instance, the workers start at once and run the jobs of the kinds registered
+func NewQueue(sdb *db.SQLDB, serverID dtypes.ServerID, configFunc dtypes.GetSchedulerConfigFunc) *Queue {
+	q := &Queue{
+		SQLDB:    sdb,
+		serverID: serverID,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+	}
+
+	workers := 1
+	if cfg, err := configFunc(); err != nil {
+		log.Errorf("get config err:%s", err.Error())
+	} else if cfg.JobQueueWorkers > 0 {
+		workers = cfg.JobQueueWorkers
+	}
+
+	diagnostics.RegisterSize("jobqueue.running", func() int { return int(atomic.LoadInt64(&q.active)) })
+
+	// the jobs left running by the previous process of the scheduler
+	q.requeue(string(serverID), time.Now())
+
+	for i := 0; i < workers; i++ {
+		go q.startWorker()
+	}
+	go q.startRequeueTimer()
+
+	return q
+}
+
+// Register registers the handler of the kind of jobs, the scheduler runs only the jobs of the kinds registered
+func (q *Queue) Register(kind string, h Handler) {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+
+	q.handlers[kind] = h
+	q.notify()
+}
+
+func (q *Queue) handler(kind string) Handler {
+	q.lk.RLock()
+	defer q.lk.RUnlock()
+
+	return q.handlers[kind]
+}
+
+func (q *Queue) kinds() []string {
+	q.lk.RLock()
+	defer q.lk.RUnlock()
+
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// Enqueue saves the job of the kind with the payload encoded as json and returns the job id,
+// ErrDuplicateJob is returned if a job of the dedup key exists
+func (q *Queue) Enqueue(kind string, payload interface{}, opts Options) (string, error) {
+	return q.EnqueueWithID(uuid.NewString(), kind, payload, opts)
+}
+
+// EnqueueWithID saves the job with the id, for the jobs whose records are saved with the id before
+func (q *Queue) EnqueueWithID(id, kind string, payload interface{}, opts Options) (string, error) {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return "", xerrors.Errorf("marshal payload err:%s", err.Error())
+	}
+
+	now := time.Now()
+	job := &types.Job{
+		ID:            id,
+		Kind:          kind,
+		DedupKey:      opts.DedupKey,
+		Payload:       buf,
+		Status:        types.JobPending,
+		MaxAttempts:   opts.MaxAttempts,
+		RunAfter:      now,
+		HeartbeatTime: now,
+		CreatedTime:   now,
+		UpdatedTime:   now,
+	}
+
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+
+	if opts.Local {
+		job.ServerID = string(q.serverID)
+	}
+
+	if opts.RunAfter.After(now) {
+		job.RunAfter = opts.RunAfter
+	}
+
+	saved, err := q.SaveJob(job)
+	if err != nil {
+		return "", err
+	}
+
+	if !saved {
+		return "", ErrDuplicateJob
+	}
+
+	q.notify()
+	return job.ID, nil
+}
+
+// Cancel cancels the pending or running job, the running job stops at the next check of its context
+func (q *Queue) Cancel(id string) error {
+	if err := q.CancelJob(id, time.Now()); err != nil {
+		return err
+	}
+
+	if cancel, ok := q.running.Load(id); ok {
+		cancel.(context.CancelFunc)()
+	}
+
+	return nil
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) startWorker() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job := q.claim()
+		if job != nil {
+			q.run(job)
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// claim claims a runnable job of the kinds registered, nil if there is none
+func (q *Queue) claim() *types.Job {
+	kinds := q.kinds()
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	ids, err := q.LoadRunnableJobIDs(kinds, string(q.serverID), now, 10)
+	if err != nil {
+		log.Errorf("LoadRunnableJobIDs err:%s", err.Error())
+		return nil
+	}
+
+	for _, id := range ids {
+		claimed, err := q.ClaimJob(id, string(q.serverID), now)
+		if err != nil {
+			log.Errorf("ClaimJob %s err:%s", id, err.Error())
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		job, err := q.LoadJob(id)
+		if err != nil {
+			log.Errorf("LoadJob %s err:%s", id, err.Error())
+			continue
+		}
+
+		return job
+	}
+
+	return nil
+}
+
+// run runs an attempt of the claimed job and saves its result
+func (q *Queue) run(job *types.Job) {
+	h := q.handler(job.Kind)
+	if h == nil {
+		q.finish(job, xerrors.Errorf("no handler of kind %s", job.Kind))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q.running.Store(job.ID, cancel)
+	defer q.running.Delete(job.ID)
+
+	atomic.AddInt64(&q.active, 1)
+	defer atomic.AddInt64(&q.active, -1)
+
+	var done, total int64
+	progress := func(d, t int64) {
+		atomic.StoreInt64(&done, d)
+		atomic.StoreInt64(&total, t)
+	}
+
+	stop := make(chan struct{})
+	go q.heartbeat(job.ID, cancel, stop, &done, &total)
+
+	log.Infof("job %s %s started, attempt %d/%d", job.ID, job.Kind, job.Attempts, job.MaxAttempts)
+	err := runHandler(ctx, h, job, progress)
+	close(stop)
+
+	if ctx.Err() != nil && err != nil {
+		// canceled, the status is kept
+		log.Infof("job %s %s canceled", job.ID, job.Kind)
+		return
+	}
+
+	job.ProgressDone, job.ProgressTotal = atomic.LoadInt64(&done), atomic.LoadInt64(&total)
+	q.finish(job, err)
+}
+
+// runHandler runs the handler, a panic of the handler fails the attempt
+func runHandler(ctx context.Context, h Handler, job *types.Job, progress Progress) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return h(ctx, job, progress)
+}
+
+// heartbeat saves the progress of the running job at intervals and cancels the job canceled by the operator on another scheduler
+func (q *Queue) heartbeat(id string, cancel context.CancelFunc, stop chan struct{}, done, total *int64) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		status, err := q.UpdateJobHeartbeat(id, atomic.LoadInt64(done), atomic.LoadInt64(total), time.Now())
+		if err != nil {
+			log.Errorf("UpdateJobHeartbeat %s err:%s", id, err.Error())
+			continue
+		}
+
+		if status != types.JobRunning {
+			cancel()
+			return
+		}
+	}
+}
+
+// finish saves the result of the attempt, the failed job is retried with backoff until its attempts are used up
+func (q *Queue) finish(job *types.Job, err error) {
+	now := time.Now()
+	job.UpdatedTime = now
+	job.RunAfter = now
+	job.Status = types.JobSucceeded
+	job.Message = ""
+
+	if err != nil {
+		job.Message = err.Error()
+		if len(job.Message) > maxMessageLen {
+			job.Message = job.Message[:maxMessageLen]
+		}
+
+		job.Status = types.JobFailed
+		if job.Attempts < job.MaxAttempts {
+			job.Status = types.JobPending
+			job.RunAfter = now.Add(backoff(job.Attempts))
+		}
+
+		log.Errorf("job %s %s attempt %d/%d failed: %s", job.ID, job.Kind, job.Attempts, job.MaxAttempts, err.Error())
+	} else {
+		log.Infof("job %s %s succeeded", job.ID, job.Kind)
+	}
+
+	if err := q.FinishJobAttempt(job); err != nil {
+		log.Errorf("FinishJobAttempt %s err:%s", job.ID, err.Error())
+	}
+}
+
+// backoff returns the delay before the retry after the attempts
+func backoff(attempts int) time.Duration {
+	delay := minBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+func (q *Queue) startRequeueTimer() {
+	ticker := time.NewTicker(requeueInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		q.requeue("", time.Now())
+	}
+}
+
+// requeue runs again the jobs without heartbeat and the jobs left running by the worker
+func (q *Queue) requeue(worker string, now time.Time) {
+	count, err := q.RequeueJobs(worker, now.Add(-staleTimeout), now)
+	if err != nil {
+		log.Errorf("RequeueJobs err:%s", err.Error())
+		return
+	}
+
+	if count > 0 {
+		log.Warnf("%d jobs requeued, their workers stopped", count)
+		q.notify()
+	}
+}
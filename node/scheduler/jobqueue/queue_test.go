@@ -0,0 +1,25 @@
+package jobqueue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, minBackoff},
+		{1, minBackoff},
+		{2, 2 * minBackoff},
+		{3, 4 * minBackoff},
+		{20, maxBackoff},
+	}
+
+	for _, c := range cases {
+		if d := backoff(c.attempts); d != c.expected {
+			t.Errorf("backoff(%d) expected %s, got %s", c.attempts, c.expected, d)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// GetJob retrieves the queued job with its progress
+func (s *Scheduler) GetJob(ctx context.Context, id string) (*types.Job, error) {
+	return s.JobQueue.LoadJob(id)
+}
+
+// ListJobs retrieves the queued jobs, the jobs of all kinds and statuses if kind and status are empty, the latest first
+func (s *Scheduler) ListJobs(ctx context.Context, kind string, status types.JobStatus, limit, offset int) (*types.ListJobRsp, error) {
+	return s.JobQueue.LoadJobs(kind, status, limit, offset)
+}
+
+// CancelJob cancels the pending or running queued job
+func (s *Scheduler) CancelJob(ctx context.Context, id string) error {
+	return s.JobQueue.Cancel(id)
+}
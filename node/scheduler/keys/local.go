@@ -0,0 +1,243 @@
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/node/types"
+	"github.com/docker/go-units"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// encryptedKeyType is the key type of the private keys encrypted with the passphrase
+	encryptedKeyType = "encrypted-private-key"
+	// retiredSuffix separates the key name and the retired time of the retired keys
+	retiredSuffix = "-retired-"
+
+	saltSize = 16
+)
+
+// LocalStore keeps the scheduler keys in the repo keystore,
+// the keys are encrypted with the passphrase if it is not empty
+type LocalStore struct {
+	keystore   types.KeyStore
+	name       string
+	passphrase string
+}
+
+// NewLocalStore creates the store, name is the keystore name of the current key
+func NewLocalStore(keystore types.KeyStore, name, passphrase string) *LocalStore {
+	return &LocalStore{keystore: keystore, name: name, passphrase: passphrase}
+}
+
+// Load returns the current key and the retired keys, newest first. The current key is generated if it does not exist
+func (s *LocalStore) Load() ([]Key, error) {
+	current, err := s.get(s.name)
+	if errors.Is(err, types.ErrKeyInfoNotFound) {
+		log.Warn("Generating new private key")
+
+		if current, err = s.generate(s.name); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, xerrors.Errorf("could not get private key: %w", err)
+	}
+
+	retired, err := s.retiredNames()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []Key{current}
+	for _, name := range retired {
+		key, err := s.get(name)
+		if err != nil {
+			return nil, xerrors.Errorf("could not get retired key %s: %w", name, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Rotate moves the current key to a retired name and generates a new current key
+func (s *LocalStore) Rotate(maxRetired int) error {
+	info, err := s.keystore.Get(s.name)
+	if err != nil {
+		return err
+	}
+
+	if err := s.keystore.Put(s.name+retiredSuffix+strconv.FormatInt(time.Now().UnixNano(), 10), info); err != nil {
+		return err
+	}
+
+	if err := s.keystore.Delete(s.name); err != nil {
+		return err
+	}
+
+	if _, err := s.generate(s.name); err != nil {
+		return err
+	}
+
+	retired, err := s.retiredNames()
+	if err != nil {
+		return err
+	}
+
+	for i := maxRetired; i < len(retired); i++ {
+		if err := s.keystore.Delete(retired[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retiredNames returns the names of the retired keys, newest first
+func (s *LocalStore) retiredNames() ([]string, error) {
+	names, err := s.keystore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := s.name + retiredSuffix
+	retired := make([]string, 0)
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			retired = append(retired, name)
+		}
+	}
+
+	// the suffixes are unix nanos of the same length
+	sort.Sort(sort.Reverse(sort.StringSlice(retired)))
+	return retired, nil
+}
+
+func (s *LocalStore) generate(name string) (*rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, units.KiB)
+	if err != nil {
+		return nil, xerrors.Errorf("GenerateKey: %w", err)
+	}
+
+	if err := s.put(name, key); err != nil {
+		return nil, xerrors.Errorf("writing private key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s *LocalStore) get(name string) (*rsa.PrivateKey, error) {
+	info, err := s.keystore.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Type != encryptedKeyType {
+		key, err := titanrsa.Pem2PrivateKey(info.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// encrypt the plain key once the passphrase is configured
+		if s.passphrase != "" {
+			if err := s.put(name, key); err != nil {
+				return nil, xerrors.Errorf("encrypt private key: %w", err)
+			}
+		}
+		return key, nil
+	}
+
+	if s.passphrase == "" {
+		return nil, xerrors.Errorf("private key %s is encrypted, passphrase is required", name)
+	}
+
+	pem, err := decryptWithPassphrase(s.passphrase, info.PrivateKey)
+	if err != nil {
+		return nil, xerrors.Errorf("decrypt private key %s: %w", name, err)
+	}
+
+	return titanrsa.Pem2PrivateKey(pem)
+}
+
+func (s *LocalStore) put(name string, key *rsa.PrivateKey) error {
+	info := types.KeyInfo{Type: types.KeyType(s.name), PrivateKey: titanrsa.PrivateKey2Pem(key)}
+
+	if s.passphrase != "" {
+		ciphertext, err := encryptWithPassphrase(s.passphrase, info.PrivateKey)
+		if err != nil {
+			return err
+		}
+		info = types.KeyInfo{Type: encryptedKeyType, PrivateKey: ciphertext}
+
+		// the keystore refuses to overwrite keys
+		if err := s.keystore.Delete(name); err != nil && !errors.Is(err, types.ErrKeyInfoNotFound) {
+			return err
+		}
+	}
+
+	return s.keystore.Put(name, info)
+}
+
+// encryptWithPassphrase encrypts data with aes-gcm, the key is derived from the passphrase by scrypt.
+// The output is salt | nonce | ciphertext
+func encryptWithPassphrase(passphrase string, data []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := passphraseCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append(salt, nonce...)
+	return gcm.Seal(out, nonce, data, nil), nil
+}
+
+func decryptWithPassphrase(passphrase string, data []byte) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, xerrors.New("invalid ciphertext")
+	}
+
+	gcm, err := passphraseCipher(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, xerrors.New("invalid ciphertext")
+	}
+
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func passphraseCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
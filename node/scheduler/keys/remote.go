@@ -0,0 +1,156 @@
+package keys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"golang.org/x/xerrors"
+)
+
+const remoteTimeout = 10 * time.Second
+
+// RemoteStore routes the rsa operations to a signer service in front of a kms or hsm,
+// the private keys never leave the service. The service exposes:
+//
+//	GET  /keys               {"keys":[{"id":"...","public_key":"<pem>"}]}, the current key first
+//	POST /keys/rotate        creates a new current key and retires the previous one
+//	POST /keys/{id}/sign     {"digest":"<base64>","hash":"SHA-256"} -> {"signature":"<base64>"}
+//	POST /keys/{id}/decrypt  {"ciphertext":"<base64>","hash":"SHA-256"} -> {"plaintext":"<base64>"}
+type RemoteStore struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type remoteKeyInfo struct {
+	ID        string `json:"id"`
+	PublicKey string `json:"public_key"`
+}
+
+type remoteOpReq struct {
+	Digest     []byte `json:"digest,omitempty"`
+	Ciphertext []byte `json:"ciphertext,omitempty"`
+	Hash       string `json:"hash"`
+	MaxRetired int    `json:"max_retired,omitempty"`
+}
+
+type remoteOpRsp struct {
+	Keys      []*remoteKeyInfo `json:"keys,omitempty"`
+	Signature []byte           `json:"signature,omitempty"`
+	Plaintext []byte           `json:"plaintext,omitempty"`
+}
+
+// NewRemoteStore creates the store of the signer service, token is sent as bearer token
+func NewRemoteStore(serviceURL, token string) *RemoteStore {
+	return &RemoteStore{url: serviceURL, token: token, client: &http.Client{Timeout: remoteTimeout}}
+}
+
+// Load returns the keys of the signer service
+func (s *RemoteStore) Load() ([]Key, error) {
+	rsp := &remoteOpRsp{}
+	if err := s.do(http.MethodGet, "/keys", nil, rsp); err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(rsp.Keys))
+	for _, info := range rsp.Keys {
+		pub, err := titanrsa.Pem2PublicKey([]byte(info.PublicKey))
+		if err != nil {
+			return nil, xerrors.Errorf("public key of %s: %w", info.ID, err)
+		}
+
+		keys = append(keys, &remoteKey{store: s, id: info.ID, pub: pub})
+	}
+
+	return keys, nil
+}
+
+// Rotate asks the signer service to rotate the key
+func (s *RemoteStore) Rotate(maxRetired int) error {
+	return s.do(http.MethodPost, "/keys/rotate", &remoteOpReq{MaxRetired: maxRetired}, nil)
+}
+
+func (s *RemoteStore) do(method, path string, req interface{}, rsp interface{}) error {
+	var body io.Reader
+	if req != nil {
+		buf, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	httpReq, err := http.NewRequest(method, s.url+path, body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	httpRsp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpRsp.Body.Close() //nolint:errcheck
+
+	if httpRsp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpRsp.Body, 1024))
+		return xerrors.Errorf("signer service %s %s status %d: %s", method, path, httpRsp.StatusCode, string(msg))
+	}
+
+	if rsp == nil {
+		return nil
+	}
+
+	return json.NewDecoder(httpRsp.Body).Decode(rsp)
+}
+
+// remoteKey is a key held by the signer service
+type remoteKey struct {
+	store *RemoteStore
+	id    string
+	pub   *rsa.PublicKey
+}
+
+// Public returns the public key
+func (k *remoteKey) Public() crypto.PublicKey {
+	return k.pub
+}
+
+// Sign signs the digest with PKCS #1 v1.5 by the signer service
+func (k *remoteKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return nil, xerrors.New("pss signature is not supported")
+	}
+
+	rsp := &remoteOpRsp{}
+	if err := k.store.do(http.MethodPost, "/keys/"+url.PathEscape(k.id)+"/sign", &remoteOpReq{Digest: digest, Hash: opts.HashFunc().String()}, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp.Signature, nil
+}
+
+// Decrypt decrypts the OAEP ciphertext by the signer service
+func (k *remoteKey) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	oaep, ok := opts.(*rsa.OAEPOptions)
+	if !ok {
+		return nil, xerrors.New("only oaep decryption is supported")
+	}
+
+	rsp := &remoteOpRsp{}
+	if err := k.store.do(http.MethodPost, "/keys/"+url.PathEscape(k.id)+"/decrypt", &remoteOpReq{Ciphertext: ciphertext, Hash: oaep.Hash.String()}, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp.Plaintext, nil
+}
@@ -0,0 +1,153 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("keys")
+
+// Key is a scheduler rsa private key. The private part may never leave its holder,
+// e.g. *rsa.PrivateKey, an encrypted keystore, an external kms or hsm.
+type Key interface {
+	crypto.Signer
+	crypto.Decrypter
+}
+
+// Store is the storage backend of the scheduler keys
+type Store interface {
+	// Load returns the current key first, followed by the keys retired by rotation
+	Load() ([]Key, error)
+	// Rotate creates a new current key and retires the previous one,
+	// at most maxRetired retired keys are kept
+	Rotate(maxRetired int) error
+}
+
+// Ring routes the rsa operations of the scheduler to its current key.
+// The retired keys are kept to decrypt the data encrypted with their public keys,
+// so nodes which still cache an old public key keep working after a rotation.
+type Ring struct {
+	store      Store
+	maxRetired int
+
+	lk   sync.RWMutex
+	keys []Key
+}
+
+// NewRing loads the keys from the store
+func NewRing(store Store, maxRetired int) (*Ring, error) {
+	r := &Ring{store: store, maxRetired: maxRetired}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Ring) load() error {
+	keys, err := r.store.Load()
+	if err != nil {
+		return xerrors.Errorf("load keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return xerrors.New("no scheduler key")
+	}
+
+	for _, key := range keys {
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return xerrors.Errorf("unsupported public key type %T", key.Public())
+		}
+	}
+
+	r.lk.Lock()
+	r.keys = keys
+	r.lk.Unlock()
+
+	return nil
+}
+
+// Current returns the key which signs and whose public key is handed out
+func (r *Ring) Current() Key {
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+
+	return r.keys[0]
+}
+
+// Keys returns the current key followed by the retired keys
+func (r *Ring) Keys() []Key {
+	r.lk.RLock()
+	defer r.lk.RUnlock()
+
+	return append([]Key(nil), r.keys...)
+}
+
+// PublicKey returns the public key of the current key
+func (r *Ring) PublicKey() *rsa.PublicKey {
+	return r.Current().Public().(*rsa.PublicKey)
+}
+
+// Sign signs the sha256 sum of the content with PKCS #1 v1.5, the same as titanrsa.Sign
+func (r *Ring) Sign(content []byte) ([]byte, error) {
+	sum := crypto.SHA256.New()
+	sum.Write(content)
+
+	return r.Current().Sign(rand.Reader, sum.Sum(nil), crypto.SHA256)
+}
+
+// Decrypt decrypts the ciphertext of titanrsa.Encrypt, the current key is tried first, then the retired keys
+func (r *Ring) Decrypt(ciphertext []byte) ([]byte, error) {
+	var err error
+	for _, key := range r.Keys() {
+		var data []byte
+		if data, err = decrypt(key, ciphertext); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, err
+}
+
+// Rotate replaces the current key with a new one, the nodes do not need to register again
+// because they fetch the public key of the scheduler and their own keys are not changed
+func (r *Ring) Rotate() error {
+	if err := r.store.Rotate(r.maxRetired); err != nil {
+		return xerrors.Errorf("rotate key: %w", err)
+	}
+
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	log.Info("scheduler key rotated")
+	return nil
+}
+
+// decrypt decrypts the ciphertext block by block with OAEP, the block size is the size of the key
+func decrypt(key Key, ciphertext []byte) ([]byte, error) {
+	step := key.Public().(*rsa.PublicKey).Size()
+	opts := &rsa.OAEPOptions{Hash: crypto.SHA256}
+
+	var data []byte
+	for start := 0; start < len(ciphertext); start += step {
+		finish := start + step
+		if finish > len(ciphertext) {
+			finish = len(ciphertext)
+		}
+
+		block, err := key.Decrypt(rand.Reader, ciphertext[start:finish], opts)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, block...)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,105 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"testing"
+
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/node/types"
+	"golang.org/x/xerrors"
+)
+
+type memKeyStore map[string]types.KeyInfo
+
+func (m memKeyStore) List() ([]string, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m memKeyStore) Get(name string) (types.KeyInfo, error) {
+	info, ok := m[name]
+	if !ok {
+		return types.KeyInfo{}, xerrors.Errorf("opening key '%s': %w", name, types.ErrKeyInfoNotFound)
+	}
+	return info, nil
+}
+
+func (m memKeyStore) Put(name string, info types.KeyInfo) error {
+	if _, ok := m[name]; ok {
+		return types.ErrKeyExists
+	}
+	m[name] = info
+	return nil
+}
+
+func (m memKeyStore) Delete(name string) error {
+	if _, ok := m[name]; !ok {
+		return types.ErrKeyInfoNotFound
+	}
+	delete(m, name)
+	return nil
+}
+
+func TestRingRotate(t *testing.T) {
+	keystore := memKeyStore{}
+	ring, err := NewRing(NewLocalStore(keystore, "private-key", "passphrase"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keystore["private-key"].Type != encryptedKeyType {
+		t.Fatalf("expected encrypted key, got %s", keystore["private-key"].Type)
+	}
+
+	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	oldPublicKey := ring.PublicKey()
+	ciphertext, err := titanRsa.Encrypt([]byte("workload report"), oldPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := ring.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(ring.Keys()) != 2 || len(keystore) != 2 {
+		t.Fatalf("expected current key and one retired key, got %d keys", len(ring.Keys()))
+	}
+
+	sign, err := ring.Sign([]byte("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := titanRsa.VerifySign(ring.PublicKey(), sign, []byte("token")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the key of the ciphertext was dropped by the second rotation
+	if _, err := ring.Decrypt(ciphertext); err == nil {
+		t.Fatal("expected decrypt error")
+	}
+
+	ciphertext, err = titanRsa.Encrypt([]byte("workload report"), ring.Keys()[1].Public().(*rsa.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ring.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "workload report" {
+		t.Fatalf("unexpected data %s", data)
+	}
+
+	// reload with the wrong passphrase
+	if _, err := NewRing(NewLocalStore(keystore, "private-key", "wrong"), 1); err == nil {
+		t.Fatal("expected error for wrong passphrase")
+	}
+}
@@ -0,0 +1,247 @@
+package leaderboard
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/overload"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("leaderboard")
+
+const (
+	refreshInterval = 10 * time.Minute
+	// the snapshots are kept one day longer than the longest period
+	snapshotRetentionDays = 31
+	// the number of nodes kept in each cached board
+	boardSize = 10000
+	maxLimit  = 500
+)
+
+var (
+	metrics = []types.LeaderboardMetric{types.LeaderboardPoints, types.LeaderboardTraffic, types.LeaderboardUptime}
+	periods = []types.LeaderboardPeriod{types.LeaderboardDay, types.LeaderboardWeek, types.LeaderboardMonth}
+)
+
+// Manager ranks the nodes by the points, the traffic served and the uptime in the periods.
+// The points and the uptime in a period are the differences from the daily snapshots taken by the master scheduler,
+// the boards are refreshed periodically and the queries are served from the cache, the refresh is skipped while the scheduler is overloaded.
+type Manager struct {
+	leadershipMgr *leadership.Manager
+	overloadMgr   *overload.Manager
+	*db.SQLDB
+
+	lk          sync.RWMutex
+	boards      map[boardKey][]*types.LeaderboardEntry
+	updatedTime time.Time
+}
+
+type boardKey struct {
+	metric types.LeaderboardMetric
+	period types.LeaderboardPeriod
+}
+
+// NewManager return new leaderboard manager instance
+func NewManager(sdb *db.SQLDB, lmgr *leadership.Manager, omgr *overload.Manager) *Manager {
+	m := &Manager{
+		leadershipMgr: lmgr,
+		overloadMgr:   omgr,
+		SQLDB:         sdb,
+		boards:        make(map[boardKey][]*types.LeaderboardEntry),
+	}
+
+	go m.startRefreshTimer()
+
+	return m
+}
+
+func (m *Manager) startRefreshTimer() {
+	t := diagnostics.NewTimer("leaderboard.refresh", refreshInterval)
+
+	done := t.Start()
+	m.refresh(time.Now())
+	done()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		done = t.Start()
+		m.refresh(time.Now())
+		done()
+	}
+}
+
+// refresh takes the snapshot of the day if it is missing and recomputes the boards
+func (m *Manager) refresh(now time.Time) {
+	if m.overloadMgr.Shedding() {
+		log.Debug("scheduler overloaded, skip refreshing leaderboards")
+		return
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if m.leadershipMgr.RequestAndBecomeMaster() {
+		if err := m.snapshot(today); err != nil {
+			log.Errorf("snapshot node stats err:%s", err.Error())
+		}
+	}
+
+	boards, err := m.compute(today)
+	if err != nil {
+		log.Errorf("compute leaderboards err:%s", err.Error())
+		return
+	}
+
+	m.lk.Lock()
+	m.boards = boards
+	m.updatedTime = now
+	m.lk.Unlock()
+}
+
+func (m *Manager) snapshot(today time.Time) error {
+	exist, err := m.IsNodeStatsSnapshotted(today)
+	if err != nil {
+		return err
+	}
+
+	if exist {
+		return nil
+	}
+
+	return m.SnapshotNodeStats(today, today.AddDate(0, 0, -snapshotRetentionDays))
+}
+
+// compute computes the boards of all the metrics and periods
+func (m *Manager) compute(today time.Time) (map[boardKey][]*types.LeaderboardEntry, error) {
+	current, err := m.LoadNodeStats()
+	if err != nil {
+		return nil, xerrors.Errorf("LoadNodeStats: %w", err)
+	}
+
+	boards := make(map[boardKey][]*types.LeaderboardEntry, len(metrics)*len(periods))
+	for _, period := range periods {
+		start := today.AddDate(0, 0, -period.Days())
+
+		snapshots, err := m.LoadNodeStatsDaily(start)
+		if err != nil {
+			return nil, xerrors.Errorf("LoadNodeStatsDaily: %w", err)
+		}
+
+		traffic, err := m.SumNodeUploadTraffic(start)
+		if err != nil {
+			return nil, xerrors.Errorf("SumNodeUploadTraffic: %w", err)
+		}
+
+		boards[boardKey{types.LeaderboardPoints, period}] = rank(current, func(s *types.NodeStatsDaily) float64 {
+			if prev, ok := snapshots[s.NodeID]; ok {
+				return s.Points - prev.Points
+			}
+			return s.Points
+		})
+		boards[boardKey{types.LeaderboardUptime, period}] = rank(current, func(s *types.NodeStatsDaily) float64 {
+			if prev, ok := snapshots[s.NodeID]; ok {
+				return float64(s.OnlineDuration - prev.OnlineDuration)
+			}
+			return float64(s.OnlineDuration)
+		})
+		boards[boardKey{types.LeaderboardTraffic, period}] = rank(current, func(s *types.NodeStatsDaily) float64 {
+			return float64(traffic[s.NodeID])
+		})
+	}
+
+	return boards, nil
+}
+
+// rank returns the nodes with a positive value in descending order of the value, ties are ordered by node id
+func rank(stats []*types.NodeStatsDaily, value func(*types.NodeStatsDaily) float64) []*types.LeaderboardEntry {
+	board := make([]*types.LeaderboardEntry, 0, len(stats))
+	for _, s := range stats {
+		v := math.Round(value(s)*1e6) / 1e6
+		if v <= 0 {
+			continue
+		}
+
+		board = append(board, &types.LeaderboardEntry{NodeID: s.NodeID, NodeType: s.NodeType, Value: v})
+	}
+
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Value != board[j].Value {
+			return board[i].Value > board[j].Value
+		}
+		return board[i].NodeID < board[j].NodeID
+	})
+
+	if len(board) > boardSize {
+		board = board[:boardSize]
+	}
+
+	return board
+}
+
+// Leaderboard returns a page of the cached board filtered by the node type, the nodes are ranked in the filtered board.
+// The board only has the nodes of the scheduler area, so the other regions are empty.
+func (m *Manager) Leaderboard(req *types.LeaderboardReq, areaID string) (*types.LeaderboardRsp, error) {
+	if req.Period.Days() == 0 {
+		return nil, xerrors.Errorf("unknown leaderboard period %s", req.Period)
+	}
+
+	switch req.Metric {
+	case types.LeaderboardPoints, types.LeaderboardTraffic, types.LeaderboardUptime:
+	default:
+		return nil, xerrors.Errorf("unknown leaderboard metric %s", req.Metric)
+	}
+
+	m.lk.RLock()
+	board := m.boards[boardKey{req.Metric, req.Period}]
+	rsp := &types.LeaderboardRsp{Data: make([]*types.LeaderboardEntry, 0), UpdatedTime: m.updatedTime}
+	m.lk.RUnlock()
+
+	if req.Region != "" && req.Region != areaID {
+		return rsp, nil
+	}
+
+	filtered := filter(board, req.NodeType)
+	rsp.Total = int64(len(filtered))
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	if req.Offset < 0 || req.Offset >= len(filtered) {
+		return rsp, nil
+	}
+
+	end := req.Offset + limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	rsp.Data = filtered[req.Offset:end]
+
+	return rsp, nil
+}
+
+// filter returns the entries of the node type ranked in the filtered board, the cached entries are not modified
+func filter(board []*types.LeaderboardEntry, nodeType types.NodeType) []*types.LeaderboardEntry {
+	out := make([]*types.LeaderboardEntry, 0, len(board))
+	for _, e := range board {
+		if nodeType != types.NodeUnknown && e.NodeType != nodeType {
+			continue
+		}
+
+		entry := *e
+		entry.Rank = len(out) + 1
+		out = append(out, &entry)
+	}
+
+	return out
+}
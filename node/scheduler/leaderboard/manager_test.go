@@ -0,0 +1,37 @@
+package leaderboard
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestRankAndFilter(t *testing.T) {
+	stats := []*types.NodeStatsDaily{
+		{NodeID: "e_1", Points: 10, NodeType: types.NodeEdge},
+		{NodeID: "c_1", Points: 30, NodeType: types.NodeCandidate},
+		{NodeID: "e_2", Points: 30, NodeType: types.NodeEdge},
+		{NodeID: "e_3", Points: 0, NodeType: types.NodeEdge},
+	}
+
+	board := rank(stats, func(s *types.NodeStatsDaily) float64 { return s.Points })
+	if len(board) != 3 {
+		t.Fatalf("expected 3 ranked nodes, got %d", len(board))
+	}
+
+	want := []string{"c_1", "e_2", "e_1"}
+	for i, e := range board {
+		if e.NodeID != want[i] {
+			t.Errorf("position %d: expected %s, got %s", i, want[i], e.NodeID)
+		}
+	}
+
+	edges := filter(board, types.NodeEdge)
+	if len(edges) != 2 || edges[0].NodeID != "e_2" || edges[0].Rank != 1 || edges[1].Rank != 2 {
+		t.Errorf("unexpected filtered board %+v", edges)
+	}
+
+	if board[1].Rank != 0 {
+		t.Errorf("cached entry modified by filter")
+	}
+}
@@ -0,0 +1,21 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// GetLeaderboard get a page of the nodes ranked by points, traffic served or uptime in the last 24h, 7d or 30d
+func (s *Scheduler) GetLeaderboard(ctx context.Context, req *types.LeaderboardReq) (*types.LeaderboardRsp, error) {
+	if req == nil {
+		return nil, xerrors.New("request can not empty")
+	}
+
+	if err := s.OverloadManager.Admit(ctx, "GetLeaderboard"); err != nil {
+		return nil, err
+	}
+
+	return s.LeaderboardManager.Leaderboard(req, s.SchedulerCfg.AreaID)
+}
@@ -0,0 +1,112 @@
+package locindex
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	lru "github.com/hashicorp/golang-lru"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+var log = logging.Logger("locindex")
+
+const (
+	defaultCacheSize = 100000
+	defaultCacheTTL  = time.Minute
+)
+
+// Index serves the locations of the assets, their succeeded replicas, to the retrieval routing.
+// The locations are read from the shared database with a connection pool of the index, usually on a read replica,
+// and cached, so the lookups of the gateways do not contend with the control plane for the scheduler database.
+// The cache of an index is dropped by the replica changes of its scheduler and expires by the ttl,
+// the indexes of the other schedulers reading the same database catch up within the ttl
+type Index struct {
+	reader *db.SQLDB
+	cache  *lru.Cache
+	ttl    time.Duration
+	group  singleflight.Group
+
+	hits   int64
+	misses int64
+	loads  int64
+}
+
+type entry struct {
+	replicas   []*types.ReplicaInfo
+	expiration time.Time
+}
+
+// NewIndex return new location index instance reading the locations by the reader
+func NewIndex(reader *db.SQLDB, cacheSize int, ttl time.Duration) (*Index, error) {
+	if cacheSize <= 0 {
+		cacheSize = defaultCacheSize
+	}
+
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Index{reader: reader, cache: cache, ttl: ttl}, nil
+}
+
+// Locations returns the succeeded replicas of the asset, the assets without replica are cached as well
+// as the gateways look up the unknown cids again and again. The returned slice may be reordered by the caller,
+// the replicas must not be modified
+func (idx *Index) Locations(hash string) ([]*types.ReplicaInfo, error) {
+	if v, ok := idx.cache.Get(hash); ok {
+		e := v.(*entry)
+		if time.Now().Before(e.expiration) {
+			atomic.AddInt64(&idx.hits, 1)
+			return append([]*types.ReplicaInfo(nil), e.replicas...), nil
+		}
+	}
+	atomic.AddInt64(&idx.misses, 1)
+
+	// the concurrent lookups of the same asset share a load
+	v, err, _ := idx.group.Do(hash, func() (interface{}, error) {
+		atomic.AddInt64(&idx.loads, 1)
+
+		replicas, err := idx.reader.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+		if err != nil {
+			return nil, err
+		}
+
+		idx.cache.Add(hash, &entry{replicas: replicas, expiration: time.Now().Add(idx.ttl)})
+		return replicas, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*types.ReplicaInfo(nil), v.([]*types.ReplicaInfo)...), nil
+}
+
+// Invalidate drops the cached locations of the asset, the next lookup reads them from the database
+func (idx *Index) Invalidate(hash string) {
+	idx.cache.Remove(hash)
+	log.Debugf("invalidate locations of %s", hash)
+}
+
+// Stats returns the lookups served by the index since the start
+func (idx *Index) Stats() *types.LocationIndexStats {
+	stats := &types.LocationIndexStats{
+		Entries: idx.cache.Len(),
+		Hits:    atomic.LoadInt64(&idx.hits),
+		Misses:  atomic.LoadInt64(&idx.misses),
+		Loads:   atomic.LoadInt64(&idx.loads),
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+
+	return stats
+}
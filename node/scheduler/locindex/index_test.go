@@ -0,0 +1,79 @@
+package locindex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/harness"
+)
+
+func TestLocations(t *testing.T) {
+	sdb, closeDB, err := harness.NewDB("test-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+
+	idx, err := NewIndex(sdb, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := "1220aa"
+	saveReplica := func(nodeID string) {
+		if err := sdb.SaveReplicasStatus([]*types.ReplicaInfo{{Hash: hash, NodeID: nodeID, Status: types.ReplicaStatusSucceeded}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saveReplica("e_1")
+
+	replicas, err := idx.Locations(hash)
+	if err != nil || len(replicas) != 1 {
+		t.Fatalf("expected 1 replica, got %d, %v", len(replicas), err)
+	}
+
+	// the cached locations are served until invalidated
+	saveReplica("e_2")
+	if replicas, _ = idx.Locations(hash); len(replicas) != 1 {
+		t.Fatalf("expected the cached replica, got %d", len(replicas))
+	}
+
+	idx.Invalidate(hash)
+	if replicas, _ = idx.Locations(hash); len(replicas) != 2 {
+		t.Fatalf("expected 2 replicas after invalidate, got %d", len(replicas))
+	}
+
+	stats := idx.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Loads != 2 || stats.Entries != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLocationsExpire(t *testing.T) {
+	sdb, closeDB, err := harness.NewDB("test-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+
+	idx, err := NewIndex(sdb, 10, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the assets without replica are cached as well
+	if replicas, err := idx.Locations("1220bb"); err != nil || len(replicas) != 0 {
+		t.Fatalf("expected no replica, got %d, %v", len(replicas), err)
+	}
+
+	if err := sdb.SaveReplicasStatus([]*types.ReplicaInfo{{Hash: "1220bb", NodeID: "e_1", Status: types.ReplicaStatusSucceeded}}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if replicas, _ := idx.Locations("1220bb"); len(replicas) != 1 {
+		t.Fatalf("expected the expired locations reloaded, got %d", len(replicas))
+	}
+}
@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// AddMaintenanceWindow declares a maintenance window of the node and returns its id, the node is not selected or validated
+// and its downtime is not penalized or counted against its score in the window
+func (s *Scheduler) AddMaintenanceWindow(ctx context.Context, window *types.MaintenanceWindow) (int64, error) {
+	if window == nil {
+		return 0, xerrors.New("window can not empty")
+	}
+
+	return s.NodeManager.AddMaintenanceWindow(window)
+}
+
+// EndMaintenanceWindow ends the maintenance window now, a window not started yet is canceled
+func (s *Scheduler) EndMaintenanceWindow(ctx context.Context, id int64) error {
+	return s.NodeManager.EndMaintenanceWindow(id)
+}
+
+// GetNodeMaintenanceWindows retrieves the maintenance windows of the node, the latest first
+func (s *Scheduler) GetNodeMaintenanceWindows(ctx context.Context, nodeID string, limit, offset int) (*types.ListMaintenanceWindowRsp, error) {
+	return s.NodeManager.LoadNodeMaintenanceWindows(nodeID, limit, offset)
+}
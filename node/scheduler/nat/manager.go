@@ -133,7 +133,8 @@ func (m *Manager) retryDetectNatType(node *retryNode) {
 		return
 	}
 
-	cNodes := m.nodeManager.GetCandidateNodes(miniCandidateCount, false)
+	// the candidates detect the nat of the ipv4 edges, the ipv6 edges are checked by the scheduler directly
+	cNodes := m.nodeManager.GetCandidateNodesOfFamily(miniCandidateCount, types.IPFamilyV4)
 	natType, err := determineEdgeNATType(context.Background(), eNode, cNodes)
 	if err != nil {
 		log.Errorf("DetermineNATType error:%s", err.Error())
@@ -166,7 +167,8 @@ func (m *Manager) DetermineEdgeNATType(ctx context.Context, nodeID string) {
 		return
 	}
 
-	cNodes := m.nodeManager.GetCandidateNodes(miniCandidateCount, false)
+	// the candidates detect the nat of the ipv4 edges, the ipv6 edges are checked by the scheduler directly
+	cNodes := m.nodeManager.GetCandidateNodesOfFamily(miniCandidateCount, types.IPFamilyV4)
 
 	natType, err := determineEdgeNATType(ctx, eNode, cNodes)
 	if err != nil {
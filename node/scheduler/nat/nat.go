@@ -3,6 +3,7 @@ package nat
 import (
 	"context"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/client"
@@ -30,8 +31,41 @@ func detectRestrictedNAT(ctx context.Context, edgeURL string) (bool, error) {
 	return true, nil
 }
 
+// determines the NAT type of an edge node connected over ipv6. Most ipv6 edges own a global address without a nat,
+// the scheduler dials the edge directly instead of asking the candidates, which may not be reachable over ipv6
+func analyzeIPv6EdgeNATType(ctx context.Context, edgeNode *node.Node) (types.NatType, error) {
+	edgeURL := fmt.Sprintf("https://%s/rpc/v0", edgeNode.RemoteAddr)
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", edgeNode.RemoteAddr)
+	if err == nil {
+		conn.Close()
+	} else {
+		log.Debugf("check edge %s tcp connectivity failed: %s", edgeNode.RemoteAddr, err.Error())
+	}
+
+	udpReachable, udpErr := detectRestrictedNAT(ctx, edgeURL)
+	if udpErr != nil {
+		return types.NatTypeUnknown, udpErr
+	}
+
+	switch {
+	case err == nil && udpReachable:
+		return types.NatTypeNo, nil
+	case udpReachable:
+		return types.NatTypeFullCone, nil
+	default:
+		// a firewall drops the connections not initiated by the edge
+		return types.NatTypePortRestricted, nil
+	}
+}
+
 // determines the NAT type of an edge node
 func analyzeEdgeNodeNATType(ctx context.Context, edgeNode *node.Node, candidateNodes []*node.Node) (types.NatType, error) {
+	if edgeNode.IPFamily() == types.IPFamilyV6 {
+		return analyzeIPv6EdgeNATType(ctx, edgeNode)
+	}
+
 	if len(candidateNodes) < miniCandidateCount {
 		return types.NatTypeUnknown, fmt.Errorf("a minimum of %d candidates is required for nat detect", miniCandidateCount)
 	}
@@ -0,0 +1,112 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// archiveBatchSize the nodes loaded at once by the archive job
+	archiveBatchSize = 500
+	// purgeOfflineTime a node not archived is purged only after offline for the duration
+	purgeOfflineTime = 10 * time.Minute
+)
+
+// runArchive archives the nodes offline longer than the archive days, their replicas are deleted first
+// so the assets are replenished on the other nodes
+func (m *Manager) runArchive(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	cfg, err := m.config()
+	if err != nil {
+		return xerrors.Errorf("get config err:%s", err.Error())
+	}
+
+	if cfg.NodeArchiveDays <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	lastSeenBefore := now.Add(-time.Duration(cfg.NodeArchiveDays) * oneDay)
+
+	var done int64
+	for {
+		nodeIDs, err := m.LoadNodesToArchive(lastSeenBefore, archiveBatchSize)
+		if err != nil {
+			return xerrors.Errorf("LoadNodesToArchive err:%s", err.Error())
+		}
+
+		archived := 0
+		for _, nodeID := range nodeIDs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			ok, err := m.archiveNode(nodeID, lastSeenBefore, now)
+			if err != nil {
+				log.Errorf("archive node %s err:%s", nodeID, err.Error())
+				continue
+			}
+
+			if ok {
+				archived++
+				done++
+				progress(done, done)
+			}
+		}
+
+		// the nodes left failed to archive, they are retried by the next job
+		if archived == 0 {
+			break
+		}
+	}
+
+	if done > 0 {
+		log.Infof("%d nodes offline longer than %d days archived", done, cfg.NodeArchiveDays)
+	}
+
+	return nil
+}
+
+// archiveNode archives the node last seen before the time, returns false if the node is online or seen since then
+func (m *Manager) archiveNode(nodeID string, lastSeenBefore, now time.Time) (bool, error) {
+	if m.GetNode(nodeID) != nil {
+		return false, nil
+	}
+
+	if err := m.DeleteAssetRecordsOfNode(nodeID); err != nil {
+		return false, xerrors.Errorf("DeleteAssetRecordsOfNode err:%s", err.Error())
+	}
+
+	return m.ArchiveNode(nodeID, lastSeenBefore, now)
+}
+
+// PurgeNode deletes the node with its records, the node is archived first if it is offline.
+// The points and the settlements of the node are kept in the ledgers
+func (m *Manager) PurgeNode(nodeID string) error {
+	archived, err := m.IsNodeArchived(nodeID)
+	if err != nil {
+		return err
+	}
+
+	if !archived {
+		now := time.Now()
+		ok, err := m.archiveNode(nodeID, now.Add(-purgeOfflineTime), now)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return xerrors.Errorf("node %s not found or seen in the last %s", nodeID, purgeOfflineTime)
+		}
+	}
+
+	if err := m.PurgeArchivedNode(nodeID); err != nil {
+		return err
+	}
+
+	log.Infof("node %s purged", nodeID)
+	return nil
+}
@@ -0,0 +1,190 @@
+package node
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/docker/go-units"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	hardwareChallengeInterval = 6 * time.Hour
+	hardwareChallengeTimeout  = 2 * time.Minute
+	// Maximum number of nodes challenged in each round
+	hardwareChallengeNodeLimit = 100
+
+	challengeDiskSize     = 64 * units.MiB
+	challengeMemorySize   = 128 * units.MiB
+	challengeMemoryRounds = 4
+)
+
+var errNoSpotChecker = xerrors.New("no candidate to spot check the challenge result")
+
+// startHardwareChallengeTimer periodically challenges random online nodes to verify their hardware claims
+func (m *Manager) startHardwareChallengeTimer() {
+	ticker := time.NewTicker(hardwareChallengeInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		m.challengeNodes()
+	}
+}
+
+// challengeNodes challenges random online nodes
+func (m *Manager) challengeNodes() {
+	_, nodes := m.GetAllValidCandidateNodes()
+	nodes = append(nodes, m.GetAllEdgeNode()...)
+
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+
+	if len(nodes) > hardwareChallengeNodeLimit {
+		nodes = nodes[:hardwareChallengeNodeLimit]
+	}
+
+	for _, node := range nodes {
+		if err := m.challengeNode(node); err != nil {
+			log.Warnf("challenge node %s err:%s", node.NodeID, err.Error())
+		}
+	}
+}
+
+// challengeNode runs a hardware challenge on the node and spot checks the result with a candidate,
+// a proof is saved only if the result is verified, a node that can not run the challenge proves nothing
+func (m *Manager) challengeNode(node *Node) error {
+	challenge := &types.HardwareChallenge{
+		ID:           uuid.NewString(),
+		Seed:         time.Now().UnixNano(),
+		DiskSize:     challengeDiskSize,
+		MemorySize:   challengeMemorySize,
+		MemoryRounds: challengeMemoryRounds,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hardwareChallengeTimeout)
+	defer cancel()
+
+	result, err := node.RunHardwareChallenge(ctx, challenge)
+	if err != nil {
+		// keep the last proof of the node, e.g. the node predates the challenge or is unreachable
+		return xerrors.Errorf("RunHardwareChallenge: %w", err)
+	}
+
+	reason, mismatched, err := m.verifyChallengeResult(ctx, node, challenge, result)
+	if err != nil {
+		// keep the last proof of the node
+		return err
+	}
+
+	proof := &types.HardwareProof{
+		NodeID:          node.NodeID,
+		Passed:          reason == "",
+		Mismatched:      mismatched,
+		DiskSize:        challenge.DiskSize,
+		DiskDuration:    result.DiskDuration,
+		MemorySize:      challenge.MemorySize,
+		MemoryBandwidth: result.MemoryBandwidth,
+		ProofTime:       time.Now(),
+	}
+
+	if !proof.Passed {
+		log.Infof("node %s failed the hardware challenge: %s", node.NodeID, reason)
+	}
+	node.hardwareFailed = !proof.Passed
+
+	if err = m.SaveHardwareProof(proof); err != nil {
+		return err
+	}
+
+	m.notify.Pub(proof, types.EventHardwareProof.String())
+	return nil
+}
+
+// verifyChallengeResult checks the signature of the result and asks a random candidate to calculate the expected
+// digests of the challenge, returns why the result fails the challenge, empty if it passes, and whether its digests
+// mismatch. An error is returned if the result can not be verified
+func (m *Manager) verifyChallengeResult(ctx context.Context, node *Node, challenge *types.HardwareChallenge, result *types.HardwareChallengeResult) (string, bool, error) {
+	if result.ChallengeID != challenge.ID {
+		return "", false, xerrors.Errorf("challenge id mismatch %s, %s", result.ChallengeID, challenge.ID)
+	}
+
+	if err := nodekey.Verify(node.PublicKey, result.Sign, result.SignData()); err != nil {
+		return "", false, xerrors.Errorf("verify sign: %w", err)
+	}
+
+	checker := m.getSpotChecker(node.NodeID)
+	if checker == nil {
+		return "", false, errNoSpotChecker
+	}
+
+	digest := *challenge
+	digest.DigestOnly = true
+
+	expected, err := checker.RunHardwareChallenge(ctx, &digest)
+	if err != nil {
+		log.Warnf("candidate %s spot check err:%s", checker.NodeID, err.Error())
+		return "", false, errNoSpotChecker
+	}
+
+	reason, mismatched := challengeMismatch(result, expected, checker.NodeID)
+	return reason, mismatched, nil
+}
+
+// challengeMismatch compares the result of the node with the digests expected by the checker, returns why the result
+// fails and whether the digests mismatch. The disk probe and the memory benchmark take time, a result measuring none
+// only calculated the digests
+func challengeMismatch(result, expected *types.HardwareChallengeResult, checkerID string) (string, bool) {
+	if expected.DiskHash != result.DiskHash {
+		return fmt.Sprintf("disk hash mismatch, checked by %s", checkerID), true
+	}
+
+	if expected.MemoryHash != result.MemoryHash {
+		return fmt.Sprintf("memory hash mismatch, checked by %s", checkerID), true
+	}
+
+	if result.DiskDuration <= 0 || result.MemoryBandwidth <= 0 {
+		return fmt.Sprintf("probes not run, disk duration %d ms, memory bandwidth %.0f", result.DiskDuration, result.MemoryBandwidth), false
+	}
+
+	return "", false
+}
+
+// getSpotChecker returns a random candidate other than the challenged node
+func (m *Manager) getSpotChecker(nodeID string) *Node {
+	_, candidates := m.GetAllValidCandidateNodes()
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	for _, candidate := range candidates {
+		if candidate.NodeID != nodeID {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// LoadHardwareVerdict loads whether the latest hardware challenge of the connecting node failed,
+// a node never challenged is taken as passed
+func (m *Manager) LoadHardwareVerdict(node *Node) {
+	proof, err := m.LoadHardwareProof(node.NodeID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Warnf("LoadHardwareProof %s err:%s", node.NodeID, err.Error())
+		}
+		return
+	}
+
+	node.hardwareFailed = !proof.Passed
+}
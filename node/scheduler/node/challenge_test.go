@@ -0,0 +1,39 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestChallengeMismatch(t *testing.T) {
+	expected := &types.HardwareChallengeResult{DiskHash: "disk", MemoryHash: "memory"}
+
+	result := &types.HardwareChallengeResult{DiskHash: "disk", MemoryHash: "memory", DiskDuration: 300, MemoryBandwidth: 1e9}
+	if reason, _ := challengeMismatch(result, expected, "c_1"); reason != "" {
+		t.Fatalf("matching result reported as %s", reason)
+	}
+
+	forged := *result
+	forged.DiskHash = "other"
+	if reason, mismatched := challengeMismatch(&forged, expected, "c_1"); reason == "" || !mismatched {
+		t.Fatal("disk hash mismatch not reported")
+	}
+
+	// the digests calculated without running the probes
+	digestOnly := *result
+	digestOnly.DiskDuration = 0
+	if reason, mismatched := challengeMismatch(&digestOnly, expected, "c_1"); reason == "" || mismatched {
+		t.Fatal("result without disk probe not reported as failed without mismatch")
+	}
+}
+
+func TestCalculateIncomeHardwareFailed(t *testing.T) {
+	n := &Node{BandwidthUp: 20 * 1024 * 1024, NATType: types.NatTypeFullCone, TitanDiskUsage: 100 * 1024 * 1024 * 1024}
+	proved := n.CalculateIncome(3000, 1)
+
+	n.hardwareFailed = true
+	if failed := n.CalculateIncome(3000, 1); failed >= proved {
+		t.Fatalf("disk usage of a node failing the hardware challenge counted, %f >= %f", failed, proved)
+	}
+}
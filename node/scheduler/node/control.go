@@ -0,0 +1,289 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// a poll of the control calls returns empty after the timeout
+	controlPollTimeout = 20 * time.Second
+	// the control channel of the node is active if the node polled within the interval
+	controlActiveInterval = controlPollTimeout + 10*time.Second
+	// a control call not picked by the node within the timeout is made with the dial path
+	controlPickupTimeout = 5 * time.Second
+	// the scheduler waits for the result of a picked call at most the timeout
+	maxControlCallTimeout = time.Minute
+	// the control calls returned by a poll
+	maxControlBatch = 16
+)
+
+// errControlUnavailable the control call is not delivered over the control channel, the node is dialed instead
+var errControlUnavailable = xerrors.New("control channel unavailable")
+
+type controlCall struct {
+	call   *types.ControlCall
+	picked chan struct{}
+	result chan *types.ControlResult
+}
+
+// controlChannel multiplexes the control calls of the scheduler to the node over the polls of the node, so the calls are
+// carried by the connection of the node to the scheduler instead of a connection dialed back to the node
+type controlChannel struct {
+	lk       sync.Mutex
+	queue    []*controlCall
+	pending  map[string]*controlCall // the calls picked by the node waiting for the results
+	notify   chan struct{}
+	polling  int
+	lastPoll time.Time
+}
+
+func newControlChannel() *controlChannel {
+	return &controlChannel{pending: make(map[string]*controlCall), notify: make(chan struct{}, 1)}
+}
+
+// active returns whether the node is polling the control calls
+func (c *controlChannel) active() bool {
+	if c == nil {
+		return false
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	return c.polling > 0 || time.Since(c.lastPoll) < controlActiveInterval
+}
+
+// call sends the call of the method to the node and decodes the result into out if not nil, errControlUnavailable is
+// returned if the channel is not active or the node does not pick the call in time
+func (c *controlChannel) call(ctx context.Context, method types.ControlMethod, params, out interface{}) error {
+	if !c.active() {
+		return errControlUnavailable
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, maxControlCallTimeout)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	cc := &controlCall{
+		call:   &types.ControlCall{ID: uuid.NewString(), Method: method, Params: data, Timeout: time.Until(deadline)},
+		picked: make(chan struct{}),
+		result: make(chan *types.ControlResult, 1),
+	}
+	c.enqueue(cc)
+
+	pickup := time.NewTimer(controlPickupTimeout)
+	defer pickup.Stop()
+
+	select {
+	case <-cc.picked:
+	case <-pickup.C:
+		if c.unqueue(cc) {
+			return errControlUnavailable
+		}
+	case <-ctx.Done():
+		if c.unqueue(cc) {
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case r := <-cc.result:
+		if r.Error != "" {
+			return xerrors.New(r.Error)
+		}
+
+		if out != nil && len(r.Result) > 0 {
+			return json.Unmarshal(r.Result, out)
+		}
+		return nil
+	case <-ctx.Done():
+		c.lk.Lock()
+		delete(c.pending, cc.call.ID)
+		c.lk.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+func (c *controlChannel) enqueue(cc *controlCall) {
+	c.lk.Lock()
+	c.queue = append(c.queue, cc)
+	c.lk.Unlock()
+
+	c.signal()
+}
+
+func (c *controlChannel) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// unqueue removes the call not picked yet, returns false if the call is picked by the node
+func (c *controlChannel) unqueue(cc *controlCall) bool {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	for i, q := range c.queue {
+		if q == cc {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// take picks a batch of the queued calls
+func (c *controlChannel) take() []*types.ControlCall {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	size := len(c.queue)
+	if size > maxControlBatch {
+		size = maxControlBatch
+	}
+
+	calls := make([]*types.ControlCall, 0, size)
+	for _, cc := range c.queue[:size] {
+		c.pending[cc.call.ID] = cc
+		close(cc.picked)
+		calls = append(calls, cc.call)
+	}
+	c.queue = c.queue[size:]
+
+	// the rest is left to the next poll
+	if len(c.queue) > 0 {
+		c.signal()
+	}
+
+	return calls
+}
+
+// poll waits for the queued calls up to the timeout
+func (c *controlChannel) poll(ctx context.Context, timeout time.Duration) []*types.ControlCall {
+	c.lk.Lock()
+	c.polling++
+	c.lk.Unlock()
+
+	defer func() {
+		c.lk.Lock()
+		c.polling--
+		c.lastPoll = time.Now()
+		c.lk.Unlock()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		if calls := c.take(); len(calls) > 0 {
+			return calls
+		}
+
+		select {
+		case <-c.notify:
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// complete delivers the results of the picked calls, the results of the calls no longer waited for are dropped
+func (c *controlChannel) complete(results []*types.ControlResult) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	for _, r := range results {
+		cc, ok := c.pending[r.ID]
+		if !ok {
+			continue
+		}
+
+		delete(c.pending, r.ID)
+		cc.result <- r
+	}
+}
+
+// calls returns the calls queued for the node and the calls picked by the node waiting for the results
+func (c *controlChannel) calls() []*types.ControlCall {
+	if c == nil {
+		return nil
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	out := make([]*types.ControlCall, 0, len(c.queue)+len(c.pending))
+	for _, cc := range c.queue {
+		out = append(out, cc.call)
+	}
+
+	for _, cc := range c.pending {
+		out = append(out, cc.call)
+	}
+
+	return out
+}
+
+// ControlCalls returns the control calls in flight to the node
+func (n *Node) ControlCalls() []*types.ControlCall {
+	return n.control.calls()
+}
+
+// PollControlCalls waits for the control calls to the node, returns empty if no call is made within the poll timeout
+func (n *Node) PollControlCalls(ctx context.Context) []*types.ControlCall {
+	return n.control.poll(ctx, controlPollTimeout)
+}
+
+// CompleteControlCalls delivers the results of the control calls returned by the node
+func (n *Node) CompleteControlCalls(results []*types.ControlResult) {
+	n.control.complete(results)
+}
+
+// ExecuteValidation asks the node to be validated over its control channel, the node is dialed if the channel is unavailable
+func (n *Node) ExecuteValidation(ctx context.Context, req *api.ValidateReq) error {
+	err := n.control.call(ctx, types.ControlExecuteValidation, req, nil)
+	if err != errControlUnavailable {
+		return err
+	}
+
+	return n.API.ExecuteValidation(ctx, req)
+}
+
+// PullAsset asks the node to pull the asset over its control channel, the node is dialed if the channel is unavailable
+func (n *Node) PullAsset(ctx context.Context, assetCID string, sources []*types.CandidateDownloadInfo) error {
+	err := n.control.call(ctx, types.ControlPullAsset, &types.PullAssetParams{AssetCID: assetCID, Sources: sources}, nil)
+	if err != errControlUnavailable {
+		return err
+	}
+
+	return n.API.PullAsset(ctx, assetCID, sources)
+}
+
+// ApplyNodeConfig pushes the config to the node over its control channel, the node is dialed if the channel is unavailable
+func (n *Node) ApplyNodeConfig(ctx context.Context, push *types.NodeConfigPush) (*types.NodeConfigAck, error) {
+	ack := &types.NodeConfigAck{}
+	err := n.control.call(ctx, types.ControlApplyNodeConfig, push, ack)
+	if err != errControlUnavailable {
+		return ack, err
+	}
+
+	return n.API.ApplyNodeConfig(ctx, push)
+}
@@ -0,0 +1,82 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestControlChannel(t *testing.T) {
+	c := newControlChannel()
+
+	// the channel is not active before the node polls
+	if err := c.call(context.Background(), types.ControlPullAsset, nil, nil); err != errControlUnavailable {
+		t.Fatalf("expected unavailable, got %v", err)
+	}
+
+	go func() {
+		for {
+			calls := c.poll(context.Background(), time.Second)
+			results := make([]*types.ControlResult, 0, len(calls))
+			for _, call := range calls {
+				results = append(results, &types.ControlResult{ID: call.ID, Result: []byte(`{"Revision":"r1"}`)})
+			}
+			c.complete(results)
+		}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !c.active() {
+		if time.Now().After(deadline) {
+			t.Fatal("channel not active")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ack := &types.NodeConfigAck{}
+	if err := c.call(context.Background(), types.ControlApplyNodeConfig, &types.NodeConfigPush{Revision: "r1"}, ack); err != nil {
+		t.Fatal(err)
+	}
+
+	if ack.Revision != "r1" {
+		t.Fatalf("unexpected ack %+v", ack)
+	}
+}
+
+func TestControlChannelNotPicked(t *testing.T) {
+	c := newControlChannel()
+	c.lastPoll = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := c.call(ctx, types.ControlPullAsset, nil, nil); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if len(c.queue) != 0 {
+		t.Fatalf("call left in queue")
+	}
+}
+
+func TestControlCallsInFlight(t *testing.T) {
+	c := newControlChannel()
+	c.enqueue(&controlCall{call: &types.ControlCall{ID: "c1"}, picked: make(chan struct{}), result: make(chan *types.ControlResult, 1)})
+	c.enqueue(&controlCall{call: &types.ControlCall{ID: "c2"}, picked: make(chan struct{}), result: make(chan *types.ControlResult, 1)})
+
+	if calls := c.take(); len(calls) != 2 {
+		t.Fatalf("unexpected picked calls %v", calls)
+	}
+	c.enqueue(&controlCall{call: &types.ControlCall{ID: "c3"}, picked: make(chan struct{}), result: make(chan *types.ControlResult, 1)})
+
+	if calls := c.calls(); len(calls) != 3 {
+		t.Fatalf("unexpected calls in flight %v", calls)
+	}
+
+	c.complete([]*types.ControlResult{{ID: "c1"}})
+	if calls := c.calls(); len(calls) != 2 {
+		t.Fatalf("unexpected calls in flight %v", calls)
+	}
+}
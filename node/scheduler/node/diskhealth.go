@@ -0,0 +1,78 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+const (
+	// a disk with the sectors reallocated is predicted to fail
+	failingReallocatedSectors = 100
+	// a nvme disk with the life used is predicted to fail, unit: percent
+	failingPercentageUsed = 100
+)
+
+// predictDiskFailure returns why the disk is predicted to fail, empty if the disk is healthy
+func predictDiskFailure(h *types.DiskHealth) string {
+	switch {
+	case h == nil:
+		return ""
+	case h.SmartAvailable && !h.SmartPassed:
+		return "SMART self-assessment failed"
+	case h.PendingSectors > 0:
+		return fmt.Sprintf("%d sectors pending reallocation", h.PendingSectors)
+	case h.UncorrectableSectors > 0:
+		return fmt.Sprintf("%d uncorrectable sectors", h.UncorrectableSectors)
+	case h.ReallocatedSectors >= failingReallocatedSectors:
+		return fmt.Sprintf("%d sectors reallocated", h.ReallocatedSectors)
+	case h.MediaErrors > 0:
+		return fmt.Sprintf("%d media errors", h.MediaErrors)
+	case h.PercentageUsed >= failingPercentageUsed:
+		return fmt.Sprintf("%d%% of the life used", h.PercentageUsed)
+	case h.FilesystemErrors > 0:
+		return fmt.Sprintf("%d filesystem errors", h.FilesystemErrors)
+	}
+
+	return ""
+}
+
+// DiskHealth returns the disk health last reported by the node and why the disk is predicted to fail,
+// empty if the disk is healthy
+func (n *Node) DiskHealth() (*types.DiskHealth, string) {
+	return n.diskHealth, n.diskFailure
+}
+
+// IsDiskFailing checks if the disk of the node is predicted to fail, no replica is placed on the node
+// and its replicas are migrated to other nodes
+func (n *Node) IsDiskFailing() bool {
+	return n.diskFailure != ""
+}
+
+// updateDiskHealth keeps the disk health reported by the node, the disk health is kept if the keepalive has none
+func (m *Manager) updateDiskHealth(node *Node, health *types.DiskHealth) {
+	if health == nil {
+		return
+	}
+
+	failure := predictDiskFailure(health)
+	if failure != "" && node.diskFailure == "" {
+		log.Warnf("disk %s of node %s is predicted to fail: %s", health.Device, node.NodeID, failure)
+	} else if failure == "" && node.diskFailure != "" {
+		log.Infof("disk %s of node %s is healthy again", health.Device, node.NodeID)
+	}
+
+	node.diskHealth = health
+	node.diskFailure = failure
+}
+
+// GetNodeDiskHealth returns the disk health last reported by the online node
+func (m *Manager) GetNodeDiskHealth(nodeID string) *types.NodeDiskHealth {
+	node := m.GetNode(nodeID)
+	if node == nil {
+		return nil
+	}
+
+	health, failure := node.DiskHealth()
+	return &types.NodeDiskHealth{NodeID: nodeID, Health: health, Failure: failure}
+}
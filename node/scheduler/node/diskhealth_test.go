@@ -0,0 +1,53 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestPredictDiskFailure(t *testing.T) {
+	cases := []struct {
+		health  *types.DiskHealth
+		failing bool
+	}{
+		{health: nil, failing: false},
+		{health: &types.DiskHealth{SmartAvailable: true, SmartPassed: true}, failing: false},
+		// the self-assessment is not read if SMART is unavailable
+		{health: &types.DiskHealth{}, failing: false},
+		{health: &types.DiskHealth{SmartAvailable: true}, failing: true},
+		{health: &types.DiskHealth{SmartAvailable: true, SmartPassed: true, ReallocatedSectors: 8}, failing: false},
+		{health: &types.DiskHealth{SmartAvailable: true, SmartPassed: true, ReallocatedSectors: failingReallocatedSectors}, failing: true},
+		{health: &types.DiskHealth{SmartAvailable: true, SmartPassed: true, PendingSectors: 1}, failing: true},
+		{health: &types.DiskHealth{SmartAvailable: true, SmartPassed: true, MediaErrors: 2}, failing: true},
+		{health: &types.DiskHealth{PercentageUsed: 100}, failing: true},
+		{health: &types.DiskHealth{FilesystemErrors: 3}, failing: true},
+	}
+
+	for i, c := range cases {
+		if failure := predictDiskFailure(c.health); (failure != "") != c.failing {
+			t.Errorf("case %d: expected failing %v, got %q", i, c.failing, failure)
+		}
+	}
+}
+
+func TestUpdateDiskHealth(t *testing.T) {
+	m := &Manager{}
+	n := New()
+
+	m.updateDiskHealth(n, &types.DiskHealth{Device: "/dev/sda", SmartAvailable: true})
+	if !n.IsDiskFailing() {
+		t.Fatal("disk failing the self-assessment is not predicted to fail")
+	}
+
+	// a keepalive without the disk health keeps the last one
+	m.updateDiskHealth(n, nil)
+	if !n.IsDiskFailing() {
+		t.Fatal("disk health is reset by a keepalive without it")
+	}
+
+	m.updateDiskHealth(n, &types.DiskHealth{Device: "/dev/sda", SmartAvailable: true, SmartPassed: true})
+	if n.IsDiskFailing() {
+		t.Fatal("healthy disk is predicted to fail")
+	}
+}
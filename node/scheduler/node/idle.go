@@ -0,0 +1,49 @@
+package node
+
+import (
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SetNodeIdle puts the edge in or out of the idle mode. An edge without replicas is granted the idle keepalive interval
+// of the config and is kept online as long as it sends keepalive at the interval
+func (m *Manager) SetNodeIdle(node *Node, idle bool) (*types.IdleMode, error) {
+	if !idle {
+		m.setIdleKeepalive(node, 0)
+		return &types.IdleMode{}, nil
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.IdleKeepaliveInterval <= 0 {
+		return &types.IdleMode{Reason: "idle mode is disabled"}, nil
+	}
+
+	if node.Type != types.NodeEdge {
+		return &types.IdleMode{Reason: "only edges can be idle"}, nil
+	}
+
+	replicas, err := m.CountNodeReplicas(node.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if replicas > 0 {
+		return &types.IdleMode{Reason: "node has replicas"}, nil
+	}
+
+	m.setIdleKeepalive(node, time.Duration(cfg.IdleKeepaliveInterval)*time.Second)
+	log.Infof("node %s enters idle mode, keepalive interval %ds", node.NodeID, cfg.IdleKeepaliveInterval)
+
+	return &types.IdleMode{Idle: true, KeepaliveInterval: cfg.IdleKeepaliveInterval}, nil
+}
+
+// setIdleKeepalive sets the idle keepalive interval of the node and moves its keepalive deadline accordingly
+func (m *Manager) setIdleKeepalive(node *Node, interval time.Duration) {
+	node.idleKeepalive = interval
+	m.keepalives.update(node.NodeID, node.LastRequestTime().Add(node.keepaliveTimeout()))
+}
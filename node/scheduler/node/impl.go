@@ -110,6 +110,7 @@ func (m *Manager) NodeOnline(node *Node, info *types.NodeInfo) error {
 
 	switch node.Type {
 	case types.NodeEdge:
+		m.loadUploadLimit(node)
 		m.storeEdgeNode(node)
 	case types.NodeCandidate, types.NodeValidator:
 		m.storeCandidateNode(node)
@@ -122,12 +123,14 @@ func (m *Manager) NodeOnline(node *Node, info *types.NodeInfo) error {
 
 // GetRandomCandidate returns a random candidate node
 func (m *Manager) GetRandomCandidate() (*Node, int) {
-	nodeID, weight := m.weightMgr.getCandidateWeightRandom()
+	z := m.pickZone(func(z *zone) int { return z.weightMgr.candidateWeights() })
+	nodeID, weight := z.weightMgr.getCandidateWeightRandom()
 	return m.GetCandidateNode(nodeID), weight
 }
 
 // GetRandomEdge returns a random edge node
 func (m *Manager) GetRandomEdge() (*Node, int) {
-	nodeID, weight := m.weightMgr.getEdgeWeightRandom()
+	z := m.pickZone(func(z *zone) int { return z.weightMgr.edgeWeights() })
+	nodeID, weight := z.weightMgr.getEdgeWeightRandom()
 	return m.GetEdgeNode(nodeID), weight
 }
@@ -0,0 +1,144 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the tasks of the online nodes failed to deliver are retried and the expired tasks are dropped at the interval
+	deliverTasksInterval = 5 * time.Minute
+	// a task is delivered within the timeout
+	deliverTaskTimeout = 30 * time.Second
+	// the tasks are kept the hours if the expiration is not configured
+	defaultTaskExpiration = 72
+	maxTaskErrorLen       = 512
+)
+
+// DeleteAssetOfNode deletes the asset from the node through the inbox of the node, the delete is delivered at once if the node
+// is online, or after the node connects again, so the deletes of the offline nodes are not lost
+func (m *Manager) DeleteAssetOfNode(nodeID, cid string) error {
+	return m.EnqueueTask(nodeID, types.NodeTaskDeleteAsset, cid)
+}
+
+// EnqueueTask queues the task in the inbox of the node and delivers the inbox if the node is online
+func (m *Manager) EnqueueTask(nodeID string, kind types.NodeTaskKind, payload string) error {
+	now := time.Now()
+	task := &types.NodeTask{
+		NodeID:      nodeID,
+		Kind:        kind,
+		Payload:     payload,
+		CreatedTime: now,
+		Expiration:  now.Add(m.taskExpiration()),
+	}
+
+	if err := m.SaveNodeTask(task); err != nil {
+		return xerrors.Errorf("SaveNodeTask %s of node %s: %w", kind, nodeID, err)
+	}
+
+	if m.GetNode(nodeID) != nil {
+		go m.DeliverTasks(nodeID)
+	}
+
+	return nil
+}
+
+func (m *Manager) taskExpiration() time.Duration {
+	hours := defaultTaskExpiration
+	if cfg, err := m.config(); err == nil && cfg.NodeTaskExpiration > 0 {
+		hours = cfg.NodeTaskExpiration
+	}
+
+	return time.Duration(hours) * time.Hour
+}
+
+// DeliverTasks delivers the tasks in the inbox of the online node in the order they were queued, a task acknowledged by
+// the node is removed from the inbox. The delivery stops at the first failed task to keep the order, the task is retried
+// when the node connects again or by the delivery timer
+func (m *Manager) DeliverTasks(nodeID string) {
+	if _, loaded := m.delivering.LoadOrStore(nodeID, struct{}{}); loaded {
+		return
+	}
+	defer m.delivering.Delete(nodeID)
+
+	n := m.GetNode(nodeID)
+	if n == nil {
+		return
+	}
+
+	tasks, err := m.LoadNodeTasks(nodeID, time.Now())
+	if err != nil {
+		log.Errorf("LoadNodeTasks %s err:%s", nodeID, err.Error())
+		return
+	}
+
+	for _, task := range tasks {
+		if err = m.deliverTask(n, task); err != nil {
+			msg := err.Error()
+			if len(msg) > maxTaskErrorLen {
+				msg = msg[:maxTaskErrorLen]
+			}
+
+			log.Warnf("deliver task %d %s to node %s err:%s", task.ID, task.Kind, nodeID, msg)
+			if err = m.UpdateNodeTaskAttempt(task.ID, msg); err != nil {
+				log.Errorf("UpdateNodeTaskAttempt %d err:%s", task.ID, err.Error())
+			}
+			return
+		}
+
+		if err = m.DeleteNodeTask(task.ID); err != nil {
+			log.Errorf("DeleteNodeTask %d err:%s", task.ID, err.Error())
+			return
+		}
+	}
+}
+
+func (m *Manager) deliverTask(n *Node, task *types.NodeTask) error {
+	ctx, cancel := context.WithTimeout(context.Background(), deliverTaskTimeout)
+	defer cancel()
+
+	switch task.Kind {
+	case types.NodeTaskDeleteAsset:
+		return n.DeleteAsset(ctx, task.Payload)
+	default:
+		return xerrors.Errorf("unknown task kind %s", task.Kind)
+	}
+}
+
+func (m *Manager) startDeliverTasksTimer() {
+	ticker := time.NewTicker(deliverTasksInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("node.deliver_tasks", deliverTasksInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.deliverPendingTasks(time.Now())
+		done()
+	}
+}
+
+// deliverPendingTasks drops the expired tasks and delivers the inboxes of the online nodes left
+func (m *Manager) deliverPendingTasks(now time.Time) {
+	if count, err := m.DeleteExpiredNodeTasks(now); err != nil {
+		log.Errorf("DeleteExpiredNodeTasks err:%s", err.Error())
+	} else if count > 0 {
+		log.Infof("dropped %d expired tasks of the nodes", count)
+	}
+
+	nodeIDs, err := m.LoadNodeIDsOfTasks(now)
+	if err != nil {
+		log.Errorf("LoadNodeIDsOfTasks err:%s", err.Error())
+		return
+	}
+
+	for _, nodeID := range nodeIDs {
+		if m.GetNode(nodeID) != nil {
+			m.DeliverTasks(nodeID)
+		}
+	}
+}
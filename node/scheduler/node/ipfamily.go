@@ -0,0 +1,105 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// ipFamilies the address families counted in the statistics
+var ipFamilies = []types.IPFamily{types.IPFamilyV4, types.IPFamilyV6}
+
+// familyRoutes counts the retrievals routed for the clients by their address family
+type familyRoutes struct {
+	lk         sync.Mutex
+	retrievals map[types.IPFamily]int64
+	matched    map[types.IPFamily]int64
+}
+
+func newFamilyRoutes() *familyRoutes {
+	return &familyRoutes{retrievals: make(map[types.IPFamily]int64), matched: make(map[types.IPFamily]int64)}
+}
+
+func (r *familyRoutes) add(client types.IPFamily, matched bool) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	r.retrievals[client]++
+	if matched {
+		r.matched[client]++
+	}
+}
+
+func (r *familyRoutes) get(family types.IPFamily) (int64, int64) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	return r.retrievals[family], r.matched[family]
+}
+
+// IPFamily returns the address family the node connected to the scheduler over
+func (n *Node) IPFamily() types.IPFamily {
+	return types.IPFamilyOf(n.RemoteAddr)
+}
+
+// GetCandidateNodesOfFamily returns at most num online candidates connected over the address family
+func (m *Manager) GetCandidateNodesOfFamily(num int, family types.IPFamily) []*Node {
+	var out []*Node
+	m.candidateNodes.Range(func(key, value interface{}) bool {
+		node := value.(*Node)
+		if node.IPFamily() == family {
+			out = append(out, node)
+		}
+
+		return len(out) < num
+	})
+
+	return out
+}
+
+// AddRetrievalRoute counts a retrieval routed for the client of the family, the retrieval is matched
+// if the first node of the route is of the family of the client. The clients of unknown family are not counted
+func (m *Manager) AddRetrievalRoute(client, first types.IPFamily) {
+	if client == "" {
+		return
+	}
+
+	m.routes.add(client, client == first)
+}
+
+// GetIPFamilyStats returns the online nodes and the routed retrievals by address family
+func (m *Manager) GetIPFamilyStats() []*types.IPFamilyStats {
+	stats := make(map[types.IPFamily]*types.IPFamilyStats, len(ipFamilies))
+	out := make([]*types.IPFamilyStats, 0, len(ipFamilies))
+	for _, family := range ipFamilies {
+		s := &types.IPFamilyStats{Family: family}
+		s.Retrievals, s.Matched = m.routes.get(family)
+
+		stats[family] = s
+		out = append(out, s)
+	}
+
+	count := func(key, value interface{}) bool {
+		node := value.(*Node)
+		s, ok := stats[node.IPFamily()]
+		if !ok {
+			return true
+		}
+
+		if node.Type == types.NodeEdge {
+			s.Edges++
+		} else {
+			s.Candidates++
+		}
+
+		if node.NATType == types.NatTypeNo || node.NATType == types.NatTypeFullCone {
+			s.Reachable++
+		}
+		return true
+	}
+
+	m.edgeNodes.Range(count)
+	m.candidateNodes.Range(count)
+
+	return out
+}
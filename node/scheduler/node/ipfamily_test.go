@@ -0,0 +1,46 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestIPv6NodeAddr(t *testing.T) {
+	n := &Node{}
+	n.RemoteAddr = "[2001:db8::1]:1234"
+	n.TCPPort = 9000
+
+	if n.IPFamily() != types.IPFamilyV6 {
+		t.Fatalf("unexpected family %s", n.IPFamily())
+	}
+
+	if addr := n.TCPAddr(); addr != "[2001:db8::1]:9000" {
+		t.Fatalf("unexpected tcp addr %s", addr)
+	}
+
+	n.PortMapping = "4321"
+	if addr := n.DownloadAddr(); addr != "[2001:db8::1]:4321" {
+		t.Fatalf("unexpected download addr %s", addr)
+	}
+
+	n.RemoteAddr = "1.2.3.4:1234"
+	if n.IPFamily() != types.IPFamilyV4 || n.DownloadAddr() != "1.2.3.4:4321" {
+		t.Fatalf("unexpected ipv4 addr %s %s", n.IPFamily(), n.DownloadAddr())
+	}
+}
+
+func TestFamilyRoutes(t *testing.T) {
+	r := newFamilyRoutes()
+	r.add(types.IPFamilyV6, true)
+	r.add(types.IPFamilyV6, false)
+	r.add(types.IPFamilyV4, true)
+
+	if retrievals, matched := r.get(types.IPFamilyV6); retrievals != 2 || matched != 1 {
+		t.Fatalf("unexpected ipv6 routes %d %d", retrievals, matched)
+	}
+
+	if retrievals, matched := r.get(types.IPFamilyV4); retrievals != 1 || matched != 1 {
+		t.Fatalf("unexpected ipv4 routes %d %d", retrievals, matched)
+	}
+}
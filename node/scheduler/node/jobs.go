@@ -0,0 +1,63 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+)
+
+const (
+	// JobDeactivateCleanup migrates the replicas of the deactivated nodes to other nodes and deletes them,
+	// run by any scheduler and handled by the asset manager
+	JobDeactivateCleanup = "node.deactivate_cleanup"
+	// JobRedistributeWeights distributes the select weights of the online nodes again by their scores,
+	// run by the scheduler the nodes are connected to
+	JobRedistributeWeights = "node.redistribute_weights"
+	// JobArchive archives the nodes offline longer than the archive days, run by any scheduler
+	JobArchive = "node.archive"
+	// JobSandboxPurge purges the expired sandbox nodes, run by any scheduler
+	JobSandboxPurge = "node.sandbox_purge"
+)
+
+func (m *Manager) registerJobs() {
+	m.jobs.Register(JobRedistributeWeights, m.runRedistributeWeights)
+	m.jobs.Register(JobArchive, m.runArchive)
+	m.jobs.Register(JobSandboxPurge, m.runSandboxPurge)
+}
+
+// enqueueDailyJobs enqueues the daily jobs of the day, the jobs enqueued by the other schedulers are not enqueued again
+func (m *Manager) enqueueDailyJobs(now time.Time) {
+	day := now.Format("2006-01-02")
+
+	_, err := m.jobs.Enqueue(JobDeactivateCleanup, struct{}{}, jobqueue.Options{
+		DedupKey:    fmt.Sprintf("%s:%s", JobDeactivateCleanup, day),
+		MaxAttempts: 3,
+	})
+	if err != nil && err != jobqueue.ErrDuplicateJob {
+		log.Errorf("enqueue %s err:%s", JobDeactivateCleanup, err.Error())
+	}
+
+	_, err = m.jobs.Enqueue(JobArchive, struct{}{}, jobqueue.Options{
+		DedupKey:    fmt.Sprintf("%s:%s", JobArchive, day),
+		MaxAttempts: 3,
+	})
+	if err != nil && err != jobqueue.ErrDuplicateJob {
+		log.Errorf("enqueue %s err:%s", JobArchive, err.Error())
+	}
+
+	_, err = m.jobs.Enqueue(JobRedistributeWeights, struct{}{}, jobqueue.Options{
+		DedupKey: fmt.Sprintf("%s:%s:%s", JobRedistributeWeights, m.ServerID, day),
+		Local:    true,
+	})
+	if err != nil && err != jobqueue.ErrDuplicateJob {
+		log.Errorf("enqueue %s err:%s", JobRedistributeWeights, err.Error())
+	}
+}
+
+func (m *Manager) runRedistributeWeights(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	m.redistributeNodeSelectWeights()
+	return nil
+}
@@ -0,0 +1,113 @@
+package node
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// keepaliveItem is the keepalive deadline of an online node
+type keepaliveItem struct {
+	nodeID   string
+	deadline time.Time
+	index    int // index of the item in the heap
+}
+
+// keepaliveHeap is a min-heap of keepalive items ordered by deadline
+type keepaliveHeap []*keepaliveItem
+
+func (h keepaliveHeap) Len() int { return len(h) }
+
+func (h keepaliveHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+func (h keepaliveHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *keepaliveHeap) Push(x interface{}) {
+	item := x.(*keepaliveItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *keepaliveHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// keepaliveQueue tracks the keepalive deadlines of online nodes.
+// Heartbeats move the deadline of a node forward, and the keepalive sweep only
+// needs to look at the nodes whose deadline has passed instead of every online node.
+type keepaliveQueue struct {
+	lock  sync.Mutex
+	items map[string]*keepaliveItem
+	heap  keepaliveHeap
+}
+
+func newKeepaliveQueue() *keepaliveQueue {
+	return &keepaliveQueue{
+		items: make(map[string]*keepaliveItem),
+		heap:  make(keepaliveHeap, 0),
+	}
+}
+
+// update sets the keepalive deadline of the node, adding the node if it is not in the queue
+func (q *keepaliveQueue) update(nodeID string, deadline time.Time) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	item, exist := q.items[nodeID]
+	if exist {
+		item.deadline = deadline
+		heap.Fix(&q.heap, item.index)
+		return
+	}
+
+	item = &keepaliveItem{nodeID: nodeID, deadline: deadline}
+	q.items[nodeID] = item
+	heap.Push(&q.heap, item)
+}
+
+// remove deletes the node from the queue
+func (q *keepaliveQueue) remove(nodeID string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	item, exist := q.items[nodeID]
+	if !exist {
+		return
+	}
+
+	delete(q.items, nodeID)
+	heap.Remove(&q.heap, item.index)
+}
+
+// popExpired removes and returns the nodes whose deadline is not after t
+func (q *keepaliveQueue) popExpired(t time.Time) []string {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	out := make([]string, 0)
+	for len(q.heap) > 0 && !q.heap[0].deadline.After(t) {
+		item := heap.Pop(&q.heap).(*keepaliveItem)
+		delete(q.items, item.nodeID)
+		out = append(out, item.nodeID)
+	}
+
+	return out
+}
+
+// len returns the number of nodes in the queue
+func (q *keepaliveQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return len(q.heap)
+}
@@ -0,0 +1,140 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+const benchmarkNodeCount = 100000
+
+func TestKeepaliveQueue(t *testing.T) {
+	q := newKeepaliveQueue()
+	now := time.Now()
+
+	q.update("e_1", now.Add(3*time.Second))
+	q.update("e_2", now.Add(1*time.Second))
+	q.update("e_3", now.Add(2*time.Second))
+
+	// heartbeat of e_2 moves its deadline after e_3
+	q.update("e_2", now.Add(5*time.Second))
+	q.remove("e_1")
+
+	if q.len() != 2 {
+		t.Fatalf("expected 2 nodes in queue, got %d", q.len())
+	}
+
+	expired := q.popExpired(now.Add(2 * time.Second))
+	if len(expired) != 1 || expired[0] != "e_3" {
+		t.Fatalf("expected [e_3] expired, got %v", expired)
+	}
+
+	expired = q.popExpired(now.Add(4 * time.Second))
+	if len(expired) != 0 {
+		t.Fatalf("expected no node expired, got %v", expired)
+	}
+
+	expired = q.popExpired(now.Add(5 * time.Second))
+	if len(expired) != 1 || expired[0] != "e_2" {
+		t.Fatalf("expected [e_2] expired, got %v", expired)
+	}
+
+	if q.len() != 0 {
+		t.Fatalf("expected empty queue, got %d", q.len())
+	}
+}
+
+func TestIdleKeepalive(t *testing.T) {
+	m := &Manager{keepalives: newKeepaliveQueue()}
+	m.config = func() (config.SchedulerCfg, error) {
+		return config.SchedulerCfg{IdleKeepaliveInterval: 300}, nil
+	}
+
+	now := time.Now()
+	node := New()
+	node.NodeID = "c_1"
+	node.Type = types.NodeCandidate
+	node.SetLastRequestTime(now)
+
+	if mode, err := m.SetNodeIdle(node, true); err != nil || mode.Idle {
+		t.Fatalf("expected the candidate denied, got %v %v", mode, err)
+	}
+
+	// the idle edge is kept online until the idle keepalive interval passes
+	node.NodeID = "e_1"
+	node.Type = types.NodeEdge
+	m.setIdleKeepalive(node, 300*time.Second)
+
+	if expired := m.keepalives.popExpired(now.Add(time.Minute)); len(expired) != 0 {
+		t.Fatalf("expected no node expired, got %v", expired)
+	}
+
+	m.setIdleKeepalive(node, 0)
+	if expired := m.keepalives.popExpired(now.Add(time.Minute)); len(expired) != 1 {
+		t.Fatalf("expected e_1 expired after leaving idle mode, got %v", expired)
+	}
+}
+
+func newBenchmarkNodes(now time.Time) ([]*Node, *sync.Map, *keepaliveQueue) {
+	nodes := make([]*Node, 0, benchmarkNodeCount)
+	nodeMap := &sync.Map{}
+	q := newKeepaliveQueue()
+
+	for i := 0; i < benchmarkNodeCount; i++ {
+		node := New()
+		node.NodeID = fmt.Sprintf("e_%d", i)
+		node.SetLastRequestTime(now)
+
+		nodes = append(nodes, node)
+		nodeMap.Store(node.NodeID, node)
+		q.update(node.NodeID, now.Add(keepaliveTime))
+	}
+
+	return nodes, nodeMap, q
+}
+
+// BenchmarkKeepaliveFullSweep ranges over every online node each tick, as the keepalive did before
+func BenchmarkKeepaliveFullSweep(b *testing.B) {
+	now := time.Now()
+	_, nodeMap, _ := newBenchmarkNodes(now)
+	t := now.Add(-keepaliveTime)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offline := 0
+		nodeMap.Range(func(key, value interface{}) bool {
+			node := value.(*Node)
+			if !node.LastRequestTime().After(t) {
+				offline++
+			}
+			return true
+		})
+	}
+}
+
+// BenchmarkKeepaliveQueueSweep only looks at the nodes whose deadline has passed
+func BenchmarkKeepaliveQueueSweep(b *testing.B) {
+	now := time.Now()
+	_, _, q := newBenchmarkNodes(now)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.popExpired(now)
+	}
+}
+
+// BenchmarkKeepaliveQueueHeartbeat measures the cost added to every heartbeat
+func BenchmarkKeepaliveQueueHeartbeat(b *testing.B) {
+	now := time.Now()
+	nodes, _, q := newBenchmarkNodes(now)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		node := nodes[i%len(nodes)]
+		q.update(node.NodeID, now.Add(keepaliveTime+time.Duration(i)))
+	}
+}
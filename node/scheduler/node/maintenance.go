@@ -0,0 +1,166 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"golang.org/x/xerrors"
+)
+
+// reloadMaintenanceInterval the interval the maintenance windows are reloaded at,
+// the windows added on the other schedulers take effect after the reload
+const reloadMaintenanceInterval = time.Minute
+
+// maintenanceWindows the maintenance windows not ended by node id
+type maintenanceWindows struct {
+	lk      sync.RWMutex
+	windows map[string][]*types.MaintenanceWindow
+}
+
+func (w *maintenanceWindows) set(windows []*types.MaintenanceWindow) {
+	byNode := make(map[string][]*types.MaintenanceWindow)
+	for _, window := range windows {
+		byNode[window.NodeID] = append(byNode[window.NodeID], window)
+	}
+
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	w.windows = byNode
+}
+
+func (w *maintenanceWindows) in(nodeID string, t time.Time) bool {
+	w.lk.RLock()
+	defer w.lk.RUnlock()
+
+	for _, window := range w.windows[nodeID] {
+		if window.In(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *maintenanceWindows) len() int {
+	w.lk.RLock()
+	defer w.lk.RUnlock()
+
+	return len(w.windows)
+}
+
+// InMaintenance checks if the node is in a maintenance window at the time
+func (m *Manager) InMaintenance(nodeID string, t time.Time) bool {
+	return m.maintenance.in(nodeID, t)
+}
+
+// AddMaintenanceWindow adds the maintenance window of the node and returns its id
+func (m *Manager) AddMaintenanceWindow(w *types.MaintenanceWindow) (int64, error) {
+	if w.NodeID == "" {
+		return 0, xerrors.New("node id can not empty")
+	}
+
+	now := time.Now()
+	if !w.EndTime.After(w.StartTime) {
+		return 0, xerrors.New("end time must be after start time")
+	}
+
+	if !w.EndTime.After(now) {
+		return 0, xerrors.New("end time must be in the future")
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return 0, err
+	}
+
+	if max := time.Duration(cfg.MaxMaintenanceHours) * time.Hour; max > 0 && w.EndTime.Sub(w.StartTime) > max {
+		return 0, xerrors.Errorf("the window can not be longer than %d hours", cfg.MaxMaintenanceHours)
+	}
+
+	w.CreatedTime = now
+	id, err := m.SaveMaintenanceWindow(w)
+	if err != nil {
+		return 0, err
+	}
+
+	m.reloadMaintenance(now)
+	return id, nil
+}
+
+// EndMaintenanceWindow ends the maintenance window now, the node resumes at once
+func (m *Manager) EndMaintenanceWindow(id int64) error {
+	now := time.Now()
+	if err := m.SQLDB.EndMaintenanceWindow(id, now); err != nil {
+		return err
+	}
+
+	m.reloadMaintenance(now)
+	return nil
+}
+
+// MaintenanceMinutes returns the minutes the node was in the maintenance windows in [start, end)
+func (m *Manager) MaintenanceMinutes(nodeID string, start, end time.Time) int {
+	minutes, err := m.LoadMaintenanceMinutes(nodeID, start, end)
+	if err != nil {
+		log.Errorf("LoadMaintenanceMinutes %s err:%s", nodeID, err.Error())
+		return 0
+	}
+
+	return minutes
+}
+
+func (m *Manager) startMaintenanceTimer() {
+	diagnostics.RegisterSize("node.maintenance", m.maintenance.len)
+
+	m.reloadMaintenance(time.Now())
+
+	ticker := time.NewTicker(reloadMaintenanceInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("node.reload_maintenance", reloadMaintenanceInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.reloadMaintenance(time.Now())
+		done()
+	}
+}
+
+// reloadMaintenance reloads the maintenance windows and updates the online nodes entering or leaving maintenance,
+// the select weights of a node are repaid when it enters and distributed again when its window ends
+func (m *Manager) reloadMaintenance(now time.Time) {
+	windows, err := m.LoadUnendedMaintenanceWindows(now)
+	if err != nil {
+		log.Errorf("LoadUnendedMaintenanceWindows err:%s", err.Error())
+		return
+	}
+
+	m.maintenance.set(windows)
+
+	update := func(key, value interface{}) bool {
+		node := value.(*Node)
+
+		in := m.InMaintenance(node.NodeID, now)
+		if in == node.inMaintenance {
+			return true
+		}
+
+		if in {
+			log.Infof("node %s enters maintenance", node.NodeID)
+			node.inMaintenance = true
+			m.RepayNodeWeight(node)
+			return true
+		}
+
+		log.Infof("node %s leaves maintenance", node.NodeID)
+		node.inMaintenance = false
+		m.DistributeNodeWeight(node)
+		return true
+	}
+
+	m.edgeNodes.Range(update)
+	m.candidateNodes.Range(update)
+}
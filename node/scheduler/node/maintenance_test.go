@@ -0,0 +1,38 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestMaintenanceWindows(t *testing.T) {
+	start := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	w := &maintenanceWindows{}
+	w.set([]*types.MaintenanceWindow{
+		{NodeID: "e_1", StartTime: start, EndTime: start.Add(time.Hour)},
+		{NodeID: "e_1", StartTime: start.Add(3 * time.Hour), EndTime: start.Add(4 * time.Hour)},
+	})
+
+	cases := []struct {
+		nodeID string
+		t      time.Time
+		in     bool
+	}{
+		{"e_1", start.Add(-time.Minute), false},
+		{"e_1", start, true},
+		{"e_1", start.Add(59 * time.Minute), true},
+		// resumed at the end of the window
+		{"e_1", start.Add(time.Hour), false},
+		{"e_1", start.Add(3*time.Hour + time.Minute), true},
+		{"e_2", start, false},
+	}
+
+	for _, c := range cases {
+		if in := w.in(c.nodeID, c.t); in != c.in {
+			t.Errorf("%s at %s: expected %v, got %v", c.nodeID, c.t.Format(time.RFC3339), c.in, in)
+		}
+	}
+}
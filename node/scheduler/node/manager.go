@@ -1,23 +1,30 @@
 package node
 
 import (
-	"crypto/rsa"
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
-	"github.com/Filecoin-Titan/titan/lib/etcdcli"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
-	"github.com/filecoin-project/pubsub"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/Filecoin-Titan/titan/node/scheduler/chaos"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/overload"
 	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
 )
 
 var log = logging.Logger("node")
 
 const (
-	syncEdgeCountTime = 5 * time.Minute //
 	// keepaliveTime is the interval between keepalive requests
 	keepaliveTime       = 30 * time.Second // seconds
 	calculatePointsTime = 30 * time.Minute
@@ -34,43 +41,96 @@ type Manager struct {
 	candidateNodes sync.Map
 	Edges          int // online edge node count
 	Candidates     int // online candidate node count
-	weightMgr      *weightManager
 	config         dtypes.GetSchedulerConfigFunc
-	notify         *pubsub.PubSub
-	etcdcli        *etcdcli.Client
+	notify         *eventbus.Bus
 	*db.SQLDB
-	*rsa.PrivateKey // scheduler privateKey
-	dtypes.ServerID // scheduler server id
+	KeyRing         *keys.Ring // scheduler keys
+	dtypes.ServerID            // scheduler server id
 
 	ipLimit           int
 	TotalNetworkEdges int // Number of edge nodes in the entire network (including those on other schedulers)
 
 	nodeIPs sync.Map
+
+	keepalives *keepaliveQueue // keepalive deadlines of online nodes
+	transfers  *transferStats  // succeeded transfers of the nodes by protocol
+	routes     *familyRoutes   // retrievals routed for the clients by address family
+
+	// zones the areas served by the scheduler, each with its own select weights and statistics
+	zones       map[string]*zone
+	defaultZone *zone // the zone of the area of the scheduler
+
+	overload *overload.Manager
+
+	// maintenance the maintenance windows not ended of all nodes
+	maintenance *maintenanceWindows
+
+	jobs *jobqueue.Queue
+
+	// delivering the nodes whose inboxes are being delivered
+	delivering sync.Map
+
+	// saveTimer tracks the saves of the node information on keepalive
+	saveTimer *diagnostics.Timer
 }
 
 // NewManager creates a new instance of the node manager
-func NewManager(sdb *db.SQLDB, serverID dtypes.ServerID, pk *rsa.PrivateKey, pb *pubsub.PubSub, config dtypes.GetSchedulerConfigFunc, ec *etcdcli.Client) *Manager {
+func NewManager(sdb *db.SQLDB, serverID dtypes.ServerID, keyRing *keys.Ring, pb *eventbus.Bus, config dtypes.GetSchedulerConfigFunc, omgr *overload.Manager, jq *jobqueue.Queue) *Manager {
 	nodeManager := &Manager{
-		SQLDB:      sdb,
-		ServerID:   serverID,
-		PrivateKey: pk,
-		notify:     pb,
-		config:     config,
-		etcdcli:    ec,
-		weightMgr:  newWeightManager(config),
+		SQLDB:       sdb,
+		ServerID:    serverID,
+		KeyRing:     keyRing,
+		notify:      pb,
+		config:      config,
+		keepalives:  newKeepaliveQueue(),
+		overload:    omgr,
+		transfers:   newTransferStats(),
+		routes:      newFamilyRoutes(),
+		maintenance: &maintenanceWindows{},
+		jobs:        jq,
+		saveTimer:   diagnostics.NewTimer("node.save_snapshots", keepaliveTime*saveInfoInterval),
 	}
 
+	nodeManager.zones, nodeManager.defaultZone = newZones(config)
+
 	nodeManager.ipLimit = nodeManager.getIPLimit()
 	log.Infof("nodeManager.ipLimit %d", nodeManager.ipLimit)
 
+	if err := nodeManager.InitStatsCounters(); err != nil {
+		log.Errorf("InitStatsCounters err:%s", err.Error())
+	}
+
+	nodeManager.registerDiagnostics()
+	nodeManager.registerJobs()
+
 	go nodeManager.startNodeKeepaliveTimer()
 	go nodeManager.startCheckNodeTimer()
-	go nodeManager.startSyncEdgeCountTimer()
+	go nodeManager.startHardwareChallengeTimer()
+	go nodeManager.startMaintenanceTimer()
+	go nodeManager.startDeliverTasksTimer()
+	go nodeManager.startSandboxPurgeTimer()
 	// go nodeManager.startCalculatePointsTimer()
 
 	return nodeManager
 }
 
+// registerDiagnostics registers the in-memory registries of the manager to the diagnostics
+func (m *Manager) registerDiagnostics() {
+	diagnostics.RegisterSize("node.edges", func() int { return syncMapLen(&m.edgeNodes) })
+	diagnostics.RegisterSize("node.candidates", func() int { return syncMapLen(&m.candidateNodes) })
+	diagnostics.RegisterSize("node.ips", func() int { return syncMapLen(&m.nodeIPs) })
+	diagnostics.RegisterSize("node.keepalives", m.keepalives.len)
+}
+
+func syncMapLen(sm *sync.Map) int {
+	n := 0
+	sm.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
 func (m *Manager) getIPLimit() int {
 	cfg, err := m.config()
 	if err != nil {
@@ -141,45 +201,35 @@ func (m *Manager) CheckIPExist(ip string) bool {
 	return exist
 }
 
-func (m *Manager) syncEdgeCountFromNetwork() {
-	err := m.etcdcli.PutEdgeCount(string(m.ServerID), m.Edges)
-	if err != nil {
-		log.Errorf("SyncEdgeCountFromNetwork PutEdgeCount err:%s", err.Error())
-	}
-
-	count, err := m.etcdcli.GetEdgeCounts(string(m.ServerID))
-	if err != nil {
-		log.Errorf("SyncEdgeCountFromNetwork GetEdgeCounts err:%s", err.Error())
-	}
-
+// SetPeerEdges sets the edges online on the other schedulers of the network
+func (m *Manager) SetPeerEdges(count int) {
 	m.TotalNetworkEdges = count + m.Edges
 }
 
-// startSyncEdgeCountTimer
-func (m *Manager) startSyncEdgeCountTimer() {
-	ticker := time.NewTicker(syncEdgeCountTime)
-	defer ticker.Stop()
-
-	for {
-		<-ticker.C
-
-		m.syncEdgeCountFromNetwork()
-	}
-}
-
-// startNodeKeepaliveTimer periodically sends keepalive requests to all nodes and checks if any nodes have been offline for too long
+// startNodeKeepaliveTimer periodically checks if any nodes have been offline for too long and saves the node information,
+// the information is saved less often while the scheduler is overloaded
 func (m *Manager) startNodeKeepaliveTimer() {
 	ticker := time.NewTicker(keepaliveTime)
 	defer ticker.Stop()
 
-	count := 0
+	t := diagnostics.NewTimer("node.keepalive", keepaliveTime)
+
+	// keepalive times since the information was saved
+	unsaved := 0
 
 	for {
 		<-ticker.C
-		count++
+		unsaved++
 
-		saveInfo := count%saveInfoInterval == 0
-		m.nodesKeepalive(saveInfo)
+		// saved on the multiples of saveInfoInterval, so the online duration is always whole minutes
+		saveInfo := unsaved%saveInfoInterval == 0 && unsaved >= saveInfoInterval*m.overload.KeepaliveSaveStretch()
+		done := t.Start()
+		m.nodesKeepalive(saveInfo, unsaved)
+		done()
+
+		if saveInfo {
+			unsaved = 0
+		}
 	}
 }
 
@@ -196,15 +246,21 @@ func (m *Manager) startCheckNodeTimer() {
 	timer := time.NewTimer(duration)
 	defer timer.Stop()
 
+	t := diagnostics.NewTimer("node.check_node", oneDay)
+
 	for {
 		<-timer.C
 
 		log.Debugln("start node timer...")
+		done := t.Start()
 
-		m.redistributeNodeSelectWeights()
+		m.enqueueDailyJobs(time.Now())
 
-		m.checkNodeDeactivate()
+		if err := m.ResetStatsCounters(); err != nil {
+			log.Errorf("ResetStatsCounters err:%s", err.Error())
+		}
 
+		done()
 		timer.Reset(oneDay)
 	}
 }
@@ -215,15 +271,20 @@ func (m *Manager) storeEdgeNode(node *Node) {
 		return
 	}
 	nodeID := node.NodeID
+	node.inMaintenance = m.InMaintenance(nodeID, time.Now())
 	_, loaded := m.edgeNodes.LoadOrStore(nodeID, node)
 	if loaded {
 		return
 	}
+	m.keepalives.update(nodeID, time.Now().Add(keepaliveTime))
+	z := m.zoneOf(node)
+	z.stats.update(node, true)
+	atomic.AddInt64(&z.edges, 1)
 	m.Edges++
 
 	m.DistributeNodeWeight(node)
 
-	m.notify.Pub(node, types.EventNodeOnline.String())
+	m.publish(node, types.EventNodeOnline)
 }
 
 // adds a candidate node to the manager's list of candidate nodes
@@ -233,43 +294,64 @@ func (m *Manager) storeCandidateNode(node *Node) {
 	}
 
 	nodeID := node.NodeID
+	node.inMaintenance = m.InMaintenance(nodeID, time.Now())
 	_, loaded := m.candidateNodes.LoadOrStore(nodeID, node)
 	if loaded {
 		return
 	}
+	m.keepalives.update(nodeID, time.Now().Add(keepaliveTime))
+	z := m.zoneOf(node)
+	z.stats.update(node, true)
+	atomic.AddInt64(&z.candidates, 1)
 	m.Candidates++
 
 	m.DistributeNodeWeight(node)
 
-	m.notify.Pub(node, types.EventNodeOnline.String())
+	m.publish(node, types.EventNodeOnline)
 }
 
 // deleteEdgeNode removes an edge node from the manager's list of edge nodes
 func (m *Manager) deleteEdgeNode(node *Node) {
 	m.RepayNodeWeight(node)
-	m.notify.Pub(node, types.EventNodeOffline.String())
+	m.publish(node, types.EventNodeOffline)
 
 	nodeID := node.NodeID
+	m.keepalives.remove(nodeID)
+
 	_, loaded := m.edgeNodes.LoadAndDelete(nodeID)
 	if !loaded {
 		return
 	}
+	z := m.zoneOf(node)
+	z.stats.remove(nodeID)
+	atomic.AddInt64(&z.edges, -1)
 	m.Edges--
 }
 
 // deleteCandidateNode removes a candidate node from the manager's list of candidate nodes
 func (m *Manager) deleteCandidateNode(node *Node) {
 	m.RepayNodeWeight(node)
-	m.notify.Pub(node, types.EventNodeOffline.String())
+	m.publish(node, types.EventNodeOffline)
 
 	nodeID := node.NodeID
+	m.keepalives.remove(nodeID)
+
 	_, loaded := m.candidateNodes.LoadAndDelete(nodeID)
 	if !loaded {
 		return
 	}
+	z := m.zoneOf(node)
+	z.stats.remove(nodeID)
+	atomic.AddInt64(&z.candidates, -1)
 	m.Candidates--
 }
 
+// publish publishes the online or offline event of the node
+func (m *Manager) publish(node *Node, event types.EventTopics) {
+	chaos.Sleep(chaos.PubsubBacklog)
+	m.notify.Pub(node, event.String())
+}
+
 // DistributeNodeWeight Distribute Node Weight
 func (m *Manager) DistributeNodeWeight(node *Node) {
 	if node.IsAbnormal() {
@@ -281,12 +363,13 @@ func (m *Manager) DistributeNodeWeight(node *Node) {
 		return
 	}
 
+	weightMgr := m.zoneOf(node).weightMgr
 	score := m.getNodeScoreLevel(node.NodeID)
-	wNum := m.weightMgr.getWeightNum(score)
+	wNum := weightMgr.getWeightNum(score)
 	if node.Type == types.NodeCandidate {
-		node.selectWeights = m.weightMgr.distributeCandidateWeight(node.NodeID, wNum)
+		node.selectWeights = weightMgr.distributeCandidateWeight(node.NodeID, wNum)
 	} else if node.Type == types.NodeEdge {
-		node.selectWeights = m.weightMgr.distributeEdgeWeight(node.NodeID, wNum)
+		node.selectWeights = weightMgr.distributeEdgeWeight(node.NodeID, wNum)
 	}
 }
 
@@ -297,15 +380,52 @@ func (m *Manager) RepayNodeWeight(node *Node) {
 		return
 	}
 
+	weightMgr := m.zoneOf(node).weightMgr
 	if node.Type == types.NodeCandidate {
-		m.weightMgr.repayCandidateWeight(node.selectWeights)
+		weightMgr.repayCandidateWeight(node.selectWeights)
 		node.selectWeights = nil
 	} else if node.Type == types.NodeEdge {
-		m.weightMgr.repayEdgeWeight(node.selectWeights)
+		weightMgr.repayEdgeWeight(node.selectWeights)
 		node.selectWeights = nil
 	}
 }
 
+// RecomputeNodeWeight repays the select weights of the online node and distributes them again by its current score
+func (m *Manager) RecomputeNodeWeight(nodeID string) error {
+	node := m.GetNode(nodeID)
+	if node == nil {
+		return xerrors.Errorf("node %s is offline", nodeID)
+	}
+
+	m.RepayNodeWeight(node)
+	m.DistributeNodeWeight(node)
+	return nil
+}
+
+// DeactivateNode saves the time the node is deactivated at, the online node stops being selected at once
+func (m *Manager) DeactivateNode(nodeID string, deactivateTime int64) error {
+	if err := m.SaveDeactivateNode(nodeID, deactivateTime); err != nil {
+		return xerrors.Errorf("SaveDeactivateNode %s err : %s", nodeID, err.Error())
+	}
+
+	node := m.GetNode(nodeID)
+	if node != nil {
+		node.DeactivateTime = deactivateTime
+		m.RepayNodeWeight(node)
+	}
+
+	return nil
+}
+
+// KeepaliveNode records a keepalive request of the node and moves its keepalive deadline forward
+func (m *Manager) KeepaliveNode(node *Node, t time.Time) {
+	chaos.Sleep(chaos.KeepaliveDelay)
+
+	node.SetLastRequestTime(t)
+	m.keepalives.update(node.NodeID, t.Add(node.keepaliveTimeout()))
+	m.zoneOf(node).stats.update(node, false)
+}
+
 // nodeKeepalive checks if a node has sent a keepalive recently and updates node status accordingly
 func (m *Manager) nodeKeepalive(node *Node, t time.Time) bool {
 	lastTime := node.LastRequestTime()
@@ -320,6 +440,10 @@ func (m *Manager) nodeKeepalive(node *Node, t time.Time) bool {
 			m.deleteEdgeNode(node)
 		}
 
+		if err := m.SaveNodeOffline(node.NodeID, lastTime); err != nil {
+			log.Errorf("SaveNodeOffline %s err:%s", node.NodeID, err.Error())
+		}
+
 		log.Infof("node offline %s", node.NodeID)
 
 		return false
@@ -328,11 +452,74 @@ func (m *Manager) nodeKeepalive(node *Node, t time.Time) bool {
 	return true
 }
 
-// nodesKeepalive checks all nodes in the manager's lists for keepalive
-func (m *Manager) nodesKeepalive(isSave bool) {
-	t := time.Now().Add(-keepaliveTime)
+// nodesKeepalive checks the nodes whose keepalive deadline has passed,
+// the nodes that are still alive are put back into the keepalive queue
+func (m *Manager) nodesKeepalive(isSave bool, keepalives int) {
+	ctx, span := tracing.Start(context.Background(), "node.keepalive_sweep", attribute.Bool("save", isSave))
+	defer span.End()
+
+	now := time.Now()
+
+	expired := m.keepalives.popExpired(now)
+	if chaos.Enabled {
+		expired = append(expired, m.chaosDisconnects()...)
+	}
+	span.SetAttributes(attribute.Int("expired", len(expired)))
+
+	for _, nodeID := range expired {
+		node := m.GetNode(nodeID)
+		if node == nil {
+			continue
+		}
+
+		// the expired sandbox nodes are taken offline as if they stopped sending keepalives
+		t := now.Add(-node.keepaliveTimeout())
+		if node.sandboxExpired(now) {
+			t = now
+		}
+
+		if m.nodeKeepalive(node, t) {
+			// a keepalive arrived while the node was being checked
+			m.keepalives.update(nodeID, node.LastRequestTime().Add(node.keepaliveTimeout()))
+		}
+	}
+
+	if isSave {
+		_, saveSpan := tracing.Start(ctx, "node.save_snapshots")
+		done := m.saveTimer.Start()
+		m.saveNodeSnapshots(time.Duration(keepalives) * keepaliveTime)
+		done()
+		saveSpan.End()
+	}
+}
+
+// CheckKeepalives runs the keepalive check of the timer immediately and saves the information of the online nodes if save is true
+func (m *Manager) CheckKeepalives(save bool) {
+	m.nodesKeepalive(save, saveInfoInterval)
+}
 
+// chaosDisconnects picks the online nodes hit by the disconnect fault, they are handled as if they stopped sending keepalives
+func (m *Manager) chaosDisconnects() []string {
+	nodeIDs := make([]string, 0)
+	disconnect := func(key, value interface{}) bool {
+		node := value.(*Node)
+		if chaos.Hit(chaos.NodeDisconnect) {
+			node.SetLastRequestTime(time.Time{})
+			nodeIDs = append(nodeIDs, node.NodeID)
+		}
+		return true
+	}
+
+	m.edgeNodes.Range(disconnect)
+	m.candidateNodes.Range(disconnect)
+
+	return nodeIDs
+}
+
+// saveNodeSnapshots updates the online duration of all online nodes for the elapsed time and saves their information
+func (m *Manager) saveNodeSnapshots(elapsed time.Duration) {
 	nodes := make([]*types.NodeSnapshot, 0)
+	decimals := m.PointDecimals()
 
 	m.edgeNodes.Range(func(key, value interface{}) bool {
 		node := value.(*Node)
@@ -340,32 +527,27 @@ func (m *Manager) nodesKeepalive(isSave bool) {
 			return true
 		}
 
-		if m.nodeKeepalive(node, t) {
-			if isSave {
-				// Minute
-				node.OnlineDuration += int((saveInfoInterval * keepaliveTime) / time.Minute)
-
-				// add node mc
-				mc := node.CalculateMCx(m.TotalNetworkEdges)
-				// update client incomeIncr (Increase value every thirty minutes)
-				node.IncomeIncr = (mc * 360)
-
-				profit := mc * float64((saveInfoInterval*keepaliveTime)/(5*time.Second))
-				// log.Infof("nodeKeepalive %s : Mc:[%v] , i:[%v]", node.NodeID, mc, float64(keepaliveTime/(5*time.Second)))
-
-				nodes = append(nodes, &types.NodeSnapshot{
-					NodeID:             node.NodeID,
-					OnlineDuration:     node.OnlineDuration,
-					DiskUsage:          node.DiskUsage,
-					LastSeen:           time.Now(),
-					BandwidthDown:      node.BandwidthDown,
-					BandwidthUp:        node.BandwidthUp,
-					Profit:             profit,
-					TitanDiskUsage:     node.TitanDiskUsage,
-					AvailableDiskSpace: node.AvailableDiskSpace,
-				})
-			}
-		}
+		// Minute
+		node.OnlineDuration += int(elapsed / time.Minute)
+
+		// add node mc
+		mc := node.CalculateMCx(m.TotalNetworkEdges)
+		// update client incomeIncr (Increase value every thirty minutes)
+		node.IncomeIncr = (mc * 360)
+
+		profit := types.PointsFromFloat(mc * elapsed.Seconds() / 5).Round(decimals)
+
+		nodes = append(nodes, &types.NodeSnapshot{
+			NodeID:             node.NodeID,
+			OnlineDuration:     node.OnlineDuration,
+			DiskUsage:          node.DiskUsage,
+			LastSeen:           time.Now(),
+			BandwidthDown:      node.BandwidthDown,
+			BandwidthUp:        node.BandwidthUp,
+			Profit:             profit,
+			TitanDiskUsage:     node.TitanDiskUsage,
+			AvailableDiskSpace: node.AvailableDiskSpace,
+		})
 
 		return true
 	})
@@ -376,32 +558,26 @@ func (m *Manager) nodesKeepalive(isSave bool) {
 			return true
 		}
 
-		if m.nodeKeepalive(node, t) {
-			if isSave {
-				// Minute
-				node.OnlineDuration += int((saveInfoInterval * keepaliveTime) / time.Minute)
-
-				nodes = append(nodes, &types.NodeSnapshot{
-					NodeID:             node.NodeID,
-					OnlineDuration:     node.OnlineDuration,
-					DiskUsage:          node.DiskUsage,
-					LastSeen:           time.Now(),
-					BandwidthDown:      node.BandwidthDown,
-					BandwidthUp:        node.BandwidthUp,
-					TitanDiskUsage:     node.TitanDiskUsage,
-					AvailableDiskSpace: node.AvailableDiskSpace,
-				})
-			}
-		}
+		// Minute
+		node.OnlineDuration += int(elapsed / time.Minute)
+
+		nodes = append(nodes, &types.NodeSnapshot{
+			NodeID:             node.NodeID,
+			OnlineDuration:     node.OnlineDuration,
+			DiskUsage:          node.DiskUsage,
+			LastSeen:           time.Now(),
+			BandwidthDown:      node.BandwidthDown,
+			BandwidthUp:        node.BandwidthUp,
+			TitanDiskUsage:     node.TitanDiskUsage,
+			AvailableDiskSpace: node.AvailableDiskSpace,
+		})
 
 		return true
 	})
 
-	if isSave {
-		err := m.UpdateOnlineDuration(nodes)
-		if err != nil {
-			log.Errorf("UpdateNodeInfos err:%s", err.Error())
-		}
+	err := m.UpdateOnlineDuration(nodes)
+	if err != nil {
+		log.Errorf("UpdateNodeInfos err:%s", err.Error())
 	}
 }
 
@@ -412,9 +588,22 @@ func (m *Manager) saveInfo(n *types.NodeInfo) error {
 	return m.SaveNodeInfo(n)
 }
 
+// PointDecimals returns the decimals the points earned by the nodes are rounded to
+func (m *Manager) PointDecimals() int {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return types.MaxPointDecimals
+	}
+
+	return cfg.PointDecimals
+}
+
 func (m *Manager) redistributeNodeSelectWeights() {
 	// repay all weights
-	m.weightMgr.cleanWeights()
+	for _, z := range m.zones {
+		z.weightMgr.cleanWeights()
+	}
 
 	// redistribute weights
 	m.candidateNodes.Range(func(key, value interface{}) bool {
@@ -429,9 +618,10 @@ func (m *Manager) redistributeNodeSelectWeights() {
 			return true
 		}
 
+		weightMgr := m.zoneOf(node).weightMgr
 		score := m.getNodeScoreLevel(node.NodeID)
-		wNum := m.weightMgr.getWeightNum(score)
-		node.selectWeights = m.weightMgr.distributeCandidateWeight(node.NodeID, wNum)
+		wNum := weightMgr.getWeightNum(score)
+		node.selectWeights = weightMgr.distributeCandidateWeight(node.NodeID, wNum)
 
 		return true
 	})
@@ -443,9 +633,10 @@ func (m *Manager) redistributeNodeSelectWeights() {
 			return true
 		}
 
+		weightMgr := m.zoneOf(node).weightMgr
 		score := m.getNodeScoreLevel(node.NodeID)
-		wNum := m.weightMgr.getWeightNum(score)
-		node.selectWeights = m.weightMgr.distributeEdgeWeight(node.NodeID, wNum)
+		wNum := weightMgr.getWeightNum(score)
+		node.selectWeights = weightMgr.distributeEdgeWeight(node.NodeID, wNum)
 
 		return true
 	})
@@ -484,22 +675,6 @@ func (m *Manager) UpdateNodeBandwidths(nodeID string, bandwidthDown, bandwidthUp
 		node.BandwidthUp = bandwidthUp
 	}
 }
-
-func (m *Manager) checkNodeDeactivate() {
-	nodes, err := m.LoadDeactivateNodes()
-	if err != nil {
-		log.Errorf("LoadDeactivateNodes err:%s", err.Error())
-		return
-	}
-
-	for _, nodeID := range nodes {
-		err = m.DeleteAssetRecordsOfNode(nodeID)
-		if err != nil {
-			log.Errorf("DeleteAssetOfNode err:%s", err.Error())
-		}
-	}
-}
-
 func (m *Manager) UpdateNodeDiskUsage(nodeID string, diskUsage float64) {
 	node := m.GetNode(nodeID)
 	if node == nil {
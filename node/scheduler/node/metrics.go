@@ -0,0 +1,102 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+const (
+	// hostMetricsWindowSize is the number of host metrics samples kept for each node,
+	// nodes report the metrics with every keepalive (about 10 seconds)
+	hostMetricsWindowSize = 30
+	// overload is only considered after the window holds enough samples
+	minOverloadSamples = hostMetricsWindowSize / 2
+
+	cpuOverloadThreshold         = 90.0 // percent
+	memoryOverloadThreshold      = 90.0 // percent
+	diskIOOverloadThreshold      = 90.0 // percent
+	temperatureOverloadThreshold = 85.0 // celsius
+)
+
+// hostMetricsWindow is a rolling window of the host metrics reported by a node
+type hostMetricsWindow struct {
+	lock    sync.RWMutex
+	samples []types.HostMetrics
+	next    int // position of the next sample
+	count   int // number of samples in the window
+}
+
+func newHostMetricsWindow() *hostMetricsWindow {
+	return &hostMetricsWindow{samples: make([]types.HostMetrics, hostMetricsWindowSize)}
+}
+
+// add appends a sample to the window, the oldest sample is overwritten if the window is full
+func (w *hostMetricsWindow) add(m types.HostMetrics) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.samples[w.next] = m
+	w.next = (w.next + 1) % len(w.samples)
+	if w.count < len(w.samples) {
+		w.count++
+	}
+}
+
+// average returns the average of the samples in the window and the number of samples
+func (w *hostMetricsWindow) average() (types.HostMetrics, int) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	out := types.HostMetrics{}
+	if w.count == 0 {
+		return out, 0
+	}
+
+	for i := 0; i < w.count; i++ {
+		s := w.samples[i]
+		out.CPULoad += s.CPULoad
+		out.MemoryPressure += s.MemoryPressure
+		out.DiskIOUtil += s.DiskIOUtil
+		out.Temperature += s.Temperature
+	}
+
+	n := float64(w.count)
+	out.CPULoad /= n
+	out.MemoryPressure /= n
+	out.DiskIOUtil /= n
+	out.Temperature /= n
+
+	return out, w.count
+}
+
+// isOverloaded checks if the average load of the window exceeds any threshold
+func (w *hostMetricsWindow) isOverloaded() bool {
+	avg, count := w.average()
+	if count < minOverloadSamples {
+		return false
+	}
+
+	return avg.CPULoad >= cpuOverloadThreshold ||
+		avg.MemoryPressure >= memoryOverloadThreshold ||
+		avg.DiskIOUtil >= diskIOOverloadThreshold ||
+		avg.Temperature >= temperatureOverloadThreshold
+}
+
+// UpdateNodeHostMetrics adds the host metrics reported by the node to its rolling window
+func (m *Manager) UpdateNodeHostMetrics(nodeID string, metrics *types.HostMetrics) {
+	node := m.GetNode(nodeID)
+	if node == nil || metrics == nil {
+		return
+	}
+
+	node.CPUUsage = metrics.CPULoad
+	node.MemoryUsage = metrics.MemoryPressure
+	node.hostMetrics.add(*metrics)
+	m.updateUploadState(node, metrics)
+	m.updateDiskHealth(node, metrics.DiskHealth)
+
+	if node.IsOverloaded() {
+		log.Debugf("node %s is overloaded", nodeID)
+	}
+}
@@ -3,19 +3,22 @@ package node
 import (
 	"bytes"
 	"context"
-	"crypto/rsa"
+	"crypto"
 	"encoding/gob"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"net"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/client"
 	"github.com/Filecoin-Titan/titan/api/types"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
 	"github.com/google/uuid"
 	"github.com/quic-go/quic-go"
 	"golang.org/x/xerrors"
@@ -26,6 +29,10 @@ import (
 // Node represents an Edge or Candidate node
 type Node struct {
 	NodeID string
+	// AreaID the zone of the node, one of the areas served by the scheduler
+	AreaID string
+	// Geo the area the node reports, the geo policies of the assets are matched against it
+	Geo string
 
 	*API
 	jsonrpc.ClientCloser
@@ -33,11 +40,15 @@ type Node struct {
 	token string
 
 	lastRequestTime time.Time // Node last keepalive time
+	// idleKeepalive the keepalive interval granted to the edge in the idle mode, 0 if not idle
+	idleKeepalive time.Duration
+	// inMaintenance the node is in a maintenance window of its operator
+	inMaintenance bool
 
 	selectWeights []int // The select weights assigned by the scheduler to each online node
 
 	// node info
-	PublicKey   *rsa.PublicKey
+	PublicKey   crypto.PublicKey
 	RemoteAddr  string
 	TCPPort     int
 	ExternalURL string
@@ -58,12 +69,30 @@ type Node struct {
 
 	IsPrivateMinioOnly bool
 
+	// SandboxExpiration the time the sandbox node is purged, zero if the node is not a sandbox node
+	SandboxExpiration time.Time
+
 	ExternalIP         string
 	IncomeIncr         float64
 	DiskSpace          float64
 	AvailableDiskSpace float64
 
 	PullAssetCount int
+
+	// DataProtocols the protocols the node serves the assets with
+	DataProtocols []types.TransferProtocol
+	// Version the release version the node runs, e.g. 0.1.16
+	Version string
+	// ProxyScheme the scheme of the outbound proxy the node connects through, empty if connected directly
+	ProxyScheme string
+
+	hostMetrics *hostMetricsWindow // host metrics reported with keepalive
+	control     *controlChannel    // control calls multiplexed over the polls of the node
+	upload      *uploadState       // upload limit of the node and its compliance
+	diskHealth  *types.DiskHealth  // disk health reported with keepalive
+	diskFailure string             // why the disk is predicted to fail, empty if healthy
+
+	hardwareFailed bool // the node failed its latest hardware challenge, its disk usage earns no points
 }
 
 // API represents the node API
@@ -74,19 +103,28 @@ type API struct {
 	api.Validation
 	api.DataSync
 	api.Asset
-	WaitQuiet func(ctx context.Context) error
+	WaitQuiet          func(ctx context.Context) error
+	CollectDiagnostics func(ctx context.Context, req *types.NodeDiagnosticsReq) ([]byte, error)
+	Upgrade            func(ctx context.Context, cmd *types.UpgradeCommand) error
+	ApplyNodeConfig    func(ctx context.Context, push *types.NodeConfigPush) (*types.NodeConfigAck, error)
+	StartLogStream     func(ctx context.Context, req *types.LogStreamReq) error
+	StopLogStream      func(ctx context.Context, id string) error
 	// edge api
 	ExternalServiceAddress func(ctx context.Context, candidateURL string) (string, error)
 	UserNATPunch           func(ctx context.Context, sourceURL string, req *types.NatPunchReq) error
+	SetUploadLimit         func(ctx context.Context, bytesPerSec int64) error
+	BandwidthTest          func(ctx context.Context, req *types.BandwidthTestReq) ([]*types.BandwidthTestReport, error)
+	WebRTCOffer            func(ctx context.Context, offer *types.WebRTCOffer) (*types.WebRTCAnswer, error)
 	// candidate api
 	GetBlocksOfAsset         func(ctx context.Context, assetCID string, randomSeed int64, randomCount int) ([]string, error)
 	CheckNetworkConnectivity func(ctx context.Context, network, targetURL string) error
 	GetMinioConfig           func(ctx context.Context) (*types.MinioConfig, error)
+	AddRelaySession          func(ctx context.Context, session *types.RelaySession) error
 }
 
 // New creates a new node
 func New() *Node {
-	node := &Node{}
+	node := &Node{hostMetrics: newHostMetricsWindow(), upload: &uploadState{}, control: newControlChannel()}
 
 	return node
 }
@@ -100,8 +138,16 @@ func APIFromEdge(api api.Edge) *API {
 		DataSync:               api,
 		Asset:                  api,
 		WaitQuiet:              api.WaitQuiet,
+		CollectDiagnostics:     api.CollectDiagnostics,
+		Upgrade:                api.Upgrade,
+		ApplyNodeConfig:        api.ApplyNodeConfig,
+		StartLogStream:         api.StartLogStream,
+		StopLogStream:          api.StopLogStream,
 		ExternalServiceAddress: api.ExternalServiceAddress,
 		UserNATPunch:           api.UserNATPunch,
+		SetUploadLimit:         api.SetUploadLimit,
+		BandwidthTest:          api.BandwidthTest,
+		WebRTCOffer:            api.WebRTCOffer,
 	}
 	return a
 }
@@ -115,9 +161,15 @@ func APIFromCandidate(api api.Candidate) *API {
 		DataSync:                 api,
 		Asset:                    api,
 		WaitQuiet:                api.WaitQuiet,
+		CollectDiagnostics:       api.CollectDiagnostics,
+		Upgrade:                  api.Upgrade,
+		ApplyNodeConfig:          api.ApplyNodeConfig,
+		StartLogStream:           api.StartLogStream,
+		StopLogStream:            api.StopLogStream,
 		GetBlocksOfAsset:         api.GetBlocksWithAssetCID,
 		CheckNetworkConnectivity: api.CheckNetworkConnectivity,
 		GetMinioConfig:           api.GetMinioConfig,
+		AddRelaySession:          api.AddRelaySession,
 	}
 	return a
 }
@@ -128,6 +180,8 @@ func (n *Node) ConnectRPC(transport *quic.Transport, addr string, nodeType types
 	if err != nil {
 		return err
 	}
+	// the trace of the scheduler is continued by the node
+	httpClient.Transport = &tracing.Transport{Base: httpClient.Transport}
 
 	rpcURL := fmt.Sprintf("https://%s/rpc/v0", addr)
 
@@ -173,9 +227,25 @@ func (n *Node) IsAbnormal() bool {
 		return true
 	}
 
+	// taken down for maintenance
+	if n.inMaintenance {
+		return true
+	}
+
 	return false
 }
 
+// IsOverloaded checks if the node has been overloaded for a sustained period of time
+func (n *Node) IsOverloaded() bool {
+	return n.hostMetrics.isOverloaded()
+}
+
+// HostMetrics returns the average host metrics of the node in the rolling window
+func (n *Node) HostMetrics() types.HostMetrics {
+	avg, _ := n.hostMetrics.average()
+	return avg
+}
+
 // SelectWeights get node select weights
 func (n *Node) SelectWeights() []int {
 	return n.selectWeights
@@ -193,9 +263,7 @@ func (n *Node) GetToken() string {
 
 // TCPAddr returns the tcp address of the node
 func (n *Node) TCPAddr() string {
-	index := strings.Index(n.RemoteAddr, ":")
-	ip := n.RemoteAddr[:index+1]
-	return fmt.Sprintf("%s%d", ip, n.TCPPort)
+	return net.JoinHostPort(n.remoteHost(), strconv.Itoa(n.TCPPort))
 }
 
 // RPCURL returns the rpc url of the node
@@ -207,14 +275,21 @@ func (n *Node) RPCURL() string {
 func (n *Node) DownloadAddr() string {
 	addr := n.RemoteAddr
 	if n.PortMapping != "" {
-		index := strings.Index(n.RemoteAddr, ":")
-		ip := n.RemoteAddr[:index+1]
-		addr = ip + n.PortMapping
+		addr = net.JoinHostPort(n.remoteHost(), n.PortMapping)
 	}
 
 	return addr
 }
 
+// remoteHost returns the ip of the remote address of the node, without the brackets of an ipv6 address
+func (n *Node) remoteHost() string {
+	host, _, err := net.SplitHostPort(n.RemoteAddr)
+	if err != nil {
+		return n.RemoteAddr
+	}
+	return host
+}
+
 // LastRequestTime returns the last request time of the node
 func (n *Node) LastRequestTime() time.Time {
 	return n.lastRequestTime
@@ -225,8 +300,29 @@ func (n *Node) SetLastRequestTime(t time.Time) {
 	n.lastRequestTime = t
 }
 
+// IsIdle checks if the edge is in the idle mode
+func (n *Node) IsIdle() bool {
+	return n.idleKeepalive > 0
+}
+
+// InMaintenance checks if the node is in a maintenance window
+func (n *Node) InMaintenance() bool {
+	return n.inMaintenance
+}
+
+// keepaliveTimeout returns the time the node is kept online without keepalive,
+// the idle edges send keepalive at the interval granted to them
+func (n *Node) keepaliveTimeout() time.Duration {
+	return keepaliveTime + n.idleKeepalive
+}
+
 // Token returns the token of the node
-func (n *Node) Token(cid, clientID string, titanRsa *titanrsa.Rsa, privateKey *rsa.PrivateKey) (*types.Token, *types.TokenPayload, error) {
+func (n *Node) Token(cid, clientID string, keyRing *keys.Ring) (*types.Token, *types.TokenPayload, error) {
+	return n.RangeToken(cid, clientID, 0, 0, keyRing)
+}
+
+// RangeToken returns the token of the node limited to the inclusive byte range, the whole content if end is 0
+func (n *Node) RangeToken(cid, clientID string, start, end int64, keyRing *keys.Ring) (*types.Token, *types.TokenPayload, error) {
 	tkPayload := &types.TokenPayload{
 		ID:          uuid.NewString(),
 		NodeID:      n.NodeID,
@@ -234,14 +330,16 @@ func (n *Node) Token(cid, clientID string, titanRsa *titanrsa.Rsa, privateKey *r
 		ClientID:    clientID, // TODO auth client and allocate id
 		CreatedTime: time.Now(),
 		Expiration:  time.Now().Add(10 * time.Hour),
+		RangeStart:  start,
+		RangeEnd:    end,
 	}
 
-	b, err := n.encryptTokenPayload(tkPayload, n.PublicKey, titanRsa)
+	b, err := n.encryptTokenPayload(tkPayload, n.PublicKey)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("%s encryptTokenPayload err:%s", n.NodeID, err.Error())
 	}
 
-	sign, err := titanRsa.Sign(privateKey, b)
+	sign, err := keyRing.Sign(b)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("%s Sign err:%s", n.NodeID, err.Error())
 	}
@@ -249,8 +347,9 @@ func (n *Node) Token(cid, clientID string, titanRsa *titanrsa.Rsa, privateKey *r
 	return &types.Token{ID: tkPayload.ID, CipherText: hex.EncodeToString(b), Sign: hex.EncodeToString(sign)}, tkPayload, nil
 }
 
-// encryptTokenPayload encrypts a token payload object using the given public key and RSA instance.
-func (n *Node) encryptTokenPayload(tkPayload *types.TokenPayload, publicKey *rsa.PublicKey, rsa *titanrsa.Rsa) ([]byte, error) {
+// encryptTokenPayload encrypts a token payload object using the given public key of the node,
+// the payload is not encrypted for ed25519 keys.
+func (n *Node) encryptTokenPayload(tkPayload *types.TokenPayload, publicKey crypto.PublicKey) ([]byte, error) {
 	var buffer bytes.Buffer
 	enc := gob.NewEncoder(&buffer)
 	err := enc.Encode(tkPayload)
@@ -258,7 +357,7 @@ func (n *Node) encryptTokenPayload(tkPayload *types.TokenPayload, publicKey *rsa
 		return nil, err
 	}
 
-	return rsa.Encrypt(buffer.Bytes(), publicKey)
+	return nodekey.Encrypt(publicKey, buffer.Bytes())
 }
 
 // CalculateIncome Calculate income of the node
@@ -267,19 +366,25 @@ func (n *Node) CalculateIncome(nodeCount, ipNum int) float64 {
 	mn := n.calculateMN()
 	mx := weighting(nodeCount)
 	mbn := (mb * mn * mx) / float64(ipNum)
-
-	ds := float64(n.TitanDiskUsage)
-	s := bToGB(ds * 12.5)
-
-	ms := mx * min(s, 2000) * (0.1 + float64(1/max(min(s, 2000), 10)))
+	ms := 0.0
+	if !n.hardwareFailed {
+		// the self-reported disk usage is accepted only from nodes proving their hardware
+		ms = n.calculateMs(mx)
+	}
 
 	poa := mbn + ms
 	poa = math.Round(poa*1000000) / 1000000
-	log.Debugf("calculatePoints [%s] BandwidthUp:[%d] NAT:[%d:%.2f] ipNum[%d] DiskSpace:[%.2f*12.5=%.2f GB] poa:[%.4f] mbn:[%.4f] ms:[%.4f] mx:[%.1f]", n.NodeID, n.BandwidthUp, n.NATType, mn, ipNum, n.TitanDiskUsage, s, poa, mbn, ms, mx)
+	log.Debugf("calculatePoints [%s] BandwidthUp:[%d] NAT:[%d:%.2f] ipNum[%d] DiskSpace:[%.2f] poa:[%.4f] mbn:[%.4f] ms:[%.4f] mx:[%.1f]", n.NodeID, n.BandwidthUp, n.NATType, mn, ipNum, n.TitanDiskUsage, poa, mbn, ms, mx)
 
 	return poa
 }
 
+// calculateMs returns the part of the income earned by the disk usage of the node
+func (n *Node) calculateMs(mx float64) float64 {
+	s := bToGB(n.TitanDiskUsage * 12.5)
+	return mx * min(s, 2000) * (0.1 + float64(1/max(min(s, 2000), 10)))
+}
+
 func bToGB(b float64) float64 {
 	return b / 1024 / 1024 / 1024
 }
@@ -321,6 +426,64 @@ func (n *Node) calculateMN() float64 {
 	return 0
 }
 
+// IsProxied returns whether the node connects to the scheduler through an outbound proxy, the remote address
+// of a proxied node is the address of its proxy and the node is not reachable with it
+func (n *Node) IsProxied() bool {
+	return n.ProxyScheme != ""
+}
+
+// TransferProtocols returns the protocols the clients transfer the assets with the node in order of preference,
+// the tcp server of the node is only reachable without nat
+func (n *Node) TransferProtocols() []types.TransferProtocol {
+	if len(n.DataProtocols) == 0 {
+		return []types.TransferProtocol{types.TransferProtocolHTTP3}
+	}
+
+	protocols := make([]types.TransferProtocol, 0, len(n.DataProtocols))
+	for _, protocol := range n.DataProtocols {
+		if protocol == types.TransferProtocolHTTP && n.NATType != types.NatTypeNo {
+			continue
+		}
+		protocols = append(protocols, protocol)
+	}
+
+	return protocols
+}
+
+// ServesTCPTLS reports whether the tcp server of the node serves tls, the nodes advertise the tcp protocol only then
+func (n *Node) ServesTCPTLS() bool {
+	for _, protocol := range n.DataProtocols {
+		if protocol == types.TransferProtocolHTTP {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GatewayCost returns the routing cost of the node to serve gateway requests,
+// the nodes behind stricter nat and the busy nodes cost more
+func (n *Node) GatewayCost() float64 {
+	cost := 0.0
+	switch n.NATType {
+	case types.NatTypeNo:
+	case types.NatTypeFullCone:
+		cost = 10
+	case types.NatTypeRestricted:
+		cost = 20
+	case types.NatTypePortRestricted:
+		cost = 30
+	default:
+		cost = 40
+	}
+
+	if n.IsOverloaded() {
+		cost += 100
+	}
+
+	return cost + n.HostMetrics().CPULoad
+}
+
 func min(a, b float64) float64 {
 	if a < b {
 		return a
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// a bandwidth report signed longer ago than the age is rejected as a replay
+	maxBandwidthReportAge = 10 * time.Minute
+	// the length of the last reason of the rejected reports
+	maxRejectReasonLen = 512
+)
+
+// VerifyReport verifies the signature of the report of the kind submitted by the node, an unsigned or mismatched report
+// is rejected and counted
+func (m *Manager) VerifyReport(n *Node, kind types.ReportKind, sign, data []byte) error {
+	var err error
+	if len(sign) == 0 {
+		err = xerrors.New("report is not signed")
+	} else if err = nodekey.Verify(n.PublicKey, sign, data); err != nil {
+		err = xerrors.Errorf("verify sign: %w", err)
+	}
+
+	if err != nil {
+		m.RejectReport(n.NodeID, kind, err)
+	}
+
+	return err
+}
+
+// VerifyBandwidthReport verifies the signature and the time of the bandwidth report of the node
+func (m *Manager) VerifyBandwidthReport(n *Node, report *types.BandwidthReport, now time.Time) error {
+	if err := m.VerifyReport(n, types.ReportBandwidth, report.Sign, report.SignData()); err != nil {
+		return err
+	}
+
+	if age := now.Sub(report.ReportTime); age > maxBandwidthReportAge || age < -maxBandwidthReportAge {
+		err := xerrors.Errorf("report time %s is out of range", report.ReportTime.Format(time.RFC3339))
+		m.RejectReport(n.NodeID, types.ReportBandwidth, err)
+		return err
+	}
+
+	return nil
+}
+
+// RejectReport counts the report of the kind of the node rejected for the reason
+func (m *Manager) RejectReport(nodeID string, kind types.ReportKind, reason error) {
+	log.Warnf("reject %s report of node %s: %s", kind, nodeID, reason.Error())
+
+	ctx, _ := tag.New(context.Background(), tag.Upsert(metrics.ReportKind, string(kind)))
+	stats.Record(ctx, metrics.SchedulerRejectedReports.M(1))
+
+	msg := reason.Error()
+	if len(msg) > maxRejectReasonLen {
+		msg = msg[:maxRejectReasonLen]
+	}
+
+	if err := m.IncrReportRejection(nodeID, kind, msg, time.Now()); err != nil {
+		log.Errorf("IncrReportRejection %s err:%s", nodeID, err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// sandboxPurgeInterval the interval the expired sandbox nodes are purged
+	sandboxPurgeInterval = time.Hour
+	// sandboxPurgeBatchSize the sandbox nodes loaded at once by the purge job
+	sandboxPurgeBatchSize = 500
+	// sandboxPurgeDelay the expired sandbox nodes are purged after the delay, so the schedulers
+	// they are connected to have taken them offline
+	sandboxPurgeDelay = 10 * time.Minute
+)
+
+// IsSandbox checks if the node is an ephemeral test node registered in the sandbox
+func (n *Node) IsSandbox() bool {
+	return !n.SandboxExpiration.IsZero()
+}
+
+// sandboxExpired checks if the node is a sandbox node expired at the time
+func (n *Node) sandboxExpired(t time.Time) bool {
+	return n.IsSandbox() && !t.Before(n.SandboxExpiration)
+}
+
+func (m *Manager) startSandboxPurgeTimer() {
+	ticker := time.NewTicker(sandboxPurgeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// the job is enqueued once an hour by all the schedulers
+		key := fmt.Sprintf("%s:%s", JobSandboxPurge, time.Now().UTC().Format("2006-01-02T15"))
+		if _, err := m.jobs.Enqueue(JobSandboxPurge, struct{}{}, jobqueue.Options{DedupKey: key, MaxAttempts: 3}); err != nil && err != jobqueue.ErrDuplicateJob {
+			log.Errorf("enqueue %s err:%s", JobSandboxPurge, err.Error())
+		}
+	}
+}
+
+// runSandboxPurge purges the expired sandbox nodes, their replicas are deleted first
+// so the assets are replenished on the other nodes
+func (m *Manager) runSandboxPurge(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	before := time.Now().Add(-sandboxPurgeDelay)
+
+	var done int64
+	for {
+		nodeIDs, err := m.LoadExpiredSandboxNodes(before, sandboxPurgeBatchSize)
+		if err != nil {
+			return xerrors.Errorf("LoadExpiredSandboxNodes err:%s", err.Error())
+		}
+
+		purged := 0
+		for _, nodeID := range nodeIDs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := m.purgeSandboxNode(nodeID); err != nil {
+				log.Errorf("purge sandbox node %s err:%s", nodeID, err.Error())
+				continue
+			}
+
+			purged++
+			done++
+			progress(done, done)
+		}
+
+		// the nodes left failed to purge, they are retried by the next job
+		if purged == 0 {
+			break
+		}
+	}
+
+	if done > 0 {
+		log.Infof("%d expired sandbox nodes purged", done)
+	}
+
+	return nil
+}
+
+// purgeSandboxNode deletes the sandbox node with its records, the node is taken offline first if it is online
+func (m *Manager) purgeSandboxNode(nodeID string) error {
+	if node := m.GetNode(nodeID); node != nil {
+		m.nodeKeepalive(node, time.Now())
+	}
+
+	if err := m.DeleteAssetRecordsOfNode(nodeID); err != nil {
+		return xerrors.Errorf("DeleteAssetRecordsOfNode err:%s", err.Error())
+	}
+
+	return m.PurgeSandboxNode(nodeID)
+}
@@ -0,0 +1,24 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSandboxExpired(t *testing.T) {
+	now := time.Now()
+
+	n := &Node{NodeID: "e_1"}
+	if n.IsSandbox() || n.sandboxExpired(now) {
+		t.Fatal("a node out of the sandbox never expires")
+	}
+
+	n.SandboxExpiration = now.Add(time.Hour)
+	if !n.IsSandbox() || n.sandboxExpired(now) {
+		t.Fatal("the sandbox node expires before its expiration")
+	}
+
+	if !n.sandboxExpired(now.Add(time.Hour)) {
+		t.Fatal("the sandbox node does not expire at its expiration")
+	}
+}
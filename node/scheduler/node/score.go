@@ -2,10 +2,14 @@ package node
 
 import (
 	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
 )
 
 const (
 	onlineScoreRatio = 100.0
+	// score deducted from nodes that have been overloaded for a sustained period of time
+	overloadScorePenalty = 20
 
 	scoreErr = "Invalid score"
 )
@@ -38,11 +42,51 @@ func (m *Manager) getNodeScoreLevel(nodeID string) string {
 		return scoreErr
 	}
 
-	minutes := time.Now().Sub(info.FirstTime).Minutes()
+	return m.getScoreLevel(m.NodeScore(info))
+}
+
+// NodeScore returns the score of the node (0 ~ 100) based on its online ratio,
+// the minutes in the maintenance windows of the node are not counted
+func (m *Manager) NodeScore(info *types.NodeInfo) int {
+	now := time.Now()
+	minutes := now.Sub(info.FirstTime).Minutes() - float64(m.MaintenanceMinutes(info.NodeID, info.FirstTime, now))
 	onlineRatio := float64(info.OnlineDuration) / minutes
 	if onlineRatio > 1 {
 		onlineRatio = 1
 	}
 
-	return m.getScoreLevel(int(onlineScoreRatio * onlineRatio))
+	score := int(onlineScoreRatio * onlineRatio)
+	if node := m.GetNode(info.NodeID); node != nil && node.IsOverloaded() {
+		score -= overloadScorePenalty
+		if score < 0 {
+			score = 0
+		}
+	}
+
+	return score
+}
+
+// EstimatePoints returns the points an edge node with the hypothetical parameters would earn
+// by the live scoring formula and the current size of the network
+func (m *Manager) EstimatePoints(req *types.PointsEstimateReq) *types.PointsEstimateRsp {
+	nodesOnIP := req.NodesOnIP
+	if nodesOnIP < 1 {
+		nodesOnIP = 1
+	}
+
+	n := &Node{NodeID: "estimate", BandwidthUp: req.BandwidthUp, NATType: req.NATType, TitanDiskUsage: req.TitanDiskUsage}
+
+	decimals := m.PointDecimals()
+	edges := m.TotalNetworkEdges
+	mx := weighting(edges)
+
+	return &types.PointsEstimateRsp{
+		ValidationPoints:    types.PointsFromFloat(n.CalculateIncome(edges, nodesOnIP)).Round(decimals),
+		OnlinePointsPerHour: types.PointsFromFloat(n.CalculateMCx(edges) * time.Hour.Seconds() / 5).Round(decimals),
+		BandwidthFactor:     n.calculateMb(),
+		NATFactor:           n.calculateMN(),
+		NetworkFactor:       mx,
+		NetworkEdges:        edges,
+		StoragePoints:       types.PointsFromFloat(n.calculateMs(mx)).Round(decimals),
+	}
 }
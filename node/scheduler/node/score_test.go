@@ -0,0 +1,40 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+func TestEstimatePoints(t *testing.T) {
+	m := &Manager{TotalNetworkEdges: 3000}
+	m.config = func() (config.SchedulerCfg, error) {
+		return config.SchedulerCfg{PointDecimals: 6}, nil
+	}
+
+	req := &types.PointsEstimateReq{BandwidthUp: 20 * 1024 * 1024, NATType: types.NatTypeFullCone, TitanDiskUsage: 100 * 1024 * 1024 * 1024}
+	rsp := m.EstimatePoints(req)
+
+	n := &Node{BandwidthUp: req.BandwidthUp, NATType: req.NATType, TitanDiskUsage: req.TitanDiskUsage}
+	if expected := types.PointsFromFloat(n.CalculateIncome(3000, 1)).Round(6); rsp.ValidationPoints.Cmp(expected) != 0 {
+		t.Fatalf("expected validation points %s, got %s", expected, rsp.ValidationPoints)
+	}
+
+	if rsp.NetworkFactor != 1.6 || rsp.NATFactor != 1.4 {
+		t.Fatalf("unexpected factors %.1f %.1f", rsp.NetworkFactor, rsp.NATFactor)
+	}
+
+	// the points are shared by the nodes on the same ip, the disk usage is not
+	req.NodesOnIP = 2
+	shared := m.EstimatePoints(req)
+	if shared.ValidationPoints.Cmp(rsp.ValidationPoints) >= 0 || shared.StoragePoints.Cmp(rsp.StoragePoints) != 0 {
+		t.Fatalf("unexpected shared points %s %s", shared.ValidationPoints, shared.StoragePoints)
+	}
+
+	// adding disk earns more
+	req.TitanDiskUsage *= 2
+	if more := m.EstimatePoints(req); more.ValidationPoints.Cmp(shared.ValidationPoints) <= 0 {
+		t.Fatalf("expected more points with more disk, got %s", more.ValidationPoints)
+	}
+}
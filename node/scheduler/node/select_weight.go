@@ -125,6 +125,22 @@ func (wm *weightManager) getWeightRandom(lock *sync.RWMutex, r *rand.Rand, max i
 	return distributed[w], w
 }
 
+// candidateWeights returns the number of candidate select weights
+func (wm *weightManager) candidateWeights() int {
+	wm.candidateLock.RLock()
+	defer wm.candidateLock.RUnlock()
+
+	return wm.candidateMax
+}
+
+// edgeWeights returns the number of edge select weights
+func (wm *weightManager) edgeWeights() int {
+	wm.edgeLock.RLock()
+	defer wm.edgeLock.RUnlock()
+
+	return wm.edgeMax
+}
+
 func (wm *weightManager) cleanWeights() {
 	wm.candidateLock.Lock()
 	defer wm.candidateLock.Unlock()
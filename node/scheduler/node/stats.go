@@ -0,0 +1,148 @@
+package node
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// nodeStats the values of a node counted in the region statistics
+type nodeStats struct {
+	bandwidthUp        int64
+	bandwidthDown      int64
+	diskSpace          float64
+	availableDiskSpace float64
+}
+
+func (s *nodeStats) add(o nodeStats, sign int64) {
+	s.bandwidthUp += sign * o.bandwidthUp
+	s.bandwidthDown += sign * o.bandwidthDown
+	s.diskSpace += float64(sign) * o.diskSpace
+	s.availableDiskSpace += float64(sign) * o.availableDiskSpace
+}
+
+// regionStats sums the values of the online nodes, the sums are updated by the difference
+// of a node when it goes online, keeps alive or goes offline
+type regionStats struct {
+	lk    sync.Mutex
+	total nodeStats
+	nodes map[string]nodeStats
+}
+
+func newRegionStats() *regionStats {
+	return &regionStats{nodes: make(map[string]nodeStats)}
+}
+
+// update replaces the counted values of the node with its current values,
+// a node not counted yet is only added if add is true
+func (r *regionStats) update(node *Node, add bool) {
+	current := nodeStats{
+		bandwidthUp:        node.BandwidthUp,
+		bandwidthDown:      node.BandwidthDown,
+		diskSpace:          node.DiskSpace,
+		availableDiskSpace: node.AvailableDiskSpace,
+	}
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	old, ok := r.nodes[node.NodeID]
+	if ok {
+		r.total.add(old, -1)
+	} else if !add {
+		return
+	}
+
+	r.nodes[node.NodeID] = current
+	r.total.add(current, 1)
+}
+
+// remove removes the counted values of the node
+func (r *regionStats) remove(nodeID string) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	if old, ok := r.nodes[nodeID]; ok {
+		r.total.add(old, -1)
+		delete(r.nodes, nodeID)
+	}
+}
+
+func (r *regionStats) sums() nodeStats {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	return r.total
+}
+
+// GetRegionStats returns the statistics of the region from the counters, the nodes of all zones are counted
+func (m *Manager) GetRegionStats(areaID string) (*types.RegionStats, error) {
+	replicaCount, trafficServed, err := m.LoadStatsCounters()
+	if err != nil {
+		return nil, xerrors.Errorf("LoadStatsCounters err:%s", err.Error())
+	}
+
+	var sums nodeStats
+	for _, z := range m.zones {
+		sums.add(z.stats.sums(), 1)
+	}
+
+	return &types.RegionStats{
+		AreaID:               areaID,
+		SchedulerCount:       1,
+		OnlineEdgeCount:      m.Edges,
+		OnlineCandidateCount: m.Candidates,
+		BandwidthUp:          sums.bandwidthUp,
+		BandwidthDown:        sums.bandwidthDown,
+		DiskSpace:            sums.diskSpace,
+		AvailableDiskSpace:   sums.availableDiskSpace,
+		ReplicaCount:         replicaCount,
+		TrafficServed:        trafficServed,
+	}, nil
+}
+
+// GetNodeGeoStats returns the online nodes grouped by the area they report, sorted by the number of nodes
+func (m *Manager) GetNodeGeoStats() []*types.NodeGeoStats {
+	stats := make(map[string]*types.NodeGeoStats)
+
+	count := func(key, value interface{}) bool {
+		node := value.(*Node)
+
+		s, ok := stats[node.Geo]
+		if !ok {
+			s = &types.NodeGeoStats{Geo: node.Geo}
+			stats[node.Geo] = s
+		}
+
+		if node.Type == types.NodeEdge {
+			s.Edges++
+		} else {
+			s.Candidates++
+		}
+
+		if node.IsAbnormal() {
+			s.Abnormal++
+		}
+		return true
+	}
+
+	m.edgeNodes.Range(count)
+	m.candidateNodes.Range(count)
+
+	out := make([]*types.NodeGeoStats, 0, len(stats))
+	for _, s := range stats {
+		out = append(out, s)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		ni, nj := out[i].Edges+out[i].Candidates, out[j].Edges+out[j].Candidates
+		if ni != nj {
+			return ni > nj
+		}
+		return out[i].Geo < out[j].Geo
+	})
+
+	return out
+}
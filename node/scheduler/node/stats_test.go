@@ -0,0 +1,52 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestRegionStats(t *testing.T) {
+	stats := newRegionStats()
+
+	n1 := &Node{NodeID: "e_1", BandwidthUp: 100, DiskSpace: 10}
+	n2 := &Node{NodeID: "e_2", BandwidthUp: 50, DiskSpace: 5}
+
+	stats.update(n1, true)
+	stats.update(n2, true)
+
+	n1.BandwidthUp = 200
+	stats.update(n1, false)
+
+	if sums := stats.sums(); sums.bandwidthUp != 250 || sums.diskSpace != 15 {
+		t.Fatalf("unexpected sums %+v", sums)
+	}
+
+	stats.remove(n2.NodeID)
+	// a keepalive after the node is offline does not count it again
+	stats.update(n2, false)
+
+	if sums := stats.sums(); sums.bandwidthUp != 200 || sums.diskSpace != 10 {
+		t.Fatalf("unexpected sums %+v", sums)
+	}
+}
+
+func TestNodeGeoStats(t *testing.T) {
+	m := &Manager{}
+	m.edgeNodes.Store("e_1", &Node{NodeID: "e_1", Type: types.NodeEdge, Geo: "asia-china"})
+	m.edgeNodes.Store("e_2", &Node{NodeID: "e_2", Type: types.NodeEdge, Geo: "asia-china", IsPrivateMinioOnly: true})
+	m.candidateNodes.Store("c_1", &Node{NodeID: "c_1", Type: types.NodeCandidate, Geo: "europe-germany"})
+
+	stats := m.GetNodeGeoStats()
+	if len(stats) != 2 {
+		t.Fatalf("unexpected geos %d", len(stats))
+	}
+
+	if s := stats[0]; s.Geo != "asia-china" || s.Edges != 2 || s.Candidates != 0 || s.Abnormal != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	if s := stats[1]; s.Geo != "europe-germany" || s.Candidates != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
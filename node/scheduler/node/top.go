@@ -0,0 +1,77 @@
+package node
+
+import (
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// TopNodes returns the live statistics of the online nodes sorted by the request, failures are the validation
+// failures of the nodes counted by the caller
+func (m *Manager) TopNodes(req *types.NodeTopReq, failures map[string]int64) []*types.NodeTopEntry {
+	entries := make([]*types.NodeTopEntry, 0)
+
+	add := func(key, value interface{}) bool {
+		node := value.(*Node)
+		entries = append(entries, &types.NodeTopEntry{
+			NodeID:             node.NodeID,
+			Type:               node.Type,
+			ExternalIP:         node.ExternalIP,
+			BandwidthUp:        node.BandwidthUp,
+			BandwidthDown:      node.BandwidthDown,
+			CPUUsage:           node.CPUUsage,
+			MemoryUsage:        node.MemoryUsage,
+			PointsRate:         node.IncomeIncr * 2, // IncomeIncr is the points of half an hour
+			ValidationFailures: failures[node.NodeID],
+			OnlineDuration:     node.OnlineDuration,
+			Abnormal:           node.IsAbnormal(),
+		})
+		return true
+	}
+
+	m.edgeNodes.Range(add)
+	m.candidateNodes.Range(add)
+
+	sortNodeTop(entries, req.SortBy, req.Desc)
+
+	if req.Limit > 0 && len(entries) > req.Limit {
+		entries = entries[:req.Limit]
+	}
+
+	return entries
+}
+
+// sortNodeTop sorts the entries by the value, the ties are sorted by the node id
+func sortNodeTop(entries []*types.NodeTopEntry, sortBy types.NodeTopSortBy, desc bool) {
+	value := func(e *types.NodeTopEntry) float64 {
+		switch sortBy {
+		case types.NodeTopBandwidthDown:
+			return float64(e.BandwidthDown)
+		case types.NodeTopPointsRate:
+			return e.PointsRate
+		case types.NodeTopValidationFailures:
+			return float64(e.ValidationFailures)
+		case types.NodeTopOnlineDuration:
+			return float64(e.OnlineDuration)
+		case types.NodeTopNodeID:
+			return 0
+		default:
+			return float64(e.BandwidthUp)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		vi, vj := value(entries[i]), value(entries[j])
+		if vi == vj {
+			if desc && sortBy == types.NodeTopNodeID {
+				return entries[i].NodeID > entries[j].NodeID
+			}
+			return entries[i].NodeID < entries[j].NodeID
+		}
+
+		if desc {
+			return vi > vj
+		}
+		return vi < vj
+	})
+}
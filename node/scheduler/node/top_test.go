@@ -0,0 +1,36 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestTopNodes(t *testing.T) {
+	m := &Manager{}
+	m.edgeNodes.Store("e_1", &Node{NodeID: "e_1", Type: types.NodeEdge, BandwidthUp: 100, IncomeIncr: 5})
+	m.edgeNodes.Store("e_2", &Node{NodeID: "e_2", Type: types.NodeEdge, BandwidthUp: 300, IncomeIncr: 1})
+	m.candidateNodes.Store("c_1", &Node{NodeID: "c_1", Type: types.NodeCandidate, BandwidthUp: 200})
+
+	failures := map[string]int64{"c_1": 3}
+
+	top := m.TopNodes(&types.NodeTopReq{SortBy: types.NodeTopBandwidthUp, Desc: true, Limit: 2}, failures)
+	if len(top) != 2 || top[0].NodeID != "e_2" || top[1].NodeID != "c_1" {
+		t.Fatalf("unexpected order by bandwidth %+v", top)
+	}
+
+	top = m.TopNodes(&types.NodeTopReq{SortBy: types.NodeTopPointsRate, Desc: true}, failures)
+	if top[0].NodeID != "e_1" || top[0].PointsRate != 10 {
+		t.Fatalf("unexpected order by points rate %+v", top[0])
+	}
+
+	top = m.TopNodes(&types.NodeTopReq{SortBy: types.NodeTopValidationFailures, Desc: true}, failures)
+	if top[0].NodeID != "c_1" || top[0].ValidationFailures != 3 {
+		t.Fatalf("unexpected order by failures %+v", top[0])
+	}
+
+	top = m.TopNodes(&types.NodeTopReq{SortBy: types.NodeTopNodeID}, failures)
+	if top[0].NodeID != "c_1" || top[2].NodeID != "e_2" {
+		t.Fatalf("unexpected order by node id %+v", top)
+	}
+}
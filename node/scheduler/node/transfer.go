@@ -0,0 +1,69 @@
+package node
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// transferStats counts the succeeded transfers of the nodes by protocol for diagnostics
+type transferStats struct {
+	lk    sync.Mutex
+	nodes map[string]map[types.TransferProtocol]*types.TransferProtocolStats
+}
+
+func newTransferStats() *transferStats {
+	return &transferStats{nodes: make(map[string]map[types.TransferProtocol]*types.TransferProtocolStats)}
+}
+
+func (t *transferStats) add(nodeID string, protocol types.TransferProtocol, size int64) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	protocols, ok := t.nodes[nodeID]
+	if !ok {
+		protocols = make(map[types.TransferProtocol]*types.TransferProtocolStats)
+		t.nodes[nodeID] = protocols
+	}
+
+	stats, ok := protocols[protocol]
+	if !ok {
+		stats = &types.TransferProtocolStats{Protocol: protocol}
+		protocols[protocol] = stats
+	}
+
+	stats.Count++
+	stats.Size += size
+}
+
+func (t *transferStats) get(nodeID string) []*types.TransferProtocolStats {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	out := make([]*types.TransferProtocolStats, 0, len(t.nodes[nodeID]))
+	for _, stats := range t.nodes[nodeID] {
+		s := *stats
+		out = append(out, &s)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Protocol < out[j].Protocol
+	})
+
+	return out
+}
+
+// AddTransfer counts a succeeded transfer of the node, the transfers reported without protocol are counted as http3
+func (m *Manager) AddTransfer(nodeID string, protocol types.TransferProtocol, size int64) {
+	if protocol == "" {
+		protocol = types.TransferProtocolHTTP3
+	}
+
+	m.transfers.add(nodeID, protocol, size)
+}
+
+// GetTransferStats returns the succeeded transfers of the node by protocol
+func (m *Manager) GetTransferStats(nodeID string) []*types.TransferProtocolStats {
+	return m.transfers.get(nodeID)
+}
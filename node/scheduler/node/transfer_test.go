@@ -0,0 +1,50 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestTransferStats(t *testing.T) {
+	stats := newTransferStats()
+
+	stats.add("e_1", types.TransferProtocolHTTP3, 100)
+	stats.add("e_1", types.TransferProtocolHTTP, 50)
+	stats.add("e_1", types.TransferProtocolHTTP3, 20)
+
+	out := stats.get("e_1")
+	if len(out) != 2 {
+		t.Fatalf("unexpected stats %v", out)
+	}
+
+	if out[0].Protocol != types.TransferProtocolHTTP3 || out[0].Count != 2 || out[0].Size != 120 {
+		t.Fatalf("unexpected h3 stats %+v", out[0])
+	}
+
+	if out[1].Protocol != types.TransferProtocolHTTP || out[1].Count != 1 || out[1].Size != 50 {
+		t.Fatalf("unexpected http stats %+v", out[1])
+	}
+
+	if out := stats.get("e_2"); len(out) != 0 {
+		t.Fatalf("unexpected stats %v", out)
+	}
+}
+
+func TestTransferProtocols(t *testing.T) {
+	n := &Node{NATType: types.NatTypeNo}
+	if protocols := n.TransferProtocols(); len(protocols) != 1 || protocols[0] != types.TransferProtocolHTTP3 {
+		t.Fatalf("unexpected protocols %v", protocols)
+	}
+
+	n.DataProtocols = []types.TransferProtocol{types.TransferProtocolHTTP3, types.TransferProtocolHTTP}
+	if protocols := n.TransferProtocols(); len(protocols) != 2 {
+		t.Fatalf("unexpected protocols %v", protocols)
+	}
+
+	// the tcp server behind nat is unreachable
+	n.NATType = types.NatTypeFullCone
+	if protocols := n.TransferProtocols(); len(protocols) != 1 || protocols[0] != types.TransferProtocolHTTP3 {
+		t.Fatalf("unexpected protocols %v", protocols)
+	}
+}
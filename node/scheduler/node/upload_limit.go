@@ -0,0 +1,145 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the limit is pushed again if the node still reports another limit after the interval
+	uploadLimitPushInterval = time.Minute
+	uploadLimitPushTimeout  = 10 * time.Second
+	// the upload rate averaged over a keepalive interval may exceed the limit by the bursts of the token bucket
+	uploadLimitTolerance = 1.1
+)
+
+// uploadState the upload limit the scheduler sets on the node and the values the node reports with keepalive
+type uploadState struct {
+	lk       sync.Mutex
+	limit    int64 // unit: byte per second, 0 if unlimited
+	applied  int64 // the limit in force on the node
+	rate     int64 // the upload rate of the node
+	pushTime time.Time
+}
+
+// compliant returns true if the node applies the limit and uploads within it
+func (u *uploadState) compliant() bool {
+	if u.applied != u.limit {
+		return false
+	}
+
+	return u.limit == 0 || float64(u.rate) <= float64(u.limit)*uploadLimitTolerance
+}
+
+// loadUploadLimit loads the upload limit of the node that comes online, the limit is pushed after its first keepalive
+func (m *Manager) loadUploadLimit(node *Node) {
+	limit, err := m.LoadUploadLimit(node.NodeID)
+	if err != nil {
+		log.Errorf("LoadUploadLimit %s err:%s", node.NodeID, err.Error())
+		return
+	}
+
+	if limit == nil {
+		return
+	}
+
+	node.upload.lk.Lock()
+	node.upload.limit = limit.Limit
+	node.upload.lk.Unlock()
+}
+
+// SetNodeUploadLimit sets the upload limit of the edge node, the limit is pushed to the node if it is online
+func (m *Manager) SetNodeUploadLimit(nodeID string, bytesPerSec int64) error {
+	if bytesPerSec < 0 {
+		return xerrors.Errorf("invalid upload limit %d", bytesPerSec)
+	}
+
+	if err := m.SaveUploadLimit(nodeID, bytesPerSec); err != nil {
+		return xerrors.Errorf("SaveUploadLimit err:%s", err.Error())
+	}
+
+	node := m.GetEdgeNode(nodeID)
+	if node == nil {
+		return nil
+	}
+
+	node.upload.lk.Lock()
+	node.upload.limit = bytesPerSec
+	node.upload.pushTime = time.Now()
+	node.upload.lk.Unlock()
+
+	return m.pushUploadLimit(node, bytesPerSec)
+}
+
+// GetNodeUploadLimit returns the upload limit of the node and its compliance
+func (m *Manager) GetNodeUploadLimit(nodeID string) (*types.UploadLimit, error) {
+	out, err := m.LoadUploadLimit(nodeID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadUploadLimit err:%s", err.Error())
+	}
+
+	if out == nil {
+		out = &types.UploadLimit{NodeID: nodeID}
+	}
+
+	node := m.GetEdgeNode(nodeID)
+	if node == nil {
+		return out, nil
+	}
+
+	node.upload.lk.Lock()
+	defer node.upload.lk.Unlock()
+
+	out.AppliedLimit = node.upload.applied
+	out.UploadRate = node.upload.rate
+	out.Compliant = node.upload.compliant()
+
+	return out, nil
+}
+
+// updateUploadState records the upload limit and rate reported by the edge node,
+// the limit is pushed again if the node does not apply it
+func (m *Manager) updateUploadState(node *Node, metrics *types.HostMetrics) {
+	if node.Type != types.NodeEdge {
+		return
+	}
+
+	u := node.upload
+	u.lk.Lock()
+	u.applied = metrics.UploadLimit
+	u.rate = metrics.UploadRate
+
+	if u.limit != 0 && u.applied == u.limit && !u.compliant() {
+		log.Warnf("node %s uploads %d B/s over the limit %d B/s", node.NodeID, u.rate, u.limit)
+	}
+
+	push := u.applied != u.limit && time.Since(u.pushTime) > uploadLimitPushInterval
+	if push {
+		u.pushTime = time.Now()
+	}
+	limit := u.limit
+	u.lk.Unlock()
+
+	if push {
+		go func() {
+			if err := m.pushUploadLimit(node, limit); err != nil {
+				log.Errorf("push upload limit to %s err:%s", node.NodeID, err.Error())
+			}
+		}()
+	}
+}
+
+func (m *Manager) pushUploadLimit(node *Node, bytesPerSec int64) error {
+	if node.API == nil || node.SetUploadLimit == nil {
+		return xerrors.Errorf("node %s does not support the upload limit", node.NodeID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadLimitPushTimeout)
+	defer cancel()
+
+	return node.SetUploadLimit(ctx, bytesPerSec)
+}
@@ -0,0 +1,26 @@
+package node
+
+import "testing"
+
+func TestUploadCompliant(t *testing.T) {
+	cases := []struct {
+		limit, applied, rate int64
+		compliant            bool
+	}{
+		{limit: 0, applied: 0, rate: 1 << 30, compliant: true},
+		{limit: 1000, applied: 1000, rate: 1000, compliant: true},
+		// bursts of the token bucket are tolerated
+		{limit: 1000, applied: 1000, rate: 1050, compliant: true},
+		{limit: 1000, applied: 1000, rate: 2000, compliant: false},
+		// the node has not applied the limit yet
+		{limit: 1000, applied: 0, rate: 0, compliant: false},
+		{limit: 0, applied: 1000, rate: 0, compliant: false},
+	}
+
+	for i, c := range cases {
+		u := &uploadState{limit: c.limit, applied: c.applied, rate: c.rate}
+		if u.compliant() != c.compliant {
+			t.Errorf("case %d: expected compliant %v, got %v", i, c.compliant, u.compliant())
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package node
+
+import (
+	"math/rand"
+	"sort"
+	"sync/atomic"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"golang.org/x/xerrors"
+)
+
+// zone the nodes of an area served by the scheduler, each zone has its own select weights and statistics,
+// the zones of a scheduler share its database
+type zone struct {
+	areaID     string
+	weightMgr  *weightManager
+	stats      *regionStats
+	edges      int64 // online edge node count
+	candidates int64 // online candidate node count
+}
+
+func newZone(areaID string, config dtypes.GetSchedulerConfigFunc) *zone {
+	return &zone{
+		areaID:    areaID,
+		weightMgr: newWeightManager(config),
+		stats:     newRegionStats(),
+	}
+}
+
+// newZones creates the zone of the area of the scheduler and the zones of the additional areas it serves
+func newZones(config dtypes.GetSchedulerConfigFunc) (map[string]*zone, *zone) {
+	cfg, err := config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		z := newZone("", config)
+		return map[string]*zone{"": z}, z
+	}
+
+	def := newZone(cfg.AreaID, config)
+	zones := map[string]*zone{cfg.AreaID: def}
+	for _, areaID := range cfg.Zones {
+		if _, ok := zones[areaID]; ok || areaID == "" {
+			continue
+		}
+		zones[areaID] = newZone(areaID, config)
+	}
+
+	return zones, def
+}
+
+// Zones returns the areas served by the scheduler, the area of the scheduler first
+func (m *Manager) Zones() []string {
+	areas := make([]string, 0, len(m.zones))
+	for areaID := range m.zones {
+		if areaID != m.defaultZone.areaID {
+			areas = append(areas, areaID)
+		}
+	}
+	sort.Strings(areas)
+
+	return append([]string{m.defaultZone.areaID}, areas...)
+}
+
+// ServedZone returns the zone of the area, the area of the scheduler if the area is not served by it
+func (m *Manager) ServedZone(areaID string) string {
+	return m.zone(areaID).areaID
+}
+
+func (m *Manager) zone(areaID string) *zone {
+	if z, ok := m.zones[areaID]; ok {
+		return z
+	}
+
+	return m.defaultZone
+}
+
+func (m *Manager) zoneOf(node *Node) *zone {
+	return m.zone(node.AreaID)
+}
+
+// GetZoneNodes returns the online edge and candidate nodes of the zone, the abnormal nodes are excluded
+func (m *Manager) GetZoneNodes(areaID string) ([]*Node, []*Node) {
+	z := m.zone(areaID)
+
+	edges := make([]*Node, 0)
+	for _, node := range m.GetAllEdgeNode() {
+		if m.zoneOf(node) == z {
+			edges = append(edges, node)
+		}
+	}
+
+	candidates := make([]*Node, 0)
+	_, nodes := m.GetAllValidCandidateNodes()
+	for _, node := range nodes {
+		if m.zoneOf(node) == z {
+			candidates = append(candidates, node)
+		}
+	}
+
+	return edges, candidates
+}
+
+// GetZoneStats returns the statistics of each zone of the scheduler
+func (m *Manager) GetZoneStats() ([]*types.RegionStats, error) {
+	replicaCount, trafficServed, err := m.LoadStatsCounters()
+	if err != nil {
+		return nil, xerrors.Errorf("LoadStatsCounters err:%s", err.Error())
+	}
+
+	out := make([]*types.RegionStats, 0, len(m.zones))
+	for _, areaID := range m.Zones() {
+		z := m.zones[areaID]
+		sums := z.stats.sums()
+
+		out = append(out, &types.RegionStats{
+			AreaID:               areaID,
+			SchedulerCount:       1,
+			OnlineEdgeCount:      int(atomic.LoadInt64(&z.edges)),
+			OnlineCandidateCount: int(atomic.LoadInt64(&z.candidates)),
+			BandwidthUp:          sums.bandwidthUp,
+			BandwidthDown:        sums.bandwidthDown,
+			DiskSpace:            sums.diskSpace,
+			AvailableDiskSpace:   sums.availableDiskSpace,
+			// the counters are kept per database, shared by the zones
+			ReplicaCount:  replicaCount,
+			TrafficServed: trafficServed,
+		})
+	}
+
+	return out, nil
+}
+
+// pickZone picks a zone in proportion to the select weights distributed in it, so a weight
+// is picked with the same chance in any zone
+func (m *Manager) pickZone(weights func(z *zone) int) *zone {
+	if len(m.zones) == 1 {
+		return m.defaultZone
+	}
+
+	total := 0
+	for _, z := range m.zones {
+		total += weights(z)
+	}
+
+	if total <= 0 {
+		return m.defaultZone
+	}
+
+	n := rand.Intn(total)
+	for _, z := range m.zones {
+		n -= weights(z)
+		if n < 0 {
+			return z
+		}
+	}
+
+	return m.defaultZone
+}
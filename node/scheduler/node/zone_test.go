@@ -0,0 +1,39 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+func TestZones(t *testing.T) {
+	cfgFunc := func() (config.SchedulerCfg, error) {
+		return config.SchedulerCfg{AreaID: "a1", Zones: []string{"a3", "a2", "a1", ""}}, nil
+	}
+
+	m := &Manager{}
+	m.zones, m.defaultZone = newZones(cfgFunc)
+
+	if zones := m.Zones(); len(zones) != 3 || zones[0] != "a1" || zones[1] != "a2" || zones[2] != "a3" {
+		t.Fatalf("unexpected zones %v", zones)
+	}
+
+	// the nodes of the areas not served are in the zone of the scheduler
+	if m.ServedZone("a2") != "a2" || m.ServedZone("other") != "a1" || m.ServedZone("") != "a1" {
+		t.Fatal("unexpected served zones")
+	}
+
+	m.zones["a2"].weightMgr.distributeEdgeWeight("e_1", 3)
+	m.zones["a3"].weightMgr.distributeEdgeWeight("e_2", 1)
+
+	picked := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		z := m.pickZone(func(z *zone) int { return z.weightMgr.edgeWeights() })
+		picked[z.areaID]++
+	}
+
+	// the zones are picked in proportion to their weights, 3:1
+	if picked["a1"] != 0 || picked["a2"] < 2700 || picked["a3"] < 800 {
+		t.Fatalf("unexpected picks %v", picked)
+	}
+}
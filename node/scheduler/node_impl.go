@@ -6,6 +6,7 @@ import (
 	"crypto"
 	crand "crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"encoding/gob"
 	"encoding/hex"
 	"encoding/json"
@@ -20,17 +21,33 @@ import (
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/terrors"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/multisource"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
 	"github.com/Filecoin-Titan/titan/node/handler"
-	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
-	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/xerrors"
 )
 
 const (
 	connectivityCheckTimeout = 2 * time.Second
+	// Size of the channel buffer of node event subscribers
+	nodeEventBufferSize = 100
+	// Maximum number of nodes in a page of node listing
+	listNodesMaxLimit = 100
+	// Maximum number of nodes scanned for a page of filtered node listing
+	listNodesScanLimit = 5000
+	// Maximum number of nodes returned to serve a gateway request
+	gatewayNodesLimit = 10
+	// Maximum number of edges a file is downloaded from in parallel
+	parallelDownloadLimit = 8
+	// Minimum size of the byte range assigned to an edge
+	minDownloadSegmentSize = 1 << 20
 )
 
 // GetOnlineNodeCount returns the count of online nodes for a given node type
@@ -55,8 +72,33 @@ func (s *Scheduler) GetOnlineNodeCount(ctx context.Context, nodeType types.NodeT
 	return s.NodeManager.GetOnlineNodeCount(nodeType), nil
 }
 
-// RegisterNode register node
+// GetRegionStats returns the aggregated statistics of the region of the scheduler
+func (s *Scheduler) GetRegionStats(ctx context.Context) (*types.RegionStats, error) {
+	return s.NodeManager.GetRegionStats(s.SchedulerCfg.AreaID)
+}
+
+// GetZoneStats returns the statistics of each zone served by the scheduler
+func (s *Scheduler) GetZoneStats(ctx context.Context) ([]*types.RegionStats, error) {
+	return s.NodeManager.GetZoneStats()
+}
+
+// RegisterNode register node, the node id is derived from the public key. Registering a key already registered
+// returns the existing registration instead of adding another node
 func (s *Scheduler) RegisterNode(ctx context.Context, nodeID, publicKey string, nodeType types.NodeType) (*types.ActivationDetail, error) {
+	return s.registerNode(ctx, nodeID, publicKey, nodeType, false)
+}
+
+// RegisterSandboxNode register an ephemeral test node in the sandbox mode, the node is not limited by the registrations
+// of its ip, is excluded from the settlements and is purged once its ttl elapses
+func (s *Scheduler) RegisterSandboxNode(ctx context.Context, nodeID, publicKey string, nodeType types.NodeType) (*types.ActivationDetail, error) {
+	if !s.SchedulerCfg.SandboxMode {
+		return nil, xerrors.New("the scheduler is not in the sandbox mode")
+	}
+
+	return s.registerNode(ctx, nodeID, publicKey, nodeType, true)
+}
+
+func (s *Scheduler) registerNode(ctx context.Context, nodeID, publicKey string, nodeType types.NodeType, sandbox bool) (*types.ActivationDetail, error) {
 	remoteAddr := handler.GetRemoteAddr(ctx)
 	ip, _, err := net.SplitHostPort(remoteAddr)
 	if err != nil {
@@ -64,54 +106,135 @@ func (s *Scheduler) RegisterNode(ctx context.Context, nodeID, publicKey string,
 	}
 
 	// check params
-	if nodeType != types.NodeEdge && nodeType != types.NodeCandidate {
+	prefix := ""
+	switch nodeType {
+	case types.NodeEdge:
+		prefix = "e_"
+	case types.NodeCandidate:
+		prefix = "c_"
+	default:
 		return nil, xerrors.New("invalid node type")
 	}
 
-	if nodeType == types.NodeEdge && !strings.HasPrefix(nodeID, "e_") {
-		return nil, xerrors.New("invalid edge node id")
+	if publicKey == "" {
+		return nil, xerrors.New("public key is nil")
 	}
 
-	if nodeType == types.NodeCandidate && !strings.HasPrefix(nodeID, "c_") {
-		return nil, xerrors.New("invalid candidate node id")
+	pub, err := s.parseNodePublicKey(publicKey)
+	if err != nil {
+		return nil, err
 	}
 
-	if publicKey == "" {
-		return nil, xerrors.New("public key is nil")
+	detail, err := s.registrationOfKey(pub, nodeType)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = titanrsa.Pem2PublicKey([]byte(publicKey))
+	if detail != nil {
+		expiration, err := s.db.LoadSandboxExpiration(detail.NodeID)
+		if err != nil {
+			return nil, xerrors.Errorf("LoadSandboxExpiration %w", err)
+		}
+
+		if sandbox && expiration.IsZero() {
+			return nil, xerrors.Errorf("the public key is registered by node %s out of the sandbox", detail.NodeID)
+		} else if !sandbox && !expiration.IsZero() {
+			return nil, xerrors.Errorf("the public key is registered by sandbox node %s", detail.NodeID)
+		}
+
+		log.Infof("node %s registered again with its key from %s", detail.NodeID, ip)
+		return detail, nil
+	}
+
+	keyID, err := nodekey.NodeID(prefix, pub)
 	if err != nil {
-		return nil, xerrors.Errorf("pem to publicKey err : %s", err.Error())
+		return nil, xerrors.Errorf("NodeID %w", err)
+	}
+
+	if nodeID != "" && nodeID != keyID {
+		return nil, xerrors.Errorf("node id %s is not derived from the public key, expect %s", nodeID, keyID)
 	}
 
-	if err = s.db.NodeExists(nodeID, nodeType); err == nil {
-		return nil, xerrors.Errorf("Node %s aready exist", nodeID)
+	if archived, err := s.db.IsNodeArchived(keyID); err != nil {
+		return nil, xerrors.Errorf("IsNodeArchived %w", err)
+	} else if archived {
+		return nil, xerrors.Errorf("Node %s is archived, restore it instead of registering again", keyID)
 	}
 
-	if count, err := s.db.RegisterCount(ip); err != nil {
-		return nil, xerrors.Errorf("RegisterCount %w", err)
-	} else if count >= s.SchedulerCfg.MaxNumberOfRegistrations &&
-		!isInIPWhitelist(ip, s.SchedulerCfg.IPWhitelist) {
-		return nil, xerrors.New("Registrations exceeded the number")
+	if err = s.db.NodeExists(keyID, nodeType); err == nil {
+		return nil, xerrors.Errorf("Node %s is bound to another key", keyID)
 	}
 
-	detail := &types.ActivationDetail{
-		NodeID:        nodeID,
+	if !sandbox {
+		if count, err := s.db.RegisterCount(ip); err != nil {
+			return nil, xerrors.Errorf("RegisterCount %w", err)
+		} else if count >= s.SchedulerCfg.MaxNumberOfRegistrations &&
+			!isInIPWhitelist(ip, s.SchedulerCfg.IPWhitelist) {
+			return nil, xerrors.New("Registrations exceeded the number")
+		}
+	}
+
+	pem, err := nodekey.PublicKey2Pem(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	detail = &types.ActivationDetail{
+		NodeID:        keyID,
 		AreaID:        s.SchedulerCfg.AreaID,
 		ActivationKey: newNodeKey(),
 		NodeType:      nodeType,
 		IP:            ip,
+		PublicKey:     string(pem),
+	}
+
+	if sandbox {
+		expiration := time.Now().Add(time.Duration(s.SchedulerCfg.SandboxNodeTTL) * time.Hour)
+		err = s.db.SaveSandboxNodeRegisterInfo(detail, expiration)
+	} else {
+		err = s.db.SaveNodeRegisterInfos([]*types.ActivationDetail{detail})
+	}
+
+	if err != nil {
+		// the same key may be registered concurrently
+		if existing, lErr := s.registrationOfKey(pub, nodeType); lErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, xerrors.Errorf("save registration %w", err)
+	}
+
+	if sandbox {
+		log.Infof("sandbox node %s registered from %s", detail.NodeID, ip)
+	}
+
+	return detail, nil
+}
+
+// registrationOfKey returns the registration the public key is bound to, nil if the key is not registered
+func (s *Scheduler) registrationOfKey(pub crypto.PublicKey, nodeType types.NodeType) (*types.ActivationDetail, error) {
+	pem, err := nodekey.PublicKey2Pem(pub)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = s.db.SaveNodeRegisterInfos([]*types.ActivationDetail{detail}); err != nil {
-		return nil, xerrors.Errorf("SaveNodeRegisterInfos %w", err)
+	nodeID, err := s.db.LoadNodeIDOfPublicKey(string(pem))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, xerrors.Errorf("LoadNodeIDOfPublicKey %w", err)
 	}
 
-	if err = s.db.SaveNodePublicKey(publicKey, nodeID); err != nil {
-		return nil, xerrors.Errorf("SaveNodePublicKey %w", err)
+	detail, err := s.db.LoadNodeRegisterInfo(nodeID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadNodeRegisterInfo %w", err)
 	}
 
+	if detail.NodeType != nodeType {
+		return nil, xerrors.Errorf("the public key is registered by %s node %s", detail.NodeType.String(), nodeID)
+	}
+
+	detail.AreaID = s.SchedulerCfg.AreaID
 	return detail, nil
 }
 
@@ -130,6 +253,85 @@ func (s *Scheduler) RegisterEdgeNode(ctx context.Context, nodeID, publicKey stri
 	return s.RegisterNode(ctx, nodeID, publicKey, types.NodeEdge)
 }
 
+// GetNodeKeyTypes returns the key types accepted for node identity
+func (s *Scheduler) GetNodeKeyTypes(ctx context.Context) ([]string, error) {
+	return s.nodeKeyTypes(), nil
+}
+
+// MigrateNodeKey binds the node id to a new identity key after verifying the signature of the new key by the current key,
+// the node keeps its id, points and assets
+func (s *Scheduler) MigrateNodeKey(ctx context.Context, nodeID, publicKey, sign string) (*types.ActivationDetail, error) {
+	pub, err := s.parseNodePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeType, err := s.verifyNodeSign(nodeID, sign, []byte(publicKey))
+	if err != nil {
+		return nil, xerrors.Errorf("verify sign err:%s", err.Error())
+	}
+
+	pem, err := nodekey.PublicKey2Pem(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	boundID, err := s.db.LoadNodeIDOfPublicKey(string(pem))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, xerrors.Errorf("LoadNodeIDOfPublicKey %w", err)
+	}
+
+	if boundID != "" && boundID != nodeID {
+		return nil, xerrors.Errorf("the public key is bound to node %s", boundID)
+	}
+
+	if boundID == "" {
+		if err := s.db.SaveNodePublicKey(string(pem), nodeID); err != nil {
+			return nil, xerrors.Errorf("SaveNodePublicKey %w", err)
+		}
+
+		if node := s.NodeManager.GetNode(nodeID); node != nil {
+			node.PublicKey = pub
+		}
+
+		log.Infof("%s node %s rebound to %s key", nodeType.String(), nodeID, nodekey.Type(pub))
+	}
+
+	detail, err := s.db.LoadNodeRegisterInfo(nodeID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadNodeRegisterInfo %w", err)
+	}
+	detail.AreaID = s.SchedulerCfg.AreaID
+
+	return detail, nil
+}
+
+// nodeKeyTypes returns the configured key types of node identity, rsa only if not configured
+func (s *Scheduler) nodeKeyTypes() []string {
+	if len(s.SchedulerCfg.NodeKeyTypes) == 0 {
+		return []string{nodekey.TypeRSA}
+	}
+
+	return s.SchedulerCfg.NodeKeyTypes
+}
+
+// parseNodePublicKey parses the public key pem and checks whether its type is accepted
+func (s *Scheduler) parseNodePublicKey(publicKey string) (crypto.PublicKey, error) {
+	pub, err := nodekey.Pem2PublicKey([]byte(publicKey))
+	if err != nil {
+		return nil, xerrors.Errorf("pem to publicKey err : %s", err.Error())
+	}
+
+	keyType := nodekey.Type(pub)
+	for _, t := range s.nodeKeyTypes() {
+		if t == keyType {
+			return pub, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("node key type %s is not accepted", keyType)
+}
+
 // DeactivateNode is used to deactivate a node in the titan server.
 // It stops the node from serving any requests and marks it as inactive.
 // - nodeID: The ID of the node to deactivate.
@@ -147,17 +349,9 @@ func (s *Scheduler) DeactivateNode(ctx context.Context, nodeID string, hours int
 	}
 
 	deactivateTime = time.Now().Add(time.Duration(hours) * time.Hour).Unix()
-	err = s.db.SaveDeactivateNode(nodeID, deactivateTime)
+	err = s.NodeManager.DeactivateNode(nodeID, deactivateTime)
 	if err != nil {
-		return xerrors.Errorf("SaveDeactivateNode %s err : %s", nodeID, err.Error())
-	}
-
-	node := s.NodeManager.GetNode(nodeID)
-	if node != nil {
-		node.DeactivateTime = deactivateTime
-		s.NodeManager.RepayNodeWeight(node)
-
-		// remove from validation
+		return err
 	}
 
 	err = s.db.NodeExists(nodeID, types.NodeCandidate)
@@ -243,6 +437,16 @@ func (s *Scheduler) RequestActivationCodes(ctx context.Context, nodeType types.N
 	return out, nil
 }
 
+// SetNodeUploadLimit sets the upload limit of the edge node and pushes it to the node
+func (s *Scheduler) SetNodeUploadLimit(ctx context.Context, nodeID string, bytesPerSec int64) error {
+	return s.NodeManager.SetNodeUploadLimit(nodeID, bytesPerSec)
+}
+
+// GetNodeUploadLimit returns the upload limit of the node and its compliance
+func (s *Scheduler) GetNodeUploadLimit(ctx context.Context, nodeID string) (*types.UploadLimit, error) {
+	return s.NodeManager.GetNodeUploadLimit(nodeID)
+}
+
 // UpdateNodePort sets the port for the specified node.
 func (s *Scheduler) UpdateNodePort(ctx context.Context, nodeID, port string) error {
 	node := s.NodeManager.GetNode(nodeID)
@@ -255,12 +459,18 @@ func (s *Scheduler) UpdateNodePort(ctx context.Context, nodeID, port string) err
 
 // CandidateConnect candidate node login to the scheduler
 func (s *Scheduler) CandidateConnect(ctx context.Context, opts *types.ConnectOptions) error {
-	return s.nodeConnect(ctx, opts, types.NodeCandidate)
+	ctx, span := tracing.Start(ctx, "scheduler.node_connect", attribute.String("node_id", handler.GetNodeID(ctx)), attribute.String("node_type", types.NodeCandidate.String()))
+	err := s.nodeConnect(ctx, opts, types.NodeCandidate)
+	tracing.End(span, err)
+	return err
 }
 
 // EdgeConnect edge node login to the scheduler
 func (s *Scheduler) EdgeConnect(ctx context.Context, opts *types.ConnectOptions) error {
-	return s.nodeConnect(ctx, opts, types.NodeEdge)
+	ctx, span := tracing.Start(ctx, "scheduler.node_connect", attribute.String("node_id", handler.GetNodeID(ctx)), attribute.String("node_type", types.NodeEdge.String()))
+	err := s.nodeConnect(ctx, opts, types.NodeEdge)
+	tracing.End(span, err)
+	return err
 }
 
 // GetExternalAddress retrieves the external address of the caller.
@@ -271,34 +481,83 @@ func (s *Scheduler) GetExternalAddress(ctx context.Context) (string, error) {
 
 // NodeLogin creates a new JWT token for a node.
 func (s *Scheduler) NodeLogin(ctx context.Context, nodeID, sign string) (string, error) {
-	pem, err := s.NodeManager.LoadNodePublicKey(nodeID)
-	if err != nil {
-		return "", xerrors.Errorf("%s load node public key failed: %w", nodeID, err)
+	if s.SchedulerCfg.RequireNodeLoginChallenge {
+		return "", xerrors.Errorf("node %s login without challenge is not allowed", nodeID)
 	}
 
-	nType, err := s.NodeManager.LoadNodeType(nodeID)
+	nType, err := s.verifyNodeSign(nodeID, sign, []byte(nodeID))
 	if err != nil {
+		return "", err
+	}
+
+	return s.newNodeToken(ctx, nodeID, nType, "")
+}
+
+// NodeLoginChallenge issues a one-time nonce for the login of the node, it expires after the login window.
+// The nonces issued to the callers are kept apart, requesting a challenge does not invalidate the others
+func (s *Scheduler) NodeLoginChallenge(ctx context.Context, nodeID string) (string, error) {
+	if _, err := s.NodeManager.LoadNodeType(nodeID); err != nil {
 		return "", xerrors.Errorf("%s load node type failed: %w", nodeID, err)
 	}
 
-	publicKey, err := titanrsa.Pem2PublicKey([]byte(pem))
-	if err != nil {
+	buf := make([]byte, 16)
+	if _, err := crand.Read(buf); err != nil {
 		return "", err
 	}
+	nonce := hex.EncodeToString(buf)
 
-	signBuf, err := hex.DecodeString(sign)
+	now := time.Now().Unix()
+	if err := s.NodeManager.SaveNodeLoginNonce(nodeID, nonce, now+s.nodeLoginWindow(), now); err != nil {
+		return "", xerrors.Errorf("SaveNodeLoginNonce err:%s", err.Error())
+	}
+
+	return nonce, nil
+}
+
+// NodeLoginV2 creates a new JWT token for a node that signed the login challenge, the token is bound to the host of the caller
+func (s *Scheduler) NodeLoginV2(ctx context.Context, req *types.NodeLoginReq) (string, error) {
+	if req == nil {
+		return "", xerrors.New("login request can not empty")
+	}
+
+	now := time.Now().Unix()
+	window := s.nodeLoginWindow()
+	if req.Timestamp < now-window || req.Timestamp > now+window {
+		return "", xerrors.Errorf("node %s login time %d is out of the window", req.NodeID, req.Timestamp)
+	}
+
+	nType, err := s.verifyNodeSign(req.NodeID, req.Sign, req.SignContent())
 	if err != nil {
 		return "", err
 	}
 
-	rsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
-	err = rsa.VerifySign(publicKey, signBuf, []byte(nodeID))
-	if err != nil {
+	// the nonce is used up in the database, the login can not be replayed even after the scheduler restarts
+	if err := s.NodeManager.ConsumeNodeLoginNonce(req.NodeID, req.Nonce, req.Timestamp, now); err != nil {
 		return "", err
 	}
 
+	host, _, err := net.SplitHostPort(handler.GetRemoteAddr(ctx))
+	if err != nil {
+		return "", xerrors.Errorf("SplitHostPort err:%s", err.Error())
+	}
+
+	return s.newNodeToken(ctx, req.NodeID, nType, host)
+}
+
+// nodeLoginWindow returns the login window of nodes in seconds
+func (s *Scheduler) nodeLoginWindow() int64 {
+	if s.SchedulerCfg.NodeLoginWindow <= 0 {
+		return 120
+	}
+
+	return int64(s.SchedulerCfg.NodeLoginWindow)
+}
+
+// newNodeToken creates the token of the node, the token is bound to the host if not empty
+func (s *Scheduler) newNodeToken(ctx context.Context, nodeID string, nType types.NodeType, host string) (string, error) {
 	p := types.JWTPayload{
-		ID: nodeID,
+		ID:   nodeID,
+		Host: host,
 	}
 
 	if nType == types.NodeEdge {
@@ -309,12 +568,66 @@ func (s *Scheduler) NodeLogin(ctx context.Context, nodeID, sign string) (string,
 		return "", xerrors.Errorf("Node type mismatch [%d]", nType)
 	}
 
-	tk, err := jwt.Sign(&p, s.APISecret)
+	tk, err := s.AuthNew(ctx, &p)
 	if err != nil {
 		return "", xerrors.Errorf("node %s sign err:%s", nodeID, err.Error())
 	}
 
-	return string(tk), nil
+	return tk, nil
+}
+
+// verifyNodeSign verifies the signature of data by the registered key of the node, returns the node type
+func (s *Scheduler) verifyNodeSign(nodeID, sign string, data []byte) (types.NodeType, error) {
+	pem, err := s.NodeManager.LoadNodePublicKey(nodeID)
+	if err != nil {
+		return types.NodeUnknown, xerrors.Errorf("%s load node public key failed: %w", nodeID, err)
+	}
+
+	nType, err := s.NodeManager.LoadNodeType(nodeID)
+	if err != nil {
+		return types.NodeUnknown, xerrors.Errorf("%s load node type failed: %w", nodeID, err)
+	}
+
+	publicKey, err := nodekey.Pem2PublicKey([]byte(pem))
+	if err != nil {
+		return types.NodeUnknown, err
+	}
+
+	signBuf, err := hex.DecodeString(sign)
+	if err != nil {
+		return types.NodeUnknown, err
+	}
+
+	err = nodekey.Verify(publicKey, signBuf, data)
+	if err != nil {
+		return types.NodeUnknown, err
+	}
+
+	return nType, nil
+}
+
+// IssueNodeCertificate issues a client certificate of mutual tls for the candidate
+func (s *Scheduler) IssueNodeCertificate(ctx context.Context, nodeID, sign string, csr []byte) (*types.NodeCertificate, error) {
+	nType, err := s.verifyNodeSign(nodeID, sign, csr)
+	if err != nil {
+		return nil, err
+	}
+
+	if nType != types.NodeCandidate {
+		return nil, xerrors.Errorf("node %s is not candidate", nodeID)
+	}
+
+	validity := time.Duration(s.SchedulerCfg.CandidateCertValidity) * 24 * time.Hour
+	if validity <= 0 {
+		return nil, xerrors.New("candidate certificate validity is not configured")
+	}
+
+	cert, notAfter, err := s.CertAuthority.IssueClientCertificate(nodeID, csr, validity)
+	if err != nil {
+		return nil, xerrors.Errorf("node %s issue certificate err:%s", nodeID, err.Error())
+	}
+
+	return &types.NodeCertificate{Certificate: cert, CACertificate: s.CertAuthority.CertPEM(), NotAfter: notAfter}, nil
 }
 
 // GetNodeInfo returns information about the specified node.
@@ -341,6 +654,8 @@ func (s *Scheduler) GetNodeInfo(ctx context.Context, nodeID string) (types.NodeI
 		nodeInfo.ExternalIP = node.ExternalIP
 		nodeInfo.IncomeIncr = node.IncomeIncr
 		nodeInfo.TitanDiskUsage = node.TitanDiskUsage
+		nodeInfo.ProxyScheme = node.ProxyScheme
+		nodeInfo.SandboxExpiration = node.SandboxExpiration
 
 		log.Debugf("%s node select codes:%v", nodeID, node.SelectWeights())
 	}
@@ -385,33 +700,253 @@ func (s *Scheduler) GetNodeList(ctx context.Context, offset int, limit int) (*ty
 			continue
 		}
 
-		node := s.NodeManager.GetNode(nodeInfo.NodeID)
-		if node != nil {
-			nodeInfo.Status = nodeStatus(node)
-			nodeInfo.NATType = node.NATType.String()
-			nodeInfo.Type = node.Type
-			nodeInfo.MemoryUsage = node.MemoryUsage
-			nodeInfo.CPUUsage = node.CPUUsage
-			nodeInfo.DiskUsage = node.DiskUsage
-			nodeInfo.BandwidthDown = node.BandwidthDown
-			nodeInfo.BandwidthUp = node.BandwidthUp
-			nodeInfo.ExternalIP = node.ExternalIP
-			nodeInfo.IncomeIncr = node.IncomeIncr
-			nodeInfo.TitanDiskUsage = node.TitanDiskUsage
-		}
+		s.fillOnlineNodeInfo(nodeInfo, validator)
+		nodeInfos = append(nodeInfos, *nodeInfo)
+	}
+
+	info.Data = nodeInfos
+	info.Total = total
+
+	return info, nil
+}
+
+// fillOnlineNodeInfo fills the runtime fields of the online node and marks the validator
+func (s *Scheduler) fillOnlineNodeInfo(nodeInfo *types.NodeInfo, validator map[string]struct{}) {
+	node := s.NodeManager.GetNode(nodeInfo.NodeID)
+	if node != nil {
+		nodeInfo.Status = nodeStatus(node)
+		nodeInfo.NATType = node.NATType.String()
+		nodeInfo.Type = node.Type
+		nodeInfo.MemoryUsage = node.MemoryUsage
+		nodeInfo.CPUUsage = node.CPUUsage
+		nodeInfo.DiskUsage = node.DiskUsage
+		nodeInfo.BandwidthDown = node.BandwidthDown
+		nodeInfo.BandwidthUp = node.BandwidthUp
+		nodeInfo.ExternalIP = node.ExternalIP
+		nodeInfo.IncomeIncr = node.IncomeIncr
+		nodeInfo.TitanDiskUsage = node.TitanDiskUsage
+	}
+
+	_, exist := validator[nodeInfo.NodeID]
+	if exist {
+		nodeInfo.Type = types.NodeValidator
+	}
+}
+
+// ListNodes retrieves a page of nodes matching the filters, sorted and paginated by cursor
+func (s *Scheduler) ListNodes(ctx context.Context, req *types.ListNodesReq) (*types.ListNodesCursorRsp, error) {
+	rsp := &types.ListNodesCursorRsp{Data: make([]types.NodeInfo, 0)}
+	if req == nil {
+		req = &types.ListNodesReq{}
+	}
+
+	// the region is one of the zones served by the scheduler
+	if req.Region != "" && s.NodeManager.ServedZone(req.Region) != req.Region {
+		return rsp, nil
+	}
+
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = "node_id"
+	}
+
+	if _, ok := db.NodeSortColumns[sortBy]; !ok {
+		return nil, xerrors.Errorf("invalid sort column %s", sortBy)
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > listNodesMaxLimit {
+		limit = listNodesMaxLimit
+	}
+
+	afterID, afterValue, err := decodeNodeCursor(req.Cursor, sortBy)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid cursor: %s", err.Error())
+	}
+
+	// validators are registered as candidates
+	dbType := req.Type
+	if dbType == types.NodeValidator {
+		dbType = types.NodeCandidate
+	}
+
+	validator := make(map[string]struct{})
+	validatorList, err := s.NodeManager.LoadValidators(s.NodeManager.ServerID)
+	if err != nil {
+		log.Errorf("get validator list: %v", err)
+	}
+	for _, id := range validatorList {
+		validator[id] = struct{}{}
+	}
+
+	scanned := 0
+	for scanned < listNodesScanLimit {
+		infos, err := s.NodeManager.LoadNodeInfosAfter(sortBy, req.Desc, dbType, afterValue, afterID, limit)
+		if err != nil {
+			return nil, xerrors.Errorf("LoadNodeInfosAfter err:%s", err.Error())
+		}
+
+		for i, nodeInfo := range infos {
+			scanned++
+			afterID, afterValue = nodeInfo.NodeID, nodeSortValue(nodeInfo, sortBy)
+
+			s.fillOnlineNodeInfo(nodeInfo, validator)
+			if s.matchNodeFilter(req, nodeInfo) {
+				rsp.Data = append(rsp.Data, *nodeInfo)
+			}
+
+			if len(rsp.Data) == limit {
+				if len(infos) < limit && i == len(infos)-1 {
+					return rsp, nil
+				}
+				return nextNodePage(rsp, afterID, afterValue)
+			}
+		}
+
+		if len(infos) < limit {
+			return rsp, nil
+		}
+	}
+
+	// the scan limit is reached, the caller continues from the last scanned node
+	return nextNodePage(rsp, afterID, afterValue)
+}
+
+func nextNodePage(rsp *types.ListNodesCursorRsp, nodeID string, value interface{}) (*types.ListNodesCursorRsp, error) {
+	cursor, err := encodeNodeCursor(nodeID, value)
+	if err != nil {
+		return nil, xerrors.Errorf("encode cursor err:%s", err.Error())
+	}
+
+	rsp.NextCursor = cursor
+	return rsp, nil
+}
+
+// matchNodeFilter checks the runtime filters that can not be applied by the database
+func (s *Scheduler) matchNodeFilter(req *types.ListNodesReq, nodeInfo *types.NodeInfo) bool {
+	if req.Type != types.NodeUnknown && nodeInfo.Type != req.Type {
+		return false
+	}
+
+	if len(req.Statuses) > 0 {
+		matched := false
+		for _, status := range req.Statuses {
+			if nodeInfo.Status == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if req.Region != "" && s.nodeZone(nodeInfo.NodeID) != req.Region {
+		return false
+	}
+
+	if req.NATType != "" && nodeInfo.NATType != req.NATType {
+		return false
+	}
+
+	if req.MinScore > 0 || req.MaxScore > 0 {
+		score := s.NodeManager.NodeScore(nodeInfo)
+		if score < req.MinScore || (req.MaxScore > 0 && score > req.MaxScore) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nodeZone returns the zone of the node, the zone is known while the node is online,
+// an offline node is taken as a node of the area of the scheduler
+func (s *Scheduler) nodeZone(nodeID string) string {
+	if node := s.NodeManager.GetNode(nodeID); node != nil {
+		return node.AreaID
+	}
+
+	return s.NodeManager.ServedZone("")
+}
+
+// nodeCursor the position of the last node of a page
+type nodeCursor struct {
+	NodeID string
+	Value  json.RawMessage
+}
+
+func encodeNodeCursor(nodeID string, value interface{}) (string, error) {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := json.Marshal(&nodeCursor{NodeID: nodeID, Value: v})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func decodeNodeCursor(cursor, sortBy string) (string, interface{}, error) {
+	if cursor == "" {
+		return "", nil, nil
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", nil, err
+	}
 
-		_, exist := validator[nodeInfo.NodeID]
-		if exist {
-			nodeInfo.Type = types.NodeValidator
-		}
+	c := &nodeCursor{}
+	if err := json.Unmarshal(buf, c); err != nil {
+		return "", nil, err
+	}
 
-		nodeInfos = append(nodeInfos, *nodeInfo)
+	if c.NodeID == "" {
+		return "", nil, xerrors.New("node id can not empty")
 	}
 
-	info.Data = nodeInfos
-	info.Total = total
+	var value interface{}
+	switch sortBy {
+	case "node_id":
+		return c.NodeID, nil, nil
+	case "profit":
+		// the points are compared exactly, a float would skip or repeat the nodes with close profits
+		value, err = types.ParsePoints(strings.Trim(string(c.Value), `"`))
+	case "disk_space":
+		var f float64
+		err = json.Unmarshal(c.Value, &f)
+		value = f
+	case "online_duration":
+		var i int
+		err = json.Unmarshal(c.Value, &i)
+		value = i
+	case "last_seen", "first_login_time":
+		var t time.Time
+		err = json.Unmarshal(c.Value, &t)
+		value = t
+	}
 
-	return info, nil
+	return c.NodeID, value, err
+}
+
+func nodeSortValue(nodeInfo *types.NodeInfo, sortBy string) interface{} {
+	switch sortBy {
+	case "profit":
+		return nodeInfo.Profit
+	case "disk_space":
+		return nodeInfo.DiskSpace
+	case "online_duration":
+		return nodeInfo.OnlineDuration
+	case "last_seen":
+		return nodeInfo.LastSeen
+	case "first_login_time":
+		return nodeInfo.FirstTime
+	}
+
+	return nil
 }
 
 func (s *Scheduler) GetCandidateURLsForDetectNat(ctx context.Context) ([]string, error) {
@@ -443,6 +978,27 @@ func (s *Scheduler) NatPunch(ctx context.Context, target *types.NatPunchReq) err
 
 // GetEdgeDownloadInfos finds edge download information for a given CID
 func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*types.EdgeDownloadInfoList, error) {
+	ctx, span := tracing.Start(ctx, "scheduler.route_retrieval", attribute.String("cid", cid), attribute.String("node_type", types.NodeEdge.String()))
+	list, err := s.edgeDownloadInfos(ctx, cid, "")
+	tracing.End(span, err)
+	return list, err
+}
+
+// GetClientDownloadInfos finds edge download information for a given CID with the tokens issued for the download client,
+// the receipts of the client are accepted only for its tokens
+func (s *Scheduler) GetClientDownloadInfos(ctx context.Context, cid, clientID string) (*types.EdgeDownloadInfoList, error) {
+	if !strings.HasPrefix(clientID, types.ClientIDPrefix) {
+		return nil, xerrors.Errorf("invalid client id %s", clientID)
+	}
+
+	ctx, span := tracing.Start(ctx, "scheduler.route_retrieval", attribute.String("cid", cid), attribute.String("node_type", types.NodeEdge.String()))
+	list, err := s.edgeDownloadInfos(ctx, cid, clientID)
+	tracing.End(span, err)
+	return list, err
+}
+
+// edgeDownloadInfos finds the edges holding the asset, the tokens are issued for the client if clientID is not empty
+func (s *Scheduler) edgeDownloadInfos(ctx context.Context, cid, clientID string) (*types.EdgeDownloadInfoList, error) {
 	if cid == "" {
 		return nil, xerrors.New("cids is nil")
 	}
@@ -452,14 +1008,39 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
 	}
 
-	replicas, err := s.NodeManager.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err := s.checkRetrievalDenied(hash, cid); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAssetAccess(ctx, hash, cid); err != nil {
+		return nil, err
+	}
+
+	s.ColdStorageManager.Retrieved(hash)
+
+	replicas, err := s.LocationIndex.Locations(hash)
 	if err != nil {
 		return nil, err
 	}
 
-	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	if len(replicas) == 0 {
+		if err := s.restoreColdAsset(hash, cid); err != nil {
+			return nil, err
+		}
+	}
+
+	tier, err := s.db.LoadAssetQoSTier(hash)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	rec := s.DecisionManager.Begin(types.DecisionRetrievalEdges, hash, fmt.Sprintf("replicas:%d,ratio:%.2f,tier:%s", len(replicas), s.getEdgeDownloadRatio(), tier))
+	defer rec.Commit()
+
 	infos := make([]*types.EdgeDownloadInfo, 0)
 	workloadRecords := make([]*types.WorkloadRecord, 0)
+	weights := make(map[string]int)
+	bandwidths := make(map[string]int64)
 
 	for _, rInfo := range replicas {
 		if rInfo.IsCandidate {
@@ -469,26 +1050,45 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 		nodeID := rInfo.NodeID
 		eNode := s.NodeManager.GetEdgeNode(nodeID)
 		if eNode == nil {
+			rec.Filter(nodeID, "offline", 0, 0)
+			continue
+		}
+		weight := len(eNode.SelectWeights())
+
+		// the streaming users are not routed to the busy edges
+		if tier == types.AssetQoSTierStreaming && eNode.IsOverloaded() {
+			rec.Filter(nodeID, "overloaded", weight, 0)
 			continue
 		}
 
-		if eNode.NATType == types.NatTypeSymmetric {
+		address := s.downloadAddr(eNode)
+		if address == "" {
+			rec.Filter(nodeID, "no_address", weight, 0)
 			continue
 		}
 
-		token, tkPayload, err := eNode.Token(cid, uuid.NewString(), titanRsa, s.NodeManager.PrivateKey)
+		tkClientID := clientID
+		if tkClientID == "" {
+			tkClientID = uuid.NewString()
+		}
+
+		token, tkPayload, err := eNode.Token(cid, tkClientID, s.NodeManager.KeyRing)
 		if err != nil {
+			rec.Filter(nodeID, "token_failed", weight, 0)
 			continue
 		}
+		weights[nodeID] = weight
+		bandwidths[nodeID] = eNode.BandwidthUp
 
 		workloadRecord := &types.WorkloadRecord{TokenPayload: *tkPayload, Status: types.WorkloadStatusCreate, ClientEndTime: tkPayload.Expiration.Unix()}
 		workloadRecords = append(workloadRecords, workloadRecord)
 
 		info := &types.EdgeDownloadInfo{
-			Address: eNode.DownloadAddr(),
-			NodeID:  nodeID,
-			Tk:      token,
-			NatType: eNode.NATType.String(),
+			Address:   address,
+			NodeID:    nodeID,
+			Tk:        token,
+			NatType:   eNode.NATType.String(),
+			Protocols: eNode.TransferProtocols(),
 		}
 		infos = append(infos, info)
 	}
@@ -523,7 +1123,30 @@ func (s *Scheduler) GetEdgeDownloadInfos(ctx context.Context, cid string) (*type
 		})
 	}
 
+	// the streaming users get the fastest edges first, the ratio cuts the slowest
+	if tier == types.AssetQoSTierStreaming {
+		sort.SliceStable(infos, func(i, j int) bool {
+			return bandwidths[infos[i].NodeID] > bandwidths[infos[j].NodeID]
+		})
+	}
+
+	// the edges reachable over the address family of the client first, the ratio cuts the others
+	family := types.IPFamilyOf(handler.GetRemoteAddr(ctx))
+	if family != "" {
+		sort.SliceStable(infos, func(i, j int) bool {
+			return types.IPFamilyOf(infos[i].Address) == family && types.IPFamilyOf(infos[j].Address) != family
+		})
+		s.NodeManager.AddRetrievalRoute(family, types.IPFamilyOf(infos[0].Address))
+	}
+
 	size := int(math.Ceil(float64(len(infos)) * edgeDownloadRatio))
+	for i, info := range infos {
+		if i < size {
+			rec.Choose(info.NodeID, weights[info.NodeID], float64(i))
+		} else {
+			rec.Filter(info.NodeID, "cut_by_ratio", weights[info.NodeID], float64(i))
+		}
+	}
 	infos = infos[:size]
 
 	ret := &types.EdgeDownloadInfoList{
@@ -548,6 +1171,69 @@ func (s *Scheduler) GetNodeOfIP(ctx context.Context, ip string) ([]string, error
 	return s.NodeManager.GetNodeOfIP(ip), nil
 }
 
+// GetPointsLeaderboard get the nodes with the most points
+func (s *Scheduler) GetPointsLeaderboard(ctx context.Context, limit int) ([]*types.NodePointsRank, error) {
+	if err := s.OverloadManager.Admit(ctx, "GetPointsLeaderboard"); err != nil {
+		return nil, err
+	}
+
+	return s.NodeManager.LoadPointsLeaderboard(limit)
+}
+
+// EstimatePoints estimates the points an edge node with the hypothetical bandwidth, nat and disk usage would earn
+func (s *Scheduler) EstimatePoints(ctx context.Context, req *types.PointsEstimateReq) (*types.PointsEstimateRsp, error) {
+	if req == nil {
+		return nil, xerrors.New("request can not empty")
+	}
+
+	if req.BandwidthUp < 0 || req.TitanDiskUsage < 0 {
+		return nil, xerrors.New("bandwidth and disk usage can not be negative")
+	}
+
+	return s.NodeManager.EstimatePoints(req), nil
+}
+
+// SubscribeNodeEvents subscribes the online and offline events of nodes, the channel is closed when ctx is done
+func (s *Scheduler) SubscribeNodeEvents(ctx context.Context) (<-chan *types.NodeEvent, error) {
+	subOnline := s.Notify.Sub("node_events", types.EventNodeOnline.String(), nodeEventBufferSize, eventbus.DropOldest)
+	subOffline := s.Notify.Sub("node_events", types.EventNodeOffline.String(), nodeEventBufferSize, eventbus.DropOldest)
+
+	out := make(chan *types.NodeEvent, nodeEventBufferSize)
+
+	go func() {
+		defer close(out)
+		defer s.Notify.Unsub(subOnline)
+		defer s.Notify.Unsub(subOffline)
+
+		for {
+			var event *types.NodeEvent
+			select {
+			case u, ok := <-subOnline:
+				if !ok {
+					return
+				}
+				event = &types.NodeEvent{NodeID: u.(*node.Node).NodeID, Event: types.EventNodeOnline, Time: time.Now()}
+			case u, ok := <-subOffline:
+				if !ok {
+					return
+				}
+				event = &types.NodeEvent{NodeID: u.(*node.Node).NodeID, Event: types.EventNodeOffline, Time: time.Now()}
+			case <-ctx.Done():
+				return
+			}
+
+			// never block the publisher with a slow subscriber
+			select {
+			case out <- event:
+			default:
+				log.Warnf("node event subscriber is full, drop event %s of %s", event.Event, event.NodeID)
+			}
+		}
+	}()
+
+	return out, nil
+}
+
 func (s *Scheduler) CheckIpUsage(ctx context.Context, ip string) (bool, error) {
 	if s.NodeManager.CheckIPExist(ip) {
 		return true, nil
@@ -563,28 +1249,58 @@ func (s *Scheduler) getEdgeDownloadRatio() float64 {
 
 // GetCandidateDownloadInfos finds candidate download info for the given CID.
 func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) {
+	ctx, span := tracing.Start(ctx, "scheduler.route_retrieval", attribute.String("cid", cid), attribute.String("node_type", types.NodeCandidate.String()))
+	sources, err := s.candidateDownloadInfos(ctx, cid)
+	tracing.End(span, err)
+	return sources, err
+}
+
+func (s *Scheduler) candidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) {
 	hash, err := cidutil.CIDToHash(cid)
 	if err != nil {
 		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
 	}
 
-	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	if err := s.checkRetrievalDenied(hash, cid); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAssetAccess(ctx, hash, cid); err != nil {
+		return nil, err
+	}
+
 	sources := make([]*types.CandidateDownloadInfo, 0)
 
-	replicas, err := s.db.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	s.ColdStorageManager.Retrieved(hash)
+
+	replicas, err := s.LocationIndex.Locations(hash)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(replicas) == 0 {
+		if err := s.restoreColdAsset(hash, cid); err != nil {
+			return nil, err
+		}
+	}
+
 	aInfo, err := s.db.LoadAssetRecord(hash)
 	if err != nil {
 		return nil, err
 	}
 
+	// the streaming users get the fastest nodes first
+	if aInfo.QoSTier == types.AssetQoSTierStreaming {
+		s.sortReplicasByBandwidth(replicas)
+	}
+
 	workloadRecords := make([]*types.WorkloadRecord, 0)
 
 	limit := 50
 
+	rec := s.DecisionManager.Begin(types.DecisionRetrievalCandidates, hash, fmt.Sprintf("replicas:%d,limit:%d,tier:%s", len(replicas), limit, aInfo.QoSTier))
+	defer rec.Commit()
+
 	for _, rInfo := range replicas {
 		if len(sources) > limit {
 			break
@@ -597,19 +1313,29 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 		nodeID := rInfo.NodeID
 		cNode := s.NodeManager.GetNode(nodeID)
 		if cNode == nil {
+			rec.Filter(nodeID, "offline", 0, 0)
 			continue
 		}
+		weight := len(cNode.SelectWeights())
 
 		if cNode.Type == types.NodeValidator {
+			rec.Filter(nodeID, "validator", weight, 0)
 			continue
 		}
 
 		if (cNode.NATType != types.NatTypeNo && cNode.NATType != types.NatTypeFullCone) || cNode.ExternalIP == "" {
+			rec.Filter(nodeID, "nat", weight, 0)
+			continue
+		}
+
+		if cNode.IsProxied() {
+			rec.Filter(nodeID, "proxied", weight, 0)
 			continue
 		}
 
-		token, tkPayload, err := cNode.Token(cid, uuid.NewString(), titanRsa, s.NodeManager.PrivateKey)
+		token, tkPayload, err := cNode.Token(cid, uuid.NewString(), s.NodeManager.KeyRing)
 		if err != nil {
+			rec.Filter(nodeID, "token_failed", weight, 0)
 			continue
 		}
 
@@ -621,9 +1347,20 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 			Address:   cNode.DownloadAddr(),
 			Tk:        token,
 			AWSBucket: aInfo.Note,
+			Protocols: cNode.TransferProtocols(),
 		}
 
 		sources = append(sources, source)
+		rec.Choose(nodeID, weight, 0)
+	}
+
+	// the candidates reachable over the address family of the client first
+	family := types.IPFamilyOf(handler.GetRemoteAddr(ctx))
+	if family != "" && len(sources) > 0 {
+		sort.SliceStable(sources, func(i, j int) bool {
+			return types.IPFamilyOf(sources[i].Address) == family && types.IPFamilyOf(sources[j].Address) != family
+		})
+		s.NodeManager.AddRetrievalRoute(family, types.IPFamilyOf(sources[0].Address))
 	}
 
 	if len(workloadRecords) > 0 {
@@ -635,6 +1372,224 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 	return sources, nil
 }
 
+// sortReplicasByBandwidth orders the replicas by the upload bandwidth of their online nodes, the offline nodes last
+func (s *Scheduler) sortReplicasByBandwidth(replicas []*types.ReplicaInfo) {
+	bandwidths := make(map[string]int64, len(replicas))
+	for _, r := range replicas {
+		if n := s.NodeManager.GetNode(r.NodeID); n != nil {
+			bandwidths[r.NodeID] = n.BandwidthUp
+		}
+	}
+
+	sort.SliceStable(replicas, func(i, j int) bool {
+		return bandwidths[replicas[i].NodeID] > bandwidths[replicas[j].NodeID]
+	})
+}
+
+// GetGatewayNodes finds the online nodes holding the asset, ordered by their gateway cost
+func (s *Scheduler) GetGatewayNodes(ctx context.Context, cid string, limit int) ([]*types.GatewayNode, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	if limit <= 0 || limit > gatewayNodesLimit {
+		limit = gatewayNodesLimit
+	}
+
+	replicas, err := s.LocationIndex.Locations(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		node        *node.Node
+		isCandidate bool
+		cost        float64
+	}
+
+	nodes := make([]*candidate, 0, len(replicas))
+	for _, rInfo := range replicas {
+		n := s.NodeManager.GetNode(rInfo.NodeID)
+		if n == nil || n.Type == types.NodeValidator || n.IsAbnormal() {
+			continue
+		}
+
+		// the nodes behind symmetric nat are reached through their relays
+		if s.downloadAddr(n) == "" {
+			continue
+		}
+
+		nodes = append(nodes, &candidate{node: n, isCandidate: rInfo.IsCandidate, cost: n.GatewayCost()})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].cost < nodes[j].cost
+	})
+
+	infos := make([]*types.GatewayNode, 0, limit)
+	for _, c := range nodes {
+		if len(infos) >= limit {
+			break
+		}
+
+		infos = append(infos, &types.GatewayNode{
+			NodeID:      c.node.NodeID,
+			Address:     s.downloadAddr(c.node),
+			NatType:     c.node.NATType,
+			IsCandidate: c.isCandidate,
+			Cost:        c.cost,
+			Protocols:   c.node.TransferProtocols(),
+		})
+	}
+
+	return infos, nil
+}
+
+// GetGatewayToken issues the token of the node holding the asset to serve the gateway request,
+// the token is issued only for the node the gateway requests
+func (s *Scheduler) GetGatewayToken(ctx context.Context, cid, nodeID string) (*types.Token, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	n := s.NodeManager.GetNode(nodeID)
+	if n == nil || n.Type == types.NodeValidator || n.IsAbnormal() {
+		return nil, xerrors.Errorf("node %s can not serve the gateway", nodeID)
+	}
+
+	replicas, err := s.LocationIndex.Locations(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	held := false
+	for _, rInfo := range replicas {
+		if rInfo.NodeID == nodeID {
+			held = true
+			break
+		}
+	}
+	if !held {
+		return nil, xerrors.Errorf("node %s does not hold the asset %s", nodeID, cid)
+	}
+
+	token, tkPayload, err := n.Token(cid, uuid.NewString(), s.NodeManager.KeyRing)
+	if err != nil {
+		return nil, xerrors.Errorf("node %s token err:%s", nodeID, err.Error())
+	}
+
+	workloadRecord := &types.WorkloadRecord{TokenPayload: *tkPayload, Status: types.WorkloadStatusCreate, ClientEndTime: tkPayload.Expiration.Unix()}
+	if err = s.NodeManager.SaveWorkloadRecord([]*types.WorkloadRecord{workloadRecord}); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// GetParallelDownloadPlan selects the edges holding the asset by their gateway cost and assigns them
+// the byte ranges of the file in proportion to their upload bandwidth, each token is limited to its range
+func (s *Scheduler) GetParallelDownloadPlan(ctx context.Context, req *types.ParallelDownloadReq) (*types.ParallelDownloadPlan, error) {
+	if req.Size <= 0 {
+		return nil, xerrors.New("size must be greater than 0")
+	}
+
+	hash, err := cidutil.CIDToHash(req.AssetCID)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", req.AssetCID, err.Error())
+	}
+
+	if err := s.checkRetrievalDenied(hash, req.AssetCID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAssetAccess(ctx, hash, req.AssetCID); err != nil {
+		return nil, err
+	}
+
+	parallelism := req.Parallelism
+	if parallelism <= 0 || parallelism > parallelDownloadLimit {
+		parallelism = parallelDownloadLimit
+	}
+
+	replicas, err := s.LocationIndex.Locations(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*node.Node, 0, len(replicas))
+	for _, rInfo := range replicas {
+		if rInfo.IsCandidate {
+			continue
+		}
+
+		eNode := s.NodeManager.GetEdgeNode(rInfo.NodeID)
+		if eNode == nil || eNode.IsAbnormal() || s.downloadAddr(eNode) == "" {
+			continue
+		}
+
+		edges = append(edges, eNode)
+	}
+
+	if len(edges) == 0 {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("no edge holds %s", req.AssetCID)}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].GatewayCost() < edges[j].GatewayCost()
+	})
+
+	if len(edges) > parallelism {
+		edges = edges[:parallelism]
+	}
+
+	weights := make([]int64, 0, len(edges))
+	for _, eNode := range edges {
+		weights = append(weights, eNode.BandwidthUp)
+	}
+
+	ranges := multisource.SplitRanges(req.Size, minDownloadSegmentSize, weights)
+
+	plan := &types.ParallelDownloadPlan{AssetCID: req.AssetCID, Size: req.Size, ETag: fmt.Sprintf("%q", req.AssetCID)}
+	workloadRecords := make([]*types.WorkloadRecord, 0, len(ranges))
+	clientID := uuid.NewString()
+
+	for i, r := range ranges {
+		eNode := edges[i]
+		token, tkPayload, err := eNode.RangeToken(req.AssetCID, clientID, r.Start, r.End, s.NodeManager.KeyRing)
+		if err != nil {
+			return nil, err
+		}
+
+		workloadRecords = append(workloadRecords, &types.WorkloadRecord{TokenPayload: *tkPayload, Status: types.WorkloadStatusCreate, ClientEndTime: tkPayload.Expiration.Unix()})
+		plan.Segments = append(plan.Segments, &types.DownloadSegment{
+			NodeID:    eNode.NodeID,
+			Address:   s.downloadAddr(eNode),
+			Tk:        token,
+			Start:     r.Start,
+			End:       r.End,
+			Protocols: eNode.TransferProtocols(),
+		})
+	}
+
+	if err = s.NodeManager.SaveWorkloadRecord(workloadRecords); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// GetTransferProtocolStats returns the succeeded transfers of the node by protocol since the scheduler started
+func (s *Scheduler) GetTransferProtocolStats(ctx context.Context, nodeID string) ([]*types.TransferProtocolStats, error) {
+	return s.NodeManager.GetTransferStats(nodeID), nil
+}
+
+// GetIPFamilyStats returns the online nodes and the retrievals routed since the scheduler started by address family
+func (s *Scheduler) GetIPFamilyStats(ctx context.Context) ([]*types.IPFamilyStats, error) {
+	return s.NodeManager.GetIPFamilyStats(), nil
+}
+
 // NodeExists checks if the node with the specified ID exists.
 func (s *Scheduler) NodeExists(ctx context.Context, nodeID string) error {
 	if err := s.NodeManager.NodeExists(nodeID, types.NodeEdge); err != nil {
@@ -646,6 +1601,9 @@ func (s *Scheduler) NodeExists(ctx context.Context, nodeID string) error {
 
 // NodeKeepalive candidate and edge keepalive
 func (s *Scheduler) NodeKeepalive(ctx context.Context) (uuid.UUID, error) {
+	_, span := tracing.Start(ctx, "scheduler.keepalive", attribute.String("node_id", handler.GetNodeID(ctx)))
+	defer span.End()
+
 	uuid, err := s.CommonAPI.Session(ctx)
 
 	remoteAddr := handler.GetRemoteAddr(ctx)
@@ -663,7 +1621,7 @@ func (s *Scheduler) NodeKeepalive(ctx context.Context) (uuid.UUID, error) {
 				return uuid, xerrors.Errorf("The node %s has been deactivate and cannot be logged in", nodeID)
 			}
 
-			node.SetLastRequestTime(lastTime)
+			s.NodeManager.KeepaliveNode(node, lastTime)
 		}
 	} else {
 		return uuid, xerrors.Errorf("nodeID %s or remoteAddr %s is nil", nodeID, remoteAddr)
@@ -674,6 +1632,9 @@ func (s *Scheduler) NodeKeepalive(ctx context.Context) (uuid.UUID, error) {
 
 // NodeKeepaliveV2 candidate and edge keepalive
 func (s *Scheduler) NodeKeepaliveV2(ctx context.Context) (uuid.UUID, error) {
+	_, span := tracing.Start(ctx, "scheduler.keepalive", attribute.String("node_id", handler.GetNodeID(ctx)))
+	defer span.End()
+
 	uuid, err := s.CommonAPI.Session(ctx)
 
 	remoteAddr := handler.GetRemoteAddr(ctx)
@@ -692,7 +1653,7 @@ func (s *Scheduler) NodeKeepaliveV2(ctx context.Context) (uuid.UUID, error) {
 				return uuid, &api.ErrNode{Code: int(terrors.NodeDeactivate), Message: fmt.Sprintf("The node %s has been deactivate and cannot be logged in", nodeID)}
 			}
 
-			node.SetLastRequestTime(lastTime)
+			s.NodeManager.KeepaliveNode(node, lastTime)
 		} else {
 			return uuid, &api.ErrNode{Code: int(terrors.NodeOffline), Message: fmt.Sprintf("node %s offline or not exist", nodeID)}
 		}
@@ -703,6 +1664,67 @@ func (s *Scheduler) NodeKeepaliveV2(ctx context.Context) (uuid.UUID, error) {
 	return uuid, err
 }
 
+// NodeKeepaliveV3 candidate and edge keepalive with the host metrics of the node
+func (s *Scheduler) NodeKeepaliveV3(ctx context.Context, metrics *types.HostMetrics) (uuid.UUID, error) {
+	uuid, err := s.NodeKeepaliveV2(ctx)
+	if err != nil {
+		return uuid, err
+	}
+
+	s.NodeManager.UpdateNodeHostMetrics(handler.GetNodeID(ctx), metrics)
+
+	return uuid, nil
+}
+
+// GetNodeDiskHealth returns the disk health last reported by the online node
+func (s *Scheduler) GetNodeDiskHealth(ctx context.Context, nodeID string) (*types.NodeDiskHealth, error) {
+	health := s.NodeManager.GetNodeDiskHealth(nodeID)
+	if health == nil {
+		return nil, &api.ErrNode{Code: int(terrors.NodeOffline), Message: fmt.Sprintf("node %s offline or not exist", nodeID)}
+	}
+
+	return health, nil
+}
+
+// GetNodeTasks returns the tasks not expired in the inbox of the node
+func (s *Scheduler) GetNodeTasks(ctx context.Context, nodeID string) ([]*types.NodeTask, error) {
+	return s.db.LoadNodeTasks(nodeID, time.Now())
+}
+
+// PullControlCalls waits for the control calls of the scheduler to the node
+func (s *Scheduler) PullControlCalls(ctx context.Context) ([]*types.ControlCall, error) {
+	nodeID := handler.GetNodeID(ctx)
+	node := s.NodeManager.GetNode(nodeID)
+	if node == nil {
+		return nil, &api.ErrNode{Code: int(terrors.NodeOffline), Message: fmt.Sprintf("node %s offline or not exist", nodeID)}
+	}
+
+	return node.PollControlCalls(ctx), nil
+}
+
+// SubmitControlResults delivers the results of the control calls executed by the node
+func (s *Scheduler) SubmitControlResults(ctx context.Context, results []*types.ControlResult) error {
+	nodeID := handler.GetNodeID(ctx)
+	node := s.NodeManager.GetNode(nodeID)
+	if node == nil {
+		return &api.ErrNode{Code: int(terrors.NodeOffline), Message: fmt.Sprintf("node %s offline or not exist", nodeID)}
+	}
+
+	node.CompleteControlCalls(results)
+	return nil
+}
+
+// SetIdleMode requests the idle mode for the edge without traffic, or leaves it
+func (s *Scheduler) SetIdleMode(ctx context.Context, idle bool) (*types.IdleMode, error) {
+	nodeID := handler.GetNodeID(ctx)
+	node := s.NodeManager.GetNode(nodeID)
+	if node == nil {
+		return nil, &api.ErrNode{Code: int(terrors.NodeOffline), Message: fmt.Sprintf("node %s offline or not exist", nodeID)}
+	}
+
+	return s.NodeManager.SetNodeIdle(node, idle)
+}
+
 // create a node id
 func newNodeID(nType types.NodeType) (string, error) {
 	nodeID := ""
@@ -795,14 +1817,28 @@ func (s *Scheduler) VerifyTokenWithLimitCount(ctx context.Context, token string)
 	return jwtPayload, nil
 }
 
-// UpdateBandwidths update bandwidths
-func (s *Scheduler) UpdateBandwidths(ctx context.Context, bandwidthDown, bandwidthUp int64) error {
-	// nodeID := handler.GetNodeID(ctx)
-	// s.NodeManager.UpdateNodeBandwidths(nodeID, bandwidthDown, bandwidthUp)
+// UpdateBandwidths update bandwidths, the report unsigned or mismatched is rejected
+func (s *Scheduler) UpdateBandwidths(ctx context.Context, report *types.BandwidthReport) error {
+	nodeID := handler.GetNodeID(ctx)
+	node := s.NodeManager.GetNode(nodeID)
+	if node == nil {
+		return xerrors.Errorf("node %s not exists", nodeID)
+	}
+
+	if err := s.NodeManager.VerifyBandwidthReport(node, report, time.Now()); err != nil {
+		return err
+	}
+
+	// s.NodeManager.UpdateNodeBandwidths(nodeID, report.BandwidthDown, report.BandwidthUp)
 
 	return nil
 }
 
+// GetReportRejections retrieves the reports of the node rejected for missing or mismatched signatures by kind
+func (s *Scheduler) GetReportRejections(ctx context.Context, nodeID string) ([]*types.ReportRejection, error) {
+	return s.NodeManager.LoadReportRejections(nodeID)
+}
+
 // DownloadDataResult node download data result
 func (s *Scheduler) DownloadDataResult(ctx context.Context, bucket, cid string, size int64) error {
 	nodeID := handler.GetNodeID(ctx)
@@ -967,3 +2003,8 @@ func (s *Scheduler) GetAssetsInBucket(ctx context.Context, nodeID string, bucket
 
 	return assetHashes, nil
 }
+
+// GetLocationIndexStats retrieves the asset location lookups served by the location index of the retrieval routing
+func (s *Scheduler) GetLocationIndexStats(ctx context.Context) (*types.LocationIndexStats, error) {
+	return s.LocationIndex.Stats(), nil
+}
@@ -0,0 +1,172 @@
+package nodediag
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the duration and the rate of a stream if the request does not ask for them, and the largest ones granted
+	defaultLogStreamDuration = 5 * time.Minute
+	maxLogStreamDuration     = 30 * time.Minute
+	defaultLogStreamRate     = 50
+	maxLogStreamRate         = 200
+	// the log streams open at once on the scheduler
+	maxLogStreams = 20
+	// the logs relayed to the operator are buffered, the logs over it are dropped for a slow operator
+	logStreamBufferSize  = 1000
+	logStreamCallTimeout = 10 * time.Second
+)
+
+// logStream relays the logs pushed by the node to the operator
+type logStream struct {
+	nodeID string
+
+	lk     sync.Mutex
+	closed bool
+	out    chan *types.LogEntry
+}
+
+func (st *logStream) send(entries []*types.LogEntry) error {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	if st.closed {
+		return xerrors.New("log stream is closed")
+	}
+
+	for _, entry := range entries {
+		// never block the node with a slow operator
+		select {
+		case st.out <- entry:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (st *logStream) close() {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+
+	if !st.closed {
+		st.closed = true
+		close(st.out)
+	}
+}
+
+// StreamLogs asks the online node to stream its logs filtered by the level and the subsystems of the request,
+// the logs are relayed to the returned channel which is closed when ctx is done or the duration of the request elapses
+func (m *Manager) StreamLogs(ctx context.Context, nodeID string, req *types.LogStreamReq) (<-chan *types.LogEntry, error) {
+	if req == nil {
+		return nil, xerrors.New("request can not be empty")
+	}
+
+	n := m.nodeMgr.GetNode(nodeID)
+	if n == nil {
+		return nil, xerrors.Errorf("node %s is not online", nodeID)
+	}
+
+	if _, err := logging.LevelFromString(req.Level); err != nil {
+		return nil, xerrors.Errorf("invalid log level %s", req.Level)
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	if duration <= 0 {
+		duration = defaultLogStreamDuration
+	} else if duration > maxLogStreamDuration {
+		duration = maxLogStreamDuration
+	}
+
+	limit := req.RateLimit
+	if limit <= 0 {
+		limit = defaultLogStreamRate
+	} else if limit > maxLogStreamRate {
+		limit = maxLogStreamRate
+	}
+
+	streamReq := &types.LogStreamReq{
+		ID:         uuid.NewString(),
+		Level:      req.Level,
+		Subsystems: req.Subsystems,
+		RateLimit:  limit,
+		Duration:   int(duration / time.Second),
+	}
+
+	st := &logStream{nodeID: nodeID, out: make(chan *types.LogEntry, logStreamBufferSize)}
+
+	m.streamsLk.Lock()
+	if len(m.streams) >= maxLogStreams {
+		m.streamsLk.Unlock()
+		return nil, xerrors.New("too many log streams are open, try again later")
+	}
+	m.streams[streamReq.ID] = st
+	m.streamsLk.Unlock()
+
+	callCtx, cancel := context.WithTimeout(context.Background(), logStreamCallTimeout)
+	err := n.API.StartLogStream(callCtx, streamReq)
+	cancel()
+
+	if err != nil {
+		m.closeLogStream(streamReq.ID)
+		return nil, xerrors.Errorf("start log stream of node %s: %w", nodeID, err)
+	}
+
+	go func() {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+
+		m.closeLogStream(streamReq.ID)
+
+		// the node closes the stream on its own after the duration or a rejected push
+		callCtx, cancel := context.WithTimeout(context.Background(), logStreamCallTimeout)
+		defer cancel()
+
+		if err := n.API.StopLogStream(callCtx, streamReq.ID); err != nil {
+			log.Debugf("StopLogStream %s of node %s err:%s", streamReq.ID, nodeID, err.Error())
+		}
+	}()
+
+	return st.out, nil
+}
+
+// PushLogs relays the logs of the stream pushed by the node, an error is returned if the stream is closed
+// so the node stops streaming
+func (m *Manager) PushLogs(nodeID string, batch *types.LogBatch) error {
+	if batch == nil {
+		return xerrors.New("batch can not be empty")
+	}
+
+	m.streamsLk.Lock()
+	st, ok := m.streams[batch.StreamID]
+	m.streamsLk.Unlock()
+
+	if !ok || st.nodeID != nodeID {
+		return xerrors.Errorf("log stream %s not found", batch.StreamID)
+	}
+
+	return st.send(batch.Entries)
+}
+
+func (m *Manager) closeLogStream(id string) {
+	m.streamsLk.Lock()
+	st, ok := m.streams[id]
+	delete(m.streams, id)
+	m.streamsLk.Unlock()
+
+	if ok {
+		st.close()
+	}
+}
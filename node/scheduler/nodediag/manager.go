@@ -0,0 +1,179 @@
+package nodediag
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("nodediag")
+
+const (
+	// the node collects the logs and probes the nat of the candidates, and may upload the bundle to the presigned url
+	collectTimeout = 6 * time.Minute
+	// a node can be asked once in the interval
+	minRequestInterval = 5 * time.Minute
+	// the bundles are kept for the days
+	retention     = 7 * 24 * time.Hour
+	cleanInterval = time.Hour
+	// the bundles collected at once on the scheduler, the bundles are held in memory until they are saved
+	maxCollecting = 10
+	// the largest bundle kept by the scheduler, the data column is a mediumblob
+	maxBundleSize = 16<<20 - 1
+)
+
+// Manager asks the nodes to collect the diagnostics bundles of the recent logs, the config, the nat probe results
+// and the disk stats, the bundles are kept by the scheduler for the operators or uploaded by the nodes to presigned urls.
+// It also relays the logs streamed by the nodes on demand to the operators
+type Manager struct {
+	nodeMgr *node.Manager
+	*db.SQLDB
+
+	collecting chan struct{}
+
+	streamsLk sync.Mutex
+	streams   map[string]*logStream
+}
+
+// NewManager return new node diagnostics manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager) *Manager {
+	m := &Manager{
+		nodeMgr:    nmgr,
+		SQLDB:      sdb,
+		collecting: make(chan struct{}, maxCollecting),
+		streams:    make(map[string]*logStream),
+	}
+
+	go m.startCleanTimer()
+
+	return m
+}
+
+// Request asks the online node to collect a diagnostics bundle and returns the bundle id,
+// the node uploads the bundle to the upload url if it is not empty
+func (m *Manager) Request(nodeID, uploadURL string) (string, error) {
+	n := m.nodeMgr.GetNode(nodeID)
+	if n == nil {
+		return "", xerrors.Errorf("node %s is not online", nodeID)
+	}
+
+	if uploadURL != "" {
+		u, err := url.Parse(uploadURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", xerrors.Errorf("invalid upload url %s", uploadURL)
+		}
+	}
+
+	count, err := m.CountNodeDiagnosticsSince(nodeID, time.Now().Add(-minRequestInterval))
+	if err != nil {
+		return "", err
+	}
+
+	if count > 0 {
+		return "", xerrors.Errorf("node %s can be asked for diagnostics once every %s", nodeID, minRequestInterval)
+	}
+
+	select {
+	case m.collecting <- struct{}{}:
+	default:
+		return "", xerrors.New("too many diagnostics bundles are being collected, try again later")
+	}
+
+	diag := &types.NodeDiagnostics{ID: uuid.NewString(), NodeID: nodeID, Status: types.NodeDiagnosticsCollecting, Location: uploadURL}
+	if err := m.SaveNodeDiagnostics(diag); err != nil {
+		<-m.collecting
+		return "", err
+	}
+
+	go m.collect(n, diag)
+
+	return diag.ID, nil
+}
+
+// Get returns the diagnostics bundle without its data, a bundle collecting longer than its timeout was interrupted
+// by a restart of the scheduler
+func (m *Manager) Get(id string) (*types.NodeDiagnostics, error) {
+	diag, err := m.LoadNodeDiagnostics(id)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("diagnostics %s not found", id)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if diag.Status == types.NodeDiagnosticsCollecting && time.Since(diag.CreatedTime) > 2*collectTimeout {
+		diag.Status = types.NodeDiagnosticsFailed
+		diag.Message = "interrupted"
+	}
+
+	return diag, nil
+}
+
+// GetData returns the gzipped tar of the diagnostics bundle kept by the scheduler
+func (m *Manager) GetData(id string) ([]byte, error) {
+	diag, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if diag.Status != types.NodeDiagnosticsSucceeded {
+		return nil, xerrors.Errorf("diagnostics %s is %s", id, diag.Status)
+	}
+
+	if diag.Location != "" {
+		return nil, xerrors.Errorf("diagnostics %s is uploaded to %s", id, diag.Location)
+	}
+
+	return m.LoadNodeDiagnosticsData(id)
+}
+
+func (m *Manager) collect(n *node.Node, diag *types.NodeDiagnostics) {
+	defer func() { <-m.collecting }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	data, err := n.API.CollectDiagnostics(ctx, &types.NodeDiagnosticsReq{ID: diag.ID, UploadURL: diag.Location})
+	switch {
+	case err != nil:
+		diag.Status = types.NodeDiagnosticsFailed
+		diag.Message = err.Error()
+		data = nil
+	case len(data) > maxBundleSize:
+		diag.Status = types.NodeDiagnosticsFailed
+		diag.Message = "bundle is too large, upload it to a presigned url"
+		data = nil
+	default:
+		diag.Status = types.NodeDiagnosticsSucceeded
+		diag.Size = int64(len(data))
+	}
+
+	if len(diag.Message) > 512 {
+		diag.Message = diag.Message[:512]
+	}
+
+	if err := m.UpdateNodeDiagnostics(diag, data); err != nil {
+		log.Errorf("UpdateNodeDiagnostics %s err:%s", diag.ID, err.Error())
+	}
+}
+
+func (m *Manager) startCleanTimer() {
+	ticker := time.NewTicker(cleanInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		if err := m.DeleteNodeDiagnosticsBefore(time.Now().Add(-retention)); err != nil {
+			log.Errorf("DeleteNodeDiagnosticsBefore err:%s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+)
+
+// RequestNodeDiagnostics asks the node to collect a diagnostics bundle and returns the bundle id,
+// the bundle is kept by the scheduler or uploaded by the node to the presigned url if it is not empty
+func (s *Scheduler) RequestNodeDiagnostics(ctx context.Context, nodeID, uploadURL string) (string, error) {
+	return s.NodeDiagManager.Request(nodeID, uploadURL)
+}
+
+// GetNodeDiagnostics retrieves the diagnostics bundle without its data
+func (s *Scheduler) GetNodeDiagnostics(ctx context.Context, id string) (*types.NodeDiagnostics, error) {
+	return s.NodeDiagManager.Get(id)
+}
+
+// GetNodeDiagnosticsData retrieves the gzipped tar of the diagnostics bundle kept by the scheduler
+func (s *Scheduler) GetNodeDiagnosticsData(ctx context.Context, id string) ([]byte, error) {
+	return s.NodeDiagManager.GetData(id)
+}
+
+// ListNodeDiagnostics retrieves the diagnostics bundles of the node, the latest first
+func (s *Scheduler) ListNodeDiagnostics(ctx context.Context, nodeID string, limit, offset int) (*types.ListNodeDiagnosticsRsp, error) {
+	return s.NodeDiagManager.LoadNodeDiagnosticsList(nodeID, limit, offset)
+}
+
+// StreamNodeLogs streams the logs of the online node filtered by the level and the subsystems of the request,
+// the channel is closed when ctx is done or the duration of the request elapses
+func (s *Scheduler) StreamNodeLogs(ctx context.Context, nodeID string, req *types.LogStreamReq) (<-chan *types.LogEntry, error) {
+	return s.NodeDiagManager.StreamLogs(ctx, nodeID, req)
+}
+
+// PushNodeLogs pushes the logs of a log stream of the node, an error is returned if the stream is closed
+func (s *Scheduler) PushNodeLogs(ctx context.Context, batch *types.LogBatch) error {
+	nodeID := handler.GetNodeID(ctx)
+	return s.NodeDiagManager.PushLogs(nodeID, batch)
+}
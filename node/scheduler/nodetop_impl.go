@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the seconds between the snapshots of the live view if the request does not ask for them, and the longest granted
+	defaultNodeTopInterval = 2
+	maxNodeTopInterval     = 60
+	// the nodes of a snapshot if the request does not ask for them, and the most granted
+	defaultNodeTopLimit = 50
+	maxNodeTopLimit     = 1000
+	// the validation failures counted when the view opens
+	nodeTopFailureWindow = 24 * time.Hour
+)
+
+// SubscribeNodeTop streams the snapshots of the online nodes sorted by the request, the snapshots are sent
+// once in the interval of the request and the channel is closed when ctx is done.
+// A snapshot not read before the next one is replaced by it
+func (s *Scheduler) SubscribeNodeTop(ctx context.Context, req *types.NodeTopReq) (<-chan *types.NodeTopSnapshot, error) {
+	if req == nil {
+		return nil, xerrors.New("request can not empty")
+	}
+
+	topReq := *req
+	if topReq.Interval <= 0 {
+		topReq.Interval = defaultNodeTopInterval
+	} else if topReq.Interval > maxNodeTopInterval {
+		topReq.Interval = maxNodeTopInterval
+	}
+
+	if topReq.Limit <= 0 {
+		topReq.Limit = defaultNodeTopLimit
+	} else if topReq.Limit > maxNodeTopLimit {
+		topReq.Limit = maxNodeTopLimit
+	}
+
+	failures, err := s.NodeManager.LoadValidationFailureCounts(time.Now().Add(-nodeTopFailureWindow))
+	if err != nil {
+		return nil, xerrors.Errorf("LoadValidationFailureCounts err:%s", err.Error())
+	}
+
+	subOnline := s.Notify.Sub("node_top", types.EventNodeOnline.String(), nodeEventBufferSize, eventbus.DropOldest)
+	subOffline := s.Notify.Sub("node_top", types.EventNodeOffline.String(), nodeEventBufferSize, eventbus.DropOldest)
+	subValidation := s.Notify.Sub("node_top", types.EventValidationResult.String(), nodeEventBufferSize, eventbus.DropOldest)
+
+	out := make(chan *types.NodeTopSnapshot, 1)
+
+	go func() {
+		defer close(out)
+		defer s.Notify.Unsub(subOnline)
+		defer s.Notify.Unsub(subOffline)
+		defer s.Notify.Unsub(subValidation)
+
+		ticker := time.NewTicker(time.Duration(topReq.Interval) * time.Second)
+		defer ticker.Stop()
+
+		var events []*types.NodeEvent
+		send := func() {
+			snapshot := &types.NodeTopSnapshot{
+				Time:             time.Now(),
+				OnlineEdges:      s.NodeManager.Edges,
+				OnlineCandidates: s.NodeManager.Candidates,
+				Nodes:            s.NodeManager.TopNodes(&topReq, failures),
+				Events:           events,
+			}
+			events = nil
+
+			// replace the snapshot the subscriber has not read yet, its events are kept
+			select {
+			case old := <-out:
+				snapshot.Events = append(old.Events, snapshot.Events...)
+			default:
+			}
+			out <- snapshot
+		}
+
+		send()
+
+		for {
+			select {
+			case u, ok := <-subOnline:
+				if !ok {
+					return
+				}
+				events = appendNodeEvent(events, &types.NodeEvent{NodeID: u.(*node.Node).NodeID, Event: types.EventNodeOnline, Time: time.Now()})
+			case u, ok := <-subOffline:
+				if !ok {
+					return
+				}
+				events = appendNodeEvent(events, &types.NodeEvent{NodeID: u.(*node.Node).NodeID, Event: types.EventNodeOffline, Time: time.Now()})
+			case u, ok := <-subValidation:
+				if !ok {
+					return
+				}
+				if info := u.(*types.ValidationResultInfo); isNodeValidationFailure(info.Status) {
+					failures[info.NodeID]++
+				}
+			case <-ticker.C:
+				send()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// appendNodeEvent keeps the latest events of a snapshot
+func appendNodeEvent(events []*types.NodeEvent, event *types.NodeEvent) []*types.NodeEvent {
+	if len(events) >= nodeEventBufferSize {
+		events = events[1:]
+	}
+	return append(events, event)
+}
+
+// isNodeValidationFailure checks if the node failed the validation, the validations failed by the validator
+// or the scheduler do not count against the node
+func isNodeValidationFailure(status types.ValidationStatus) bool {
+	switch status {
+	case types.ValidationStatusNodeTimeOut, types.ValidationStatusValidateFail, types.ValidationStatusNodeOffline:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// GetNodeOperations assembles the operations in flight assigned to the node from the managers
+func (s *Scheduler) GetNodeOperations(ctx context.Context, nodeID string) (*types.NodeOperations, error) {
+	ops := &types.NodeOperations{NodeID: nodeID}
+
+	if n := s.NodeManager.GetNode(nodeID); n != nil {
+		ops.Online = true
+		ops.ControlCalls = n.ControlCalls()
+	}
+
+	var err error
+	if ops.PendingPulls, err = s.AssetManager.PendingPullsOfNode(nodeID); err != nil {
+		return nil, xerrors.Errorf("load pending pulls: %w", err)
+	}
+
+	if ops.ActiveValidations, err = s.ValidationMgr.ActiveValidations(nodeID); err != nil {
+		return nil, xerrors.Errorf("load active validations: %w", err)
+	}
+
+	if ops.QueuedTasks, err = s.NodeManager.LoadNodeTasks(nodeID, time.Now()); err != nil {
+		return nil, xerrors.Errorf("load queued tasks: %w", err)
+	}
+
+	ops.RelaySessions = s.RelayManager.SessionsOfNode(nodeID)
+
+	return ops, nil
+}
@@ -0,0 +1,253 @@
+package outbox
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("outbox")
+
+const (
+	batchSize        = 100
+	dispatchInterval = time.Second
+	maxBackoff       = time.Minute
+	pruneInterval    = 10 * time.Minute
+)
+
+// Manager delivers the events of the outbox to the configured sinks in the order they were written,
+// a batch is marked delivered after all the sinks accept it and retried with backoff otherwise,
+// so the consumers receive every event at least once
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	*db.SQLDB
+
+	lk       sync.Mutex
+	sinkURLs []string
+	sinks    []sink
+
+	pending  int64
+	failures int
+}
+
+// NewManager return new outbox manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager) *Manager {
+	m := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		SQLDB:         sdb,
+	}
+
+	diagnostics.RegisterSize("outbox.pending", func() int { return int(atomic.LoadInt64(&m.pending)) })
+
+	go m.startDispatcher()
+	go m.startPruneTimer()
+
+	return m
+}
+
+func (m *Manager) startDispatcher() {
+	t := diagnostics.NewTimer("outbox.dispatch", dispatchInterval)
+
+	for {
+		done := t.Start()
+		delay := m.dispatch(context.Background())
+		done()
+
+		time.Sleep(delay)
+	}
+}
+
+// dispatch delivers a batch of the pending events and returns the time to wait before the next batch
+func (m *Manager) dispatch(ctx context.Context) time.Duration {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return dispatchInterval
+	}
+
+	sinks := m.loadSinks()
+	if len(sinks) == 0 {
+		return dispatchInterval
+	}
+
+	return m.deliver(ctx, sinks)
+}
+
+// deliver delivers a batch of the pending events to the sinks
+func (m *Manager) deliver(ctx context.Context, sinks []sink) time.Duration {
+	events, err := m.LoadPendingOutboxEvents(batchSize)
+	if err != nil {
+		log.Errorf("LoadPendingOutboxEvents err:%s", err.Error())
+		return dispatchInterval
+	}
+
+	if len(events) == 0 {
+		atomic.StoreInt64(&m.pending, 0)
+		return dispatchInterval
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		event.Version = types.OutboxSchemaVersion
+		ids = append(ids, event.ID)
+	}
+
+	if err = m.send(ctx, sinks, events); err != nil {
+		m.failures++
+		log.Errorf("deliver outbox events %d-%d err:%s, attempts %d", ids[0], ids[len(ids)-1], err.Error(), events[0].Attempts+1)
+
+		if err := m.IncrOutboxEventAttempts(ids); err != nil {
+			log.Errorf("IncrOutboxEventAttempts err:%s", err.Error())
+		}
+
+		m.updatePending(len(events))
+		return m.backoff()
+	}
+	m.failures = 0
+
+	if err = m.MarkOutboxEventsDispatched(ids); err != nil {
+		// the batch is delivered again, the consumers deduplicate by the event id
+		log.Errorf("MarkOutboxEventsDispatched err:%s", err.Error())
+		return dispatchInterval
+	}
+
+	if len(events) < batchSize {
+		atomic.StoreInt64(&m.pending, 0)
+		return dispatchInterval
+	}
+
+	// more events are pending
+	m.updatePending(0)
+	return 0
+}
+
+func (m *Manager) send(ctx context.Context, sinks []sink, events []*types.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	for _, s := range sinks {
+		if err := s.send(ctx, events); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) updatePending(loaded int) {
+	if loaded > 0 && loaded < batchSize {
+		atomic.StoreInt64(&m.pending, int64(loaded))
+		return
+	}
+
+	count, err := m.CountPendingOutboxEvents()
+	if err != nil {
+		log.Errorf("CountPendingOutboxEvents err:%s", err.Error())
+		return
+	}
+
+	atomic.StoreInt64(&m.pending, int64(count))
+}
+
+// backoff returns the delay before retrying a failed batch, doubled with each consecutive failure
+func (m *Manager) backoff() time.Duration {
+	delay := dispatchInterval
+	for i := 1; i < m.failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+// loadSinks returns the sinks of the config, they are reopened when the config changes
+func (m *Manager) loadSinks() []sink {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return nil
+	}
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	if reflect.DeepEqual(cfg.OutboxSinks, m.sinkURLs) {
+		return m.sinks
+	}
+
+	for _, s := range m.sinks {
+		if err := s.close(); err != nil {
+			log.Warnf("close outbox sink err:%s", err.Error())
+		}
+	}
+	m.sinks = nil
+	m.sinkURLs = nil
+
+	sinks := make([]sink, 0, len(cfg.OutboxSinks))
+	for _, u := range cfg.OutboxSinks {
+		s, err := newSink(u)
+		if err != nil {
+			// no event is delivered until all the sinks are available, the sinks are opened again on the next dispatch
+			log.Errorf("open outbox sink %s err:%s", u, err.Error())
+			for _, opened := range sinks {
+				opened.close() //nolint:errcheck
+			}
+			return nil
+		}
+
+		sinks = append(sinks, s)
+	}
+
+	m.sinks = sinks
+	m.sinkURLs = cfg.OutboxSinks
+
+	return m.sinks
+}
+
+func (m *Manager) startPruneTimer() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("outbox.prune", pruneInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.prune(time.Now())
+		done()
+	}
+}
+
+// prune deletes the delivered events older than the retention, the undelivered events are also deleted if no sink is configured
+func (m *Manager) prune(now time.Time) {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	if cfg.OutboxRetentionDays <= 0 || !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	deleted, err := m.DeleteOutboxEventsBefore(now.AddDate(0, 0, -cfg.OutboxRetentionDays), len(cfg.OutboxSinks) == 0)
+	if err != nil {
+		log.Errorf("DeleteOutboxEventsBefore err:%s", err.Error())
+		return
+	}
+
+	if deleted > 0 {
+		log.Infof("pruned %d outbox events", deleted)
+	}
+}
@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/harness"
+)
+
+func TestDeliver(t *testing.T) {
+	sdb, closeDB, err := harness.NewDB("test-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+
+	var lk sync.Mutex
+	var received []*types.OutboxEvent
+	fail := true
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		defer lk.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var events []*types.OutboxEvent
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, events...)
+	}))
+	defer srv.Close()
+
+	if err = sdb.SaveNodeInfo(&types.NodeInfo{NodeDynamicInfo: types.NodeDynamicInfo{NodeID: "e_1", LastSeen: time.Now()}, SchedulerID: "test-scheduler"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = sdb.SaveNodeOffline("e_1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newSink(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{SQLDB: sdb}
+
+	if delay := m.deliver(context.Background(), []sink{s}); delay != dispatchInterval {
+		t.Errorf("expected the first retry after %s, got %s", dispatchInterval, delay)
+	}
+
+	pending, err := sdb.LoadPendingOutboxEvents(batchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].Attempts != 1 {
+		t.Fatalf("expected 2 pending events after the failed delivery, got %d", len(pending))
+	}
+
+	lk.Lock()
+	fail = false
+	lk.Unlock()
+
+	m.deliver(context.Background(), []sink{s})
+
+	lk.Lock()
+	defer lk.Unlock()
+
+	if len(received) != 2 || received[0].Topic != types.OutboxNodeOnline || received[1].Topic != types.OutboxNodeOffline {
+		t.Fatalf("unexpected delivered events %v", received)
+	}
+
+	var event types.OutboxNodePayload
+	if err = json.Unmarshal(received[1].Payload, &event); err != nil || event.NodeID != "e_1" || received[1].Version != types.OutboxSchemaVersion {
+		t.Errorf("unexpected payload %s", received[1].Payload)
+	}
+
+	if count, err := sdb.CountPendingOutboxEvents(); err != nil || count != 0 {
+		t.Errorf("expected no pending event, got %d %v", count, err)
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	for _, u := range []string{"ftp://host/path", "kafka://broker:9092", "nats://host:4222"} {
+		if _, err := newSink(u); err == nil {
+			t.Errorf("expected an error for %s", u)
+		}
+	}
+}
+
+func TestFilteredSink(t *testing.T) {
+	var received []*types.OutboxEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []*types.OutboxEvent
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, events...)
+	}))
+	defer srv.Close()
+
+	s, err := newSink(srv.URL + "?topics=node.,validation.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []*types.OutboxEvent{
+		{ID: 1, Topic: types.OutboxNodeOffline},
+		{ID: 2, Topic: types.OutboxAssetState},
+		{ID: 3, Topic: types.OutboxValidationResult},
+	}
+	if err = s.send(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 2 || received[0].ID != 1 || received[1].ID != 3 {
+		t.Fatalf("unexpected delivered events %v", received)
+	}
+
+	if err = s.send(context.Background(), events[1:2]); err != nil || len(received) != 2 {
+		t.Fatalf("expected the asset event to be filtered out")
+	}
+}
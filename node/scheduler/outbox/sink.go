@@ -0,0 +1,229 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"golang.org/x/xerrors"
+)
+
+const sendTimeout = 30 * time.Second
+
+// sink delivers the events to an external consumer, a batch is delivered in full or not at all
+type sink interface {
+	send(ctx context.Context, events []*types.OutboxEvent) error
+	close() error
+}
+
+// topicPlaceholder is replaced by the topic of the event in the kafka topic
+const topicPlaceholder = "{topic}"
+
+// newSink returns the sink of the url by its scheme:
+// http(s)://host/path posts the events as a json array to the webhook,
+// kafka://broker1:9092,broker2:9092/topic writes the events keyed by their subject to the kafka topic,
+// {topic} in the kafka topic is replaced by the topic of the event, e.g. kafka://broker:9092/titan.{topic},
+// nats://host:4222/prefix publishes the events to the jetstream subjects prefix.<topic> with the event id as the message id.
+// The query parameter topics limits the sink to the events whose topic starts with one of the comma separated prefixes,
+// e.g. ?topics=node.,validation.
+func newSink(sinkURL string) (sink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	query := u.Query()
+	if topics := query.Get("topics"); topics != "" {
+		prefixes = strings.Split(topics, ",")
+	}
+	query.Del("topics")
+	u.RawQuery = query.Encode()
+
+	s, err := newSchemeSink(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prefixes) > 0 {
+		return &filteredSink{sink: s, prefixes: prefixes}, nil
+	}
+
+	return s, nil
+}
+
+func newSchemeSink(u *url.URL) (sink, error) {
+	sinkURL := u.String()
+
+	switch u.Scheme {
+	case "http", "https":
+		return &webhookSink{url: sinkURL, client: &http.Client{Timeout: sendTimeout}}, nil
+	case "kafka":
+		topic := strings.Trim(u.Path, "/")
+		if u.Host == "" || topic == "" {
+			return nil, xerrors.Errorf("kafka sink %s needs the brokers and the topic", sinkURL)
+		}
+
+		writer := &kafka.Writer{
+			Addr:         kafka.TCP(strings.Split(u.Host, ",")...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			WriteTimeout: sendTimeout,
+		}
+
+		// the topic is set per message if it depends on the event
+		if !strings.Contains(topic, topicPlaceholder) {
+			writer.Topic = topic
+		}
+
+		return &kafkaSink{writer: writer, topic: topic}, nil
+	case "nats":
+		prefix := strings.Trim(u.Path, "/")
+		if prefix == "" {
+			return nil, xerrors.Errorf("nats sink %s needs the subject prefix", sinkURL)
+		}
+
+		nc, err := nats.Connect(u.Scheme+"://"+u.Host, nats.MaxReconnects(-1))
+		if err != nil {
+			return nil, err
+		}
+
+		js, err := nc.JetStream()
+		if err != nil {
+			nc.Close()
+			return nil, err
+		}
+
+		return &natsSink{conn: nc, js: js, prefix: prefix}, nil
+	default:
+		return nil, xerrors.Errorf("unsupported outbox sink %s", sinkURL)
+	}
+}
+
+// filteredSink delivers the events whose topic starts with one of the prefixes
+type filteredSink struct {
+	sink
+	prefixes []string
+}
+
+func (s *filteredSink) send(ctx context.Context, events []*types.OutboxEvent) error {
+	matched := make([]*types.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		for _, prefix := range s.prefixes {
+			if strings.HasPrefix(string(event.Topic), prefix) {
+				matched = append(matched, event)
+				break
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil
+	}
+
+	return s.sink.send(ctx, matched)
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) send(ctx context.Context, events []*types.OutboxEvent) error {
+	buf, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close() //nolint:errcheck
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return xerrors.Errorf("webhook status code %d", rsp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *webhookSink) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+type kafkaSink struct {
+	writer *kafka.Writer
+	topic  string
+}
+
+func (s *kafkaSink) send(ctx context.Context, events []*types.OutboxEvent) error {
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		buf, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		msg := kafka.Message{
+			// the events of a subject go to the same partition and keep their order
+			Key:     []byte(event.Subject),
+			Value:   buf,
+			Headers: []kafka.Header{{Key: "id", Value: []byte(strconv.FormatInt(event.ID, 10))}},
+		}
+		if s.writer.Topic == "" {
+			msg.Topic = strings.ReplaceAll(s.topic, topicPlaceholder, string(event.Topic))
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return s.writer.WriteMessages(ctx, msgs...)
+}
+
+func (s *kafkaSink) close() error {
+	return s.writer.Close()
+}
+
+type natsSink struct {
+	conn   *nats.Conn
+	js     nats.JetStreamContext
+	prefix string
+}
+
+func (s *natsSink) send(ctx context.Context, events []*types.OutboxEvent) error {
+	for _, event := range events {
+		buf, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		// jetstream drops the redelivered events with the same message id in its duplicate window
+		_, err = s.js.Publish(s.prefix+"."+string(event.Topic), buf, nats.MsgId(strconv.FormatInt(event.ID, 10)), nats.Context(ctx))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *natsSink) close() error {
+	s.conn.Close()
+	return nil
+}
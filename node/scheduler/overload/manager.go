@@ -0,0 +1,169 @@
+package overload
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/metrics"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("overload")
+
+const (
+	checkInterval = 5 * time.Second
+	pingTimeout   = 10 * time.Second
+	// the scheduler stops shedding after the load stays under the thresholds for the checks
+	recoverChecks = 6
+)
+
+// ErrOverloaded is returned for the low priority requests rejected while the scheduler is shedding
+var ErrOverloaded = xerrors.New("scheduler is overloaded, try again later")
+
+// Manager checks the latency of the database and the number of goroutines, and turns on shedding when either exceeds its threshold.
+// While shedding, the low priority work such as stats queries and leaderboards is rejected or skipped, and the node information
+// is saved less often on keepalive, the keepalives and the retrieval routing are always served.
+type Manager struct {
+	config dtypes.GetSchedulerConfigFunc
+	*db.SQLDB
+
+	lk       sync.RWMutex
+	shedding bool
+	// consecutive checks under the thresholds while shedding
+	calmChecks int
+}
+
+// NewManager return new overload manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	m := &Manager{
+		config: configFunc,
+		SQLDB:  sdb,
+	}
+
+	go m.startCheckTimer()
+
+	return m
+}
+
+// Shedding returns true if the scheduler is shedding low priority work
+func (m *Manager) Shedding() bool {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	return m.shedding
+}
+
+// Admit returns ErrOverloaded if the scheduler is shedding, the rejected requests are counted by endpoint
+func (m *Manager) Admit(ctx context.Context, endpoint string) error {
+	if !m.Shedding() {
+		return nil
+	}
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Endpoint, endpoint))
+	stats.Record(ctx, metrics.SchedulerShedRequests.M(1))
+
+	return ErrOverloaded
+}
+
+// KeepaliveSaveStretch returns the factor the interval of saving the node information on keepalive is multiplied by
+func (m *Manager) KeepaliveSaveStretch() int {
+	if !m.Shedding() {
+		return 1
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return 1
+	}
+
+	if cfg.OverloadKeepaliveSaveStretch < 1 {
+		return 1
+	}
+
+	return cfg.OverloadKeepaliveSaveStretch
+}
+
+func (m *Manager) startCheckTimer() {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.check()
+	}
+}
+
+// check measures the load and updates the shedding state
+func (m *Manager) check() {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	start := time.Now()
+	err = m.Ping(ctx)
+	latency := time.Since(start)
+	cancel()
+	if err != nil {
+		log.Warnf("ping database err:%s", err.Error())
+		// a failed ping counts as the slowest
+		latency = pingTimeout
+	}
+
+	overloaded := exceeded(latency, goroutines, cfg.OverloadDBLatencyMs, cfg.OverloadGoroutines)
+	m.update(overloaded, latency, goroutines)
+
+	ctx = context.Background()
+	stats.Record(ctx, metrics.SchedulerDBLatency.M(float64(latency.Nanoseconds())/1e6), metrics.SchedulerGoroutines.M(int64(goroutines)))
+	if m.Shedding() {
+		stats.Record(ctx, metrics.SchedulerShedding.M(1))
+	} else {
+		stats.Record(ctx, metrics.SchedulerShedding.M(0))
+	}
+}
+
+// update turns on shedding as soon as the load is over the thresholds, and turns it off after the load stays under them
+func (m *Manager) update(overloaded bool, latency time.Duration, goroutines int) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	if overloaded {
+		m.calmChecks = 0
+		if !m.shedding {
+			m.shedding = true
+			log.Warnf("scheduler overloaded, db latency %s, goroutines %d, start shedding", latency, goroutines)
+		}
+		return
+	}
+
+	if !m.shedding {
+		return
+	}
+
+	m.calmChecks++
+	if m.calmChecks >= recoverChecks {
+		m.shedding = false
+		m.calmChecks = 0
+		log.Infof("scheduler load recovered, db latency %s, goroutines %d, stop shedding", latency, goroutines)
+	}
+}
+
+// exceeded checks the load against the thresholds, a threshold of 0 is disabled
+func exceeded(latency time.Duration, goroutines, latencyThresholdMs, goroutinesThreshold int) bool {
+	if latencyThresholdMs > 0 && latency > time.Duration(latencyThresholdMs)*time.Millisecond {
+		return true
+	}
+
+	return goroutinesThreshold > 0 && goroutines > goroutinesThreshold
+}
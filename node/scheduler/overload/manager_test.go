@@ -0,0 +1,57 @@
+package overload
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExceeded(t *testing.T) {
+	cases := []struct {
+		latency    time.Duration
+		goroutines int
+		want       bool
+	}{
+		{100 * time.Millisecond, 100, false},
+		{600 * time.Millisecond, 100, true},
+		{100 * time.Millisecond, 2000, true},
+	}
+
+	for _, c := range cases {
+		if got := exceeded(c.latency, c.goroutines, 500, 1000); got != c.want {
+			t.Errorf("exceeded(%s, %d) = %v, want %v", c.latency, c.goroutines, got, c.want)
+		}
+	}
+
+	if exceeded(time.Hour, 1e6, 0, 0) {
+		t.Errorf("disabled thresholds exceeded")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	m := &Manager{}
+
+	m.update(true, time.Second, 0)
+	if !m.Shedding() {
+		t.Fatalf("expected shedding when overloaded")
+	}
+
+	for i := 0; i < recoverChecks-1; i++ {
+		m.update(false, 0, 0)
+	}
+	if !m.Shedding() {
+		t.Fatalf("expected shedding before the load stays under the thresholds")
+	}
+
+	m.update(true, time.Second, 0)
+	for i := 0; i < recoverChecks-1; i++ {
+		m.update(false, 0, 0)
+	}
+	if !m.Shedding() {
+		t.Fatalf("expected the calm checks reset by an overloaded check")
+	}
+
+	m.update(false, 0, 0)
+	if m.Shedding() {
+		t.Fatalf("expected shedding stopped after the load recovered")
+	}
+}
@@ -0,0 +1,385 @@
+package penalty
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("penalty")
+
+const (
+	checkOfflineInterval = time.Minute
+	// interval to reload the rules changed by the other schedulers
+	reloadRulesInterval = 5 * time.Minute
+)
+
+// Manager applies the penalty rules to the nodes on their validation results, hardware proofs, offline time and broken commitments,
+// the counters of the nodes are kept in memory, each scheduler counts the nodes connected to it
+type Manager struct {
+	nodeMgr *node.Manager
+	notify  *eventbus.Bus
+	config  dtypes.GetSchedulerConfigFunc
+	*db.SQLDB
+
+	rulesLk sync.RWMutex
+	rules   []*types.PenaltyRule
+
+	lk sync.Mutex
+	// detections of the nodes counted by rule id and node id
+	counters map[int64]map[string]int
+	// offline nodes by node id
+	offline map[string]*offlineState
+
+	tiersLk sync.RWMutex
+	// trust tiers of the edges connected to the scheduler by node id
+	tiers map[string]types.TrustTier
+}
+
+// offlineState the offline minutes in the committed hours of the rules since the node went offline
+type offlineState struct {
+	minutes map[int64]int
+	applied map[int64]bool
+}
+
+// NewManager return new penalty manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, p *eventbus.Bus, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	m := &Manager{
+		nodeMgr:  nmgr,
+		notify:   p,
+		config:   configFunc,
+		SQLDB:    sdb,
+		counters: make(map[int64]map[string]int),
+		offline:  make(map[string]*offlineState),
+		tiers:    make(map[string]types.TrustTier),
+	}
+
+	if err := m.reloadRules(); err != nil {
+		log.Errorf("reload penalty rules err:%s", err.Error())
+	}
+
+	diagnostics.RegisterSize("penalty.offline", func() int {
+		m.lk.Lock()
+		defer m.lk.Unlock()
+		return len(m.offline)
+	})
+
+	m.subscribeEvents()
+	go m.startReloadRulesTimer()
+	go m.startCheckOfflineTimer()
+	go m.startTrustTimer()
+
+	return m
+}
+
+// SaveRule checks and saves the rule, the rule is updated if its id is set
+func (m *Manager) SaveRule(rule *types.PenaltyRule) (int64, error) {
+	if err := checkRule(rule); err != nil {
+		return 0, err
+	}
+
+	id, err := m.SavePenaltyRule(rule)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, m.reloadRules()
+}
+
+// DeleteRule deletes the rule
+func (m *Manager) DeleteRule(id int64) error {
+	if err := m.DeletePenaltyRule(id); err != nil {
+		return err
+	}
+
+	return m.reloadRules()
+}
+
+func checkRule(rule *types.PenaltyRule) error {
+	switch rule.Type {
+	case types.PenaltyRuleMissedValidations, types.PenaltyRuleFakeStorage, types.PenaltyRuleBrokenCommitment, types.PenaltyRuleSlowRetrievals,
+		types.PenaltyRuleWorkloadDiscrepancy:
+	case types.PenaltyRuleOfflineCommittedHours:
+		if rule.CommittedStartHour < 0 || rule.CommittedStartHour > 23 || rule.CommittedEndHour < 0 || rule.CommittedEndHour > 24 ||
+			rule.CommittedStartHour == rule.CommittedEndHour {
+			return xerrors.Errorf("invalid committed hours [%d, %d)", rule.CommittedStartHour, rule.CommittedEndHour)
+		}
+	default:
+		return xerrors.Errorf("unknown penalty rule type %s", rule.Type)
+	}
+
+	if rule.Threshold <= 0 {
+		return xerrors.New("threshold must be greater than 0")
+	}
+
+	if rule.DeductPoints.Sign() < 0 || rule.FreezeHours < 0 {
+		return xerrors.New("deduct points and freeze hours can not be negative")
+	}
+
+	if rule.DeductPoints.IsZero() && rule.FreezeHours == 0 {
+		return xerrors.New("rule deducts no points and freezes no rewards")
+	}
+
+	return nil
+}
+
+func (m *Manager) reloadRules() error {
+	rules, err := m.LoadPenaltyRules()
+	if err != nil {
+		return err
+	}
+
+	m.rulesLk.Lock()
+	m.rules = rules
+	m.rulesLk.Unlock()
+
+	return nil
+}
+
+func (m *Manager) startReloadRulesTimer() {
+	ticker := time.NewTicker(reloadRulesInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := m.reloadRules(); err != nil {
+			log.Errorf("reload penalty rules err:%s", err.Error())
+		}
+	}
+}
+
+// enabledRules returns the enabled rules of the type
+func (m *Manager) enabledRules(ruleType types.PenaltyRuleType) []*types.PenaltyRule {
+	m.rulesLk.RLock()
+	defer m.rulesLk.RUnlock()
+
+	var out []*types.PenaltyRule
+	for _, rule := range m.rules {
+		if rule.Enabled && rule.Type == ruleType {
+			out = append(out, rule)
+		}
+	}
+
+	return out
+}
+
+func (m *Manager) subscribeEvents() {
+	// the counters tolerate a lost result better than the stale ones, the node states only need the latest events
+	subValidation := m.notify.Sub("penalty", types.EventValidationResult.String(), eventbus.DefaultQueueSize, eventbus.DropNewest)
+	subHardware := m.notify.Sub("penalty", types.EventHardwareProof.String(), eventbus.DefaultQueueSize, eventbus.DropNewest)
+	subOnline := m.notify.Sub("penalty", types.EventNodeOnline.String(), eventbus.DefaultQueueSize, eventbus.DropOldest)
+	subOffline := m.notify.Sub("penalty", types.EventNodeOffline.String(), eventbus.DefaultQueueSize, eventbus.DropOldest)
+
+	go func() {
+		defer m.notify.Unsub(subValidation)
+		defer m.notify.Unsub(subHardware)
+		defer m.notify.Unsub(subOnline)
+		defer m.notify.Unsub(subOffline)
+
+		for {
+			select {
+			case u, ok := <-subValidation:
+				if !ok {
+					return
+				}
+				m.onValidationResult(u.(*types.ValidationResultInfo))
+			case u, ok := <-subHardware:
+				if !ok {
+					return
+				}
+				m.onHardwareProof(u.(*types.HardwareProof))
+			case u, ok := <-subOnline:
+				if !ok {
+					return
+				}
+				m.onNodeStateChange(u.(*node.Node), true)
+			case u, ok := <-subOffline:
+				if !ok {
+					return
+				}
+				m.onNodeStateChange(u.(*node.Node), false)
+			}
+		}
+	}()
+}
+
+func (m *Manager) onValidationResult(info *types.ValidationResultInfo) {
+	switch info.Status {
+	case types.ValidationStatusSuccess:
+		m.resetCounters(types.PenaltyRuleMissedValidations, info.NodeID)
+	case types.ValidationStatusNodeTimeOut, types.ValidationStatusNodeOffline:
+		if m.nodeMgr.InMaintenance(info.NodeID, time.Now()) {
+			return
+		}
+		m.count(types.PenaltyRuleMissedValidations, info.NodeID, "missed %d validations in a row, the last in round %s", info.RoundID)
+	case types.ValidationStatusValidateFail:
+		m.count(types.PenaltyRuleFakeStorage, info.NodeID, "returned mismatched blocks %d times, the last in round %s", info.RoundID)
+	}
+}
+
+func (m *Manager) onHardwareProof(proof *types.HardwareProof) {
+	// a node failing to run the probes fakes nothing
+	if !proof.Mismatched {
+		return
+	}
+
+	m.count(types.PenaltyRuleFakeStorage, proof.NodeID, "faked storage %d times, the last failed the hardware challenge %s", proof.ProofTime.Format(time.RFC3339))
+}
+
+// ReplicaLost counts the replica of the asset the node lost by failing the storage challenges
+func (m *Manager) ReplicaLost(nodeID, hash string) {
+	m.count(types.PenaltyRuleFakeStorage, nodeID, "faked storage %d times, the last lost the replica of asset %s by the storage challenges", hash)
+}
+
+// SpotCheckMismatched counts the spot check the node served the bytes of the asset differing from the candidates in
+func (m *Manager) SpotCheckMismatched(nodeID, assetCID string) {
+	m.count(types.PenaltyRuleFakeStorage, nodeID, "faked storage %d times, the last served the wrong bytes of asset %s to a spot check", assetCID)
+}
+
+// BrokenCommitment counts the commitment window started at windowStart broken by the node
+func (m *Manager) BrokenCommitment(nodeID string, windowStart time.Time) {
+	m.count(types.PenaltyRuleBrokenCommitment, nodeID, "broke %d commitment windows, the last started at %s", windowStart.Format(time.RFC3339))
+}
+
+// RetrievalProbed counts the slow or failed retrieval probe of the node, a good probe resets the count
+func (m *Manager) RetrievalProbed(nodeID string, good bool, probeTime time.Time) {
+	if good {
+		m.resetCounters(types.PenaltyRuleSlowRetrievals, nodeID)
+		return
+	}
+
+	m.count(types.PenaltyRuleSlowRetrievals, nodeID, "was slow in %d retrieval probes in a row, the last at %s", probeTime.Format(time.RFC3339))
+}
+
+// WorkloadDiscrepancy counts the sampled workload receipt the node reported more bytes for than the downloader received
+func (m *Manager) WorkloadDiscrepancy(nodeID, tokenID string) {
+	m.count(types.PenaltyRuleWorkloadDiscrepancy, nodeID, "overreported the workloads of %d sampled receipts, the last of token %s", tokenID)
+}
+
+// count counts a detection of the node by the rules of the type and applies the rules reaching the threshold,
+// the counter of an applied rule restarts
+func (m *Manager) count(ruleType types.PenaltyRuleType, nodeID, reasonFormat, last string) {
+	for _, rule := range m.enabledRules(ruleType) {
+		m.lk.Lock()
+		counter, ok := m.counters[rule.ID]
+		if !ok {
+			counter = make(map[string]int)
+			m.counters[rule.ID] = counter
+		}
+
+		counter[nodeID]++
+		reached := counter[nodeID] >= rule.Threshold
+		if reached {
+			delete(counter, nodeID)
+		}
+		m.lk.Unlock()
+
+		if reached {
+			go m.apply(rule, nodeID, fmt.Sprintf(reasonFormat, rule.Threshold, last))
+		}
+	}
+}
+
+func (m *Manager) resetCounters(ruleType types.PenaltyRuleType, nodeID string) {
+	for _, rule := range m.enabledRules(ruleType) {
+		m.lk.Lock()
+		delete(m.counters[rule.ID], nodeID)
+		m.lk.Unlock()
+	}
+}
+
+func (m *Manager) onNodeStateChange(n *node.Node, isOnline bool) {
+	if n == nil {
+		return
+	}
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	if isOnline {
+		delete(m.offline, n.NodeID)
+		return
+	}
+
+	if _, ok := m.offline[n.NodeID]; !ok {
+		m.offline[n.NodeID] = &offlineState{minutes: make(map[int64]int), applied: make(map[int64]bool)}
+	}
+}
+
+func (m *Manager) startCheckOfflineTimer() {
+	ticker := time.NewTicker(checkOfflineInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("penalty.check_offline", checkOfflineInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.checkOffline(time.Now())
+		done()
+	}
+}
+
+// checkOffline counts the offline minute of the nodes in the committed hours of the rules,
+// a rule is applied once to a node each time it goes offline. The minutes in the maintenance windows are not counted
+func (m *Manager) checkOffline(now time.Time) {
+	rules := m.enabledRules(types.PenaltyRuleOfflineCommittedHours)
+	if len(rules) == 0 {
+		return
+	}
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	for nodeID, state := range m.offline {
+		if m.nodeMgr.InMaintenance(nodeID, now) {
+			continue
+		}
+
+		for _, rule := range rules {
+			if state.applied[rule.ID] || !rule.InCommittedHours(now) {
+				continue
+			}
+
+			state.minutes[rule.ID]++
+			if state.minutes[rule.ID] < rule.Threshold {
+				continue
+			}
+
+			state.applied[rule.ID] = true
+			go m.apply(rule, nodeID, fmt.Sprintf("offline for %d minutes in the committed hours [%d, %d)",
+				rule.Threshold, rule.CommittedStartHour, rule.CommittedEndHour))
+		}
+	}
+}
+
+// apply deducts the points of the rule from the node and freezes its rewards
+func (m *Manager) apply(rule *types.PenaltyRule, nodeID, reason string) {
+	now := time.Now().Truncate(time.Second)
+	penalty := &types.Penalty{
+		NodeID:      nodeID,
+		RuleID:      rule.ID,
+		RuleType:    rule.Type,
+		Reason:      reason,
+		FrozenUntil: now.Add(time.Duration(rule.FreezeHours) * time.Hour),
+		CreatedTime: now,
+	}
+
+	id, err := m.ApplyPenalty(penalty, rule.DeductPoints)
+	if err != nil {
+		log.Errorf("apply penalty rule %d to node %s err:%s", rule.ID, nodeID, err.Error())
+		return
+	}
+
+	log.Infof("penalty %d of rule %d applied to node %s, deducted %s points, frozen until %s: %s",
+		id, rule.ID, nodeID, penalty.DeductedPoints, penalty.FrozenUntil.Format(time.RFC3339), reason)
+
+	m.probate(nodeID, fmt.Sprintf("penalty %d: %s", id, reason))
+}
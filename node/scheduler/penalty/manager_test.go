@@ -0,0 +1,59 @@
+package penalty
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestCheckRule(t *testing.T) {
+	valid := []*types.PenaltyRule{
+		{Type: types.PenaltyRuleMissedValidations, Threshold: 3, DeductPoints: types.PointsFromInt(10)},
+		{Type: types.PenaltyRuleFakeStorage, Threshold: 1, FreezeHours: 24},
+		{Type: types.PenaltyRuleOfflineCommittedHours, Threshold: 30, CommittedStartHour: 22, CommittedEndHour: 6, DeductPoints: types.PointsFromInt(1)},
+	}
+	for i, rule := range valid {
+		if err := checkRule(rule); err != nil {
+			t.Fatalf("rule %d should be valid: %s", i, err.Error())
+		}
+	}
+
+	invalid := []*types.PenaltyRule{
+		{Type: "unknown", Threshold: 3, DeductPoints: types.PointsFromInt(10)},
+		{Type: types.PenaltyRuleMissedValidations, Threshold: 0, DeductPoints: types.PointsFromInt(10)},
+		{Type: types.PenaltyRuleMissedValidations, Threshold: 3},
+		{Type: types.PenaltyRuleFakeStorage, Threshold: 1, DeductPoints: types.PointsFromInt(-1), FreezeHours: 1},
+		{Type: types.PenaltyRuleOfflineCommittedHours, Threshold: 30, CommittedStartHour: 8, CommittedEndHour: 8, DeductPoints: types.PointsFromInt(1)},
+		{Type: types.PenaltyRuleOfflineCommittedHours, Threshold: 30, CommittedStartHour: 8, CommittedEndHour: 25, DeductPoints: types.PointsFromInt(1)},
+	}
+	for i, rule := range invalid {
+		if err := checkRule(rule); err == nil {
+			t.Fatalf("rule %d should be invalid", i)
+		}
+	}
+}
+
+func TestInCommittedHours(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2024, 1, 1, hour, 30, 0, 0, time.Local)
+	}
+
+	day := &types.PenaltyRule{CommittedStartHour: 8, CommittedEndHour: 20}
+	night := &types.PenaltyRule{CommittedStartHour: 22, CommittedEndHour: 6}
+
+	cases := []struct {
+		rule     *types.PenaltyRule
+		hour     int
+		expected bool
+	}{
+		{day, 7, false}, {day, 8, true}, {day, 19, true}, {day, 20, false},
+		{night, 21, false}, {night, 22, true}, {night, 0, true}, {night, 5, true}, {night, 6, false},
+	}
+
+	for _, c := range cases {
+		if c.rule.InCommittedHours(at(c.hour)) != c.expected {
+			t.Fatalf("hour %d in [%d, %d) expect %v", c.hour, c.rule.CommittedStartHour, c.rule.CommittedEndHour, c.expected)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package penalty
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+)
+
+const (
+	trustInterval = 10 * time.Minute
+	// the nodes evaluated in a query at most
+	trustBatchSize = 500
+	// length of the reason column of the trust tables
+	maxReasonLen = 256
+)
+
+// Tier returns the trust tier of the edge connected to the scheduler, standard if the edge is not evaluated yet
+func (m *Manager) Tier(nodeID string) types.TrustTier {
+	m.tiersLk.RLock()
+	defer m.tiersLk.RUnlock()
+
+	if tier, ok := m.tiers[nodeID]; ok {
+		return tier
+	}
+
+	return types.TrustTierStandard
+}
+
+func (m *Manager) startTrustTimer() {
+	ticker := time.NewTicker(trustInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("penalty.trust", trustInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.evaluateTrust(time.Now())
+		done()
+	}
+}
+
+// evaluateTrust evaluates the trust tiers of the edges connected to the scheduler, the tiers of the edges gone are forgotten
+func (m *Manager) evaluateTrust(now time.Time) {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	edges := m.nodeMgr.GetAllEdgeNode()
+	nodeIDs := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		nodeIDs = append(nodeIDs, edge.NodeID)
+	}
+
+	tiers := make(map[string]types.TrustTier, len(nodeIDs))
+	for len(nodeIDs) > 0 {
+		n := len(nodeIDs)
+		if n > trustBatchSize {
+			n = trustBatchSize
+		}
+
+		facts, err := m.LoadNodeTrustFacts(nodeIDs[:n])
+		if err != nil {
+			log.Errorf("LoadNodeTrustFacts err:%s", err.Error())
+			return
+		}
+		nodeIDs = nodeIDs[n:]
+
+		for _, f := range facts {
+			tier, reason := trustTier(f, now, cfg.TrustProbationDays, cfg.TrustedAfterDays)
+			tiers[f.NodeID] = tier
+
+			if tier != f.Tier {
+				m.transit(f.NodeID, f.Tier, tier, reason)
+			}
+		}
+	}
+
+	m.tiersLk.Lock()
+	m.tiers = tiers
+	m.tiersLk.Unlock()
+}
+
+// trustTier returns the tier of the node by its facts with the reason, the nodes registered or penalized in the
+// probation days are on probation, and the nodes registered and not penalized in the trusted days are trusted
+func trustTier(f *types.NodeTrustFacts, now time.Time, probationDays, trustedAfterDays int) (types.TrustTier, string) {
+	probation := now.AddDate(0, 0, -probationDays)
+	if f.LastPenaltyTime != nil && f.LastPenaltyTime.After(probation) {
+		return types.TrustTierProbation, fmt.Sprintf("penalized at %s", f.LastPenaltyTime.Format(time.RFC3339))
+	}
+
+	if f.FirstTime.After(probation) {
+		return types.TrustTierProbation, fmt.Sprintf("registered at %s", f.FirstTime.Format(time.RFC3339))
+	}
+
+	// the node is trusted once it is registered and not penalized for the trusted days
+	since := f.FirstTime
+	if f.LastPenaltyTime != nil && f.LastPenaltyTime.After(since) {
+		since = *f.LastPenaltyTime
+	}
+
+	trustedTime := since.AddDate(0, 0, trustedAfterDays)
+	if !trustedTime.After(now) {
+		return types.TrustTierTrusted, fmt.Sprintf("registered and not penalized for %d days", trustedAfterDays)
+	}
+
+	return types.TrustTierStandard, fmt.Sprintf("trusted after %s", trustedTime.Format(time.RFC3339))
+}
+
+// transit moves the node to the tier and logs the transition
+func (m *Manager) transit(nodeID string, from, to types.TrustTier, reason string) {
+	if len(reason) > maxReasonLen {
+		reason = reason[:maxReasonLen]
+	}
+
+	err := m.SaveTrustTransition(&types.TrustTransition{NodeID: nodeID, FromTier: from, ToTier: to, Reason: reason})
+	if err != nil {
+		log.Errorf("SaveTrustTransition %s err:%s", nodeID, err.Error())
+		return
+	}
+
+	log.Infof("node %s trust tier %s -> %s: %s", nodeID, from, to, reason)
+}
+
+// probate puts the penalized node on probation at once, the node stays on probation for the probation days
+func (m *Manager) probate(nodeID, reason string) {
+	m.tiersLk.Lock()
+	from, ok := m.tiers[nodeID]
+	m.tiers[nodeID] = types.TrustTierProbation
+	m.tiersLk.Unlock()
+
+	if !ok {
+		trust, err := m.LoadNodeTrust(nodeID)
+		if err == nil {
+			from = trust.Tier
+		}
+	}
+
+	if from == types.TrustTierProbation {
+		return
+	}
+
+	m.transit(nodeID, from, types.TrustTierProbation, reason)
+}
@@ -0,0 +1,39 @@
+package penalty
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestTrustTier(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+
+	cases := []struct {
+		facts *types.NodeTrustFacts
+		tier  types.TrustTier
+	}{
+		// registered recently
+		{&types.NodeTrustFacts{FirstTime: *daysAgo(3)}, types.TrustTierProbation},
+		// penalized recently
+		{&types.NodeTrustFacts{FirstTime: *daysAgo(100), LastPenaltyTime: daysAgo(2)}, types.TrustTierProbation},
+		// out of probation but not trusted yet
+		{&types.NodeTrustFacts{FirstTime: *daysAgo(10)}, types.TrustTierStandard},
+		{&types.NodeTrustFacts{FirstTime: *daysAgo(100), LastPenaltyTime: daysAgo(20)}, types.TrustTierStandard},
+		// registered and not penalized for long
+		{&types.NodeTrustFacts{FirstTime: *daysAgo(30)}, types.TrustTierTrusted},
+		{&types.NodeTrustFacts{FirstTime: *daysAgo(100), LastPenaltyTime: daysAgo(40)}, types.TrustTierTrusted},
+	}
+
+	for i, c := range cases {
+		tier, reason := trustTier(c.facts, now, 7, 30)
+		if tier != c.tier {
+			t.Fatalf("case %d: expected tier %s, got %s (%s)", i, c.tier, tier, reason)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// SavePenaltyRule saves the penalty rule and returns its id, the rule is updated if its id is set
+func (s *Scheduler) SavePenaltyRule(ctx context.Context, rule *types.PenaltyRule) (int64, error) {
+	if rule == nil {
+		return 0, xerrors.New("rule can not empty")
+	}
+
+	return s.PenaltyManager.SaveRule(rule)
+}
+
+// DeletePenaltyRule deletes the penalty rule, the penalties applied by the rule are kept
+func (s *Scheduler) DeletePenaltyRule(ctx context.Context, id int64) error {
+	return s.PenaltyManager.DeleteRule(id)
+}
+
+// GetPenaltyRules retrieves the penalty rules with the number of times each has been applied
+func (s *Scheduler) GetPenaltyRules(ctx context.Context) ([]*types.PenaltyRule, error) {
+	return s.PenaltyManager.LoadPenaltyRules()
+}
+
+// GetNodePenalties retrieves the penalties applied to the node, the latest first
+func (s *Scheduler) GetNodePenalties(ctx context.Context, nodeID string, limit, offset int) (*types.ListPenaltyRsp, error) {
+	return s.PenaltyManager.LoadNodePenalties(nodeID, limit, offset)
+}
+
+// AppealPenalty appeals the penalty with the reason, a penalty can only be appealed once
+func (s *Scheduler) AppealPenalty(ctx context.Context, id int64, reason string) error {
+	if reason == "" {
+		return xerrors.New("appeal reason can not empty")
+	}
+
+	return s.PenaltyManager.AppealPenalty(id, reason)
+}
+
+// ResolvePenaltyAppeal resolves the pending appeal of the penalty, the points are restored and the freeze is lifted if accepted
+func (s *Scheduler) ResolvePenaltyAppeal(ctx context.Context, id int64, accepted bool) error {
+	return s.PenaltyManager.ResolvePenaltyAppeal(id, accepted)
+}
+
+// GetNodeTrust retrieves the trust tier of the node, the nodes of the lower tiers are validated more often
+func (s *Scheduler) GetNodeTrust(ctx context.Context, nodeID string) (*types.NodeTrust, error) {
+	trust, err := s.PenaltyManager.LoadNodeTrust(nodeID)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("node %s not evaluated yet", nodeID)
+	}
+
+	return trust, err
+}
+
+// GetNodeTrustTransitions retrieves the transitions of the node between the trust tiers, the latest first
+func (s *Scheduler) GetNodeTrustTransitions(ctx context.Context, nodeID string, limit, offset int) (*types.ListTrustTransitionRsp, error) {
+	return s.PenaltyManager.LoadTrustTransitions(nodeID, limit, offset)
+}
@@ -0,0 +1,131 @@
+package pointsepoch
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("pointsepoch")
+
+const (
+	closeInterval = 10 * time.Minute
+	// the longest period of the epochs of a node loaded at once
+	maxQueryDays = 366
+)
+
+// Manager closes an epoch of points every utc day. The epoch keeps the points each node earned since the previous close
+// and is never changed afterwards, the points of a closed epoch are changed by corrections of the epoch.
+// The first epoch carries the points earned before it, and the points earned while the epochs are not closed
+// are counted in the next closed epoch.
+type Manager struct {
+	leadershipMgr *leadership.Manager
+	*db.SQLDB
+}
+
+// NewManager return new points epoch manager instance
+func NewManager(sdb *db.SQLDB, lmgr *leadership.Manager) *Manager {
+	m := &Manager{
+		leadershipMgr: lmgr,
+		SQLDB:         sdb,
+	}
+
+	go m.startCloseTimer()
+
+	return m
+}
+
+func (m *Manager) startCloseTimer() {
+	ticker := time.NewTicker(closeInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("pointsepoch.close", closeInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.close(time.Now())
+		done()
+	}
+}
+
+// close closes the epoch of the previous utc day if it is not closed yet
+func (m *Manager) close(now time.Time) {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	last, err := m.LoadLatestPointsEpoch()
+	if err != nil && err != sql.ErrNoRows {
+		log.Errorf("LoadLatestPointsEpoch err:%s", err.Error())
+		return
+	}
+
+	epoch, ok := epochToClose(last, now)
+	if !ok {
+		return
+	}
+
+	summary, err := m.ClosePointsEpoch(epoch, now)
+	if err != nil {
+		log.Errorf("close points epoch %s err:%s", epoch.Format("2006-01-02"), err.Error())
+		return
+	}
+
+	log.Infof("closed points epoch %s, nodes %d, points %s", epoch.Format("2006-01-02"), summary.NodeCount, summary.Points)
+}
+
+// epochToClose returns the previous utc day of now, false if it has been closed
+func epochToClose(last *types.PointsEpochSummary, now time.Time) (time.Time, bool) {
+	epoch := Epoch(now).AddDate(0, 0, -1)
+	if last != nil && !Epoch(last.Epoch).Before(epoch) {
+		return time.Time{}, false
+	}
+
+	return epoch, true
+}
+
+// Epoch returns the epoch of the time, the start of its utc day
+func Epoch(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Correct corrects the points of the node in the closed epoch, the points are added to the node
+func (m *Manager) Correct(correction *types.PointsCorrection) (int64, error) {
+	if correction.NodeID == "" {
+		return 0, xerrors.New("node id can not empty")
+	}
+
+	if correction.Points.IsZero() {
+		return 0, xerrors.New("correction points can not be zero")
+	}
+
+	if correction.Reason == "" {
+		return 0, xerrors.New("correction reason can not empty")
+	}
+
+	correction.Epoch = Epoch(correction.Epoch)
+	correction.CreatedTime = time.Now()
+
+	return m.CorrectPointsEpoch(correction)
+}
+
+// NodeEpochs returns the epochs of the node in the utc days [start, end] with the totals derived from the epochs
+func (m *Manager) NodeEpochs(nodeID string, start, end time.Time) (*types.NodePointsEpochsRsp, error) {
+	start, end = Epoch(start), Epoch(end)
+	if end.Before(start) {
+		return nil, xerrors.New("end can not be before start")
+	}
+
+	if end.Sub(start) > maxQueryDays*24*time.Hour {
+		return nil, xerrors.Errorf("the period can not be longer than %d days", maxQueryDays)
+	}
+
+	return m.LoadNodePointsEpochs(nodeID, start, end)
+}
@@ -0,0 +1,29 @@
+package pointsepoch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestEpochToClose(t *testing.T) {
+	// 01:30 on 2024-03-02 in utc
+	now := time.Date(2024, 3, 2, 9, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+
+	epoch, ok := epochToClose(nil, now)
+	if !ok || !epoch.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected to close 2024-03-01, got %s %v", epoch, ok)
+	}
+
+	// the epochs missed are closed as one
+	last := &types.PointsEpochSummary{Epoch: time.Date(2024, 2, 27, 0, 0, 0, 0, time.UTC)}
+	if epoch, ok = epochToClose(last, now); !ok || !epoch.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected to close 2024-03-01, got %s %v", epoch, ok)
+	}
+
+	last.Epoch = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, ok = epochToClose(last, now); ok {
+		t.Fatal("the epoch is closed")
+	}
+}
@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// GetPointsEpochs retrieves the closed utc days of points with the points earned by all nodes, the latest first
+func (s *Scheduler) GetPointsEpochs(ctx context.Context, limit, offset int) (*types.ListPointsEpochRsp, error) {
+	return s.PointsEpochManager.LoadPointsEpochs(limit, offset)
+}
+
+// GetNodePointsEpochs retrieves the points the node earned in the closed utc days [start, end] with the corrections
+func (s *Scheduler) GetNodePointsEpochs(ctx context.Context, nodeID string, start, end time.Time) (*types.NodePointsEpochsRsp, error) {
+	return s.PointsEpochManager.NodeEpochs(nodeID, start, end)
+}
+
+// CorrectPointsEpoch corrects the points of the node in the closed utc day and returns the correction id
+func (s *Scheduler) CorrectPointsEpoch(ctx context.Context, correction *types.PointsCorrection) (int64, error) {
+	if correction == nil {
+		return 0, xerrors.New("correction can not empty")
+	}
+
+	return s.PointsEpochManager.Correct(correction)
+}
@@ -0,0 +1,198 @@
+package relay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/relay"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("relay")
+
+const (
+	// number of random bytes of the session key
+	sessionKeyBytes = 16
+	// time to set up the session on the candidate
+	addSessionTimeout = 10 * time.Second
+)
+
+// Manager designates candidates as the relays of the edges behind symmetric nat and accounts the relayed bytes
+type Manager struct {
+	nodeMgr *node.Manager
+	*db.SQLDB
+
+	lk       sync.Mutex
+	sessions map[string]*types.RelaySession // by edge id
+}
+
+// NewManager return new relay manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager) *Manager {
+	return &Manager{
+		nodeMgr:  nmgr,
+		SQLDB:    sdb,
+		sessions: make(map[string]*types.RelaySession),
+	}
+}
+
+// Session returns the relay session of the edge, a candidate is designated and the session is set up on it
+// if the edge has no session or the relay of the session is offline
+func (m *Manager) Session(ctx context.Context, eNode *node.Node) (*types.RelaySession, error) {
+	m.lk.Lock()
+	session, ok := m.sessions[eNode.NodeID]
+	m.lk.Unlock()
+
+	if ok && m.nodeMgr.GetCandidateNode(session.CandidateID) != nil {
+		return session, nil
+	}
+
+	cNode := m.selectRelay(eNode)
+	if cNode == nil {
+		return nil, xerrors.New("no candidate can relay")
+	}
+
+	key := make([]byte, sessionKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session = &types.RelaySession{
+		ID:               uuid.NewString(),
+		EdgeID:           eNode.NodeID,
+		CandidateID:      cNode.NodeID,
+		CandidateAddress: cNode.DownloadAddr(),
+		Key:              hex.EncodeToString(key),
+		CreatedTime:      now,
+		UpdatedTime:      now,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, addSessionTimeout)
+	defer cancel()
+
+	if err := cNode.AddRelaySession(ctx, session); err != nil {
+		return nil, xerrors.Errorf("add relay session to %s: %w", cNode.NodeID, err)
+	}
+
+	if err := m.SaveRelaySession(session); err != nil {
+		return nil, err
+	}
+
+	m.lk.Lock()
+	m.sessions[eNode.NodeID] = session
+	m.lk.Unlock()
+
+	log.Infof("candidate %s relays edge %s in session %s", cNode.NodeID, eNode.NodeID, session.ID)
+	return session, nil
+}
+
+// selectRelay selects the candidate serving tls over tcp in the same network of the edge if any, otherwise the one relaying the fewest edges
+func (m *Manager) selectRelay(eNode *node.Node) *node.Node {
+	_, cNodes := m.nodeMgr.GetAllValidCandidateNodes()
+
+	m.lk.Lock()
+	relayed := make(map[string]int)
+	for edgeID, session := range m.sessions {
+		// the sessions of the offline edges are dropped
+		if m.nodeMgr.GetEdgeNode(edgeID) == nil {
+			delete(m.sessions, edgeID)
+			continue
+		}
+		relayed[session.CandidateID]++
+	}
+	m.lk.Unlock()
+
+	var selected *node.Node
+	minCost := math.MaxFloat64
+	for _, cNode := range cNodes {
+		// the edges dial the relay with https over tcp
+		if cNode.IsOverloaded() || !cNode.ServesTCPTLS() {
+			continue
+		}
+
+		cost := float64(relayed[cNode.NodeID])
+		if !sameNetwork(eNode.ExternalIP, cNode.ExternalIP) {
+			cost += math.MaxInt32
+		}
+
+		if cost < minCost {
+			selected = cNode
+			minCost = cost
+		}
+	}
+
+	return selected
+}
+
+// sameNetwork checks if the ips are in the same /16 network
+func sameNetwork(ip1, ip2 string) bool {
+	a, b := net.ParseIP(ip1), net.ParseIP(ip2)
+	if a == nil || b == nil {
+		return false
+	}
+
+	mask := net.CIDRMask(16, 32)
+	if a.To4() == nil || b.To4() == nil {
+		mask = net.CIDRMask(32, 128)
+	}
+
+	return a.Mask(mask).Equal(b.Mask(mask))
+}
+
+// Address returns the address the clients retrieve from the edge through its relay, empty if the edge has no online relay
+func (m *Manager) Address(edgeID string) string {
+	m.lk.Lock()
+	session, ok := m.sessions[edgeID]
+	m.lk.Unlock()
+
+	if !ok {
+		return ""
+	}
+
+	cNode := m.nodeMgr.GetCandidateNode(session.CandidateID)
+	if cNode == nil {
+		return ""
+	}
+
+	return relay.Address(cNode.DownloadAddr(), edgeID)
+}
+
+// SessionsOfNode returns the sessions the node is relayed in as the edge or relays in as the candidate
+func (m *Manager) SessionsOfNode(nodeID string) []*types.RelaySession {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	var out []*types.RelaySession
+	for _, session := range m.sessions {
+		if session.EdgeID == nodeID || session.CandidateID == nodeID {
+			out = append(out, session)
+		}
+	}
+
+	return out
+}
+
+// AddTraffic accounts the bytes relayed by the candidate to the sessions
+func (m *Manager) AddTraffic(candidateID string, traffics []*types.RelayTraffic) error {
+	for _, traffic := range traffics {
+		if traffic.Bytes <= 0 {
+			continue
+		}
+
+		if err := m.AddRelayedBytes(traffic.SessionID, candidateID, traffic.Bytes); err != nil {
+			return xerrors.Errorf("session %s: %w", traffic.SessionID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+// GetRelaySession returns the relay session of the edge behind symmetric nat, a candidate is designated as the relay if the edge has none,
+// returns nil if the edge needs no relay
+func (s *Scheduler) GetRelaySession(ctx context.Context) (*types.RelaySession, error) {
+	nodeID := handler.GetNodeID(ctx)
+
+	eNode := s.NodeManager.GetEdgeNode(nodeID)
+	if eNode == nil {
+		return nil, fmt.Errorf("edge %s offline or not exist", nodeID)
+	}
+
+	if eNode.NATType != types.NatTypeSymmetric {
+		return nil, nil
+	}
+
+	return s.RelayManager.Session(ctx, eNode)
+}
+
+// SubmitRelayTraffic submits the bytes relayed by the candidate, the bytes are accounted to the edge and the candidate
+func (s *Scheduler) SubmitRelayTraffic(ctx context.Context, traffics []*types.RelayTraffic) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	if s.NodeManager.GetCandidateNode(nodeID) == nil {
+		return xerrors.Errorf("candidate %s offline or not exist", nodeID)
+	}
+
+	return s.RelayManager.AddTraffic(nodeID, traffics)
+}
+
+// GetRelaySessions retrieves the relay sessions the node takes part in with the relayed bytes
+func (s *Scheduler) GetRelaySessions(ctx context.Context, nodeID string, limit, offset int) (*types.ListRelaySessionRsp, error) {
+	return s.db.LoadRelaySessions(nodeID, limit, offset)
+}
+
+// downloadAddr returns the address the clients retrieve from the node with, the node behind symmetric nat
+// or connected through an outbound proxy is reached through its relay, returns empty if the node has no relay
+func (s *Scheduler) downloadAddr(n *node.Node) string {
+	if n.NATType == types.NatTypeSymmetric || n.IsProxied() {
+		return s.RelayManager.Address(n.NodeID)
+	}
+
+	return n.DownloadAddr()
+}
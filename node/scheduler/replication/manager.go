@@ -0,0 +1,353 @@
+package replication
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/discovery"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("replication")
+
+const (
+	syncInterval   = time.Minute
+	requestTimeout = 30 * time.Second
+	// the manifests streamed in a call, and the calls of an agreement in a round at most
+	batchSize       = 100
+	batchesPerRound = 10
+	// the assets created in the last minute are streamed in the next round, so the assets created in the same second
+	// are streamed in the same batch
+	settleDelay   = time.Minute
+	maxMessageLen = 512
+)
+
+// Manager keeps the replication agreements mirroring the assets of the tenants into the other areas. The master
+// scheduler streams the manifests of the assets of the tenant of each active agreement to a scheduler of the peer
+// area in the order the assets were created, and the peer scheduler creates the assets, whose seeds are pulled by
+// its candidates from the nodes of the area of the tenant
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	assetMgr      *assets.Manager
+	leadershipMgr *leadership.Manager
+	discoveryMgr  *discovery.Manager
+	serverID      dtypes.ServerID
+	*db.SQLDB
+
+	lk sync.Mutex
+	// the clients of the peer schedulers, keyed by their url
+	peers map[string]api.Scheduler
+}
+
+// NewManager return new replication manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, amgr *assets.Manager, lmgr *leadership.Manager,
+	dmgr *discovery.Manager, serverID dtypes.ServerID) *Manager {
+	m := &Manager{
+		config:        configFunc,
+		assetMgr:      amgr,
+		leadershipMgr: lmgr,
+		discoveryMgr:  dmgr,
+		serverID:      serverID,
+		SQLDB:         sdb,
+		peers:         make(map[string]api.Scheduler),
+	}
+
+	amgr.RegisterSeedSources(m.seedSources)
+	go m.startSyncTimer()
+
+	return m
+}
+
+// CreateAgreement creates the agreement mirroring the assets of the tenant into the peer area, the assets are
+// streamed from the oldest. The caller checks the tenant exists
+func (m *Manager) CreateAgreement(agreement *types.ReplicationAgreement) (string, error) {
+	if agreement == nil {
+		return "", xerrors.New("agreement can not empty")
+	}
+
+	if err := agreement.Validate(); err != nil {
+		return "", err
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return "", xerrors.Errorf("get scheduler config err:%s", err.Error())
+	}
+
+	if agreement.PeerAreaID == cfg.AreaID {
+		return "", xerrors.Errorf("peer area %s is the area of the scheduler", agreement.PeerAreaID)
+	}
+
+	agreement.ID = uuid.NewString()
+	agreement.State = types.ReplicationAgreementActive
+	agreement.CursorTime = time.Unix(0, 0)
+	agreement.CursorHash = ""
+
+	if err = m.SaveReplicationAgreement(agreement); err != nil {
+		return "", xerrors.Errorf("SaveReplicationAgreement err:%s", err.Error())
+	}
+
+	return agreement.ID, nil
+}
+
+// Receive creates the assets of the manifests streamed by a scheduler of another area in their order, and returns
+// the number of the manifests accepted. The streaming stops at the first manifest not accepted, which is streamed
+// again in the next round
+func (m *Manager) Receive(batch *types.MirrorManifestBatch) (int, error) {
+	if batch == nil || batch.AgreementID == "" || batch.SourceServerID == "" {
+		return 0, xerrors.New("invalid manifest batch")
+	}
+
+	replicas := batch.Replicas
+	if replicas <= 0 {
+		cfg, err := m.config()
+		if err != nil {
+			return 0, xerrors.Errorf("get scheduler config err:%s", err.Error())
+		}
+		replicas = int64(cfg.UploadAssetReplicaCount)
+	}
+
+	now := time.Now()
+	for i, manifest := range batch.Manifests {
+		if err := m.receive(batch, manifest, replicas, now); err != nil {
+			log.Warnf("receive manifest %s of agreement %s err:%s", manifest.CID, batch.AgreementID, err.Error())
+			if i == 0 {
+				return 0, err
+			}
+			return i, nil
+		}
+	}
+
+	return len(batch.Manifests), nil
+}
+
+// receive creates the asset of the manifest, the expired assets and the assets existing already are skipped
+func (m *Manager) receive(batch *types.MirrorManifestBatch, manifest *types.MirrorManifest, replicas int64, now time.Time) error {
+	if manifest.Expiration.Before(now) {
+		return nil
+	}
+
+	_, err := m.LoadAssetRecord(manifest.Hash)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	err = m.SaveMirroredAsset(&types.MirroredAsset{
+		Hash:           manifest.Hash,
+		CID:            manifest.CID,
+		AgreementID:    batch.AgreementID,
+		TenantID:       batch.TenantID,
+		SourceServerID: batch.SourceServerID,
+		SourceAreaID:   batch.SourceAreaID,
+	})
+	if err != nil {
+		return xerrors.Errorf("SaveMirroredAsset err:%s", err.Error())
+	}
+
+	return m.assetMgr.CreateAssetPullTask(&types.PullAssetReq{
+		CID:        manifest.CID,
+		Hash:       manifest.Hash,
+		Replicas:   replicas,
+		Expiration: manifest.Expiration,
+	})
+}
+
+// seedSources returns the download sources of the mirrored asset on its source scheduler, the seed of the asset is
+// pulled from ipfs if the source scheduler is unreachable
+func (m *Manager) seedSources(hash, cid string) []*types.CandidateDownloadInfo {
+	asset, err := m.LoadMirroredAsset(hash)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Errorf("LoadMirroredAsset %s err:%s", hash, err.Error())
+		}
+		return nil
+	}
+
+	peer, err := m.peerOf(asset.SourceServerID, asset.SourceAreaID)
+	if err != nil {
+		log.Warnf("source of mirrored asset %s err:%s", cid, err.Error())
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	sources, err := peer.GetCandidateDownloadInfos(ctx, cid)
+	if err != nil {
+		log.Warnf("GetCandidateDownloadInfos %s from scheduler %s err:%s", cid, asset.SourceServerID, err.Error())
+		return nil
+	}
+
+	return sources
+}
+
+func (m *Manager) startSyncTimer() {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !m.leadershipMgr.RequestAndBecomeMaster() {
+			continue
+		}
+
+		m.sync()
+	}
+}
+
+// sync streams the manifests of the active agreements
+func (m *Manager) sync() {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get scheduler config err:%s", err.Error())
+		return
+	}
+
+	list, err := m.LoadReplicationAgreements("")
+	if err != nil {
+		log.Errorf("LoadReplicationAgreements err:%s", err.Error())
+		return
+	}
+
+	for _, agreement := range list {
+		if agreement.State != types.ReplicationAgreementActive {
+			continue
+		}
+
+		for i := 0; i < batchesPerRound; i++ {
+			if !m.stream(agreement, cfg.AreaID) {
+				break
+			}
+		}
+	}
+}
+
+// stream streams a batch of the manifests of the agreement to a scheduler of the peer area and moves the cursor of
+// the agreement, returns true if the batch was full and accepted, so the next batch is streamed
+func (m *Manager) stream(agreement *types.ReplicationAgreement, areaID string) bool {
+	manifests, err := m.LoadTenantManifests(agreement.TenantID, agreement.CursorTime, agreement.CursorHash, time.Now().Add(-settleDelay), batchSize)
+	if err != nil {
+		log.Errorf("LoadTenantManifests %s err:%s", agreement.ID, err.Error())
+		return false
+	}
+
+	if len(manifests) == 0 {
+		return false
+	}
+
+	accepted, err := m.send(agreement, areaID, manifests)
+	if accepted > len(manifests) {
+		accepted = len(manifests)
+	}
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+		if len(message) > maxMessageLen {
+			message = message[:maxMessageLen]
+		}
+		log.Warnf("stream agreement %s to area %s err:%s", agreement.ID, agreement.PeerAreaID, message)
+	}
+
+	var last *types.MirrorManifest
+	if accepted > 0 {
+		last = manifests[accepted-1]
+	}
+
+	if err := m.UpdateReplicationProgress(agreement.ID, last, accepted, message); err != nil {
+		log.Errorf("UpdateReplicationProgress %s err:%s", agreement.ID, err.Error())
+		return false
+	}
+
+	if last != nil {
+		agreement.CursorTime = last.CreatedTime
+		agreement.CursorHash = last.Hash
+	}
+
+	return err == nil && accepted == batchSize
+}
+
+// send sends the manifests to a scheduler of the peer area
+func (m *Manager) send(agreement *types.ReplicationAgreement, areaID string, manifests []*types.MirrorManifest) (int, error) {
+	peer, err := m.peerOf("", agreement.PeerAreaID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	return peer.ReceiveMirrorManifests(ctx, &types.MirrorManifestBatch{
+		AgreementID:    agreement.ID,
+		TenantID:       agreement.TenantID,
+		SourceServerID: string(m.serverID),
+		SourceAreaID:   areaID,
+		Replicas:       agreement.Replicas,
+		Manifests:      manifests,
+	})
+}
+
+// peerOf returns the client of the scheduler, or of a scheduler of the area if the scheduler is not registered
+func (m *Manager) peerOf(serverID, areaID string) (api.Scheduler, error) {
+	var candidates []*types.SchedulerCfg
+	for _, peer := range m.discoveryMgr.Peers() {
+		if serverID != "" && peer.ServerID == serverID {
+			candidates = []*types.SchedulerCfg{peer}
+			break
+		}
+
+		if peer.AreaID == areaID {
+			candidates = append(candidates, peer)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, xerrors.Errorf("no scheduler of area %s registered", areaID)
+	}
+
+	// the least loaded scheduler of the area first
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].CPUUsage < candidates[j].CPUUsage
+	})
+
+	return m.client(candidates[0])
+}
+
+// client returns the client of the scheduler, the clients are kept by the url and the token of the schedulers
+func (m *Manager) client(cfg *types.SchedulerCfg) (api.Scheduler, error) {
+	key := fmt.Sprintf("%s/%s", cfg.SchedulerURL, cfg.AccessToken)
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	if peer, ok := m.peers[key]; ok {
+		return peer, nil
+	}
+
+	headers := http.Header{}
+	headers.Add("Authorization", "Bearer "+cfg.AccessToken)
+	peer, _, err := client.NewScheduler(context.Background(), cfg.SchedulerURL, headers, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+	if err != nil {
+		return nil, xerrors.Errorf("new scheduler client %s err:%s", cfg.SchedulerURL, err.Error())
+	}
+
+	m.peers[key] = peer
+	return peer, nil
+}
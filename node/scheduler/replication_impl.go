@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+// CreateReplicationAgreement creates the agreement mirroring all the assets of the tenant into the schedulers of the peer area
+func (s *Scheduler) CreateReplicationAgreement(ctx context.Context, agreement *types.ReplicationAgreement) (string, error) {
+	if agreement != nil && s.TenantManager.Tenant(agreement.TenantID) == nil {
+		return "", xerrors.Errorf("tenant %s not found", agreement.TenantID)
+	}
+
+	return s.ReplicationManager.CreateAgreement(agreement)
+}
+
+// SetReplicationAgreementState pauses or resumes the agreement
+func (s *Scheduler) SetReplicationAgreementState(ctx context.Context, id string, state types.ReplicationAgreementState) error {
+	if state != types.ReplicationAgreementActive && state != types.ReplicationAgreementPaused {
+		return xerrors.Errorf("invalid agreement state %s", state)
+	}
+
+	return s.db.UpdateReplicationAgreementState(id, state)
+}
+
+// RemoveReplicationAgreement removes the agreement, the assets mirrored already are kept in the peer area
+func (s *Scheduler) RemoveReplicationAgreement(ctx context.Context, id string) error {
+	return s.db.DeleteReplicationAgreement(id)
+}
+
+// ListReplicationAgreements get the agreements of the tenant, a caller scoped to a tenant gets the agreements of its tenant only
+func (s *Scheduler) ListReplicationAgreements(ctx context.Context, tenantID string) ([]*types.ReplicationAgreement, error) {
+	if scope := api.GetTenant(ctx); scope != "" && tenantID == "" {
+		tenantID = scope
+	}
+
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return s.db.LoadReplicationAgreements(tenantID)
+}
+
+// ReceiveMirrorManifests creates the assets of the manifests streamed by a scheduler of another area,
+// the callers scoped to a tenant can not stream manifests
+func (s *Scheduler) ReceiveMirrorManifests(ctx context.Context, batch *types.MirrorManifestBatch) (int, error) {
+	if api.GetTenant(ctx) != "" {
+		return 0, &api.ErrWeb{Code: terrors.TenantAccessDenied.Int(), Message: "can not stream the manifests of another area"}
+	}
+
+	return s.ReplicationManager.Receive(batch)
+}
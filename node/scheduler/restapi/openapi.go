@@ -0,0 +1,154 @@
+package restapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+const openAPIVersion = "3.0.3"
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	pointsType = reflect.TypeOf(types.Points{})
+)
+
+// Spec generates the openapi spec of the routes from their response types
+func (s *Server) Spec() map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+
+	for _, rt := range s.routes {
+		params := make([]interface{}, 0, len(rt.Params))
+		for _, p := range rt.Params {
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.In == "path",
+				"description": p.Description,
+				"schema":      map[string]interface{}{"type": p.Type},
+			})
+		}
+
+		paths[PathPrefix+rt.Path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    rt.Summary,
+				"parameters": params,
+				"security":   []interface{}{map[string]interface{}{"bearerAuth": []string{}}},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "OK",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": typeSchema(rt.Response, schemas)},
+						},
+					},
+					"default": map[string]interface{}{
+						"description": "Error",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": typeSchema(reflect.TypeOf(errorResponse{}), schemas)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "Titan scheduler rest api",
+			"version": strings.TrimPrefix(PathPrefix, "/rest/"),
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+// typeSchema returns the json schema of t, named structs are added to schemas and referenced
+func typeSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if t == pointsType {
+		return map[string]interface{}{"type": "number"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return structSchema(t, schemas)
+		}
+
+		if _, ok := schemas[name]; !ok {
+			// reserve the name first, the struct may reference itself
+			schemas[name] = nil
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]interface{}{}
+}
+
+// structSchema returns the schema of the struct fields as encoding/json marshals them
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	addStructFields(t, properties, schemas)
+
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func addStructFields(t reflect.Type, properties map[string]interface{}, schemas map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := field.Name
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+			name = tagName
+		}
+
+		// embedded structs without json name are flattened by encoding/json
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(field.Type, properties, schemas)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		properties[name] = typeSchema(field.Type, schemas)
+	}
+}
@@ -0,0 +1,267 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/gorilla/mux"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("restapi")
+
+const (
+	// PathPrefix the path prefix of the rest api
+	PathPrefix = "/rest/v0"
+
+	defaultLimit = 20
+)
+
+// param describes a path or query parameter of a route
+type param struct {
+	Name        string
+	In          string // path or query
+	Type        string // string, integer or boolean
+	Description string
+}
+
+// route describes a read-only rest route
+type route struct {
+	Path     string
+	Summary  string
+	Params   []param
+	Response reflect.Type
+	handle   func(r *http.Request) (interface{}, error)
+}
+
+// Server exposes read-only scheduler queries as rest api
+type Server struct {
+	scheduler api.Scheduler
+	routes    []*route
+}
+
+// New creates a rest server, the scheduler is expected to be permissioned
+func New(scheduler api.Scheduler) *Server {
+	s := &Server{scheduler: scheduler}
+	s.routes = s.buildRoutes()
+	return s
+}
+
+// Register mounts the routes and the openapi spec on the router
+func (s *Server) Register(router *mux.Router) {
+	sub := router.PathPrefix(PathPrefix).Subrouter()
+	for _, rt := range s.routes {
+		sub.Handle(rt.Path, s.handler(rt)).Methods(http.MethodGet)
+	}
+
+	sub.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Spec())
+	}).Methods(http.MethodGet)
+}
+
+func (s *Server) buildRoutes() []*route {
+	nodeID := param{Name: "node_id", In: "path", Type: "string", Description: "node id"}
+	limit := param{Name: "limit", In: "query", Type: "integer", Description: "maximum number of entries"}
+	offset := param{Name: "offset", In: "query", Type: "integer", Description: "number of entries to skip"}
+
+	return []*route{
+		{
+			Path:    "/nodes",
+			Summary: "List nodes with filters, sorting and cursor pagination",
+			Params: []param{
+				{Name: "cursor", In: "query", Type: "string", Description: "next_cursor of the previous page"},
+				limit,
+				{Name: "sort_by", In: "query", Type: "string", Description: "node_id, profit, online_duration, last_seen, first_login_time or disk_space"},
+				{Name: "desc", In: "query", Type: "boolean", Description: "sort in descending order"},
+				{Name: "type", In: "query", Type: "integer", Description: "node type"},
+				{Name: "status", In: "query", Type: "integer", Description: "node status, can be repeated"},
+				{Name: "region", In: "query", Type: "string", Description: "area served by the scheduler, the zone of the nodes"},
+				{Name: "nat_type", In: "query", Type: "string", Description: "nat type of the node"},
+				{Name: "min_score", In: "query", Type: "integer", Description: "minimum score of the node"},
+				{Name: "max_score", In: "query", Type: "integer", Description: "maximum score of the node"},
+			},
+			Response: reflect.TypeOf(types.ListNodesCursorRsp{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.ListNodes(r.Context(), listNodesReq(r))
+			},
+		},
+		{
+			Path:     "/nodes/{node_id}",
+			Summary:  "Get information of the node",
+			Params:   []param{nodeID},
+			Response: reflect.TypeOf(types.NodeInfo{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.GetNodeInfo(r.Context(), mux.Vars(r)["node_id"])
+			},
+		},
+		{
+			Path:     "/nodes/{node_id}/validations",
+			Summary:  "List validation history of the node",
+			Params:   []param{nodeID, limit, offset},
+			Response: reflect.TypeOf(types.ListValidationResultRsp{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.GetValidationResults(r.Context(), mux.Vars(r)["node_id"], queryInt(r, "limit", defaultLimit), queryInt(r, "offset", 0))
+			},
+		},
+		{
+			Path:     "/assets/{cid}",
+			Summary:  "Get status of the asset",
+			Params:   []param{{Name: "cid", In: "path", Type: "string", Description: "asset cid"}},
+			Response: reflect.TypeOf(types.AssetRecord{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.GetAssetRecord(r.Context(), mux.Vars(r)["cid"])
+			},
+		},
+		{
+			Path:     "/leaderboard/points",
+			Summary:  "List the nodes with the most points",
+			Params:   []param{limit},
+			Response: reflect.TypeOf([]*types.NodePointsRank{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.GetPointsLeaderboard(r.Context(), queryInt(r, "limit", defaultLimit))
+			},
+		},
+		{
+			Path:    "/leaderboard",
+			Summary: "List the nodes ranked by points, traffic served or uptime in a period",
+			Params: []param{
+				{Name: "metric", In: "query", Type: "string", Description: "points, traffic or uptime, default points"},
+				{Name: "period", In: "query", Type: "string", Description: "24h, 7d or 30d, default 24h"},
+				{Name: "region", In: "query", Type: "string", Description: "area id of the scheduler"},
+				{Name: "type", In: "query", Type: "integer", Description: "node type"},
+				limit,
+				offset,
+			},
+			Response: reflect.TypeOf(types.LeaderboardRsp{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.GetLeaderboard(r.Context(), leaderboardReq(r))
+			},
+		},
+		{
+			Path:     "/dashboard",
+			Summary:  "Get the online nodes by area, the assets by state, the validation pass rates and the points distribution",
+			Response: reflect.TypeOf(types.DashboardStats{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.GetDashboardStats(r.Context())
+			},
+		},
+		{
+			Path:    "/points/estimate",
+			Summary: "Estimate the points an edge node with the hypothetical parameters would earn",
+			Params: []param{
+				{Name: "bandwidth_up", In: "query", Type: "integer", Description: "upload bandwidth in bytes per second"},
+				{Name: "nat_type", In: "query", Type: "string", Description: "NoNat, FullConeNAT, RestrictedNAT, PortRestrictedNAT or SymmetricNAT"},
+				{Name: "disk_usage", In: "query", Type: "integer", Description: "bytes of the disk used to store the assets"},
+				{Name: "nodes_on_ip", In: "query", Type: "integer", Description: "nodes sharing the external ip, default 1"},
+			},
+			Response: reflect.TypeOf(types.PointsEstimateRsp{}),
+			handle: func(r *http.Request) (interface{}, error) {
+				return s.scheduler.EstimatePoints(r.Context(), pointsEstimateReq(r))
+			},
+		},
+	}
+}
+
+func (s *Server) handler(rt *route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		out, err := rt.handle(r)
+		if err != nil {
+			log.Debugf("rest %s err:%s", r.URL.Path, err.Error())
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// listNodesReq parses the node listing query parameters
+func listNodesReq(r *http.Request) *types.ListNodesReq {
+	query := r.URL.Query()
+
+	req := &types.ListNodesReq{
+		Cursor:   query.Get("cursor"),
+		Limit:    queryInt(r, "limit", defaultLimit),
+		SortBy:   query.Get("sort_by"),
+		Desc:     query.Get("desc") == "true",
+		Type:     types.NodeType(queryInt(r, "type", int(types.NodeUnknown))),
+		Region:   query.Get("region"),
+		NATType:  query.Get("nat_type"),
+		MinScore: queryInt(r, "min_score", 0),
+		MaxScore: queryInt(r, "max_score", 0),
+	}
+
+	for _, v := range query["status"] {
+		if status, err := strconv.Atoi(v); err == nil {
+			req.Statuses = append(req.Statuses, types.NodeStatus(status))
+		}
+	}
+
+	return req
+}
+
+// leaderboardReq parses the leaderboard query parameters
+func leaderboardReq(r *http.Request) *types.LeaderboardReq {
+	query := r.URL.Query()
+
+	req := &types.LeaderboardReq{
+		Metric:   types.LeaderboardMetric(query.Get("metric")),
+		Period:   types.LeaderboardPeriod(query.Get("period")),
+		Region:   query.Get("region"),
+		NodeType: types.NodeType(queryInt(r, "type", int(types.NodeUnknown))),
+		Limit:    queryInt(r, "limit", defaultLimit),
+		Offset:   queryInt(r, "offset", 0),
+	}
+
+	if req.Metric == "" {
+		req.Metric = types.LeaderboardPoints
+	}
+	if req.Period == "" {
+		req.Period = types.LeaderboardDay
+	}
+
+	return req
+}
+
+// pointsEstimateReq parses the points estimate query parameters
+func pointsEstimateReq(r *http.Request) *types.PointsEstimateReq {
+	query := r.URL.Query()
+
+	return &types.PointsEstimateReq{
+		BandwidthUp:    int64(queryInt(r, "bandwidth_up", 0)),
+		NATType:        types.NatTypeUnknown.FromString(query.Get("nat_type")),
+		TitanDiskUsage: float64(queryInt(r, "disk_usage", 0)),
+		NodesOnIP:      queryInt(r, "nodes_on_ip", 1),
+	}
+}
+
+// queryInt returns the integer query parameter, def if the parameter is missing or invalid
+func queryInt(r *http.Request, name string, def int) int {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return def
+	}
+
+	return i
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("write response err:%s", err.Error())
+	}
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/gorilla/mux"
+)
+
+func TestNodeInfoRoute(t *testing.T) {
+	scheduler := &api.SchedulerStruct{}
+	scheduler.NodeAPIStruct.Internal.GetNodeInfo = func(ctx context.Context, nodeID string) (types.NodeInfo, error) {
+		info := types.NodeInfo{}
+		info.NodeID = nodeID
+		return info, nil
+	}
+
+	router := mux.NewRouter()
+	New(scheduler).Register(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathPrefix+"/nodes/e_1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var info types.NodeInfo
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+
+	if info.NodeID != "e_1" {
+		t.Fatalf("expected node e_1, got %s", info.NodeID)
+	}
+
+	// methods that are not implemented return an error
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathPrefix+"/assets/cid", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", rec.Code)
+	}
+}
+
+func TestSpecReferences(t *testing.T) {
+	spec := New(nil).Spec()
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	buf, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range strings.Split(string(buf), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		if _, ok := schemas[name]; !ok {
+			t.Fatalf("schema %s is referenced but not defined", name)
+		}
+	}
+
+	if _, ok := spec["paths"].(map[string]interface{})[PathPrefix+"/nodes/{node_id}"]; !ok {
+		t.Fatal("missing node path")
+	}
+}
+
+func TestListNodesReq(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, PathPrefix+"/nodes?cursor=abc&limit=5&sort_by=profit&desc=true&type=1&status=1&status=3&nat_type=NoNAT&min_score=60", nil)
+	req := listNodesReq(r)
+
+	if req.Cursor != "abc" || req.Limit != 5 || req.SortBy != "profit" || !req.Desc || req.Type != types.NodeEdge {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	if len(req.Statuses) != 2 || req.Statuses[0] != types.NodeServicing || req.Statuses[1] != types.NodeStatus(3) {
+		t.Fatalf("unexpected statuses %v", req.Statuses)
+	}
+
+	if req.NATType != "NoNAT" || req.MinScore != 60 || req.MaxScore != 0 {
+		t.Fatalf("unexpected filters %+v", req)
+	}
+}
@@ -0,0 +1,164 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/jobqueue"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("retention")
+
+const (
+	pruneInterval = time.Hour
+	// the rows deleted by a statement
+	pruneBatchSize = 5000
+
+	// jobPrune the kind of the jobs pruning the tables, run by any scheduler once an hour
+	jobPrune = "retention.prune"
+)
+
+// policy the retention of a table
+type policy struct {
+	table string
+	days  func(cfg *config.SchedulerCfg) int
+	prune func(sdb *db.SQLDB, before time.Time, limit int) (int64, error)
+}
+
+var policies = []policy{
+	{
+		table: "validation_result",
+		days:  func(cfg *config.SchedulerCfg) int { return cfg.ValidationResultRetentionDays },
+		prune: (*db.SQLDB).DeleteValidationResultsBefore,
+	},
+	{
+		table: "replica_event",
+		days:  func(cfg *config.SchedulerCfg) int { return cfg.ReplicaEventRetentionDays },
+		prune: (*db.SQLDB).DeleteReplicaEventsBefore,
+	},
+	{
+		table: "retrieve_event",
+		days:  func(cfg *config.SchedulerCfg) int { return cfg.RetrieveEventRetentionDays },
+		prune: (*db.SQLDB).DeleteRetrieveEventsBefore,
+	},
+	{
+		table: "workload_record",
+		days:  func(cfg *config.SchedulerCfg) int { return cfg.WorkloadRecordRetentionDays },
+		prune: (*db.SQLDB).DeleteWorkloadRecordsBefore,
+	},
+	{
+		table: "relay_session",
+		days:  func(cfg *config.SchedulerCfg) int { return cfg.RelaySessionRetentionDays },
+		prune: (*db.SQLDB).DeleteRelaySessionsBefore,
+	},
+	{
+		table: "webhook_delivery",
+		days:  func(cfg *config.SchedulerCfg) int { return cfg.WebhookDeliveryRetentionDays },
+		prune: (*db.SQLDB).DeleteWebhookDeliveriesBefore,
+	},
+}
+
+// Manager keeps the size of the high-volume tables bounded, the rows older than the retention of their tables
+// are pruned once an hour by a job of the job queue
+type Manager struct {
+	config dtypes.GetSchedulerConfigFunc
+	jobs   *jobqueue.Queue
+	*db.SQLDB
+}
+
+// NewManager return new retention manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, jq *jobqueue.Queue) *Manager {
+	m := &Manager{
+		config: configFunc,
+		jobs:   jq,
+		SQLDB:  sdb,
+	}
+
+	jq.Register(jobPrune, m.prune)
+	go m.startPruneTimer()
+
+	return m
+}
+
+func (m *Manager) startPruneTimer() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// the job is enqueued once an hour by all the schedulers
+		key := fmt.Sprintf("%s:%s", jobPrune, time.Now().UTC().Format("2006-01-02T15"))
+		if _, err := m.jobs.Enqueue(jobPrune, struct{}{}, jobqueue.Options{DedupKey: key}); err != nil && err != jobqueue.ErrDuplicateJob {
+			log.Errorf("enqueue %s err:%s", jobPrune, err.Error())
+		}
+	}
+}
+
+// prune deletes the rows older than the retention of each table, the tables failed are pruned by the next job
+func (m *Manager) prune(ctx context.Context, job *types.Job, progress jobqueue.Progress) error {
+	cfg, err := m.config()
+	if err != nil {
+		return xerrors.Errorf("get config err:%s", err.Error())
+	}
+
+	now := time.Now()
+	failed := 0
+	for i, p := range policies {
+		days := p.days(&cfg)
+		if days > 0 {
+			deleted, err := m.pruneTable(ctx, p, now.AddDate(0, 0, -days))
+			if err != nil {
+				log.Errorf("prune %s err:%s", p.table, err.Error())
+				failed++
+			}
+
+			if deleted > 0 {
+				log.Infof("pruned %d rows of %s older than %d days", deleted, p.table, days)
+			}
+		}
+
+		progress(int64(i+1), int64(len(policies)))
+	}
+
+	if failed > 0 {
+		return xerrors.Errorf("failed to prune %d tables", failed)
+	}
+
+	return nil
+}
+
+// pruneTable deletes the rows of the table before the time in batches and returns the rows deleted
+func (m *Manager) pruneTable(ctx context.Context, p policy, before time.Time) (int64, error) {
+	var total int64
+	defer func() {
+		if total > 0 {
+			tagCtx, _ := tag.New(context.Background(), tag.Upsert(metrics.Table, p.table))
+			stats.Record(tagCtx, metrics.SchedulerPrunedRows.M(total))
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		deleted, err := p.prune(m.SQLDB, before, pruneBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+		if deleted < pruneBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package retention
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+func TestPolicies(t *testing.T) {
+	cfg := config.DefaultSchedulerCfg()
+
+	tables := make(map[string]bool)
+	for _, p := range policies {
+		if tables[p.table] {
+			t.Fatalf("duplicate policy of %s", p.table)
+		}
+		tables[p.table] = true
+
+		if p.days(cfg) <= 0 {
+			t.Errorf("%s is not pruned by default", p.table)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package retrievalprobe
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("retrievalprobe")
+
+const (
+	// bytes of the asset retrieved by a probe
+	probeRangeSize = 256 << 10
+	probeTimeout   = 30 * time.Second
+	// the signed urls of the probes are valid for the lifetime
+	signedURLLifetime = 5 * time.Minute
+	// the asset of a probe is picked from the latest replicas of the node
+	maxReplicasPicked = 50
+	// the nodes probed at once
+	maxProbing = 5
+	// the interval is read from the config again after the delay if the probes are disabled
+	disabledCheckDelay = time.Minute
+	cleanInterval      = time.Hour
+)
+
+// Manager probes a sample of the nodes connected to the scheduler with small ranged retrievals of their replicas
+// by signed urls, records the time to first byte and the throughput for the sla reports, and counts the slow
+// or failed probes in the penalty rules
+type Manager struct {
+	config     dtypes.GetSchedulerConfigFunc
+	nodeMgr    *node.Manager
+	penaltyMgr *penalty.Manager
+	keyRing    *keys.Ring
+	*db.SQLDB
+
+	client *http.Client
+
+	proxyLk sync.Mutex
+	// clients of the proxies of the designated probes the spot checks are sent through
+	proxyClients map[string]*http.Client
+}
+
+// NewManager return new retrieval probe manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, pmgr *penalty.Manager, keyRing *keys.Ring, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	m := &Manager{
+		config:     configFunc,
+		nodeMgr:    nmgr,
+		penaltyMgr: pmgr,
+		keyRing:    keyRing,
+		SQLDB:      sdb,
+		client:     &http.Client{Timeout: probeTimeout},
+
+		proxyClients: make(map[string]*http.Client),
+	}
+
+	go m.startProbeTimer()
+	go m.startSpotCheckTimer()
+	go m.startCleanTimer()
+
+	return m
+}
+
+// GetSLAReport returns the sla of the retrievals probed in [start, end) by node or area, the worst sla first
+func (m *Manager) GetSLAReport(groupBy types.RetrievalSLAGroup, start, end time.Time, limit, offset int) (*types.RetrievalSLAReport, error) {
+	if !start.Before(end) {
+		return nil, xerrors.New("start must be before end")
+	}
+
+	return m.LoadRetrievalSLA(groupBy, start, end, limit, offset)
+}
+
+func (m *Manager) startProbeTimer() {
+	for {
+		cfg, err := m.config()
+		if err != nil {
+			log.Errorf("get scheduler config err:%s", err.Error())
+			time.Sleep(disabledCheckDelay)
+			continue
+		}
+
+		if cfg.RetrievalProbeInterval <= 0 {
+			time.Sleep(disabledCheckDelay)
+			continue
+		}
+
+		time.Sleep(time.Duration(cfg.RetrievalProbeInterval) * time.Minute)
+		m.probeRound(&cfg)
+	}
+}
+
+func (m *Manager) startCleanTimer() {
+	ticker := time.NewTicker(cleanInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		cfg, err := m.config()
+		if err != nil {
+			log.Errorf("get scheduler config err:%s", err.Error())
+			continue
+		}
+
+		if cfg.RetrievalProbeRetentionDays <= 0 {
+			continue
+		}
+
+		before := time.Now().AddDate(0, 0, -cfg.RetrievalProbeRetentionDays)
+		if err := m.DeleteRetrievalProbesBefore(before); err != nil {
+			log.Errorf("DeleteRetrievalProbesBefore err:%s", err.Error())
+		}
+
+		if err := m.DeleteSpotChecksBefore(before); err != nil {
+			log.Errorf("DeleteSpotChecksBefore err:%s", err.Error())
+		}
+	}
+}
+
+// sample picks the nodes of the round, the edges behind a nat the scheduler can not reach are skipped
+func (m *Manager) sample(size int) []*node.Node {
+	_, nodes := m.nodeMgr.GetAllValidCandidateNodes()
+	nodes = append(nodes, m.reachableEdges()...)
+
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+
+	if len(nodes) > size {
+		nodes = nodes[:size]
+	}
+	return nodes
+}
+
+// reachableEdges returns the edges not behind a nat the scheduler can not reach
+func (m *Manager) reachableEdges() []*node.Node {
+	var edges []*node.Node
+	for _, n := range m.nodeMgr.GetAllEdgeNode() {
+		if n.NATType == types.NatTypeNo || n.NATType == types.NatTypeFullCone {
+			edges = append(edges, n)
+		}
+	}
+
+	return edges
+}
+
+// probeRound probes the sampled nodes, saves the probes and counts them in the penalty rules
+func (m *Manager) probeRound(cfg *config.SchedulerCfg) {
+	nodes := m.sample(cfg.RetrievalProbeSampleSize)
+
+	var lk sync.Mutex
+	var wg sync.WaitGroup
+	probes := make([]*types.RetrievalProbe, 0, len(nodes))
+	probing := make(chan struct{}, maxProbing)
+
+	for _, n := range nodes {
+		probing <- struct{}{}
+		wg.Add(1)
+		go func(n *node.Node) {
+			defer func() {
+				<-probing
+				wg.Done()
+			}()
+
+			probe, err := m.probeNode(cfg, n)
+			if err != nil {
+				log.Debugf("probe %s err:%s", n.NodeID, err.Error())
+				return
+			}
+
+			lk.Lock()
+			probes = append(probes, probe)
+			lk.Unlock()
+		}(n)
+	}
+	wg.Wait()
+
+	if err := m.SaveRetrievalProbes(probes); err != nil {
+		log.Errorf("SaveRetrievalProbes err:%s", err.Error())
+	}
+
+	for _, probe := range probes {
+		m.penaltyMgr.RetrievalProbed(probe.NodeID, probe.Success && !probe.Slow, probe.CreatedTime)
+	}
+}
+
+// probeNode retrieves the head of a replica of the node, an error is returned if the node can not be probed,
+// e.g. it has no replica, the failed retrievals are returned as failed probes
+func (m *Manager) probeNode(cfg *config.SchedulerCfg, n *node.Node) (*types.RetrievalProbe, error) {
+	replicas, err := m.LoadSucceedReplicasByNodeID(n.NodeID, maxReplicasPicked, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replicas.NodeAssetInfos) == 0 {
+		return nil, xerrors.New("no replica")
+	}
+
+	asset := replicas.NodeAssetInfos[rand.Intn(len(replicas.NodeAssetInfos))]
+	probeURL, err := m.signedURL(n, asset.Cid)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeSize := int64(probeRangeSize)
+	if asset.TotalSize > 0 && asset.TotalSize < rangeSize {
+		rangeSize = asset.TotalSize
+	}
+
+	probe := &types.RetrievalProbe{NodeID: n.NodeID, NodeType: n.Type, AreaID: n.AreaID, CID: asset.Cid, CreatedTime: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	ttfb, bytes, duration, err := retrieve(ctx, m.client, probeURL, rangeSize)
+	probe.TTFB, probe.Bytes, probe.Duration = ttfb.Milliseconds(), bytes, duration.Milliseconds()
+	if err != nil {
+		probe.Message = err.Error()
+		if len(probe.Message) > 512 {
+			probe.Message = probe.Message[:512]
+		}
+		return probe, nil
+	}
+
+	probe.Success = true
+	if duration > 0 {
+		probe.Throughput = int64(float64(bytes) / duration.Seconds())
+	}
+	probe.Slow = isSlow(cfg, probe, rangeSize)
+
+	return probe, nil
+}
+
+// isSlow returns true if the probe exceeds the ttfb threshold, the throughput is only judged on the full range,
+// the transfers of the small assets are dominated by the first byte
+func isSlow(cfg *config.SchedulerCfg, probe *types.RetrievalProbe, rangeSize int64) bool {
+	if cfg.RetrievalProbeSlowTTFB > 0 && probe.TTFB > int64(cfg.RetrievalProbeSlowTTFB) {
+		return true
+	}
+
+	return rangeSize >= probeRangeSize && cfg.RetrievalProbeMinThroughput > 0 && probe.Throughput < cfg.RetrievalProbeMinThroughput
+}
+
+// signedURL returns the url of the asset on the node signed by the scheduler, the external url of the node is preferred
+func (m *Manager) signedURL(n *node.Node, assetCID string) (string, error) {
+	expires := time.Now().Add(signedURLLifetime).Unix()
+	sign, err := m.keyRing.Sign(types.SignedURLContent(assetCID, expires))
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set(types.SignedURLExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(types.SignedURLSignatureParam, hex.EncodeToString(sign))
+
+	address := fmt.Sprintf("http://%s", n.DownloadAddr())
+	if len(n.ExternalURL) > 0 {
+		address = n.ExternalURL
+	}
+
+	return fmt.Sprintf("%s/ipfs/%s?%s", address, assetCID, query.Encode()), nil
+}
+
+// retrieve gets the first bytes of the url by a ranged request, returns the time to the first byte of the response,
+// the bytes received and the duration of the whole retrieval
+func retrieve(ctx context.Context, client *http.Client, u string, rangeSize int64) (time.Duration, int64, time.Duration, error) {
+	start := time.Now()
+	var ttfb time.Duration
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			ttfb = time.Since(start)
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, u, nil)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", rangeSize-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ttfb, 0, time.Since(start), err
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return ttfb, 0, time.Since(start), xerrors.Errorf("status code %d", resp.StatusCode)
+	}
+
+	bytes, err := io.Copy(io.Discard, io.LimitReader(resp.Body, rangeSize))
+	duration := time.Since(start)
+	if err != nil {
+		return ttfb, bytes, duration, err
+	}
+
+	if bytes < rangeSize {
+		return ttfb, bytes, duration, xerrors.Errorf("received %d of %d bytes", bytes, rangeSize)
+	}
+
+	return ttfb, bytes, duration, nil
+}
@@ -0,0 +1,56 @@
+package retrievalprobe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+func TestRetrieve(t *testing.T) {
+	content := strings.Repeat("x", 1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "asset", time.Time{}, strings.NewReader(content))
+	}))
+	defer srv.Close()
+
+	_, bytes, _, err := retrieve(context.Background(), srv.Client(), srv.URL, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes != 100 {
+		t.Fatalf("expected 100 bytes, got %d", bytes)
+	}
+
+	if _, _, _, err := retrieve(context.Background(), srv.Client(), srv.URL, 2048); err == nil {
+		t.Fatal("expected the short retrieval to fail")
+	}
+}
+
+func TestIsSlow(t *testing.T) {
+	cfg := &config.SchedulerCfg{RetrievalProbeSlowTTFB: 1000, RetrievalProbeMinThroughput: 100 << 10}
+
+	cases := []struct {
+		probe     *types.RetrievalProbe
+		rangeSize int64
+		slow      bool
+	}{
+		{&types.RetrievalProbe{TTFB: 200, Throughput: 1 << 20}, probeRangeSize, false},
+		{&types.RetrievalProbe{TTFB: 1500, Throughput: 1 << 20}, probeRangeSize, true},
+		{&types.RetrievalProbe{TTFB: 200, Throughput: 10 << 10}, probeRangeSize, true},
+		// the throughput of a small asset is not judged
+		{&types.RetrievalProbe{TTFB: 200, Throughput: 10 << 10}, 1024, false},
+	}
+
+	for i, c := range cases {
+		if got := isSlow(cfg, c.probe, c.rangeSize); got != c.slow {
+			t.Errorf("case %d: expected slow %v, got %v", i, c.slow, got)
+		}
+	}
+}
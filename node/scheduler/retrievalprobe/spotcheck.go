@@ -0,0 +1,313 @@
+package retrievalprobe
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the range of a spot check is sized at random between the bounds
+	minSpotCheckRange = 64 << 10
+	maxSpotCheckRange = 1 << 20
+	// the signed urls of the spot checks are valid for a random lifetime between the bounds, as the urls shared by the users
+	minSpotCheckURLLifetime = time.Hour
+	maxSpotCheckURLLifetime = 7 * 24 * time.Hour
+)
+
+// userAgents the browsers the spot checks pose as
+var userAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+}
+
+// GetSpotChecks returns the spot checks of the node, the latest first
+func (m *Manager) GetSpotChecks(nodeID string, limit, offset int) (*types.ListSpotCheckRsp, error) {
+	return m.LoadSpotChecks(nodeID, limit, offset)
+}
+
+func (m *Manager) startSpotCheckTimer() {
+	for {
+		cfg, err := m.config()
+		if err != nil {
+			log.Errorf("get scheduler config err:%s", err.Error())
+			time.Sleep(disabledCheckDelay)
+			continue
+		}
+
+		if cfg.SpotChecksPerHour <= 0 {
+			time.Sleep(disabledCheckDelay)
+			continue
+		}
+
+		time.Sleep(spotCheckDelay(cfg.SpotChecksPerHour, rand.ExpFloat64()))
+		m.spotCheck(&cfg)
+	}
+}
+
+// spotCheckDelay returns the delay before the next spot check, the checks arrive as a poisson process so the edges
+// can not tell when the next check comes. exp is a sample of the standard exponential distribution
+func spotCheckDelay(perHour int, exp float64) time.Duration {
+	return time.Duration(exp * float64(time.Hour) / float64(perHour))
+}
+
+// spotCheck checks a random edge, saves the check and counts it in the penalty rules
+func (m *Manager) spotCheck(cfg *config.SchedulerCfg) {
+	edges := m.reachableEdges()
+	if len(edges) == 0 {
+		return
+	}
+
+	n := edges[rand.Intn(len(edges))]
+	check, err := m.spotCheckNode(cfg, n)
+	if err != nil {
+		log.Debugf("spot check %s err:%s", n.NodeID, err.Error())
+		return
+	}
+
+	if err := m.SaveSpotCheck(check); err != nil {
+		log.Errorf("SaveSpotCheck err:%s", err.Error())
+	}
+
+	if check.Mismatched {
+		log.Warnf("edge %s served the wrong bytes of asset %s to a spot check", check.NodeID, check.CID)
+		m.penaltyMgr.SpotCheckMismatched(check.NodeID, check.CID)
+		return
+	}
+
+	m.penaltyMgr.RetrievalProbed(check.NodeID, check.Success && !check.Slow, check.CreatedTime)
+}
+
+// spotCheckNode retrieves a random range of a replica of the edge as a client would and compares the bytes with the same
+// range retrieved from a candidate, an error is returned if the edge can not be checked, e.g. it has no replica
+func (m *Manager) spotCheckNode(cfg *config.SchedulerCfg, n *node.Node) (*types.SpotCheck, error) {
+	replicas, err := m.LoadSucceedReplicasByNodeID(n.NodeID, maxReplicasPicked, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replicas.NodeAssetInfos) == 0 {
+		return nil, xerrors.New("no replica")
+	}
+
+	asset := replicas.NodeAssetInfos[rand.Intn(len(replicas.NodeAssetInfos))]
+	start, size := spotCheckRange(asset.TotalSize, minSpotCheckRange+rand.Int63n(maxSpotCheckRange-minSpotCheckRange), rand.Int63)
+	if size <= 0 {
+		return nil, xerrors.Errorf("asset %s is too small", asset.Cid)
+	}
+
+	checkURL, err := m.clientURL(n, asset.Hash, asset.Cid)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, client, err := m.spotCheckClient(cfg.SpotCheckProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &types.SpotCheck{
+		NodeID:           n.NodeID,
+		AreaID:           n.AreaID,
+		CID:              asset.Cid,
+		RangeStart:       start,
+		RangeSize:        size,
+		Proxy:            proxy,
+		ClaimedBandwidth: n.BandwidthUp,
+		CreatedTime:      time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	data, ttfb, duration, err := retrieveRange(ctx, client, checkURL, start, size, userAgents[rand.Intn(len(userAgents))])
+	check.TTFB = ttfb.Milliseconds()
+	if err != nil {
+		check.Message = err.Error()
+		if len(check.Message) > 512 {
+			check.Message = check.Message[:512]
+		}
+		return check, nil
+	}
+
+	check.Success = true
+	if duration > 0 {
+		check.Throughput = int64(float64(len(data)) / duration.Seconds())
+	}
+	check.Slow = check.ClaimedBandwidth > 0 && cfg.SpotCheckMinSpeedRatio > 0 &&
+		float64(check.Throughput) < float64(check.ClaimedBandwidth)*cfg.SpotCheckMinSpeedRatio
+
+	reference, err := m.referenceRange(asset.Hash, asset.Cid, start, size)
+	if err != nil {
+		check.Message = fmt.Sprintf("not verified: %s", err.Error())
+		return check, nil
+	}
+
+	check.Verified = true
+	check.Mismatched = !bytes.Equal(data, reference)
+
+	return check, nil
+}
+
+// spotCheckRange returns a random range of the size in the asset, the range stays clear of the tail of the asset because
+// the total size of the blocks exceeds the size of the file served. The range is shrunk to fit a small asset
+func spotCheckRange(totalSize, size int64, random func() int64) (int64, int64) {
+	limit := totalSize * 9 / 10
+	if limit <= size {
+		return 0, limit
+	}
+
+	return random() % (limit - size + 1), size
+}
+
+// referenceRange retrieves the range of the asset from a candidate holding a replica of it
+func (m *Manager) referenceRange(hash, assetCID string, start, size int64) ([]byte, error) {
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return nil, err
+	}
+
+	rand.Shuffle(len(replicas), func(i, j int) {
+		replicas[i], replicas[j] = replicas[j], replicas[i]
+	})
+
+	err = xerrors.New("no candidate holds the asset")
+	for _, replica := range replicas {
+		candidate := m.nodeMgr.GetCandidateNode(replica.NodeID)
+		if candidate == nil {
+			continue
+		}
+
+		var u string
+		if u, err = m.signedURL(candidate, assetCID); err != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		var data []byte
+		data, _, _, err = retrieveRange(ctx, m.client, u, start, size, userAgents[0])
+		cancel()
+		if err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, err
+}
+
+// clientURL returns a url of the asset on the edge signed the way the urls shared by the users are,
+// with a random lifetime and the name an owner gave the asset
+func (m *Manager) clientURL(n *node.Node, hash, assetCID string) (string, error) {
+	lifetime := minSpotCheckURLLifetime + time.Duration(rand.Int63n(int64(maxSpotCheckURLLifetime-minSpotCheckURLLifetime)))
+	expires := time.Now().Add(lifetime).Unix()
+	sign, err := m.keyRing.Sign(types.SignedURLContent(assetCID, expires))
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set(types.SignedURLExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(types.SignedURLSignatureParam, hex.EncodeToString(sign))
+
+	name, err := m.LoadAnyAssetName(hash)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		query.Set("filename", name)
+	}
+
+	address := fmt.Sprintf("http://%s", n.DownloadAddr())
+	if len(n.ExternalURL) > 0 {
+		address = n.ExternalURL
+	}
+
+	return fmt.Sprintf("%s/ipfs/%s?%s", address, assetCID, query.Encode()), nil
+}
+
+// spotCheckClient returns the client of a random proxy of the designated probes, the client of the scheduler if no proxy
+func (m *Manager) spotCheckClient(proxies []string) (string, *http.Client, error) {
+	if len(proxies) == 0 {
+		return "", m.client, nil
+	}
+
+	proxy := proxies[rand.Intn(len(proxies))]
+
+	m.proxyLk.Lock()
+	defer m.proxyLk.Unlock()
+
+	if client, ok := m.proxyClients[proxy]; ok {
+		return proxy, client, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return "", nil, xerrors.Errorf("parse proxy %s: %w", proxy, err)
+	}
+
+	client := &http.Client{Timeout: probeTimeout, Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	m.proxyClients[proxy] = client
+
+	return proxy, client, nil
+}
+
+// retrieveRange gets the range of the url as a browser would, returns the bytes, the time to the first byte of the response
+// and the duration of the whole retrieval
+func retrieveRange(ctx context.Context, client *http.Client, u string, start, size int64, userAgent string) ([]byte, time.Duration, time.Duration, error) {
+	begin := time.Now()
+	var ttfb time.Duration
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			ttfb = time.Since(begin)
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, start+size-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, ttfb, time.Since(begin), err
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	// a server ignoring the range answers the whole content, only the head is usable then
+	if resp.StatusCode != http.StatusPartialContent && (resp.StatusCode != http.StatusOK || start > 0) {
+		return nil, ttfb, time.Since(begin), xerrors.Errorf("status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, size))
+	duration := time.Since(begin)
+	if err != nil {
+		return data, ttfb, duration, err
+	}
+
+	if int64(len(data)) < size {
+		return data, ttfb, duration, xerrors.Errorf("received %d of %d bytes", len(data), size)
+	}
+
+	return data, ttfb, duration, nil
+}
@@ -0,0 +1,68 @@
+package retrievalprobe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetrieveRange(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		http.ServeContent(w, r, "asset", time.Time{}, strings.NewReader(content))
+	}))
+	defer srv.Close()
+
+	data, _, _, err := retrieveRange(context.Background(), srv.Client(), srv.URL, 15, 10, userAgents[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "5678901234" {
+		t.Fatalf("unexpected bytes %q", data)
+	}
+
+	if userAgent != userAgents[0] {
+		t.Fatalf("expected the user agent of a browser, got %q", userAgent)
+	}
+
+	if _, _, _, err := retrieveRange(context.Background(), srv.Client(), srv.URL, 995, 10, userAgents[0]); err == nil {
+		t.Fatal("expected the short retrieval to fail")
+	}
+
+	// a server ignoring the range can not serve a range after the head
+	whole := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content)) //nolint:errcheck // ignore error
+	}))
+	defer whole.Close()
+
+	if _, _, _, err := retrieveRange(context.Background(), whole.Client(), whole.URL, 15, 10, userAgents[0]); err == nil {
+		t.Fatal("expected the retrieval ignoring the range to fail")
+	}
+}
+
+func TestSpotCheckRange(t *testing.T) {
+	random := func() int64 { return 1 << 40 }
+
+	start, size := spotCheckRange(1000, 100, random)
+	if size != 100 || start < 0 || start+size > 900 {
+		t.Fatalf("range [%d, %d) out of the head of the asset", start, start+size)
+	}
+
+	// the range is shrunk to fit a small asset
+	start, size = spotCheckRange(100, 200, random)
+	if start != 0 || size != 90 {
+		t.Fatalf("expected range [0, 90), got [%d, %d)", start, start+size)
+	}
+}
+
+func TestSpotCheckDelay(t *testing.T) {
+	if d := spotCheckDelay(60, 1); d != time.Minute {
+		t.Fatalf("expected the mean delay of a minute, got %s", d)
+	}
+}
@@ -0,0 +1,23 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// ListRetrievalProbes retrieves the retrieval probes of the node, the latest first
+func (s *Scheduler) ListRetrievalProbes(ctx context.Context, nodeID string, limit, offset int) (*types.ListRetrievalProbeRsp, error) {
+	return s.RetrievalProbeManager.LoadRetrievalProbes(nodeID, limit, offset)
+}
+
+// ListSpotChecks retrieves the spot checks of the edge disguised as client retrievals, the latest first
+func (s *Scheduler) ListSpotChecks(ctx context.Context, nodeID string, limit, offset int) (*types.ListSpotCheckRsp, error) {
+	return s.RetrievalProbeManager.GetSpotChecks(nodeID, limit, offset)
+}
+
+// GetRetrievalSLAReport retrieves the sla of the retrievals probed in [start, end) by node or area, the worst sla first
+func (s *Scheduler) GetRetrievalSLAReport(ctx context.Context, groupBy types.RetrievalSLAGroup, start, end time.Time, limit, offset int) (*types.RetrievalSLAReport, error) {
+	return s.RetrievalProbeManager.GetSLAReport(groupBy, start, end, limit, offset)
+}
@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/sigv4"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// number of random bytes of the s3 access key and secret key
+	s3AccessKeyBytes = 10
+	s3SecretKeyBytes = 30
+	// the objects of an authorized request are put by the candidate within the ttl
+	s3GrantTTL = time.Hour
+)
+
+// CreateS3AccessKey creates an access key of the user for the s3 gateway
+func (s *Scheduler) CreateS3AccessKey(ctx context.Context, userID string) (*types.S3AccessKey, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	if len(userID) == 0 {
+		return nil, xerrors.New("user id can not empty")
+	}
+
+	keys, err := s.db.LoadS3AccessKeysOfUser(userID)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if len(keys) >= s.SchedulerCfg.MaxAPIKey {
+		return nil, &api.ErrWeb{Code: terrors.OutOfMaxAPIKeyLimit.Int(), Message: fmt.Sprintf("s3 access key exceeds maximum limit %d", s.SchedulerCfg.MaxAPIKey)}
+	}
+
+	accessKey, secretKey, err := newS3Credential()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &types.S3AccessKey{AccessKey: accessKey, SecretKey: secretKey, UserID: userID, CreatedTime: time.Now()}
+	if err = s.db.SaveS3AccessKey(key); err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	return key, nil
+}
+
+// GetS3AccessKeys get the access keys of the user, the secret keys are not returned
+func (s *Scheduler) GetS3AccessKeys(ctx context.Context, userID string) ([]*types.S3AccessKey, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	keys, err := s.db.LoadS3AccessKeysOfUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		key.SecretKey = ""
+	}
+
+	return keys, nil
+}
+
+// DeleteS3AccessKey deletes the access key of the user
+func (s *Scheduler) DeleteS3AccessKey(ctx context.Context, userID, accessKey string) error {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	return s.db.DeleteS3AccessKey(userID, accessKey)
+}
+
+// S3Authorize verifies the signature of the s3 request and returns the grant of the user of the access key
+func (s *Scheduler) S3Authorize(ctx context.Context, req *types.S3AuthReq) (*types.S3Grant, error) {
+	key, err := s.db.LoadS3AccessKey(req.AccessKey)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("access key %s not found", req.AccessKey)
+	} else if err != nil {
+		return nil, xerrors.Errorf("LoadS3AccessKey err:%s", err.Error())
+	}
+
+	if err = sigv4.Verify(key.SecretKey, req.StringToSign, req.Signature, time.Now()); err != nil {
+		return nil, xerrors.Errorf("access key %s: %s", req.AccessKey, err.Error())
+	}
+
+	grant := &types.S3Grant{UserID: key.UserID, NodeID: handler.GetNodeID(ctx), Expiration: time.Now().Add(s3GrantTTL)}
+	sign, err := s.NodeManager.KeyRing.Sign(grant.SignContent())
+	if err != nil {
+		return nil, xerrors.Errorf("sign grant err:%s", err.Error())
+	}
+	grant.Sign = hex.EncodeToString(sign)
+
+	return grant, nil
+}
+
+// verifyS3Grant checks the grant is signed by the scheduler for the candidate and not expired
+func (s *Scheduler) verifyS3Grant(grant *types.S3Grant, nodeID string) error {
+	if grant == nil {
+		return xerrors.New("grant can not empty")
+	}
+
+	if grant.NodeID != nodeID {
+		return xerrors.Errorf("grant is issued for node %s", grant.NodeID)
+	}
+
+	if time.Now().After(grant.Expiration) {
+		return xerrors.Errorf("grant expired at %s", grant.Expiration.Format(time.RFC3339))
+	}
+
+	sign, err := hex.DecodeString(grant.Sign)
+	if err != nil {
+		return xerrors.Errorf("decode grant signature err:%s", err.Error())
+	}
+
+	verifier := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	for _, key := range s.NodeManager.KeyRing.Keys() {
+		if verifier.VerifySign(key.Public().(*rsa.PublicKey), sign, grant.SignContent()) == nil {
+			return nil
+		}
+	}
+
+	return xerrors.New("grant signature mismatch")
+}
+
+// S3PutObject records the object stored by the candidate for the user of the grant, the asset of the object is replicated from the candidate
+func (s *Scheduler) S3PutObject(ctx context.Context, grant *types.S3Grant, object *types.S3Object) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	if err := s.verifyS3Grant(grant, nodeID); err != nil {
+		return err
+	}
+	object.UserID = grant.UserID
+
+	if len(object.UserID) == 0 || len(object.Bucket) == 0 || len(object.Key) == 0 {
+		return xerrors.New("user id, bucket and key can not empty")
+	}
+
+	hash, err := cidutil.CIDToHash(object.AssetCID)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", object.AssetCID, err.Error())
+	}
+
+	exists, err := s.db.AssetExistsOfUser(hash, object.UserID)
+	if err != nil {
+		return xerrors.Errorf("AssetExistsOfUser err:%s", err.Error())
+	}
+
+	// the objects with the same content share the asset of the user
+	if !exists {
+		createReq := &types.CreateAssetReq{
+			UserID: object.UserID,
+			AssetProperty: types.AssetProperty{
+				AssetCID:    object.AssetCID,
+				AssetName:   path.Base(object.Key),
+				AssetSize:   object.CarSize,
+				AssetType:   "file",
+				NodeID:      nodeID,
+				ContentType: object.ContentType,
+			},
+		}
+
+		if err = s.newUser(object.UserID).CreateIngestedAsset(ctx, createReq); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("S3PutObject nodeID:%s, user:%s, object:%s/%s, cid:%s", nodeID, object.UserID, object.Bucket, object.Key, object.AssetCID)
+
+	object.CreatedTime = time.Now()
+	return s.db.SaveS3Object(object)
+}
+
+// S3GetObject get the object of the bucket
+func (s *Scheduler) S3GetObject(ctx context.Context, userID, bucket, key string) (*types.S3Object, error) {
+	object, err := s.db.LoadS3Object(userID, bucket, key)
+	if err == sql.ErrNoRows {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("object %s/%s not found", bucket, key)}
+	}
+
+	return object, err
+}
+
+// S3ListObjects lists the objects of the bucket in key order
+func (s *Scheduler) S3ListObjects(ctx context.Context, req *types.ListS3ObjectsReq) (*types.ListS3ObjectsRsp, error) {
+	return s.db.LoadS3Objects(req)
+}
+
+// S3ListBuckets lists the buckets of the user
+func (s *Scheduler) S3ListBuckets(ctx context.Context, userID string) ([]string, error) {
+	return s.db.LoadS3Buckets(userID)
+}
+
+// newS3Credential generates a random access key and secret key
+func newS3Credential() (string, string, error) {
+	buf := make([]byte, s3AccessKeyBytes+s3SecretKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	accessKey := strings.ToUpper(hex.EncodeToString(buf[:s3AccessKeyBytes]))
+	secretKey := base64.RawURLEncoding.EncodeToString(buf[s3AccessKeyBytes:])
+
+	return accessKey, secretKey, nil
+}
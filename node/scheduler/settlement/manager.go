@@ -0,0 +1,355 @@
+package settlement
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("settlement")
+
+const (
+	settleInterval = 10 * time.Minute
+	webhookTimeout = 30 * time.Second
+
+	// points and amounts are settled with 6 decimals
+	decimals = 6
+)
+
+// Manager freezes the points earned by the nodes in each epoch, settles them to the accounts the nodes are bound to
+// in proportion to the points, and hands the signed report to the payout hook.
+// The points of a node not bound to an account or with its rewards frozen by a penalty are carried over
+// until it is bound or the freeze ends, and the first epoch also settles the points earned before it.
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	keyRing       *keys.Ring
+	*db.SQLDB
+
+	httpClient *http.Client
+}
+
+// NewManager return new settlement manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager, keyRing *keys.Ring) *Manager {
+	m := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		keyRing:       keyRing,
+		SQLDB:         sdb,
+		httpClient:    &http.Client{Timeout: webhookTimeout},
+	}
+
+	go m.startSettleTimer()
+
+	return m
+}
+
+func (m *Manager) startSettleTimer() {
+	ticker := time.NewTicker(settleInterval)
+	defer ticker.Stop()
+
+	t := diagnostics.NewTimer("settlement.settle", settleInterval)
+
+	for range ticker.C {
+		done := t.Start()
+		m.settle()
+		done()
+	}
+}
+
+// settle settles the epoch once it ends, the epoch failed to be exported is exported again
+func (m *Manager) settle() {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	if cfg.SettlementEpochHours <= 0 {
+		return
+	}
+
+	last, err := m.LoadLatestSettlementEpoch()
+	if err != nil && err != sql.ErrNoRows {
+		log.Errorf("LoadLatestSettlementEpoch err:%s", err.Error())
+		return
+	}
+
+	if last != nil && !last.Exported {
+		if err = m.Export(last.Epoch); err != nil {
+			log.Errorf("export settlement epoch %d err:%s", last.Epoch, err.Error())
+		}
+	}
+
+	interval := time.Duration(cfg.SettlementEpochHours) * time.Hour
+	now := time.Now().Truncate(time.Second)
+	if last != nil && now.Sub(last.EndTime) < interval {
+		return
+	}
+
+	epoch, err := m.freeze(last, now, interval, cfg.SettlementRewardPerEpoch)
+	if err != nil {
+		log.Errorf("freeze settlement epoch err:%s", err.Error())
+		return
+	}
+
+	log.Infof("settled epoch %d, points %s, accounts %d, merkle root %s", epoch.Epoch, epoch.TotalPoints, epoch.AccountCount, epoch.MerkleRoot)
+
+	if err = m.Export(epoch.Epoch); err != nil {
+		log.Errorf("export settlement epoch %d err:%s", epoch.Epoch, err.Error())
+	}
+}
+
+// freeze freezes the points of the nodes at end and settles the points earned since the last epoch
+func (m *Manager) freeze(last *types.SettlementEpoch, end time.Time, interval time.Duration, reward float64) (*types.SettlementEpoch, error) {
+	epoch := &types.SettlementEpoch{Epoch: 1, StartTime: end.Add(-interval), EndTime: end, TotalReward: reward}
+
+	frozen := make(map[string]types.Points)
+	if last != nil {
+		epoch.Epoch = last.Epoch + 1
+		epoch.StartTime = last.EndTime
+
+		var err error
+		if frozen, err = m.LoadSettlementPoints(last.Epoch); err != nil {
+			return nil, xerrors.Errorf("LoadSettlementPoints: %w", err)
+		}
+	}
+
+	current, err := m.LoadNodePoints()
+	if err != nil {
+		return nil, xerrors.Errorf("LoadNodePoints: %w", err)
+	}
+
+	accounts, err := m.LoadAllAccountNodes()
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAllAccountNodes: %w", err)
+	}
+
+	held, err := m.LoadRewardFrozenNodes(end)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadRewardFrozenNodes: %w", err)
+	}
+
+	shares, points := computeShares(epoch.Epoch, current, frozen, accounts, held, reward)
+
+	leaves := leafHashes(shares)
+	epoch.MerkleRoot = merkleRoot(leaves)
+	epoch.AccountCount = len(shares)
+	for _, share := range shares {
+		epoch.TotalPoints = epoch.TotalPoints.Add(share.Points)
+	}
+
+	sign, err := m.keyRing.Sign(epoch.SignContent())
+	if err != nil {
+		return nil, xerrors.Errorf("sign: %w", err)
+	}
+	epoch.Signature = hex.EncodeToString(sign)
+
+	if err = m.SaveSettlement(epoch, shares, points); err != nil {
+		return nil, xerrors.Errorf("SaveSettlement: %w", err)
+	}
+
+	return epoch, nil
+}
+
+// computeShares computes the shares of the accounts from the points earned by their nodes since the points frozen,
+// and returns the points to freeze. The points of the nodes not bound or held are carried over, and the points lost by
+// a node are offset against its later points. The points of the nodes archived are carried over too, so that the points
+// of a restored node are not settled again.
+func computeShares(epoch int64, current, frozen map[string]types.Points, accounts map[string]string, held map[string]bool, reward float64) ([]*types.SettlementShare, map[string]types.Points) {
+	points := make(map[string]types.Points, len(current))
+	earned := make(map[string]types.Points)
+
+	for nodeID, p := range current {
+		prev, ok := frozen[nodeID]
+
+		accountID := accounts[nodeID]
+		if accountID == "" || held[nodeID] {
+			if ok {
+				points[nodeID] = prev
+			}
+			continue
+		}
+
+		if p.Cmp(prev) <= 0 {
+			points[nodeID] = prev
+			continue
+		}
+
+		points[nodeID] = p
+		earned[accountID] = earned[accountID].Add(p.Sub(prev))
+	}
+
+	for nodeID, prev := range frozen {
+		if _, ok := current[nodeID]; !ok {
+			points[nodeID] = prev
+		}
+	}
+
+	var total types.Points
+	shares := make([]*types.SettlementShare, 0, len(earned))
+	for accountID, p := range earned {
+		p = p.Round(decimals)
+		if p.Sign() <= 0 {
+			continue
+		}
+
+		total = total.Add(p)
+		shares = append(shares, &types.SettlementShare{Epoch: epoch, AccountID: accountID, Points: p})
+	}
+
+	for _, share := range shares {
+		share.Share = share.Points.Ratio(total)
+		share.Amount = types.PointsFromFloat(reward).MulDiv(share.Points, total).Round(decimals).Float64()
+	}
+
+	sortShares(shares)
+	return shares, points
+}
+
+// Export hands the signed report of the epoch to the payout hook, the shares are written as csv and the report as json
+// to the payout directory, and the report is posted to the payout webhook
+func (m *Manager) Export(epoch int64) error {
+	cfg, err := m.config()
+	if err != nil {
+		return err
+	}
+
+	report, err := m.Report(epoch)
+	if err != nil {
+		return err
+	}
+
+	if cfg.SettlementPayoutDir != "" {
+		if err = writePayoutFiles(cfg.SettlementPayoutDir, report); err != nil {
+			return xerrors.Errorf("write payout files: %w", err)
+		}
+	}
+
+	if cfg.SettlementPayoutWebhook != "" {
+		if err = m.postReport(cfg.SettlementPayoutWebhook, report); err != nil {
+			return xerrors.Errorf("post report: %w", err)
+		}
+	}
+
+	return m.SetSettlementExported(epoch)
+}
+
+// Report returns the signed report of the epoch with the shares ordered as the merkle leaves
+func (m *Manager) Report(epoch int64) (*types.SettlementReport, error) {
+	info, err := m.LoadSettlementEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := m.LoadAllSettlementShares(epoch)
+	if err != nil {
+		return nil, err
+	}
+	sortShares(shares)
+
+	return &types.SettlementReport{Epoch: info, Shares: shares}, nil
+}
+
+// Proof returns the merkle proof of the share of the account in the epoch
+func (m *Manager) Proof(epoch int64, accountID string) (*types.SettlementProof, error) {
+	report, err := m.Report(epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := leafHashes(report.Shares)
+	for i, share := range report.Shares {
+		if share.AccountID == accountID {
+			return &types.SettlementProof{Share: share, MerkleRoot: report.Epoch.MerkleRoot, Proof: merkleProof(leaves, i)}, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("account %s has no share in epoch %d", accountID, epoch)
+}
+
+func (m *Manager) postReport(url string, report *types.SettlementReport) error {
+	buf, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := m.httpClient.Post(url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close() //nolint:errcheck
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return xerrors.Errorf("webhook status code %d", rsp.StatusCode)
+	}
+
+	return nil
+}
+
+// writePayoutFiles writes epoch-{n}.csv with the shares and epoch-{n}.json with the report to the directory
+func writePayoutFiles(dir string, report *types.SettlementReport) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	records := [][]string{{"account_id", "points", "share", "amount", "leaf"}}
+	for _, share := range report.Shares {
+		leaf := leafHashes([]*types.SettlementShare{share})[0]
+		records = append(records, []string{
+			share.AccountID,
+			share.Points.StringFixed(decimals),
+			strconv.FormatFloat(share.Share, 'f', 12, 64),
+			strconv.FormatFloat(share.Amount, 'f', decimals, 64),
+			hex.EncodeToString(leaf),
+		})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("epoch-%d", report.Epoch.Epoch)
+	if err := writeFile(filepath.Join(dir, name+".csv"), buf.Bytes()); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(dir, name+".json"), data)
+}
+
+// writeFile writes the file through a temporary file, so the consumers never see a partial file
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
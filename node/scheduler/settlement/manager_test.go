@@ -0,0 +1,91 @@
+package settlement
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func points(m map[string]int64) map[string]types.Points {
+	out := make(map[string]types.Points, len(m))
+	for k, v := range m {
+		out[k] = types.PointsFromInt(v)
+	}
+	return out
+}
+
+func TestComputeShares(t *testing.T) {
+	current := points(map[string]int64{"n1": 30, "n2": 20, "n3": 50, "n4": 5, "n5": 40, "n6": 70})
+	frozen := points(map[string]int64{"n1": 10, "n2": 0, "n3": 45, "n5": 50, "n6": 60})
+	accounts := map[string]string{"n1": "a", "n2": "a", "n3": "b", "n5": "b", "n6": "b"}
+	held := map[string]bool{"n6": true}
+
+	shares, frozenPoints := computeShares(2, current, frozen, accounts, held, 100)
+	if len(shares) != 2 {
+		t.Fatalf("expect 2 shares, got %d", len(shares))
+	}
+
+	// a earned 20+20, b earned 5, the lost points of n5 are not deducted
+	if shares[0].AccountID != "a" || shares[0].Points.Cmp(types.PointsFromInt(40)) != 0 ||
+		shares[1].AccountID != "b" || shares[1].Points.Cmp(types.PointsFromInt(5)) != 0 {
+		t.Fatalf("unexpected shares %+v %+v", shares[0], shares[1])
+	}
+
+	if shares[0].Amount != 88.888889 || shares[1].Amount != 11.111111 {
+		t.Fatalf("unexpected amounts %f %f", shares[0].Amount, shares[1].Amount)
+	}
+
+	// n4 is not bound and never frozen, n5 keeps the higher points frozen
+	if _, ok := frozenPoints["n4"]; ok {
+		t.Fatal("points of the node not bound should not be frozen")
+	}
+	// the points of the held n6 are carried over
+	if frozenPoints["n5"].Cmp(types.PointsFromInt(50)) != 0 || frozenPoints["n1"].Cmp(types.PointsFromInt(30)) != 0 ||
+		frozenPoints["n6"].Cmp(types.PointsFromInt(60)) != 0 {
+		t.Fatalf("unexpected frozen points %v", frozenPoints)
+	}
+}
+
+func TestComputeSharesArchivedNode(t *testing.T) {
+	accounts := map[string]string{"n1": "a"}
+
+	_, frozen := computeShares(1, points(map[string]int64{"n1": 100}), nil, accounts, nil, 100)
+
+	// n1 is archived, its node info is moved out
+	shares, frozen := computeShares(2, points(map[string]int64{}), frozen, accounts, nil, 100)
+	if len(shares) != 0 || frozen["n1"].Cmp(types.PointsFromInt(100)) != 0 {
+		t.Fatalf("points of the archived node should be carried over, shares %v frozen %v", shares, frozen)
+	}
+
+	// n1 is restored and earns 10 more
+	shares, _ = computeShares(3, points(map[string]int64{"n1": 110}), frozen, accounts, nil, 100)
+	if len(shares) != 1 || shares[0].Points.Cmp(types.PointsFromInt(10)) != 0 {
+		t.Fatalf("restored node should settle only the points earned since, got %+v", shares)
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	for count := 1; count <= 9; count++ {
+		shares := make([]*types.SettlementShare, 0, count)
+		for i := 0; i < count; i++ {
+			shares = append(shares, &types.SettlementShare{Epoch: 1, AccountID: fmt.Sprintf("account%d", i), Amount: float64(i)})
+		}
+
+		leaves := leafHashes(shares)
+		root := merkleRoot(leaves)
+
+		for i, share := range shares {
+			sum := sha256.Sum256(share.Leaf())
+			if !verifyProof(sum[:], merkleProof(leaves, i), root) {
+				t.Fatalf("proof of leaf %d in %d leaves is invalid", i, count)
+			}
+		}
+
+		sum := sha256.Sum256([]byte("unknown"))
+		if verifyProof(sum[:], merkleProof(leaves, 0), root) {
+			t.Fatalf("proof of unknown leaf in %d leaves should be invalid", count)
+		}
+	}
+}
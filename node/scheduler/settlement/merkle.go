@@ -0,0 +1,97 @@
+package settlement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// sortShares sorts the shares by account id, the order of the merkle leaves
+func sortShares(shares []*types.SettlementShare) {
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].AccountID < shares[j].AccountID
+	})
+}
+
+func leafHashes(shares []*types.SettlementShare) [][]byte {
+	leaves := make([][]byte, 0, len(shares))
+	for _, share := range shares {
+		sum := sha256.Sum256(share.Leaf())
+		leaves = append(leaves, sum[:])
+	}
+	return leaves
+}
+
+// hashPair hashes the pair in sorted order, so the proof needs no position of the siblings
+func hashPair(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+
+	h := sha256.New()
+	h.Write(a)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// nextLevel hashes the pairs of the level, the odd node is promoted to the next level
+func nextLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, hashPair(level[i], level[i+1]))
+	}
+	return next
+}
+
+// merkleRoot returns the hex of the merkle root of the leaves, empty if there is no leaf
+func merkleRoot(leaves [][]byte) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+
+	return hex.EncodeToString(level[0])
+}
+
+// merkleProof returns the hex of the sibling hashes from the leaf at index to the root
+func merkleProof(leaves [][]byte, index int) []string {
+	proof := make([]string, 0)
+
+	level := leaves
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, hex.EncodeToString(level[sibling]))
+		}
+
+		level = nextLevel(level)
+		index /= 2
+	}
+
+	return proof
+}
+
+// verifyProof checks the leaf is in the tree of the root with the proof
+func verifyProof(leaf []byte, proof []string, root string) bool {
+	hash := leaf
+	for _, p := range proof {
+		sibling, err := hex.DecodeString(p)
+		if err != nil {
+			return false
+		}
+		hash = hashPair(hash, sibling)
+	}
+
+	return hex.EncodeToString(hash) == root
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// GetSettlementEpochs retrieves the settled epochs of points with the merkle roots and signatures, the latest first
+func (s *Scheduler) GetSettlementEpochs(ctx context.Context, limit, offset int) (*types.ListSettlementEpochRsp, error) {
+	return s.SettlementManager.LoadSettlementEpochs(limit, offset)
+}
+
+// GetSettlementShares retrieves the shares of the accounts in the settled epoch
+func (s *Scheduler) GetSettlementShares(ctx context.Context, epoch int64, limit, offset int) (*types.ListSettlementShareRsp, error) {
+	return s.SettlementManager.LoadSettlementShares(epoch, limit, offset)
+}
+
+// GetSettlementProof retrieves the merkle proof of the share of the account in the settled epoch for the on-chain distributor
+func (s *Scheduler) GetSettlementProof(ctx context.Context, epoch int64, accountID string) (*types.SettlementProof, error) {
+	return s.SettlementManager.Proof(epoch, accountID)
+}
+
+// ExportSettlement hands the signed report of the settled epoch to the payout hook again
+func (s *Scheduler) ExportSettlement(ctx context.Context, epoch int64) error {
+	return s.SettlementManager.Export(epoch)
+}
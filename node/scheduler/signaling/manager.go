@@ -0,0 +1,148 @@
+package signaling
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/locindex"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("signaling")
+
+const (
+	// the edges the offer of a browser is relayed to before the connect fails
+	maxConnectAttempts = 3
+	// the time an edge takes to answer the offer
+	offerTimeout = 15 * time.Second
+	// the offers expired are refused by the edges
+	offerExpiration = 30 * time.Second
+	// the browsers send sdps of a few kilobytes, the larger are refused
+	maxSDPLength = 64 << 10
+)
+
+// Manager relays the webrtc offers of the browsers to the edges holding the assets and returns their answers,
+// the edges behind nat serve the browsers over data channels without a public http address
+type Manager struct {
+	nodeMgr *node.Manager
+	index   *locindex.Index
+	config  dtypes.GetSchedulerConfigFunc
+}
+
+// NewManager return new signaling manager instance
+func NewManager(nmgr *node.Manager, index *locindex.Index, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	return &Manager{
+		nodeMgr: nmgr,
+		index:   index,
+		config:  configFunc,
+	}
+}
+
+// ICEServers returns the stun and turn servers of the webrtc sessions
+func (m *Manager) ICEServers() ([]*types.WebRTCICEServer, error) {
+	cfg, err := m.config()
+	if err != nil {
+		return nil, xerrors.Errorf("get scheduler config err:%s", err.Error())
+	}
+
+	if len(cfg.WebRTCICEServers) == 0 {
+		return nil, nil
+	}
+
+	return []*types.WebRTCICEServer{{
+		URLs:       cfg.WebRTCICEServers,
+		Username:   cfg.WebRTCICEUsername,
+		Credential: cfg.WebRTCICECredential,
+	}}, nil
+}
+
+// Connect relays the offer of the browser to the edges holding the asset one by one, and returns the answer of the first edge answering
+func (m *Manager) Connect(ctx context.Context, hash string, req *types.WebRTCOfferReq) (*types.WebRTCAnswer, error) {
+	if req.SDP == "" || len(req.SDP) > maxSDPLength {
+		return nil, xerrors.Errorf("invalid sdp length %d", len(req.SDP))
+	}
+
+	iceServers, err := m.ICEServers()
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := m.selectEdges(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(edges) == 0 {
+		return nil, xerrors.Errorf("no edge can serve the asset %s over webrtc", req.AssetCID)
+	}
+
+	offer := &types.WebRTCOffer{
+		SessionID:  uuid.NewString(),
+		AssetCID:   req.AssetCID,
+		SDP:        req.SDP,
+		ICEServers: iceServers,
+		Expiration: time.Now().Add(offerExpiration),
+	}
+
+	for i, eNode := range edges {
+		if i >= maxConnectAttempts {
+			break
+		}
+
+		answer, err := m.offer(ctx, eNode, offer)
+		if err != nil {
+			log.Debugf("edge %s answer session %s err:%s", eNode.NodeID, offer.SessionID, err.Error())
+			continue
+		}
+
+		answer.SessionID = offer.SessionID
+		answer.NodeID = eNode.NodeID
+		return answer, nil
+	}
+
+	return nil, xerrors.Errorf("no edge answered the offer of the asset %s", req.AssetCID)
+}
+
+func (m *Manager) offer(ctx context.Context, eNode *node.Node, offer *types.WebRTCOffer) (*types.WebRTCAnswer, error) {
+	ctx, cancel := context.WithTimeout(ctx, offerTimeout)
+	defer cancel()
+
+	return eNode.WebRTCOffer(ctx, offer)
+}
+
+// selectEdges returns the online edges holding the asset in random order, the edges behind symmetric nat are last
+// as the browsers seldom reach them without a turn server
+func (m *Manager) selectEdges(hash string) ([]*node.Node, error) {
+	replicas, err := m.index.Locations(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*node.Node, 0, len(replicas))
+	for _, rInfo := range replicas {
+		if rInfo.IsCandidate {
+			continue
+		}
+
+		eNode := m.nodeMgr.GetEdgeNode(rInfo.NodeID)
+		if eNode == nil || eNode.API == nil || eNode.WebRTCOffer == nil || eNode.IsOverloaded() {
+			continue
+		}
+
+		edges = append(edges, eNode)
+	}
+
+	rand.Shuffle(len(edges), func(i, j int) { edges[i], edges[j] = edges[j], edges[i] })
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].NATType != types.NatTypeSymmetric && edges[j].NATType == types.NatTypeSymmetric
+	})
+
+	return edges, nil
+}
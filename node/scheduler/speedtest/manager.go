@@ -0,0 +1,282 @@
+package speedtest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("speedtest")
+
+const (
+	// number of the nearest candidates the edge is measured against
+	maxTestCandidates = 3
+	// seconds of each transfer between the edge and a candidate
+	transferDuration = 5
+	// the edge measures the candidates one by one, latency probes and a transfer each way
+	testTimeout = 2 * time.Minute
+	// a node can be tested once in the interval
+	minTestInterval = 10 * time.Minute
+	// the tests running at once on the scheduler, the candidates are shared by all the tests
+	maxRunningTests = 20
+)
+
+// Manager runs the on-demand bandwidth tests of the edges against the nearest candidates,
+// the transfers are timed and signed by the candidates so the edge can not inflate its bandwidth
+type Manager struct {
+	nodeMgr *node.Manager
+	keyRing *keys.Ring
+	*db.SQLDB
+
+	lk sync.Mutex
+	// running tests by node id
+	running map[string]string
+}
+
+// NewManager return new bandwidth test manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, keyRing *keys.Ring) *Manager {
+	return &Manager{
+		nodeMgr: nmgr,
+		keyRing: keyRing,
+		SQLDB:   sdb,
+		running: make(map[string]string),
+	}
+}
+
+// target a candidate the edge is measured against
+type target struct {
+	candidate *node.Node
+	url       string
+}
+
+// StartTest starts a bandwidth test of the edge and returns the test id, the result is saved when the test finishes
+func (m *Manager) StartTest(nodeID string) (string, error) {
+	edge := m.nodeMgr.GetEdgeNode(nodeID)
+	if edge == nil {
+		return "", xerrors.Errorf("edge %s is not online", nodeID)
+	}
+
+	count, err := m.CountBandwidthTestsSince(nodeID, time.Now().Add(-minTestInterval))
+	if err != nil {
+		return "", err
+	}
+
+	if count > 0 {
+		return "", xerrors.Errorf("node %s can be tested once every %s", nodeID, minTestInterval)
+	}
+
+	testID := uuid.NewString()
+	targets, err := m.targets(edge, testID)
+	if err != nil {
+		return "", err
+	}
+
+	m.lk.Lock()
+	if _, ok := m.running[nodeID]; ok {
+		m.lk.Unlock()
+		return "", xerrors.Errorf("node %s is being tested", nodeID)
+	}
+
+	if len(m.running) >= maxRunningTests {
+		m.lk.Unlock()
+		return "", xerrors.New("too many bandwidth tests are running, try again later")
+	}
+	m.running[nodeID] = testID
+	m.lk.Unlock()
+
+	test := &types.BandwidthTest{ID: testID, NodeID: nodeID, Status: types.BandwidthTestRunning}
+	if err := m.SaveBandwidthTest(test); err != nil {
+		m.finish(nodeID)
+		return "", err
+	}
+
+	go m.run(edge, test, targets)
+
+	return testID, nil
+}
+
+// GetTest returns the bandwidth test, a running test not finished within its timeout was interrupted by a restart of the scheduler
+func (m *Manager) GetTest(testID string) (*types.BandwidthTest, error) {
+	test, err := m.LoadBandwidthTest(testID)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("bandwidth test %s not found", testID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if test.Status == types.BandwidthTestRunning && time.Since(test.CreatedTime) > 2*testTimeout {
+		test.Status = types.BandwidthTestFailed
+		test.Message = "interrupted"
+	}
+
+	return test, nil
+}
+
+func (m *Manager) finish(nodeID string) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	delete(m.running, nodeID)
+}
+
+// targets picks the nearest candidates of the edge, the candidates of its own area first and then the rest of its zone,
+// and signs their bandwidth test urls
+func (m *Manager) targets(edge *node.Node, testID string) ([]*target, error) {
+	_, candidates := m.nodeMgr.GetZoneNodes(edge.AreaID)
+
+	picked := make([]*node.Node, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !candidate.IsOverloaded() && candidate.PublicKey != nil {
+			picked = append(picked, candidate)
+		}
+	}
+
+	if len(picked) == 0 {
+		return nil, xerrors.Errorf("no candidate in the zone of %s", edge.AreaID)
+	}
+
+	sort.SliceStable(picked, func(i, j int) bool {
+		iLocal, jLocal := picked[i].AreaID == edge.AreaID, picked[j].AreaID == edge.AreaID
+		if iLocal != jLocal {
+			return iLocal
+		}
+		return picked[i].BandwidthUp > picked[j].BandwidthUp
+	})
+
+	if len(picked) > maxTestCandidates {
+		picked = picked[:maxTestCandidates]
+	}
+
+	expires := time.Now().Add(testTimeout).Unix()
+	targets := make([]*target, 0, len(picked))
+	for _, candidate := range picked {
+		sign, err := m.keyRing.Sign(types.BandwidthTestContent(testID, edge.NodeID, candidate.NodeID, transferDuration, expires))
+		if err != nil {
+			return nil, err
+		}
+
+		query := url.Values{}
+		query.Set(types.BandwidthTestIDParam, testID)
+		query.Set(types.BandwidthTestNodeParam, edge.NodeID)
+		query.Set(types.BandwidthTestCandidateParam, candidate.NodeID)
+		query.Set(types.BandwidthTestDurationParam, strconv.Itoa(transferDuration))
+		query.Set(types.BandwidthTestExpiresParam, strconv.FormatInt(expires, 10))
+		query.Set(types.BandwidthTestSignatureParam, hex.EncodeToString(sign))
+
+		address := fmt.Sprintf("http://%s", candidate.DownloadAddr())
+		if len(candidate.ExternalURL) > 0 {
+			address = candidate.ExternalURL
+		}
+
+		targets = append(targets, &target{candidate: candidate, url: fmt.Sprintf("%s/bandwidth-test?%s", address, query.Encode())})
+	}
+
+	return targets, nil
+}
+
+// run asks the edge to measure against the targets, the best verified measurements become the bandwidth of the edge
+// which the next points calculation uses
+func (m *Manager) run(edge *node.Node, test *types.BandwidthTest, targets []*target) {
+	defer m.finish(edge.NodeID)
+
+	req := &types.BandwidthTestReq{TestID: test.ID, Duration: transferDuration}
+	byID := make(map[string]*target, len(targets))
+	for _, t := range targets {
+		req.Targets = append(req.Targets, &types.BandwidthTestTarget{CandidateID: t.candidate.NodeID, URL: t.url})
+		byID[t.candidate.NodeID] = t
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	reports, err := edge.API.BandwidthTest(ctx, req)
+	if err != nil {
+		test.Status = types.BandwidthTestFailed
+		test.Message = err.Error()
+	} else {
+		m.evaluate(test, reports, byID)
+	}
+
+	if test.Status == types.BandwidthTestSucceeded {
+		m.nodeMgr.UpdateNodeBandwidths(edge.NodeID, test.BandwidthDown, test.BandwidthUp)
+	}
+
+	if err := m.UpdateBandwidthTest(test); err != nil {
+		log.Errorf("UpdateBandwidthTest %s err:%s", test.ID, err.Error())
+	}
+}
+
+// evaluate keeps the best of the measurements signed by the candidates of the test
+func (m *Manager) evaluate(test *types.BandwidthTest, reports []*types.BandwidthTestReport, targets map[string]*target) {
+	var errs []string
+	for _, report := range reports {
+		t, ok := targets[report.CandidateID]
+		if !ok {
+			continue
+		}
+
+		if report.Err != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", report.CandidateID, report.Err))
+		}
+
+		if report.Latency > 0 && (test.Latency == 0 || report.Latency < test.Latency) {
+			test.Latency = report.Latency
+		}
+
+		if up := m.verified(test, t, report.Upload, true); up > test.BandwidthUp {
+			test.BandwidthUp = up
+		}
+
+		if down := m.verified(test, t, report.Download, false); down > test.BandwidthDown {
+			test.BandwidthDown = down
+		}
+	}
+
+	if test.BandwidthUp > 0 {
+		test.Status = types.BandwidthTestSucceeded
+		return
+	}
+
+	test.Status = types.BandwidthTestFailed
+	test.Message = "no valid measurement"
+	if len(errs) > 0 {
+		test.Message = fmt.Sprintf("%s, %s", test.Message, errs[0])
+	}
+	if len(test.Message) > 512 {
+		test.Message = test.Message[:512]
+	}
+}
+
+// verified returns the bandwidth of the measurement if it is signed by the candidate for the test, otherwise 0
+func (m *Manager) verified(test *types.BandwidthTest, t *target, measurement *types.BandwidthMeasurement, upload bool) int64 {
+	if measurement == nil {
+		return 0
+	}
+
+	if measurement.TestID != test.ID || measurement.NodeID != test.NodeID || measurement.CandidateID != t.candidate.NodeID || measurement.Upload != upload {
+		log.Warnf("bandwidth test %s measurement of %s mismatch", test.ID, t.candidate.NodeID)
+		return 0
+	}
+
+	if err := nodekey.Verify(t.candidate.PublicKey, measurement.Sign, measurement.SignData()); err != nil {
+		log.Warnf("bandwidth test %s verify measurement of %s err:%s", test.ID, t.candidate.NodeID, err.Error())
+		return 0
+	}
+
+	return measurement.BytesPerSecond()
+}
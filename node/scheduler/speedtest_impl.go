@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+// StartBandwidthTest starts a bandwidth and latency test of the edge against the nearest candidates and returns the test id
+func (s *Scheduler) StartBandwidthTest(ctx context.Context, nodeID string) (string, error) {
+	if api.HasPerm(ctx, api.RoleDefault, api.RoleEdge) {
+		nodeID = handler.GetNodeID(ctx)
+	}
+
+	return s.SpeedTestManager.StartTest(nodeID)
+}
+
+// GetBandwidthTest retrieves the bandwidth test, an edge can only retrieve its own tests
+func (s *Scheduler) GetBandwidthTest(ctx context.Context, testID string) (*types.BandwidthTest, error) {
+	test, err := s.SpeedTestManager.GetTest(testID)
+	if err != nil {
+		return nil, err
+	}
+
+	if api.HasPerm(ctx, api.RoleDefault, api.RoleEdge) && test.NodeID != handler.GetNodeID(ctx) {
+		return nil, xerrors.Errorf("bandwidth test %s not found", testID)
+	}
+
+	return test, nil
+}
+
+// ListBandwidthTests retrieves the bandwidth tests of the node, the latest first
+func (s *Scheduler) ListBandwidthTests(ctx context.Context, nodeID string, limit, offset int) (*types.ListBandwidthTestRsp, error) {
+	return s.SpeedTestManager.LoadBandwidthTests(nodeID, limit, offset)
+}
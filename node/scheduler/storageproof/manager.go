@@ -0,0 +1,280 @@
+package storageproof
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	"github.com/docker/go-units"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("storageproof")
+
+const (
+	// the node proves the replica within the deadline
+	challengeTimeout = time.Minute
+	// blocks of the asset sampled by a challenge
+	challengeBlocks = 8
+	// bytes of a block hashed from its random offset
+	challengeSampleSize = 4 * units.KiB
+	// the replica of a challenge is picked from the latest replicas of the node
+	maxReplicasPicked = 50
+	// the replicas challenged at once
+	maxChallenging = 5
+	// the interval is read from the config again after the delay if the challenges are disabled
+	disabledCheckDelay = time.Minute
+	maxMessageLen      = 512
+)
+
+var errNoSpotChecker = xerrors.New("no node holding the replica to spot check the challenge result")
+
+// Manager challenges a sample of the replicas on the nodes connected to the scheduler to prove they hold the data.
+// A node returns the hashes over the random offsets of the blocks of the asset picked by the challenge, which are compared
+// with the hashes of another node holding the replica. A replica failing the challenges in a row is lost, it is removed
+// from the node and counted in the fake storage penalty rules, the reconciler of the assets repairs it on other nodes
+type Manager struct {
+	config     dtypes.GetSchedulerConfigFunc
+	nodeMgr    *node.Manager
+	assetMgr   *assets.Manager
+	penaltyMgr *penalty.Manager
+	*db.SQLDB
+}
+
+// NewManager return new storage proof manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, amgr *assets.Manager, pmgr *penalty.Manager, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	m := &Manager{
+		config:     configFunc,
+		nodeMgr:    nmgr,
+		assetMgr:   amgr,
+		penaltyMgr: pmgr,
+		SQLDB:      sdb,
+	}
+
+	go m.startChallengeTimer()
+
+	return m
+}
+
+func (m *Manager) startChallengeTimer() {
+	for {
+		cfg, err := m.config()
+		if err != nil {
+			log.Errorf("get scheduler config err:%s", err.Error())
+			time.Sleep(disabledCheckDelay)
+			continue
+		}
+
+		if cfg.StorageProofInterval <= 0 {
+			time.Sleep(disabledCheckDelay)
+			continue
+		}
+
+		time.Sleep(time.Duration(cfg.StorageProofInterval) * time.Minute)
+		m.challengeRound(&cfg)
+	}
+}
+
+// sample picks the nodes of the round, each challenged on one of its replicas
+func (m *Manager) sample(size int) []*node.Node {
+	_, nodes := m.nodeMgr.GetAllValidCandidateNodes()
+	nodes = append(nodes, m.nodeMgr.GetAllEdgeNode()...)
+
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+
+	if len(nodes) > size {
+		nodes = nodes[:size]
+	}
+	return nodes
+}
+
+// challengeRound challenges a replica of each sampled node and removes the replicas lost
+func (m *Manager) challengeRound(cfg *config.SchedulerCfg) {
+	nodes := m.sample(cfg.StorageProofSampleSize)
+
+	var wg sync.WaitGroup
+	challenging := make(chan struct{}, maxChallenging)
+
+	for _, n := range nodes {
+		challenging <- struct{}{}
+		wg.Add(1)
+		go func(n *node.Node) {
+			defer func() {
+				<-challenging
+				wg.Done()
+			}()
+
+			proof, err := m.challengeNode(n)
+			if err != nil {
+				log.Debugf("challenge %s err:%s", n.NodeID, err.Error())
+				return
+			}
+
+			if !proof.Passed && cfg.StorageProofMaxFailures > 0 && proof.Failures >= cfg.StorageProofMaxFailures {
+				m.replicaLost(proof)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+// challengeNode challenges a random replica of the node and saves the proof, an error is returned without a proof if
+// the node can not be challenged, e.g. it has no replica, no other node holds the replica or the node is unreachable.
+// Only the results with a mismatched signature or hashes fail the challenge
+func (m *Manager) challengeNode(n *node.Node) (*types.StorageProof, error) {
+	replicas, err := m.LoadSucceedReplicasByNodeID(n.NodeID, maxReplicasPicked, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replicas.NodeAssetInfos) == 0 {
+		return nil, xerrors.New("no replica")
+	}
+
+	asset := replicas.NodeAssetInfos[rand.Intn(len(replicas.NodeAssetInfos))]
+
+	checker, err := m.getSpotChecker(asset.Hash, n.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &types.StorageChallenge{
+		ID:         uuid.NewString(),
+		CID:        asset.Cid,
+		Seed:       time.Now().UnixNano(),
+		BlockCount: challengeBlocks,
+		SampleSize: challengeSampleSize,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), challengeTimeout)
+	defer cancel()
+
+	proof := &types.StorageProof{Hash: asset.Hash, CID: asset.Cid, NodeID: n.NodeID, Checker: checker.NodeID, ProofTime: time.Now()}
+
+	result, err := n.ProveStorage(ctx, challenge)
+	if err != nil {
+		// e.g. the node predates the challenge, times out or is unreachable behind nat
+		return nil, xerrors.Errorf("ProveStorage: %w", err)
+	}
+	proof.Duration = time.Since(proof.ProofTime).Milliseconds()
+
+	expected, err := spotCheck(checker, challenge)
+	if err != nil {
+		// the replica of the checker may be broken, the node is not blamed
+		return nil, xerrors.Errorf("spot check by %s: %w", checker.NodeID, err)
+	}
+
+	if err = verifyResult(n, challenge, result, expected); err == nil {
+		proof.Passed = true
+	} else {
+		proof.Message = err.Error()
+		if len(proof.Message) > maxMessageLen {
+			proof.Message = proof.Message[:maxMessageLen]
+		}
+		log.Infof("node %s failed the storage challenge of asset %s: %s", n.NodeID, asset.Hash, proof.Message)
+	}
+
+	if err = m.SaveStorageProof(proof); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// getSpotChecker returns a random online node holding the replica other than the challenged node, the candidates first
+func (m *Manager) getSpotChecker(hash, nodeID string) (*node.Node, error) {
+	replicas, err := m.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return nil, err
+	}
+
+	rand.Shuffle(len(replicas), func(i, j int) {
+		replicas[i], replicas[j] = replicas[j], replicas[i]
+	})
+
+	var checker *node.Node
+	for _, replica := range replicas {
+		if replica.NodeID == nodeID {
+			continue
+		}
+
+		n := m.nodeMgr.GetNode(replica.NodeID)
+		if n == nil {
+			continue
+		}
+
+		if n.Type == types.NodeCandidate {
+			return n, nil
+		}
+
+		if checker == nil {
+			checker = n
+		}
+	}
+
+	if checker == nil {
+		return nil, errNoSpotChecker
+	}
+
+	return checker, nil
+}
+
+// spotCheck asks the checker to calculate the expected hashes of the challenge, within its own deadline
+func spotCheck(checker *node.Node, challenge *types.StorageChallenge) (*types.StorageChallengeResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), challengeTimeout)
+	defer cancel()
+
+	return checker.ProveStorage(ctx, challenge)
+}
+
+// verifyResult checks the signature of the result of the node and compares its hashes with the expected ones
+func verifyResult(n *node.Node, challenge *types.StorageChallenge, result, expected *types.StorageChallengeResult) error {
+	if result.ChallengeID != challenge.ID {
+		return xerrors.Errorf("challenge id mismatch %s, %s", result.ChallengeID, challenge.ID)
+	}
+
+	if err := nodekey.Verify(n.PublicKey, result.Sign, result.SignData()); err != nil {
+		return xerrors.Errorf("verify sign: %w", err)
+	}
+
+	if len(result.Hashes) != len(expected.Hashes) {
+		return xerrors.Errorf("expected %d hashes, got %d", len(expected.Hashes), len(result.Hashes))
+	}
+
+	for i := range expected.Hashes {
+		if result.Hashes[i] != expected.Hashes[i] {
+			return xerrors.Errorf("hash of block %d mismatch", i)
+		}
+	}
+
+	return nil
+}
+
+// replicaLost removes the replica lost by the node and counts it in the penalty rules
+func (m *Manager) replicaLost(proof *types.StorageProof) {
+	log.Warnf("node %s lost the replica of asset %s, failed %d storage challenges in a row", proof.NodeID, proof.Hash, proof.Failures)
+
+	if err := m.assetMgr.RemoveReplica(proof.CID, proof.Hash, proof.NodeID); err != nil {
+		log.Errorf("RemoveReplica %s of node %s err:%s", proof.Hash, proof.NodeID, err.Error())
+		return
+	}
+
+	if err := m.DeleteStorageProof(proof.Hash, proof.NodeID); err != nil {
+		log.Errorf("DeleteStorageProof %s of node %s err:%s", proof.Hash, proof.NodeID, err.Error())
+	}
+
+	m.penaltyMgr.ReplicaLost(proof.NodeID, proof.Hash)
+}
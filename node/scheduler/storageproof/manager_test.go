@@ -0,0 +1,45 @@
+package storageproof
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+func TestVerifyResult(t *testing.T) {
+	key, err := nodekey.Generate(nodekey.TypeEd25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := &node.Node{NodeID: "e_1", PublicKey: key.Public()}
+	challenge := &types.StorageChallenge{ID: "c1"}
+	expected := &types.StorageChallengeResult{ChallengeID: "c1", Hashes: []string{"a", "b"}}
+
+	sign := func(result *types.StorageChallengeResult) *types.StorageChallengeResult {
+		if result.Sign, err = nodekey.Sign(key, result.SignData()); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if err := verifyResult(n, challenge, sign(&types.StorageChallengeResult{ChallengeID: "c1", Hashes: []string{"a", "b"}}), expected); err != nil {
+		t.Fatalf("expected the result to pass, got %s", err)
+	}
+
+	failed := []*types.StorageChallengeResult{
+		sign(&types.StorageChallengeResult{ChallengeID: "c2", Hashes: []string{"a", "b"}}),
+		sign(&types.StorageChallengeResult{ChallengeID: "c1", Hashes: []string{"a"}}),
+		sign(&types.StorageChallengeResult{ChallengeID: "c1", Hashes: []string{"a", "c"}}),
+		// signed by another key
+		{ChallengeID: "c1", Hashes: []string{"a", "b"}, Sign: expected.Sign},
+	}
+
+	for i, result := range failed {
+		if err := verifyResult(n, challenge, result, expected); err == nil {
+			t.Errorf("case %d: expected the result to fail", i)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// ListStorageProofs retrieves the latest storage proofs of the replicas of the node, the latest first
+func (s *Scheduler) ListStorageProofs(ctx context.Context, nodeID string, limit, offset int) (*types.ListStorageProofRsp, error) {
+	return s.StorageProofManager.LoadStorageProofs(nodeID, limit, offset)
+}
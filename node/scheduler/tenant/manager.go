@@ -0,0 +1,493 @@
+package tenant
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("tenant")
+
+// the tenants changed on the other schedulers are loaded and the usage is saved in the interval
+const syncInterval = time.Minute
+
+// Manager keeps the tenants and the tenants of the users, the requests of the users of a tenant are limited by
+// the rate limit of the tenant and their usage is counted in memory and saved once a minute
+type Manager struct {
+	*db.SQLDB
+
+	lk      sync.RWMutex
+	tenants map[string]*types.Tenant
+	// user id -> tenant id, empty for the users not in a tenant, cleared when the tenants are reloaded
+	users    map[string]string
+	limiters map[string]*rate.Limiter
+	// tenant id and quota -> the day the soft limit of the quota was warned
+	warned map[string]string
+
+	usageLk sync.Mutex
+	// the usage not saved yet, keyed by the tenant and the day
+	usage map[string]*types.TenantUsageDaily
+}
+
+// NewManager return new tenant manager instance
+func NewManager(sdb *db.SQLDB) *Manager {
+	m := &Manager{
+		SQLDB:    sdb,
+		tenants:  make(map[string]*types.Tenant),
+		users:    make(map[string]string),
+		limiters: make(map[string]*rate.Limiter),
+		warned:   make(map[string]string),
+		usage:    make(map[string]*types.TenantUsageDaily),
+	}
+
+	m.reload()
+	go m.startSyncTimer()
+
+	return m
+}
+
+func (m *Manager) startSyncTimer() {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		m.saveUsage()
+		m.reload()
+	}
+}
+
+// reload loads the tenants, the limiters of the tenants whose rate limit is not changed are kept
+func (m *Manager) reload() {
+	list, err := m.LoadTenants()
+	if err != nil {
+		log.Errorf("LoadTenants err:%s", err.Error())
+		return
+	}
+
+	tenants := make(map[string]*types.Tenant, len(list))
+	for _, t := range list {
+		tenants[t.ID] = t
+	}
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	for id := range m.limiters {
+		t, ok := tenants[id]
+		old, loaded := m.tenants[id]
+		if !ok || !loaded || t.RateLimit != old.RateLimit {
+			delete(m.limiters, id)
+		}
+	}
+
+	m.tenants = tenants
+	m.users = make(map[string]string)
+}
+
+// SetTenant creates or updates the tenant
+func (m *Manager) SetTenant(tenant *types.Tenant) error {
+	if tenant == nil {
+		return xerrors.New("tenant can not empty")
+	}
+
+	if err := tenant.Validate(); err != nil {
+		return err
+	}
+
+	if err := m.SaveTenant(tenant); err != nil {
+		return xerrors.Errorf("SaveTenant err:%s", err.Error())
+	}
+
+	m.reload()
+	return nil
+}
+
+// RemoveTenant removes the tenant, the tenant can not have users
+func (m *Manager) RemoveTenant(tenantID string) error {
+	if err := m.DeleteTenant(tenantID); err != nil {
+		return xerrors.Errorf("DeleteTenant err:%s", err.Error())
+	}
+
+	m.reload()
+	return nil
+}
+
+// Tenants returns the tenants ordered by id
+func (m *Manager) Tenants() []*types.Tenant {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	out := make([]*types.Tenant, 0, len(m.tenants))
+	for _, t := range m.tenants {
+		out = append(out, t)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Tenant returns the tenant, nil if it is not found
+func (m *Manager) Tenant(tenantID string) *types.Tenant {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	return m.tenants[tenantID]
+}
+
+// AddUser adds the user to the tenant, a user is in one tenant at most
+func (m *Manager) AddUser(tenantID, userID string) error {
+	if userID == "" {
+		return xerrors.New("user id can not empty")
+	}
+
+	if m.Tenant(tenantID) == nil {
+		return xerrors.Errorf("tenant %s not found", tenantID)
+	}
+
+	if err := m.SaveTenantUser(tenantID, userID); err != nil {
+		return xerrors.Errorf("SaveTenantUser err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	m.users[userID] = tenantID
+	m.lk.Unlock()
+
+	return nil
+}
+
+// RemoveUser removes the user from the tenant
+func (m *Manager) RemoveUser(tenantID, userID string) error {
+	if err := m.DeleteTenantUser(tenantID, userID); err != nil {
+		return xerrors.Errorf("DeleteTenantUser err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	m.users[userID] = ""
+	m.lk.Unlock()
+
+	return nil
+}
+
+// TenantOfUser returns the tenant of the user, nil if the user is not in a tenant
+func (m *Manager) TenantOfUser(userID string) (*types.Tenant, error) {
+	m.lk.RLock()
+	tenantID, ok := m.users[userID]
+	m.lk.RUnlock()
+
+	if !ok {
+		var err error
+		tenantID, err = m.LoadTenantOfUser(userID)
+		if err != nil {
+			return nil, xerrors.Errorf("LoadTenantOfUser err:%s", err.Error())
+		}
+
+		m.lk.Lock()
+		m.users[userID] = tenantID
+		m.lk.Unlock()
+	}
+
+	if tenantID == "" {
+		return nil, nil
+	}
+
+	return m.Tenant(tenantID), nil
+}
+
+// Admit counts a request of a user of the tenant, the request is rejected if it exceeds the rate limit of the tenant
+func (m *Manager) Admit(tenant *types.Tenant) error {
+	allowed := m.limiter(tenant).Allow()
+
+	m.addUsage(tenant.ID, func(u *types.TenantUsageDaily) {
+		if allowed {
+			u.Requests++
+		} else {
+			u.Throttled++
+		}
+	})
+
+	if !allowed {
+		return &api.ErrWeb{Code: terrors.TenantRateLimited.Int(), Message: fmt.Sprintf("the requests of tenant %s exceed %d per second", tenant.ID, tenant.RateLimit)}
+	}
+
+	return nil
+}
+
+// limiter returns the rate limiter of the tenant, the limiter of a tenant without rate limit allows all requests
+func (m *Manager) limiter(tenant *types.Tenant) *rate.Limiter {
+	m.lk.RLock()
+	l, ok := m.limiters[tenant.ID]
+	m.lk.RUnlock()
+
+	if ok {
+		return l
+	}
+
+	l = rate.NewLimiter(rate.Inf, 0)
+	if tenant.RateLimit > 0 {
+		l = rate.NewLimiter(rate.Limit(tenant.RateLimit), tenant.RateLimit)
+	}
+
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	if existing, ok := m.limiters[tenant.ID]; ok {
+		return existing
+	}
+	m.limiters[tenant.ID] = l
+
+	return l
+}
+
+// CheckStorage checks if the storage quota of the tenant has room for the asset, the warning is not empty
+// if the stored bytes of the tenant pass its soft limit with the asset
+func (m *Manager) CheckStorage(tenant *types.Tenant, size int64) (string, error) {
+	if tenant.StorageQuota <= 0 {
+		return "", nil
+	}
+
+	quota, err := m.loadQuota(tenant.ID)
+	if err != nil {
+		return "", &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	stored := quota.StoredBytes + size
+	if stored > tenant.StorageQuota {
+		return "", &api.ErrWeb{Code: terrors.TenantStorageNotEnough.Int(), Message: fmt.Sprintf("the storage quota %d of tenant %s is used up", tenant.StorageQuota, tenant.ID)}
+	}
+
+	if !passSoftLimit(stored, tenant.StorageQuota, tenant.SoftLimitPercent()) {
+		return "", nil
+	}
+
+	return m.warn(tenant, "storage", stored, tenant.StorageQuota), nil
+}
+
+// CheckTraffic checks if the traffic quota of the tenant for the month is not used up, the warning is not empty
+// if the egress of the tenant passes its soft limit
+func (m *Manager) CheckTraffic(tenant *types.Tenant) (string, error) {
+	if tenant.TrafficQuota <= 0 {
+		return "", nil
+	}
+
+	quota, err := m.loadQuota(tenant.ID)
+	if err != nil {
+		return "", &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if quota.EgressBytes >= tenant.TrafficQuota {
+		return "", &api.ErrWeb{Code: terrors.TenantTrafficExceeded.Int(), Message: fmt.Sprintf("the traffic quota %d of tenant %s is used up this month", tenant.TrafficQuota, tenant.ID)}
+	}
+
+	if !passSoftLimit(quota.EgressBytes, tenant.TrafficQuota, tenant.SoftLimitPercent()) {
+		return "", nil
+	}
+
+	return m.warn(tenant, "traffic", quota.EgressBytes, tenant.TrafficQuota), nil
+}
+
+// Quota returns the quota counters of the tenant against its quotas
+func (m *Manager) Quota(tenantID string) (*types.TenantQuotaStatus, error) {
+	tenant := m.Tenant(tenantID)
+	if tenant == nil {
+		return nil, xerrors.Errorf("tenant %s not found", tenantID)
+	}
+
+	quota, err := m.loadQuota(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &types.TenantQuotaStatus{Tenant: tenant, StoredBytes: quota.StoredBytes, EgressBytes: quota.EgressBytes}
+	if tenant.StorageQuota > 0 {
+		status.StorageWarning = passSoftLimit(quota.StoredBytes, tenant.StorageQuota, tenant.SoftLimitPercent())
+		status.StorageExceeded = quota.StoredBytes >= tenant.StorageQuota
+	}
+
+	if tenant.TrafficQuota > 0 {
+		status.TrafficWarning = passSoftLimit(quota.EgressBytes, tenant.TrafficQuota, tenant.SoftLimitPercent())
+		status.TrafficExceeded = quota.EgressBytes >= tenant.TrafficQuota
+	}
+
+	return status, nil
+}
+
+// loadQuota loads the quota counters of the tenant, the egress counted in a previous month is reported as 0
+func (m *Manager) loadQuota(tenantID string) (*types.TenantQuota, error) {
+	quota, err := m.LoadTenantQuota(tenantID)
+	if err == sql.ErrNoRows {
+		return &types.TenantQuota{TenantID: tenantID}, nil
+	} else if err != nil {
+		return nil, xerrors.Errorf("LoadTenantQuota err:%s", err.Error())
+	}
+
+	if quota.EgressMonth.Before(monthStart(time.Now())) {
+		quota.EgressBytes = 0
+	}
+
+	return quota, nil
+}
+
+// warn returns the warning of the quota of the tenant passing its soft limit, the warning is logged once a day
+func (m *Manager) warn(tenant *types.Tenant, quota string, used, limit int64) string {
+	warning := fmt.Sprintf("the %s of tenant %s is %d%% of its quota %d", quota, tenant.ID, used*100/limit, limit)
+
+	day := time.Now().Format("2006-01-02")
+	key := fmt.Sprintf("%s/%s", tenant.ID, quota)
+
+	m.lk.Lock()
+	logged := m.warned[key] == day
+	m.warned[key] = day
+	m.lk.Unlock()
+
+	if !logged {
+		log.Warn(warning)
+	}
+
+	return warning
+}
+
+// passSoftLimit checks if the used passes the percent of the quota
+func passSoftLimit(used, quota int64, percent int) bool {
+	return used*100 >= quota*int64(percent)
+}
+
+// AddUploaded counts the asset uploaded by a user of the tenant
+func (m *Manager) AddUploaded(tenantID string, size int64) {
+	m.addUsage(tenantID, func(u *types.TenantUsageDaily) { u.Uploaded += size })
+}
+
+// AddTraffic counts the traffic downloaded by a user of the tenant in the daily usage
+func (m *Manager) AddTraffic(tenantID string, traffic int64) {
+	m.addUsage(tenantID, func(u *types.TenantUsageDaily) { u.Traffic += traffic })
+}
+
+func (m *Manager) addUsage(tenantID string, add func(u *types.TenantUsageDaily)) {
+	day := dayStart(time.Now())
+	key := fmt.Sprintf("%s/%s", tenantID, day.Format("2006-01-02"))
+
+	m.usageLk.Lock()
+	defer m.usageLk.Unlock()
+
+	u, ok := m.usage[key]
+	if !ok {
+		u = &types.TenantUsageDaily{TenantID: tenantID, Day: day}
+		m.usage[key] = u
+	}
+	add(u)
+}
+
+// saveUsage saves the usage counted since the last save, the usage failed to save is kept for the next save
+func (m *Manager) saveUsage() {
+	m.usageLk.Lock()
+	usage := m.usage
+	m.usage = make(map[string]*types.TenantUsageDaily)
+	m.usageLk.Unlock()
+
+	for key, u := range usage {
+		if err := m.AddTenantUsage(u); err != nil {
+			log.Errorf("AddTenantUsage %s err:%s", key, err.Error())
+
+			m.usageLk.Lock()
+			if pending, ok := m.usage[key]; ok {
+				pending.Requests += u.Requests
+				pending.Throttled += u.Throttled
+				pending.Uploaded += u.Uploaded
+				pending.Traffic += u.Traffic
+			} else {
+				m.usage[key] = u
+			}
+			m.usageLk.Unlock()
+		}
+	}
+}
+
+// Usage returns the users, the assets, the storage and the usage of the month of the tenant
+func (m *Manager) Usage(tenantID string) (*types.TenantUsage, error) {
+	tenant := m.Tenant(tenantID)
+	if tenant == nil {
+		return nil, xerrors.Errorf("tenant %s not found", tenantID)
+	}
+
+	users, assets, used, err := m.LoadTenantStorage(tenantID)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadTenantStorage err:%s", err.Error())
+	}
+
+	now := time.Now()
+	report, err := m.Report(tenantID, monthStart(now), now)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Total.Day = monthStart(now)
+	return &types.TenantUsage{Tenant: tenant, Users: users, Assets: assets, StorageUsed: used, Month: report.Total}, nil
+}
+
+// Report returns the daily usage of the tenant in [start, end], the usage not saved yet is included
+func (m *Manager) Report(tenantID string, start, end time.Time) (*types.TenantUsageReport, error) {
+	if !end.After(start) {
+		return nil, xerrors.New("end must be after start")
+	}
+
+	start, last := dayStart(start), dayStart(end)
+	days, err := m.LoadTenantUsage(tenantID, start, last.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, xerrors.Errorf("LoadTenantUsage err:%s", err.Error())
+	}
+
+	byDay := make(map[string]*types.TenantUsageDaily, len(days))
+	for _, d := range days {
+		byDay[d.Day.Format("2006-01-02")] = d
+	}
+
+	m.usageLk.Lock()
+	for _, u := range m.usage {
+		if u.TenantID != tenantID || u.Day.Before(start) || u.Day.After(last) {
+			continue
+		}
+
+		key := u.Day.Format("2006-01-02")
+		d, ok := byDay[key]
+		if !ok {
+			d = &types.TenantUsageDaily{TenantID: tenantID, Day: u.Day}
+			byDay[key] = d
+			days = append(days, d)
+		}
+		d.Requests += u.Requests
+		d.Throttled += u.Throttled
+		d.Uploaded += u.Uploaded
+		d.Traffic += u.Traffic
+	}
+	m.usageLk.Unlock()
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+
+	total := &types.TenantUsageDaily{TenantID: tenantID, Day: start}
+	for _, d := range days {
+		total.Requests += d.Requests
+		total.Throttled += d.Throttled
+		total.Uploaded += d.Uploaded
+		total.Traffic += d.Traffic
+	}
+
+	return &types.TenantUsageReport{TenantID: tenantID, Start: start, End: end, Days: days, Total: total}, nil
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,81 @@
+package tenant
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/time/rate"
+)
+
+func newTestManager(tenants ...*types.Tenant) *Manager {
+	m := &Manager{
+		tenants:  make(map[string]*types.Tenant),
+		users:    make(map[string]string),
+		limiters: make(map[string]*rate.Limiter),
+		warned:   make(map[string]string),
+		usage:    make(map[string]*types.TenantUsageDaily),
+	}
+
+	for _, t := range tenants {
+		m.tenants[t.ID] = t
+	}
+	return m
+}
+
+func TestAdmit(t *testing.T) {
+	limited := &types.Tenant{ID: "reseller", RateLimit: 2}
+	unlimited := &types.Tenant{ID: "free"}
+	m := newTestManager(limited, unlimited)
+
+	for i := 0; i < 2; i++ {
+		if err := m.Admit(limited); err != nil {
+			t.Fatalf("request %d rejected within the burst: %s", i, err)
+		}
+	}
+
+	if err := m.Admit(limited); err == nil {
+		t.Fatal("request over the rate limit admitted")
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := m.Admit(unlimited); err != nil {
+			t.Fatalf("request of the tenant without rate limit rejected: %s", err)
+		}
+	}
+
+	var requests, throttled int64
+	for _, u := range m.usage {
+		if u.TenantID == limited.ID {
+			requests += u.Requests
+			throttled += u.Throttled
+		}
+	}
+
+	if requests != 2 || throttled != 1 {
+		t.Errorf("expect 2 requests and 1 throttled, got %d and %d", requests, throttled)
+	}
+}
+
+func TestPassSoftLimit(t *testing.T) {
+	tests := []struct {
+		used, quota int64
+		percent     int
+		expect      bool
+	}{
+		{used: 79, quota: 100, percent: 80, expect: false},
+		{used: 80, quota: 100, percent: 80, expect: true},
+		{used: 100, quota: 100, percent: 100, expect: true},
+		{used: 0, quota: 100, percent: 1, expect: false},
+		{used: 7 << 40, quota: 8 << 40, percent: 90, expect: false},
+	}
+
+	for _, tt := range tests {
+		if got := passSoftLimit(tt.used, tt.quota, tt.percent); got != tt.expect {
+			t.Errorf("passSoftLimit(%d, %d, %d) = %v, expect %v", tt.used, tt.quota, tt.percent, got, tt.expect)
+		}
+	}
+
+	if percent := (&types.Tenant{}).SoftLimitPercent(); percent != types.DefaultTenantSoftLimit {
+		t.Errorf("expect the default soft limit %d, got %d", types.DefaultTenantSoftLimit, percent)
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"golang.org/x/xerrors"
+)
+
+// the id prefix of the tokens scoped to a tenant
+const tenantTokenPrefix = "tenant:"
+
+// SetTenant creates or updates the tenant with its quotas, rate limit and geo rules
+func (s *Scheduler) SetTenant(ctx context.Context, tenant *types.Tenant) error {
+	return s.TenantManager.SetTenant(tenant)
+}
+
+// RemoveTenant removes the tenant, the users of the tenant are removed first
+func (s *Scheduler) RemoveTenant(ctx context.Context, tenantID string) error {
+	return s.TenantManager.RemoveTenant(tenantID)
+}
+
+// ListTenants get the tenants, a caller scoped to a tenant gets its tenant only
+func (s *Scheduler) ListTenants(ctx context.Context) ([]*types.Tenant, error) {
+	scope := api.GetTenant(ctx)
+	if scope == "" {
+		return s.TenantManager.Tenants(), nil
+	}
+
+	tenant := s.TenantManager.Tenant(scope)
+	if tenant == nil {
+		return nil, nil
+	}
+
+	return []*types.Tenant{tenant}, nil
+}
+
+// AddTenantUser adds the user to the tenant, a user is in one tenant at most
+func (s *Scheduler) AddTenantUser(ctx context.Context, tenantID, userID string) error {
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return err
+	}
+
+	return s.TenantManager.AddUser(tenantID, userID)
+}
+
+// RemoveTenantUser removes the user from the tenant
+func (s *Scheduler) RemoveTenantUser(ctx context.Context, tenantID, userID string) error {
+	return s.TenantManager.RemoveUser(tenantID, userID)
+}
+
+// ListTenantUsers get the users of the tenant
+func (s *Scheduler) ListTenantUsers(ctx context.Context, tenantID string, limit, offset int) (*types.ListTenantUserRsp, error) {
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return s.db.LoadTenantUsers(tenantID, limit, offset)
+}
+
+// GetTenantUsage get the users, the assets, the storage and the usage of the month of the tenant
+func (s *Scheduler) GetTenantUsage(ctx context.Context, tenantID string) (*types.TenantUsage, error) {
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return s.TenantManager.Usage(tenantID)
+}
+
+// GetTenantUsageReport get the daily usage of the tenant in the days from start to end
+func (s *Scheduler) GetTenantUsageReport(ctx context.Context, tenantID string, start, end time.Time) (*types.TenantUsageReport, error) {
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return s.TenantManager.Report(tenantID, start, end)
+}
+
+// GetTenantQuota get the stored bytes and the egress of the month of the tenant against its quotas
+func (s *Scheduler) GetTenantQuota(ctx context.Context, tenantID string) (*types.TenantQuotaStatus, error) {
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return s.TenantManager.Quota(tenantID)
+}
+
+// CreateTenantToken creates a token scoped to the tenant, the token manages the users and the assets of the tenant only
+func (s *Scheduler) CreateTenantToken(ctx context.Context, tenantID string) (string, error) {
+	if s.TenantManager.Tenant(tenantID) == nil {
+		return "", xerrors.Errorf("tenant %s not found", tenantID)
+	}
+
+	payload := types.JWTPayload{ID: tenantTokenPrefix + tenantID, Allow: []auth.Permission{api.RoleWeb}, Tenant: tenantID}
+	return s.AuthNew(ctx, &payload)
+}
+
+// checkTenantScope checks if the caller can access the tenant, a caller not scoped to a tenant accesses all tenants
+func checkTenantScope(ctx context.Context, tenantID string) error {
+	scope := api.GetTenant(ctx)
+	if scope != "" && scope != tenantID {
+		return &api.ErrWeb{Code: terrors.TenantAccessDenied.Int(), Message: fmt.Sprintf("can not access tenant %s", tenantID)}
+	}
+
+	return nil
+}
+
+// admitUser resolves the user of the request and its tenant, the request is counted and limited by the rate limit
+// of the tenant. A caller scoped to a tenant can access the users of the tenant only
+func (s *Scheduler) admitUser(ctx context.Context, userID string) (string, *types.Tenant, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	tenant, err := s.TenantManager.TenantOfUser(userID)
+	if err != nil {
+		return "", nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if scope := api.GetTenant(ctx); scope != "" && (tenant == nil || tenant.ID != scope) {
+		return "", nil, &api.ErrWeb{Code: terrors.TenantAccessDenied.Int(), Message: fmt.Sprintf("user %s is not a user of tenant %s", userID, scope)}
+	}
+
+	if tenant != nil {
+		if err := s.TenantManager.Admit(tenant); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return userID, tenant, nil
+}
@@ -0,0 +1,218 @@
+package token
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("token")
+
+// interval to reload the roles and revocations, they may be changed by other schedulers sharing the database
+const reloadInterval = time.Minute
+
+// Manager manages the api tokens and the revocation of tokens, the roles and revocations
+// are cached and looked up on every request so changes take effect immediately
+type Manager struct {
+	*db.SQLDB
+
+	lk    sync.RWMutex
+	roles map[string]auth.Permission
+	// subject -> unix nano time its tokens are revoked
+	revoked map[string]int64
+}
+
+// NewManager return new token manager instance
+func NewManager(sdb *db.SQLDB) (*Manager, error) {
+	m := &Manager{SQLDB: sdb}
+
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+
+	go m.startReloadTimer()
+
+	return m, nil
+}
+
+func (m *Manager) startReloadTimer() {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		if err := m.reload(); err != nil {
+			log.Errorf("reload tokens err:%s", err.Error())
+		}
+	}
+}
+
+func (m *Manager) reload() error {
+	tokens, err := m.LoadAPITokens()
+	if err != nil {
+		return xerrors.Errorf("LoadAPITokens err:%s", err.Error())
+	}
+
+	subjects, err := m.LoadRevokedTokenSubjects()
+	if err != nil {
+		return xerrors.Errorf("LoadRevokedTokenSubjects err:%s", err.Error())
+	}
+
+	roles := make(map[string]auth.Permission, len(tokens))
+	for _, tk := range tokens {
+		roles[tk.ID] = tk.Role
+	}
+
+	revoked := make(map[string]int64, len(subjects))
+	for _, sub := range subjects {
+		revoked[sub.Subject] = sub.RevokedAt
+	}
+
+	m.lk.Lock()
+	m.roles = roles
+	m.revoked = revoked
+	m.lk.Unlock()
+
+	return nil
+}
+
+// NewToken creates an api token with the role
+func (m *Manager) NewToken(name string, role auth.Permission) (*types.APIToken, error) {
+	if !isTokenRole(role) {
+		return nil, xerrors.Errorf("invalid role %s", role)
+	}
+
+	tk := &types.APIToken{ID: uuid.NewString(), Name: name, Role: role}
+	if err := m.SaveAPIToken(tk); err != nil {
+		return nil, xerrors.Errorf("SaveAPIToken err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	m.roles[tk.ID] = role
+	m.lk.Unlock()
+
+	return tk, nil
+}
+
+// AssignRole assigns the role to the api token
+func (m *Manager) AssignRole(id string, role auth.Permission) error {
+	if !isTokenRole(role) {
+		return xerrors.Errorf("invalid role %s", role)
+	}
+
+	old, ok := m.Role(id)
+	if !ok {
+		return xerrors.Errorf("api token %s not found", id)
+	}
+
+	if old == role {
+		return nil
+	}
+
+	if err := m.UpdateAPITokenRole(id, role); err != nil {
+		return xerrors.Errorf("UpdateAPITokenRole err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	m.roles[id] = role
+	m.lk.Unlock()
+
+	return nil
+}
+
+// Role returns the role of the api token, false if the token is not issued by the manager
+func (m *Manager) Role(id string) (auth.Permission, bool) {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+
+	role, ok := m.roles[id]
+	return role, ok
+}
+
+func isTokenRole(role auth.Permission) bool {
+	for _, r := range api.TokenRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Issue records the issuing of the token and sets its issued time
+func (m *Manager) Issue(payload *types.JWTPayload) error {
+	payload.IssuedAt = time.Now().UnixNano()
+	if payload.ID == "" {
+		return nil
+	}
+
+	var role auth.Permission
+	if len(payload.Allow) > 0 {
+		role = payload.Allow[0]
+	}
+
+	return m.SaveTokenIssued(payload.ID, role, payload.IssuedAt)
+}
+
+// Revoke revokes all tokens of the subject issued before now, a revoked api token is deleted
+func (m *Manager) Revoke(subject string) error {
+	if subject == "" {
+		return xerrors.New("subject can not empty")
+	}
+
+	revokedAt := time.Now().UnixNano()
+	if err := m.SaveTokenRevoked(subject, revokedAt); err != nil {
+		return xerrors.Errorf("SaveTokenRevoked err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	m.revoked[subject] = revokedAt
+	_, isAPIToken := m.roles[subject]
+	delete(m.roles, subject)
+	m.lk.Unlock()
+
+	if isAPIToken {
+		return m.DeleteAPIToken(subject)
+	}
+
+	return nil
+}
+
+// Rotate revokes the tokens of the api token, the caller issues a new token with the same id
+func (m *Manager) Rotate(id string) (auth.Permission, error) {
+	role, ok := m.Role(id)
+	if !ok {
+		return "", xerrors.Errorf("api token %s not found", id)
+	}
+
+	revokedAt := time.Now().UnixNano()
+	if err := m.SaveTokenRevoked(id, revokedAt); err != nil {
+		return "", xerrors.Errorf("SaveTokenRevoked err:%s", err.Error())
+	}
+
+	m.lk.Lock()
+	m.revoked[id] = revokedAt
+	m.lk.Unlock()
+
+	return role, nil
+}
+
+// Verify checks whether the token has been revoked
+func (m *Manager) Verify(payload *types.JWTPayload) error {
+	m.lk.RLock()
+	revokedAt, ok := m.revoked[payload.ID]
+	m.lk.RUnlock()
+
+	if ok && payload.IssuedAt <= revokedAt {
+		return xerrors.Errorf("token of %s has been revoked", payload.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestVerifyRevoked(t *testing.T) {
+	m := &Manager{revoked: map[string]int64{"e_1": 100}}
+
+	tests := []struct {
+		payload types.JWTPayload
+		revoked bool
+	}{
+		{types.JWTPayload{ID: "e_1"}, true},
+		{types.JWTPayload{ID: "e_1", IssuedAt: 100}, true},
+		{types.JWTPayload{ID: "e_1", IssuedAt: 101}, false},
+		{types.JWTPayload{ID: "e_2"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := m.Verify(&tt.payload); (err != nil) != tt.revoked {
+			t.Fatalf("payload %+v: expected revoked %v, got err %v", tt.payload, tt.revoked, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"golang.org/x/xerrors"
+)
+
+// AuthVerify verifies the token, the role assigned to an api token replaces the permissions in the token
+func (s *Scheduler) AuthVerify(ctx context.Context, token string) (*types.JWTPayload, error) {
+	payload, err := s.CommonAPI.AuthVerify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.TokenManager.Verify(payload); err != nil {
+		return nil, err
+	}
+
+	// candidates must connect with the client certificate issued to them
+	if s.SchedulerCfg.EnableCandidateMTLS && hasPerm(payload.Allow, api.RoleCandidate) && handler.GetPeerCertID(ctx) != payload.ID {
+		return nil, xerrors.Errorf("candidate %s has no valid client certificate", payload.ID)
+	}
+
+	if role, ok := s.TokenManager.Role(payload.ID); ok {
+		payload.Allow = []auth.Permission{role}
+	}
+
+	return payload, nil
+}
+
+// AuthNew generates a new token, the issuing is recorded so the token can be revoked
+func (s *Scheduler) AuthNew(ctx context.Context, payload *types.JWTPayload) (string, error) {
+	if err := s.TokenManager.Issue(payload); err != nil {
+		return "", xerrors.Errorf("issue token err:%s", err.Error())
+	}
+
+	return s.CommonAPI.AuthNew(ctx, payload)
+}
+
+// CreateAPIToken creates an api token with the role
+func (s *Scheduler) CreateAPIToken(ctx context.Context, name string, role auth.Permission) (string, error) {
+	tk, err := s.TokenManager.NewToken(name, role)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.AuthNew(ctx, &types.JWTPayload{Allow: []auth.Permission{tk.Role}, ID: tk.ID})
+	if err != nil {
+		return "", xerrors.Errorf("AuthNew err:%s", err.Error())
+	}
+
+	return token, nil
+}
+
+// AssignAPITokenRole assigns the role to the api token
+func (s *Scheduler) AssignAPITokenRole(ctx context.Context, id string, role auth.Permission) error {
+	return s.TokenManager.AssignRole(id, role)
+}
+
+// GetAPITokens get the api tokens issued by the scheduler
+func (s *Scheduler) GetAPITokens(ctx context.Context) ([]*types.APIToken, error) {
+	return s.TokenManager.LoadAPITokens()
+}
+
+// RevokeTokens revokes all tokens of the subject issued before now
+func (s *Scheduler) RevokeTokens(ctx context.Context, subject string) error {
+	return s.TokenManager.Revoke(subject)
+}
+
+// RotateAPIToken revokes the api token and issues a new one with the same id and role
+func (s *Scheduler) RotateAPIToken(ctx context.Context, id string) (string, error) {
+	role, err := s.TokenManager.Rotate(id)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.AuthNew(ctx, &types.JWTPayload{Allow: []auth.Permission{role}, ID: id})
+	if err != nil {
+		return "", xerrors.Errorf("AuthNew err:%s", err.Error())
+	}
+
+	return token, nil
+}
+
+// RotateUserTokens revokes the tokens of the user, regenerates its api keys and returns a new access token
+func (s *Scheduler) RotateUserTokens(ctx context.Context, userID string) (string, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	if _, err := s.GetUserInfo(ctx, userID); err != nil {
+		return "", err
+	}
+
+	if err := s.TokenManager.Revoke(userID); err != nil {
+		return "", err
+	}
+
+	if err := s.newUser(userID).RegenerateAPIKeys(ctx, s); err != nil {
+		return "", xerrors.Errorf("RegenerateAPIKeys err:%s", err.Error())
+	}
+
+	return s.GetUserAccessToken(ctx, userID)
+}
+
+// GetActiveTokens get the subjects that have tokens issued after their revocation
+func (s *Scheduler) GetActiveTokens(ctx context.Context, role auth.Permission, limit, offset int) (*types.ListTokenSubjectRsp, error) {
+	return s.TokenManager.LoadActiveTokenSubjects(role, limit, offset)
+}
+
+func hasPerm(perms []auth.Permission, perm auth.Permission) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAuditLogs get the mutations made by admin, operator and web callers
+func (s *Scheduler) GetAuditLogs(ctx context.Context, req *types.ListAuditLogsReq) (*types.ListAuditLogRsp, error) {
+	if req == nil {
+		req = &types.ListAuditLogsReq{}
+	}
+
+	return s.NodeManager.LoadAuditLogs(req)
+}
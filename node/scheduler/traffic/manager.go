@@ -0,0 +1,249 @@
+package traffic
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("traffic")
+
+const (
+	// MonthLayout the layout of the months of the statements
+	MonthLayout = "2006-01"
+
+	flushInterval  = time.Minute
+	rollupInterval = time.Hour
+	// time to wait the traffic of the last day of the month to be flushed before rolling up the month
+	rollupDelay = time.Hour
+)
+
+// Manager accounts the bytes uploaded and downloaded by the nodes per day and rolls them up to monthly statements,
+// the uploads are accounted from the succeeded retrievals and the downloads from the succeeded replications
+type Manager struct {
+	leadershipMgr *leadership.Manager
+	*db.SQLDB
+
+	lk      sync.Mutex
+	pending map[dailyKey]*types.NodeTrafficDaily
+}
+
+type dailyKey struct {
+	nodeID string
+	date   time.Time
+}
+
+// NewManager return new traffic manager instance
+func NewManager(sdb *db.SQLDB, lmgr *leadership.Manager) *Manager {
+	m := &Manager{
+		leadershipMgr: lmgr,
+		SQLDB:         sdb,
+		pending:       make(map[dailyKey]*types.NodeTrafficDaily),
+	}
+
+	go m.startFlushTimer()
+	go m.startRollupTimer()
+
+	return m
+}
+
+// AddUpload accounts the bytes uploaded by the node
+func (m *Manager) AddUpload(nodeID string, size int64) {
+	m.add(nodeID, size, 0)
+}
+
+// AddDownload accounts the bytes downloaded by the node
+func (m *Manager) AddDownload(nodeID string, size int64) {
+	m.add(nodeID, 0, size)
+}
+
+func (m *Manager) add(nodeID string, upload, download int64) {
+	if nodeID == "" || (upload <= 0 && download <= 0) {
+		return
+	}
+
+	m.addDaily(dailyKey{nodeID: nodeID, date: dateOf(time.Now())}, upload, download)
+}
+
+// addDaily accounts the traffic to the day of the key
+func (m *Manager) addDaily(key dailyKey, upload, download int64) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	traffic, ok := m.pending[key]
+	if !ok {
+		traffic = &types.NodeTrafficDaily{NodeID: key.nodeID, Date: key.date}
+		m.pending[key] = traffic
+	}
+
+	traffic.UploadBytes += upload
+	traffic.DownloadBytes += download
+}
+
+func (m *Manager) startFlushTimer() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.flush()
+	}
+}
+
+// flush saves the pending traffic, the traffic is kept to be saved again to its day on failure
+func (m *Manager) flush() {
+	m.lk.Lock()
+	pending := m.pending
+	m.pending = make(map[dailyKey]*types.NodeTrafficDaily)
+	m.lk.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	traffics := make([]*types.NodeTrafficDaily, 0, len(pending))
+	for _, traffic := range pending {
+		traffics = append(traffics, traffic)
+	}
+
+	if err := m.AddNodeTraffics(traffics); err != nil {
+		log.Errorf("AddNodeTraffics err:%s", err.Error())
+
+		for key, traffic := range pending {
+			m.addDaily(key, traffic.UploadBytes, traffic.DownloadBytes)
+		}
+	}
+}
+
+func (m *Manager) startRollupTimer() {
+	ticker := time.NewTicker(rollupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.rollup()
+	}
+}
+
+// rollup rolls up the previous month to the statements once the month is closed
+func (m *Manager) rollup() {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return
+	}
+
+	// the previous month is closed for rollupDelay at least
+	end, _ := monthRange(time.Now().Add(-rollupDelay))
+	start := end.AddDate(0, -1, 0)
+
+	month := start.Format(MonthLayout)
+	rolledUp, err := m.IsNodeTrafficRolledUp(month)
+	if err != nil {
+		log.Errorf("IsNodeTrafficRolledUp %s err:%s", month, err.Error())
+		return
+	}
+
+	if rolledUp {
+		return
+	}
+
+	if err = m.RollupNodeTraffic(month, start, end); err != nil {
+		log.Errorf("RollupNodeTraffic %s err:%s", month, err.Error())
+		return
+	}
+
+	log.Infof("rolled up node traffic of %s", month)
+}
+
+// Daily returns the daily traffic of the node in the date range [start, end]
+func (m *Manager) Daily(nodeID string, start, end time.Time) ([]*types.NodeTrafficDaily, error) {
+	return m.LoadNodeTrafficDaily(nodeID, dateOf(start), dateOf(end).AddDate(0, 0, 1))
+}
+
+// Statement returns the statement of the node in the month with the daily traffic,
+// the statement of the month not closed is summed from the daily traffic
+func (m *Manager) Statement(nodeID, month string) (*types.NodeTrafficStatement, error) {
+	start, end, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := m.LoadNodeTrafficDaily(nodeID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := m.LoadNodeTrafficStatement(nodeID, month)
+	if err == nil {
+		statement.Final = true
+		statement.Days = days
+		return statement, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	statement = &types.NodeTrafficStatement{NodeID: nodeID, Month: month, Days: days}
+	for _, day := range days {
+		statement.UploadBytes += day.UploadBytes
+		statement.DownloadBytes += day.DownloadBytes
+		statement.ActiveDays++
+	}
+
+	return statement, nil
+}
+
+// Statements returns the statements of the nodes in the month,
+// the statements of the month not closed are summed from the daily traffic
+func (m *Manager) Statements(month string, limit, offset int) (*types.ListNodeTrafficStatementRsp, error) {
+	start, end, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	rolledUp, err := m.IsNodeTrafficRolledUp(month)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rolledUp {
+		return m.SumNodeTrafficStatements(month, start, end, limit, offset)
+	}
+
+	rsp, err := m.LoadNodeTrafficStatements(month, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range rsp.Data {
+		statement.Final = true
+	}
+
+	return rsp, nil
+}
+
+// dateOf returns the start of the day of t
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// monthRange returns the range [start, end) of the month of t
+func monthRange(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// parseMonth returns the range [start, end) of the month formatted as 2006-01
+func parseMonth(month string) (time.Time, time.Time, error) {
+	t, err := time.ParseInLocation(MonthLayout, month, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, xerrors.Errorf("invalid month %s, expect format %s", month, MonthLayout)
+	}
+
+	start, end := monthRange(t)
+	return start, end, nil
+}
@@ -0,0 +1,59 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestParseMonth(t *testing.T) {
+	start, end, err := parseMonth("2024-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if start.Format("2006-01-02") != "2024-12-01" || end.Format("2006-01-02") != "2025-01-01" {
+		t.Fatalf("unexpected range %s - %s", start, end)
+	}
+
+	if _, _, err = parseMonth("2024-13"); err == nil {
+		t.Fatal("expect error with invalid month")
+	}
+}
+
+func TestPendingTraffic(t *testing.T) {
+	m := &Manager{pending: make(map[dailyKey]*types.NodeTrafficDaily)}
+
+	m.AddUpload("e_1", 100)
+	m.AddDownload("e_1", 50)
+	m.AddUpload("e_1", 20)
+	m.AddUpload("e_2", 0)
+
+	if len(m.pending) != 1 {
+		t.Fatalf("unexpected pending traffic %v", m.pending)
+	}
+
+	traffic := m.pending[dailyKey{nodeID: "e_1", date: dateOf(time.Now())}]
+	if traffic == nil || traffic.UploadBytes != 120 || traffic.DownloadBytes != 50 {
+		t.Fatalf("unexpected traffic %+v", traffic)
+	}
+}
+
+func TestRequeueTraffic(t *testing.T) {
+	m := &Manager{pending: make(map[dailyKey]*types.NodeTrafficDaily)}
+
+	// the traffic of yesterday failed to be saved
+	yesterday := dailyKey{nodeID: "e_1", date: dateOf(time.Now()).AddDate(0, 0, -1)}
+	m.addDaily(yesterday, 100, 0)
+	m.AddUpload("e_1", 20)
+
+	traffic := m.pending[yesterday]
+	if traffic == nil || traffic.UploadBytes != 100 || !traffic.Date.Equal(yesterday.date) {
+		t.Fatalf("traffic of yesterday re-dated %+v", traffic)
+	}
+
+	if today := m.pending[dailyKey{nodeID: "e_1", date: dateOf(time.Now())}]; today == nil || today.UploadBytes != 20 {
+		t.Fatalf("unexpected traffic of today %+v", today)
+	}
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// GetNodeTrafficDaily retrieves the bytes the node uploaded and downloaded per day in the date range [start, end]
+func (s *Scheduler) GetNodeTrafficDaily(ctx context.Context, nodeID string, start, end time.Time) ([]*types.NodeTrafficDaily, error) {
+	return s.TrafficManager.Daily(nodeID, start, end)
+}
+
+// GetNodeTrafficStatement retrieves the traffic statement of the node in the month formatted as 2006-01, with the daily traffic
+func (s *Scheduler) GetNodeTrafficStatement(ctx context.Context, nodeID, month string) (*types.NodeTrafficStatement, error) {
+	return s.TrafficManager.Statement(nodeID, month)
+}
+
+// GetTrafficStatements retrieves the traffic statements of the nodes in the month formatted as 2006-01
+func (s *Scheduler) GetTrafficStatements(ctx context.Context, month string, limit, offset int) (*types.ListNodeTrafficStatementRsp, error) {
+	if err := s.OverloadManager.Admit(ctx, "GetTrafficStatements"); err != nil {
+		return nil, err
+	}
+
+	return s.TrafficManager.Statements(month, limit, offset)
+}
@@ -0,0 +1,526 @@
+package upgrade
+
+import (
+	"context"
+	"crypto"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("upgrade")
+
+const (
+	rolloutInterval = time.Minute
+	// a commanded node restarts and reconnects with the release within the grace, otherwise it is counted offline
+	upgradeGrace = 10 * time.Minute
+	// the node downloads and verifies the binary of the release within the timeout
+	commandTimeout    = 10 * time.Minute
+	commandExpiration = 15 * time.Minute
+	// the nodes being commanded at once by the scheduler
+	maxCommanding = 20
+	// the nodes seen within the window are counted in the version adoption
+	adoptionWindow = 24 * time.Hour
+)
+
+// Manager rolls out the signed releases to the nodes, the canary nodes first and then the waves of the percentages
+// of the nodes. The leader scheduler advances the waves and halts the rollout if the offline rate of a wave exceeds
+// its limit, every scheduler commands the nodes connected to it
+type Manager struct {
+	config        dtypes.GetSchedulerConfigFunc
+	leadershipMgr *leadership.Manager
+	nodeMgr       *node.Manager
+	keyRing       *keys.Ring
+	*db.SQLDB
+
+	commanding chan struct{}
+}
+
+// NewManager return new upgrade manager instance
+func NewManager(sdb *db.SQLDB, nmgr *node.Manager, lmgr *leadership.Manager, keyRing *keys.Ring, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	m := &Manager{
+		config:        configFunc,
+		leadershipMgr: lmgr,
+		nodeMgr:       nmgr,
+		keyRing:       keyRing,
+		SQLDB:         sdb,
+		commanding:    make(chan struct{}, maxCommanding),
+	}
+
+	go m.startRolloutTimer()
+
+	return m
+}
+
+// AddReleaseManifest saves the release manifest after verifying its signature with the release public key
+func (m *Manager) AddReleaseManifest(manifest *types.ReleaseManifest) error {
+	cfg, err := m.config()
+	if err != nil {
+		return err
+	}
+
+	if cfg.ReleasePublicKeyPath == "" {
+		return xerrors.New("upgrade rollouts are disabled, the release public key is not configured")
+	}
+
+	if _, err := parseVersion(manifest.Version); err != nil {
+		return err
+	}
+
+	if manifest.NodeType != types.NodeEdge && manifest.NodeType != types.NodeCandidate {
+		return xerrors.Errorf("node type %d can not be upgraded", manifest.NodeType)
+	}
+
+	if len(manifest.Artifacts) == 0 {
+		return xerrors.New("release has no artifact")
+	}
+
+	for _, artifact := range manifest.Artifacts {
+		if hash, err := hex.DecodeString(artifact.Hash); err != nil || len(hash) != 32 {
+			return xerrors.Errorf("invalid sha256 %s of %s", artifact.Hash, artifact.Platform)
+		}
+
+		if !strings.HasPrefix(artifact.URL, "https://") && !strings.HasPrefix(artifact.URL, "http://") {
+			return xerrors.Errorf("invalid url %s of %s", artifact.URL, artifact.Platform)
+		}
+	}
+
+	pem, err := os.ReadFile(cfg.ReleasePublicKeyPath)
+	if err != nil {
+		return xerrors.Errorf("read release public key err:%s", err.Error())
+	}
+
+	publicKey, err := titanrsa.Pem2PublicKey(pem)
+	if err != nil {
+		return xerrors.Errorf("parse release public key err:%s", err.Error())
+	}
+
+	if err := titanrsa.New(crypto.SHA256, crypto.SHA256.New()).VerifySign(publicKey, manifest.Sign, manifest.SignData()); err != nil {
+		return xerrors.Errorf("verify release manifest err:%s", err.Error())
+	}
+
+	return m.SaveReleaseManifest(manifest)
+}
+
+// StartRollout starts rolling out the release to the nodes of its type and returns the rollout id,
+// only one rollout of a node type runs at a time
+func (m *Manager) StartRollout(req *types.UpgradeRolloutReq) (string, error) {
+	if _, err := m.LoadReleaseManifest(req.Version, req.NodeType); err == sql.ErrNoRows {
+		return "", xerrors.Errorf("release %s of node type %d not found", req.Version, req.NodeType)
+	} else if err != nil {
+		return "", err
+	}
+
+	if err := checkWaves(req.Waves); err != nil {
+		return "", err
+	}
+
+	if req.CanaryCount < 0 || req.MaxOfflineRate <= 0 || req.MaxOfflineRate > 1 || req.WaveInterval <= 0 {
+		return "", xerrors.New("canary count, max offline rate in (0, 1] and wave interval are required")
+	}
+
+	running, err := m.LoadUpgradeRolloutsByStatus(types.UpgradeRolloutRunning)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range running {
+		if r.NodeType == req.NodeType {
+			return "", xerrors.Errorf("rollout %s of node type %d is running", r.ID, r.NodeType)
+		}
+	}
+
+	waves := make([]string, 0, len(req.Waves))
+	for _, w := range req.Waves {
+		waves = append(waves, strconv.Itoa(w))
+	}
+
+	rollout := &types.UpgradeRollout{
+		ID:             uuid.NewString(),
+		Version:        req.Version,
+		NodeType:       req.NodeType,
+		CanaryCount:    req.CanaryCount,
+		Waves:          strings.Join(waves, ","),
+		MaxOfflineRate: req.MaxOfflineRate,
+		WaveInterval:   req.WaveInterval,
+		Status:         types.UpgradeRolloutRunning,
+	}
+
+	if rollout.CanaryCount == 0 {
+		rollout.Wave = 1
+	}
+
+	if err := m.SaveUpgradeRollout(rollout); err != nil {
+		return "", err
+	}
+
+	return rollout.ID, nil
+}
+
+// GetRollout returns the upgrade rollout
+func (m *Manager) GetRollout(id string) (*types.UpgradeRollout, error) {
+	rollout, err := m.LoadUpgradeRollout(id)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("rollout %s not found", id)
+	}
+	return rollout, err
+}
+
+// HaltRollout halts the running rollout, the nodes being commanded finish their upgrades
+func (m *Manager) HaltRollout(id, reason string) error {
+	rollout, err := m.GetRollout(id)
+	if err != nil {
+		return err
+	}
+
+	if rollout.Status != types.UpgradeRolloutRunning {
+		return xerrors.Errorf("rollout %s is %s", id, rollout.Status)
+	}
+
+	rollout.Status = types.UpgradeRolloutHalted
+	rollout.Message = reason
+	return m.UpdateUpgradeRollout(rollout)
+}
+
+// ResumeRollout resumes the halted rollout, the current wave is observed again for its interval
+func (m *Manager) ResumeRollout(id string) error {
+	rollout, err := m.GetRollout(id)
+	if err != nil {
+		return err
+	}
+
+	if rollout.Status != types.UpgradeRolloutHalted {
+		return xerrors.Errorf("rollout %s is %s", id, rollout.Status)
+	}
+
+	running, err := m.LoadUpgradeRolloutsByStatus(types.UpgradeRolloutRunning)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range running {
+		if r.NodeType == rollout.NodeType {
+			return xerrors.Errorf("rollout %s of node type %d is running", r.ID, r.NodeType)
+		}
+	}
+
+	rollout.Status = types.UpgradeRolloutRunning
+	rollout.Message = ""
+	rollout.WaveStartedTime = time.Now()
+	return m.UpdateUpgradeRollout(rollout)
+}
+
+// VersionAdoption counts the nodes of the type seen in the last day by release version
+func (m *Manager) VersionAdoption(nodeType types.NodeType) ([]*types.VersionAdoption, error) {
+	return m.LoadVersionAdoption(nodeType, time.Now().Add(-adoptionWindow))
+}
+
+// NodeConnected marks the node upgraded if it reconnects with the release it was commanded to upgrade to
+func (m *Manager) NodeConnected(nodeID, version string) {
+	if version == "" {
+		return
+	}
+
+	if err := m.MarkNodeUpgraded(nodeID, version); err != nil {
+		log.Errorf("MarkNodeUpgraded %s err:%s", nodeID, err.Error())
+	}
+}
+
+func (m *Manager) startRolloutTimer() {
+	ticker := time.NewTicker(rolloutInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		rollouts, err := m.LoadUpgradeRolloutsByStatus(types.UpgradeRolloutRunning)
+		if err != nil {
+			log.Errorf("LoadUpgradeRolloutsByStatus err:%s", err.Error())
+			continue
+		}
+
+		for _, rollout := range rollouts {
+			if m.leadershipMgr.RequestAndBecomeMaster() {
+				if err := m.evaluate(rollout); err != nil {
+					log.Errorf("evaluate rollout %s err:%s", rollout.ID, err.Error())
+					continue
+				}
+			}
+
+			if rollout.Status != types.UpgradeRolloutRunning {
+				continue
+			}
+
+			if err := m.dispatch(rollout); err != nil {
+				log.Errorf("dispatch rollout %s err:%s", rollout.ID, err.Error())
+			}
+		}
+	}
+}
+
+// evaluate halts the rollout if the rate of the nodes of the current wave not back online with the release
+// within the grace exceeds the limit, otherwise advances to the next wave after the wave interval
+func (m *Manager) evaluate(rollout *types.UpgradeRollout) error {
+	waves, err := parseWaves(rollout.Waves)
+	if err != nil {
+		return err
+	}
+
+	total, offline, err := m.CountUpgradeNodesCommandedBefore(rollout.ID, rollout.Wave, time.Now().Add(-upgradeGrace))
+	if err != nil {
+		return err
+	}
+
+	if total > 0 && float64(offline)/float64(total) > rollout.MaxOfflineRate {
+		rollout.Status = types.UpgradeRolloutHalted
+		rollout.Message = fmt.Sprintf("wave %d offline rate %d/%d exceeds %.2f", rollout.Wave, offline, total, rollout.MaxOfflineRate)
+		log.Warnf("rollout %s halted, %s", rollout.ID, rollout.Message)
+		return m.UpdateUpgradeRollout(rollout)
+	}
+
+	if time.Since(rollout.WaveStartedTime) < time.Duration(rollout.WaveInterval)*time.Minute {
+		return nil
+	}
+
+	// all the nodes of the wave are observed for the grace before the next wave
+	all, _, err := m.CountUpgradeNodesCommandedBefore(rollout.ID, rollout.Wave, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if all > total {
+		return nil
+	}
+
+	rollout.Wave++
+	rollout.WaveStartedTime = time.Now()
+	if rollout.Wave > len(waves) {
+		rollout.Status = types.UpgradeRolloutCompleted
+	}
+
+	log.Infof("rollout %s advances to wave %d", rollout.ID, rollout.Wave)
+	return m.UpdateUpgradeRollout(rollout)
+}
+
+// dispatch commands the nodes connected to the scheduler up to the target of the current wave
+func (m *Manager) dispatch(rollout *types.UpgradeRollout) error {
+	waves, err := parseWaves(rollout.Waves)
+	if err != nil {
+		return err
+	}
+
+	var nodes []*node.Node
+	switch rollout.NodeType {
+	case types.NodeEdge:
+		nodes = m.nodeMgr.GetAllEdgeNode()
+	case types.NodeCandidate:
+		_, nodes = m.nodeMgr.GetAllValidCandidateNodes()
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	commandedIDs, err := m.LoadUpgradeNodeIDs(rollout.ID)
+	if err != nil {
+		return err
+	}
+
+	commanded := make(map[string]bool, len(commandedIDs))
+	for _, id := range commandedIDs {
+		commanded[id] = true
+	}
+
+	var done int
+	pending := make([]*node.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if commanded[n.NodeID] || compareVersions(n.Version, rollout.Version) >= 0 {
+			done++
+			continue
+		}
+		pending = append(pending, n)
+	}
+
+	var need int
+	if rollout.Wave == 0 {
+		// the canary count is shared by the schedulers
+		stats, err := m.LoadUpgradeWaveStats(rollout.ID)
+		if err != nil {
+			return err
+		}
+
+		need = rollout.CanaryCount
+		for _, s := range stats {
+			if s.Wave == 0 {
+				need -= s.Count
+			}
+		}
+	} else {
+		need = waveTarget(waves[rollout.Wave-1], len(nodes)) - done
+	}
+
+	if need <= 0 || len(pending) == 0 {
+		return nil
+	}
+
+	manifest, err := m.LoadReleaseManifest(rollout.Version, rollout.NodeType)
+	if err != nil {
+		return xerrors.Errorf("LoadReleaseManifest err:%s", err.Error())
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].NodeID < pending[j].NodeID
+	})
+
+	for _, n := range pending {
+		if need == 0 {
+			break
+		}
+
+		select {
+		case m.commanding <- struct{}{}:
+		default:
+			return nil
+		}
+
+		record := &types.UpgradeNode{RolloutID: rollout.ID, NodeID: n.NodeID, Wave: rollout.Wave, FromVersion: n.Version, Status: types.UpgradeNodeCommanded}
+		saved, err := m.SaveUpgradeNode(record)
+		if err != nil || !saved {
+			<-m.commanding
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		need--
+		go m.command(rollout, manifest, n)
+	}
+
+	return nil
+}
+
+// command asks the node to upgrade to the release, the node exits after replacing its binary
+func (m *Manager) command(rollout *types.UpgradeRollout, manifest *types.ReleaseManifest, n *node.Node) {
+	defer func() { <-m.commanding }()
+
+	cmd := &types.UpgradeCommand{RolloutID: rollout.ID, Manifest: manifest, Expiration: time.Now().Add(commandExpiration).Unix()}
+	sign, err := m.keyRing.Sign(cmd.SignData())
+	if err == nil {
+		cmd.Sign = sign
+
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		err = n.API.Upgrade(ctx, cmd)
+		cancel()
+	}
+
+	if err != nil {
+		message := err.Error()
+		if len(message) > 512 {
+			message = message[:512]
+		}
+
+		log.Warnf("upgrade %s to %s err:%s", n.NodeID, rollout.Version, message)
+		if err := m.UpdateUpgradeNodeStatus(rollout.ID, n.NodeID, types.UpgradeNodeFailed, message); err != nil {
+			log.Errorf("UpdateUpgradeNodeStatus err:%s", err.Error())
+		}
+	}
+}
+
+// waveTarget returns the number of the nodes upgraded by the wave of the percentage
+func waveTarget(percentage, nodes int) int {
+	return int(math.Ceil(float64(percentage) * float64(nodes) / 100))
+}
+
+// checkWaves checks the cumulative percentages of the waves ascend to 100
+func checkWaves(waves []int) error {
+	if len(waves) == 0 || waves[len(waves)-1] != 100 {
+		return xerrors.New("the last wave must be 100 percent")
+	}
+
+	last := 0
+	for _, w := range waves {
+		if w <= last || w > 100 {
+			return xerrors.Errorf("the percentages of the waves must ascend within 100, got %v", waves)
+		}
+		last = w
+	}
+
+	return nil
+}
+
+func parseWaves(s string) ([]int, error) {
+	var waves []int
+	for _, w := range strings.Split(s, ",") {
+		percentage, err := strconv.Atoi(strings.TrimSpace(w))
+		if err != nil {
+			return nil, xerrors.Errorf("invalid waves %s", s)
+		}
+		waves = append(waves, percentage)
+	}
+
+	return waves, checkWaves(waves)
+}
+
+// parseVersion parses the release version <major>.<minor>.<patch>
+func parseVersion(version string) ([3]int, error) {
+	var out [3]int
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return out, xerrors.Errorf("invalid version %s", version)
+	}
+
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return out, xerrors.Errorf("invalid version %s", version)
+		}
+		out[i] = v
+	}
+
+	return out, nil
+}
+
+// compareVersions compares the release versions, an invalid version is older than any valid version
+func compareVersions(a, b string) int {
+	va, errA := parseVersion(a)
+	vb, errB := parseVersion(b)
+
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
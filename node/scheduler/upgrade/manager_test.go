@@ -0,0 +1,50 @@
+package upgrade
+
+import "testing"
+
+func TestParseWaves(t *testing.T) {
+	waves, err := parseWaves("10, 50,100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(waves) != 3 || waves[0] != 10 || waves[1] != 50 || waves[2] != 100 {
+		t.Fatalf("unexpected waves %v", waves)
+	}
+
+	for _, s := range []string{"", "10,50", "50,10,100", "10,10,100", "10,a,100", "10,150"} {
+		if _, err := parseWaves(s); err == nil {
+			t.Errorf("waves %q should be invalid", s)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"0.1.16", "0.1.17", -1},
+		{"0.1.17", "0.1.17", 0},
+		{"0.2.0", "0.1.17", 1},
+		{"1.0.0", "0.10.10", 1},
+		{"", "0.1.17", -1},
+		{"dev", "", 0},
+	}
+
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestWaveTarget(t *testing.T) {
+	if n := waveTarget(10, 25); n != 3 {
+		t.Errorf("10%% of 25 nodes is %d, want 3", n)
+	}
+
+	if n := waveTarget(100, 25); n != 25 {
+		t.Errorf("100%% of 25 nodes is %d, want 25", n)
+	}
+}
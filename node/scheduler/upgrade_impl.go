@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// AddReleaseManifest saves the release manifest signed by the release key, a manifest of the same version and node type is replaced
+func (s *Scheduler) AddReleaseManifest(ctx context.Context, manifest *types.ReleaseManifest) error {
+	return s.UpgradeManager.AddReleaseManifest(manifest)
+}
+
+// ListReleaseManifests retrieves the release manifests of the node type, the latest first
+func (s *Scheduler) ListReleaseManifests(ctx context.Context, nodeType types.NodeType) ([]*types.ReleaseManifest, error) {
+	return s.UpgradeManager.LoadReleaseManifests(nodeType)
+}
+
+// StartUpgradeRollout starts rolling out the release to the canary nodes and then the waves of the nodes, returns the rollout id
+func (s *Scheduler) StartUpgradeRollout(ctx context.Context, req *types.UpgradeRolloutReq) (string, error) {
+	return s.UpgradeManager.StartRollout(req)
+}
+
+// GetUpgradeRollout retrieves the upgrade rollout
+func (s *Scheduler) GetUpgradeRollout(ctx context.Context, id string) (*types.UpgradeRollout, error) {
+	return s.UpgradeManager.GetRollout(id)
+}
+
+// GetUpgradeWaveStats retrieves the nodes of the waves of the rollout by status
+func (s *Scheduler) GetUpgradeWaveStats(ctx context.Context, id string) ([]*types.UpgradeWaveStats, error) {
+	return s.UpgradeManager.LoadUpgradeWaveStats(id)
+}
+
+// ListUpgradeRollouts retrieves the upgrade rollouts, the latest first
+func (s *Scheduler) ListUpgradeRollouts(ctx context.Context, limit, offset int) (*types.ListUpgradeRolloutRsp, error) {
+	return s.UpgradeManager.LoadUpgradeRollouts(limit, offset)
+}
+
+// ListUpgradeNodes retrieves the nodes commanded by the rollout, the latest first
+func (s *Scheduler) ListUpgradeNodes(ctx context.Context, id string, limit, offset int) (*types.ListUpgradeNodeRsp, error) {
+	return s.UpgradeManager.LoadUpgradeNodes(id, limit, offset)
+}
+
+// HaltUpgradeRollout halts the running rollout
+func (s *Scheduler) HaltUpgradeRollout(ctx context.Context, id, reason string) error {
+	return s.UpgradeManager.HaltRollout(id, reason)
+}
+
+// ResumeUpgradeRollout resumes the halted rollout from its current wave
+func (s *Scheduler) ResumeUpgradeRollout(ctx context.Context, id string) error {
+	return s.UpgradeManager.ResumeRollout(id)
+}
+
+// GetVersionAdoption retrieves the number of the nodes of the type seen in the last day by release version
+func (s *Scheduler) GetVersionAdoption(ctx context.Context, nodeType types.NodeType) ([]*types.VersionAdoption, error) {
+	return s.UpgradeManager.VersionAdoption(nodeType)
+}
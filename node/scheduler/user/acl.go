@@ -0,0 +1,115 @@
+package user
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"golang.org/x/xerrors"
+)
+
+// the users and api keys an access control list can hold
+const maxACLEntries = 100
+
+// SetAssetACL makes the asset of the user private, only the owners and the users and api keys in the list can download it
+func (u *User) SetAssetACL(acl *types.AssetACL) error {
+	hash, err := u.ownedAssetHash(acl.CID)
+	if err != nil {
+		return err
+	}
+
+	users, err := checkACLEntries(acl.Users, false)
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	apiKeys, err := checkACLEntries(acl.APIKeys, true)
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	if len(users)+len(apiKeys) > maxACLEntries {
+		return &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: fmt.Sprintf("the acl can hold %d users and api keys", maxACLEntries)}
+	}
+
+	return u.SaveAssetACL(hash, u.ID, &types.AssetACL{Users: users, APIKeys: apiKeys})
+}
+
+// RemoveAssetACL makes the asset of the user public
+func (u *User) RemoveAssetACL(assetCID string) error {
+	hash, err := u.ownedAssetHash(assetCID)
+	if err != nil {
+		return err
+	}
+
+	return u.DeleteAssetACL(hash, u.ID)
+}
+
+// GetAssetACL returns the access control list of the asset of the user, nil if the asset is public
+func (u *User) GetAssetACL(assetCID string) (*types.AssetACL, error) {
+	hash, err := u.ownedAssetHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := u.LoadAssetACL(hash, u.ID, assetCID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return acl, err
+}
+
+// isPrivateAsset returns true if the user made the asset private
+func (u *User) isPrivateAsset(hash string) (bool, error) {
+	_, err := u.LoadAssetACL(hash, u.ID, "")
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (u *User) ownedAssetHash(assetCID string) (string, error) {
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return "", &api.ErrWeb{Code: terrors.CidToHashFiled.Int(), Message: err.Error()}
+	}
+
+	if _, err = u.GetAssetName(hash, u.ID); err == sql.ErrNoRows {
+		return "", &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("asset %s does not exist", assetCID)}
+	} else if err != nil {
+		return "", &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	return hash, nil
+}
+
+// checkACLEntries removes the duplicated entries, the api keys are <user id>/<key name>
+func checkACLEntries(entries []string, isAPIKey bool) ([]string, error) {
+	out := make([]string, 0, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.Contains(entry, ",") {
+			return nil, xerrors.Errorf("invalid acl entry %q", entry)
+		}
+
+		if isAPIKey {
+			userID, keyName, ok := strings.Cut(entry, "/")
+			if !ok || userID == "" || keyName == "" {
+				return nil, xerrors.Errorf("invalid api key %q, expect <user id>/<key name>", entry)
+			}
+		}
+
+		if _, ok := seen[entry]; ok {
+			continue
+		}
+		seen[entry] = struct{}{}
+		out = append(out, entry)
+	}
+
+	return out, nil
+}
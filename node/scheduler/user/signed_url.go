@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+// the longest time a signed url is valid for
+const maxSignedURLLifetime = 7 * 24 * time.Hour
+
+// CreateSignedURL signs a url to share the asset of the user until the expiration, any node holding the asset serves it
+// without asking the scheduler. The signed urls are invalid once the scheduler key is rotated
+func (u *User) CreateSignedURL(ctx context.Context, assetCID string, expiration time.Time, schedulerAPI api.Scheduler, nodeManager *node.Manager, keyRing *keys.Ring) (*types.SignedURL, error) {
+	hash, err := u.ownedAssetHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	if private, err := u.isPrivateAsset(hash); err != nil {
+		return nil, err
+	} else if private {
+		return nil, &api.ErrWeb{Code: terrors.AssetAccessDenied.Int(), Message: fmt.Sprintf("asset %s is private and can not be shared by link", assetCID)}
+	}
+
+	now := time.Now()
+	if !expiration.After(now) || expiration.Sub(now) > maxSignedURLLifetime {
+		return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: fmt.Sprintf("the expiration must be within %s", maxSignedURLLifetime)}
+	}
+
+	assetName, err := u.GetAssetName(hash, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadInfos, err := schedulerAPI.GetCandidateDownloadInfos(ctx, assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(downloadInfos) == 0 {
+		return nil, fmt.Errorf("asset %s not exist", assetCID)
+	}
+
+	expires := expiration.Unix()
+	sign, err := keyRing.Sign(types.SignedURLContent(assetCID, expires))
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set(types.SignedURLExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(types.SignedURLSignatureParam, hex.EncodeToString(sign))
+	query.Set("filename", assetName)
+
+	signed := &types.SignedURL{
+		AssetCID:   assetCID,
+		Path:       fmt.Sprintf("/ipfs/%s?%s", assetCID, query.Encode()),
+		Expiration: time.Unix(expires, 0),
+	}
+
+	for _, info := range downloadInfos {
+		if len(signed.URLs) >= maxDownloadSources {
+			break
+		}
+
+		address := fmt.Sprintf("http://%s", info.Address)
+		if n := nodeManager.GetCandidateNode(info.NodeID); n != nil && len(n.ExternalURL) > 0 {
+			address = n.ExternalURL
+		}
+		signed.URLs = append(signed.URLs, address+signed.Path)
+	}
+
+	return signed, nil
+}
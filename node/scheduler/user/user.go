@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/terrors"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/assetcrypt"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
@@ -25,6 +28,9 @@ import (
 
 var log = logging.Logger("user")
 
+// maximum number of urls returned to download an asset from multiple sources
+const maxDownloadSources = 5
+
 type User struct {
 	*db.SQLDB
 	ID string
@@ -164,6 +170,33 @@ func (u *User) DeleteAPIKey(ctx context.Context, name string) error {
 	return u.SaveUserAPIKeys(u.ID, buf)
 }
 
+// RegenerateAPIKeys regenerates the values of all api keys of the user, the names and permissions are kept
+func (u *User) RegenerateAPIKeys(ctx context.Context, commonAPI api.Common) error {
+	apiKeys, err := u.GetAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	for name, key := range apiKeys {
+		perms, err := apiKeyPerms(key.APIKey)
+		if err != nil {
+			return xerrors.Errorf("api key %s: %w", name, err)
+		}
+
+		keyValue, err := generateAPIKey(u.ID, name, perms, commonAPI)
+		if err != nil {
+			return err
+		}
+		apiKeys[name] = types.UserAPIKeysInfo{CreatedTime: time.Now(), APIKey: keyValue}
+	}
+
+	buf, err := u.encodeAPIKeys(apiKeys)
+	if err != nil {
+		return err
+	}
+	return u.SaveUserAPIKeys(u.ID, buf)
+}
+
 // CreateAsset creates an asset with car CID, car name, and car size.
 func (u *User) CreateAsset(ctx context.Context, req *types.CreateAssetReq) (*types.CreateAssetRsp, error) {
 	hash, err := cidutil.CIDToHash(req.AssetCID)
@@ -171,16 +204,49 @@ func (u *User) CreateAsset(ctx context.Context, req *types.CreateAssetReq) (*typ
 		return nil, &api.ErrWeb{Code: terrors.CidToHashFiled.Int(), Message: err.Error()}
 	}
 
+	if req.Encryption != nil {
+		if err := assetcrypt.Validate(req.Encryption); err != nil {
+			return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+		}
+	}
+
+	if err := u.checkStorageSize(req.AssetSize); err != nil {
+		return nil, err
+	}
+
+	return u.Manager.CreateAssetUploadTask(hash, req)
+}
+
+// CreateIngestTask creates a task to ingest the asset of the user through a candidate.
+func (u *User) CreateIngestTask(ctx context.Context, req *types.IngestAssetReq) (*types.CreateAssetRsp, error) {
+	if err := u.checkStorageSize(req.AssetSize); err != nil {
+		return nil, err
+	}
+
+	return u.Manager.CreateAssetIngestTask(req)
+}
+
+// CreateIngestedAsset records the asset ingested by the candidate and starts the replication.
+func (u *User) CreateIngestedAsset(ctx context.Context, req *types.CreateAssetReq) error {
+	if err := u.checkStorageSize(req.AssetSize); err != nil {
+		return err
+	}
+
+	return u.Manager.CreateIngestedAssetTask(req)
+}
+
+// checkStorageSize checks whether the remaining storage of the user is enough for the asset.
+func (u *User) checkStorageSize(assetSize int64) error {
 	storageSize, err := u.GetInfo()
 	if err != nil {
-		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+		return &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
 	}
 
-	if storageSize.TotalSize-storageSize.UsedSize < req.AssetSize {
-		return nil, &api.ErrWeb{Code: terrors.UserStorageSizeNotEnough.Int(), Message: terrors.UserStorageSizeNotEnough.String()}
+	if storageSize.TotalSize-storageSize.UsedSize < assetSize {
+		return &api.ErrWeb{Code: terrors.UserStorageSizeNotEnough.Int(), Message: terrors.UserStorageSizeNotEnough.String()}
 	}
 
-	return u.Manager.CreateAssetUploadTask(hash, req)
+	return nil
 }
 
 // ListAssets lists the assets of the user.
@@ -249,56 +315,130 @@ func (u *User) DeleteAsset(ctx context.Context, cid string) error {
 		return err
 	}
 
-	users, err := u.ListUsersForAsset(hash)
+	last, err := u.ReleaseAssetOwner(hash, u.ID)
 	if err != nil {
 		return err
 	}
 
-	if len(users) == 1 && users[0] == u.ID {
+	// the replicas are removed with the last owner of the asset
+	if last {
 		return u.Manager.RemoveAsset(hash, true)
 	}
 
-	return u.DeleteAssetUser(hash, u.ID)
+	return nil
 }
 
 // ShareAssets shares the assets of the user.
 func (u *User) ShareAssets(ctx context.Context, assetCIDs []string, schedulerAPI api.Scheduler, nodeManager *node.Manager) (map[string]string, error) {
 	urls := make(map[string]string)
 	for _, assetCID := range assetCIDs {
-		downloadInfos, err := schedulerAPI.GetCandidateDownloadInfos(context.Background(), assetCID)
+		hash, err := cidutil.CIDToHash(assetCID)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(downloadInfos) == 0 {
-			return nil, fmt.Errorf("asset %s not exist", assetCID)
+		// a share link can be downloaded by anyone, the private assets are shared through the acl
+		if private, err := u.isPrivateAsset(hash); err != nil {
+			return nil, err
+		} else if private {
+			return nil, &api.ErrWeb{Code: terrors.AssetAccessDenied.Int(), Message: fmt.Sprintf("asset %s is private and can not be shared by link", assetCID)}
 		}
 
-		tk, err := generateAccessToken(&types.AuthUserUploadDownloadAsset{UserID: u.ID, AssetCID: assetCID}, schedulerAPI.(api.Common))
+		downloadInfos, err := schedulerAPI.GetCandidateDownloadInfos(ctx, assetCID)
 		if err != nil {
 			return nil, err
 		}
 
-		hash, err := cidutil.CIDToHash(assetCID)
+		if len(downloadInfos) == 0 {
+			return nil, fmt.Errorf("asset %s not exist", assetCID)
+		}
+
+		tk, err := generateAccessToken(&types.AuthUserUploadDownloadAsset{UserID: u.ID, AssetCID: assetCID}, schedulerAPI.(api.Common))
 		if err != nil {
 			return nil, err
 		}
+
 		assetName, err := u.GetAssetName(hash, u.ID)
 		if err != nil {
 			return nil, err
 		}
 
-		nodeID := downloadInfos[0].NodeID
-		node := nodeManager.GetCandidateNode(nodeID)
+		urls[assetCID] = downloadURL(nodeManager, downloadInfos[0], assetCID, tk, assetName)
+	}
 
-		url := fmt.Sprintf("http://%s/ipfs/%s?token=%s&filename=%s", downloadInfos[0].Address, assetCID, tk, assetName)
-		if node != nil && len(node.ExternalURL) > 0 {
-			url = fmt.Sprintf("%s/ipfs/%s?token=%s&filename=%s", node.ExternalURL, assetCID, tk, assetName)
+	return urls, nil
+}
+
+// GetDownloadSources returns the urls of the candidates holding the asset, all the urls share the same token,
+// so the client can resume the download from another candidate with the Range and If-Range headers
+func (u *User) GetDownloadSources(ctx context.Context, assetCID string, schedulerAPI api.Scheduler, nodeManager *node.Manager) (*types.DownloadSources, error) {
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	assetName, err := u.GetAssetName(hash, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadInfos, err := schedulerAPI.GetCandidateDownloadInfos(ctx, assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(downloadInfos) == 0 {
+		return nil, fmt.Errorf("asset %s not exist", assetCID)
+	}
+
+	tk, err := generateAccessToken(&types.AuthUserUploadDownloadAsset{UserID: u.ID, AssetCID: assetCID}, schedulerAPI.(api.Common))
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := u.GetAssetEncryption(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the content of the file is addressed by the cid, it is byte-identical on every node
+	sources := &types.DownloadSources{AssetCID: assetCID, AssetName: assetName, ETag: fmt.Sprintf("%q", assetCID), Encryption: encryption}
+	for _, info := range downloadInfos {
+		if len(sources.URLs) >= maxDownloadSources {
+			break
 		}
-		urls[assetCID] = url
+		sources.URLs = append(sources.URLs, downloadURL(nodeManager, info, assetCID, tk, assetName))
 	}
 
-	return urls, nil
+	return sources, nil
+}
+
+// GetAssetEncryption returns the metadata to decrypt the asset of the user, nil if the asset is not encrypted
+func (u *User) GetAssetEncryption(assetCID string) (*types.AssetEncryption, error) {
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := u.LoadAssetEncryption(hash, u.ID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// downloadURL returns the url to download the asset from the candidate, the external url of the candidate is preferred
+func downloadURL(nodeManager *node.Manager, info *types.CandidateDownloadInfo, assetCID, tk, assetName string) string {
+	node := nodeManager.GetCandidateNode(info.NodeID)
+	if node != nil && len(node.ExternalURL) > 0 {
+		return fmt.Sprintf("%s/ipfs/%s?token=%s&filename=%s", node.ExternalURL, assetCID, tk, assetName)
+	}
+
+	return fmt.Sprintf("http://%s/ipfs/%s?token=%s&filename=%s", info.Address, assetCID, tk, assetName)
 }
 
 // GetAssetStatus retrieves a asset status
@@ -377,6 +517,27 @@ func generateAPIKey(userID string, keyName string, perms []types.UserAccessContr
 	return tk, nil
 }
 
+// apiKeyPerms returns the access control list of the api key, the key is loaded from
+// the database so its payload is decoded without verifying
+func apiKeyPerms(apiKey string) ([]types.UserAccessControl, error) {
+	parts := strings.Split(apiKey, ".")
+	if len(parts) != 3 {
+		return nil, xerrors.New("invalid api key")
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &types.JWTPayload{}
+	if err := json.Unmarshal(buf, payload); err != nil {
+		return nil, err
+	}
+
+	return payload.AccessControlList, nil
+}
+
 func generateAccessToken(auth *types.AuthUserUploadDownloadAsset, commonAPI api.Common) (string, error) {
 	buf, err := json.Marshal(auth)
 	if err != nil {
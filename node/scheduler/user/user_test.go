@@ -134,3 +134,41 @@ func TestGetAssetName(t *testing.T) {
 
 	t.Logf("name %s", name)
 }
+
+func TestAPIKeyPerms(t *testing.T) {
+	perms := []types.UserAccessControl{types.UserAPIKeyReadFile}
+	apiKey, err := generateAPIKey("123", "test_key", perms, &common.CommonAPI{APISecret: jwt.NewHS256([]byte("abc_123"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := apiKeyPerms(apiKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded) != 1 || decoded[0] != types.UserAPIKeyReadFile {
+		t.Fatalf("unexpected perms %v", decoded)
+	}
+}
+
+func TestCheckACLEntries(t *testing.T) {
+	keys, err := checkACLEntries([]string{"u1/k1", " u1/k1 ", "u2/k2"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 2 || keys[0] != "u1/k1" || keys[1] != "u2/k2" {
+		t.Fatalf("unexpected api keys %v", keys)
+	}
+
+	for _, entry := range []string{"u1", "/k1", "u1/", "u1/k1,u2/k2"} {
+		if _, err := checkACLEntries([]string{entry}, true); err == nil {
+			t.Fatalf("expected api key %q to be refused", entry)
+		}
+	}
+
+	if _, err := checkACLEntries([]string{""}, false); err == nil {
+		t.Fatal("expected the empty user to be refused")
+	}
+}
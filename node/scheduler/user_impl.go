@@ -42,6 +42,11 @@ func (s *Scheduler) UserAPIKeysExists(ctx context.Context, userID string) error
 
 // AllocateStorage allocates storage space.
 func (s *Scheduler) AllocateStorage(ctx context.Context, userID string) (*types.UserInfo, error) {
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	u := s.newUser(userID)
 
 	info, err := u.AllocateStorage(ctx, s.SchedulerCfg.UserFreeStorageSize)
@@ -54,9 +59,9 @@ func (s *Scheduler) AllocateStorage(ctx context.Context, userID string) (*types.
 
 // GetUserInfo get user info
 func (s *Scheduler) GetUserInfo(ctx context.Context, userID string) (*types.UserInfo, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	return s.loadUserInfo(userID)
@@ -84,13 +89,13 @@ func (s *Scheduler) loadUserInfo(userID string) (*types.UserInfo, error) {
 
 // CreateAPIKey creates a key for the client API.
 func (s *Scheduler) CreateAPIKey(ctx context.Context, userID, keyName string, perms []types.UserAccessControl) (string, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return "", err
 	}
 
 	u := s.newUser(userID)
-	info, err := u.CreateAPIKey(ctx, keyName, perms, s.SchedulerCfg, s.CommonAPI)
+	info, err := u.CreateAPIKey(ctx, keyName, perms, s.SchedulerCfg, s)
 	if err != nil {
 		return "", err
 	}
@@ -100,9 +105,9 @@ func (s *Scheduler) CreateAPIKey(ctx context.Context, userID, keyName string, pe
 
 // GetAPIKeys get all api key for user.
 func (s *Scheduler) GetAPIKeys(ctx context.Context, userID string) (map[string]types.UserAPIKeysInfo, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	u := s.newUser(userID)
@@ -115,9 +120,9 @@ func (s *Scheduler) GetAPIKeys(ctx context.Context, userID string) (map[string]t
 }
 
 func (s *Scheduler) DeleteAPIKey(ctx context.Context, userID, name string) error {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return err
 	}
 
 	u := s.newUser(userID)
@@ -151,6 +156,13 @@ func (s *Scheduler) UserAssetDownloadResult(ctx context.Context, userID, cid str
 		return err
 	}
 
+	tenant, err := s.TenantManager.TenantOfUser(userID)
+	if err != nil {
+		log.Errorf("UserAssetDownloadResult TenantOfUser %s err:%s", userID, err.Error())
+	} else if tenant != nil {
+		s.TenantManager.AddTraffic(tenant.ID, totalTraffic)
+	}
+
 	return s.db.UpdateUserPeakSize(userID, peakBandwidth)
 }
 
@@ -168,12 +180,12 @@ func (s *Scheduler) SetUserVIP(ctx context.Context, userID string, enableVIP boo
 }
 
 func (s *Scheduler) GetUserAccessToken(ctx context.Context, userID string) (string, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return "", err
 	}
 
-	_, err := s.GetUserInfo(ctx, userID)
+	_, err = s.loadUserInfo(userID)
 	if err != nil {
 		return "", err
 	}
@@ -188,15 +200,19 @@ func (s *Scheduler) GetUserAccessToken(ctx context.Context, userID string) (stri
 
 // GetUserStorageStats get user storage info
 func (s *Scheduler) GetUserStorageStats(ctx context.Context, userID string) (*types.StorageStats, error) {
-	uID := handler.GetUserID(ctx)
-	if len(uID) > 0 {
-		userID = uID
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 	return s.db.LoadStorageStatsOfUser(userID)
 }
 
 // ListUserStorageStats list storage info
 func (s *Scheduler) ListUserStorageStats(ctx context.Context, limit, offset int) (*types.ListStorageStatsRsp, error) {
+	if err := s.OverloadManager.Admit(ctx, "ListUserStorageStats"); err != nil {
+		return nil, err
+	}
+
 	return s.db.ListStorageStatsOfUsers(limit, offset)
 }
 
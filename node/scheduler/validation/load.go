@@ -0,0 +1,309 @@
+package validation
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/docker/go-units"
+)
+
+// validatorLoad the validations of a validator
+type validatorLoad struct {
+	capacity int
+	assigned int
+	deferred int
+	total    int64
+}
+
+// resetLoads clears the validations of the validators in the previous round before the rounds of the zones start,
+// the validations still running are timed out by then
+func (m *Manager) resetLoads() {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	for _, load := range m.loads {
+		load.assigned = 0
+		load.deferred = 0
+	}
+	m.running = make(map[string]string)
+}
+
+// loadOf returns the load of the validator, the caller must hold loadLk
+func (m *Manager) loadOf(validatorID string) *validatorLoad {
+	load, ok := m.loads[validatorID]
+	if !ok {
+		load = &validatorLoad{capacity: 1}
+		m.loads[validatorID] = load
+	}
+
+	return load
+}
+
+// capacityOf returns the validations the validator runs at a time at most by its downstream bandwidth
+func (m *Manager) capacityOf(validatorID string) int {
+	cfg, err := m.config()
+	if err != nil || cfg.ValidatorTaskBwDn <= 0 {
+		return 1
+	}
+
+	node := m.nodeMgr.GetCandidateNode(validatorID)
+	if node == nil {
+		return 1
+	}
+
+	capacity := int(node.BandwidthDown / int64(cfg.ValidatorTaskBwDn*units.MiB))
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return capacity
+}
+
+// spreadSeconds returns the seconds the validations of a round are spread over, less than the interval of the rounds
+func (m *Manager) spreadSeconds() int {
+	spread := 20 * 60
+
+	cfg, err := m.config()
+	if err == nil && cfg.ValidationRoundSpread > 0 {
+		spread = cfg.ValidationRoundSpread * 60
+	}
+
+	if max := int(validationInterval/time.Second) - 2*duration; spread > max {
+		spread = max
+	}
+
+	return spread
+}
+
+// rotateValidators swaps the nodes paired with the validators that validated them last time
+func (m *Manager) rotateValidators(vrs []*VWindow) {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	rotate(vrs, m.lastValidators)
+}
+
+// schedule spreads the validations of the windows over the round by the capacities of the validators and returns
+// the delays of the nodes in seconds, the nodes not fitting in the round are removed from the windows
+func (m *Manager) schedule(vrs []*VWindow) map[string]int {
+	capacities := make(map[string]int)
+	for _, vr := range vrs {
+		if _, ok := capacities[vr.NodeID]; !ok {
+			capacities[vr.NodeID] = m.capacityOf(vr.NodeID)
+		}
+	}
+
+	delays, deferred := planDelays(vrs, capacities, m.spreadSeconds())
+
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	for validatorID, capacity := range capacities {
+		m.loadOf(validatorID).capacity = capacity
+	}
+
+	for validatorID, nodes := range deferred {
+		m.loadOf(validatorID).deferred += len(nodes)
+		log.Warnf("validator %s is full, %d validations deferred to the next rounds", validatorID, len(nodes))
+	}
+
+	return delays
+}
+
+// assign records the validations assigned to the validators
+func (m *Manager) assign(infos []*types.ValidationResultInfo) {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	for _, info := range infos {
+		load := m.loadOf(info.ValidatorID)
+		load.assigned++
+		load.total++
+
+		m.lastValidators[info.NodeID] = info.ValidatorID
+	}
+}
+
+// pickValidator returns the validator of the node with the most capacity left, the validator that validated
+// the node last time is picked only if it is the only one
+func (m *Manager) pickValidator(nodeID string, validators []string) string {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	running := make(map[string]int)
+	for _, validatorID := range m.running {
+		running[validatorID]++
+	}
+
+	rand.Shuffle(len(validators), func(i, j int) {
+		validators[i], validators[j] = validators[j], validators[i]
+	})
+
+	last := m.lastValidators[nodeID]
+	best, bestLeft := "", 0
+	for _, validatorID := range validators {
+		if validatorID == last && len(validators) > 1 {
+			continue
+		}
+
+		left := m.loadOf(validatorID).capacity - running[validatorID]
+		if best == "" || left > bestLeft {
+			best, bestLeft = validatorID, left
+		}
+	}
+
+	return best
+}
+
+// validationStarted records the validation of the node the validator started
+func (m *Manager) validationStarted(nodeID, validatorID string) {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	m.running[nodeID] = validatorID
+}
+
+// validationEnded records the validation of the node ended
+func (m *Manager) validationEnded(nodeID string) {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	delete(m.running, nodeID)
+}
+
+// ValidatorLoads returns the validations of the validators assigned validations since the scheduler started
+func (m *Manager) ValidatorLoads() []*types.ValidatorLoad {
+	m.loadLk.Lock()
+	defer m.loadLk.Unlock()
+
+	running := make(map[string]int)
+	for _, validatorID := range m.running {
+		running[validatorID]++
+	}
+
+	out := make([]*types.ValidatorLoad, 0, len(m.loads))
+	for validatorID, load := range m.loads {
+		out = append(out, &types.ValidatorLoad{
+			NodeID:   validatorID,
+			Capacity: load.capacity,
+			Running:  running[validatorID],
+			Assigned: load.assigned,
+			Deferred: load.deferred,
+			Total:    load.total,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].NodeID < out[j].NodeID
+	})
+
+	return out
+}
+
+// planDelays spreads the validations of each validator over the spread seconds in batches of its capacity, the validations
+// of a batch run at the same time and the batches are duration seconds apart at least. The nodes not fitting in the spread
+// are removed from the windows and returned by validator
+func planDelays(vrs []*VWindow, capacities map[string]int, spread int) (map[string]int, map[string][]string) {
+	nodes := make(map[string][]string)
+	windows := make(map[string]*VWindow)
+	for _, vr := range vrs {
+		for nodeID := range vr.ValidatableNodes {
+			nodes[vr.NodeID] = append(nodes[vr.NodeID], nodeID)
+			windows[nodeID] = vr
+		}
+	}
+
+	maxBatches := spread / duration
+	if maxBatches < 1 {
+		maxBatches = 1
+	}
+
+	delays := make(map[string]int)
+	deferred := make(map[string][]string)
+	for validatorID, list := range nodes {
+		capacity := capacities[validatorID]
+		if capacity < 1 {
+			capacity = 1
+		}
+
+		// the nodes deferred differ from round to round
+		rand.Shuffle(len(list), func(i, j int) {
+			list[i], list[j] = list[j], list[i]
+		})
+
+		batches := (len(list) + capacity - 1) / capacity
+		if batches > maxBatches {
+			for _, nodeID := range list[maxBatches*capacity:] {
+				delete(windows[nodeID].ValidatableNodes, nodeID)
+			}
+
+			deferred[validatorID] = list[maxBatches*capacity:]
+			list = list[:maxBatches*capacity]
+			batches = maxBatches
+		}
+
+		step := spread / batches
+		for i, nodeID := range list {
+			delays[nodeID] = (i / capacity) * step
+		}
+	}
+
+	return delays, deferred
+}
+
+// rotate swaps the nodes paired with the validators that validated them last time with the nodes of the windows of the
+// other validators, so that a node is not validated by the same validator in a row while another validator is available
+func rotate(vrs []*VWindow, last map[string]string) {
+	if len(vrs) < 2 {
+		return
+	}
+
+	for i, vr := range vrs {
+		var repeated []string
+		for nodeID := range vr.ValidatableNodes {
+			if last[nodeID] == vr.NodeID {
+				repeated = append(repeated, nodeID)
+			}
+		}
+
+		for _, nodeID := range repeated {
+			// start from a random window to spread the swaps over the validators
+			start := rand.Intn(len(vrs))
+			for k := 0; k < len(vrs); k++ {
+				other := vrs[(start+k)%len(vrs)]
+				if (start+k)%len(vrs) == i || other.NodeID == vr.NodeID {
+					continue
+				}
+
+				if swapped := swap(vr, other, nodeID, last); swapped {
+					break
+				}
+			}
+		}
+	}
+}
+
+// swap swaps the node of the window with a node of the other window not validated by the validator of the window last time
+func swap(vr, other *VWindow, nodeID string, last map[string]string) bool {
+	if last[nodeID] == other.NodeID {
+		return false
+	}
+
+	for otherID, bwUp := range other.ValidatableNodes {
+		if last[otherID] == vr.NodeID {
+			continue
+		}
+
+		other.ValidatableNodes[nodeID] = vr.ValidatableNodes[nodeID]
+		delete(other.ValidatableNodes, otherID)
+
+		vr.ValidatableNodes[otherID] = bwUp
+		delete(vr.ValidatableNodes, nodeID)
+
+		return true
+	}
+
+	return false
+}
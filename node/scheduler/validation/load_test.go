@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+)
+
+func windowOf(validatorID string, nodeIDs ...string) *VWindow {
+	vr := newVWindow(validatorID)
+	for _, nodeID := range nodeIDs {
+		vr.ValidatableNodes[nodeID] = 1
+	}
+	return vr
+}
+
+func TestPlanDelays(t *testing.T) {
+	var nodes []string
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, fmt.Sprintf("e_%d", i))
+	}
+
+	vr := windowOf("c_1", nodes...)
+	// 2 validations at a time in 3 batches at most
+	delays, deferred := planDelays([]*VWindow{vr}, map[string]int{"c_1": 2}, 3*duration)
+
+	if len(deferred["c_1"]) != 4 || len(vr.ValidatableNodes) != 6 || len(delays) != 6 {
+		t.Fatalf("expected 4 deferred and 6 scheduled, got %d deferred, %d in window, %d delays", len(deferred["c_1"]), len(vr.ValidatableNodes), len(delays))
+	}
+
+	batches := make(map[int]int)
+	for _, delay := range delays {
+		batches[delay]++
+	}
+	for delay, count := range batches {
+		if count > 2 {
+			t.Fatalf("%d validations at delay %d exceed the capacity", count, delay)
+		}
+	}
+	if len(batches) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(batches))
+	}
+
+	// the batches are spread over the spread seconds
+	delays, _ = planDelays([]*VWindow{windowOf("c_1", "e_1", "e_2")}, map[string]int{"c_1": 1}, 100*duration)
+	if delays["e_1"]+delays["e_2"] != 50*duration {
+		t.Fatalf("expected the batches 50 durations apart, got %v", delays)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	vrs := []*VWindow{windowOf("c_1", "e_1", "e_2"), windowOf("c_2", "e_3", "e_4")}
+	last := map[string]string{"e_1": "c_1", "e_3": "c_2"}
+
+	rotate(vrs, last)
+
+	for _, vr := range vrs {
+		if len(vr.ValidatableNodes) != 2 {
+			t.Fatalf("window of %s has %d nodes, expected 2", vr.NodeID, len(vr.ValidatableNodes))
+		}
+
+		for nodeID := range vr.ValidatableNodes {
+			if last[nodeID] == vr.NodeID {
+				t.Fatalf("node %s paired with %s again", nodeID, vr.NodeID)
+			}
+		}
+	}
+
+	// a single validator can not rotate
+	vrs = []*VWindow{windowOf("c_1", "e_1")}
+	rotate(vrs, last)
+	if _, ok := vrs[0].ValidatableNodes["e_1"]; !ok {
+		t.Fatal("node of the single validator removed")
+	}
+}
@@ -11,9 +11,13 @@ import (
 	"github.com/Filecoin-Titan/titan/lotuscli"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/decision"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/eventbus"
+	"github.com/Filecoin-Titan/titan/node/scheduler/keys"
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
-	"github.com/filecoin-project/pubsub"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
 	logging "github.com/ipfs/go-log/v2"
 	"golang.org/x/xerrors"
 )
@@ -54,11 +58,18 @@ func newValidatableGroup() *ValidatableGroup {
 	}
 }
 
+// validationReport the validation result reported by a validator with the report signed by the validator
+type validationReport struct {
+	result *api.ValidationResult
+	report []byte
+	sign   []byte
+}
+
 // Manager validation manager
 type Manager struct {
 	nodeMgr  *node.Manager
 	assetMgr *assets.Manager
-	notify   *pubsub.PubSub
+	notify   *eventbus.Bus
 
 	// Each validator provides n window(VWindow) for titan according to the bandwidth down, and each window corresponds to a group(ValidatableGroup).
 	// All nodes will randomly fall into a group(ValidatableGroup).
@@ -68,10 +79,13 @@ type Manager struct {
 	validatableGroups  []*ValidatableGroup // Each VWindow has a ValidatableGroup
 	unpairedGroup      *ValidatableGroup   // Save unpaired Validatable nodes
 
-	seed       int64
-	curRoundID string
-	close      chan struct{}
-	config     dtypes.GetSchedulerConfigFunc
+	seed   int64
+	close  chan struct{}
+	config dtypes.GetSchedulerConfigFunc
+
+	// the rounds of the validated nodes, each zone validates its nodes in its own round
+	roundLk    sync.RWMutex
+	nodeRounds map[string]string
 
 	updateCh chan struct{}
 
@@ -80,9 +94,20 @@ type Manager struct {
 	profit float64
 
 	// validation result worker
-	resultQueue chan *api.ValidationResult
+	resultQueue chan *validationReport
 
 	leadershipMgr *leadership.Manager
+	decisionMgr   *decision.Manager
+	penaltyMgr    *penalty.Manager
+	keyRing       *keys.Ring
+
+	loadLk sync.Mutex
+	// validations of the validators by node id
+	loads map[string]*validatorLoad
+	// validators of the running validations by the node id validated
+	running map[string]string
+	// validators that validated the nodes last time by node id
+	lastValidators map[string]string
 
 	lck             sync.Mutex
 	isCacheValid    bool // use cache to reduce 'ChainHead' calls
@@ -91,19 +116,33 @@ type Manager struct {
 }
 
 // NewManager return new node manager instance
-func NewManager(nodeMgr *node.Manager, assetMgr *assets.Manager, configFunc dtypes.GetSchedulerConfigFunc, p *pubsub.PubSub, lmgr *leadership.Manager) *Manager {
+func NewManager(nodeMgr *node.Manager, assetMgr *assets.Manager, configFunc dtypes.GetSchedulerConfigFunc, p *eventbus.Bus, lmgr *leadership.Manager, dmgr *decision.Manager, pmgr *penalty.Manager, keyRing *keys.Ring) *Manager {
 	manager := &Manager{
 		nodeMgr:       nodeMgr,
 		assetMgr:      assetMgr,
 		config:        configFunc,
 		close:         make(chan struct{}),
 		unpairedGroup: newValidatableGroup(),
+		nodeRounds:    make(map[string]string),
 		updateCh:      make(chan struct{}, 1),
 		notify:        p,
-		resultQueue:   make(chan *api.ValidationResult),
+		resultQueue:   make(chan *validationReport),
 		leadershipMgr: lmgr,
+		decisionMgr:   dmgr,
+		penaltyMgr:    pmgr,
+		keyRing:       keyRing,
+
+		loads:          make(map[string]*validatorLoad),
+		running:        make(map[string]string),
+		lastValidators: make(map[string]string),
 	}
 
+	diagnostics.RegisterSize("validation.last_validators", func() int {
+		manager.loadLk.Lock()
+		defer manager.loadLk.Unlock()
+		return len(manager.lastValidators)
+	})
+
 	return manager
 }
 
@@ -123,8 +162,8 @@ func (m *Manager) Stop(ctx context.Context) error {
 }
 
 func (m *Manager) subscribeNodeEvents() {
-	subOnline := m.notify.Sub(types.EventNodeOnline.String())
-	subOffline := m.notify.Sub(types.EventNodeOffline.String())
+	subOnline := m.notify.Sub("validation", types.EventNodeOnline.String(), eventbus.DefaultQueueSize, eventbus.DropOldest)
+	subOffline := m.notify.Sub("validation", types.EventNodeOffline.String(), eventbus.DefaultQueueSize, eventbus.DropOldest)
 
 	go func() {
 		defer m.notify.Unsub(subOnline)
@@ -132,10 +171,16 @@ func (m *Manager) subscribeNodeEvents() {
 
 		for {
 			select {
-			case u := <-subOnline:
+			case u, ok := <-subOnline:
+				if !ok {
+					return
+				}
 				node := u.(*node.Node)
 				m.onNodeStateChange(node, true)
-			case u := <-subOffline:
+			case u, ok := <-subOffline:
+				if !ok {
+					return
+				}
 				node := u.(*node.Node)
 				m.onNodeStateChange(node, false)
 			}
@@ -187,6 +232,11 @@ func (m *Manager) onNodeStateChange(node *node.Node, isOnline bool) {
 	}
 }
 
+// ActiveValidations returns the validations not finished the node is validated or validates in
+func (m *Manager) ActiveValidations(nodeID string) ([]*types.ValidationResultInfo, error) {
+	return m.nodeMgr.LoadActiveValidationsOfNode(nodeID)
+}
+
 // GetNextElectionTime Get the time of the next election
 func (m *Manager) GetNextElectionTime() time.Time {
 	return m.nextElectionTime
@@ -1,9 +1,12 @@
 package validation
 
 import (
+	"encoding/binary"
+	"hash/fnv"
 	"math"
 	"math/rand"
 
+	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/docker/go-units"
 )
 
@@ -104,13 +107,19 @@ func (b *ValidatableGroup) removeNode(nodeID string) {
 	b.sumBwUp -= bwUp
 }
 
-func (m *Manager) resetGroup() {
+// resetGroup pairs the validators of the zone with the edge nodes of the zone drawn for the round
+func (m *Manager) resetGroup(areaID string) {
 	m.validationPairLock.Lock()
 	defer m.validationPairLock.Unlock()
 
+	edges, candidates := m.nodeMgr.GetZoneNodes(areaID)
+	percents := m.tierPercents()
+
 	m.unpairedGroup = newValidatableGroup()
-	edges := m.nodeMgr.GetAllEdgeNode()
 	for _, node := range edges {
+		if !drawn(m.seed, node.NodeID, percents[m.penaltyMgr.Tier(node.NodeID)]) {
+			continue
+		}
 		m.unpairedGroup.addNode(node.NodeID, node.BandwidthUp)
 	}
 
@@ -118,7 +127,6 @@ func (m *Manager) resetGroup() {
 	m.validatableGroups = make([]*ValidatableGroup, 0)
 	m.vWindows = make([]*VWindow, 0)
 
-	_, candidates := m.nodeMgr.GetAllValidCandidateNodes()
 	for _, node := range candidates {
 		isValidator, err := m.nodeMgr.IsValidator(node.NodeID)
 		if err != nil || !isValidator {
@@ -141,6 +149,49 @@ func (m *Manager) resetGroup() {
 	}
 }
 
+// tierPercents returns the percents of the rounds the nodes of the trust tiers are validated in
+func (m *Manager) tierPercents() map[types.TrustTier]int {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return map[types.TrustTier]int{types.TrustTierProbation: 100, types.TrustTierStandard: 100, types.TrustTierTrusted: 100}
+	}
+
+	return map[types.TrustTier]int{
+		types.TrustTierProbation: cfg.ValidationPercentProbation,
+		types.TrustTierStandard:  cfg.ValidationPercentStandard,
+		types.TrustTierTrusted:   cfg.ValidationPercentTrusted,
+	}
+}
+
+// drawn reports whether the node is validated in the round of the seed, a node is drawn in about percent of the rounds
+func drawn(seed int64, nodeID string, percent int) bool {
+	if percent >= 100 {
+		return true
+	}
+	if percent <= 0 {
+		return false
+	}
+
+	h := fnv.New64a()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(seed))
+	h.Write(b[:])
+	h.Write([]byte(nodeID))
+
+	return h.Sum64()%100 < uint64(percent)
+}
+
+// drawnScale returns the times of the income of a round the node drawn in percent of the rounds earns,
+// so that the nodes earn the same income per round whatever percent of the rounds they are validated in
+func drawnScale(percent int) float64 {
+	if percent <= 0 || percent >= 100 {
+		return 1
+	}
+
+	return 100 / float64(percent)
+}
+
 // ResetValidatorGroup clears and initializes the validator and validatable groups
 func (m *Manager) ResetValidatorGroup(validators, validatables []string) {
 	m.validationPairLock.Lock()
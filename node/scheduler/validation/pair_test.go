@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestDrawnIncome(t *testing.T) {
+	const rounds = 2000
+
+	for _, percent := range []int{100, 50, 20} {
+		income := 0.0
+		for seed := int64(0); seed < rounds; seed++ {
+			if drawn(seed, fmt.Sprintf("e_%d", percent), percent) {
+				income += drawnScale(percent)
+			}
+		}
+
+		// the income per round is about the same as the one of a node validated in every round
+		if perRound := income / rounds; math.Abs(perRound-1) > 0.15 {
+			t.Fatalf("percent %d earns %.2f per round", percent, perRound)
+		}
+	}
+}
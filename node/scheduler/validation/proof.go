@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"encoding/hex"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// times to append a proof to the chain of a node appended by another worker at the same time
+	proofRetries = 3
+	// proofs checked in a query
+	proofChainPage = 500
+)
+
+// recordProof countersigns the validation result and appends it to the proof chain of the node,
+// report and sign are the result reported by the validator and its signature, empty if the validator reported nothing
+func (m *Manager) recordProof(info *types.ValidationResultInfo, validatorID string, report, sign []byte) {
+	var err error
+	for i := 0; i < proofRetries; i++ {
+		if err = m.appendProof(info, validatorID, report, sign); err == nil {
+			return
+		}
+	}
+
+	log.Errorf("record validation proof of node %s round %s err:%s", info.NodeID, info.RoundID, err.Error())
+}
+
+func (m *Manager) appendProof(info *types.ValidationResultInfo, validatorID string, report, sign []byte) error {
+	last, err := m.nodeMgr.LoadLastValidationProof(info.NodeID)
+	if err != nil {
+		return xerrors.Errorf("LoadLastValidationProof: %w", err)
+	}
+
+	proof := &types.ValidationProof{
+		NodeID:        info.NodeID,
+		RoundID:       info.RoundID,
+		ValidatorID:   validatorID,
+		Status:        info.Status,
+		BlockNumber:   info.BlockNumber,
+		Bandwidth:     info.Bandwidth,
+		Report:        report,
+		ValidatorSign: hex.EncodeToString(sign),
+		CreatedTime:   time.Now().Truncate(time.Second),
+	}
+	if last != nil {
+		proof.Seq = last.Seq + 1
+		proof.PrevHash = last.Hash
+	}
+
+	schedulerSign, err := m.keyRing.Sign(proof.SignContent())
+	if err != nil {
+		return xerrors.Errorf("sign: %w", err)
+	}
+	proof.Hash = proof.ContentHash()
+	proof.SchedulerSign = hex.EncodeToString(schedulerSign)
+
+	return m.nodeMgr.SaveValidationProof(proof)
+}
+
+// VerifyProofs checks the hashes, the links and the signatures of the proof chain of the node from the first record,
+// the scheduler signatures are checked with the current and the retired keys of the scheduler
+func (m *Manager) VerifyProofs(nodeID string) (*types.ValidationProofAudit, error) {
+	var schedulerKeys []*rsa.PublicKey
+	for _, key := range m.keyRing.Keys() {
+		schedulerKeys = append(schedulerKeys, key.Public().(*rsa.PublicKey))
+	}
+
+	validatorKeys := make(map[string]crypto.PublicKey)
+	validatorKey := func(validatorID string) (crypto.PublicKey, error) {
+		if key, ok := validatorKeys[validatorID]; ok {
+			return key, nil
+		}
+
+		pem, err := m.nodeMgr.LoadNodePublicKey(validatorID)
+		if err != nil {
+			return nil, xerrors.Errorf("load public key of validator %s: %w", validatorID, err)
+		}
+
+		key, err := nodekey.Pem2PublicKey([]byte(pem))
+		if err != nil {
+			return nil, err
+		}
+
+		validatorKeys[validatorID] = key
+		return key, nil
+	}
+
+	audit := &types.ValidationProofAudit{NodeID: nodeID, Valid: true}
+
+	var prev *types.ValidationProof
+	for {
+		afterSeq := int64(-1)
+		if prev != nil {
+			afterSeq = prev.Seq
+		}
+
+		proofs, err := m.nodeMgr.LoadValidationProofChain(nodeID, afterSeq, proofChainPage)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, proof := range proofs {
+			audit.Records++
+
+			if err := verifyProof(proof, prev, schedulerKeys, validatorKey); err != nil {
+				audit.Valid = false
+				audit.BrokenSeq = proof.Seq
+				audit.Reason = err.Error()
+				return audit, nil
+			}
+
+			prev = proof
+		}
+
+		if len(proofs) < proofChainPage {
+			return audit, nil
+		}
+	}
+}
+
+// verifyProof checks the proof follows the previous proof of the chain and its signatures are valid
+func verifyProof(proof, prev *types.ValidationProof, schedulerKeys []*rsa.PublicKey, validatorKey func(string) (crypto.PublicKey, error)) error {
+	if prev == nil {
+		if proof.Seq != 0 || proof.PrevHash != "" {
+			return xerrors.Errorf("first record has seq %d and previous hash %s", proof.Seq, proof.PrevHash)
+		}
+	} else if proof.Seq != prev.Seq+1 || proof.PrevHash != prev.Hash {
+		return xerrors.Errorf("record does not follow record %d", prev.Seq)
+	}
+
+	if proof.Hash != proof.ContentHash() {
+		return xerrors.New("hash mismatch")
+	}
+
+	schedulerSign, err := hex.DecodeString(proof.SchedulerSign)
+	if err != nil {
+		return xerrors.Errorf("decode scheduler signature: %w", err)
+	}
+
+	verifier := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	signed := false
+	for _, key := range schedulerKeys {
+		if verifier.VerifySign(key, schedulerSign, proof.SignContent()) == nil {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return xerrors.New("scheduler signature mismatch")
+	}
+
+	if len(proof.Report) == 0 {
+		return nil
+	}
+
+	validatorSign, err := hex.DecodeString(proof.ValidatorSign)
+	if err != nil {
+		return xerrors.Errorf("decode validator signature: %w", err)
+	}
+
+	key, err := validatorKey(proof.ValidatorID)
+	if err != nil {
+		return err
+	}
+
+	if err = nodekey.Verify(key, validatorSign, proof.Report); err != nil {
+		return xerrors.Errorf("validator signature mismatch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+)
+
+func TestVerifyProof(t *testing.T) {
+	schedulerKey, err := titanrsa.GeneratePrivateKey(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validatorPub, validatorKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	seal := func(proof *types.ValidationProof) {
+		sign, err := signer.Sign(schedulerKey, proof.SignContent())
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof.Hash = proof.ContentHash()
+		proof.SchedulerSign = hex.EncodeToString(sign)
+	}
+
+	report := []byte("report")
+	first := &types.ValidationProof{NodeID: "e_1", RoundID: "r_1", ValidatorID: "c_1", Status: types.ValidationStatusSuccess,
+		Report: report, ValidatorSign: hex.EncodeToString(ed25519.Sign(validatorKey, report)), CreatedTime: time.Now()}
+	seal(first)
+
+	second := &types.ValidationProof{NodeID: "e_1", Seq: 1, RoundID: "r_2", ValidatorID: "c_2", Status: types.ValidationStatusNodeTimeOut,
+		PrevHash: first.Hash, CreatedTime: time.Now()}
+	seal(second)
+
+	schedulerKeys := []*rsa.PublicKey{&schedulerKey.PublicKey}
+	validatorKeyOf := func(string) (crypto.PublicKey, error) { return validatorPub, nil }
+
+	if err := verifyProof(first, nil, schedulerKeys, validatorKeyOf); err != nil {
+		t.Fatalf("first record: %s", err.Error())
+	}
+	if err := verifyProof(second, first, schedulerKeys, validatorKeyOf); err != nil {
+		t.Fatalf("second record: %s", err.Error())
+	}
+
+	// a record modified after it was sealed
+	tampered := *second
+	tampered.Status = types.ValidationStatusSuccess
+	if err := verifyProof(&tampered, first, schedulerKeys, validatorKeyOf); err == nil {
+		t.Fatal("tampered record should be invalid")
+	}
+
+	// a record resealed with its hash but without the scheduler key
+	tampered.Hash = tampered.ContentHash()
+	if err := verifyProof(&tampered, first, schedulerKeys, validatorKeyOf); err == nil {
+		t.Fatal("record not signed by the scheduler should be invalid")
+	}
+
+	// a record removed from the chain
+	third := &types.ValidationProof{NodeID: "e_1", Seq: 2, RoundID: "r_3", PrevHash: second.Hash, CreatedTime: time.Now()}
+	seal(third)
+	if err := verifyProof(third, first, schedulerKeys, validatorKeyOf); err == nil {
+		t.Fatal("record not following the previous record should be invalid")
+	}
+
+	// a report not signed by the validator
+	forged := *first
+	forged.Report = []byte("forged")
+	seal(&forged)
+	if err := verifyProof(&forged, nil, schedulerKeys, validatorKeyOf); err == nil {
+		t.Fatal("report not signed by the validator should be invalid")
+	}
+}
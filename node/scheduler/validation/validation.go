@@ -8,8 +8,11 @@ import (
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tracing"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/xerrors"
 )
 
@@ -73,30 +76,57 @@ func (m *Manager) getValidationProfit() float64 {
 	return cfg.ValidationProfit
 }
 
-// startValidate is a method of the Manager that starts a new validation round.
+// startValidate is a method of the Manager that starts a new validation round in each zone.
 func (m *Manager) startValidate() error {
 	// Set the timeout status of the previous verification
 	m.updateTimeoutResultInfo()
 
-	delay := 0
-
-	roundID := uuid.NewString()
-	m.curRoundID = roundID
-
 	seed, err := m.getSeedFromFilecoin()
 	if err != nil {
-		log.Errorf("startNewRound:%s getSeedFromFilecoin err:%s", m.curRoundID, err.Error())
+		log.Errorf("startNewRound getSeedFromFilecoin err:%s", err.Error())
 	}
 	m.seed = seed
 
-	m.resetGroup()
+	m.resetRounds()
+	m.resetLoads()
+
+	started := 0
+	for _, areaID := range m.nodeMgr.Zones() {
+		if err := m.startZoneRound(areaID); err != nil {
+			log.Errorf("start round of zone %s: %s", areaID, err.Error())
+			continue
+		}
+		started++
+	}
+
+	if started == 0 {
+		return xerrors.New("no round started in the zones")
+	}
+
+	return nil
+}
+
+// startZoneRound starts a validation round in the zone, the validators of the zone validate the nodes of the zone
+func (m *Manager) startZoneRound(areaID string) (err error) {
+	roundID := uuid.NewString()
+
+	// the requests sent with delays are traced in the round
+	ctx, span := tracing.Start(context.Background(), "validation.round", attribute.String("round_id", roundID), attribute.String("area_id", areaID))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	m.resetGroup(areaID)
 
 	vrs := m.PairValidatorsAndValidatableNodes()
-	if vrs == nil {
+	if len(vrs) == 0 {
 		return xerrors.Errorf("PairValidatorsAndValidatableNodes err...")
 	}
 
-	vReqs, dbInfos := m.getValidationDetails(vrs)
+	m.rotateValidators(vrs)
+	delays := m.schedule(vrs)
+
+	vReqs, dbInfos := m.getValidationDetails(roundID, vrs)
 	if len(vReqs) == 0 {
 		return xerrors.New("validation pair fail")
 	}
@@ -106,43 +136,127 @@ func (m *Manager) startValidate() error {
 		return xerrors.Errorf("SaveValidationResultInfos err:%s", err.Error())
 	}
 
-	for nodeID, reqs := range vReqs {
-		delay += duration
-		if delay > 20*60 {
-			delay = 0
-		}
+	span.SetAttributes(attribute.Int("nodes", len(vReqs)))
+	m.setRound(roundID, vReqs)
+	m.assign(dbInfos)
 
-		go m.sendValidateReqToNode(nodeID, reqs, delay)
+	for _, info := range dbInfos {
+		go m.sendValidateReqToNode(ctx, roundID, info.NodeID, info.ValidatorID, vReqs[info.NodeID], delays[info.NodeID])
 	}
 
 	return nil
 }
 
+// ValidateNode validates the online node out of the rounds by a validator of its zone and returns the round id,
+// the result is saved and processed as the results of the rounds
+func (m *Manager) ValidateNode(nodeID string) (string, error) {
+	nd := m.nodeMgr.GetNode(nodeID)
+	if nd == nil {
+		return "", xerrors.Errorf("node %s is offline", nodeID)
+	}
+
+	if nd.InMaintenance() {
+		return "", xerrors.Errorf("node %s is in maintenance", nodeID)
+	}
+
+	_, candidates := m.nodeMgr.GetZoneNodes(nd.AreaID)
+	validators := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.NodeID == nodeID {
+			continue
+		}
+
+		if isValidator, err := m.nodeMgr.IsValidator(candidate.NodeID); err == nil && isValidator {
+			validators = append(validators, candidate.NodeID)
+		}
+	}
+
+	if len(validators) == 0 {
+		return "", xerrors.Errorf("no validator online in the zone %s", nd.AreaID)
+	}
+
+	vr := newVWindow(m.pickValidator(nodeID, validators))
+	vr.ValidatableNodes[nodeID] = nd.BandwidthUp
+
+	roundID := uuid.NewString()
+	vReqs, dbInfos := m.getValidationDetails(roundID, []*VWindow{vr})
+	req, ok := vReqs[nodeID]
+	if !ok {
+		return "", xerrors.Errorf("node %s has no asset to validate", nodeID)
+	}
+
+	if err := m.nodeMgr.SaveValidationResultInfos(dbInfos); err != nil {
+		return "", xerrors.Errorf("SaveValidationResultInfos err:%s", err.Error())
+	}
+
+	m.setRound(roundID, vReqs)
+	m.assign(dbInfos)
+	go m.sendValidateReqToNode(context.Background(), roundID, nodeID, vr.NodeID, req, 0)
+
+	return roundID, nil
+}
+
+// resetRounds clears the rounds of the validated nodes before the rounds of the zones start
+func (m *Manager) resetRounds() {
+	m.roundLk.Lock()
+	defer m.roundLk.Unlock()
+
+	m.nodeRounds = make(map[string]string)
+}
+
+// setRound records the round the nodes are validated in
+func (m *Manager) setRound(roundID string, reqs map[string]*api.ValidateReq) {
+	m.roundLk.Lock()
+	defer m.roundLk.Unlock()
+
+	for nodeID := range reqs {
+		m.nodeRounds[nodeID] = roundID
+	}
+}
+
+// roundOf returns the current round the node is validated in
+func (m *Manager) roundOf(nodeID string) string {
+	m.roundLk.RLock()
+	defer m.roundLk.RUnlock()
+
+	return m.nodeRounds[nodeID]
+}
+
 // sends a validation request to a node.
-func (m *Manager) sendValidateReqToNode(nID string, req *api.ValidateReq, delay int) {
+func (m *Manager) sendValidateReqToNode(ctx context.Context, roundID, nID, validatorID string, req *api.ValidateReq, delay int) {
 	time.Sleep(time.Duration(delay) * time.Second)
 	log.Infof("%d sendValidateReqToNodes v:[%s] n:[%s]", delay, req.TCPSrvAddr, nID)
 
+	ctx, span := tracing.Start(ctx, "validation.request", attribute.String("node_id", nID), attribute.String("validator", req.TCPSrvAddr))
+	defer span.End()
+
 	status := types.ValidationStatusNodeOffline
 
 	cNode := m.nodeMgr.GetNode(nID)
 	if cNode != nil {
-		err := cNode.ExecuteValidation(context.Background(), req)
+		m.validationStarted(nID, validatorID)
+
+		err := cNode.ExecuteValidation(ctx, req)
 		if err == nil {
 			return
 		}
+		m.validationEnded(nID)
+		span.RecordError(err)
 		log.Errorf("%s Validate err:%s", nID, err.Error())
 		status = types.ValidationStatusNodeTimeOut
 	}
 
-	err := m.nodeMgr.UpdateValidationResultStatus(m.curRoundID, nID, status)
+	err := m.nodeMgr.UpdateValidationResultStatus(roundID, nID, status)
 	if err != nil {
 		log.Errorf("%s UpdateValidationResultStatus err:%s", nID, err.Error())
+		return
 	}
+
+	m.recordProof(&types.ValidationResultInfo{RoundID: roundID, NodeID: nID, Status: status}, validatorID, nil, nil)
 }
 
 // get validation details.
-func (m *Manager) getValidationDetails(vrs []*VWindow) (map[string]*api.ValidateReq, []*types.ValidationResultInfo) {
+func (m *Manager) getValidationDetails(roundID string, vrs []*VWindow) (map[string]*api.ValidateReq, []*types.ValidationResultInfo) {
 	bReqs := make(map[string]*api.ValidateReq)
 	vrInfos := make([]*types.ValidationResultInfo, 0)
 
@@ -156,15 +270,24 @@ func (m *Manager) getValidationDetails(vrs []*VWindow) (map[string]*api.Validate
 			vTCPAddr = vNode.TCPAddr()
 		}
 
-		for nodeID := range vr.ValidatableNodes {
+		rec := m.decisionMgr.Begin(types.DecisionValidation, roundID, fmt.Sprintf("validator:%s,nodes:%d", vID, len(vr.ValidatableNodes)))
+
+		for nodeID, bandwidth := range vr.ValidatableNodes {
+			if m.nodeMgr.InMaintenance(nodeID, time.Now()) {
+				rec.Filter(nodeID, "maintenance", 0, float64(bandwidth))
+				continue
+			}
+
 			cid, err := m.assetMgr.RandomAsset(nodeID, m.seed)
 			if err != nil {
 				log.Errorf("%s RandomAsset err:%s", nodeID, err.Error())
+				rec.Filter(nodeID, "no_asset", 0, float64(bandwidth))
 				continue
 			}
+			rec.Choose(nodeID, 0, float64(bandwidth))
 
 			dbInfo := &types.ValidationResultInfo{
-				RoundID:     m.curRoundID,
+				RoundID:     roundID,
 				NodeID:      nodeID,
 				ValidatorID: vID,
 				Status:      types.ValidationStatusCreate,
@@ -183,6 +306,8 @@ func (m *Manager) getValidationDetails(vrs []*VWindow) (map[string]*api.Validate
 
 			bReqs[nodeID] = req
 		}
+
+		rec.Commit()
 	}
 
 	return bReqs, vrInfos
@@ -209,7 +334,7 @@ func (m *Manager) updateTimeoutResultInfo() {
 
 		node := m.nodeMgr.GetNode(resultInfo.NodeID)
 		if node != nil {
-			resultInfo.Profit = node.CalculateIncome(m.nodeMgr.TotalNetworkEdges, len(m.nodeMgr.GetNodeOfIP(node.ExternalIP)))
+			resultInfo.Profit = m.income(node)
 		} else {
 			resultInfo.Status = types.ValidationStatusNodeOffline
 		}
@@ -217,13 +342,25 @@ func (m *Manager) updateTimeoutResultInfo() {
 		err = m.nodeMgr.UpdateValidationResultInfo(resultInfo)
 		if err != nil {
 			log.Errorf("%d updateTimeoutResultInfo UpdateValidationResultInfo err:%s", resultInfo.ID, err.Error())
+			continue
 		}
+
+		m.recordProof(resultInfo, resultInfo.ValidatorID, nil, nil)
+		m.notify.Pub(resultInfo, types.EventValidationResult.String())
 	}
 }
 
+// income returns the points the node earns by the validation, rounded to the point decimals,
+// the validation in a round the node is drawn in pays the rounds of its tier it is not drawn in
+func (m *Manager) income(nd *node.Node) types.Points {
+	income := nd.CalculateIncome(m.nodeMgr.TotalNetworkEdges, len(m.nodeMgr.GetNodeOfIP(nd.ExternalIP)))
+	income *= drawnScale(m.tierPercents()[m.penaltyMgr.Tier(nd.NodeID)])
+	return types.PointsFromFloat(income).Round(m.nodeMgr.PointDecimals())
+}
+
 // updateResultInfo updates the validation result information for a given node.
-func (m *Manager) updateResultInfo(status types.ValidationStatus, vr *api.ValidationResult) error {
-	profit := 0.0
+func (m *Manager) updateResultInfo(status types.ValidationStatus, vr *api.ValidationResult, report, sign []byte) error {
+	profit := types.Points{}
 	// update node bandwidths
 	node := m.nodeMgr.GetNode(vr.NodeID)
 	if node != nil {
@@ -233,14 +370,14 @@ func (m *Manager) updateResultInfo(status types.ValidationStatus, vr *api.Valida
 			if status != types.ValidationStatusCancel {
 				node.BandwidthUp = int64(vr.Bandwidth)
 			}
-			profit = node.CalculateIncome(m.nodeMgr.TotalNetworkEdges, len(m.nodeMgr.GetNodeOfIP(node.ExternalIP)))
+			profit = m.income(node)
 		}
 	} else {
 		status = types.ValidationStatusNodeOffline
 	}
 
 	resultInfo := &types.ValidationResultInfo{
-		RoundID:     m.curRoundID,
+		RoundID:     m.roundOf(vr.NodeID),
 		NodeID:      vr.NodeID,
 		Status:      status,
 		BlockNumber: int64(len(vr.Cids)),
@@ -250,33 +387,42 @@ func (m *Manager) updateResultInfo(status types.ValidationStatus, vr *api.Valida
 		TokenID:     vr.Token,
 	}
 
-	return m.nodeMgr.UpdateValidationResultInfo(resultInfo)
+	if err := m.nodeMgr.UpdateValidationResultInfo(resultInfo); err != nil {
+		return err
+	}
+
+	m.recordProof(resultInfo, vr.Validator, report, sign)
+	m.notify.Pub(resultInfo, types.EventValidationResult.String())
+	return nil
 }
 
-// PushResult push validation result info to queue
-func (m *Manager) PushResult(vr *api.ValidationResult) {
+// PushResult push validation result info to queue, report and sign are the result reported by the validator and its signature
+func (m *Manager) PushResult(vr *api.ValidationResult, report, sign []byte) {
 	// TODO If the server is down, the data will be lost
-	m.resultQueue <- vr
+	m.resultQueue <- &validationReport{result: vr, report: report, sign: sign}
 }
 
 func (m *Manager) pullResults() {
 	for i := 0; i < validationWorkers; i++ {
 		go func() {
 			for {
-				result := <-m.resultQueue
-				m.handleResult(result)
+				r := <-m.resultQueue
+				m.handleResult(r.result, r.report, r.sign)
 			}
 		}()
 	}
 }
 
 // handleResult handles the validation result for a given node.
-func (m *Manager) handleResult(vr *api.ValidationResult) {
+func (m *Manager) handleResult(vr *api.ValidationResult, report, sign []byte) {
 	var status types.ValidationStatus
 	nodeID := vr.NodeID
+	roundID := m.roundOf(nodeID)
+
+	m.validationEnded(nodeID)
 
 	defer func() {
-		err := m.updateResultInfo(status, vr)
+		err := m.updateResultInfo(status, vr, report, sign)
 		if err != nil {
 			log.Errorf("updateResultInfo [%s] fail : %s", nodeID, err.Error())
 			return
@@ -296,14 +442,14 @@ func (m *Manager) handleResult(vr *api.ValidationResult) {
 	cidCount := len(vr.Cids)
 	if cidCount < 1 {
 		status = types.ValidationStatusValidateFail
-		log.Errorf("handleResult round [%s] validator [%s] nodeID [%s], seed [%d] ;cidCount<1", m.curRoundID, vr.Validator, nodeID)
+		log.Errorf("handleResult round [%s] validator [%s] nodeID [%s], seed [%d] ;cidCount<1", roundID, vr.Validator, nodeID)
 		return
 	}
 
-	vInfo, err := m.nodeMgr.LoadNodeValidationInfo(m.curRoundID, nodeID)
+	vInfo, err := m.nodeMgr.LoadNodeValidationInfo(roundID, nodeID)
 	if err != nil {
 		status = types.ValidationStatusLoadDBErr
-		log.Errorf("LoadNodeValidationCID %s , %s, err:%s", m.curRoundID, nodeID, err.Error())
+		log.Errorf("LoadNodeValidationCID %s , %s, err:%s", roundID, nodeID, err.Error())
 		return
 	}
 
@@ -340,7 +486,7 @@ func (m *Manager) handleResult(vr *api.ValidationResult) {
 
 		if !m.compareCid(resultCid, validatorCid) {
 			status = types.ValidationStatusValidateFail
-			log.Errorf("handleResult round [%s] validator [%s] cNodeID [%s] nodeID [%s], assetCID [%s] seed [%d] ; validator fail resultCid:%s, vCid:%s,index:%d", m.curRoundID, vr.Validator, cNodeID, nodeID, vInfo.Cid, m.seed, resultCid, validatorCid, i)
+			log.Errorf("handleResult round [%s] validator [%s] cNodeID [%s] nodeID [%s], assetCID [%s] seed [%d] ; validator fail resultCid:%s, vCid:%s,index:%d", roundID, vr.Validator, cNodeID, nodeID, vInfo.Cid, m.seed, resultCid, validatorCid, i)
 			return
 		}
 	}
@@ -451,7 +597,7 @@ func (m *Manager) handleValidationResults() {
 	}
 }
 
-func (m *Manager) loadResults(maxTime time.Time) ([]int, map[string]float64, error) {
+func (m *Manager) loadResults(maxTime time.Time) ([]int, map[string]types.Points, error) {
 	rows, err := m.nodeMgr.LoadUnCalculatedValidationResults(maxTime, vResultLimit)
 	if err != nil {
 		return nil, nil, err
@@ -460,7 +606,7 @@ func (m *Manager) loadResults(maxTime time.Time) ([]int, map[string]float64, err
 
 	// infos := make([]*types.ValidationResultInfo, 0)
 	ids := make([]int, 0)
-	nodeProfits := make(map[string]float64)
+	nodeProfits := make(map[string]types.Points)
 
 	for rows.Next() {
 		vInfo := &types.ValidationResultInfo{}
@@ -474,15 +620,15 @@ func (m *Manager) loadResults(maxTime time.Time) ([]int, map[string]float64, err
 			tokenID := vInfo.TokenID
 			record, err := m.nodeMgr.LoadRetrieveEvent(tokenID)
 			if err != nil {
-				vInfo.Profit = 0
+				vInfo.Profit = types.Points{}
 			} else {
 				// check time
 				if record.CreatedTime > vInfo.EndTime.Unix() {
-					vInfo.Profit = 0
+					vInfo.Profit = types.Points{}
 				}
 
 				if record.EndTime < vInfo.StartTime.Unix() {
-					vInfo.Profit = 0
+					vInfo.Profit = types.Points{}
 				}
 			}
 		}
@@ -490,11 +636,11 @@ func (m *Manager) loadResults(maxTime time.Time) ([]int, map[string]float64, err
 		// infos = append(infos, vInfo)
 		ids = append(ids, vInfo.ID)
 
-		if vInfo.Profit == 0 {
+		if vInfo.Profit.IsZero() {
 			continue
 		}
 
-		nodeProfits[vInfo.NodeID] += vInfo.Profit
+		nodeProfits[vInfo.NodeID] = nodeProfits[vInfo.NodeID].Add(vInfo.Profit)
 	}
 
 	return ids, nodeProfits, nil
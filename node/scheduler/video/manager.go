@@ -0,0 +1,97 @@
+package video
+
+import (
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("video")
+
+const (
+	// the segments reported by a node at once
+	maxReportedSegments = 1000
+	// the segment paths longer than the column are dropped
+	maxSegmentLength = 255
+	cleanInterval    = time.Hour
+)
+
+// Manager collects the retrievals of the segments of the video assets counted by the nodes
+type Manager struct {
+	config dtypes.GetSchedulerConfigFunc
+	*db.SQLDB
+}
+
+// NewManager return new video manager instance
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc) *Manager {
+	m := &Manager{
+		config: configFunc,
+		SQLDB:  sdb,
+	}
+
+	go m.startCleanTimer()
+
+	return m
+}
+
+// ReportSegmentHits adds the retrievals of the segments counted by the node since its last report
+func (m *Manager) ReportSegmentHits(nodeID string, hits []*types.SegmentHits) error {
+	if len(hits) > maxReportedSegments {
+		return xerrors.Errorf("%d segments exceed the limit %d", len(hits), maxReportedSegments)
+	}
+
+	stats := make([]*types.SegmentStats, 0, len(hits))
+	for _, h := range hits {
+		if h.Hits <= 0 || h.Segment == "" || len(h.Segment) > maxSegmentLength {
+			continue
+		}
+
+		hash, err := cidutil.CIDToHash(h.AssetCID)
+		if err != nil {
+			log.Warnf("node %s reported segment %s of invalid cid %s", nodeID, h.Segment, h.AssetCID)
+			continue
+		}
+
+		stats = append(stats, &types.SegmentStats{Hash: hash, Segment: h.Segment, Hits: h.Hits})
+	}
+
+	return m.AddSegmentHits(stats)
+}
+
+// ListSegmentStats returns the stats of the segments of the asset, the most retrieved first
+func (m *Manager) ListSegmentStats(assetCID string, limit, offset int) (*types.ListSegmentStatsRsp, error) {
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", assetCID, err.Error())
+	}
+
+	return m.LoadSegmentStats(hash, limit, offset)
+}
+
+func (m *Manager) startCleanTimer() {
+	ticker := time.NewTicker(cleanInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		cfg, err := m.config()
+		if err != nil {
+			log.Errorf("get scheduler config err:%s", err.Error())
+			continue
+		}
+
+		if cfg.SegmentStatsRetentionDays <= 0 {
+			continue
+		}
+
+		if err := m.DeleteSegmentStatsBefore(time.Now().AddDate(0, 0, -cfg.SegmentStatsRetentionDays)); err != nil {
+			log.Errorf("DeleteSegmentStatsBefore err:%s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+// UpdateAssetVideoFormat marks the asset as hls or dash
+func (s *Scheduler) UpdateAssetVideoFormat(ctx context.Context, cid string, format types.AssetVideoFormat) error {
+	return s.AssetManager.UpdateAssetVideoFormat(cid, format)
+}
+
+// GetAssetVideoFormat retrieves the video format of the asset, none if the asset is unknown
+func (s *Scheduler) GetAssetVideoFormat(ctx context.Context, cid string) (types.AssetVideoFormat, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return "", xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	format, err := s.db.LoadAssetVideoFormat(hash)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return format, nil
+}
+
+// ReportSegmentHits records the retrievals of the segments counted by the node
+func (s *Scheduler) ReportSegmentHits(ctx context.Context, hits []*types.SegmentHits) error {
+	nodeID := handler.GetNodeID(ctx)
+	if s.NodeManager.GetNode(nodeID) == nil {
+		return xerrors.Errorf("node %s not online", nodeID)
+	}
+
+	return s.VideoManager.ReportSegmentHits(nodeID, hits)
+}
+
+// ListSegmentStats retrieves the retrieval stats of the segments of the video asset, the most retrieved first
+func (s *Scheduler) ListSegmentStats(ctx context.Context, cid string, limit, offset int) (*types.ListSegmentStatsRsp, error) {
+	return s.VideoManager.ListSegmentStats(cid, limit, offset)
+}
@@ -0,0 +1,428 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/scheduler/diagnostics"
+	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("webhook")
+
+const (
+	batchSize       = 100
+	followInterval  = time.Second
+	deliverInterval = time.Second
+	sendTimeout     = 10 * time.Second
+
+	// the failed callbacks are retried after 30s, 1m, 2m ... up to 6h, and marked failed after maxAttempts
+	retryDelay    = 30 * time.Second
+	maxRetryDelay = 6 * time.Hour
+	maxAttempts   = 8
+)
+
+// errForbiddenAddress the url of the webhook resolves to an address of the network of the scheduler
+var errForbiddenAddress = xerrors.New("webhook url resolves to a forbidden address")
+
+// statusError the url responds the callback with a non-2xx status code
+type statusError int
+
+func (e statusError) Error() string {
+	return "webhook status code " + strconv.Itoa(int(e))
+}
+
+const (
+	// HeaderEvent the header of the event of the callback
+	HeaderEvent = "X-Titan-Event"
+	// HeaderDelivery the header of the delivery id, the same for the retries of a callback
+	HeaderDelivery = "X-Titan-Delivery"
+	// HeaderTimestamp the header of the unix time the callback is signed at
+	HeaderTimestamp = "X-Titan-Timestamp"
+	// HeaderSignature the header of the signature of the callback
+	HeaderSignature = "X-Titan-Signature"
+)
+
+// the outbox topics the milestones of the webhooks are derived from
+var topics = []types.OutboxTopic{types.OutboxAssetState, types.OutboxReplicaRemoved, types.OutboxAssetExpired, types.OutboxAssetPurged}
+
+// Manager follows the asset events of the outbox and calls the webhooks of the assets and their tenants,
+// the callbacks are saved before they are sent and retried with backoff until the url accepts them
+type Manager struct {
+	*db.SQLDB
+	leadershipMgr *leadership.Manager
+	httpClient    *http.Client
+}
+
+// NewManager return new webhook manager instance
+func NewManager(sdb *db.SQLDB, lmgr *leadership.Manager) *Manager {
+	m := &Manager{
+		SQLDB:         sdb,
+		leadershipMgr: lmgr,
+		httpClient:    newHTTPClient(),
+	}
+
+	go m.startFollower()
+	go m.startDeliverer()
+
+	return m
+}
+
+// newHTTPClient returns the client the callbacks are sent with, the addresses are checked when they are dialed
+// so the host names resolving or redirecting to the local addresses are rejected as well
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: sendTimeout, Control: checkAddress}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// the proxy would be the dialed address instead of the url
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: sendTimeout, Transport: transport}
+}
+
+// checkAddress rejects the connections to the addresses the webhooks may not call
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !types.WebhookIPAllowed(ip) {
+		return errForbiddenAddress
+	}
+
+	return nil
+}
+
+func (m *Manager) startFollower() {
+	t := diagnostics.NewTimer("webhook.follow", followInterval)
+
+	for {
+		done := t.Start()
+		delay := m.follow()
+		done()
+
+		time.Sleep(delay)
+	}
+}
+
+func (m *Manager) startDeliverer() {
+	t := diagnostics.NewTimer("webhook.deliver", deliverInterval)
+
+	for {
+		done := t.Start()
+		delay := m.deliver()
+		done()
+
+		time.Sleep(delay)
+	}
+}
+
+// follow saves the callbacks of a batch of the outbox events and returns the time to wait before the next batch
+func (m *Manager) follow() time.Duration {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return followInterval
+	}
+
+	cursor, err := m.LoadWebhookCursor()
+	if err != nil {
+		log.Errorf("LoadWebhookCursor err:%s", err.Error())
+		return followInterval
+	}
+
+	events, err := m.LoadOutboxEventsAfter(cursor, topics, batchSize)
+	if err != nil {
+		log.Errorf("LoadOutboxEventsAfter err:%s", err.Error())
+		return followInterval
+	}
+
+	if len(events) == 0 {
+		return followInterval
+	}
+
+	var deliveries []*types.WebhookDelivery
+	for _, event := range events {
+		list, err := m.deliveriesOf(event)
+		if err != nil {
+			// the batch is followed again
+			log.Errorf("load webhooks of outbox event %d err:%s", event.ID, err.Error())
+			return followInterval
+		}
+
+		deliveries = append(deliveries, list...)
+	}
+
+	if err = m.SaveWebhookDeliveries(deliveries, events[len(events)-1].ID); err != nil {
+		log.Errorf("SaveWebhookDeliveries err:%s", err.Error())
+		return followInterval
+	}
+
+	if len(events) < batchSize {
+		return followInterval
+	}
+
+	// more events are pending
+	return 0
+}
+
+// deliveriesOf returns the callbacks of the webhooks called on the milestone of the outbox event
+func (m *Manager) deliveriesOf(event *types.OutboxEvent) ([]*types.WebhookDelivery, error) {
+	payload, users, err := milestoneOf(event)
+	if err != nil {
+		log.Warnf("outbox event %d is not a valid %s event: %s", event.ID, event.Topic, err.Error())
+		return nil, nil
+	}
+
+	if payload == nil {
+		return nil, nil
+	}
+
+	if users == nil {
+		record, err := m.LoadAssetRecord(payload.Hash)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// the replicas of a removed asset are purged and the replicas of an archived asset are offloaded, not lost
+		if payload.Event == types.WebhookReplicaLost && (record.State == assets.Remove.String() || record.State == assets.Archived.String()) {
+			return nil, nil
+		}
+
+		payload.CID = record.CID
+		users, err = m.ListUsersForAsset(payload.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	webhooks, err := m.LoadWebhooksOfAsset(payload.Hash, users)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*types.WebhookDelivery, 0, len(webhooks))
+	for _, w := range webhooks {
+		if !w.Events.Has(payload.Event) {
+			continue
+		}
+
+		p := *payload
+		p.WebhookID = w.ID
+		buf, err := json.Marshal(&p)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, &types.WebhookDelivery{
+			WebhookID: w.ID,
+			Event:     p.Event,
+			Hash:      p.Hash,
+			Payload:   buf,
+			Status:    types.WebhookDeliveryPending,
+			NextTime:  time.Now(),
+		})
+	}
+
+	return deliveries, nil
+}
+
+// milestoneOf returns the payload of the callbacks on the outbox event, nil if the event is not a milestone.
+// The users of the asset are returned for the expired and purged events, the other events load them
+func milestoneOf(event *types.OutboxEvent) (*types.WebhookPayload, []string, error) {
+	payload := &types.WebhookPayload{Hash: event.Subject, Time: event.CreatedTime}
+
+	switch event.Topic {
+	case types.OutboxAssetState:
+		var p types.OutboxAssetStatePayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return nil, nil, err
+		}
+
+		if p.State != assets.Servicing.String() {
+			return nil, nil, nil
+		}
+		payload.Event = types.WebhookReplicationComplete
+	case types.OutboxReplicaRemoved:
+		var p types.OutboxReplicaPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return nil, nil, err
+		}
+
+		payload.Event = types.WebhookReplicaLost
+		payload.NodeID = p.NodeID
+	case types.OutboxAssetExpired, types.OutboxAssetPurged:
+		var p types.OutboxAssetLifecyclePayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return nil, nil, err
+		}
+
+		payload.Event = types.WebhookAssetPurged
+		if event.Topic == types.OutboxAssetExpired {
+			payload.Event = types.WebhookAssetExpired
+		}
+		payload.CID = p.CID
+
+		return payload, append([]string{}, p.Users...), nil
+	default:
+		return nil, nil, nil
+	}
+
+	return payload, nil, nil
+}
+
+// deliver sends a batch of the due callbacks and returns the time to wait before the next batch
+func (m *Manager) deliver() time.Duration {
+	if !m.leadershipMgr.RequestAndBecomeMaster() {
+		return deliverInterval
+	}
+
+	deliveries, err := m.LoadDueWebhookDeliveries(time.Now(), batchSize)
+	if err != nil {
+		log.Errorf("LoadDueWebhookDeliveries err:%s", err.Error())
+		return deliverInterval
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+
+		go func(d *types.WebhookDelivery) {
+			defer wg.Done()
+			m.attempt(d)
+		}(d)
+	}
+	wg.Wait()
+
+	if len(deliveries) < batchSize {
+		return deliverInterval
+	}
+
+	// more callbacks are due
+	return 0
+}
+
+// attempt sends the callback and saves the result, a failed callback is retried with backoff
+func (m *Manager) attempt(d *types.WebhookDelivery) {
+	w, err := m.LoadWebhook(d.WebhookID)
+	if err != nil {
+		log.Errorf("LoadWebhook %d err:%s", d.WebhookID, err.Error())
+		return
+	}
+
+	d.Attempts++
+	err = m.send(w, d)
+	switch {
+	case err == nil:
+		d.Status = types.WebhookDeliveryDelivered
+		d.LastError = ""
+	case d.Attempts >= maxAttempts:
+		d.Status = types.WebhookDeliveryFailed
+		d.LastError = deliveryError(err)
+	default:
+		d.NextTime = time.Now().Add(backoff(d.Attempts))
+		d.LastError = deliveryError(err)
+	}
+
+	if err != nil {
+		log.Warnf("deliver webhook %d callback %d err:%s, attempts %d", w.ID, d.ID, err.Error(), d.Attempts)
+	}
+
+	if err = m.UpdateWebhookDelivery(d); err != nil {
+		// the callback is sent again, the receivers deduplicate by the delivery id
+		log.Errorf("UpdateWebhookDelivery %d err:%s", d.ID, err.Error())
+	}
+}
+
+func (m *Manager) send(w *types.Webhook, d *types.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(d.Event))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, d.Payload))
+
+	rsp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close() //nolint:errcheck
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return statusError(rsp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the signature of the callback, the hex HMAC-SHA256 of the timestamp and the body joined by a dot,
+// keyed by the secret of the webhook
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before retrying a callback failed the attempts, doubled with each attempt
+func backoff(attempts int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
+
+// deliveryError returns the error of the attempt shown to the owner of the webhook, the errors of the requests
+// are reduced to their kinds so the addresses and the responses of the network of the scheduler are not exposed
+func deliveryError(err error) string {
+	var status statusError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, errForbiddenAddress):
+		return errForbiddenAddress.Error()
+	case errors.As(err, &status):
+		return status.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "webhook request timed out"
+	default:
+		return "webhook request failed"
+	}
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+)
+
+func outboxEvent(t *testing.T, topic types.OutboxTopic, payload interface{}) *types.OutboxEvent {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &types.OutboxEvent{ID: 1, Topic: topic, Subject: "hash", Payload: buf, CreatedTime: time.Now()}
+}
+
+func TestMilestoneOf(t *testing.T) {
+	tests := []struct {
+		event  *types.OutboxEvent
+		expect types.WebhookEvent
+		users  int
+	}{
+		{event: outboxEvent(t, types.OutboxAssetState, &types.OutboxAssetStatePayload{Hash: "hash", State: assets.Servicing.String()}), expect: types.WebhookReplicationComplete},
+		{event: outboxEvent(t, types.OutboxAssetState, &types.OutboxAssetStatePayload{Hash: "hash", State: assets.SeedPulling.String()})},
+		{event: outboxEvent(t, types.OutboxReplicaRemoved, &types.OutboxReplicaPayload{Hash: "hash", NodeID: "e_1"}), expect: types.WebhookReplicaLost},
+		{event: outboxEvent(t, types.OutboxAssetExpired, &types.OutboxAssetLifecyclePayload{Hash: "hash", CID: "cid", Users: []string{"u1", "u2"}}), expect: types.WebhookAssetExpired, users: 2},
+		{event: outboxEvent(t, types.OutboxAssetPurged, &types.OutboxAssetLifecyclePayload{Hash: "hash", CID: "cid"}), expect: types.WebhookAssetPurged},
+		{event: outboxEvent(t, types.OutboxReplicaAdded, &types.OutboxReplicaPayload{Hash: "hash", NodeID: "e_1"})},
+	}
+
+	for _, tt := range tests {
+		payload, users, err := milestoneOf(tt.event)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.expect == "" {
+			if payload != nil {
+				t.Errorf("%s event %s is not a milestone, got %s", tt.event.Topic, tt.event.Payload, payload.Event)
+			}
+			continue
+		}
+
+		if payload == nil || payload.Event != tt.expect || payload.Hash != "hash" {
+			t.Errorf("expect %s for the %s event, got %v", tt.expect, tt.event.Topic, payload)
+			continue
+		}
+
+		if len(users) != tt.users {
+			t.Errorf("expect %d users for the %s event, got %d", tt.users, tt.event.Topic, len(users))
+		}
+	}
+
+	// the lifecycle events carry the users, the other events load them
+	_, users, _ := milestoneOf(tests[4].event)
+	if users == nil {
+		t.Error("expect the users of the purged event even if the asset has no users")
+	}
+
+	if _, _, err := milestoneOf(&types.OutboxEvent{Topic: types.OutboxReplicaRemoved, Payload: []byte("{")}); err == nil {
+		t.Error("expect an error for an invalid payload")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expect   time.Duration
+	}{
+		{attempts: 1, expect: 30 * time.Second},
+		{attempts: 2, expect: time.Minute},
+		{attempts: 5, expect: 8 * time.Minute},
+		{attempts: 20, expect: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.expect {
+			t.Errorf("backoff(%d) = %s, expect %s", tt.attempts, got, tt.expect)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	w := &types.Webhook{ID: 1, Secret: "secret"}
+	d := &types.WebhookDelivery{ID: 7, WebhookID: 1, Event: types.WebhookAssetPurged, Payload: []byte(`{"webhook_id":1}`)}
+
+	status := http.StatusOK
+	var verified bool
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		expect := Sign("secret", r.Header.Get(HeaderTimestamp), d.Payload)
+		verified = r.Header.Get(HeaderSignature) == expect && r.Header.Get(HeaderDelivery) == "7" &&
+			r.Header.Get(HeaderEvent) == string(types.WebhookAssetPurged)
+		rw.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	w.URL = srv.URL
+	m := &Manager{httpClient: srv.Client()}
+
+	if err := m.send(w, d); err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Error("the callback is not signed with the secret of the webhook")
+	}
+
+	status = http.StatusInternalServerError
+	if err := m.send(w, d); err == nil {
+		t.Error("expect an error for a failed callback")
+	}
+
+	if Sign("secret", "1", d.Payload) == Sign("other", "1", d.Payload) {
+		t.Error("expect different signatures for different secrets")
+	}
+}
+
+func TestForbiddenAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	w := &types.Webhook{ID: 1, Secret: "secret", URL: srv.URL}
+	d := &types.WebhookDelivery{ID: 7, WebhookID: 1, Event: types.WebhookAssetPurged, Payload: []byte(`{"webhook_id":1}`)}
+	m := &Manager{httpClient: newHTTPClient()}
+
+	err := m.send(w, d)
+	if err == nil {
+		t.Fatal("expect the callback to the loopback address to be rejected")
+	}
+	if msg := deliveryError(err); msg != errForbiddenAddress.Error() {
+		t.Errorf("expect %q, got %q", errForbiddenAddress.Error(), msg)
+	}
+
+	if msg := deliveryError(statusError(http.StatusBadGateway)); msg != "webhook status code 502" {
+		t.Errorf("unexpected error of the status code %q", msg)
+	}
+	if msg := deliveryError(errors.New("dial tcp 10.0.0.1:80: connection refused")); msg != "webhook request failed" {
+		t.Errorf("expect the request error to be hidden, got %q", msg)
+	}
+
+	for _, u := range []string{"http://127.0.0.1:8080/", "http://localhost/", "http://169.254.169.254/latest", "http://10.1.2.3/", "http://[::1]/", "http://0.0.0.0/"} {
+		if err := (&types.Webhook{URL: u}).ValidateURL(); err == nil {
+			t.Errorf("expect url %s to be rejected", u)
+		}
+	}
+	if err := (&types.Webhook{URL: "https://example.com/hook"}).ValidateURL(); err != nil {
+		t.Error(err)
+	}
+}
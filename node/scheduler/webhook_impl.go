@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+)
+
+const (
+	// the webhooks a user or a tenant registers at most
+	maxWebhooks = 20
+	// the random bytes of the secret the callbacks of a webhook are signed with
+	webhookSecretBytes = 32
+)
+
+// CreateAssetWebhook registers the url called on the events of the asset of the user, all events if events is empty
+func (s *Scheduler) CreateAssetWebhook(ctx context.Context, userID, assetCID, url string, events []types.WebhookEvent) (*types.Webhook, error) {
+	userID, _, err := s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.CidToHashFiled.Int(), Message: err.Error()}
+	}
+
+	exist, err := s.db.AssetExistsOfUser(hash, userID)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if !exist {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("asset %s not found", assetCID)}
+	}
+
+	return s.createWebhook(&types.Webhook{UserID: userID, Hash: hash, AssetCID: assetCID, URL: url, Events: events})
+}
+
+// CreateTenantWebhook registers the url called on the events of the assets of the users of the tenant, all events if events is empty
+func (s *Scheduler) CreateTenantWebhook(ctx context.Context, tenantID, url string, events []types.WebhookEvent) (*types.Webhook, error) {
+	if err := checkTenantScope(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	if s.TenantManager.Tenant(tenantID) == nil {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("tenant %s not found", tenantID)}
+	}
+
+	return s.createWebhook(&types.Webhook{TenantID: tenantID, URL: url, Events: events})
+}
+
+// createWebhook saves the webhook with a new secret, the secret is returned to the caller this time only
+func (s *Scheduler) createWebhook(w *types.Webhook) (*types.Webhook, error) {
+	if err := w.ValidateURL(); err != nil {
+		return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	if err := w.Events.Validate(); err != nil {
+		return nil, &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: err.Error()}
+	}
+
+	webhooks, err := s.db.LoadWebhooks(w.UserID, w.TenantID)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if len(webhooks) >= maxWebhooks {
+		return nil, &api.ErrWeb{Code: terrors.WebhookLimit.Int(), Message: fmt.Sprintf("webhooks exceed maximum limit %d", maxWebhooks)}
+	}
+
+	buf := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	w.Secret = hex.EncodeToString(buf)
+
+	id, err := s.db.SaveWebhook(w)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+	w.ID = id
+	w.CreatedTime = time.Now()
+
+	return w, nil
+}
+
+// ListWebhooks get the asset webhooks of the user, or the webhooks of the tenant if tenantID is not empty
+func (s *Scheduler) ListWebhooks(ctx context.Context, userID, tenantID string) ([]*types.Webhook, error) {
+	var webhooks []*types.Webhook
+	var err error
+
+	if tenantID != "" {
+		if err = checkTenantWebhookAccess(ctx, tenantID); err != nil {
+			return nil, err
+		}
+		webhooks, err = s.db.LoadWebhooks("", tenantID)
+	} else {
+		if userID, _, err = s.admitUser(ctx, userID); err != nil {
+			return nil, err
+		}
+		webhooks, err = s.db.LoadWebhooks(userID, "")
+	}
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	for _, w := range webhooks {
+		w.Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook and its deliveries
+func (s *Scheduler) DeleteWebhook(ctx context.Context, userID string, webhookID int64) error {
+	if _, err := s.loadWebhookOfCaller(ctx, userID, webhookID); err != nil {
+		return err
+	}
+
+	return s.db.DeleteWebhook(webhookID)
+}
+
+// ListWebhookDeliveries get the callbacks of the webhook with their delivery status, the latest first
+func (s *Scheduler) ListWebhookDeliveries(ctx context.Context, userID string, webhookID int64, limit, offset int) (*types.ListWebhookDeliveryRsp, error) {
+	if _, err := s.loadWebhookOfCaller(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	return s.db.LoadWebhookDeliveries(webhookID, limit, offset)
+}
+
+// loadWebhookOfCaller loads the webhook if the caller can access it, the asset webhooks are accessed by their users
+// and the tenant webhooks by the callers of the tenant
+func (s *Scheduler) loadWebhookOfCaller(ctx context.Context, userID string, webhookID int64) (*types.Webhook, error) {
+	w, err := s.db.LoadWebhook(webhookID)
+	if err == sql.ErrNoRows {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("webhook %d not found", webhookID)}
+	}
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if w.TenantID != "" {
+		if err = checkTenantWebhookAccess(ctx, w.TenantID); err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	userID, _, err = s.admitUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.UserID != userID {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("webhook %d not found", webhookID)}
+	}
+
+	return w, nil
+}
+
+// checkTenantWebhookAccess checks if the caller can manage the webhooks of the tenant, the users of the tenant can not
+func checkTenantWebhookAccess(ctx context.Context, tenantID string) error {
+	if len(handler.GetUserID(ctx)) > 0 {
+		return &api.ErrWeb{Code: terrors.TenantAccessDenied.Int(), Message: fmt.Sprintf("can not access the webhooks of tenant %s", tenantID)}
+	}
+
+	return checkTenantScope(ctx, tenantID)
+}
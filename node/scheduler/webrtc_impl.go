@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"golang.org/x/xerrors"
+)
+
+// GetWebRTCConfig retrieves the stun and turn servers the browsers connect the webrtc sessions with
+func (s *Scheduler) GetWebRTCConfig(ctx context.Context) (*types.WebRTCConfig, error) {
+	servers, err := s.SignalingManager.ICEServers()
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WebRTCConfig{ICEServers: servers}, nil
+}
+
+// WebRTCConnect relays the webrtc offer of the browser to an edge holding the asset and returns the answer of the edge
+func (s *Scheduler) WebRTCConnect(ctx context.Context, req *types.WebRTCOfferReq) (*types.WebRTCAnswer, error) {
+	hash, err := cidutil.CIDToHash(req.AssetCID)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", req.AssetCID, err.Error())
+	}
+
+	if err := s.checkRetrievalDenied(hash, req.AssetCID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAssetAccess(ctx, hash, req.AssetCID); err != nil {
+		return nil, err
+	}
+
+	return s.SignalingManager.Connect(ctx, hash, req)
+}
@@ -0,0 +1,292 @@
+// the dashboard reads the statistics from the rest api and subscribes the node events over the json-rpc websocket,
+// the token is kept in the local storage of the browser
+(function () {
+  'use strict';
+
+  const tokenKey = 'titan-dashboard-token';
+  const refreshInterval = 30 * 1000;
+  const maxEvents = 100;
+  const reconnectDelay = 5 * 1000;
+
+  let token = localStorage.getItem(tokenKey) || '';
+  let refreshTimer = null;
+  let socket = null;
+
+  const $ = (id) => document.getElementById(id);
+
+  function el(tag, className, text) {
+    const e = document.createElement(tag);
+    if (className) {
+      e.className = className;
+    }
+    if (text !== undefined) {
+      e.textContent = text;
+    }
+    return e;
+  }
+
+  function svgEl(tag, attrs) {
+    const e = document.createElementNS('http://www.w3.org/2000/svg', tag);
+    Object.keys(attrs).forEach((k) => e.setAttribute(k, attrs[k]));
+    return e;
+  }
+
+  function setStatus(message) {
+    $('status').textContent = message || '';
+  }
+
+  function formatNumber(n) {
+    if (n >= 1e9) return (n / 1e9).toFixed(1) + 'G';
+    if (n >= 1e6) return (n / 1e6).toFixed(1) + 'M';
+    if (n >= 1e3) return (n / 1e3).toFixed(1) + 'k';
+    return String(Math.round(n * 100) / 100);
+  }
+
+  async function fetchDashboard() {
+    const rsp = await fetch('/rest/v0/dashboard', { headers: { Authorization: 'Bearer ' + token } });
+    if (rsp.status === 401) {
+      throw new Error('the token is rejected by the scheduler');
+    }
+
+    const body = await rsp.json();
+    if (!rsp.ok) {
+      throw new Error(body.error || rsp.statusText);
+    }
+    return body;
+  }
+
+  async function refresh() {
+    try {
+      render(await fetchDashboard());
+      setStatus('');
+      $('dashboard').hidden = false;
+    } catch (err) {
+      setStatus(err.message);
+    }
+  }
+
+  function render(stats) {
+    $('area').textContent = stats.area_id ? '· ' + stats.area_id : '';
+    $('updated').textContent = new Date(stats.updated_time).toLocaleString();
+
+    renderSummary(stats);
+    renderNodeMap(stats.nodes || []);
+    renderReplication(stats.assets || []);
+    renderPoints(stats.points || []);
+    renderValidations(stats.validations || []);
+  }
+
+  function renderSummary(stats) {
+    let edges = 0;
+    let candidates = 0;
+    (stats.nodes || []).forEach((n) => {
+      edges += n.edges;
+      candidates += n.candidates;
+    });
+
+    let assets = 0;
+    (stats.assets || []).forEach((a) => {
+      assets += a.count;
+    });
+
+    let total = 0;
+    let passed = 0;
+    (stats.validations || []).forEach((v) => {
+      total += v.total;
+      passed += v.passed;
+    });
+
+    $('edges').textContent = formatNumber(edges);
+    $('candidates').textContent = formatNumber(candidates);
+    $('assets').textContent = formatNumber(assets);
+    $('pass-rate').textContent = total > 0 ? (passed / total * 100).toFixed(1) + '%' : '-';
+  }
+
+  // renderNodeMap draws a tile for each area, the more nodes the darker the tile
+  function renderNodeMap(nodes) {
+    const map = $('node-map');
+    map.replaceChildren();
+
+    const max = Math.max(1, ...nodes.map((n) => n.edges + n.candidates));
+    nodes.forEach((n) => {
+      const count = n.edges + n.candidates;
+      const tile = el('div', 'tile');
+      const lightness = 70 - Math.round(40 * Math.log(1 + count) / Math.log(1 + max));
+      tile.style.background = 'hsl(217, 80%, ' + lightness + '%)';
+      tile.title = n.geo || 'unknown';
+
+      tile.appendChild(el('div', 'geo', n.geo || 'unknown'));
+      tile.appendChild(el('div', '', n.edges + ' edges · ' + n.candidates + ' candidates'));
+      if (n.abnormal > 0) {
+        tile.appendChild(el('div', 'abnormal', n.abnormal + ' abnormal'));
+      }
+      map.appendChild(tile);
+    });
+
+    if (nodes.length === 0) {
+      map.appendChild(el('p', '', 'No node is online.'));
+    }
+  }
+
+  function renderBars(container, rows) {
+    container.replaceChildren();
+
+    const max = Math.max(1, ...rows.map((r) => r.value));
+    rows.forEach((r) => {
+      const bar = el('div', 'bar');
+      bar.appendChild(el('span', '', r.label));
+
+      const track = el('div');
+      const fill = el('div', 'fill');
+      fill.style.width = (r.value / max * 100) + '%';
+      track.appendChild(fill);
+      bar.appendChild(track);
+
+      bar.appendChild(el('span', 'count', formatNumber(r.value)));
+      container.appendChild(bar);
+    });
+
+    if (rows.length === 0) {
+      container.appendChild(el('p', '', 'No data.'));
+    }
+  }
+
+  function renderReplication(assets) {
+    renderBars($('replication'), assets.map((a) => ({ label: a.state, value: a.count })));
+  }
+
+  function renderPoints(buckets) {
+    renderBars($('points'), buckets.map((b) => ({
+      label: formatNumber(b.min) + ' - ' + formatNumber(b.max) + ' points',
+      value: b.nodes,
+    })));
+  }
+
+  // renderValidations draws the passed and failed validations of each hour as stacked bars
+  function renderValidations(validations) {
+    const svg = $('validations');
+    svg.replaceChildren();
+
+    const width = 960;
+    const height = 180;
+    const max = Math.max(1, ...validations.map((v) => v.total));
+    const slot = width / Math.max(24, validations.length);
+
+    validations.forEach((v, i) => {
+      const x = i * slot + 2;
+      const w = Math.max(1, slot - 4);
+      const passedHeight = v.passed / max * height;
+      const failedHeight = (v.total - v.passed) / max * height;
+
+      const passed = svgEl('rect', { class: 'passed', x: x, y: height - passedHeight, width: w, height: passedHeight });
+      const failed = svgEl('rect', { class: 'failed', x: x, y: height - passedHeight - failedHeight, width: w, height: failedHeight });
+
+      const hour = new Date(v.hour);
+      const rate = v.total > 0 ? (v.passed / v.total * 100).toFixed(1) : '0';
+      const title = svgEl('title', {});
+      title.textContent = hour.toLocaleString() + ': ' + v.passed + '/' + v.total + ' passed (' + rate + '%)';
+      passed.appendChild(title);
+      failed.appendChild(title.cloneNode(true));
+
+      svg.appendChild(passed);
+      svg.appendChild(failed);
+
+      const label = svgEl('text', { x: x, y: height + 14 });
+      label.textContent = String(hour.getHours()).padStart(2, '0');
+      svg.appendChild(label);
+    });
+  }
+
+  function addEvent(event) {
+    const list = $('events');
+    const time = new Date(event.Time).toLocaleTimeString();
+    list.insertBefore(el('li', event.Event, time + ' ' + event.NodeID + ' ' + event.Event), list.firstChild);
+
+    while (list.children.length > maxEvents) {
+      list.removeChild(list.lastChild);
+    }
+  }
+
+  // subscribe opens the json-rpc websocket and subscribes the node events, the values of the channel
+  // are sent by the scheduler as xrpc.ch.val notifications
+  function subscribe() {
+    closeSocket();
+
+    const scheme = location.protocol === 'https:' ? 'wss://' : 'ws://';
+    const ws = new WebSocket(scheme + location.host + '/rpc/v0?token=' + encodeURIComponent(token));
+    let channelID = null;
+    socket = ws;
+
+    ws.onopen = () => {
+      ws.send(JSON.stringify({ jsonrpc: '2.0', id: 1, method: 'titan.SubscribeNodeEvents', params: [] }));
+    };
+
+    ws.onmessage = (msg) => {
+      const data = JSON.parse(msg.data);
+      if (data.id === 1) {
+        if (data.error) {
+          setStatus('subscribe node events: ' + data.error.message);
+          return;
+        }
+        channelID = data.result;
+        return;
+      }
+
+      if (data.method === 'xrpc.ch.val' && data.params[0] === channelID) {
+        addEvent(data.params[1]);
+      } else if (data.method === 'xrpc.ch.close' && data.params[0] === channelID) {
+        ws.close();
+      }
+    };
+
+    ws.onclose = () => {
+      if (socket === ws) {
+        socket = null;
+        setTimeout(() => {
+          if (token && socket === null) {
+            subscribe();
+          }
+        }, reconnectDelay);
+      }
+    };
+  }
+
+  function closeSocket() {
+    if (socket) {
+      const ws = socket;
+      socket = null;
+      ws.close();
+    }
+  }
+
+  function start() {
+    clearInterval(refreshTimer);
+    if (!token) {
+      setStatus('Enter a web or admin token of the scheduler to connect.');
+      return;
+    }
+
+    refresh();
+    refreshTimer = setInterval(refresh, refreshInterval);
+    subscribe();
+  }
+
+  $('auth').addEventListener('submit', (e) => {
+    e.preventDefault();
+    token = $('token').value.trim();
+    $('token').value = '';
+    localStorage.setItem(tokenKey, token);
+    start();
+  });
+
+  $('logout').addEventListener('click', () => {
+    token = '';
+    localStorage.removeItem(tokenKey);
+    clearInterval(refreshTimer);
+    closeSocket();
+    $('dashboard').hidden = true;
+    start();
+  });
+
+  start();
+})();
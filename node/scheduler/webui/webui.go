@@ -0,0 +1,26 @@
+// Package webui serves the web dashboard of the scheduler for the operators without their own dashboards,
+// the dashboard is a static page that reads the rest api and subscribes the node events over the json-rpc websocket
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// PathPrefix the path the dashboard is served at
+const PathPrefix = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// Handler returns the handler of the static files of the dashboard
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		// the embedded directory always exists
+		panic(err)
+	}
+
+	return http.StripPrefix(PathPrefix, http.FileServer(http.FS(files)))
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/penalty"
+	"github.com/Filecoin-Titan/titan/node/scheduler/traffic"
 	logging "github.com/ipfs/go-log/v2"
 	"golang.org/x/xerrors"
 )
@@ -32,21 +34,26 @@ type Manager struct {
 	config        dtypes.GetSchedulerConfigFunc
 	leadershipMgr *leadership.Manager
 	nodeMgr       *node.Manager
+	trafficMgr    *traffic.Manager
+	penaltyMgr    *penalty.Manager
 	*db.SQLDB
 
 	resultQueue chan *WorkloadResult
 }
 
 // NewManager return new node manager instance
-func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager, nmgr *node.Manager) *Manager {
+func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *leadership.Manager, nmgr *node.Manager, tmgr *traffic.Manager, pmgr *penalty.Manager) *Manager {
 	manager := &Manager{
 		config:        configFunc,
 		leadershipMgr: lmgr,
 		SQLDB:         sdb,
 		nodeMgr:       nmgr,
+		trafficMgr:    tmgr,
+		penaltyMgr:    pmgr,
 	}
 
 	go manager.startHandleWorkloadResults()
+	go manager.startCleanReceiptsTimer()
 	manager.handleResults()
 
 	return manager
@@ -100,7 +107,7 @@ func (m *Manager) handleWorkloadResults() {
 		log.Debugf("handleWorkloadResult time:%s , len:%d , endTime:%d", time.Since(startTime), resultLen, endTime)
 	}()
 
-	profit := m.getValidationProfit()
+	profit := types.PointsFromFloat(m.getValidationProfit())
 
 	// do handle workload result
 	rows, err := m.LoadUnprocessedWorkloadResults(vWorkloadLimit, endTime)
@@ -108,10 +115,8 @@ func (m *Manager) handleWorkloadResults() {
 		log.Errorf("LoadWorkloadResults err:%s", err.Error())
 		return
 	}
-	defer rows.Close()
-
-	removeIDs := make([]string, 0)
 
+	records := make([]*types.WorkloadRecord, 0)
 	for rows.Next() {
 		resultLen++
 
@@ -122,8 +127,22 @@ func (m *Manager) handleWorkloadResults() {
 			continue
 		}
 
+		records = append(records, record)
+	}
+	rows.Close()
+
+	// the workloads the sampled receipts show overreported are not credited
+	overreported := m.verifyReceipts(records)
+
+	removeIDs := make([]string, 0)
+
+	for _, record := range records {
 		removeIDs = append(removeIDs, record.ID)
 
+		if overreported[record.ID] {
+			continue
+		}
+
 		// check workload ...
 		status, cWorkload := m.checkWorkload(record)
 		if status == types.WorkloadStatusSucceeded {
@@ -142,6 +161,9 @@ func (m *Manager) handleWorkloadResults() {
 				continue
 			}
 
+			m.nodeMgr.AddTransfer(record.NodeID, cWorkload.Protocol, cWorkload.DownloadSize)
+			m.trafficMgr.AddUpload(record.NodeID, cWorkload.DownloadSize)
+
 			// update node bandwidths
 			t := cWorkload.EndTime.Sub(cWorkload.StartTime)
 			if t > 1 {
@@ -203,6 +225,11 @@ func (m *Manager) checkWorkload(record *types.WorkloadRecord) (types.WorkloadSta
 		return types.WorkloadStatusFailed, cWorkload
 	}
 
+	// the protocol seen by the node is used if the client does not report it
+	if cWorkload.Protocol == "" {
+		cWorkload.Protocol = nWorkload.Protocol
+	}
+
 	// TODO other ...
 
 	return types.WorkloadStatusSucceeded, cWorkload
@@ -320,8 +347,12 @@ func (m *Manager) mergeWorkloads(workloads []*types.Workload) *types.Workload {
 	endTime := time.Time{}
 	speedCount := int64(0)
 	accumulateSpeed := int64(0)
+	protocol := types.TransferProtocol("")
 
 	for _, workload := range workloads {
+		if workload.Protocol != "" {
+			protocol = workload.Protocol
+		}
 		if workload.DownloadSpeed > 0 {
 			accumulateSpeed += workload.DownloadSpeed
 			speedCount++
@@ -341,5 +372,5 @@ func (m *Manager) mergeWorkloads(workloads []*types.Workload) *types.Workload {
 	if speedCount > 0 {
 		downloadSpeed = accumulateSpeed / speedCount
 	}
-	return &types.Workload{DownloadSpeed: downloadSpeed, DownloadSize: downloadSize, StartTime: startTime, EndTime: endTime}
+	return &types.Workload{DownloadSpeed: downloadSpeed, DownloadSize: downloadSize, StartTime: startTime, EndTime: endTime, Protocol: protocol}
 }
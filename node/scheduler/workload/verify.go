@@ -0,0 +1,236 @@
+package workload
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the receipts submitted at once
+	maxReceipts          = 1000
+	receiptCleanInterval = time.Hour
+)
+
+// AddReceipts samples the workload receipts signed by the downloader, the sampled receipts are verified against
+// the workloads reported by the serving nodes when the workload records of their tokens are processed.
+// The receipts are sampled by the scheduler, so the nodes do not know which of their workloads are verified
+func (m *Manager) AddReceipts(client *node.Node, receipts []*types.WorkloadReceipt) error {
+	if len(receipts) > maxReceipts {
+		return xerrors.Errorf("%d receipts exceed the limit %d", len(receipts), maxReceipts)
+	}
+
+	cfg, err := m.config()
+	if err != nil {
+		return xerrors.Errorf("get scheduler config err:%s", err.Error())
+	}
+
+	if cfg.WorkloadVerifySampleRate <= 0 {
+		return nil
+	}
+
+	sampled := make([]*types.WorkloadReceipt, 0)
+	for _, receipt := range receipts {
+		if receipt.ClientID != client.NodeID {
+			m.nodeMgr.RejectReport(client.NodeID, types.ReportWorkloadReceipt,
+				xerrors.Errorf("submitted the receipt of token %s of client %s", receipt.TokenID, receipt.ClientID))
+			continue
+		}
+
+		if err := m.nodeMgr.VerifyReport(client, types.ReportWorkloadReceipt, receipt.Sign, receipt.SignContent()); err != nil {
+			continue
+		}
+
+		if rand.Float64() >= cfg.WorkloadVerifySampleRate {
+			continue
+		}
+
+		receipt.ClientKey = ""
+		receipt.Status = types.WorkloadVerifyPending
+		sampled = append(sampled, receipt)
+	}
+
+	return m.SaveWorkloadReceipts(sampled)
+}
+
+// AddClientReceipts saves the receipts signed by the download clients as independent evidence of the bytes
+// the nodes served, the client ids are derived from the keys of the clients. Only the first receipt of a token
+// is kept, and the token must be issued for the node and the client and not processed yet
+func (m *Manager) AddClientReceipts(receipts []*types.WorkloadReceipt) error {
+	if len(receipts) > maxReceipts {
+		return xerrors.Errorf("%d receipts exceed the limit %d", len(receipts), maxReceipts)
+	}
+
+	accepted := make([]*types.WorkloadReceipt, 0, len(receipts))
+	for _, receipt := range receipts {
+		if err := m.checkClientReceipt(receipt); err != nil {
+			log.Warnf("client %s receipt of token %s: %s", receipt.ClientID, receipt.TokenID, err.Error())
+			continue
+		}
+
+		receipt.Status = types.WorkloadVerifyPending
+		accepted = append(accepted, receipt)
+	}
+
+	return m.SaveWorkloadReceipts(accepted)
+}
+
+func (m *Manager) checkClientReceipt(receipt *types.WorkloadReceipt) error {
+	pub, err := nodekey.Pem2PublicKey([]byte(receipt.ClientKey))
+	if err != nil {
+		return xerrors.Errorf("decode client key err:%s", err.Error())
+	}
+
+	clientID, err := nodekey.NodeID(types.ClientIDPrefix, pub)
+	if err != nil {
+		return err
+	}
+
+	if clientID != receipt.ClientID {
+		return xerrors.Errorf("client id is not derived from the key, expected %s", clientID)
+	}
+
+	if err := nodekey.Verify(pub, receipt.Sign, receipt.SignContent()); err != nil {
+		return xerrors.Errorf("verify sign err:%s", err.Error())
+	}
+
+	record, err := m.LoadWorkloadRecord(receipt.TokenID)
+	if err != nil {
+		return xerrors.Errorf("load workload record err:%s", err.Error())
+	}
+
+	if record.NodeID != receipt.NodeID {
+		return xerrors.Errorf("token is issued for node %s", record.NodeID)
+	}
+
+	// the receipts of the tokens issued for other clients are not evidence
+	if record.ClientID != receipt.ClientID {
+		return xerrors.Errorf("token is issued for client %s", record.ClientID)
+	}
+
+	if record.Status != types.WorkloadStatusCreate {
+		return xerrors.Errorf("token is processed")
+	}
+
+	return nil
+}
+
+// verifyReceipts cross-checks the sampled receipts of the records against the workloads reported by the nodes,
+// returns the tokens the nodes overreported
+func (m *Manager) verifyReceipts(records []*types.WorkloadRecord) map[string]bool {
+	tokenIDs := make([]string, 0, len(records))
+	for _, record := range records {
+		tokenIDs = append(tokenIDs, record.ID)
+	}
+
+	receipts, err := m.LoadPendingWorkloadReceipts(tokenIDs)
+	if err != nil {
+		log.Errorf("LoadPendingWorkloadReceipts err:%s", err.Error())
+		return nil
+	}
+
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	tolerance := 0.0
+	if cfg, err := m.config(); err == nil {
+		tolerance = cfg.WorkloadVerifyTolerance
+	}
+
+	reported := make(map[string]int64, len(records))
+	for _, record := range records {
+		if len(record.NodeWorkload) == 0 {
+			continue
+		}
+
+		nWorkload, err := decodeWorkload(record.NodeWorkload)
+		if err != nil {
+			log.Errorf("decode workload of token %s err:%s", record.ID, err.Error())
+			continue
+		}
+		reported[record.ID] = nWorkload.DownloadSize
+	}
+
+	overreported := make(map[string]bool)
+	for _, receipt := range receipts {
+		size := reported[receipt.TokenID]
+		status := verifyStatus(receipt.DownloadSize, size, tolerance)
+
+		if err := m.UpdateWorkloadReceiptResult(receipt.TokenID, status, size); err != nil {
+			log.Errorf("UpdateWorkloadReceiptResult %s err:%s", receipt.TokenID, err.Error())
+			continue
+		}
+
+		if status == types.WorkloadVerifyOverreported {
+			overreported[receipt.TokenID] = true
+			log.Warnf("node %s reported %d bytes of token %s, the downloader %s received %d", receipt.NodeID, size, receipt.TokenID, receipt.ClientID, receipt.DownloadSize)
+
+			if m.penaltyMgr != nil {
+				m.penaltyMgr.WorkloadDiscrepancy(receipt.NodeID, receipt.TokenID)
+			}
+		}
+	}
+
+	return overreported
+}
+
+// verifyStatus compares the bytes reported by the node with the bytes of the receipt within the tolerance
+func verifyStatus(receiptSize, reportedSize int64, tolerance float64) types.WorkloadVerifyStatus {
+	switch {
+	case reportedSize == 0:
+		return types.WorkloadVerifyUnreported
+	case float64(reportedSize) > float64(receiptSize)*(1+tolerance):
+		return types.WorkloadVerifyOverreported
+	case float64(reportedSize) < float64(receiptSize)*(1-tolerance):
+		return types.WorkloadVerifyUnderreported
+	default:
+		return types.WorkloadVerifyMatched
+	}
+}
+
+func decodeWorkload(data []byte) (*types.Workload, error) {
+	workload := &types.Workload{}
+	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(workload); err != nil {
+		return nil, err
+	}
+	return workload, nil
+}
+
+// GetVerificationStats returns the receipts sampled in [start, end) of the node by their verification result, of all the nodes if nodeID is empty
+func (m *Manager) GetVerificationStats(nodeID string, start, end time.Time) (*types.WorkloadVerificationStats, error) {
+	if !start.Before(end) {
+		return nil, xerrors.New("start must be before end")
+	}
+
+	return m.LoadWorkloadVerificationStats(nodeID, start, end)
+}
+
+func (m *Manager) startCleanReceiptsTimer() {
+	ticker := time.NewTicker(receiptCleanInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		cfg, err := m.config()
+		if err != nil {
+			log.Errorf("get scheduler config err:%s", err.Error())
+			continue
+		}
+
+		if cfg.WorkloadReceiptRetentionDays <= 0 {
+			continue
+		}
+
+		if err := m.DeleteWorkloadReceiptsBefore(time.Now().AddDate(0, 0, -cfg.WorkloadReceiptRetentionDays)); err != nil {
+			log.Errorf("DeleteWorkloadReceiptsBefore err:%s", err.Error())
+		}
+	}
+}
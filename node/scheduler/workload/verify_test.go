@@ -0,0 +1,51 @@
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/nodekey"
+)
+
+func TestVerifyStatus(t *testing.T) {
+	cases := []struct {
+		receipt, reported int64
+		expected          types.WorkloadVerifyStatus
+	}{
+		{1000, 1000, types.WorkloadVerifyMatched},
+		{1000, 1050, types.WorkloadVerifyMatched},
+		{1000, 950, types.WorkloadVerifyMatched},
+		{1000, 1051, types.WorkloadVerifyOverreported},
+		{1000, 949, types.WorkloadVerifyUnderreported},
+		{1000, 0, types.WorkloadVerifyUnreported},
+	}
+
+	for _, c := range cases {
+		if status := verifyStatus(c.receipt, c.reported, 0.05); status != c.expected {
+			t.Fatalf("receipt %d reported %d: expected %s, got %s", c.receipt, c.reported, c.expected, status)
+		}
+	}
+}
+
+func TestReceiptSign(t *testing.T) {
+	key, err := nodekey.Generate(nodekey.TypeEd25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt := &types.WorkloadReceipt{TokenID: "t_1", NodeID: "c_1", ClientID: "e_1", DownloadSize: 1 << 20, StartTime: time.Now(), EndTime: time.Now()}
+	if receipt.Sign, err = nodekey.Sign(key, receipt.SignContent()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = nodekey.Verify(key.Public(), receipt.Sign, receipt.SignContent()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the downloaders sign the bytes they received, a receipt altered after is refused
+	receipt.DownloadSize *= 2
+	if err = nodekey.Verify(key.Public(), receipt.Sign, receipt.SignContent()); err == nil {
+		t.Fatal("expected the altered receipt refused")
+	}
+}
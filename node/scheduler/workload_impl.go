@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+// SubmitWorkloadReceipts submits the receipts of the bytes the node downloaded from the other nodes, signed by the node
+func (s *Scheduler) SubmitWorkloadReceipts(ctx context.Context, receipts []*types.WorkloadReceipt) error {
+	nodeID := handler.GetNodeID(ctx)
+
+	n := s.NodeManager.GetNode(nodeID)
+	if n == nil {
+		return xerrors.Errorf("node %s not exists", nodeID)
+	}
+
+	return s.WorkloadManager.AddReceipts(n, receipts)
+}
+
+// SubmitClientReceipts submits the receipts of the bytes a download client received from the nodes, signed by the key of the client
+func (s *Scheduler) SubmitClientReceipts(ctx context.Context, receipts []*types.WorkloadReceipt) error {
+	return s.WorkloadManager.AddClientReceipts(receipts)
+}
+
+// ListWorkloadReceipts retrieves the sampled workload receipts of the bytes served by the node, the latest first
+func (s *Scheduler) ListWorkloadReceipts(ctx context.Context, nodeID string, limit, offset int) (*types.ListWorkloadReceiptRsp, error) {
+	return s.WorkloadManager.LoadWorkloadReceipts(nodeID, limit, offset)
+}
+
+// GetWorkloadVerificationStats retrieves the workload receipts sampled in [start, end) of the node by their verification result
+func (s *Scheduler) GetWorkloadVerificationStats(ctx context.Context, nodeID string, start, end time.Time) (*types.WorkloadVerificationStats, error) {
+	return s.WorkloadManager.GetVerificationStats(nodeID, start, end)
+}
@@ -0,0 +1,153 @@
+package upgrader
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/build"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("upgrader")
+
+const (
+	// the largest binary the node downloads
+	maxBinarySize = 512 << 20
+	// the node answers the scheduler before it exits
+	exitDelay = 2 * time.Second
+)
+
+// Apply verifies the upgrade command signed by the scheduler, downloads the binary of the platform of the node,
+// checks its hash and replaces the running binary, the node exits after and is restarted by its supervisor.
+// The replaced binary is kept with the suffix .old
+func Apply(ctx context.Context, cmd *types.UpgradeCommand, schedulerKey *rsa.PublicKey, shutdown chan struct{}) error {
+	if cmd.Manifest == nil {
+		return xerrors.New("upgrade command has no release")
+	}
+
+	if time.Now().After(time.Unix(cmd.Expiration, 0)) {
+		return xerrors.New("upgrade command expired")
+	}
+
+	if err := titanrsa.New(crypto.SHA256, crypto.SHA256.New()).VerifySign(schedulerKey, cmd.Sign, cmd.SignData()); err != nil {
+		return xerrors.Errorf("verify upgrade command err:%s", err.Error())
+	}
+
+	manifest := cmd.Manifest
+	if manifest.NodeType != types.RunningNodeType {
+		return xerrors.Errorf("release of node type %d, the node is %d", manifest.NodeType, types.RunningNodeType)
+	}
+
+	if types.ReleaseVersion(build.UserVersion()) == manifest.Version {
+		return nil
+	}
+
+	artifact := manifest.Artifacts.Artifact(types.CurrentPlatform())
+	if artifact == nil {
+		return xerrors.Errorf("release %s has no binary of %s", manifest.Version, types.CurrentPlatform())
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	tmp := exe + ".download"
+	defer os.Remove(tmp) //nolint:errcheck // the file is renamed on success
+
+	if err := download(ctx, artifact, tmp); err != nil {
+		return err
+	}
+
+	if err := replace(exe, tmp); err != nil {
+		return err
+	}
+
+	log.Infof("binary is replaced by release %s, the node exits", manifest.Version)
+
+	go func() {
+		time.Sleep(exitDelay)
+		shutdown <- struct{}{}
+	}()
+
+	return nil
+}
+
+// download saves the binary of the artifact to the path if its sha256 matches
+func download(ctx context.Context, artifact *types.ReleaseArtifact, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifact.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("download %s err:%s", artifact.URL, err.Error())
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("download %s status %d", artifact.URL, resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(resp.Body, maxBinarySize+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return xerrors.Errorf("download %s err:%s", artifact.URL, err.Error())
+	}
+
+	if n > maxBinarySize {
+		return xerrors.Errorf("binary of %s exceeds %d bytes", artifact.URL, maxBinarySize)
+	}
+
+	if hash := hex.EncodeToString(h.Sum(nil)); hash != artifact.Hash {
+		return xerrors.Errorf("sha256 %s of the binary mismatches %s", hash, artifact.Hash)
+	}
+
+	return os.Chmod(path, 0o755)
+}
+
+// replace moves the running binary aside and the new binary in its place, the running binary is restored on failure
+func replace(exe, binary string) error {
+	old := exe + ".old"
+	if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(exe, old); err != nil {
+		return xerrors.Errorf("move the running binary err:%s", err.Error())
+	}
+
+	if err := os.Rename(binary, exe); err != nil {
+		if rerr := os.Rename(old, exe); rerr != nil {
+			log.Errorf("restore the running binary err:%s", rerr.Error())
+		}
+		return xerrors.Errorf("move the new binary err:%s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package upgrader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestDownloadAndReplace(t *testing.T) {
+	binary := []byte("new binary")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary) //nolint:errcheck // test server
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256(binary)
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "titan-edge")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tmp := exe + ".download"
+	bad := &types.ReleaseArtifact{URL: srv.URL, Hash: hex.EncodeToString(make([]byte, 32))}
+	if err := download(context.Background(), bad, tmp); err == nil {
+		t.Fatal("the binary of a mismatched hash should be rejected")
+	}
+
+	artifact := &types.ReleaseArtifact{URL: srv.URL, Hash: hex.EncodeToString(sum[:])}
+	if err := download(context.Background(), artifact, tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replace(exe, tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := os.ReadFile(exe); string(data) != "new binary" {
+		t.Errorf("binary is not replaced: %s", data)
+	}
+
+	if data, _ := os.ReadFile(exe + ".old"); string(data) != "old binary" {
+		t.Errorf("old binary is not kept: %s", data)
+	}
+}